@@ -36,6 +36,55 @@ type RemoteClusterSpec struct {
 	// PVCSync configures PVC synchronization for this cluster
 	// +optional
 	PVCSync *PVCSyncSpec `json:"pvcSync,omitempty"`
+
+	// Proxy configures how API traffic to this cluster is proxied, e.g. when the
+	// cluster API is only reachable through a bastion
+	// +optional
+	Proxy *ProxyConfig `json:"proxy,omitempty"`
+}
+
+// ProxyConfig defines how API and exec traffic to a remote cluster is proxied.
+// Only one of URL or SSHJumpHost may be set.
+type ProxyConfig struct {
+	// URL is an HTTP, HTTPS or SOCKS5 proxy URL (e.g. http://proxy:3128, socks5://bastion:1080)
+	// +optional
+	// +kubebuilder:validation:Pattern=`^(http|https|socks5)://.+`
+	URL string `json:"url,omitempty"`
+
+	// SSHJumpHost tunnels traffic to the cluster API through an SSH bastion
+	// +optional
+	SSHJumpHost *SSHJumpHostConfig `json:"sshJumpHost,omitempty"`
+}
+
+// SSHJumpHostConfig defines an SSH bastion used to reach a remote cluster API
+type SSHJumpHostConfig struct {
+	// Address is the host:port of the SSH bastion
+	Address string `json:"address"`
+
+	// User is the SSH user on the bastion
+	User string `json:"user"`
+
+	// KeySecretRef references a secret containing the SSH private key
+	KeySecretRef SSHJumpHostKeySecretRef `json:"keySecretRef"`
+
+	// HostKey is the bastion public host key in authorized_keys format.
+	// Connections to a bastion whose host key does not match are refused.
+	// +kubebuilder:validation:MinLength=1
+	HostKey string `json:"hostKey"`
+}
+
+// SSHJumpHostKeySecretRef references the secret holding an SSH jump host private key
+type SSHJumpHostKeySecretRef struct {
+	// Name is the name of the secret
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the secret
+	Namespace string `json:"namespace"`
+
+	// Key is the key in the secret containing the private key
+	// +optional
+	// +kubebuilder:default=ssh-privatekey
+	Key string `json:"key,omitempty"`
 }
 
 // HealthCheckConfig defines configuration for health checking
@@ -83,6 +132,12 @@ type PVCSyncSpec struct {
 	// +kubebuilder:validation:Maximum=256
 	GlobalConcurrencyLimit *int32 `json:"globalConcurrencyLimit,omitempty"`
 
+	// MaxConcurrentDataSyncs limits the PVC data syncs this cluster takes part in at the same time,
+	// as source or destination, across all NamespaceMappings using it. Unset leaves it unlimited.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	MaxConcurrentDataSyncs *int32 `json:"maxConcurrentDataSyncs,omitempty"`
+
 	// RetryConfig configures retry behavior for failed syncs
 	// +optional
 	RetryConfig *PVCSyncRetryConfig `json:"retryConfig,omitempty"`
@@ -115,6 +170,42 @@ type PVCSyncSpec struct {
 	// of rsync pods on each destination cluster node.
 	// +optional
 	RsyncDaemonSet *RsyncDaemonSetConfig `json:"rsyncDaemonSet,omitempty"`
+
+	// SecurityProfile selects the securityContext of the rsync pods created in this cluster
+	// when it is the destination of a PVC sync. Restricted runs rsync as a non-root user that
+	// complies with the restricted PodSecurity standard, recording file ownership in extended
+	// attributes instead of applying it, and does not use the privileged rsync DaemonSet pool.
+	// +optional
+	// +kubebuilder:default=Privileged
+	SecurityProfile RsyncSecurityProfile `json:"securityProfile,omitempty"`
+
+	// Agentless syncs PVC data out of this cluster without the agent DaemonSet, for clusters where
+	// privileged pods or SSH to the nodes are not allowed. The agent is not deployed, a helper pod
+	// mounting the source PVC read-only streams a tar archive through the exec API of both clusters
+	// into the destination rsync pod. Every sync transfers the whole volume, so it suits small volumes.
+	// +optional
+	// +kubebuilder:default=false
+	Agentless bool `json:"agentless,omitempty"`
+}
+
+// RsyncSecurityProfile selects the securityContext of rsync pods
+// +kubebuilder:validation:Enum=Privileged;Restricted
+type RsyncSecurityProfile string
+
+const (
+	// RsyncSecurityProfilePrivileged runs rsync as root so file ownership is preserved
+	RsyncSecurityProfilePrivileged RsyncSecurityProfile = "Privileged"
+
+	// RsyncSecurityProfileRestricted runs rsync rootless under the restricted PodSecurity standard
+	RsyncSecurityProfileRestricted RsyncSecurityProfile = "Restricted"
+)
+
+// GetSecurityProfile returns the rsync security profile with default value of Privileged
+func (p *PVCSyncSpec) GetSecurityProfile() RsyncSecurityProfile {
+	if p == nil || p.SecurityProfile == "" {
+		return RsyncSecurityProfilePrivileged
+	}
+	return p.SecurityProfile
 }
 
 // GetGlobalConcurrencyLimit returns the global concurrency limit with default value of 4
@@ -125,6 +216,14 @@ func (p *PVCSyncSpec) GetGlobalConcurrencyLimit() int32 {
 	return *p.GlobalConcurrencyLimit
 }
 
+// GetMaxConcurrentDataSyncs returns the data sync limit of the cluster, 0 when it is unlimited
+func (p *PVCSyncSpec) GetMaxConcurrentDataSyncs() int32 {
+	if p == nil || p.MaxConcurrentDataSyncs == nil {
+		return 0
+	}
+	return *p.MaxConcurrentDataSyncs
+}
+
 // RsyncDaemonSetConfig configures the destination rsync DaemonSet pool
 type RsyncDaemonSetConfig struct {
 	// Enabled controls whether to use the DaemonSet pool approach.
@@ -278,8 +377,126 @@ type PVCSyncSSH struct {
 	// KeySecretRef references a secret containing SSH keys
 	// +optional
 	KeySecretRef *SSHKeySecretRef `json:"keySecretRef,omitempty"`
+
+	// RsyncMode selects how the destination pulls data from the agent.
+	// Shell runs rsync over a full SSH session, Daemon restricts the key to a
+	// read-only rsync daemon serving a per-sync module.
+	// +optional
+	// +kubebuilder:validation:Enum=Shell;Daemon
+	// +kubebuilder:default=Shell
+	RsyncMode RsyncMode `json:"rsyncMode,omitempty"`
+
+	// KeyRotationInterval regenerates the agent host keys and the rsync key pair once it elapsed
+	// since the last rotation. Unset never rotates the keys.
+	// +optional
+	KeyRotationInterval *metav1.Duration `json:"keyRotationInterval,omitempty"`
+
+	// Exposure selects how the destination rsync pods reach the SSH port of the agents.
+	// Unset exposes the port on the host network of the nodes.
+	// +optional
+	Exposure *AgentExposure `json:"exposure,omitempty"`
+}
+
+// AgentExposureType defines how the agent SSH port is exposed outside the cluster
+type AgentExposureType string
+
+const (
+	// AgentExposureHostNetwork runs the agents on the host network, reached on the node address and SSH port (default)
+	AgentExposureHostNetwork AgentExposureType = "HostNetwork"
+	// AgentExposureHostPort maps a host port of each node to the agent running on it
+	AgentExposureHostPort AgentExposureType = "HostPort"
+	// AgentExposureNodePort exposes the agents through a NodePort Service routing to the agent of the node connected to
+	AgentExposureNodePort AgentExposureType = "NodePort"
+	// AgentExposureLoadBalancer exposes the agents through a LoadBalancer Service with a port per node
+	AgentExposureLoadBalancer AgentExposureType = "LoadBalancer"
+	// AgentExposureReverse has the agents connect out to the rendezvous of the controller, which forwards a
+	// port per node back to them, for clusters whose nodes accept no inbound connections
+	AgentExposureReverse AgentExposureType = "Reverse"
+)
+
+// NodeAddressType selects the node address advertised for the agents
+type NodeAddressType string
+
+const (
+	// NodeAddressExternalIP advertises the external IP of the node, the internal IP when it has none (default)
+	NodeAddressExternalIP NodeAddressType = "ExternalIP"
+	// NodeAddressInternalIP advertises the internal IP of the node
+	NodeAddressInternalIP NodeAddressType = "InternalIP"
+)
+
+// AgentExposure configures how the agent SSH port is reached from the destination cluster
+type AgentExposure struct {
+	// Type is how the agent SSH port is exposed
+	// +optional
+	// +kubebuilder:validation:Enum=HostNetwork;HostPort;NodePort;LoadBalancer;Reverse
+	// +kubebuilder:default=HostNetwork
+	Type AgentExposureType `json:"type,omitempty"`
+
+	// HostPort is the port opened on each node with the HostPort type, the SSH port when unset
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	HostPort int32 `json:"hostPort,omitempty"`
+
+	// NodePort is the node port of the NodePort Service, allocated by Kubernetes when unset
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	NodePort int32 `json:"nodePort,omitempty"`
+
+	// AddressType is the node address advertised with the HostNetwork, HostPort and NodePort types
+	// +optional
+	// +kubebuilder:validation:Enum=ExternalIP;InternalIP
+	// +kubebuilder:default=ExternalIP
+	AddressType NodeAddressType `json:"addressType,omitempty"`
+
+	// ServiceAnnotations are set on the agent Service, such as to request an internal load balancer
+	// +optional
+	ServiceAnnotations map[string]string `json:"serviceAnnotations,omitempty"`
+
+	// Rendezvous is the controller rendezvous the agents connect to with the Reverse type
+	// +optional
+	Rendezvous *AgentRendezvous `json:"rendezvous,omitempty"`
+}
+
+// AgentRendezvous configures the outbound connections of the agents to the rendezvous of the controller
+type AgentRendezvous struct {
+	// Address is the host:port of the rendezvous the agents connect to
+	// +kubebuilder:validation:MinLength=1
+	Address string `json:"address"`
+
+	// AdvertisedAddress is the host the destination rsync pods reach the forwarded ports on, the host of
+	// Address when unset
+	// +optional
+	AdvertisedAddress string `json:"advertisedAddress,omitempty"`
+
+	// HostKey is the public host key of the rendezvous in authorized_keys format, verified by the agents
+	// +kubebuilder:validation:MinLength=1
+	HostKey string `json:"hostKey"`
+
+	// PortRangeStart is the first rendezvous port forwarded to the agents of the cluster, each node is
+	// given a port of the range. Ranges of clusters sharing a rendezvous must not overlap.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	PortRangeStart int32 `json:"portRangeStart"`
+
+	// PortRangeSize is the number of ports reserved for the cluster, nodes beyond it are not forwarded
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=100
+	PortRangeSize int32 `json:"portRangeSize,omitempty"`
 }
 
+// RsyncMode defines how rsync reaches the agent over SSH
+type RsyncMode string
+
+const (
+	// RsyncModeShell runs rsync through a remote shell on the agent (default)
+	RsyncModeShell RsyncMode = "Shell"
+	// RsyncModeDaemon serves the PVC through a read-only rsync daemon module behind an SSH forced command
+	RsyncModeDaemon RsyncMode = "Daemon"
+)
+
 // SSHKeySecretRef references a secret containing SSH keys
 type SSHKeySecretRef struct {
 	// Name is the name of the secret
@@ -314,6 +531,10 @@ type KubeconfigSecretRef struct {
 	// Key is the key in the secret containing the kubeconfig
 	// +optional
 	Key string `json:"key,omitempty"`
+
+	// Context is the kubeconfig context to use. Defaults to the kubeconfig's current context.
+	// +optional
+	Context string `json:"context,omitempty"`
 }
 
 type RemoteClusterStatus struct {
@@ -352,6 +573,10 @@ type PVCSyncStatus struct {
 	// +optional
 	LastDeploymentTime *metav1.Time `json:"lastDeploymentTime,omitempty"`
 
+	// LastKeyRotation is when the agent and rsync SSH keys were last generated
+	// +optional
+	LastKeyRotation *metav1.Time `json:"lastKeyRotation,omitempty"`
+
 	// FailedSyncs is the number of failed sync attempts
 	// +optional
 	FailedSyncs int32 `json:"failedSyncs,omitempty"`
@@ -371,9 +596,38 @@ type PVCSyncAgentStatus struct {
 	// +optional
 	TotalNodes int32 `json:"totalNodes,omitempty"`
 
+	// UpdatedNodes is the number of nodes running the agent of the current DaemonSet spec
+	// +optional
+	UpdatedNodes int32 `json:"updatedNodes,omitempty"`
+
+	// AvailableNodes is the number of nodes with an available agent
+	// +optional
+	AvailableNodes int32 `json:"availableNodes,omitempty"`
+
+	// Image is the agent image of the current DaemonSet spec
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// RolloutComplete indicates whether every node runs an available agent of the current DaemonSet spec
+	// +optional
+	RolloutComplete bool `json:"rolloutComplete,omitempty"`
+
 	// NodeStatuses contains per-node agent status
 	// +optional
 	NodeStatuses map[string]PVCSyncNodeStatus `json:"nodeStatuses,omitempty"`
+
+	// Endpoints are the advertised SSH addresses of the agents by node name, resolved from the exposure
+	// +optional
+	Endpoints map[string]AgentEndpoint `json:"endpoints,omitempty"`
+}
+
+// AgentEndpoint is the address and port the destination rsync pods connect to for the agent of a node
+type AgentEndpoint struct {
+	// Address is the IP address or host name
+	Address string `json:"address"`
+
+	// Port is the SSH port
+	Port int32 `json:"port"`
 }
 
 // SSHConnectionStatus contains SSH connectivity information
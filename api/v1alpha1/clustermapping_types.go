@@ -20,6 +20,11 @@ const (
 	ClusterMappingPhaseFailed ClusterMappingPhase = "Failed"
 )
 
+// AllowedNamespacesAnnotation lists the namespaces whose NamespaceMappings may reference a
+// ClusterMapping from another namespace, as a comma-separated list or "*" for all namespaces.
+// NamespaceMappings in the ClusterMapping's own namespace are always allowed.
+const AllowedNamespacesAnnotation = "dr-syncer.io/allowed-namespaces"
+
 // ClusterMappingSSHKeySecretRef extends SSHKeySecretRef with additional fields
 type ClusterMappingSSHKeySecretRef struct {
 	// Name is the name of the secret
@@ -40,6 +45,52 @@ type ClusterMappingSSHKeySecretRef struct {
 	PrivateKeyKey string `json:"privateKeyKey,omitempty"`
 }
 
+// ClusterMappingDefaults are the mappings inherited by all NamespaceMappings referencing a
+// ClusterMapping. A NamespaceMapping's own mapping for the same source name takes precedence.
+type ClusterMappingDefaults struct {
+	// StorageClassMappings are the default storage class mappings of pvcConfig.storageClassMappings
+	// +optional
+	StorageClassMappings []StorageClassMapping `json:"storageClassMappings,omitempty"`
+
+	// AccessModeMappings are the default access mode mappings of pvcConfig.accessModeMappings
+	// +optional
+	AccessModeMappings []AccessModeMapping `json:"accessModeMappings,omitempty"`
+
+	// IngressClassMappings are the default ingress class mappings of ingressConfig.ingressClassMappings
+	// +optional
+	IngressClassMappings []IngressClassMapping `json:"ingressClassMappings,omitempty"`
+}
+
+// DeepCopyInto copies ClusterMappingDefaults into out
+func (in *ClusterMappingDefaults) DeepCopyInto(out *ClusterMappingDefaults) {
+	*out = *in
+	if in.StorageClassMappings != nil {
+		in, out := &in.StorageClassMappings, &out.StorageClassMappings
+		*out = make([]StorageClassMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.AccessModeMappings != nil {
+		in, out := &in.AccessModeMappings, &out.AccessModeMappings
+		*out = make([]AccessModeMapping, len(*in))
+		copy(*out, *in)
+	}
+	if in.IngressClassMappings != nil {
+		in, out := &in.IngressClassMappings, &out.IngressClassMappings
+		*out = make([]IngressClassMapping, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy creates a deep copy of ClusterMappingDefaults
+func (in *ClusterMappingDefaults) DeepCopy() *ClusterMappingDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterMappingDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // ClusterMappingSpec defines the desired state of ClusterMapping
 type ClusterMappingSpec struct {
 	// Paused defines whether connectivity verification is paused
@@ -67,6 +118,16 @@ type ClusterMappingSpec struct {
 	// +optional
 	// +kubebuilder:default=60
 	ConnectivityTimeoutSeconds *int32 `json:"connectivityTimeoutSeconds,omitempty"`
+
+	// Defaults are the storage class, access mode and ingress class mappings inherited by all
+	// NamespaceMappings referencing this ClusterMapping
+	// +optional
+	Defaults *ClusterMappingDefaults `json:"defaults,omitempty"`
+
+	// KeyExpiryGracePeriod is how long the source agents keep accepting the previous key of the
+	// target after it was rotated, so syncs started under the old key can finish. Defaults to 1h.
+	// +optional
+	KeyExpiryGracePeriod *metav1.Duration `json:"keyExpiryGracePeriod,omitempty"`
 }
 
 // AgentConnectionDetail provides connection details for a specific agent
@@ -131,6 +192,10 @@ type ClusterMappingStatus struct {
 	// +optional
 	// +kubebuilder:default=0
 	ConsecutiveFailures int `json:"consecutiveFailures,omitempty"`
+
+	// LastKeyDistribution is when the key of the target was last declared to the source agents
+	// +optional
+	LastKeyDistribution *metav1.Time `json:"lastKeyDistribution,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -190,6 +255,14 @@ func (c *ClusterMapping) DeepCopyInto(out *ClusterMapping) {
 		*out = new(int32)
 		**out = **in
 	}
+	if c.Spec.Defaults != nil {
+		out.Spec.Defaults = c.Spec.Defaults.DeepCopy()
+	}
+	if c.Spec.KeyExpiryGracePeriod != nil {
+		in, out := &c.Spec.KeyExpiryGracePeriod, &out.Spec.KeyExpiryGracePeriod
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 
 	// Deep copy status
 	if c.Status.LastVerified != nil {
@@ -220,6 +293,11 @@ func (c *ClusterMapping) DeepCopyInto(out *ClusterMapping) {
 		*out = new(metav1.Time)
 		**out = **in
 	}
+	if c.Status.LastKeyDistribution != nil {
+		in, out := &c.Status.LastKeyDistribution, &out.Status.LastKeyDistribution
+		*out = new(metav1.Time)
+		**out = **in
+	}
 }
 
 // DeepCopyObject implements runtime.Object interface
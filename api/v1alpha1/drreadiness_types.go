@@ -0,0 +1,289 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+// DRReadinessConditionReady reports whether the application is recoverable in the DR cluster
+const DRReadinessConditionReady = "Ready"
+
+// DRReadinessSpec defines which NamespaceMappings make up an application and how fresh their syncs must be
+type DRReadinessSpec struct {
+	// NamespaceMappings lists NamespaceMappings in this namespace that make up the application
+	// +optional
+	NamespaceMappings []string `json:"namespaceMappings,omitempty"`
+
+	// Selector selects NamespaceMappings in this namespace by label, in addition to NamespaceMappings
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// MaxResourceSyncAge is the longest time since the last successful resource sync before the application is not ready
+	// +optional
+	// +kubebuilder:default="1h"
+	MaxResourceSyncAge *metav1.Duration `json:"maxResourceSyncAge,omitempty"`
+
+	// MaxDataSyncAge is the longest time since the last successful PVC data sync before the application is not ready
+	// +optional
+	// +kubebuilder:default="24h"
+	MaxDataSyncAge *metav1.Duration `json:"maxDataSyncAge,omitempty"`
+
+	// CheckInterval is how often readiness is re-evaluated
+	// +optional
+	// +kubebuilder:default="1m"
+	CheckInterval *metav1.Duration `json:"checkInterval,omitempty"`
+}
+
+// NamespaceMappingReadiness reports the resource sync state of a single NamespaceMapping
+type NamespaceMappingReadiness struct {
+	// Name of the NamespaceMapping
+	Name string `json:"name"`
+
+	// Phase of the NamespaceMapping
+	// +optional
+	Phase SyncPhase `json:"phase,omitempty"`
+
+	// LastSuccessfulSyncTime is when resources were last synced successfully
+	// +optional
+	LastSuccessfulSyncTime *metav1.Time `json:"lastSuccessfulSyncTime,omitempty"`
+
+	// SyncAge is the time since the last successful resource sync
+	// +optional
+	SyncAge *metav1.Duration `json:"syncAge,omitempty"`
+
+	// Ready indicates the resource sync is fresh enough
+	Ready bool `json:"ready"`
+
+	// Message explains why the NamespaceMapping is not ready
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// PVCReadiness reports the data sync state of a single PVC
+type PVCReadiness struct {
+	// NamespaceMapping is the NamespaceMapping replicating the PVC
+	NamespaceMapping string `json:"namespaceMapping"`
+
+	// Namespace of the PVC in the source cluster
+	Namespace string `json:"namespace"`
+
+	// Name of the PVC in the source cluster
+	Name string `json:"name"`
+
+	// LastDataSyncTime is when the last successful data sync started. Changes made after this time
+	// may not have reached the destination.
+	// +optional
+	LastDataSyncTime *metav1.Time `json:"lastDataSyncTime,omitempty"`
+
+	// SyncAge is the time since the last successful data sync started
+	// +optional
+	SyncAge *metav1.Duration `json:"syncAge,omitempty"`
+
+	// Ready indicates the data sync is fresh enough
+	Ready bool `json:"ready"`
+
+	// Message explains why the PVC is not ready
+	// +optional
+	Message string `json:"message,omitempty"`
+}
+
+// DRReadinessStatus reports whether the application is recoverable right now
+type DRReadinessStatus struct {
+	// LastCheckTime is when readiness was last evaluated
+	// +optional
+	LastCheckTime *metav1.Time `json:"lastCheckTime,omitempty"`
+
+	// EstimatedRPO is the age of the oldest successful sync across all resources and PVC data,
+	// an estimate of how much would be lost if the source cluster failed now
+	// +optional
+	EstimatedRPO *metav1.Duration `json:"estimatedRPO,omitempty"`
+
+	// NamespaceMappings reports the resource sync state of each NamespaceMapping
+	// +optional
+	NamespaceMappings []NamespaceMappingReadiness `json:"namespaceMappings,omitempty"`
+
+	// PVCs reports the data sync state of each PVC with data replication enabled
+	// +optional
+	PVCs []PVCReadiness `json:"pvcs,omitempty"`
+
+	// Conditions contains the Ready condition
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="RPO",type="string",JSONPath=".status.estimatedRPO"
+// +kubebuilder:printcolumn:name="Last Check",type="date",JSONPath=".status.lastCheckTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:shortName=drr
+type DRReadiness struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DRReadinessSpec   `json:"spec,omitempty"`
+	Status DRReadinessStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type DRReadinessList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DRReadiness `json:"items"`
+}
+
+// DeepCopyInto copies DRReadinessSpec into out
+func (in *DRReadinessSpec) DeepCopyInto(out *DRReadinessSpec) {
+	*out = *in
+	if in.NamespaceMappings != nil {
+		in, out := &in.NamespaceMappings, &out.NamespaceMappings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = (*in).DeepCopy()
+	}
+	if in.MaxResourceSyncAge != nil {
+		in, out := &in.MaxResourceSyncAge, &out.MaxResourceSyncAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.MaxDataSyncAge != nil {
+		in, out := &in.MaxDataSyncAge, &out.MaxDataSyncAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.CheckInterval != nil {
+		in, out := &in.CheckInterval, &out.CheckInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopyInto copies NamespaceMappingReadiness into out
+func (in *NamespaceMappingReadiness) DeepCopyInto(out *NamespaceMappingReadiness) {
+	*out = *in
+	if in.LastSuccessfulSyncTime != nil {
+		in, out := &in.LastSuccessfulSyncTime, &out.LastSuccessfulSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SyncAge != nil {
+		in, out := &in.SyncAge, &out.SyncAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopyInto copies PVCReadiness into out
+func (in *PVCReadiness) DeepCopyInto(out *PVCReadiness) {
+	*out = *in
+	if in.LastDataSyncTime != nil {
+		in, out := &in.LastDataSyncTime, &out.LastDataSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SyncAge != nil {
+		in, out := &in.SyncAge, &out.SyncAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopyInto copies DRReadinessStatus into out
+func (in *DRReadinessStatus) DeepCopyInto(out *DRReadinessStatus) {
+	*out = *in
+	if in.LastCheckTime != nil {
+		in, out := &in.LastCheckTime, &out.LastCheckTime
+		*out = (*in).DeepCopy()
+	}
+	if in.EstimatedRPO != nil {
+		in, out := &in.EstimatedRPO, &out.EstimatedRPO
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.NamespaceMappings != nil {
+		in, out := &in.NamespaceMappings, &out.NamespaceMappings
+		*out = make([]NamespaceMappingReadiness, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PVCs != nil {
+		in, out := &in.PVCs, &out.PVCs
+		*out = make([]PVCReadiness, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopyInto copies all properties of DRReadiness into another instance
+func (in *DRReadiness) DeepCopyInto(out *DRReadiness) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy creates a deep copy of DRReadiness
+func (in *DRReadiness) DeepCopy() *DRReadiness {
+	if in == nil {
+		return nil
+	}
+	out := new(DRReadiness)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object interface
+func (in *DRReadiness) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies all properties of DRReadinessList into another instance
+func (in *DRReadinessList) DeepCopyInto(out *DRReadinessList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DRReadiness, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of DRReadinessList
+func (in *DRReadinessList) DeepCopy() *DRReadinessList {
+	if in == nil {
+		return nil
+	}
+	out := new(DRReadinessList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object interface
+func (in *DRReadinessList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+func init() {
+	SchemeBuilder.Register(&DRReadiness{}, &DRReadinessList{})
+}
@@ -66,20 +66,71 @@ type NamespaceMappingSpec struct {
 	// +optional
 	DestinationNamespace string `json:"destinationNamespace,omitempty"`
 
+	// AllowAdoptExisting lets the mapping sync into an existing destination namespace that is not
+	// labeled dr-syncer.io/managed-by=dr-syncer. The namespace is labeled as managed on the first sync.
+	// Without it such namespaces are refused, so a mapping never clobbers a live namespace.
+	// +optional
+	// +kubebuilder:default=false
+	AllowAdoptExisting *bool `json:"allowAdoptExisting,omitempty"`
+
 	// Schedule is the crontab schedule for replication
 	// +optional
 	// +kubebuilder:validation:Pattern=^(\*|([0-9]|1[0-9]|2[0-9]|3[0-9]|4[0-9]|5[0-9])|\*/[0-9]+|\*\/[1-5][0-9])\s+(\*|([0-9]|1[0-9]|2[0-3])|\*/[0-9]+)\s+(\*|([1-9]|1[0-9]|2[0-9]|3[0-1])|\*/[0-9]+)\s+(\*|([1-9]|1[0-2])|\*/[0-9]+)\s+(\*|([0-6])|\*/[0-9]+)$
 	Schedule string `json:"schedule,omitempty"`
 
+	// Timezone is the IANA time zone the schedule is evaluated in, such as Europe/Berlin. Defaults to the
+	// time zone of the controller, which is UTC in the released image.
+	// +optional
+	Timezone string `json:"timezone,omitempty"`
+
 	// ResourceTypes is the list of resource types to replicate
 	// +optional
 	ResourceTypes []string `json:"resourceTypes,omitempty"`
 
+	// ExcludedResourceTypes lists resource types skipped when ResourceTypes is ["*"], on top of
+	// pods, events, endpoints, endpointslices, leases, replicasets and controllerrevisions.
+	// Format: "resource" for any group or "resource.group" (e.g. "widgets.example.com")
+	// +optional
+	ExcludedResourceTypes []string `json:"excludedResourceTypes,omitempty"`
+
+	// ResourceSelector limits the synced resources to those whose labels match. NamespaceMappings sharing
+	// a destination namespace must select disjoint resources, such as different values of the same label.
+	// +optional
+	ResourceSelector *metav1.LabelSelector `json:"resourceSelector,omitempty"`
+
+	// Consistency configures how consistent the namespace state applied by a sync is
+	// +optional
+	Consistency *SyncConsistency `json:"consistency,omitempty"`
+
 	// ScaleToZero determines whether deployments should be scaled to zero replicas in the destination cluster
 	// +optional
 	// +kubebuilder:default=true
 	ScaleToZero *bool `json:"scaleToZero,omitempty"`
 
+	// WorkloadOverrides set the replicas of specific Deployments and StatefulSets in the destination
+	// cluster regardless of ScaleToZero, e.g. to keep a database operator running warm in DR. A source
+	// workload can also set its destination replicas with the dr-syncer.io/dr-replicas annotation, the
+	// overrides listed here take precedence over it.
+	// +optional
+	WorkloadOverrides []WorkloadOverride `json:"workloadOverrides,omitempty"`
+
+	// NetworkIsolation isolates the destination namespace with NetworkPolicies while its workloads are
+	// scaled to zero as a standby, so synced workloads cannot make calls from the DR cluster before activation
+	// +optional
+	NetworkIsolation *NetworkIsolationConfig `json:"networkIsolation,omitempty"`
+
+	// ServiceBridge replaces selected destination Services with bridges to their source endpoints, so
+	// workloads activated in the destination can read from services still running in the source during
+	// a partial failover. A mapping syncing back from DR during failback bridges to the DR endpoints.
+	// +optional
+	ServiceBridge *ServiceBridgeConfig `json:"serviceBridge,omitempty"`
+
+	// SuspendCronJobs determines whether CronJobs and Jobs should be created suspended in the destination cluster
+	// so they don't run in both clusters. They are unsuspended during cutover.
+	// +optional
+	// +kubebuilder:default=true
+	SuspendCronJobs *bool `json:"suspendCronJobs,omitempty"`
+
 	// NamespaceScopedResources is a list of namespace scoped resources to replicate
 	// Format: "resource.group" (e.g. "widgets.example.com")
 	// +optional
@@ -93,6 +144,68 @@ type NamespaceMappingSpec struct {
 	// +optional
 	ImmutableResourceConfig *ImmutableResourceConfig `json:"immutableResourceConfig,omitempty"`
 
+	// BackupConfig enables snapshots of destination objects before they are overwritten
+	// +optional
+	BackupConfig *BackupConfig `json:"backupConfig,omitempty"`
+
+	// SanitizationConfig controls which labels, annotations and finalizers are copied to the destination
+	// +optional
+	SanitizationConfig *SanitizationConfig `json:"sanitizationConfig,omitempty"`
+
+	// ImageOverrides rewrite image registries in workload pod templates, e.g. to pull from a mirrored
+	// registry in the destination cluster. Overrides are evaluated in order and the first match wins.
+	// Image pull secrets referenced by synced workloads are synced even when secrets are not listed.
+	// +optional
+	ImageOverrides []ImageOverride `json:"imageOverrides,omitempty"`
+
+	// Inject adds DR-specific labels, annotations, environment variables, node selectors and tolerations
+	// to the destination copies of workloads, so DR copies carry settings such as ENVIRONMENT=dr without
+	// separate manifests. Every rule matching a workload applies, in order, so later rules win.
+	// +optional
+	Inject []InjectRule `json:"inject,omitempty"`
+
+	// KeyFilters limit the keys of ConfigMaps and Secrets replicated to the destination, e.g. to leave
+	// cluster-local cloud credentials out of a Secret. Filters are evaluated in order and the first
+	// filter matching a resource applies; resources no filter matches are replicated whole.
+	// +optional
+	KeyFilters []KeyFilter `json:"keyFilters,omitempty"`
+
+	// PreserveDestinationFields lists fields of destination resources that are kept as they are in the
+	// destination cluster when the resources are updated, so DR-side changes such as webhook-injected
+	// environment variables are not overwritten on every sync. Entries of all kinds matching a resource apply.
+	// +optional
+	PreserveDestinationFields []PreservedFields `json:"preserveDestinationFields,omitempty"`
+
+	// IgnoreFields configures the differences between source and destination resources that do not
+	// update the destination resource, so resources changed by HPAs, webhooks or the destination API
+	// server are not updated on every sync
+	// +optional
+	IgnoreFields *IgnoreFieldsConfig `json:"ignoreFields,omitempty"`
+
+	// NameTransformation renames the destination copies of ConfigMaps, Secrets and Services, e.g. to
+	// prefix all ConfigMaps with "dr-". The references of synced workloads and Ingresses to renamed
+	// resources are rewritten. Transformations are evaluated in order and the first matching a resource
+	// applies.
+	// +optional
+	NameTransformation []NameTransformation `json:"nameTransformation,omitempty"`
+
+	// DependencyConfig syncs the ConfigMaps and Secrets that synced workloads reference but that do not
+	// exist in the source namespace from an allow list of other source namespaces. A reference found in
+	// none of them fails the sync with the DependencyMissing reason.
+	// +optional
+	DependencyConfig *DependencyConfig `json:"dependencyConfig,omitempty"`
+
+	// Limits are guardrails checked against the source namespace before any of its resources is written. A sync
+	// exceeding one fails with the QuotaExceeded condition instead of replicating part of the namespace.
+	// +optional
+	Limits *SyncLimits `json:"limits,omitempty"`
+
+	// QuotaScaling scales the ResourceQuotas and LimitRanges synced to the destination, e.g. to give the DR
+	// namespace half of the production quota. Quotas and limit ranges are synced when resourcequotas or
+	// limitranges are listed in resourceTypes; without QuotaScaling they are copied unscaled.
+	// +optional
+	QuotaScaling *QuotaScalingConfig `json:"quotaScaling,omitempty"`
+
 	// SyncCRDs determines whether to sync Custom Resource Definitions
 	// When true, CRDs will be synced along with other resources
 	// When false (default), CRDs will be skipped
@@ -100,14 +213,72 @@ type NamespaceMappingSpec struct {
 	// +kubebuilder:default=false
 	SyncCRDs *bool `json:"syncCRDs,omitempty"`
 
+	// CleanupPolicy determines what is removed from the destination cluster when the mapping is deleted.
+	// Deletion waits for in-flight PVC data syncs of the mapping to finish before cleaning up.
+	// +kubebuilder:validation:Enum=None;SyncedOnly;All
+	// +kubebuilder:default=None
+	// +optional
+	CleanupPolicy CleanupPolicy `json:"cleanupPolicy,omitempty"`
+
 	// FailureHandling defines how different types of failures are handled
 	// +optional
 	FailureHandling *FailureHandlingConfig `json:"failureHandling,omitempty"`
 
+	// PreserveNodePorts keeps the node ports of NodePort and LoadBalancer services in the destination.
+	// When false (default), node ports are allocated by the destination cluster.
+	// +optional
+	// +kubebuilder:default=false
+	PreserveNodePorts *bool `json:"preserveNodePorts,omitempty"`
+
+	// ConvertLoadBalancerServices creates LoadBalancer services as ClusterIP services in the destination,
+	// so no load balancer is provisioned for the DR copy
+	// +optional
+	// +kubebuilder:default=false
+	ConvertLoadBalancerServices *bool `json:"convertLoadBalancerServices,omitempty"`
+
+	// SelectorlessEndpoints syncs the Endpoints and EndpointSlices of Services without a selector, whose
+	// endpoints are managed by hand rather than by Kubernetes
+	// +optional
+	SelectorlessEndpoints *SelectorlessEndpointsConfig `json:"selectorlessEndpoints,omitempty"`
+
+	// SkipOwnedResources skips resources with a controller ownerReference, such as the children of
+	// operator custom resources. DR clusters running the same operators recreate the children from
+	// the synced custom resources instead of fighting over copies.
+	// +optional
+	// +kubebuilder:default=false
+	SkipOwnedResources *bool `json:"skipOwnedResources,omitempty"`
+
+	// VerifyAfterSync re-reads every synced destination object after each sync and compares it against the
+	// state dr-syncer wrote, catching mutating webhooks or controllers in the DR cluster that silently alter
+	// synced objects. Fields defaulted by the API server are not reported.
+	// +optional
+	// +kubebuilder:default=false
+	VerifyAfterSync *bool `json:"verifyAfterSync,omitempty"`
+
+	// Verification configures smoke tests probing the destination namespace, to check the DR stack
+	// actually serves before DNS is failed over to it
+	// +optional
+	Verification *VerificationConfig `json:"verification,omitempty"`
+
 	// IngressConfig defines configuration for ingress replication
 	// +optional
 	IngressConfig *IngressConfig `json:"ingressConfig,omitempty"`
 
+	// DestinationImpersonation is the user or ServiceAccount impersonated for all writes to the destination
+	// cluster, so RBAC on the destination constrains what the mapping can touch. The credentials of the
+	// destination cluster must be allowed to impersonate it.
+	// +optional
+	DestinationImpersonation *ImpersonationConfig `json:"destinationImpersonation,omitempty"`
+
+	// SourceAnnotations writes the DR state of synced workloads onto the source workloads after each sync,
+	// so dashboards in the source cluster can show replication freshness without querying the DR cluster
+	// +optional
+	SourceAnnotations *SourceAnnotationConfig `json:"sourceAnnotations,omitempty"`
+
+	// Notifications sends sync failures, RPO breaches and cutover events of the mapping to webhooks
+	// +optional
+	Notifications *NotificationConfig `json:"notifications,omitempty"`
+
 	// ClusterMappingRef references a ClusterMapping resource for cluster connectivity
 	// This is the preferred way to specify source and target clusters
 	// +optional
@@ -117,6 +288,11 @@ type NamespaceMappingSpec struct {
 // DeepCopyInto copies NamespaceMappingSpec into out
 func (in *NamespaceMappingSpec) DeepCopyInto(out *NamespaceMappingSpec) {
 	*out = *in
+	if in.AllowAdoptExisting != nil {
+		in, out := &in.AllowAdoptExisting, &out.AllowAdoptExisting
+		*out = new(bool)
+		**out = **in
+	}
 	if in.NamespaceConfig != nil {
 		in, out := &in.NamespaceConfig, &out.NamespaceConfig
 		*out = new(NamespaceConfig)
@@ -137,11 +313,46 @@ func (in *NamespaceMappingSpec) DeepCopyInto(out *NamespaceMappingSpec) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ExcludedResourceTypes != nil {
+		in, out := &in.ExcludedResourceTypes, &out.ExcludedResourceTypes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ResourceSelector != nil {
+		in, out := &in.ResourceSelector, &out.ResourceSelector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Consistency != nil {
+		in, out := &in.Consistency, &out.Consistency
+		*out = new(SyncConsistency)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ScaleToZero != nil {
 		in, out := &in.ScaleToZero, &out.ScaleToZero
 		*out = new(bool)
 		**out = **in
 	}
+	if in.WorkloadOverrides != nil {
+		in, out := &in.WorkloadOverrides, &out.WorkloadOverrides
+		*out = make([]WorkloadOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.NetworkIsolation != nil {
+		in, out := &in.NetworkIsolation, &out.NetworkIsolation
+		*out = new(NetworkIsolationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ServiceBridge != nil {
+		in, out := &in.ServiceBridge, &out.ServiceBridge
+		*out = new(ServiceBridgeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SuspendCronJobs != nil {
+		in, out := &in.SuspendCronJobs, &out.SuspendCronJobs
+		*out = new(bool)
+		**out = **in
+	}
 	if in.NamespaceScopedResources != nil {
 		in, out := &in.NamespaceScopedResources, &out.NamespaceScopedResources
 		*out = make([]string, len(*in))
@@ -157,6 +368,67 @@ func (in *NamespaceMappingSpec) DeepCopyInto(out *NamespaceMappingSpec) {
 		*out = new(ImmutableResourceConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BackupConfig != nil {
+		in, out := &in.BackupConfig, &out.BackupConfig
+		*out = new(BackupConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SanitizationConfig != nil {
+		in, out := &in.SanitizationConfig, &out.SanitizationConfig
+		*out = new(SanitizationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ImageOverrides != nil {
+		in, out := &in.ImageOverrides, &out.ImageOverrides
+		*out = make([]ImageOverride, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inject != nil {
+		in, out := &in.Inject, &out.Inject
+		*out = make([]InjectRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.KeyFilters != nil {
+		in, out := &in.KeyFilters, &out.KeyFilters
+		*out = make([]KeyFilter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.PreserveDestinationFields != nil {
+		in, out := &in.PreserveDestinationFields, &out.PreserveDestinationFields
+		*out = make([]PreservedFields, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IgnoreFields != nil {
+		in, out := &in.IgnoreFields, &out.IgnoreFields
+		*out = new(IgnoreFieldsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NameTransformation != nil {
+		in, out := &in.NameTransformation, &out.NameTransformation
+		*out = make([]NameTransformation, len(*in))
+		copy(*out, *in)
+	}
+	if in.DependencyConfig != nil {
+		in, out := &in.DependencyConfig, &out.DependencyConfig
+		*out = new(DependencyConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Limits != nil {
+		in, out := &in.Limits, &out.Limits
+		*out = new(SyncLimits)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.QuotaScaling != nil {
+		in, out := &in.QuotaScaling, &out.QuotaScaling
+		*out = new(QuotaScalingConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.SyncCRDs != nil {
 		in, out := &in.SyncCRDs, &out.SyncCRDs
 		*out = new(bool)
@@ -167,11 +439,56 @@ func (in *NamespaceMappingSpec) DeepCopyInto(out *NamespaceMappingSpec) {
 		*out = new(FailureHandlingConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.PreserveNodePorts != nil {
+		in, out := &in.PreserveNodePorts, &out.PreserveNodePorts
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ConvertLoadBalancerServices != nil {
+		in, out := &in.ConvertLoadBalancerServices, &out.ConvertLoadBalancerServices
+		*out = new(bool)
+		**out = **in
+	}
+	if in.SelectorlessEndpoints != nil {
+		in, out := &in.SelectorlessEndpoints, &out.SelectorlessEndpoints
+		*out = new(SelectorlessEndpointsConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SkipOwnedResources != nil {
+		in, out := &in.SkipOwnedResources, &out.SkipOwnedResources
+		*out = new(bool)
+		**out = **in
+	}
+	if in.VerifyAfterSync != nil {
+		in, out := &in.VerifyAfterSync, &out.VerifyAfterSync
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(VerificationConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.IngressConfig != nil {
 		in, out := &in.IngressConfig, &out.IngressConfig
 		*out = new(IngressConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DestinationImpersonation != nil {
+		in, out := &in.DestinationImpersonation, &out.DestinationImpersonation
+		*out = new(ImpersonationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SourceAnnotations != nil {
+		in, out := &in.SourceAnnotations, &out.SourceAnnotations
+		*out = new(SourceAnnotationConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Notifications != nil {
+		in, out := &in.Notifications, &out.Notifications
+		*out = new(NotificationConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ClusterMappingRef != nil {
 		in, out := &in.ClusterMappingRef, &out.ClusterMappingRef
 		*out = new(ClusterMappingReference)
@@ -207,10 +524,23 @@ type NamespaceMappingStatus struct {
 	// +optional
 	NextSyncTime *metav1.Time `json:"nextSyncTime,omitempty"`
 
+	// NextSyncTimeLocal is the next scheduled sync time in the schedule's time zone (Scheduled mode only)
+	// +optional
+	NextSyncTimeLocal string `json:"nextSyncTimeLocal,omitempty"`
+
 	// LastWatchEvent is the last time a watch event was processed (Continuous mode only)
 	// +optional
 	LastWatchEvent *metav1.Time `json:"lastWatchEvent,omitempty"`
 
+	// ActiveWatches is the number of source resource types watched for the mapping (Continuous mode only)
+	// +optional
+	ActiveWatches int32 `json:"activeWatches,omitempty"`
+
+	// LastDataChange is the latest source PVC data change replicated by a data watch sync
+	// (Continuous mode with dataWatch only)
+	// +optional
+	LastDataChange *metav1.Time `json:"lastDataChange,omitempty"`
+
 	// SyncProgress tracks the current progress of the sync operation
 	// +optional
 	SyncProgress *SyncProgress `json:"syncProgress,omitempty"`
@@ -243,6 +573,11 @@ type NamespaceMappingStatus struct {
 	// +optional
 	LastError *SyncError `json:"lastError,omitempty"`
 
+	// FailedResources are the resources that failed to sync in the last sync while the others were
+	// synced. Retries before the next scheduled sync only sync these resources.
+	// +optional
+	FailedResources []SyncError `json:"failedResources,omitempty"`
+
 	// Conditions represent the latest available observations of the namespace mapping's state
 	// +optional
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
@@ -250,6 +585,34 @@ type NamespaceMappingStatus struct {
 	// DeploymentScales stores the original scale values of deployments
 	// +optional
 	DeploymentScales []DeploymentScale `json:"deploymentScales,omitempty"`
+
+	// LastManualTrigger records the last sync requested through the dr-syncer.io/sync-now annotation
+	// +optional
+	LastManualTrigger *ManualTrigger `json:"lastManualTrigger,omitempty"`
+
+	// Verification reports how the destination objects compared to the synced state after the last sync,
+	// set when spec.verifyAfterSync is enabled
+	// +optional
+	Verification *VerificationStatus `json:"verification,omitempty"`
+
+	// SmokeTests holds the results of the last run of spec.verification.smokeTests
+	// +optional
+	SmokeTests []SmokeTestResult `json:"smokeTests,omitempty"`
+
+	// LastFailoverTest records the last failover test requested through the dr-syncer.io/failover-test annotation
+	// +optional
+	LastFailoverTest *FailoverTest `json:"lastFailoverTest,omitempty"`
+
+	// SyncGeneration is the sync generation of the last sync that applied a complete source snapshot,
+	// set when spec.consistency.snapshot is enabled. The destination resources of that sync carry it in
+	// the dr-syncer.io/sync-generation label.
+	// +optional
+	SyncGeneration int64 `json:"syncGeneration,omitempty"`
+
+	// SnapshotResourceVersions records the resourceVersion each resource type of the source namespace was
+	// listed at for the snapshot of SyncGeneration, keyed by resource and group such as "deployments.apps"
+	// +optional
+	SnapshotResourceVersions map[string]string `json:"snapshotResourceVersions,omitempty"`
 }
 
 // DeepCopyInto copies NamespaceMappingStatus into out
@@ -267,6 +630,10 @@ func (in *NamespaceMappingStatus) DeepCopyInto(out *NamespaceMappingStatus) {
 		in, out := &in.LastWatchEvent, &out.LastWatchEvent
 		*out = (*in).DeepCopy()
 	}
+	if in.LastDataChange != nil {
+		in, out := &in.LastDataChange, &out.LastDataChange
+		*out = (*in).DeepCopy()
+	}
 	if in.SyncProgress != nil {
 		in, out := &in.SyncProgress, &out.SyncProgress
 		*out = new(SyncProgress)
@@ -313,6 +680,13 @@ func (in *NamespaceMappingStatus) DeepCopyInto(out *NamespaceMappingStatus) {
 		*out = new(SyncError)
 		**out = **in
 	}
+	if in.FailedResources != nil {
+		in, out := &in.FailedResources, &out.FailedResources
+		*out = make([]SyncError, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]metav1.Condition, len(*in))
@@ -327,6 +701,35 @@ func (in *NamespaceMappingStatus) DeepCopyInto(out *NamespaceMappingStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.LastManualTrigger != nil {
+		in, out := &in.LastManualTrigger, &out.LastManualTrigger
+		*out = new(ManualTrigger)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Verification != nil {
+		in, out := &in.Verification, &out.Verification
+		*out = new(VerificationStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SmokeTests != nil {
+		in, out := &in.SmokeTests, &out.SmokeTests
+		*out = make([]SmokeTestResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.LastFailoverTest != nil {
+		in, out := &in.LastFailoverTest, &out.LastFailoverTest
+		*out = new(FailoverTest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SnapshotResourceVersions != nil {
+		in, out := &in.SnapshotResourceVersions, &out.SnapshotResourceVersions
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy creates a deep copy of NamespaceMappingStatus
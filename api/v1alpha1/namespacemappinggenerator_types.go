@@ -0,0 +1,235 @@
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	runtime "k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// NamespaceMappingGeneratorConditionReady reports whether the generated NamespaceMappings match the selected namespaces
+	NamespaceMappingGeneratorConditionReady = "Ready"
+
+	// NamespaceMappingGeneratorLabel is set on generated NamespaceMappings to the name of their NamespaceMappingGenerator
+	NamespaceMappingGeneratorLabel = "dr-syncer.io/generator"
+)
+
+// NamespaceMappingTemplateMetadata holds the labels and annotations of generated NamespaceMappings
+type NamespaceMappingTemplateMetadata struct {
+	// Labels are added to every generated NamespaceMapping
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to every generated NamespaceMapping
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// NamespaceMappingTemplate describes the NamespaceMappings created for the selected namespaces
+type NamespaceMappingTemplate struct {
+	// Metadata holds the labels and annotations of the generated NamespaceMappings
+	// +optional
+	Metadata NamespaceMappingTemplateMetadata `json:"metadata,omitempty"`
+
+	// Spec is the spec of the generated NamespaceMappings. SourceNamespace and DestinationNamespace
+	// are set for each selected namespace.
+	Spec NamespaceMappingSpec `json:"spec"`
+}
+
+// NamespaceMappingGeneratorSpec selects source namespaces and the NamespaceMapping created for each of them
+type NamespaceMappingGeneratorSpec struct {
+	// NamespaceSelector selects the namespaces of the source cluster to replicate by label
+	NamespaceSelector metav1.LabelSelector `json:"namespaceSelector"`
+
+	// DestinationNamespacePrefix is prepended to the source namespace name to name the destination namespace
+	// +optional
+	DestinationNamespacePrefix string `json:"destinationNamespacePrefix,omitempty"`
+
+	// DestinationNamespaceSuffix is appended to the source namespace name to name the destination namespace
+	// +optional
+	DestinationNamespaceSuffix string `json:"destinationNamespaceSuffix,omitempty"`
+
+	// Template is the NamespaceMapping created for each selected namespace
+	Template NamespaceMappingTemplate `json:"template"`
+
+	// ResyncInterval is how often the namespaces of the source cluster are listed again
+	// +optional
+	// +kubebuilder:default="5m"
+	ResyncInterval *metav1.Duration `json:"resyncInterval,omitempty"`
+}
+
+// NamespaceMappingGeneratorStatus reports the NamespaceMappings generated for the selected namespaces
+type NamespaceMappingGeneratorStatus struct {
+	// ObservedGeneration is the generation of the spec the NamespaceMappings were last generated from
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// LastSyncTime is when the NamespaceMappings were last generated
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// MatchedNamespaces is the number of source namespaces selected
+	// +optional
+	MatchedNamespaces int32 `json:"matchedNamespaces,omitempty"`
+
+	// NamespaceMappings lists the generated NamespaceMappings
+	// +optional
+	NamespaceMappings []string `json:"namespaceMappings,omitempty"`
+
+	// Conditions contains the Ready condition
+	// +optional
+	Conditions []metav1.Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.conditions[?(@.type==\"Ready\")].status"
+// +kubebuilder:printcolumn:name="Namespaces",type="integer",JSONPath=".status.matchedNamespaces"
+// +kubebuilder:printcolumn:name="Last Sync",type="date",JSONPath=".status.lastSyncTime"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:resource:shortName=nmg
+type NamespaceMappingGenerator struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   NamespaceMappingGeneratorSpec   `json:"spec,omitempty"`
+	Status NamespaceMappingGeneratorStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+type NamespaceMappingGeneratorList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []NamespaceMappingGenerator `json:"items"`
+}
+
+// DeepCopyInto copies NamespaceMappingTemplateMetadata into out
+func (in *NamespaceMappingTemplateMetadata) DeepCopyInto(out *NamespaceMappingTemplateMetadata) {
+	*out = *in
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopyInto copies NamespaceMappingTemplate into out
+func (in *NamespaceMappingTemplate) DeepCopyInto(out *NamespaceMappingTemplate) {
+	*out = *in
+	in.Metadata.DeepCopyInto(&out.Metadata)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy creates a deep copy of NamespaceMappingTemplate
+func (in *NamespaceMappingTemplate) DeepCopy() *NamespaceMappingTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceMappingTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies NamespaceMappingGeneratorSpec into out
+func (in *NamespaceMappingGeneratorSpec) DeepCopyInto(out *NamespaceMappingGeneratorSpec) {
+	*out = *in
+	in.NamespaceSelector.DeepCopyInto(&out.NamespaceSelector)
+	in.Template.DeepCopyInto(&out.Template)
+	if in.ResyncInterval != nil {
+		in, out := &in.ResyncInterval, &out.ResyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopyInto copies NamespaceMappingGeneratorStatus into out
+func (in *NamespaceMappingGeneratorStatus) DeepCopyInto(out *NamespaceMappingGeneratorStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NamespaceMappings != nil {
+		in, out := &in.NamespaceMappings, &out.NamespaceMappings
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]metav1.Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopyInto copies all properties of NamespaceMappingGenerator into another instance
+func (in *NamespaceMappingGenerator) DeepCopyInto(out *NamespaceMappingGenerator) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy creates a deep copy of NamespaceMappingGenerator
+func (in *NamespaceMappingGenerator) DeepCopy() *NamespaceMappingGenerator {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceMappingGenerator)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object interface
+func (in *NamespaceMappingGenerator) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto copies all properties of NamespaceMappingGeneratorList into another instance
+func (in *NamespaceMappingGeneratorList) DeepCopyInto(out *NamespaceMappingGeneratorList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]NamespaceMappingGenerator, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of NamespaceMappingGeneratorList
+func (in *NamespaceMappingGeneratorList) DeepCopy() *NamespaceMappingGeneratorList {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceMappingGeneratorList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject implements runtime.Object interface
+func (in *NamespaceMappingGeneratorList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+func init() {
+	SchemeBuilder.Register(&NamespaceMappingGenerator{}, &NamespaceMappingGeneratorList{})
+}
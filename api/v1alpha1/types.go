@@ -1,6 +1,8 @@
 package v1alpha1
 
 import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -30,8 +32,10 @@ func (in *StorageClassMapping) DeepCopy() *StorageClassMapping {
 // AccessModeMapping defines a mapping between source and destination access modes
 type AccessModeMapping struct {
 	// From is the source cluster access mode
+	// +kubebuilder:validation:Enum=ReadWriteOnce;ReadOnlyMany;ReadWriteMany;ReadWriteOncePod
 	From string `json:"from"`
 	// To is the destination cluster access mode
+	// +kubebuilder:validation:Enum=ReadWriteOnce;ReadOnlyMany;ReadWriteMany;ReadWriteOncePod
 	To string `json:"to"`
 }
 
@@ -50,6 +54,98 @@ func (in *AccessModeMapping) DeepCopy() *AccessModeMapping {
 	return out
 }
 
+// IngressClassMapping defines a mapping between source and destination ingress classes
+type IngressClassMapping struct {
+	// From is the source cluster ingress class name
+	From string `json:"from"`
+	// To is the destination cluster ingress class name
+	To string `json:"to"`
+}
+
+// DeepCopyInto copies IngressClassMapping into out
+func (in *IngressClassMapping) DeepCopyInto(out *IngressClassMapping) {
+	*out = *in
+}
+
+// DeepCopy creates a deep copy of IngressClassMapping
+func (in *IngressClassMapping) DeepCopy() *IngressClassMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(IngressClassMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// PVCMapping defines a rename rule between source and destination PVC names
+type PVCMapping struct {
+	// From is the source PVC name, or a regular expression matched against the
+	// whole source PVC name when Regex is true
+	From string `json:"from"`
+	// To is the destination PVC name. When Regex is true it may reference
+	// capture groups from From, e.g. "dr-$1"
+	To string `json:"to"`
+	// Regex treats From as a regular expression
+	// +optional
+	Regex bool `json:"regex,omitempty"`
+}
+
+// WorkloadOverride sets the replicas of a workload in the destination cluster
+type WorkloadOverride struct {
+	// Kind is the kind of the workload
+	// +kubebuilder:validation:Enum=Deployment;StatefulSet
+	Kind string `json:"kind"`
+	// Name is the name of the workload in the source namespace
+	Name string `json:"name"`
+	// Replicas is the number of replicas in the destination cluster
+	// +kubebuilder:validation:Minimum=0
+	Replicas int32 `json:"replicas"`
+}
+
+// DeepCopyInto copies WorkloadOverride into out
+func (in *WorkloadOverride) DeepCopyInto(out *WorkloadOverride) {
+	*out = *in
+}
+
+// ImageOverride rewrites image references that start with a registry prefix
+type ImageOverride struct {
+	// From is the prefix replaced, e.g. "registry.prod.local" or "registry.prod.local/team".
+	// It only matches whole path segments, so "registry.prod" does not match "registry.prod.local/app"
+	From string `json:"from"`
+	// To replaces From, e.g. "registry.dr.local"
+	To string `json:"to"`
+}
+
+// DeepCopyInto copies ImageOverride into out
+func (in *ImageOverride) DeepCopyInto(out *ImageOverride) {
+	*out = *in
+}
+
+// DeepCopy creates a deep copy of ImageOverride
+func (in *ImageOverride) DeepCopy() *ImageOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(ImageOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies PVCMapping into out
+func (in *PVCMapping) DeepCopyInto(out *PVCMapping) {
+	*out = *in
+}
+
+// DeepCopy creates a deep copy of PVCMapping
+func (in *PVCMapping) DeepCopy() *PVCMapping {
+	if in == nil {
+		return nil
+	}
+	out := new(PVCMapping)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // PVCConfig defines configuration for PVC replication
 type PVCConfig struct {
 	// SyncPersistentVolumes determines whether to sync PVs when StorageClass supports multi-cluster attachment.
@@ -70,13 +166,22 @@ type PVCConfig struct {
 	// AccessModeMappings defines mappings to convert access modes between clusters.
 	// This allows using different access modes in the destination cluster.
 	// If a mapping is not found, the original access mode will be used.
+	// Each source access mode is translated by the first matching mapping, so modes can be swapped.
+	// New destination PVCs are only created when their StorageClass supports the translated modes.
 	// This can be overridden per-PVC using the 'dr-syncer.io/access-mode' label.
 	// +optional
 	AccessModeMappings []AccessModeMapping `json:"accessModeMappings,omitempty"`
 
-	// PreserveVolumeAttributes determines whether to preserve volume attributes when creating new PVs.
-	// When true, volume attributes like filesystem type, mount options, etc. will be preserved.
-	// When false (default), the storage class defaults will be used.
+	// PVCMappings rename PVCs in the destination cluster. Mappings are evaluated in order
+	// and the first match wins; PVCs without a match keep their source name.
+	// Workload volumes that reference a renamed PVC are rewritten to the destination name.
+	// +optional
+	PVCMappings []PVCMapping `json:"pvcMappings,omitempty"`
+
+	// PreserveVolumeAttributes determines whether new destination PVCs keep their volume attributes: the
+	// volume mode, VolumeAttributesClass, selector, data source, zone and region labels and the annotations
+	// the destination StorageClass reads. PVC data sources are remapped to the destination PVC names.
+	// When false (default), they are stripped and the storage class defaults will be used.
 	// +optional
 	// +kubebuilder:default=false
 	PreserveVolumeAttributes bool `json:"preserveVolumeAttributes,omitempty"`
@@ -92,6 +197,28 @@ type PVCConfig struct {
 	// Only used when SyncData is true.
 	// +optional
 	DataSyncConfig *PVCDataSyncConfig `json:"dataSyncConfig,omitempty"`
+
+	// RecreateOnExpansionFailure determines whether a destination PVC is deleted and recreated
+	// at the new size when the source PVC grew but the destination StorageClass does not allow
+	// volume expansion. The recreated PVC is re-synced when SyncData is true.
+	// When false (default), the destination PVC keeps its size and a warning event is recorded.
+	// +optional
+	// +kubebuilder:default=false
+	RecreateOnExpansionFailure bool `json:"recreateOnExpansionFailure,omitempty"`
+
+	// KeepWarm keeps destination PVCs that no workload mounts attached to warm pool pods between
+	// syncs, so scheduled data syncs with the rsync DaemonSet skip the attach and detach of a
+	// placeholder pod on every run. Warm pool pods are removed when KeepWarm is disabled.
+	// +optional
+	// +kubebuilder:default=false
+	KeepWarm bool `json:"keepWarm,omitempty"`
+
+	// SyncUnmounted syncs the data of source PVCs that no pod mounts by mounting them in a temporary
+	// pod in the source cluster. When false (default), the data sync of unmounted PVCs is skipped
+	// and their sync status reports the NotMounted reason.
+	// +optional
+	// +kubebuilder:default=false
+	SyncUnmounted bool `json:"syncUnmounted,omitempty"`
 }
 
 // VerificationMode defines how data integrity is verified during PVC sync
@@ -123,6 +250,16 @@ type PVCDataSyncConfig struct {
 	// +optional
 	RsyncOptions []string `json:"rsyncOptions,omitempty"`
 
+	// ParallelStreams splits the data sync of a PVC into this many concurrent rsync streams,
+	// each copying a share of the top-level directories of the volume over the same SSH target.
+	// Files at the top level of the volume are copied by an additional stream. A value of 1
+	// keeps the single rsync stream.
+	// +optional
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=32
+	ParallelStreams *int32 `json:"parallelStreams,omitempty"`
+
 	// BandwidthLimit sets a maximum transfer rate in kilobytes per second.
 	// This is passed to rsync as --bwlimit=<value>.
 	// +optional
@@ -149,6 +286,208 @@ type PVCDataSyncConfig struct {
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=100
 	SamplePercent *int32 `json:"samplePercent,omitempty"`
+
+	// SkipUnchanged enables a change-detection pre-check on the agent before each data sync.
+	// When no file under the source mount changed since the last successful sync, the
+	// rsync phase is skipped and the PVC sync status is set to Skipped.
+	// +optional
+	SkipUnchanged bool `json:"skipUnchanged,omitempty"`
+
+	// FullSyncInterval forces a data sync when the last successful one is older than this,
+	// even if no changes were detected. Only used when SkipUnchanged is true.
+	// +optional
+	// +kubebuilder:default="24h"
+	FullSyncInterval *metav1.Duration `json:"fullSyncInterval,omitempty"`
+
+	// StaleLockTimeout is how old the lock of another controller on a source PVC must be before it is
+	// taken over. The rsync deployment and agent-side rsync processes of the stale sync are stopped
+	// before the lock is taken. Defaults to LOCK_TIMEOUT_MINUTES of the controller, 1h when unset.
+	// +optional
+	StaleLockTimeout *metav1.Duration `json:"staleLockTimeout,omitempty"`
+
+	// AutoGrowDestination grows a destination PVC that is smaller than the data used on the source
+	// PVC before the data sync, when its StorageClass allows volume expansion. When false (default),
+	// or when the StorageClass cannot expand volumes, the data sync fails before rsync starts and the
+	// InsufficientSpace condition is set on the NamespaceMapping.
+	// +optional
+	// +kubebuilder:default=false
+	AutoGrowDestination bool `json:"autoGrowDestination,omitempty"`
+
+	// Transport selects how PVC data reaches the destination cluster. Rsync (default) copies the data
+	// over SSH from the source agent. ObjectStorage backs the data up from the source agent into a
+	// restic repository and restores it in the destination cluster, for environments where the
+	// clusters cannot reach each other.
+	// +optional
+	// +kubebuilder:default=Rsync
+	Transport PVCDataTransport `json:"transport,omitempty"`
+
+	// ObjectStorage configures the repository of the ObjectStorage transport
+	// +optional
+	ObjectStorage *ObjectStorageConfig `json:"objectStorage,omitempty"`
+
+	// Strategy selects how the destination rsync pod reaches the source PVC data with the Rsync
+	// transport. Agent (default) connects to the agent on the node mounting the PVC. LbSvc runs a
+	// temporary sshd pod mounting the PVC in the source namespace behind a LoadBalancer Service.
+	// PortForward runs the same sshd pod and tunnels the connection through the controller's
+	// port-forward, for clusters that can only be reached through their API servers.
+	// +optional
+	// +kubebuilder:default=Agent
+	Strategy PVCDataSyncStrategy `json:"strategy,omitempty"`
+
+	// TempFiles selects where rsync writes the files it transfers. Inplace (default) updates the
+	// destination files directly. TempDir writes them to a directory on the destination volume
+	// before moving them into place. Ignored when RsyncOptions set --inplace or --temp-dir.
+	// +optional
+	// +kubebuilder:default=Inplace
+	TempFiles RsyncTempFiles `json:"tempFiles,omitempty"`
+
+	// EphemeralStorage sets the ephemeral-storage request and limit of the destination rsync pods
+	// +optional
+	EphemeralStorage *EphemeralStorageConfig `json:"ephemeralStorage,omitempty"`
+}
+
+// RsyncTempFiles selects where rsync writes the files it transfers
+// +kubebuilder:validation:Enum=Inplace;TempDir
+type RsyncTempFiles string
+
+const (
+	// RsyncTempFilesInplace updates the destination files in place with --inplace
+	RsyncTempFilesInplace RsyncTempFiles = "Inplace"
+
+	// RsyncTempFilesTempDir writes the transferred files to a --temp-dir on the destination volume
+	RsyncTempFilesTempDir RsyncTempFiles = "TempDir"
+)
+
+// GetTempFiles returns where rsync writes the transferred files with default value of Inplace
+func (c *PVCDataSyncConfig) GetTempFiles() RsyncTempFiles {
+	if c == nil || c.TempFiles == "" {
+		return RsyncTempFilesInplace
+	}
+	return c.TempFiles
+}
+
+// EphemeralStorageConfig sets the ephemeral-storage resources of a pod
+type EphemeralStorageConfig struct {
+	// Request is the ephemeral storage requested for the pod
+	// +optional
+	Request *resource.Quantity `json:"request,omitempty"`
+
+	// Limit is the ephemeral storage the pod is evicted above
+	// +optional
+	Limit *resource.Quantity `json:"limit,omitempty"`
+}
+
+// DeepCopyInto copies EphemeralStorageConfig into out
+func (in *EphemeralStorageConfig) DeepCopyInto(out *EphemeralStorageConfig) {
+	*out = *in
+	if in.Request != nil {
+		in, out := &in.Request, &out.Request
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Limit != nil {
+		in, out := &in.Limit, &out.Limit
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy creates a deep copy of EphemeralStorageConfig
+func (in *EphemeralStorageConfig) DeepCopy() *EphemeralStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(EphemeralStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// PVCDataTransport selects how PVC data is transferred between clusters
+// +kubebuilder:validation:Enum=Rsync;ObjectStorage
+type PVCDataTransport string
+
+const (
+	// PVCDataTransportRsync copies PVC data over SSH from the source agent
+	PVCDataTransportRsync PVCDataTransport = "Rsync"
+
+	// PVCDataTransportObjectStorage transfers PVC data through a restic repository in object storage
+	PVCDataTransportObjectStorage PVCDataTransport = "ObjectStorage"
+)
+
+// GetTransport returns the PVC data transport with default value of Rsync
+func (c *PVCDataSyncConfig) GetTransport() PVCDataTransport {
+	if c == nil || c.Transport == "" {
+		return PVCDataTransportRsync
+	}
+	return c.Transport
+}
+
+// PVCDataSyncStrategy selects how the destination rsync pod connects to the source PVC data
+// +kubebuilder:validation:Enum=Agent;LbSvc;PortForward
+type PVCDataSyncStrategy string
+
+const (
+	// PVCDataSyncStrategyAgent connects to the agent on the node mounting the source PVC
+	PVCDataSyncStrategyAgent PVCDataSyncStrategy = "Agent"
+
+	// PVCDataSyncStrategyLbSvc connects to a temporary sshd pod exposed by a LoadBalancer Service in the source namespace
+	PVCDataSyncStrategyLbSvc PVCDataSyncStrategy = "LbSvc"
+
+	// PVCDataSyncStrategyPortForward connects to a temporary sshd pod through the controller's port-forward
+	PVCDataSyncStrategyPortForward PVCDataSyncStrategy = "PortForward"
+)
+
+// GetStrategy returns the PVC data sync strategy with default value of Agent
+func (c *PVCDataSyncConfig) GetStrategy() PVCDataSyncStrategy {
+	if c == nil || c.Strategy == "" {
+		return PVCDataSyncStrategyAgent
+	}
+	return c.Strategy
+}
+
+// ObjectStorageConfig configures the restic repository PVC data is transferred through
+type ObjectStorageConfig struct {
+	// Repository is the restic repository, such as s3:s3.amazonaws.com/dr-bucket/dr-syncer.
+	// It is initialized on the first sync.
+	// +kubebuilder:validation:MinLength=1
+	Repository string `json:"repository"`
+
+	// CredentialsSecretRef references a secret in the NamespaceMapping's namespace. Every key of the
+	// secret is passed to restic as an environment variable, so it must hold RESTIC_PASSWORD and the
+	// credentials of the repository backend, such as AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY.
+	CredentialsSecretRef ObjectStorageSecretRef `json:"credentialsSecretRef"`
+
+	// KeepSnapshots is the number of snapshots kept in the repository for each PVC
+	// +optional
+	// +kubebuilder:default=3
+	// +kubebuilder:validation:Minimum=1
+	KeepSnapshots *int32 `json:"keepSnapshots,omitempty"`
+}
+
+// DeepCopyInto copies ObjectStorageConfig into out
+func (in *ObjectStorageConfig) DeepCopyInto(out *ObjectStorageConfig) {
+	*out = *in
+	if in.KeepSnapshots != nil {
+		in, out := &in.KeepSnapshots, &out.KeepSnapshots
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy creates a deep copy of ObjectStorageConfig
+func (in *ObjectStorageConfig) DeepCopy() *ObjectStorageConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ObjectStorageConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// ObjectStorageSecretRef references the secret holding the credentials of an object storage repository
+type ObjectStorageSecretRef struct {
+	// Name is the name of the secret
+	Name string `json:"name"`
 }
 
 // DeepCopyInto copies PVCDataSyncConfig into out
@@ -169,6 +508,11 @@ func (in *PVCDataSyncConfig) DeepCopyInto(out *PVCDataSyncConfig) {
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.ParallelStreams != nil {
+		in, out := &in.ParallelStreams, &out.ParallelStreams
+		*out = new(int32)
+		**out = **in
+	}
 	if in.BandwidthLimit != nil {
 		in, out := &in.BandwidthLimit, &out.BandwidthLimit
 		*out = new(int32)
@@ -184,6 +528,26 @@ func (in *PVCDataSyncConfig) DeepCopyInto(out *PVCDataSyncConfig) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.FullSyncInterval != nil {
+		in, out := &in.FullSyncInterval, &out.FullSyncInterval
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.StaleLockTimeout != nil {
+		in, out := &in.StaleLockTimeout, &out.StaleLockTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ObjectStorage != nil {
+		in, out := &in.ObjectStorage, &out.ObjectStorage
+		*out = new(ObjectStorageConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.EphemeralStorage != nil {
+		in, out := &in.EphemeralStorage, &out.EphemeralStorage
+		*out = new(EphemeralStorageConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy creates a deep copy of PVCDataSyncConfig
@@ -209,6 +573,11 @@ func (in *PVCConfig) DeepCopyInto(out *PVCConfig) {
 		*out = make([]AccessModeMapping, len(*in))
 		copy(*out, *in)
 	}
+	if in.PVCMappings != nil {
+		in, out := &in.PVCMappings, &out.PVCMappings
+		*out = make([]PVCMapping, len(*in))
+		copy(*out, *in)
+	}
 	if in.DataSyncConfig != nil {
 		in, out := &in.DataSyncConfig, &out.DataSyncConfig
 		*out = new(PVCDataSyncConfig)
@@ -266,94 +635,441 @@ type ImmutableResourceConfig struct {
 	ForceDeleteTimeout *metav1.Duration `json:"forceDeleteTimeout,omitempty"`
 }
 
-// ReplicationMode defines the type of replication
-type ReplicationMode string
+// BackupConfig defines how destination objects are backed up before they are overwritten
+type BackupConfig struct {
+	// Enabled snapshots the current destination object before each update
+	// +optional
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
 
-const (
-	// ScheduledMode uses cron schedule for replication
-	ScheduledMode ReplicationMode = "Scheduled"
-	// ContinuousMode uses watchers and background sync
-	ContinuousMode ReplicationMode = "Continuous"
-	// ManualMode requires manual trigger via CRD updates
-	ManualMode ReplicationMode = "Manual"
-)
+	// Retention is the number of prior versions kept per object
+	// +optional
+	// +kubebuilder:default=5
+	// +kubebuilder:validation:Minimum=1
+	Retention *int32 `json:"retention,omitempty"`
 
-// ContinuousConfig defines configuration for continuous replication mode
-type ContinuousConfig struct {
-	// WatchResources enables real-time resource watching
+	// ArchiveNamespace is the destination namespace backups are stored in
+	// Defaults to the destination namespace of the synced object
 	// +optional
-	// +kubebuilder:default=true
-	WatchResources *bool `json:"watchResources,omitempty"`
+	ArchiveNamespace string `json:"archiveNamespace,omitempty"`
+}
 
-	// BackgroundSyncInterval defines the interval for full sync
+// SanitizationConfig controls which labels, annotations and finalizers are carried over to the
+// destination. Patterns match a key exactly, or by prefix when they end in "*". When a key matches
+// both a strip and a preserve pattern, the more specific pattern wins.
+type SanitizationConfig struct {
+	// Annotations filters annotations. kubectl.kubernetes.io/last-applied-configuration is stripped by default.
 	// +optional
-	// +kubebuilder:default="1h"
-	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
-	BackgroundSyncInterval string `json:"backgroundSyncInterval,omitempty"`
+	Annotations *MetadataFilter `json:"annotations,omitempty"`
+
+	// Labels filters labels. No labels are stripped by default.
+	// +optional
+	Labels *MetadataFilter `json:"labels,omitempty"`
+
+	// Finalizers filters finalizers. All finalizers are stripped by default.
+	// +optional
+	Finalizers *MetadataFilter `json:"finalizers,omitempty"`
 }
 
-// RetryConfig defines configuration for retry behavior
-type RetryConfig struct {
-	// MaxRetries is the maximum number of retries before giving up
+// DependencyConfig resolves the ConfigMaps and Secrets referenced by synced workloads that do not exist
+// in the source namespace, such as certificates shared from another namespace
+type DependencyConfig struct {
+	// Namespaces are the source namespaces searched in order for a referenced ConfigMap or Secret
+	// missing from the source namespace. The first match is synced into the destination namespace.
+	// +kubebuilder:validation:MinItems=1
+	Namespaces []string `json:"namespaces"`
+}
+
+// SyncLimits caps what a NamespaceMapping may replicate
+type SyncLimits struct {
+	// MaxObjectsPerKind is the largest number of source objects of a single resource type
 	// +optional
-	// +kubebuilder:default=5
-	MaxRetries *int32 `json:"maxRetries,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxObjectsPerKind *int32 `json:"maxObjectsPerKind,omitempty"`
 
-	// InitialBackoff is the initial backoff duration after first failure
+	// MaxTotalPVCSizeGi is the largest total requested storage of the source PVCs, in GiB
 	// +optional
-	// +kubebuilder:default="5s"
-	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
-	InitialBackoff string `json:"initialBackoff,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxTotalPVCSizeGi *int64 `json:"maxTotalPVCSizeGi,omitempty"`
 
-	// MaxBackoff is the maximum backoff duration
+	// MaxSecretSizeKi is the largest data size of a single source Secret, in KiB
 	// +optional
-	// +kubebuilder:default="5m"
-	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
-	MaxBackoff string `json:"maxBackoff,omitempty"`
+	// +kubebuilder:validation:Minimum=1
+	MaxSecretSizeKi *int64 `json:"maxSecretSizeKi,omitempty"`
+}
 
-	// BackoffMultiplier is the multiplier for backoff duration after each failure (as percentage)
+// QuotaScalingConfig scales the quantities of ResourceQuotas and LimitRanges synced to the destination
+type QuotaScalingConfig struct {
+	// Percent is the share of the source quantities set in the destination, e.g. 50 for half of the
+	// production quota. Scaled quantities are rounded up, object counts to whole objects.
 	// +optional
-	// +kubebuilder:default=200
-	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:default=100
+	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:validation:Maximum=1000
-	BackoffMultiplier *int32 `json:"backoffMultiplier,omitempty"`
+	Percent int32 `json:"percent,omitempty"`
+
+	// UnscaledResources lists resource names copied as they are, e.g. "pods" or "count/secrets"
+	// +optional
+	UnscaledResources []string `json:"unscaledResources,omitempty"`
 }
 
-// DeepCopyInto copies RetryConfig into out
-func (in *RetryConfig) DeepCopyInto(out *RetryConfig) {
-	*out = *in
-	if in.MaxRetries != nil {
-		in, out := &in.MaxRetries, &out.MaxRetries
-		*out = new(int32)
-		**out = **in
-	}
-	if in.BackoffMultiplier != nil {
-		in, out := &in.BackoffMultiplier, &out.BackoffMultiplier
-		*out = new(int32)
-		**out = **in
-	}
+// SourceAnnotationConfig configures the DR state annotations written onto source workloads after a sync
+type SourceAnnotationConfig struct {
+	// Enabled writes the dr-syncer.io/dr-synced-at and dr-syncer.io/dr-revision annotations onto the
+	// source workloads synced successfully. The source cluster credentials must be allowed to patch them.
+	// +optional
+	// +kubebuilder:default=false
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// Kinds lists the workload kinds annotated (default: Deployment and StatefulSet)
+	// +optional
+	// +kubebuilder:validation:items:Enum=Deployment;StatefulSet;DaemonSet
+	Kinds []string `json:"kinds,omitempty"`
 }
 
-// DeepCopy creates a deep copy of RetryConfig
-func (in *RetryConfig) DeepCopy() *RetryConfig {
-	if in == nil {
-		return nil
-	}
-	out := new(RetryConfig)
-	in.DeepCopyInto(out)
-	return out
+// NetworkIsolationConfig configures the NetworkPolicies isolating a standby destination namespace
+type NetworkIsolationConfig struct {
+	// Enabled creates a default-deny NetworkPolicy for all ingress and egress of the destination namespace
+	// while scaleToZero keeps it a standby, and a policy letting the dr-syncer data sync pods reach the
+	// source cluster. Both are removed once scaleToZero is disabled to activate the namespace.
+	// +optional
+	// +kubebuilder:default=false
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// ExemptPods selects the pods of the destination namespace kept reachable and allowed all egress
+	// while it is isolated, such as a database operator kept warm with workloadOverrides
+	// +optional
+	ExemptPods *metav1.LabelSelector `json:"exemptPods,omitempty"`
 }
 
-// FailureHandlingMode defines how to handle different types of failures
-// +kubebuilder:validation:Enum=RetryAndWait;RetryOnly;WaitForNextSync;FailFast
-type FailureHandlingMode string
+// ServiceBridgeMode selects how a bridge Service reaches the source endpoints
+type ServiceBridgeMode string
 
 const (
-	// RetryAndWait retries with backoff, then waits for next scheduled sync
-	RetryAndWait FailureHandlingMode = "RetryAndWait"
-	// RetryOnly retries with backoff until success or max retries
-	RetryOnly FailureHandlingMode = "RetryOnly"
-	// WaitForNextSync skips retries and waits for next scheduled sync
+	// ServiceBridgeModeEndpointSlice bridges with a selectorless Service and an EndpointSlice listing
+	// the source addresses
+	ServiceBridgeModeEndpointSlice ServiceBridgeMode = "EndpointSlice"
+
+	// ServiceBridgeModeExternalName bridges with an ExternalName Service resolving to the LoadBalancer
+	// hostname of the source Service
+	ServiceBridgeModeExternalName ServiceBridgeMode = "ExternalName"
+)
+
+// ServiceBridgeAddresses selects the source addresses an EndpointSlice bridge points at
+type ServiceBridgeAddresses string
+
+const (
+	// ServiceBridgeAddressesLoadBalancer points at the LoadBalancer ingress IPs of the source Service
+	ServiceBridgeAddressesLoadBalancer ServiceBridgeAddresses = "LoadBalancer"
+
+	// ServiceBridgeAddressesPod points at the ready pod IPs of the source Service, for clusters sharing
+	// a flat pod network
+	ServiceBridgeAddressesPod ServiceBridgeAddresses = "Pod"
+)
+
+// ServiceBridgeConfig configures the destination Services bridged to their source endpoints
+type ServiceBridgeConfig struct {
+	// Services lists the names of the source Services to bridge
+	// +optional
+	Services []string `json:"services,omitempty"`
+
+	// Selector selects the source Services to bridge by label, in addition to those listed in Services
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Mode selects how the bridge reaches the source endpoints
+	// +optional
+	// +kubebuilder:validation:Enum=EndpointSlice;ExternalName
+	// +kubebuilder:default=EndpointSlice
+	Mode ServiceBridgeMode `json:"mode,omitempty"`
+
+	// Addresses selects the source addresses of EndpointSlice bridges
+	// +optional
+	// +kubebuilder:validation:Enum=LoadBalancer;Pod
+	// +kubebuilder:default=LoadBalancer
+	Addresses ServiceBridgeAddresses `json:"addresses,omitempty"`
+}
+
+// SelectorlessEndpointsConfig syncs the manually managed endpoints of Services without a selector, such
+// as a Service pointing at a database outside the cluster
+type SelectorlessEndpointsConfig struct {
+	// Enabled syncs the Endpoints and EndpointSlices of the synced Services without a selector
+	// +optional
+	// +kubebuilder:default=false
+	Enabled *bool `json:"enabled,omitempty"`
+
+	// AddressRewrites replace endpoint addresses in the destination, such as the address of the DR
+	// replica of an external database. The first rule matching an address applies.
+	// +optional
+	AddressRewrites []AddressRewrite `json:"addressRewrites,omitempty"`
+}
+
+// AddressRewrite replaces an endpoint IP address, or the addresses of a network
+type AddressRewrite struct {
+	// From is the source IP address or CIDR
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+
+	// To is the destination IP address, or a CIDR of the same size as the CIDR in From keeping the host
+	// part of the rewritten addresses
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
+}
+
+// InjectRule adds DR-specific settings to the destination copies of workloads, such as an
+// ENVIRONMENT=dr variable, monitoring tags or the scheduling constraints of the DR cluster
+type InjectRule struct {
+	// Kinds limits the rule to workloads of these kinds, all workload kinds when empty
+	// +optional
+	Kinds []InjectKind `json:"kinds,omitempty"`
+
+	// Selector limits the rule to workloads whose source labels match
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// Labels are added to the workload and its pod template, replacing source labels of the same key.
+	// Selectors are not changed, so existing pods keep matching.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations are added to the workload and its pod template, replacing source annotations of the same key
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// Env sets environment variables in every container and init container, replacing source variables
+	// of the same name
+	// +optional
+	Env []EnvVar `json:"env,omitempty"`
+
+	// NodeSelector is added to the node selector of the pod template, replacing source entries of the same key
+	// +optional
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+
+	// Tolerations are added to the tolerations of the pod template
+	// +optional
+	Tolerations []corev1.Toleration `json:"tolerations,omitempty"`
+}
+
+// InjectKind is a workload kind inject rules apply to
+// +kubebuilder:validation:Enum=Deployment;StatefulSet;DaemonSet;Job;CronJob
+type InjectKind string
+
+// SyncConsistency configures how consistent the namespace state applied by a sync is
+type SyncConsistency struct {
+	// Snapshot lists every synced resource type of the source namespace before anything is written, so
+	// a deploy running in the source during the sync cannot leave a mix of old and new resources in the
+	// destination. The listed state is applied as one sync generation: the destination resources are
+	// labeled dr-syncer.io/sync-generation and the generation is recorded in the status once complete.
+	// +optional
+	// +kubebuilder:default=false
+	Snapshot *bool `json:"snapshot,omitempty"`
+}
+
+type MetadataFilter struct {
+	// Strip lists keys removed in the destination
+	// +optional
+	Strip []string `json:"strip,omitempty"`
+
+	// Preserve lists keys kept in the destination, overriding the defaults and less specific strip patterns
+	// +optional
+	Preserve []string `json:"preserve,omitempty"`
+}
+
+// KeyFilter selects the keys of ConfigMaps or Secrets replicated to the destination. Patterns are
+// regular expressions matched against the whole name or key.
+type KeyFilter struct {
+	// Kind is the kind of resource filtered
+	// +kubebuilder:validation:Enum=ConfigMap;Secret
+	Kind string `json:"kind"`
+
+	// Name matches the names of the resources filtered, all resources of Kind when empty
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Include lists the keys replicated, all keys when empty
+	// +optional
+	Include []string `json:"include,omitempty"`
+
+	// Exclude lists keys not replicated even when included. Excluded keys already present in the
+	// destination resource are kept.
+	// +optional
+	Exclude []string `json:"exclude,omitempty"`
+}
+
+// PreservedFields lists fields of destination resources of a kind that are owned by the destination
+// cluster, such as environment variables injected by a mutating webhook in the DR cluster. The values
+// of the fields in the existing destination resource replace the source values on every update.
+type PreservedFields struct {
+	// Kind is the kind of resource, such as Deployment
+	Kind string `json:"kind"`
+
+	// Name is a regular expression matching the whole names of the resources, all resources of Kind
+	// when empty
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Paths are JSONPath expressions of the preserved fields, such as
+	// .spec.template.spec.containers[?(@.name=="app")].env or .metadata.annotations['example.com/region'].
+	// Fields are addressed with .field or ['field'], list items with [index] or [?(@.field=="value")].
+	// A field missing from the destination resource keeps its source value.
+	// +kubebuilder:validation:MinItems=1
+	Paths []string `json:"paths"`
+}
+
+// NameTransformation renames the destination copies of resources of a kind. Pattern is replaced first,
+// then Prefix and Suffix are added.
+type NameTransformation struct {
+	// Kind is the kind of resource renamed. Workloads keep their names, PVCs are renamed with
+	// pvcConfig.pvcMappings.
+	// +kubebuilder:validation:Enum=ConfigMap;Secret;Service
+	Kind string `json:"kind"`
+
+	// Name is a regular expression matching the whole names of the resources renamed, all resources
+	// of Kind when empty
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Prefix is added to the names
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Suffix is added to the names
+	// +optional
+	Suffix string `json:"suffix,omitempty"`
+
+	// Pattern is a regular expression replaced with Replacement in the names
+	// +optional
+	Pattern string `json:"pattern,omitempty"`
+
+	// Replacement replaces the matches of Pattern and can reference its groups, such as ${1}
+	// +optional
+	Replacement string `json:"replacement,omitempty"`
+}
+
+type ReplicationMode string
+
+const (
+	// ScheduledMode uses cron schedule for replication
+	ScheduledMode ReplicationMode = "Scheduled"
+	// ContinuousMode uses watchers and background sync
+	ContinuousMode ReplicationMode = "Continuous"
+	// ManualMode requires manual trigger via CRD updates
+	ManualMode ReplicationMode = "Manual"
+)
+
+// CleanupPolicy determines what is removed from the destination cluster when a NamespaceMapping is deleted
+type CleanupPolicy string
+
+const (
+	// CleanupPolicyNone leaves all destination resources in place
+	CleanupPolicyNone CleanupPolicy = "None"
+	// CleanupPolicySyncedOnly removes the destination resources labeled as synced by the mapping
+	CleanupPolicySyncedOnly CleanupPolicy = "SyncedOnly"
+	// CleanupPolicyAll removes every resource of the synced resource types from the destination namespace
+	CleanupPolicyAll CleanupPolicy = "All"
+)
+
+// ContinuousConfig defines configuration for continuous replication mode
+type ContinuousConfig struct {
+	// WatchResources enables real-time resource watching
+	// +optional
+	// +kubebuilder:default=true
+	WatchResources *bool `json:"watchResources,omitempty"`
+
+	// BackgroundSyncInterval defines the interval for full sync
+	// +optional
+	// +kubebuilder:default="1h"
+	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
+	BackgroundSyncInterval string `json:"backgroundSyncInterval,omitempty"`
+
+	// UseInformerCache reads source resources of the watched types from the watch informer caches
+	// instead of listing them from the source cluster on every sync
+	// +optional
+	// +kubebuilder:default=false
+	UseInformerCache *bool `json:"useInformerCache,omitempty"`
+
+	// DataWatch replicates PVC data shortly after it changes: the agents watch the mounts of the source
+	// PVCs for file changes and the changed PVCs are synced without waiting for the background sync
+	// +optional
+	DataWatch *DataWatchConfig `json:"dataWatch,omitempty"`
+}
+
+// DataWatchConfig defines near-real-time replication of PVC data in continuous mode
+type DataWatchConfig struct {
+	// Enabled has the agents watch the data of the source PVCs for file changes
+	// +optional
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
+
+	// MinInterval is the minimum interval between two data syncs triggered by file changes
+	// +optional
+	// +kubebuilder:default="5m"
+	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
+	MinInterval string `json:"minInterval,omitempty"`
+}
+
+// RetryConfig defines configuration for retry behavior
+type RetryConfig struct {
+	// MaxRetries is the maximum number of retries before giving up
+	// +optional
+	// +kubebuilder:default=5
+	MaxRetries *int32 `json:"maxRetries,omitempty"`
+
+	// InitialBackoff is the initial backoff duration after first failure
+	// +optional
+	// +kubebuilder:default="5s"
+	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
+	InitialBackoff string `json:"initialBackoff,omitempty"`
+
+	// MaxBackoff is the maximum backoff duration
+	// +optional
+	// +kubebuilder:default="5m"
+	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
+	MaxBackoff string `json:"maxBackoff,omitempty"`
+
+	// BackoffMultiplier is the multiplier for backoff duration after each failure (as percentage)
+	// +optional
+	// +kubebuilder:default=200
+	// +kubebuilder:validation:Minimum=100
+	// +kubebuilder:validation:Maximum=1000
+	BackoffMultiplier *int32 `json:"backoffMultiplier,omitempty"`
+}
+
+// DeepCopyInto copies RetryConfig into out
+func (in *RetryConfig) DeepCopyInto(out *RetryConfig) {
+	*out = *in
+	if in.MaxRetries != nil {
+		in, out := &in.MaxRetries, &out.MaxRetries
+		*out = new(int32)
+		**out = **in
+	}
+	if in.BackoffMultiplier != nil {
+		in, out := &in.BackoffMultiplier, &out.BackoffMultiplier
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy creates a deep copy of RetryConfig
+func (in *RetryConfig) DeepCopy() *RetryConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(RetryConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// FailureHandlingMode defines how to handle different types of failures
+// +kubebuilder:validation:Enum=RetryAndWait;RetryOnly;WaitForNextSync;FailFast
+type FailureHandlingMode string
+
+const (
+	// RetryAndWait retries with backoff, then waits for next scheduled sync
+	RetryAndWait FailureHandlingMode = "RetryAndWait"
+	// RetryOnly retries with backoff until success or max retries
+	RetryOnly FailureHandlingMode = "RetryOnly"
+	// WaitForNextSync skips retries and waits for next scheduled sync
 	WaitForNextSync FailureHandlingMode = "WaitForNextSync"
 	// FailFast fails immediately without retrying
 	FailFast FailureHandlingMode = "FailFast"
@@ -418,6 +1134,12 @@ type IngressConfig struct {
 	// +optional
 	// +kubebuilder:default=true
 	PreserveBackends *bool `json:"preserveBackends,omitempty"`
+
+	// IngressClassMappings convert the ingress class of Ingresses between clusters, both
+	// spec.ingressClassName and the legacy kubernetes.io/ingress.class annotation.
+	// If a mapping is not found, the original ingress class will be used.
+	// +optional
+	IngressClassMappings []IngressClassMapping `json:"ingressClassMappings,omitempty"`
 }
 
 // DeepCopyInto copies IngressConfig into out
@@ -438,6 +1160,11 @@ func (in *IngressConfig) DeepCopyInto(out *IngressConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.IngressClassMappings != nil {
+		in, out := &in.IngressClassMappings, &out.IngressClassMappings
+		*out = make([]IngressClassMapping, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy creates a deep copy of IngressConfig
@@ -450,121 +1177,747 @@ func (in *IngressConfig) DeepCopy() *IngressConfig {
 	return out
 }
 
-// NamespaceConfig defines configuration for namespace handling
-type NamespaceConfig struct {
-	// CreateNamespace determines whether to create destination namespace if it doesn't exist
+// ImpersonationConfig identifies the user or ServiceAccount impersonated for writes to a cluster.
+// Exactly one of User and ServiceAccount must be set.
+type ImpersonationConfig struct {
+	// User is the user name to impersonate
 	// +optional
-	// +kubebuilder:default=true
-	CreateNamespace bool `json:"createNamespace,omitempty"`
+	User string `json:"user,omitempty"`
 
-	// PreserveLabels determines whether to maintain namespace labels
+	// Groups are the groups to impersonate with User
 	// +optional
-	// +kubebuilder:default=true
-	PreserveLabels bool `json:"preserveLabels,omitempty"`
+	Groups []string `json:"groups,omitempty"`
 
-	// PreserveAnnotations determines whether to maintain namespace annotations
+	// ServiceAccount is the ServiceAccount to impersonate
 	// +optional
-	// +kubebuilder:default=true
-	PreserveAnnotations bool `json:"preserveAnnotations,omitempty"`
+	ServiceAccount *ServiceAccountReference `json:"serviceAccount,omitempty"`
 }
 
-// DeepCopyInto copies NamespaceConfig into out
-func (in *NamespaceConfig) DeepCopyInto(out *NamespaceConfig) {
+// DeepCopyInto copies ImpersonationConfig into out
+func (in *ImpersonationConfig) DeepCopyInto(out *ImpersonationConfig) {
 	*out = *in
+	if in.Groups != nil {
+		in, out := &in.Groups, &out.Groups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(ServiceAccountReference)
+		**out = **in
+	}
 }
 
-// DeepCopy creates a deep copy of NamespaceConfig
-func (in *NamespaceConfig) DeepCopy() *NamespaceConfig {
+// DeepCopy creates a deep copy of ImpersonationConfig
+func (in *ImpersonationConfig) DeepCopy() *ImpersonationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImpersonationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// ServiceAccountReference references a ServiceAccount
+type ServiceAccountReference struct {
+	// Name is the name of the ServiceAccount
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ServiceAccount, defaults to the destination namespace
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// NotificationEvent is a DR event sent to notification webhooks
+// +kubebuilder:validation:Enum=SyncFailed;RPOBreached;CutoverStarted;CutoverCompleted
+type NotificationEvent string
+
+const (
+	// NotificationEventSyncFailed is sent when a sync of the mapping starts failing
+	NotificationEventSyncFailed NotificationEvent = "SyncFailed"
+	// NotificationEventRPOBreached is sent when at least one scheduled sync of the mapping was missed
+	NotificationEventRPOBreached NotificationEvent = "RPOBreached"
+	// NotificationEventCutoverStarted is sent when a cutover begins
+	NotificationEventCutoverStarted NotificationEvent = "CutoverStarted"
+	// NotificationEventCutoverCompleted is sent when a cutover finished successfully
+	NotificationEventCutoverCompleted NotificationEvent = "CutoverCompleted"
+)
+
+// NotificationFormat is the payload format of a notification webhook
+type NotificationFormat string
+
+const (
+	// NotificationFormatGeneric posts the notification as JSON
+	NotificationFormatGeneric NotificationFormat = "Generic"
+	// NotificationFormatSlack posts a Slack incoming webhook message
+	NotificationFormatSlack NotificationFormat = "Slack"
+	// NotificationFormatTeams posts a Microsoft Teams incoming webhook message card
+	NotificationFormatTeams NotificationFormat = "Teams"
+)
+
+// NotificationConfig sends DR events of a mapping to webhooks, in addition to the controller-wide webhook
+type NotificationConfig struct {
+	// Webhooks receive the events of the mapping
+	// +optional
+	Webhooks []NotificationWebhook `json:"webhooks,omitempty"`
+}
+
+// DeepCopyInto copies NotificationConfig into out
+func (in *NotificationConfig) DeepCopyInto(out *NotificationConfig) {
+	*out = *in
+	if in.Webhooks != nil {
+		in, out := &in.Webhooks, &out.Webhooks
+		*out = make([]NotificationWebhook, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of NotificationConfig
+func (in *NotificationConfig) DeepCopy() *NotificationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// NotificationWebhook is a webhook receiving DR events. Exactly one of URL and URLSecretRef must be set.
+type NotificationWebhook struct {
+	// URL of the webhook
+	// +optional
+	URL string `json:"url,omitempty"`
+
+	// URLSecretRef references a secret in the mapping's namespace holding the webhook URL,
+	// for URLs embedding credentials such as Slack incoming webhooks
+	// +optional
+	URLSecretRef *WebhookURLSecretRef `json:"urlSecretRef,omitempty"`
+
+	// Format is the payload format of the webhook
+	// +optional
+	// +kubebuilder:validation:Enum=Generic;Slack;Teams
+	// +kubebuilder:default=Generic
+	Format NotificationFormat `json:"format,omitempty"`
+
+	// Events filters the events sent to the webhook, all events are sent when empty
+	// +optional
+	Events []NotificationEvent `json:"events,omitempty"`
+}
+
+// DeepCopyInto copies NotificationWebhook into out
+func (in *NotificationWebhook) DeepCopyInto(out *NotificationWebhook) {
+	*out = *in
+	if in.URLSecretRef != nil {
+		in, out := &in.URLSecretRef, &out.URLSecretRef
+		*out = new(WebhookURLSecretRef)
+		**out = **in
+	}
+	if in.Events != nil {
+		in, out := &in.Events, &out.Events
+		*out = make([]NotificationEvent, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy creates a deep copy of NotificationWebhook
+func (in *NotificationWebhook) DeepCopy() *NotificationWebhook {
+	if in == nil {
+		return nil
+	}
+	out := new(NotificationWebhook)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// WebhookURLSecretRef references the secret holding a notification webhook URL
+type WebhookURLSecretRef struct {
+	// Name is the name of the secret
+	Name string `json:"name"`
+
+	// Key is the key in the secret containing the URL
+	// +optional
+	// +kubebuilder:default=url
+	Key string `json:"key,omitempty"`
+}
+
+// NamespaceConfig defines configuration for namespace handling
+type NamespaceConfig struct {
+	// CreateNamespace determines whether to create destination namespace if it doesn't exist
+	// +optional
+	// +kubebuilder:default=true
+	CreateNamespace bool `json:"createNamespace,omitempty"`
+
+	// PreserveLabels determines whether to maintain namespace labels
+	// +optional
+	// +kubebuilder:default=true
+	PreserveLabels bool `json:"preserveLabels,omitempty"`
+
+	// PreserveAnnotations determines whether to maintain namespace annotations
+	// +optional
+	// +kubebuilder:default=true
+	PreserveAnnotations bool `json:"preserveAnnotations,omitempty"`
+}
+
+// DeepCopyInto copies NamespaceConfig into out
+func (in *NamespaceConfig) DeepCopyInto(out *NamespaceConfig) {
+	*out = *in
+}
+
+// DeepCopy creates a deep copy of NamespaceConfig
+func (in *NamespaceConfig) DeepCopy() *NamespaceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// SecretReference references a secret
+type SecretReference struct {
+	// Name is the name of the secret
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the secret
+	Namespace string `json:"namespace"`
+}
+
+// ClusterMappingReference references a ClusterMapping resource
+type ClusterMappingReference struct {
+	// Name is the name of the ClusterMapping
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the ClusterMapping. Defaults to the namespace of the
+	// NamespaceMapping. A ClusterMapping in another namespace must list the NamespaceMapping's
+	// namespace in its dr-syncer.io/allowed-namespaces annotation.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// DeepCopyInto copies ClusterMappingReference into out
+func (in *ClusterMappingReference) DeepCopyInto(out *ClusterMappingReference) {
+	*out = *in
+}
+
+// DeepCopy creates a deep copy of ClusterMappingReference
+func (in *ClusterMappingReference) DeepCopy() *ClusterMappingReference {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterMappingReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies SecretReference into out
+func (in *SecretReference) DeepCopyInto(out *SecretReference) {
+	*out = *in
+}
+
+// DeepCopy creates a deep copy of SecretReference
+func (in *SecretReference) DeepCopy() *SecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies ImmutableResourceConfig into out
+func (in *ImmutableResourceConfig) DeepCopyInto(out *ImmutableResourceConfig) {
+	*out = *in
+	if in.ResourceOverrides != nil {
+		in, out := &in.ResourceOverrides, &out.ResourceOverrides
+		*out = make(map[string]ImmutableResourceHandling, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.DrainTimeout != nil {
+		in, out := &in.DrainTimeout, &out.DrainTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+	if in.ForceDeleteTimeout != nil {
+		in, out := &in.ForceDeleteTimeout, &out.ForceDeleteTimeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy creates a deep copy of ImmutableResourceConfig
+func (in *ImmutableResourceConfig) DeepCopy() *ImmutableResourceConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ImmutableResourceConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies BackupConfig into out
+func (in *BackupConfig) DeepCopyInto(out *BackupConfig) {
+	*out = *in
+	if in.Retention != nil {
+		in, out := &in.Retention, &out.Retention
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy creates a deep copy of BackupConfig
+func (in *BackupConfig) DeepCopy() *BackupConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies SanitizationConfig into out
+func (in *SanitizationConfig) DeepCopyInto(out *SanitizationConfig) {
+	*out = *in
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = new(MetadataFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = new(MetadataFilter)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Finalizers != nil {
+		in, out := &in.Finalizers, &out.Finalizers
+		*out = new(MetadataFilter)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy creates a deep copy of SanitizationConfig
+func (in *SanitizationConfig) DeepCopy() *SanitizationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SanitizationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies DependencyConfig into out
+func (in *DependencyConfig) DeepCopyInto(out *DependencyConfig) {
+	*out = *in
+	if in.Namespaces != nil {
+		in, out := &in.Namespaces, &out.Namespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopyInto copies SyncLimits into out
+func (in *SyncLimits) DeepCopyInto(out *SyncLimits) {
+	*out = *in
+	if in.MaxObjectsPerKind != nil {
+		in, out := &in.MaxObjectsPerKind, &out.MaxObjectsPerKind
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxTotalPVCSizeGi != nil {
+		in, out := &in.MaxTotalPVCSizeGi, &out.MaxTotalPVCSizeGi
+		*out = new(int64)
+		**out = **in
+	}
+	if in.MaxSecretSizeKi != nil {
+		in, out := &in.MaxSecretSizeKi, &out.MaxSecretSizeKi
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy creates a deep copy of SyncLimits
+func (in *SyncLimits) DeepCopy() *SyncLimits {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncLimits)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopy creates a deep copy of DependencyConfig
+func (in *DependencyConfig) DeepCopy() *DependencyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DependencyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies QuotaScalingConfig into out
+func (in *QuotaScalingConfig) DeepCopyInto(out *QuotaScalingConfig) {
+	*out = *in
+	if in.UnscaledResources != nil {
+		in, out := &in.UnscaledResources, &out.UnscaledResources
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy creates a deep copy of QuotaScalingConfig
+func (in *QuotaScalingConfig) DeepCopy() *QuotaScalingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(QuotaScalingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies SourceAnnotationConfig into out
+func (in *SourceAnnotationConfig) DeepCopyInto(out *SourceAnnotationConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy creates a deep copy of SourceAnnotationConfig
+func (in *SourceAnnotationConfig) DeepCopy() *SourceAnnotationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SourceAnnotationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies NetworkIsolationConfig into out
+func (in *NetworkIsolationConfig) DeepCopyInto(out *NetworkIsolationConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.ExemptPods != nil {
+		in, out := &in.ExemptPods, &out.ExemptPods
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy creates a deep copy of NetworkIsolationConfig
+func (in *NetworkIsolationConfig) DeepCopy() *NetworkIsolationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(NetworkIsolationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies ServiceBridgeConfig into out
+func (in *ServiceBridgeConfig) DeepCopyInto(out *ServiceBridgeConfig) {
+	*out = *in
+	if in.Services != nil {
+		in, out := &in.Services, &out.Services
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy creates a deep copy of ServiceBridgeConfig
+func (in *ServiceBridgeConfig) DeepCopy() *ServiceBridgeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ServiceBridgeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies SelectorlessEndpointsConfig into out
+func (in *SelectorlessEndpointsConfig) DeepCopyInto(out *SelectorlessEndpointsConfig) {
+	*out = *in
+	if in.Enabled != nil {
+		in, out := &in.Enabled, &out.Enabled
+		*out = new(bool)
+		**out = **in
+	}
+	if in.AddressRewrites != nil {
+		in, out := &in.AddressRewrites, &out.AddressRewrites
+		*out = make([]AddressRewrite, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy creates a deep copy of SelectorlessEndpointsConfig
+func (in *SelectorlessEndpointsConfig) DeepCopy() *SelectorlessEndpointsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SelectorlessEndpointsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies InjectRule into out
+func (in *InjectRule) DeepCopyInto(out *InjectRule) {
+	*out = *in
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]InjectKind, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(metav1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Labels != nil {
+		in, out := &in.Labels, &out.Labels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Annotations != nil {
+		in, out := &in.Annotations, &out.Annotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodeSelector != nil {
+		in, out := &in.NodeSelector, &out.NodeSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Tolerations != nil {
+		in, out := &in.Tolerations, &out.Tolerations
+		*out = make([]corev1.Toleration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of InjectRule
+func (in *InjectRule) DeepCopy() *InjectRule {
+	if in == nil {
+		return nil
+	}
+	out := new(InjectRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto copies SyncConsistency into out
+func (in *SyncConsistency) DeepCopyInto(out *SyncConsistency) {
+	*out = *in
+	if in.Snapshot != nil {
+		in, out := &in.Snapshot, &out.Snapshot
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy creates a deep copy of SyncConsistency
+func (in *SyncConsistency) DeepCopy() *SyncConsistency {
+	if in == nil {
+		return nil
+	}
+	out := new(SyncConsistency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+func (in *MetadataFilter) DeepCopyInto(out *MetadataFilter) {
+	*out = *in
+	if in.Strip != nil {
+		in, out := &in.Strip, &out.Strip
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Preserve != nil {
+		in, out := &in.Preserve, &out.Preserve
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy creates a deep copy of MetadataFilter
+func (in *MetadataFilter) DeepCopy() *MetadataFilter {
 	if in == nil {
 		return nil
 	}
-	out := new(NamespaceConfig)
+	out := new(MetadataFilter)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// SecretReference references a secret
-type SecretReference struct {
-	// Name is the name of the secret
-	Name string `json:"name"`
-
-	// Namespace is the namespace of the secret
-	Namespace string `json:"namespace"`
+// DeepCopyInto copies KeyFilter into out
+func (in *KeyFilter) DeepCopyInto(out *KeyFilter) {
+	*out = *in
+	if in.Include != nil {
+		in, out := &in.Include, &out.Include
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Exclude != nil {
+		in, out := &in.Exclude, &out.Exclude
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// ClusterMappingReference references a ClusterMapping resource
-type ClusterMappingReference struct {
-	// Name is the name of the ClusterMapping
-	Name string `json:"name"`
-
-	// Namespace is the namespace of the ClusterMapping
-	// +optional
-	Namespace string `json:"namespace,omitempty"`
+// DeepCopy creates a deep copy of KeyFilter
+func (in *KeyFilter) DeepCopy() *KeyFilter {
+	if in == nil {
+		return nil
+	}
+	out := new(KeyFilter)
+	in.DeepCopyInto(out)
+	return out
 }
 
-// DeepCopyInto copies ClusterMappingReference into out
-func (in *ClusterMappingReference) DeepCopyInto(out *ClusterMappingReference) {
+// DeepCopyInto copies PreservedFields into out
+func (in *PreservedFields) DeepCopyInto(out *PreservedFields) {
 	*out = *in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
-// DeepCopy creates a deep copy of ClusterMappingReference
-func (in *ClusterMappingReference) DeepCopy() *ClusterMappingReference {
+// DeepCopy creates a deep copy of PreservedFields
+func (in *PreservedFields) DeepCopy() *PreservedFields {
 	if in == nil {
 		return nil
 	}
-	out := new(ClusterMappingReference)
+	out := new(PreservedFields)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto copies SecretReference into out
-func (in *SecretReference) DeepCopyInto(out *SecretReference) {
+// IgnoreFieldsConfig configures the differences between source and destination resources that do not
+// update the destination resource
+type IgnoreFieldsConfig struct {
+	// BuiltIn enables the built-in ignores: the replicas of Deployments and StatefulSets scaled by a
+	// HorizontalPodAutoscaler in the destination namespace, containers injected into destination pod
+	// templates by service meshes and secret agents, and fields the destination API server defaulted that
+	// the source resource omits. The destination values of those fields are kept. Defaults to true.
+	// +optional
+	BuiltIn *bool `json:"builtIn,omitempty"`
+
+	// InjectedContainers are regular expressions matching the whole names of containers injected into
+	// destination pod templates, in addition to the built-in names such as istio-proxy and linkerd-proxy
+	// +optional
+	InjectedContainers []string `json:"injectedContainers,omitempty"`
+
+	// Fields lists fields whose differences alone do not update the destination resource. When another
+	// difference updates it, the source values of the fields are written.
+	// +optional
+	Fields []IgnoredFields `json:"fields,omitempty"`
+}
+
+// DeepCopyInto copies IgnoreFieldsConfig into out
+func (in *IgnoreFieldsConfig) DeepCopyInto(out *IgnoreFieldsConfig) {
 	*out = *in
+	if in.BuiltIn != nil {
+		in, out := &in.BuiltIn, &out.BuiltIn
+		*out = new(bool)
+		**out = **in
+	}
+	if in.InjectedContainers != nil {
+		in, out := &in.InjectedContainers, &out.InjectedContainers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]IgnoredFields, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
-// DeepCopy creates a deep copy of SecretReference
-func (in *SecretReference) DeepCopy() *SecretReference {
+// DeepCopy creates a deep copy of IgnoreFieldsConfig
+func (in *IgnoreFieldsConfig) DeepCopy() *IgnoreFieldsConfig {
 	if in == nil {
 		return nil
 	}
-	out := new(SecretReference)
+	out := new(IgnoreFieldsConfig)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyInto copies ImmutableResourceConfig into out
-func (in *ImmutableResourceConfig) DeepCopyInto(out *ImmutableResourceConfig) {
+// IgnoredFields lists fields of resources whose differences are ignored when comparing the source and
+// destination resources
+type IgnoredFields struct {
+	// Kind is the kind of resource, such as Deployment, all kinds when empty
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// Name is a regular expression matching the whole names of the resources, all resources of Kind
+	// when empty
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Paths are JSONPath expressions of the ignored fields, in the syntax of preserveDestinationFields,
+	// such as .metadata.annotations['kubectl.kubernetes.io/restartedAt']
+	// +kubebuilder:validation:MinItems=1
+	Paths []string `json:"paths"`
+}
+
+// DeepCopyInto copies IgnoredFields into out
+func (in *IgnoredFields) DeepCopyInto(out *IgnoredFields) {
 	*out = *in
-	if in.ResourceOverrides != nil {
-		in, out := &in.ResourceOverrides, &out.ResourceOverrides
-		*out = make(map[string]ImmutableResourceHandling, len(*in))
-		for key, val := range *in {
-			(*out)[key] = val
-		}
-	}
-	if in.DrainTimeout != nil {
-		in, out := &in.DrainTimeout, &out.DrainTimeout
-		*out = new(metav1.Duration)
-		**out = **in
-	}
-	if in.ForceDeleteTimeout != nil {
-		in, out := &in.ForceDeleteTimeout, &out.ForceDeleteTimeout
-		*out = new(metav1.Duration)
-		**out = **in
+	if in.Paths != nil {
+		in, out := &in.Paths, &out.Paths
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
 }
 
-// DeepCopy creates a deep copy of ImmutableResourceConfig
-func (in *ImmutableResourceConfig) DeepCopy() *ImmutableResourceConfig {
+// DeepCopy creates a deep copy of IgnoredFields
+func (in *IgnoredFields) DeepCopy() *IgnoredFields {
 	if in == nil {
 		return nil
 	}
-	out := new(ImmutableResourceConfig)
+	out := new(IgnoredFields)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// SyncPhase represents the current phase of replication
 // +kubebuilder:validation:Enum=Pending;Running;Completed;Failed
 type SyncPhase string
 
@@ -784,6 +2137,16 @@ type SyncStats struct {
 	// +kubebuilder:validation:Minimum=0
 	FailedSyncs int32 `json:"failedSyncs"`
 
+	// PVCDataSyncs is the number of PVCs whose data was synced
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	PVCDataSyncs int32 `json:"pvcDataSyncs,omitempty"`
+
+	// FailedPVCDataSyncs is the number of PVCs whose data failed to sync
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	FailedPVCDataSyncs int32 `json:"failedPVCDataSyncs,omitempty"`
+
 	// LastSyncDuration is the duration of the last sync operation
 	// +kubebuilder:validation:Pattern=^([0-9]+h)?([0-9]+m)?([0-9]+s)?$
 	LastSyncDuration string `json:"lastSyncDuration"`
@@ -906,3 +2269,345 @@ func (in *DeploymentScale) DeepCopy() *DeploymentScale {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// ManualTrigger records a manually requested sync
+type ManualTrigger struct {
+	// RequestedBy identifies who requested the sync, taken from the
+	// dr-syncer.io/sync-requested-by annotation or the field manager that set the trigger annotation
+	// +optional
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// Value is the value of the trigger annotation
+	// +optional
+	Value string `json:"value,omitempty"`
+
+	// TriggeredAt is when the controller processed the request
+	// +optional
+	TriggeredAt *metav1.Time `json:"triggeredAt,omitempty"`
+}
+
+// DeepCopyInto copies ManualTrigger into out
+func (in *ManualTrigger) DeepCopyInto(out *ManualTrigger) {
+	*out = *in
+	if in.TriggeredAt != nil {
+		in, out := &in.TriggeredAt, &out.TriggeredAt
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy creates a deep copy of ManualTrigger
+func (in *ManualTrigger) DeepCopy() *ManualTrigger {
+	if in == nil {
+		return nil
+	}
+	out := new(ManualTrigger)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// FailoverTest records a failover test requested through the dr-syncer.io/failover-test annotation
+type FailoverTest struct {
+	// RequestedBy identifies who requested the test, taken from the field manager that set the annotation
+	// +optional
+	RequestedBy string `json:"requestedBy,omitempty"`
+
+	// Namespace is the temporary destination namespace the source namespace was cloned into
+	Namespace string `json:"namespace"`
+
+	// StartTime is when the test started
+	StartTime metav1.Time `json:"startTime"`
+
+	// CompletionTime is when the test finished and its namespace was deleted, unset while it runs
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Passed reports whether the clone synced, its workloads became available and the smoke tests passed
+	Passed bool `json:"passed"`
+
+	// Message explains the result of the test
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// SmokeTests holds the results of spec.verification.smokeTests against the temporary namespace
+	// +optional
+	SmokeTests []SmokeTestResult `json:"smokeTests,omitempty"`
+}
+
+// DeepCopyInto copies FailoverTest into out
+func (in *FailoverTest) DeepCopyInto(out *FailoverTest) {
+	*out = *in
+	in.StartTime.DeepCopyInto(&out.StartTime)
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+	if in.SmokeTests != nil {
+		in, out := &in.SmokeTests, &out.SmokeTests
+		*out = make([]SmokeTestResult, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of FailoverTest
+func (in *FailoverTest) DeepCopy() *FailoverTest {
+	if in == nil {
+		return nil
+	}
+	out := new(FailoverTest)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// VerificationStatus reports how the destination objects compared to the synced state after a sync
+type VerificationStatus struct {
+	// VerifiedAt is when the destination objects were re-read
+	// +optional
+	VerifiedAt *metav1.Time `json:"verifiedAt,omitempty"`
+
+	// Kinds holds the verification results per kind
+	// +optional
+	Kinds []KindVerification `json:"kinds,omitempty"`
+}
+
+// DeepCopyInto copies VerificationStatus into out
+func (in *VerificationStatus) DeepCopyInto(out *VerificationStatus) {
+	*out = *in
+	if in.VerifiedAt != nil {
+		in, out := &in.VerifiedAt, &out.VerifiedAt
+		*out = (*in).DeepCopy()
+	}
+	if in.Kinds != nil {
+		in, out := &in.Kinds, &out.Kinds
+		*out = make([]KindVerification, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy creates a deep copy of VerificationStatus
+func (in *VerificationStatus) DeepCopy() *VerificationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// VerificationConfig configures the smoke tests probing the destination namespace
+type VerificationConfig struct {
+	// SmokeTests run after each sync leaving the destination workloads running, i.e. with scaleToZero
+	// disabled, and their results are recorded in the SmokeTestsPassed condition
+	// +optional
+	SmokeTests []SmokeTest `json:"smokeTests,omitempty"`
+
+	// RunAfterEachSync also runs the smoke tests after syncs scaling the destination workloads to zero,
+	// e.g. to probe databases that keep running in the DR cluster
+	// +optional
+	// +kubebuilder:default=false
+	RunAfterEachSync *bool `json:"runAfterEachSync,omitempty"`
+
+	// ProbeImage is the image of the Jobs running HTTP and TCP probes in the destination namespace.
+	// It must provide busybox compatible wget and nc commands.
+	// +optional
+	// +kubebuilder:default="busybox:1.36"
+	ProbeImage string `json:"probeImage,omitempty"`
+}
+
+// DeepCopyInto copies VerificationConfig into out
+func (in *VerificationConfig) DeepCopyInto(out *VerificationConfig) {
+	*out = *in
+	if in.SmokeTests != nil {
+		in, out := &in.SmokeTests, &out.SmokeTests
+		*out = make([]SmokeTest, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.RunAfterEachSync != nil {
+		in, out := &in.RunAfterEachSync, &out.RunAfterEachSync
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy creates a deep copy of VerificationConfig
+func (in *VerificationConfig) DeepCopy() *VerificationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VerificationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// SmokeTest is a probe of the destination namespace, exactly one of HTTPGet, TCP and Exec must be set
+type SmokeTest struct {
+	// Name identifies the smoke test in the status
+	// +kubebuilder:validation:Pattern=`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`
+	// +kubebuilder:validation:MaxLength=63
+	Name string `json:"name"`
+
+	// HTTPGet requests a path of a destination Service from a probe Job, passing on a 2xx response
+	// +optional
+	HTTPGet *HTTPSmokeTest `json:"httpGet,omitempty"`
+
+	// TCP opens a connection to a port of a destination Service from a probe Job
+	// +optional
+	TCP *TCPSmokeTest `json:"tcp,omitempty"`
+
+	// Exec runs a command in a running destination pod, passing when it exits with status 0
+	// +optional
+	Exec *ExecSmokeTest `json:"exec,omitempty"`
+
+	// TimeoutSeconds bounds the probe
+	// +optional
+	// +kubebuilder:default=30
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds *int32 `json:"timeoutSeconds,omitempty"`
+}
+
+// DeepCopyInto copies SmokeTest into out
+func (in *SmokeTest) DeepCopyInto(out *SmokeTest) {
+	*out = *in
+	if in.HTTPGet != nil {
+		in, out := &in.HTTPGet, &out.HTTPGet
+		*out = new(HTTPSmokeTest)
+		**out = **in
+	}
+	if in.TCP != nil {
+		in, out := &in.TCP, &out.TCP
+		*out = new(TCPSmokeTest)
+		**out = **in
+	}
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(ExecSmokeTest)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TimeoutSeconds != nil {
+		in, out := &in.TimeoutSeconds, &out.TimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// HTTPSmokeTest requests a path of a Service in the destination namespace
+type HTTPSmokeTest struct {
+	// Service is the name of the Service
+	Service string `json:"service"`
+
+	// Port is the Service port
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+
+	// Path is the requested path
+	// +optional
+	// +kubebuilder:default="/"
+	Path string `json:"path,omitempty"`
+}
+
+// TCPSmokeTest connects to a port of a Service in the destination namespace
+type TCPSmokeTest struct {
+	// Service is the name of the Service
+	Service string `json:"service"`
+
+	// Port is the Service port
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	Port int32 `json:"port"`
+}
+
+// ExecSmokeTest runs a command in a running pod of the destination namespace
+type ExecSmokeTest struct {
+	// PodSelector selects the pods by label, the command runs in one of the running pods
+	PodSelector map[string]string `json:"podSelector"`
+
+	// Container runs the command, defaults to the first container of the pod
+	// +optional
+	Container string `json:"container,omitempty"`
+
+	// Command is the command and its arguments, it is not run in a shell
+	// +kubebuilder:validation:MinItems=1
+	Command []string `json:"command"`
+}
+
+// DeepCopyInto copies ExecSmokeTest into out
+func (in *ExecSmokeTest) DeepCopyInto(out *ExecSmokeTest) {
+	*out = *in
+	if in.PodSelector != nil {
+		in, out := &in.PodSelector, &out.PodSelector
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// SmokeTestResult is the result of the last run of a smoke test
+type SmokeTestResult struct {
+	// Name of the smoke test
+	Name string `json:"name"`
+
+	// Passed reports whether the probe succeeded
+	Passed bool `json:"passed"`
+
+	// Message explains why the probe failed
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// LastRunTime is when the probe ran
+	LastRunTime metav1.Time `json:"lastRunTime"`
+}
+
+// DeepCopyInto copies SmokeTestResult into out
+func (in *SmokeTestResult) DeepCopyInto(out *SmokeTestResult) {
+	*out = *in
+	in.LastRunTime.DeepCopyInto(&out.LastRunTime)
+}
+
+// KindVerification holds the verification results of the synced objects of one kind
+type KindVerification struct {
+	// Kind of the verified objects
+	Kind string `json:"kind"`
+
+	// Verified is the number of objects matching the synced state
+	// +kubebuilder:validation:Minimum=0
+	Verified int32 `json:"verified"`
+
+	// Mismatched is the number of objects that differ from the synced state or are missing
+	// +kubebuilder:validation:Minimum=0
+	Mismatched int32 `json:"mismatched"`
+
+	// Mismatches lists the first mismatched objects with the fields that differ
+	// +optional
+	Mismatches []VerificationMismatch `json:"mismatches,omitempty"`
+}
+
+// DeepCopyInto copies KindVerification into out
+func (in *KindVerification) DeepCopyInto(out *KindVerification) {
+	*out = *in
+	if in.Mismatches != nil {
+		in, out := &in.Mismatches, &out.Mismatches
+		*out = make([]VerificationMismatch, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// VerificationMismatch is a destination object that differs from the synced state
+type VerificationMismatch struct {
+	// Name of the destination object
+	Name string `json:"name"`
+
+	// Fields lists the paths of the fields that differ, or says that the object is missing
+	Fields string `json:"fields"`
+}
@@ -33,6 +33,16 @@ func (in *ContinuousConfig) DeepCopyInto(out *ContinuousConfig) {
 		*out = new(bool)
 		**out = **in
 	}
+	if in.UseInformerCache != nil {
+		in, out := &in.UseInformerCache, &out.UseInformerCache
+		*out = new(bool)
+		**out = **in
+	}
+	if in.DataWatch != nil {
+		in, out := &in.DataWatch, &out.DataWatch
+		*out = new(DataWatchConfig)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContinuousConfig.
@@ -45,6 +55,21 @@ func (in *ContinuousConfig) DeepCopy() *ContinuousConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DataWatchConfig) DeepCopyInto(out *DataWatchConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DataWatchConfig.
+func (in *DataWatchConfig) DeepCopy() *DataWatchConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DataWatchConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvVar) DeepCopyInto(out *EnvVar) {
 	*out = *in
@@ -156,6 +181,13 @@ func (in *PVCSyncAgentStatus) DeepCopyInto(out *PVCSyncAgentStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.Endpoints != nil {
+		in, out := &in.Endpoints, &out.Endpoints
+		*out = make(map[string]AgentEndpoint, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCSyncAgentStatus.
@@ -306,6 +338,58 @@ func (in *PVCSyncSSH) DeepCopyInto(out *PVCSyncSSH) {
 		*out = new(SSHKeySecretRef)
 		**out = **in
 	}
+	if in.KeyRotationInterval != nil {
+		in, out := &in.KeyRotationInterval, &out.KeyRotationInterval
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.Exposure != nil {
+		in, out := &in.Exposure, &out.Exposure
+		*out = new(AgentExposure)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentExposure) DeepCopyInto(out *AgentExposure) {
+	*out = *in
+	if in.ServiceAnnotations != nil {
+		in, out := &in.ServiceAnnotations, &out.ServiceAnnotations
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Rendezvous != nil {
+		in, out := &in.Rendezvous, &out.Rendezvous
+		*out = new(AgentRendezvous)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentExposure.
+func (in *AgentExposure) DeepCopy() *AgentExposure {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentExposure)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentRendezvous) DeepCopyInto(out *AgentRendezvous) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentRendezvous.
+func (in *AgentRendezvous) DeepCopy() *AgentRendezvous {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentRendezvous)
+	in.DeepCopyInto(out)
+	return out
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCSyncSSH.
@@ -336,6 +420,16 @@ func (in *PVCSyncSpec) DeepCopyInto(out *PVCSyncSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.GlobalConcurrencyLimit != nil {
+		in, out := &in.GlobalConcurrencyLimit, &out.GlobalConcurrencyLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MaxConcurrentDataSyncs != nil {
+		in, out := &in.MaxConcurrentDataSyncs, &out.MaxConcurrentDataSyncs
+		*out = new(int32)
+		**out = **in
+	}
 	if in.RetryConfig != nil {
 		in, out := &in.RetryConfig, &out.RetryConfig
 		*out = new(PVCSyncRetryConfig)
@@ -379,6 +473,10 @@ func (in *PVCSyncStatus) DeepCopyInto(out *PVCSyncStatus) {
 		in, out := &in.LastDeploymentTime, &out.LastDeploymentTime
 		*out = (*in).DeepCopy()
 	}
+	if in.LastKeyRotation != nil {
+		in, out := &in.LastKeyRotation, &out.LastKeyRotation
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PVCSyncStatus.
@@ -469,6 +567,26 @@ func (in *RemoteClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProxyConfig) DeepCopyInto(out *ProxyConfig) {
+	*out = *in
+	if in.SSHJumpHost != nil {
+		in, out := &in.SSHJumpHost, &out.SSHJumpHost
+		*out = new(SSHJumpHostConfig)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProxyConfig.
+func (in *ProxyConfig) DeepCopy() *ProxyConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ProxyConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RemoteClusterSpec) DeepCopyInto(out *RemoteClusterSpec) {
 	*out = *in
@@ -483,6 +601,11 @@ func (in *RemoteClusterSpec) DeepCopyInto(out *RemoteClusterSpec) {
 		*out = new(PVCSyncSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Proxy != nil {
+		in, out := &in.Proxy, &out.Proxy
+		*out = new(ProxyConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteClusterSpec.
@@ -619,3 +742,19 @@ func (in *SyncAttempt) DeepCopy() *SyncAttempt {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SSHJumpHostConfig) DeepCopyInto(out *SSHJumpHostConfig) {
+	*out = *in
+	out.KeySecretRef = in.KeySecretRef
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SSHJumpHostConfig.
+func (in *SSHJumpHostConfig) DeepCopy() *SSHJumpHostConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SSHJumpHostConfig)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -105,6 +105,17 @@ stream_logs() {
 stream_logs "/var/log/console.log" "[CONSOLE] " 
 stream_logs "/var/log/auth.log" "[AUTH] " 2>/dev/null || true
 
+# Directory the agent installs the shared authorized_keys into, sshd reads it on every login
+mkdir -p /etc/ssh/authorized_keys.d
+chmod 755 /etc/ssh/authorized_keys.d
+
+# Serve the agent health and metrics endpoint, only the agent image ships the binary.
+# SSH keys are generated by the controller, the agents only elect a leader to distribute the authorized keys.
+if [ -x /usr/local/bin/dr-syncer-agent ]; then
+    log "Starting agent health and metrics endpoint on port ${HEALTH_PORT:-9801}"
+    /usr/local/bin/dr-syncer-agent --ssh-port="${SSH_PORT:-2222}" --health-port="${HEALTH_PORT:-9801}" --manage-keys=false >> /var/log/console.log 2>&1 &
+fi
+
 log "Starting SSH daemon in debug mode"
 # Start sshd in debug mode to get more verbose output
 exec /usr/sbin/sshd -D -e -E /var/log/console.log
@@ -0,0 +1,22 @@
+#!/bin/bash
+# Forced command for rsync keys in Daemon mode. The key may only reach the
+# read-only rsync daemon whose modules are allow-listed per sync by the
+# controller under /etc/dr-syncer/rsyncd.d.
+set -e
+
+case "${SSH_ORIGINAL_COMMAND}" in
+    "test-connection")
+        echo 'SSH proxy connection successful'
+        ;;
+    "echo SSH_CONNECTION_SUCCESSFUL")
+        echo 'SSH_CONNECTION_SUCCESSFUL'
+        ;;
+    "rsync --server --daemon "*)
+        # Ignore client-supplied arguments so the config cannot be overridden
+        exec /usr/bin/rsync --server --daemon --config=/etc/dr-syncer/rsyncd.conf .
+        ;;
+    *)
+        echo 'Unauthorized command' >&2
+        exit 1
+        ;;
+esac
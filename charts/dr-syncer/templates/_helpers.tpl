@@ -60,3 +60,75 @@ Create the name of the service account to use
 {{- default "default" .Values.serviceAccount.name }}
 {{- end }}
 {{- end }}
+
+{{/*
+Rules of the manager role, granted cluster-wide or in each watched namespace
+*/}}
+{{- define "dr-syncer.managerRules" }}
+- apiGroups:
+  - "dr-syncer.io"
+  resources:
+  - remoteclusters
+  - remoteclusters/status
+  - remoteclusters/finalizers
+  - namespacemappings
+  - namespacemappings/status
+  - namespacemappings/finalizers
+  - clustermappings
+  - clustermappings/status
+  - clustermappings/finalizers
+  - drreadinesses
+  - drreadinesses/status
+  - namespacemappinggenerators
+  - namespacemappinggenerators/status
+  verbs:
+  - get
+  - list
+  - watch
+  - create
+  - update
+  - patch
+  - delete
+- apiGroups:
+  - coordination.k8s.io
+  resources:
+  - leases
+  verbs:
+  - get
+  - list
+  - watch
+  - create
+  - update
+  - patch
+  - delete
+- apiGroups:
+  - ""
+  resources:
+  - namespaces
+  - secrets
+  - configmaps
+  verbs:
+  - get
+  - list
+  - watch
+  - create
+  - update
+  - patch
+  - delete
+- apiGroups:
+  - "*"
+  resources:
+  - "*"
+  verbs:
+  - get
+  - list
+  - watch
+# Events permission for PVC sync workflow and NamespaceMapping observability
+- apiGroups:
+  - ""
+  resources:
+  - events
+  verbs:
+  - create
+  - patch
+{{- end }}
@@ -11,8 +11,10 @@ import (
 	"time"
 
 	"github.com/supporttools/dr-syncer/pkg/agent/daemon"
+	"github.com/supporttools/dr-syncer/pkg/agent/datawatch"
 	"github.com/supporttools/dr-syncer/pkg/agent/leader"
 	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
+	"github.com/supporttools/dr-syncer/pkg/agent/tunnel"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
@@ -22,6 +24,14 @@ import (
 var (
 	sshPort    = flag.Int("ssh-port", 2222, "SSH server port")
 	kubeconfig = flag.String("kubeconfig", "", "Path to kubeconfig file")
+	healthPort = flag.Int("health-port", daemon.DefaultHealthPort, "Port of the health and metrics endpoint, 0 disables it")
+	manageKeys = flag.Bool("manage-keys", true, "Generate and rotate the agent SSH keys from the elected leader")
+	distribute = flag.Bool("distribute-keys", true, "Elect a leader among the agents to maintain the shared authorized_keys Secret")
+	keysMount  = flag.String("authorized-keys-mount", ssh.AuthorizedKeysMountPath, "Directory the shared authorized_keys Secret is mounted in, empty disables installing it")
+	watchData  = flag.Bool("watch-data", true, "Watch the data of the PVCs annotated by the controller for changes")
+	rendezvous = flag.String("rendezvous", os.Getenv("RENDEZVOUS_ADDRESS"), "host:port of the controller rendezvous to connect out to with the Reverse exposure, empty only accepts inbound connections")
+	rvHostKey  = flag.String("rendezvous-host-key", os.Getenv("RENDEZVOUS_HOST_KEY"), "Public host key of the rendezvous in authorized_keys format")
+	agentKey   = flag.String("agent-key", "/etc/ssh/keys/id_rsa", "Private key the agent authenticates to the rendezvous with")
 )
 
 func main() {
@@ -65,34 +75,95 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Initialize key system
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-	if err := d.InitKeySystem(ctx, clientset); err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to initialize key system: %v\n", err)
-		os.Exit(1)
+	leaderCtx, leaderCancel := context.WithCancel(context.Background())
+	defer leaderCancel()
+	if *manageKeys {
+		// Initialize key system
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := d.InitKeySystem(ctx, clientset); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize key system: %v\n", err)
+			os.Exit(1)
+		}
 	}
 
-	// Initialize leader election manager
-	leaderMgr, err := leader.NewManager(clientset, namespace)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Failed to initialize leader election manager: %v\n", err)
-		os.Exit(1)
+	if *manageKeys || *distribute {
+		// Initialize leader election manager, the leader distributes the authorized keys
+		leaderMgr, err := leader.NewManager(clientset, namespace)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize leader election manager: %v\n", err)
+			os.Exit(1)
+		}
+		leaderMgr.SetManageKeys(*manageKeys)
+		d.SetLeaderStatus(leaderMgr.IsLeader)
+
+		// Start leader election in background
+		go func() {
+			if err := leaderMgr.Run(leaderCtx); err != nil {
+				fmt.Fprintf(os.Stderr, "Leader election failed: %v\n", err)
+			}
+		}()
 	}
 
-	// Start leader election in background
-	leaderCtx, leaderCancel := context.WithCancel(context.Background())
-	defer leaderCancel()
-	go func() {
-		if err := leaderMgr.Run(leaderCtx); err != nil {
-			fmt.Fprintf(os.Stderr, "Leader election failed: %v\n", err)
+	// Install the shared authorized keys for sshd and reload them when the Secret changes
+	if *keysMount != "" {
+		keysWatcher := ssh.NewAuthorizedKeysWatcher(*keysMount, ssh.SharedAuthorizedKeysFile)
+		go func() {
+			if err := keysWatcher.Run(leaderCtx); err != nil {
+				fmt.Fprintf(os.Stderr, "Authorized keys watch failed: %v\n", err)
+			}
+		}()
+	}
+
+	// Serve health and metrics for the DaemonSet probes and Prometheus
+	if *healthPort > 0 {
+		if err := d.StartHealthServer(leaderCtx, *healthPort); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to start health server: %v\n", err)
+			os.Exit(1)
 		}
-	}()
+	}
+
+	// Watch the data of the PVCs mounted on this node for near-real-time replication
+	if *watchData {
+		if nodeName := os.Getenv("NODE_NAME"); nodeName != "" {
+			watcher := datawatch.NewWatcher(clientset, nodeName)
+			go func() {
+				if err := watcher.Run(leaderCtx); err != nil {
+					fmt.Fprintf(os.Stderr, "PVC data watch failed: %v\n", err)
+				}
+			}()
+		} else {
+			fmt.Fprintln(os.Stderr, "NODE_NAME is not set, PVC data is not watched")
+		}
+	}
+
+	// Connect out to the rendezvous of the controller, which forwards the port of this node to sshd
+	if *rendezvous != "" {
+		nodeName := os.Getenv("NODE_NAME")
+		if nodeName == "" {
+			fmt.Fprintln(os.Stderr, "NODE_NAME is required to connect to the rendezvous")
+			os.Exit(1)
+		}
+		agent, err := tunnel.NewAgent(*rendezvous, *rvHostKey, *agentKey, fmt.Sprintf("127.0.0.1:%d", *sshPort),
+			tunnel.ConfigMapPort(clientset, namespace, nodeName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to initialize rendezvous connection: %v\n", err)
+			os.Exit(1)
+		}
+		go func() {
+			if err := agent.Run(leaderCtx); err != nil {
+				fmt.Fprintf(os.Stderr, "Rendezvous connection failed: %v\n", err)
+			}
+		}()
+	}
 
 	// Start the daemon
 	if err := d.Start(); err != nil {
 		fmt.Fprintf(os.Stderr, "Failed to start daemon: %v\n", err)
-		os.Exit(1)
+		// Keys can be installed after sshd started, keep serving health and metrics meanwhile
+		if *healthPort == 0 {
+			os.Exit(1)
+		}
 	}
 
 	// Handle shutdown
@@ -1,16 +1,39 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
 	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/supporttools/dr-syncer/pkg/cli"
 	"github.com/supporttools/dr-syncer/pkg/logging"
 	"github.com/supporttools/dr-syncer/pkg/version"
 )
 
+// namespaceMappingFlags collects the repeatable --namespace-mapping flag
+type namespaceMappingFlags []cli.NamespaceMapping
+
+func (f *namespaceMappingFlags) String() string {
+	values := make([]string, len(*f))
+	for i, mapping := range *f {
+		values[i] = mapping.String()
+	}
+	return strings.Join(values, ",")
+}
+
+func (f *namespaceMappingFlags) Set(value string) error {
+	mapping, err := cli.ParseNamespaceMapping(value)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, mapping)
+	return nil
+}
+
 func main() {
 	// Initialize logging
 	log := logging.SetupLogging()
@@ -22,19 +45,39 @@ func main() {
 	// Required flags
 	sourceKubeconfig := flag.String("source-kubeconfig", "", "Path to the source kubeconfig file")
 	destKubeconfig := flag.String("dest-kubeconfig", "", "Path to the destination kubeconfig file")
+	sourceContext := flag.String("source-context", "", "Kubeconfig context for the source cluster (defaults to the current context)")
+	destContext := flag.String("dest-context", "", "Kubeconfig context for the destination cluster (defaults to the current context)")
 	sourceNamespace := flag.String("source-namespace", "", "Namespace in the source cluster")
 	destNamespace := flag.String("dest-namespace", "", "Namespace in the destination cluster")
+	var namespaceMappings namespaceMappingFlags
+	flag.Var(&namespaceMappings, "namespace-mapping", "Source and destination namespace pair as source=destination, repeatable to sync several namespaces (replaces --source-namespace and --dest-namespace)")
+	namespaceMappingsFile := flag.String("namespace-mappings-file", "", "Path to a YAML file of namespace mappings to sync (replaces --source-namespace and --dest-namespace)")
+	concurrency := flag.Int("concurrency", cli.DefaultConcurrency, "Number of namespace mappings processed at the same time")
 
 	// Mode flag with validation
-	mode := flag.String("mode", "", "Operation mode: Stage, Cutover, or Failback")
+	mode := flag.String("mode", "", "Operation mode: Stage, PreProvision, Cutover, Failback, Rollback, or Export")
 
 	// Optional flags
 	includeCustomResources := flag.Bool("include-custom-resources", false, "Include custom resources in synchronization")
 	migratePVCData := flag.Bool("migrate-pvc-data", false, "Migrate PVC data using pv-migrate (requires pv-migrate to be installed)")
 	reverseMigratePVCData := flag.Bool("reverse-migrate-pvc-data", false, "Migrate PVC data from destination back to source (for Failback mode)")
-	resourceTypes := flag.String("resource-types", "", "Comma-separated list of resource types to include (overrides defaults)")
-	excludeResourceTypes := flag.String("exclude-resource-types", "", "Comma-separated list of resource types to exclude")
+	resourceTypes := flag.String("resource-types", "", "Comma-separated list of resource types, aliases (deploy, svc, pvc) or categories (core, workloads, networking) to include (overrides defaults)")
+	excludeResourceTypes := flag.String("exclude-resource-types", "", "Comma-separated list of resource types, aliases or categories to exclude")
+	suspendCronJobs := flag.Bool("suspend-cronjobs", true, "Create CronJobs and Jobs suspended in the destination; they are unsuspended during Cutover")
 	pvMigrateFlags := flag.String("pv-migrate-flags", "", "Additional flags to pass to pv-migrate (e.g. \"--strategy rsync --lbsvc-timeout 10m\")")
+	pvcs := flag.String("pvcs", "", "Comma-separated list of PVCs whose data is migrated, the others are skipped (defaults to all PVCs)")
+	pvcSelector := flag.String("pvc-selector", "", "Label selector of the PVCs whose data is migrated, e.g. app=db, in addition to --pvcs (defaults to all PVCs)")
+	backupEnabled := flag.Bool("backup", false, "Back up destination objects before they are overwritten so they can be restored with --mode Rollback")
+	backupRetention := flag.Int("backup-retention", 5, "Number of backed up versions kept per object")
+	backupNamespace := flag.String("backup-namespace", "", "Namespace to store backups in (defaults to the destination namespace)")
+	rollbackSince := flag.String("rollback-since", "", "For Rollback mode: restore the state before the first sync after this RFC3339 time (defaults to the latest backup)")
+	hooksConfig := flag.String("hooks-config", "", "Path to a YAML file of hooks (webhooks, Ingress/Service annotation updates) run after a successful Cutover")
+	notifyWebhookURL := flag.String("notify-webhook-url", "", "Webhook notified when a Cutover starts and completes")
+	notifyWebhookFormat := flag.String("notify-webhook-format", "Generic", "Payload format of --notify-webhook-url: Generic, Slack, Teams")
+	exportDir := flag.String("export-dir", "", "For Export mode: directory the manifests are written to, one subdirectory per destination namespace")
+	exportFile := flag.String("export-file", "", "For Export mode: file the manifests are written to as a single YAML stream, - for stdout")
+	stateDir := flag.String("state-dir", cli.DefaultStateDir, "Directory the progress of Stage, Cutover and Failback runs is recorded in, empty to disable")
+	resume := flag.Bool("resume", false, "Continue a failed run from the failed step instead of starting from the beginning")
 	logLevel := flag.String("log-level", "info", "Log level: debug, info, warn, error")
 
 	// Parse command line flags
@@ -46,6 +89,11 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Keep stdout for the manifests when they are exported to it
+	if *exportFile == "-" {
+		log.SetOutput(os.Stderr)
+	}
+
 	// Handle version flags
 	if *showVersion {
 		fmt.Println(version.GetVersionString())
@@ -57,22 +105,42 @@ func main() {
 	}
 
 	// Validate required flags
-	if *sourceKubeconfig == "" {
-		fmt.Fprintln(os.Stderr, "Error: --source-kubeconfig is required")
+	if *sourceKubeconfig == "" && *sourceContext == "" && *mode != "Rollback" {
+		fmt.Fprintln(os.Stderr, "Error: --source-kubeconfig or --source-context is required")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *destKubeconfig == "" && *destContext == "" && *mode != "Export" {
+		fmt.Fprintln(os.Stderr, "Error: --dest-kubeconfig or --dest-context is required")
 		flag.Usage()
 		os.Exit(1)
 	}
-	if *destKubeconfig == "" {
-		fmt.Fprintln(os.Stderr, "Error: --dest-kubeconfig is required")
+	if *namespaceMappingsFile != "" {
+		mappings, err := cli.LoadNamespaceMappings(*namespaceMappingsFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		namespaceMappings = append(namespaceMappings, mappings...)
+	}
+	multiNamespace := len(namespaceMappings) > 0
+	if multiNamespace && (*sourceNamespace != "" || *destNamespace != "") {
+		fmt.Fprintln(os.Stderr, "Error: --source-namespace and --dest-namespace cannot be combined with --namespace-mapping or --namespace-mappings-file")
 		flag.Usage()
 		os.Exit(1)
 	}
-	if *sourceNamespace == "" {
+	for _, mapping := range namespaceMappings {
+		if mapping.Source == "" && *mode != "Rollback" {
+			fmt.Fprintf(os.Stderr, "Error: namespace mapping %s has no source namespace\n", mapping)
+			os.Exit(1)
+		}
+	}
+	if *sourceNamespace == "" && *mode != "Rollback" && !multiNamespace {
 		fmt.Fprintln(os.Stderr, "Error: --source-namespace is required")
 		flag.Usage()
 		os.Exit(1)
 	}
-	if *destNamespace == "" {
+	if *destNamespace == "" && !multiNamespace {
 		fmt.Fprintln(os.Stderr, "Error: --dest-namespace is required")
 		flag.Usage()
 		os.Exit(1)
@@ -80,21 +148,51 @@ func main() {
 
 	// Validate mode flag
 	validModes := map[string]bool{
-		"Stage":    true,
-		"Cutover":  true,
-		"Failback": true,
+		"Stage":        true,
+		"PreProvision": true,
+		"Cutover":      true,
+		"Failback":     true,
+		"Rollback":     true,
+		"Export":       true,
 	}
 	if *mode == "" {
-		fmt.Fprintln(os.Stderr, "Error: --mode is required (Stage, Cutover, or Failback)")
+		fmt.Fprintln(os.Stderr, "Error: --mode is required (Stage, PreProvision, Cutover, Failback, Rollback, or Export)")
 		flag.Usage()
 		os.Exit(1)
 	}
 	if !validModes[*mode] {
-		fmt.Fprintf(os.Stderr, "Error: Invalid mode '%s'. Must be one of: Stage, Cutover, Failback\n", *mode)
+		fmt.Fprintf(os.Stderr, "Error: Invalid mode '%s'. Must be one of: Stage, PreProvision, Cutover, Failback, Rollback, Export\n", *mode)
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *mode == "Export" && (*exportDir == "") == (*exportFile == "") {
+		fmt.Fprintln(os.Stderr, "Error: Export mode requires exactly one of --export-dir and --export-file")
+		flag.Usage()
+		os.Exit(1)
+	}
+	if *mode != "Export" && (*exportDir != "" || *exportFile != "") {
+		fmt.Fprintln(os.Stderr, "Error: --export-dir and --export-file are only valid in Export mode")
 		flag.Usage()
 		os.Exit(1)
 	}
 
+	if *resume && *stateDir == "" {
+		fmt.Fprintln(os.Stderr, "Error: --resume requires --state-dir")
+		flag.Usage()
+		os.Exit(1)
+	}
+
+	// Parse rollback time
+	var rollbackSinceTime time.Time
+	if *rollbackSince != "" {
+		parsed, err := time.Parse(time.RFC3339, *rollbackSince)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Invalid --rollback-since '%s': %v\n", *rollbackSince, err)
+			os.Exit(1)
+		}
+		rollbackSinceTime = parsed
+	}
+
 	// Parse resource types
 	var resourceTypesList []string
 	if *resourceTypes != "" {
@@ -115,34 +213,97 @@ func main() {
 		}
 	}
 
+	// Parse the PVCs whose data is migrated
+	var pvcList []string
+	for _, name := range strings.Split(*pvcs, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			pvcList = append(pvcList, name)
+		}
+	}
+
 	// Create config
 	config := &cli.Config{
 		SourceKubeconfig:       *sourceKubeconfig,
 		DestKubeconfig:         *destKubeconfig,
+		SourceContext:          *sourceContext,
+		DestContext:            *destContext,
 		SourceNamespace:        *sourceNamespace,
 		DestNamespace:          *destNamespace,
+		NamespaceMappings:      namespaceMappings,
+		Concurrency:            *concurrency,
 		Mode:                   *mode,
 		IncludeCustomResources: *includeCustomResources,
 		MigratePVCData:         *migratePVCData,
 		ReverseMigratePVCData:  *reverseMigratePVCData,
 		ResourceTypes:          resourceTypesList,
 		ExcludeResourceTypes:   excludeResourceTypesList,
+		SuspendCronJobs:        *suspendCronJobs,
 		PVMigrateFlags:         *pvMigrateFlags,
+		PVCNames:               pvcList,
+		PVCSelector:            *pvcSelector,
+		Backup:                 *backupEnabled,
+		BackupRetention:        *backupRetention,
+		BackupNamespace:        *backupNamespace,
+		RollbackSince:          rollbackSinceTime,
+		HooksConfig:            *hooksConfig,
+		NotifyWebhookURL:       *notifyWebhookURL,
+		NotifyWebhookFormat:    *notifyWebhookFormat,
+		ExportDir:              *exportDir,
+		ExportFile:             *exportFile,
+		StateDir:               *stateDir,
+		Resume:                 *resume,
+	}
+
+	if _, err := config.PVCFilter(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
 	}
 
 	// Log configuration
 	log.Info("Starting DR Syncer CLI")
 	log.Infof("Source kubeconfig: %s", *sourceKubeconfig)
 	log.Infof("Destination kubeconfig: %s", *destKubeconfig)
-	log.Infof("Source namespace: %s", *sourceNamespace)
-	log.Infof("Destination namespace: %s", *destNamespace)
+	if *sourceContext != "" {
+		log.Infof("Source context: %s", *sourceContext)
+	}
+	if *destContext != "" {
+		log.Infof("Destination context: %s", *destContext)
+	}
+	if multiNamespace {
+		log.Infof("Namespace mappings: %s (concurrency %d)", namespaceMappings.String(), *concurrency)
+	} else {
+		log.Infof("Source namespace: %s", *sourceNamespace)
+		log.Infof("Destination namespace: %s", *destNamespace)
+	}
 	log.Infof("Mode: %s", *mode)
 
 	// Run CLI with config
-	if err := cli.Run(config); err != nil {
+	result, err := cli.NewOperation(config).Run(context.Background())
+	if result != nil && *mode == "PreProvision" {
+		printCapacityReport(result.Capacity())
+	}
+	if err != nil {
 		log.Errorf("Error: %v", err)
 		os.Exit(1)
 	}
 
 	log.Info("Operation completed successfully")
 }
+
+// printCapacityReport prints the storage requested per storage class of the destination by a PreProvision
+func printCapacityReport(capacity []cli.StorageClassCapacity) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "STORAGE CLASS\tPVCS\tREQUESTED\tSTATUS")
+	for _, c := range capacity {
+		storageClass := c.StorageClass
+		if storageClass == "" {
+			storageClass = "<none>"
+		}
+		status := "OK"
+		if c.Missing {
+			status = "MISSING"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", storageClass, c.PVCs, c.Requested.String(), status)
+	}
+	_ = w.Flush()
+}
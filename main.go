@@ -4,25 +4,43 @@ import (
 	"context"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
+
+	// Embed the time zone database so NamespaceMapping timezones resolve in images without one
+	_ "time/tzdata"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/agent/tunnel"
+	"github.com/supporttools/dr-syncer/pkg/audit"
 	"github.com/supporttools/dr-syncer/pkg/config"
 	"github.com/supporttools/dr-syncer/pkg/controller/remotecluster"
+	"github.com/supporttools/dr-syncer/pkg/controller/replication"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/notify"
+	"github.com/supporttools/dr-syncer/pkg/syncstate"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
+	"github.com/supporttools/dr-syncer/pkg/util"
 	"github.com/supporttools/dr-syncer/pkg/version"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	"github.com/supporttools/dr-syncer/pkg/controllers"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	"github.com/supporttools/dr-syncer/pkg/dashboard"
 )
 
 var scheme = runtime.NewScheme()
@@ -55,6 +73,26 @@ func main() {
 	flag.BoolVar(&config.CFG.EnableLeaderElection, "leader-elect", config.CFG.EnableLeaderElection,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.IntVar(&config.CFG.MaxConcurrentDataSyncs, "max-concurrent-data-syncs", config.CFG.MaxConcurrentDataSyncs,
+		"Maximum number of PVC data syncs running at the same time across all clusters. "+
+			"0 uses the globalConcurrencyLimit of the RemoteClusters.")
+	flag.BoolVar(&config.CFG.EnableDashboard, "enable-dashboard", config.CFG.EnableDashboard,
+		"Serve a read-only web dashboard of the mappings, PVC syncs and cluster connectivity on the metrics server at "+dashboard.Path)
+	flag.BoolVar(&config.CFG.EnableTracing, "enable-tracing", config.CFG.EnableTracing,
+		"Export OpenTelemetry traces of reconciles and sync workflows to the OTLP collector set by OTEL_EXPORTER_OTLP_ENDPOINT.")
+	flag.StringVar(&config.CFG.WatchNamespaces, "namespaces", config.CFG.WatchNamespaces,
+		"Comma-separated namespaces the controller caches and reconciles, for installs with namespace-scoped RBAC. "+
+			"Empty watches all namespaces.")
+	flag.StringVar(&config.CFG.RendezvousAddr, "rendezvous-bind-address", config.CFG.RendezvousAddr,
+		"The address the rendezvous of the agents with the Reverse exposure binds to, the ports forwarded to the agents "+
+			"are opened on its host. Empty disables the rendezvous.")
+	flag.StringVar(&config.CFG.RendezvousHostKeyFile, "rendezvous-host-key", config.CFG.RendezvousHostKeyFile,
+		"Path to the private host key of the rendezvous.")
+	flag.BoolVar(&config.CFG.EnableWebhooks, "enable-webhooks", config.CFG.EnableWebhooks,
+		"Serve the validating admission webhook rejecting NamespaceMappings that conflict with the other mappings.")
+	flag.IntVar(&config.CFG.WebhookPort, "webhook-port", config.CFG.WebhookPort, "The port the webhook server listens on.")
+	flag.StringVar(&config.CFG.WebhookCertDir, "webhook-cert-dir", config.CFG.WebhookCertDir,
+		"Directory holding the tls.crt and tls.key of the webhook server.")
 
 	flag.Parse()
 
@@ -71,20 +109,120 @@ func main() {
 	// Log configuration settings
 	log.Info("configuration loaded")
 
+	// Export traces and attach their IDs to the logs when enabled
+	shutdownTracing := func(context.Context) error { return nil }
+	if config.CFG.EnableTracing {
+		shutdown, err := tracing.Setup(context.Background())
+		if err != nil {
+			log.Errorf("unable to set up tracing: %v", err)
+			os.Exit(1)
+		}
+		shutdownTracing = shutdown
+		log.AddHook(tracing.LogHook{})
+		logrus.AddHook(tracing.LogHook{})
+		log.Info("exporting OpenTelemetry traces")
+	}
+
+	// Only cache and reconcile the configured namespaces, so a team can run its own install with Roles
+	var cacheOptions cache.Options
+	if namespaces := config.ParseNamespaces(config.CFG.WatchNamespaces); len(namespaces) > 0 {
+		cacheOptions.DefaultNamespaces = make(map[string]cache.Config, len(namespaces))
+		for _, namespace := range namespaces {
+			cacheOptions.DefaultNamespaces[namespace] = cache.Config{}
+		}
+		log.Infof("watching namespaces %s only", strings.Join(namespaces, ", "))
+	}
+
 	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
 		Scheme: scheme,
+		Cache:  cacheOptions,
 		Metrics: metricsserver.Options{
 			BindAddress: config.CFG.MetricsAddr,
+			// Serve the in-flight sync state next to the metrics for troubleshooting
+			ExtraHandlers: map[string]http.Handler{syncstate.Path: syncstate.Handler()},
 		},
 		HealthProbeBindAddress: config.CFG.ProbeAddr,
-		LeaderElection:         config.CFG.EnableLeaderElection,
-		LeaderElectionID:       config.CFG.LeaderElectionID,
+		WebhookServer: webhook.NewServer(webhook.Options{
+			Port:    config.CFG.WebhookPort,
+			CertDir: config.CFG.WebhookCertDir,
+		}),
+		LeaderElection:   config.CFG.EnableLeaderElection,
+		LeaderElectionID: config.CFG.LeaderElectionID,
 	})
 	if err != nil {
 		log.Error("unable to start manager")
 		os.Exit(1)
 	}
 
+	// Serve the read-only dashboard next to the metrics when enabled
+	if config.CFG.EnableDashboard {
+		if err := mgr.AddMetricsServerExtraHandler(dashboard.Path, dashboard.NewHandler(mgr.GetClient())); err != nil {
+			log.Error("unable to set up the dashboard")
+			os.Exit(1)
+		}
+		log.Infof("serving the dashboard on %s%s", config.CFG.MetricsAddr, dashboard.Path)
+	}
+
+	// Keep an audit trail of destination mutations in a ConfigMap ring buffer when configured
+	if config.CFG.AuditConfigMapName != "" {
+		sink := audit.NewConfigMapSink(mgr.GetClient(), mgr.GetAPIReader(),
+			config.CFG.AuditConfigMapNamespace, config.CFG.AuditConfigMapName, config.CFG.AuditMaxEntries)
+		if err := mgr.Add(sink); err != nil {
+			log.Error("unable to set up audit ConfigMap sink")
+			os.Exit(1)
+		}
+		audit.RegisterSink(sink)
+		log.Infof("recording audit entries to ConfigMap %s/%s", config.CFG.AuditConfigMapNamespace, config.CFG.AuditConfigMapName)
+	}
+
+	// Serve the rendezvous the agents of clusters without inbound connectivity connect out to
+	if config.CFG.RendezvousAddr != "" {
+		hostKey, err := tunnel.LoadHostKey(config.CFG.RendezvousHostKeyFile)
+		if err != nil {
+			log.Errorf("unable to set up the agent rendezvous: %v", err)
+			os.Exit(1)
+		}
+		server := tunnel.NewServer(hostKey, tunnel.ClusterAuthorizer(mgr.GetAPIReader()))
+		if err := mgr.Add(manager.RunnableFunc(func(ctx context.Context) error {
+			return server.ListenAndServe(ctx, config.CFG.RendezvousAddr)
+		})); err != nil {
+			log.Error("unable to set up the agent rendezvous")
+			os.Exit(1)
+		}
+	}
+
+	// Send notifications of every NamespaceMapping to the controller-wide webhook when configured
+	if config.CFG.NotifyWebhookURL != "" {
+		format, err := notify.ParseFormat(config.CFG.NotifyWebhookFormat)
+		if err != nil {
+			log.Errorf("invalid NOTIFY_WEBHOOK_FORMAT: %v", err)
+			os.Exit(1)
+		}
+		events, err := notify.ParseEvents(config.CFG.NotifyEvents)
+		if err != nil {
+			log.Errorf("invalid NOTIFY_EVENTS: %v", err)
+			os.Exit(1)
+		}
+		notify.RegisterWebhook(notify.Webhook{URL: config.CFG.NotifyWebhookURL, Format: format, Events: events})
+		log.Infof("sending %s notifications to the configured webhook", format)
+	}
+
+	// Exclude source resources carrying one of the configured labels from replication
+	exclusionLabels, err := utils.ParseExclusionLabels(config.CFG.ExclusionLabels)
+	if err != nil {
+		log.Errorf("invalid EXCLUSION_LABELS: %v", err)
+		os.Exit(1)
+	}
+	utils.SetExclusionLabels(exclusionLabels)
+
+	// Fail fast on remote clusters whose API server keeps failing
+	util.RemoteClusters.Configure(config.CFG.RemoteClusterFailureThreshold, config.CFG.RemoteClusterCircuitCooldown)
+
+	// Limit the PVC data syncs of all clusters together when configured
+	if config.CFG.MaxConcurrentDataSyncs > 0 {
+		replication.InitGlobalConcurrencyManager(int64(config.CFG.MaxConcurrentDataSyncs))
+	}
+
 	log.Info("setting up controllers")
 
 	// Set up RemoteCluster controller
@@ -99,14 +237,26 @@ func main() {
 
 	// Set up NamespaceMapping controller
 	if err = (&controllers.NamespaceMappingReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:   mgr.GetClient(),
+		Scheme:   mgr.GetScheme(),
+		Recorder: mgr.GetEventRecorderFor("dr-syncer"),
 	}).SetupWithManager(mgr); err != nil {
 		log.Error("unable to create NamespaceMapping controller")
 		os.Exit(1)
 	}
 	log.Info("configured NamespaceMapping controller")
 
+	// Reject NamespaceMappings conflicting with the other mappings at admission when enabled
+	if config.CFG.EnableWebhooks {
+		if err = (&controllers.NamespaceMappingValidator{
+			Client: mgr.GetClient(),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			log.Error("unable to create NamespaceMapping webhook")
+			os.Exit(1)
+		}
+		log.Infof("serving the NamespaceMapping validating webhook on port %d", config.CFG.WebhookPort)
+	}
+
 	// Set up ClusterMapping controller
 	if err = (&controllers.ClusterMappingReconciler{
 		Client: mgr.GetClient(),
@@ -117,6 +267,26 @@ func main() {
 	}
 	log.Info("configured ClusterMapping controller")
 
+	// Set up DRReadiness controller
+	if err = (&controllers.DRReadinessReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		log.Error("unable to create DRReadiness controller")
+		os.Exit(1)
+	}
+	log.Info("configured DRReadiness controller")
+
+	// Set up NamespaceMappingGenerator controller
+	if err = (&controllers.NamespaceMappingGeneratorReconciler{
+		Client: mgr.GetClient(),
+		Scheme: mgr.GetScheme(),
+	}).SetupWithManager(mgr); err != nil {
+		log.Error("unable to create NamespaceMappingGenerator controller")
+		os.Exit(1)
+	}
+	log.Info("configured NamespaceMappingGenerator controller")
+
 	// Set up health checks
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		log.Error("unable to set up health check")
@@ -130,6 +300,20 @@ func main() {
 	}
 	log.Info("configured readiness check endpoint")
 
+	// Report the controller not ready while it cannot keep up, for alerts and autoscalers
+	thresholds := syncstate.Thresholds{
+		MaxQueueDepth:      config.CFG.ReadinessMaxQueueDepth,
+		MaxOverdueMappings: config.CFG.ReadinessMaxOverdueMappings,
+		MaxWaitingPVCSyncs: config.CFG.ReadinessMaxWaitingDataSyncs,
+	}
+	if thresholds.Enabled() {
+		if err := mgr.AddReadyzCheck("load", syncstate.ReadyCheck(thresholds)); err != nil {
+			log.Error("unable to set up load check")
+			os.Exit(1)
+		}
+		log.Infof("configured load readiness check: %+v", thresholds)
+	}
+
 	log.Info("performing initial agent sync")
 	if err := remotecluster.SyncAllAgents(context.Background(), mgr.GetClient()); err != nil {
 		log.Warnf("initial agent sync encountered issues: %v", err)
@@ -142,4 +326,9 @@ func main() {
 		log.Error("problem running manager")
 		os.Exit(1)
 	}
+
+	// Flush the spans of the syncs that ran until shutdown
+	if err := shutdownTracing(context.Background()); err != nil {
+		log.Warnf("failed to flush traces: %v", err)
+	}
 }
@@ -22,6 +22,9 @@ type Daemon struct {
 	client    kubernetes.Interface
 	config    *rest.Config
 	namespace string
+
+	// isLeader reports whether the agent is the key management leader, nil when keys are not managed
+	isLeader func() bool
 }
 
 // NewDaemon creates a new daemon instance
@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/supporttools/dr-syncer/pkg/agent/volumeapi"
+)
+
+const (
+	// DefaultHealthPort is the default port of the health and metrics endpoint
+	DefaultHealthPort = 9801
+
+	// sampleInterval is how often rsync senders are sampled between scrapes
+	sampleInterval = 5 * time.Second
+)
+
+// SetLeaderStatus sets the function reporting whether this agent is the key management leader
+func (d *Daemon) SetLeaderStatus(isLeader func() bool) {
+	d.isLeader = isLeader
+}
+
+// healthHandler serves /healthz, /readyz, /metrics and the volume API. The agent is live while it serves
+// requests and ready while sshd accepts connections on the SSH port.
+func (d *Daemon) healthHandler(collector *agentCollector) http.Handler {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector, collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := d.ready(r.Context()); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	})
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	volumeapi.NewHandler(volumeapi.DefaultPodsDir).Register(mux)
+	return mux
+}
+
+// ready checks that sshd accepts connections
+func (d *Daemon) ready(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort("localhost", strconv.Itoa(d.sshServer.Port())))
+	if err != nil {
+		return fmt.Errorf("sshd is not accepting connections: %v", err)
+	}
+	return conn.Close()
+}
+
+// StartHealthServer serves the health and metrics endpoint on port until ctx is done
+func (d *Daemon) StartHealthServer(ctx context.Context, port int) error {
+	collector := newAgentCollector("/proc", d.sshServer.AuthorizedKeysFiles(), d.isLeader)
+	server := &http.Server{
+		Addr:              fmt.Sprintf(":%d", port),
+		Handler:           d.healthHandler(collector),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	listener, err := net.Listen("tcp", server.Addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on health port %d: %v", port, err)
+	}
+
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("Health server failed: %v\n", err)
+		}
+	}()
+
+	// Sample rsync senders between scrapes so short transfers are counted
+	go func() {
+		ticker := time.NewTicker(sampleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+				defer cancel()
+				_ = server.Shutdown(shutdownCtx)
+				return
+			case <-ticker.C:
+				collector.sample()
+			}
+		}
+	}()
+
+	fmt.Println("Health and metrics endpoint running on port", port)
+	return nil
+}
@@ -0,0 +1,41 @@
+package daemon
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
+)
+
+func TestHealthHandler(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	port := listener.Addr().(*net.TCPAddr).Port
+
+	sshServer, err := ssh.NewServer(port)
+	require.NoError(t, err)
+	d := NewDaemon(sshServer)
+	handler := d.healthHandler(newAgentCollector(t.TempDir(), nil, nil))
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	assert.Equal(t, http.StatusOK, get("/healthz").Code)
+	assert.Equal(t, http.StatusOK, get("/readyz").Code, "sshd accepts connections")
+
+	metrics := get("/metrics")
+	assert.Equal(t, http.StatusOK, metrics.Code)
+	assert.Contains(t, metrics.Body.String(), "dr_syncer_agent_ssh_sessions 0")
+
+	require.NoError(t, listener.Close())
+	rec := get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	assert.Contains(t, rec.Body.String(), "sshd is not accepting connections")
+}
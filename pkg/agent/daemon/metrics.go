@@ -0,0 +1,243 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	sshSessionsDesc = prometheus.NewDesc(
+		"dr_syncer_agent_ssh_sessions",
+		"Number of active SSH sessions served by the agent",
+		nil, nil,
+	)
+	rsyncSessionsDesc = prometheus.NewDesc(
+		"dr_syncer_agent_rsync_sessions",
+		"Number of active rsync server sessions on the agent",
+		nil, nil,
+	)
+	bytesServedDesc = prometheus.NewDesc(
+		"dr_syncer_agent_bytes_served_total",
+		"Bytes sent by rsync sessions serving PVC data, sampled from the rsync processes",
+		nil, nil,
+	)
+	authorizedKeysDesc = prometheus.NewDesc(
+		"dr_syncer_agent_authorized_keys",
+		"Number of keys authorized to connect to the agent",
+		nil, nil,
+	)
+	leaderDesc = prometheus.NewDesc(
+		"dr_syncer_agent_leader",
+		"Whether the agent is the leader managing the agent SSH keys (1) or not (0)",
+		nil, nil,
+	)
+)
+
+// process is the part of a /proc entry the collector needs
+type process struct {
+	pid  int
+	ppid int
+	args []string
+}
+
+// agentCollector reports the agent's sessions from /proc. sshd and rsync are run by the container
+// entrypoint, not by the agent, so they are observed rather than instrumented.
+type agentCollector struct {
+	procRoot       string
+	authorizedKeys []string
+	isLeader       func() bool
+
+	mu sync.Mutex
+	// served is the total of bytes sent by rsync senders that are gone, written holds the last
+	// sampled count of the running ones
+	served  uint64
+	written map[int]uint64
+}
+
+func newAgentCollector(procRoot string, authorizedKeys []string, isLeader func() bool) *agentCollector {
+	return &agentCollector{
+		procRoot:       procRoot,
+		authorizedKeys: authorizedKeys,
+		isLeader:       isLeader,
+		written:        make(map[int]uint64),
+	}
+}
+
+// Describe implements prometheus.Collector
+func (c *agentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- sshSessionsDesc
+	ch <- rsyncSessionsDesc
+	ch <- bytesServedDesc
+	ch <- authorizedKeysDesc
+	ch <- leaderDesc
+}
+
+// Collect implements prometheus.Collector
+func (c *agentCollector) Collect(ch chan<- prometheus.Metric) {
+	sshSessions, rsyncSessions, served := c.sample()
+
+	leader := 0.0
+	if c.isLeader != nil && c.isLeader() {
+		leader = 1
+	}
+
+	ch <- prometheus.MustNewConstMetric(sshSessionsDesc, prometheus.GaugeValue, float64(sshSessions))
+	ch <- prometheus.MustNewConstMetric(rsyncSessionsDesc, prometheus.GaugeValue, float64(rsyncSessions))
+	ch <- prometheus.MustNewConstMetric(bytesServedDesc, prometheus.CounterValue, float64(served))
+	ch <- prometheus.MustNewConstMetric(authorizedKeysDesc, prometheus.GaugeValue, float64(countAuthorizedKeys(c.authorizedKeys)))
+	ch <- prometheus.MustNewConstMetric(leaderDesc, prometheus.GaugeValue, leader)
+}
+
+// sample scans the running processes, counts the sessions and accumulates the bytes written by rsync senders.
+// Bytes a sender writes after the last sample before it exits are not counted, so sample runs periodically
+// in addition to every scrape.
+func (c *agentCollector) sample() (sshSessions, rsyncSessions int, served uint64) {
+	processes := listProcesses(c.procRoot)
+	rsyncServers := make(map[int]bool)
+	for _, p := range processes {
+		if isRsyncServer(p.args) {
+			rsyncServers[p.pid] = true
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	running := make(map[int]uint64)
+	for _, p := range processes {
+		switch {
+		case isSSHSession(p.args):
+			sshSessions++
+		case rsyncServers[p.pid]:
+			// A receiving rsync forks, its child is part of the same session
+			if !rsyncServers[p.ppid] {
+				rsyncSessions++
+			}
+			if isRsyncSender(p.args) {
+				if written, ok := readWrittenBytes(c.procRoot, p.pid); ok {
+					running[p.pid] = written
+				}
+			}
+		}
+	}
+
+	for pid, written := range c.written {
+		if _, ok := running[pid]; !ok {
+			c.served += written
+		}
+	}
+	c.written = running
+
+	served = c.served
+	for _, written := range running {
+		served += written
+	}
+	return sshSessions, rsyncSessions, served
+}
+
+// listProcesses reads the command line and parent of every process in procRoot
+func listProcesses(procRoot string) []process {
+	entries, err := os.ReadDir(procRoot)
+	if err != nil {
+		return nil
+	}
+
+	var processes []process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		cmdline, err := os.ReadFile(filepath.Join(procRoot, entry.Name(), "cmdline"))
+		if err != nil || len(cmdline) == 0 {
+			continue // Exited or kernel thread
+		}
+		processes = append(processes, process{
+			pid:  pid,
+			ppid: readParentPID(procRoot, entry.Name()),
+			args: strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00"),
+		})
+	}
+	return processes
+}
+
+// readParentPID returns the parent of a process from its stat file, or 0 when it cannot be read
+func readParentPID(procRoot, pid string) int {
+	stat, err := os.ReadFile(filepath.Join(procRoot, pid, "stat"))
+	if err != nil {
+		return 0
+	}
+	// The command name in parentheses may contain spaces, the fields after it are state and ppid
+	fields := strings.Fields(string(stat[bytes.LastIndexByte(stat, ')')+1:]))
+	if len(fields) < 2 {
+		return 0
+	}
+	ppid, _ := strconv.Atoi(fields[1])
+	return ppid
+}
+
+// readWrittenBytes returns the bytes a process has written, which for an rsync sender is the data it served
+func readWrittenBytes(procRoot string, pid int) (uint64, bool) {
+	f, err := os.Open(filepath.Join(procRoot, strconv.Itoa(pid), "io"))
+	if err != nil {
+		return 0, false
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "wchar:"); ok {
+			written, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+			return written, err == nil
+		}
+	}
+	return 0, false
+}
+
+// isSSHSession matches the per-session sshd process, which sshd titles "sshd: user@tty"
+func isSSHSession(args []string) bool {
+	return len(args) > 0 && strings.HasPrefix(args[0], "sshd: ") && strings.Contains(args[0], "@")
+}
+
+// isRsyncServer matches the rsync started by an SSH session or the rsync daemon for a client
+func isRsyncServer(args []string) bool {
+	return len(args) > 1 && filepath.Base(args[0]) == "rsync" && containsArg(args[1:], "--server")
+}
+
+// isRsyncSender matches an rsync server that sends data to the client. Daemon modules of the agent are read-only.
+func isRsyncSender(args []string) bool {
+	return containsArg(args[1:], "--sender") || containsArg(args[1:], "--daemon")
+}
+
+func containsArg(args []string, arg string) bool {
+	for _, a := range args {
+		if a == arg {
+			return true
+		}
+	}
+	return false
+}
+
+// countAuthorizedKeys counts the key lines of authorized_keys files, missing files have no keys
+func countAuthorizedKeys(paths []string) int {
+	count := 0
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		for _, line := range strings.Split(string(content), "\n") {
+			if line = strings.TrimSpace(line); line != "" && !strings.HasPrefix(line, "#") {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,87 @@
+package daemon
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeProcess writes a /proc entry with a command line, parent and written byte count
+func fakeProcess(t *testing.T, root string, pid, ppid int, wchar uint64, args ...string) {
+	dir := filepath.Join(root, strconv.Itoa(pid))
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "cmdline"), []byte(strings.Join(args, "\x00")+"\x00"), 0644))
+	stat := strconv.Itoa(pid) + " (" + filepath.Base(args[0]) + ") S " + strconv.Itoa(ppid) + " 1 1 0 -1"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "stat"), []byte(stat), 0644))
+	io := "rchar: 10\nwchar: " + strconv.FormatUint(wchar, 10) + "\nsyscr: 1\n"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "io"), []byte(io), 0644))
+}
+
+func TestAgentCollectorSessions(t *testing.T) {
+	root := t.TempDir()
+	fakeProcess(t, root, 1, 0, 0, "/usr/sbin/sshd", "-D", "-e")
+	fakeProcess(t, root, 20, 1, 0, "sshd: root [priv]")
+	fakeProcess(t, root, 21, 20, 0, "sshd: root@notty")
+	fakeProcess(t, root, 22, 21, 1000, "rsync", "--server", "--sender", "-vlogDtpre.iLsfxC", ".", "/var/lib/kubelet/pods/x/")
+	fakeProcess(t, root, 30, 1, 0, "sshd: root@notty")
+	fakeProcess(t, root, 31, 30, 0, "rsync", "--server", "-vlogDtpre.iLsfxC", ".", "/data/")
+	fakeProcess(t, root, 32, 31, 0, "rsync", "--server", "-vlogDtpre.iLsfxC", ".", "/data/")
+	require.NoError(t, os.Mkdir(filepath.Join(root, "self"), 0755))
+
+	keys := filepath.Join(t.TempDir(), "authorized_keys")
+	require.NoError(t, os.WriteFile(keys, []byte("# managed by dr-syncer\nssh-rsa AAAA one\n\nssh-rsa AAAA two\n"), 0644))
+
+	leader := true
+	c := newAgentCollector(root, []string{keys, filepath.Join(t.TempDir(), "missing")}, func() bool { return leader })
+
+	expected := `
+# HELP dr_syncer_agent_authorized_keys Number of keys authorized to connect to the agent
+# TYPE dr_syncer_agent_authorized_keys gauge
+dr_syncer_agent_authorized_keys 2
+# HELP dr_syncer_agent_leader Whether the agent is the leader managing the agent SSH keys (1) or not (0)
+# TYPE dr_syncer_agent_leader gauge
+dr_syncer_agent_leader 1
+# HELP dr_syncer_agent_rsync_sessions Number of active rsync server sessions on the agent
+# TYPE dr_syncer_agent_rsync_sessions gauge
+dr_syncer_agent_rsync_sessions 2
+# HELP dr_syncer_agent_ssh_sessions Number of active SSH sessions served by the agent
+# TYPE dr_syncer_agent_ssh_sessions gauge
+dr_syncer_agent_ssh_sessions 2
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected),
+		"dr_syncer_agent_authorized_keys", "dr_syncer_agent_leader", "dr_syncer_agent_rsync_sessions", "dr_syncer_agent_ssh_sessions"))
+}
+
+func TestAgentCollectorBytesServed(t *testing.T) {
+	root := t.TempDir()
+	fakeProcess(t, root, 22, 21, 1000, "rsync", "--server", "--sender", ".", "/mnt/")
+	fakeProcess(t, root, 23, 21, 50, "rsync", "--server", "--daemon", ".")
+	fakeProcess(t, root, 31, 30, 7000, "rsync", "--server", ".", "/data/")
+	c := newAgentCollector(root, nil, nil)
+
+	_, _, served := c.sample()
+	assert.Equal(t, uint64(1050), served, "receivers do not serve data")
+
+	// A sender that exits keeps its last sampled bytes
+	fakeProcess(t, root, 22, 21, 4000, "rsync", "--server", "--sender", ".", "/mnt/")
+	_, _, served = c.sample()
+	assert.Equal(t, uint64(4050), served)
+
+	require.NoError(t, os.RemoveAll(filepath.Join(root, "22")))
+	fakeProcess(t, root, 40, 21, 10, "rsync", "--server", "--sender", ".", "/mnt/")
+	_, _, served = c.sample()
+	assert.Equal(t, uint64(4060), served)
+
+	expected := `
+# HELP dr_syncer_agent_bytes_served_total Bytes sent by rsync sessions serving PVC data, sampled from the rsync processes
+# TYPE dr_syncer_agent_bytes_served_total counter
+dr_syncer_agent_bytes_served_total 4060
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected), "dr_syncer_agent_bytes_served_total"))
+}
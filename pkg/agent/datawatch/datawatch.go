@@ -0,0 +1,326 @@
+// Package datawatch watches the data of the PVCs mounted on the agent's node for file changes and
+// reports them on the PVCs, so the controller replicates the data shortly after it changed instead of
+// waiting for the next scheduled sync.
+//
+// The controller sets WatchAnnotation on the source PVCs of the NamespaceMappings using a data watch.
+// The agent of each node watches the mounts of those PVCs with inotify and sets ChangedAnnotation to
+// the time of the last change, at most once per the interval given in WatchAnnotation.
+package datawatch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/agent/tempod"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// WatchAnnotation is set by the controller on the source PVCs whose data is watched, its value is
+	// the minimum interval between two reported changes
+	WatchAnnotation = "dr-syncer.io/data-watch"
+
+	// ChangedAnnotation is set by the agents on the watched PVCs to the time of the last data change
+	ChangedAnnotation = "dr-syncer.io/data-changed-at"
+
+	// DefaultRescanInterval is how often the pods of the node are listed for watched PVCs
+	DefaultRescanInterval = 30 * time.Second
+
+	// flushInterval is how often pending changes are reported on the PVCs
+	flushInterval = 5 * time.Second
+
+	// maxWatchesPerPVC bounds the inotify watches of a PVC, changes in the directories beyond it are
+	// only replicated by the scheduled syncs
+	maxWatchesPerPVC = 8192
+)
+
+// target is the mount of a watched PVC on the node
+type target struct {
+	mountPath   string
+	minInterval time.Duration
+}
+
+// watchedPVC is the watch state of a PVC
+type watchedPVC struct {
+	target
+	dirs      map[string]bool
+	truncated bool
+
+	// pending is set when the data changed since the last report, at changed
+	pending  bool
+	changed  time.Time
+	reported time.Time
+}
+
+// Watcher watches the data of the PVCs annotated with WatchAnnotation mounted on a node. Its state is
+// only used by the Run goroutine.
+type Watcher struct {
+	client         kubernetes.Interface
+	nodeName       string
+	podsDir        string
+	rescanInterval time.Duration
+	now            func() time.Time
+
+	fsw  *fsnotify.Watcher
+	pvcs map[types.NamespacedName]*watchedPVC
+	dirs map[string]types.NamespacedName
+}
+
+// NewWatcher creates a Watcher for the PVCs mounted on nodeName
+func NewWatcher(client kubernetes.Interface, nodeName string) *Watcher {
+	return &Watcher{
+		client:         client,
+		nodeName:       nodeName,
+		podsDir:        tempod.KubeletVolumesPath,
+		rescanInterval: DefaultRescanInterval,
+		now:            time.Now,
+		pvcs:           make(map[types.NamespacedName]*watchedPVC),
+		dirs:           make(map[string]types.NamespacedName),
+	}
+}
+
+// Run watches the PVC data until ctx is done
+func (w *Watcher) Run(ctx context.Context) error {
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %v", err)
+	}
+	defer fsw.Close()
+	w.fsw = fsw
+
+	log.WithField("node", w.nodeName).Info("Watching PVC data for changes")
+	w.rescan(ctx)
+
+	rescan := time.NewTicker(w.rescanInterval)
+	defer rescan.Stop()
+	flush := time.NewTicker(flushInterval)
+	defer flush.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-rescan.C:
+			w.rescan(ctx)
+		case <-flush.C:
+			w.flush(ctx)
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			w.handleEvent(event)
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			log.WithError(err).Warn("File watcher error, changes may be missed until the next scheduled sync")
+		}
+	}
+}
+
+// watchInterval returns the minimum interval between two reported changes of a PVC, false when its
+// data is not watched
+func watchInterval(pvc *corev1.PersistentVolumeClaim) (time.Duration, bool) {
+	value, ok := pvc.Annotations[WatchAnnotation]
+	if !ok {
+		return 0, false
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval < 0 {
+		log.WithFields(logrus.Fields{
+			"namespace": pvc.Namespace,
+			"pvc":       pvc.Name,
+			"value":     value,
+		}).Warn("Invalid data watch interval on PVC")
+		return 0, false
+	}
+	return interval, true
+}
+
+// scan returns the mounts of the watched PVCs used by the running pods of the node
+func (w *Watcher) scan(ctx context.Context) (map[types.NamespacedName]target, error) {
+	pods, err := w.client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("spec.nodeName=%s", w.nodeName),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %v", err)
+	}
+
+	targets := make(map[types.NamespacedName]target)
+	seen := make(map[types.NamespacedName]bool)
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim == nil {
+				continue
+			}
+			name := types.NamespacedName{Namespace: pod.Namespace, Name: volume.PersistentVolumeClaim.ClaimName}
+			if seen[name] {
+				continue
+			}
+			seen[name] = true
+
+			pvc, err := w.client.CoreV1().PersistentVolumeClaims(name.Namespace).Get(ctx, name.Name, metav1.GetOptions{})
+			if err != nil {
+				log.WithError(err).WithField("pvc", name.String()).Debug("Failed to get PVC")
+				continue
+			}
+			minInterval, watched := watchInterval(pvc)
+			if !watched || pvc.Spec.VolumeName == "" {
+				continue
+			}
+
+			mountPath := filepath.Join(w.podsDir, string(pod.UID), tempod.CSIVolumesSubPath, pvc.Spec.VolumeName, tempod.MountSubPath)
+			if _, err := os.Stat(mountPath); err != nil {
+				log.WithFields(logrus.Fields{
+					"pvc":        name.String(),
+					"mount_path": mountPath,
+				}).Debug("PVC is not mounted through CSI, its data is not watched")
+				continue
+			}
+			targets[name] = target{mountPath: mountPath, minInterval: minInterval}
+		}
+	}
+	return targets, nil
+}
+
+// rescan starts and stops watching PVCs as they are mounted, unmounted, annotated or not
+func (w *Watcher) rescan(ctx context.Context) {
+	targets, err := w.scan(ctx)
+	if err != nil {
+		log.WithError(err).Warn("Failed to scan the node for watched PVCs")
+		return
+	}
+
+	for name, pvc := range w.pvcs {
+		if t, ok := targets[name]; ok && t.mountPath == pvc.mountPath {
+			continue
+		}
+		w.unwatch(name, pvc)
+	}
+	for name, t := range targets {
+		if pvc, ok := w.pvcs[name]; ok {
+			pvc.minInterval = t.minInterval
+			continue
+		}
+		pvc := &watchedPVC{target: t, dirs: make(map[string]bool)}
+		w.pvcs[name] = pvc
+		w.watchTree(name, pvc, t.mountPath)
+		log.WithFields(logrus.Fields{
+			"pvc":          name.String(),
+			"mount_path":   t.mountPath,
+			"directories":  len(pvc.dirs),
+			"min_interval": t.minInterval,
+		}).Info("Watching PVC data")
+	}
+}
+
+// unwatch stops watching a PVC
+func (w *Watcher) unwatch(name types.NamespacedName, pvc *watchedPVC) {
+	for dir := range pvc.dirs {
+		_ = w.fsw.Remove(dir)
+		delete(w.dirs, dir)
+	}
+	delete(w.pvcs, name)
+	log.WithField("pvc", name.String()).Info("Stopped watching PVC data")
+}
+
+// watchTree watches root and the directories below it
+func (w *Watcher) watchTree(name types.NamespacedName, pvc *watchedPVC, root string) {
+	_ = filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() || pvc.dirs[path] {
+			return nil
+		}
+		if len(pvc.dirs) >= maxWatchesPerPVC {
+			if !pvc.truncated {
+				pvc.truncated = true
+				log.WithField("pvc", name.String()).Warnf("PVC has more than %d directories, changes in the others are not watched", maxWatchesPerPVC)
+			}
+			return filepath.SkipAll
+		}
+		if err := w.fsw.Add(path); err != nil {
+			log.WithError(err).WithField("path", path).Warn("Failed to watch directory")
+			return filepath.SkipDir
+		}
+		pvc.dirs[path] = true
+		w.dirs[path] = name
+		return nil
+	})
+}
+
+// handleEvent records a file change in a watched PVC
+func (w *Watcher) handleEvent(event fsnotify.Event) {
+	name, ok := w.dirs[filepath.Dir(event.Name)]
+	if !ok {
+		if name, ok = w.dirs[event.Name]; !ok {
+			return
+		}
+	}
+	pvc := w.pvcs[name]
+
+	// Permission changes alone are not replicated by rsync
+	if event.Op == fsnotify.Chmod {
+		return
+	}
+	if event.Has(fsnotify.Create) {
+		if info, err := os.Lstat(event.Name); err == nil && info.IsDir() {
+			w.watchTree(name, pvc, event.Name)
+		}
+	}
+	if event.Has(fsnotify.Remove) || event.Has(fsnotify.Rename) {
+		if pvc.dirs[event.Name] {
+			_ = w.fsw.Remove(event.Name)
+			delete(pvc.dirs, event.Name)
+			delete(w.dirs, event.Name)
+		}
+	}
+
+	pvc.pending = true
+	pvc.changed = w.now()
+}
+
+// flush reports the pending changes of the PVCs whose minimum interval elapsed since their last report
+func (w *Watcher) flush(ctx context.Context) {
+	now := w.now()
+	for name, pvc := range w.pvcs {
+		if !pvc.pending || now.Sub(pvc.reported) < pvc.minInterval {
+			continue
+		}
+		if err := w.report(ctx, name, pvc.changed); err != nil {
+			log.WithError(err).WithField("pvc", name.String()).Warn("Failed to report PVC data change")
+			continue
+		}
+		pvc.pending = false
+		pvc.reported = now
+		log.WithFields(logrus.Fields{
+			"pvc":     name.String(),
+			"changed": pvc.changed,
+		}).Debug("Reported PVC data change")
+	}
+}
+
+// report sets ChangedAnnotation on a PVC
+func (w *Watcher) report(ctx context.Context, name types.NamespacedName, changed time.Time) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{ChangedAnnotation: changed.UTC().Format(time.RFC3339Nano)},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = w.client.CoreV1().PersistentVolumeClaims(name.Namespace).Patch(ctx, name.Name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
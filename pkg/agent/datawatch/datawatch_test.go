@@ -0,0 +1,129 @@
+package datawatch
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// handleEventsUntil feeds the file events to the watcher until done returns true
+func handleEventsUntil(t *testing.T, w *Watcher, done func() bool) {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for !done() {
+		select {
+		case event := <-w.fsw.Events:
+			w.handleEvent(event)
+		case err := <-w.fsw.Errors:
+			t.Fatalf("file watcher error: %v", err)
+		case <-timeout:
+			t.Fatal("expected file events not received")
+		}
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	ctx := context.Background()
+	podsDir := t.TempDir()
+	mountPath := filepath.Join(podsDir, "uid-1", "volumes", "kubernetes.io~csi", "pv-data", "mount")
+	require.NoError(t, os.MkdirAll(filepath.Join(mountPath, "uploads"), 0755))
+
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "app-0", Namespace: "app", UID: "uid-1"},
+			Spec: corev1.PodSpec{
+				NodeName: "node-1",
+				Volumes: []corev1.Volume{
+					{Name: "data", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"}}},
+					{Name: "logs", VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "logs"}}},
+				},
+			},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app", Annotations: map[string]string{WatchAnnotation: "1m"}},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-data"},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "app"},
+			Spec:       corev1.PersistentVolumeClaimSpec{VolumeName: "pv-logs"},
+		},
+	)
+
+	fsw, err := fsnotify.NewWatcher()
+	require.NoError(t, err)
+	defer fsw.Close()
+
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	w := NewWatcher(client, "node-1")
+	w.podsDir = podsDir
+	w.now = func() time.Time { return now }
+	w.fsw = fsw
+
+	// Only the annotated PVC is watched, with all its directories
+	w.rescan(ctx)
+	data := types.NamespacedName{Namespace: "app", Name: "data"}
+	require.Contains(t, w.pvcs, data)
+	assert.Len(t, w.pvcs, 1)
+	assert.Equal(t, time.Minute, w.pvcs[data].minInterval)
+	assert.Equal(t, map[string]bool{mountPath: true, filepath.Join(mountPath, "uploads"): true}, w.pvcs[data].dirs)
+
+	require.NoError(t, os.WriteFile(filepath.Join(mountPath, "uploads", "photo.jpg"), []byte("jpeg"), 0644))
+	handleEventsUntil(t, w, func() bool { return w.pvcs[data].pending })
+	w.flush(ctx)
+
+	pvc, err := client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-01T12:00:00Z", pvc.Annotations[ChangedAnnotation])
+	assert.False(t, w.pvcs[data].pending)
+
+	// New directories are watched, and changes are reported at most once per minimum interval
+	now = now.Add(10 * time.Second)
+	require.NoError(t, os.Mkdir(filepath.Join(mountPath, "thumbnails"), 0755))
+	handleEventsUntil(t, w, func() bool { return w.pvcs[data].dirs[filepath.Join(mountPath, "thumbnails")] })
+	assert.True(t, w.pvcs[data].pending)
+
+	w.flush(ctx)
+	pvc, err = client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-01T12:00:00Z", pvc.Annotations[ChangedAnnotation])
+
+	now = now.Add(time.Minute)
+	w.flush(ctx)
+	pvc, err = client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "2025-03-01T12:00:10Z", pvc.Annotations[ChangedAnnotation])
+
+	// Removing the annotation stops the watch
+	delete(pvc.Annotations, WatchAnnotation)
+	_, err = client.CoreV1().PersistentVolumeClaims("app").Update(ctx, pvc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	w.rescan(ctx)
+	assert.Empty(t, w.pvcs)
+	assert.Empty(t, w.dirs)
+}
+
+func TestWatchInterval(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	_, watched := watchInterval(pvc)
+	assert.False(t, watched)
+
+	pvc.Annotations = map[string]string{WatchAnnotation: "5m"}
+	interval, watched := watchInterval(pvc)
+	assert.True(t, watched)
+	assert.Equal(t, 5*time.Minute, interval)
+
+	pvc.Annotations[WatchAnnotation] = "soon"
+	_, watched = watchInterval(pvc)
+	assert.False(t, watched)
+}
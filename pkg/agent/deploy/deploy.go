@@ -16,11 +16,16 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	agentssh "github.com/supporttools/dr-syncer/pkg/agent/ssh"
+	"github.com/supporttools/dr-syncer/pkg/agent/tunnel"
 )
 
 const (
 	agentNamespace = "dr-syncer"
 	agentName      = "dr-syncer-agent"
+
+	// healthPort serves the agent's /healthz, /readyz and /metrics endpoints
+	healthPort = 9801
 )
 
 // Deployer handles agent deployment in remote clusters
@@ -53,12 +58,20 @@ func (d *Deployer) Deploy(ctx context.Context, rc *drv1alpha1.RemoteCluster) err
 	if err := d.createOrUpdateRBAC(ctx); err != nil {
 		return fmt.Errorf("failed to create/update RBAC: %v", err)
 	}
+	if err := d.createOrUpdateRole(ctx); err != nil {
+		return fmt.Errorf("failed to create/update role: %v", err)
+	}
 
 	// Create or update DaemonSet
 	if err := d.createOrUpdateDaemonSet(ctx, rc); err != nil {
 		return fmt.Errorf("failed to create/update daemonset: %v", err)
 	}
 
+	// Expose the agent SSH port and advertise the agent endpoints
+	if err := d.UpdateAgentEndpoints(ctx, rc); err != nil {
+		return fmt.Errorf("failed to update agent endpoints: %v", err)
+	}
+
 	// Update status with agent information
 	if err := d.updateAgentStatus(ctx, rc); err != nil {
 		return fmt.Errorf("failed to update agent status: %v", err)
@@ -76,6 +89,14 @@ func (d *Deployer) Cleanup(ctx context.Context) error {
 		return fmt.Errorf("failed to delete daemonset: %v", err)
 	}
 
+	// Delete the Service and EndpointSlices exposing the agents
+	if err := d.deleteService(ctx); err != nil {
+		return fmt.Errorf("failed to delete agent service: %v", err)
+	}
+	if err := d.deleteEndpointSlices(ctx, nil); err != nil {
+		return fmt.Errorf("failed to delete agent endpoint slices: %v", err)
+	}
+
 	// Delete RBAC
 	if err := d.deleteRBAC(ctx); err != nil {
 		return fmt.Errorf("failed to delete RBAC: %v", err)
@@ -169,6 +190,12 @@ func (d *Deployer) createOrUpdateRBAC(ctx context.Context) error {
 				Resources: []string{"persistentvolumes", "persistentvolumeclaims", "pods", "nodes"},
 				Verbs:     []string{"get", "list", "watch"},
 			},
+			{
+				// The agents report PVC data changes as annotations on the watched PVCs
+				APIGroups: []string{""},
+				Resources: []string{"persistentvolumeclaims"},
+				Verbs:     []string{"patch"},
+			},
 		},
 	}
 
@@ -234,6 +261,95 @@ func (d *Deployer) createOrUpdateRBAC(ctx context.Context) error {
 	return d.client.Update(ctx, existingCRB)
 }
 
+// createOrUpdateRole creates or updates the Role letting the agents elect a leader and the leader
+// maintain the shared authorized-keys secret in the agent namespace
+func (d *Deployer) createOrUpdateRole(ctx context.Context) error {
+	labels := map[string]string{
+		"app.kubernetes.io/name":       agentName,
+		"app.kubernetes.io/part-of":    "dr-syncer",
+		"app.kubernetes.io/managed-by": "dr-syncer-controller",
+	}
+
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      agentName,
+			Namespace: agentNamespace,
+			Labels:    labels,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups: []string{"coordination.k8s.io"},
+				Resources: []string{"leases"},
+				Verbs:     []string{"get", "list", "watch", "create", "update", "patch"},
+			},
+			{
+				// The leader merges the key sources into the authorized-keys secret and deletes the expired ones
+				APIGroups: []string{""},
+				Resources: []string{"secrets"},
+				Verbs:     []string{"get", "list", "watch", "create", "update", "delete"},
+			},
+			{
+				// Agents with the Reverse exposure look up the rendezvous port of their node
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				ResourceNames: []string{tunnel.ConfigMapName},
+				Verbs:         []string{"get"},
+			},
+		},
+	}
+
+	existingRole := &rbacv1.Role{}
+	err := d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, existingRole)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err := d.client.Create(ctx, role); err != nil {
+			return err
+		}
+	} else {
+		existingRole.Rules = role.Rules
+		existingRole.Labels = role.Labels
+		if err := d.client.Update(ctx, existingRole); err != nil {
+			return err
+		}
+	}
+
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      agentName,
+			Namespace: agentNamespace,
+			Labels:    labels,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      agentName,
+				Namespace: agentNamespace,
+			},
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     agentName,
+		},
+	}
+
+	existingRB := &rbacv1.RoleBinding{}
+	err = d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, existingRB)
+	if err != nil {
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		return d.client.Create(ctx, rb)
+	}
+
+	existingRB.Subjects = rb.Subjects
+	existingRB.RoleRef = rb.RoleRef
+	existingRB.Labels = rb.Labels
+	return d.client.Update(ctx, existingRB)
+}
+
 // createOrUpdateDaemonSet creates or updates the agent DaemonSet
 func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.RemoteCluster) error {
 	if rc.Spec.PVCSync == nil || rc.Spec.PVCSync.Image == nil {
@@ -241,10 +357,8 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 	}
 
 	// Set default SSH port if not specified
-	sshPort := int32(2222)
-	if rc.Spec.PVCSync.SSH != nil && rc.Spec.PVCSync.SSH.Port > 0 {
-		sshPort = rc.Spec.PVCSync.SSH.Port
-	}
+	sshPort := agentSSHPort(rc)
+	exposure := agentExposure(rc)
 
 	// Get image repository from environment variable or CRD
 	repository := rc.Spec.PVCSync.Image.Repository
@@ -303,6 +417,13 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 		hostNetwork = *rc.Spec.PVCSync.Deployment.HostNetwork
 	}
 
+	// A host port is mapped to the agent in the pod network instead
+	var hostPort int32
+	if exposure.Type == drv1alpha1.AgentExposureHostPort {
+		hostNetwork = false
+		hostPort = exposure.HostPort
+	}
+
 	// Create base volumes and volume mounts
 	defaultMode := int32(420)                // 0644 in octal
 	hostPathType := corev1.HostPathDirectory // Use explicit type instead of nil
@@ -328,26 +449,18 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 		},
 	}
 
-	// Add authorized-keys secret volume if it exists
-	authorizedKeysSecretName := "dr-syncer-authorized-keys"
-	authorizedKeysSecret := &corev1.Secret{}
-	authKeysErr := d.client.Get(ctx, client.ObjectKey{Name: authorizedKeysSecretName, Namespace: agentNamespace}, authorizedKeysSecret)
-	if authKeysErr == nil {
-		// Secret exists, add it as a volume
-		volumes = append(volumes, corev1.Volume{
-			Name: "authorized-keys",
-			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName:  authorizedKeysSecretName,
-					Optional:    &[]bool{true}[0], // Make it optional to maintain backward compatibility
-					DefaultMode: &defaultMode,
-				},
+	// Mount the authorized-keys secret maintained by the leader agent as a directory, so the kubelet
+	// propagates its updates and the agents reinstall the keys without a restart
+	volumes = append(volumes, corev1.Volume{
+		Name: "authorized-keys",
+		VolumeSource: corev1.VolumeSource{
+			Secret: &corev1.SecretVolumeSource{
+				SecretName:  agentssh.AuthorizedKeysSecretName,
+				Optional:    &[]bool{true}[0], // Created by the leader agent after the first start
+				DefaultMode: &defaultMode,
 			},
-		})
-		log.Infof("Found authorized-keys secret, mounting it to the agent pods")
-	} else {
-		log.Infof("Authorized-keys secret not found, using direct file updates for backward compatibility")
-	}
+		},
+	})
 
 	// Add rsync-keys secret volume for pre-provisioned SSH keys (performance optimization)
 	// This secret contains the authorized_keys that allow rsync pods to connect
@@ -383,15 +496,11 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 		},
 	}
 
-	// Add authorized-keys volume mount if the secret exists
-	if authKeysErr == nil {
-		volumeMounts = append(volumeMounts, corev1.VolumeMount{
-			Name:      "authorized-keys",
-			MountPath: "/etc/ssh/keys/authorized_keys",
-			SubPath:   "authorized_keys",
-			ReadOnly:  true,
-		})
-	}
+	volumeMounts = append(volumeMounts, corev1.VolumeMount{
+		Name:      "authorized-keys",
+		MountPath: agentssh.AuthorizedKeysMountPath,
+		ReadOnly:  true,
+	})
 
 	// Add rsync-authorized-keys volume mount if the secret exists
 	// This provides the pre-provisioned authorized_keys for rsync pod connections
@@ -410,6 +519,10 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 			Name:  "SSH_PORT",
 			Value: fmt.Sprintf("%d", sshPort),
 		},
+		{
+			Name:  "HEALTH_PORT",
+			Value: fmt.Sprintf("%d", healthPort),
+		},
 		{
 			Name: "NODE_NAME",
 			ValueFrom: &corev1.EnvVarSource{
@@ -420,6 +533,14 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 		},
 	}
 
+	// Agents with the Reverse exposure connect out to the rendezvous of the controller
+	if exposure.Type == drv1alpha1.AgentExposureReverse && exposure.Rendezvous != nil {
+		env = append(env,
+			corev1.EnvVar{Name: "RENDEZVOUS_ADDRESS", Value: exposure.Rendezvous.Address},
+			corev1.EnvVar{Name: "RENDEZVOUS_HOST_KEY", Value: exposure.Rendezvous.HostKey},
+		)
+	}
+
 	// Add extra environment variables if specified
 	if rc.Spec.PVCSync.Deployment != nil && rc.Spec.PVCSync.Deployment.ExtraEnv != nil {
 		for _, extraEnv := range rc.Spec.PVCSync.Deployment.ExtraEnv {
@@ -451,11 +572,13 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 		securityContext.Privileged = rc.Spec.PVCSync.Deployment.Privileged
 	}
 
-	// Create liveness and readiness probes
+	// Create liveness and readiness probes against the agent's health endpoint,
+	// readiness checks that sshd accepts connections
 	livenessProbe := &corev1.Probe{
 		ProbeHandler: corev1.ProbeHandler{
-			TCPSocket: &corev1.TCPSocketAction{
-				Port: intstr.FromInt(int(sshPort)),
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/healthz",
+				Port: intstr.FromString("health"),
 			},
 		},
 		InitialDelaySeconds: 30,
@@ -464,8 +587,9 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 
 	readinessProbe := &corev1.Probe{
 		ProbeHandler: corev1.ProbeHandler{
-			TCPSocket: &corev1.TCPSocketAction{
-				Port: intstr.FromInt(int(sshPort)),
+			HTTPGet: &corev1.HTTPGetAction{
+				Path: "/readyz",
+				Port: intstr.FromString("health"),
 			},
 		},
 		InitialDelaySeconds: 5,
@@ -485,6 +609,12 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 				Ports: []corev1.ContainerPort{
 					{
 						ContainerPort: sshPort,
+						HostPort:      hostPort,
+						Protocol:      corev1.ProtocolTCP,
+					},
+					{
+						Name:          "health",
+						ContainerPort: healthPort,
 						Protocol:      corev1.ProtocolTCP,
 					},
 				},
@@ -641,6 +771,14 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 		}
 	}
 
+	// Compare the network of the agents, changed by the exposure of the SSH port
+	if existing.Spec.Template.Spec.HostNetwork != ds.Spec.Template.Spec.HostNetwork ||
+		(len(existing.Spec.Template.Spec.Containers) > 0 &&
+			hostPortsChanged(existing.Spec.Template.Spec.Containers[0].Ports, ds.Spec.Template.Spec.Containers[0].Ports, hostNetwork)) {
+		needsUpdate = true
+		log.Infof("Agent network changed (hostNetwork=%t, hostPort=%d), updating DaemonSet", hostNetwork, hostPort)
+	}
+
 	// Compare volumes
 	if !reflect.DeepEqual(existing.Spec.Template.Spec.Volumes, ds.Spec.Template.Spec.Volumes) {
 		needsUpdate = true
@@ -732,6 +870,24 @@ func (d *Deployer) createOrUpdateDaemonSet(ctx context.Context, rc *drv1alpha1.R
 	}
 }
 
+// hostPortsChanged returns true if the host ports of the desired container ports differ from the existing
+// ones. Kubernetes sets the host port of every port on the host network, so they are only compared off it.
+func hostPortsChanged(existing, desired []corev1.ContainerPort, hostNetwork bool) bool {
+	if hostNetwork {
+		return false
+	}
+	existingHostPorts := make(map[int32]int32)
+	for _, port := range existing {
+		existingHostPorts[port.ContainerPort] = port.HostPort
+	}
+	for _, port := range desired {
+		if existingHostPorts[port.ContainerPort] != port.HostPort {
+			return true
+		}
+	}
+	return false
+}
+
 // updateAgentStatus updates the agent status in the RemoteCluster status
 func (d *Deployer) updateAgentStatus(ctx context.Context, rc *drv1alpha1.RemoteCluster) error {
 	// Get DaemonSet to check status
@@ -756,8 +912,7 @@ func (d *Deployer) updateAgentStatus(ctx context.Context, rc *drv1alpha1.RemoteC
 	}
 
 	// Update agent status
-	rc.Status.PVCSync.AgentStatus.TotalNodes = ds.Status.DesiredNumberScheduled
-	rc.Status.PVCSync.AgentStatus.ReadyNodes = ds.Status.NumberReady
+	setRolloutStatus(rc.Status.PVCSync.AgentStatus, ds)
 
 	// Update phase based on agent status
 	if ds.Status.NumberReady == 0 {
@@ -771,6 +926,44 @@ func (d *Deployer) updateAgentStatus(ctx context.Context, rc *drv1alpha1.RemoteC
 	return nil
 }
 
+// UpdateRolloutStatus records the rollout of the agent DaemonSet in the agent status of the RemoteCluster
+func (d *Deployer) UpdateRolloutStatus(ctx context.Context, rc *drv1alpha1.RemoteCluster) error {
+	ds := &appsv1.DaemonSet{}
+	if err := d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, ds); err != nil {
+		return fmt.Errorf("failed to get agent DaemonSet: %w", err)
+	}
+
+	if rc.Status.PVCSync == nil {
+		rc.Status.PVCSync = &drv1alpha1.PVCSyncStatus{}
+	}
+	if rc.Status.PVCSync.AgentStatus == nil {
+		rc.Status.PVCSync.AgentStatus = &drv1alpha1.PVCSyncAgentStatus{
+			NodeStatuses: make(map[string]drv1alpha1.PVCSyncNodeStatus),
+		}
+	}
+	setRolloutStatus(rc.Status.PVCSync.AgentStatus, ds)
+	return nil
+}
+
+// setRolloutStatus copies the node counts and the image of the agent DaemonSet into the agent status. The
+// rollout is complete once the DaemonSet controller observed the current spec and every node runs an
+// available agent of it.
+func setRolloutStatus(status *drv1alpha1.PVCSyncAgentStatus, ds *appsv1.DaemonSet) {
+	status.TotalNodes = ds.Status.DesiredNumberScheduled
+	status.ReadyNodes = ds.Status.NumberReady
+	status.UpdatedNodes = ds.Status.UpdatedNumberScheduled
+	status.AvailableNodes = ds.Status.NumberAvailable
+
+	status.Image = ""
+	if containers := ds.Spec.Template.Spec.Containers; len(containers) > 0 {
+		status.Image = containers[0].Image
+	}
+
+	status.RolloutComplete = ds.Status.ObservedGeneration >= ds.Generation &&
+		ds.Status.UpdatedNumberScheduled >= ds.Status.DesiredNumberScheduled &&
+		ds.Status.NumberAvailable >= ds.Status.DesiredNumberScheduled
+}
+
 // deleteDaemonSet deletes the agent DaemonSet
 func (d *Deployer) deleteDaemonSet(ctx context.Context) error {
 	log.Infof("Deleting DaemonSet %s in namespace %s", agentName, agentNamespace)
@@ -786,6 +979,26 @@ func (d *Deployer) deleteDaemonSet(ctx context.Context) error {
 // deleteRBAC deletes the agent RBAC resources
 func (d *Deployer) deleteRBAC(ctx context.Context) error {
 	log.Info("Deleting RBAC resources")
+	// Delete RoleBinding and Role
+	rb := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      agentName,
+			Namespace: agentNamespace,
+		},
+	}
+	if err := client.IgnoreNotFound(d.client.Delete(ctx, rb)); err != nil {
+		return err
+	}
+	role := &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      agentName,
+			Namespace: agentNamespace,
+		},
+	}
+	if err := client.IgnoreNotFound(d.client.Delete(ctx, role)); err != nil {
+		return err
+	}
+
 	// Delete ClusterRoleBinding
 	crb := &rbacv1.ClusterRoleBinding{
 		ObjectMeta: metav1.ObjectMeta{
@@ -1,10 +1,18 @@
 package deploy
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestConstants(t *testing.T) {
@@ -639,3 +647,72 @@ func TestConvertEnvToMap_FullAgentConfig(t *testing.T) {
 	assert.Equal(t, "configMapKeyRef:agent-config:settings", result["APP_CONFIG"])
 	assert.Equal(t, "secretKeyRef:ssh-keys:id_rsa", result["SSH_PRIVATE_KEY"])
 }
+
+func TestCreateOrUpdateDaemonSetProbes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	d := NewDeployer(fake.NewClientBuilder().WithScheme(scheme).Build())
+	rc := &drv1alpha1.RemoteCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec: drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{
+			Image: &drv1alpha1.PVCSyncImage{Repository: "supporttools/dr-syncer-agent", Tag: "v1"},
+		}},
+	}
+	require.NoError(t, d.createOrUpdateDaemonSet(context.Background(), rc))
+
+	ds := &appsv1.DaemonSet{}
+	require.NoError(t, d.client.Get(context.Background(), client.ObjectKey{Name: agentName, Namespace: agentNamespace}, ds))
+	container := ds.Spec.Template.Spec.Containers[0]
+
+	assert.Contains(t, container.Ports, corev1.ContainerPort{Name: "health", ContainerPort: healthPort, Protocol: corev1.ProtocolTCP})
+	assert.Equal(t, "/healthz", container.LivenessProbe.HTTPGet.Path)
+	assert.Equal(t, "/readyz", container.ReadinessProbe.HTTPGet.Path)
+	assert.Equal(t, "health", container.ReadinessProbe.HTTPGet.Port.String())
+	assert.Equal(t, "9801", convertEnvToMap(container.Env)["HEALTH_PORT"])
+}
+
+func TestUpdateRolloutStatus(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: agentName, Namespace: agentNamespace, Generation: 2},
+		Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "agent", Image: "supporttools/dr-syncer-agent:v2"}},
+		}}},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration:     2,
+			DesiredNumberScheduled: 3,
+			NumberReady:            3,
+			UpdatedNumberScheduled: 1,
+			NumberAvailable:        3,
+		},
+	}
+	d := NewDeployer(fake.NewClientBuilder().WithScheme(scheme).WithObjects(ds).Build())
+	rc := &drv1alpha1.RemoteCluster{ObjectMeta: metav1.ObjectMeta{Name: "prod"}}
+
+	require.NoError(t, d.UpdateRolloutStatus(context.Background(), rc))
+	agent := rc.Status.PVCSync.AgentStatus
+	assert.Equal(t, "supporttools/dr-syncer-agent:v2", agent.Image)
+	assert.Equal(t, int32(3), agent.TotalNodes)
+	assert.Equal(t, int32(1), agent.UpdatedNodes)
+	assert.Equal(t, int32(3), agent.AvailableNodes)
+	assert.False(t, agent.RolloutComplete)
+
+	ds.Status.UpdatedNumberScheduled = 3
+	setRolloutStatus(agent, ds)
+	assert.True(t, agent.RolloutComplete)
+
+	// A spec the DaemonSet controller did not observe yet is still rolling out
+	ds.Generation = 3
+	setRolloutStatus(agent, ds)
+	assert.False(t, agent.RolloutComplete)
+}
+
+func TestUpdateRolloutStatus_NotDeployed(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	d := NewDeployer(fake.NewClientBuilder().WithScheme(scheme).Build())
+
+	assert.Error(t, d.UpdateRolloutStatus(context.Background(), &drv1alpha1.RemoteCluster{}))
+}
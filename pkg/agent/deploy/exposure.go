@@ -0,0 +1,584 @@
+package deploy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/tunnel"
+)
+
+const (
+	// defaultSSHPort is the agent SSH port when the RemoteCluster does not set one
+	defaultSSHPort = int32(2222)
+
+	// managedBy is the value of the managed-by labels of the agent components
+	managedBy = "dr-syncer-controller"
+)
+
+// agentSSHPort returns the SSH port the agents listen on
+func agentSSHPort(rc *drv1alpha1.RemoteCluster) int32 {
+	if rc.Spec.PVCSync != nil && rc.Spec.PVCSync.SSH != nil && rc.Spec.PVCSync.SSH.Port > 0 {
+		return rc.Spec.PVCSync.SSH.Port
+	}
+	return defaultSSHPort
+}
+
+// agentExposure returns the exposure of the agent SSH port with its defaults applied
+func agentExposure(rc *drv1alpha1.RemoteCluster) drv1alpha1.AgentExposure {
+	exposure := drv1alpha1.AgentExposure{}
+	if rc.Spec.PVCSync != nil && rc.Spec.PVCSync.SSH != nil && rc.Spec.PVCSync.SSH.Exposure != nil {
+		exposure = *rc.Spec.PVCSync.SSH.Exposure
+	}
+	if exposure.Type == "" {
+		exposure.Type = drv1alpha1.AgentExposureHostNetwork
+	}
+	if exposure.AddressType == "" {
+		exposure.AddressType = drv1alpha1.NodeAddressExternalIP
+	}
+	if exposure.Type == drv1alpha1.AgentExposureHostPort && exposure.HostPort == 0 {
+		exposure.HostPort = agentSSHPort(rc)
+	}
+	return exposure
+}
+
+// usesService returns true if the exposure type reaches the agents through the agent Service
+func usesService(exposureType drv1alpha1.AgentExposureType) bool {
+	return exposureType == drv1alpha1.AgentExposureNodePort || exposureType == drv1alpha1.AgentExposureLoadBalancer
+}
+
+// nodePortName returns the name of the load balancer port and EndpointSlice suffix of a node, stable
+// across reconciles and short enough for a port name
+func nodePortName(nodeName string) string {
+	sum := sha256.Sum256([]byte(nodeName))
+	return "ssh-" + hex.EncodeToString(sum[:])[:10]
+}
+
+// assignNodePorts assigns each node a port, starting at base. Nodes keep the port they were assigned
+// before so connections in flight are not moved to another node.
+func assignNodePorts(assigned map[string]int32, nodes []string, base int32) map[string]int32 {
+	used := make(map[int32]bool)
+	for _, node := range nodes {
+		if port, ok := assigned[node]; ok {
+			used[port] = true
+		}
+	}
+
+	sorted := append([]string(nil), nodes...)
+	sort.Strings(sorted)
+
+	ports := make(map[string]int32, len(sorted))
+	next := base
+	for _, node := range sorted {
+		port, ok := assigned[node]
+		if !ok {
+			for used[next] {
+				next++
+			}
+			port = next
+			used[port] = true
+		}
+		ports[node] = port
+	}
+	return ports
+}
+
+// loadBalancerPorts assigns each node a port of the load balancer, starting at base. Nodes keep the
+// port they were assigned by existing so connections in flight are not moved to another node.
+func loadBalancerPorts(existing []corev1.ServicePort, nodes []string, base, targetPort int32) []corev1.ServicePort {
+	byName := make(map[string]int32)
+	for _, port := range existing {
+		byName[port.Name] = port.Port
+	}
+	assigned := make(map[string]int32)
+	for _, node := range nodes {
+		if port, ok := byName[nodePortName(node)]; ok {
+			assigned[node] = port
+		}
+	}
+
+	sorted := append([]string(nil), nodes...)
+	sort.Strings(sorted)
+
+	nodePorts := assignNodePorts(assigned, nodes, base)
+	ports := make([]corev1.ServicePort, 0, len(sorted))
+	for _, node := range sorted {
+		ports = append(ports, corev1.ServicePort{
+			Name:       nodePortName(node),
+			Protocol:   corev1.ProtocolTCP,
+			Port:       nodePorts[node],
+			TargetPort: intstr.FromInt32(targetPort),
+		})
+	}
+	return ports
+}
+
+// agentPodsByNode returns the running agent pods with an IP address by node name
+func (d *Deployer) agentPodsByNode(ctx context.Context) (map[string]*corev1.Pod, error) {
+	pods := &corev1.PodList{}
+	if err := d.client.List(ctx, pods, client.InNamespace(agentNamespace), client.MatchingLabels{"app": agentName}); err != nil {
+		return nil, fmt.Errorf("failed to list agent pods: %v", err)
+	}
+
+	byNode := make(map[string]*corev1.Pod)
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if pod.Spec.NodeName == "" || pod.Status.PodIP == "" || pod.Status.Phase != corev1.PodRunning ||
+			pod.DeletionTimestamp != nil {
+			continue
+		}
+		byNode[pod.Spec.NodeName] = pod
+	}
+	return byNode, nil
+}
+
+// UpdateAgentEndpoints exposes the agent SSH port as configured by the RemoteCluster and records the
+// address and port of the agent of each node in its status, where the controller resolves them from
+func (d *Deployer) UpdateAgentEndpoints(ctx context.Context, rc *drv1alpha1.RemoteCluster) error {
+	exposure := agentExposure(rc)
+	sshPort := agentSSHPort(rc)
+
+	pods, err := d.agentPodsByNode(ctx)
+	if err != nil {
+		return err
+	}
+	nodes := make([]string, 0, len(pods))
+	for node := range pods {
+		nodes = append(nodes, node)
+	}
+
+	if exposure.Type == drv1alpha1.AgentExposureReverse && exposure.Rendezvous == nil {
+		return fmt.Errorf("the Reverse agent exposure requires pvcSync.ssh.exposure.rendezvous")
+	}
+
+	var service *corev1.Service
+	if usesService(exposure.Type) {
+		if service, err = d.createOrUpdateService(ctx, exposure, sshPort, nodes); err != nil {
+			return fmt.Errorf("failed to create/update agent service: %v", err)
+		}
+	} else if err := d.deleteService(ctx); err != nil {
+		return fmt.Errorf("failed to delete agent service: %v", err)
+	}
+
+	if exposure.Type == drv1alpha1.AgentExposureLoadBalancer {
+		if err := d.syncEndpointSlices(ctx, pods, sshPort); err != nil {
+			return fmt.Errorf("failed to update agent endpoint slices: %v", err)
+		}
+	} else if err := d.deleteEndpointSlices(ctx, nil); err != nil {
+		return fmt.Errorf("failed to delete agent endpoint slices: %v", err)
+	}
+
+	var tunnelPorts map[string]int32
+	if exposure.Type == drv1alpha1.AgentExposureReverse {
+		if tunnelPorts, err = d.syncTunnelPorts(ctx, exposure.Rendezvous, nodes); err != nil {
+			return fmt.Errorf("failed to update agent tunnel ports: %v", err)
+		}
+	} else if err := d.deleteTunnelPorts(ctx); err != nil {
+		return fmt.Errorf("failed to delete agent tunnel ports: %v", err)
+	}
+
+	endpoints := make(map[string]drv1alpha1.AgentEndpoint)
+	for _, node := range nodes {
+		if exposure.Type == drv1alpha1.AgentExposureReverse {
+			if port, ok := tunnelPorts[node]; ok {
+				endpoints[node] = drv1alpha1.AgentEndpoint{Address: rendezvousHost(exposure.Rendezvous), Port: port}
+			}
+			continue
+		}
+		endpoint, ok, err := d.agentEndpoint(ctx, exposure, sshPort, service, node)
+		if err != nil {
+			return err
+		}
+		if ok {
+			endpoints[node] = endpoint
+		}
+	}
+
+	if rc.Status.PVCSync == nil {
+		rc.Status.PVCSync = &drv1alpha1.PVCSyncStatus{}
+	}
+	if rc.Status.PVCSync.AgentStatus == nil {
+		rc.Status.PVCSync.AgentStatus = &drv1alpha1.PVCSyncAgentStatus{
+			NodeStatuses: make(map[string]drv1alpha1.PVCSyncNodeStatus),
+		}
+	}
+	if len(endpoints) == 0 {
+		endpoints = nil
+	}
+	rc.Status.PVCSync.AgentStatus.Endpoints = endpoints
+	return nil
+}
+
+// agentEndpoint returns the advertised address and port of the agent of a node, false while the
+// exposure has not assigned one yet, such as a load balancer without an ingress address
+func (d *Deployer) agentEndpoint(ctx context.Context, exposure drv1alpha1.AgentExposure, sshPort int32,
+	service *corev1.Service, nodeName string) (drv1alpha1.AgentEndpoint, bool, error) {
+	if exposure.Type == drv1alpha1.AgentExposureLoadBalancer {
+		var address string
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				address = ingress.IP
+				break
+			}
+			if ingress.Hostname != "" {
+				address = ingress.Hostname
+				break
+			}
+		}
+		if address == "" {
+			return drv1alpha1.AgentEndpoint{}, false, nil
+		}
+		for _, port := range service.Spec.Ports {
+			if port.Name == nodePortName(nodeName) {
+				return drv1alpha1.AgentEndpoint{Address: address, Port: port.Port}, true, nil
+			}
+		}
+		return drv1alpha1.AgentEndpoint{}, false, nil
+	}
+
+	port := sshPort
+	switch exposure.Type {
+	case drv1alpha1.AgentExposureHostPort:
+		port = exposure.HostPort
+	case drv1alpha1.AgentExposureNodePort:
+		if len(service.Spec.Ports) == 0 || service.Spec.Ports[0].NodePort == 0 {
+			return drv1alpha1.AgentEndpoint{}, false, nil
+		}
+		port = service.Spec.Ports[0].NodePort
+	}
+
+	node := &corev1.Node{}
+	if err := d.client.Get(ctx, client.ObjectKey{Name: nodeName}, node); err != nil {
+		return drv1alpha1.AgentEndpoint{}, false, fmt.Errorf("failed to get node %s: %v", nodeName, err)
+	}
+	address := nodeAddress(node, exposure.AddressType)
+	if address == "" {
+		log.Warnf("Node %s has no %s address, its agent endpoint is not advertised", nodeName, exposure.AddressType)
+		return drv1alpha1.AgentEndpoint{}, false, nil
+	}
+	return drv1alpha1.AgentEndpoint{Address: address, Port: port}, true, nil
+}
+
+// nodeAddress returns the address of a node of the given type. ExternalIP falls back to the internal IP
+// for nodes without an external one.
+func nodeAddress(node *corev1.Node, addressType drv1alpha1.NodeAddressType) string {
+	var external, internal string
+	for _, addr := range node.Status.Addresses {
+		switch {
+		case addr.Type == corev1.NodeExternalIP && external == "":
+			external = addr.Address
+		case addr.Type == corev1.NodeInternalIP && internal == "":
+			internal = addr.Address
+		}
+	}
+	if addressType == drv1alpha1.NodeAddressExternalIP && external != "" {
+		return external
+	}
+	return internal
+}
+
+// createOrUpdateService creates or updates the agent Service. The NodePort Service selects the agents
+// and keeps traffic on the node connected to, the LoadBalancer Service has no selector and a port per
+// node routed by the agent EndpointSlices.
+func (d *Deployer) createOrUpdateService(ctx context.Context, exposure drv1alpha1.AgentExposure, sshPort int32,
+	nodes []string) (*corev1.Service, error) {
+	existing := &corev1.Service{}
+	err := d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, existing)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	found := err == nil
+
+	spec := corev1.ServiceSpec{}
+	if exposure.Type == drv1alpha1.AgentExposureNodePort {
+		spec.Type = corev1.ServiceTypeNodePort
+		spec.Selector = map[string]string{"app": agentName}
+		spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyLocal
+		port := corev1.ServicePort{
+			Name:       "ssh",
+			Protocol:   corev1.ProtocolTCP,
+			Port:       sshPort,
+			TargetPort: intstr.FromInt32(sshPort),
+			NodePort:   exposure.NodePort,
+		}
+		// Keep the node port allocated by Kubernetes
+		if port.NodePort == 0 && found && existing.Spec.Type == corev1.ServiceTypeNodePort && len(existing.Spec.Ports) == 1 {
+			port.NodePort = existing.Spec.Ports[0].NodePort
+		}
+		spec.Ports = []corev1.ServicePort{port}
+	} else {
+		spec.Type = corev1.ServiceTypeLoadBalancer
+		spec.ExternalTrafficPolicy = corev1.ServiceExternalTrafficPolicyCluster
+		spec.Ports = loadBalancerPorts(existing.Spec.Ports, nodes, sshPort, sshPort)
+		// A Service needs a port, nodes are given theirs once their agent runs
+		if len(spec.Ports) == 0 {
+			spec.Ports = []corev1.ServicePort{{Name: "ssh", Protocol: corev1.ProtocolTCP, Port: sshPort, TargetPort: intstr.FromInt32(sshPort)}}
+		}
+	}
+
+	labels := map[string]string{
+		"app":                          agentName,
+		"app.kubernetes.io/name":       agentName,
+		"app.kubernetes.io/part-of":    "dr-syncer",
+		"app.kubernetes.io/managed-by": managedBy,
+	}
+
+	if !found {
+		service := &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        agentName,
+				Namespace:   agentNamespace,
+				Labels:      labels,
+				Annotations: exposure.ServiceAnnotations,
+			},
+			Spec: spec,
+		}
+		log.Infof("Creating %s Service %s in namespace %s", spec.Type, agentName, agentNamespace)
+		if err := d.client.Create(ctx, service); err != nil {
+			return nil, err
+		}
+		return service, nil
+	}
+
+	annotations := existing.Annotations
+	if len(exposure.ServiceAnnotations) > 0 {
+		if annotations == nil {
+			annotations = make(map[string]string)
+		}
+		for k, v := range exposure.ServiceAnnotations {
+			annotations[k] = v
+		}
+	}
+
+	if existing.Spec.Type == spec.Type && reflect.DeepEqual(existing.Spec.Selector, spec.Selector) &&
+		existing.Spec.ExternalTrafficPolicy == spec.ExternalTrafficPolicy && servicePortsEqual(existing.Spec.Ports, spec.Ports) &&
+		reflect.DeepEqual(existing.Annotations, annotations) {
+		return existing, nil
+	}
+
+	log.Infof("Updating %s Service %s in namespace %s", spec.Type, agentName, agentNamespace)
+	existing.Labels = labels
+	existing.Annotations = annotations
+	existing.Spec.Type = spec.Type
+	existing.Spec.Selector = spec.Selector
+	existing.Spec.ExternalTrafficPolicy = spec.ExternalTrafficPolicy
+	existing.Spec.Ports = spec.Ports
+	if err := d.client.Update(ctx, existing); err != nil {
+		return nil, err
+	}
+	return existing, nil
+}
+
+// servicePortsEqual compares the ports of a Service, ignoring node ports allocated by Kubernetes
+func servicePortsEqual(existing, desired []corev1.ServicePort) bool {
+	if len(existing) != len(desired) {
+		return false
+	}
+	for i := range desired {
+		e, d := existing[i], desired[i]
+		if e.Name != d.Name || e.Port != d.Port || e.TargetPort != d.TargetPort ||
+			(d.NodePort != 0 && e.NodePort != d.NodePort) {
+			return false
+		}
+	}
+	return true
+}
+
+// syncEndpointSlices routes the load balancer port of each node to the agent running on it and removes
+// the EndpointSlices of nodes without an agent
+func (d *Deployer) syncEndpointSlices(ctx context.Context, pods map[string]*corev1.Pod, sshPort int32) error {
+	keep := make(map[string]bool)
+	for node, pod := range pods {
+		slice := agentEndpointSlice(node, pod, sshPort)
+		keep[slice.Name] = true
+
+		existing := &discoveryv1.EndpointSlice{}
+		err := d.client.Get(ctx, client.ObjectKeyFromObject(slice), existing)
+		if client.IgnoreNotFound(err) != nil {
+			return err
+		}
+		if err != nil {
+			if err := d.client.Create(ctx, slice); err != nil {
+				return err
+			}
+			continue
+		}
+		if existing.AddressType == slice.AddressType && reflect.DeepEqual(existing.Endpoints, slice.Endpoints) &&
+			reflect.DeepEqual(existing.Ports, slice.Ports) {
+			continue
+		}
+		// The address type of an EndpointSlice cannot change
+		if existing.AddressType != slice.AddressType {
+			if err := client.IgnoreNotFound(d.client.Delete(ctx, existing)); err != nil {
+				return err
+			}
+			if err := d.client.Create(ctx, slice); err != nil {
+				return err
+			}
+			continue
+		}
+		existing.Endpoints = slice.Endpoints
+		existing.Ports = slice.Ports
+		if err := d.client.Update(ctx, existing); err != nil {
+			return err
+		}
+	}
+	return d.deleteEndpointSlices(ctx, keep)
+}
+
+// agentEndpointSlice returns the EndpointSlice routing the load balancer port of a node to its agent pod
+func agentEndpointSlice(nodeName string, pod *corev1.Pod, sshPort int32) *discoveryv1.EndpointSlice {
+	portName := nodePortName(nodeName)
+	addressType := discoveryv1.AddressTypeIPv4
+	if ip := net.ParseIP(pod.Status.PodIP); ip != nil && ip.To4() == nil {
+		addressType = discoveryv1.AddressTypeIPv6
+	}
+	ready := true
+	protocol := corev1.ProtocolTCP
+	node := nodeName
+
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      agentName + "-" + portName,
+			Namespace: agentNamespace,
+			Labels: map[string]string{
+				"app":                        agentName,
+				discoveryv1.LabelServiceName: agentName,
+				discoveryv1.LabelManagedBy:   managedBy,
+			},
+		},
+		AddressType: addressType,
+		Endpoints: []discoveryv1.Endpoint{{
+			Addresses:  []string{pod.Status.PodIP},
+			Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+			NodeName:   &node,
+		}},
+		Ports: []discoveryv1.EndpointPort{{
+			Name:     &portName,
+			Protocol: &protocol,
+			Port:     &sshPort,
+		}},
+	}
+}
+
+// deleteEndpointSlices deletes the agent EndpointSlices not in keep
+func (d *Deployer) deleteEndpointSlices(ctx context.Context, keep map[string]bool) error {
+	slices := &discoveryv1.EndpointSliceList{}
+	if err := d.client.List(ctx, slices, client.InNamespace(agentNamespace), client.MatchingLabels{
+		discoveryv1.LabelServiceName: agentName,
+		discoveryv1.LabelManagedBy:   managedBy,
+	}); err != nil {
+		return err
+	}
+	for i := range slices.Items {
+		if keep[slices.Items[i].Name] {
+			continue
+		}
+		log.Infof("Deleting EndpointSlice %s in namespace %s", slices.Items[i].Name, agentNamespace)
+		if err := client.IgnoreNotFound(d.client.Delete(ctx, &slices.Items[i])); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deleteService deletes the agent Service
+func (d *Deployer) deleteService(ctx context.Context) error {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      agentName,
+			Namespace: agentNamespace,
+		},
+	}
+	return client.IgnoreNotFound(d.client.Delete(ctx, service))
+}
+
+// rendezvousHost returns the host the destination rsync pods reach the ports forwarded by a rendezvous on
+func rendezvousHost(rendezvous *drv1alpha1.AgentRendezvous) string {
+	if rendezvous.AdvertisedAddress != "" {
+		return rendezvous.AdvertisedAddress
+	}
+	if host, _, err := net.SplitHostPort(rendezvous.Address); err == nil {
+		return host
+	}
+	return rendezvous.Address
+}
+
+// syncTunnelPorts assigns each node a port of the rendezvous range and publishes the assignment in the
+// tunnel ConfigMap, where the agents look up the port they have forwarded. Nodes beyond the range are
+// not assigned a port.
+func (d *Deployer) syncTunnelPorts(ctx context.Context, rendezvous *drv1alpha1.AgentRendezvous, nodes []string) (map[string]int32, error) {
+	ports := tunnel.Ports(rendezvous)
+
+	existing := &corev1.ConfigMap{}
+	err := d.client.Get(ctx, client.ObjectKey{Name: tunnel.ConfigMapName, Namespace: agentNamespace}, existing)
+	if client.IgnoreNotFound(err) != nil {
+		return nil, err
+	}
+	found := err == nil
+
+	// Ports outside a changed range are assigned again
+	assigned := make(map[string]int32)
+	for node, value := range existing.Data {
+		if port, err := strconv.ParseInt(value, 10, 32); err == nil && ports.Contains(uint32(port)) {
+			assigned[node] = int32(port)
+		}
+	}
+
+	nodePorts := assignNodePorts(assigned, nodes, ports.Start)
+	data := make(map[string]string, len(nodePorts))
+	for node, port := range nodePorts {
+		if !ports.Contains(uint32(port)) {
+			log.Warnf("No rendezvous port left for node %s, increase pvcSync.ssh.exposure.rendezvous.portRangeSize", node)
+			delete(nodePorts, node)
+			continue
+		}
+		data[node] = strconv.Itoa(int(port))
+	}
+
+	if !found {
+		cm := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      tunnel.ConfigMapName,
+				Namespace: agentNamespace,
+				Labels: map[string]string{
+					"app":                          agentName,
+					"app.kubernetes.io/name":       agentName,
+					"app.kubernetes.io/part-of":    "dr-syncer",
+					"app.kubernetes.io/managed-by": managedBy,
+				},
+			},
+			Data: data,
+		}
+		log.Infof("Creating ConfigMap %s in namespace %s", tunnel.ConfigMapName, agentNamespace)
+		return nodePorts, d.client.Create(ctx, cm)
+	}
+	if reflect.DeepEqual(existing.Data, data) || (len(existing.Data) == 0 && len(data) == 0) {
+		return nodePorts, nil
+	}
+	existing.Data = data
+	return nodePorts, d.client.Update(ctx, existing)
+}
+
+// deleteTunnelPorts deletes the tunnel ConfigMap
+func (d *Deployer) deleteTunnelPorts(ctx context.Context) error {
+	cm := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      tunnel.ConfigMapName,
+			Namespace: agentNamespace,
+		},
+	}
+	return client.IgnoreNotFound(d.client.Delete(ctx, cm))
+}
@@ -0,0 +1,231 @@
+package deploy
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/tunnel"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func agentNode(name, internalIP, externalIP string) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	node.Status.Addresses = []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: internalIP}}
+	if externalIP != "" {
+		node.Status.Addresses = append(node.Status.Addresses, corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: externalIP})
+	}
+	return node
+}
+
+func agentPod(name, nodeName, podIP string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: agentNamespace, Labels: map[string]string{"app": agentName}},
+		Spec:       corev1.PodSpec{NodeName: nodeName},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning, PodIP: podIP},
+	}
+}
+
+func exposureTestDeployer(t *testing.T, objs ...client.Object) *Deployer {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	require.NoError(t, discoveryv1.AddToScheme(scheme))
+	objs = append(objs,
+		agentNode("node-a", "10.0.0.1", "203.0.113.1"), agentNode("node-b", "10.0.0.2", ""),
+		agentPod("agent-a", "node-a", "10.244.0.5"), agentPod("agent-b", "node-b", "10.244.1.7"))
+	return NewDeployer(fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).WithStatusSubresource(&corev1.Service{}).Build())
+}
+
+func exposedCluster(exposure *drv1alpha1.AgentExposure) *drv1alpha1.RemoteCluster {
+	return &drv1alpha1.RemoteCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+		Spec: drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{
+			Image: &drv1alpha1.PVCSyncImage{Repository: "supporttools/dr-syncer-agent", Tag: "v1"},
+			SSH:   &drv1alpha1.PVCSyncSSH{Port: 2222, Exposure: exposure},
+		}},
+	}
+}
+
+func TestUpdateAgentEndpoints_HostNetwork(t *testing.T) {
+	d := exposureTestDeployer(t)
+	rc := exposedCluster(nil)
+	require.NoError(t, d.UpdateAgentEndpoints(context.Background(), rc))
+
+	assert.Equal(t, map[string]drv1alpha1.AgentEndpoint{
+		"node-a": {Address: "203.0.113.1", Port: 2222},
+		"node-b": {Address: "10.0.0.2", Port: 2222},
+	}, rc.Status.PVCSync.AgentStatus.Endpoints, "external addresses are preferred, internal ones used without")
+
+	err := d.client.Get(context.Background(), client.ObjectKey{Name: agentName, Namespace: agentNamespace}, &corev1.Service{})
+	assert.True(t, apierrors.IsNotFound(err), "no Service is created on the host network")
+}
+
+func TestUpdateAgentEndpoints_HostPort(t *testing.T) {
+	ctx := context.Background()
+	d := exposureTestDeployer(t)
+	rc := exposedCluster(&drv1alpha1.AgentExposure{Type: drv1alpha1.AgentExposureHostPort, HostPort: 32222,
+		AddressType: drv1alpha1.NodeAddressInternalIP})
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+	assert.Equal(t, drv1alpha1.AgentEndpoint{Address: "10.0.0.1", Port: 32222}, rc.Status.PVCSync.AgentStatus.Endpoints["node-a"])
+
+	require.NoError(t, d.createOrUpdateDaemonSet(ctx, rc))
+	ds := &appsv1.DaemonSet{}
+	require.NoError(t, d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, ds))
+	assert.False(t, ds.Spec.Template.Spec.HostNetwork)
+	assert.Equal(t, int32(32222), ds.Spec.Template.Spec.Containers[0].Ports[0].HostPort)
+
+	// Switching back to the host network updates the DaemonSet
+	rc.Spec.PVCSync.SSH.Exposure = nil
+	require.NoError(t, d.createOrUpdateDaemonSet(ctx, rc))
+	require.NoError(t, d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, ds))
+	assert.True(t, ds.Spec.Template.Spec.HostNetwork)
+}
+
+func TestUpdateAgentEndpoints_NodePort(t *testing.T) {
+	ctx := context.Background()
+	d := exposureTestDeployer(t)
+	rc := exposedCluster(&drv1alpha1.AgentExposure{Type: drv1alpha1.AgentExposureNodePort, NodePort: 30222})
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+
+	service := &corev1.Service{}
+	require.NoError(t, d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, service))
+	assert.Equal(t, corev1.ServiceTypeNodePort, service.Spec.Type)
+	assert.Equal(t, corev1.ServiceExternalTrafficPolicyLocal, service.Spec.ExternalTrafficPolicy, "connections stay on the node of the PVC")
+	assert.Equal(t, map[string]string{"app": agentName}, service.Spec.Selector)
+	assert.Equal(t, map[string]drv1alpha1.AgentEndpoint{
+		"node-a": {Address: "203.0.113.1", Port: 30222},
+		"node-b": {Address: "10.0.0.2", Port: 30222},
+	}, rc.Status.PVCSync.AgentStatus.Endpoints)
+
+	// Going back to the host network removes the Service
+	rc.Spec.PVCSync.SSH.Exposure = nil
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+	err := d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, service)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestUpdateAgentEndpoints_LoadBalancer(t *testing.T) {
+	ctx := context.Background()
+	d := exposureTestDeployer(t)
+	rc := exposedCluster(&drv1alpha1.AgentExposure{
+		Type:               drv1alpha1.AgentExposureLoadBalancer,
+		ServiceAnnotations: map[string]string{"service.beta.kubernetes.io/aws-load-balancer-internal": "true"},
+	})
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+	assert.Empty(t, rc.Status.PVCSync.AgentStatus.Endpoints, "nothing is advertised before the load balancer has an address")
+
+	service := &corev1.Service{}
+	require.NoError(t, d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, service))
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, service.Spec.Type)
+	assert.Empty(t, service.Spec.Selector, "the ports are routed by the agent EndpointSlices")
+	assert.Equal(t, "true", service.Annotations["service.beta.kubernetes.io/aws-load-balancer-internal"])
+	require.Len(t, service.Spec.Ports, 2)
+
+	slices := &discoveryv1.EndpointSliceList{}
+	require.NoError(t, d.client.List(ctx, slices, client.InNamespace(agentNamespace)))
+	require.Len(t, slices.Items, 2)
+	for _, slice := range slices.Items {
+		assert.Equal(t, agentName, slice.Labels[discoveryv1.LabelServiceName])
+		require.Len(t, slice.Endpoints, 1)
+		assert.Equal(t, agentName+"-"+nodePortName(*slice.Endpoints[0].NodeName), slice.Name)
+		assert.Equal(t, nodePortName(*slice.Endpoints[0].NodeName), *slice.Ports[0].Name)
+		assert.Equal(t, int32(2222), *slice.Ports[0].Port)
+	}
+
+	service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: "agents.example.com"}}
+	require.NoError(t, d.client.Status().Update(ctx, service))
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+	endpoints := rc.Status.PVCSync.AgentStatus.Endpoints
+	require.Len(t, endpoints, 2)
+	assert.Equal(t, "agents.example.com", endpoints["node-a"].Address)
+	assert.ElementsMatch(t, []int32{2222, 2223}, []int32{endpoints["node-a"].Port, endpoints["node-b"].Port})
+
+	// A node leaving frees its port and EndpointSlice, the other node keeps its port
+	require.NoError(t, d.client.Delete(ctx, agentPod("agent-a", "node-a", "")))
+	portB := endpoints["node-b"].Port
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+	assert.Equal(t, map[string]drv1alpha1.AgentEndpoint{"node-b": {Address: "agents.example.com", Port: portB}},
+		rc.Status.PVCSync.AgentStatus.Endpoints)
+	require.NoError(t, d.client.List(ctx, slices, client.InNamespace(agentNamespace)))
+	assert.Len(t, slices.Items, 1)
+}
+
+func TestLoadBalancerPorts(t *testing.T) {
+	existing := []corev1.ServicePort{{Name: nodePortName("node-b"), Port: 2222}}
+	ports := loadBalancerPorts(existing, []string{"node-c", "node-b", "node-a"}, 2222, 2222)
+
+	byName := make(map[string]int32)
+	for _, port := range ports {
+		byName[port.Name] = port.Port
+		assert.Equal(t, intstr.FromInt32(2222), port.TargetPort)
+		assert.LessOrEqual(t, len(port.Name), 15, "port names are limited to 15 characters")
+	}
+	assert.Equal(t, map[string]int32{
+		nodePortName("node-b"): 2222,
+		nodePortName("node-a"): 2223,
+		nodePortName("node-c"): 2224,
+	}, byName, "assigned ports are kept, new nodes get the lowest free ones")
+}
+
+func TestUpdateAgentEndpoints_Reverse(t *testing.T) {
+	ctx := context.Background()
+	d := exposureTestDeployer(t, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: tunnel.ConfigMapName, Namespace: agentNamespace},
+		Data:       map[string]string{"node-b": "30000", "node-gone": "30001"},
+	})
+	rendezvous := &drv1alpha1.AgentRendezvous{
+		Address:        "rendezvous.example.com:2022",
+		HostKey:        "ssh-ed25519 AAAA",
+		PortRangeStart: 30000,
+		PortRangeSize:  2,
+	}
+	rc := exposedCluster(&drv1alpha1.AgentExposure{Type: drv1alpha1.AgentExposureReverse, Rendezvous: rendezvous})
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+
+	assert.Equal(t, map[string]drv1alpha1.AgentEndpoint{
+		"node-a": {Address: "rendezvous.example.com", Port: 30001},
+		"node-b": {Address: "rendezvous.example.com", Port: 30000},
+	}, rc.Status.PVCSync.AgentStatus.Endpoints, "nodes keep their port, ports of removed nodes are reused")
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, d.client.Get(ctx, client.ObjectKey{Name: tunnel.ConfigMapName, Namespace: agentNamespace}, cm))
+	assert.Equal(t, map[string]string{"node-a": "30001", "node-b": "30000"}, cm.Data)
+
+	require.NoError(t, d.createOrUpdateDaemonSet(ctx, rc))
+	ds := &appsv1.DaemonSet{}
+	require.NoError(t, d.client.Get(ctx, client.ObjectKey{Name: agentName, Namespace: agentNamespace}, ds))
+	env := convertEnvToMap(ds.Spec.Template.Spec.Containers[0].Env)
+	assert.Equal(t, "rendezvous.example.com:2022", env["RENDEZVOUS_ADDRESS"])
+	assert.Equal(t, "ssh-ed25519 AAAA", env["RENDEZVOUS_HOST_KEY"])
+
+	// Nodes beyond the range are not forwarded
+	rendezvous.PortRangeSize = 1
+	rendezvous.AdvertisedAddress = "10.96.0.20"
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+	assert.Equal(t, map[string]drv1alpha1.AgentEndpoint{
+		"node-b": {Address: "10.96.0.20", Port: 30000},
+	}, rc.Status.PVCSync.AgentStatus.Endpoints)
+
+	// Other exposures remove the tunnel ports
+	rc.Spec.PVCSync.SSH.Exposure = nil
+	require.NoError(t, d.UpdateAgentEndpoints(ctx, rc))
+	err := d.client.Get(ctx, client.ObjectKey{Name: tunnel.ConfigMapName, Namespace: agentNamespace}, cm)
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestUpdateAgentEndpoints_ReverseRequiresRendezvous(t *testing.T) {
+	d := exposureTestDeployer(t)
+	rc := exposedCluster(&drv1alpha1.AgentExposure{Type: drv1alpha1.AgentExposureReverse})
+	assert.Error(t, d.UpdateAgentEndpoints(context.Background(), rc))
+}
@@ -107,8 +107,8 @@ func (h *HealthChecker) CheckAgentHealth(ctx context.Context, rc *drv1alpha1.Rem
 		}
 	}
 
-	// Skip health check if PVC sync is not enabled
-	if rc.Spec.PVCSync == nil || !rc.Spec.PVCSync.Enabled {
+	// Skip health check if PVC sync is not enabled or runs without the agent
+	if rc.Spec.PVCSync == nil || !rc.Spec.PVCSync.Enabled || rc.Spec.PVCSync.Agentless {
 		return nil
 	}
 
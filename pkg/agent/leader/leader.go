@@ -1,14 +1,20 @@
 package leader
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"os"
+	"sync/atomic"
 	"time"
 
 	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 )
@@ -24,13 +30,20 @@ const (
 	DefaultKeySecretName = "pvc-syncer-agent-keys"
 	// DefaultKeyBits is the default number of bits for the SSH key
 	DefaultKeyBits = 2048
+	// AuthorizedKeysResyncPeriod is how often the leader rebuilds the shared authorized_keys Secret
+	// without a key source change, expiring the key sources past their expiry
+	AuthorizedKeysResyncPeriod = 5 * time.Minute
 )
 
-// Manager handles leader election and key management
+// Manager handles leader election and key management. The leader maintains the shared
+// authorized_keys Secret mounted by all agent pods and, when it manages keys, generates the agent keys.
 type Manager struct {
-	client    kubernetes.Interface
-	namespace string
-	podName   string
+	client     kubernetes.Interface
+	namespace  string
+	podName    string
+	manageKeys bool
+	leading    atomic.Bool
+	now        func() time.Time
 }
 
 // NewManager creates a new leader election manager
@@ -41,22 +54,21 @@ func NewManager(client kubernetes.Interface, namespace string) (*Manager, error)
 	}
 
 	return &Manager{
-		client:    client,
-		namespace: namespace,
-		podName:   podName,
+		client:     client,
+		namespace:  namespace,
+		podName:    podName,
+		manageKeys: true,
+		now:        time.Now,
 	}, nil
 }
 
+// SetManageKeys sets whether the leader generates the agent SSH keys, key distribution is always done
+func (m *Manager) SetManageKeys(manageKeys bool) {
+	m.manageKeys = manageKeys
+}
+
 // Run starts the leader election process
 func (m *Manager) Run(ctx context.Context) error {
-	// Check if the key secret already exists
-	_, err := m.client.CoreV1().Secrets(m.namespace).Get(ctx, DefaultKeySecretName, metav1.GetOptions{})
-	if err == nil {
-		// Secret already exists, no need for leader election
-		fmt.Printf("SSH key secret %s already exists in namespace %s\n", DefaultKeySecretName, m.namespace)
-		return nil
-	}
-
 	// Create a new resource lock
 	lock := &resourcelock.LeaseLock{
 		LeaseMeta: metav1.ObjectMeta{
@@ -69,7 +81,17 @@ func (m *Manager) Run(ctx context.Context) error {
 		},
 	}
 
-	// Start leader election
+	// Start leader election, standing again after losing the lease so another agent can take over
+	// the key distribution and this one can later
+	for ctx.Err() == nil {
+		m.runElection(ctx, lock)
+	}
+
+	return nil
+}
+
+// runElection campaigns for the lease and leads until it is lost or ctx is done
+func (m *Manager) runElection(ctx context.Context, lock resourcelock.Interface) {
 	leaderelection.RunOrDie(ctx, leaderelection.LeaderElectionConfig{
 		Lock:            lock,
 		ReleaseOnCancel: true,
@@ -78,13 +100,16 @@ func (m *Manager) Run(ctx context.Context) error {
 		RetryPeriod:     DefaultRetryPeriod,
 		Callbacks: leaderelection.LeaderCallbacks{
 			OnStartedLeading: func(ctx context.Context) {
-				// This pod is the leader, generate SSH keys
-				fmt.Printf("Pod %s became leader, generating SSH keys\n", m.podName)
-				if err := m.generateSSHKeys(); err != nil {
-					fmt.Printf("Error generating SSH keys: %v\n", err)
+				m.leading.Store(true)
+				fmt.Printf("Pod %s became leader\n", m.podName)
+				if m.manageKeys {
+					m.ensureSSHKeys(ctx)
 				}
+				// Distribute the authorized keys for as long as this pod leads
+				m.maintainAuthorizedKeys(ctx)
 			},
 			OnStoppedLeading: func() {
+				m.leading.Store(false)
 				fmt.Printf("Pod %s stopped leading\n", m.podName)
 			},
 			OnNewLeader: func(identity string) {
@@ -95,7 +120,141 @@ func (m *Manager) Run(ctx context.Context) error {
 			},
 		},
 	})
+}
+
+// IsLeader returns true while this pod holds the key management lease
+func (m *Manager) IsLeader() bool {
+	return m.leading.Load()
+}
+
+// ensureSSHKeys generates the agent SSH keys unless their secret already exists
+func (m *Manager) ensureSSHKeys(ctx context.Context) {
+	_, err := m.client.CoreV1().Secrets(m.namespace).Get(ctx, DefaultKeySecretName, metav1.GetOptions{})
+	if err == nil {
+		fmt.Printf("SSH key secret %s already exists in namespace %s\n", DefaultKeySecretName, m.namespace)
+		return
+	}
+	if !apierrors.IsNotFound(err) {
+		fmt.Printf("Error checking SSH key secret %s: %v\n", DefaultKeySecretName, err)
+		return
+	}
+
+	fmt.Printf("Generating SSH keys as leader %s\n", m.podName)
+	if err := m.generateSSHKeys(); err != nil {
+		fmt.Printf("Error generating SSH keys: %v\n", err)
+	}
+}
+
+// maintainAuthorizedKeys rebuilds the shared authorized_keys Secret whenever a key source changes
+// and periodically, until ctx is done
+func (m *Manager) maintainAuthorizedKeys(ctx context.Context) {
+	factory := informers.NewSharedInformerFactoryWithOptions(m.client, AuthorizedKeysResyncPeriod,
+		informers.WithNamespace(m.namespace),
+		informers.WithTweakListOptions(func(opts *metav1.ListOptions) {
+			opts.LabelSelector = ssh.AuthorizedKeySourceLabel + "=true"
+		}))
+	informer := factory.Core().V1().Secrets().Informer()
 
+	changed := make(chan struct{}, 1)
+	notify := func() {
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+	if _, err := informer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(interface{}) { notify() },
+		UpdateFunc: func(interface{}, interface{}) { notify() },
+		DeleteFunc: func(interface{}) { notify() },
+	}); err != nil {
+		fmt.Printf("Error watching authorized key sources: %v\n", err)
+		return
+	}
+
+	factory.Start(ctx.Done())
+	defer factory.Shutdown()
+	if !cache.WaitForCacheSync(ctx.Done(), informer.HasSynced) {
+		return
+	}
+
+	resync := time.NewTicker(AuthorizedKeysResyncPeriod)
+	defer resync.Stop()
+	notify()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-changed:
+		case <-resync.C:
+		}
+		if err := m.reconcileAuthorizedKeys(ctx); err != nil {
+			fmt.Printf("Error updating authorized keys secret %s: %v\n", ssh.AuthorizedKeysSecretName, err)
+		}
+	}
+}
+
+// reconcileAuthorizedKeys writes the merged key sources to the shared authorized_keys Secret and
+// deletes the expired key sources
+func (m *Manager) reconcileAuthorizedKeys(ctx context.Context) error {
+	secrets := m.client.CoreV1().Secrets(m.namespace)
+	list, err := secrets.List(ctx, metav1.ListOptions{
+		LabelSelector: ssh.AuthorizedKeySourceLabel + "=true",
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list authorized key sources: %v", err)
+	}
+
+	now := m.now()
+	var sources []corev1.Secret
+	for _, source := range list.Items {
+		if ssh.AuthorizedKeySourceExpired(&source, now) {
+			fmt.Printf("Deleting expired authorized key source %s\n", source.Name)
+			if err := secrets.Delete(ctx, source.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				fmt.Printf("Error deleting expired authorized key source %s: %v\n", source.Name, err)
+			}
+			continue
+		}
+		sources = append(sources, source)
+	}
+	merged := ssh.MergeAuthorizedKeys(sources)
+
+	existing, err := secrets.Get(ctx, ssh.AuthorizedKeysSecretName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		secret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      ssh.AuthorizedKeysSecretName,
+				Namespace: m.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/name":       "dr-syncer-agent",
+					"app.kubernetes.io/part-of":    "dr-syncer",
+					"app.kubernetes.io/managed-by": "dr-syncer-agent",
+				},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{"authorized_keys": merged},
+		}
+		if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create secret: %v", err)
+		}
+		fmt.Printf("Created authorized keys secret %s from %d key sources\n", ssh.AuthorizedKeysSecretName, len(sources))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get secret: %v", err)
+	}
+
+	if bytes.Equal(existing.Data["authorized_keys"], merged) {
+		return nil
+	}
+	if existing.Data == nil {
+		existing.Data = map[string][]byte{}
+	}
+	existing.Data["authorized_keys"] = merged
+	if _, err := secrets.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update secret: %v", err)
+	}
+	fmt.Printf("Updated authorized keys secret %s from %d key sources\n", ssh.AuthorizedKeysSecretName, len(sources))
 	return nil
 }
 
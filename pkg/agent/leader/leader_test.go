@@ -0,0 +1,61 @@
+package leader
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func keySource(name, keys string, annotations map[string]string) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   "dr-syncer",
+			Labels:      map[string]string{ssh.AuthorizedKeySourceLabel: "true"},
+			Annotations: annotations,
+		},
+		Data: map[string][]byte{"authorized_keys": []byte(keys)},
+	}
+}
+
+func TestReconcileAuthorizedKeys(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	expired := map[string]string{ssh.AuthorizedKeyExpiresAnnotation: now.Add(-time.Minute).Format(time.RFC3339)}
+	client := fake.NewSimpleClientset(
+		keySource("mapping", "ssh-rsa KEY1\n", nil),
+		keySource("sync", "ssh-rsa KEY2\n", expired),
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "unrelated", Namespace: "dr-syncer"},
+			Data:       map[string][]byte{"authorized_keys": []byte("ssh-rsa KEY3\n")},
+		},
+	)
+	m := &Manager{client: client, namespace: "dr-syncer", now: func() time.Time { return now }}
+	ctx := context.Background()
+
+	require.NoError(t, m.reconcileAuthorizedKeys(ctx))
+
+	secret, err := client.CoreV1().Secrets("dr-syncer").Get(ctx, ssh.AuthorizedKeysSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "# mapping\nssh-rsa KEY1\n", string(secret.Data["authorized_keys"]))
+
+	// The expired key source is deleted, the unlabelled secret is left alone
+	_, err = client.CoreV1().Secrets("dr-syncer").Get(ctx, "sync", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = client.CoreV1().Secrets("dr-syncer").Get(ctx, "unrelated", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	// Removing the last key source empties the shared keys
+	require.NoError(t, client.CoreV1().Secrets("dr-syncer").Delete(ctx, "mapping", metav1.DeleteOptions{}))
+	require.NoError(t, m.reconcileAuthorizedKeys(ctx))
+	secret, err = client.CoreV1().Secrets("dr-syncer").Get(ctx, ssh.AuthorizedKeysSecretName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, secret.Data["authorized_keys"])
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/sirupsen/logrus"
 	"github.com/supporttools/dr-syncer/pkg/controller/replication"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/kubernetes"
@@ -236,14 +237,14 @@ func (e *Executor) executeCommandOnce(ctx context.Context, namespace, podName st
 		"command_id": commandId,
 	}).Debug(logging.LogTagDetail + " Preparing execution URL")
 
-	// Create a SPDY executor
-	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	// Create the exec executor, streaming over WebSocket with an SPDY fallback
+	executor, err := util.NewPodExecutor(config, req.URL())
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"error":      err,
 			"command_id": commandId,
-		}).Error(logging.LogTagError + " Failed to create SPDY executor")
-		return "", "", &RetryableError{Err: fmt.Errorf("failed to create SPDY executor: %v", err)}
+		}).Error(logging.LogTagError + " Failed to create exec executor")
+		return "", "", &RetryableError{Err: fmt.Errorf("failed to create exec executor: %v", err)}
 	}
 
 	// Create buffers for stdout and stderr with enhanced logging capability
@@ -172,12 +172,15 @@ func (d *RsyncDaemonSet) buildDaemonSet() *appsv1.DaemonSet {
 							},
 							Resources: corev1.ResourceRequirements{
 								Requests: corev1.ResourceList{
-									corev1.ResourceCPU:    mustParseQuantity("100m"),
-									corev1.ResourceMemory: mustParseQuantity("128Mi"),
+									corev1.ResourceCPU:              mustParseQuantity("100m"),
+									corev1.ResourceMemory:           mustParseQuantity("128Mi"),
+									corev1.ResourceEphemeralStorage: DefaultEphemeralStorageRequest,
 								},
+								// The DaemonSet is shared by all mappings, so it keeps the default ephemeral storage
 								Limits: corev1.ResourceList{
-									corev1.ResourceCPU:    mustParseQuantity("2"),
-									corev1.ResourceMemory: mustParseQuantity("2Gi"),
+									corev1.ResourceCPU:              mustParseQuantity("2"),
+									corev1.ResourceMemory:           mustParseQuantity("2Gi"),
+									corev1.ResourceEphemeralStorage: DefaultEphemeralStorageLimit,
 								},
 							},
 						},
@@ -253,6 +256,18 @@ func (d *RsyncDaemonSet) FindPodOnNode(ctx context.Context, nodeName string) (*c
 	return nil, fmt.Errorf("no running rsync DaemonSet pod found on node %s", nodeName)
 }
 
+// BindDestinationVolume binds an unbound WaitForFirstConsumer PVC on a node, so its volume is
+// provisioned where the DaemonSet pod that writes it runs before the destination path is resolved
+func (d *RsyncDaemonSet) BindDestinationVolume(ctx context.Context, nodeName, namespace, pvcName string) error {
+	d.log.WithFields(logrus.Fields{
+		"node":      nodeName,
+		"namespace": namespace,
+		"pvc":       pvcName,
+	}).Info("Binding WaitForFirstConsumer PVC on node")
+
+	return tempod.BindOnNode(ctx, d.Client, namespace, pvcName, nodeName, PlaceholderPodTimeout)
+}
+
 // ResolveDestinationPath resolves the path to write data for a destination PVC
 // It uses a hybrid approach: kubelet path if PVC is mounted, TempPod fallback if not
 // Returns: path, cleanup function (may be nil), error
@@ -326,6 +341,59 @@ func (d *RsyncDaemonSet) ResolveDestinationPath(ctx context.Context, nodeName, n
 	return csiPath, cleanup, nil
 }
 
+// ResolveWarmDestinationPath resolves the path to write data for a destination PVC like
+// ResolveDestinationPath, but attaches an unmounted PVC with a warm pool pod that is kept
+// after the sync, so the next sync finds the PVC mounted and takes the kubelet path
+func (d *RsyncDaemonSet) ResolveWarmDestinationPath(ctx context.Context, nodeName, namespace, pvcName string) (string, error) {
+	csiPath, err := tempod.FindCSIPath(ctx, d.Client, namespace, pvcName, nodeName)
+	if err == nil {
+		d.log.WithFields(logrus.Fields{
+			"pvc":      pvcName,
+			"csi_path": csiPath,
+		}).Info("Found existing CSI path for PVC (fast path)")
+		return csiPath, nil
+	}
+
+	d.log.WithFields(logrus.Fields{
+		"pvc":   pvcName,
+		"error": err,
+	}).Info("CSI path not found, attaching PVC to warm pool pod")
+
+	warmPod, err := tempod.EnsureWarmPoolPod(ctx, d.Client, namespace, pvcName, nodeName)
+	if err != nil {
+		return "", fmt.Errorf("failed to ensure warm pool pod: %w", err)
+	}
+	if warmPod.Spec.NodeName != nodeName {
+		// The PVC is only writable through the DaemonSet pod on the node the warm pool pod runs on
+		return "", fmt.Errorf("warm pool pod %s/%s runs on node %s, not %s", namespace, warmPod.Name, warmPod.Spec.NodeName, nodeName)
+	}
+
+	if err := tempod.WaitForPlaceholderPod(ctx, d.Client, namespace, warmPod.Name, PlaceholderPodTimeout); err != nil {
+		// A warm pool pod that cannot start is not kept, the next sync creates a new one
+		_ = tempod.DeletePlaceholderPod(ctx, d.Client, namespace, warmPod.Name)
+		return "", fmt.Errorf("warm pool pod failed to start: %w", err)
+	}
+
+	csiPath, err = tempod.FindCSIPath(ctx, d.Client, namespace, pvcName, nodeName)
+	if err != nil {
+		return "", fmt.Errorf("failed to find CSI path after attaching warm pool pod: %w", err)
+	}
+
+	d.log.WithFields(logrus.Fields{
+		"pvc":           pvcName,
+		"csi_path":      csiPath,
+		"warm_pool_pod": warmPod.Name,
+	}).Info("Resolved destination path using warm pool pod")
+
+	return csiPath, nil
+}
+
+// ReleaseWarmPool deletes the warm pool pod keeping a destination PVC attached, or every warm
+// pool pod of the namespace when pvcName is empty
+func (d *RsyncDaemonSet) ReleaseWarmPool(ctx context.Context, namespace, pvcName string) error {
+	return tempod.DeleteWarmPoolPods(ctx, d.Client, namespace, pvcName)
+}
+
 // Delete removes the rsync DaemonSet
 func (d *RsyncDaemonSet) Delete(ctx context.Context) error {
 	d.log.Info("Deleting rsync DaemonSet")
@@ -8,8 +8,10 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/agent/tempod"
 	"github.com/supporttools/dr-syncer/pkg/contextkeys"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/util"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -21,7 +23,6 @@ import (
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/remotecommand"
-	"k8s.io/utils/pointer"
 )
 
 var log = logrus.WithField("component", "rsyncpod")
@@ -64,6 +65,14 @@ type RsyncPodOptions struct {
 	// If set, the pod will mount the private key from this secret instead of generating new keys
 	// The secret is expected to have an "id_rsa" key containing the private key
 	CachedKeySecretName string
+
+	// Restricted runs the pod rootless so it is admitted under the restricted PodSecurity standard
+	Restricted bool
+
+	// EphemeralStorageRequest and EphemeralStorageLimit set the ephemeral storage of the pod,
+	// DefaultEphemeralStorageRequest and DefaultEphemeralStorageLimit when nil
+	EphemeralStorageRequest *resource.Quantity
+	EphemeralStorageLimit   *resource.Quantity
 }
 
 // Manager manages rsync operations
@@ -130,6 +139,7 @@ func (m *Manager) CreateRsyncDeployment(ctx context.Context, opts RsyncPodOption
 
 	// Create deployment spec
 	replicas := int32(1)
+	podSecurityContext, securityContext := rsyncSecurityContext(opts.Restricted)
 	deployment := &appsv1.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      deploymentName,
@@ -182,6 +192,12 @@ func (m *Manager) CreateRsyncDeployment(ctx context.Context, opts RsyncPodOption
 										MountPath: "/data",
 									},
 								}
+								if opts.Restricted {
+									mounts = append(mounts, corev1.VolumeMount{
+										Name:      homeVolumeName,
+										MountPath: "/root",
+									})
+								}
 								// Add cached SSH key mount if specified
 								if opts.CachedKeySecretName != "" {
 									mounts = append(mounts, corev1.VolumeMount{
@@ -192,23 +208,8 @@ func (m *Manager) CreateRsyncDeployment(ctx context.Context, opts RsyncPodOption
 								}
 								return mounts
 							}(),
-							Resources: corev1.ResourceRequirements{
-								Limits: corev1.ResourceList{
-									corev1.ResourceCPU:    resource.MustParse("2"),
-									corev1.ResourceMemory: resource.MustParse("2Gi"),
-								},
-								Requests: corev1.ResourceList{
-									corev1.ResourceCPU:    resource.MustParse("500m"),
-									corev1.ResourceMemory: resource.MustParse("512Mi"),
-								},
-							},
-							SecurityContext: &corev1.SecurityContext{
-								Privileged: pointer.Bool(false),
-								Capabilities: &corev1.Capabilities{
-									Add: []corev1.Capability{"SYS_RESOURCE"},
-								},
-								RunAsUser: pointer.Int64(0), // Run as root to preserve file ownership
-							},
+							Resources:       rsyncResources(opts),
+							SecurityContext: securityContext,
 							Env: []corev1.EnvVar{
 								{
 									Name:  "RSYNC_MAX_THREADS",
@@ -232,9 +233,18 @@ func (m *Manager) CreateRsyncDeployment(ctx context.Context, opts RsyncPodOption
 								},
 							},
 						}
+						if opts.Restricted {
+							vols = append(vols, corev1.Volume{
+								Name:         homeVolumeName,
+								VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+							})
+						}
 						// Add cached SSH key secret volume if specified
 						if opts.CachedKeySecretName != "" {
 							defaultMode := int32(0600) // Secure permissions for private key
+							if opts.Restricted {
+								defaultMode = 0440 // Readable through the fsGroup by the non-root user
+							}
 							vols = append(vols, corev1.Volume{
 								Name: "ssh-keys",
 								VolumeSource: corev1.VolumeSource{
@@ -253,7 +263,8 @@ func (m *Manager) CreateRsyncDeployment(ctx context.Context, opts RsyncPodOption
 						}
 						return vols
 					}(),
-					RestartPolicy: corev1.RestartPolicyAlways,
+					SecurityContext: podSecurityContext,
+					RestartPolicy:   corev1.RestartPolicyAlways,
 				},
 			},
 		},
@@ -343,17 +354,6 @@ func (d *RsyncDeployment) WaitForPodReady(ctx context.Context, timeout time.Dura
 			return false, nil
 		}
 
-		// Check if deployment is available
-		if deployment.Status.AvailableReplicas == 0 {
-			log.WithFields(logrus.Fields{
-				"deployment":         d.Name,
-				"namespace":          d.Namespace,
-				"available_replicas": deployment.Status.AvailableReplicas,
-				"ready_replicas":     deployment.Status.ReadyReplicas,
-			}).Debug(logging.LogTagDetail + " Deployment not yet ready")
-			return false, nil
-		}
-
 		// Get pods for this deployment
 		labelSelector := metav1.FormatLabelSelector(deployment.Spec.Selector)
 		pods, err := d.client.CoreV1().Pods(d.Namespace).List(ctx, metav1.ListOptions{
@@ -369,6 +369,22 @@ func (d *RsyncDeployment) WaitForPodReady(ctx context.Context, timeout time.Dura
 			return false, nil
 		}
 
+		// Check if deployment is available, failing early when its pod cannot start
+		if deployment.Status.AvailableReplicas == 0 {
+			for i := range pods.Items {
+				if err := tempod.CheckPodStartup(ctx, d.client, &pods.Items[i]); err != nil {
+					return false, err
+				}
+			}
+			log.WithFields(logrus.Fields{
+				"deployment":         d.Name,
+				"namespace":          d.Namespace,
+				"available_replicas": deployment.Status.AvailableReplicas,
+				"ready_replicas":     deployment.Status.ReadyReplicas,
+			}).Debug(logging.LogTagDetail + " Deployment not yet ready")
+			return false, nil
+		}
+
 		// Find a running pod
 		for _, pod := range pods.Items {
 			if pod.Status.Phase == corev1.PodRunning {
@@ -389,6 +405,16 @@ func (d *RsyncDeployment) WaitForPodReady(ctx context.Context, timeout time.Dura
 		return false, nil
 	})
 
+	// The poll returns the error of a pod that cannot start as it is
+	if startupErr, ok := err.(*tempod.PodStartupError); ok {
+		log.WithFields(logrus.Fields{
+			"deployment": d.Name,
+			"namespace":  d.Namespace,
+			"pod":        startupErr.Name,
+			"reason":     startupErr.Reason,
+		}).Error(logging.LogTagError + " Rsync pod cannot start")
+		return fmt.Errorf("rsync deployment %s/%s cannot become ready: %w", d.Namespace, d.Name, err)
+	}
 	if err != nil {
 		return fmt.Errorf("timeout waiting for rsync deployment %s/%s to be ready: %v", d.Namespace, d.Name, err)
 	}
@@ -684,14 +710,14 @@ func ExecuteCommandInPod(ctx context.Context, client kubernetes.Interface, names
 		"command_id": commandId,
 	}).Debug("[DR-SYNC-EXEC] Preparing execution URL")
 
-	// Create a SPDY executor
-	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	// Create the exec executor, streaming over WebSocket with an SPDY fallback
+	executor, err := util.NewPodExecutor(config, req.URL())
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"error":      err,
 			"command_id": commandId,
-		}).Error("[DR-SYNC-ERROR] Failed to create SPDY executor")
-		return "", "", &RetryableError{Err: fmt.Errorf("failed to create SPDY executor: %v", err)}
+		}).Error("[DR-SYNC-ERROR] Failed to create exec executor")
+		return "", "", &RetryableError{Err: fmt.Errorf("failed to create exec executor: %v", err)}
 	}
 
 	// Create buffers for stdout and stderr with enhanced logging capability
@@ -751,7 +777,7 @@ func ExecuteCommandInPod(ctx context.Context, client kubernetes.Interface, names
 			"command_id": commandId,
 			"timestamp":  time.Now().Format(time.RFC3339),
 		}).Error("[DR-SYNC-ERROR] Failed to execute command")
-		return stdoutBuffer.String(), stderrBuffer.String(), fmt.Errorf("failed to execute command: %v, stderr: %s", err, stderrBuffer.String())
+		return stdoutBuffer.String(), stderrBuffer.String(), fmt.Errorf("failed to execute command: %w, stderr: %s", err, stderrBuffer.String())
 	}
 
 	// Log completion
@@ -0,0 +1,43 @@
+package rsyncpod
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	// DefaultEphemeralStorageRequest is the ephemeral storage requested by rsync pods. rsync writes the
+	// transferred data to the mounted volume, the container only needs room for its logs and SSH files.
+	DefaultEphemeralStorageRequest = resource.MustParse("256Mi")
+
+	// DefaultEphemeralStorageLimit evicts an rsync pod before it fills the ephemeral storage of its node
+	DefaultEphemeralStorageLimit = resource.MustParse("2Gi")
+)
+
+// rsyncResources returns the resources of the rsync container of a pod
+func rsyncResources(opts RsyncPodOptions) corev1.ResourceRequirements {
+	request, limit := DefaultEphemeralStorageRequest, DefaultEphemeralStorageLimit
+	if opts.EphemeralStorageRequest != nil {
+		request = *opts.EphemeralStorageRequest
+	}
+	if opts.EphemeralStorageLimit != nil {
+		limit = *opts.EphemeralStorageLimit
+	}
+	// A request above the limit is rejected by the API server
+	if request.Cmp(limit) > 0 {
+		request = limit
+	}
+
+	return corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:              resource.MustParse("2"),
+			corev1.ResourceMemory:           resource.MustParse("2Gi"),
+			corev1.ResourceEphemeralStorage: limit,
+		},
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:              resource.MustParse("500m"),
+			corev1.ResourceMemory:           resource.MustParse("512Mi"),
+			corev1.ResourceEphemeralStorage: request,
+		},
+	}
+}
@@ -0,0 +1,28 @@
+package rsyncpod
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestCreateRsyncDeploymentEphemeralStorage(t *testing.T) {
+	spec := createdPodSpec(t, RsyncPodOptions{Namespace: "shop", PVCName: "data"})
+	resources := spec.Containers[0].Resources
+	assert.True(t, DefaultEphemeralStorageRequest.Equal(resources.Requests[corev1.ResourceEphemeralStorage]))
+	assert.True(t, DefaultEphemeralStorageLimit.Equal(resources.Limits[corev1.ResourceEphemeralStorage]))
+
+	request, limit := resource.MustParse("1Gi"), resource.MustParse("8Gi")
+	spec = createdPodSpec(t, RsyncPodOptions{Namespace: "shop", PVCName: "data", EphemeralStorageRequest: &request, EphemeralStorageLimit: &limit})
+	resources = spec.Containers[0].Resources
+	assert.True(t, request.Equal(resources.Requests[corev1.ResourceEphemeralStorage]))
+	assert.True(t, limit.Equal(resources.Limits[corev1.ResourceEphemeralStorage]))
+}
+
+func TestRsyncResourcesRequestAboveLimit(t *testing.T) {
+	limit := resource.MustParse("100Mi")
+	resources := rsyncResources(RsyncPodOptions{EphemeralStorageLimit: &limit})
+	assert.True(t, limit.Equal(resources.Requests[corev1.ResourceEphemeralStorage]))
+}
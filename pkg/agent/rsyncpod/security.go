@@ -0,0 +1,47 @@
+package rsyncpod
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/pointer"
+)
+
+// RestrictedUID is the user rsync pods run as under the restricted profile. It is nobody in the
+// rsync image, ssh refuses to run as a user without a passwd entry.
+const RestrictedUID int64 = 65534
+
+// homeVolumeName is the writable emptyDir mounted over /root in restricted pods, so the SSH
+// keys keep their usual path while the image's /root is not accessible to RestrictedUID
+const homeVolumeName = "home"
+
+// rsyncSecurityContext returns the pod and container security contexts of an rsync pod. The restricted
+// profile complies with the restricted PodSecurity standard: no root, no added capabilities, no
+// privilege escalation and the runtime default seccomp profile.
+func rsyncSecurityContext(restricted bool) (*corev1.PodSecurityContext, *corev1.SecurityContext) {
+	if !restricted {
+		return nil, &corev1.SecurityContext{
+			Privileged: pointer.Bool(false),
+			Capabilities: &corev1.Capabilities{
+				Add: []corev1.Capability{"SYS_RESOURCE"},
+			},
+			RunAsUser: pointer.Int64(0), // Run as root to preserve file ownership
+		}
+	}
+
+	seccomp := &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault}
+	pod := &corev1.PodSecurityContext{
+		RunAsNonRoot:   pointer.Bool(true),
+		RunAsUser:      pointer.Int64(RestrictedUID),
+		RunAsGroup:     pointer.Int64(RestrictedUID),
+		FSGroup:        pointer.Int64(RestrictedUID), // Makes the PVC and the SSH key secret accessible
+		SeccompProfile: seccomp,
+	}
+	container := &corev1.SecurityContext{
+		RunAsNonRoot:             pointer.Bool(true),
+		AllowPrivilegeEscalation: pointer.Bool(false),
+		Capabilities: &corev1.Capabilities{
+			Drop: []corev1.Capability{"ALL"},
+		},
+		SeccompProfile: seccomp,
+	}
+	return pod, container
+}
@@ -0,0 +1,67 @@
+package rsyncpod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func createdPodSpec(t *testing.T, opts RsyncPodOptions) corev1.PodSpec {
+	client := fake.NewSimpleClientset()
+	m := &Manager{client: client}
+	rd, err := m.CreateRsyncDeployment(context.Background(), opts)
+	require.NoError(t, err)
+
+	deployment, err := client.AppsV1().Deployments(opts.Namespace).Get(context.Background(), rd.Name, metav1.GetOptions{})
+	require.NoError(t, err)
+	return deployment.Spec.Template.Spec
+}
+
+func TestCreateRsyncDeploymentPrivileged(t *testing.T) {
+	spec := createdPodSpec(t, RsyncPodOptions{Namespace: "shop", PVCName: "data", CachedKeySecretName: "keys"})
+
+	assert.Nil(t, spec.SecurityContext)
+	container := spec.Containers[0]
+	assert.Equal(t, int64(0), *container.SecurityContext.RunAsUser)
+	assert.Equal(t, []corev1.Capability{"SYS_RESOURCE"}, container.SecurityContext.Capabilities.Add)
+	assert.Len(t, spec.Volumes, 2)
+	assert.Equal(t, int32(0600), *spec.Volumes[1].Secret.DefaultMode)
+}
+
+func TestCreateRsyncDeploymentRestricted(t *testing.T) {
+	spec := createdPodSpec(t, RsyncPodOptions{Namespace: "shop", PVCName: "data", CachedKeySecretName: "keys", Restricted: true})
+
+	// Pod level settings required by the restricted PodSecurity standard
+	require.NotNil(t, spec.SecurityContext)
+	assert.True(t, *spec.SecurityContext.RunAsNonRoot)
+	assert.Equal(t, RestrictedUID, *spec.SecurityContext.RunAsUser)
+	assert.Equal(t, RestrictedUID, *spec.SecurityContext.FSGroup)
+	assert.Equal(t, corev1.SeccompProfileTypeRuntimeDefault, spec.SecurityContext.SeccompProfile.Type)
+
+	container := spec.Containers[0].SecurityContext
+	assert.Nil(t, container.RunAsUser)
+	assert.False(t, *container.AllowPrivilegeEscalation)
+	assert.Empty(t, container.Capabilities.Add)
+	assert.Equal(t, []corev1.Capability{"ALL"}, container.Capabilities.Drop)
+
+	// The SSH keys stay at /root/.ssh on a writable home readable by the non-root user
+	mounts := map[string]string{}
+	for _, mount := range spec.Containers[0].VolumeMounts {
+		mounts[mount.MountPath] = mount.Name
+	}
+	assert.Equal(t, homeVolumeName, mounts["/root"])
+	assert.Equal(t, "ssh-keys", mounts["/root/.ssh"])
+	for _, vol := range spec.Volumes {
+		switch vol.Name {
+		case homeVolumeName:
+			assert.NotNil(t, vol.EmptyDir)
+		case "ssh-keys":
+			assert.Equal(t, int32(0440), *vol.Secret.DefaultMode)
+		}
+	}
+}
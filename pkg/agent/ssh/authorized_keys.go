@@ -0,0 +1,189 @@
+package ssh
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The keys accepted by the agents of a cluster are declared as key source Secrets labelled with
+// AuthorizedKeySourceLabel in the agent namespace. The leader agent merges them into the
+// AuthorizedKeysSecretName Secret, which every agent pod mounts and installs into
+// SharedAuthorizedKeysFile when it changes, so no key is ever written into an agent pod by exec.
+const (
+	// AuthorizedKeysSecretName is the Secret maintained by the leader agent with the merged key sources
+	AuthorizedKeysSecretName = "dr-syncer-authorized-keys"
+
+	// AuthorizedKeySourceLabel marks the Secrets whose authorized_keys entries the agents accept
+	AuthorizedKeySourceLabel = "dr-syncer.io/authorized-key"
+
+	// AuthorizedKeyExpiresAnnotation is the RFC3339 time after which a key source is ignored and deleted
+	AuthorizedKeyExpiresAnnotation = "dr-syncer.io/authorized-key-expires-at"
+
+	// AuthorizedKeysMountPath is where the agent pods mount the AuthorizedKeysSecretName Secret
+	AuthorizedKeysMountPath = "/etc/ssh/authorized-keys"
+
+	// SharedAuthorizedKeysFile is the authorized_keys file sshd reads the merged keys from
+	SharedAuthorizedKeysFile = "/etc/ssh/authorized_keys.d/shared_authorized_keys"
+
+	// authorizedKeySourcePrefix prefixes the names of the key source Secrets
+	authorizedKeySourcePrefix = "dr-syncer-authorized-key-"
+)
+
+var invalidSourceNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// AuthorizedKeySourceName returns the name of the key source Secret of owner
+func AuthorizedKeySourceName(owner string) string {
+	name := invalidSourceNameChars.ReplaceAllString(strings.ToLower(owner), "-")
+	name = strings.Trim(authorizedKeySourcePrefix+name, "-")
+	if len(name) > 253 {
+		name = strings.TrimRight(name[:253], "-")
+	}
+	return name
+}
+
+// ApplyAuthorizedKeySource creates or updates the key source Secret of owner in the agent namespace
+// with entries. A positive ttl lets the leader agent drop the keys once it elapsed.
+func ApplyAuthorizedKeySource(ctx context.Context, client kubernetes.Interface, namespace, owner string, entries []byte, ttl time.Duration) error {
+	name := AuthorizedKeySourceName(owner)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/part-of":    "dr-syncer",
+				"app.kubernetes.io/managed-by": "dr-syncer-controller",
+				AuthorizedKeySourceLabel:       "true",
+			},
+			Annotations: map[string]string{},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			authorizedKeys: entries,
+		},
+	}
+	if ttl > 0 {
+		secret.Annotations[AuthorizedKeyExpiresAnnotation] = time.Now().Add(ttl).UTC().Format(time.RFC3339)
+	}
+
+	secrets := client.CoreV1().Secrets(namespace)
+	existing, err := secrets.Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := secrets.Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create authorized key source %s/%s: %v", namespace, name, err)
+		}
+		log.Infof("Created authorized key source %s/%s", namespace, name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get authorized key source %s/%s: %v", namespace, name, err)
+	}
+
+	existing.Labels = secret.Labels
+	existing.Annotations = secret.Annotations
+	existing.Data = secret.Data
+	if _, err := secrets.Update(ctx, existing, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update authorized key source %s/%s: %v", namespace, name, err)
+	}
+	log.Infof("Updated authorized key source %s/%s", namespace, name)
+	return nil
+}
+
+// AuthorizedKeySourceEntries returns the authorized_keys entries of the key source Secret of owner,
+// nil when it does not exist
+func AuthorizedKeySourceEntries(ctx context.Context, client kubernetes.Interface, namespace, owner string) ([]byte, error) {
+	name := AuthorizedKeySourceName(owner)
+	secret, err := client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get authorized key source %s/%s: %v", namespace, name, err)
+	}
+	return secret.Data[authorizedKeys], nil
+}
+
+// DeleteAuthorizedKeySource deletes the key source Secret of owner, a missing source is not an error
+func DeleteAuthorizedKeySource(ctx context.Context, client kubernetes.Interface, namespace, owner string) error {
+	name := AuthorizedKeySourceName(owner)
+	err := client.CoreV1().Secrets(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete authorized key source %s/%s: %v", namespace, name, err)
+	}
+	return nil
+}
+
+// WaitForAuthorizedKey waits until the leader agent merged publicKey into the shared authorized_keys
+// Secret, the agents install it once the kubelet updated their mounts
+func WaitForAuthorizedKey(ctx context.Context, client kubernetes.Interface, namespace string, publicKey []byte, timeout time.Duration) error {
+	key := bytes.TrimSpace(publicKey)
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		secret, err := client.CoreV1().Secrets(namespace).Get(ctx, AuthorizedKeysSecretName, metav1.GetOptions{})
+		if err != nil {
+			if !apierrors.IsNotFound(err) {
+				log.Warnf("Failed to get authorized keys secret %s/%s: %v", namespace, AuthorizedKeysSecretName, err)
+			}
+			return false, nil
+		}
+		return bytes.Contains(secret.Data[authorizedKeys], key), nil
+	})
+	if err != nil {
+		return fmt.Errorf("key not distributed by the leader agent in %s/%s: %v", namespace, AuthorizedKeysSecretName, err)
+	}
+	return nil
+}
+
+// AuthorizedKeySourceExpired reports whether the key source expired at now, sources without a
+// valid expiry never expire
+func AuthorizedKeySourceExpired(source *corev1.Secret, now time.Time) bool {
+	value, ok := source.Annotations[AuthorizedKeyExpiresAnnotation]
+	if !ok {
+		return false
+	}
+	expires, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return false
+	}
+	return !now.Before(expires)
+}
+
+// MergeAuthorizedKeys merges the authorized_keys entries of the key sources in name order. Each
+// source is preceded by a comment naming it, a key already accepted by an earlier source is skipped.
+func MergeAuthorizedKeys(sources []corev1.Secret) []byte {
+	sorted := make([]corev1.Secret, len(sources))
+	copy(sorted, sources)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	var merged bytes.Buffer
+	seen := make(map[string]bool)
+	for _, source := range sorted {
+		fmt.Fprintf(&merged, "# %s\n", source.Name)
+		scanner := bufio.NewScanner(bytes.NewReader(source.Data[authorizedKeys]))
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			if !strings.HasPrefix(line, "#") {
+				if seen[line] {
+					continue
+				}
+				seen[line] = true
+			}
+			merged.WriteString(line)
+			merged.WriteByte('\n')
+		}
+	}
+	return merged.Bytes()
+}
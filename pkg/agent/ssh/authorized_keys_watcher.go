@@ -0,0 +1,113 @@
+package ssh
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// AuthorizedKeysWatcher installs the authorized_keys of the mounted AuthorizedKeysSecretName Secret
+// for sshd and reinstalls them whenever the kubelet updates the mount
+type AuthorizedKeysWatcher struct {
+	mountDir string
+	target   string
+}
+
+// NewAuthorizedKeysWatcher creates a watcher installing the keys mounted in mountDir into target
+func NewAuthorizedKeysWatcher(mountDir, target string) *AuthorizedKeysWatcher {
+	return &AuthorizedKeysWatcher{
+		mountDir: mountDir,
+		target:   target,
+	}
+}
+
+// Run installs the keys and keeps them up to date until ctx is done
+func (w *AuthorizedKeysWatcher) Run(ctx context.Context) error {
+	if _, err := os.Stat(w.mountDir); err != nil {
+		log.Warnf("Authorized keys mount %s not available, shared keys are not installed: %v", w.mountDir, err)
+		return nil
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to create file watcher: %v", err)
+	}
+	defer fsw.Close()
+
+	// The kubelet swaps the ..data symlink of the mount, watching the directory catches every update
+	if err := fsw.Add(w.mountDir); err != nil {
+		return fmt.Errorf("failed to watch %s: %v", w.mountDir, err)
+	}
+
+	if err := w.Install(); err != nil {
+		log.Errorf("Failed to install authorized keys: %v", err)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event, ok := <-fsw.Events:
+			if !ok {
+				return nil
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			if err := w.Install(); err != nil {
+				log.Errorf("Failed to install authorized keys: %v", err)
+			}
+		case err, ok := <-fsw.Errors:
+			if !ok {
+				return nil
+			}
+			log.Warnf("Authorized keys watcher error, updates may be delayed: %v", err)
+		}
+	}
+}
+
+// Install writes the mounted keys to the target file when they differ from the installed ones. A
+// missing key file installs no keys, so removed key sources stop being accepted.
+func (w *AuthorizedKeysWatcher) Install() error {
+	keys, err := os.ReadFile(filepath.Join(w.mountDir, authorizedKeys))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read mounted authorized keys: %v", err)
+	}
+
+	installed, err := os.ReadFile(w.target)
+	if err == nil && bytes.Equal(installed, keys) {
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(w.target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %v", filepath.Dir(w.target), err)
+	}
+
+	// Replace the file atomically so sshd never reads a partial key list
+	tmp, err := os.CreateTemp(filepath.Dir(w.target), ".authorized_keys-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary authorized keys file: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(keys); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write authorized keys: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write authorized keys: %v", err)
+	}
+	// sshd reads the file as the authenticating user and refuses group or world writable files
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to set authorized keys permissions: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), w.target); err != nil {
+		return fmt.Errorf("failed to install authorized keys: %v", err)
+	}
+
+	log.Infof("Installed shared authorized keys into %s", w.target)
+	return nil
+}
@@ -0,0 +1,34 @@
+package ssh
+
+import (
+	"bytes"
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+const (
+	// RsyncDaemonCommand is the agent's forced command that only serves the read-only rsync daemon
+	RsyncDaemonCommand = "/usr/local/bin/dr-syncer-rsync-daemon"
+
+	// rsyncDaemonKeyOptions restrict a key to the rsync daemon forced command
+	rsyncDaemonKeyOptions = `command="` + RsyncDaemonCommand + `",no-pty,no-port-forwarding,no-agent-forwarding,no-X11-forwarding`
+)
+
+// RsyncModeForCluster returns the rsync mode configured on the RemoteCluster, defaulting to Shell
+func RsyncModeForCluster(rc *drv1alpha1.RemoteCluster) drv1alpha1.RsyncMode {
+	if rc == nil || rc.Spec.PVCSync == nil || rc.Spec.PVCSync.SSH == nil || rc.Spec.PVCSync.SSH.RsyncMode == "" {
+		return drv1alpha1.RsyncModeShell
+	}
+	return rc.Spec.PVCSync.SSH.RsyncMode
+}
+
+// AuthorizedKeysEntry returns the authorized_keys line for publicKey.
+// In Daemon mode the key is bound to the rsync daemon forced command.
+func AuthorizedKeysEntry(publicKey []byte, mode drv1alpha1.RsyncMode) []byte {
+	key := bytes.TrimSpace(publicKey)
+	if mode != drv1alpha1.RsyncModeDaemon {
+		return append(key, '\n')
+	}
+	return []byte(fmt.Sprintf("%s %s\n", rsyncDaemonKeyOptions, key))
+}
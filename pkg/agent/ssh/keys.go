@@ -1,6 +1,7 @@
 package ssh
 
 import (
+	"bytes"
 	"context"
 	"crypto/rand"
 	"crypto/rsa"
@@ -481,10 +482,20 @@ func (k *KeyManager) EnsureRsyncKeys(ctx context.Context, rc *drv1alpha1.RemoteC
 		Namespace: namespace,
 	}, existingSecret)
 
-	// If secret exists, return it
+	// If secret exists, return it after making sure authorized_keys matches the rsync mode
 	if err == nil {
 		log.Infof("Rsync SSH key secret %s/%s already exists for cluster %s",
 			namespace, secretName, rc.Name)
+
+		expected := AuthorizedKeysEntry(existingSecret.Data[publicKeyKey], RsyncModeForCluster(rc))
+		if !bytes.Equal(existingSecret.Data[authorizedKeys], expected) {
+			log.Infof("Updating authorized_keys in rsync SSH key secret %s/%s for rsync mode %s",
+				namespace, secretName, RsyncModeForCluster(rc))
+			existingSecret.Data[authorizedKeys] = expected
+			if err := k.client.Update(ctx, existingSecret); err != nil {
+				return nil, fmt.Errorf("failed to update rsync SSH key secret: %v", err)
+			}
+		}
 		return existingSecret, nil
 	}
 
@@ -506,7 +517,7 @@ func (k *KeyManager) EnsureRsyncKeys(ctx context.Context, rc *drv1alpha1.RemoteC
 		"id_rsa":       privateKey,
 		publicKeyKey:   publicKey,
 		"id_rsa.pub":   publicKey,
-		authorizedKeys: publicKey, // The public key in authorized_keys format
+		authorizedKeys: AuthorizedKeysEntry(publicKey, RsyncModeForCluster(rc)),
 	}
 
 	// Create secret
@@ -613,6 +624,21 @@ func (k *KeyManager) DeleteRsyncKeys(ctx context.Context, rc *drv1alpha1.RemoteC
 	return nil
 }
 
+// RotateRsyncKeys replaces the rsync SSH key pair of the remote cluster in a namespace with a new one.
+// Syncs already running keep the key they mounted, which stays authorized until its key source expires.
+func (k *KeyManager) RotateRsyncKeys(ctx context.Context, rc *drv1alpha1.RemoteCluster, namespace string) (*corev1.Secret, error) {
+	if err := k.DeleteRsyncKeys(ctx, rc, namespace); err != nil {
+		return nil, fmt.Errorf("failed to delete existing rsync keys: %v", err)
+	}
+
+	secret, err := k.EnsureRsyncKeys(ctx, rc, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create new rsync keys: %v", err)
+	}
+
+	return secret, nil
+}
+
 // Helper function to get keys from a secret for logging
 func getKeysFromSecret(secret *corev1.Secret) []string {
 	keys := []string{}
@@ -19,7 +19,7 @@ func (s *Server) Port() int {
 	return s.port
 }
 
-// NewServer creates a new SSH server instance
+// NewServer creates a new SSH server instance, the keys it needs are verified by Start
 func NewServer(port int) (*Server, error) {
 	keyPath := "/etc/ssh/keys"
 	hostKeys := []string{
@@ -28,24 +28,6 @@ func NewServer(port int) (*Server, error) {
 		filepath.Join(keyPath, "ssh_host_ed25519_key"),
 	}
 
-	// Verify host keys exist
-	for _, key := range hostKeys {
-		if _, err := os.Stat(key); err != nil {
-			return nil, fmt.Errorf("host key not found: %s", key)
-		}
-	}
-
-	// Verify at least one authorized_keys file exists
-	// Check both the primary authorized_keys and the rsync_authorized_keys
-	authKeysPath := filepath.Join(keyPath, "authorized_keys")
-	rsyncAuthKeysPath := filepath.Join(keyPath, "rsync_authorized_keys")
-	_, authKeysErr := os.Stat(authKeysPath)
-	_, rsyncAuthKeysErr := os.Stat(rsyncAuthKeysPath)
-
-	if authKeysErr != nil && rsyncAuthKeysErr != nil {
-		return nil, fmt.Errorf("no authorized_keys found: checked %s and %s", authKeysPath, rsyncAuthKeysPath)
-	}
-
 	return &Server{
 		port:     port,
 		keyPath:  keyPath,
@@ -85,12 +67,14 @@ func (s *Server) verifySetup() error {
 
 	authKeysContent, authKeysErr := ioutil.ReadFile(authKeysPath)
 	rsyncAuthKeysContent, rsyncAuthKeysErr := ioutil.ReadFile(rsyncAuthKeysPath)
+	sharedAuthKeysContent, sharedAuthKeysErr := ioutil.ReadFile(SharedAuthorizedKeysFile)
 
 	hasValidAuthKeys := authKeysErr == nil && len(authKeysContent) > 0
 	hasValidRsyncAuthKeys := rsyncAuthKeysErr == nil && len(rsyncAuthKeysContent) > 0
+	hasValidSharedAuthKeys := sharedAuthKeysErr == nil && len(sharedAuthKeysContent) > 0
 
-	if !hasValidAuthKeys && !hasValidRsyncAuthKeys {
-		return fmt.Errorf("no valid authorized_keys found: checked %s and %s", authKeysPath, rsyncAuthKeysPath)
+	if !hasValidAuthKeys && !hasValidRsyncAuthKeys && !hasValidSharedAuthKeys {
+		return fmt.Errorf("no valid authorized_keys found: checked %s, %s and %s", authKeysPath, rsyncAuthKeysPath, SharedAuthorizedKeysFile)
 	}
 
 	// Check sshd_config
@@ -100,3 +84,12 @@ func (s *Server) verifySetup() error {
 
 	return nil
 }
+
+// AuthorizedKeysFiles returns the authorized_keys files sshd reads keys from
+func (s *Server) AuthorizedKeysFiles() []string {
+	return []string{
+		filepath.Join(s.keyPath, "authorized_keys"),
+		filepath.Join(s.keyPath, "rsync_authorized_keys"),
+		SharedAuthorizedKeysFile,
+	}
+}
@@ -1,12 +1,16 @@
 package ssh
 
 import (
+	"context"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -485,3 +489,121 @@ func TestServer_CustomPort(t *testing.T) {
 		assert.Equal(t, port, server.Port(), "Port should be %d", port)
 	}
 }
+
+// Tests for forced_command.go
+
+func TestRsyncModeForCluster(t *testing.T) {
+	assert.Equal(t, drv1alpha1.RsyncModeShell, RsyncModeForCluster(nil))
+	assert.Equal(t, drv1alpha1.RsyncModeShell, RsyncModeForCluster(&drv1alpha1.RemoteCluster{}))
+
+	rc := &drv1alpha1.RemoteCluster{Spec: drv1alpha1.RemoteClusterSpec{
+		PVCSync: &drv1alpha1.PVCSyncSpec{SSH: &drv1alpha1.PVCSyncSSH{RsyncMode: drv1alpha1.RsyncModeDaemon}},
+	}}
+	assert.Equal(t, drv1alpha1.RsyncModeDaemon, RsyncModeForCluster(rc))
+}
+
+func TestAuthorizedKeysEntry(t *testing.T) {
+	publicKey := []byte("ssh-rsa AAAAB3NzaC1yc2E test\n")
+
+	assert.Equal(t, "ssh-rsa AAAAB3NzaC1yc2E test\n", string(AuthorizedKeysEntry(publicKey, drv1alpha1.RsyncModeShell)))
+	assert.Equal(t, "ssh-rsa AAAAB3NzaC1yc2E test\n", string(AuthorizedKeysEntry(publicKey, "")))
+
+	entry := string(AuthorizedKeysEntry(publicKey, drv1alpha1.RsyncModeDaemon))
+	assert.Equal(t, `command="/usr/local/bin/dr-syncer-rsync-daemon",no-pty,no-port-forwarding,no-agent-forwarding,no-X11-forwarding ssh-rsa AAAAB3NzaC1yc2E test`+"\n", entry)
+}
+
+// Tests for authorized_keys.go
+
+func TestAuthorizedKeySourceName(t *testing.T) {
+	assert.Equal(t, "dr-syncer-authorized-key-clustermapping-dr-syncer-prod", AuthorizedKeySourceName("clustermapping-dr-syncer-prod"))
+	assert.Equal(t, "dr-syncer-authorized-key-dr-syncer-rsync-ns-ab12", AuthorizedKeySourceName("dr-syncer-rsync-NS_ab12"))
+	assert.LessOrEqual(t, len(AuthorizedKeySourceName(strings.Repeat("a", 300))), 253)
+}
+
+func TestAuthorizedKeySourceExpired(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	source := &corev1.Secret{}
+	assert.False(t, AuthorizedKeySourceExpired(source, now))
+
+	source.Annotations = map[string]string{AuthorizedKeyExpiresAnnotation: "not-a-time"}
+	assert.False(t, AuthorizedKeySourceExpired(source, now))
+
+	source.Annotations[AuthorizedKeyExpiresAnnotation] = now.Add(time.Minute).Format(time.RFC3339)
+	assert.False(t, AuthorizedKeySourceExpired(source, now))
+
+	source.Annotations[AuthorizedKeyExpiresAnnotation] = now.Format(time.RFC3339)
+	assert.True(t, AuthorizedKeySourceExpired(source, now))
+}
+
+func TestMergeAuthorizedKeys(t *testing.T) {
+	sources := []corev1.Secret{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "b"},
+			Data:       map[string][]byte{authorizedKeys: []byte("ssh-rsa KEY1\n\nssh-rsa KEY2\n")},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "a"},
+			Data:       map[string][]byte{authorizedKeys: []byte("# sync-1\nssh-rsa KEY1")},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "c"},
+		},
+	}
+
+	assert.Equal(t, "# a\n# sync-1\nssh-rsa KEY1\n# b\nssh-rsa KEY2\n# c\n", string(MergeAuthorizedKeys(sources)))
+	assert.Equal(t, "b", sources[0].Name, "sources must not be reordered")
+	assert.Empty(t, MergeAuthorizedKeys(nil))
+}
+
+// Tests for authorized_keys_watcher.go
+
+func TestAuthorizedKeysWatcher_Install(t *testing.T) {
+	mountDir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "authorized_keys.d", "shared_authorized_keys")
+	w := NewAuthorizedKeysWatcher(mountDir, target)
+
+	// Without a mounted key file no keys are installed
+	require.NoError(t, w.Install())
+	installed, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Empty(t, installed)
+
+	require.NoError(t, os.WriteFile(filepath.Join(mountDir, authorizedKeys), []byte("ssh-rsa KEY1\n"), 0600))
+	require.NoError(t, w.Install())
+	installed, err = os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Equal(t, "ssh-rsa KEY1\n", string(installed))
+
+	info, err := os.Stat(target)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0644), info.Mode().Perm())
+}
+
+func TestAuthorizedKeysWatcher_Run(t *testing.T) {
+	mountDir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "shared_authorized_keys")
+	w := NewAuthorizedKeysWatcher(mountDir, target)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- w.Run(ctx) }()
+
+	// The key file written after the start is installed by the watch
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(target)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, os.WriteFile(filepath.Join(mountDir, authorizedKeys), []byte("ssh-rsa KEY2\n"), 0600))
+	require.Eventually(t, func() bool {
+		installed, err := os.ReadFile(target)
+		return err == nil && string(installed) == "ssh-rsa KEY2\n"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestAuthorizedKeysWatcher_RunWithoutMount(t *testing.T) {
+	w := NewAuthorizedKeysWatcher(filepath.Join(t.TempDir(), "missing"), filepath.Join(t.TempDir(), "keys"))
+	assert.NoError(t, w.Run(context.Background()))
+}
@@ -0,0 +1,119 @@
+package tempod
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// bindPollInterval is the interval at which BindOnNode checks whether the PVC is bound
+var bindPollInterval = 2 * time.Second
+
+// buildConsumerPod returns a placeholder pod that the scheduler places on a node. Unlike a pod created
+// with a node name, a scheduled pod triggers the volume binding of WaitForFirstConsumer PVCs.
+func buildConsumerPod(namespace, podName, pvcName, nodeName string) *corev1.Pod {
+	pod := buildPlaceholderPod(namespace, podName, pvcName, "")
+	pod.Labels["app.kubernetes.io/component"] = "pvc-consumer"
+	pod.Spec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchFields: []corev1.NodeSelectorRequirement{{
+						Key:      metav1.ObjectNameField,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   []string{nodeName},
+					}},
+				}},
+			},
+		},
+	}
+	// Like the rsync DaemonSet pod it stands in for, the pod runs on the node whatever its taints
+	pod.Spec.Tolerations = []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+	return pod
+}
+
+// BindOnNode binds a WaitForFirstConsumer PVC to a volume provisioned for nodeName, by scheduling a
+// consumer pod on the node and waiting for the PVC to be bound. The consumer pod is deleted afterwards,
+// the bound volume stays reachable from the node.
+func BindOnNode(ctx context.Context, client kubernetes.Interface, namespace, pvcName, nodeName string, timeout time.Duration) error {
+	pvc, err := client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get PVC: %v", err)
+	}
+	if pvc.Status.Phase == corev1.ClaimBound {
+		return nil
+	}
+
+	podName := fmt.Sprintf("pvc-consumer-%s-%s", pvcName, time.Now().Format("20060102-150405"))
+	log.WithFields(map[string]interface{}{
+		"pvc":       pvcName,
+		"namespace": namespace,
+		"node":      nodeName,
+		"pod":       podName,
+	}).Info("Scheduling consumer pod to bind WaitForFirstConsumer PVC")
+
+	if _, err := client.CoreV1().Pods(namespace).Create(ctx, buildConsumerPod(namespace, podName, pvcName, nodeName), metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create consumer pod: %v", err)
+	}
+	defer func() {
+		err := client.CoreV1().Pods(namespace).Delete(context.Background(), podName, metav1.DeleteOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			log.WithFields(map[string]interface{}{
+				"pod":       podName,
+				"namespace": namespace,
+				"error":     err,
+			}).Warn("Failed to delete consumer pod")
+		}
+	}()
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	ticker := time.NewTicker(bindPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-timeoutCtx.Done():
+			return fmt.Errorf("timeout waiting for PVC %s/%s to be bound on node %s%s", namespace, pvcName, nodeName,
+				schedulingMessage(ctx, client, namespace, podName))
+		case <-ticker.C:
+			pvc, err := client.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+			if err != nil {
+				log.WithFields(map[string]interface{}{
+					"pvc":       pvcName,
+					"namespace": namespace,
+					"error":     err,
+				}).Warn("Failed to get PVC")
+				continue
+			}
+			if pvc.Status.Phase == corev1.ClaimBound {
+				log.WithFields(map[string]interface{}{
+					"pvc":       pvcName,
+					"namespace": namespace,
+					"node":      nodeName,
+					"volume":    pvc.Spec.VolumeName,
+				}).Info("WaitForFirstConsumer PVC is bound")
+				return nil
+			}
+		}
+	}
+}
+
+// schedulingMessage returns why the scheduler did not place a pod, empty when it did or is not known
+func schedulingMessage(ctx context.Context, client kubernetes.Interface, namespace, podName string) string {
+	pod, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse && condition.Message != "" {
+			return ": " + condition.Message
+		}
+	}
+	return ""
+}
@@ -0,0 +1,80 @@
+package tempod
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestBuildConsumerPod(t *testing.T) {
+	pod := buildConsumerPod("shop-dr", "pvc-consumer-data", "data", "node-2")
+
+	assert.Empty(t, pod.Spec.NodeName, "the scheduler must place the pod to bind the PVC")
+	terms := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	require.Len(t, terms, 1)
+	assert.Equal(t, metav1.ObjectNameField, terms[0].MatchFields[0].Key)
+	assert.Equal(t, []string{"node-2"}, terms[0].MatchFields[0].Values)
+	assert.Equal(t, "pvc-consumer", pod.Labels["app.kubernetes.io/component"])
+	assert.Equal(t, "data", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+}
+
+func TestBindOnNode(t *testing.T) {
+	interval := bindPollInterval
+	bindPollInterval = 10 * time.Millisecond
+	defer func() { bindPollInterval = interval }()
+
+	ctx := context.Background()
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "shop-dr"}}
+	client := fake.NewSimpleClientset(pvc)
+
+	// The volume is provisioned once the consumer pod is scheduled
+	var scheduled *corev1.Pod
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		scheduled = action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		bound := pvc.DeepCopy()
+		bound.Spec.VolumeName = "pv-data"
+		bound.Status.Phase = corev1.ClaimBound
+		err := client.Tracker().Update(corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims"), bound, "shop-dr")
+		return false, nil, err
+	})
+
+	require.NoError(t, BindOnNode(ctx, client, "shop-dr", "data", "node-1", time.Second))
+	require.NotNil(t, scheduled)
+	assert.Empty(t, podNames(t, client, "shop-dr"), "the consumer pod is deleted once the PVC is bound")
+
+	// A bound PVC needs no consumer pod
+	scheduled = nil
+	require.NoError(t, BindOnNode(ctx, client, "shop-dr", "data", "node-1", time.Second))
+	assert.Nil(t, scheduled)
+}
+
+func TestBindOnNode_Timeout(t *testing.T) {
+	interval := bindPollInterval
+	bindPollInterval = 10 * time.Millisecond
+	defer func() { bindPollInterval = interval }()
+
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "shop-dr"}})
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Status.Conditions = []corev1.PodCondition{{
+			Type:    corev1.PodScheduled,
+			Status:  corev1.ConditionFalse,
+			Message: "0/3 nodes are available: 3 node(s) didn't find available persistent volumes to bind.",
+		}}
+		return false, nil, nil
+	})
+
+	err := BindOnNode(ctx, client, "shop-dr", "data", "node-1", 50*time.Millisecond)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "didn't find available persistent volumes to bind")
+	assert.Empty(t, podNames(t, client, "shop-dr"))
+}
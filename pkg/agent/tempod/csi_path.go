@@ -244,7 +244,24 @@ func CreatePlaceholderPod(ctx context.Context, client kubernetes.Interface, name
 	podName := fmt.Sprintf("pvc-placeholder-%s-%s", pvcName, timestamp)
 
 	// Create the pod
-	pod := &corev1.Pod{
+	createdPod, err := client.CoreV1().Pods(namespace).Create(ctx, buildPlaceholderPod(namespace, podName, pvcName, nodeName), metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create placeholder pod: %v", err)
+	}
+
+	log.WithFields(map[string]interface{}{
+		"pod":       createdPod.Name,
+		"namespace": createdPod.Namespace,
+		"pvc":       pvcName,
+		"node":      nodeName,
+	}).Info("Created placeholder pod")
+
+	return createdPod, nil
+}
+
+// buildPlaceholderPod returns a pod that only mounts a PVC on a node
+func buildPlaceholderPod(namespace, podName, pvcName, nodeName string) *corev1.Pod {
+	return &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      podName,
 			Namespace: namespace,
@@ -297,21 +314,6 @@ func CreatePlaceholderPod(ctx context.Context, client kubernetes.Interface, name
 			RestartPolicy: corev1.RestartPolicyNever,
 		},
 	}
-
-	// Create the pod
-	createdPod, err := client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
-	if err != nil {
-		return nil, fmt.Errorf("failed to create placeholder pod: %v", err)
-	}
-
-	log.WithFields(map[string]interface{}{
-		"pod":       createdPod.Name,
-		"namespace": createdPod.Namespace,
-		"pvc":       pvcName,
-		"node":      nodeName,
-	}).Info("Created placeholder pod")
-
-	return createdPod, nil
 }
 
 // WaitForPlaceholderPod waits for a placeholder pod to be running
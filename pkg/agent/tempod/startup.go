@@ -0,0 +1,112 @@
+package tempod
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/kubernetes"
+)
+
+// startupFailureGrace is how long a pod may stay unschedulable or fail to attach its volumes before it is
+// reported as unable to start. Both can clear on their own, e.g. while a cluster autoscaler adds a node.
+var startupFailureGrace = time.Minute
+
+// startupFailureWaitingReasons are the container waiting reasons that only clear after the pod spec or the
+// cluster is changed, they are reported without grace
+var startupFailureWaitingReasons = map[string]bool{
+	"ImagePullBackOff":           true,
+	"ErrImageNeverPull":          true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CrashLoopBackOff":           true,
+}
+
+// startupFailureEventReasons are the warning events of a pod that cannot attach or mount its volumes
+var startupFailureEventReasons = map[string]bool{
+	"FailedAttachVolume": true,
+	"FailedMount":        true,
+}
+
+// PodStartupError reports a pod that cannot start, so waiting for it to become ready would only run
+// into the timeout
+type PodStartupError struct {
+	// Namespace and Name identify the pod
+	Namespace string
+	Name      string
+	// Reason is the reason the pod cannot start, such as FailedScheduling, ImagePullBackOff or
+	// FailedAttachVolume
+	Reason string
+	// Message is the message of the condition, container status or event reporting the reason
+	Message string
+}
+
+func (e *PodStartupError) Error() string {
+	return fmt.Sprintf("pod %s/%s cannot start: %s: %s", e.Namespace, e.Name, e.Reason, e.Message)
+}
+
+// CheckPodStartup returns a *PodStartupError when a pod that is not running yet cannot start: it is
+// unschedulable, a container is stuck in a back-off or its volumes cannot be attached. It returns nil
+// while the pod may still start.
+func CheckPodStartup(ctx context.Context, client kubernetes.Interface, pod *corev1.Pod) error {
+	if pod.Status.Phase != corev1.PodPending {
+		return nil
+	}
+	failed := func(reason, message string) error {
+		return &PodStartupError{Namespace: pod.Namespace, Name: pod.Name, Reason: reason, Message: message}
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse &&
+			condition.Reason == corev1.PodReasonUnschedulable && time.Since(condition.LastTransitionTime.Time) >= startupFailureGrace {
+			return failed("FailedScheduling", condition.Message)
+		}
+	}
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		if waiting := status.State.Waiting; waiting != nil && startupFailureWaitingReasons[waiting.Reason] {
+			return failed(waiting.Reason, fmt.Sprintf("container %s: %s", status.Name, waiting.Message))
+		}
+	}
+
+	if pod.Spec.NodeName == "" {
+		return nil
+	}
+	events, err := client.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fields.SelectorFromSet(fields.Set{
+			"involvedObject.kind": "Pod",
+			"involvedObject.name": pod.Name,
+		}).String(),
+	})
+	if err != nil {
+		// Events only make the failure visible earlier, the wait goes on without them
+		return nil
+	}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name != pod.Name || (event.InvolvedObject.UID != "" && event.InvolvedObject.UID != pod.UID) {
+			continue
+		}
+		if event.Type != corev1.EventTypeWarning || !startupFailureEventReasons[event.Reason] {
+			continue
+		}
+		if time.Since(eventFirstSeen(event)) >= startupFailureGrace {
+			return failed(event.Reason, event.Message)
+		}
+	}
+	return nil
+}
+
+// eventFirstSeen returns when an event was first reported, by either the events or the core API
+func eventFirstSeen(event corev1.Event) time.Time {
+	if !event.FirstTimestamp.IsZero() {
+		return event.FirstTimestamp.Time
+	}
+	if !event.EventTime.IsZero() {
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
@@ -0,0 +1,99 @@
+package tempod
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func startupTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "rsync-0", Namespace: "shop-dr", UID: "uid-1"},
+		Status:     corev1.PodStatus{Phase: corev1.PodPending},
+	}
+}
+
+func TestCheckPodStartup_Unschedulable(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	pod := startupTestPod()
+	pod.Status.Conditions = []corev1.PodCondition{{
+		Type:               corev1.PodScheduled,
+		Status:             corev1.ConditionFalse,
+		Reason:             corev1.PodReasonUnschedulable,
+		Message:            "0/3 nodes are available: 3 node(s) had volume node affinity conflict.",
+		LastTransitionTime: metav1.NewTime(time.Now()),
+	}}
+
+	// A pod that only just became unschedulable may still be placed
+	assert.NoError(t, CheckPodStartup(ctx, client, pod))
+
+	pod.Status.Conditions[0].LastTransitionTime = metav1.NewTime(time.Now().Add(-2 * startupFailureGrace))
+	err := CheckPodStartup(ctx, client, pod)
+	var startupErr *PodStartupError
+	require.True(t, errors.As(err, &startupErr))
+	assert.Equal(t, "FailedScheduling", startupErr.Reason)
+	assert.Contains(t, err.Error(), "volume node affinity conflict")
+}
+
+func TestCheckPodStartup_ImagePullBackOff(t *testing.T) {
+	ctx := context.Background()
+	pod := startupTestPod()
+	pod.Spec.NodeName = "node-1"
+	pod.Status.ContainerStatuses = []corev1.ContainerStatus{{
+		Name:  "rsync",
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}},
+	}}
+
+	// Image pulls are retried once before the kubelet backs off
+	assert.NoError(t, CheckPodStartup(ctx, fake.NewSimpleClientset(), pod))
+
+	pod.Status.ContainerStatuses[0].State.Waiting = &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}
+	err := CheckPodStartup(ctx, fake.NewSimpleClientset(), pod)
+	var startupErr *PodStartupError
+	require.True(t, errors.As(err, &startupErr))
+	assert.Equal(t, "ImagePullBackOff", startupErr.Reason)
+	assert.Equal(t, "container rsync: Back-off pulling image", startupErr.Message)
+
+	// Running pods are left to the readiness checks
+	pod.Status.Phase = corev1.PodRunning
+	assert.NoError(t, CheckPodStartup(ctx, fake.NewSimpleClientset(), pod))
+}
+
+func TestCheckPodStartup_FailedAttachVolume(t *testing.T) {
+	ctx := context.Background()
+	pod := startupTestPod()
+	pod.Spec.NodeName = "node-1"
+	event := func(name, uid string, firstSeen time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop-dr"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "rsync-0", UID: types.UID("uid-" + uid)},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "FailedAttachVolume",
+			Message:        "Multi-Attach error for volume pvc-1",
+			FirstTimestamp: metav1.NewTime(firstSeen),
+		}
+	}
+
+	// Recent failures and failures of an earlier pod of the same name are ignored
+	client := fake.NewSimpleClientset(
+		event("recent", "1", time.Now()),
+		event("earlier-pod", "0", time.Now().Add(-time.Hour)),
+	)
+	assert.NoError(t, CheckPodStartup(ctx, client, pod))
+
+	client = fake.NewSimpleClientset(event("persistent", "1", time.Now().Add(-2*startupFailureGrace)))
+	err := CheckPodStartup(ctx, client, pod)
+	var startupErr *PodStartupError
+	require.True(t, errors.As(err, &startupErr))
+	assert.Equal(t, "FailedAttachVolume", startupErr.Reason)
+	assert.Equal(t, "Multi-Attach error for volume pvc-1", startupErr.Message)
+}
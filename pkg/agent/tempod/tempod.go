@@ -317,6 +317,8 @@ func (p *TempPod) WaitForPodReady(ctx context.Context, timeout time.Duration) er
 				}
 			} else if pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded {
 				return fmt.Errorf("pod is in terminal state: %s", pod.Status.Phase)
+			} else if err := CheckPodStartup(ctx, p.Client, pod); err != nil {
+				return err
 			}
 		}
 	}
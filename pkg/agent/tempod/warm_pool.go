@@ -0,0 +1,94 @@
+package tempod
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// WarmPoolPodPrefix is the name prefix of warm pool pods
+	WarmPoolPodPrefix = "dr-syncer-warm-"
+
+	// WarmPoolLabel marks placeholder pods that keep a PVC attached between syncs
+	WarmPoolLabel = "dr-syncer.io/warm-pool"
+)
+
+// WarmPoolPodName returns the name of the warm pool pod of a PVC
+func WarmPoolPodName(pvcName string) string {
+	return WarmPoolPodPrefix + pvcName
+}
+
+// EnsureWarmPoolPod returns the warm pool pod keeping a PVC attached, creating it on nodeName
+// when the PVC has none. A warm pool pod that terminated is replaced.
+func EnsureWarmPoolPod(ctx context.Context, client kubernetes.Interface, namespace, pvcName, nodeName string) (*corev1.Pod, error) {
+	podName := WarmPoolPodName(pvcName)
+
+	existing, err := client.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	switch {
+	case err == nil && existing.DeletionTimestamp == nil &&
+		existing.Status.Phase != corev1.PodFailed && existing.Status.Phase != corev1.PodSucceeded:
+		return existing, nil
+	case err == nil:
+		log.WithFields(map[string]interface{}{
+			"pod":       podName,
+			"namespace": namespace,
+			"phase":     existing.Status.Phase,
+		}).Info("Replacing terminated warm pool pod")
+		if err := client.CoreV1().Pods(namespace).Delete(ctx, podName, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to delete terminated warm pool pod: %v", err)
+		}
+		// A deleted pod keeps its name until it is gone, the next sync creates the replacement
+		return nil, fmt.Errorf("warm pool pod %s/%s is being replaced", namespace, podName)
+	case !errors.IsNotFound(err):
+		return nil, fmt.Errorf("failed to get warm pool pod: %v", err)
+	}
+
+	pod := buildPlaceholderPod(namespace, podName, pvcName, nodeName)
+	pod.Labels[WarmPoolLabel] = "true"
+	// Warm pool pods are meant to outlive a sync, so they restart instead of staying terminated
+	pod.Spec.RestartPolicy = corev1.RestartPolicyAlways
+
+	createdPod, err := client.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create warm pool pod: %v", err)
+	}
+
+	log.WithFields(map[string]interface{}{
+		"pod":       createdPod.Name,
+		"namespace": namespace,
+		"pvc":       pvcName,
+		"node":      nodeName,
+	}).Info("Created warm pool pod")
+
+	return createdPod, nil
+}
+
+// DeleteWarmPoolPods deletes the warm pool pods of a namespace, or only the one of pvcName when it is set
+func DeleteWarmPoolPods(ctx context.Context, client kubernetes.Interface, namespace, pvcName string) error {
+	selector := WarmPoolLabel + "=true"
+	if pvcName != "" {
+		selector += ",pvc-name=" + pvcName
+	}
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list warm pool pods: %v", err)
+	}
+
+	for _, pod := range pods.Items {
+		if err := client.CoreV1().Pods(namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete warm pool pod %s: %v", pod.Name, err)
+		}
+		log.WithFields(map[string]interface{}{
+			"pod":       pod.Name,
+			"namespace": namespace,
+		}).Info("Deleted warm pool pod")
+	}
+
+	return nil
+}
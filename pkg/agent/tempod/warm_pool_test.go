@@ -0,0 +1,69 @@
+package tempod
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestEnsureWarmPoolPod(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+
+	pod, err := EnsureWarmPoolPod(ctx, client, "shop-dr", "data", "node-1")
+	require.NoError(t, err)
+	assert.Equal(t, "dr-syncer-warm-data", pod.Name)
+	assert.Equal(t, "node-1", pod.Spec.NodeName)
+	assert.Equal(t, "true", pod.Labels[WarmPoolLabel])
+	assert.Equal(t, "data", pod.Labels["pvc-name"])
+	assert.Equal(t, corev1.RestartPolicyAlways, pod.Spec.RestartPolicy)
+	assert.Equal(t, "data", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+
+	// The next sync reuses the pod instead of creating another one
+	pod, err = EnsureWarmPoolPod(ctx, client, "shop-dr", "data", "node-2")
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", pod.Spec.NodeName)
+
+	// A terminated pod is deleted so it can be replaced
+	pod.Status.Phase = corev1.PodFailed
+	_, err = client.CoreV1().Pods("shop-dr").UpdateStatus(ctx, pod, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	_, err = EnsureWarmPoolPod(ctx, client, "shop-dr", "data", "node-1")
+	assert.Error(t, err)
+	pods, err := client.CoreV1().Pods("shop-dr").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, pods.Items)
+}
+
+func TestDeleteWarmPoolPods(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "placeholder", Namespace: "shop-dr",
+			Labels: map[string]string{"pvc-name": "data"}}},
+	)
+	for _, pvc := range []string{"data", "logs"} {
+		_, err := EnsureWarmPoolPod(ctx, client, "shop-dr", pvc, "node-1")
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, DeleteWarmPoolPods(ctx, client, "shop-dr", "data"))
+	assert.ElementsMatch(t, []string{"placeholder", "dr-syncer-warm-logs"}, podNames(t, client, "shop-dr"))
+
+	require.NoError(t, DeleteWarmPoolPods(ctx, client, "shop-dr", ""))
+	assert.Equal(t, []string{"placeholder"}, podNames(t, client, "shop-dr"))
+}
+
+func podNames(t *testing.T, client *fake.Clientset, namespace string) []string {
+	pods, err := client.CoreV1().Pods(namespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+	return names
+}
@@ -0,0 +1,180 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"golang.org/x/crypto/ssh"
+)
+
+const (
+	// minBackoff and maxBackoff bound the wait between two connection attempts to the rendezvous
+	minBackoff = time.Second
+	maxBackoff = time.Minute
+)
+
+// PortFunc returns the rendezvous port forwarded to the agent
+type PortFunc func(ctx context.Context) (int32, error)
+
+// ConfigMapPort returns a PortFunc reading the port of nodeName from the tunnel ConfigMap in namespace
+func ConfigMapPort(client kubernetes.Interface, namespace, nodeName string) PortFunc {
+	return func(ctx context.Context) (int32, error) {
+		cm, err := client.CoreV1().ConfigMaps(namespace).Get(ctx, ConfigMapName, metav1.GetOptions{})
+		if err != nil {
+			return 0, fmt.Errorf("failed to get ConfigMap %s/%s: %v", namespace, ConfigMapName, err)
+		}
+		value, ok := cm.Data[nodeName]
+		if !ok {
+			return 0, fmt.Errorf("no rendezvous port is assigned to node %s yet", nodeName)
+		}
+		port, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid rendezvous port %q of node %s: %v", value, nodeName, err)
+		}
+		return int32(port), nil
+	}
+}
+
+// Agent keeps an outbound connection of an agent to the rendezvous and forwards the connections to the
+// port assigned to its node to the local sshd
+type Agent struct {
+	address string
+	hostKey ssh.PublicKey
+	keyFile string
+	target  string
+	port    PortFunc
+}
+
+// NewAgent creates an Agent connecting to the rendezvous at address, verifying its host key given in
+// authorized_keys format and authenticating with the private key in keyFile. Connections are forwarded
+// to target.
+func NewAgent(address, hostKey, keyFile, target string, port PortFunc) (*Agent, error) {
+	if address == "" {
+		return nil, fmt.Errorf("rendezvous address is required")
+	}
+	if hostKey == "" {
+		return nil, fmt.Errorf("rendezvous host key is required, agents do not connect to an unverified rendezvous")
+	}
+	key, _, _, _, err := ssh.ParseAuthorizedKey([]byte(hostKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rendezvous host key: %v", err)
+	}
+	return &Agent{address: address, hostKey: key, keyFile: keyFile, target: target, port: port}, nil
+}
+
+// Run connects to the rendezvous and reconnects with a backoff when the connection is lost, until ctx
+// is done
+func (a *Agent) Run(ctx context.Context) error {
+	backoff := minBackoff
+	for {
+		started := time.Now()
+		err := a.connect(ctx)
+		if ctx.Err() != nil {
+			return nil
+		}
+		// A connection that lasted resets the backoff
+		if time.Since(started) > maxBackoff {
+			backoff = minBackoff
+		}
+		log.WithError(err).WithField("rendezvous", a.address).Warnf("Rendezvous connection lost, reconnecting in %s", backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxBackoff {
+			backoff = maxBackoff
+		}
+	}
+}
+
+// connect connects to the rendezvous and forwards connections until the connection is lost
+func (a *Agent) connect(ctx context.Context) error {
+	port, err := a.port(ctx)
+	if err != nil {
+		return err
+	}
+
+	// The key is read on each attempt, so a rotated key is picked up
+	keyData, err := os.ReadFile(a.keyFile)
+	if err != nil {
+		return fmt.Errorf("failed to read agent key: %v", err)
+	}
+	signer, err := ssh.ParsePrivateKey(keyData)
+	if err != nil {
+		return fmt.Errorf("failed to parse agent key: %v", err)
+	}
+
+	client, err := ssh.Dial("tcp", a.address, &ssh.ClientConfig{
+		User:            User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(a.hostKey),
+		Timeout:         dialTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to rendezvous: %v", err)
+	}
+	defer client.Close()
+
+	listener, err := client.Listen("tcp", net.JoinHostPort("0.0.0.0", strconv.Itoa(int(port))))
+	if err != nil {
+		return fmt.Errorf("rendezvous refused to forward port %d: %v", port, err)
+	}
+	log.WithFields(map[string]interface{}{
+		"rendezvous": a.address,
+		"port":       port,
+	}).Info("Connected to rendezvous")
+
+	done := make(chan struct{})
+	defer close(done)
+	go a.keepAlive(ctx, client, done)
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return err
+		}
+		go a.serve(conn)
+	}
+}
+
+// keepAlive closes the connection to the rendezvous when it stops answering or ctx is done, which
+// ends the accept loop of connect
+func (a *Agent) keepAlive(ctx context.Context, client *ssh.Client, done <-chan struct{}) {
+	ticker := time.NewTicker(keepAliveInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-done:
+			return
+		case <-ctx.Done():
+			_ = client.Close()
+			return
+		case <-ticker.C:
+			if _, _, err := client.SendRequest(keepAliveRequest, true, nil); err != nil {
+				_ = client.Close()
+				return
+			}
+		}
+	}
+}
+
+// serve forwards a connection from the rendezvous to the local sshd
+func (a *Agent) serve(conn net.Conn) {
+	target, err := net.DialTimeout("tcp", a.target, dialTimeout)
+	if err != nil {
+		log.WithError(err).WithField("target", a.target).Warn("Failed to connect a forwarded connection to sshd")
+		_ = conn.Close()
+		return
+	}
+	pipe(conn, target)
+}
@@ -0,0 +1,71 @@
+package tunnel
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+// Rendezvous returns the rendezvous of a RemoteCluster whose agents use the Reverse exposure, nil for
+// other exposures
+func Rendezvous(rc *drv1alpha1.RemoteCluster) *drv1alpha1.AgentRendezvous {
+	if rc.Spec.PVCSync == nil || rc.Spec.PVCSync.SSH == nil || rc.Spec.PVCSync.SSH.Exposure == nil ||
+		rc.Spec.PVCSync.SSH.Exposure.Type != drv1alpha1.AgentExposureReverse {
+		return nil
+	}
+	return rc.Spec.PVCSync.SSH.Exposure.Rendezvous
+}
+
+// ClusterAuthorizer authorizes the agents of the RemoteClusters with the Reverse exposure by the public
+// key of their SSH key Secret in the controller cluster. An agent may have the ports of every cluster
+// sharing its key forwarded.
+func ClusterAuthorizer(reader client.Reader) AuthorizeFunc {
+	return func(ctx context.Context, key ssh.PublicKey) ([]PortRange, error) {
+		clusters := &drv1alpha1.RemoteClusterList{}
+		if err := reader.List(ctx, clusters); err != nil {
+			return nil, fmt.Errorf("failed to list remote clusters: %v", err)
+		}
+
+		var ranges []PortRange
+		for i := range clusters.Items {
+			rc := &clusters.Items[i]
+			rendezvous := Rendezvous(rc)
+			if rendezvous == nil {
+				continue
+			}
+			// The same Secret as the one the agents of the cluster mount their key from
+			secretName, secretNamespace := "pvc-syncer-agent-keys", "dr-syncer"
+			if rc.Spec.PVCSync.SSH.KeySecretRef != nil {
+				secretName = rc.Spec.PVCSync.SSH.KeySecretRef.Name
+				secretNamespace = rc.Spec.PVCSync.SSH.KeySecretRef.Namespace
+			}
+			secret := &corev1.Secret{}
+			if err := reader.Get(ctx, client.ObjectKey{Name: secretName, Namespace: secretNamespace}, secret); err != nil {
+				log.WithError(err).WithField("remote_cluster", rc.Name).Warn("Failed to get the agent key secret of a remote cluster")
+				continue
+			}
+			publicKey := secret.Data["ssh-public-key"]
+			if len(publicKey) == 0 {
+				publicKey = secret.Data["id_rsa.pub"]
+			}
+			clusterKey, _, _, _, err := ssh.ParseAuthorizedKey(publicKey)
+			if err != nil {
+				continue
+			}
+			if bytes.Equal(clusterKey.Marshal(), key.Marshal()) {
+				ranges = append(ranges, Ports(rendezvous))
+			}
+		}
+
+		if len(ranges) == 0 {
+			return nil, fmt.Errorf("key %s is not the agent key of a remote cluster with the Reverse exposure", ssh.FingerprintSHA256(key))
+		}
+		return ranges, nil
+	}
+}
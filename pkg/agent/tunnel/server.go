@@ -0,0 +1,235 @@
+package tunnel
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// permissionPorts is the extension of the SSH permissions of an agent connection holding its port ranges
+const permissionPorts = "dr-syncer.io/ports"
+
+// AuthorizeFunc returns the port ranges an agent connecting with key may have forwarded, an error
+// refuses the agent
+type AuthorizeFunc func(ctx context.Context, key ssh.PublicKey) ([]PortRange, error)
+
+// Server is the rendezvous the agents connect out to. It forwards the ports the agents request on the
+// host it listens on, for the agents of the clusters whose nodes accept no inbound connections.
+type Server struct {
+	hostKey   ssh.Signer
+	authorize AuthorizeFunc
+}
+
+// NewServer creates a rendezvous serving with hostKey, authorizing the agents with authorize
+func NewServer(hostKey ssh.Signer, authorize AuthorizeFunc) *Server {
+	return &Server{hostKey: hostKey, authorize: authorize}
+}
+
+// LoadHostKey reads the private host key of the rendezvous from a file
+func LoadHostKey(path string) (ssh.Signer, error) {
+	if path == "" {
+		return nil, fmt.Errorf("the rendezvous requires a host key, the agents verify it")
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendezvous host key: %v", err)
+	}
+	signer, err := ssh.ParsePrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse rendezvous host key: %v", err)
+	}
+	return signer, nil
+}
+
+// ListenAndServe listens on address and serves the agents until ctx is done
+func (s *Server) ListenAndServe(ctx context.Context, address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", address, err)
+	}
+	log.WithField("address", address).Info("Serving agent rendezvous")
+	return s.Serve(ctx, listener)
+}
+
+// Serve serves the agents connecting to listener until ctx is done. Forwarded ports are opened on the
+// host listener listens on.
+func (s *Server) Serve(ctx context.Context, listener net.Listener) error {
+	bindHost := ""
+	if addr, ok := listener.Addr().(*net.TCPAddr); ok && !addr.IP.IsUnspecified() {
+		bindHost = addr.IP.String()
+	}
+
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		go s.handleConn(ctx, conn, bindHost)
+	}
+}
+
+// handleConn authenticates an agent and serves its forward requests until it disconnects
+func (s *Server) handleConn(ctx context.Context, conn net.Conn, bindHost string) {
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			ranges, err := s.authorize(ctx, key)
+			if err != nil {
+				return nil, err
+			}
+			encoded, err := json.Marshal(ranges)
+			if err != nil {
+				return nil, err
+			}
+			return &ssh.Permissions{Extensions: map[string]string{permissionPorts: string(encoded)}}, nil
+		},
+	}
+	config.AddHostKey(s.hostKey)
+
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+	sshConn, channels, requests, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		log.WithError(err).WithField("remote", conn.RemoteAddr().String()).Warn("Refused agent connection")
+		_ = conn.Close()
+		return
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	var ranges []PortRange
+	if err := json.Unmarshal([]byte(sshConn.Permissions.Extensions[permissionPorts]), &ranges); err != nil {
+		log.WithError(err).Error("Failed to decode the port ranges of an agent")
+		_ = sshConn.Close()
+		return
+	}
+
+	agentLog := log.WithField("remote", sshConn.RemoteAddr().String())
+	agentLog.Info("Agent connected")
+
+	connCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		<-connCtx.Done()
+		_ = sshConn.Close()
+	}()
+
+	// Agents only request forwards, they open no channels
+	go func() {
+		for channel := range channels {
+			_ = channel.Reject(ssh.Prohibited, "only port forwarding is supported")
+		}
+	}()
+
+	var mu sync.Mutex
+	listeners := make(map[uint32]net.Listener)
+	defer func() {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, listener := range listeners {
+			_ = listener.Close()
+		}
+	}()
+
+	for request := range requests {
+		switch request.Type {
+		case "tcpip-forward":
+			var forward forwardRequest
+			if err := ssh.Unmarshal(request.Payload, &forward); err != nil {
+				_ = request.Reply(false, nil)
+				continue
+			}
+			if !allowed(ranges, forward.BindPort) {
+				agentLog.WithField("port", forward.BindPort).Warn("Refused to forward a port outside the range of the agent's cluster")
+				_ = request.Reply(false, nil)
+				continue
+			}
+			mu.Lock()
+			_, exists := listeners[forward.BindPort]
+			mu.Unlock()
+			if exists {
+				_ = request.Reply(false, nil)
+				continue
+			}
+			listener, err := net.Listen("tcp", net.JoinHostPort(bindHost, strconv.Itoa(int(forward.BindPort))))
+			if err != nil {
+				agentLog.WithError(err).WithField("port", forward.BindPort).Warn("Failed to open forwarded port")
+				_ = request.Reply(false, nil)
+				continue
+			}
+			mu.Lock()
+			listeners[forward.BindPort] = listener
+			mu.Unlock()
+			agentLog.WithField("port", forward.BindPort).Info("Forwarding port to agent")
+			go forwardConnections(sshConn, listener, forward)
+			_ = request.Reply(true, nil)
+		case "cancel-tcpip-forward":
+			var forward forwardRequest
+			if err := ssh.Unmarshal(request.Payload, &forward); err != nil {
+				_ = request.Reply(false, nil)
+				continue
+			}
+			mu.Lock()
+			listener, exists := listeners[forward.BindPort]
+			delete(listeners, forward.BindPort)
+			mu.Unlock()
+			if exists {
+				_ = listener.Close()
+			}
+			_ = request.Reply(exists, nil)
+		default:
+			if request.WantReply {
+				_ = request.Reply(false, nil)
+			}
+		}
+	}
+	agentLog.Info("Agent disconnected")
+}
+
+// allowed returns true if port is in one of ranges
+func allowed(ranges []PortRange, port uint32) bool {
+	for _, r := range ranges {
+		if r.Contains(port) {
+			return true
+		}
+	}
+	return false
+}
+
+// forwardConnections forwards each connection accepted on a forwarded port to the agent, until the
+// listener is closed
+func forwardConnections(conn ssh.Conn, listener net.Listener, forward forwardRequest) {
+	for {
+		client, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			payload := forwardedTCPPayload{Addr: forward.BindAddr, Port: forward.BindPort}
+			if origin, ok := client.RemoteAddr().(*net.TCPAddr); ok {
+				payload.OriginAddr = origin.IP.String()
+				payload.OriginPort = uint32(origin.Port)
+			}
+			channel, requests, err := conn.OpenChannel("forwarded-tcpip", ssh.Marshal(&payload))
+			if err != nil {
+				log.WithError(err).WithField("port", forward.BindPort).Warn("Agent refused a forwarded connection")
+				_ = client.Close()
+				return
+			}
+			go ssh.DiscardRequests(requests)
+			pipe(client, channel)
+		}()
+	}
+}
@@ -0,0 +1,96 @@
+// Package tunnel connects the agents of clusters whose nodes accept no inbound connections to the
+// controller. The controller runs a rendezvous SSH server, each agent connects out to it and has the
+// rendezvous port assigned to its node forwarded back to its own sshd. The destination rsync pods
+// connect to that port of the rendezvous as if it was the agent.
+//
+// The controller assigns the ports when it deploys the agents and publishes them in ConfigMapName, the
+// rendezvous only forwards the ports of the RemoteCluster the key of an agent belongs to.
+package tunnel
+
+import (
+	"io"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+const (
+	// ConfigMapName is the ConfigMap in the agent namespace mapping each node name to the rendezvous
+	// port forwarded to its agent
+	ConfigMapName = "dr-syncer-agent-tunnels"
+
+	// User is the user name the agents connect to the rendezvous with
+	User = "dr-syncer-agent"
+
+	// DefaultPortRangeSize is the number of rendezvous ports of a cluster when its rendezvous does not set one
+	DefaultPortRangeSize = int32(100)
+
+	// dialTimeout bounds the connection and SSH handshake with the rendezvous and the local sshd
+	dialTimeout = 15 * time.Second
+
+	// keepAliveInterval is how often the agents check their rendezvous connection is alive
+	keepAliveInterval = 30 * time.Second
+
+	// keepAliveRequest is the global request the agents send to check their connection, the rendezvous
+	// answers it like any unknown request
+	keepAliveRequest = "keepalive@dr-syncer.io"
+)
+
+// PortRange is a range of rendezvous ports forwarded to the agents of a cluster
+type PortRange struct {
+	Start int32
+	Size  int32
+}
+
+// Contains returns true if port is in the range
+func (r PortRange) Contains(port uint32) bool {
+	return port >= uint32(r.Start) && port < uint32(r.Start)+uint32(r.Size)
+}
+
+// Ports returns the port range of a rendezvous with its default size applied
+func Ports(rendezvous *drv1alpha1.AgentRendezvous) PortRange {
+	size := rendezvous.PortRangeSize
+	if size <= 0 {
+		size = DefaultPortRangeSize
+	}
+	return PortRange{Start: rendezvous.PortRangeStart, Size: size}
+}
+
+// forwardRequest is the payload of the tcpip-forward and cancel-tcpip-forward requests (RFC 4254 7.1)
+type forwardRequest struct {
+	BindAddr string
+	BindPort uint32
+}
+
+// forwardedTCPPayload is the payload of the forwarded-tcpip channels opened per connection (RFC 4254 7.2)
+type forwardedTCPPayload struct {
+	Addr       string
+	Port       uint32
+	OriginAddr string
+	OriginPort uint32
+}
+
+// closeWriter is implemented by connections that can be half-closed, such as TCP connections and SSH channels
+type closeWriter interface {
+	CloseWrite() error
+}
+
+// pipe copies between two connections until both directions are done, then closes them
+func pipe(a, b io.ReadWriteCloser) {
+	done := make(chan struct{}, 2)
+	copyTo := func(dst, src io.ReadWriteCloser) {
+		_, _ = io.Copy(dst, src)
+		if cw, ok := dst.(closeWriter); ok {
+			_ = cw.CloseWrite()
+		} else {
+			_ = dst.Close()
+		}
+		done <- struct{}{}
+	}
+	go copyTo(a, b)
+	go copyTo(b, a)
+	<-done
+	<-done
+	_ = a.Close()
+	_ = b.Close()
+}
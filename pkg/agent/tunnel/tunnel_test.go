@@ -0,0 +1,219 @@
+package tunnel
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+// newKey generates a key pair, returning its signer and the PEM encoded private key
+func newKey(t *testing.T) (ssh.Signer, []byte) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	signer, err := ssh.NewSignerFromKey(private)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(private, "")
+	require.NoError(t, err)
+	return signer, pem.EncodeToMemory(block)
+}
+
+// freePort returns a local port nothing listens on
+func freePort(t *testing.T) int32 {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+	return int32(listener.Addr().(*net.TCPAddr).Port)
+}
+
+// echoServer stands in for the agent sshd, echoing what it reads
+func echoServer(t *testing.T) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+	return listener.Addr().String()
+}
+
+type tunnelTest struct {
+	rendezvous string
+	hostKey    string
+	keyFile    string
+}
+
+// startRendezvous starts a rendezvous on the loopback interface allowing the agent key to forward ranges
+func startRendezvous(t *testing.T, ctx context.Context, ranges []PortRange) tunnelTest {
+	hostSigner, _ := newKey(t)
+	agentSigner, agentPEM := newKey(t)
+	keyFile := filepath.Join(t.TempDir(), "id_rsa")
+	require.NoError(t, os.WriteFile(keyFile, agentPEM, 0o600))
+
+	server := NewServer(hostSigner, func(_ context.Context, key ssh.PublicKey) ([]PortRange, error) {
+		if string(key.Marshal()) != string(agentSigner.PublicKey().Marshal()) {
+			return nil, fmt.Errorf("unknown key")
+		}
+		return ranges, nil
+	})
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	go func() { _ = server.Serve(ctx, listener) }()
+
+	return tunnelTest{
+		rendezvous: listener.Addr().String(),
+		hostKey:    string(ssh.MarshalAuthorizedKey(hostSigner.PublicKey())),
+		keyFile:    keyFile,
+	}
+}
+
+func staticPort(port int32) PortFunc {
+	return func(context.Context) (int32, error) { return port, nil }
+}
+
+func TestTunnel_ForwardsToAgent(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	port := freePort(t)
+	tt := startRendezvous(t, ctx, []PortRange{{Start: port, Size: 1}})
+
+	agent, err := NewAgent(tt.rendezvous, tt.hostKey, tt.keyFile, echoServer(t), staticPort(port))
+	require.NoError(t, err)
+	go func() { _ = agent.Run(ctx) }()
+
+	// The destination rsync pods connect to the forwarded port of the rendezvous
+	var conn net.Conn
+	require.Eventually(t, func() bool {
+		conn, err = net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", port))
+		return err == nil
+	}, 10*time.Second, 50*time.Millisecond)
+	defer conn.Close()
+
+	_, err = conn.Write([]byte("rsync"))
+	require.NoError(t, err)
+	reply := make([]byte, 5)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	_, err = io.ReadFull(conn, reply)
+	require.NoError(t, err)
+	assert.Equal(t, "rsync", string(reply))
+}
+
+func TestTunnel_RefusesPortOutsideRange(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	port := freePort(t)
+	tt := startRendezvous(t, ctx, []PortRange{{Start: port + 1, Size: 10}})
+
+	agent, err := NewAgent(tt.rendezvous, tt.hostKey, tt.keyFile, echoServer(t), staticPort(port))
+	require.NoError(t, err)
+	err = agent.connect(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "refused to forward port")
+}
+
+func TestTunnel_VerifiesHostKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	port := freePort(t)
+	tt := startRendezvous(t, ctx, []PortRange{{Start: port, Size: 1}})
+
+	otherHost, _ := newKey(t)
+	agent, err := NewAgent(tt.rendezvous, string(ssh.MarshalAuthorizedKey(otherHost.PublicKey())), tt.keyFile, echoServer(t), staticPort(port))
+	require.NoError(t, err)
+	err = agent.connect(ctx)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to connect to rendezvous")
+}
+
+func TestNewAgent_RequiresHostKey(t *testing.T) {
+	_, err := NewAgent("rendezvous.example.com:2022", "", "/etc/ssh/keys/id_rsa", "127.0.0.1:2222", staticPort(30000))
+	assert.Error(t, err)
+}
+
+func TestConfigMapPort(t *testing.T) {
+	client := kubefake.NewSimpleClientset(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: ConfigMapName, Namespace: "dr-syncer"},
+		Data:       map[string]string{"node-a": "30001"},
+	})
+
+	port, err := ConfigMapPort(client, "dr-syncer", "node-a")(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int32(30001), port)
+
+	_, err = ConfigMapPort(client, "dr-syncer", "node-b")(context.Background())
+	assert.Error(t, err)
+}
+
+func TestClusterAuthorizer(t *testing.T) {
+	agentSigner, _ := newKey(t)
+	otherSigner, _ := newKey(t)
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+
+	reverse := func(name, secret string, start int32) *drv1alpha1.RemoteCluster {
+		return &drv1alpha1.RemoteCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{SSH: &drv1alpha1.PVCSyncSSH{
+				KeySecretRef: &drv1alpha1.SSHKeySecretRef{Name: secret, Namespace: "dr-syncer"},
+				Exposure: &drv1alpha1.AgentExposure{
+					Type:       drv1alpha1.AgentExposureReverse,
+					Rendezvous: &drv1alpha1.AgentRendezvous{Address: "rendezvous:2022", HostKey: "key", PortRangeStart: start},
+				},
+			}}},
+		}
+	}
+	keySecret := func(name string, key ssh.PublicKey) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dr-syncer"},
+			Data:       map[string][]byte{"ssh-public-key": ssh.MarshalAuthorizedKey(key)},
+		}
+	}
+
+	reader := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		reverse("prod", "prod-keys", 30000),
+		reverse("other", "other-keys", 31000),
+		keySecret("prod-keys", agentSigner.PublicKey()),
+		keySecret("other-keys", otherSigner.PublicKey()),
+	).Build()
+	authorize := ClusterAuthorizer(reader)
+
+	ranges, err := authorize(context.Background(), agentSigner.PublicKey())
+	require.NoError(t, err)
+	assert.Equal(t, []PortRange{{Start: 30000, Size: DefaultPortRangeSize}}, ranges)
+
+	unknown, _ := newKey(t)
+	_, err = authorize(context.Background(), unknown.PublicKey())
+	assert.Error(t, err)
+}
@@ -0,0 +1,41 @@
+//go:build linux
+
+package volumeapi
+
+import (
+	"io/fs"
+	"syscall"
+	"time"
+)
+
+// changeTime returns the inode change time of a file
+func changeTime(info fs.FileInfo) time.Time {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return time.Unix(stat.Ctim.Sec, stat.Ctim.Nsec)
+	}
+	return info.ModTime()
+}
+
+// device returns the device of the file system holding a file
+func device(info fs.FileInfo) (uint64, bool) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev), true
+	}
+	return 0, false
+}
+
+// diskUsage returns the space allocated to a file
+func diskUsage(info fs.FileInfo) int64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return stat.Blocks * 512
+	}
+	return info.Size()
+}
+
+// inode returns the inode number and link count of a file
+func inode(info fs.FileInfo) (uint64, uint64, bool) {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok && !info.IsDir() {
+		return stat.Ino, uint64(stat.Nlink), true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,28 @@
+//go:build !linux
+
+package volumeapi
+
+import (
+	"io/fs"
+	"time"
+)
+
+// changeTime returns the modification time of a file, the inode change time is only read on Linux
+func changeTime(info fs.FileInfo) time.Time {
+	return info.ModTime()
+}
+
+// device is only known on Linux, walks may cross into other file systems elsewhere
+func device(info fs.FileInfo) (uint64, bool) {
+	return 0, false
+}
+
+// diskUsage returns the size of a file, the allocated blocks are only read on Linux
+func diskUsage(info fs.FileInfo) int64 {
+	return info.Size()
+}
+
+// inode is only known on Linux, hard links are counted once per link elsewhere
+func inode(info fs.FileInfo) (uint64, uint64, bool) {
+	return 0, 0, false
+}
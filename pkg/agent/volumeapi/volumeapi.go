@@ -0,0 +1,383 @@
+// Package volumeapi is the HTTP API the agent serves next to its health endpoint so the controller can
+// inspect the PVC mounts of the agent's node without exec'ing shell commands into the agent pod:
+// resolving the kubelet mount path of a PV, stat'ing a path, finding the latest change under a path and
+// estimating the space used under a path.
+//
+// All paths are restricted to the kubelet pods directory.
+package volumeapi
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// MountPathEndpoint resolves the mount path of the PV given in the volume parameter
+	MountPathEndpoint = "/v1/volumes/mount-path"
+
+	// StatEndpoint stats the path given in the path parameter
+	StatEndpoint = "/v1/volumes/stat"
+
+	// LatestChangeEndpoint finds the latest change under the path given in the path parameter. With the
+	// since parameter, a Unix time, it returns the first change after since instead.
+	LatestChangeEndpoint = "/v1/volumes/latest-change"
+
+	// UsageEndpoint estimates the space used by the files under the path given in the path parameter,
+	// like du
+	UsageEndpoint = "/v1/volumes/usage"
+
+	// DefaultPodsDir is where the kubelet mounts the volumes of the pods
+	DefaultPodsDir = "/var/lib/kubelet/pods"
+
+	// defaultMountsFile lists the mounts seen by the agent
+	defaultMountsFile = "/proc/self/mounts"
+)
+
+// MountPathResponse is the response of MountPathEndpoint, Path is empty when the PV is not mounted on
+// the node
+type MountPathResponse struct {
+	Path string `json:"path"`
+}
+
+// StatResponse is the response of StatEndpoint
+type StatResponse struct {
+	Path    string    `json:"path"`
+	Exists  bool      `json:"exists"`
+	IsDir   bool      `json:"isDir,omitempty"`
+	Size    int64     `json:"size,omitempty"`
+	ModTime time.Time `json:"modTime"`
+}
+
+// LatestChangeResponse is the response of LatestChangeEndpoint. The change time is the inode change
+// time, which covers content writes, renames, permission changes and deletions (via the parent
+// directory). Path is empty when nothing changed after since.
+type LatestChangeResponse struct {
+	Path       string    `json:"path,omitempty"`
+	ChangeTime time.Time `json:"changeTime"`
+}
+
+// UsageResponse is the response of UsageEndpoint. UsedBytes counts the blocks allocated to the files
+// under Path without crossing into other file systems.
+type UsageResponse struct {
+	Path      string `json:"path"`
+	UsedBytes int64  `json:"usedBytes"`
+}
+
+// Handler serves the volume API
+type Handler struct {
+	podsDir    string
+	mountsFile string
+}
+
+// NewHandler returns a Handler serving the volumes mounted under podsDir
+func NewHandler(podsDir string) *Handler {
+	return &Handler{podsDir: filepath.Clean(podsDir), mountsFile: defaultMountsFile}
+}
+
+// Register adds the endpoints of the volume API to mux
+func (h *Handler) Register(mux *http.ServeMux) {
+	mux.HandleFunc(MountPathEndpoint, h.serveMountPath)
+	mux.HandleFunc(StatEndpoint, h.serveStat)
+	mux.HandleFunc(LatestChangeEndpoint, h.serveLatestChange)
+	mux.HandleFunc(UsageEndpoint, h.serveUsage)
+}
+
+// serveMountPath serves MountPathEndpoint
+func (h *Handler) serveMountPath(w http.ResponseWriter, r *http.Request) {
+	volume := r.URL.Query().Get("volume")
+	if volume == "" || strings.ContainsAny(volume, "/\x00") || volume == "." || volume == ".." {
+		http.Error(w, "invalid volume name", http.StatusBadRequest)
+		return
+	}
+
+	path, err := h.mountPath(volume)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, MountPathResponse{Path: path})
+}
+
+// serveStat serves StatEndpoint
+func (h *Handler) serveStat(w http.ResponseWriter, r *http.Request) {
+	path, ok := h.allowedPath(w, r)
+	if !ok {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		writeJSON(w, StatResponse{Path: path})
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, StatResponse{
+		Path:    path,
+		Exists:  true,
+		IsDir:   info.IsDir(),
+		Size:    info.Size(),
+		ModTime: info.ModTime().UTC(),
+	})
+}
+
+// serveLatestChange serves LatestChangeEndpoint
+func (h *Handler) serveLatestChange(w http.ResponseWriter, r *http.Request) {
+	path, ok := h.allowedPath(w, r)
+	if !ok {
+		return
+	}
+
+	var since time.Time
+	if value := r.URL.Query().Get("since"); value != "" {
+		seconds, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			http.Error(w, "invalid since parameter", http.StatusBadRequest)
+			return
+		}
+		since = time.Unix(seconds, 0)
+	}
+
+	response, err := latestChange(r, path, since)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, response)
+}
+
+// serveUsage serves UsageEndpoint
+func (h *Handler) serveUsage(w http.ResponseWriter, r *http.Request) {
+	path, ok := h.allowedPath(w, r)
+	if !ok {
+		return
+	}
+
+	used, err := usage(r, path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, UsageResponse{Path: path, UsedBytes: used})
+}
+
+// allowedPath returns the path parameter of the request, failing it when the path is outside the pods
+// directory
+func (h *Handler) allowedPath(w http.ResponseWriter, r *http.Request) (string, bool) {
+	path := filepath.Clean(r.URL.Query().Get("path"))
+	if !filepath.IsAbs(path) || !strings.HasPrefix(path, h.podsDir+string(filepath.Separator)) {
+		http.Error(w, fmt.Sprintf("path must be under %s", h.podsDir), http.StatusBadRequest)
+		return "", false
+	}
+	return path, true
+}
+
+// mountPath returns the mount path of a PV on the node, empty when it is not mounted. The mount table
+// is searched first; volumes that are not separate mounts are found by their kubelet directory.
+func (h *Handler) mountPath(volume string) (string, error) {
+	mounts, err := h.mountPoints()
+	if err != nil {
+		return "", err
+	}
+	for _, mount := range mounts {
+		if isVolumeMount(h.podsDir, mount, volume) {
+			return mount, nil
+		}
+	}
+
+	dirs, err := filepath.Glob(filepath.Join(h.podsDir, "*", "volumes", "*", volume))
+	if err != nil {
+		return "", err
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		if info, err := os.Stat(filepath.Join(dir, "mount")); err == nil && info.IsDir() {
+			return filepath.Join(dir, "mount"), nil
+		}
+		if info, err := os.Stat(dir); err == nil && info.IsDir() {
+			return dir, nil
+		}
+	}
+	return "", nil
+}
+
+// mountPoints returns the mount points under the pods directory
+func (h *Handler) mountPoints() ([]string, error) {
+	file, err := os.Open(h.mountsFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the mount table: %v", err)
+	}
+	defer file.Close()
+
+	var mounts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		mount := unescapeMountPath(fields[1])
+		if strings.HasPrefix(mount, h.podsDir+"/") {
+			mounts = append(mounts, mount)
+		}
+	}
+	return mounts, scanner.Err()
+}
+
+// isVolumeMount returns true if mount is the kubelet mount of volume, either the volume directory of
+// a pod, <podsDir>/<uid>/volumes/<plugin>/<volume>, or the mount directory inside it used by CSI
+func isVolumeMount(podsDir, mount, volume string) bool {
+	rel, err := filepath.Rel(podsDir, mount)
+	if err != nil {
+		return false
+	}
+	parts := strings.Split(rel, "/")
+	if len(parts) < 4 || parts[1] != "volumes" {
+		return false
+	}
+	switch len(parts) {
+	case 4:
+		return parts[3] == volume
+	case 5:
+		return parts[3] == volume && parts[4] == "mount"
+	}
+	return false
+}
+
+// unescapeMountPath decodes the octal escapes of spaces, tabs, newlines and backslashes in the mount table
+func unescapeMountPath(path string) string {
+	if !strings.Contains(path, `\`) {
+		return path
+	}
+	var b strings.Builder
+	for i := 0; i < len(path); i++ {
+		if path[i] == '\\' && i+3 < len(path) {
+			if value, err := strconv.ParseUint(path[i+1:i+4], 8, 8); err == nil {
+				b.WriteByte(byte(value))
+				i += 3
+				continue
+			}
+		}
+		b.WriteByte(path[i])
+	}
+	return b.String()
+}
+
+// latestChange walks the file system of root without crossing into other file systems and returns the
+// entry changed last, or the first entry changed after since when since is set
+func latestChange(r *http.Request, root string, since time.Time) (LatestChangeResponse, error) {
+	rootInfo, err := os.Lstat(root)
+	if err != nil {
+		return LatestChangeResponse{}, err
+	}
+	rootDevice, _ := device(rootInfo)
+
+	var latest LatestChangeResponse
+	errFound := errors.New("found")
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Entries deleted during the walk are not changes of interest
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if dev, ok := device(info); ok && dev != rootDevice && path != root {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		changed := changeTime(info)
+		if !since.IsZero() {
+			if changed.After(since) {
+				latest = LatestChangeResponse{Path: path, ChangeTime: changed.UTC()}
+				return errFound
+			}
+			return nil
+		}
+		if changed.After(latest.ChangeTime) {
+			latest = LatestChangeResponse{Path: path, ChangeTime: changed.UTC()}
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFound) {
+		return LatestChangeResponse{}, err
+	}
+	return latest, nil
+}
+
+// usage walks the file system of root without crossing into other file systems and sums the space
+// used by its entries. Hard links are counted once.
+func usage(r *http.Request, root string) (int64, error) {
+	rootInfo, err := os.Lstat(root)
+	if err != nil {
+		return 0, err
+	}
+	rootDevice, _ := device(rootInfo)
+
+	var used int64
+	seen := make(map[uint64]bool)
+	err = filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			// Entries deleted during the walk use no space
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if err := r.Context().Err(); err != nil {
+			return err
+		}
+
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+		if dev, ok := device(info); ok && dev != rootDevice && path != root {
+			if entry.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if ino, links, ok := inode(info); ok && links > 1 {
+			if seen[ino] {
+				return nil
+			}
+			seen[ino] = true
+		}
+		used += diskUsage(info)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return used, nil
+}
+
+// writeJSON writes a JSON response
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,123 @@
+package volumeapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serve calls an endpoint of the handler and decodes its JSON response into out
+func serve(t *testing.T, h *Handler, endpoint string, params url.Values, out interface{}) int {
+	t.Helper()
+	mux := http.NewServeMux()
+	h.Register(mux)
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, endpoint+"?"+params.Encode(), nil))
+	if rec.Code == http.StatusOK && out != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out))
+	}
+	return rec.Code
+}
+
+func TestMountPath(t *testing.T) {
+	podsDir := t.TempDir()
+	csiMount := filepath.Join(podsDir, "uid-1", "volumes", "kubernetes.io~csi", "pv-csi", "mount")
+	hostDir := filepath.Join(podsDir, "uid-2", "volumes", "kubernetes.io~local-volume", "pv-local")
+	require.NoError(t, os.MkdirAll(csiMount, 0755))
+	require.NoError(t, os.MkdirAll(hostDir, 0755))
+
+	mountsFile := filepath.Join(t.TempDir(), "mounts")
+	require.NoError(t, os.WriteFile(mountsFile, []byte(
+		"/dev/sda1 / ext4 rw 0 0\n"+
+			"/dev/rbd0 "+filepath.Join(podsDir, "uid-3", "volumes", "kubernetes.io~csi", "pv-rbd", "mount")+" ext4 rw 0 0\n"+
+			"/dev/rbd1 "+filepath.Join(podsDir, "uid-4", "volumes", "kubernetes.io~csi", "pv-rbd-2", "mount")+" ext4 rw 0 0\n",
+	), 0644))
+	h := &Handler{podsDir: podsDir, mountsFile: mountsFile}
+
+	for volume, expected := range map[string]string{
+		"pv-rbd":   filepath.Join(podsDir, "uid-3", "volumes", "kubernetes.io~csi", "pv-rbd", "mount"),
+		"pv-csi":   csiMount,
+		"pv-local": hostDir,
+		"pv-other": "",
+	} {
+		var response MountPathResponse
+		require.Equal(t, http.StatusOK, serve(t, h, MountPathEndpoint, url.Values{"volume": {volume}}, &response))
+		assert.Equal(t, expected, response.Path, volume)
+	}
+
+	assert.Equal(t, http.StatusBadRequest, serve(t, h, MountPathEndpoint, url.Values{"volume": {"../etc"}}, nil))
+}
+
+func TestStat(t *testing.T) {
+	podsDir := t.TempDir()
+	dir := filepath.Join(podsDir, "uid-1", "volumes")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	h := NewHandler(podsDir)
+
+	var response StatResponse
+	require.Equal(t, http.StatusOK, serve(t, h, StatEndpoint, url.Values{"path": {dir}}, &response))
+	assert.True(t, response.Exists)
+	assert.True(t, response.IsDir)
+
+	response = StatResponse{}
+	require.Equal(t, http.StatusOK, serve(t, h, StatEndpoint, url.Values{"path": {filepath.Join(dir, "gone")}}, &response))
+	assert.False(t, response.Exists)
+
+	// Paths outside the pods directory are refused
+	assert.Equal(t, http.StatusBadRequest, serve(t, h, StatEndpoint, url.Values{"path": {"/etc/shadow"}}, nil))
+	assert.Equal(t, http.StatusBadRequest, serve(t, h, StatEndpoint, url.Values{"path": {filepath.Join(podsDir, "..", "etc")}}, nil))
+}
+
+func TestLatestChange(t *testing.T) {
+	podsDir := t.TempDir()
+	mount := filepath.Join(podsDir, "uid-1", "volumes", "kubernetes.io~csi", "pv-data", "mount")
+	require.NoError(t, os.MkdirAll(filepath.Join(mount, "uploads"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(mount, "uploads", "a.txt"), []byte("a"), 0644))
+	h := NewHandler(podsDir)
+
+	var response LatestChangeResponse
+	require.Equal(t, http.StatusOK, serve(t, h, LatestChangeEndpoint, url.Values{"path": {mount}}, &response))
+	assert.NotEmpty(t, response.Path)
+	assert.WithinDuration(t, time.Now(), response.ChangeTime, time.Minute)
+
+	// Nothing changed after since
+	future := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+	response = LatestChangeResponse{}
+	require.Equal(t, http.StatusOK, serve(t, h, LatestChangeEndpoint, url.Values{"path": {mount}, "since": {future}}, &response))
+	assert.Empty(t, response.Path)
+
+	past := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+	require.Equal(t, http.StatusOK, serve(t, h, LatestChangeEndpoint, url.Values{"path": {mount}, "since": {past}}, &response))
+	assert.NotEmpty(t, response.Path)
+}
+
+func TestUsage(t *testing.T) {
+	podsDir := t.TempDir()
+	mount := filepath.Join(podsDir, "uid-1", "volumes", "kubernetes.io~csi", "pv-data", "mount")
+	require.NoError(t, os.MkdirAll(filepath.Join(mount, "uploads"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(mount, "uploads", "a.bin"), make([]byte, 64*1024), 0644))
+	require.NoError(t, os.Link(filepath.Join(mount, "uploads", "a.bin"), filepath.Join(mount, "a-link.bin")))
+	h := NewHandler(podsDir)
+
+	var response UsageResponse
+	require.Equal(t, http.StatusOK, serve(t, h, UsageEndpoint, url.Values{"path": {mount}}, &response))
+	assert.Equal(t, mount, response.Path)
+	assert.GreaterOrEqual(t, response.UsedBytes, int64(64*1024))
+	assert.Less(t, response.UsedBytes, int64(2*64*1024), "hard links are counted once")
+
+	assert.Equal(t, http.StatusBadRequest, serve(t, h, UsageEndpoint, url.Values{"path": {"/etc"}}, nil))
+}
+
+func TestUnescapeMountPath(t *testing.T) {
+	assert.Equal(t, "/var/lib/kubelet/pods/a b/mount", unescapeMountPath(`/var/lib/kubelet/pods/a\040b/mount`))
+	assert.Equal(t, `/plain`, unescapeMountPath(`/plain`))
+}
@@ -0,0 +1,241 @@
+package audit
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/contextkeys"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var log = logging.SetupLogging()
+
+// Operation is a mutation performed on the destination cluster
+type Operation string
+
+const (
+	// OperationCreate records the creation of a destination resource
+	OperationCreate Operation = "create"
+	// OperationUpdate records an update of a destination resource
+	OperationUpdate Operation = "update"
+	// OperationDelete records the deletion of a destination resource
+	OperationDelete Operation = "delete"
+)
+
+// maxDiffPaths bounds the number of changed field paths listed in a diff summary
+const maxDiffPaths = 10
+
+// Entry is a single audit record of a destination mutation
+type Entry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Operation  Operation `json:"operation"`
+	Mapping    string    `json:"mapping,omitempty"`
+	APIVersion string    `json:"apiVersion,omitempty"`
+	Kind       string    `json:"kind"`
+	Namespace  string    `json:"namespace,omitempty"`
+	Name       string    `json:"name"`
+	Diff       string    `json:"diff,omitempty"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// Sink receives audit entries in addition to the structured log and metrics
+type Sink interface {
+	Add(entry Entry)
+}
+
+var (
+	sinksMu sync.RWMutex
+	sinks   []Sink
+)
+
+// RegisterSink adds a sink that receives every recorded entry
+func RegisterSink(sink Sink) {
+	sinksMu.Lock()
+	defer sinksMu.Unlock()
+	sinks = append(sinks, sink)
+}
+
+// WithMapping returns a context that attributes recorded mutations to the given mapping
+func WithMapping(ctx context.Context, mapping string) context.Context {
+	return context.WithValue(ctx, contextkeys.MappingKey, mapping)
+}
+
+// MappingFromContext returns the mapping stored by WithMapping, if any
+func MappingFromContext(ctx context.Context) string {
+	if mapping, ok := ctx.Value(contextkeys.MappingKey).(string); ok {
+		return mapping
+	}
+	return ""
+}
+
+// ObjectRef builds an object reference for an audit entry
+func ObjectRef(gvk schema.GroupVersionKind, obj metav1.Object) corev1.ObjectReference {
+	return corev1.ObjectReference{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}
+}
+
+// RefForObject builds an object reference for an audit entry, resolving the kind of typed
+// objects that do not carry TypeMeta from the client-go scheme
+func RefForObject(obj runtime.Object) corev1.ObjectReference {
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Empty() {
+		if resolved, err := apiutil.GVKForObject(obj, scheme.Scheme); err == nil {
+			gvk = resolved
+		}
+	}
+	ref := corev1.ObjectReference{APIVersion: gvk.GroupVersion().String(), Kind: gvk.Kind}
+	if meta, ok := obj.(metav1.Object); ok {
+		ref.Namespace = meta.GetNamespace()
+		ref.Name = meta.GetName()
+	}
+	return ref
+}
+
+// Record audits a create, update or delete performed on the destination cluster. The entry is
+// written to the structured log, counted in Prometheus and handed to all registered sinks.
+// A non-nil err records a failed attempt.
+func Record(ctx context.Context, op Operation, ref corev1.ObjectReference, diff string, err error) {
+	entry := Entry{
+		Timestamp:  time.Now().UTC(),
+		Operation:  op,
+		Mapping:    MappingFromContext(ctx),
+		APIVersion: ref.APIVersion,
+		Kind:       ref.Kind,
+		Namespace:  ref.Namespace,
+		Name:       ref.Name,
+		Diff:       diff,
+	}
+	result := "success"
+	if err != nil {
+		entry.Error = err.Error()
+		result = "failure"
+	}
+
+	fields := logrus.Fields{
+		"audit":      true,
+		"operation":  entry.Operation,
+		"mapping":    entry.Mapping,
+		"apiVersion": entry.APIVersion,
+		"kind":       entry.Kind,
+		"namespace":  entry.Namespace,
+		"name":       entry.Name,
+		"result":     result,
+	}
+	if entry.Diff != "" {
+		fields["diff"] = entry.Diff
+	}
+	if entry.Error != "" {
+		fields["error"] = entry.Error
+	}
+	log.WithFields(fields).Info(fmt.Sprintf("audit: %s %s %s/%s", entry.Operation, entry.Kind, entry.Namespace, entry.Name))
+
+	DestinationMutations.WithLabelValues(string(entry.Operation), entry.Kind, entry.Mapping, result).Inc()
+
+	sinksMu.RLock()
+	defer sinksMu.RUnlock()
+	for _, sink := range sinks {
+		sink.Add(entry)
+	}
+}
+
+// DiffObjects summarizes the fields that differ between two objects, see DiffSummary
+func DiffObjects(before, after interface{}) string {
+	beforeMap, err := toMap(before)
+	if err != nil {
+		return ""
+	}
+	afterMap, err := toMap(after)
+	if err != nil {
+		return ""
+	}
+	return DiffSummary(beforeMap, afterMap)
+}
+
+// DiffSummary returns a comma-separated list of the field paths that differ between two
+// unstructured objects. Server-managed metadata and status are ignored.
+func DiffSummary(before, after map[string]interface{}) string {
+	var paths []string
+	diffPaths("", before, after, 0, &paths)
+	sort.Strings(paths)
+
+	if len(paths) > maxDiffPaths {
+		more := len(paths) - maxDiffPaths
+		paths = append(paths[:maxDiffPaths], fmt.Sprintf("and %d more", more))
+	}
+	return strings.Join(paths, ", ")
+}
+
+// ignoredPaths are server-managed fields that are not meaningful in a diff summary
+var ignoredPaths = map[string]bool{
+	"status":                     true,
+	"metadata.resourceVersion":   true,
+	"metadata.uid":               true,
+	"metadata.managedFields":     true,
+	"metadata.creationTimestamp": true,
+	"metadata.generation":        true,
+	"metadata.selfLink":          true,
+}
+
+// diffPaths walks nested maps up to three levels deep collecting paths whose values differ
+func diffPaths(prefix string, before, after map[string]interface{}, depth int, paths *[]string) {
+	keys := map[string]struct{}{}
+	for k := range before {
+		keys[k] = struct{}{}
+	}
+	for k := range after {
+		keys[k] = struct{}{}
+	}
+
+	for k := range keys {
+		path := k
+		if prefix != "" {
+			path = prefix + "." + k
+		}
+		if ignoredPaths[path] {
+			continue
+		}
+
+		b, a := before[k], after[k]
+		if reflect.DeepEqual(b, a) {
+			continue
+		}
+
+		bMap, bOK := b.(map[string]interface{})
+		aMap, aOK := a.(map[string]interface{})
+		if bOK && aOK && depth < 2 {
+			diffPaths(path, bMap, aMap, depth+1, paths)
+			continue
+		}
+		*paths = append(*paths, path)
+	}
+}
+
+// toMap converts typed or unstructured objects to a map for comparison
+func toMap(obj interface{}) (map[string]interface{}, error) {
+	switch o := obj.(type) {
+	case nil:
+		return map[string]interface{}{}, nil
+	case map[string]interface{}:
+		return o, nil
+	case runtime.Unstructured:
+		return o.UnstructuredContent(), nil
+	default:
+		return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	}
+}
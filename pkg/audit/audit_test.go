@@ -0,0 +1,138 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+type memorySink struct {
+	entries []Entry
+}
+
+func (m *memorySink) Add(entry Entry) {
+	m.entries = append(m.entries, entry)
+}
+
+func withSink(t *testing.T) *memorySink {
+	t.Helper()
+	sink := &memorySink{}
+	sinksMu.Lock()
+	previous := sinks
+	sinks = []Sink{sink}
+	sinksMu.Unlock()
+	t.Cleanup(func() {
+		sinksMu.Lock()
+		sinks = previous
+		sinksMu.Unlock()
+	})
+	return sink
+}
+
+func TestMappingContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, "", MappingFromContext(ctx))
+
+	ctx = WithMapping(ctx, "dr-syncer/app-mapping")
+	assert.Equal(t, "dr-syncer/app-mapping", MappingFromContext(ctx))
+}
+
+func TestRefForObject(t *testing.T) {
+	deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app"}}
+	ref := RefForObject(deployment)
+	assert.Equal(t, "apps/v1", ref.APIVersion)
+	assert.Equal(t, "Deployment", ref.Kind)
+	assert.Equal(t, "app", ref.Namespace)
+	assert.Equal(t, "web", ref.Name)
+
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("example.com/v1")
+	u.SetKind("Widget")
+	u.SetName("w1")
+	ref = RefForObject(u)
+	assert.Equal(t, "example.com/v1", ref.APIVersion)
+	assert.Equal(t, "Widget", ref.Kind)
+}
+
+func TestRecord(t *testing.T) {
+	sink := withSink(t)
+	ctx := WithMapping(context.Background(), "dr-syncer/record-test")
+	ref := corev1.ObjectReference{APIVersion: "v1", Kind: "ConfigMap", Namespace: "app", Name: "settings"}
+
+	before := testutil.ToFloat64(DestinationMutations.WithLabelValues("update", "ConfigMap", "dr-syncer/record-test", "success"))
+	Record(ctx, OperationUpdate, ref, "data.key", nil)
+	Record(ctx, OperationDelete, ref, "", errors.New("forbidden"))
+
+	require.Len(t, sink.entries, 2)
+	assert.Equal(t, OperationUpdate, sink.entries[0].Operation)
+	assert.Equal(t, "dr-syncer/record-test", sink.entries[0].Mapping)
+	assert.Equal(t, "ConfigMap", sink.entries[0].Kind)
+	assert.Equal(t, "data.key", sink.entries[0].Diff)
+	assert.Empty(t, sink.entries[0].Error)
+	assert.False(t, sink.entries[0].Timestamp.IsZero())
+	assert.Equal(t, "forbidden", sink.entries[1].Error)
+
+	after := testutil.ToFloat64(DestinationMutations.WithLabelValues("update", "ConfigMap", "dr-syncer/record-test", "success"))
+	assert.Equal(t, before+1, after)
+	assert.Equal(t, float64(1), testutil.ToFloat64(DestinationMutations.WithLabelValues("delete", "ConfigMap", "dr-syncer/record-test", "failure")))
+}
+
+func TestDiffSummary(t *testing.T) {
+	before := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"resourceVersion": "1",
+			"labels":          map[string]interface{}{"app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{"spec": map[string]interface{}{"image": "web:1"}},
+		},
+		"status": map[string]interface{}{"readyReplicas": int64(1)},
+	}
+	after := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"resourceVersion": "2",
+			"labels":          map[string]interface{}{"app": "web", "tier": "frontend"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(0),
+			"template": map[string]interface{}{"spec": map[string]interface{}{"image": "web:2"}},
+		},
+		"status": map[string]interface{}{"readyReplicas": int64(0)},
+	}
+
+	assert.Equal(t, "metadata.labels.tier, spec.replicas, spec.template.spec", DiffSummary(before, after))
+	assert.Equal(t, "", DiffSummary(before, before))
+}
+
+func TestDiffSummary_Truncated(t *testing.T) {
+	before := map[string]interface{}{}
+	after := map[string]interface{}{}
+	for i := 0; i < maxDiffPaths+3; i++ {
+		after[fmt.Sprintf("field%02d", i)] = i
+	}
+
+	summary := DiffSummary(before, after)
+	assert.Contains(t, summary, "field00")
+	assert.Contains(t, summary, "and 3 more")
+	assert.NotContains(t, summary, fmt.Sprintf("field%02d", maxDiffPaths))
+}
+
+func TestDiffObjects_Typed(t *testing.T) {
+	before := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings"}, Data: map[string]string{"a": "1"}}
+	after := before.DeepCopy()
+	after.Data["a"] = "2"
+
+	assert.Equal(t, "data.a", DiffObjects(before, after))
+}
@@ -0,0 +1,181 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConfigMapEntriesKey is the ConfigMap data key holding the JSON encoded audit entries
+	ConfigMapEntriesKey = "entries.json"
+
+	// DefaultMaxEntries is the default capacity of the ConfigMap ring buffer
+	DefaultMaxEntries = 500
+
+	// defaultFlushInterval is how often buffered entries are written to the ConfigMap
+	defaultFlushInterval = 10 * time.Second
+)
+
+// ConfigMapSink keeps the most recent audit entries in a ConfigMap ring buffer.
+// Entries are buffered in memory and flushed periodically while the sink is running.
+type ConfigMapSink struct {
+	client        client.Client
+	reader        client.Reader
+	namespace     string
+	name          string
+	maxEntries    int
+	flushInterval time.Duration
+
+	mu      sync.Mutex
+	entries []Entry
+	dirty   bool
+}
+
+// NewConfigMapSink creates a sink that stores up to maxEntries entries in the named ConfigMap.
+// The reader is used to load existing entries and should bypass the informer cache.
+func NewConfigMapSink(c client.Client, reader client.Reader, namespace, name string, maxEntries int) *ConfigMapSink {
+	if maxEntries <= 0 {
+		maxEntries = DefaultMaxEntries
+	}
+	return &ConfigMapSink{
+		client:        c,
+		reader:        reader,
+		namespace:     namespace,
+		name:          name,
+		maxEntries:    maxEntries,
+		flushInterval: defaultFlushInterval,
+	}
+}
+
+// Add appends an entry, dropping the oldest entries once the buffer is full
+func (s *ConfigMapSink) Add(entry Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(s.entries, entry)
+	if len(s.entries) > s.maxEntries {
+		s.entries = s.entries[len(s.entries)-s.maxEntries:]
+	}
+	s.dirty = true
+}
+
+// Entries returns a copy of the buffered entries, oldest first
+func (s *ConfigMapSink) Entries() []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Entry(nil), s.entries...)
+}
+
+// Start loads previously stored entries and flushes new ones until the context is cancelled.
+// It implements manager.Runnable.
+func (s *ConfigMapSink) Start(ctx context.Context) error {
+	if err := s.load(ctx); err != nil {
+		log.Warnf("unable to load audit entries from ConfigMap %s/%s: %v", s.namespace, s.name, err)
+	}
+
+	ticker := time.NewTicker(s.flushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Final flush with a fresh context so shutdown does not drop pending entries
+			flushCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			if err := s.Flush(flushCtx); err != nil {
+				log.Errorf("unable to flush audit entries on shutdown: %v", err)
+			}
+			return nil
+		case <-ticker.C:
+			if err := s.Flush(ctx); err != nil {
+				log.Errorf("unable to flush audit entries to ConfigMap %s/%s: %v", s.namespace, s.name, err)
+			}
+		}
+	}
+}
+
+// load seeds the buffer with entries already stored in the ConfigMap
+func (s *ConfigMapSink) load(ctx context.Context) error {
+	cm := &corev1.ConfigMap{}
+	if err := s.reader.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.name}, cm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	var stored []Entry
+	if data := cm.Data[ConfigMapEntriesKey]; data != "" {
+		if err := json.Unmarshal([]byte(data), &stored); err != nil {
+			return fmt.Errorf("failed to decode audit entries: %w", err)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries = append(stored, s.entries...)
+	if len(s.entries) > s.maxEntries {
+		s.entries = s.entries[len(s.entries)-s.maxEntries:]
+	}
+	return nil
+}
+
+// Flush writes the buffered entries to the ConfigMap if anything changed since the last flush
+func (s *ConfigMapSink) Flush(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.dirty {
+		s.mu.Unlock()
+		return nil
+	}
+	data, err := json.Marshal(s.entries)
+	s.dirty = false
+	s.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to encode audit entries: %w", err)
+	}
+
+	if err := s.write(ctx, string(data)); err != nil {
+		// Retry on the next flush
+		s.mu.Lock()
+		s.dirty = true
+		s.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// write creates or updates the ConfigMap with the encoded entries
+func (s *ConfigMapSink) write(ctx context.Context, data string) error {
+	cm := &corev1.ConfigMap{}
+	err := s.reader.Get(ctx, client.ObjectKey{Namespace: s.namespace, Name: s.name}, cm)
+	if apierrors.IsNotFound(err) {
+		cm = &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      s.name,
+				Namespace: s.namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "dr-syncer",
+					"dr-syncer.io/audit":           "true",
+				},
+			},
+			Data: map[string]string{ConfigMapEntriesKey: data},
+		}
+		return s.client.Create(ctx, cm)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cm.Data == nil {
+		cm.Data = map[string]string{}
+	}
+	cm.Data[ConfigMapEntriesKey] = data
+	return s.client.Update(ctx, cm)
+}
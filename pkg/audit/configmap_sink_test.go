@@ -0,0 +1,77 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestConfigMapSink_RingBuffer(t *testing.T) {
+	sink := NewConfigMapSink(nil, nil, "dr-syncer", "audit", 3)
+	for i := 0; i < 5; i++ {
+		sink.Add(Entry{Operation: OperationCreate, Kind: "ConfigMap", Name: fmt.Sprintf("cm-%d", i)})
+	}
+
+	entries := sink.Entries()
+	require.Len(t, entries, 3)
+	assert.Equal(t, "cm-2", entries[0].Name)
+	assert.Equal(t, "cm-4", entries[2].Name)
+}
+
+func TestConfigMapSink_DefaultMaxEntries(t *testing.T) {
+	sink := NewConfigMapSink(nil, nil, "dr-syncer", "audit", 0)
+	assert.Equal(t, DefaultMaxEntries, sink.maxEntries)
+}
+
+func TestConfigMapSink_Flush(t *testing.T) {
+	ctx := context.Background()
+	c := fake.NewClientBuilder().Build()
+	sink := NewConfigMapSink(c, c, "dr-syncer", "audit", 10)
+
+	// Nothing buffered, nothing written
+	require.NoError(t, sink.Flush(ctx))
+	err := c.Get(ctx, client.ObjectKey{Namespace: "dr-syncer", Name: "audit"}, &corev1.ConfigMap{})
+	assert.Error(t, err)
+
+	sink.Add(Entry{Operation: OperationCreate, Kind: "Secret", Namespace: "app", Name: "creds"})
+	require.NoError(t, sink.Flush(ctx))
+
+	sink.Add(Entry{Operation: OperationDelete, Kind: "Secret", Namespace: "app", Name: "creds"})
+	require.NoError(t, sink.Flush(ctx))
+
+	cm := &corev1.ConfigMap{}
+	require.NoError(t, c.Get(ctx, client.ObjectKey{Namespace: "dr-syncer", Name: "audit"}, cm))
+	var stored []Entry
+	require.NoError(t, json.Unmarshal([]byte(cm.Data[ConfigMapEntriesKey]), &stored))
+	require.Len(t, stored, 2)
+	assert.Equal(t, OperationCreate, stored[0].Operation)
+	assert.Equal(t, OperationDelete, stored[1].Operation)
+	assert.Equal(t, "true", cm.Labels["dr-syncer.io/audit"])
+}
+
+func TestConfigMapSink_Load(t *testing.T) {
+	ctx := context.Background()
+	existing, err := json.Marshal([]Entry{{Operation: OperationUpdate, Kind: "Service", Name: "old"}})
+	require.NoError(t, err)
+	c := fake.NewClientBuilder().WithObjects(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "dr-syncer", Name: "audit"},
+		Data:       map[string]string{ConfigMapEntriesKey: string(existing)},
+	}).Build()
+
+	sink := NewConfigMapSink(c, c, "dr-syncer", "audit", 10)
+	sink.Add(Entry{Operation: OperationCreate, Kind: "Service", Name: "new"})
+	require.NoError(t, sink.load(ctx))
+
+	entries := sink.Entries()
+	require.Len(t, entries, 2)
+	assert.Equal(t, "old", entries[0].Name)
+	assert.Equal(t, "new", entries[1].Name)
+}
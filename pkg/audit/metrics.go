@@ -0,0 +1,24 @@
+package audit
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// DestinationMutations counts create, update and delete operations performed on destination clusters
+	DestinationMutations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dr_syncer_audit_destination_mutations_total",
+			Help: "Total number of create, update and delete operations performed on destination clusters",
+		},
+		[]string{"operation", "kind", "mapping", "result"},
+	)
+)
+
+func init() {
+	// Register metrics with the controller-runtime metrics registry
+	metrics.Registry.MustRegister(
+		DestinationMutations,
+	)
+}
@@ -0,0 +1,43 @@
+package backup
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Restore writes a backed up version back to the destination cluster, creating the object if it
+// was deleted since the backup was taken
+func Restore(ctx context.Context, client dynamic.Interface, b *Backup, version *Version) error {
+	obj := version.Object.DeepCopy()
+	obj.SetNamespace(b.Namespace)
+	obj.SetResourceVersion("")
+	obj.SetUID("")
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetManagedFields(nil)
+	obj.SetGeneration(0)
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	resource := client.Resource(b.GVR).Namespace(b.Namespace)
+	existing, err := resource.Get(ctx, b.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		if _, err := resource.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to recreate %s %s/%s: %w", b.GVR.Resource, b.Namespace, b.Name, err)
+		}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s/%s: %w", b.GVR.Resource, b.Namespace, b.Name, err)
+	}
+
+	obj.SetResourceVersion(existing.GetResourceVersion())
+	obj.SetUID(existing.GetUID())
+	if _, err := resource.Update(ctx, obj, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to restore %s %s/%s: %w", b.GVR.Resource, b.Namespace, b.Name, err)
+	}
+	return nil
+}
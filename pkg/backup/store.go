@@ -0,0 +1,252 @@
+package backup
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+var log = logging.SetupLogging()
+
+const (
+	// SecretType is the type of the Secrets holding backed up versions
+	SecretType corev1.SecretType = "dr-syncer.io/backup"
+
+	// BackupLabel marks Secrets created by the backup store
+	BackupLabel = "dr-syncer.io/backup"
+
+	// BackupNamespaceLabel records the namespace of the backed up object
+	BackupNamespaceLabel = "dr-syncer.io/backup-namespace"
+
+	// ResourceAnnotation records the group/version/resource of the backed up object
+	ResourceAnnotation = "dr-syncer.io/backup-resource"
+
+	// NameAnnotation records the name of the backed up object
+	NameAnnotation = "dr-syncer.io/backup-name"
+
+	// VersionsKey is the Secret data key holding the JSON encoded versions, oldest first
+	VersionsKey = "versions.json"
+
+	// DefaultRetention is the number of versions kept per object when no retention is configured
+	DefaultRetention = 5
+)
+
+// Version is a snapshot of a destination object taken before it was overwritten
+type Version struct {
+	Time   metav1.Time                `json:"time"`
+	Object *unstructured.Unstructured `json:"object"`
+}
+
+// Backup holds the stored versions of a single destination object
+type Backup struct {
+	GVR       schema.GroupVersionResource
+	Namespace string
+	Name      string
+	Versions  []Version
+}
+
+// Store keeps prior versions of destination objects in Secrets in the destination cluster.
+// Secrets are used rather than ConfigMaps because backed up objects may themselves be Secrets.
+type Store struct {
+	client           kubernetes.Interface
+	archiveNamespace string
+	retention        int
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+// NewStore creates a backup store. Backups are written to archiveNamespace, or to the namespace of
+// the backed up object when archiveNamespace is empty. At most retention versions are kept per object.
+func NewStore(client kubernetes.Interface, archiveNamespace string, retention int) *Store {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Store{
+		client:           client,
+		archiveNamespace: archiveNamespace,
+		retention:        retention,
+		now:              time.Now,
+	}
+}
+
+// Save appends obj as the newest version of its backup, dropping the oldest versions beyond the retention
+func (s *Store) Save(ctx context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	namespace := s.backupNamespace(obj.GetNamespace())
+	name := secretName(gvr, obj.GetNamespace(), obj.GetName())
+	version := Version{Time: metav1.NewTime(s.now()), Object: obj.DeepCopy()}
+
+	secret, err := s.client.CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		data, err := encodeVersions([]Version{version})
+		if err != nil {
+			return err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels: map[string]string{
+					"app.kubernetes.io/managed-by": "dr-syncer",
+					BackupLabel:                    "true",
+					BackupNamespaceLabel:           obj.GetNamespace(),
+				},
+				Annotations: map[string]string{
+					ResourceAnnotation: gvrString(gvr),
+					NameAnnotation:     obj.GetName(),
+				},
+			},
+			Type: SecretType,
+			Data: map[string][]byte{VersionsKey: data},
+		}
+		if _, err := s.client.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create backup secret %s/%s: %w", namespace, name, err)
+		}
+		log.Debugf("backed up %s %s/%s to %s/%s", gvr.Resource, obj.GetNamespace(), obj.GetName(), namespace, name)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get backup secret %s/%s: %w", namespace, name, err)
+	}
+
+	versions, err := decodeVersions(secret.Data[VersionsKey])
+	if err != nil {
+		return fmt.Errorf("failed to decode backup secret %s/%s: %w", namespace, name, err)
+	}
+	versions = append(versions, version)
+	if len(versions) > s.retention {
+		versions = versions[len(versions)-s.retention:]
+	}
+
+	data, err := encodeVersions(versions)
+	if err != nil {
+		return err
+	}
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[VersionsKey] = data
+	if _, err := s.client.CoreV1().Secrets(namespace).Update(ctx, secret, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update backup secret %s/%s: %w", namespace, name, err)
+	}
+	log.Debugf("backed up %s %s/%s to %s/%s (%d versions)", gvr.Resource, obj.GetNamespace(), obj.GetName(), namespace, name, len(versions))
+	return nil
+}
+
+// List returns the backups of objects in namespace, sorted by resource and name
+func (s *Store) List(ctx context.Context, namespace string) ([]Backup, error) {
+	secrets, err := s.client.CoreV1().Secrets(s.backupNamespace(namespace)).List(ctx, metav1.ListOptions{
+		LabelSelector: fmt.Sprintf("%s=true,%s=%s", BackupLabel, BackupNamespaceLabel, namespace),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list backups for namespace %s: %w", namespace, err)
+	}
+
+	var backups []Backup
+	for _, secret := range secrets.Items {
+		gvr, err := parseGVR(secret.Annotations[ResourceAnnotation])
+		if err != nil {
+			log.Warnf("skipping backup secret %s/%s: %v", secret.Namespace, secret.Name, err)
+			continue
+		}
+		versions, err := decodeVersions(secret.Data[VersionsKey])
+		if err != nil {
+			log.Warnf("skipping backup secret %s/%s: %v", secret.Namespace, secret.Name, err)
+			continue
+		}
+		backups = append(backups, Backup{
+			GVR:       gvr,
+			Namespace: namespace,
+			Name:      secret.Annotations[NameAnnotation],
+			Versions:  versions,
+		})
+	}
+
+	sort.Slice(backups, func(i, j int) bool {
+		if backups[i].GVR.Resource != backups[j].GVR.Resource {
+			return backups[i].GVR.Resource < backups[j].GVR.Resource
+		}
+		return backups[i].Name < backups[j].Name
+	})
+	return backups, nil
+}
+
+// VersionFor selects the version to restore. With a zero since the latest version is returned, which is
+// the state before the most recent sync. Otherwise the oldest version taken at or after since is returned,
+// which is the state before the first sync after that time. Returns nil if no version qualifies.
+func (b *Backup) VersionFor(since time.Time) *Version {
+	if len(b.Versions) == 0 {
+		return nil
+	}
+	if since.IsZero() {
+		return &b.Versions[len(b.Versions)-1]
+	}
+	for i := range b.Versions {
+		if !b.Versions[i].Time.Time.Before(since) {
+			return &b.Versions[i]
+		}
+	}
+	return nil
+}
+
+// backupNamespace returns the namespace backups of objects in namespace are stored in
+func (s *Store) backupNamespace(namespace string) string {
+	if s.archiveNamespace != "" {
+		return s.archiveNamespace
+	}
+	return namespace
+}
+
+// secretName derives a stable, DNS-safe Secret name for an object
+func secretName(gvr schema.GroupVersionResource, namespace, name string) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s/%s/%s/%s", gvr.Group, gvr.Resource, namespace, name)))
+	return "dr-backup-" + hex.EncodeToString(sum[:])[:20]
+}
+
+// gvrString encodes a GroupVersionResource as "group/version/resource", with an empty group for the core API
+func gvrString(gvr schema.GroupVersionResource) string {
+	return fmt.Sprintf("%s/%s/%s", gvr.Group, gvr.Version, gvr.Resource)
+}
+
+// parseGVR decodes the output of gvrString
+func parseGVR(s string) (schema.GroupVersionResource, error) {
+	var gvr schema.GroupVersionResource
+	parts := strings.SplitN(s, "/", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return gvr, fmt.Errorf("invalid %s annotation %q", ResourceAnnotation, s)
+	}
+	gvr.Group, gvr.Version, gvr.Resource = parts[0], parts[1], parts[2]
+	return gvr, nil
+}
+
+func encodeVersions(versions []Version) ([]byte, error) {
+	data, err := json.Marshal(versions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode backup versions: %w", err)
+	}
+	return data, nil
+}
+
+func decodeVersions(data []byte) ([]Version, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+	var versions []Version
+	if err := json.Unmarshal(data, &versions); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
@@ -0,0 +1,132 @@
+package backup
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var configMapGVR = corev1.SchemeGroupVersion.WithResource("configmaps")
+
+func newConfigMap(namespace, name, value string) *unstructured.Unstructured {
+	u := &unstructured.Unstructured{}
+	u.SetAPIVersion("v1")
+	u.SetKind("ConfigMap")
+	u.SetNamespace(namespace)
+	u.SetName(name)
+	u.SetResourceVersion("42")
+	_ = unstructured.SetNestedField(u.Object, value, "data", "key")
+	return u
+}
+
+// newTestStore returns a store whose clock advances one minute per backup
+func newTestStore(archiveNamespace string, retention int) *Store {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store := NewStore(fake.NewSimpleClientset(), archiveNamespace, retention)
+	calls := 0
+	store.now = func() time.Time {
+		calls++
+		return start.Add(time.Duration(calls) * time.Minute)
+	}
+	return store
+}
+
+func TestStore_SaveAndList(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore("", 0)
+
+	require.NoError(t, store.Save(ctx, configMapGVR, newConfigMap("app", "settings", "v1")))
+	require.NoError(t, store.Save(ctx, configMapGVR, newConfigMap("app", "settings", "v2")))
+	require.NoError(t, store.Save(ctx, configMapGVR, newConfigMap("other", "settings", "v1")))
+
+	backups, err := store.List(ctx, "app")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, configMapGVR, backups[0].GVR)
+	assert.Equal(t, "settings", backups[0].Name)
+	require.Len(t, backups[0].Versions, 2)
+
+	value, _, _ := unstructured.NestedString(backups[0].Versions[1].Object.Object, "data", "key")
+	assert.Equal(t, "v2", value)
+
+	secrets, err := store.client.CoreV1().Secrets("app").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, secrets.Items, 1)
+	assert.Equal(t, SecretType, secrets.Items[0].Type)
+}
+
+func TestStore_Retention(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore("", 2)
+
+	for _, value := range []string{"v1", "v2", "v3"} {
+		require.NoError(t, store.Save(ctx, configMapGVR, newConfigMap("app", "settings", value)))
+	}
+
+	backups, err := store.List(ctx, "app")
+	require.NoError(t, err)
+	require.Len(t, backups[0].Versions, 2)
+	value, _, _ := unstructured.NestedString(backups[0].Versions[0].Object.Object, "data", "key")
+	assert.Equal(t, "v2", value)
+}
+
+func TestStore_ArchiveNamespace(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore("dr-backups", 0)
+
+	require.NoError(t, store.Save(ctx, configMapGVR, newConfigMap("app", "settings", "v1")))
+
+	secrets, err := store.client.CoreV1().Secrets("dr-backups").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, secrets.Items, 1)
+	assert.Equal(t, "app", secrets.Items[0].Labels[BackupNamespaceLabel])
+
+	backups, err := store.List(ctx, "app")
+	require.NoError(t, err)
+	require.Len(t, backups, 1)
+	assert.Equal(t, "app", backups[0].Namespace)
+}
+
+func TestBackup_VersionFor(t *testing.T) {
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := &Backup{Versions: []Version{
+		{Time: metav1.NewTime(base.Add(1 * time.Hour))},
+		{Time: metav1.NewTime(base.Add(2 * time.Hour))},
+		{Time: metav1.NewTime(base.Add(3 * time.Hour))},
+	}}
+
+	assert.Equal(t, &b.Versions[2], b.VersionFor(time.Time{}))
+	assert.Equal(t, &b.Versions[0], b.VersionFor(base))
+	assert.Equal(t, &b.Versions[1], b.VersionFor(base.Add(90*time.Minute)))
+	assert.Nil(t, b.VersionFor(base.Add(4*time.Hour)))
+	assert.Nil(t, (&Backup{}).VersionFor(time.Time{}))
+}
+
+func TestRestore(t *testing.T) {
+	ctx := context.Background()
+	current := newConfigMap("app", "settings", "synced")
+	client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), current)
+
+	b := &Backup{GVR: configMapGVR, Namespace: "app", Name: "settings"}
+	require.NoError(t, Restore(ctx, client, b, &Version{Object: newConfigMap("app", "settings", "previous")}))
+
+	restored, err := client.Resource(configMapGVR).Namespace("app").Get(ctx, "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	value, _, _ := unstructured.NestedString(restored.Object, "data", "key")
+	assert.Equal(t, "previous", value)
+
+	// Objects deleted since the backup are recreated
+	deleted := &Backup{GVR: configMapGVR, Namespace: "app", Name: "removed"}
+	require.NoError(t, Restore(ctx, client, deleted, &Version{Object: newConfigMap("app", "removed", "previous")}))
+	_, err = client.Resource(configMapGVR).Namespace("app").Get(ctx, "removed", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
@@ -3,50 +3,252 @@ package cli
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
 	"github.com/supporttools/dr-syncer/pkg/logging"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 )
 
-// Run executes the CLI operation with the given configuration
-func Run(config *Config) error {
+// Operation is a Stage, PreProvision, Cutover, Failback, Rollback or Export of the namespaces of a configuration.
+// It is the programmatic API of the CLI, cmd/cli only builds the Config from its flags and runs it.
+type Operation struct {
+	config     *Config
+	onProgress ProgressFunc
+
+	// Clients set with WithClients, the kubeconfigs of the configuration are used when nil
+	sourceClient        kubernetes.Interface
+	destClient          kubernetes.Interface
+	sourceDynamicClient dynamic.Interface
+	destDynamicClient   dynamic.Interface
+}
+
+// OperationOption configures an Operation
+type OperationOption func(*Operation)
+
+// WithProgress has fn called as the steps of the operation start and end
+func WithProgress(fn ProgressFunc) OperationOption {
+	return func(o *Operation) {
+		o.onProgress = fn
+	}
+}
+
+// WithClients has the operation use the given clients instead of creating them from the kubeconfigs
+// and contexts of the configuration
+func WithClients(sourceClient, destClient kubernetes.Interface, sourceDynamicClient, destDynamicClient dynamic.Interface) OperationOption {
+	return func(o *Operation) {
+		o.sourceClient = sourceClient
+		o.destClient = destClient
+		o.sourceDynamicClient = sourceDynamicClient
+		o.destDynamicClient = destDynamicClient
+	}
+}
+
+// NewOperation creates an operation for the given configuration. The configuration is not modified.
+func NewOperation(config *Config, options ...OperationOption) *Operation {
+	o := &Operation{config: config}
+	for _, option := range options {
+		option(o)
+	}
+	return o
+}
+
+// Stage syncs the resources to the destination and scales the destination down
+func (o *Operation) Stage(ctx context.Context) (*Result, error) {
+	return o.run(ctx, "Stage")
+}
+
+// PreProvision creates the PVCs in the destination without workloads or data, and reports the storage
+// they request per storage class
+func (o *Operation) PreProvision(ctx context.Context) (*Result, error) {
+	return o.run(ctx, "PreProvision")
+}
+
+// Cutover syncs the resources, scales the source down and the destination up
+func (o *Operation) Cutover(ctx context.Context) (*Result, error) {
+	return o.run(ctx, "Cutover")
+}
+
+// Failback scales the destination down and the source back up
+func (o *Operation) Failback(ctx context.Context) (*Result, error) {
+	return o.run(ctx, "Failback")
+}
+
+// Rollback restores the destination objects from the backups taken before they were overwritten
+func (o *Operation) Rollback(ctx context.Context) (*Result, error) {
+	return o.run(ctx, "Rollback")
+}
+
+// Export renders the objects Stage would create in the destination to config.ExportDir or config.ExportFile,
+// without connecting to the destination cluster
+func (o *Operation) Export(ctx context.Context) (*Result, error) {
+	return o.run(ctx, "Export")
+}
+
+// Run runs the operation of config.Mode
+func (o *Operation) Run(ctx context.Context) (*Result, error) {
+	return o.run(ctx, o.config.Mode)
+}
+
+// RunAll executes the CLI operation of config.Mode for every namespace mapping of the configuration.
+// Use NewOperation to get the results of the namespace mappings.
+func RunAll(config *Config) error {
+	_, err := NewOperation(config).Run(context.Background())
+	return err
+}
+
+// run runs the operation in mode for every namespace mapping of the configuration, running up to
+// config.Concurrency mappings at the same time. Without namespace mappings it runs the single
+// SourceNamespace/DestNamespace pair. The returned error is the error of the single pair, or lists
+// the failed namespace mappings.
+func (o *Operation) run(ctx context.Context, mode string) (*Result, error) {
+	config := *o.config
+	config.Mode = mode
+
+	start := time.Now()
+	result := &Result{Mode: mode}
+
+	if len(config.NamespaceMappings) == 0 {
+		mapping := MappingResult{Mapping: NamespaceMapping{Source: config.SourceNamespace, Destination: config.DestNamespace}}
+		mapping.Err = o.runMapping(ctx, &config, &mapping)
+		if mapping.Err == nil && mode == "Export" {
+			mapping.Err = writeExportStream(&config, []MappingResult{mapping})
+		}
+		mapping.Duration = time.Since(start)
+		result.Mappings = []MappingResult{mapping}
+		result.Duration = time.Since(start)
+		return result, mapping.Err
+	}
+
+	result.Mappings = runNamespaceMappings(&config, func(c *Config, mapping *MappingResult) error {
+		return o.runMapping(ctx, c, mapping)
+	})
+	if mode == "Export" {
+		if err := writeExportStream(&config, result.Mappings); err != nil {
+			result.Duration = time.Since(start)
+			return result, err
+		}
+	}
+	result.Duration = time.Since(start)
+	logSummary(result.Mappings)
+
+	var failed []string
+	for _, mapping := range result.Failed() {
+		failed = append(failed, mapping.Mapping.String())
+	}
+	if len(failed) > 0 {
+		return result, fmt.Errorf("%d of %d namespace mappings failed: %s", len(failed), len(result.Mappings), strings.Join(failed, ", "))
+	}
+	return result, nil
+}
+
+// clients returns the clients of the operation, creating them from the configuration unless they
+// were set with WithClients
+func (o *Operation) clients(config *Config) (kubernetes.Interface, kubernetes.Interface, dynamic.Interface, dynamic.Interface, error) {
+	if o.sourceClient != nil {
+		return o.sourceClient, o.destClient, o.sourceDynamicClient, o.destDynamicClient, nil
+	}
+	return setupClients(config)
+}
+
+// sourceClients returns the source clients of the operation, creating them from the configuration
+// unless they were set with WithClients
+func (o *Operation) sourceClients(config *Config) (kubernetes.Interface, dynamic.Interface, error) {
+	if o.sourceClient != nil {
+		return o.sourceClient, o.sourceDynamicClient, nil
+	}
+	return setupSourceClients(config)
+}
+
+// destClients returns the destination clients of the operation, creating them from the configuration
+// unless they were set with WithClients
+func (o *Operation) destClients(config *Config) (kubernetes.Interface, dynamic.Interface, error) {
+	if o.destClient != nil {
+		return o.destClient, o.destDynamicClient, nil
+	}
+	return setupDestClients(config)
+}
+
+// runMapping executes the operation for the single namespace pair of config, recording its steps in result
+func (o *Operation) runMapping(ctx context.Context, config *Config, result *MappingResult) error {
 	log := logging.SetupLogging()
 	log.Info("Starting DR Syncer CLI operation")
 
+	run := &mappingRun{mapping: result.Mapping, onProgress: o.onProgress, result: result}
+
+	// Rollback only touches the destination cluster, which may be all that is reachable
+	if config.Mode == "Rollback" {
+		destClient, destDynamicClient, err := o.destClients(config)
+		if err != nil {
+			return fmt.Errorf("failed to setup Kubernetes clients: %v", err)
+		}
+		if err := run.step("restore-backups", func() error {
+			return executeRollback(ctx, destClient, destDynamicClient, config, run)
+		}); err != nil {
+			return fmt.Errorf("rollback failed: %v", err)
+		}
+		log.Info("DR Syncer CLI operation completed successfully")
+		return nil
+	}
+
+	// Export only reads the source cluster, the destination may not exist yet
+	if config.Mode == "Export" {
+		sourceClient, sourceDynamicClient, err := o.sourceClients(config)
+		if err != nil {
+			return fmt.Errorf("failed to setup Kubernetes clients: %v", err)
+		}
+		if err := executeExport(ctx, sourceClient, sourceDynamicClient, config, run); err != nil {
+			return fmt.Errorf("export failed: %v", err)
+		}
+		log.Info("DR Syncer CLI operation completed successfully")
+		return nil
+	}
+
 	// Create Kubernetes clients
-	sourceClient, destClient, sourceDynamicClient, destDynamicClient, err := setupClients(config)
+	sourceClient, destClient, sourceDynamicClient, destDynamicClient, err := o.clients(config)
 	if err != nil {
 		return fmt.Errorf("failed to setup Kubernetes clients: %v", err)
 	}
 
-	// Create context
-	ctx := context.Background()
-
 	// Ensure destination namespace exists
 	if err := ensureNamespace(ctx, destClient, config.DestNamespace); err != nil {
 		return fmt.Errorf("failed to ensure destination namespace exists: %v", err)
 	}
 
+	// Record completed steps so a failed run can be resumed with --resume
+	run.progress, err = openProgress(config)
+	if err != nil {
+		return err
+	}
+
 	// Execute the appropriate mode
 	switch config.Mode {
 	case "Stage":
 		log.Info("Executing Stage mode")
-		if err := executeStageModeSync(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config); err != nil {
+		if err := executeStageModeSync(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config, run); err != nil {
 			return fmt.Errorf("stage mode failed: %v", err)
 		}
 
+	case "PreProvision":
+		log.Info("Executing PreProvision mode")
+		if err := executePreProvision(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config, run); err != nil {
+			return fmt.Errorf("pre-provision mode failed: %v", err)
+		}
+
 	case "Cutover":
 		log.Info("Executing Cutover mode")
-		if err := executeCutoverModeSync(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config); err != nil {
+		if err := executeCutoverModeSync(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config, run); err != nil {
 			return fmt.Errorf("cutover mode failed: %v", err)
 		}
 
 	case "Failback":
 		log.Info("Executing Failback mode")
-		if err := executeFailbackModeSync(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config); err != nil {
+		if err := executeFailbackModeSync(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config, run); err != nil {
 			return fmt.Errorf("failback mode failed: %v", err)
 		}
 
@@ -54,6 +256,9 @@ func Run(config *Config) error {
 		return fmt.Errorf("unknown mode: %s", config.Mode)
 	}
 
+	if err := run.progress.finish(); err != nil {
+		log.Warnf("Operation completed but its progress was not cleared: %v", err)
+	}
 	log.Info("DR Syncer CLI operation completed successfully")
 	return nil
 }
@@ -79,12 +284,7 @@ func ensureNamespace(ctx context.Context, client kubernetes.Interface, namespace
 
 	// Create namespace
 	log.Infof("Creating namespace %s", namespace)
-	nsObj := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name: namespace,
-		},
-	}
-	_, err = client.CoreV1().Namespaces().Create(ctx, nsObj, metav1.CreateOptions{})
+	_, err = client.CoreV1().Namespaces().Create(ctx, destinationNamespace(namespace), metav1.CreateOptions{})
 	if err != nil {
 		return fmt.Errorf("error creating namespace %s: %v", namespace, err)
 	}
@@ -92,3 +292,14 @@ func ensureNamespace(ctx context.Context, client kubernetes.Interface, namespace
 	log.Infof("Namespace %s created successfully", namespace)
 	return nil
 }
+
+// destinationNamespace returns the namespace the CLI creates in the destination cluster, labeled as
+// managed by dr-syncer like the namespaces the controller creates
+func destinationNamespace(namespace string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   namespace,
+			Labels: map[string]string{utils.ManagedByLabel: utils.ManagedByValue},
+		},
+	}
+}
@@ -1,11 +1,26 @@
 package cli
 
 import (
+	"context"
+	"fmt"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
 )
 
 // Test constants
@@ -25,6 +40,8 @@ func TestDefaultResourceTypes(t *testing.T) {
 	assert.Contains(t, DefaultResourceTypes, "ingresses")
 	assert.Contains(t, DefaultResourceTypes, "serviceaccounts")
 	assert.Contains(t, DefaultResourceTypes, "persistentvolumeclaims")
+	assert.Contains(t, DefaultResourceTypes, "cronjobs")
+	assert.Contains(t, DefaultResourceTypes, "jobs")
 }
 
 // Test Config struct
@@ -144,6 +161,18 @@ func TestShouldSyncResourceType_CustomWithExplicitTypes(t *testing.T) {
 	assert.False(t, config.ShouldSyncResourceType("othercrd", true))
 }
 
+func TestShouldSyncResourceType_Categories(t *testing.T) {
+	config := &Config{
+		ResourceTypes:        []string{"workloads", "cm"},
+		ExcludeResourceTypes: []string{"ds"},
+	}
+
+	assert.True(t, config.ShouldSyncResourceType("statefulsets", false))
+	assert.True(t, config.ShouldSyncResourceType("configmaps", false))
+	assert.False(t, config.ShouldSyncResourceType("daemonsets", false), "excluded by its alias")
+	assert.False(t, config.ShouldSyncResourceType("services", false))
+}
+
 // Test parseCommandLineArgs function
 func TestParseCommandLineArgs_Simple(t *testing.T) {
 	args, err := parseCommandLineArgs("--flag1 value1 --flag2 value2")
@@ -234,6 +263,30 @@ func TestParseCommandLineArgs_EmptyQuotedString(t *testing.T) {
 	assert.Equal(t, "value", args[2])
 }
 
+// Test pvMigrateArgs function
+func TestPvMigrateArgs_Contexts(t *testing.T) {
+	args, err := pvMigrateArgs(&Config{
+		SourceKubeconfig: "/kubeconfig/all",
+		SourceContext:    "prod",
+		DestContext:      "dr",
+		SourceNamespace:  "production",
+		DestNamespace:    "production-dr",
+		PVMigrateFlags:   "--strategy rsync",
+	}, "data", "data")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"--source", "data",
+		"--dest", "data",
+		"--source-namespace", "production",
+		"--dest-namespace", "production-dr",
+		"-k", "/kubeconfig/all",
+		"--source-context", "prod",
+		"--dest-context", "dr",
+		"--strategy", "rsync",
+	}, args)
+}
+
 // Test transformResource function
 func TestTransformResource_Basic(t *testing.T) {
 	resource := &unstructured.Unstructured{
@@ -406,6 +459,131 @@ func TestHandleDeploymentTransform_NoReplicas(t *testing.T) {
 	}
 }
 
+// Test handleCronJobTransform function
+func TestHandleCronJobTransform(t *testing.T) {
+	cronJob := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"metadata": map[string]interface{}{
+				"name":      "test-cronjob",
+				"namespace": "source-ns",
+				"ownerReferences": []interface{}{
+					map[string]interface{}{
+						"kind": "SomeOwner",
+						"name": "owner",
+					},
+				},
+			},
+			"spec": map[string]interface{}{
+				"schedule": "*/5 * * * *",
+			},
+		},
+	}
+
+	handleCronJobTransform(cronJob)
+
+	// Should record that the source CronJob was not suspended
+	assert.Equal(t, "false", cronJob.GetAnnotations()[utils.OriginalSuspendAnnotation])
+
+	// Should remove owner references
+	assert.Nil(t, cronJob.GetOwnerReferences())
+}
+
+func TestHandleCronJobTransform_KeepsExistingAnnotation(t *testing.T) {
+	cronJob := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "CronJob",
+			"metadata": map[string]interface{}{
+				"name": "test-cronjob",
+				"annotations": map[string]interface{}{
+					utils.OriginalSuspendAnnotation: "false",
+				},
+			},
+			"spec": map[string]interface{}{
+				"suspend": true,
+			},
+		},
+	}
+
+	handleCronJobTransform(cronJob)
+
+	assert.Equal(t, "false", cronJob.GetAnnotations()[utils.OriginalSuspendAnnotation])
+}
+
+// Test handleJobTransform function
+func TestHandleJobTransform(t *testing.T) {
+	job := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name": "test-job",
+				"labels": map[string]interface{}{
+					"app":                                "test",
+					"controller-uid":                     "abc",
+					"batch.kubernetes.io/controller-uid": "abc",
+				},
+			},
+			"spec": map[string]interface{}{
+				"suspend": true,
+				"selector": map[string]interface{}{
+					"matchLabels": map[string]interface{}{
+						"controller-uid": "abc",
+					},
+				},
+				"template": map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"labels": map[string]interface{}{
+							"app":            "test",
+							"controller-uid": "abc",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	handleJobTransform(job)
+
+	assert.Equal(t, "true", job.GetAnnotations()[utils.OriginalSuspendAnnotation])
+	assert.Equal(t, map[string]string{"app": "test"}, job.GetLabels())
+
+	_, found, _ := unstructured.NestedFieldNoCopy(job.Object, "spec", "selector")
+	assert.False(t, found)
+
+	templateLabels, _, _ := unstructured.NestedStringMap(job.Object, "spec", "template", "metadata", "labels")
+	assert.Equal(t, map[string]string{"app": "test"}, templateLabels)
+}
+
+// Test skipJob function
+func TestSkipJob(t *testing.T) {
+	standalone := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Job",
+		"metadata": map[string]interface{}{"name": "standalone"},
+	}}
+	assert.False(t, skipJob(standalone))
+
+	owned := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Job",
+		"metadata": map[string]interface{}{
+			"name": "owned",
+			"ownerReferences": []interface{}{
+				map[string]interface{}{"kind": "CronJob", "name": "nightly"},
+			},
+		},
+	}}
+	assert.True(t, skipJob(owned))
+
+	completed := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Job",
+		"metadata": map[string]interface{}{"name": "completed"},
+		"status":   map[string]interface{}{"completionTime": "2024-01-01T00:00:00Z"},
+	}}
+	assert.True(t, skipJob(completed))
+}
+
 // Test handleStatefulSetTransform function
 func TestHandleStatefulSetTransform(t *testing.T) {
 	replicas := int64(5)
@@ -563,6 +741,45 @@ func TestConfig_FailbackMode(t *testing.T) {
 	assert.True(t, config.ReverseMigratePVCData)
 }
 
+func TestSelectPVCs(t *testing.T) {
+	pvc := func(name string, labels map[string]string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+	}
+	pvcs := []corev1.PersistentVolumeClaim{
+		pvc("db-data", map[string]string{"app": "db"}),
+		pvc("db-wal", map[string]string{"app": "db"}),
+		pvc("uploads", map[string]string{"app": "web"}),
+		pvc("cache", nil),
+	}
+	names := func(pvcs []corev1.PersistentVolumeClaim) []string {
+		var result []string
+		for _, pvc := range pvcs {
+			result = append(result, pvc.Name)
+		}
+		return result
+	}
+
+	// Without a selection all PVCs are migrated
+	selected, skipped, err := selectPVCs(pvcs, &Config{})
+	require.NoError(t, err)
+	assert.Len(t, selected, 4)
+	assert.Empty(t, skipped)
+
+	selected, skipped, err = selectPVCs(pvcs, &Config{PVCSelector: "app=db"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"db-data", "db-wal"}, names(selected))
+	assert.Equal(t, []string{"uploads", "cache"}, skipped)
+
+	// Names and selector add up
+	selected, skipped, err = selectPVCs(pvcs, &Config{PVCNames: []string{"cache", "missing"}, PVCSelector: "app in (web)"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"uploads", "cache"}, names(selected))
+	assert.Equal(t, []string{"db-data", "db-wal"}, skipped)
+
+	_, _, err = selectPVCs(pvcs, &Config{PVCSelector: "app in db"})
+	assert.Error(t, err)
+}
+
 func TestTransformResource_Deployment(t *testing.T) {
 	replicas := int64(3)
 	resource := &unstructured.Unstructured{
@@ -708,3 +925,209 @@ func TestTransformResource_PreservesData(t *testing.T) {
 	assert.Equal(t, "key: value", data["config.yaml"])
 	assert.Equal(t, "setting=true", data["app.conf"])
 }
+
+// discoveryClientset is a fake clientset whose discovery returns its resources as the preferred
+// resources, the fake discovery of client-go returns none
+type discoveryClientset struct {
+	*fake.Clientset
+}
+
+func (c discoveryClientset) Discovery() discovery.DiscoveryInterface {
+	return preferredDiscovery{c.Clientset.Discovery().(*fakediscovery.FakeDiscovery)}
+}
+
+type preferredDiscovery struct {
+	*fakediscovery.FakeDiscovery
+}
+
+func (d preferredDiscovery) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	return d.Resources, nil
+}
+
+func TestOperationStage(t *testing.T) {
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "namespace": "shop"},
+		"data":       map[string]interface{}{"mode": "production"},
+	}}
+	listKinds := map[schema.GroupVersionResource]string{{Version: "v1", Resource: "configmaps"}: "ConfigMapList"}
+	sourceDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, configMap)
+	destDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+
+	sourceClient := fake.NewSimpleClientset()
+	sourceClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"}},
+	}}
+	destClient := fake.NewSimpleClientset()
+
+	var mu sync.Mutex
+	var events []ProgressEvent
+	config := &Config{Mode: "Cutover", SourceNamespace: "shop", DestNamespace: "shop-dr"}
+	operation := NewOperation(config,
+		WithClients(discoveryClientset{sourceClient}, destClient, sourceDynamicClient, destDynamicClient),
+		WithProgress(func(event ProgressEvent) {
+			mu.Lock()
+			defer mu.Unlock()
+			events = append(events, event)
+		}),
+	)
+
+	result, err := operation.Stage(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "Stage", result.Mode)
+	assert.Equal(t, "Cutover", config.Mode, "the configuration is not modified")
+	require.Len(t, result.Mappings, 1)
+	mapping := result.Mappings[0]
+	assert.Equal(t, "shop=shop-dr", mapping.Mapping.String())
+	assert.Equal(t, 1, mapping.ResourcesSynced)
+	assert.Zero(t, mapping.ResourcesFailed)
+	require.Len(t, mapping.Steps, 2)
+	assert.Equal(t, "sync-resources", mapping.Steps[0].Name)
+	assert.Equal(t, "scale-down-destination", mapping.Steps[1].Name)
+	assert.Empty(t, result.Failed())
+
+	var phases []string
+	for _, event := range events {
+		phases = append(phases, event.Step+" "+string(event.Phase))
+	}
+	assert.Equal(t, []string{
+		"sync-resources Started", "sync-resources Completed",
+		"scale-down-destination Started", "scale-down-destination Completed",
+	}, phases)
+
+	synced, err := destDynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("shop-dr").Get(context.Background(), "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "shop-dr", synced.GetNamespace())
+}
+
+func TestOperationFailedMappings(t *testing.T) {
+	destClient := fake.NewSimpleClientset()
+	destClient.PrependReactor("get", "namespaces", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	config := &Config{NamespaceMappings: []NamespaceMapping{{Source: "a", Destination: "a-dr"}, {Source: "b", Destination: "b-dr"}}}
+	operation := NewOperation(config, WithClients(fake.NewSimpleClientset(), destClient, nil, nil))
+
+	result, err := operation.Failback(context.Background())
+	assert.EqualError(t, err, "2 of 2 namespace mappings failed: a=a-dr, b=b-dr")
+	require.Len(t, result.Failed(), 2)
+	assert.Contains(t, result.Failed()[0].Err.Error(), "failed to ensure destination namespace exists")
+}
+
+func TestHandlePVCTransform(t *testing.T) {
+	pvc := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "PersistentVolumeClaim",
+		"metadata": map[string]interface{}{
+			"name": "data",
+			"annotations": map[string]interface{}{
+				"pv.kubernetes.io/bind-completed":    "yes",
+				"volume.kubernetes.io/selected-node": "node-1",
+				"team":                               "shop",
+			},
+		},
+		"spec": map[string]interface{}{
+			"volumeName":       "pvc-1234",
+			"storageClassName": "fast",
+		},
+	}}
+
+	handlePVCTransform(pvc)
+
+	_, found, _ := unstructured.NestedString(pvc.Object, "spec", "volumeName")
+	assert.False(t, found)
+	assert.Equal(t, map[string]string{"team": "shop"}, pvc.GetAnnotations())
+	storageClass, _, _ := unstructured.NestedString(pvc.Object, "spec", "storageClassName")
+	assert.Equal(t, "fast", storageClass)
+}
+
+func testPVC(name, storageClass, size string) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			VolumeName: "pv-" + name,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+	if storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+	return pvc
+}
+
+func TestOperationPreProvision(t *testing.T) {
+	pvcs := []*corev1.PersistentVolumeClaim{
+		testPVC("data", "fast", "10Gi"),
+		testPVC("logs", "fast", "5Gi"),
+		testPVC("cache", "", "1Gi"),
+		testPVC("archive", "slow", "100Gi"),
+	}
+	sourceObjects := []runtime.Object{&unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "namespace": "shop"},
+	}}}
+	sourceClient := fake.NewSimpleClientset()
+	for _, pvc := range pvcs {
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(pvc)
+		require.NoError(t, err)
+		object := &unstructured.Unstructured{Object: content}
+		object.SetAPIVersion("v1")
+		object.SetKind("PersistentVolumeClaim")
+		sourceObjects = append(sourceObjects, object)
+		require.NoError(t, sourceClient.Tracker().Add(pvc))
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}:             "ConfigMapList",
+		{Version: "v1", Resource: "persistentvolumeclaims"}: "PersistentVolumeClaimList",
+	}
+	sourceDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, sourceObjects...)
+	destDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds)
+	sourceClient.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "v1",
+		APIResources: []metav1.APIResource{
+			{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"},
+			{Name: "persistentvolumeclaims", Namespaced: true, Kind: "PersistentVolumeClaim"},
+		},
+	}}
+	destClient := fake.NewSimpleClientset(&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{
+		Name:        "fast",
+		Annotations: map[string]string{defaultStorageClassAnnotation: "true"},
+	}})
+
+	config := &Config{SourceNamespace: "shop", DestNamespace: "shop-dr"}
+	operation := NewOperation(config, WithClients(discoveryClientset{sourceClient}, destClient, sourceDynamicClient, destDynamicClient))
+
+	result, err := operation.PreProvision(context.Background())
+	require.NoError(t, err)
+	mapping := result.Mappings[0]
+	assert.Equal(t, 4, mapping.ResourcesSynced)
+	require.Len(t, mapping.Steps, 1)
+	assert.Equal(t, "provision-pvcs", mapping.Steps[0].Name)
+
+	// Only the PVCs are created, unbound from the source volumes
+	ctx := context.Background()
+	pvcResource := schema.GroupVersionResource{Version: "v1", Resource: "persistentvolumeclaims"}
+	created, err := destDynamicClient.Resource(pvcResource).Namespace("shop-dr").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	_, found, _ := unstructured.NestedString(created.Object, "spec", "volumeName")
+	assert.False(t, found)
+	configMaps, err := destDynamicClient.Resource(schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}).Namespace("shop-dr").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, configMaps.Items)
+
+	// The PVC without a storage class counts towards the default storage class
+	capacity := result.Capacity()
+	require.Len(t, capacity, 2)
+	assert.Equal(t, "fast", capacity[0].StorageClass)
+	assert.Equal(t, 3, capacity[0].PVCs)
+	assert.Equal(t, "16Gi", capacity[0].Requested.String())
+	assert.False(t, capacity[0].Missing)
+	assert.Equal(t, "slow", capacity[1].StorageClass)
+	assert.Equal(t, "100Gi", capacity[1].Requested.String())
+	assert.True(t, capacity[1].Missing)
+}
@@ -18,7 +18,7 @@ func setupClients(config *Config) (kubernetes.Interface, kubernetes.Interface, d
 
 	// Create source client
 	log.Info("Creating source cluster client")
-	sourceConfig, err := loadKubeconfig(config.SourceKubeconfig)
+	sourceConfig, err := loadKubeconfig(config.SourceKubeconfig, config.SourceContext)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to load source kubeconfig: %v", err)
 	}
@@ -35,7 +35,7 @@ func setupClients(config *Config) (kubernetes.Interface, kubernetes.Interface, d
 
 	// Create destination client
 	log.Info("Creating destination cluster client")
-	destConfig, err := loadKubeconfig(config.DestKubeconfig)
+	destConfig, err := loadKubeconfig(config.DestKubeconfig, config.DestContext)
 	if err != nil {
 		return nil, nil, nil, nil, fmt.Errorf("failed to load destination kubeconfig: %v", err)
 	}
@@ -67,22 +67,84 @@ func setupClients(config *Config) (kubernetes.Interface, kubernetes.Interface, d
 	return sourceClient, destClient, sourceDynamicClient, destDynamicClient, nil
 }
 
-// loadKubeconfig loads a kubeconfig file from the given path
-func loadKubeconfig(kubeconfigPath string) (*rest.Config, error) {
-	// If path starts with ~, expand it
-	if kubeconfigPath[:1] == "~" {
-		homeDir, err := os.UserHomeDir()
-		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %v", err)
-		}
-		kubeconfigPath = filepath.Join(homeDir, kubeconfigPath[1:])
+// setupDestClients creates Kubernetes clients for the destination cluster only
+func setupDestClients(config *Config) (kubernetes.Interface, dynamic.Interface, error) {
+	log := logging.SetupLogging()
+
+	log.Info("Creating destination cluster client")
+	destConfig, err := loadKubeconfig(config.DestKubeconfig, config.DestContext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load destination kubeconfig: %v", err)
+	}
+
+	destClient, err := kubernetes.NewForConfig(destConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create destination Kubernetes client: %v", err)
+	}
+
+	destDynamicClient, err := dynamic.NewForConfig(destConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create destination dynamic client: %v", err)
+	}
+
+	log.Info("Testing connectivity to destination cluster")
+	if _, err := destClient.Discovery().ServerVersion(); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to destination cluster: %v", err)
+	}
+
+	return destClient, destDynamicClient, nil
+}
+
+// setupSourceClients creates Kubernetes clients for the source cluster only
+func setupSourceClients(config *Config) (kubernetes.Interface, dynamic.Interface, error) {
+	log := logging.SetupLogging()
+
+	log.Info("Creating source cluster client")
+	sourceConfig, err := loadKubeconfig(config.SourceKubeconfig, config.SourceContext)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load source kubeconfig: %v", err)
+	}
+
+	sourceClient, err := kubernetes.NewForConfig(sourceConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create source Kubernetes client: %v", err)
+	}
+
+	sourceDynamicClient, err := dynamic.NewForConfig(sourceConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create source dynamic client: %v", err)
+	}
+
+	log.Info("Testing connectivity to source cluster")
+	if _, err := sourceClient.Discovery().ServerVersion(); err != nil {
+		return nil, nil, fmt.Errorf("failed to connect to source cluster: %v", err)
 	}
 
-	// Check if file exists
-	if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("kubeconfig file not found: %s", kubeconfigPath)
+	return sourceClient, sourceDynamicClient, nil
+}
+
+// loadKubeconfig loads a kubeconfig file from the given path using the named context. An empty
+// path falls back to $KUBECONFIG or ~/.kube/config, and an empty context uses the current context.
+func loadKubeconfig(kubeconfigPath, contextName string) (*rest.Config, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfigPath != "" {
+		// If path starts with ~, expand it
+		if kubeconfigPath[:1] == "~" {
+			homeDir, err := os.UserHomeDir()
+			if err != nil {
+				return nil, fmt.Errorf("failed to get home directory: %v", err)
+			}
+			kubeconfigPath = filepath.Join(homeDir, kubeconfigPath[1:])
+		}
+
+		// Check if file exists
+		if _, err := os.Stat(kubeconfigPath); os.IsNotExist(err) {
+			return nil, fmt.Errorf("kubeconfig file not found: %s", kubeconfigPath)
+		}
+		loadingRules.ExplicitPath = kubeconfigPath
 	}
 
 	// Load the kubeconfig file
-	return clientcmd.BuildConfigFromFlags("", kubeconfigPath)
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, overrides).ClientConfig()
 }
@@ -1,5 +1,13 @@
 package cli
 
+import (
+	"fmt"
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
 // Config represents the configuration for the CLI
 type Config struct {
 	// Required fields
@@ -7,17 +15,51 @@ type Config struct {
 	DestKubeconfig   string
 	SourceNamespace  string
 	DestNamespace    string
-	Mode             string // Stage, Cutover, Failback
+	Mode             string // Stage, PreProvision, Cutover, Failback, Rollback, Export
+
+	// Multiple namespaces, replacing SourceNamespace and DestNamespace when set
+	NamespaceMappings []NamespaceMapping
+	Concurrency       int // Number of namespace mappings processed at the same time
+
+	// Kubeconfig contexts, defaulting to the kubeconfig's current context
+	SourceContext string
+	DestContext   string
 
 	// Optional fields
 	IncludeCustomResources bool
 	MigratePVCData         bool
 	ReverseMigratePVCData  bool
-	ResourceTypes          []string // If empty, defaults will be used
+	ResourceTypes          []string // If empty, defaults will be used. Aliases and categories such as "workloads" are expanded
 	ExcludeResourceTypes   []string
+	SuspendCronJobs        bool // Create CronJobs and Jobs suspended in the destination until cutover
 
 	// PV-migrate options
 	PVMigrateFlags string // Additional flags to pass to pv-migrate
+
+	// PVC selection, the data of all PVCs is migrated when neither is set
+	PVCNames    []string // Names of the PVCs whose data is migrated
+	PVCSelector string   // Label selector of the PVCs whose data is migrated
+
+	// Backup options
+	Backup          bool      // Back up destination objects before they are overwritten
+	BackupRetention int       // Number of versions kept per object
+	BackupNamespace string    // Namespace backups are stored in, defaults to the destination namespace
+	RollbackSince   time.Time // Rollback restores the state before the first sync after this time, or the latest backup if zero
+
+	// Hooks options
+	HooksConfig string // Path to a hooks file whose postCutover hooks run after a successful Cutover
+
+	// Notification options
+	NotifyWebhookURL    string // Webhook notified when a Cutover starts and completes
+	NotifyWebhookFormat string // Payload format of the webhook: Generic, Slack or Teams
+
+	// Export options, one of them is required by Export
+	ExportDir  string // Directory the manifests are written to, one subdirectory per destination namespace and one file per object
+	ExportFile string // File the manifests are written to as a single YAML stream, "-" for stdout
+
+	// Resume options
+	StateDir string // Directory the completed steps of an operation are recorded in, nothing is recorded if empty
+	Resume   bool   // Skip the steps completed by a previous run of the same operation
 }
 
 // Standard Kubernetes resources to sync by default
@@ -35,10 +77,13 @@ var DefaultResourceTypes = []string{
 	"persistentvolumeclaims",
 	"horizontalpodautoscalers",
 	"networkpolicies",
+	"cronjobs",
+	"jobs",
 }
 
 // ShouldSyncResourceType determines if a resource type should be synchronized
-// based on the configuration
+// based on the configuration. The included and excluded types may use the aliases
+// and categories shared with the controller, such as "deploy" or "networking".
 func (c *Config) ShouldSyncResourceType(resourceType string, isCustomResource bool) bool {
 	// If it's a custom resource and we're not including custom resources, skip it
 	if isCustomResource && !c.IncludeCustomResources {
@@ -46,7 +91,7 @@ func (c *Config) ShouldSyncResourceType(resourceType string, isCustomResource bo
 	}
 
 	// If resource is in the exclude list, skip it
-	for _, excludeType := range c.ExcludeResourceTypes {
+	for _, excludeType := range utils.ExpandResourceTypes(c.ExcludeResourceTypes) {
 		if excludeType == resourceType {
 			return false
 		}
@@ -54,7 +99,7 @@ func (c *Config) ShouldSyncResourceType(resourceType string, isCustomResource bo
 
 	// If specific resource types are provided, check if this type is included
 	if len(c.ResourceTypes) > 0 {
-		for _, includeType := range c.ResourceTypes {
+		for _, includeType := range utils.ExpandResourceTypes(c.ResourceTypes) {
 			if includeType == resourceType {
 				return true
 			}
@@ -76,3 +121,27 @@ func (c *Config) ShouldSyncResourceType(resourceType string, isCustomResource bo
 	// types were listed, then include it (we've already checked IncludeCustomResources above)
 	return isCustomResource
 }
+
+// PVCFilter returns whether the data of a PVC is migrated: PVCs listed in PVCNames or matching
+// PVCSelector are, all PVCs are when neither is set
+func (c *Config) PVCFilter() (func(name string, pvcLabels map[string]string) bool, error) {
+	if len(c.PVCNames) == 0 && c.PVCSelector == "" {
+		return func(string, map[string]string) bool { return true }, nil
+	}
+
+	var selector labels.Selector
+	if c.PVCSelector != "" {
+		parsed, err := labels.Parse(c.PVCSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid PVC selector %q: %v", c.PVCSelector, err)
+		}
+		selector = parsed
+	}
+	names := make(map[string]bool, len(c.PVCNames))
+	for _, name := range c.PVCNames {
+		names[name] = true
+	}
+	return func(name string, pvcLabels map[string]string) bool {
+		return names[name] || (selector != nil && selector.Matches(labels.Set(pvcLabels)))
+	}, nil
+}
@@ -0,0 +1,206 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+)
+
+// exportHeader starts the manifest files written to an export directory. Stale manifests are only
+// removed when they start with it, so files added next to them, such as a kustomization.yaml, are kept.
+const exportHeader = "# Exported by dr-syncer, changes are overwritten by the next export\n"
+
+// executeExport handles the Export mode operation:
+// 1. Render the resources of the source namespace for the destination namespace, like Stage syncs them
+// 2. Scale the rendered workloads to zero, the standby state Stage leaves the destination in
+// 3. Write the manifests to the export directory, or keep them for the YAML stream of the export file
+func executeExport(
+	ctx context.Context,
+	sourceClient kubernetes.Interface,
+	sourceDynamicClient dynamic.Interface,
+	config *Config,
+	run *mappingRun,
+) error {
+	log := logging.SetupLogging()
+	log.Info("Executing Export mode")
+
+	namespace := &unstructured.Unstructured{}
+	namespace.SetAPIVersion("v1")
+	namespace.SetKind("Namespace")
+	namespace.SetName(config.DestNamespace)
+	namespace.SetLabels(destinationNamespace(config.DestNamespace).Labels)
+	objects := []*unstructured.Unstructured{namespace}
+
+	if err := run.step("render-resources", func() error {
+		_, failed, err := renderResources(ctx, sourceClient, sourceDynamicClient, config, func(_ schema.GroupVersionResource, transformed *unstructured.Unstructured) bool {
+			if err := scaleToStandby(transformed); err != nil {
+				log.Warnf("Failed to scale resource %s/%s to zero: %v", transformed.GetKind(), transformed.GetName(), err)
+				return false
+			}
+			objects = append(objects, transformed)
+			return true
+		})
+		run.result.ResourcesFailed += failed
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to render resources: %v", err)
+	}
+
+	sortExported(objects[1:])
+	run.result.ObjectsExported = len(objects)
+
+	if config.ExportDir == "" {
+		manifests, err := marshalManifests(objects)
+		if err != nil {
+			return err
+		}
+		run.result.manifests = manifests
+		log.Infof("Rendered %d objects for namespace %s", len(objects), config.DestNamespace)
+		return nil
+	}
+
+	if err := run.step("write-manifests", func() error {
+		return writeExportDir(filepath.Join(config.ExportDir, config.DestNamespace), objects)
+	}); err != nil {
+		return fmt.Errorf("failed to write manifests: %v", err)
+	}
+	log.Infof("Exported %d objects to %s", len(objects), filepath.Join(config.ExportDir, config.DestNamespace))
+	return nil
+}
+
+// scaleToStandby scales a rendered Deployment or StatefulSet to zero replicas, its original replicas are
+// kept in the annotation set by transformResource
+func scaleToStandby(resource *unstructured.Unstructured) error {
+	switch resource.GetKind() {
+	case "Deployment", "StatefulSet":
+		return unstructured.SetNestedField(resource.Object, int64(0), "spec", "replicas")
+	}
+	return nil
+}
+
+// sortExported orders rendered objects by kind and name, so exports of unchanged namespaces are identical
+func sortExported(objects []*unstructured.Unstructured) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		if ki, kj := exportedKind(objects[i]), exportedKind(objects[j]); ki != kj {
+			return ki < kj
+		}
+		return objects[i].GetName() < objects[j].GetName()
+	})
+}
+
+// exportedKind returns the lower case kind of an object, qualified by its API group outside the core group
+func exportedKind(object *unstructured.Unstructured) string {
+	kind := strings.ToLower(object.GetKind())
+	if group := object.GroupVersionKind().Group; group != "" {
+		kind += "." + group
+	}
+	return kind
+}
+
+// exportedManifest returns the YAML manifest of a rendered object, without the fields the destination
+// cluster sets
+func exportedManifest(object *unstructured.Unstructured) ([]byte, error) {
+	exported := object.DeepCopy()
+	delete(exported.Object, "status")
+	unstructured.RemoveNestedField(exported.Object, "metadata", "generation")
+
+	manifest, err := yaml.Marshal(exported.Object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s/%s: %v", object.GetKind(), object.GetName(), err)
+	}
+	return manifest, nil
+}
+
+// marshalManifests returns the YAML stream of rendered objects
+func marshalManifests(objects []*unstructured.Unstructured) ([]byte, error) {
+	var stream bytes.Buffer
+	for _, object := range objects {
+		manifest, err := exportedManifest(object)
+		if err != nil {
+			return nil, err
+		}
+		stream.WriteString("---\n")
+		stream.Write(manifest)
+	}
+	return stream.Bytes(), nil
+}
+
+// writeExportDir writes one manifest file per rendered object to dir and removes the manifests of
+// objects exported before that are no longer rendered
+func writeExportDir(dir string, objects []*unstructured.Unstructured) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create export directory %s: %v", dir, err)
+	}
+
+	written := make(map[string]bool, len(objects))
+	for _, object := range objects {
+		manifest, err := exportedManifest(object)
+		if err != nil {
+			return err
+		}
+		name := fmt.Sprintf("%s-%s.yaml", exportedKind(object), object.GetName())
+		if err := os.WriteFile(filepath.Join(dir, name), append([]byte(exportHeader), manifest...), 0o644); err != nil {
+			return fmt.Errorf("failed to write manifest %s: %v", name, err)
+		}
+		written[name] = true
+	}
+
+	stale, err := filepath.Glob(filepath.Join(dir, "*.yaml"))
+	if err != nil {
+		return fmt.Errorf("failed to list manifests in %s: %v", dir, err)
+	}
+	for _, path := range stale {
+		if written[filepath.Base(path)] {
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %v", path, err)
+		}
+		if !bytes.HasPrefix(content, []byte(exportHeader)) {
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove stale manifest %s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// writeExportStream writes the manifests rendered for the namespace mappings that succeeded to
+// config.ExportFile as a single YAML stream, in the order of the mappings
+func writeExportStream(config *Config, mappings []MappingResult) error {
+	if config.ExportFile == "" {
+		return nil
+	}
+
+	var stream bytes.Buffer
+	for _, mapping := range mappings {
+		if mapping.Err == nil {
+			stream.Write(mapping.manifests)
+		}
+	}
+
+	if config.ExportFile == "-" {
+		if _, err := os.Stdout.Write(stream.Bytes()); err != nil {
+			return fmt.Errorf("failed to write manifests to stdout: %v", err)
+		}
+		return nil
+	}
+	if err := os.WriteFile(config.ExportFile, stream.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write manifests to %s: %v", config.ExportFile, err)
+	}
+	logging.SetupLogging().Infof("Exported manifests to %s", config.ExportFile)
+	return nil
+}
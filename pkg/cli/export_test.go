@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+)
+
+// exportTestOperation returns an Export of config reading a source cluster with a ConfigMap and a
+// Deployment in each of the namespaces
+func exportTestOperation(config *Config, namespaces ...string) *Operation {
+	var objects []runtime.Object
+	for _, namespace := range namespaces {
+		objects = append(objects,
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "settings", "namespace": namespace, "resourceVersion": "42"},
+				"data":       map[string]interface{}{"mode": "production"},
+			}},
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata":   map[string]interface{}{"name": "web", "namespace": namespace, "generation": int64(3)},
+				"spec":       map[string]interface{}{"replicas": int64(3)},
+				"status":     map[string]interface{}{"readyReplicas": int64(3)},
+			}},
+		)
+	}
+	listKinds := map[schema.GroupVersionResource]string{
+		{Version: "v1", Resource: "configmaps"}:                 "ConfigMapList",
+		{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+	}
+	sourceDynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), listKinds, objects...)
+
+	sourceClient := fake.NewSimpleClientset()
+	sourceClient.Resources = []*metav1.APIResourceList{
+		{GroupVersion: "apps/v1", APIResources: []metav1.APIResource{{Name: "deployments", Namespaced: true, Kind: "Deployment"}}},
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{{Name: "configmaps", Namespaced: true, Kind: "ConfigMap"}}},
+	}
+	return NewOperation(config, WithClients(discoveryClientset{sourceClient}, nil, sourceDynamicClient, nil))
+}
+
+func TestOperationExport_Dir(t *testing.T) {
+	dir := t.TempDir()
+	namespaceDir := filepath.Join(dir, "shop-dr")
+	require.NoError(t, os.MkdirAll(namespaceDir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(namespaceDir, "secret-old.yaml"), []byte(exportHeader+"kind: Secret\n"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(namespaceDir, "kustomization.yaml"), []byte("resources: []\n"), 0o644))
+
+	config := &Config{SourceNamespace: "shop", DestNamespace: "shop-dr", SuspendCronJobs: true, ExportDir: dir}
+	result, err := exportTestOperation(config, "shop").Export(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 3, result.Mappings[0].ObjectsExported)
+
+	entries, err := os.ReadDir(namespaceDir)
+	require.NoError(t, err)
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	assert.ElementsMatch(t, []string{
+		"namespace-shop-dr.yaml",
+		"configmap-settings.yaml",
+		"deployment.apps-web.yaml",
+		"kustomization.yaml",
+	}, names, "stale manifests are removed, other files are kept")
+
+	content, err := os.ReadFile(filepath.Join(namespaceDir, "deployment.apps-web.yaml"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(content), exportHeader))
+	var deployment map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &deployment))
+	object := &unstructured.Unstructured{Object: deployment}
+	assert.Equal(t, "shop-dr", object.GetNamespace())
+	assert.Equal(t, "3", object.GetAnnotations()[OriginalReplicasAnnotation])
+	replicas, _, _ := unstructured.NestedInt64(object.Object, "spec", "replicas")
+	assert.Zero(t, replicas, "workloads are exported in standby")
+	assert.NotContains(t, object.Object, "status")
+	assert.Zero(t, object.GetGeneration())
+
+	content, err = os.ReadFile(filepath.Join(namespaceDir, "namespace-shop-dr.yaml"))
+	require.NoError(t, err)
+	var namespace map[string]interface{}
+	require.NoError(t, yaml.Unmarshal(content, &namespace))
+	labels := (&unstructured.Unstructured{Object: namespace}).GetLabels()
+	assert.Equal(t, utils.ManagedByValue, labels[utils.ManagedByLabel])
+}
+
+func TestOperationExport_File(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "dr.yaml")
+	config := &Config{
+		NamespaceMappings: []NamespaceMapping{{Source: "shop", Destination: "shop-dr"}, {Source: "blog", Destination: "blog-dr"}},
+		ExportFile:        file,
+	}
+	result, err := exportTestOperation(config, "shop", "blog").Export(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, result.Failed())
+
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	documents := strings.Split(strings.TrimPrefix(string(content), "---\n"), "---\n")
+	require.Len(t, documents, 6)
+
+	var kinds []string
+	for _, document := range documents {
+		var object map[string]interface{}
+		require.NoError(t, yaml.Unmarshal([]byte(document), &object))
+		u := &unstructured.Unstructured{Object: object}
+		kinds = append(kinds, u.GetKind()+" "+u.GetNamespace()+u.GetName())
+	}
+	assert.Equal(t, []string{
+		"Namespace shop-dr", "ConfigMap shop-drsettings", "Deployment shop-drweb",
+		"Namespace blog-dr", "ConfigMap blog-drsettings", "Deployment blog-drweb",
+	}, kinds, "the stream follows the order of the mappings")
+}
@@ -0,0 +1,281 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/yaml"
+)
+
+// Hook clusters
+const (
+	HookClusterSource      = "source"
+	HookClusterDestination = "destination"
+)
+
+// defaultWebhookTimeout bounds a webhook call when the hook sets no timeout
+const defaultWebhookTimeout = 30 * time.Second
+
+// hookGVRs are the kinds an annotate hook can update
+var hookGVRs = map[string]schema.GroupVersionResource{
+	"Ingress": {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	"Service": {Group: "", Version: "v1", Resource: "services"},
+}
+
+// HooksConfig is the hooks file passed with --hooks-config
+type HooksConfig struct {
+	// PostCutover hooks run in order once Cutover has scaled up the destination
+	PostCutover []Hook `json:"postCutover"`
+}
+
+// Hook is a single hook, exactly one of Webhook and Annotate must be set
+type Hook struct {
+	Name string `json:"name"`
+
+	// IgnoreFailure logs a failure of this hook instead of failing the cutover
+	IgnoreFailure bool `json:"ignoreFailure,omitempty"`
+
+	Webhook  *WebhookHook  `json:"webhook,omitempty"`
+	Annotate *AnnotateHook `json:"annotate,omitempty"`
+}
+
+// WebhookHook calls an HTTP endpoint. The body is a Go template rendered with HookContext,
+// defaulting to a JSON document describing the cutover.
+type WebhookHook struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method,omitempty"` // Defaults to POST
+	Headers map[string]string `json:"headers,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Timeout string            `json:"timeout,omitempty"` // Go duration, defaults to 30s
+}
+
+// AnnotateHook sets and removes annotations on Ingresses or Services, e.g. to move an
+// external-dns hostname from the source to the destination cluster
+type AnnotateHook struct {
+	Cluster           string            `json:"cluster,omitempty"` // source or destination, defaults to destination
+	Kind              string            `json:"kind"`              // Ingress or Service
+	Names             []string          `json:"names,omitempty"`   // Defaults to all objects of Kind in the namespace
+	Annotations       map[string]string `json:"annotations,omitempty"`
+	RemoveAnnotations []string          `json:"removeAnnotations,omitempty"`
+}
+
+// HookContext is the data available to webhook body templates
+type HookContext struct {
+	Event           string `json:"event"`
+	SourceNamespace string `json:"sourceNamespace"`
+	DestNamespace   string `json:"destNamespace"`
+	Timestamp       string `json:"timestamp"`
+}
+
+// loadHooksConfig reads and validates a hooks file, an empty path returns no hooks
+func loadHooksConfig(path string) (*HooksConfig, error) {
+	if path == "" {
+		return &HooksConfig{}, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read hooks config: %v", err)
+	}
+
+	var hooks HooksConfig
+	if err := yaml.UnmarshalStrict(data, &hooks); err != nil {
+		return nil, fmt.Errorf("failed to parse hooks config %s: %v", path, err)
+	}
+
+	for i := range hooks.PostCutover {
+		if err := hooks.PostCutover[i].validate(); err != nil {
+			return nil, fmt.Errorf("invalid hook %d in %s: %v", i, path, err)
+		}
+	}
+	return &hooks, nil
+}
+
+// validate checks a hook before any cluster is modified
+func (h *Hook) validate() error {
+	if h.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if (h.Webhook == nil) == (h.Annotate == nil) {
+		return fmt.Errorf("hook %s must set exactly one of webhook and annotate", h.Name)
+	}
+
+	if h.Webhook != nil {
+		if h.Webhook.URL == "" {
+			return fmt.Errorf("hook %s: webhook url is required", h.Name)
+		}
+		if h.Webhook.Timeout != "" {
+			if _, err := time.ParseDuration(h.Webhook.Timeout); err != nil {
+				return fmt.Errorf("hook %s: invalid webhook timeout: %v", h.Name, err)
+			}
+		}
+		if _, err := template.New(h.Name).Parse(h.Webhook.Body); err != nil {
+			return fmt.Errorf("hook %s: invalid webhook body template: %v", h.Name, err)
+		}
+	}
+
+	if h.Annotate != nil {
+		if _, ok := hookGVRs[h.Annotate.Kind]; !ok {
+			return fmt.Errorf("hook %s: unsupported kind %q, must be Ingress or Service", h.Name, h.Annotate.Kind)
+		}
+		switch h.Annotate.Cluster {
+		case "", HookClusterSource, HookClusterDestination:
+		default:
+			return fmt.Errorf("hook %s: cluster must be %s or %s", h.Name, HookClusterSource, HookClusterDestination)
+		}
+		if len(h.Annotate.Annotations) == 0 && len(h.Annotate.RemoveAnnotations) == 0 {
+			return fmt.Errorf("hook %s: annotate sets no annotations and removes none", h.Name)
+		}
+	}
+	return nil
+}
+
+// runHooks executes hooks in order. Every hook runs even when an earlier one failed, the failures
+// of hooks that do not ignore them are returned together.
+func runHooks(ctx context.Context, hooks []Hook, sourceDynamicClient, destDynamicClient dynamic.Interface, hookCtx HookContext) error {
+	log := logging.SetupLogging()
+
+	var failed []string
+	for i := range hooks {
+		hook := &hooks[i]
+		log.Infof("Running %s hook %s", hookCtx.Event, hook.Name)
+
+		var err error
+		switch {
+		case hook.Webhook != nil:
+			err = runWebhookHook(ctx, hook.Webhook, hookCtx)
+		case hook.Annotate != nil:
+			client := destDynamicClient
+			namespace := hookCtx.DestNamespace
+			if hook.Annotate.Cluster == HookClusterSource {
+				client = sourceDynamicClient
+				namespace = hookCtx.SourceNamespace
+			}
+			err = runAnnotateHook(ctx, client, namespace, hook.Annotate)
+		}
+
+		if err != nil {
+			if hook.IgnoreFailure {
+				log.Warnf("Hook %s failed, ignoring: %v", hook.Name, err)
+				continue
+			}
+			log.Errorf("Hook %s failed: %v", hook.Name, err)
+			failed = append(failed, fmt.Sprintf("%s: %v", hook.Name, err))
+			continue
+		}
+		log.Infof("Hook %s completed", hook.Name)
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d hooks failed: %s", len(failed), strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// runWebhookHook calls the webhook and fails on a non-2xx response
+func runWebhookHook(ctx context.Context, hook *WebhookHook, hookCtx HookContext) error {
+	body, err := renderWebhookBody(hook, hookCtx)
+	if err != nil {
+		return err
+	}
+
+	timeout := defaultWebhookTimeout
+	if hook.Timeout != "" {
+		timeout, _ = time.ParseDuration(hook.Timeout)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	method := hook.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range hook.Headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request to %s failed: %v", hook.URL, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s %s returned %s", method, hook.URL, resp.Status)
+	}
+	return nil
+}
+
+// renderWebhookBody renders the body template, or the hook context as JSON when there is none
+func renderWebhookBody(hook *WebhookHook, hookCtx HookContext) ([]byte, error) {
+	if hook.Body == "" {
+		return json.Marshal(hookCtx)
+	}
+
+	tmpl, err := template.New("body").Parse(hook.Body)
+	if err != nil {
+		return nil, fmt.Errorf("invalid body template: %v", err)
+	}
+	var body bytes.Buffer
+	if err := tmpl.Execute(&body, hookCtx); err != nil {
+		return nil, fmt.Errorf("failed to render body template: %v", err)
+	}
+	return body.Bytes(), nil
+}
+
+// runAnnotateHook merge-patches the annotations of the selected objects
+func runAnnotateHook(ctx context.Context, client dynamic.Interface, namespace string, hook *AnnotateHook) error {
+	log := logging.SetupLogging()
+	resource := client.Resource(hookGVRs[hook.Kind]).Namespace(namespace)
+
+	names := hook.Names
+	if len(names) == 0 {
+		list, err := resource.List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list %s objects: %v", hook.Kind, err)
+		}
+		for _, item := range list.Items {
+			names = append(names, item.GetName())
+		}
+	}
+
+	annotations := make(map[string]interface{}, len(hook.Annotations)+len(hook.RemoveAnnotations))
+	for _, key := range hook.RemoveAnnotations {
+		annotations[key] = nil
+	}
+	for key, value := range hook.Annotations {
+		annotations[key] = value
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		if _, err := resource.Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to annotate %s %s/%s: %v", hook.Kind, namespace, name, err)
+		}
+		log.Infof("Annotated %s %s/%s", hook.Kind, namespace, name)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func writeHooksConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "hooks.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadHooksConfig(t *testing.T) {
+	hooks, err := loadHooksConfig("")
+	require.NoError(t, err)
+	assert.Empty(t, hooks.PostCutover)
+
+	hooks, err = loadHooksConfig(writeHooksConfig(t, `
+postCutover:
+  - name: dns
+    annotate:
+      kind: Ingress
+      names: [web]
+      annotations:
+        external-dns.alpha.kubernetes.io/hostname: app.example.com
+  - name: notify
+    ignoreFailure: true
+    webhook:
+      url: https://hooks.example.com/dr
+      timeout: 5s
+`))
+	require.NoError(t, err)
+	require.Len(t, hooks.PostCutover, 2)
+	assert.Equal(t, "Ingress", hooks.PostCutover[0].Annotate.Kind)
+	assert.Equal(t, []string{"web"}, hooks.PostCutover[0].Annotate.Names)
+	assert.True(t, hooks.PostCutover[1].IgnoreFailure)
+	assert.Equal(t, "5s", hooks.PostCutover[1].Webhook.Timeout)
+}
+
+func TestLoadHooksConfig_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":    "postCutover:\n  - name: a\n    script: run.sh\n",
+		"no action":        "postCutover:\n  - name: a\n",
+		"both actions":     "postCutover:\n  - name: a\n    webhook: {url: http://x}\n    annotate: {kind: Service, annotations: {a: b}}\n",
+		"missing name":     "postCutover:\n  - webhook: {url: http://x}\n",
+		"bad timeout":      "postCutover:\n  - name: a\n    webhook: {url: http://x, timeout: soon}\n",
+		"bad template":     "postCutover:\n  - name: a\n    webhook: {url: http://x, body: '{{ .Missing'}\n",
+		"unsupported kind": "postCutover:\n  - name: a\n    annotate: {kind: Deployment, annotations: {a: b}}\n",
+		"bad cluster":      "postCutover:\n  - name: a\n    annotate: {kind: Service, cluster: other, annotations: {a: b}}\n",
+		"no annotations":   "postCutover:\n  - name: a\n    annotate: {kind: Service}\n",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := loadHooksConfig(writeHooksConfig(t, content))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestRunWebhookHook(t *testing.T) {
+	var method, body, auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method = r.Method
+		auth = r.Header.Get("Authorization")
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	hookCtx := HookContext{Event: "PostCutover", SourceNamespace: "app", DestNamespace: "app-dr", Timestamp: "2026-01-01T00:00:00Z"}
+
+	err := runWebhookHook(context.Background(), &WebhookHook{
+		URL:     server.URL,
+		Headers: map[string]string{"Authorization": "Bearer token"},
+		Body:    `{"text": "{{ .SourceNamespace }} -> {{ .DestNamespace }}"}`,
+	}, hookCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPost, method)
+	assert.Equal(t, "Bearer token", auth)
+	assert.Equal(t, `{"text": "app -> app-dr"}`, body)
+
+	// Without a body template the hook context is sent as JSON
+	err = runWebhookHook(context.Background(), &WebhookHook{URL: server.URL, Method: http.MethodPut}, hookCtx)
+	require.NoError(t, err)
+	assert.Equal(t, http.MethodPut, method)
+	assert.JSONEq(t, `{"event":"PostCutover","sourceNamespace":"app","destNamespace":"app-dr","timestamp":"2026-01-01T00:00:00Z"}`, body)
+}
+
+func TestRunWebhookHook_ErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	err := runWebhookHook(context.Background(), &WebhookHook{URL: server.URL}, HookContext{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "502")
+}
+
+func newHookDynamicClient(objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	return dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		hookGVRs["Ingress"]: "IngressList",
+		hookGVRs["Service"]: "ServiceList",
+	}, objects...)
+}
+
+func newIngress(namespace, name string, annotations map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "networking.k8s.io/v1",
+		"kind":       "Ingress",
+		"metadata": map[string]interface{}{
+			"name":        name,
+			"namespace":   namespace,
+			"annotations": annotations,
+		},
+	}}
+}
+
+func TestRunHooks_Annotate(t *testing.T) {
+	const hostname = "external-dns.alpha.kubernetes.io/hostname"
+	source := newHookDynamicClient(newIngress("app", "web", map[string]interface{}{hostname: "app.example.com"}))
+	dest := newHookDynamicClient(
+		newIngress("app-dr", "web", map[string]interface{}{"keep": "me"}),
+		newIngress("app-dr", "api", nil),
+	)
+
+	err := runHooks(context.Background(), []Hook{
+		{Name: "dns-destination", Annotate: &AnnotateHook{Kind: "Ingress", Annotations: map[string]string{hostname: "app.example.com"}}},
+		{Name: "dns-source", Annotate: &AnnotateHook{Cluster: HookClusterSource, Kind: "Ingress", Names: []string{"web"}, RemoveAnnotations: []string{hostname}}},
+	}, source, dest, HookContext{Event: "PostCutover", SourceNamespace: "app", DestNamespace: "app-dr"})
+	require.NoError(t, err)
+
+	ingresses := source.Resource(hookGVRs["Ingress"]).Namespace("app")
+	web, err := ingresses.Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, web.GetAnnotations(), hostname)
+
+	ingresses = dest.Resource(hookGVRs["Ingress"]).Namespace("app-dr")
+	for _, name := range []string{"web", "api"} {
+		ingress, err := ingresses.Get(context.Background(), name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, "app.example.com", ingress.GetAnnotations()[hostname])
+	}
+	web, err = ingresses.Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "me", web.GetAnnotations()["keep"])
+}
+
+func TestRunHooks_Failures(t *testing.T) {
+	dest := newHookDynamicClient()
+	missing := &AnnotateHook{Kind: "Service", Names: []string{"missing"}, Annotations: map[string]string{"a": "b"}}
+
+	// Failing hooks that are ignored do not fail the run
+	err := runHooks(context.Background(), []Hook{{Name: "optional", IgnoreFailure: true, Annotate: missing}},
+		newHookDynamicClient(), dest, HookContext{DestNamespace: "app-dr"})
+	assert.NoError(t, err)
+
+	// Later hooks still run after a failure
+	called := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { called = true }))
+	defer server.Close()
+
+	err = runHooks(context.Background(), []Hook{
+		{Name: "required", Annotate: missing},
+		{Name: "notify", Webhook: &WebhookHook{URL: server.URL}},
+	}, newHookDynamicClient(), dest, HookContext{DestNamespace: "app-dr"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "required")
+	assert.True(t, called)
+}
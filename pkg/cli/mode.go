@@ -4,9 +4,15 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"strconv"
 	"strings"
+	"time"
 
+	"github.com/supporttools/dr-syncer/pkg/backup"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/notify"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -29,25 +35,33 @@ func executeStageModeSync(
 	sourceDynamicClient dynamic.Interface,
 	destDynamicClient dynamic.Interface,
 	config *Config,
+	run *mappingRun,
 ) error {
 	log := logging.SetupLogging()
 	log.Info("Executing Stage mode sync")
 
 	// Sync resources from source to destination
-	if err := syncResources(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config); err != nil {
+	if err := run.step("sync-resources", func() error {
+		synced, failed, err := syncResources(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config)
+		run.result.ResourcesSynced += synced
+		run.result.ResourcesFailed += failed
+		return err
+	}); err != nil {
 		return fmt.Errorf("failed to sync resources: %v", err)
 	}
 
 	// Scale down deployments in destination
-	log.Info("Scaling down deployments in destination")
-	if err := scaleDeployments(ctx, destClient, config.DestNamespace, 0); err != nil {
+	if err := run.step("scale-down-destination", func() error {
+		log.Info("Scaling down deployments in destination")
+		return scaleDeployments(ctx, destClient, config.DestNamespace, 0)
+	}); err != nil {
 		return fmt.Errorf("failed to scale down deployments in destination: %v", err)
 	}
 
 	// Handle PVC data migration if enabled
 	if config.MigratePVCData {
 		log.Info("PVC data migration is enabled")
-		if err := migratePVCData(ctx, sourceClient, destClient, config); err != nil {
+		if err := migratePVCData(ctx, sourceClient, destClient, config, run); err != nil {
 			return fmt.Errorf("failed to migrate PVC data: %v", err)
 		}
 	}
@@ -58,8 +72,8 @@ func executeStageModeSync(
 
 // executeCutoverModeSync handles the Cutover mode operation:
 // 1. Synchronize resources from source to destination
-// 2. Scale down deployments in source
-// 3. Scale up deployments in destination
+// 2. Scale down deployments and suspend CronJobs in source
+// 3. Scale up deployments and unsuspend CronJobs in destination
 func executeCutoverModeSync(
 	ctx context.Context,
 	sourceClient kubernetes.Interface,
@@ -67,49 +81,105 @@ func executeCutoverModeSync(
 	sourceDynamicClient dynamic.Interface,
 	destDynamicClient dynamic.Interface,
 	config *Config,
+	run *mappingRun,
 ) error {
 	log := logging.SetupLogging()
 	log.Info("Executing Cutover mode sync")
 
-	// Sync resources from source to destination
-	if err := syncResources(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config); err != nil {
+	// Load hooks before changing anything so an invalid hooks file fails the cutover early
+	hooks, err := loadHooksConfig(config.HooksConfig)
+	if err != nil {
+		return err
+	}
+	webhooks, err := cutoverWebhooks(config)
+	if err != nil {
+		return err
+	}
+	notifyCutover(ctx, webhooks, notify.EventCutoverStarted, config)
+
+	// Sync resources from source to destination. Once the source is scaled down a re-run must not sync
+	// again, it would copy the scaled down source over the destination.
+	if err := run.step("sync-resources", func() error {
+		synced, failed, err := syncResources(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, config)
+		run.result.ResourcesSynced += synced
+		run.result.ResourcesFailed += failed
+		return err
+	}); err != nil {
 		return fmt.Errorf("failed to sync resources: %v", err)
 	}
 
 	// Annotate source deployments with original replica counts before scaling down
-	log.Info("Annotating source deployments with original replica counts")
-	if err := annotateOriginalReplicas(ctx, sourceClient, config.SourceNamespace); err != nil {
+	if err := run.step("annotate-source-replicas", func() error {
+		log.Info("Annotating source deployments with original replica counts")
+		return annotateOriginalReplicas(ctx, sourceClient, config.SourceNamespace)
+	}); err != nil {
 		return fmt.Errorf("failed to annotate original replicas: %v", err)
 	}
 
 	// Scale down deployments in source
-	log.Info("Scaling down deployments in source")
-	if err := scaleDeployments(ctx, sourceClient, config.SourceNamespace, 0); err != nil {
+	if err := run.step("scale-down-source", func() error {
+		log.Info("Scaling down deployments in source")
+		return scaleDeployments(ctx, sourceClient, config.SourceNamespace, 0)
+	}); err != nil {
 		return fmt.Errorf("failed to scale down deployments in source: %v", err)
 	}
 
 	// Scale up deployments in destination (based on source replica counts)
-	log.Info("Scaling up deployments in destination")
-	if err := restoreDeploymentScales(ctx, sourceClient, destClient, config.SourceNamespace, config.DestNamespace); err != nil {
+	if err := run.step("scale-up-destination", func() error {
+		log.Info("Scaling up deployments in destination")
+		return restoreDeploymentScales(ctx, sourceClient, destClient, config.SourceNamespace, config.DestNamespace)
+	}); err != nil {
 		return fmt.Errorf("failed to scale up deployments in destination: %v", err)
 	}
 
+	// Suspend CronJobs in source so they only run in the destination
+	if err := run.step("suspend-source-cronjobs", func() error {
+		log.Info("Suspending cronjobs in source")
+		return suspendCronJobs(ctx, sourceClient, config.SourceNamespace)
+	}); err != nil {
+		return fmt.Errorf("failed to suspend cronjobs in source: %v", err)
+	}
+
+	// Unsuspend CronJobs and Jobs in destination (based on original suspend state)
+	if err := run.step("unsuspend-destination-cronjobs", func() error {
+		log.Info("Unsuspending cronjobs in destination")
+		return restoreCronJobsSuspend(ctx, destClient, config.DestNamespace)
+	}); err != nil {
+		return fmt.Errorf("failed to unsuspend cronjobs in destination: %v", err)
+	}
+
 	// Handle final PVC data migration if enabled
 	if config.MigratePVCData {
 		log.Info("PVC data migration is enabled")
-		if err := migratePVCData(ctx, sourceClient, destClient, config); err != nil {
+		if err := migratePVCData(ctx, sourceClient, destClient, config, run); err != nil {
 			return fmt.Errorf("failed to migrate PVC data: %v", err)
 		}
 	}
 
+	// Run post-cutover hooks, e.g. to point DNS at the destination, now that it is serving
+	if len(hooks.PostCutover) > 0 {
+		if err := run.step("post-cutover-hooks", func() error {
+			log.Infof("Running %d post-cutover hooks", len(hooks.PostCutover))
+			return runHooks(ctx, hooks.PostCutover, sourceDynamicClient, destDynamicClient, HookContext{
+				Event:           "PostCutover",
+				SourceNamespace: config.SourceNamespace,
+				DestNamespace:   config.DestNamespace,
+				Timestamp:       time.Now().UTC().Format(time.RFC3339),
+			})
+		}); err != nil {
+			return fmt.Errorf("failed to run post-cutover hooks: %v", err)
+		}
+	}
+
+	notifyCutover(ctx, webhooks, notify.EventCutoverCompleted, config)
 	log.Info("Cutover mode sync completed successfully")
 	return nil
 }
 
 // executeFailbackModeSync handles the Failback mode operation:
 // 1. Optionally reverse sync specific resources
-// 2. Scale down deployments in destination
-// 3. Scale up deployments in source
+// 2. Scale down deployments and suspend CronJobs in destination
+// 3. Scale up deployments and unsuspend CronJobs in source
 func executeFailbackModeSync(
 	ctx context.Context,
 	sourceClient kubernetes.Interface,
@@ -117,6 +187,7 @@ func executeFailbackModeSync(
 	sourceDynamicClient dynamic.Interface,
 	destDynamicClient dynamic.Interface,
 	config *Config,
+	run *mappingRun,
 ) error {
 	log := logging.SetupLogging()
 	log.Info("Executing Failback mode sync")
@@ -127,32 +198,57 @@ func executeFailbackModeSync(
 		if err := migratePVCData(ctx, destClient, sourceClient, &Config{
 			SourceKubeconfig: config.DestKubeconfig,
 			DestKubeconfig:   config.SourceKubeconfig,
+			SourceContext:    config.DestContext,
+			DestContext:      config.SourceContext,
 			SourceNamespace:  config.DestNamespace,
 			DestNamespace:    config.SourceNamespace,
 			MigratePVCData:   true,
 			PVMigrateFlags:   config.PVMigrateFlags, // Pass the PV migrate flags to reverse migration
-		}); err != nil {
+			PVCNames:         config.PVCNames,
+			PVCSelector:      config.PVCSelector,
+		}, run); err != nil {
 			return fmt.Errorf("failed to reverse migrate PVC data: %v", err)
 		}
 	}
 
 	// Scale down deployments in destination
-	log.Info("Scaling down deployments in destination")
-	if err := scaleDeployments(ctx, destClient, config.DestNamespace, 0); err != nil {
+	if err := run.step("scale-down-destination", func() error {
+		log.Info("Scaling down deployments in destination")
+		return scaleDeployments(ctx, destClient, config.DestNamespace, 0)
+	}); err != nil {
 		return fmt.Errorf("failed to scale down deployments in destination: %v", err)
 	}
 
 	// Scale up deployments in source (restore original replica counts)
-	log.Info("Scaling up deployments in source")
-	if err := restoreOriginalReplicas(ctx, sourceClient, config.SourceNamespace); err != nil {
+	if err := run.step("scale-up-source", func() error {
+		log.Info("Scaling up deployments in source")
+		return restoreOriginalReplicas(ctx, sourceClient, config.SourceNamespace)
+	}); err != nil {
 		return fmt.Errorf("failed to scale up deployments in source: %v", err)
 	}
 
+	// Suspend CronJobs in destination
+	if err := run.step("suspend-destination-cronjobs", func() error {
+		log.Info("Suspending cronjobs in destination")
+		return suspendCronJobs(ctx, destClient, config.DestNamespace)
+	}); err != nil {
+		return fmt.Errorf("failed to suspend cronjobs in destination: %v", err)
+	}
+
+	// Unsuspend CronJobs in source (restore original suspend state)
+	if err := run.step("unsuspend-source-cronjobs", func() error {
+		log.Info("Unsuspending cronjobs in source")
+		return restoreCronJobsSuspend(ctx, sourceClient, config.SourceNamespace)
+	}); err != nil {
+		return fmt.Errorf("failed to unsuspend cronjobs in source: %v", err)
+	}
+
 	log.Info("Failback mode sync completed successfully")
 	return nil
 }
 
-// syncResources synchronizes resources from source to destination
+// syncResources synchronizes resources from source to destination, returning the number of objects
+// synced and the number that failed to sync
 func syncResources(
 	ctx context.Context,
 	sourceClient kubernetes.Interface,
@@ -160,14 +256,73 @@ func syncResources(
 	sourceDynamicClient dynamic.Interface,
 	destDynamicClient dynamic.Interface,
 	config *Config,
-) error {
+) (synced, failed int, err error) {
+	log := logging.SetupLogging()
+
+	var backups *backup.Store
+	if config.Backup {
+		backups = backup.NewStore(destClient, config.BackupNamespace, config.BackupRetention)
+	}
+
+	return renderResources(ctx, sourceClient, sourceDynamicClient, config, func(gvr schema.GroupVersionResource, transformedResource *unstructured.Unstructured) bool {
+		kind, name := transformedResource.GetKind(), transformedResource.GetName()
+
+		// Apply resource to destination
+		_, err := destDynamicClient.Resource(gvr).Namespace(config.DestNamespace).Create(ctx, transformedResource, metav1.CreateOptions{})
+		if err != nil {
+			// Check if error is "already exists"
+			statusErr, ok := err.(interface {
+				Status() interface {
+					Reason() string
+				}
+			})
+			if !ok || statusErr.Status().Reason() != "AlreadyExists" {
+				log.Warnf("Failed to create resource %s/%s: %v", kind, name, err)
+				return false
+			}
+
+			// Resource already exists, back it up before overwriting it
+			if backups != nil {
+				existing, err := destDynamicClient.Resource(gvr).Namespace(config.DestNamespace).Get(ctx, name, metav1.GetOptions{})
+				if err == nil {
+					err = backups.Save(ctx, gvr, existing)
+				}
+				if err != nil {
+					log.Warnf("Failed to back up resource %s/%s, skipping update: %v", kind, name, err)
+					return false
+				}
+			}
+
+			// Update it
+			_, err = destDynamicClient.Resource(gvr).Namespace(config.DestNamespace).Update(ctx, transformedResource, metav1.UpdateOptions{})
+			if err != nil {
+				log.Warnf("Failed to update resource %s/%s: %v", kind, name, err)
+				return false
+			}
+		}
+
+		log.Infof("Successfully synced resource: %s/%s", kind, name)
+		return true
+	})
+}
+
+// renderResources lists the resources of the source namespace selected by the configuration and passes
+// each of them, transformed for the destination namespace, to apply. It returns the number of objects
+// apply reported as synced and the number that failed to transform or apply.
+func renderResources(
+	ctx context.Context,
+	sourceClient kubernetes.Interface,
+	sourceDynamicClient dynamic.Interface,
+	config *Config,
+	apply func(gvr schema.GroupVersionResource, transformed *unstructured.Unstructured) bool,
+) (synced, failed int, err error) {
 	log := logging.SetupLogging()
 	log.Info("Discovering resources in source namespace")
 
 	// Get API resources
 	apiResources, err := sourceClient.Discovery().ServerPreferredResources()
 	if err != nil {
-		return fmt.Errorf("failed to get API resources: %v", err)
+		return 0, 0, fmt.Errorf("failed to get API resources: %v", err)
 	}
 
 	// Process API resources
@@ -220,41 +375,39 @@ func syncResources(
 			for _, item := range resourceList.Items {
 				log.Infof("Processing resource: %s/%s", item.GetKind(), item.GetName())
 
+				// Jobs created by CronJobs or already finished are not synced
+				if item.GetKind() == "Job" && skipJob(&item) {
+					log.Infof("Skipping job %s (owned by a cronjob or completed)", item.GetName())
+					continue
+				}
+
 				// Transform resource for destination
 				transformedResource, err := transformResource(&item, config.DestNamespace)
 				if err != nil {
 					log.Warnf("Failed to transform resource %s/%s: %v", item.GetKind(), item.GetName(), err)
+					failed++
 					continue
 				}
 
-				// Apply resource to destination
-				_, err = destDynamicClient.Resource(gvr).Namespace(config.DestNamespace).Create(ctx, transformedResource, metav1.CreateOptions{})
-				if err != nil {
-					// Check if error is "already exists"
-					statusErr, ok := err.(interface {
-						Status() interface {
-							Reason() string
-						}
-					})
-					if !ok || statusErr.Status().Reason() != "AlreadyExists" {
-						log.Warnf("Failed to create resource %s/%s: %v", item.GetKind(), item.GetName(), err)
-						continue
-					}
-
-					// Resource already exists, update it
-					_, err = destDynamicClient.Resource(gvr).Namespace(config.DestNamespace).Update(ctx, transformedResource, metav1.UpdateOptions{})
-					if err != nil {
-						log.Warnf("Failed to update resource %s/%s: %v", item.GetKind(), item.GetName(), err)
+				// Suspend CronJobs and Jobs in destination so they don't run in both clusters
+				if config.SuspendCronJobs && (item.GetKind() == "CronJob" || item.GetKind() == "Job") {
+					if err := unstructured.SetNestedField(transformedResource.Object, true, "spec", "suspend"); err != nil {
+						log.Warnf("Failed to suspend resource %s/%s: %v", item.GetKind(), item.GetName(), err)
+						failed++
 						continue
 					}
 				}
 
-				log.Infof("Successfully synced resource: %s/%s", item.GetKind(), item.GetName())
+				if apply(gvr, transformedResource) {
+					synced++
+				} else {
+					failed++
+				}
 			}
 		}
 	}
 
-	return nil
+	return synced, failed, nil
 }
 
 // transformResource transforms a resource for the destination cluster
@@ -282,6 +435,12 @@ func transformResource(resource *unstructured.Unstructured, destNamespace string
 		handleStatefulSetTransform(transformed)
 	case "Ingress":
 		handleIngressTransform(transformed)
+	case "CronJob":
+		handleCronJobTransform(transformed)
+	case "Job":
+		handleJobTransform(transformed)
+	case "PersistentVolumeClaim":
+		handlePVCTransform(transformed)
 	}
 
 	return transformed, nil
@@ -365,6 +524,170 @@ func handleIngressTransform(ingress *unstructured.Unstructured) {
 	ingress.SetOwnerReferences(nil)
 }
 
+// recordOriginalSuspend stores the suspend state of a CronJob or Job in an annotation
+// unless it has already been recorded
+func recordOriginalSuspend(resource *unstructured.Unstructured) {
+	annotations := resource.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	if _, ok := annotations[utils.OriginalSuspendAnnotation]; !ok {
+		suspend, _, _ := unstructured.NestedBool(resource.Object, "spec", "suspend")
+		annotations[utils.OriginalSuspendAnnotation] = strconv.FormatBool(suspend)
+		resource.SetAnnotations(annotations)
+	}
+}
+
+// handleCronJobTransform handles CronJob-specific transformations
+func handleCronJobTransform(cronJob *unstructured.Unstructured) {
+	recordOriginalSuspend(cronJob)
+
+	// Remove owner references
+	cronJob.SetOwnerReferences(nil)
+}
+
+// handleJobTransform handles Job-specific transformations
+func handleJobTransform(job *unstructured.Unstructured) {
+	recordOriginalSuspend(job)
+
+	// Remove the generated selector and controller-uid labels, they are assigned by the destination cluster
+	unstructured.RemoveNestedField(job.Object, "spec", "selector")
+	unstructured.RemoveNestedField(job.Object, "spec", "manualSelector")
+	for _, key := range []string{"controller-uid", "batch.kubernetes.io/controller-uid"} {
+		unstructured.RemoveNestedField(job.Object, "metadata", "labels", key)
+		unstructured.RemoveNestedField(job.Object, "spec", "template", "metadata", "labels", key)
+	}
+
+	// Remove owner references
+	job.SetOwnerReferences(nil)
+}
+
+// handlePVCTransform handles PersistentVolumeClaim-specific transformations
+func handlePVCTransform(pvc *unstructured.Unstructured) {
+	// Remove the binding to the source PV and node, the destination provisions its own volume
+	unstructured.RemoveNestedField(pvc.Object, "spec", "volumeName")
+	for _, key := range []string{
+		"pv.kubernetes.io/bind-completed",
+		"pv.kubernetes.io/bound-by-controller",
+		"volume.kubernetes.io/selected-node",
+		"volume.kubernetes.io/storage-provisioner",
+		"volume.beta.kubernetes.io/storage-provisioner",
+	} {
+		unstructured.RemoveNestedField(pvc.Object, "metadata", "annotations", key)
+	}
+}
+
+// skipJob determines if a Job should not be synced because it is managed by a CronJob
+// or has already completed
+func skipJob(job *unstructured.Unstructured) bool {
+	for _, ref := range job.GetOwnerReferences() {
+		if ref.Kind == "CronJob" {
+			return true
+		}
+	}
+	_, completed, _ := unstructured.NestedString(job.Object, "status", "completionTime")
+	return completed
+}
+
+// suspendCronJobs suspends all CronJobs in the namespace, recording their original suspend state
+func suspendCronJobs(ctx context.Context, client kubernetes.Interface, namespace string) error {
+	log := logging.SetupLogging()
+
+	log.Infof("Suspending cronjobs in namespace %s", namespace)
+	cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list cronjobs: %v", err)
+	}
+
+	for _, cronJob := range cronJobs.Items {
+		if cronJob.Annotations == nil {
+			cronJob.Annotations = make(map[string]string)
+		}
+		if _, ok := cronJob.Annotations[utils.OriginalSuspendAnnotation]; !ok {
+			original := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+			cronJob.Annotations[utils.OriginalSuspendAnnotation] = strconv.FormatBool(original)
+		}
+
+		suspend := true
+		cronJob.Spec.Suspend = &suspend
+		_, err := client.BatchV1().CronJobs(namespace).Update(ctx, &cronJob, metav1.UpdateOptions{})
+		if err != nil {
+			log.Warnf("Failed to suspend cronjob %s: %v", cronJob.Name, err)
+			continue
+		}
+
+		log.Infof("Suspended cronjob %s", cronJob.Name)
+	}
+
+	return nil
+}
+
+// restoreCronJobsSuspend restores CronJobs and Jobs to their original suspend state
+func restoreCronJobsSuspend(ctx context.Context, client kubernetes.Interface, namespace string) error {
+	log := logging.SetupLogging()
+
+	// Restore cronjobs
+	log.Infof("Restoring original cronjob suspend state in namespace %s", namespace)
+	cronJobs, err := client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list cronjobs: %v", err)
+	}
+
+	for _, cronJob := range cronJobs.Items {
+		suspendStr, ok := cronJob.Annotations[utils.OriginalSuspendAnnotation]
+		if !ok {
+			log.Warnf("CronJob %s has no original suspend annotation, skipping", cronJob.Name)
+			continue
+		}
+
+		suspend, err := strconv.ParseBool(suspendStr)
+		if err != nil {
+			log.Warnf("Failed to parse original suspend annotation for cronjob %s: %v", cronJob.Name, err)
+			continue
+		}
+
+		cronJob.Spec.Suspend = &suspend
+		_, err = client.BatchV1().CronJobs(namespace).Update(ctx, &cronJob, metav1.UpdateOptions{})
+		if err != nil {
+			log.Warnf("Failed to restore cronjob %s: %v", cronJob.Name, err)
+			continue
+		}
+
+		log.Infof("Restored cronjob %s to suspend: %v", cronJob.Name, suspend)
+	}
+
+	// Restore jobs
+	log.Infof("Restoring original job suspend state in namespace %s", namespace)
+	jobs, err := client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list jobs: %v", err)
+	}
+
+	for _, job := range jobs.Items {
+		suspendStr, ok := job.Annotations[utils.OriginalSuspendAnnotation]
+		if !ok {
+			continue
+		}
+
+		suspend, err := strconv.ParseBool(suspendStr)
+		if err != nil {
+			log.Warnf("Failed to parse original suspend annotation for job %s: %v", job.Name, err)
+			continue
+		}
+
+		job.Spec.Suspend = &suspend
+		_, err = client.BatchV1().Jobs(namespace).Update(ctx, &job, metav1.UpdateOptions{})
+		if err != nil {
+			log.Warnf("Failed to restore job %s: %v", job.Name, err)
+			continue
+		}
+
+		log.Infof("Restored job %s to suspend: %v", job.Name, suspend)
+	}
+
+	return nil
+}
+
 // scaleDeployments scales all deployments in the namespace to the specified replica count
 func scaleDeployments(ctx context.Context, client kubernetes.Interface, namespace string, replicas int32) error {
 	log := logging.SetupLogging()
@@ -655,8 +978,9 @@ func restoreOriginalReplicas(ctx context.Context, client kubernetes.Interface, n
 	return nil
 }
 
-// migratePVCData migrates PVC data using pv-migrate
-func migratePVCData(ctx context.Context, sourceClient kubernetes.Interface, destClient kubernetes.Interface, config *Config) error {
+// migratePVCData migrates PVC data using pv-migrate. PVCs migrated by a previous run are skipped, PVCs
+// that fail do not stop the migration of the others but fail it once all were attempted.
+func migratePVCData(ctx context.Context, sourceClient kubernetes.Interface, destClient kubernetes.Interface, config *Config, run *mappingRun) error {
 	log := logging.SetupLogging()
 
 	// Check if pv-migrate is installed
@@ -678,8 +1002,25 @@ func migratePVCData(ctx context.Context, sourceClient kubernetes.Interface, dest
 
 	log.Infof("Found %d PVCs in source namespace for potential migration", len(pvcs.Items))
 
+	selected, skipped, err := selectPVCs(pvcs.Items, config)
+	if err != nil {
+		return err
+	}
+	if len(skipped) > 0 {
+		log.Infof("Skipping data migration of %d PVCs not selected by --pvcs or --pvc-selector: %s", len(skipped), strings.Join(skipped, ", "))
+		run.result.PVCsSkipped = append(run.result.PVCsSkipped, skipped...)
+	}
+
 	// Migrate each PVC's data
-	for _, pvc := range pvcs.Items {
+	var failed []string
+	for _, pvc := range selected {
+		step := fmt.Sprintf("migrate-pvc/%s/%s", config.SourceNamespace, pvc.Name)
+		if run.progress.done(step) {
+			log.Infof("Skipping PVC %s, its data was migrated by a previous run", pvc.Name)
+			run.record(StepResult{Name: step, Skipped: true})
+			continue
+		}
+
 		// Check if destination PVC exists
 		_, err := destClient.CoreV1().PersistentVolumeClaims(config.DestNamespace).Get(ctx, pvc.Name, metav1.GetOptions{})
 		if err != nil {
@@ -690,18 +1031,58 @@ func migratePVCData(ctx context.Context, sourceClient kubernetes.Interface, dest
 		log.Infof("Migrating data for PVC %s from %s to %s", pvc.Name, config.SourceNamespace, config.DestNamespace)
 
 		// Use pv-migrate to transfer data
-		err = pvMigrate(config.SourceKubeconfig, config.DestKubeconfig, config.SourceNamespace, config.DestNamespace, pvc.Name, pvc.Name, config.PVMigrateFlags)
+		run.report(step, StepStarted, nil)
+		start := time.Now()
+		err = pvMigrate(config, pvc.Name, pvc.Name)
+		run.record(StepResult{Name: step, Duration: time.Since(start), Err: err})
 		if err != nil {
 			log.Warnf("Failed to migrate data for PVC %s: %v", pvc.Name, err)
+			failed = append(failed, pvc.Name)
 			continue
 		}
 
 		log.Infof("Successfully migrated data for PVC %s", pvc.Name)
+		run.result.PVCsMigrated = append(run.result.PVCsMigrated, pvc.Name)
+		if err := run.progress.complete(step); err != nil {
+			return err
+		}
 	}
 
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to migrate data of %d PVCs: %s", len(failed), strings.Join(failed, ", "))
+	}
 	return nil
 }
 
+// selectPVCs splits the source PVCs into those whose data is migrated and the names of those skipped
+// because the PVC names and selector of the configuration do not select them
+func selectPVCs(pvcs []corev1.PersistentVolumeClaim, config *Config) ([]corev1.PersistentVolumeClaim, []string, error) {
+	log := logging.SetupLogging()
+
+	migrated, err := config.PVCFilter()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var selected []corev1.PersistentVolumeClaim
+	var skipped []string
+	found := make(map[string]bool, len(pvcs))
+	for _, pvc := range pvcs {
+		found[pvc.Name] = true
+		if migrated(pvc.Name, pvc.Labels) {
+			selected = append(selected, pvc)
+		} else {
+			skipped = append(skipped, pvc.Name)
+		}
+	}
+	for _, name := range config.PVCNames {
+		if !found[name] {
+			log.Warnf("PVC %s selected by --pvcs not found in source namespace %s", name, config.SourceNamespace)
+		}
+	}
+	return selected, skipped, nil
+}
+
 // isPvMigrateAvailable checks if pv-migrate is available in the PATH
 func isPvMigrateAvailable() bool {
 	// First try the most common flag format
@@ -725,25 +1106,11 @@ func isPvMigrateAvailable() bool {
 }
 
 // pvMigrate uses pv-migrate to transfer data between PVCs
-func pvMigrate(sourceKubeconfig, destKubeconfig, sourceNamespace, destNamespace, sourcePVC, destPVC, additionalFlags string) error {
+func pvMigrate(config *Config, sourcePVC, destPVC string) error {
 	log := logging.SetupLogging()
-	args := []string{
-		"--source", sourcePVC,
-		"--dest", destPVC,
-		"--source-namespace", sourceNamespace,
-		"--dest-namespace", destNamespace,
-		"-k", sourceKubeconfig,
-		"-K", destKubeconfig,
-	}
-
-	// If additional flags are provided, parse and add them
-	if additionalFlags != "" {
-		// Split string by spaces, but respect quoted arguments
-		additionalArgs, err := parseCommandLineArgs(additionalFlags)
-		if err != nil {
-			return fmt.Errorf("failed to parse additional pv-migrate flags: %v", err)
-		}
-		args = append(args, additionalArgs...)
+	args, err := pvMigrateArgs(config, sourcePVC, destPVC)
+	if err != nil {
+		return err
 	}
 
 	// Print PV migrate command being executed
@@ -761,6 +1128,40 @@ func pvMigrate(sourceKubeconfig, destKubeconfig, sourceNamespace, destNamespace,
 	return err
 }
 
+// pvMigrateArgs builds the pv-migrate arguments for copying sourcePVC to destPVC. Kubeconfigs and
+// contexts are only passed when set so pv-migrate applies its own defaults.
+func pvMigrateArgs(config *Config, sourcePVC, destPVC string) ([]string, error) {
+	args := []string{
+		"--source", sourcePVC,
+		"--dest", destPVC,
+		"--source-namespace", config.SourceNamespace,
+		"--dest-namespace", config.DestNamespace,
+	}
+	if config.SourceKubeconfig != "" {
+		args = append(args, "-k", config.SourceKubeconfig)
+	}
+	if config.DestKubeconfig != "" {
+		args = append(args, "-K", config.DestKubeconfig)
+	}
+	if config.SourceContext != "" {
+		args = append(args, "--source-context", config.SourceContext)
+	}
+	if config.DestContext != "" {
+		args = append(args, "--dest-context", config.DestContext)
+	}
+
+	// If additional flags are provided, parse and add them
+	if config.PVMigrateFlags != "" {
+		// Split string by spaces, but respect quoted arguments
+		additionalArgs, err := parseCommandLineArgs(config.PVMigrateFlags)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse additional pv-migrate flags: %v", err)
+		}
+		args = append(args, additionalArgs...)
+	}
+	return args, nil
+}
+
 // parseCommandLineArgs parses a command line string into separate arguments
 // respecting quotes (both single and double)
 func parseCommandLineArgs(cmd string) ([]string, error) {
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultConcurrency is the number of namespace mappings processed at the same time
+const DefaultConcurrency = 2
+
+// NamespaceMapping is a source and destination namespace pair synced in one invocation
+type NamespaceMapping struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"`
+}
+
+// String returns the mapping in the source=destination form of --namespace-mapping
+func (m NamespaceMapping) String() string {
+	return m.Source + "=" + m.Destination
+}
+
+// NamespaceMappingsFile is the file passed with --namespace-mappings-file
+type NamespaceMappingsFile struct {
+	NamespaceMappings []NamespaceMapping `json:"namespaceMappings"`
+}
+
+// ParseNamespaceMapping parses a --namespace-mapping value of the form source=destination
+func ParseNamespaceMapping(value string) (NamespaceMapping, error) {
+	source, destination, found := strings.Cut(value, "=")
+	mapping := NamespaceMapping{Source: strings.TrimSpace(source), Destination: strings.TrimSpace(destination)}
+	if !found || mapping.Destination == "" {
+		return NamespaceMapping{}, fmt.Errorf("invalid namespace mapping %q, expected source=destination", value)
+	}
+	return mapping, nil
+}
+
+// LoadNamespaceMappings reads the namespace mappings of a --namespace-mappings-file
+func LoadNamespaceMappings(path string) ([]NamespaceMapping, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read namespace mappings file: %v", err)
+	}
+
+	var file NamespaceMappingsFile
+	if err := yaml.UnmarshalStrict(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse namespace mappings file %s: %v", path, err)
+	}
+
+	for i, mapping := range file.NamespaceMappings {
+		if mapping.Destination == "" {
+			return nil, fmt.Errorf("namespace mapping %d in %s has no destination", i, path)
+		}
+	}
+	return file.NamespaceMappings, nil
+}
+
+// runNamespaceMappings runs the operation for each namespace mapping with bounded concurrency,
+// returning the results in the order of the mappings. run records the steps of a mapping in its result.
+func runNamespaceMappings(config *Config, run func(*Config, *MappingResult) error) []MappingResult {
+	concurrency := config.Concurrency
+	if concurrency <= 0 {
+		concurrency = DefaultConcurrency
+	}
+
+	results := make([]MappingResult, len(config.NamespaceMappings))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, mapping := range config.NamespaceMappings {
+		wg.Add(1)
+		go func(i int, mapping NamespaceMapping) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			mappingConfig := *config
+			mappingConfig.SourceNamespace = mapping.Source
+			mappingConfig.DestNamespace = mapping.Destination
+			mappingConfig.NamespaceMappings = nil
+
+			start := time.Now()
+			results[i].Mapping = mapping
+			results[i].Err = run(&mappingConfig, &results[i])
+			results[i].Duration = time.Since(start)
+		}(i, mapping)
+	}
+	wg.Wait()
+	return results
+}
+
+// logSummary logs the outcome of every namespace mapping
+func logSummary(results []MappingResult) {
+	log := logging.SetupLogging()
+
+	succeeded := 0
+	for _, result := range results {
+		if result.Err == nil {
+			succeeded++
+		}
+	}
+
+	log.Infof("Summary: %d of %d namespace mappings succeeded", succeeded, len(results))
+	for _, result := range results {
+		if result.Err != nil {
+			log.Errorf("  %s: failed after %s: %v", result.Mapping, result.Duration.Round(time.Second), result.Err)
+			continue
+		}
+		if len(result.PVCsSkipped) > 0 {
+			log.Infof("  %s: succeeded in %s, data of %d PVCs migrated, %d PVCs skipped", result.Mapping,
+				result.Duration.Round(time.Second), len(result.PVCsMigrated), len(result.PVCsSkipped))
+			continue
+		}
+		log.Infof("  %s: succeeded in %s", result.Mapping, result.Duration.Round(time.Second))
+	}
+}
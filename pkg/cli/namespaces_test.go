@@ -0,0 +1,90 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseNamespaceMapping(t *testing.T) {
+	mapping, err := ParseNamespaceMapping("prod-a=dr-a")
+	require.NoError(t, err)
+	assert.Equal(t, NamespaceMapping{Source: "prod-a", Destination: "dr-a"}, mapping)
+	assert.Equal(t, "prod-a=dr-a", mapping.String())
+
+	for _, value := range []string{"prod-a", "prod-a=", ""} {
+		_, err := ParseNamespaceMapping(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestLoadNamespaceMappings(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mappings.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+namespaceMappings:
+  - source: prod-a
+    destination: dr-a
+  - source: prod-b
+    destination: dr-b
+`), 0o600))
+
+	mappings, err := LoadNamespaceMappings(path)
+	require.NoError(t, err)
+	assert.Equal(t, []NamespaceMapping{{Source: "prod-a", Destination: "dr-a"}, {Source: "prod-b", Destination: "dr-b"}}, mappings)
+
+	require.NoError(t, os.WriteFile(path, []byte("namespaceMappings:\n  - source: prod-a\n"), 0o600))
+	_, err = LoadNamespaceMappings(path)
+	assert.Error(t, err)
+
+	require.NoError(t, os.WriteFile(path, []byte("mappings: []\n"), 0o600))
+	_, err = LoadNamespaceMappings(path)
+	assert.Error(t, err, "unknown fields are rejected")
+}
+
+func TestRunNamespaceMappings(t *testing.T) {
+	config := &Config{
+		Mode:        "Stage",
+		Concurrency: 2,
+		NamespaceMappings: []NamespaceMapping{
+			{Source: "prod-a", Destination: "dr-a"},
+			{Source: "prod-b", Destination: "dr-b"},
+			{Source: "prod-c", Destination: "dr-c"},
+		},
+	}
+
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	results := runNamespaceMappings(config, func(c *Config, _ *MappingResult) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+		mu.Lock()
+		running--
+		mu.Unlock()
+
+		assert.Empty(t, c.NamespaceMappings)
+		assert.Equal(t, "Stage", c.Mode)
+		if c.SourceNamespace == "prod-b" {
+			return fmt.Errorf("sync of %s failed", c.DestNamespace)
+		}
+		return nil
+	})
+
+	assert.LessOrEqual(t, maxRunning, 2)
+	require.Len(t, results, 3)
+	assert.Equal(t, "prod-a=dr-a", results[0].Mapping.String())
+	assert.NoError(t, results[0].Err)
+	assert.EqualError(t, results[1].Err, "sync of dr-b failed")
+	assert.NoError(t, results[2].Err)
+	assert.Len(t, config.NamespaceMappings, 3, "the shared configuration is not modified")
+}
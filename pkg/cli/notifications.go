@@ -0,0 +1,32 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supporttools/dr-syncer/pkg/notify"
+)
+
+// cutoverWebhooks returns the webhook notified of cutover events, if configured
+func cutoverWebhooks(config *Config) ([]notify.Webhook, error) {
+	if config.NotifyWebhookURL == "" {
+		return nil, nil
+	}
+	format, err := notify.ParseFormat(config.NotifyWebhookFormat)
+	if err != nil {
+		return nil, fmt.Errorf("invalid notification webhook: %v", err)
+	}
+	return []notify.Webhook{{URL: config.NotifyWebhookURL, Format: format}}, nil
+}
+
+// notifyCutover sends a cutover event of the synced namespaces to webhooks
+func notifyCutover(ctx context.Context, webhooks []notify.Webhook, event notify.Event, config *Config) {
+	if len(webhooks) == 0 {
+		return
+	}
+	notify.Dispatch(ctx, notify.Notification{
+		Event:                event,
+		SourceNamespace:      config.SourceNamespace,
+		DestinationNamespace: config.DestNamespace,
+	}, webhooks...)
+}
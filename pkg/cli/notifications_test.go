@@ -0,0 +1,39 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/notify"
+)
+
+func TestCutoverWebhooks(t *testing.T) {
+	webhooks, err := cutoverWebhooks(&Config{})
+	require.NoError(t, err)
+	assert.Empty(t, webhooks)
+
+	webhooks, err = cutoverWebhooks(&Config{NotifyWebhookURL: "https://hooks.example.com", NotifyWebhookFormat: "Teams"})
+	require.NoError(t, err)
+	assert.Equal(t, []notify.Webhook{{URL: "https://hooks.example.com", Format: notify.FormatTeams}}, webhooks)
+
+	_, err = cutoverWebhooks(&Config{NotifyWebhookURL: "https://hooks.example.com", NotifyWebhookFormat: "Discord"})
+	assert.Error(t, err)
+}
+
+func TestNotifyCutover(t *testing.T) {
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		body = string(data)
+	}))
+	defer server.Close()
+
+	notifyCutover(context.Background(), []notify.Webhook{{URL: server.URL, Format: notify.FormatSlack}}, notify.EventCutoverCompleted,
+		&Config{SourceNamespace: "app", DestNamespace: "app-dr"})
+	assert.JSONEq(t, `{"text": "[dr-syncer] CutoverCompleted (app -> app-dr)"}`, body)
+}
@@ -0,0 +1,139 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultStorageClassAnnotation marks the default storage class of a cluster
+const defaultStorageClassAnnotation = "storageclass.kubernetes.io/is-default-class"
+
+// StorageClassCapacity is the storage requested in the destination by the PVCs of a storage class
+type StorageClassCapacity struct {
+	// StorageClass is the storage class of the PVCs, the destination's default storage class for PVCs
+	// without one. It is empty when such PVCs exist and the destination has no default storage class.
+	StorageClass string
+
+	// PVCs counts the PVCs of the storage class
+	PVCs int
+
+	// Requested is the storage requested by the PVCs
+	Requested resource.Quantity
+
+	// Missing is true when the destination cluster has no such storage class, its PVCs stay pending
+	Missing bool
+}
+
+// executePreProvision handles the PreProvision mode operation:
+// 1. Create the PVCs of the source namespace in the destination, without workloads or data
+// 2. Report the storage the PVCs request per storage class of the destination
+func executePreProvision(
+	ctx context.Context,
+	sourceClient kubernetes.Interface,
+	destClient kubernetes.Interface,
+	sourceDynamicClient dynamic.Interface,
+	destDynamicClient dynamic.Interface,
+	config *Config,
+	run *mappingRun,
+) error {
+	log := logging.SetupLogging()
+	log.Info("Executing PreProvision mode sync")
+
+	// Only PVCs are synced, whatever resource types are configured
+	provisionConfig := *config
+	provisionConfig.ResourceTypes = []string{"persistentvolumeclaims"}
+	provisionConfig.IncludeCustomResources = false
+
+	if err := run.step("provision-pvcs", func() error {
+		synced, failed, err := syncResources(ctx, sourceClient, destClient, sourceDynamicClient, destDynamicClient, &provisionConfig)
+		run.result.ResourcesSynced += synced
+		run.result.ResourcesFailed += failed
+		return err
+	}); err != nil {
+		return fmt.Errorf("failed to provision PVCs: %v", err)
+	}
+
+	// The report is not a recorded step, a resumed run builds it again
+	capacity, err := capacityReport(ctx, sourceClient, destClient, config.SourceNamespace)
+	if err != nil {
+		return fmt.Errorf("failed to build the capacity report: %v", err)
+	}
+	run.result.Capacity = capacity
+	for _, c := range capacity {
+		if c.Missing {
+			log.Warnf("Storage class %q requested by %d PVCs (%s) does not exist in the destination", c.StorageClass, c.PVCs, c.Requested.String())
+			continue
+		}
+		log.Infof("Storage class %q: %d PVCs requesting %s", c.StorageClass, c.PVCs, c.Requested.String())
+	}
+
+	log.Info("PreProvision mode sync completed successfully")
+	return nil
+}
+
+// capacityReport sums the storage requested by the PVCs of the source namespace per storage class, and
+// checks the storage classes exist in the destination. PVCs without a storage class are counted in the
+// destination's default storage class.
+func capacityReport(ctx context.Context, sourceClient, destClient kubernetes.Interface, namespace string) ([]StorageClassCapacity, error) {
+	pvcs, err := sourceClient.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list PVCs in namespace %s: %v", namespace, err)
+	}
+
+	storageClasses, err := destClient.StorageV1().StorageClasses().List(ctx, metav1.ListOptions{})
+	if err != nil && !errors.IsForbidden(err) {
+		return nil, fmt.Errorf("failed to list storage classes in the destination: %v", err)
+	}
+	// Without access to the storage classes of the destination none is reported missing
+	checkStorageClasses := err == nil
+	exists := map[string]bool{}
+	defaultClass := ""
+	if checkStorageClasses {
+		for _, sc := range storageClasses.Items {
+			exists[sc.Name] = true
+			if sc.Annotations[defaultStorageClassAnnotation] == "true" {
+				defaultClass = sc.Name
+			}
+		}
+	}
+
+	byClass := map[string]*StorageClassCapacity{}
+	for _, pvc := range pvcs.Items {
+		class := defaultClass
+		if pvc.Spec.StorageClassName != nil {
+			class = *pvc.Spec.StorageClassName
+		}
+		c, ok := byClass[class]
+		if !ok {
+			c = &StorageClassCapacity{StorageClass: class, Missing: checkStorageClasses && !exists[class]}
+			byClass[class] = c
+		}
+		c.PVCs++
+		if request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+			c.Requested.Add(request)
+		}
+	}
+
+	return sortedCapacity(byClass), nil
+}
+
+// sortedCapacity returns the capacities ordered by storage class
+func sortedCapacity(byClass map[string]*StorageClassCapacity) []StorageClassCapacity {
+	capacity := make([]StorageClassCapacity, 0, len(byClass))
+	for _, c := range byClass {
+		capacity = append(capacity, *c)
+	}
+	sort.Slice(capacity, func(i, j int) bool {
+		return capacity[i].StorageClass < capacity[j].StorageClass
+	})
+	return capacity
+}
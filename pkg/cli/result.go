@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+)
+
+// StepPhase is the phase of a step reported to a ProgressFunc
+type StepPhase string
+
+const (
+	StepStarted   StepPhase = "Started"
+	StepCompleted StepPhase = "Completed"
+	StepFailed    StepPhase = "Failed"
+	// StepSkipped is reported for the steps completed by a previous run resumed with Config.Resume
+	StepSkipped StepPhase = "Skipped"
+)
+
+// ProgressEvent reports a step of an operation starting or ending
+type ProgressEvent struct {
+	Mapping NamespaceMapping
+	Step    string
+	Phase   StepPhase
+	Err     error
+	Time    time.Time
+}
+
+// ProgressFunc receives the progress events of an operation. The namespace mappings of a configuration
+// run concurrently, so it must be safe to call from several goroutines.
+type ProgressFunc func(ProgressEvent)
+
+// StepResult is the outcome of a step of an operation
+type StepResult struct {
+	Name     string
+	Skipped  bool
+	Duration time.Duration
+	Err      error
+}
+
+// MappingResult is the outcome of running the CLI operation for one namespace mapping
+type MappingResult struct {
+	Mapping  NamespaceMapping
+	Duration time.Duration
+	Err      error
+
+	// Steps are the steps run or skipped, in order
+	Steps []StepResult
+
+	// ResourcesSynced and ResourcesFailed count the objects synced to the destination
+	ResourcesSynced int
+	ResourcesFailed int
+
+	// PVCsMigrated are the PVCs whose data was migrated with pv-migrate
+	PVCsMigrated []string
+
+	// PVCsSkipped are the PVCs whose data was not migrated because PVCNames and PVCSelector do not select them
+	PVCsSkipped []string
+
+	// ObjectsRestored counts the objects restored by a rollback
+	ObjectsRestored int
+
+	// Capacity is the storage requested per storage class of the destination by a pre-provisioning
+	Capacity []StorageClassCapacity
+
+	// ObjectsExported counts the objects rendered by an export, including the destination namespace
+	ObjectsExported int
+
+	// manifests is the YAML stream of the objects rendered by an export to Config.ExportFile
+	manifests []byte
+}
+
+// Result is the outcome of an operation
+type Result struct {
+	Mode     string
+	Mappings []MappingResult
+	Duration time.Duration
+}
+
+// Failed returns the results of the namespace mappings that failed
+func (r *Result) Failed() []MappingResult {
+	var failed []MappingResult
+	for _, mapping := range r.Mappings {
+		if mapping.Err != nil {
+			failed = append(failed, mapping)
+		}
+	}
+	return failed
+}
+
+// Capacity returns the storage requested per storage class of the destination by all namespace mappings
+func (r *Result) Capacity() []StorageClassCapacity {
+	byClass := map[string]*StorageClassCapacity{}
+	for _, mapping := range r.Mappings {
+		for _, c := range mapping.Capacity {
+			total, ok := byClass[c.StorageClass]
+			if !ok {
+				total = &StorageClassCapacity{StorageClass: c.StorageClass}
+				byClass[c.StorageClass] = total
+			}
+			total.PVCs += c.PVCs
+			total.Requested.Add(c.Requested)
+			total.Missing = total.Missing || c.Missing
+		}
+	}
+	return sortedCapacity(byClass)
+}
+
+// mappingRun is the run of an operation for one namespace mapping. Its steps are recorded in the
+// progress for --resume, reported to the progress callback and collected in the result.
+type mappingRun struct {
+	mapping    NamespaceMapping
+	progress   *progress
+	onProgress ProgressFunc
+	result     *MappingResult
+}
+
+// report sends a progress event to the progress callback
+func (r *mappingRun) report(step string, phase StepPhase, err error) {
+	if r.onProgress == nil {
+		return
+	}
+	r.onProgress(ProgressEvent{Mapping: r.mapping, Step: step, Phase: phase, Err: err, Time: time.Now()})
+}
+
+// record adds the outcome of a step to the result and reports it
+func (r *mappingRun) record(step StepResult) {
+	r.result.Steps = append(r.result.Steps, step)
+	switch {
+	case step.Skipped:
+		r.report(step.Name, StepSkipped, nil)
+	case step.Err != nil:
+		r.report(step.Name, StepFailed, step.Err)
+	default:
+		r.report(step.Name, StepCompleted, nil)
+	}
+}
+
+// step runs fn unless a previous run completed step, and records step as completed when fn succeeds
+func (r *mappingRun) step(step string, fn func() error) error {
+	if r.progress.done(step) {
+		logging.SetupLogging().Infof("Skipping step %s, completed by a previous run", step)
+		r.record(StepResult{Name: step, Skipped: true})
+		return nil
+	}
+
+	r.report(step, StepStarted, nil)
+	start := time.Now()
+	err := fn()
+	r.record(StepResult{Name: step, Duration: time.Since(start), Err: err})
+	if err != nil {
+		return err
+	}
+	return r.progress.complete(step)
+}
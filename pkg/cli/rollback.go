@@ -0,0 +1,59 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supporttools/dr-syncer/pkg/backup"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// executeRollback handles the Rollback mode operation:
+// restores destination objects from the backups taken before they were overwritten
+func executeRollback(
+	ctx context.Context,
+	destClient kubernetes.Interface,
+	destDynamicClient dynamic.Interface,
+	config *Config,
+	run *mappingRun,
+) error {
+	log := logging.SetupLogging()
+	log.Info("Executing Rollback")
+
+	store := backup.NewStore(destClient, config.BackupNamespace, config.BackupRetention)
+	backups, err := store.List(ctx, config.DestNamespace)
+	if err != nil {
+		return err
+	}
+	if len(backups) == 0 {
+		log.Warnf("No backups found for namespace %s", config.DestNamespace)
+		return nil
+	}
+
+	var restored, failed int
+	for i := range backups {
+		b := &backups[i]
+		version := b.VersionFor(config.RollbackSince)
+		if version == nil {
+			log.Infof("Skipping %s/%s (not overwritten since %s)", b.GVR.Resource, b.Name, config.RollbackSince.Format("2006-01-02T15:04:05Z07:00"))
+			continue
+		}
+
+		log.Infof("Restoring %s/%s to the version backed up at %s", b.GVR.Resource, b.Name, version.Time.Format("2006-01-02T15:04:05Z07:00"))
+		if err := backup.Restore(ctx, destDynamicClient, b, version); err != nil {
+			log.Warnf("Failed to restore %s/%s: %v", b.GVR.Resource, b.Name, err)
+			failed++
+			continue
+		}
+		restored++
+		run.result.ObjectsRestored++
+	}
+
+	log.Infof("Rollback restored %d objects in namespace %s", restored, config.DestNamespace)
+	if failed > 0 {
+		return fmt.Errorf("failed to restore %d objects", failed)
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+)
+
+// DefaultStateDir is the directory the progress of operations is recorded in for --resume
+const DefaultStateDir = ".dr-syncer-state"
+
+// operationState is the content of a state file
+type operationState struct {
+	Mode            string    `json:"mode"`
+	SourceNamespace string    `json:"sourceNamespace"`
+	DestNamespace   string    `json:"destNamespace"`
+	StartedAt       time.Time `json:"startedAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+	CompletedSteps  []string  `json:"completedSteps"`
+}
+
+// progress records the completed steps of an operation in a state file, so a run that died midway can
+// be resumed from the failed step. A nil progress records nothing and runs every step.
+type progress struct {
+	path  string
+	state operationState
+}
+
+// stateFilePath returns the state file of the operation configured by config, one per mode and namespace pair
+func stateFilePath(config *Config) string {
+	name := fmt.Sprintf("%s-%s-%s.json", strings.ToLower(config.Mode), config.SourceNamespace, config.DestNamespace)
+	return filepath.Join(config.StateDir, name)
+}
+
+// openProgress starts recording the progress of the configured operation. With config.Resume the steps
+// completed by a previous run are loaded, otherwise the progress of a previous run is discarded.
+// Without a state directory progress is not recorded.
+func openProgress(config *Config) (*progress, error) {
+	if config.StateDir == "" {
+		return nil, nil
+	}
+	log := logging.SetupLogging()
+
+	now := time.Now().UTC()
+	p := &progress{
+		path: stateFilePath(config),
+		state: operationState{
+			Mode:            config.Mode,
+			SourceNamespace: config.SourceNamespace,
+			DestNamespace:   config.DestNamespace,
+			StartedAt:       now,
+			UpdatedAt:       now,
+		},
+	}
+
+	previous, err := loadState(p.path)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case previous == nil:
+		if config.Resume {
+			log.Infof("No progress recorded in %s, starting from the beginning", p.path)
+		}
+	case !config.Resume:
+		log.Warnf("Discarding the progress of the %s run started at %s, use --resume to continue it",
+			previous.Mode, previous.StartedAt.Format(time.RFC3339))
+	default:
+		p.state = *previous
+		log.Infof("Resuming the %s run started at %s, %d steps already completed",
+			previous.Mode, previous.StartedAt.Format(time.RFC3339), len(previous.CompletedSteps))
+	}
+
+	return p, p.save()
+}
+
+// loadState reads a state file, returning nil when it does not exist
+func loadState(path string) (*operationState, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read state file: %v", err)
+	}
+
+	var state operationState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse state file %s: %v", path, err)
+	}
+	return &state, nil
+}
+
+// done reports whether a previous run completed step
+func (p *progress) done(step string) bool {
+	if p == nil {
+		return false
+	}
+	for _, completed := range p.state.CompletedSteps {
+		if completed == step {
+			return true
+		}
+	}
+	return false
+}
+
+// complete records that step is completed
+func (p *progress) complete(step string) error {
+	if p == nil || p.done(step) {
+		return nil
+	}
+	p.state.CompletedSteps = append(p.state.CompletedSteps, step)
+	p.state.UpdatedAt = time.Now().UTC()
+	return p.save()
+}
+
+// finish removes the state file once the operation succeeded, there is nothing left to resume
+func (p *progress) finish() error {
+	if p == nil {
+		return nil
+	}
+	if err := os.Remove(p.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to remove state file: %v", err)
+	}
+	return nil
+}
+
+// save writes the state file, replacing it atomically so a crash never leaves a truncated file
+func (p *progress) save() error {
+	data, err := json.MarshalIndent(p.state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode state: %v", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create state directory: %v", err)
+	}
+
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("failed to write state file: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProgressResume(t *testing.T) {
+	config := &Config{Mode: "Cutover", SourceNamespace: "shop", DestNamespace: "shop-dr", StateDir: t.TempDir()}
+	var ran []string
+	var result *MappingResult
+	run := func(p *progress, failAt string) error {
+		result = &MappingResult{}
+		r := &mappingRun{progress: p, result: result}
+		for _, step := range []string{"sync-resources", "scale-down-source", "scale-up-destination"} {
+			step := step
+			if err := r.step(step, func() error {
+				ran = append(ran, step)
+				if step == failAt {
+					return errors.New("connection refused")
+				}
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+		return p.finish()
+	}
+
+	// The first run dies after scaling down the source
+	p, err := openProgress(config)
+	require.NoError(t, err)
+	require.Error(t, run(p, "scale-up-destination"))
+	path := filepath.Join(config.StateDir, "cutover-shop-shop-dr.json")
+	state, err := loadState(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sync-resources", "scale-down-source"}, state.CompletedSteps)
+
+	// Resuming continues from the failed step and clears the progress once done
+	config.Resume = true
+	ran = nil
+	p, err = openProgress(config)
+	require.NoError(t, err)
+	require.NoError(t, run(p, ""))
+	assert.Equal(t, []string{"scale-up-destination"}, ran)
+	require.Len(t, result.Steps, 3)
+	assert.True(t, result.Steps[0].Skipped)
+	assert.True(t, result.Steps[1].Skipped)
+	assert.False(t, result.Steps[2].Skipped)
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestProgressWithoutResume(t *testing.T) {
+	config := &Config{Mode: "Stage", SourceNamespace: "shop", DestNamespace: "shop-dr", StateDir: t.TempDir()}
+	p, err := openProgress(config)
+	require.NoError(t, err)
+	require.NoError(t, p.complete("sync-resources"))
+
+	// A run without --resume starts from the beginning
+	p, err = openProgress(config)
+	require.NoError(t, err)
+	assert.False(t, p.done("sync-resources"))
+
+	// Without a state directory nothing is recorded and every step runs
+	p, err = openProgress(&Config{Mode: "Stage"})
+	require.NoError(t, err)
+	assert.Nil(t, p)
+	ran := false
+	r := &mappingRun{progress: p, result: &MappingResult{}}
+	require.NoError(t, r.step("sync-resources", func() error { ran = true; return nil }))
+	assert.True(t, ran)
+	assert.NoError(t, p.finish())
+}
@@ -20,6 +20,40 @@ type AppConfig struct {
 	LeaderElectionID     string        `json:"leaderElectionId"` // ID for leader election
 	LogLevel             string        `json:"logLevel"`         // Log level for the application
 	IgnoreCert           bool          `json:"ignoreCert"`       // Ignore certificate errors
+
+	AuditConfigMapName      string `json:"auditConfigMapName"`      // ConfigMap holding the audit ring buffer, empty disables it
+	AuditConfigMapNamespace string `json:"auditConfigMapNamespace"` // Namespace of the audit ConfigMap
+	AuditMaxEntries         int    `json:"auditMaxEntries"`         // Number of audit entries kept in the ConfigMap
+
+	ListPageSize int64 `json:"listPageSize"` // Number of objects requested per List call to the source cluster, 0 disables pagination
+
+	NotifyWebhookURL    string `json:"notifyWebhookURL"`    // Webhook receiving the notifications of every NamespaceMapping, empty disables it
+	NotifyWebhookFormat string `json:"notifyWebhookFormat"` // Payload format of the webhook: Generic, Slack or Teams
+	NotifyEvents        string `json:"notifyEvents"`        // Comma-separated events sent to the webhook, empty sends all events
+
+	MaxConcurrentDataSyncs int `json:"maxConcurrentDataSyncs"` // PVC data syncs running at the same time across all clusters, 0 uses the RemoteClusters' globalConcurrencyLimit
+
+	ReadinessMaxQueueDepth       int `json:"readinessMaxQueueDepth"`       // NamespaceMappings waiting for a reconcile worker above which the controller is not ready, 0 disables the check
+	ReadinessMaxOverdueMappings  int `json:"readinessMaxOverdueMappings"`  // NamespaceMappings with an overdue scheduled reconcile above which the controller is not ready, 0 disables the check
+	ReadinessMaxWaitingDataSyncs int `json:"readinessMaxWaitingDataSyncs"` // PVC data syncs waiting for a concurrency slot above which the controller is not ready, 0 disables the check
+
+	EnableDashboard bool `json:"enableDashboard"` // Serve the read-only web dashboard on the metrics server
+
+	EnableTracing bool `json:"enableTracing"` // Export OpenTelemetry traces to the collector set by the OTEL_EXPORTER_OTLP_* variables
+
+	ExclusionLabels string `json:"exclusionLabels"` // Comma-separated labels (key or key=value) excluding source resources from replication
+
+	RemoteClusterFailureThreshold int           `json:"remoteClusterFailureThreshold"` // Consecutive failed requests opening the circuit of a remote cluster
+	RemoteClusterCircuitCooldown  time.Duration `json:"remoteClusterCircuitCooldown"`  // How long requests to a remote cluster fail fast after its circuit opened
+
+	WatchNamespaces string `json:"watchNamespaces"` // Comma-separated namespaces the controller caches and reconciles, empty watches all namespaces
+
+	RendezvousAddr        string `json:"rendezvousAddr"`        // Address the rendezvous of the agents with the Reverse exposure listens on, empty disables it
+	RendezvousHostKeyFile string `json:"rendezvousHostKeyFile"` // Private host key of the rendezvous, verified by the agents
+
+	EnableWebhooks bool   `json:"enableWebhooks"` // Serve the validating admission webhook of NamespaceMappings
+	WebhookPort    int    `json:"webhookPort"`    // Port the webhook server listens on
+	WebhookCertDir string `json:"webhookCertDir"` // Directory holding the tls.crt and tls.key of the webhook server
 }
 
 // CFG is the global configuration instance.
@@ -38,6 +72,44 @@ func LoadConfiguration() {
 	CFG.LeaderElectionID = getEnvOrDefault("LEADER_ELECTION_ID", "dr-syncer.io")
 	CFG.LogLevel = getEnvOrDefault("LOG_LEVEL", "info")
 	CFG.IgnoreCert = parseEnvBool("IGNORE_CERT", false)
+	CFG.AuditConfigMapName = getEnvOrDefault("AUDIT_CONFIGMAP_NAME", "")
+	CFG.AuditConfigMapNamespace = getEnvOrDefault("AUDIT_CONFIGMAP_NAMESPACE", getEnvOrDefault("WATCH_NAMESPACE", "dr-syncer"))
+	CFG.AuditMaxEntries = parseEnvInt("AUDIT_MAX_ENTRIES", 500)
+	CFG.ListPageSize = int64(parseEnvInt("LIST_PAGE_SIZE", 500))
+	CFG.NotifyWebhookURL = getEnvOrDefault("NOTIFY_WEBHOOK_URL", "")
+	CFG.NotifyWebhookFormat = getEnvOrDefault("NOTIFY_WEBHOOK_FORMAT", "Generic")
+	CFG.NotifyEvents = getEnvOrDefault("NOTIFY_EVENTS", "")
+	CFG.MaxConcurrentDataSyncs = parseEnvInt("MAX_CONCURRENT_DATA_SYNCS", 0)
+	CFG.ReadinessMaxQueueDepth = parseEnvInt("READINESS_MAX_QUEUE_DEPTH", 0)
+	CFG.ReadinessMaxOverdueMappings = parseEnvInt("READINESS_MAX_OVERDUE_MAPPINGS", 0)
+	CFG.ReadinessMaxWaitingDataSyncs = parseEnvInt("READINESS_MAX_WAITING_DATA_SYNCS", 0)
+	CFG.EnableDashboard = parseEnvBool("ENABLE_DASHBOARD", false)
+	CFG.EnableTracing = parseEnvBool("ENABLE_TRACING", false)
+	CFG.ExclusionLabels = getEnvOrDefault("EXCLUSION_LABELS", "")
+	CFG.RemoteClusterFailureThreshold = parseEnvInt("REMOTE_CLUSTER_FAILURE_THRESHOLD", 5)
+	CFG.RemoteClusterCircuitCooldown = parseEnvDuration("REMOTE_CLUSTER_CIRCUIT_COOLDOWN", "1m")
+	CFG.WatchNamespaces = getEnvOrDefault("WATCH_NAMESPACES", "")
+	CFG.RendezvousAddr = getEnvOrDefault("RENDEZVOUS_ADDR", "")
+	CFG.RendezvousHostKeyFile = getEnvOrDefault("RENDEZVOUS_HOST_KEY_FILE", "")
+	CFG.EnableWebhooks = parseEnvBool("ENABLE_WEBHOOKS", false)
+	CFG.WebhookPort = parseEnvInt("WEBHOOK_PORT", 9443)
+	CFG.WebhookCertDir = getEnvOrDefault("WEBHOOK_CERT_DIR", "/tmp/k8s-webhook-server/serving-certs")
+}
+
+// ParseNamespaces splits a comma-separated list of namespaces such as WatchNamespaces, dropping blanks
+// and duplicates. It returns nil for an empty list, which stands for all namespaces.
+func ParseNamespaces(value string) []string {
+	var namespaces []string
+	seen := make(map[string]bool)
+	for _, namespace := range strings.Split(value, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" || seen[namespace] {
+			continue
+		}
+		seen[namespace] = true
+		namespaces = append(namespaces, namespace)
+	}
+	return namespaces
 }
 
 // getEnvOrDefault retrieves the value of an environment variable or returns a default value if not set.
@@ -185,6 +185,7 @@ func TestLoadConfiguration_Defaults(t *testing.T) {
 		"KUBECONFIG", "SYNC_INTERVAL", "RESYNC_PERIOD", "LOG_VERBOSITY",
 		"METRICS_ADDR", "PROBE_ADDR", "ENABLE_LEADER_ELECTION",
 		"LEADER_ELECTION_ID", "LOG_LEVEL", "IGNORE_CERT",
+		"AUDIT_CONFIGMAP_NAME", "AUDIT_CONFIGMAP_NAMESPACE", "AUDIT_MAX_ENTRIES", "WATCH_NAMESPACE", "LIST_PAGE_SIZE",
 	}
 
 	cleanups := make([]func(), 0, len(envVars))
@@ -210,6 +211,10 @@ func TestLoadConfiguration_Defaults(t *testing.T) {
 	assert.Equal(t, "dr-syncer.io", CFG.LeaderElectionID)
 	assert.Equal(t, "info", CFG.LogLevel)
 	assert.False(t, CFG.IgnoreCert)
+	assert.Equal(t, "", CFG.AuditConfigMapName)
+	assert.Equal(t, "dr-syncer", CFG.AuditConfigMapNamespace)
+	assert.Equal(t, 500, CFG.AuditMaxEntries)
+	assert.Equal(t, int64(500), CFG.ListPageSize)
 }
 
 func TestLoadConfiguration_CustomValues(t *testing.T) {
@@ -225,6 +230,10 @@ func TestLoadConfiguration_CustomValues(t *testing.T) {
 		withEnv(t, "LEADER_ELECTION_ID", "custom-leader-id"),
 		withEnv(t, "LOG_LEVEL", "debug"),
 		withEnv(t, "IGNORE_CERT", "yes"),
+		withEnv(t, "AUDIT_CONFIGMAP_NAME", "dr-syncer-audit"),
+		withEnv(t, "AUDIT_CONFIGMAP_NAMESPACE", "dr-system"),
+		withEnv(t, "AUDIT_MAX_ENTRIES", "100"),
+		withEnv(t, "LIST_PAGE_SIZE", "250"),
 	}
 	defer func() {
 		for _, cleanup := range cleanups {
@@ -244,4 +253,14 @@ func TestLoadConfiguration_CustomValues(t *testing.T) {
 	assert.Equal(t, "custom-leader-id", CFG.LeaderElectionID)
 	assert.Equal(t, "debug", CFG.LogLevel)
 	assert.True(t, CFG.IgnoreCert)
+	assert.Equal(t, "dr-syncer-audit", CFG.AuditConfigMapName)
+	assert.Equal(t, "dr-system", CFG.AuditConfigMapNamespace)
+	assert.Equal(t, 100, CFG.AuditMaxEntries)
+	assert.Equal(t, int64(250), CFG.ListPageSize)
+}
+
+func TestParseNamespaces(t *testing.T) {
+	assert.Nil(t, ParseNamespaces(""), "an empty list watches all namespaces")
+	assert.Nil(t, ParseNamespaces(" , "))
+	assert.Equal(t, []string{"team-a", "team-b"}, ParseNamespaces("team-a, team-b,,team-a"))
 }
@@ -26,4 +26,16 @@ const (
 
 	// ClusterTypeKey is used to store the cluster type (source/destination) in context
 	ClusterTypeKey ContextKey = "cluster-type"
+
+	// MappingKey is used to store the name of the mapping being synced in context
+	MappingKey ContextKey = "mapping"
+
+	// SourceCacheKey is used to store the informer cache serving source cluster reads in context
+	SourceCacheKey ContextKey = "source-cache"
+
+	// RetryResourcesKey is used to store the failed resources a retry sync is limited to in context
+	RetryResourcesKey ContextKey = "retry-resources"
+
+	// SyncGenerationKey is used to store the sync generation destination resources are labeled with in context
+	SyncGenerationKey ContextKey = "sync-generation"
 )
@@ -26,6 +26,7 @@ func TestContextKey_Constants(t *testing.T) {
 	assert.Equal(t, ContextKey("source-cluster"), SourceClusterKey)
 	assert.Equal(t, ContextKey("dest-cluster"), DestClusterKey)
 	assert.Equal(t, ContextKey("cluster-type"), ClusterTypeKey)
+	assert.Equal(t, ContextKey("mapping"), MappingKey)
 }
 
 func TestContextKey_StringValues(t *testing.T) {
@@ -125,6 +126,7 @@ func TestContextKey_Uniqueness(t *testing.T) {
 		SourceClusterKey,
 		DestClusterKey,
 		ClusterTypeKey,
+		MappingKey,
 	}
 
 	// Check all pairs
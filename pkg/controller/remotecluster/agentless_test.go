@@ -0,0 +1,33 @@
+package remotecluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestReconcile_AgentlessRemovesAgent(t *testing.T) {
+	agent := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "dr-syncer-agent", Namespace: "dr-syncer"}}
+	remote := fake.NewClientBuilder().WithObjects(agent).Build()
+	p := NewPVCSyncManager(remote, fake.NewClientBuilder().Build())
+
+	rc := &drv1alpha1.RemoteCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "dr-syncer"},
+		Spec:       drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{Enabled: true, Agentless: true}},
+		Status:     drv1alpha1.RemoteClusterStatus{PVCSync: &drv1alpha1.PVCSyncStatus{Phase: "Running"}},
+	}
+	require.NoError(t, p.Reconcile(context.Background(), rc))
+
+	assert.Equal(t, PhaseAgentless, rc.Status.PVCSync.Phase)
+	err := remote.Get(context.Background(), client.ObjectKeyFromObject(agent), &appsv1.DaemonSet{})
+	assert.True(t, apierrors.IsNotFound(err), "the agent DaemonSet is removed")
+}
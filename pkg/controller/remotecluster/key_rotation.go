@@ -0,0 +1,124 @@
+package remotecluster
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controller/replication"
+)
+
+// keyRotationInterval returns the key rotation interval of the cluster, zero when keys are not rotated
+func keyRotationInterval(rc *drv1alpha1.RemoteCluster) time.Duration {
+	if rc.Spec.PVCSync == nil || rc.Spec.PVCSync.SSH == nil || rc.Spec.PVCSync.SSH.KeyRotationInterval == nil {
+		return 0
+	}
+	return rc.Spec.PVCSync.SSH.KeyRotationInterval.Duration
+}
+
+// keyRotationDue reports whether the keys of the cluster are due for rotation at now. Keys never
+// rotated are not due, their rotation is scheduled from the first time they are seen.
+func keyRotationDue(rc *drv1alpha1.RemoteCluster, now time.Time) bool {
+	interval := keyRotationInterval(rc)
+	if interval <= 0 || rc.Status.PVCSync == nil || rc.Status.PVCSync.LastKeyRotation == nil {
+		return false
+	}
+	return !now.Before(rc.Status.PVCSync.LastKeyRotation.Add(interval))
+}
+
+// staleLockTimeouts returns the age after which the PVC locks of each source namespace are taken over,
+// the longest stale lock timeout of the NamespaceMappings syncing from the namespace
+func (p *PVCSyncManager) staleLockTimeouts(ctx context.Context) (map[string]time.Duration, error) {
+	timeouts := map[string]time.Duration{}
+	if p.controllerClient == nil {
+		return timeouts, nil
+	}
+	mappings := &drv1alpha1.NamespaceMappingList{}
+	if err := p.controllerClient.List(ctx, mappings); err != nil {
+		return nil, fmt.Errorf("failed to list namespace mappings: %v", err)
+	}
+	for i := range mappings.Items {
+		spec := &mappings.Items[i].Spec
+		var configured *metav1.Duration
+		if spec.PVCConfig != nil && spec.PVCConfig.DataSyncConfig != nil {
+			configured = spec.PVCConfig.DataSyncConfig.StaleLockTimeout
+		}
+		if timeout := replication.StaleLockTimeout(configured); timeout > timeouts[spec.SourceNamespace] {
+			timeouts[spec.SourceNamespace] = timeout
+		}
+	}
+	return timeouts, nil
+}
+
+// inFlightDataSyncs returns the PVCs of the remote cluster whose data is being synced from its agents
+func (p *PVCSyncManager) inFlightDataSyncs(ctx context.Context, now time.Time) ([]string, error) {
+	timeouts, err := p.staleLockTimeouts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pvcs := &corev1.PersistentVolumeClaimList{}
+	if err := p.remoteClient.List(ctx, pvcs); err != nil {
+		return nil, fmt.Errorf("failed to list PVCs: %v", err)
+	}
+
+	var inFlight []string
+	for i := range pvcs.Items {
+		pvc := &pvcs.Items[i]
+		staleAfter, ok := timeouts[pvc.Namespace]
+		if !ok {
+			staleAfter = replication.StaleLockTimeout(nil)
+		}
+		if replication.LockHeld(pvc, staleAfter, now) {
+			inFlight = append(inFlight, pvc.Namespace+"/"+pvc.Name)
+		}
+	}
+	return inFlight, nil
+}
+
+// rotateKeysIfDue regenerates the agent host keys and the rsync key pair of the cluster once its key
+// rotation interval elapsed. The agents are restarted with the new keys, so the rotation is postponed
+// while data is synced from them. ClusterMappings targeting the cluster redistribute the new key when
+// they see lastKeyRotation change and keep accepting the previous one for their grace period. It
+// returns whether the keys were rotated.
+func (p *PVCSyncManager) rotateKeysIfDue(ctx context.Context, rc *drv1alpha1.RemoteCluster) (bool, error) {
+	now := time.Now()
+	if keyRotationInterval(rc) <= 0 {
+		return false, nil
+	}
+	if rc.Status.PVCSync.LastKeyRotation == nil {
+		rc.Status.PVCSync.LastKeyRotation = &metav1.Time{Time: now}
+		return false, nil
+	}
+	if !keyRotationDue(rc, now) {
+		return false, nil
+	}
+
+	inFlight, err := p.inFlightDataSyncs(ctx, now)
+	if err != nil {
+		return false, fmt.Errorf("failed to check in-flight syncs before key rotation: %v", err)
+	}
+	if len(inFlight) > 0 {
+		log.Infof("Postponing SSH key rotation for cluster %s, %d PVC syncs in flight: %v", rc.Name, len(inFlight), inFlight)
+		return false, nil
+	}
+
+	log.Infof("Rotating SSH keys for cluster %s, last rotated at %s", rc.Name, rc.Status.PVCSync.LastKeyRotation.Format(time.RFC3339))
+	if err := p.RotateSSHKeys(ctx, rc); err != nil {
+		return false, err
+	}
+
+	rsyncSecret, err := p.keyManager.RotateRsyncKeys(ctx, rc, "dr-syncer")
+	if err != nil {
+		return false, fmt.Errorf("failed to rotate rsync SSH keys: %v", err)
+	}
+	if err := p.keyManager.PushRsyncKeysToCluster(ctx, rc, p.remoteClient, rsyncSecret, "dr-syncer"); err != nil {
+		return false, fmt.Errorf("failed to push rotated rsync SSH keys to remote cluster: %v", err)
+	}
+
+	rc.Status.PVCSync.LastKeyRotation = &metav1.Time{Time: now}
+	return true, nil
+}
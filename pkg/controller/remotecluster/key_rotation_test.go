@@ -0,0 +1,112 @@
+package remotecluster
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controller/replication"
+)
+
+func rotatingCluster(interval time.Duration, lastRotation *time.Time) *drv1alpha1.RemoteCluster {
+	rc := &drv1alpha1.RemoteCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "dr-syncer"},
+		Spec: drv1alpha1.RemoteClusterSpec{
+			PVCSync: &drv1alpha1.PVCSyncSpec{
+				Enabled: true,
+				SSH:     &drv1alpha1.PVCSyncSSH{KeyRotationInterval: &metav1.Duration{Duration: interval}},
+			},
+		},
+		Status: drv1alpha1.RemoteClusterStatus{PVCSync: &drv1alpha1.PVCSyncStatus{}},
+	}
+	if lastRotation != nil {
+		rc.Status.PVCSync.LastKeyRotation = &metav1.Time{Time: *lastRotation}
+	}
+	return rc
+}
+
+func lockedPVC(name string, lockedAt time.Time) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "app",
+			Annotations: map[string]string{
+				replication.LockOwnerAnnotation:     "dr-syncer-controller-0",
+				replication.LockTimestampAnnotation: lockedAt.Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+func TestKeyRotationDue(t *testing.T) {
+	now := time.Now()
+	dayAgo := now.Add(-24 * time.Hour)
+	hourAgo := now.Add(-time.Hour)
+
+	assert.False(t, keyRotationDue(rotatingCluster(0, &dayAgo), now), "no interval")
+	assert.False(t, keyRotationDue(rotatingCluster(12*time.Hour, nil), now), "never rotated")
+	assert.False(t, keyRotationDue(rotatingCluster(12*time.Hour, &hourAgo), now), "interval not elapsed")
+	assert.True(t, keyRotationDue(rotatingCluster(12*time.Hour, &dayAgo), now), "interval elapsed")
+}
+
+func TestInFlightDataSyncs(t *testing.T) {
+	now := time.Now()
+	idle := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "idle", Namespace: "app"}}
+	remote := fake.NewClientBuilder().WithObjects(
+		idle,
+		lockedPVC("syncing", now.Add(-5*time.Minute)),
+		lockedPVC("stale", now.Add(-2*time.Hour)),
+	).Build()
+
+	p := &PVCSyncManager{remoteClient: remote}
+	inFlight, err := p.inFlightDataSyncs(context.Background(), now)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app/syncing"}, inFlight)
+
+	// The stale lock timeout configured by the mappings of the namespace keeps older locks live
+	scheme := runtime.NewScheme()
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+	p.controllerClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dr-syncer"},
+		Spec: drv1alpha1.NamespaceMappingSpec{
+			SourceNamespace: "app",
+			PVCConfig: &drv1alpha1.PVCConfig{DataSyncConfig: &drv1alpha1.PVCDataSyncConfig{
+				StaleLockTimeout: &metav1.Duration{Duration: 3 * time.Hour},
+			}},
+		},
+	}).Build()
+	inFlight, err = p.inFlightDataSyncs(context.Background(), now)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"app/syncing", "app/stale"}, inFlight)
+}
+
+func TestRotateKeysIfDue_SchedulesFirstRotation(t *testing.T) {
+	rc := rotatingCluster(12*time.Hour, nil)
+	p := &PVCSyncManager{remoteClient: fake.NewClientBuilder().Build()}
+
+	rotated, err := p.rotateKeysIfDue(context.Background(), rc)
+	require.NoError(t, err)
+	assert.False(t, rotated)
+	require.NotNil(t, rc.Status.PVCSync.LastKeyRotation)
+	assert.WithinDuration(t, time.Now(), rc.Status.PVCSync.LastKeyRotation.Time, time.Minute)
+}
+
+func TestRotateKeysIfDue_PostponedWhileSyncing(t *testing.T) {
+	dayAgo := time.Now().Add(-24 * time.Hour)
+	rc := rotatingCluster(12*time.Hour, &dayAgo)
+	remote := fake.NewClientBuilder().WithObjects(lockedPVC("syncing", time.Now())).Build()
+	p := &PVCSyncManager{remoteClient: remote}
+
+	rotated, err := p.rotateKeysIfDue(context.Background(), rc)
+	require.NoError(t, err)
+	assert.False(t, rotated)
+	assert.Equal(t, dayAgo.Unix(), rc.Status.PVCSync.LastKeyRotation.Unix(), "the rotation stays due")
+}
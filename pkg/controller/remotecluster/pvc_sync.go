@@ -3,23 +3,21 @@ package remotecluster
 import (
 	"context"
 	"fmt"
-	"os"
 	"time"
 
-	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	"github.com/supporttools/dr-syncer/pkg/agent/deploy"
 	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
 	"github.com/supporttools/dr-syncer/pkg/controller/remotecluster/temp"
+	"github.com/supporttools/dr-syncer/pkg/util"
 )
 
 const (
@@ -27,6 +25,8 @@ const (
 	DefaultSyncPeriod = 60 * time.Minute
 	// MinSyncPeriod is the minimum allowed sync period
 	MinSyncPeriod = 5 * time.Minute
+	// PhaseAgentless is the PVC sync phase of clusters whose PVC data is synced without the agent
+	PhaseAgentless = "Agentless"
 )
 
 // PVCSyncManager handles PVC sync operations
@@ -61,6 +61,11 @@ func (p *PVCSyncManager) ForceSync(ctx context.Context, rc *drv1alpha1.RemoteClu
 		return nil
 	}
 
+	// Agentless clusters never run the agent, their PVC data is streamed through the exec API
+	if rc.Spec.PVCSync.Agentless {
+		return p.reconcileAgentless(ctx, rc)
+	}
+
 	// Initialize status if needed
 	if rc.Status.PVCSync == nil {
 		rc.Status.PVCSync = &drv1alpha1.PVCSyncStatus{
@@ -145,28 +150,8 @@ func (p *PVCSyncManager) ForceSync(ctx context.Context, rc *drv1alpha1.RemoteClu
 		}
 	}
 
-	// Update agent status from DaemonSet
-	ds := &appsv1.DaemonSet{}
-	if err := p.remoteClient.Get(ctx, client.ObjectKey{Name: "dr-syncer-agent", Namespace: "dr-syncer"}, ds); err == nil {
-		rc.Status.PVCSync.AgentStatus.TotalNodes = ds.Status.DesiredNumberScheduled
-		rc.Status.PVCSync.AgentStatus.ReadyNodes = ds.Status.NumberReady
-
-		// Update overall status based on DaemonSet status
-		if ds.Status.NumberReady > 0 {
-			rc.Status.PVCSync.Phase = "Running"
-			rc.Status.PVCSync.Message = "PVC sync agent is running"
-		} else if ds.Status.DesiredNumberScheduled > 0 {
-			rc.Status.PVCSync.Phase = "Degraded"
-			rc.Status.PVCSync.Message = fmt.Sprintf("PVC sync agent is degraded: 0/%d pods ready", ds.Status.DesiredNumberScheduled)
-		} else {
-			rc.Status.PVCSync.Phase = "Running"
-			rc.Status.PVCSync.Message = "PVC sync agent deployed successfully"
-		}
-	} else {
-		// If we can't get the DaemonSet, assume it's still starting up
-		rc.Status.PVCSync.Phase = "Running"
-		rc.Status.PVCSync.Message = "PVC sync agent deployed successfully"
-	}
+	// Update agent status from the DaemonSet rollout
+	p.updateRolloutPhase(ctx, rc)
 
 	// Update deployment time
 	rc.Status.PVCSync.LastDeploymentTime = &metav1.Time{Time: time.Now()}
@@ -188,6 +173,11 @@ func (p *PVCSyncManager) Reconcile(ctx context.Context, rc *drv1alpha1.RemoteClu
 		return nil
 	}
 
+	// Agentless clusters never run the agent, their PVC data is streamed through the exec API
+	if rc.Spec.PVCSync.Agentless {
+		return p.reconcileAgentless(ctx, rc)
+	}
+
 	// Initialize status if needed
 	if rc.Status.PVCSync == nil {
 		rc.Status.PVCSync = &drv1alpha1.PVCSyncStatus{
@@ -212,6 +202,18 @@ func (p *PVCSyncManager) Reconcile(ctx context.Context, rc *drv1alpha1.RemoteClu
 		return err
 	}
 
+	// Rotate the SSH keys once the rotation interval elapsed, the agents are redeployed with them
+	rotated, err := p.rotateKeysIfDue(ctx, rc)
+	if err != nil {
+		log.Errorf("Failed to rotate SSH keys: %v", err)
+		rc.Status.PVCSync.Phase = "Failed"
+		rc.Status.PVCSync.Message = fmt.Sprintf("Failed to rotate SSH keys: %v", err)
+		return err
+	}
+	if rotated {
+		return nil
+	}
+
 	// Check if agents are already deployed and running
 	agentsRunning := false
 	if rc.Status.PVCSync != nil && rc.Status.PVCSync.AgentStatus != nil &&
@@ -226,6 +228,14 @@ func (p *PVCSyncManager) Reconcile(ctx context.Context, rc *drv1alpha1.RemoteClu
 			// Not enough time has passed and agents are already running, skip deployment
 			log.Infof("Skipping agent deployment for cluster %s - last deployment was %v ago, waiting for %v",
 				rc.Name, timeSinceLastDeployment.Round(time.Second), DefaultSyncPeriod)
+
+			// Agent pods and load balancer addresses change between deployments
+			if err := p.deployer.UpdateAgentEndpoints(ctx, rc); err != nil {
+				log.Warnf("Failed to update agent endpoints for cluster %s: %v", rc.Name, err)
+			}
+
+			// Report the progress of a rollout started by the last deployment
+			p.updateRolloutPhase(ctx, rc)
 			return nil
 		}
 	}
@@ -298,33 +308,41 @@ func (p *PVCSyncManager) Reconcile(ctx context.Context, rc *drv1alpha1.RemoteClu
 		}
 	}
 
-	// Update agent status from DaemonSet
-	ds := &appsv1.DaemonSet{}
-	if err := p.remoteClient.Get(ctx, client.ObjectKey{Name: "dr-syncer-agent", Namespace: "dr-syncer"}, ds); err == nil {
-		rc.Status.PVCSync.AgentStatus.TotalNodes = ds.Status.DesiredNumberScheduled
-		rc.Status.PVCSync.AgentStatus.ReadyNodes = ds.Status.NumberReady
+	// Update agent status from the DaemonSet rollout
+	p.updateRolloutPhase(ctx, rc)
 
-		// Update overall status based on DaemonSet status
-		if ds.Status.NumberReady > 0 {
-			rc.Status.PVCSync.Phase = "Running"
-			rc.Status.PVCSync.Message = "PVC sync agent is running"
-		} else if ds.Status.DesiredNumberScheduled > 0 {
-			rc.Status.PVCSync.Phase = "Degraded"
-			rc.Status.PVCSync.Message = fmt.Sprintf("PVC sync agent is degraded: 0/%d pods ready", ds.Status.DesiredNumberScheduled)
-		} else {
-			rc.Status.PVCSync.Phase = "Running"
-			rc.Status.PVCSync.Message = "PVC sync agent deployed successfully"
-		}
-	} else {
+	// Update deployment time
+	rc.Status.PVCSync.LastDeploymentTime = &metav1.Time{Time: time.Now()}
+
+	return nil
+}
+
+// updateRolloutPhase records the rollout of the agent DaemonSet and derives the PVC sync phase from it
+func (p *PVCSyncManager) updateRolloutPhase(ctx context.Context, rc *drv1alpha1.RemoteCluster) {
+	if err := p.deployer.UpdateRolloutStatus(ctx, rc); err != nil {
 		// If we can't get the DaemonSet, assume it's still starting up
+		log.Warnf("Failed to get agent rollout status for cluster %s: %v", rc.Name, err)
 		rc.Status.PVCSync.Phase = "Running"
 		rc.Status.PVCSync.Message = "PVC sync agent deployed successfully"
+		return
 	}
 
-	// Update deployment time
-	rc.Status.PVCSync.LastDeploymentTime = &metav1.Time{Time: time.Now()}
-
-	return nil
+	agent := rc.Status.PVCSync.AgentStatus
+	switch {
+	case agent.ReadyNodes > 0 && !agent.RolloutComplete:
+		rc.Status.PVCSync.Phase = "Running"
+		rc.Status.PVCSync.Message = fmt.Sprintf("PVC sync agent %s is rolling out: %d/%d nodes updated, %d available",
+			agent.Image, agent.UpdatedNodes, agent.TotalNodes, agent.AvailableNodes)
+	case agent.ReadyNodes > 0:
+		rc.Status.PVCSync.Phase = "Running"
+		rc.Status.PVCSync.Message = "PVC sync agent is running"
+	case agent.TotalNodes > 0:
+		rc.Status.PVCSync.Phase = "Degraded"
+		rc.Status.PVCSync.Message = fmt.Sprintf("PVC sync agent is degraded: 0/%d pods ready", agent.TotalNodes)
+	default:
+		rc.Status.PVCSync.Phase = "Running"
+		rc.Status.PVCSync.Message = "PVC sync agent deployed successfully"
+	}
 }
 
 // cleanupPVCSync removes PVC sync components
@@ -360,6 +378,28 @@ func (p *PVCSyncManager) cleanupPVCSync(ctx context.Context, rc *drv1alpha1.Remo
 	return nil
 }
 
+// reconcileAgentless removes the agent of a cluster switched to agentless PVC data syncs
+func (p *PVCSyncManager) reconcileAgentless(ctx context.Context, rc *drv1alpha1.RemoteCluster) error {
+	if rc.Status.PVCSync != nil && rc.Status.PVCSync.Phase == PhaseAgentless {
+		return nil
+	}
+
+	if rc.Status.PVCSync != nil && rc.Status.PVCSync.Phase != "" {
+		log.Infof("PVC sync of cluster %s is agentless, removing the agent", rc.Name)
+		if err := p.deployer.Cleanup(ctx); err != nil {
+			rc.Status.PVCSync.Phase = "Failed"
+			rc.Status.PVCSync.Message = fmt.Sprintf("Failed to remove the agent: %v", err)
+			return fmt.Errorf("failed to clean up agent components: %v", err)
+		}
+	}
+
+	rc.Status.PVCSync = &drv1alpha1.PVCSyncStatus{
+		Phase:   PhaseAgentless,
+		Message: "PVC data is streamed through the Kubernetes API, the agent is not deployed",
+	}
+	return nil
+}
+
 // RotateSSHKeys rotates SSH keys for the PVC sync agent
 func (p *PVCSyncManager) RotateSSHKeys(ctx context.Context, rc *drv1alpha1.RemoteCluster) error {
 	if rc.Spec.PVCSync == nil || !rc.Spec.PVCSync.Enabled {
@@ -603,27 +643,17 @@ func (p *PVCSyncManager) getRemoteClient(ctx context.Context, rc *drv1alpha1.Rem
 		return nil, fmt.Errorf("kubeconfig not found in secret %s/%s", secretNamespace, secretName)
 	}
 
-	// Create a temporary file for the kubeconfig
-	kubeconfigFile, err := os.CreateTemp("", "kubeconfig-*.yaml")
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary kubeconfig file: %v", err)
-	}
-	defer os.Remove(kubeconfigFile.Name())
-
-	// Write the kubeconfig data to the file
-	if _, err := kubeconfigFile.Write(kubeconfigData); err != nil {
-		return nil, fmt.Errorf("failed to write kubeconfig data to file: %v", err)
-	}
-	if err := kubeconfigFile.Close(); err != nil {
-		return nil, fmt.Errorf("failed to close kubeconfig file: %v", err)
-	}
-
 	// Create a Kubernetes client from the kubeconfig
-	config, err := clientcmd.BuildConfigFromFlags("", kubeconfigFile.Name())
+	config, err := util.RESTConfigFromKubeconfig(kubeconfigData, rc.Spec.KubeconfigSecretRef.Context)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build config from kubeconfig: %v", err)
 	}
 
+	// Route API and exec traffic through the configured proxy or jump host
+	if err := util.ApplyProxyConfig(ctx, p.controllerClient, config, rc.Spec.Proxy); err != nil {
+		return nil, fmt.Errorf("failed to configure proxy: %v", err)
+	}
+
 	// Create a Kubernetes clientset
 	clientset, err := kubernetes.NewForConfig(config)
 	if err != nil {
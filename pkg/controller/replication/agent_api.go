@@ -0,0 +1,94 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/agent/daemon"
+	"github.com/supporttools/dr-syncer/pkg/agent/volumeapi"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// agentAPITimeout bounds the mount path and stat requests to the agent's volume API
+const agentAPITimeout = 15 * time.Second
+
+// agentAPIPort returns the port the agent pod serves its volume API on, the port of its health endpoint
+func agentAPIPort(agentPod *corev1.Pod) string {
+	for _, container := range agentPod.Spec.Containers {
+		for _, port := range container.Ports {
+			if port.Name == "health" {
+				return strconv.Itoa(int(port.ContainerPort))
+			}
+		}
+	}
+	return strconv.Itoa(daemon.DefaultHealthPort)
+}
+
+// agentAPIGet calls an endpoint of the volume API of an agent pod through the pod proxy of the source
+// API server and decodes the JSON response into out. Agents that predate the volume API fail with a
+// not found error.
+func (p *PVCSyncer) agentAPIGet(ctx context.Context, agentPod *corev1.Pod, endpoint string, params map[string]string, out interface{}) error {
+	response := p.SourceK8sClient.CoreV1().Pods(agentPod.Namespace).ProxyGet("http", agentPod.Name, agentAPIPort(agentPod), endpoint, params)
+	if response == nil {
+		return fmt.Errorf("agent pod %s/%s cannot be proxied", agentPod.Namespace, agentPod.Name)
+	}
+
+	body, err := response.DoRaw(ctx)
+	if err != nil {
+		return fmt.Errorf("agent API %s of pod %s/%s failed: %v", endpoint, agentPod.Namespace, agentPod.Name, err)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("invalid response of agent API %s of pod %s/%s: %v", endpoint, agentPod.Namespace, agentPod.Name, err)
+	}
+	return nil
+}
+
+// agentMountPath asks the agent for the mount path of a PV on its node, empty when it is not mounted there
+func (p *PVCSyncer) agentMountPath(ctx context.Context, agentPod *corev1.Pod, volumeName string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, agentAPITimeout)
+	defer cancel()
+
+	var response volumeapi.MountPathResponse
+	if err := p.agentAPIGet(ctx, agentPod, volumeapi.MountPathEndpoint, map[string]string{"volume": volumeName}, &response); err != nil {
+		return "", err
+	}
+	return response.Path, nil
+}
+
+// agentStat asks the agent to stat a path on its node
+func (p *PVCSyncer) agentStat(ctx context.Context, agentPod *corev1.Pod, path string) (*volumeapi.StatResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, agentAPITimeout)
+	defer cancel()
+
+	var response volumeapi.StatResponse
+	if err := p.agentAPIGet(ctx, agentPod, volumeapi.StatEndpoint, map[string]string{"path": path}, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// agentChangedSince asks the agent for an entry under path whose inode changed after since, empty when
+// nothing changed
+func (p *PVCSyncer) agentChangedSince(ctx context.Context, agentPod *corev1.Pod, path string, since time.Time) (string, error) {
+	var response volumeapi.LatestChangeResponse
+	params := map[string]string{
+		"path":  path,
+		"since": strconv.FormatInt(since.Unix(), 10),
+	}
+	if err := p.agentAPIGet(ctx, agentPod, volumeapi.LatestChangeEndpoint, params, &response); err != nil {
+		return "", err
+	}
+	return response.Path, nil
+}
+
+// agentDiskUsage asks the agent for the space used by the files under path on its node
+func (p *PVCSyncer) agentDiskUsage(ctx context.Context, agentPod *corev1.Pod, path string) (int64, error) {
+	var response volumeapi.UsageResponse
+	if err := p.agentAPIGet(ctx, agentPod, volumeapi.UsageEndpoint, map[string]string{"path": path}, &response); err != nil {
+		return 0, err
+	}
+	return response.UsedBytes, nil
+}
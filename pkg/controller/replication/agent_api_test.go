@@ -0,0 +1,144 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/agent/volumeapi"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// proxyResponse is the response of a proxied agent API call
+type proxyResponse struct {
+	body []byte
+}
+
+func (r proxyResponse) DoRaw(context.Context) ([]byte, error) {
+	return r.body, nil
+}
+
+func (r proxyResponse) Stream(context.Context) (io.ReadCloser, error) {
+	return nil, fmt.Errorf("not implemented")
+}
+
+// serveAgentAPI answers the proxied agent API calls of client with the response of each endpoint
+func serveAgentAPI(client *fake.Clientset, responses map[string]interface{}) {
+	client.AddProxyReactor("pods", func(action k8stesting.Action) (bool, rest.ResponseWrapper, error) {
+		response, ok := responses[action.(k8stesting.ProxyGetAction).GetPath()]
+		if !ok {
+			return false, nil, nil
+		}
+		body, _ := json.Marshal(response)
+		return true, proxyResponse{body: body}, nil
+	})
+}
+
+func mountPathTestObjects() (*corev1.PersistentVolumeClaim, *corev1.PersistentVolume, *corev1.Pod) {
+	pvc := newTestPVC("shop", "orders", nil)
+	pvc.Spec.VolumeName = "pv-orders"
+	pv := &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "pv-orders"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumeBound},
+	}
+	agentPod := agentTestPod("agent-a", "node-1", corev1.PodRunning)
+	agentPod.UID = "agent-uid"
+	agentPod.Spec.Containers = []corev1.Container{{
+		Name:  "agent",
+		Ports: []corev1.ContainerPort{{Name: "health", ContainerPort: 9900}},
+	}}
+	return pvc, pv, agentPod
+}
+
+func TestFindPVCMountPath_AgentAPI(t *testing.T) {
+	ctx := context.Background()
+	pvc, pv, agentPod := mountPathTestObjects()
+	client := fake.NewSimpleClientset(pvc, pv)
+	mountPath := "/var/lib/kubelet/pods/uid-1/volumes/kubernetes.io~csi/pv-orders/mount"
+	serveAgentAPI(client, map[string]interface{}{
+		volumeapi.MountPathEndpoint: volumeapi.MountPathResponse{Path: mountPath},
+	})
+	p := &PVCSyncer{SourceK8sClient: client, SourceConfig: &rest.Config{}}
+
+	path, err := p.FindPVCMountPath(ctx, "shop", "orders", agentPod)
+	require.NoError(t, err)
+	assert.Equal(t, mountPath, path)
+
+	// The agent is called on its health port through the pod proxy
+	var proxied []k8stesting.ProxyGetAction
+	for _, action := range client.Actions() {
+		if proxy, ok := action.(k8stesting.ProxyGetAction); ok {
+			proxied = append(proxied, proxy)
+		}
+	}
+	require.Len(t, proxied, 1)
+	assert.Equal(t, "9900", proxied[0].GetPort())
+	assert.Equal(t, map[string]string{"volume": "pv-orders"}, proxied[0].GetParams())
+
+	pvc, err = client.CoreV1().PersistentVolumeClaims("shop").Get(ctx, "orders", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Contains(t, pvc.Annotations[MountPathCacheAnnotation], mountPath)
+}
+
+func TestFindPVCMountPath_NotMounted(t *testing.T) {
+	pvc, pv, agentPod := mountPathTestObjects()
+	client := fake.NewSimpleClientset(pvc, pv)
+	serveAgentAPI(client, map[string]interface{}{
+		volumeapi.MountPathEndpoint: volumeapi.MountPathResponse{},
+	})
+	p := &PVCSyncer{SourceK8sClient: client, SourceConfig: &rest.Config{}}
+
+	_, err := p.FindPVCMountPath(context.Background(), "shop", "orders", agentPod)
+	assert.ErrorContains(t, err, "volume pv-orders is not mounted on node node-1")
+}
+
+func TestFindPVCMountPath_StaleCache(t *testing.T) {
+	ctx := context.Background()
+	pvc, pv, agentPod := mountPathTestObjects()
+	cache, _ := json.Marshal(MountPathCache{
+		Path:        "/var/lib/kubelet/pods/old/volumes/kubernetes.io~csi/pv-orders/mount",
+		NodeName:    "node-1",
+		AgentPodUID: "agent-uid",
+		Timestamp:   time.Now().Format(time.RFC3339),
+	})
+	pvc.Annotations = map[string]string{MountPathCacheAnnotation: string(cache)}
+	client := fake.NewSimpleClientset(pvc, pv)
+	mountPath := "/var/lib/kubelet/pods/new/volumes/kubernetes.io~csi/pv-orders/mount"
+	serveAgentAPI(client, map[string]interface{}{
+		volumeapi.StatEndpoint:      volumeapi.StatResponse{Exists: false},
+		volumeapi.MountPathEndpoint: volumeapi.MountPathResponse{Path: mountPath},
+	})
+	p := &PVCSyncer{SourceK8sClient: client, SourceConfig: &rest.Config{}}
+
+	// The cached path of the previous pod is gone, the mount path is discovered again
+	path, err := p.FindPVCMountPath(ctx, "shop", "orders", agentPod)
+	require.NoError(t, err)
+	assert.Equal(t, mountPath, path)
+}
+
+func TestAgentChangedSince(t *testing.T) {
+	_, _, agentPod := mountPathTestObjects()
+	client := fake.NewSimpleClientset()
+	serveAgentAPI(client, map[string]interface{}{
+		volumeapi.LatestChangeEndpoint: volumeapi.LatestChangeResponse{Path: "/data/uploads/a.txt"},
+	})
+	p := &PVCSyncer{SourceK8sClient: client, SourceConfig: &rest.Config{}}
+
+	since := time.Unix(1700000000, 0)
+	changed, err := p.changedSince(context.Background(), agentPod, "/data", since)
+	require.NoError(t, err)
+	assert.Equal(t, "/data/uploads/a.txt", changed)
+
+	// The clock skew allowance applies as it does for find
+	params := client.Actions()[0].(k8stesting.ProxyGetAction).GetParams()
+	assert.Equal(t, "1699999940", params["since"])
+}
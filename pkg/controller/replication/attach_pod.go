@@ -0,0 +1,267 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// The agent reads a PVC's data from the kubelet mount of the pod using it. A source PVC that no pod
+// mounts is attached to a temporary pod for the sync when pvcConfig.syncUnmounted is enabled, then the
+// agent syncs it like any mounted PVC.
+const (
+	// attachPodAppName names the temporary attach pods and labels them
+	attachPodAppName = "dr-syncer-attach"
+
+	// attachPodDataPath is where the attach pod mounts the source PVC
+	attachPodDataPath = "/data"
+
+	// attachPodTimeout bounds the wait for the attach pod to be scheduled, bind the PVC and run
+	attachPodTimeout = 5 * time.Minute
+
+	// attachPodCommand keeps the attach pod running until it is deleted
+	attachPodCommand = "trap 'exit 0' TERM; while true; do sleep 1; done"
+)
+
+// attachPodSelector selects the attach pods of a source PVC
+func attachPodSelector(pvcName string) string {
+	return fmt.Sprintf("app.kubernetes.io/name=%s,dr-syncer.io/pvc-name=%s", attachPodAppName, sourceSSHDPVCLabel(pvcName))
+}
+
+// attachPodOptions describes the attach pod of a sync
+type attachPodOptions struct {
+	// Namespace is the namespace of the source PVC
+	Namespace string
+
+	// PVCName is the name of the source PVC
+	PVCName string
+
+	// SyncID makes the name of the pod unique
+	SyncID string
+
+	// AgentNodes are the nodes running an agent, the pod is only scheduled on one of them
+	AgentNodes []string
+
+	// NodeSelector and Tolerations are those of the agent
+	NodeSelector map[string]string
+	Tolerations  []corev1.Toleration
+
+	// Image and PullPolicy are those of the agent
+	Image      string
+	PullPolicy corev1.PullPolicy
+}
+
+// attachPodObject builds the attach pod. It is placed by the scheduler rather than pinned to a node,
+// so volumes with WaitForFirstConsumer binding are provisioned and bound for it, and its node
+// affinity keeps it on the nodes running an agent.
+func attachPodObject(opts attachPodOptions) *corev1.Pod {
+	automountToken := false
+	gracePeriod := int64(5)
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", attachPodAppName, opts.SyncID),
+			Namespace: opts.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":       attachPodAppName,
+				"app.kubernetes.io/instance":   opts.SyncID,
+				"app.kubernetes.io/managed-by": "dr-syncer",
+				"dr-syncer.io/sync-id":         opts.SyncID,
+				"dr-syncer.io/pvc-name":        sourceSSHDPVCLabel(opts.PVCName),
+			},
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy:                 corev1.RestartPolicyNever,
+			AutomountServiceAccountToken:  &automountToken,
+			TerminationGracePeriodSeconds: &gracePeriod,
+			NodeSelector:                  opts.NodeSelector,
+			Tolerations:                   opts.Tolerations,
+			Affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{
+							{
+								MatchFields: []corev1.NodeSelectorRequirement{
+									{
+										Key:      metav1.ObjectNameField,
+										Operator: corev1.NodeSelectorOpIn,
+										Values:   opts.AgentNodes,
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:            "attach",
+					Image:           opts.Image,
+					ImagePullPolicy: opts.PullPolicy,
+					Command:         []string{"/bin/sh", "-c", attachPodCommand},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "data", MountPath: attachPodDataPath, ReadOnly: true},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: opts.PVCName,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// agentPlacement returns the nodes running a ready agent and the tolerations of the agent pods
+func (p *PVCSyncer) agentPlacement(ctx context.Context) ([]string, []corev1.Toleration, error) {
+	pods, err := listAgentPods(ctx, p.SourceK8sClient, "app=dr-syncer-agent")
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list agent pods: %v", err)
+	}
+
+	var nodes []string
+	var tolerations []corev1.Toleration
+	for _, pod := range pods.Items {
+		if pod.Status.Phase != corev1.PodRunning || pod.Spec.NodeName == "" {
+			continue
+		}
+		nodes = append(nodes, pod.Spec.NodeName)
+		tolerations = pod.Spec.Tolerations
+	}
+	if len(nodes) == 0 {
+		return nil, nil, fmt.Errorf("no running DR-Syncer-Agent found in the source cluster")
+	}
+	sort.Strings(nodes)
+	return nodes, tolerations, nil
+}
+
+// attachSourcePVC mounts an unmounted source PVC in a temporary attach pod and returns the running pod.
+// The pod is deleted again when it does not start.
+func (p *PVCSyncer) attachSourcePVC(ctx context.Context, namespace, pvcName string) (*corev1.Pod, error) {
+	nodes, tolerations, err := p.agentPlacement(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	rc := p.sourceRemoteCluster(ctx)
+	image, pullPolicy := sourceSSHDImage(rc)
+	var nodeSelector map[string]string
+	if rc != nil && rc.Spec.PVCSync != nil && rc.Spec.PVCSync.Deployment != nil {
+		nodeSelector = rc.Spec.PVCSync.Deployment.NodeSelector
+	}
+
+	pod := attachPodObject(attachPodOptions{
+		Namespace:    namespace,
+		PVCName:      pvcName,
+		SyncID:       rand.String(8),
+		AgentNodes:   nodes,
+		NodeSelector: nodeSelector,
+		Tolerations:  tolerations,
+		Image:        image,
+		PullPolicy:   pullPolicy,
+	})
+	if _, err := p.SourceK8sClient.CoreV1().Pods(namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create attach pod %s/%s: %v", namespace, pod.Name, err)
+	}
+
+	fields := logrus.Fields{
+		"namespace": namespace,
+		"pvc":       pvcName,
+		"pod_name":  pod.Name,
+	}
+	log.WithFields(fields).WithField("agent_nodes", nodes).
+		Info(logging.LogTagDetail + " Waiting for temporary attach pod to mount source PVC")
+
+	var running *corev1.Pod
+	var pending string
+	err = wait.PollUntilContextTimeout(ctx, 2*time.Second, attachPodTimeout, true, func(ctx context.Context) (bool, error) {
+		current, err := p.SourceK8sClient.CoreV1().Pods(namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		switch current.Status.Phase {
+		case corev1.PodRunning:
+			running = current
+			return true, nil
+		case corev1.PodFailed, corev1.PodSucceeded:
+			return false, fmt.Errorf("attach pod terminated in phase %s", current.Status.Phase)
+		}
+		for _, condition := range current.Status.Conditions {
+			if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+				pending = condition.Message
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		p.deleteAttachPod(ctx, namespace, pvcName, pod.Name)
+		if pending != "" {
+			return nil, fmt.Errorf("attach pod %s/%s not running: %v: %s", namespace, pod.Name, err, pending)
+		}
+		return nil, fmt.Errorf("attach pod %s/%s not running: %v", namespace, pod.Name, err)
+	}
+
+	log.WithFields(fields).WithField("node", running.Spec.NodeName).Info(logging.LogTagDetail + " Source PVC mounted by temporary attach pod")
+	return running, nil
+}
+
+// deleteAttachPod deletes an attach pod and forgets the mount path cached for the source PVC, the
+// kubelet mount of the attach pod goes away with it
+func (p *PVCSyncer) deleteAttachPod(ctx context.Context, namespace, pvcName, podName string) {
+	fields := logrus.Fields{
+		"namespace": namespace,
+		"pvc":       pvcName,
+		"pod_name":  podName,
+	}
+
+	if err := p.SourceK8sClient.CoreV1().Pods(namespace).Delete(ctx, podName, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to delete temporary attach pod")
+	}
+
+	pvc, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil || pvc.Annotations[MountPathCacheAnnotation] == "" {
+		return
+	}
+	delete(pvc.Annotations, MountPathCacheAnnotation)
+	if _, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to clear mount path cache of source PVC")
+	}
+}
+
+// cleanupAttachPods deletes the attach pods of a source PVC, for syncs that could not delete their own
+func (p *PVCSyncer) cleanupAttachPods(ctx context.Context, namespace, pvcName string) {
+	pods, err := p.SourceK8sClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: attachPodSelector(pvcName)})
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"pvc":       pvcName,
+			"error":     err,
+		}).Warn(logging.LogTagWarn + " Failed to list temporary attach pods")
+		return
+	}
+	for _, pod := range pods.Items {
+		p.deleteAttachPod(ctx, namespace, pvcName, pod.Name)
+	}
+}
+
+// usesAttachPod returns true if the data of a source PVC that no pod mounts is synced through an attach
+// pod, the LbSvc and PortForward strategies mount it in their sshd pod and agentless syncs in their tar
+// helper pod instead
+func (p *PVCSyncer) usesAttachPod() bool {
+	return p.SyncUnmounted && !p.Agentless && (p.ObjectStorage != nil || !usesSourceSSHD(p.Strategy))
+}
@@ -0,0 +1,108 @@
+package replication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func agentTestPod(name, node string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dr-syncer", Labels: map[string]string{"app": "dr-syncer-agent"}},
+		Spec: corev1.PodSpec{
+			NodeName:    node,
+			Tolerations: []corev1.Toleration{{Key: "storage", Operator: corev1.TolerationOpExists}},
+		},
+		Status: corev1.PodStatus{Phase: phase},
+	}
+}
+
+func TestAttachPodObject(t *testing.T) {
+	pod := attachPodObject(attachPodOptions{
+		Namespace:    "shop",
+		PVCName:      "orders.db",
+		SyncID:       "abc123",
+		AgentNodes:   []string{"node-1", "node-2"},
+		NodeSelector: map[string]string{"storage": "true"},
+		Image:        "supporttools/dr-syncer-agent:v1",
+		PullPolicy:   corev1.PullIfNotPresent,
+	})
+
+	assert.Equal(t, "dr-syncer-attach-abc123", pod.Name)
+	assert.Equal(t, "orders-db", pod.Labels["dr-syncer.io/pvc-name"])
+
+	// The scheduler places the pod so WaitForFirstConsumer volumes bind, limited to the agent nodes
+	assert.Empty(t, pod.Spec.NodeName)
+	assert.Equal(t, map[string]string{"storage": "true"}, pod.Spec.NodeSelector)
+	term := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0]
+	assert.Equal(t, "metadata.name", term.MatchFields[0].Key)
+	assert.Equal(t, []string{"node-1", "node-2"}, term.MatchFields[0].Values)
+
+	assert.Equal(t, "orders.db", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.True(t, pod.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly)
+	assert.True(t, pod.Spec.Containers[0].VolumeMounts[0].ReadOnly)
+}
+
+func TestAgentPlacement(t *testing.T) {
+	p := &PVCSyncer{SourceK8sClient: fake.NewSimpleClientset(
+		agentTestPod("agent-b", "node-2", corev1.PodRunning),
+		agentTestPod("agent-a", "node-1", corev1.PodRunning),
+		agentTestPod("agent-c", "node-3", corev1.PodPending),
+	)}
+
+	nodes, tolerations, err := p.agentPlacement(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"node-1", "node-2"}, nodes)
+	assert.Equal(t, "storage", tolerations[0].Key)
+
+	p.SourceK8sClient = fake.NewSimpleClientset()
+	_, _, err = p.agentPlacement(context.Background())
+	assert.Error(t, err)
+}
+
+func TestAttachSourcePVC(t *testing.T) {
+	ctx := context.Background()
+	pvc := newTestPVC("shop", "orders", nil)
+	pvc.Annotations = map[string]string{MountPathCacheAnnotation: `{"path":"/var/lib/kubelet/pods/old"}`}
+	client := fake.NewSimpleClientset(pvc, agentTestPod("agent-a", "node-1", corev1.PodRunning))
+
+	// The scheduler places the attach pod on the agent node
+	client.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		pod := action.(k8stesting.CreateAction).GetObject().(*corev1.Pod)
+		pod.Spec.NodeName = "node-1"
+		pod.Status.Phase = corev1.PodRunning
+		return false, nil, nil
+	})
+	p := &PVCSyncer{SourceK8sClient: client, SyncUnmounted: true}
+	require.True(t, p.usesAttachPod())
+
+	attachPod, err := p.attachSourcePVC(ctx, "shop", "orders")
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", attachPod.Spec.NodeName)
+
+	// Deleting the attach pod forgets the mount path cached for it
+	p.cleanupAttachPods(ctx, "shop", "orders")
+	pods, err := client.CoreV1().Pods("shop").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, pods.Items)
+	pvc, err = client.CoreV1().PersistentVolumeClaims("shop").Get(ctx, "orders", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, pvc.Annotations, MountPathCacheAnnotation)
+}
+
+func TestUsesAttachPod(t *testing.T) {
+	assert.False(t, (&PVCSyncer{}).usesAttachPod())
+	assert.True(t, (&PVCSyncer{SyncUnmounted: true, Strategy: drv1alpha1.PVCDataSyncStrategyAgent}).usesAttachPod())
+	assert.True(t, (&PVCSyncer{SyncUnmounted: true, ObjectStorage: &ObjectStorageRepository{}}).usesAttachPod())
+
+	// The sshd pod of these strategies mounts the PVC itself
+	assert.False(t, (&PVCSyncer{SyncUnmounted: true, Strategy: drv1alpha1.PVCDataSyncStrategyPortForward}).usesAttachPod())
+}
@@ -0,0 +1,55 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+)
+
+// SkipReasonBlockVolume is recorded when a PVC's data is not synced because it is a raw block volume
+const SkipReasonBlockVolume = "BlockVolumeModeUnsupported"
+
+// IsBlockVolume reports whether a PVC uses volumeMode: Block
+func IsBlockVolume(pvc *corev1.PersistentVolumeClaim) bool {
+	return pvc != nil && pvc.Spec.VolumeMode != nil && *pvc.Spec.VolumeMode == corev1.PersistentVolumeBlock
+}
+
+// skipBlockVolume checks the source and destination PVCs for volumeMode: Block. Rsync can only
+// transfer filesystem volumes, so block PVCs are marked as Skipped with a reason instead of
+// failing the rsync workflow.
+func (p *PVCSyncer) skipBlockVolume(ctx context.Context, sourceNamespace, sourcePVCName, destNamespace, destPVCName string) (bool, error) {
+	sourcePVC, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(sourceNamespace).Get(ctx, sourcePVCName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get source PVC: %v", err)
+	}
+
+	destPVC, err := p.DestinationK8sClient.CoreV1().PersistentVolumeClaims(destNamespace).Get(ctx, destPVCName, metav1.GetOptions{})
+	if err != nil {
+		return false, fmt.Errorf("failed to get destination PVC: %v", err)
+	}
+
+	if !IsBlockVolume(sourcePVC) && !IsBlockVolume(destPVC) {
+		return false, nil
+	}
+
+	message := fmt.Sprintf("PVC uses volumeMode Block which rsync cannot transfer, skipping data sync to %s/%s",
+		destNamespace, destPVCName)
+
+	log.WithFields(logrus.Fields{
+		"source_namespace": sourceNamespace,
+		"source_pvc":       sourcePVCName,
+		"dest_namespace":   destNamespace,
+		"dest_pvc":         destPVCName,
+	}).Warn(logging.LogTagWarn + " " + message)
+
+	p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped, "%s", message)
+
+	p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonBlockVolume, message)
+
+	return true, nil
+}
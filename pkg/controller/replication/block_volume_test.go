@@ -0,0 +1,76 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newTestPVC(namespace, name string, mode *corev1.PersistentVolumeMode) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.PersistentVolumeClaimSpec{VolumeMode: mode},
+	}
+}
+
+func TestIsBlockVolume(t *testing.T) {
+	block := corev1.PersistentVolumeBlock
+	filesystem := corev1.PersistentVolumeFilesystem
+
+	assert.False(t, IsBlockVolume(nil))
+	assert.False(t, IsBlockVolume(newTestPVC("ns", "data", nil)))
+	assert.False(t, IsBlockVolume(newTestPVC("ns", "data", &filesystem)))
+	assert.True(t, IsBlockVolume(newTestPVC("ns", "data", &block)))
+}
+
+func TestSkipBlockVolume_Filesystem(t *testing.T) {
+	p := &PVCSyncer{
+		SourceK8sClient:      fake.NewSimpleClientset(newTestPVC("app", "data", nil)),
+		DestinationK8sClient: fake.NewSimpleClientset(newTestPVC("app-dr", "data", nil)),
+	}
+
+	skip, err := p.skipBlockVolume(context.Background(), "app", "data", "app-dr", "data")
+
+	assert.NoError(t, err)
+	assert.False(t, skip)
+}
+
+func TestSkipBlockVolume_Block(t *testing.T) {
+	block := corev1.PersistentVolumeBlock
+	sourceClient := fake.NewSimpleClientset(newTestPVC("app", "disk", &block))
+	p := &PVCSyncer{
+		SourceK8sClient:      sourceClient,
+		DestinationK8sClient: fake.NewSimpleClientset(newTestPVC("app-dr", "disk", &block)),
+	}
+
+	skip, err := p.skipBlockVolume(context.Background(), "app", "disk", "app-dr", "disk")
+
+	assert.NoError(t, err)
+	assert.True(t, skip)
+
+	// The skip reason is recorded on the source PVC
+	pvc, err := sourceClient.CoreV1().PersistentVolumeClaims("app").Get(context.Background(), "disk", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "Skipped", pvc.Annotations["dr-syncer.io/phase"])
+
+	var status SyncStatus
+	assert.NoError(t, json.Unmarshal([]byte(pvc.Annotations["dr-syncer.io/sync-status"]), &status))
+	assert.Equal(t, SkipReasonBlockVolume, status.Reason)
+	assert.NotEmpty(t, status.Message)
+}
+
+func TestSkipBlockVolume_MissingDestination(t *testing.T) {
+	p := &PVCSyncer{
+		SourceK8sClient:      fake.NewSimpleClientset(newTestPVC("app", "data", nil)),
+		DestinationK8sClient: fake.NewSimpleClientset(),
+	}
+
+	_, err := p.skipBlockVolume(context.Background(), "app", "data", "app-dr", "data")
+
+	assert.Error(t, err)
+}
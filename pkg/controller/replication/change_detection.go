@@ -0,0 +1,177 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+)
+
+const (
+	// SkipReasonUnchanged is recorded when a PVC's data sync is skipped because nothing changed
+	SkipReasonUnchanged = "NoChangesSinceLastSync"
+
+	// AnnotationLastDataSyncStart records on the source PVC when the last successful rsync started.
+	// Anything changed after this point may not have been transferred.
+	AnnotationLastDataSyncStart = "dr-syncer.io/last-data-sync-start"
+
+	// DefaultFullSyncInterval is how long data syncs may be skipped before a sync is forced
+	DefaultFullSyncInterval = 24 * time.Hour
+
+	// changeDetectionClockSkew is subtracted from the baseline to tolerate clock drift
+	// between the controller and the source node
+	changeDetectionClockSkew = time.Minute
+
+	// changeDetectionTimeout bounds the change-detection scan on the agent
+	changeDetectionTimeout = 2 * time.Minute
+)
+
+// changeDetectionSettings returns whether unchanged PVCs should be skipped and how long they may be
+// skipped before a full sync is forced
+func changeDetectionSettings(nm *drv1alpha1.NamespaceMapping) (bool, time.Duration) {
+	if nm == nil || nm.Spec.PVCConfig == nil || nm.Spec.PVCConfig.DataSyncConfig == nil {
+		return false, DefaultFullSyncInterval
+	}
+
+	dsc := nm.Spec.PVCConfig.DataSyncConfig
+	interval := DefaultFullSyncInterval
+	if dsc.FullSyncInterval != nil && dsc.FullSyncInterval.Duration > 0 {
+		interval = dsc.FullSyncInterval.Duration
+	}
+	return dsc.SkipUnchanged, interval
+}
+
+// lastDataSyncStart returns the start time of the last successful data sync recorded on a PVC
+func lastDataSyncStart(pvc *corev1.PersistentVolumeClaim) (time.Time, bool) {
+	value, ok := pvc.Annotations[AnnotationLastDataSyncStart]
+	if !ok {
+		return time.Time{}, false
+	}
+	start, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return start, true
+}
+
+// changeDetectionCommand builds a find command that prints the first entry under the mount path
+// whose inode changed after since. The inode change time covers content writes, renames,
+// permission changes and deletions (via the parent directory), and cannot be set by applications.
+func changeDetectionCommand(mountPath string, since time.Time) []string {
+	return []string{
+		"find", mountPath, "-xdev",
+		"-newerct", fmt.Sprintf("@%d", since.Add(-changeDetectionClockSkew).Unix()),
+		"-print", "-quit",
+	}
+}
+
+// changedSince returns an entry under the mount path whose inode changed after since, empty when
+// nothing changed. The agent's volume API is asked first, agents that do not serve it run find.
+func (p *PVCSyncer) changedSince(ctx context.Context, agentPod *corev1.Pod, mountPath string, since time.Time) (string, error) {
+	changed, err := p.agentChangedSince(ctx, agentPod, mountPath, since.Add(-changeDetectionClockSkew))
+	if err == nil {
+		return changed, nil
+	}
+	log.WithFields(logrus.Fields{
+		"agent_pod":  agentPod.Name,
+		"mount_path": mountPath,
+		"error":      err,
+	}).Debug(logging.LogTagDetail + " Agent volume API unavailable, running change detection with find")
+
+	stdout, stderr, err := p.execCommandOnPod(ctx, agentPod.Namespace, agentPod.Name, changeDetectionCommand(mountPath, since))
+	if err != nil {
+		return "", fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr))
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
+// recordDataSyncStart stores the start time of a successful data sync on the source PVC so later
+// syncs can detect whether anything changed since
+func (p *PVCSyncer) recordDataSyncStart(ctx context.Context, namespace, pvcName string, start time.Time) error {
+	pvc, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get PVC: %v", err)
+	}
+
+	if pvc.Annotations == nil {
+		pvc.Annotations = make(map[string]string)
+	}
+	pvc.Annotations[AnnotationLastDataSyncStart] = start.UTC().Format(time.RFC3339)
+
+	if _, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("failed to update PVC annotations: %v", err)
+	}
+	return nil
+}
+
+// skipUnchanged runs the change-detection pre-check on the agent when SkipUnchanged is enabled.
+// If nothing under the mount path changed since the last successful sync, the PVC is marked as
+// Skipped and true is returned. Any error during the check falls back to a regular sync.
+func (p *PVCSyncer) skipUnchanged(ctx context.Context, sourceNamespace, sourcePVCName string, agentPod *corev1.Pod, mountPath string) bool {
+	var nm drv1alpha1.NamespaceMapping
+	var nmPtr *drv1alpha1.NamespaceMapping
+	nmKey := client.ObjectKey{Name: fmt.Sprintf("%s-%s", p.SourceNamespace, p.DestinationNamespace)}
+	if err := p.SourceClient.Get(ctx, nmKey, &nm); err == nil {
+		nmPtr = &nm
+	}
+
+	enabled, fullSyncInterval := changeDetectionSettings(nmPtr)
+	if !enabled {
+		return false
+	}
+
+	fields := logrus.Fields{
+		"source_namespace": sourceNamespace,
+		"source_pvc":       sourcePVCName,
+		"mount_path":       mountPath,
+	}
+
+	pvc, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(sourceNamespace).Get(ctx, sourcePVCName, metav1.GetOptions{})
+	if err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to get source PVC for change detection, syncing anyway")
+		return false
+	}
+
+	since, ok := lastDataSyncStart(pvc)
+	if !ok {
+		log.WithFields(fields).Info(logging.LogTagInfo + " No previous successful data sync recorded, skipping change detection")
+		return false
+	}
+	if time.Since(since) > fullSyncInterval {
+		log.WithFields(fields).WithField("full_sync_interval", fullSyncInterval.String()).
+			Info(logging.LogTagInfo + " Last data sync is older than the full sync interval, forcing sync")
+		return false
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, changeDetectionTimeout)
+	defer cancel()
+
+	changed, err := p.changedSince(checkCtx, agentPod, mountPath, since)
+	if err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Change detection failed, syncing anyway")
+		return false
+	}
+	if changed != "" {
+		log.WithFields(fields).WithField("changed_path", changed).Info(logging.LogTagInfo + " Changes detected since last data sync")
+		return false
+	}
+
+	message := fmt.Sprintf("No changes on the source volume since the last successful sync at %s, skipping data sync",
+		since.UTC().Format(time.RFC3339))
+
+	log.WithFields(fields).Info(logging.LogTagSkip + " " + message)
+
+	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped, "%s", message)
+
+	p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonUnchanged, message)
+
+	return true
+}
@@ -0,0 +1,76 @@
+package replication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestChangeDetectionSettings(t *testing.T) {
+	enabled, interval := changeDetectionSettings(nil)
+	assert.False(t, enabled)
+	assert.Equal(t, DefaultFullSyncInterval, interval)
+
+	nm := &drv1alpha1.NamespaceMapping{
+		Spec: drv1alpha1.NamespaceMappingSpec{
+			PVCConfig: &drv1alpha1.PVCConfig{
+				DataSyncConfig: &drv1alpha1.PVCDataSyncConfig{SkipUnchanged: true},
+			},
+		},
+	}
+	enabled, interval = changeDetectionSettings(nm)
+	assert.True(t, enabled)
+	assert.Equal(t, DefaultFullSyncInterval, interval)
+
+	nm.Spec.PVCConfig.DataSyncConfig.FullSyncInterval = &metav1.Duration{Duration: 6 * time.Hour}
+	_, interval = changeDetectionSettings(nm)
+	assert.Equal(t, 6*time.Hour, interval)
+}
+
+func TestLastDataSyncStart(t *testing.T) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	_, ok := lastDataSyncStart(pvc)
+	assert.False(t, ok)
+
+	pvc.Annotations = map[string]string{AnnotationLastDataSyncStart: "not-a-time"}
+	_, ok = lastDataSyncStart(pvc)
+	assert.False(t, ok)
+
+	pvc.Annotations[AnnotationLastDataSyncStart] = "2024-05-01T10:00:00Z"
+	start, ok := lastDataSyncStart(pvc)
+	assert.True(t, ok)
+	assert.Equal(t, time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC), start)
+}
+
+func TestChangeDetectionCommand(t *testing.T) {
+	since := time.Unix(1714557600, 0)
+	cmd := changeDetectionCommand("/var/lib/kubelet/pods/abc/volumes/data", since)
+
+	assert.Equal(t, []string{
+		"find", "/var/lib/kubelet/pods/abc/volumes/data", "-xdev",
+		"-newerct", "@1714557540",
+		"-print", "-quit",
+	}, cmd)
+}
+
+func TestRecordDataSyncStart(t *testing.T) {
+	sourceClient := fake.NewSimpleClientset(newTestPVC("app", "data", nil))
+	p := &PVCSyncer{SourceK8sClient: sourceClient}
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	require.NoError(t, p.recordDataSyncStart(context.Background(), "app", "data", start))
+
+	pvc, err := sourceClient.CoreV1().PersistentVolumeClaims("app").Get(context.Background(), "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	recorded, ok := lastDataSyncStart(pvc)
+	assert.True(t, ok)
+	assert.Equal(t, start, recorded)
+}
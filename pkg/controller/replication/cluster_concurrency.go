@@ -0,0 +1,134 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/syncstate"
+	"golang.org/x/sync/semaphore"
+)
+
+// ClusterLimit is the maxConcurrentDataSyncs of a RemoteCluster taking part in a PVC data sync
+type ClusterLimit struct {
+	// Namespace and Name identify the RemoteCluster
+	Namespace string
+	Name      string
+
+	// Limit is the number of data syncs the cluster takes part in at the same time
+	Limit int64
+}
+
+// key identifies the RemoteCluster of the limit
+func (l ClusterLimit) key() string {
+	return l.Namespace + "/" + l.Name
+}
+
+// clusterSemaphore holds the data sync slots of a RemoteCluster
+type clusterSemaphore struct {
+	semaphore *semaphore.Weighted
+	limit     int64
+}
+
+var (
+	clusterSemaphores   = map[string]*clusterSemaphore{}
+	clusterSemaphoresMu sync.Mutex
+)
+
+// getClusterSemaphore returns the semaphore of a RemoteCluster, replaced by a new one when its limit
+// changed. Syncs holding a slot of a replaced semaphore release it there.
+func getClusterSemaphore(l ClusterLimit) *clusterSemaphore {
+	clusterSemaphoresMu.Lock()
+	defer clusterSemaphoresMu.Unlock()
+
+	if s, ok := clusterSemaphores[l.key()]; ok && s.limit == l.Limit {
+		return s
+	}
+	s := &clusterSemaphore{semaphore: semaphore.NewWeighted(l.Limit), limit: l.Limit}
+	clusterSemaphores[l.key()] = s
+	return s
+}
+
+// sortedClusterLimits returns the limited clusters once each, ordered by namespace and name
+func sortedClusterLimits(limits []ClusterLimit) []ClusterLimit {
+	seen := make(map[string]bool, len(limits))
+	var sorted []ClusterLimit
+	for _, l := range limits {
+		if l.Limit <= 0 || seen[l.key()] {
+			continue
+		}
+		seen[l.key()] = true
+		sorted = append(sorted, l)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].key() < sorted[j].key()
+	})
+	return sorted
+}
+
+// AcquireDataSyncSlots waits for a data sync slot of every RemoteCluster in limits, then for a slot of
+// the global concurrency manager. Cluster slots are taken in the order of their names so syncs sharing
+// clusters cannot deadlock, and before the global slot so syncs waiting on a busy cluster do not hold
+// up the syncs of other clusters. The returned function releases all slots.
+func AcquireDataSyncSlots(ctx context.Context, limits []ClusterLimit, namespace, pvcName string) (func(), error) {
+	var releases []func()
+	release := func() {
+		for i := len(releases) - 1; i >= 0; i-- {
+			releases[i]()
+		}
+	}
+
+	limits = sortedClusterLimits(limits)
+	for _, l := range limits {
+		s := getClusterSemaphore(l)
+		if !s.semaphore.TryAcquire(1) {
+			log.WithFields(logrus.Fields{
+				"namespace":      namespace,
+				"pvc":            pvcName,
+				"remote_cluster": l.key(),
+				"limit":          l.Limit,
+			}).Info(logging.LogTagDetail + " Waiting for a data sync slot of the remote cluster")
+
+			syncstate.SyncWaiting(namespace, pvcName)
+			ClusterDataSyncsWaiting.WithLabelValues(l.Namespace, l.Name).Inc()
+			err := s.semaphore.Acquire(ctx, 1)
+			ClusterDataSyncsWaiting.WithLabelValues(l.Namespace, l.Name).Dec()
+			if err != nil {
+				release()
+				syncstate.SyncFinished(namespace, pvcName)
+				return nil, fmt.Errorf("failed to acquire a data sync slot of RemoteCluster %s: %w", l.key(), err)
+			}
+		}
+
+		ClusterDataSyncsActive.WithLabelValues(l.Namespace, l.Name).Inc()
+		l := l
+		releases = append(releases, func() {
+			s.semaphore.Release(1)
+			ClusterDataSyncsActive.WithLabelValues(l.Namespace, l.Name).Dec()
+		})
+	}
+
+	gcm := GetGlobalConcurrencyManager()
+	if gcm == nil {
+		// Without a global manager the cluster slots alone mark the sync as started
+		if len(limits) > 0 {
+			syncstate.SyncStarted(namespace, pvcName)
+			releases = append(releases, func() {
+				syncstate.SyncFinished(namespace, pvcName)
+			})
+		}
+		return release, nil
+	}
+
+	if err := gcm.Acquire(ctx, namespace, pvcName); err != nil {
+		release()
+		return nil, err
+	}
+	releases = append(releases, func() {
+		gcm.Release(namespace, pvcName)
+	})
+	return release, nil
+}
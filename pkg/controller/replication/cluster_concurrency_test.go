@@ -0,0 +1,60 @@
+package replication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSortedClusterLimits(t *testing.T) {
+	limits := sortedClusterLimits([]ClusterLimit{
+		{Namespace: "dr-syncer", Name: "prod", Limit: 2},
+		{Namespace: "dr-syncer", Name: "dr", Limit: 1},
+		{Namespace: "dr-syncer", Name: "prod", Limit: 2},
+		{Namespace: "dr-syncer", Name: "lab", Limit: 0},
+	})
+	assert.Equal(t, []ClusterLimit{
+		{Namespace: "dr-syncer", Name: "dr", Limit: 1},
+		{Namespace: "dr-syncer", Name: "prod", Limit: 2},
+	}, limits)
+}
+
+func TestAcquireDataSyncSlots(t *testing.T) {
+	ctx := context.Background()
+	dest := ClusterLimit{Namespace: "limits-test", Name: "dr", Limit: 1}
+
+	// Two mappings syncing to the same destination cluster share its single slot
+	release, err := AcquireDataSyncSlots(ctx, []ClusterLimit{{Namespace: "limits-test", Name: "prod-a", Limit: 4}, dest}, "shop", "orders")
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, testutil.ToFloat64(ClusterDataSyncsActive.WithLabelValues("limits-test", "dr")))
+
+	waitCtx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+	_, err = AcquireDataSyncSlots(waitCtx, []ClusterLimit{{Namespace: "limits-test", Name: "prod-b", Limit: 4}, dest}, "billing", "ledger")
+	require.Error(t, err)
+	assert.Equal(t, 0.0, testutil.ToFloat64(ClusterDataSyncsActive.WithLabelValues("limits-test", "prod-b")), "slots taken before the wait are released")
+	assert.Equal(t, 0.0, testutil.ToFloat64(ClusterDataSyncsWaiting.WithLabelValues("limits-test", "dr")))
+
+	release()
+	assert.Equal(t, 0.0, testutil.ToFloat64(ClusterDataSyncsActive.WithLabelValues("limits-test", "dr")))
+
+	release, err = AcquireDataSyncSlots(ctx, []ClusterLimit{{Namespace: "limits-test", Name: "prod-b", Limit: 4}, dest}, "billing", "ledger")
+	require.NoError(t, err)
+	release()
+}
+
+func TestAcquireDataSyncSlots_LimitChange(t *testing.T) {
+	ctx := context.Background()
+	release, err := AcquireDataSyncSlots(ctx, []ClusterLimit{{Namespace: "limits-test", Name: "resized", Limit: 1}}, "shop", "orders")
+	require.NoError(t, err)
+
+	// A raised limit takes effect for new syncs, the running sync releases its slot on the old semaphore
+	second, err := AcquireDataSyncSlots(ctx, []ClusterLimit{{Namespace: "limits-test", Name: "resized", Limit: 2}}, "shop", "invoices")
+	require.NoError(t, err)
+	release()
+	second()
+}
@@ -0,0 +1,355 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// A live volume copied file by file is crash-inconsistent: files written during the rsync are copied
+// at different points in time. The consistency annotation of a source PVC makes its data sync read a
+// consistent state instead, by freezing the filesystem through the agent, by running quiesce hooks in
+// the pods using the PVC, or by copying a CSI snapshot of the PVC.
+const (
+	// AnnotationConsistency selects how the data of a source PVC is made consistent for its sync
+	AnnotationConsistency = "dr-syncer.io/consistency"
+
+	// ConsistencyFSFreeze freezes the source filesystem through the agent while rsync runs
+	ConsistencyFSFreeze = "fsfreeze"
+
+	// ConsistencyHook runs the quiesce command in the pods mounting the source PVC before rsync and the
+	// unquiesce command after it
+	ConsistencyHook = "hook"
+
+	// ConsistencySnapshot copies a CSI VolumeSnapshot of the source PVC, restored into a temporary PVC
+	ConsistencySnapshot = "snapshot"
+
+	// AnnotationQuiesceCommand and AnnotationUnquiesceCommand are the shell commands of the hooks. With
+	// ConsistencySnapshot they run around taking the snapshot when set.
+	AnnotationQuiesceCommand   = "dr-syncer.io/quiesce-command"
+	AnnotationUnquiesceCommand = "dr-syncer.io/unquiesce-command"
+
+	// AnnotationQuiesceContainer is the container the hooks run in, the first container when unset
+	AnnotationQuiesceContainer = "dr-syncer.io/quiesce-container"
+
+	// AnnotationVolumeSnapshotClass is the VolumeSnapshotClass of the snapshot, the default class when unset
+	AnnotationVolumeSnapshotClass = "dr-syncer.io/volume-snapshot-class"
+
+	// defaultFreezeLimit thaws a frozen filesystem when the sync has no deadline and the controller
+	// could not thaw it
+	defaultFreezeLimit = DefaultSyncTimeout
+
+	// hookTimeout bounds a quiesce or unquiesce command
+	hookTimeout = 2 * time.Minute
+
+	// snapshotReadyTimeout bounds the wait for a snapshot to be ready to use
+	snapshotReadyTimeout = 10 * time.Minute
+
+	// snapshotPVCSuffix names the temporary PVCs restored from snapshots
+	snapshotPVCSuffix = "-dr-snap"
+)
+
+// volumeSnapshotGVK is the kind of CSI volume snapshots
+var volumeSnapshotGVK = schema.GroupVersionKind{Group: "snapshot.storage.k8s.io", Version: "v1", Kind: "VolumeSnapshot"}
+
+// consistencyMode returns the consistency mode of a source PVC, empty when its data is copied live
+func consistencyMode(pvc *corev1.PersistentVolumeClaim) (string, error) {
+	mode := strings.ToLower(strings.TrimSpace(pvc.Annotations[AnnotationConsistency]))
+	switch mode {
+	case "", ConsistencyFSFreeze, ConsistencySnapshot:
+		return mode, nil
+	case ConsistencyHook:
+		if pvc.Annotations[AnnotationQuiesceCommand] == "" {
+			return "", fmt.Errorf("consistency %q of PVC %s/%s needs the %s annotation", mode, pvc.Namespace, pvc.Name, AnnotationQuiesceCommand)
+		}
+		return mode, nil
+	}
+	return "", fmt.Errorf("unknown consistency %q of PVC %s/%s, expected %s, %s or %s", mode, pvc.Namespace, pvc.Name,
+		ConsistencyFSFreeze, ConsistencyHook, ConsistencySnapshot)
+}
+
+// sourceConsistency reads the consistency mode of a source PVC
+func (p *PVCSyncer) sourceConsistency(ctx context.Context, namespace, pvcName string) (*corev1.PersistentVolumeClaim, string, error) {
+	pvc, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get source PVC %s/%s: %v", namespace, pvcName, err)
+	}
+	mode, err := consistencyMode(pvc)
+	return pvc, mode, err
+}
+
+// cleanupContext returns a context for undoing a consistency step that outlives the cancellation of the sync
+func cleanupContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), rsyncKillTimeout)
+}
+
+// freezeLimit returns how long a filesystem may stay frozen: until shortly after the sync deadline
+func freezeLimit(ctx context.Context) time.Duration {
+	if deadline, ok := ctx.Deadline(); ok {
+		return time.Until(deadline) + time.Minute
+	}
+	return defaultFreezeLimit
+}
+
+// freezeCommand freezes the filesystem mounted at mountPath and starts a watchdog in the agent that
+// thaws it after limit, so a controller that dies mid-sync cannot leave it frozen
+func freezeCommand(mountPath string, limit time.Duration) []string {
+	return []string{"sh", "-c", fmt.Sprintf(
+		"fsfreeze -f '%[1]s' && (nohup sh -c \"sleep %[2]d; fsfreeze -u '%[1]s'\" >/dev/null 2>&1 &)",
+		mountPath, int(limit.Seconds()))}
+}
+
+// quiesceSource prepares the source data of a sync in fsfreeze or hook mode before rsync starts and
+// returns the function resuming the source after rsync
+func (p *PVCSyncer) quiesceSource(ctx context.Context, pvc *corev1.PersistentVolumeClaim, mode string, agentPod *corev1.Pod, mountPath string) (func(), error) {
+	switch mode {
+	case ConsistencyFSFreeze:
+		return p.freezeSource(ctx, pvc, agentPod, mountPath)
+	case ConsistencyHook:
+		return p.runQuiesceHooks(ctx, pvc)
+	}
+	return func() {}, nil
+}
+
+// freezeSource freezes the source filesystem through the agent mounting it
+func (p *PVCSyncer) freezeSource(ctx context.Context, pvc *corev1.PersistentVolumeClaim, agentPod *corev1.Pod, mountPath string) (func(), error) {
+	fields := logrus.Fields{
+		"namespace":  pvc.Namespace,
+		"pvc":        pvc.Name,
+		"agent_pod":  agentPod.Name,
+		"mount_path": mountPath,
+	}
+
+	if _, stderr, err := p.execCommandOnPod(ctx, agentPod.Namespace, agentPod.Name, freezeCommand(mountPath, freezeLimit(ctx))); err != nil {
+		return nil, fmt.Errorf("failed to freeze the filesystem of PVC %s/%s: %v: %s", pvc.Namespace, pvc.Name, err, strings.TrimSpace(stderr))
+	}
+	log.WithFields(fields).Info(logging.LogTagDetail + " Froze source filesystem for a consistent sync")
+
+	return func() {
+		thawCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+		if _, stderr, err := p.execCommandOnPod(thawCtx, agentPod.Namespace, agentPod.Name, []string{"fsfreeze", "-u", mountPath}); err != nil {
+			log.WithFields(fields).WithField("error", err).WithField("stderr", stderr).
+				Error(logging.LogTagError + " Failed to thaw source filesystem, the agent thaws it when the sync deadline passed")
+			p.RecordWarningEvent(thawCtx, pvc.Namespace, pvc.Name, EventReasonSyncFailed,
+				"Failed to thaw the filesystem of the PVC after its data sync: %v", err)
+			return
+		}
+		log.WithFields(fields).Info(logging.LogTagDetail + " Thawed source filesystem")
+	}, nil
+}
+
+// hookTargets returns the running pods mounting a source PVC
+func hookTargets(pods []corev1.Pod, pvcName string) []corev1.Pod {
+	var targets []corev1.Pod
+	for _, pod := range pods {
+		if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+			continue
+		}
+		for _, volume := range pod.Spec.Volumes {
+			if volume.PersistentVolumeClaim != nil && volume.PersistentVolumeClaim.ClaimName == pvcName {
+				targets = append(targets, pod)
+				break
+			}
+		}
+	}
+	return targets
+}
+
+// runHook runs a hook command in the hook container of the pods mounting a source PVC
+func (p *PVCSyncer) runHook(ctx context.Context, pvc *corev1.PersistentVolumeClaim, pods []corev1.Pod, command string) error {
+	for _, pod := range pods {
+		hookCtx, cancel := context.WithTimeout(ctx, hookTimeout)
+		_, stderr, err := p.execCommandInContainer(hookCtx, pod.Namespace, pod.Name, pvc.Annotations[AnnotationQuiesceContainer],
+			[]string{"sh", "-c", command})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("hook %q failed in pod %s/%s: %v: %s", command, pod.Namespace, pod.Name, err, strings.TrimSpace(stderr))
+		}
+	}
+	return nil
+}
+
+// runQuiesceHooks runs the quiesce command of a source PVC in the pods mounting it and returns the
+// function running its unquiesce command. Pods that were quiesced are unquiesced when a later pod fails.
+func (p *PVCSyncer) runQuiesceHooks(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (func(), error) {
+	pods, err := p.SourceK8sClient.CoreV1().Pods(pvc.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list the pods of PVC %s/%s: %v", pvc.Namespace, pvc.Name, err)
+	}
+	targets := hookTargets(pods.Items, pvc.Name)
+
+	fields := logrus.Fields{"namespace": pvc.Namespace, "pvc": pvc.Name, "pods": len(targets)}
+	var quiesced []corev1.Pod
+	unquiesce := func() {
+		command := pvc.Annotations[AnnotationUnquiesceCommand]
+		if command == "" || len(quiesced) == 0 {
+			return
+		}
+		hookCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+		if err := p.runHook(hookCtx, pvc, quiesced, command); err != nil {
+			log.WithFields(fields).WithField("error", err).Error(logging.LogTagError + " Failed to unquiesce source pods")
+			p.RecordWarningEvent(hookCtx, pvc.Namespace, pvc.Name, EventReasonSyncFailed,
+				"Failed to unquiesce the pods of the PVC after its data sync: %v", err)
+			return
+		}
+		log.WithFields(fields).Info(logging.LogTagDetail + " Unquiesced source pods")
+	}
+
+	for _, pod := range targets {
+		if err := p.runHook(ctx, pvc, []corev1.Pod{pod}, pvc.Annotations[AnnotationQuiesceCommand]); err != nil {
+			unquiesce()
+			return nil, err
+		}
+		quiesced = append(quiesced, pod)
+	}
+	log.WithFields(fields).Info(logging.LogTagDetail + " Quiesced source pods for a consistent sync")
+	return unquiesce, nil
+}
+
+// volumeSnapshotObject builds the VolumeSnapshot of a source PVC
+func volumeSnapshotObject(pvc *corev1.PersistentVolumeClaim, name string) *unstructured.Unstructured {
+	source := map[string]interface{}{"persistentVolumeClaimName": pvc.Name}
+	spec := map[string]interface{}{"source": source}
+	if class := pvc.Annotations[AnnotationVolumeSnapshotClass]; class != "" {
+		spec["volumeSnapshotClassName"] = class
+	}
+
+	snapshot := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	snapshot.SetGroupVersionKind(volumeSnapshotGVK)
+	snapshot.SetName(name)
+	snapshot.SetNamespace(pvc.Namespace)
+	snapshot.SetLabels(map[string]string{
+		"app.kubernetes.io/managed-by": "dr-syncer",
+		"dr-syncer.io/pvc-name":        sourceSSHDPVCLabel(pvc.Name),
+	})
+	return snapshot
+}
+
+// snapshotPVCObject builds the temporary PVC restoring the snapshot of a source PVC, with the storage
+// class, access modes, volume mode and size of the source PVC
+func snapshotPVCObject(pvc *corev1.PersistentVolumeClaim, name string) *corev1.PersistentVolumeClaim {
+	size := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok && capacity.Cmp(size) > 0 {
+		size = capacity
+	}
+	apiGroup := volumeSnapshotGVK.Group
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: pvc.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "dr-syncer",
+				"dr-syncer.io/pvc-name":        sourceSSHDPVCLabel(pvc.Name),
+			},
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      pvc.Spec.AccessModes,
+			StorageClassName: pvc.Spec.StorageClassName,
+			VolumeMode:       pvc.Spec.VolumeMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: size.DeepCopy()},
+			},
+			DataSource: &corev1.TypedLocalObjectReference{
+				APIGroup: &apiGroup,
+				Kind:     volumeSnapshotGVK.Kind,
+				Name:     name,
+			},
+		},
+	}
+}
+
+// snapshotState reads whether a VolumeSnapshot was cut and is ready to use, or failed
+func snapshotState(snapshot *unstructured.Unstructured) (cut, ready bool, err error) {
+	if message, found, _ := unstructured.NestedString(snapshot.Object, "status", "error", "message"); found && message != "" {
+		return false, false, fmt.Errorf("snapshot %s/%s failed: %s", snapshot.GetNamespace(), snapshot.GetName(), message)
+	}
+	_, cut, _ = unstructured.NestedString(snapshot.Object, "status", "creationTime")
+	ready, _, _ = unstructured.NestedBool(snapshot.Object, "status", "readyToUse")
+	return cut || ready, ready, nil
+}
+
+// snapshotSourcePVC takes a CSI snapshot of a source PVC and restores it into a temporary PVC the
+// sync reads the data from. The quiesce hooks of the PVC, when set, run until the snapshot is cut.
+// It returns the name of the temporary PVC and the function deleting it and the snapshot.
+func (p *PVCSyncer) snapshotSourcePVC(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (string, func(), error) {
+	name := fmt.Sprintf("%s%s-%s", pvc.Name, snapshotPVCSuffix, rand.String(5))
+	fields := logrus.Fields{"namespace": pvc.Namespace, "pvc": pvc.Name, "snapshot": name}
+
+	unquiesce := func() {}
+	if pvc.Annotations[AnnotationQuiesceCommand] != "" {
+		resume, err := p.runQuiesceHooks(ctx, pvc)
+		if err != nil {
+			return "", nil, err
+		}
+		unquiesce = resume
+	}
+
+	snapshot := volumeSnapshotObject(pvc, name)
+	if err := p.SourceClient.Create(ctx, snapshot); err != nil {
+		unquiesce()
+		return "", nil, fmt.Errorf("failed to create VolumeSnapshot %s/%s: %v", pvc.Namespace, name, err)
+	}
+
+	var restored *corev1.PersistentVolumeClaim
+	release := func() {
+		cleanupCtx, cancel := cleanupContext(ctx)
+		defer cancel()
+		if restored != nil {
+			if err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Delete(cleanupCtx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+				log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to delete temporary snapshot PVC")
+			}
+		}
+		if err := p.SourceClient.Delete(cleanupCtx, snapshot); err != nil && !apierrors.IsNotFound(err) {
+			log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to delete VolumeSnapshot")
+		}
+	}
+
+	current := &unstructured.Unstructured{}
+	current.SetGroupVersionKind(volumeSnapshotGVK)
+	quiesced := true
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, snapshotReadyTimeout, true, func(ctx context.Context) (bool, error) {
+		if err := p.SourceClient.Get(ctx, client.ObjectKeyFromObject(snapshot), current); err != nil {
+			return false, nil
+		}
+		cut, ready, err := snapshotState(current)
+		if err != nil {
+			return false, err
+		}
+		if cut && quiesced {
+			unquiesce()
+			quiesced = false
+		}
+		return ready, nil
+	})
+	if quiesced {
+		unquiesce()
+	}
+	if err != nil {
+		release()
+		return "", nil, fmt.Errorf("VolumeSnapshot %s/%s not ready: %v", pvc.Namespace, name, err)
+	}
+	log.WithFields(fields).Info(logging.LogTagDetail + " Snapshot of source PVC ready")
+
+	restored, err = p.SourceK8sClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(ctx, snapshotPVCObject(pvc, name), metav1.CreateOptions{})
+	if err != nil {
+		restored = nil
+		release()
+		return "", nil, fmt.Errorf("failed to restore VolumeSnapshot %s/%s into a PVC: %v", pvc.Namespace, name, err)
+	}
+	log.WithFields(fields).Info(logging.LogTagDetail + " Restored snapshot of source PVC into a temporary PVC")
+	return name, release, nil
+}
@@ -0,0 +1,149 @@
+package replication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func consistencyPVC(annotations map[string]string) *corev1.PersistentVolumeClaim {
+	storageClass := "csi-rbd"
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders.db", Namespace: "shop", Annotations: annotations},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			StorageClassName: &storageClass,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("10Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("12Gi")},
+		},
+	}
+}
+
+func TestConsistencyMode(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        string
+		wantErr     bool
+	}{
+		{name: "live copy", want: ""},
+		{name: "fsfreeze", annotations: map[string]string{AnnotationConsistency: " FSFreeze "}, want: ConsistencyFSFreeze},
+		{name: "snapshot", annotations: map[string]string{AnnotationConsistency: "snapshot"}, want: ConsistencySnapshot},
+		{
+			name:        "hook",
+			annotations: map[string]string{AnnotationConsistency: "hook", AnnotationQuiesceCommand: "pg_ctl stop"},
+			want:        ConsistencyHook,
+		},
+		{name: "hook without command", annotations: map[string]string{AnnotationConsistency: "hook"}, wantErr: true},
+		{name: "unknown", annotations: map[string]string{AnnotationConsistency: "lvm"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mode, err := consistencyMode(consistencyPVC(tt.annotations))
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, mode)
+		})
+	}
+}
+
+func TestFreezeLimit(t *testing.T) {
+	assert.Equal(t, defaultFreezeLimit, freezeLimit(context.Background()))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	limit := freezeLimit(ctx)
+	assert.Greater(t, limit, 10*time.Minute)
+	assert.LessOrEqual(t, limit, 11*time.Minute)
+}
+
+func TestFreezeCommand(t *testing.T) {
+	command := freezeCommand("/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/pvc-1/mount", 90*time.Second)
+	assert.Equal(t, []string{"sh", "-c",
+		"fsfreeze -f '/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/pvc-1/mount' && " +
+			"(nohup sh -c \"sleep 90; fsfreeze -u '/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/pvc-1/mount'\" >/dev/null 2>&1 &)"},
+		command)
+}
+
+func TestHookTargets(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, claims ...string) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}, Status: corev1.PodStatus{Phase: phase}}
+		for _, claim := range claims {
+			p.Spec.Volumes = append(p.Spec.Volumes, corev1.Volume{VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+			}})
+		}
+		return p
+	}
+	deleting := pod("db-2", corev1.PodRunning, "orders.db")
+	deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+
+	targets := hookTargets([]corev1.Pod{
+		pod("db-0", corev1.PodRunning, "config", "orders.db"),
+		pod("db-1", corev1.PodPending, "orders.db"),
+		deleting,
+		pod("web", corev1.PodRunning, "assets"),
+	}, "orders.db")
+	require.Len(t, targets, 1)
+	assert.Equal(t, "db-0", targets[0].Name)
+}
+
+func TestSnapshotObjects(t *testing.T) {
+	pvc := consistencyPVC(map[string]string{AnnotationVolumeSnapshotClass: "csi-rbd-snap"})
+
+	snapshot := volumeSnapshotObject(pvc, "orders.db-dr-snap-abcde")
+	assert.Equal(t, volumeSnapshotGVK, snapshot.GroupVersionKind())
+	assert.Equal(t, "shop", snapshot.GetNamespace())
+	assert.Equal(t, "orders-db", snapshot.GetLabels()["dr-syncer.io/pvc-name"])
+	source, _, _ := unstructured.NestedString(snapshot.Object, "spec", "source", "persistentVolumeClaimName")
+	assert.Equal(t, "orders.db", source)
+	class, _, _ := unstructured.NestedString(snapshot.Object, "spec", "volumeSnapshotClassName")
+	assert.Equal(t, "csi-rbd-snap", class)
+
+	// The restored PVC is as large as the source volume
+	restored := snapshotPVCObject(pvc, "orders.db-dr-snap-abcde")
+	assert.Equal(t, "csi-rbd", *restored.Spec.StorageClassName)
+	assert.Equal(t, pvc.Spec.AccessModes, restored.Spec.AccessModes)
+	size := restored.Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.Equal(t, "12Gi", size.String())
+	assert.Equal(t, "VolumeSnapshot", restored.Spec.DataSource.Kind)
+	assert.Equal(t, "snapshot.storage.k8s.io", *restored.Spec.DataSource.APIGroup)
+	assert.Equal(t, "orders.db-dr-snap-abcde", restored.Spec.DataSource.Name)
+}
+
+func TestSnapshotState(t *testing.T) {
+	snapshot := volumeSnapshotObject(consistencyPVC(nil), "snap")
+	cut, ready, err := snapshotState(snapshot)
+	require.NoError(t, err)
+	assert.False(t, cut)
+	assert.False(t, ready)
+
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, "2026-10-16T00:00:00Z", "status", "creationTime"))
+	cut, ready, err = snapshotState(snapshot)
+	require.NoError(t, err)
+	assert.True(t, cut)
+	assert.False(t, ready)
+
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, true, "status", "readyToUse"))
+	_, ready, err = snapshotState(snapshot)
+	require.NoError(t, err)
+	assert.True(t, ready)
+
+	require.NoError(t, unstructured.SetNestedField(snapshot.Object, "no snapshot class", "status", "error", "message"))
+	_, _, err = snapshotState(snapshot)
+	assert.Error(t, err)
+}
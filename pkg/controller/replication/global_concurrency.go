@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/syncstate"
 	"golang.org/x/sync/semaphore"
 )
 
@@ -73,6 +74,7 @@ func (m *GlobalConcurrencyManager) Acquire(ctx context.Context, namespace, pvcNa
 	m.mu.Unlock()
 
 	PVCSyncQueueDepth.Set(float64(waitingNow))
+	syncstate.SyncWaiting(namespace, pvcName)
 
 	startWait := time.Now()
 	m.log.WithFields(logrus.Fields{
@@ -96,6 +98,7 @@ func (m *GlobalConcurrencyManager) Acquire(ctx context.Context, namespace, pvcNa
 	PVCSyncConcurrentCount.Set(float64(activeNow))
 
 	if err == nil {
+		syncstate.SyncStarted(namespace, pvcName)
 		waitDuration := time.Since(startWait)
 		PVCSyncQueueWaitDuration.Observe(waitDuration.Seconds())
 		m.log.WithFields(logrus.Fields{
@@ -106,6 +109,7 @@ func (m *GlobalConcurrencyManager) Acquire(ctx context.Context, namespace, pvcNa
 			"waiting":       waitingNow,
 		}).Debug("Acquired concurrency slot")
 	} else {
+		syncstate.SyncFinished(namespace, pvcName)
 		m.log.WithFields(logrus.Fields{
 			"namespace": namespace,
 			"pvc":       pvcName,
@@ -127,6 +131,7 @@ func (m *GlobalConcurrencyManager) Release(namespace, pvcName string) {
 	m.mu.Unlock()
 
 	PVCSyncConcurrentCount.Set(float64(activeNow))
+	syncstate.SyncFinished(namespace, pvcName)
 
 	m.log.WithFields(logrus.Fields{
 		"namespace": namespace,
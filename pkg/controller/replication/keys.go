@@ -22,6 +22,12 @@ import (
 const (
 	// DefaultKeyBits is the default number of bits for RSA keys
 	DefaultKeyBits = 4096
+
+	// syncKeyTTL is how long the agents accept a key declared for a single sync
+	syncKeyTTL = 24 * time.Hour
+
+	// keyDistributionTimeout bounds the wait for the leader agent to distribute a declared key
+	keyDistributionTimeout = 2 * time.Minute
 )
 
 // KeyPair represents an SSH key pair
@@ -0,0 +1,175 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+var (
+	// fenceTimeout bounds the wait for the pods of a stale sync to terminate
+	fenceTimeout = 2 * time.Minute
+
+	// fencePollInterval is how often the pods of a stale sync are checked while they terminate
+	fencePollInterval = 2 * time.Second
+)
+
+// agentRsyncKillScript stops the rsync processes of the agent reading or writing the mount path passed as
+// its argument, escalating to SIGKILL, and fails when one is still running. It reads /proc as the agent
+// image has no procps.
+const agentRsyncKillScript = `path="$1"
+rsyncs() {
+  for proc in /proc/[0-9]*; do
+    cmdline=$(tr '\0' ' ' < "$proc/cmdline" 2>/dev/null) || continue
+    command="${cmdline%% *}"
+    [ "${command##*/}" = rsync ] || continue
+    case "$cmdline" in
+      *"$path"*) echo "${proc#/proc/}" ;;
+    esac
+  done
+}
+for signal in TERM TERM TERM KILL; do
+  pids=$(rsyncs)
+  [ -z "$pids" ] && exit 0
+  echo "stopping rsync $pids with SIG$signal"
+  kill -s "$signal" $pids 2>/dev/null
+  sleep 2
+done
+[ -z "$(rsyncs)" ]`
+
+// staleLockTimeout returns the age after which another controller's lock is taken over
+func (p *PVCSyncer) staleLockTimeout() time.Duration {
+	if p.StaleLockTimeout > 0 {
+		return p.StaleLockTimeout
+	}
+	return GetLockTimeout()
+}
+
+// recordLockSync records the rsync deployment of the sync holding the lock on the source PVC
+func (p *PVCSyncer) recordLockSync(ctx context.Context, namespace, pvcName string, destPod *rsyncpod.RsyncDeployment) {
+	if destPod == nil || destPod.SyncID == "" {
+		return
+	}
+
+	pvc, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err == nil {
+		podName := os.Getenv("POD_NAME")
+		if podName == "" {
+			podName = "unknown"
+		}
+		if pvc.Annotations[LockOwnerAnnotation] != podName {
+			return
+		}
+		pvc.Annotations[LockSyncAnnotation] = destPod.Namespace + "/" + destPod.SyncID
+		_, err = p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{})
+	}
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"pvc_name":  pvcName,
+			"sync_id":   destPod.SyncID,
+			"error":     err,
+		}).Warn(logging.LogTagWarn + " Failed to record the rsync deployment of the sync on the lock")
+	}
+}
+
+// fenceStaleSync stops the sync of a stale lock on the source PVC before it is taken over, so the sync of
+// the previous owner cannot keep writing next to the new one. The rsync deployment recorded on the lock
+// is deleted in the destination cluster and waited for, then the rsync processes of the agents using the
+// source PVC are stopped. An error means the stale sync may still be running and the lock must not be taken.
+func (p *PVCSyncer) fenceStaleSync(ctx context.Context, namespace, pvcName string, annotations map[string]string) error {
+	if lockSync := annotations[LockSyncAnnotation]; lockSync != "" {
+		destNamespace, syncID, ok := strings.Cut(lockSync, "/")
+		if !ok || destNamespace == "" || syncID == "" {
+			return fmt.Errorf("invalid %s annotation %q", LockSyncAnnotation, lockSync)
+		}
+		if err := p.fenceRsyncDeployments(ctx, destNamespace, syncID); err != nil {
+			return err
+		}
+	}
+
+	if p.SourceClient == nil {
+		return nil
+	}
+	nodes, err := p.FindPVCNodes(ctx, p.SourceClient, namespace, pvcName)
+	if err != nil {
+		return fmt.Errorf("failed to find the nodes mounting the PVC: %w", err)
+	}
+	for _, node := range nodes {
+		if err := p.fenceAgentRsync(ctx, namespace, pvcName, node); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fenceRsyncDeployments deletes the rsync deployments of the sync syncID and waits for their pods to terminate
+func (p *PVCSyncer) fenceRsyncDeployments(ctx context.Context, namespace, syncID string) error {
+	selector := "dr-syncer.io/sync-id=" + syncID
+	deployments, err := p.DestinationK8sClient.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list the rsync deployments of stale sync %s: %w", syncID, err)
+	}
+
+	propagation := metav1.DeletePropagationForeground
+	for _, deployment := range deployments.Items {
+		log.WithFields(logrus.Fields{
+			"namespace":  namespace,
+			"deployment": deployment.Name,
+			"sync_id":    syncID,
+		}).Info(logging.LogTagDetail + " Deleting rsync deployment of stale sync")
+		err := p.DestinationK8sClient.AppsV1().Deployments(namespace).Delete(ctx, deployment.Name, metav1.DeleteOptions{PropagationPolicy: &propagation})
+		if err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete rsync deployment %s of stale sync: %w", deployment.Name, err)
+		}
+	}
+
+	err = wait.PollUntilContextTimeout(ctx, fencePollInterval, fenceTimeout, true, func(ctx context.Context) (bool, error) {
+		pods, err := p.DestinationK8sClient.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+		if err != nil {
+			return false, nil
+		}
+		return len(pods.Items) == 0, nil
+	})
+	if err != nil {
+		return fmt.Errorf("rsync pods of stale sync %s are still running: %w", syncID, err)
+	}
+	return nil
+}
+
+// fenceAgentRsync stops the rsync processes of the agent on node reading the source PVC
+func (p *PVCSyncer) fenceAgentRsync(ctx context.Context, namespace, pvcName, node string) error {
+	agentPod, _, err := p.FindAgentPod(ctx, node)
+	if err != nil {
+		return fmt.Errorf("failed to find the agent on node %s: %w", node, err)
+	}
+	mountPath, err := p.FindPVCMountPath(ctx, namespace, pvcName, agentPod)
+	if err != nil {
+		return fmt.Errorf("failed to find the mount path of the PVC on node %s: %w", node, err)
+	}
+
+	stdout, stderr, err := p.execCommandOnPod(ctx, agentPod.Namespace, agentPod.Name,
+		[]string{"sh", "-c", agentRsyncKillScript, "sh", mountPath})
+	if err != nil {
+		return fmt.Errorf("failed to stop the rsync processes of stale sync on agent %s: %w (stderr: %s)", agentPod.Name, err, stderr)
+	}
+	if stdout != "" {
+		log.WithFields(logrus.Fields{
+			"namespace":  namespace,
+			"pvc_name":   pvcName,
+			"agent_pod":  agentPod.Name,
+			"mount_path": mountPath,
+			"output":     strings.TrimSpace(stdout),
+		}).Info(logging.LogTagDetail + " Stopped rsync processes of stale sync on agent")
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package replication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func lockedPVC(age time.Duration, lockSync string) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+		Name:      "data",
+		Namespace: "app",
+		Annotations: map[string]string{
+			LockOwnerAnnotation:     "dr-syncer-old",
+			LockTimestampAnnotation: time.Now().Add(-age).UTC().Format(time.RFC3339),
+		},
+	}}
+	if lockSync != "" {
+		pvc.Annotations[LockSyncAnnotation] = lockSync
+	}
+	return pvc
+}
+
+func staleRsyncDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:      "dr-syncer-rsync-data-abc",
+		Namespace: "app-dr",
+		Labels:    map[string]string{"dr-syncer.io/sync-id": "abc"},
+	}}
+}
+
+func TestAcquirePVCLock_FencesStaleSync(t *testing.T) {
+	t.Setenv("POD_NAME", "dr-syncer-new")
+	source := fake.NewSimpleClientset(lockedPVC(2*time.Hour, "app-dr/abc"))
+	destination := fake.NewSimpleClientset(staleRsyncDeployment())
+	p := &PVCSyncer{SourceConfig: &rest.Config{}, SourceK8sClient: source, DestinationK8sClient: destination}
+
+	acquired, lockInfo, err := p.AcquirePVCLock(context.Background(), "app", "data")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+	assert.Equal(t, "dr-syncer-new", lockInfo.ControllerPodName)
+
+	_, err = destination.AppsV1().Deployments("app-dr").Get(context.Background(), "dr-syncer-rsync-data-abc", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "the rsync deployment of the stale sync is deleted")
+
+	pvc, err := source.CoreV1().PersistentVolumeClaims("app").Get(context.Background(), "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, pvc.Annotations, LockSyncAnnotation)
+}
+
+func TestAcquirePVCLock_StaleSyncStillRunning(t *testing.T) {
+	t.Setenv("POD_NAME", "dr-syncer-new")
+	previousTimeout, previousInterval := fenceTimeout, fencePollInterval
+	fenceTimeout, fencePollInterval = 50*time.Millisecond, time.Millisecond
+	t.Cleanup(func() { fenceTimeout, fencePollInterval = previousTimeout, previousInterval })
+
+	source := fake.NewSimpleClientset(lockedPVC(2*time.Hour, "app-dr/abc"))
+	runningPod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		Name:      "dr-syncer-rsync-data-abc-1",
+		Namespace: "app-dr",
+		Labels:    map[string]string{"dr-syncer.io/sync-id": "abc"},
+	}}
+	p := &PVCSyncer{
+		SourceConfig:         &rest.Config{},
+		SourceK8sClient:      source,
+		DestinationK8sClient: fake.NewSimpleClientset(staleRsyncDeployment(), runningPod),
+	}
+
+	acquired, _, err := p.AcquirePVCLock(context.Background(), "app", "data")
+	require.Error(t, err)
+	assert.False(t, acquired)
+	assert.Contains(t, err.Error(), "rsync pods of stale sync abc are still running")
+
+	pvc, err := source.CoreV1().PersistentVolumeClaims("app").Get(context.Background(), "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "dr-syncer-old", pvc.Annotations[LockOwnerAnnotation], "the lock is not taken")
+}
+
+func TestAcquirePVCLock_StaleLockTimeout(t *testing.T) {
+	t.Setenv("POD_NAME", "dr-syncer-new")
+	t.Setenv("LOCK_TIMEOUT_MINUTES", "")
+
+	p := &PVCSyncer{
+		SourceConfig:         &rest.Config{},
+		SourceK8sClient:      fake.NewSimpleClientset(lockedPVC(30*time.Minute, "")),
+		DestinationK8sClient: fake.NewSimpleClientset(),
+	}
+	acquired, lockInfo, err := p.AcquirePVCLock(context.Background(), "app", "data")
+	require.NoError(t, err)
+	assert.False(t, acquired, "a 30m old lock is live with the default timeout")
+	assert.Equal(t, "dr-syncer-old", lockInfo.ControllerPodName)
+
+	p.StaleLockTimeout = 10 * time.Minute
+	acquired, _, err = p.AcquirePVCLock(context.Background(), "app", "data")
+	require.NoError(t, err)
+	assert.True(t, acquired)
+}
+
+func TestRecordLockSync(t *testing.T) {
+	t.Setenv("POD_NAME", "dr-syncer-new")
+	pvc := lockedPVC(0, "")
+	pvc.Annotations[LockOwnerAnnotation] = "dr-syncer-new"
+	source := fake.NewSimpleClientset(pvc)
+	p := &PVCSyncer{SourceConfig: &rest.Config{}, SourceK8sClient: source}
+
+	p.recordLockSync(context.Background(), "app", "data", &rsyncpod.RsyncDeployment{Namespace: "app-dr", SyncID: "xyz"})
+	stored, err := source.CoreV1().PersistentVolumeClaims("app").Get(context.Background(), "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "app-dr/xyz", stored.Annotations[LockSyncAnnotation])
+
+	require.NoError(t, p.ReleasePVCLock(context.Background(), "app", "data"))
+	stored, err = source.CoreV1().PersistentVolumeClaims("app").Get(context.Background(), "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, stored.Annotations, LockSyncAnnotation)
+}
@@ -52,6 +52,24 @@ var (
 		[]string{"namespace", "pvc_name", "destination_namespace", "status"},
 	)
 
+	// PVCSyncSkipped tracks PVC data syncs that were skipped, by reason
+	PVCSyncSkipped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dr_syncer_pvc_sync_skipped_total",
+			Help: "Total number of PVC data syncs skipped, by reason",
+		},
+		[]string{"namespace", "pvc_name", "destination_namespace", "reason"},
+	)
+
+	// PVCSyncRsyncFailures tracks failed rsync runs of PVC data syncs, by failure class
+	PVCSyncRsyncFailures = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "dr_syncer_pvc_sync_rsync_failures_total",
+			Help: "Total number of PVC data syncs failed by rsync, by failure class",
+		},
+		[]string{"namespace", "pvc_name", "destination_namespace", "class"},
+	)
+
 	// PVCSyncSpeed tracks current sync speed in bytes per second
 	PVCSyncSpeed = prometheus.NewGaugeVec(
 		prometheus.GaugeOpts{
@@ -61,6 +79,33 @@ var (
 		[]string{"namespace", "pvc_name", "destination_namespace"},
 	)
 
+	// PVCSyncTotalSize tracks the size of the PVC data compared by the last sync
+	PVCSyncTotalSize = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dr_syncer_pvc_sync_total_size_bytes",
+			Help: "Total size of the files compared by the last PVC sync",
+		},
+		[]string{"namespace", "pvc_name", "destination_namespace"},
+	)
+
+	// PVCSyncLastBytesTransferred tracks the bytes sent by the last sync
+	PVCSyncLastBytesTransferred = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dr_syncer_pvc_sync_last_bytes_transferred",
+			Help: "Bytes sent over the replication link by the last PVC sync",
+		},
+		[]string{"namespace", "pvc_name", "destination_namespace"},
+	)
+
+	// PVCSyncSpeedup tracks the rsync speedup factor of the last sync
+	PVCSyncSpeedup = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dr_syncer_pvc_sync_speedup_ratio",
+			Help: "Total size divided by the bytes sent and received by the last PVC sync",
+		},
+		[]string{"namespace", "pvc_name", "destination_namespace"},
+	)
+
 	// PVCSyncQueueDepth tracks number of PVC syncs waiting for a concurrency slot
 	PVCSyncQueueDepth = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -85,6 +130,24 @@ var (
 			Buckets: prometheus.ExponentialBuckets(0.1, 2, 12), // 0.1s to ~7 minutes
 		},
 	)
+
+	// ClusterDataSyncsActive tracks the PVC data syncs holding a slot of a RemoteCluster
+	ClusterDataSyncsActive = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dr_syncer_cluster_data_syncs_active",
+			Help: "Number of PVC data syncs holding a maxConcurrentDataSyncs slot of the remote cluster",
+		},
+		[]string{"namespace", "remote_cluster"},
+	)
+
+	// ClusterDataSyncsWaiting tracks the PVC data syncs waiting for a slot of a RemoteCluster
+	ClusterDataSyncsWaiting = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "dr_syncer_cluster_data_syncs_waiting",
+			Help: "Number of PVC data syncs waiting for a maxConcurrentDataSyncs slot of the remote cluster",
+		},
+		[]string{"namespace", "remote_cluster"},
+	)
 )
 
 func init() {
@@ -95,10 +158,17 @@ func init() {
 		PVCSyncProgress,
 		PVCSyncDuration,
 		PVCSyncOperations,
+		PVCSyncSkipped,
+		PVCSyncRsyncFailures,
 		PVCSyncSpeed,
+		PVCSyncTotalSize,
+		PVCSyncLastBytesTransferred,
+		PVCSyncSpeedup,
 		PVCSyncQueueDepth,
 		PVCSyncConcurrentCount,
 		PVCSyncQueueWaitDuration,
+		ClusterDataSyncsActive,
+		ClusterDataSyncsWaiting,
 	)
 }
 
@@ -129,6 +199,23 @@ func RecordSyncComplete(namespace, pvcName, destNamespace string, bytesTransferr
 	PVCSyncOperations.WithLabelValues(namespace, pvcName, destNamespace, status).Inc()
 }
 
+// RecordSyncSkipped records a PVC data sync that was skipped for reason
+func RecordSyncSkipped(namespace, pvcName, destNamespace, reason string) {
+	PVCSyncSkipped.WithLabelValues(namespace, pvcName, destNamespace, reason).Inc()
+}
+
+// RecordRsyncFailure records a PVC data sync failed by rsync with a failure class
+func RecordRsyncFailure(namespace, pvcName, destNamespace string, class RsyncFailureClass) {
+	PVCSyncRsyncFailures.WithLabelValues(namespace, pvcName, destNamespace, string(class)).Inc()
+}
+
+// RecordSyncStats records the rsync statistics of a completed sync
+func RecordSyncStats(namespace, pvcName, destNamespace string, stats RsyncStats) {
+	PVCSyncTotalSize.WithLabelValues(namespace, pvcName, destNamespace).Set(float64(stats.TotalSize))
+	PVCSyncLastBytesTransferred.WithLabelValues(namespace, pvcName, destNamespace).Set(float64(stats.BytesSent))
+	PVCSyncSpeedup.WithLabelValues(namespace, pvcName, destNamespace).Set(stats.Speedup())
+}
+
 // RecordSyncFailure records a failed sync operation
 func RecordSyncFailure(namespace, pvcName, destNamespace string, durationSeconds float64) {
 	PVCSyncProgress.WithLabelValues(namespace, pvcName, destNamespace).Set(0)
@@ -0,0 +1,333 @@
+package replication
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
+	"github.com/supporttools/dr-syncer/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// resticHost is the host recorded in restic snapshots, so snapshots of a PVC form one group
+	// regardless of the agent pod that took them
+	resticHost = "dr-syncer"
+
+	// resticTagPrefix prefixes the restic tag identifying the snapshots of a source PVC
+	resticTagPrefix = "dr-syncer-pvc="
+
+	// DefaultKeepSnapshots is the number of snapshots kept per PVC when none is configured
+	DefaultKeepSnapshots = 3
+
+	// objectStorageRestoreTarget is where the destination rsync pod mounts the destination PVC
+	objectStorageRestoreTarget = "/data"
+)
+
+// resticEnvName matches the environment variable names passed to restic
+var resticEnvName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ObjectStorageRepository is the restic repository the ObjectStorage transport moves PVC data through
+type ObjectStorageRepository struct {
+	// Repository is the restic repository URL
+	Repository string
+
+	// Env holds the credentials of the repository, passed to restic as environment variables
+	Env map[string]string
+
+	// KeepSnapshots is the number of snapshots kept per PVC
+	KeepSnapshots int
+}
+
+// envFile renders the repository and its credentials as shell assignments. They are written to the
+// stdin of the restic command, so the credentials never show up in the exec request or pod spec.
+func (r *ObjectStorageRepository) envFile() (string, error) {
+	names := make([]string, 0, len(r.Env))
+	for name := range r.Env {
+		if !resticEnvName.MatchString(name) {
+			return "", fmt.Errorf("credentials key %q is not a valid environment variable name", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "RESTIC_REPOSITORY=%s\n", shellQuote(r.Repository))
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s=%s\n", name, shellQuote(r.Env[name]))
+	}
+	return b.String(), nil
+}
+
+// resticTag returns the restic tag of the snapshots of a source PVC
+func resticTag(namespace, pvcName string) string {
+	return resticTagPrefix + namespace + "/" + pvcName
+}
+
+// resticScript wraps restic commands so they read the repository environment from stdin
+func resticScript(commands ...string) []string {
+	return []string{"bash", "-c", "set -a && . /dev/stdin && set +a && " + strings.Join(commands, " && ")}
+}
+
+// resticBackupCommand backs up the mount path of a source PVC, initializing the repository on first
+// use and forgetting snapshots beyond keep. The contents of the mount path are stored at the root of
+// the snapshot, so they restore into any target.
+func resticBackupCommand(mountPath, tag string, keep int) []string {
+	return resticScript(
+		"(restic cat config >/dev/null 2>&1 || restic init >&2)",
+		fmt.Sprintf("cd %s", shellQuote(mountPath)),
+		fmt.Sprintf("restic backup --json --host %s --tag %s .", resticHost, shellQuote(tag)),
+		fmt.Sprintf("restic forget --host %s --tag %s --keep-last %d --prune >&2", resticHost, shellQuote(tag), keep),
+	)
+}
+
+// resticRestoreCommand restores a snapshot into the destination PVC, deleting files that are not in it
+func resticRestoreCommand(snapshotID string) []string {
+	return resticScript(
+		fmt.Sprintf("restic restore %s --target %s --delete", shellQuote(snapshotID), objectStorageRestoreTarget),
+	)
+}
+
+// resticBackupSummary is the summary message printed by restic backup --json
+type resticBackupSummary struct {
+	MessageType  string `json:"message_type"`
+	SnapshotID   string `json:"snapshot_id"`
+	FilesNew     int    `json:"files_new"`
+	FilesChanged int    `json:"files_changed"`
+	DataAdded    int64  `json:"data_added"`
+}
+
+// parseResticBackupSummary returns the summary of restic backup --json output
+func parseResticBackupSummary(output string) (*resticBackupSummary, error) {
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var summary resticBackupSummary
+		if err := json.Unmarshal(scanner.Bytes(), &summary); err != nil {
+			continue
+		}
+		if summary.MessageType == "summary" && summary.SnapshotID != "" {
+			return &summary, nil
+		}
+	}
+	return nil, fmt.Errorf("restic backup did not report a snapshot")
+}
+
+// execInPodWithStdin runs a command in a pod, streaming stdin to it
+func execInPodWithStdin(ctx context.Context, k8sClient kubernetes.Interface, config *rest.Config, namespace, podName string, command []string, stdin io.Reader) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	err := execInPodStreams(ctx, k8sClient, config, namespace, podName, command, stdin, &stdout, &stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+// execInPodStreams runs a command in a pod, streaming stdin to it and its output to stdout and stderr
+func execInPodStreams(ctx context.Context, k8sClient kubernetes.Interface, config *rest.Config, namespace, podName string, command []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	req := k8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec")
+
+	req.VersionedParams(&corev1.PodExecOptions{
+		Command: command,
+		Stdin:   stdin != nil,
+		Stdout:  true,
+		Stderr:  true,
+		TTY:     false,
+	}, scheme.ParameterCodec)
+
+	exec, err := util.NewPodExecutor(config, req.URL())
+	if err != nil {
+		return err
+	}
+
+	return exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+		Tty:    false,
+	})
+}
+
+// ObjectStorageWorkflow transfers the data of a PVC through the ObjectStorage repository. The source
+// agent on the node mounting the PVC backs it up into the repository, then an rsync pod mounting the
+// destination PVC restores the snapshot. The clusters never connect to each other.
+func (p *PVCSyncer) ObjectStorageWorkflow(ctx context.Context, sourceNamespace, sourcePVCName, destNamespace, destPVCName string) error {
+	startTime := time.Now()
+
+	// Tag the logs of the workflow with the trace of the sync
+	log := log.WithContext(ctx)
+
+	fields := logrus.Fields{
+		"source_namespace": sourceNamespace,
+		"source_pvc":       sourcePVCName,
+		"dest_namespace":   destNamespace,
+		"dest_pvc":         destPVCName,
+		"repository":       p.ObjectStorage.Repository,
+	}
+	log.WithFields(fields).Info(logging.LogTagInfo + " Starting object storage workflow")
+
+	env, err := p.ObjectStorage.envFile()
+	if err != nil {
+		return fmt.Errorf("invalid object storage credentials: %v", err)
+	}
+
+	// Restic cannot back up raw block volumes either
+	if skip, err := p.skipBlockVolume(ctx, sourceNamespace, sourcePVCName, destNamespace, destPVCName); err != nil {
+		return err
+	} else if skip {
+		return nil
+	}
+
+	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncStarted,
+		"Starting PVC data sync to %s/%s through object storage", destNamespace, destPVCName)
+
+	p.SourceNamespace = sourceNamespace
+	p.DestinationNamespace = destNamespace
+
+	tracing.Step(ctx, "lock")
+	acquired, lockInfo, err := p.AcquirePVCLock(ctx, sourceNamespace, sourcePVCName)
+	if err != nil {
+		return fmt.Errorf("failed to check lock on source PVC: %v", err)
+	}
+	if !acquired {
+		log.WithFields(fields).WithField("lock_owner", lockInfo.ControllerPodName).
+			Info(logging.LogTagSkip + " Source PVC is locked by another controller, skipping sync")
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped,
+			"PVC is locked by %s, skipping sync", lockInfo.ControllerPodName)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonLocked, "")
+		return nil
+	}
+	defer func() {
+		if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+			log.WithFields(fields).WithField("error", relErr).Warn(logging.LogTagWarn + " Failed to release lock on source PVC")
+		}
+	}()
+
+	fail := func(format string, args ...interface{}) error {
+		err := fmt.Errorf(format, args...)
+		log.WithFields(fields).WithField("error", err).Error(logging.LogTagError + " Object storage workflow failed")
+		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed, "%v", err)
+		if statusErr := p.FailedSyncStatus(ctx, sourceNamespace, sourcePVCName, err); statusErr != nil {
+			log.WithFields(fields).WithField("error", statusErr).Warn(logging.LogTagWarn + " Failed to update sync status")
+		}
+		return err
+	}
+
+	tracing.Step(ctx, "locate-source")
+	mounted, err := p.HasVolumeAttachments(ctx, sourceNamespace, sourcePVCName)
+	if err != nil {
+		return fail("failed to check if source PVC is mounted: %v", err)
+	}
+	if !mounted && p.usesAttachPod() {
+		attachPod, err := p.attachSourcePVC(ctx, sourceNamespace, sourcePVCName)
+		if err != nil {
+			return fail("failed to mount unmounted source PVC: %v", err)
+		}
+		defer p.deleteAttachPod(ctx, sourceNamespace, sourcePVCName, attachPod.Name)
+		mounted = true
+	}
+	if !mounted {
+		log.WithFields(fields).Info(logging.LogTagSkip + " Source PVC is not mounted, skipping sync")
+		message := notMountedMessage
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped, "%s", message)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonNotMounted, message)
+		return nil
+	}
+
+	sourceNode, err := p.FindPVCNode(ctx, p.SourceClient, sourceNamespace, sourcePVCName)
+	if err != nil {
+		return fail("failed to find node where source PVC is mounted: %v", err)
+	}
+	agentPod, _, err := p.FindAgentPod(ctx, sourceNode)
+	if err != nil {
+		return fail("failed to find DR-Syncer-Agent on node %s: %v", sourceNode, err)
+	}
+	mountPath, err := p.FindPVCMountPath(ctx, sourceNamespace, sourcePVCName, agentPod)
+	if err != nil {
+		return fail("failed to find mount path for PVC: %v", err)
+	}
+
+	if p.skipUnchanged(ctx, sourceNamespace, sourcePVCName, agentPod, mountPath) {
+		return nil
+	}
+
+	if err := p.InitSyncStatus(ctx, sourceNamespace, sourcePVCName); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to initialize sync status")
+	}
+
+	tracing.Step(ctx, "backup")
+	// Back up the source PVC from the agent
+	log.WithFields(fields).WithField("agent_pod", agentPod.Name).Info(logging.LogTagDetail + " Backing up source PVC to object storage")
+	keep := p.ObjectStorage.KeepSnapshots
+	if keep < 1 {
+		keep = DefaultKeepSnapshots
+	}
+	stdout, stderr, err := execInPodWithStdin(ctx, p.SourceK8sClient, p.SourceConfig, agentPod.Namespace, agentPod.Name,
+		resticBackupCommand(mountPath, resticTag(sourceNamespace, sourcePVCName), keep), strings.NewReader(env))
+	if err != nil {
+		return fail("restic backup failed: %v: %s", err, strings.TrimSpace(stderr))
+	}
+	summary, err := parseResticBackupSummary(stdout)
+	if err != nil {
+		return fail("%v: %s", err, strings.TrimSpace(stderr))
+	}
+
+	tracing.Step(ctx, "restore")
+	// Restore the snapshot into the destination PVC
+	destPod, err := p.deployRsyncPod(ctx, destNamespace, destPVCName)
+	if err != nil {
+		return fail("failed to deploy restore pod in destination cluster: %v", err)
+	}
+	defer p.cleanupResources(ctx, destPod)
+	p.recordLockSync(ctx, sourceNamespace, sourcePVCName, destPod)
+
+	log.WithFields(fields).WithFields(logrus.Fields{
+		"pod_name": destPod.PodName,
+		"snapshot": summary.SnapshotID,
+	}).Info(logging.LogTagDetail + " Restoring snapshot into destination PVC")
+	if _, stderr, err := p.restoreSnapshot(ctx, destPod, summary.SnapshotID, env); err != nil {
+		return fail("restic restore of snapshot %s failed: %v: %s", summary.SnapshotID, err, strings.TrimSpace(stderr))
+	}
+
+	tracing.Step(ctx, "annotations")
+	if err := p.UpdateSourcePVCAnnotations(ctx, sourceNamespace, sourcePVCName); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to update source PVC annotations")
+	}
+	if err := p.recordDataSyncStart(ctx, sourceNamespace, sourcePVCName, startTime); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to record data sync start for change detection")
+	}
+	if err := p.CompleteSyncStatus(ctx, sourceNamespace, sourcePVCName, summary.DataAdded, summary.FilesNew+summary.FilesChanged); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to update sync status")
+	}
+
+	duration := time.Since(startTime)
+	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncCompleted,
+		"PVC data sync completed through object storage snapshot %s (duration: %s)", summary.SnapshotID, duration.Round(time.Second))
+	log.WithFields(fields).WithFields(logrus.Fields{
+		"snapshot": summary.SnapshotID,
+		"duration": duration.Round(time.Second),
+	}).Info(logging.LogTagComplete + " Object storage workflow completed successfully")
+	return nil
+}
+
+// restoreSnapshot runs restic restore of a snapshot in the destination pod mounting the destination PVC
+func (p *PVCSyncer) restoreSnapshot(ctx context.Context, destPod *rsyncpod.RsyncDeployment, snapshotID, env string) (string, string, error) {
+	return execInPodWithStdin(ctx, p.DestinationK8sClient, p.DestinationConfig, destPod.Namespace, destPod.PodName,
+		resticRestoreCommand(snapshotID), strings.NewReader(env))
+}
@@ -0,0 +1,56 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObjectStorageRepositoryEnvFile(t *testing.T) {
+	repository := &ObjectStorageRepository{
+		Repository: "s3:s3.amazonaws.com/dr-bucket/dr-syncer",
+		Env: map[string]string{
+			"RESTIC_PASSWORD":       "it's secret",
+			"AWS_ACCESS_KEY_ID":     "AKIA123",
+			"AWS_SECRET_ACCESS_KEY": "a$b`c",
+		},
+	}
+	env, err := repository.envFile()
+	require.NoError(t, err)
+	assert.Equal(t, "RESTIC_REPOSITORY='s3:s3.amazonaws.com/dr-bucket/dr-syncer'\n"+
+		"AWS_ACCESS_KEY_ID='AKIA123'\n"+
+		"AWS_SECRET_ACCESS_KEY='a$b`c'\n"+
+		"RESTIC_PASSWORD='it'\\''s secret'\n", env)
+
+	repository.Env["aws-region"] = "eu-west-1"
+	_, err = repository.envFile()
+	assert.ErrorContains(t, err, `credentials key "aws-region" is not a valid environment variable name`)
+}
+
+func TestResticCommands(t *testing.T) {
+	backup := resticBackupCommand("/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/pv/mount", resticTag("shop", "data"), 3)
+	assert.Equal(t, []string{"bash", "-c", "set -a && . /dev/stdin && set +a && " +
+		"(restic cat config >/dev/null 2>&1 || restic init >&2) && " +
+		"cd '/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/pv/mount' && " +
+		"restic backup --json --host dr-syncer --tag 'dr-syncer-pvc=shop/data' . && " +
+		"restic forget --host dr-syncer --tag 'dr-syncer-pvc=shop/data' --keep-last 3 --prune >&2"}, backup)
+
+	assert.Equal(t, []string{"bash", "-c", "set -a && . /dev/stdin && set +a && " +
+		"restic restore 'f3a1b2c4' --target /data --delete"}, resticRestoreCommand("f3a1b2c4"))
+}
+
+func TestParseResticBackupSummary(t *testing.T) {
+	output := `{"message_type":"status","percent_done":0.5,"total_files":10}
+not json
+{"message_type":"summary","files_new":2,"files_changed":1,"files_unmodified":7,"data_added":2048,"snapshot_id":"f3a1b2c4d5"}
+`
+	summary, err := parseResticBackupSummary(output)
+	require.NoError(t, err)
+	assert.Equal(t, "f3a1b2c4d5", summary.SnapshotID)
+	assert.Equal(t, 3, summary.FilesNew+summary.FilesChanged)
+	assert.Equal(t, int64(2048), summary.DataAdded)
+
+	_, err = parseResticBackupSummary(`{"message_type":"status","percent_done":1}`)
+	assert.ErrorContains(t, err, "did not report a snapshot")
+}
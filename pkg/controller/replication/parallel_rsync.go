@@ -0,0 +1,130 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+)
+
+// rsyncRshFormat is the remote shell rsync uses to reach the source agent, formatted with the SSH port
+const rsyncRshFormat = "ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i /root/.ssh/id_rsa -p %d"
+
+// listOnlyPattern matches an entry of rsync --list-only output: permissions, size, date, time and name
+var listOnlyPattern = regexp.MustCompile(`^(\S+)\s+\S+\s+\S+\s+\S+\s+(.+)$`)
+
+// parallelStreams returns the number of rsync streams configured for the data sync of a mapping
+func parallelStreams(nm *drv1alpha1.NamespaceMapping) int {
+	if nm == nil || nm.Spec.PVCConfig == nil || nm.Spec.PVCConfig.DataSyncConfig == nil ||
+		nm.Spec.PVCConfig.DataSyncConfig.ParallelStreams == nil || *nm.Spec.PVCConfig.DataSyncConfig.ParallelStreams < 1 {
+		return 1
+	}
+	return int(*nm.Spec.PVCConfig.DataSyncConfig.ParallelStreams)
+}
+
+// parseTopLevelDirs returns the directories listed by rsync --list-only, without the listed directory itself
+func parseTopLevelDirs(output string) []string {
+	var dirs []string
+	for _, line := range strings.Split(output, "\n") {
+		matches := listOnlyPattern.FindStringSubmatch(strings.TrimSpace(line))
+		if matches == nil || !strings.HasPrefix(matches[1], "d") || matches[2] == "." {
+			continue
+		}
+		dirs = append(dirs, matches[2])
+	}
+	return dirs
+}
+
+// splitRsyncStreams distributes directories round-robin over at most streams buckets
+func splitRsyncStreams(dirs []string, streams int) [][]string {
+	if streams > len(dirs) {
+		streams = len(dirs)
+	}
+	buckets := make([][]string, streams)
+	for i, dir := range dirs {
+		buckets[i%streams] = append(buckets[i%streams], dir)
+	}
+	return buckets
+}
+
+// shellQuote quotes a string for sh
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// rsyncPatternEscape escapes the wildcard characters of a name used in an rsync filter pattern
+func rsyncPatternEscape(name string) string {
+	return strings.NewReplacer(`\`, `\\`, `*`, `\*`, `?`, `\?`, `[`, `\[`).Replace(name)
+}
+
+// parallelRsyncCommands builds the shell commands of a parallel data sync. Each bucket of top-level
+// directories is copied by its own rsync, and a last rsync copies the remaining top-level entries.
+// The last one excludes the directories of the other streams so its --delete leaves them alone,
+// while directories removed from the source are still deleted. Every stream records its pid in
+// pidFile with the stream index appended.
+func parallelRsyncCommands(rsyncOpts string, sshPort int32, sourceInfo, destInfo, pidFile string, buckets [][]string) []string {
+	rsh := fmt.Sprintf(rsyncRshFormat, sshPort)
+	cmds := make([]string, 0, len(buckets)+1)
+
+	var excludes []string
+	for i, bucket := range buckets {
+		sources := make([]string, 0, len(bucket))
+		for _, dir := range bucket {
+			sources = append(sources, shellQuote(sourceInfo+dir))
+			excludes = append(excludes, shellQuote("--exclude=/"+rsyncPatternEscape(dir)+"/"))
+		}
+		cmds = append(cmds, fmt.Sprintf("echo $$ > %s.%d && exec rsync %s --rsh=\"%s\" %s %s",
+			pidFile, i, rsyncOpts, rsh, strings.Join(sources, " "), destInfo))
+	}
+
+	cmds = append(cmds, fmt.Sprintf("echo $$ > %s.%d && exec rsync %s %s --rsh=\"%s\" %s %s",
+		pidFile, len(buckets), rsyncOpts, strings.Join(excludes, " "), rsh, sourceInfo, destInfo))
+	return cmds
+}
+
+// runRsyncStreams runs every command concurrently and returns their outputs in command order.
+// All streams run to completion, and the errors of the failed ones are joined.
+func runRsyncStreams(cmds []string, run func(cmd string) (string, error)) ([]string, error) {
+	outputs := make([]string, len(cmds))
+	errs := make([]error, len(cmds))
+
+	var wg sync.WaitGroup
+	for i, cmd := range cmds {
+		wg.Add(1)
+		go func(i int, cmd string) {
+			defer wg.Done()
+			output, err := run(cmd)
+			outputs[i] = output
+			if err != nil {
+				errs[i] = fmt.Errorf("rsync stream %d: %w", i, err)
+			}
+		}(i, cmd)
+	}
+	wg.Wait()
+
+	return outputs, errors.Join(errs...)
+}
+
+// listTopLevelDirs lists the directories at the top of the source volume from the destination pod
+func (p *PVCSyncer) listTopLevelDirs(ctx context.Context, destDeployment *rsyncpod.RsyncDeployment, sshPort int32, sourceInfo string) ([]string, error) {
+	cmd := []string{"sh", "-c", fmt.Sprintf("rsync --list-only --rsh=\"%s\" %s", fmt.Sprintf(rsyncRshFormat, sshPort), sourceInfo)}
+	stdout, stderr, err := rsyncpod.ExecuteCommandInPod(context.WithValue(ctx, SyncerKey, p), p.DestinationK8sClient,
+		destDeployment.Namespace, destDeployment.PodName, cmd, p.DestinationConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source directories: %v: %s", err, stderr)
+	}
+
+	dirs := parseTopLevelDirs(stdout)
+	log.WithFields(logrus.Fields{
+		"source": sourceInfo,
+		"dirs":   len(dirs),
+	}).Debug(logging.LogTagDetail + " Listed top-level source directories")
+	return dirs, nil
+}
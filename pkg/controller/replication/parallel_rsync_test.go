@@ -0,0 +1,63 @@
+package replication
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestParallelStreams(t *testing.T) {
+	four := int32(4)
+	assert.Equal(t, 1, parallelStreams(nil))
+	assert.Equal(t, 1, parallelStreams(&drv1alpha1.NamespaceMapping{}))
+	assert.Equal(t, 4, parallelStreams(&drv1alpha1.NamespaceMapping{Spec: drv1alpha1.NamespaceMappingSpec{
+		PVCConfig: &drv1alpha1.PVCConfig{DataSyncConfig: &drv1alpha1.PVCDataSyncConfig{ParallelStreams: &four}},
+	}}))
+}
+
+func TestParseTopLevelDirs(t *testing.T) {
+	output := `drwxr-xr-x          4,096 2024/05/01 10:00:00 .
+-rw-r--r--            120 2024/05/01 10:00:00 config.yaml
+drwxr-xr-x          4,096 2024/05/01 10:00:00 base
+drwxr-xr-x          4,096 2024/05/01 10:00:00 pg wal
+lrwxrwxrwx             11 2024/05/01 10:00:00 current
+`
+	assert.Equal(t, []string{"base", "pg wal"}, parseTopLevelDirs(output))
+	assert.Empty(t, parseTopLevelDirs(""))
+}
+
+func TestSplitRsyncStreams(t *testing.T) {
+	assert.Equal(t, [][]string{{"a", "d"}, {"b", "e"}, {"c"}}, splitRsyncStreams([]string{"a", "b", "c", "d", "e"}, 3))
+	assert.Equal(t, [][]string{{"a"}, {"b"}}, splitRsyncStreams([]string{"a", "b"}, 8))
+}
+
+func TestParallelRsyncCommands(t *testing.T) {
+	cmds := parallelRsyncCommands("-avz --delete", 2222, "root@10.0.0.1:/mnt/data/", "/data/", "/tmp/sync.pid",
+		[][]string{{"base", "it's"}, {"logs*"}})
+
+	assert.Len(t, cmds, 3)
+	assert.True(t, strings.HasPrefix(cmds[0], "echo $$ > /tmp/sync.pid.0 && exec rsync -avz --delete --rsh=\"ssh "))
+	assert.True(t, strings.HasSuffix(cmds[0], `'root@10.0.0.1:/mnt/data/base' 'root@10.0.0.1:/mnt/data/it'\''s' /data/`))
+	assert.True(t, strings.HasSuffix(cmds[1], "'root@10.0.0.1:/mnt/data/logs*' /data/"))
+	assert.Contains(t, cmds[2], "/tmp/sync.pid.2")
+	assert.Contains(t, cmds[2], `'--exclude=/base/' '--exclude=/it'\''s/' '--exclude=/logs\*/'`)
+	assert.True(t, strings.HasSuffix(cmds[2], "root@10.0.0.1:/mnt/data/ /data/"))
+}
+
+func TestRunRsyncStreams(t *testing.T) {
+	outputs, err := runRsyncStreams([]string{"a", "b", "c"}, func(cmd string) (string, error) {
+		if cmd == "b" {
+			return "", fmt.Errorf("exit status 23")
+		}
+		return "synced " + cmd, nil
+	})
+
+	assert.Equal(t, []string{"synced a", "", "synced c"}, outputs)
+	assert.EqualError(t, err, "rsync stream 1: exit status 23")
+
+	_, err = runRsyncStreams([]string{"a"}, func(string) (string, error) { return "", nil })
+	assert.NoError(t, err)
+}
@@ -2,6 +2,7 @@ package replication
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/rpo"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -177,6 +179,28 @@ func (p *PVCSyncer) performSampleVerification(ctx context.Context, destDeploymen
 			continue
 		}
 
+		// In Daemon mode the key cannot run md5sum, so compare with a checksum dry-run against the module
+		if module, ok := GetRsyncDaemonModule(ctx); ok && module != "" {
+			compareCmd := []string{"sh", "-c", fmt.Sprintf(
+				"rsync -nc --out-format='%%n' --rsh=\"ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i /root/.ssh/id_rsa -p %d\" '%s' '%s'",
+				sshPort, rsyncSourceSpec(nodeIP, "", module)+strings.TrimPrefix(relPath, "/"), file)}
+			differing, _, err := rsyncpod.ExecuteCommandInPod(pvcCtx, p.DestinationK8sClient, destDeployment.Namespace, destDeployment.PodName, compareCmd, p.DestinationConfig)
+			if err != nil {
+				log.WithFields(logrus.Fields{
+					"file":  file,
+					"error": err,
+				}).Warn(logging.LogTagWarn + " Failed to compare file against rsync daemon module")
+				continue
+			}
+			if strings.TrimSpace(differing) != "" {
+				log.WithField("file", file).Warn(logging.LogTagWarn + " Checksum mismatch detected")
+				result.ChecksumMatch = false
+				result.Error = fmt.Sprintf("checksum mismatch for file: %s", file)
+			}
+			verified++
+			continue
+		}
+
 		// Get source checksum via SSH
 		sourceChecksumCmd := []string{"sh", "-c", fmt.Sprintf(
 			"ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i /root/.ssh/id_rsa -p %d root@%s \"md5sum '%s' 2>/dev/null | awk '{print \\$1}'\"",
@@ -272,7 +296,9 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 	defer cancel()
 
 	// Source and destination info for logs
-	sourceInfo := fmt.Sprintf("root@%s:%s/", nodeIP, mountPath)
+	// In Daemon mode the source is the read-only module allow-listed for this sync
+	module, _ := GetRsyncDaemonModule(ctx)
+	sourceInfo := rsyncSourceSpec(nodeIP, mountPath, module)
 
 	// Check if we're running in DaemonSet mode (destination path provided via context)
 	// In DaemonSet mode, we use the kubelet CSI path instead of /data/
@@ -313,6 +339,10 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 		"-avz",             // Archive mode, verbose, compress
 		"--info=progress2", // Show overall progress (streaming format)
 		"--delete",         // Delete files on destination that don't exist on source
+		"--stats",          // Print transfer statistics for the sync status and metrics
+	}
+	if p.restricted() {
+		rsyncOptions = append(rsyncOptions, rootlessRsyncOptions...)
 	}
 
 	// By default we won't use checksums for faster performance
@@ -404,6 +434,19 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 		}).Warn(logging.LogTagWarn + " Failed to update sync status, continuing anyway")
 	}
 
+	// Write the transferred files to the destination volume rather than the ephemeral storage of the pod,
+	// unless the custom options already choose where they go
+	var dataSyncConfig *drv1alpha1.PVCDataSyncConfig
+	if nmPtr != nil && nmPtr.Spec.PVCConfig != nil {
+		dataSyncConfig = nmPtr.Spec.PVCConfig.DataSyncConfig
+	}
+	var tempDir string
+	if dataSyncConfig == nil || !hasTempFileOption(dataSyncConfig.RsyncOptions) {
+		var tempFileOptions []string
+		tempFileOptions, tempDir = tempFileRsyncOptions(dataSyncConfig.GetTempFiles(), destInfo)
+		rsyncOptions = append(rsyncOptions, tempFileOptions...)
+	}
+
 	// Combine rsync options
 	rsyncOptsStr := strings.Join(rsyncOptions, " ")
 
@@ -447,11 +490,39 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 		}).Warn(logging.LogTagWarn + " Failed to list RemoteClusters, using default SSH port 2222")
 	}
 
+	// A temporary sshd pod in place of the agent listens on its own port
+	if port, ok := sshPortOverride(ctx); ok {
+		sshPort = port
+	}
+
 	// Build the rsync command to display output to pod's console
 	// Output goes directly to the pod's stdout/stderr without capturing
 	// This will show in the pod logs but not be returned to the controller
-	rsyncCmd := fmt.Sprintf("rsync %s --rsh=\"ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i /root/.ssh/id_rsa -p %d\" %s %s",
-		rsyncOptsStr, sshPort, sourceInfo, destInfo)
+	// The shell records its pid before exec'ing rsync so the process can be killed if the sync times out
+	pidFile := rsyncPIDFile(p.DestinationNamespace, destDeployment.PVCName)
+	rsyncCmd := fmt.Sprintf("echo $$ > %s && exec rsync %s --rsh=\"%s\" %s %s",
+		pidFile, rsyncOptsStr, fmt.Sprintf(rsyncRshFormat, sshPort), sourceInfo, destInfo)
+
+	// Huge volumes can be split into concurrent rsync streams over their top-level directories
+	var parallelCmds []string
+	if streams := parallelStreams(nmPtr); streams > 1 {
+		dirs, err := p.listTopLevelDirs(ctx, destDeployment, sshPort, sourceInfo)
+		switch {
+		case err != nil:
+			log.WithFields(logrus.Fields{
+				"error": err,
+			}).Warn(logging.LogTagWarn + " Failed to list source directories, falling back to a single rsync stream")
+		case len(dirs) == 0:
+			log.Debug(logging.LogTagDetail + " No top-level source directories, using a single rsync stream")
+		default:
+			parallelCmds = parallelRsyncCommands(rsyncOptsStr, sshPort, sourceInfo, destInfo, pidFile, splitRsyncStreams(dirs, streams))
+			log.WithFields(logrus.Fields{
+				"pvc":     destDeployment.PVCName,
+				"streams": len(parallelCmds),
+				"dirs":    len(dirs),
+			}).Info(logging.LogTagInfo + " Running parallel rsync streams")
+		}
+	}
 
 	entry = log.WithFields(logrus.Fields{
 		"rsync_cmd": rsyncCmd,
@@ -461,14 +532,19 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 	})
 	entry.Debug(logging.LogTagDetail + " Executing rsync command")
 
-	// Execute command in rsync pod
-	cmd := []string{"sh", "-c", rsyncCmd}
-
 	// Put the PVCSyncer in the context for ExecuteCommandInPod using our exported context key
 	pvcSyncCtx := context.WithValue(rsyncCtx, SyncerKey, p)
 
-	// Variable to store rsync output for parsing after successful execution
-	var rsyncOutput string
+	// rsync does not create its temp directory
+	if tempDir != "" {
+		cmd := []string{"mkdir", "-p", tempDir}
+		if _, stderr, err := rsyncpod.ExecuteCommandInPod(pvcSyncCtx, p.DestinationK8sClient, destDeployment.Namespace, destDeployment.PodName, cmd, p.DestinationConfig); err != nil {
+			return fmt.Errorf("failed to create rsync temp directory %s: %v, stderr: %s", tempDir, err, stderr)
+		}
+	}
+
+	// Outputs of the rsync streams for parsing after successful execution
+	var rsyncOutputs []string
 
 	// Mutex to protect shared state during progress updates
 	var progressMu sync.Mutex
@@ -550,41 +626,77 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 
 	// Execute with configurable retry logic for transient failures
 	// Uses RetryConfig from NamespaceMapping if available, otherwise uses defaults
-	err := withRetryConfig(ctx, retryConfig, func() error {
-		entry := log.WithFields(logrus.Fields{
-			"deployment":       destDeployment.Name,
-			"namespace":        destDeployment.Namespace,
-			"pod_name":         destDeployment.PodName,
-			"dest_client_host": p.DestinationConfig.Host,
-		})
-		entry.Debug(logging.LogTagDetail + " Executing rsync command with destination config")
-
-		// Execute command with a long timeout (rsync can take hours for large volumes)
-		// The 24-hour timeout from rsyncCtx applies here
-		stdout, stderr, execErr := rsyncpod.ExecuteCommandInPod(pvcSyncCtx, p.DestinationK8sClient, destDeployment.Namespace, destDeployment.PodName, cmd, p.DestinationConfig)
+	// Parallel streams retry independently of each other
+	runStream := func(rsyncCmd string) (string, error) {
+		var rsyncOutput string
+		err := withRetryConfig(ctx, retryConfig, func() error {
+			entry := log.WithFields(logrus.Fields{
+				"deployment":       destDeployment.Name,
+				"namespace":        destDeployment.Namespace,
+				"pod_name":         destDeployment.PodName,
+				"dest_client_host": p.DestinationConfig.Host,
+			})
+			entry.Debug(logging.LogTagDetail + " Executing rsync command with destination config")
+
+			// Execute command with a long timeout (rsync can take hours for large volumes)
+			// The 24-hour timeout from rsyncCtx applies here
+			cmd := []string{"sh", "-c", rsyncCmd}
+			stdout, stderr, execErr := rsyncpod.ExecuteCommandInPod(pvcSyncCtx, p.DestinationK8sClient, destDeployment.Namespace, destDeployment.PodName, cmd, p.DestinationConfig)
+
+			// Accumulate output for progress parsing
+			progressMu.Lock()
+			accumulatedOutput.WriteString(stdout)
+			progressMu.Unlock()
+
+			if execErr != nil {
+				// Classify the failure by the exit code of rsync, retrying network errors, timeouts and
+				// vanished source files
+				if rsyncErr := classifyRsyncError(execErr, stderr); rsyncErr != nil {
+					if rsyncErr.Class == RsyncFailureVanishedFiles {
+						rsyncOutput = stdout
+					}
+					if rsyncErr.Retryable() {
+						return &RetryableError{Err: rsyncErr}
+					}
+					return rsyncErr
+				}
 
-		// Accumulate output for progress parsing
-		progressMu.Lock()
-		accumulatedOutput.WriteString(stdout)
-		progressMu.Unlock()
+				// Use expanded error classification for transient detection
+				if isTransientError(execErr, "") {
+					return &RetryableError{Err: fmt.Errorf("transient error during rsync: %v", execErr)}
+				}
+				return execErr
+			}
 
-		if execErr != nil {
-			// Use expanded error classification for transient detection
-			if isTransientError(execErr, "") {
-				return &RetryableError{Err: fmt.Errorf("transient error during rsync: %v", execErr)}
+			// Check stderr for SSH/rsync specific transient errors
+			if isTransientError(nil, stderr) {
+				return &RetryableError{Err: fmt.Errorf("transient connection error in rsync: %s", stderr)}
 			}
-			return execErr
-		}
 
-		// Check stderr for SSH/rsync specific transient errors
-		if isTransientError(nil, stderr) {
-			return &RetryableError{Err: fmt.Errorf("transient connection error in rsync: %s", stderr)}
+			// Store stdout for parsing after successful execution
+			rsyncOutput = stdout
+			return nil
+		})
+
+		// Files deleted from a live volume during every attempt do not fail the sync, all others were copied
+		if rsyncErr, ok := AsRsyncError(err); ok && rsyncErr.Class == RsyncFailureVanishedFiles {
+			log.WithFields(logrus.Fields{
+				"pvc":   destDeployment.PVCName,
+				"error": err,
+			}).Warn(logging.LogTagWarn + " Source files vanished during every rsync attempt, accepting the transfer")
+			return rsyncOutput, nil
 		}
+		return rsyncOutput, err
+	}
 
-		// Store stdout for parsing after successful execution
-		rsyncOutput = stdout
-		return nil
-	})
+	var err error
+	if len(parallelCmds) > 0 {
+		rsyncOutputs, err = runRsyncStreams(parallelCmds, runStream)
+	} else {
+		var rsyncOutput string
+		rsyncOutput, err = runStream(rsyncCmd)
+		rsyncOutputs = []string{rsyncOutput}
+	}
 
 	// Stop the progress update goroutine
 	progressCancel()
@@ -596,25 +708,46 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 		})
 		errorEntry.Error(logging.LogTagError + " Rsync command failed after retries")
 
+		// Closing the exec stream does not stop rsync in the pod, so kill it when the sync deadline expired
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			if len(parallelCmds) == 0 {
+				p.killRemoteRsync(destDeployment, pidFile)
+			}
+			for i := range parallelCmds {
+				p.killRemoteRsync(destDeployment, fmt.Sprintf("%s.%d", pidFile, i))
+			}
+		}
+
 		// Record failure metrics
 		syncDuration := time.Since(syncStartTime).Seconds()
 		RecordSyncFailure(p.SourceNamespace, destDeployment.PVCName, p.DestinationNamespace, syncDuration)
+		if rsyncErr, ok := AsRsyncError(err); ok {
+			RecordRsyncFailure(p.SourceNamespace, destDeployment.PVCName, p.DestinationNamespace, rsyncErr.Class)
+		}
 
 		// Update status to failed
 		p.FailedSyncStatus(ctx, p.SourceNamespace, destDeployment.PVCName, err)
 
-		return fmt.Errorf("rsync command failed: %v", err)
+		if isNoSpaceError(err) {
+			p.RecordWarningEvent(ctx, p.SourceNamespace, destDeployment.PVCName, EventReasonNoSpace,
+				"Rsync to %s/%s aborted, no space left on device. Increase the destination PVC size or the rsync pod ephemeral storage",
+				p.DestinationNamespace, destDeployment.PVCName)
+		} else if rsyncErr, ok := AsRsyncError(err); ok {
+			p.RecordWarningEvent(ctx, p.SourceNamespace, destDeployment.PVCName, rsyncErr.EventReason(),
+				"Rsync to %s/%s failed with exit code %d (%s failure)", p.DestinationNamespace, destDeployment.PVCName,
+				rsyncErr.ExitCode, rsyncErr.Class)
+		}
+
+		return fmt.Errorf("rsync command failed: %w", err)
 	}
 
 	// Suppress unused variable warning for latestProgress (used in goroutine)
 	_ = latestProgress
 
-	// Parse rsync output to get actual transfer statistics
-	bytesTransferred, filesTransferred, _, parseErr := ParseRsyncOutput(rsyncOutput)
-	if parseErr != nil {
-		log.WithField("error", parseErr).Warn(logging.LogTagWarn + " Failed to parse rsync output, using defaults")
-		bytesTransferred = 0
-		filesTransferred = 0
+	// Parse the rsync --stats output to get actual transfer statistics, summed over all streams
+	var stats RsyncStats
+	for _, rsyncOutput := range rsyncOutputs {
+		stats.Add(ParseRsyncStats(rsyncOutput))
 	}
 
 	entry = log.WithFields(logrus.Fields{
@@ -681,18 +814,27 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 
 	// Record success metrics
 	syncDuration := time.Since(syncStartTime).Seconds()
+	rpo.RecordPVCSync(p.SourceNamespace, destDeployment.PVCName, p.DestinationNamespace, syncStartTime)
 	RecordSyncComplete(
 		p.SourceNamespace,
 		destDeployment.PVCName,
 		p.DestinationNamespace,
-		bytesTransferred,
-		filesTransferred,
+		stats.BytesSent,
+		stats.FilesTransferred,
 		syncDuration,
 		true, // success
 	)
+	RecordSyncStats(p.SourceNamespace, destDeployment.PVCName, p.DestinationNamespace, stats)
+
+	// Remember when this sync started so unchanged PVCs can be skipped next time
+	if err := p.recordDataSyncStart(ctx, p.SourceNamespace, destDeployment.PVCName, syncStartTime); err != nil {
+		log.WithFields(logrus.Fields{
+			"error": err,
+		}).Warn(logging.LogTagWarn + " Failed to record data sync start time, continuing anyway")
+	}
 
 	// Update status to completed with verification result
-	if err := p.CompleteSyncStatusWithVerification(ctx, p.SourceNamespace, destDeployment.PVCName, bytesTransferred, filesTransferred, verificationResult); err != nil {
+	if err := p.CompleteSyncStatusWithVerification(ctx, p.SourceNamespace, destDeployment.PVCName, stats, verificationResult); err != nil {
 		warnEntry := log.WithFields(logrus.Fields{
 			"error": err,
 		})
@@ -701,8 +843,10 @@ func (p *PVCSyncer) performRsync(ctx context.Context, destDeployment *rsyncpod.R
 
 	log.WithFields(logrus.Fields{
 		"pvc":               destDeployment.PVCName,
-		"bytes_transferred": bytesTransferred,
-		"files_transferred": filesTransferred,
+		"bytes_transferred": stats.BytesSent,
+		"files_transferred": stats.FilesTransferred,
+		"total_size":        stats.TotalSize,
+		"speedup":           stats.Speedup(),
 		"duration_seconds":  syncDuration,
 	}).Info(logging.LogTagInfo + " PVC sync completed successfully")
 
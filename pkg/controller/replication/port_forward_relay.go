@@ -0,0 +1,259 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/tools/remotecommand"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	// portForwardRelayPort is the port the relay listens on in the destination rsync pod
+	portForwardRelayPort int32 = 2222
+
+	// relayListenerTimeout bounds the wait for the first relay listener in the destination rsync pod
+	relayListenerTimeout = 30 * time.Second
+
+	// relayRetryInterval spaces out relay listeners that failed without carrying a connection
+	relayRetryInterval = time.Second
+)
+
+// relayListenCommand accepts a single connection on the relay port of the destination rsync pod and
+// connects it to the stdin and stdout of the exec, closing it once stdin ends
+func relayListenCommand(port int32) []string {
+	return []string{"nc", "-N", "-l", "127.0.0.1", fmt.Sprintf("%d", port)}
+}
+
+// relayListeningCommand succeeds once the relay port of the destination rsync pod is listening,
+// read from /proc since the rsync image ships no socket tools
+func relayListeningCommand(port int32) []string {
+	return []string{"grep", "-q", fmt.Sprintf(":%04X 00000000:0000 0A", port), "/proc/net/tcp"}
+}
+
+// portForwardRelay tunnels the SSH connections of the destination rsync pod to a temporary sshd pod in
+// the source cluster through the controller, for clusters that can only reach each other through their
+// API servers. The controller port-forwards the sshd pod and keeps a netcat listener running in the
+// destination rsync pod, piping every connection it accepts to the port-forward.
+type portForwardRelay struct {
+	p         *PVCSyncer
+	destPod   *rsyncpod.RsyncDeployment
+	localAddr string
+	stopCh    chan struct{}
+	cancel    context.CancelFunc
+	done      chan struct{}
+}
+
+// startPortForwardRelay forwards the sshd port of sshdPod to the controller and serves it on
+// portForwardRelayPort of the destination rsync pod until Stop is called
+func (p *PVCSyncer) startPortForwardRelay(ctx context.Context, sshdPod *corev1.Pod, destPod *rsyncpod.RsyncDeployment) (*portForwardRelay, error) {
+	req := p.SourceK8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Namespace(sshdPod.Namespace).
+		Name(sshdPod.Name).
+		SubResource("portforward")
+
+	transport, upgrader, err := spdy.RoundTripperFor(p.SourceConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create port-forward transport: %v", err)
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"},
+		[]string{fmt.Sprintf("0:%d", sourceSSHDPort)}, stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create port-forward to %s/%s: %v", sshdPod.Namespace, sshdPod.Name, err)
+	}
+
+	forwardErr := make(chan error, 1)
+	go func() {
+		forwardErr <- forwarder.ForwardPorts()
+	}()
+	select {
+	case <-readyCh:
+	case err := <-forwardErr:
+		return nil, fmt.Errorf("failed to port-forward %s/%s: %v", sshdPod.Namespace, sshdPod.Name, err)
+	case <-ctx.Done():
+		close(stopCh)
+		return nil, ctx.Err()
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil || len(ports) == 0 {
+		close(stopCh)
+		return nil, fmt.Errorf("failed to get the local port of the port-forward to %s/%s: %v", sshdPod.Namespace, sshdPod.Name, err)
+	}
+
+	relayCtx, cancel := context.WithCancel(ctx)
+	r := &portForwardRelay{
+		p:         p,
+		destPod:   destPod,
+		localAddr: fmt.Sprintf("127.0.0.1:%d", ports[0].Local),
+		stopCh:    stopCh,
+		cancel:    cancel,
+		done:      make(chan struct{}),
+	}
+	go r.serve(relayCtx)
+
+	if err := r.waitForListener(ctx); err != nil {
+		r.Stop()
+		return nil, err
+	}
+
+	log.WithFields(logrus.Fields{
+		"sshd_pod":   sshdPod.Name,
+		"dest_pod":   destPod.PodName,
+		"local_addr": r.localAddr,
+		"relay_port": portForwardRelayPort,
+	}).Info(logging.LogTagDetail + " Relaying SSH connections of the rsync pod through the controller")
+	return r, nil
+}
+
+// Stop closes the relay listener, the relayed connections and the port-forward
+func (r *portForwardRelay) Stop() {
+	r.cancel()
+	<-r.done
+	close(r.stopCh)
+}
+
+// serve keeps a relay listener running in the destination rsync pod, starting the next one as soon as
+// the current one accepted a connection so parallel connections are relayed as well
+func (r *portForwardRelay) serve(ctx context.Context) {
+	defer close(r.done)
+	for ctx.Err() == nil {
+		accepted := make(chan struct{})
+		finished := make(chan error, 1)
+		go func() {
+			finished <- r.relayConnection(ctx, accepted)
+		}()
+
+		select {
+		case <-accepted:
+		case err := <-finished:
+			if err != nil && ctx.Err() == nil {
+				log.WithFields(logrus.Fields{
+					"dest_pod": r.destPod.PodName,
+					"error":    err,
+				}).Warn(logging.LogTagWarn + " Relay listener failed, restarting it")
+				select {
+				case <-time.After(relayRetryInterval):
+				case <-ctx.Done():
+				}
+			}
+		case <-ctx.Done():
+		}
+	}
+}
+
+// waitForListener waits until the first relay listener is accepting connections
+func (r *portForwardRelay) waitForListener(ctx context.Context) error {
+	syncerCtx := context.WithValue(ctx, syncerKey, r.p)
+	err := wait.PollUntilContextTimeout(ctx, time.Second, relayListenerTimeout, true, func(context.Context) (bool, error) {
+		_, _, err := rsyncpod.ExecuteCommandInPod(syncerCtx, r.p.DestinationK8sClient, r.destPod.Namespace, r.destPod.PodName,
+			relayListeningCommand(portForwardRelayPort), r.p.DestinationConfig)
+		return err == nil, nil
+	})
+	if err != nil {
+		return fmt.Errorf("relay listener not started in rsync pod %s: %v", r.destPod.PodName, err)
+	}
+	return nil
+}
+
+// relayConnection runs one relay listener and pipes the connection it accepts to the port-forward.
+// accepted is closed once the connection carries data.
+func (r *portForwardRelay) relayConnection(ctx context.Context, accepted chan struct{}) error {
+	stdin, stdinWriter := io.Pipe()
+	conn := &relayConn{addr: r.localAddr, stdin: stdinWriter, accepted: accepted}
+	defer conn.Close()
+
+	return streamInPod(ctx, r.p.DestinationK8sClient, r.p.DestinationConfig, r.destPod.Namespace, r.destPod.PodName,
+		relayListenCommand(portForwardRelayPort), stdin, conn)
+}
+
+// relayConn connects the output of a relay listener to the port-forward when the first bytes arrive,
+// and copies the port-forward back to the listener's stdin
+type relayConn struct {
+	addr     string
+	stdin    *io.PipeWriter
+	accepted chan struct{}
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+// Write implements io.Writer
+func (c *relayConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		conn, err := net.Dial("tcp", c.addr)
+		if err != nil {
+			return 0, fmt.Errorf("failed to connect to the port-forward: %v", err)
+		}
+		c.conn = conn
+		close(c.accepted)
+		go func() {
+			_, err := io.Copy(c.stdin, conn)
+			c.stdin.CloseWithError(err)
+		}()
+	}
+	return c.conn.Write(b)
+}
+
+// Close closes the port-forward connection and the listener's stdin
+func (c *relayConn) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn != nil {
+		c.conn.Close()
+	}
+	c.stdin.Close()
+}
+
+// streamInPod runs a command in a pod, streaming stdin to it and its stdout to stdout
+func streamInPod(ctx context.Context, k8sClient kubernetes.Interface, config *rest.Config, namespace, podName string, command []string, stdin io.Reader, stdout io.Writer) error {
+	req := k8sClient.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(podName).
+		Namespace(namespace).
+		SubResource("exec")
+
+	req.VersionedParams(&corev1.PodExecOptions{
+		Command: command,
+		Stdin:   true,
+		Stdout:  true,
+		Stderr:  true,
+		TTY:     false,
+	}, scheme.ParameterCodec)
+
+	exec, err := util.NewPodExecutor(config, req.URL())
+	if err != nil {
+		return err
+	}
+
+	return exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: io.Discard,
+		Tty:    false,
+	})
+}
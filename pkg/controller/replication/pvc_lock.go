@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -18,6 +19,17 @@ const (
 	// LockAnnotation is the annotation used to indicate a PVC is being synced
 	LockAnnotation = "dr-syncer.io/replication-lock"
 
+	// LockOwnerAnnotation names the controller pod holding the data sync lock of a source PVC
+	LockOwnerAnnotation = "dr-syncer.io/lock-owner"
+
+	// LockTimestampAnnotation records when the data sync lock of a source PVC was taken
+	LockTimestampAnnotation = "dr-syncer.io/lock-timestamp"
+
+	// LockSyncAnnotation records the destination namespace and sync ID of the rsync deployment of the sync
+	// holding the lock on a source PVC, as <namespace>/<sync-id>, so a controller taking over a stale lock
+	// can stop it
+	LockSyncAnnotation = "dr-syncer.io/lock-sync"
+
 	// DefaultLockTimeout is the default timeout for a lock (in minutes)
 	DefaultLockTimeout = 60
 )
@@ -87,6 +99,28 @@ func GetLockTimeout() time.Duration {
 	return DefaultLockTimeout * time.Minute
 }
 
+// StaleLockTimeout returns the age after which the data sync lock of a source PVC is taken over: the
+// configured dataSyncConfig.staleLockTimeout when set, the controller's lock timeout otherwise
+func StaleLockTimeout(configured *metav1.Duration) time.Duration {
+	if configured != nil && configured.Duration > 0 {
+		return configured.Duration
+	}
+	return GetLockTimeout()
+}
+
+// LockHeld reports whether pvc holds a data sync lock that is not stale at now. A lock without a valid
+// timestamp is never taken over, so it is held.
+func LockHeld(pvc *corev1.PersistentVolumeClaim, staleAfter time.Duration, now time.Time) bool {
+	if pvc.Annotations[LockOwnerAnnotation] == "" {
+		return false
+	}
+	lockTime, err := time.Parse(time.RFC3339, pvc.Annotations[LockTimestampAnnotation])
+	if err != nil {
+		return true
+	}
+	return now.Sub(lockTime) <= staleAfter
+}
+
 // CleanupOrphanedRsyncDeployments finds and cleans up orphaned rsync deployments
 func (p *PVCSyncer) CleanupOrphanedRsyncDeployments(ctx context.Context, k8sClient kubernetes.Interface, namespace string) error {
 	log.WithFields(logrus.Fields{
@@ -0,0 +1,36 @@
+package replication
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestLockHeld(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	lockedAt := func(ts string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{
+			LockOwnerAnnotation:     "dr-syncer-controller-0",
+			LockTimestampAnnotation: ts,
+		}}}
+	}
+
+	assert.False(t, LockHeld(&corev1.PersistentVolumeClaim{}, time.Hour, now))
+	assert.True(t, LockHeld(lockedAt("2026-01-01T11:30:00Z"), time.Hour, now))
+	assert.False(t, LockHeld(lockedAt("2026-01-01T10:30:00Z"), time.Hour, now), "stale locks are not held")
+	assert.True(t, LockHeld(lockedAt("2026-01-01T10:30:00Z"), 2*time.Hour, now), "the stale age is configurable")
+	assert.True(t, LockHeld(lockedAt(""), time.Hour, now), "locks without a timestamp are never stale")
+}
+
+func TestStaleLockTimeout(t *testing.T) {
+	t.Setenv("LOCK_TIMEOUT_MINUTES", "")
+	assert.Equal(t, time.Hour, StaleLockTimeout(nil))
+	assert.Equal(t, time.Hour, StaleLockTimeout(&metav1.Duration{}))
+	assert.Equal(t, 3*time.Hour, StaleLockTimeout(&metav1.Duration{Duration: 3 * time.Hour}))
+
+	t.Setenv("LOCK_TIMEOUT_MINUTES", "90")
+	assert.Equal(t, 90*time.Minute, StaleLockTimeout(nil))
+}
@@ -0,0 +1,61 @@
+package replication
+
+import (
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+// DestinationPVCName returns the name a source PVC should have in the destination cluster.
+// Mappings are evaluated in order and the first match wins; without a match the source
+// name is returned unchanged.
+func DestinationPVCName(mappings []drv1alpha1.PVCMapping, sourceName string) (string, error) {
+	for _, mapping := range mappings {
+		if !mapping.Regex {
+			if mapping.From == sourceName {
+				return mapping.To, nil
+			}
+			continue
+		}
+
+		re, err := regexp.Compile("^(?:" + mapping.From + ")$")
+		if err != nil {
+			return "", fmt.Errorf("invalid PVC mapping pattern %q: %v", mapping.From, err)
+		}
+		if match := re.FindStringSubmatchIndex(sourceName); match != nil {
+			return string(re.ExpandString(nil, mapping.To, sourceName, match)), nil
+		}
+	}
+	return sourceName, nil
+}
+
+// PVCMappingsFor returns the PVC mappings configured on a NamespaceMapping
+func PVCMappingsFor(mapping *drv1alpha1.NamespaceMapping) []drv1alpha1.PVCMapping {
+	if mapping == nil || mapping.Spec.PVCConfig == nil {
+		return nil
+	}
+	return mapping.Spec.PVCConfig.PVCMappings
+}
+
+// RewritePodSpecPVCNames points volumes of a pod spec that reference renamed PVCs at their
+// destination names
+func RewritePodSpecPVCNames(spec *corev1.PodSpec, mappings []drv1alpha1.PVCMapping) error {
+	if len(mappings) == 0 {
+		return nil
+	}
+	for i := range spec.Volumes {
+		claim := spec.Volumes[i].PersistentVolumeClaim
+		if claim == nil {
+			continue
+		}
+		name, err := DestinationPVCName(mappings, claim.ClaimName)
+		if err != nil {
+			return err
+		}
+		claim.ClaimName = name
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestDestinationPVCName(t *testing.T) {
+	mappings := []drv1alpha1.PVCMapping{
+		{From: "data", To: "data-dr"},
+		{From: "cache-(.*)", To: "dr-cache-$1", Regex: true},
+		{From: "cache-.*", To: "unreachable", Regex: true},
+	}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"data", "data-dr"},
+		{"cache-redis", "dr-cache-redis"},
+		{"logs", "logs"},
+		// Regex patterns are anchored to the whole name
+		{"old-cache-redis", "old-cache-redis"},
+		{"data-2", "data-2"},
+	}
+
+	for _, tt := range tests {
+		got, err := DestinationPVCName(mappings, tt.source)
+		assert.NoError(t, err)
+		assert.Equal(t, tt.want, got, tt.source)
+	}
+
+	got, err := DestinationPVCName(nil, "data")
+	assert.NoError(t, err)
+	assert.Equal(t, "data", got)
+}
+
+func TestDestinationPVCName_InvalidPattern(t *testing.T) {
+	_, err := DestinationPVCName([]drv1alpha1.PVCMapping{{From: "data-(", To: "x", Regex: true}}, "data-1")
+	assert.Error(t, err)
+}
+
+func TestRewritePodSpecPVCNames(t *testing.T) {
+	spec := &corev1.PodSpec{
+		Volumes: []corev1.Volume{
+			{Name: "data", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "data"},
+			}},
+			{Name: "config", VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{},
+			}},
+			{Name: "logs", VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "logs"},
+			}},
+		},
+	}
+
+	err := RewritePodSpecPVCNames(spec, []drv1alpha1.PVCMapping{{From: "data", To: "data-dr"}})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "data-dr", spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.Equal(t, "logs", spec.Volumes[2].PersistentVolumeClaim.ClaimName)
+}
@@ -23,6 +23,8 @@ import (
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
+	"github.com/supporttools/dr-syncer/pkg/util"
 )
 
 // Import ReplicationMode constants
@@ -119,6 +121,32 @@ type PVCSyncer struct {
 
 	// RsyncDaemonSetConfig is the configuration for the rsync DaemonSet pool
 	RsyncDaemonSetConfig *drv1alpha1.RsyncDaemonSetConfig
+
+	// SecurityProfile is the rsync pod security profile of the destination RemoteCluster
+	SecurityProfile drv1alpha1.RsyncSecurityProfile
+
+	// EphemeralStorage overrides the default ephemeral storage of the destination rsync pods
+	EphemeralStorage *drv1alpha1.EphemeralStorageConfig
+
+	// ObjectStorage transfers PVC data through a restic repository instead of rsync over SSH when set
+	ObjectStorage *ObjectStorageRepository
+
+	// Strategy selects how the destination rsync pod reaches the source PVC data, the agent when empty
+	Strategy drv1alpha1.PVCDataSyncStrategy
+
+	// SyncUnmounted syncs source PVCs that no pod mounts by mounting them in a temporary source pod
+	SyncUnmounted bool
+
+	// AutoGrowDestination grows destination PVCs smaller than the data of their source PVC before the data
+	// sync when their StorageClass allows volume expansion
+	AutoGrowDestination bool
+
+	// StaleLockTimeout is the age after which another controller's lock on a source PVC is taken over,
+	// GetLockTimeout when zero
+	StaleLockTimeout time.Duration
+
+	// Agentless streams PVC data out of a source cluster without the agent through tar over the exec API
+	Agentless bool
 }
 
 // CreateEventRecorderForCluster creates an EventRecorder for emitting events to a Kubernetes cluster
@@ -188,6 +216,10 @@ func (e *RetryableError) Error() string {
 	return e.Err.Error()
 }
 
+func (e *RetryableError) Unwrap() error {
+	return e.Err
+}
+
 // withRetry executes a function with retries
 func withRetry(ctx context.Context, maxRetries int, backoff time.Duration, operation func() error) error {
 	var err error
@@ -219,7 +251,7 @@ func withRetry(ctx context.Context, maxRetries int, backoff time.Duration, opera
 		}
 	}
 
-	return fmt.Errorf("operation failed after %d attempts: %v", maxRetries, err)
+	return fmt.Errorf("operation failed after %d attempts: %w", maxRetries, err)
 }
 
 // withRetryConfig performs operation with retry using CRD configuration
@@ -301,7 +333,7 @@ func withRetryConfig(ctx context.Context, config *drv1alpha1.RetryConfig, operat
 		currentBackoff = nextBackoff
 	}
 
-	return fmt.Errorf("operation failed after %d attempts: %v", maxRetries, err)
+	return fmt.Errorf("operation failed after %d attempts: %w", maxRetries, err)
 }
 
 // executeCommandInPod executes a command in a pod
@@ -429,13 +461,13 @@ func executeCommandInPod(ctx context.Context, client kubernetes.Interface, names
 		"url": req.URL().String(),
 	}).Debug("[DR-SYNC-EXEC] Preparing execution URL")
 
-	// Create a SPDY executor
-	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	// Create the exec executor, streaming over WebSocket with an SPDY fallback
+	executor, err := util.NewPodExecutor(config, req.URL())
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"error": err,
-		}).Error("[DR-SYNC-ERROR] Failed to create SPDY executor")
-		return "", "", &RetryableError{Err: fmt.Errorf("failed to create SPDY executor: %v", err)}
+		}).Error("[DR-SYNC-ERROR] Failed to create exec executor")
+		return "", "", &RetryableError{Err: fmt.Errorf("failed to create exec executor: %v", err)}
 	}
 
 	// Create buffers for stdout and stderr
@@ -484,16 +516,14 @@ func executeCommandInPod(ctx context.Context, client kubernetes.Interface, names
 	return stdout.String(), stderr.String(), nil
 }
 
-// AddPublicKeyToSourceAgent adds a public key to the agent in the source cluster
+// AddPublicKeyToSourceAgent declares a public key for the agents in the source cluster
 func (p *PVCSyncer) AddPublicKeyToSourceAgent(ctx context.Context, publicKey, trackingInfo string) error {
 	log.WithFields(logrus.Fields{
 		"tracking_info": trackingInfo,
 	}).Info("[DR-SYNC] Adding public key to agent in source cluster")
 
 	// Find the agent pod in the source cluster
-	podList, err := p.SourceK8sClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{
-		LabelSelector: "app.kubernetes.io/name=dr-syncer-agent",
-	})
+	podList, err := listAgentPods(ctx, p.SourceK8sClient, "app.kubernetes.io/name=dr-syncer-agent")
 	if err != nil {
 		return fmt.Errorf("failed to list agent pods in source cluster: %v", err)
 	}
@@ -509,38 +539,18 @@ func (p *PVCSyncer) AddPublicKeyToSourceAgent(ctx context.Context, publicKey, tr
 		"namespace": agentPod.Namespace,
 	}).Info("[DR-SYNC] Found agent pod in source cluster")
 
-	// Create command to add the public key to the agent's authorized_keys file
-	cmd := []string{
-		"sh",
-		"-c",
-		fmt.Sprintf("mkdir -p /root/.ssh && echo '%s %s' >> /root/.ssh/authorized_keys && chmod 600 /root/.ssh/authorized_keys",
-			publicKey, trackingInfo),
-	}
-
-	// Put the PVCSyncer in the context for ExecuteCommandInPod
-	pvcSyncCtx := context.WithValue(ctx, syncerKey, p)
-
-	// Execute the command in agent pod with context that includes PVCSyncer
-	log.WithFields(logrus.Fields{
-		"agent_pod":          agentPod.Name,
-		"namespace":          agentPod.Namespace,
-		"source_config_host": p.SourceConfig.Host,
-	}).Info("[DR-SYNC-DETAIL] Executing command with source config context")
-
-	stdout, stderr, err := executeCommandInPod(pvcSyncCtx, p.SourceK8sClient, agentPod.Namespace, agentPod.Name, cmd)
-	if err != nil {
+	// Declare the key for the agents, the leader agent distributes it to all of them
+	entry := []byte(fmt.Sprintf("# %s\n%s\n", trackingInfo, strings.TrimSpace(publicKey)))
+	if err := ssh.ApplyAuthorizedKeySource(ctx, p.SourceK8sClient, agentPod.Namespace, trackingInfo, entry, syncKeyTTL); err != nil {
 		log.WithFields(logrus.Fields{
-			"stderr": stderr,
-			"error":  err,
-		}).Error("[DR-SYNC-ERROR] Failed to add public key to agent pod")
+			"error": err,
+		}).Error("[DR-SYNC-ERROR] Failed to declare public key for the agents")
 		return fmt.Errorf("failed to add public key to agent pod: %v", err)
 	}
 
 	log.WithFields(logrus.Fields{
-		"pod":       agentPod.Name,
 		"namespace": agentPod.Namespace,
-		"stdout":    stdout,
-	}).Info("[DR-SYNC] Successfully added public key to agent pod")
+	}).Info("[DR-SYNC] Successfully declared public key for the agents")
 
 	return nil
 }
@@ -897,7 +907,8 @@ func (p *PVCSyncer) UseRsyncDaemonSet() bool {
 	// DaemonSet is enabled if:
 	// 1. RsyncDaemonSet manager is set, AND
 	// 2. RsyncDaemonSetConfig is either nil (defaults to enabled) or explicitly enabled
-	if p.RsyncDaemonSet == nil {
+	// The pool pods mount kubelet host paths privileged, which the restricted profile forbids
+	if p.RsyncDaemonSet == nil || p.restricted() {
 		return false
 	}
 	return p.RsyncDaemonSetConfig.IsEnabled()
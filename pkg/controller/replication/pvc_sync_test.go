@@ -8,6 +8,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
@@ -484,3 +485,11 @@ func TestWithRetryConfig_InvalidDurationUsesDefault(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 2, callCount, "Should work with invalid durations, using defaults")
 }
+
+func TestUseRsyncDaemonSetRestricted(t *testing.T) {
+	p := &PVCSyncer{RsyncDaemonSet: &rsyncpod.RsyncDaemonSet{}}
+	assert.True(t, p.UseRsyncDaemonSet())
+
+	p.SecurityProfile = drv1alpha1.RsyncSecurityProfileRestricted
+	assert.False(t, p.UseRsyncDaemonSet(), "the privileged pool cannot run under the restricted profile")
+}
@@ -0,0 +1,30 @@
+package replication
+
+import (
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+)
+
+// rootlessRsyncOptions are added to rsync run by a non-root destination pod. It cannot chown or
+// create device files, so with --fake-super the source ownership, modes and special files are
+// stored in user.rsync.% extended attributes and restored on a later rsync with --fake-super.
+// --numeric-ids keeps the recorded IDs independent of the users known to the rsync image.
+var rootlessRsyncOptions = []string{
+	"--numeric-ids",
+	"--fake-super",
+}
+
+// rsyncPodOptions sets the security profile and ephemeral storage of the destination rsync pods in opts
+func (p *PVCSyncer) rsyncPodOptions(opts rsyncpod.RsyncPodOptions) rsyncpod.RsyncPodOptions {
+	opts.Restricted = p.restricted()
+	if p.EphemeralStorage != nil {
+		opts.EphemeralStorageRequest = p.EphemeralStorage.Request
+		opts.EphemeralStorageLimit = p.EphemeralStorage.Limit
+	}
+	return opts
+}
+
+// restricted returns true if the destination rsync pods run under the restricted security profile
+func (p *PVCSyncer) restricted() bool {
+	return p.SecurityProfile == drv1alpha1.RsyncSecurityProfileRestricted
+}
@@ -0,0 +1,101 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+// rsyncDaemonModuleDir is the agent directory the rsync daemon includes module configs from
+const rsyncDaemonModuleDir = "/etc/dr-syncer/rsyncd.d"
+
+// rsyncDaemonModuleKeyType is the type for the rsync daemon module context key
+type rsyncDaemonModuleKeyType string
+
+// rsyncDaemonModuleKey is the context key for storing the allow-listed rsync daemon module
+const rsyncDaemonModuleKey rsyncDaemonModuleKeyType = "rsyncDaemonModule"
+
+// GetRsyncDaemonModule retrieves the rsync daemon module allow-listed for this sync from context
+func GetRsyncDaemonModule(ctx context.Context) (string, bool) {
+	module, ok := ctx.Value(rsyncDaemonModuleKey).(string)
+	return module, ok
+}
+
+// rsyncDaemonModuleConfig renders the agent config allow-listing mountPath as a read-only module
+func rsyncDaemonModuleConfig(module, mountPath string) string {
+	return fmt.Sprintf("[%s]\n    path = %s\n    read only = yes\n    list = no\n", module, strings.TrimSuffix(mountPath, "/"))
+}
+
+// rsyncSourceSpec returns the rsync source for a sync, reading from the daemon module when one is set
+func rsyncSourceSpec(nodeIP, mountPath, module string) string {
+	if module != "" {
+		return fmt.Sprintf("root@%s::%s/", nodeIP, module)
+	}
+	return fmt.Sprintf("root@%s:%s/", nodeIP, mountPath)
+}
+
+// rsyncMode returns the rsync mode of the source RemoteCluster, defaulting to Shell
+func (p *PVCSyncer) rsyncMode(ctx context.Context) drv1alpha1.RsyncMode {
+	return ssh.RsyncModeForCluster(p.sourceRemoteCluster(ctx))
+}
+
+// allowRsyncDaemonModule allow-lists mountPath as a read-only rsync daemon module on the agent
+// when the source cluster runs in Daemon mode. The returned context carries the module name
+// for performRsync and the returned func removes the module again.
+func (p *PVCSyncer) allowRsyncDaemonModule(ctx context.Context, agentPod *corev1.Pod, mountPath string) (context.Context, func(), error) {
+	if p.rsyncMode(ctx) != drv1alpha1.RsyncModeDaemon {
+		return ctx, func() {}, nil
+	}
+
+	module := fmt.Sprintf("sync-%s", rand.String(10))
+	moduleFile := fmt.Sprintf("%s/%s.conf", rsyncDaemonModuleDir, module)
+
+	log.WithFields(logrus.Fields{
+		"agent_pod":  agentPod.Name,
+		"module":     module,
+		"mount_path": mountPath,
+	}).Info(logging.LogTagDetail + " Allow-listing rsync daemon module on agent pod")
+
+	cmd := []string{
+		"bash",
+		"-c",
+		fmt.Sprintf("mkdir -p %s && cat > %s <<'EOF'\n%sEOF", rsyncDaemonModuleDir, moduleFile, rsyncDaemonModuleConfig(module, mountPath)),
+	}
+	if _, stderr, err := p.execCommandOnPod(ctx, agentPod.Namespace, agentPod.Name, cmd); err != nil {
+		return ctx, func() {}, fmt.Errorf("failed to allow-list rsync daemon module on agent pod: %v: %s", err, stderr)
+	}
+
+	release := func() {
+		cmd := []string{"rm", "-f", moduleFile}
+		if _, stderr, err := p.execCommandOnPod(context.Background(), agentPod.Namespace, agentPod.Name, cmd); err != nil {
+			log.WithFields(logrus.Fields{
+				"agent_pod": agentPod.Name,
+				"module":    module,
+				"error":     err,
+				"stderr":    stderr,
+			}).Warn(logging.LogTagWarn + " Failed to remove rsync daemon module from agent pod")
+		}
+	}
+
+	return context.WithValue(ctx, rsyncDaemonModuleKey, module), release, nil
+}
+
+// performRsyncFromAgent runs performRsync against the agent, allow-listing the PVC
+// mount path as a daemon module for the duration of the sync when required
+func (p *PVCSyncer) performRsyncFromAgent(ctx context.Context, agentPod *corev1.Pod, destDeployment *rsyncpod.RsyncDeployment, nodeIP, mountPath string) error {
+	moduleCtx, release, err := p.allowRsyncDaemonModule(ctx, agentPod, mountPath)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	return p.performRsync(moduleCtx, destDeployment, nodeIP, mountPath)
+}
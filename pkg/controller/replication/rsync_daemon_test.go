@@ -0,0 +1,28 @@
+package replication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRsyncDaemonModuleConfig(t *testing.T) {
+	config := rsyncDaemonModuleConfig("sync-abc", "/var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/pv/mount/")
+
+	assert.Equal(t, "[sync-abc]\n    path = /var/lib/kubelet/pods/uid/volumes/kubernetes.io~csi/pv/mount\n    read only = yes\n    list = no\n", config)
+}
+
+func TestRsyncSourceSpec(t *testing.T) {
+	assert.Equal(t, "root@10.0.0.1:/mnt/data/", rsyncSourceSpec("10.0.0.1", "/mnt/data", ""))
+	assert.Equal(t, "root@10.0.0.1::sync-abc/", rsyncSourceSpec("10.0.0.1", "/mnt/data", "sync-abc"))
+}
+
+func TestGetRsyncDaemonModule(t *testing.T) {
+	_, ok := GetRsyncDaemonModule(context.Background())
+	assert.False(t, ok)
+
+	module, ok := GetRsyncDaemonModule(context.WithValue(context.Background(), rsyncDaemonModuleKey, "sync-abc"))
+	assert.True(t, ok)
+	assert.Equal(t, "sync-abc", module)
+}
@@ -88,6 +88,10 @@ func (r *RsyncController) SyncReplication(ctx context.Context, sourceNS, destNS,
 		}).Error("[DR-SYNC-ERROR] Failed to find DR-Syncer-Agent on node")
 		return fmt.Errorf("failed to find DR-Syncer-Agent on node: %v", err)
 	}
+	agentIP, sshPort, err := r.syncer.AgentSSHEndpoint(ctx, selectedNode, agentIP)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the DR-Syncer-Agent endpoint: %v", err)
+	}
 
 	// Step 5: Find the mount path for the PVC
 	log.Info("[DR-SYNC] Step 5: Finding mount path for source PVC")
@@ -163,7 +167,7 @@ func (r *RsyncController) SyncReplication(ctx context.Context, sourceNS, destNS,
 
 	// Step 11: Test SSH connectivity from rsync pod to agent pod
 	log.Info("[DR-SYNC] Step 11: Testing SSH connectivity")
-	err = r.testSSHConnectivity(ctx, deployment.Namespace, rsyncPod, agentIP, 2222)
+	err = r.testSSHConnectivity(ctx, deployment.Namespace, rsyncPod, agentIP, int(sshPort))
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"error": err,
@@ -173,7 +177,7 @@ func (r *RsyncController) SyncReplication(ctx context.Context, sourceNS, destNS,
 
 	// Step 12: Run the rsync command and monitor status
 	log.Info("[DR-SYNC] Step 12: Running rsync command")
-	err = r.performRsync(ctx, deployment.Namespace, rsyncPod, agentIP, mountPath)
+	err = r.performRsync(ctx, deployment.Namespace, rsyncPod, agentIP, sshPort, mountPath)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"error": err,
@@ -215,7 +219,7 @@ func (r *RsyncController) findPVCNodes(ctx context.Context, namespace, pvcName s
 	}
 
 	// Get volume attachments for this PVC
-	volumeAttachments, err := r.syncer.SourceK8sClient.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	volumeAttachments, err := listVolumeAttachments(ctx, r.syncer.SourceK8sClient)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list volume attachments: %v", err)
 	}
@@ -574,7 +578,7 @@ func (r *RsyncController) testSSHConnectivity(ctx context.Context, namespace, po
 }
 
 // performRsync runs the rsync command in the rsync pod
-func (r *RsyncController) performRsync(ctx context.Context, namespace, podName, agentIP, mountPath string) error {
+func (r *RsyncController) performRsync(ctx context.Context, namespace, podName, agentIP string, sshPort int32, mountPath string) error {
 	log.WithFields(logrus.Fields{
 		"pod":        podName,
 		"namespace":  namespace,
@@ -588,14 +592,15 @@ func (r *RsyncController) performRsync(ctx context.Context, namespace, podName,
 		"--verbose",
 		"--delete",
 		"--human-readable",
+		"--inplace", // Write to the destination volume, not the ephemeral storage of the pod
 	}
 
 	// Combine rsync options
 	rsyncOptsStr := strings.Join(rsyncOptions, " ")
 
 	// Build the rsync command with tee to log the output
-	rsyncCmd := fmt.Sprintf("rsync %s -e 'ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i /root/.ssh/id_rsa -p 2222' root@%s:%s/ /data/ | tee /var/log/rsync.log",
-		rsyncOptsStr, agentIP, mountPath)
+	rsyncCmd := fmt.Sprintf("rsync %s -e 'ssh -o StrictHostKeyChecking=no -o UserKnownHostsFile=/dev/null -i /root/.ssh/id_rsa -p %d' root@%s:%s/ /data/ | tee /var/log/rsync.log",
+		rsyncOptsStr, sshPort, agentIP, mountPath)
 
 	log.WithFields(logrus.Fields{
 		"rsync_cmd": rsyncCmd,
@@ -0,0 +1,166 @@
+package replication
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+// RsyncFailureClass groups rsync failures by their cause, so alerts can be routed to the network, storage
+// or platform team
+type RsyncFailureClass string
+
+// Failure classes of rsync, recorded in the sync status of the source PVC, its events and metrics
+const (
+	// RsyncFailureNetwork is a failed SSH connection or a broken connection to the source
+	RsyncFailureNetwork RsyncFailureClass = "Network"
+
+	// RsyncFailureTimeout is a connection or transfer that exceeded the rsync timeouts
+	RsyncFailureTimeout RsyncFailureClass = "Timeout"
+
+	// RsyncFailurePermission is a file rsync was not allowed to read or write
+	RsyncFailurePermission RsyncFailureClass = "Permission"
+
+	// RsyncFailureDisk is a full, read-only or failing volume
+	RsyncFailureDisk RsyncFailureClass = "Disk"
+
+	// RsyncFailurePartialTransfer is a transfer some files failed to copy for other reasons
+	RsyncFailurePartialTransfer RsyncFailureClass = "PartialTransfer"
+
+	// RsyncFailureVanishedFiles is a transfer some source files were deleted during, expected on live volumes
+	RsyncFailureVanishedFiles RsyncFailureClass = "VanishedFiles"
+
+	// RsyncFailureConfiguration is an invalid rsync option or an incompatible rsync on the source
+	RsyncFailureConfiguration RsyncFailureClass = "Configuration"
+
+	// RsyncFailureInterrupted is an rsync killed by a signal, such as when the sync timed out
+	RsyncFailureInterrupted RsyncFailureClass = "Interrupted"
+
+	// RsyncFailureUnknown is any other exit code
+	RsyncFailureUnknown RsyncFailureClass = "Unknown"
+)
+
+// rsyncExitClasses maps the exit codes of rsync, see EXIT VALUES in rsync(1), and of ssh to failure classes
+var rsyncExitClasses = map[int]RsyncFailureClass{
+	1:   RsyncFailureConfiguration,   // syntax or usage error
+	2:   RsyncFailureConfiguration,   // protocol incompatibility
+	3:   RsyncFailurePermission,      // errors selecting input/output files, dirs
+	4:   RsyncFailureConfiguration,   // requested action not supported
+	5:   RsyncFailureNetwork,         // error starting client-server protocol
+	10:  RsyncFailureNetwork,         // error in socket I/O
+	11:  RsyncFailureDisk,            // error in file I/O
+	12:  RsyncFailureNetwork,         // error in rsync protocol data stream
+	20:  RsyncFailureInterrupted,     // received SIGUSR1 or SIGINT
+	23:  RsyncFailurePartialTransfer, // partial transfer due to error
+	24:  RsyncFailureVanishedFiles,   // partial transfer due to vanished source files
+	30:  RsyncFailureTimeout,         // timeout in data send/receive
+	35:  RsyncFailureTimeout,         // timeout waiting for daemon connection
+	137: RsyncFailureInterrupted,     // killed
+	143: RsyncFailureInterrupted,     // terminated
+	255: RsyncFailureNetwork,         // ssh failed to connect or authenticate
+}
+
+// diskErrorPatterns and permissionErrorPatterns refine the class of file errors from the rsync output
+var (
+	diskErrorPatterns       = []string{"no space left on device", "disk quota exceeded", "read-only file system", "input/output error"}
+	permissionErrorPatterns = []string{"permission denied (13)", "operation not permitted (1)"}
+)
+
+// exitCodePattern finds the exit code in errors of exec streams flattened into strings
+var exitCodePattern = regexp.MustCompile(`exit code (\d+)`)
+
+// RsyncError is an rsync run that exited with a non-zero exit code
+type RsyncError struct {
+	ExitCode int
+	Class    RsyncFailureClass
+	Err      error
+}
+
+func (e *RsyncError) Error() string {
+	return fmt.Sprintf("rsync exited with code %d (%s): %v", e.ExitCode, e.Class, e.Err)
+}
+
+func (e *RsyncError) Unwrap() error {
+	return e.Err
+}
+
+// Retryable reports whether running rsync again may succeed
+func (e *RsyncError) Retryable() bool {
+	switch e.Class {
+	case RsyncFailureNetwork, RsyncFailureTimeout, RsyncFailureVanishedFiles:
+		return true
+	}
+	return false
+}
+
+// EventReason is the reason of the Warning event recorded on the source PVC for the failure
+func (e *RsyncError) EventReason() string {
+	return "Rsync" + string(e.Class) + "Failure"
+}
+
+// AsRsyncError returns the RsyncError wrapped in err
+func AsRsyncError(err error) (*RsyncError, bool) {
+	var rsyncErr *RsyncError
+	if errors.As(err, &rsyncErr) {
+		return rsyncErr, true
+	}
+	return nil, false
+}
+
+// rsyncExitCode returns the exit code of a command run in a pod, false when the command did not run to an
+// exit, such as when the exec stream failed
+func rsyncExitCode(err error) (int, bool) {
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		return exitErr.ExitStatus(), true
+	}
+	if match := exitCodePattern.FindStringSubmatch(err.Error()); match != nil {
+		if code, convErr := strconv.Atoi(match[1]); convErr == nil {
+			return code, true
+		}
+	}
+	return 0, false
+}
+
+// classifyRsyncError returns the RsyncError of a failed rsync command from its exit code and output, nil
+// when the command did not exit
+func classifyRsyncError(err error, stderr string) *RsyncError {
+	if err == nil {
+		return nil
+	}
+	code, ok := rsyncExitCode(err)
+	if !ok || code == 0 {
+		return nil
+	}
+
+	class, known := rsyncExitClasses[code]
+	if !known {
+		class = RsyncFailureUnknown
+	}
+
+	// Files that failed to copy are caused by the volume or permissions more often than not
+	output := strings.ToLower(stderr + " " + err.Error())
+	if class != RsyncFailureNetwork && class != RsyncFailureTimeout {
+		switch {
+		case containsAny(output, diskErrorPatterns):
+			class = RsyncFailureDisk
+		case containsAny(output, permissionErrorPatterns):
+			class = RsyncFailurePermission
+		}
+	}
+	return &RsyncError{ExitCode: code, Class: class, Err: err}
+}
+
+// containsAny reports whether s contains one of the patterns
+func containsAny(s string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(s, pattern) {
+			return true
+		}
+	}
+	return false
+}
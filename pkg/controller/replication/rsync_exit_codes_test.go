@@ -0,0 +1,81 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	utilexec "k8s.io/client-go/util/exec"
+)
+
+func exitError(code int) error {
+	return fmt.Errorf("failed to execute command: %w, stderr: ", utilexec.CodeExitError{
+		Err:  fmt.Errorf("command terminated with exit code %d", code),
+		Code: code,
+	})
+}
+
+func TestClassifyRsyncError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		stderr    string
+		class     RsyncFailureClass
+		retryable bool
+	}{
+		{name: "ssh", err: exitError(255), stderr: "ssh: connect to host 10.0.0.4 port 2222: Connection refused", class: RsyncFailureNetwork, retryable: true},
+		{name: "timeout", err: exitError(30), stderr: "[receiver] io timeout after 600 seconds -- exiting", class: RsyncFailureTimeout, retryable: true},
+		{name: "vanished", err: exitError(24), stderr: `file has vanished: "/data/tmp/lock"`, class: RsyncFailureVanishedFiles, retryable: true},
+		{name: "partial", err: exitError(23), stderr: `rsync: [sender] read errors mapping "/data/db": Input/output error (5)`, class: RsyncFailureDisk},
+		{name: "permission", err: exitError(23), stderr: `rsync: [receiver] mkstemp "/data/.x" failed: Permission denied (13)`, class: RsyncFailurePermission},
+		{name: "partial other", err: exitError(23), stderr: "some files/attrs were not transferred", class: RsyncFailurePartialTransfer},
+		{name: "no space", err: exitError(11), stderr: `write failed on "/data/db.sqlite": No space left on device (28)`, class: RsyncFailureDisk},
+		{name: "usage", err: exitError(1), stderr: "rsync: --bwlimt=100: unknown option", class: RsyncFailureConfiguration},
+		{name: "unknown", err: exitError(42), class: RsyncFailureUnknown},
+		{name: "flattened", err: errors.New("failed to execute command: command terminated with exit code 24, stderr: "), class: RsyncFailureVanishedFiles, retryable: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rsyncErr := classifyRsyncError(tt.err, tt.stderr)
+			require.NotNil(t, rsyncErr)
+			assert.Equal(t, tt.class, rsyncErr.Class)
+			assert.Equal(t, tt.retryable, rsyncErr.Retryable())
+		})
+	}
+
+	assert.Nil(t, classifyRsyncError(nil, ""))
+	assert.Nil(t, classifyRsyncError(errors.New("unable to upgrade connection: pod not found"), ""), "the command did not run")
+}
+
+func TestAsRsyncError_ThroughRetries(t *testing.T) {
+	rsyncErr := classifyRsyncError(exitError(255), "")
+	err := fmt.Errorf("rsync command failed: %w", fmt.Errorf("operation failed after 5 attempts: %w", &RetryableError{Err: rsyncErr}))
+
+	found, ok := AsRsyncError(err)
+	require.True(t, ok)
+	assert.Equal(t, 255, found.ExitCode)
+	assert.Equal(t, "RsyncNetworkFailure", found.EventReason())
+}
+
+func TestFailedSyncStatus_RecordsFailureClass(t *testing.T) {
+	source := fake.NewSimpleClientset(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app"}})
+	p := &PVCSyncer{SourceK8sClient: source}
+
+	rsyncErr := classifyRsyncError(exitError(23), "Permission denied (13)")
+	require.NoError(t, p.FailedSyncStatus(context.Background(), "app", "data", fmt.Errorf("rsync command failed: %w", rsyncErr)))
+
+	pvc, err := source.CoreV1().PersistentVolumeClaims("app").Get(context.Background(), "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	var status SyncStatus
+	require.NoError(t, json.Unmarshal([]byte(pvc.Annotations["dr-syncer.io/sync-status"]), &status))
+	assert.Equal(t, "Failed", status.Phase)
+	assert.Equal(t, RsyncFailurePermission, status.FailureClass)
+	assert.Equal(t, 23, status.ExitCode)
+}
@@ -10,7 +10,11 @@ import (
 	"time"
 
 	"github.com/sirupsen/logrus"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/syncstate"
+	"github.com/supporttools/dr-syncer/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -55,10 +59,17 @@ func contains(slice []string, s string) bool {
 
 // execCommandOnPod executes a command in a pod
 func (p *PVCSyncer) execCommandOnPod(ctx context.Context, namespace, podName string, command []string) (string, string, error) {
+	return p.execCommandInContainer(ctx, namespace, podName, "", command)
+}
+
+// execCommandInContainer executes a command in a container of a source pod, the default container when
+// container is empty
+func (p *PVCSyncer) execCommandInContainer(ctx context.Context, namespace, podName, container string, command []string) (string, string, error) {
 	// Add debug logging to show which cluster we're executing commands on
 	log.WithFields(logrus.Fields{
 		"namespace":          namespace,
 		"pod_name":           podName,
+		"container":          container,
 		"command":            strings.Join(command, " "),
 		"source_cluster_url": p.SourceConfig.Host,
 	}).Debug(logging.LogTagDetail + " Executing command on pod using source cluster")
@@ -70,15 +81,16 @@ func (p *PVCSyncer) execCommandOnPod(ctx context.Context, namespace, podName str
 		SubResource("exec")
 
 	req.VersionedParams(&corev1.PodExecOptions{
-		Command: command,
-		Stdin:   false,
-		Stdout:  true,
-		Stderr:  true,
-		TTY:     false,
+		Container: container,
+		Command:   command,
+		Stdin:     false,
+		Stdout:    true,
+		Stderr:    true,
+		TTY:       false,
 	}, scheme.ParameterCodec)
 
 	var stdout, stderr bytes.Buffer
-	exec, err := remotecommand.NewSPDYExecutor(p.SourceConfig, "POST", req.URL())
+	exec, err := util.NewPodExecutor(p.SourceConfig, req.URL())
 	if err != nil {
 		return "", "", err
 	}
@@ -348,7 +360,7 @@ func (p *PVCSyncer) FindPVCNodes(ctx context.Context, c client.Client, namespace
 			"source_cluster_url": p.SourceConfig.Host,
 		}).Debug(logging.LogTagDetail + " Listing volume attachments with direct client")
 
-		volumeAttachments, err := p.SourceK8sClient.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+		volumeAttachments, err := listVolumeAttachments(ctx, p.SourceK8sClient)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"namespace": namespace,
@@ -390,7 +402,6 @@ func (p *PVCSyncer) FindPVCNodes(ctx context.Context, c client.Client, namespace
 				// Check if this attachment matches our PV
 				if pvName == pvc.Spec.VolumeName {
 					matchFound = true
-					fmt.Printf("Found volume attachment: %s on node %s for PV %s\n", va.Name, va.Spec.NodeName, pvName)
 					log.WithFields(logrus.Fields{
 						"namespace":     namespace,
 						"pvc_name":      pvcName,
@@ -401,7 +412,10 @@ func (p *PVCSyncer) FindPVCNodes(ctx context.Context, c client.Client, namespace
 
 					// Add the node if not already in the list
 					if !contains(nodes, va.Spec.NodeName) {
-						fmt.Printf("Adding node %s to list from volume attachment\n", va.Spec.NodeName)
+						log.WithFields(logrus.Fields{
+							"pvc_name": pvcName,
+							"node":     va.Spec.NodeName,
+						}).Debug(logging.LogTagDetail + " Adding node from volume attachment")
 						nodes = append(nodes, va.Spec.NodeName)
 					}
 				}
@@ -423,12 +437,14 @@ func (p *PVCSyncer) FindPVCNodes(ctx context.Context, c client.Client, namespace
 				"pv_name":          pvc.Spec.VolumeName,
 				"attachment_count": len(volumeAttachments.Items),
 			}).Warn(logging.LogTagWarn + " No matching volume attachments found for this PV")
-			fmt.Printf("No volume attachments found for PV %s among %d attachments\n",
-				pvc.Spec.VolumeName, len(volumeAttachments.Items))
 		}
 	}
 
-	fmt.Printf("Final list of nodes: %v\n", nodes)
+	log.WithFields(logrus.Fields{
+		"namespace": namespace,
+		"pvc_name":  pvcName,
+		"nodes":     nodes,
+	}).Debug(logging.LogTagDetail + " Found nodes mounting PVC")
 	return nodes, nil
 }
 
@@ -440,9 +456,7 @@ func (p *PVCSyncer) FindAgentPod(ctx context.Context, nodeName string) (*corev1.
 	}).Info(logging.LogTagDetail + " Finding DR-Syncer-Agent on node using source cluster")
 
 	// List pods with agent selector
-	podList, err := p.SourceK8sClient.CoreV1().Pods("").List(ctx, metav1.ListOptions{
-		LabelSelector: "app=dr-syncer-agent",
-	})
+	podList, err := listAgentPods(ctx, p.SourceK8sClient, "app=dr-syncer-agent")
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"node":  nodeName,
@@ -508,6 +522,46 @@ func (p *PVCSyncer) FindAgentPod(ctx context.Context, nodeName string) (*corev1.
 	return agentPod, nodeIP, nil
 }
 
+// AgentSSHEndpoint returns the address and port the destination rsync pods connect to for the agent on
+// nodeName, as advertised in the status of the source RemoteCluster. Agents on the host network that have
+// not advertised an endpoint are reached on nodeIP and the SSH port of the RemoteCluster.
+func (p *PVCSyncer) AgentSSHEndpoint(ctx context.Context, nodeName, nodeIP string) (string, int32, error) {
+	port := int32(2222)
+	exposure := drv1alpha1.AgentExposureHostNetwork
+
+	rc := p.sourceRemoteCluster(ctx)
+	if rc == nil {
+		return nodeIP, port, nil
+	}
+	if rc.Spec.PVCSync != nil && rc.Spec.PVCSync.SSH != nil {
+		if rc.Spec.PVCSync.SSH.Port > 0 {
+			port = rc.Spec.PVCSync.SSH.Port
+		}
+		if rc.Spec.PVCSync.SSH.Exposure != nil && rc.Spec.PVCSync.SSH.Exposure.Type != "" {
+			exposure = rc.Spec.PVCSync.SSH.Exposure.Type
+		}
+	}
+
+	if rc.Status.PVCSync != nil && rc.Status.PVCSync.AgentStatus != nil {
+		if endpoint, ok := rc.Status.PVCSync.AgentStatus.Endpoints[nodeName]; ok && endpoint.Address != "" && endpoint.Port > 0 {
+			log.WithFields(logrus.Fields{
+				"node":           nodeName,
+				"remote_cluster": rc.Name,
+				"exposure":       exposure,
+				"address":        endpoint.Address,
+				"port":           endpoint.Port,
+			}).Info(logging.LogTagDetail + " Resolved advertised agent endpoint")
+			return endpoint.Address, endpoint.Port, nil
+		}
+	}
+
+	if exposure != drv1alpha1.AgentExposureHostNetwork {
+		return "", 0, fmt.Errorf("RemoteCluster %s does not advertise a %s endpoint for the agent on node %s yet",
+			rc.Name, exposure, nodeName)
+	}
+	return nodeIP, port, nil
+}
+
 // getMountPathFromCache attempts to retrieve a valid cached mount path from PVC annotations.
 // Returns the cached path and true if valid, or empty string and false if cache miss/invalid.
 func (p *PVCSyncer) getMountPathFromCache(ctx context.Context, pvc *corev1.PersistentVolumeClaim, agentPod *corev1.Pod) (string, bool) {
@@ -639,14 +693,22 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 		return "", fmt.Errorf("failed to get PVC: %v", err)
 	}
 
-	// Try to get mount path from cache first (avoids expensive df/mount/find cascade)
+	// Try to get mount path from cache first (avoids the discovery round-trip). A cached path the
+	// agent no longer finds is discovered again, agents without the volume API keep using it.
 	if cachedPath, valid := p.getMountPathFromCache(ctx, pvc, agentPod); valid {
-		log.WithFields(logrus.Fields{
-			"pvc_name":   pvcName,
-			"mount_path": cachedPath,
-			"cache_hit":  true,
-		}).Info(logging.LogTagInfo + " Using cached mount path, skipping discovery")
-		return cachedPath, nil
+		if stat, err := p.agentStat(ctx, agentPod, cachedPath); err == nil && !stat.IsDir {
+			log.WithFields(logrus.Fields{
+				"pvc_name":   pvcName,
+				"mount_path": cachedPath,
+			}).Info(logging.LogTagInfo + " Cached mount path no longer exists, finding it again")
+		} else {
+			log.WithFields(logrus.Fields{
+				"pvc_name":   pvcName,
+				"mount_path": cachedPath,
+				"cache_hit":  true,
+			}).Info(logging.LogTagInfo + " Using cached mount path, skipping discovery")
+			return cachedPath, nil
+		}
 	}
 
 	// If no PV is bound yet, we can't find a mount path
@@ -680,10 +742,49 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 		return "", fmt.Errorf("PV %s/%s is not bound", namespace, pvc.Spec.VolumeName)
 	}
 
+	// Ask the agent for the mount path, agents that do not serve the volume API yet are searched with
+	// shell commands
+	mountPath, err := p.agentMountPath(ctx, agentPod, pvc.Spec.VolumeName)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"pvc_name":  pvcName,
+			"agent_pod": agentPod.Name,
+			"error":     err,
+		}).Warn(logging.LogTagWarn + " Agent volume API unavailable, finding mount path with commands")
+		return p.findPVCMountPathByExec(ctx, namespace, pvcName, pvc.Spec.VolumeName, agentPod)
+	}
+	if mountPath == "" {
+		log.WithFields(logrus.Fields{
+			"namespace": namespace,
+			"pvc_name":  pvcName,
+			"pv_name":   pvc.Spec.VolumeName,
+			"agent_pod": agentPod.Name,
+		}).Error(logging.LogTagError + " Mount path not found for PVC")
+		return "", fmt.Errorf("mount path not found for PVC %s/%s: volume %s is not mounted on node %s",
+			namespace, pvcName, pvc.Spec.VolumeName, agentPod.Spec.NodeName)
+	}
+
+	log.WithFields(logrus.Fields{
+		"pvc_name":   pvcName,
+		"pv_name":    pvc.Spec.VolumeName,
+		"mount_path": mountPath,
+		"approach":   "agent-api",
+	}).Info(logging.LogTagDetail + " Found mount path using the agent volume API")
+
+	// Cache the discovered mount path for future syncs
+	if err := p.saveMountPathToCache(ctx, namespace, pvcName, mountPath, agentPod); err != nil {
+		log.WithField("error", err).Warn(logging.LogTagWarn + " Failed to cache mount path, continuing anyway")
+	}
+	return mountPath, nil
+}
+
+// findPVCMountPathByExec finds the mount path of a PV by running df, mount and find in the agent pod,
+// for agents that do not serve the volume API
+func (p *PVCSyncer) findPVCMountPathByExec(ctx context.Context, namespace, pvcName, volumeName string, agentPod *corev1.Pod) (string, error) {
 	// First try: Use df to find the mount path - most efficient approach
 	log.WithFields(logrus.Fields{
 		"pvc_name":  pvcName,
-		"pv_name":   pvc.Spec.VolumeName,
+		"pv_name":   volumeName,
 		"agent_pod": agentPod.Name,
 		"approach":  "df-grep",
 	}).Info(logging.LogTagDetail + " Trying df approach to find mount path")
@@ -693,7 +794,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 		"bash",
 		"-c",
 		fmt.Sprintf("df | grep -E '%s|%s' | awk '{print $6}' | head -n 1",
-			pvc.Spec.VolumeName, pvcName),
+			volumeName, pvcName),
 	}
 
 	// Create a context with a short timeout
@@ -706,7 +807,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 	if mountPath != "" {
 		log.WithFields(logrus.Fields{
 			"pvc_name":   pvcName,
-			"pv_name":    pvc.Spec.VolumeName,
+			"pv_name":    volumeName,
 			"mount_path": mountPath,
 			"approach":   "df-grep",
 		}).Info(logging.LogTagDetail + " Found mount path using df approach")
@@ -720,7 +821,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 	// If df approach failed, try the 'mount' command - moderately efficient
 	log.WithFields(logrus.Fields{
 		"pvc_name":  pvcName,
-		"pv_name":   pvc.Spec.VolumeName,
+		"pv_name":   volumeName,
 		"agent_pod": agentPod.Name,
 		"approach":  "mount-grep",
 	}).Info(logging.LogTagDetail + " Trying mount approach to find mount path")
@@ -728,7 +829,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 	mountCmd := []string{
 		"bash",
 		"-c",
-		"mount | grep " + pvc.Spec.VolumeName + " | awk '{print $3}' | head -n 1",
+		"mount | grep " + volumeName + " | awk '{print $3}' | head -n 1",
 	}
 
 	// Create a context with a short timeout
@@ -741,7 +842,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 	if mountPath != "" {
 		log.WithFields(logrus.Fields{
 			"pvc_name":   pvcName,
-			"pv_name":    pvc.Spec.VolumeName,
+			"pv_name":    volumeName,
 			"mount_path": mountPath,
 			"approach":   "mount-grep",
 		}).Info(logging.LogTagDetail + " Found mount path using mount approach")
@@ -755,7 +856,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 	// Last resort: Use the find command with a strict timeout - least efficient but most thorough
 	log.WithFields(logrus.Fields{
 		"pvc_name":  pvcName,
-		"pv_name":   pvc.Spec.VolumeName,
+		"pv_name":   volumeName,
 		"agent_pod": agentPod.Name,
 		"approach":  "find-command",
 	}).Info(logging.LogTagDetail + " Trying find approach to find mount path (with timeout)")
@@ -765,7 +866,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 		"bash",
 		"-c",
 		fmt.Sprintf("timeout 30s find /var/lib/kubelet/pods -name %s -type d -path '*/volumes/*' | grep -v plugins | head -n 1",
-			pvc.Spec.VolumeName),
+			volumeName),
 	}
 
 	// Create a context with a reasonable timeout
@@ -780,7 +881,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 		log.WithFields(logrus.Fields{
 			"namespace":    namespace,
 			"pvc_name":     pvcName,
-			"pv_name":      pvc.Spec.VolumeName,
+			"pv_name":      volumeName,
 			"agent_pod":    agentPod.Name,
 			"df_error":     dfErr,
 			"df_stderr":    dfStderr,
@@ -795,7 +896,7 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 	log.WithFields(logrus.Fields{
 		"namespace":  namespace,
 		"pvc_name":   pvcName,
-		"pv_name":    pvc.Spec.VolumeName,
+		"pv_name":    volumeName,
 		"agent_pod":  agentPod.Name,
 		"mount_path": mountPath,
 		"approach":   "find-command",
@@ -809,39 +910,40 @@ func (p *PVCSyncer) FindPVCMountPath(ctx context.Context, namespace, pvcName str
 	return mountPath, nil
 }
 
-// PushPublicKeyToAgent pushes the public key to the agent pod
+// PushPublicKeyToAgent declares the public key as an authorized key source next to the agent pod
+// and waits until the leader agent distributed it to the agents
 func (p *PVCSyncer) PushPublicKeyToAgent(ctx context.Context, agentPod *corev1.Pod, publicKey, trackingInfo string) error {
 	log.WithFields(logrus.Fields{
 		"agent_pod":          agentPod.Name,
 		"agent_ns":           agentPod.Namespace,
 		"tracking_info":      trackingInfo,
 		"source_cluster_url": p.SourceConfig.Host,
-	}).Info(logging.LogTagDetail + " Pushing public key to agent pod using source cluster")
+	}).Info(logging.LogTagDetail + " Declaring public key for the agents using source cluster")
 
-	// Format the authorized_keys entry with the tracking info as a comment
-	authKeyEntry := fmt.Sprintf("# %s\n%s\n", trackingInfo, publicKey)
+	// Format the authorized_keys entry with the tracking info as a comment.
+	// In Daemon mode the key is restricted to the rsync daemon forced command.
+	authKeyEntry := fmt.Sprintf("# %s\n%s", trackingInfo, ssh.AuthorizedKeysEntry([]byte(publicKey), p.rsyncMode(ctx)))
 
-	// Append the key to authorized_keys
-	cmd := []string{
-		"bash",
-		"-c",
-		fmt.Sprintf("mkdir -p /root/.ssh && echo '%s' >> /root/.ssh/authorized_keys && chmod 600 /root/.ssh/authorized_keys", authKeyEntry),
+	// The key only serves this sync, the leader agent drops it once it expired
+	if err := ssh.ApplyAuthorizedKeySource(ctx, p.SourceK8sClient, agentPod.Namespace, trackingInfo, []byte(authKeyEntry), syncKeyTTL); err != nil {
+		log.WithFields(logrus.Fields{
+			"agent_pod": agentPod.Name,
+			"error":     err,
+		}).Error(logging.LogTagError + " Failed to declare public key for the agents")
+		return fmt.Errorf("failed to push public key to agent pod: %v", err)
 	}
 
-	stdout, stderr, err := p.execCommandOnPod(ctx, agentPod.Namespace, agentPod.Name, cmd)
-	if err != nil {
+	if err := ssh.WaitForAuthorizedKey(ctx, p.SourceK8sClient, agentPod.Namespace, []byte(publicKey), keyDistributionTimeout); err != nil {
 		log.WithFields(logrus.Fields{
 			"agent_pod": agentPod.Name,
 			"error":     err,
-			"stderr":    stderr,
-		}).Error(logging.LogTagError + " Failed to push public key to agent pod")
-		return fmt.Errorf("failed to push public key to agent pod: %v: %s", err, stderr)
+		}).Error(logging.LogTagError + " Public key was not distributed to the agents")
+		return fmt.Errorf("failed to push public key to agent pod: %v", err)
 	}
 
 	log.WithFields(logrus.Fields{
 		"agent_pod": agentPod.Name,
-		"stdout":    stdout,
-	}).Debug(logging.LogTagDetail + " Public key pushed to agent pod")
+	}).Debug(logging.LogTagDetail + " Public key distributed to the agents")
 
 	return nil
 }
@@ -988,7 +1090,7 @@ func (p *PVCSyncer) HasVolumeAttachments(ctx context.Context, namespace, pvcName
 	}
 
 	// If no running pods are found, check volume attachments
-	volumeAttachments, err := p.SourceK8sClient.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	volumeAttachments, err := listVolumeAttachments(ctx, p.SourceK8sClient)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"namespace": namespace,
@@ -1038,7 +1140,7 @@ func (p *PVCSyncer) AcquirePVCLock(ctx context.Context, namespace, pvcName strin
 	}
 
 	// Check if the PVC is already locked
-	if pvc.Annotations != nil && pvc.Annotations["dr-syncer.io/lock-owner"] != "" {
+	if pvc.Annotations != nil && pvc.Annotations[LockOwnerAnnotation] != "" {
 		// Get our pod name
 		podName := os.Getenv("POD_NAME")
 		if podName == "" {
@@ -1046,43 +1148,52 @@ func (p *PVCSyncer) AcquirePVCLock(ctx context.Context, namespace, pvcName strin
 		}
 
 		// If we already own the lock, return success
-		if pvc.Annotations["dr-syncer.io/lock-owner"] == podName {
+		if pvc.Annotations[LockOwnerAnnotation] == podName {
 			log.WithFields(logrus.Fields{
 				"namespace": namespace,
 				"pvc_name":  pvcName,
 				"pod_name":  podName,
 			}).Info(logging.LogTagDetail + " We already own the lock on PVC")
 
+			syncstate.LockAcquired(namespace, pvcName, podName)
 			return true, &PVCLockInfo{
 				ControllerPodName: podName,
-				Timestamp:         pvc.Annotations["dr-syncer.io/lock-timestamp"],
+				Timestamp:         pvc.Annotations[LockTimestampAnnotation],
 			}, nil
 		}
 
-		// Check if the lock is stale (older than 1 hour)
-		if pvc.Annotations["dr-syncer.io/lock-timestamp"] != "" {
-			lockTime, err := time.Parse(time.RFC3339, pvc.Annotations["dr-syncer.io/lock-timestamp"])
+		// Check if the lock is stale
+		if pvc.Annotations[LockTimestampAnnotation] != "" {
+			lockTime, err := time.Parse(time.RFC3339, pvc.Annotations[LockTimestampAnnotation])
 			if err == nil {
-				if time.Since(lockTime) > 1*time.Hour {
+				if time.Since(lockTime) > p.staleLockTimeout() {
 					log.WithFields(logrus.Fields{
 						"namespace":    namespace,
 						"pvc_name":     pvcName,
-						"lock_owner":   pvc.Annotations["dr-syncer.io/lock-owner"],
+						"lock_owner":   pvc.Annotations[LockOwnerAnnotation],
+						"lock_sync":    pvc.Annotations[LockSyncAnnotation],
 						"lock_time":    lockTime,
 						"current_time": time.Now(),
-					}).Info(logging.LogTagDetail + " Lock is stale, taking over")
+					}).Info(logging.LogTagDetail + " Lock is stale, stopping its sync before taking over")
+
+					// The previous owner's rsync may still be running, it is stopped before the lock is taken
+					if err := p.fenceStaleSync(ctx, namespace, pvcName, pvc.Annotations); err != nil {
+						return false, nil, fmt.Errorf("failed to stop the sync of stale lock owner %s: %w",
+							pvc.Annotations[LockOwnerAnnotation], err)
+					}
+					delete(pvc.Annotations, LockSyncAnnotation)
 				} else {
 					// Lock is not stale, return the lock info
 					return false, &PVCLockInfo{
-						ControllerPodName: pvc.Annotations["dr-syncer.io/lock-owner"],
-						Timestamp:         pvc.Annotations["dr-syncer.io/lock-timestamp"],
+						ControllerPodName: pvc.Annotations[LockOwnerAnnotation],
+						Timestamp:         pvc.Annotations[LockTimestampAnnotation],
 					}, nil
 				}
 			}
 		} else {
 			// No timestamp, but has owner - return the lock info
 			return false, &PVCLockInfo{
-				ControllerPodName: pvc.Annotations["dr-syncer.io/lock-owner"],
+				ControllerPodName: pvc.Annotations[LockOwnerAnnotation],
 				Timestamp:         "",
 			}, nil
 		}
@@ -1100,8 +1211,8 @@ func (p *PVCSyncer) AcquirePVCLock(ctx context.Context, namespace, pvcName strin
 	}
 
 	// Set lock annotations
-	pvc.Annotations["dr-syncer.io/lock-owner"] = podName
-	pvc.Annotations["dr-syncer.io/lock-timestamp"] = time.Now().UTC().Format(time.RFC3339)
+	pvc.Annotations[LockOwnerAnnotation] = podName
+	pvc.Annotations[LockTimestampAnnotation] = time.Now().UTC().Format(time.RFC3339)
 
 	// Update the PVC
 	_, err = p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{})
@@ -1120,9 +1231,10 @@ func (p *PVCSyncer) AcquirePVCLock(ctx context.Context, namespace, pvcName strin
 		"pod_name":  podName,
 	}).Info(logging.LogTagDetail + " Lock acquired on PVC")
 
+	syncstate.LockAcquired(namespace, pvcName, podName)
 	return true, &PVCLockInfo{
 		ControllerPodName: podName,
-		Timestamp:         pvc.Annotations["dr-syncer.io/lock-timestamp"],
+		Timestamp:         pvc.Annotations[LockTimestampAnnotation],
 	}, nil
 }
 
@@ -1213,7 +1325,7 @@ func (p *PVCSyncer) findPVCNodesWithClient(ctx context.Context, c client.Client,
 		}
 
 		// Get volume attachments
-		volumeAttachments, err := k8sClient.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+		volumeAttachments, err := listVolumeAttachments(ctx, k8sClient)
 		if err != nil {
 			log.WithFields(logrus.Fields{
 				"namespace": namespace,
@@ -1289,7 +1401,7 @@ func (p *PVCSyncer) ReleasePVCLock(ctx context.Context, namespace, pvcName strin
 	}
 
 	// Check if we have the lock
-	if pvc.Annotations == nil || pvc.Annotations["dr-syncer.io/lock-owner"] == "" {
+	if pvc.Annotations == nil || pvc.Annotations[LockOwnerAnnotation] == "" {
 		log.WithFields(logrus.Fields{
 			"namespace": namespace,
 			"pvc_name":  pvcName,
@@ -1304,19 +1416,20 @@ func (p *PVCSyncer) ReleasePVCLock(ctx context.Context, namespace, pvcName strin
 	}
 
 	// Only release the lock if we own it
-	if pvc.Annotations["dr-syncer.io/lock-owner"] != podName {
+	if pvc.Annotations[LockOwnerAnnotation] != podName {
 		log.WithFields(logrus.Fields{
 			"namespace":  namespace,
 			"pvc_name":   pvcName,
-			"lock_owner": pvc.Annotations["dr-syncer.io/lock-owner"],
+			"lock_owner": pvc.Annotations[LockOwnerAnnotation],
 			"our_pod":    podName,
 		}).Warn(logging.LogTagWarn + " PVC is locked by another controller, not releasing")
-		return fmt.Errorf("PVC is locked by another controller: %s", pvc.Annotations["dr-syncer.io/lock-owner"])
+		return fmt.Errorf("PVC is locked by another controller: %s", pvc.Annotations[LockOwnerAnnotation])
 	}
 
 	// Remove lock annotations
-	delete(pvc.Annotations, "dr-syncer.io/lock-owner")
-	delete(pvc.Annotations, "dr-syncer.io/lock-timestamp")
+	delete(pvc.Annotations, LockOwnerAnnotation)
+	delete(pvc.Annotations, LockTimestampAnnotation)
+	delete(pvc.Annotations, LockSyncAnnotation)
 
 	// Update the PVC
 	_, err = p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Update(ctx, pvc, metav1.UpdateOptions{})
@@ -1334,5 +1447,6 @@ func (p *PVCSyncer) ReleasePVCLock(ctx context.Context, namespace, pvcName strin
 		"pvc_name":  pvcName,
 	}).Info(logging.LogTagDetail + " Lock released on PVC")
 
+	syncstate.LockReleased(namespace, pvcName)
 	return nil
 }
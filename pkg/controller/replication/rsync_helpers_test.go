@@ -1,11 +1,17 @@
 package replication
 
 import (
+	"context"
 	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestContains_Found(t *testing.T) {
@@ -297,3 +303,40 @@ func TestMountPathCache_NodeNameVariations(t *testing.T) {
 		assert.Equal(t, nodeName, cache.NodeName, "Node name should be stored correctly: %s", nodeName)
 	}
 }
+
+func TestAgentSSHEndpoint(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+
+	rc := &drv1alpha1.RemoteCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "dr-syncer"},
+		Spec: drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{SSH: &drv1alpha1.PVCSyncSSH{
+			Port:     2022,
+			Exposure: &drv1alpha1.AgentExposure{Type: drv1alpha1.AgentExposureLoadBalancer},
+		}}},
+		Status: drv1alpha1.RemoteClusterStatus{PVCSync: &drv1alpha1.PVCSyncStatus{AgentStatus: &drv1alpha1.PVCSyncAgentStatus{
+			Endpoints: map[string]drv1alpha1.AgentEndpoint{"node-a": {Address: "agents.example.com", Port: 2023}},
+		}}},
+	}
+	p := &PVCSyncer{
+		SourceClient:            fake.NewClientBuilder().WithScheme(scheme).WithObjects(rc).Build(),
+		SourceRemoteClusterName: "prod",
+	}
+
+	address, port, err := p.AgentSSHEndpoint(ctx, "node-a", "10.0.0.1")
+	require.NoError(t, err)
+	assert.Equal(t, "agents.example.com", address)
+	assert.Equal(t, int32(2023), port)
+
+	_, _, err = p.AgentSSHEndpoint(ctx, "node-b", "10.0.0.2")
+	assert.Error(t, err, "agents behind a load balancer are not reached on the node address")
+
+	// Agents on the host network are reached on the node address until they advertise an endpoint
+	rc.Spec.PVCSync.SSH.Exposure = nil
+	p.SourceClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(rc).Build()
+	address, port, err = p.AgentSSHEndpoint(ctx, "node-b", "10.0.0.2")
+	require.NoError(t, err)
+	assert.Equal(t, "10.0.0.2", address)
+	assert.Equal(t, int32(2022), port)
+}
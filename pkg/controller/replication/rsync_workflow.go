@@ -2,6 +2,7 @@ package replication
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -13,7 +14,9 @@ import (
 
 	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
 	"github.com/supporttools/dr-syncer/pkg/agent/ssh"
+	"github.com/supporttools/dr-syncer/pkg/agent/tempod"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
 )
 
 // RsyncWorkflow orchestrates the rsync process between source and destination PVCs
@@ -21,6 +24,9 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 	// Track start time for duration calculation
 	startTime := time.Now()
 
+	// Tag the logs of the workflow with the trace of the sync
+	log := log.WithContext(ctx)
+
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
 		"source_pvc":       sourcePVCName,
@@ -28,6 +34,13 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		"dest_pvc":         destPVCName,
 	}).Info(logging.LogTagInfo + " Starting rsync workflow")
 
+	// Rsync cannot transfer raw block volumes
+	if skip, err := p.skipBlockVolume(ctx, sourceNamespace, sourcePVCName, destNamespace, destPVCName); err != nil {
+		return err
+	} else if skip {
+		return nil
+	}
+
 	// Emit SyncStarted event
 	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncStarted,
 		"Starting PVC data sync to %s/%s", destNamespace, destPVCName)
@@ -71,6 +84,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		}
 	}()
 
+	tracing.Step(ctx, "lock")
 	// Step 0: Try to acquire a lock on the source PVC
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
@@ -98,6 +112,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		// Emit SyncSkipped event
 		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped,
 			"PVC is locked by %s, skipping sync", lockInfo.ControllerPodName)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonLocked, "")
 		return nil
 	}
 
@@ -138,6 +153,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonLockAcquired,
 		"Acquired sync lock for PVC")
 
+	tracing.Step(ctx, "deploy")
 	// Step 1: Deploy rsync deployment in destination cluster and wait for it to be ready
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
@@ -153,9 +169,22 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 			"error": err,
 		}).Error(logging.LogTagError + " Failed to deploy rsync pod in destination cluster")
 
-		// Emit SyncFailed event
-		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed,
-			"Failed to deploy rsync pod: %v", err)
+		// Emit PodStartupFailed when the rsync pod cannot start, SyncFailed otherwise
+		var startupErr *tempod.PodStartupError
+		if errors.As(err, &startupErr) {
+			p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonPodStartupFailed,
+				"Rsync pod %s cannot start: %s: %s", startupErr.Name, startupErr.Reason, startupErr.Message)
+			if statusErr := p.FailedSyncStatus(ctx, sourceNamespace, sourcePVCName, err); statusErr != nil {
+				log.WithFields(logrus.Fields{
+					"source_namespace": sourceNamespace,
+					"source_pvc":       sourcePVCName,
+					"error":            statusErr,
+				}).Warn(logging.LogTagWarn + " Failed to update sync status")
+			}
+		} else {
+			p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed,
+				"Failed to deploy rsync pod: %v", err)
+		}
 
 		// Release the lock since we're failing
 		if lockAcquired {
@@ -167,14 +196,16 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
 			}
 		}
-		return fmt.Errorf("failed to deploy rsync pod in destination cluster: %v", err)
+		return fmt.Errorf("failed to deploy rsync pod in destination cluster: %w", err)
 	}
 	log.Info(logging.LogTagStep1Complete + " Rsync pod deployed successfully")
+	p.recordLockSync(ctx, sourceNamespace, sourcePVCName, destRsyncPod)
 
 	// Emit RsyncPodDeployed event
 	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonRsyncPodDeployed,
 		"Rsync pod deployed in destination cluster")
 
+	tracing.Step(ctx, "key")
 	// Steps 2-3: Generate SSH keys and get public key (skip if using cached keys)
 	var publicKey string
 	if destRsyncPod.HasCachedKeys {
@@ -243,13 +274,48 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		log.Info(logging.LogTagStep3Complete + " Public key retrieved successfully")
 	}
 
+	// Read the data of a PVC that asks for a consistent sync from a snapshot of it
+	dataPVCName := sourcePVCName
+	sourcePVC, consistency, err := p.sourceConsistency(ctx, sourceNamespace, sourcePVCName)
+	if err == nil && consistency == ConsistencySnapshot {
+		var releaseSnapshot func()
+		dataPVCName, releaseSnapshot, err = p.snapshotSourcePVC(ctx, sourcePVC)
+		if err == nil {
+			defer releaseSnapshot()
+		}
+	}
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"source_namespace": sourceNamespace,
+			"source_pvc":       sourcePVCName,
+			"consistency":      consistency,
+			"error":            err,
+		}).Error(logging.LogTagError + " Failed to prepare a consistent sync of source PVC")
+
+		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed,
+			"Failed to prepare a consistent sync: %v", err)
+
+		p.cleanupResources(ctx, destRsyncPod)
+		if lockAcquired {
+			if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+				log.WithFields(logrus.Fields{
+					"source_namespace": sourceNamespace,
+					"source_pvc":       sourcePVCName,
+					"error":            relErr,
+				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
+			}
+		}
+		return fmt.Errorf("failed to prepare a consistent sync: %v", err)
+	}
+
+	tracing.Step(ctx, "locate-source")
 	// Step 4: Check if source PVC is mounted
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
 		"source_pvc":       sourcePVCName,
 	}).Info(logging.LogTagStep4 + " Checking if source PVC is mounted")
 
-	mounted, err := p.HasVolumeAttachments(ctx, sourceNamespace, sourcePVCName)
+	mounted, err := p.HasVolumeAttachments(ctx, sourceNamespace, dataPVCName)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"source_namespace": sourceNamespace,
@@ -273,15 +339,43 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		return fmt.Errorf("failed to check if source PVC is mounted: %v", err)
 	}
 
+	// Mount an unmounted source PVC in a temporary attach pod when syncUnmounted is enabled
+	// and always for the PVC restored from a snapshot
+	if !mounted && (p.usesAttachPod() || dataPVCName != sourcePVCName) {
+		attachPod, err := p.attachSourcePVC(ctx, sourceNamespace, dataPVCName)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"source_namespace": sourceNamespace,
+				"source_pvc":       sourcePVCName,
+				"error":            err,
+			}).Error(logging.LogTagError + " Failed to mount unmounted source PVC in an attach pod")
+
+			p.cleanupResources(ctx, destRsyncPod)
+			if lockAcquired {
+				if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+					log.WithFields(logrus.Fields{
+						"source_namespace": sourceNamespace,
+						"source_pvc":       sourcePVCName,
+						"error":            relErr,
+					}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
+				}
+			}
+			return fmt.Errorf("failed to mount unmounted source PVC: %v", err)
+		}
+		defer p.deleteAttachPod(ctx, sourceNamespace, dataPVCName, attachPod.Name)
+		mounted = true
+	}
+
 	if !mounted {
 		log.WithFields(logrus.Fields{
 			"source_namespace": sourceNamespace,
 			"source_pvc":       sourcePVCName,
 		}).Info(logging.LogTagSkip + " Source PVC is not mounted, skipping rsync")
 
-		// Emit SyncSkipped event
-		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped,
-			"Source PVC is not mounted by any pod, skipping sync")
+		// Emit SyncSkipped event and report the skip on the PVC
+		message := notMountedMessage
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped, "%s", message)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonNotMounted, message)
 
 		// Clean up resources before returning
 		p.cleanupResources(ctx, destRsyncPod)
@@ -307,7 +401,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 	}).Info(logging.LogTagStep5 + " Finding node where source PVC is mounted")
 
 	// Find the node where the PVC is mounted
-	sourceNode, err := p.FindPVCNode(ctx, p.SourceClient, sourceNamespace, sourcePVCName)
+	sourceNode, err := p.FindPVCNode(ctx, p.SourceClient, sourceNamespace, dataPVCName)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"source_namespace": sourceNamespace,
@@ -341,6 +435,11 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 	}).Info(logging.LogTagStep6 + " Finding DR-Syncer-Agent on node")
 
 	agentPod, nodeIP, err := p.FindAgentPod(ctx, sourceNode)
+	var sshPort int32
+	if err == nil {
+		// Connect to the endpoint the agent SSH port is exposed on
+		nodeIP, sshPort, err = p.AgentSSHEndpoint(ctx, sourceNode, nodeIP)
+	}
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"node":  sourceNode,
@@ -367,6 +466,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		"agent_pod": agentPod.Name,
 		"node_ip":   nodeIP,
 	}).Info(logging.LogTagStep6Complete + " Found DR-Syncer-Agent")
+	ctx = withSSHPort(ctx, sshPort)
 
 	// Step 7: Find the mount path for the PVC
 	log.WithFields(logrus.Fields{
@@ -375,7 +475,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		"agent_pod":        agentPod.Name,
 	}).Info(logging.LogTagStep7 + " Finding mount path for PVC")
 
-	mountPath, err := p.FindPVCMountPath(ctx, sourceNamespace, sourcePVCName, agentPod)
+	mountPath, err := p.FindPVCMountPath(ctx, sourceNamespace, dataPVCName, agentPod)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"source_namespace": sourceNamespace,
@@ -403,6 +503,37 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		"mount_path": mountPath,
 	}).Info(logging.LogTagStep7Complete + " Found mount path for PVC")
 
+	// Skip the data phase when nothing changed since the last successful sync
+	if p.skipUnchanged(ctx, sourceNamespace, sourcePVCName, agentPod, mountPath) {
+		p.cleanupResources(ctx, destRsyncPod)
+		if lockAcquired {
+			if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+				log.WithFields(logrus.Fields{
+					"source_namespace": sourceNamespace,
+					"source_pvc":       sourcePVCName,
+					"error":            relErr,
+				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after skipping")
+			}
+		}
+		return nil
+	}
+
+	// Make sure the destination PVC can hold the source data before rsync starts
+	if err := p.checkDestinationSpace(ctx, sourceNamespace, sourcePVCName, destNamespace, destPVCName, agentPod, mountPath); err != nil {
+		p.cleanupResources(ctx, destRsyncPod)
+		if lockAcquired {
+			if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+				log.WithFields(logrus.Fields{
+					"source_namespace": sourceNamespace,
+					"source_pvc":       sourcePVCName,
+					"error":            relErr,
+				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
+			}
+		}
+		return err
+	}
+
+	tracing.Step(ctx, "push-key")
 	// Step 8: Push the public key to the agent pod (skip if using cached keys)
 	if destRsyncPod.HasCachedKeys {
 		log.WithFields(logrus.Fields{
@@ -439,6 +570,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		log.Info(logging.LogTagStep8Complete + " Public key pushed to agent pod")
 	}
 
+	tracing.Step(ctx, "connectivity")
 	// Step 9: Test SSH connectivity
 	log.WithFields(logrus.Fields{
 		"dest_pod": destRsyncPod.Name,
@@ -446,7 +578,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 	}).Info(logging.LogTagStep9 + " Testing SSH connectivity")
 
 	// Test SSH connectivity to make sure we can reach the agent
-	err = p.TestSSHConnectivity(ctx, destRsyncPod, nodeIP, 2222, p.DestinationConfig)
+	err = p.TestSSHConnectivity(ctx, destRsyncPod, nodeIP, int(sshPort), p.DestinationConfig)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"dest_pod": destRsyncPod.Name,
@@ -475,6 +607,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSSHConnected,
 		"SSH connectivity established to source agent on node %s", sourceNode)
 
+	tracing.Step(ctx, "rsync")
 	// Step 10: Run rsync command using the node's external IP
 	log.WithFields(logrus.Fields{
 		"dest_pod":   destRsyncPod.Name,
@@ -482,7 +615,13 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 		"mount_path": mountPath,
 	}).Info(logging.LogTagStep10 + " Running rsync command")
 
-	if err := p.performRsync(ctx, destRsyncPod, nodeIP, mountPath); err != nil {
+	// Freeze the source filesystem or quiesce the pods using it while rsync runs
+	resume, err := p.quiesceSource(ctx, sourcePVC, consistency, agentPod, mountPath)
+	if err == nil {
+		err = p.performRsyncFromAgent(ctx, agentPod, destRsyncPod, nodeIP, mountPath)
+		resume()
+	}
+	if err != nil {
 		log.WithFields(logrus.Fields{
 			"dest_pod":   destRsyncPod.Name,
 			"node_ip":    nodeIP,
@@ -507,10 +646,11 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
 			}
 		}
-		return fmt.Errorf("failed to perform rsync: %v", err)
+		return fmt.Errorf("failed to perform rsync: %w", err)
 	}
 	log.Info(logging.LogTagStep10Complete + " Rsync completed successfully")
 
+	tracing.Step(ctx, "annotations")
 	// Step 11: Update source PVC annotations
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
@@ -541,6 +681,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 	}
 	log.Info(logging.LogTagStep11Complete + " Source PVC annotations updated successfully")
 
+	tracing.Step(ctx, "cleanup")
 	// Step 12: Clean up resources
 	log.WithFields(logrus.Fields{
 		"dest_pod": destRsyncPod.Name,
@@ -549,6 +690,7 @@ func (p *PVCSyncer) RsyncWorkflow(ctx context.Context, sourceNamespace, sourcePV
 	p.cleanupResources(ctx, destRsyncPod)
 	log.Info(logging.LogTagStep12Complete + " Resource cleanup completed")
 
+	tracing.Step(ctx, "unlock")
 	// Step 13: Release the lock on the source PVC
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
@@ -637,7 +779,7 @@ func (p *PVCSyncer) deployRsyncPod(ctx context.Context, namespace, pvcName strin
 	}
 
 	// Create rsync pod options
-	opts := rsyncpod.RsyncPodOptions{
+	opts := p.rsyncPodOptions(rsyncpod.RsyncPodOptions{
 		Namespace:           namespace,
 		PVCName:             pvcName,
 		Type:                rsyncpod.DestinationPodType,
@@ -645,7 +787,7 @@ func (p *PVCSyncer) deployRsyncPod(ctx context.Context, namespace, pvcName strin
 		ReplicationName:     fmt.Sprintf("pvc-sync-%s-%s", namespace, pvcName),
 		DestinationInfo:     fmt.Sprintf("destination-%s-%s", namespace, pvcName),
 		CachedKeySecretName: cachedKeySecretName, // Will be empty if no cached keys
-	}
+	})
 
 	// Create the rsync deployment
 	rsyncDeployment, err := rsyncMgr.CreateRsyncDeployment(ctx, opts)
@@ -656,7 +798,7 @@ func (p *PVCSyncer) deployRsyncPod(ctx context.Context, namespace, pvcName strin
 	// Wait for the deployment to be ready
 	timeout := 5 * time.Minute
 	if err := rsyncDeployment.WaitForPodReady(ctx, timeout); err != nil {
-		return nil, fmt.Errorf("timeout waiting for rsync deployment to be ready: %v", err)
+		return nil, fmt.Errorf("rsync deployment did not become ready: %w", err)
 	}
 
 	log.WithFields(logrus.Fields{
@@ -796,7 +938,8 @@ func (p *PVCSyncer) cleanupDaemonSetResources(ctx context.Context, dsPod *rsyncp
 // This method:
 // 1. Finds the node where the destination PVC is (or should be) mounted
 // 2. Finds the DaemonSet pod on that node
-// 3. Resolves the destination path using the hybrid approach (kubelet path or TempPod)
+// 3. Binds an unbound WaitForFirstConsumer PVC on that node
+// 4. Resolves the destination path using the hybrid approach (kubelet path or TempPod)
 func (p *PVCSyncer) findRsyncDaemonSetPod(ctx context.Context, destNamespace, destPVCName string) (*rsyncpod.RsyncDaemonSetPod, error) {
 	log.WithFields(logrus.Fields{
 		"namespace": destNamespace,
@@ -809,6 +952,7 @@ func (p *PVCSyncer) findRsyncDaemonSetPod(ctx context.Context, destNamespace, de
 
 	// Step 1: Find the node where the destination PVC should be written
 	// First, try to find an existing node where the PVC is already mounted
+	var binding volumeBinding
 	destNode, err := p.FindPVCNode(ctx, p.DestinationClient, destNamespace, destPVCName)
 	if err != nil {
 		// If PVC is not mounted anywhere, we need to pick a node
@@ -829,7 +973,13 @@ func (p *PVCSyncer) findRsyncDaemonSetPod(ctx context.Context, destNamespace, de
 			return nil, fmt.Errorf("no ready rsync DaemonSet pods available")
 		}
 
-		// List DaemonSet pods and pick the first ready one
+		// The volume of the PVC may only be reachable from, or provisionable on, some nodes
+		binding, err = p.destinationBinding(ctx, destNamespace, destPVCName)
+		if err != nil {
+			return nil, err
+		}
+
+		// List DaemonSet pods and pick a running one on a node the volume can be written from
 		pods, err := p.DestinationK8sClient.CoreV1().Pods(p.RsyncDaemonSet.Namespace).List(ctx, metav1.ListOptions{
 			LabelSelector: fmt.Sprintf("%s=%s", rsyncpod.RsyncDaemonSetLabelKey, rsyncpod.RsyncDaemonSetLabelValue),
 		})
@@ -837,15 +987,9 @@ func (p *PVCSyncer) findRsyncDaemonSetPod(ctx context.Context, destNamespace, de
 			return nil, fmt.Errorf("failed to list rsync DaemonSet pods: %w", err)
 		}
 
-		for _, pod := range pods.Items {
-			if pod.Status.Phase == corev1.PodRunning {
-				destNode = pod.Spec.NodeName
-				break
-			}
-		}
-
-		if destNode == "" {
-			return nil, fmt.Errorf("no running rsync DaemonSet pods found")
+		destNode, err = p.selectDestinationNode(ctx, pods.Items, binding)
+		if err != nil {
+			return nil, err
 		}
 	}
 
@@ -868,8 +1012,26 @@ func (p *PVCSyncer) findRsyncDaemonSetPod(ctx context.Context, destNamespace, de
 		"pod":       dsPod.Name,
 	}).Info(logging.LogTagDetail + " Found DaemonSet pod on target node")
 
-	// Step 3: Resolve the destination path using hybrid approach
-	destPath, cleanup, err := p.RsyncDaemonSet.ResolveDestinationPath(ctx, destNode, destNamespace, destPVCName)
+	// Step 3: Bind a WaitForFirstConsumer PVC on the node before its path is resolved, a placeholder
+	// pod pinned to the node would never trigger its provisioning
+	if binding.waitForConsumer {
+		if err := p.bindDestinationVolume(ctx, destNode, destNamespace, destPVCName); err != nil {
+			return nil, err
+		}
+	}
+
+	// Step 4: Resolve the destination path using hybrid approach, keeping unmounted PVCs
+	// attached to warm pool pods when the mapping asks for it
+	var (
+		destPath string
+		cleanup  func()
+	)
+	if p.keepWarm(ctx) {
+		destPath, err = p.RsyncDaemonSet.ResolveWarmDestinationPath(ctx, destNode, destNamespace, destPVCName)
+	} else {
+		destPath, cleanup, err = p.RsyncDaemonSet.ResolveDestinationPath(ctx, destNode, destNamespace, destPVCName)
+		cleanup = p.releaseWarmPoolAfter(cleanup, destNamespace, destPVCName)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to resolve destination path for PVC %s/%s: %w", destNamespace, destPVCName, err)
 	}
@@ -891,6 +1053,9 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 	// Track start time for duration calculation
 	startTime := time.Now()
 
+	// Tag the logs of the workflow with the trace of the sync
+	log := log.WithContext(ctx)
+
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
 		"source_pvc":       sourcePVCName,
@@ -899,6 +1064,13 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		"mode":             "daemonset",
 	}).Info(logging.LogTagInfo + " Starting rsync workflow with DaemonSet pool (fast path)")
 
+	// Rsync cannot transfer raw block volumes
+	if skip, err := p.skipBlockVolume(ctx, sourceNamespace, sourcePVCName, destNamespace, destPVCName); err != nil {
+		return err
+	} else if skip {
+		return nil
+	}
+
 	// Emit SyncStarted event
 	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncStarted,
 		"Starting PVC data sync to %s/%s (DaemonSet mode)", destNamespace, destPVCName)
@@ -940,6 +1112,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		}
 	}()
 
+	tracing.Step(ctx, "lock")
 	// Step 0: Try to acquire a lock on the source PVC
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
@@ -966,6 +1139,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 
 		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped,
 			"PVC is locked by %s, skipping sync", lockInfo.ControllerPodName)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonLocked, "")
 		return nil
 	}
 
@@ -1012,6 +1186,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 	}
 	log.Info(logging.LogTagInfo + " Rsync DaemonSet deployed/verified successfully")
 
+	tracing.Step(ctx, "deploy")
 	// Step 1: Find DaemonSet pod (FAST - no deployment creation)
 	log.WithFields(logrus.Fields{
 		"dest_namespace": destNamespace,
@@ -1026,8 +1201,13 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 			"error":          err,
 		}).Error(logging.LogTagError + " Failed to find DaemonSet pod")
 
-		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed,
-			"Failed to find DaemonSet rsync pod: %v", err)
+		if errors.Is(err, ErrPendingBinding) {
+			p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonPendingBinding,
+				"Destination PVC %s/%s is pending binding: %v", destNamespace, destPVCName, err)
+		} else {
+			p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed,
+				"Failed to find DaemonSet rsync pod: %v", err)
+		}
 
 		if lockAcquired {
 			if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
@@ -1038,7 +1218,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
 			}
 		}
-		return fmt.Errorf("failed to find DaemonSet pod: %v", err)
+		return fmt.Errorf("failed to find DaemonSet pod: %w", err)
 	}
 	log.WithFields(logrus.Fields{
 		"pod_name":  dsPod.PodName,
@@ -1052,13 +1232,48 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 	log.Info(logging.LogTagStep3 + " Skipping public key retrieval - using cached keys")
 	log.Info(logging.LogTagStep3Complete + " Public key already provisioned on agent")
 
+	// Read the data of a PVC that asks for a consistent sync from a snapshot of it
+	dataPVCName := sourcePVCName
+	sourcePVC, consistency, err := p.sourceConsistency(ctx, sourceNamespace, sourcePVCName)
+	if err == nil && consistency == ConsistencySnapshot {
+		var releaseSnapshot func()
+		dataPVCName, releaseSnapshot, err = p.snapshotSourcePVC(ctx, sourcePVC)
+		if err == nil {
+			defer releaseSnapshot()
+		}
+	}
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"source_namespace": sourceNamespace,
+			"source_pvc":       sourcePVCName,
+			"consistency":      consistency,
+			"error":            err,
+		}).Error(logging.LogTagError + " Failed to prepare a consistent sync of source PVC")
+
+		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed,
+			"Failed to prepare a consistent sync: %v", err)
+
+		p.cleanupDaemonSetResources(ctx, dsPod)
+		if lockAcquired {
+			if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+				log.WithFields(logrus.Fields{
+					"source_namespace": sourceNamespace,
+					"source_pvc":       sourcePVCName,
+					"error":            relErr,
+				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
+			}
+		}
+		return fmt.Errorf("failed to prepare a consistent sync: %v", err)
+	}
+
+	tracing.Step(ctx, "locate-source")
 	// Step 4: Check if source PVC is mounted
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
 		"source_pvc":       sourcePVCName,
 	}).Info(logging.LogTagStep4 + " Checking if source PVC is mounted")
 
-	mounted, err := p.HasVolumeAttachments(ctx, sourceNamespace, sourcePVCName)
+	mounted, err := p.HasVolumeAttachments(ctx, sourceNamespace, dataPVCName)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"source_namespace": sourceNamespace,
@@ -1079,14 +1294,42 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		return fmt.Errorf("failed to check if source PVC is mounted: %v", err)
 	}
 
+	// Mount an unmounted source PVC in a temporary attach pod when syncUnmounted is enabled
+	// and always for the PVC restored from a snapshot
+	if !mounted && (p.usesAttachPod() || dataPVCName != sourcePVCName) {
+		attachPod, err := p.attachSourcePVC(ctx, sourceNamespace, dataPVCName)
+		if err != nil {
+			log.WithFields(logrus.Fields{
+				"source_namespace": sourceNamespace,
+				"source_pvc":       sourcePVCName,
+				"error":            err,
+			}).Error(logging.LogTagError + " Failed to mount unmounted source PVC in an attach pod")
+
+			p.cleanupDaemonSetResources(ctx, dsPod)
+			if lockAcquired {
+				if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+					log.WithFields(logrus.Fields{
+						"source_namespace": sourceNamespace,
+						"source_pvc":       sourcePVCName,
+						"error":            relErr,
+					}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
+				}
+			}
+			return fmt.Errorf("failed to mount unmounted source PVC: %v", err)
+		}
+		defer p.deleteAttachPod(ctx, sourceNamespace, dataPVCName, attachPod.Name)
+		mounted = true
+	}
+
 	if !mounted {
 		log.WithFields(logrus.Fields{
 			"source_namespace": sourceNamespace,
 			"source_pvc":       sourcePVCName,
 		}).Info(logging.LogTagSkip + " Source PVC is not mounted, skipping rsync")
 
-		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped,
-			"Source PVC is not mounted by any pod, skipping sync")
+		message := notMountedMessage
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped, "%s", message)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonNotMounted, message)
 
 		p.cleanupDaemonSetResources(ctx, dsPod)
 		if lockAcquired {
@@ -1108,7 +1351,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		"source_pvc":       sourcePVCName,
 	}).Info(logging.LogTagStep5 + " Finding node where source PVC is mounted")
 
-	sourceNode, err := p.FindPVCNode(ctx, p.SourceClient, sourceNamespace, sourcePVCName)
+	sourceNode, err := p.FindPVCNode(ctx, p.SourceClient, sourceNamespace, dataPVCName)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"source_namespace": sourceNamespace,
@@ -1138,6 +1381,11 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 	}).Info(logging.LogTagStep6 + " Finding DR-Syncer-Agent on node")
 
 	agentPod, nodeIP, err := p.FindAgentPod(ctx, sourceNode)
+	var sshPort int32
+	if err == nil {
+		// Connect to the endpoint the agent SSH port is exposed on
+		nodeIP, sshPort, err = p.AgentSSHEndpoint(ctx, sourceNode, nodeIP)
+	}
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"node":  sourceNode,
@@ -1161,6 +1409,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		"agent_pod": agentPod.Name,
 		"node_ip":   nodeIP,
 	}).Info(logging.LogTagStep6Complete + " Found DR-Syncer-Agent")
+	ctx = withSSHPort(ctx, sshPort)
 
 	// Step 7: Find the mount path for the source PVC
 	log.WithFields(logrus.Fields{
@@ -1169,7 +1418,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		"agent_pod":        agentPod.Name,
 	}).Info(logging.LogTagStep7 + " Finding mount path for PVC")
 
-	mountPath, err := p.FindPVCMountPath(ctx, sourceNamespace, sourcePVCName, agentPod)
+	mountPath, err := p.FindPVCMountPath(ctx, sourceNamespace, dataPVCName, agentPod)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"source_namespace": sourceNamespace,
@@ -1194,12 +1443,43 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		"mount_path": mountPath,
 	}).Info(logging.LogTagStep7Complete + " Found mount path for PVC")
 
+	// Skip the data phase when nothing changed since the last successful sync
+	if p.skipUnchanged(ctx, sourceNamespace, sourcePVCName, agentPod, mountPath) {
+		p.cleanupDaemonSetResources(ctx, dsPod)
+		if lockAcquired {
+			if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+				log.WithFields(logrus.Fields{
+					"source_namespace": sourceNamespace,
+					"source_pvc":       sourcePVCName,
+					"error":            relErr,
+				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after skipping")
+			}
+		}
+		return nil
+	}
+
+	// Make sure the destination PVC can hold the source data before rsync starts
+	if err := p.checkDestinationSpace(ctx, sourceNamespace, sourcePVCName, destNamespace, destPVCName, agentPod, mountPath); err != nil {
+		p.cleanupDaemonSetResources(ctx, dsPod)
+		if lockAcquired {
+			if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+				log.WithFields(logrus.Fields{
+					"source_namespace": sourceNamespace,
+					"source_pvc":       sourcePVCName,
+					"error":            relErr,
+				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
+			}
+		}
+		return err
+	}
+
 	// DaemonSet pods have pre-provisioned SSH keys - skip step 8 (push public key)
 	log.WithFields(logrus.Fields{
 		"agent_pod": agentPod.Name,
 	}).Info(logging.LogTagStep8 + " Skipping public key push - agent already has authorized_keys from cached secret")
 	log.Info(logging.LogTagStep8Complete + " Public key already provisioned on agent via cached secret")
 
+	tracing.Step(ctx, "connectivity")
 	// Step 9: Test SSH connectivity
 	log.WithFields(logrus.Fields{
 		"dest_pod": dsPod.PodName,
@@ -1216,7 +1496,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		HasCachedKeys: true,
 	}
 
-	err = p.TestSSHConnectivity(ctx, tempDeployment, nodeIP, 2222, p.DestinationConfig)
+	err = p.TestSSHConnectivity(ctx, tempDeployment, nodeIP, int(sshPort), p.DestinationConfig)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"dest_pod": dsPod.PodName,
@@ -1241,6 +1521,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSSHConnected,
 		"SSH connectivity established to source agent on node %s", sourceNode)
 
+	tracing.Step(ctx, "rsync")
 	// Step 10: Run rsync command using the DaemonSet pod and kubelet path
 	log.WithFields(logrus.Fields{
 		"dest_pod":   dsPod.PodName,
@@ -1249,7 +1530,13 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 		"dest_path":  dsPod.DestinationPath,
 	}).Info(logging.LogTagStep10 + " Running rsync command with kubelet destination path")
 
-	if err := p.performRsyncWithDaemonSet(ctx, dsPod, nodeIP, mountPath); err != nil {
+	// Freeze the source filesystem or quiesce the pods using it while rsync runs
+	resume, err := p.quiesceSource(ctx, sourcePVC, consistency, agentPod, mountPath)
+	if err == nil {
+		err = p.performRsyncWithDaemonSet(ctx, agentPod, dsPod, nodeIP, mountPath)
+		resume()
+	}
+	if err != nil {
 		log.WithFields(logrus.Fields{
 			"dest_pod":   dsPod.PodName,
 			"node_ip":    nodeIP,
@@ -1271,10 +1558,11 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 				}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after failure")
 			}
 		}
-		return fmt.Errorf("failed to perform rsync: %v", err)
+		return fmt.Errorf("failed to perform rsync: %w", err)
 	}
 	log.Info(logging.LogTagStep10Complete + " Rsync completed successfully")
 
+	tracing.Step(ctx, "annotations")
 	// Step 11: Update source PVC annotations
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
@@ -1302,6 +1590,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 	}
 	log.Info(logging.LogTagStep11Complete + " Source PVC annotations updated successfully")
 
+	tracing.Step(ctx, "cleanup")
 	// Step 12: Clean up temporary resources (not the DaemonSet pod itself)
 	log.WithFields(logrus.Fields{
 		"dest_pod": dsPod.PodName,
@@ -1310,6 +1599,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 	p.cleanupDaemonSetResources(ctx, dsPod)
 	log.Info(logging.LogTagStep12Complete + " Resource cleanup completed")
 
+	tracing.Step(ctx, "unlock")
 	// Step 13: Release the lock on the source PVC
 	log.WithFields(logrus.Fields{
 		"source_namespace": sourceNamespace,
@@ -1346,7 +1636,7 @@ func (p *PVCSyncer) RsyncWorkflowWithDaemonSet(ctx context.Context, sourceNamesp
 }
 
 // performRsyncWithDaemonSet executes rsync using a DaemonSet pod with kubelet path destination
-func (p *PVCSyncer) performRsyncWithDaemonSet(ctx context.Context, dsPod *rsyncpod.RsyncDaemonSetPod, nodeIP, sourcePath string) error {
+func (p *PVCSyncer) performRsyncWithDaemonSet(ctx context.Context, agentPod *corev1.Pod, dsPod *rsyncpod.RsyncDaemonSetPod, nodeIP, sourcePath string) error {
 	log.WithFields(logrus.Fields{
 		"pod_name":    dsPod.PodName,
 		"node_ip":     nodeIP,
@@ -1367,7 +1657,7 @@ func (p *PVCSyncer) performRsyncWithDaemonSet(ctx context.Context, dsPod *rsyncp
 	// Store the destination path in context for performRsync to use
 	dsCtx := context.WithValue(ctx, daemonSetDestPathKey, dsPod.DestinationPath)
 
-	return p.performRsync(dsCtx, tempDeployment, nodeIP, sourcePath)
+	return p.performRsyncFromAgent(dsCtx, agentPod, tempDeployment, nodeIP, sourcePath)
 }
 
 // daemonSetDestPathKeyType is the type for the DaemonSet destination path context key
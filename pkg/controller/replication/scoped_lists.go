@@ -0,0 +1,36 @@
+package replication
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// agentNamespace is the namespace the agents run in on the remote clusters
+const agentNamespace = "dr-syncer"
+
+// listAgentPods lists the agent pods matching selector. They are only looked up in the agent namespace,
+// so remote cluster credentials limited to some namespaces find them.
+func listAgentPods(ctx context.Context, c kubernetes.Interface, selector string) (*corev1.PodList, error) {
+	return c.CoreV1().Pods(agentNamespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+}
+
+// listVolumeAttachments lists the VolumeAttachments of a cluster. They are cluster-scoped: credentials
+// not allowed to list them, such as those of namespace-scoped installs, get an empty list, and the nodes
+// of a volume are only found from the pods mounting it.
+func listVolumeAttachments(ctx context.Context, c kubernetes.Interface) (*storagev1.VolumeAttachmentList, error) {
+	attachments, err := c.StorageV1().VolumeAttachments().List(ctx, metav1.ListOptions{})
+	if apierrors.IsForbidden(err) {
+		log.WithFields(logrus.Fields{
+			"error": err,
+		}).Debug(logging.LogTagDetail + " Not allowed to list volume attachments, finding volume nodes from pods only")
+		return &storagev1.VolumeAttachmentList{}, nil
+	}
+	return attachments, err
+}
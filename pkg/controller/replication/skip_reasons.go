@@ -0,0 +1,60 @@
+package replication
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Reasons recorded when the data sync of a PVC is skipped, so a skipped PVC is not mistaken for a synced one
+const (
+	// SkipReasonNotMounted is recorded when no pod mounts the source PVC and pvcConfig.syncUnmounted is disabled
+	SkipReasonNotMounted = "NotMounted"
+
+	// SkipReasonNotBound is recorded when the source PVC is not bound to a volume
+	SkipReasonNotBound = "NotBound"
+
+	// SkipReasonLocked is recorded when another controller holds the sync lock of the source PVC
+	SkipReasonLocked = "Locked"
+
+	// SkipReasonExcluded is recorded when the source PVC is labeled to be ignored by dr-syncer
+	SkipReasonExcluded = "Excluded"
+
+	// SkipReasonAnnotation holds the reason the last data sync of a source PVC was skipped
+	SkipReasonAnnotation = "dr-syncer.io/skip-reason"
+
+	// notMountedMessage explains why the data sync of a source PVC that no pod mounts is skipped
+	notMountedMessage = "Source PVC is not mounted by any pod, skipping sync; set pvcConfig.syncUnmounted to sync it"
+)
+
+// recordSkip marks the source PVC as Skipped with reason and counts the skip. A PVC locked by another
+// controller keeps its sync status, which the sync of the lock owner reports.
+func (p *PVCSyncer) recordSkip(ctx context.Context, namespace, pvcName, reason, message string) {
+	RecordSyncSkipped(namespace, pvcName, p.DestinationNamespace, reason)
+	if reason == SkipReasonLocked {
+		return
+	}
+
+	if err := p.SkippedSyncStatus(ctx, namespace, pvcName, reason, message); err != nil {
+		log.WithFields(logrus.Fields{
+			"source_namespace": namespace,
+			"source_pvc":       pvcName,
+			"reason":           reason,
+			"error":            err,
+		}).Warn(logging.LogTagWarn + " Failed to update sync status for skipped PVC")
+	}
+}
+
+// RecordExcludedPVC marks a source PVC that is labeled to be ignored as Skipped, so its missing data
+// sync is visible on the PVC and in the skipped sync metric. The PVC is only updated once.
+func RecordExcludedPVC(ctx context.Context, sourceClient kubernetes.Interface, pvc *corev1.PersistentVolumeClaim, destNamespace string) {
+	if pvc.Annotations[SkipReasonAnnotation] == SkipReasonExcluded {
+		RecordSyncSkipped(pvc.Namespace, pvc.Name, destNamespace, SkipReasonExcluded)
+		return
+	}
+	p := &PVCSyncer{SourceK8sClient: sourceClient, DestinationNamespace: destNamespace}
+	p.recordSkip(ctx, pvc.Namespace, pvc.Name, SkipReasonExcluded, "PVC is labeled dr-syncer.io/ignore, skipping data sync")
+}
@@ -0,0 +1,100 @@
+package replication
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func skipStatus(t *testing.T, pvc *corev1.PersistentVolumeClaim) SyncStatus {
+	t.Helper()
+	var status SyncStatus
+	require.NoError(t, json.Unmarshal([]byte(pvc.Annotations["dr-syncer.io/sync-status"]), &status))
+	return status
+}
+
+func TestRecordSkip(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(newTestPVC("app", "data", nil))
+	p := &PVCSyncer{SourceK8sClient: client, DestinationNamespace: "app-dr"}
+
+	p.recordSkip(ctx, "app", "data", SkipReasonNotMounted, "not mounted")
+	pvc, err := client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "Skipped", pvc.Annotations["dr-syncer.io/phase"])
+	assert.Equal(t, SkipReasonNotMounted, pvc.Annotations[SkipReasonAnnotation])
+	assert.Equal(t, "not mounted", skipStatus(t, pvc).Message)
+	assert.Equal(t, 1.0, testutil.ToFloat64(PVCSyncSkipped.WithLabelValues("app", "data", "app-dr", SkipReasonNotMounted)))
+
+	// A locked PVC keeps the status of the lock owner's sync
+	p.recordSkip(ctx, "app", "data", SkipReasonLocked, "")
+	pvc, err = client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, SkipReasonNotMounted, pvc.Annotations[SkipReasonAnnotation])
+	assert.Equal(t, 1.0, testutil.ToFloat64(PVCSyncSkipped.WithLabelValues("app", "data", "app-dr", SkipReasonLocked)))
+
+	// The skip reason is cleared by the next sync
+	require.NoError(t, p.UpdateSyncStatus(ctx, "app", "data", SyncStatus{Phase: "Running"}))
+	pvc, err = client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, pvc.Annotations, SkipReasonAnnotation)
+}
+
+func TestRecordExcludedPVC(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(newTestPVC("shop", "cache", nil))
+	pvc, err := client.CoreV1().PersistentVolumeClaims("shop").Get(ctx, "cache", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	RecordExcludedPVC(ctx, client, pvc, "shop-dr")
+	pvc, err = client.CoreV1().PersistentVolumeClaims("shop").Get(ctx, "cache", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, SkipReasonExcluded, pvc.Annotations[SkipReasonAnnotation])
+	actions := len(client.Actions())
+
+	// An excluded PVC is only updated once but every skip is counted
+	RecordExcludedPVC(ctx, client, pvc, "shop-dr")
+	assert.Len(t, client.Actions(), actions)
+	assert.Equal(t, 2.0, testutil.ToFloat64(PVCSyncSkipped.WithLabelValues("shop", "cache", "shop-dr", SkipReasonExcluded)))
+}
+
+func TestSyncPVCWithNamespaceMapping_Skips(t *testing.T) {
+	ctx := context.Background()
+	mapping := &drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{Name: "pvc-sync-data"}}
+
+	pending := newTestPVC("web", "pending", nil)
+	pending.Status.Phase = corev1.ClaimPending
+	bound := newTestPVC("web", "bound", nil)
+	bound.Status.Phase = corev1.ClaimBound
+	client := fake.NewSimpleClientset(pending, bound)
+	p := &PVCSyncer{SourceK8sClient: client, SourceConfig: &rest.Config{}}
+
+	for _, tc := range []struct {
+		pvc    *corev1.PersistentVolumeClaim
+		reason string
+	}{
+		{pvc: pending, reason: SkipReasonNotBound},
+		{pvc: bound, reason: SkipReasonNotMounted},
+	} {
+		err := p.SyncPVCWithNamespaceMapping(ctx, mapping, PVCSyncOptions{
+			SourcePVC:            tc.pvc,
+			DestinationPVC:       newTestPVC("web-dr", tc.pvc.Name, nil),
+			SourceNamespace:      "web",
+			DestinationNamespace: "web-dr",
+		})
+		require.NoError(t, err)
+
+		pvc, err := client.CoreV1().PersistentVolumeClaims("web").Get(ctx, tc.pvc.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, tc.reason, skipStatus(t, pvc).Reason, tc.pvc.Name)
+	}
+}
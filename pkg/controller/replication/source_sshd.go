@@ -0,0 +1,551 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// The LbSvc and PortForward strategies do not use the source agent. A temporary sshd pod running the
+// agent image mounts the source PVC read-only and accepts the public key of the destination rsync pod,
+// which pulls the data from it like it does from an agent.
+const (
+	// sourceSSHDAppName names the temporary sshd pods and labels their objects
+	sourceSSHDAppName = "dr-syncer-sshd"
+
+	// sourceSSHDPort is the port sshd listens on in the agent image
+	sourceSSHDPort int32 = 2222
+
+	// sourceSSHDDataPath is where the temporary sshd pod mounts the source PVC
+	sourceSSHDDataPath = "/data"
+
+	// sourceSSHDKeysPath is where the agent image reads the authorized_keys of the temporary sshd pod from
+	sourceSSHDKeysPath = "/etc/ssh/keys"
+
+	// sourceSSHDReadyTimeout bounds the wait for the temporary sshd pod to accept connections
+	sourceSSHDReadyTimeout = 5 * time.Minute
+
+	// loadBalancerTimeout bounds the wait for the LoadBalancer Service of the LbSvc strategy to get an address
+	loadBalancerTimeout = 5 * time.Minute
+
+	// defaultSourceSSHDImage runs the temporary sshd pod when the source RemoteCluster configures no agent image
+	defaultSourceSSHDImage = "supporttools/dr-syncer-agent:latest"
+
+	// sourceSSHDCommand installs the host keys generated into the agent image before starting its
+	// entrypoint, the agent DaemonSet gets them from its key Secret instead
+	sourceSSHDCommand = "mkdir -p /etc/ssh/host_keys && cp /etc/ssh/ssh_host_*_key /etc/ssh/ssh_host_*_key.pub /etc/ssh/host_keys/ && exec /entrypoint.sh"
+)
+
+// sshPortKeyType is the type for the SSH port context key
+type sshPortKeyType string
+
+// sshPortKey is the context key for the SSH port of the sync target, such as a temporary sshd or an exposed agent
+const sshPortKey sshPortKeyType = "sshPort"
+
+// withSSHPort returns a context making performRsync connect to port instead of the SSH port of the RemoteCluster
+func withSSHPort(ctx context.Context, port int32) context.Context {
+	return context.WithValue(ctx, sshPortKey, port)
+}
+
+// sshPortOverride returns the SSH port set by withSSHPort
+func sshPortOverride(ctx context.Context) (int32, bool) {
+	port, ok := ctx.Value(sshPortKey).(int32)
+	return port, ok
+}
+
+// usesSourceSSHD returns true if the strategy syncs from a temporary sshd pod instead of the agent
+func usesSourceSSHD(strategy drv1alpha1.PVCDataSyncStrategy) bool {
+	return strategy == drv1alpha1.PVCDataSyncStrategyLbSvc || strategy == drv1alpha1.PVCDataSyncStrategyPortForward
+}
+
+// sourceSSHDOptions describes the temporary sshd pod of a sync
+type sourceSSHDOptions struct {
+	// Namespace is the namespace of the source PVC
+	Namespace string
+
+	// PVCName is the name of the source PVC
+	PVCName string
+
+	// SyncID makes the names of the objects unique
+	SyncID string
+
+	// Node pins the pod to the node mounting the source PVC, so ReadWriteOnce volumes can be mounted.
+	// An unmounted PVC leaves it empty for the scheduler to place the pod.
+	Node string
+
+	// Image and PullPolicy are those of the agent
+	Image      string
+	PullPolicy corev1.PullPolicy
+
+	// PublicKey is the public key of the destination rsync pod
+	PublicKey string
+
+	// Strategy adds a LoadBalancer Service for LbSvc
+	Strategy drv1alpha1.PVCDataSyncStrategy
+}
+
+// sourceSSHD is a running temporary sshd pod
+type sourceSSHD struct {
+	// Name is the name of the pod and of its Secret and Service
+	Name string
+
+	// Pod is the ready sshd pod
+	Pod *corev1.Pod
+}
+
+// sourceSSHDPVCLabel returns the label value identifying the source PVC of a temporary sshd pod
+func sourceSSHDPVCLabel(pvcName string) string {
+	value := strings.ReplaceAll(pvcName, ".", "-")
+	if len(value) > 63 {
+		value = value[:63]
+	}
+	return strings.TrimRight(value, "-")
+}
+
+// sourceSSHDSelector selects the temporary sshd objects of a source PVC
+func sourceSSHDSelector(pvcName string) string {
+	return fmt.Sprintf("app.kubernetes.io/name=%s,dr-syncer.io/pvc-name=%s", sourceSSHDAppName, sourceSSHDPVCLabel(pvcName))
+}
+
+// sourceSSHDImage returns the agent image of the source RemoteCluster, honouring the same environment
+// overrides as the agent DaemonSet
+func sourceSSHDImage(rc *drv1alpha1.RemoteCluster) (string, corev1.PullPolicy) {
+	pullPolicy := corev1.PullIfNotPresent
+	repository, tag, _ := strings.Cut(defaultSourceSSHDImage, ":")
+	if rc != nil && rc.Spec.PVCSync != nil && rc.Spec.PVCSync.Image != nil {
+		if rc.Spec.PVCSync.Image.Repository != "" {
+			repository = rc.Spec.PVCSync.Image.Repository
+		}
+		if rc.Spec.PVCSync.Image.Tag != "" {
+			tag = rc.Spec.PVCSync.Image.Tag
+		}
+		if rc.Spec.PVCSync.Image.PullPolicy != "" {
+			pullPolicy = corev1.PullPolicy(rc.Spec.PVCSync.Image.PullPolicy)
+		}
+	}
+	if envRepo := os.Getenv("AGENT_IMAGE_REPOSITORY"); envRepo != "" {
+		repository = envRepo
+	}
+	if envTag := os.Getenv("AGENT_IMAGE_TAG"); envTag != "" {
+		tag = envTag
+	}
+	return fmt.Sprintf("%s:%s", repository, tag), pullPolicy
+}
+
+// sourceSSHDObjects builds the Secret holding the authorized key, the sshd pod and, for the LbSvc
+// strategy, the LoadBalancer Service of a temporary sshd
+func sourceSSHDObjects(opts sourceSSHDOptions) (*corev1.Secret, *corev1.Pod, *corev1.Service) {
+	name := fmt.Sprintf("%s-%s", sourceSSHDAppName, opts.SyncID)
+	labels := map[string]string{
+		"app.kubernetes.io/name":       sourceSSHDAppName,
+		"app.kubernetes.io/instance":   opts.SyncID,
+		"app.kubernetes.io/managed-by": "dr-syncer",
+		"dr-syncer.io/sync-id":         opts.SyncID,
+		"dr-syncer.io/pvc-name":        sourceSSHDPVCLabel(opts.PVCName),
+	}
+	meta := func() metav1.ObjectMeta {
+		objectLabels := make(map[string]string, len(labels))
+		for k, v := range labels {
+			objectLabels[k] = v
+		}
+		return metav1.ObjectMeta{Name: name, Namespace: opts.Namespace, Labels: objectLabels}
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: meta(),
+		Type:       corev1.SecretTypeOpaque,
+		Data: map[string][]byte{
+			"authorized_keys": []byte(strings.TrimSpace(opts.PublicKey) + "\n"),
+		},
+	}
+
+	automountToken := false
+	keysMode := int32(0600)
+	pod := &corev1.Pod{
+		ObjectMeta: meta(),
+		Spec: corev1.PodSpec{
+			NodeName:                     opts.Node,
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: &automountToken,
+			Containers: []corev1.Container{
+				{
+					Name:            "sshd",
+					Image:           opts.Image,
+					ImagePullPolicy: opts.PullPolicy,
+					Command:         []string{"/bin/bash", "-c", sourceSSHDCommand},
+					Env: []corev1.EnvVar{
+						{Name: "SSH_PORT", Value: fmt.Sprintf("%d", sourceSSHDPort)},
+						// The health endpoint of the agent is not needed
+						{Name: "HEALTH_PORT", Value: "0"},
+					},
+					Ports: []corev1.ContainerPort{
+						{Name: "ssh", ContainerPort: sourceSSHDPort, Protocol: corev1.ProtocolTCP},
+					},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt32(sourceSSHDPort)},
+						},
+						PeriodSeconds: 2,
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "data", MountPath: sourceSSHDDataPath, ReadOnly: true},
+						{Name: "ssh-keys", MountPath: sourceSSHDKeysPath, ReadOnly: true},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: opts.PVCName,
+							ReadOnly:  true,
+						},
+					},
+				},
+				{
+					Name: "ssh-keys",
+					VolumeSource: corev1.VolumeSource{
+						Secret: &corev1.SecretVolumeSource{SecretName: name, DefaultMode: &keysMode},
+					},
+				},
+			},
+		},
+	}
+
+	if opts.Strategy != drv1alpha1.PVCDataSyncStrategyLbSvc {
+		return secret, pod, nil
+	}
+	service := &corev1.Service{
+		ObjectMeta: meta(),
+		Spec: corev1.ServiceSpec{
+			Type: corev1.ServiceTypeLoadBalancer,
+			Selector: map[string]string{
+				"app.kubernetes.io/name": sourceSSHDAppName,
+				"dr-syncer.io/sync-id":   opts.SyncID,
+			},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "ssh",
+					Port:       sourceSSHDPort,
+					TargetPort: intstr.FromInt32(sourceSSHDPort),
+					Protocol:   corev1.ProtocolTCP,
+				},
+			},
+		},
+	}
+	return secret, pod, service
+}
+
+// sourceRemoteCluster returns the RemoteCluster of the source cluster, nil when none can be listed
+func (p *PVCSyncer) sourceRemoteCluster(ctx context.Context) *drv1alpha1.RemoteCluster {
+	if p.SourceClient == nil {
+		return nil
+	}
+
+	remoteClustersList := &drv1alpha1.RemoteClusterList{}
+	if err := p.SourceClient.List(ctx, remoteClustersList); err != nil || len(remoteClustersList.Items) == 0 {
+		return nil
+	}
+
+	for i := range remoteClustersList.Items {
+		if remoteClustersList.Items[i].Name == p.SourceRemoteClusterName {
+			return &remoteClustersList.Items[i]
+		}
+	}
+	return &remoteClustersList.Items[0]
+}
+
+// deploySourceSSHD creates a temporary sshd and waits until its pod accepts connections
+func (p *PVCSyncer) deploySourceSSHD(ctx context.Context, opts sourceSSHDOptions) (*sourceSSHD, error) {
+	secret, pod, service := sourceSSHDObjects(opts)
+
+	if _, err := p.SourceK8sClient.CoreV1().Secrets(opts.Namespace).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create sshd key secret %s/%s: %v", opts.Namespace, secret.Name, err)
+	}
+	if service != nil {
+		if _, err := p.SourceK8sClient.CoreV1().Services(opts.Namespace).Create(ctx, service, metav1.CreateOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to create sshd service %s/%s: %v", opts.Namespace, service.Name, err)
+		}
+	}
+	if _, err := p.SourceK8sClient.CoreV1().Pods(opts.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create sshd pod %s/%s: %v", opts.Namespace, pod.Name, err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"namespace": opts.Namespace,
+		"pod_name":  pod.Name,
+		"pvc":       opts.PVCName,
+		"node":      opts.Node,
+	}).Info(logging.LogTagDetail + " Waiting for temporary sshd pod in source cluster")
+
+	var ready *corev1.Pod
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, sourceSSHDReadyTimeout, true, func(ctx context.Context) (bool, error) {
+		current, err := p.SourceK8sClient.CoreV1().Pods(opts.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		if current.Status.Phase == corev1.PodFailed || current.Status.Phase == corev1.PodSucceeded {
+			return false, fmt.Errorf("sshd pod terminated in phase %s", current.Status.Phase)
+		}
+		for _, condition := range current.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				ready = current
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("sshd pod %s/%s not ready: %v", opts.Namespace, pod.Name, err)
+	}
+
+	return &sourceSSHD{Name: pod.Name, Pod: ready}, nil
+}
+
+// waitForLoadBalancer waits for the LoadBalancer Service of a temporary sshd to get an address
+func (p *PVCSyncer) waitForLoadBalancer(ctx context.Context, namespace, name string) (string, error) {
+	var address string
+	err := wait.PollUntilContextTimeout(ctx, 5*time.Second, loadBalancerTimeout, true, func(ctx context.Context) (bool, error) {
+		service, err := p.SourceK8sClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				address = ingress.IP
+				return true, nil
+			}
+			if ingress.Hostname != "" {
+				address = ingress.Hostname
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("load balancer of service %s/%s got no address: %v", namespace, name, err)
+	}
+	return address, nil
+}
+
+// cleanupSourceSSHD deletes the temporary sshd objects of a source PVC
+func (p *PVCSyncer) cleanupSourceSSHD(ctx context.Context, namespace, pvcName string) {
+	selector := metav1.ListOptions{LabelSelector: sourceSSHDSelector(pvcName)}
+	fields := logrus.Fields{
+		"namespace": namespace,
+		"pvc":       pvcName,
+	}
+	warn := func(kind, name string, err error) {
+		if err != nil && !apierrors.IsNotFound(err) {
+			log.WithFields(fields).WithFields(logrus.Fields{
+				"kind":  kind,
+				"name":  name,
+				"error": err,
+			}).Warn(logging.LogTagWarn + " Failed to delete temporary sshd object")
+		}
+	}
+
+	core := p.SourceK8sClient.CoreV1()
+	if pods, err := core.Pods(namespace).List(ctx, selector); err != nil {
+		warn("Pod", "", err)
+	} else {
+		for _, pod := range pods.Items {
+			warn("Pod", pod.Name, core.Pods(namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{}))
+		}
+	}
+	if services, err := core.Services(namespace).List(ctx, selector); err != nil {
+		warn("Service", "", err)
+	} else {
+		for _, service := range services.Items {
+			warn("Service", service.Name, core.Services(namespace).Delete(ctx, service.Name, metav1.DeleteOptions{}))
+		}
+	}
+	if secrets, err := core.Secrets(namespace).List(ctx, selector); err != nil {
+		warn("Secret", "", err)
+	} else {
+		for _, secret := range secrets.Items {
+			warn("Secret", secret.Name, core.Secrets(namespace).Delete(ctx, secret.Name, metav1.DeleteOptions{}))
+		}
+	}
+}
+
+// SourceSSHDWorkflow syncs the data of a PVC without the source agent. A temporary sshd pod mounting
+// the source PVC read-only is started in the source namespace. The LbSvc strategy exposes it through a
+// LoadBalancer Service, the PortForward strategy tunnels the connection of the destination rsync pod
+// through the controller's port-forward. The destination rsync pod then pulls the data from it.
+func (p *PVCSyncer) SourceSSHDWorkflow(ctx context.Context, sourceNamespace, sourcePVCName, destNamespace, destPVCName string) error {
+	startTime := time.Now()
+
+	// Tag the logs of the workflow with the trace of the sync
+	log := log.WithContext(ctx)
+
+	fields := logrus.Fields{
+		"source_namespace": sourceNamespace,
+		"source_pvc":       sourcePVCName,
+		"dest_namespace":   destNamespace,
+		"dest_pvc":         destPVCName,
+		"strategy":         p.Strategy,
+	}
+	log.WithFields(fields).Info(logging.LogTagInfo + " Starting source sshd workflow")
+
+	if skip, err := p.skipBlockVolume(ctx, sourceNamespace, sourcePVCName, destNamespace, destPVCName); err != nil {
+		return err
+	} else if skip {
+		return nil
+	}
+
+	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncStarted,
+		"Starting PVC data sync to %s/%s with the %s strategy", destNamespace, destPVCName, p.Strategy)
+
+	p.SourceNamespace = sourceNamespace
+	p.DestinationNamespace = destNamespace
+
+	tracing.Step(ctx, "lock")
+	acquired, lockInfo, err := p.AcquirePVCLock(ctx, sourceNamespace, sourcePVCName)
+	if err != nil {
+		return fmt.Errorf("failed to check lock on source PVC: %v", err)
+	}
+	if !acquired {
+		log.WithFields(fields).WithField("lock_owner", lockInfo.ControllerPodName).
+			Info(logging.LogTagSkip + " Source PVC is locked by another controller, skipping sync")
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped,
+			"PVC is locked by %s, skipping sync", lockInfo.ControllerPodName)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonLocked, "")
+		return nil
+	}
+	defer func() {
+		if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+			log.WithFields(fields).WithField("error", relErr).Warn(logging.LogTagWarn + " Failed to release lock on source PVC")
+		}
+	}()
+
+	fail := func(format string, args ...interface{}) error {
+		err := fmt.Errorf(format, args...)
+		log.WithFields(fields).WithField("error", err).Error(logging.LogTagError + " Source sshd workflow failed")
+		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed, "%v", err)
+		if statusErr := p.FailedSyncStatus(ctx, sourceNamespace, sourcePVCName, err); statusErr != nil {
+			log.WithFields(fields).WithField("error", statusErr).Warn(logging.LogTagWarn + " Failed to update sync status")
+		}
+		return err
+	}
+
+	tracing.Step(ctx, "locate-source")
+	mounted, err := p.HasVolumeAttachments(ctx, sourceNamespace, sourcePVCName)
+	if err != nil {
+		return fail("failed to check if source PVC is mounted: %v", err)
+	}
+	// The sshd pod mounts an unmounted PVC itself, on the node the scheduler picks
+	var sourceNode string
+	switch {
+	case mounted:
+		sourceNode, err = p.FindPVCNode(ctx, p.SourceClient, sourceNamespace, sourcePVCName)
+		if err != nil {
+			return fail("failed to find node where source PVC is mounted: %v", err)
+		}
+	case p.SyncUnmounted:
+		log.WithFields(fields).Info(logging.LogTagInfo + " Source PVC is not mounted, mounting it in the temporary sshd pod")
+	default:
+		log.WithFields(fields).Info(logging.LogTagSkip + " Source PVC is not mounted, skipping sync")
+		message := notMountedMessage
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped, "%s", message)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonNotMounted, message)
+		return nil
+	}
+
+	tracing.Step(ctx, "deploy")
+	destPod, err := p.deployRsyncPod(ctx, destNamespace, destPVCName)
+	if err != nil {
+		return fail("failed to deploy rsync pod in destination cluster: %v", err)
+	}
+	defer p.cleanupResources(ctx, destPod)
+	p.recordLockSync(ctx, sourceNamespace, sourcePVCName, destPod)
+
+	if !destPod.HasCachedKeys {
+		if err := p.generateSSHKeys(ctx, destPod); err != nil {
+			return fail("failed to generate SSH keys: %v", err)
+		}
+	}
+	publicKey, err := p.getPublicKey(ctx, destPod)
+	if err != nil {
+		return fail("failed to get public key: %v", err)
+	}
+
+	image, pullPolicy := sourceSSHDImage(p.sourceRemoteCluster(ctx))
+	defer p.cleanupSourceSSHD(ctx, sourceNamespace, sourcePVCName)
+	sshd, err := p.deploySourceSSHD(ctx, sourceSSHDOptions{
+		Namespace:  sourceNamespace,
+		PVCName:    sourcePVCName,
+		SyncID:     rand.String(8),
+		Node:       sourceNode,
+		Image:      image,
+		PullPolicy: pullPolicy,
+		PublicKey:  publicKey,
+		Strategy:   p.Strategy,
+	})
+	if err != nil {
+		return fail("failed to deploy temporary sshd pod in source cluster: %v", err)
+	}
+
+	if p.skipUnchanged(ctx, sourceNamespace, sourcePVCName, sshd.Pod, sourceSSHDDataPath) {
+		return nil
+	}
+
+	tracing.Step(ctx, "connectivity")
+	host, port := "", sourceSSHDPort
+	switch p.Strategy {
+	case drv1alpha1.PVCDataSyncStrategyLbSvc:
+		host, err = p.waitForLoadBalancer(ctx, sourceNamespace, sshd.Name)
+		if err != nil {
+			return fail("%v", err)
+		}
+	case drv1alpha1.PVCDataSyncStrategyPortForward:
+		relay, err := p.startPortForwardRelay(ctx, sshd.Pod, destPod)
+		if err != nil {
+			return fail("failed to tunnel the source sshd pod to the rsync pod: %v", err)
+		}
+		defer relay.Stop()
+		host, port = "127.0.0.1", portForwardRelayPort
+	}
+	log.WithFields(fields).WithFields(logrus.Fields{
+		"sshd_pod": sshd.Name,
+		"host":     host,
+		"port":     port,
+	}).Info(logging.LogTagDetail + " Temporary sshd pod is reachable from the destination rsync pod")
+
+	if err := p.TestSSHConnectivity(ctx, destPod, host, int(port), p.DestinationConfig); err != nil {
+		return fail("failed to test SSH connectivity: %v", err)
+	}
+	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSSHConnected,
+		"SSH connectivity established to temporary sshd pod %s", sshd.Name)
+
+	tracing.Step(ctx, "rsync")
+	if err := p.performRsync(withSSHPort(ctx, port), destPod, host, sourceSSHDDataPath); err != nil {
+		return fail("failed to perform rsync: %v", err)
+	}
+
+	tracing.Step(ctx, "annotations")
+	if err := p.UpdateSourcePVCAnnotations(ctx, sourceNamespace, sourcePVCName); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to update source PVC annotations")
+	}
+
+	duration := time.Since(startTime)
+	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncCompleted,
+		"PVC data sync completed with the %s strategy (duration: %s)", p.Strategy, duration.Round(time.Second))
+	log.WithFields(fields).WithField("duration", duration.Round(time.Second)).
+		Info(logging.LogTagComplete + " Source sshd workflow completed successfully")
+	return nil
+}
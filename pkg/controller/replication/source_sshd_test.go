@@ -0,0 +1,125 @@
+package replication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSourceSSHDObjects(t *testing.T) {
+	opts := sourceSSHDOptions{
+		Namespace:  "shop",
+		PVCName:    "data.v1",
+		SyncID:     "abc123",
+		Node:       "node-1",
+		Image:      "supporttools/dr-syncer-agent:v1",
+		PullPolicy: corev1.PullAlways,
+		PublicKey:  "ssh-rsa AAAA rsync\n\n",
+		Strategy:   drv1alpha1.PVCDataSyncStrategyLbSvc,
+	}
+
+	secret, pod, service := sourceSSHDObjects(opts)
+	assert.Equal(t, "dr-syncer-sshd-abc123", secret.Name)
+	assert.Equal(t, "ssh-rsa AAAA rsync\n", string(secret.Data["authorized_keys"]))
+	assert.Equal(t, "data-v1", pod.Labels["dr-syncer.io/pvc-name"])
+
+	// The pod mounts the PVC read-only on the node already mounting it
+	assert.Equal(t, "node-1", pod.Spec.NodeName)
+	assert.Equal(t, "data.v1", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.True(t, pod.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly)
+	assert.Equal(t, secret.Name, pod.Spec.Volumes[1].Secret.SecretName)
+	container := pod.Spec.Containers[0]
+	assert.Equal(t, opts.Image, container.Image)
+	assert.Equal(t, corev1.VolumeMount{Name: "data", MountPath: "/data", ReadOnly: true}, container.VolumeMounts[0])
+	assert.Equal(t, "/etc/ssh/keys", container.VolumeMounts[1].MountPath)
+
+	require.NotNil(t, service)
+	assert.Equal(t, corev1.ServiceTypeLoadBalancer, service.Spec.Type)
+	assert.Equal(t, "abc123", service.Spec.Selector["dr-syncer.io/sync-id"])
+	assert.Equal(t, sourceSSHDPort, service.Spec.Ports[0].Port)
+
+	// PortForward needs no Service
+	opts.Strategy = drv1alpha1.PVCDataSyncStrategyPortForward
+	_, _, service = sourceSSHDObjects(opts)
+	assert.Nil(t, service)
+}
+
+func TestSourceSSHDImage(t *testing.T) {
+	image, pullPolicy := sourceSSHDImage(nil)
+	assert.Equal(t, "supporttools/dr-syncer-agent:latest", image)
+	assert.Equal(t, corev1.PullIfNotPresent, pullPolicy)
+
+	rc := &drv1alpha1.RemoteCluster{Spec: drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{
+		Image: &drv1alpha1.PVCSyncImage{Repository: "registry.local/agent", Tag: "v2", PullPolicy: "Always"},
+	}}}
+	image, pullPolicy = sourceSSHDImage(rc)
+	assert.Equal(t, "registry.local/agent:v2", image)
+	assert.Equal(t, corev1.PullAlways, pullPolicy)
+
+	t.Setenv("AGENT_IMAGE_TAG", "dev")
+	image, _ = sourceSSHDImage(rc)
+	assert.Equal(t, "registry.local/agent:dev", image)
+}
+
+func TestUsesSourceSSHD(t *testing.T) {
+	assert.False(t, usesSourceSSHD(""))
+	assert.False(t, usesSourceSSHD(drv1alpha1.PVCDataSyncStrategyAgent))
+	assert.True(t, usesSourceSSHD(drv1alpha1.PVCDataSyncStrategyLbSvc))
+	assert.True(t, usesSourceSSHD(drv1alpha1.PVCDataSyncStrategyPortForward))
+
+	port, ok := sshPortOverride(withSSHPort(context.Background(), 2222))
+	assert.True(t, ok)
+	assert.Equal(t, int32(2222), port)
+	_, ok = sshPortOverride(context.Background())
+	assert.False(t, ok)
+}
+
+func TestRelayCommands(t *testing.T) {
+	assert.Equal(t, []string{"nc", "-N", "-l", "127.0.0.1", "2222"}, relayListenCommand(2222))
+	assert.Equal(t, []string{"grep", "-q", ":08AE 00000000:0000 0A", "/proc/net/tcp"}, relayListeningCommand(2222))
+}
+
+func TestWaitForLoadBalancer(t *testing.T) {
+	service := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "dr-syncer-sshd-abc123", Namespace: "shop"},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+			Ingress: []corev1.LoadBalancerIngress{{Hostname: "lb.example.com"}},
+		}},
+	}
+	p := &PVCSyncer{SourceK8sClient: fake.NewSimpleClientset(service)}
+
+	address, err := p.waitForLoadBalancer(context.Background(), "shop", service.Name)
+	require.NoError(t, err)
+	assert.Equal(t, "lb.example.com", address)
+}
+
+func TestCleanupSourceSSHD(t *testing.T) {
+	secret, pod, service := sourceSSHDObjects(sourceSSHDOptions{
+		Namespace: "shop",
+		PVCName:   "data",
+		SyncID:    "abc123",
+		Strategy:  drv1alpha1.PVCDataSyncStrategyLbSvc,
+	})
+	other, _, _ := sourceSSHDObjects(sourceSSHDOptions{Namespace: "shop", PVCName: "logs", SyncID: "def456"})
+	client := fake.NewSimpleClientset(secret, pod, service, other)
+	p := &PVCSyncer{SourceK8sClient: client}
+
+	p.cleanupSourceSSHD(context.Background(), "shop", "data")
+
+	pods, err := client.CoreV1().Pods("shop").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, pods.Items)
+	services, err := client.CoreV1().Services("shop").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, services.Items)
+	secrets, err := client.CoreV1().Secrets("shop").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, secrets.Items, 1)
+	assert.Equal(t, other.Name, secrets.Items[0].Name)
+}
@@ -0,0 +1,175 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+)
+
+// ErrInsufficientSpace is wrapped by the error of a data sync whose destination PVC is too small for the
+// data of its source PVC
+var ErrInsufficientSpace = errors.New("insufficient space")
+
+const (
+	// spaceCheckTimeout bounds the disk usage scan of the source PVC on the agent
+	spaceCheckTimeout = 5 * time.Minute
+
+	// growHeadroomPercent is added to the used space of the source PVC when growing a destination PVC
+	growHeadroomPercent = 10
+)
+
+// growPollInterval and growTimeout bound the wait for the expansion of a grown destination PVC
+var (
+	growPollInterval = 5 * time.Second
+	growTimeout      = 2 * time.Minute
+)
+
+// pvcCapacity returns the capacity of a PVC, its requested storage while it is not bound
+func pvcCapacity(pvc *corev1.PersistentVolumeClaim) (resource.Quantity, bool) {
+	if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok {
+		return capacity, true
+	}
+	request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	return request, ok
+}
+
+// grownSize returns the size a destination PVC is grown to for the used space of its source PVC: the used
+// space plus headroom, rounded up to a whole Gi
+func grownSize(used int64) resource.Quantity {
+	const gi = int64(1) << 30
+	size := used + used*growHeadroomPercent/100
+	return *resource.NewQuantity((size+gi-1)/gi*gi, resource.BinarySI)
+}
+
+// checkDestinationSpace estimates the space used on the source PVC through the agent and makes sure the
+// destination PVC can hold it before rsync starts. Agents that cannot estimate the usage skip the check.
+func (p *PVCSyncer) checkDestinationSpace(ctx context.Context, sourceNamespace, sourcePVCName, destNamespace, destPVCName string, agentPod *corev1.Pod, mountPath string) error {
+	usageCtx, cancel := context.WithTimeout(ctx, spaceCheckTimeout)
+	defer cancel()
+
+	used, err := p.agentDiskUsage(usageCtx, agentPod, mountPath)
+	if err != nil {
+		log.WithFields(logrus.Fields{
+			"source_namespace": sourceNamespace,
+			"source_pvc":       sourcePVCName,
+			"agent_pod":        agentPod.Name,
+			"error":            err,
+		}).Warn(logging.LogTagWarn + " Failed to estimate the space used on the source PVC, skipping the space check")
+		return nil
+	}
+	return p.ensureDestinationSpace(ctx, sourceNamespace, sourcePVCName, destNamespace, destPVCName, used)
+}
+
+// ensureDestinationSpace fails the data sync with ErrInsufficientSpace when the destination PVC is smaller
+// than used, unless AutoGrowDestination is set and the destination StorageClass allows the PVC to grow
+func (p *PVCSyncer) ensureDestinationSpace(ctx context.Context, sourceNamespace, sourcePVCName, destNamespace, destPVCName string, used int64) error {
+	pvc, err := p.DestinationK8sClient.CoreV1().PersistentVolumeClaims(destNamespace).Get(ctx, destPVCName, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get destination PVC %s/%s: %w", destNamespace, destPVCName, err)
+	}
+	capacity, ok := pvcCapacity(pvc)
+	if !ok || capacity.Value() >= used {
+		return nil
+	}
+
+	usedQuantity := resource.NewQuantity(used, resource.BinarySI)
+	fields := logrus.Fields{
+		"source_namespace": sourceNamespace,
+		"source_pvc":       sourcePVCName,
+		"dest_namespace":   destNamespace,
+		"dest_pvc":         destPVCName,
+		"used":             usedQuantity.String(),
+		"capacity":         capacity.String(),
+	}
+	shortage := fmt.Sprintf("source PVC %s/%s uses %s but destination PVC %s/%s has a capacity of %s",
+		sourceNamespace, sourcePVCName, usedQuantity.String(), destNamespace, destPVCName, capacity.String())
+
+	if !p.AutoGrowDestination {
+		log.WithFields(fields).Error(logging.LogTagError + " Destination PVC is too small for the source data")
+		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonInsufficientSpace, "%s", shortage)
+		return fmt.Errorf("%w: %s", ErrInsufficientSpace, shortage)
+	}
+
+	allowed, reason, err := p.destinationAllowsExpansion(ctx, pvc)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		log.WithFields(fields).WithField("reason", reason).Error(logging.LogTagError + " Destination PVC is too small for the source data and cannot grow")
+		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonInsufficientSpace, "%s, and %s", shortage, reason)
+		return fmt.Errorf("%w: %s, and %s", ErrInsufficientSpace, shortage, reason)
+	}
+
+	size := grownSize(used)
+	if request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; !ok || size.Cmp(request) > 0 {
+		if pvc.Spec.Resources.Requests == nil {
+			pvc.Spec.Resources.Requests = corev1.ResourceList{}
+		}
+		pvc.Spec.Resources.Requests[corev1.ResourceStorage] = size
+		if _, err := p.DestinationK8sClient.CoreV1().PersistentVolumeClaims(destNamespace).Update(ctx, pvc, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to grow destination PVC %s/%s to %s: %w", destNamespace, destPVCName, size.String(), err)
+		}
+		log.WithFields(fields).WithField("size", size.String()).Info(logging.LogTagInfo + " Grew destination PVC to fit the source data")
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonDestinationGrown,
+			"Grew destination PVC %s/%s from %s to %s to fit %s of data", destNamespace, destPVCName, capacity.String(), size.String(), usedQuantity.String())
+	}
+	return p.waitForExpansion(ctx, destNamespace, destPVCName, size)
+}
+
+// destinationAllowsExpansion checks allowVolumeExpansion on the StorageClass of a destination PVC. PVCs
+// without an explicit StorageClass are left to the API server, unbound PVCs cannot grow.
+func (p *PVCSyncer) destinationAllowsExpansion(ctx context.Context, pvc *corev1.PersistentVolumeClaim) (bool, string, error) {
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return false, "it is not bound yet", nil
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return true, "", nil
+	}
+
+	name := *pvc.Spec.StorageClassName
+	sc, err := p.DestinationK8sClient.StorageV1().StorageClasses().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, fmt.Sprintf("storage class %s does not exist in the destination cluster", name), nil
+	}
+	if err != nil {
+		return false, "", fmt.Errorf("failed to get storage class %s: %w", name, err)
+	}
+	if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+		return false, fmt.Sprintf("storage class %s does not allow volume expansion", name), nil
+	}
+	return true, "", nil
+}
+
+// waitForExpansion waits until the volume of a grown destination PVC was expanded. Volumes whose file
+// system is resized when the rsync pod mounts them are ready once the resize is pending on the node.
+func (p *PVCSyncer) waitForExpansion(ctx context.Context, namespace, name string, size resource.Quantity) error {
+	err := wait.PollUntilContextTimeout(ctx, growPollInterval, growTimeout, true, func(ctx context.Context) (bool, error) {
+		pvc, err := p.DestinationK8sClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		if capacity, ok := pvc.Status.Capacity[corev1.ResourceStorage]; ok && capacity.Cmp(size) >= 0 {
+			return true, nil
+		}
+		for _, condition := range pvc.Status.Conditions {
+			if condition.Type == corev1.PersistentVolumeClaimFileSystemResizePending && condition.Status == corev1.ConditionTrue {
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("destination PVC %s/%s was not expanded to %s: %w", namespace, name, size.String(), err)
+	}
+	return nil
+}
@@ -0,0 +1,89 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+const gib = int64(1) << 30
+
+func smallDestinationPVC(storageClass string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app-dr"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: ptr.To(storageClass),
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+			},
+		},
+		Status: corev1.PersistentVolumeClaimStatus{
+			Phase:    corev1.ClaimBound,
+			Capacity: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("5Gi")},
+			Conditions: []corev1.PersistentVolumeClaimCondition{{
+				Type:   corev1.PersistentVolumeClaimFileSystemResizePending,
+				Status: corev1.ConditionTrue,
+			}},
+		},
+	}
+}
+
+func storageClass(name string, expandable bool) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: name},
+		AllowVolumeExpansion: ptr.To(expandable),
+	}
+}
+
+func TestGrownSize(t *testing.T) {
+	assert.Equal(t, "1Gi", ptr.To(grownSize(100)).String())
+	assert.Equal(t, "11Gi", ptr.To(grownSize(10*gib)).String())
+	assert.Equal(t, "12Gi", ptr.To(grownSize(10*gib+1)).String())
+}
+
+func TestEnsureDestinationSpace_Fits(t *testing.T) {
+	p := &PVCSyncer{DestinationK8sClient: fake.NewSimpleClientset(smallDestinationPVC("fixed"))}
+	assert.NoError(t, p.ensureDestinationSpace(context.Background(), "app", "data", "app-dr", "data", 4*gib))
+}
+
+func TestEnsureDestinationSpace_Insufficient(t *testing.T) {
+	p := &PVCSyncer{DestinationK8sClient: fake.NewSimpleClientset(smallDestinationPVC("fast"), storageClass("fast", true))}
+
+	err := p.ensureDestinationSpace(context.Background(), "app", "data", "app-dr", "data", 8*gib)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInsufficientSpace))
+	assert.Contains(t, err.Error(), "source PVC app/data uses 8Gi but destination PVC app-dr/data has a capacity of 5Gi")
+}
+
+func TestEnsureDestinationSpace_CannotGrow(t *testing.T) {
+	p := &PVCSyncer{
+		DestinationK8sClient: fake.NewSimpleClientset(smallDestinationPVC("fixed"), storageClass("fixed", false)),
+		AutoGrowDestination:  true,
+	}
+
+	err := p.ensureDestinationSpace(context.Background(), "app", "data", "app-dr", "data", 8*gib)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrInsufficientSpace))
+	assert.Contains(t, err.Error(), "storage class fixed does not allow volume expansion")
+}
+
+func TestEnsureDestinationSpace_AutoGrow(t *testing.T) {
+	client := fake.NewSimpleClientset(smallDestinationPVC("fast"), storageClass("fast", true))
+	p := &PVCSyncer{DestinationK8sClient: client, AutoGrowDestination: true}
+
+	require.NoError(t, p.ensureDestinationSpace(context.Background(), "app", "data", "app-dr", "data", 8*gib))
+
+	pvc, err := client.CoreV1().PersistentVolumeClaims("app-dr").Get(context.Background(), "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	request := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	assert.Equal(t, "9Gi", request.String())
+}
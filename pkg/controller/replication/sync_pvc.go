@@ -19,18 +19,25 @@ func (p *PVCSyncer) SyncPVC(ctx context.Context, namespace, name string, destNam
 		"dest_namespace":   destNamespace,
 	}).Info("Syncing PVC")
 
+	// The destination PVC may be renamed by the mapping's PVC mappings
+	destName, err := DestinationPVCName(PVCMappingsFor(mapping), name)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination PVC name: %v", err)
+	}
+
 	// Check if both source and destination PVCs exist
-	if err := p.ValidatePVCSync(ctx, name, namespace, name, destNamespace); err != nil {
+	if err := p.ValidatePVCSync(ctx, name, namespace, destName, destNamespace); err != nil {
 		return fmt.Errorf("validation failed: %v", err)
 	}
 
 	// Log sync progress
-	p.LogSyncProgress(ctx, name, namespace, name, destNamespace, "Started", "PVC sync started")
+	p.LogSyncProgress(ctx, name, namespace, destName, destNamespace, "Started", "PVC sync started")
 
-	// Perform the rsync workflow
-	err := p.RsyncWorkflow(ctx, namespace, name, destNamespace, name)
+	// Perform the rsync workflow, bounded by the PVC's sync timeout
+	timeout := p.syncTimeoutFor(ctx, mapping, namespace, name)
+	err = p.RsyncWorkflowWithTimeout(ctx, timeout, namespace, name, destNamespace, destName)
 	if err != nil {
-		p.LogSyncProgress(ctx, name, namespace, name, destNamespace, "Failed", fmt.Sprintf("PVC sync failed: %v", err))
+		p.LogSyncProgress(ctx, name, namespace, destName, destNamespace, "Failed", fmt.Sprintf("PVC sync failed: %v", err))
 		return fmt.Errorf("rsync workflow failed: %v", err)
 	}
 
@@ -59,12 +66,13 @@ func (p *PVCSyncer) SyncPVC(ctx context.Context, namespace, name string, destNam
 	}
 
 	// Log sync progress
-	p.LogSyncProgress(ctx, name, namespace, name, destNamespace, "Completed", "PVC sync completed successfully")
+	p.LogSyncProgress(ctx, name, namespace, destName, destNamespace, "Completed", "PVC sync completed successfully")
 
 	log.WithFields(logrus.Fields{
 		"source_namespace": namespace,
 		"source_pvc":       name,
 		"dest_namespace":   destNamespace,
+		"dest_pvc":         destName,
 	}).Info("PVC sync completed successfully")
 
 	return nil
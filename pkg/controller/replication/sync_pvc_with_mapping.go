@@ -5,6 +5,7 @@ import (
 	"fmt"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
 )
 
 // SyncPVCWithNamespaceMapping synchronizes a PVC from source to destination using the specified options and namespace mapping
@@ -21,17 +22,33 @@ func (p *PVCSyncer) SyncPVCWithNamespaceMapping(ctx context.Context, mapping *dr
 	p.SourceNamespace = opts.SourceNamespace
 	p.DestinationNamespace = opts.DestinationNamespace
 
-	// Check if source PVC is mounted
+	// A PVC without a volume has no data to sync
+	if opts.SourcePVC.Status.Phase != corev1.ClaimBound {
+		message := fmt.Sprintf("Source PVC is %s and not bound to a volume, skipping sync", opts.SourcePVC.Status.Phase)
+		log.WithFields(map[string]interface{}{
+			"source_namespace": opts.SourceNamespace,
+			"source_pvc":       opts.SourcePVC.Name,
+			"phase":            opts.SourcePVC.Status.Phase,
+		}).Info("Source PVC is not bound, skipping rsync")
+		p.RecordWarningEvent(ctx, opts.SourceNamespace, opts.SourcePVC.Name, EventReasonSyncSkipped, "%s", message)
+		p.recordSkip(ctx, opts.SourceNamespace, opts.SourcePVC.Name, SkipReasonNotBound, message)
+		return nil
+	}
+
+	// Check if source PVC is mounted, unmounted PVCs are left to the workflow when they may be mounted for the sync
 	hasMounts, err := p.HasVolumeAttachments(ctx, opts.SourceNamespace, opts.SourcePVC.Name)
 	if err != nil {
 		return fmt.Errorf("failed to check if source PVC is mounted: %v", err)
 	}
 
-	if !hasMounts {
+	if !hasMounts && !p.SyncUnmounted {
+		message := notMountedMessage
 		log.WithFields(map[string]interface{}{
 			"source_namespace": opts.SourceNamespace,
 			"source_pvc":       opts.SourcePVC.Name,
 		}).Info("Source PVC is not mounted, skipping rsync")
+		p.RecordWarningEvent(ctx, opts.SourceNamespace, opts.SourcePVC.Name, EventReasonSyncSkipped, "%s", message)
+		p.recordSkip(ctx, opts.SourceNamespace, opts.SourcePVC.Name, SkipReasonNotMounted, message)
 		return nil
 	}
 
@@ -48,8 +65,9 @@ func (p *PVCSyncer) SyncPVCWithNamespaceMapping(ctx context.Context, mapping *dr
 		opts.DestinationPVC.Name, opts.DestinationNamespace,
 		"Started", "PVC sync started")
 
-	// Perform the rsync workflow
-	err = p.RsyncWorkflow(ctx,
+	// Perform the rsync workflow, bounded by the PVC's sync timeout
+	timeout := ResolveSyncTimeout(opts.SourcePVC.Annotations, mapping)
+	err = p.RsyncWorkflowWithTimeout(ctx, timeout,
 		opts.SourceNamespace, opts.SourcePVC.Name,
 		opts.DestinationNamespace, opts.DestinationPVC.Name)
 	if err != nil {
@@ -57,7 +75,7 @@ func (p *PVCSyncer) SyncPVCWithNamespaceMapping(ctx context.Context, mapping *dr
 			opts.SourcePVC.Name, opts.SourceNamespace,
 			opts.DestinationPVC.Name, opts.DestinationNamespace,
 			"Failed", fmt.Sprintf("PVC sync failed: %v", err))
-		return fmt.Errorf("rsync workflow failed: %v", err)
+		return fmt.Errorf("rsync workflow failed: %w", err)
 	}
 
 	// Update namespace mapping status
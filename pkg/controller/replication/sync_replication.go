@@ -55,6 +55,11 @@ func (p *PVCSyncer) SyncReplication(ctx context.Context, sourceNS, destNS, pvcNa
 		}).Error("[DR-SYNC-ERROR] Failed to find DR-Syncer-Agent on node")
 		return fmt.Errorf("failed to find DR-Syncer-Agent on node: %v", err)
 	}
+	agentIP, sshPort, err := p.AgentSSHEndpoint(ctx, nodeName, agentIP)
+	if err != nil {
+		return fmt.Errorf("failed to resolve the DR-Syncer-Agent endpoint: %v", err)
+	}
+	ctx = withSSHPort(ctx, sshPort)
 
 	// Step 4: Find the mount path for the PVC
 	log.Info("[DR-SYNC] Step 4: Finding mount path for source PVC")
@@ -76,13 +81,13 @@ func (p *PVCSyncer) SyncReplication(ctx context.Context, sourceNS, destNS, pvcNa
 		return fmt.Errorf("failed to create rsync manager: %v", err)
 	}
 
-	opts := rsyncpod.RsyncPodOptions{
+	opts := p.rsyncPodOptions(rsyncpod.RsyncPodOptions{
 		Namespace:       destNS,
 		PVCName:         pvcName,
 		Type:            rsyncpod.DestinationPodType,
 		SyncID:          syncID,
 		ReplicationName: pvcName,
-	}
+	})
 
 	// Create the rsync deployment that mounts the destination PVC
 	rsyncDeployment, err := rsyncMgr.CreateRsyncDeployment(ctx, opts)
@@ -157,7 +162,7 @@ func (p *PVCSyncer) SyncReplication(ctx context.Context, sourceNS, destNS, pvcNa
 
 	// Step 11: Run the rsync command and monitor status
 	log.Info("[DR-SYNC] Step 11: Running rsync command")
-	err = p.performRsync(ctx, rsyncDeployment, agentIP, mountPath)
+	err = p.performRsyncFromAgent(ctx, agentPod, rsyncDeployment, agentIP, mountPath)
 	if err != nil {
 		log.WithFields(logrus.Fields{
 			"error": err,
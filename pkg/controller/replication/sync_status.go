@@ -3,6 +3,7 @@ package replication
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -11,6 +12,7 @@ import (
 
 	"github.com/sirupsen/logrus"
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/tempod"
 	"github.com/supporttools/dr-syncer/pkg/logging"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,6 +43,25 @@ const (
 
 	// EventReasonSyncSkipped indicates the sync was skipped (e.g., locked by another, PVC not mounted)
 	EventReasonSyncSkipped = "SyncSkipped"
+
+	// EventReasonSyncTimedOut indicates the sync exceeded its timeout and was aborted
+	EventReasonSyncTimedOut = "SyncTimedOut"
+
+	// EventReasonNoSpace indicates rsync aborted because the destination ran out of space
+	EventReasonNoSpace = "NoSpaceLeft"
+
+	// EventReasonInsufficientSpace indicates the destination PVC is smaller than the data of the source PVC
+	EventReasonInsufficientSpace = "InsufficientSpace"
+
+	// EventReasonDestinationGrown indicates the destination PVC was grown to fit the data of the source PVC
+	EventReasonDestinationGrown = "DestinationPVCGrown"
+
+	// EventReasonPendingBinding indicates the WaitForFirstConsumer destination PVC could not be bound on a node
+	EventReasonPendingBinding = "PendingBinding"
+
+	// EventReasonPodStartupFailed indicates the rsync pod cannot start, e.g. it is unschedulable or its image
+	// cannot be pulled
+	EventReasonPodStartupFailed = "PodStartupFailed"
 )
 
 // SyncStatus represents the status of a sync operation
@@ -54,9 +75,14 @@ type SyncStatus struct {
 	TotalFiles         int                 `json:"totalFiles,omitempty"`         // Total files to transfer (if known)
 	Progress           int                 `json:"progress"`                     // 0-100
 	SpeedBytesPerSec   float64             `json:"speedBytesPerSec,omitempty"`   // Current transfer speed
+	Speedup            float64             `json:"speedup,omitempty"`            // Total size divided by the bytes sent and received, from rsync --stats
 	EstimatedRemaining string              `json:"estimatedRemaining,omitempty"` // Estimated time remaining (e.g., "5m30s")
 	Error              string              `json:"error,omitempty"`
+	Reason             string              `json:"reason,omitempty"`  // Machine-readable reason when the sync was skipped or its pod could not start
+	Message            string              `json:"message,omitempty"` // Human-readable explanation for the reason
 	Verification       *VerificationResult `json:"verification,omitempty"`
+	FailureClass       RsyncFailureClass   `json:"failureClass,omitempty"` // Cause of a failed rsync, such as Network, Permission or Disk
+	ExitCode           int                 `json:"exitCode,omitempty"`     // Exit code of a failed rsync
 }
 
 // VerificationResult holds the result of data verification after sync
@@ -107,6 +133,11 @@ func (p *PVCSyncer) UpdateSyncStatus(ctx context.Context, namespace, pvcName str
 	pvc.Annotations["dr-syncer.io/sync-status"] = string(statusJSON)
 	pvc.Annotations["dr-syncer.io/last-updated"] = time.Now().UTC().Format(time.RFC3339)
 	pvc.Annotations["dr-syncer.io/phase"] = status.Phase
+	if status.Reason != "" && status.Phase == "Skipped" {
+		pvc.Annotations[SkipReasonAnnotation] = status.Reason
+	} else {
+		delete(pvc.Annotations, SkipReasonAnnotation)
+	}
 
 	if status.Progress > 0 {
 		pvc.Annotations["dr-syncer.io/progress"] = fmt.Sprintf("%d", status.Progress)
@@ -208,6 +239,71 @@ func ParseRsyncOutput(output string) (int64, int, int, error) {
 	return bytesTransferred, filesTransferred, progress, nil
 }
 
+// RsyncStats holds the transfer statistics printed by rsync --stats
+type RsyncStats struct {
+	// TotalSize is the total size of the files in the source
+	TotalSize int64
+	// BytesSent and BytesReceived are the bytes sent and received over the connection
+	BytesSent     int64
+	BytesReceived int64
+	// FilesTransferred is the number of regular files transferred
+	FilesTransferred int
+}
+
+// rsyncStatsPatterns match the lines of rsync --stats output, numbers may contain thousands separators
+var rsyncStatsPatterns = struct {
+	totalSize, sent, received, files *regexp.Regexp
+}{
+	totalSize: regexp.MustCompile(`^Total file size: ([0-9,]+) bytes`),
+	sent:      regexp.MustCompile(`^Total bytes sent: ([0-9,]+)`),
+	received:  regexp.MustCompile(`^Total bytes received: ([0-9,]+)`),
+	files:     regexp.MustCompile(`^Number of (?:regular )?files transferred: ([0-9,]+)`),
+}
+
+// ParseRsyncStats parses the statistics rsync prints with --stats, leaving missing values zero
+func ParseRsyncStats(output string) RsyncStats {
+	parse := func(re *regexp.Regexp, line string) (int64, bool) {
+		match := re.FindStringSubmatch(line)
+		if match == nil {
+			return 0, false
+		}
+		value, err := strconv.ParseInt(strings.ReplaceAll(match[1], ",", ""), 10, 64)
+		return value, err == nil
+	}
+
+	var stats RsyncStats
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if value, ok := parse(rsyncStatsPatterns.totalSize, line); ok {
+			stats.TotalSize = value
+		} else if value, ok := parse(rsyncStatsPatterns.sent, line); ok {
+			stats.BytesSent = value
+		} else if value, ok := parse(rsyncStatsPatterns.received, line); ok {
+			stats.BytesReceived = value
+		} else if value, ok := parse(rsyncStatsPatterns.files, line); ok {
+			stats.FilesTransferred = int(value)
+		}
+	}
+	return stats
+}
+
+// Add sums the statistics of another rsync stream
+func (s *RsyncStats) Add(other RsyncStats) {
+	s.TotalSize += other.TotalSize
+	s.BytesSent += other.BytesSent
+	s.BytesReceived += other.BytesReceived
+	s.FilesTransferred += other.FilesTransferred
+}
+
+// Speedup is the total size divided by the bytes sent and received, as reported by rsync. A high
+// speedup means the delta transfer saved most of the data, zero means there was nothing to compare.
+func (s RsyncStats) Speedup() float64 {
+	if s.BytesSent+s.BytesReceived == 0 {
+		return 0
+	}
+	return float64(s.TotalSize) / float64(s.BytesSent+s.BytesReceived)
+}
+
 // min is a helper function to return the minimum of two integers
 func min(a, b int) int {
 	if a < b {
@@ -430,16 +526,19 @@ func (p *PVCSyncer) CompleteSyncStatus(ctx context.Context, namespace, pvcName s
 	return p.UpdateSyncStatus(ctx, namespace, pvcName, status)
 }
 
-// CompleteSyncStatusWithVerification updates the sync status to completed with verification results
+// CompleteSyncStatusWithVerification updates the sync status to completed with the rsync statistics
+// and verification results
 func (p *PVCSyncer) CompleteSyncStatusWithVerification(ctx context.Context, namespace, pvcName string,
-	bytesTransferred int64, filesTransferred int, verification *VerificationResult) error {
+	stats RsyncStats, verification *VerificationResult) error {
 
 	status := SyncStatus{
 		Phase:            "Completed",
 		StartTime:        time.Now().Add(-1 * time.Minute), // Approximate
 		CompletionTime:   time.Now(),
-		BytesTransferred: bytesTransferred,
-		FilesTransferred: filesTransferred,
+		BytesTransferred: stats.BytesSent,
+		FilesTransferred: stats.FilesTransferred,
+		TotalBytes:       stats.TotalSize,
+		Speedup:          stats.Speedup(),
 		Progress:         100,
 		Verification:     verification,
 	}
@@ -474,6 +573,38 @@ func (p *PVCSyncer) FailedSyncStatus(ctx context.Context, namespace, pvcName str
 		Progress:         0,
 		Error:            errMsg,
 	}
+	if rsyncErr, ok := AsRsyncError(err); ok {
+		status.FailureClass = rsyncErr.Class
+		status.ExitCode = rsyncErr.ExitCode
+	}
+	var startupErr *tempod.PodStartupError
+	if errors.As(err, &startupErr) {
+		status.Reason = startupErr.Reason
+		status.Message = startupErr.Message
+	}
+
+	return p.UpdateSyncStatus(ctx, namespace, pvcName, status)
+}
+
+// SkippedSyncStatus updates the sync status to skipped with the given reason
+func (p *PVCSyncer) SkippedSyncStatus(ctx context.Context, namespace, pvcName, reason, message string) error {
+	status := SyncStatus{
+		Phase:          "Skipped",
+		CompletionTime: time.Now(),
+		Reason:         reason,
+		Message:        message,
+	}
+
+	return p.UpdateSyncStatus(ctx, namespace, pvcName, status)
+}
+
+// TimedOutSyncStatus updates the sync status to timed out
+func (p *PVCSyncer) TimedOutSyncStatus(ctx context.Context, namespace, pvcName string, timeout time.Duration) error {
+	status := SyncStatus{
+		Phase:          SyncPhaseTimedOut,
+		CompletionTime: time.Now(),
+		Error:          fmt.Sprintf("sync exceeded timeout of %s", timeout),
+	}
 
 	return p.UpdateSyncStatus(ctx, namespace, pvcName, status)
 }
@@ -1,9 +1,15 @@
 package replication
 
 import (
+	"context"
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/agent/tempod"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 func TestParseRsyncOutput_SentBytes(t *testing.T) {
@@ -237,6 +243,7 @@ func TestEventReasonConstants(t *testing.T) {
 	assert.Equal(t, "SyncFailed", EventReasonSyncFailed)
 	assert.Equal(t, "LockReleased", EventReasonLockReleased)
 	assert.Equal(t, "SyncSkipped", EventReasonSyncSkipped)
+	assert.Equal(t, "PodStartupFailed", EventReasonPodStartupFailed)
 }
 
 func TestEventReasonConstants_AllUpperCamelCase(t *testing.T) {
@@ -250,6 +257,7 @@ func TestEventReasonConstants_AllUpperCamelCase(t *testing.T) {
 		EventReasonSyncFailed,
 		EventReasonLockReleased,
 		EventReasonSyncSkipped,
+		EventReasonPodStartupFailed,
 	}
 
 	for _, reason := range reasons {
@@ -261,6 +269,23 @@ func TestEventReasonConstants_AllUpperCamelCase(t *testing.T) {
 	}
 }
 
+func TestFailedSyncStatus_PodStartupError(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(newTestPVC("app", "data", nil))
+	p := &PVCSyncer{SourceK8sClient: client}
+
+	startupErr := &tempod.PodStartupError{Namespace: "app-dr", Name: "rsync-0", Reason: "FailedScheduling", Message: "0/3 nodes are available"}
+	require.NoError(t, p.FailedSyncStatus(ctx, "app", "data", fmt.Errorf("failed to deploy rsync pod: %w", startupErr)))
+
+	pvc, err := client.CoreV1().PersistentVolumeClaims("app").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	status := skipStatus(t, pvc)
+	assert.Equal(t, "Failed", status.Phase)
+	assert.Equal(t, "FailedScheduling", status.Reason)
+	assert.Equal(t, "0/3 nodes are available", status.Message)
+	assert.NotContains(t, pvc.Annotations, SkipReasonAnnotation, "failed syncs are not skipped")
+}
+
 func TestPVCSyncer_RecordEventWithNilRecorder(t *testing.T) {
 	// Test that RecordNormalEvent/RecordWarningEvent gracefully handle nil EventRecorder
 	syncer := &PVCSyncer{
@@ -275,3 +300,41 @@ func TestPVCSyncer_RecordEventWithNilRecorder(t *testing.T) {
 		assert.Nil(t, syncer.SourceEventRecorder)
 	})
 }
+
+func TestParseRsyncStats(t *testing.T) {
+	output := `
+sending incremental file list
+data/orders.db
+    104,857,600 100%   98.45MB/s    0:00:01 (xfr#1, to-chk=0/42)
+
+Number of files: 43 (reg: 40, dir: 3)
+Number of created files: 1 (reg: 1)
+Number of deleted files: 0
+Number of regular files transferred: 2
+Total file size: 2,147,483,648 bytes
+Total transferred file size: 104,867,600 bytes
+Literal data: 1,048,576 bytes
+Matched data: 103,819,024 bytes
+File list size: 1,234
+Total bytes sent: 1,052,100
+Total bytes received: 2,500
+
+sent 1,052,100 bytes  received 2,500 bytes  703,066.67 bytes/sec
+total size is 2,147,483,648  speedup is 2,036.30
+`
+	stats := ParseRsyncStats(output)
+
+	assert.Equal(t, RsyncStats{TotalSize: 2147483648, BytesSent: 1052100, BytesReceived: 2500, FilesTransferred: 2}, stats)
+	assert.InDelta(t, 2036.30, stats.Speedup(), 0.01)
+}
+
+func TestRsyncStats_Add(t *testing.T) {
+	stats := ParseRsyncStats("Total file size: 1,000 bytes\nTotal bytes sent: 150\nTotal bytes received: 50\nNumber of regular files transferred: 1\n")
+	stats.Add(ParseRsyncStats("Total file size: 3,000 bytes\nTotal bytes sent: 750\nTotal bytes received: 50\nNumber of regular files transferred: 3\n"))
+
+	assert.Equal(t, RsyncStats{TotalSize: 4000, BytesSent: 900, BytesReceived: 100, FilesTransferred: 4}, stats)
+	assert.Equal(t, 4.0, stats.Speedup())
+
+	// Without statistics there is no speedup
+	assert.Zero(t, ParseRsyncStats("rsync: connection unexpectedly closed").Speedup())
+}
@@ -0,0 +1,166 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/rsyncpod"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// DefaultSyncTimeout bounds a PVC sync when no timeout is configured, matching the CRD default
+	DefaultSyncTimeout = 30 * time.Minute
+
+	// AnnotationSyncTimeout overrides the sync timeout for a single source PVC (e.g. "2h")
+	AnnotationSyncTimeout = "dr-syncer.io/sync-timeout"
+
+	// SyncPhaseTimedOut is the sync status phase of a PVC sync that exceeded its timeout
+	SyncPhaseTimedOut = "TimedOut"
+
+	// rsyncKillTimeout bounds the cleanup performed after a sync timed out
+	rsyncKillTimeout = 30 * time.Second
+)
+
+// ResolveSyncTimeout returns the timeout for a PVC sync. The source PVC annotation
+// takes priority over the NamespaceMapping's dataSyncConfig.timeout.
+func ResolveSyncTimeout(pvcAnnotations map[string]string, mapping *drv1alpha1.NamespaceMapping) time.Duration {
+	if value, ok := pvcAnnotations[AnnotationSyncTimeout]; ok {
+		if timeout, err := time.ParseDuration(value); err == nil && timeout > 0 {
+			return timeout
+		}
+		log.WithFields(logrus.Fields{
+			"annotation": AnnotationSyncTimeout,
+			"value":      value,
+		}).Warn(logging.LogTagWarn + " Ignoring invalid sync timeout annotation")
+	}
+
+	if mapping != nil && mapping.Spec.PVCConfig != nil && mapping.Spec.PVCConfig.DataSyncConfig != nil {
+		if timeout := mapping.Spec.PVCConfig.DataSyncConfig.Timeout; timeout != nil && timeout.Duration > 0 {
+			return timeout.Duration
+		}
+	}
+
+	return DefaultSyncTimeout
+}
+
+// syncTimeoutFor resolves the sync timeout for a source PVC, reading its annotations from the source cluster
+func (p *PVCSyncer) syncTimeoutFor(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, namespace, pvcName string) time.Duration {
+	var annotations map[string]string
+	if pvc, err := p.SourceK8sClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{}); err == nil {
+		annotations = pvc.Annotations
+	}
+	return ResolveSyncTimeout(annotations, mapping)
+}
+
+// rsyncPIDFile returns the path in the destination pod holding the pid of the rsync for a PVC
+func rsyncPIDFile(namespace, pvcName string) string {
+	return fmt.Sprintf("/tmp/dr-syncer-rsync-%s-%s.pid", namespace, pvcName)
+}
+
+// killRemoteRsync terminates the rsync process left running in the destination pod after its sync timed out
+func (p *PVCSyncer) killRemoteRsync(destDeployment *rsyncpod.RsyncDeployment, pidFile string) {
+	ctx, cancel := context.WithTimeout(context.Background(), rsyncKillTimeout)
+	defer cancel()
+
+	cmd := []string{"sh", "-c", fmt.Sprintf("if [ -f %[1]s ]; then kill -TERM $(cat %[1]s) 2>/dev/null; rm -f %[1]s; fi", pidFile)}
+	if _, stderr, err := rsyncpod.ExecuteCommandInPod(context.WithValue(ctx, SyncerKey, p), p.DestinationK8sClient,
+		destDeployment.Namespace, destDeployment.PodName, cmd, p.DestinationConfig); err != nil {
+		log.WithFields(logrus.Fields{
+			"pod_name": destDeployment.PodName,
+			"error":    err,
+			"stderr":   stderr,
+		}).Warn(logging.LogTagWarn + " Failed to kill rsync process after timeout")
+		return
+	}
+
+	log.WithField("pod_name", destDeployment.PodName).Info(logging.LogTagInfo + " Killed rsync process after timeout")
+}
+
+// RsyncWorkflowWithTimeout runs RsyncWorkflow, or ObjectStorageWorkflow when the PVC data is transferred
+// through object storage and TarStreamWorkflow when the source cluster is agentless, under a deadline
+// covering every phase of the sync.
+// When the deadline expires the rsync resources are cleaned up, the lock is released and the
+// PVC sync status is marked TimedOut.
+func (p *PVCSyncer) RsyncWorkflowWithTimeout(ctx context.Context, timeout time.Duration, sourceNamespace, sourcePVCName, destNamespace, destPVCName string) error {
+	syncCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	workflow, workflowName := p.RsyncWorkflow, "rsync"
+	if p.Agentless {
+		workflow, workflowName = p.TarStreamWorkflow, "tar-stream"
+	} else if p.ObjectStorage != nil {
+		workflow, workflowName = p.ObjectStorageWorkflow, "object-storage"
+	} else if usesSourceSSHD(p.Strategy) {
+		workflow, workflowName = p.SourceSSHDWorkflow, "source-sshd"
+	}
+
+	// The workflow's steps are traced as children of the PVC sync span
+	syncCtx, endTrace := tracing.StartWorkflow(syncCtx, "PVCSync",
+		attribute.String("workflow", workflowName),
+		attribute.String("source.namespace", sourceNamespace),
+		attribute.String("source.pvc", sourcePVCName),
+		attribute.String("destination.namespace", destNamespace),
+		attribute.String("destination.pvc", destPVCName),
+	)
+	err := workflow(syncCtx, sourceNamespace, sourcePVCName, destNamespace, destPVCName)
+	endTrace(err)
+	if err == nil || !errors.Is(syncCtx.Err(), context.DeadlineExceeded) || ctx.Err() != nil {
+		return err
+	}
+
+	log.WithFields(logrus.Fields{
+		"source_namespace": sourceNamespace,
+		"source_pvc":       sourcePVCName,
+		"dest_namespace":   destNamespace,
+		"dest_pvc":         destPVCName,
+		"timeout":          timeout,
+	}).Error(logging.LogTagError + " PVC sync timed out")
+
+	// The workflow's own cleanup ran on the expired context, so repeat it on the caller's context
+	if rsyncMgr, mgrErr := rsyncpod.NewManager(p.DestinationConfig); mgrErr == nil {
+		if cleanupErr := rsyncMgr.CleanupExistingDeployments(ctx, destNamespace, destPVCName); cleanupErr != nil {
+			log.WithFields(logrus.Fields{
+				"dest_namespace": destNamespace,
+				"dest_pvc":       destPVCName,
+				"error":          cleanupErr,
+			}).Warn(logging.LogTagWarn + " Failed to cleanup rsync deployments after timeout")
+		}
+	}
+	if p.Agentless {
+		p.cleanupTarSource(ctx, sourceNamespace, sourcePVCName)
+	} else if p.ObjectStorage == nil && usesSourceSSHD(p.Strategy) {
+		p.cleanupSourceSSHD(ctx, sourceNamespace, sourcePVCName)
+	}
+	if p.usesAttachPod() {
+		p.cleanupAttachPods(ctx, sourceNamespace, sourcePVCName)
+	}
+
+	if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+		log.WithFields(logrus.Fields{
+			"source_namespace": sourceNamespace,
+			"source_pvc":       sourcePVCName,
+			"error":            relErr,
+		}).Warn(logging.LogTagWarn + " Failed to release lock on source PVC after timeout")
+	}
+
+	if statusErr := p.TimedOutSyncStatus(ctx, sourceNamespace, sourcePVCName, timeout); statusErr != nil {
+		log.WithFields(logrus.Fields{
+			"source_namespace": sourceNamespace,
+			"source_pvc":       sourcePVCName,
+			"error":            statusErr,
+		}).Warn(logging.LogTagWarn + " Failed to mark PVC sync as timed out")
+	}
+
+	p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncTimedOut,
+		"PVC data sync timed out after %s", timeout)
+
+	return fmt.Errorf("PVC sync timed out after %s: %v", timeout, err)
+}
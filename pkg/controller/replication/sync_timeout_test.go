@@ -0,0 +1,48 @@
+package replication
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveSyncTimeout(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{Spec: drv1alpha1.NamespaceMappingSpec{
+		PVCConfig: &drv1alpha1.PVCConfig{DataSyncConfig: &drv1alpha1.PVCDataSyncConfig{
+			Timeout: &metav1.Duration{Duration: time.Hour},
+		}},
+	}}
+
+	assert.Equal(t, DefaultSyncTimeout, ResolveSyncTimeout(nil, nil))
+	assert.Equal(t, DefaultSyncTimeout, ResolveSyncTimeout(nil, &drv1alpha1.NamespaceMapping{}))
+	assert.Equal(t, time.Hour, ResolveSyncTimeout(nil, mapping))
+
+	// The PVC annotation wins over the mapping
+	assert.Equal(t, 2*time.Hour, ResolveSyncTimeout(map[string]string{AnnotationSyncTimeout: "2h"}, mapping))
+
+	// Invalid annotations fall back to the mapping
+	assert.Equal(t, time.Hour, ResolveSyncTimeout(map[string]string{AnnotationSyncTimeout: "soon"}, mapping))
+	assert.Equal(t, time.Hour, ResolveSyncTimeout(map[string]string{AnnotationSyncTimeout: "-5m"}, mapping))
+}
+
+func TestTimedOutSyncStatus(t *testing.T) {
+	sourceClient := fake.NewSimpleClientset(newTestPVC("app", "data", nil))
+	p := &PVCSyncer{SourceK8sClient: sourceClient}
+
+	require.NoError(t, p.TimedOutSyncStatus(context.Background(), "app", "data", 30*time.Minute))
+
+	pvc, err := sourceClient.CoreV1().PersistentVolumeClaims("app").Get(context.Background(), "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, SyncPhaseTimedOut, pvc.Annotations["dr-syncer.io/phase"])
+	assert.Contains(t, pvc.Annotations["dr-syncer.io/sync-status"], "sync exceeded timeout of 30m0s")
+}
+
+func TestRsyncPIDFile(t *testing.T) {
+	assert.Equal(t, "/tmp/dr-syncer-rsync-app-dr-data.pid", rsyncPIDFile("app-dr", "data"))
+}
@@ -0,0 +1,421 @@
+package replication
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// Agentless source clusters run no agent and accept no SSH connections. A helper pod mounting the source
+// PVC read-only writes a tar archive of it to the stdout of an exec session, which the controller copies
+// into the stdin of an exec session extracting it in the destination rsync pod.
+const (
+	// tarSourceAppName names the helper pods and labels them
+	tarSourceAppName = "dr-syncer-tar-source"
+
+	// tarSourceDataPath is where the helper pod mounts the source PVC
+	tarSourceDataPath = "/data"
+
+	// tarDestinationPath is where the destination rsync pod mounts the destination PVC
+	tarDestinationPath = "/data"
+
+	// tarStagingDir is the directory of the destination PVC the archive is extracted into before it
+	// replaces the previous data, so a failed transfer leaves the previous data in place
+	tarStagingDir = ".dr-syncer-incoming"
+
+	// tarSourceReadyTimeout bounds the wait for the helper pod to run
+	tarSourceReadyTimeout = 5 * time.Minute
+
+	// tarSourceCommand keeps the helper pod running until it is deleted
+	tarSourceCommand = "trap 'exit 0' TERM; while true; do sleep 3600 & wait $!; done"
+)
+
+// tarSourceOptions describes the helper pod of an agentless sync
+type tarSourceOptions struct {
+	// Namespace is the namespace of the source PVC
+	Namespace string
+
+	// PVCName is the name of the source PVC
+	PVCName string
+
+	// SyncID makes the name of the pod unique
+	SyncID string
+
+	// Node pins the pod to the node mounting the source PVC, so ReadWriteOnce volumes can be mounted.
+	// An unmounted PVC leaves it empty for the scheduler to place the pod.
+	Node string
+
+	// Image and PullPolicy are those of the agent, which ships tar
+	Image      string
+	PullPolicy corev1.PullPolicy
+}
+
+// tarSourceSelector selects the helper pods of a source PVC
+func tarSourceSelector(pvcName string) string {
+	return fmt.Sprintf("app.kubernetes.io/name=%s,dr-syncer.io/pvc-name=%s", tarSourceAppName, sourceSSHDPVCLabel(pvcName))
+}
+
+// tarSourcePod builds the unprivileged helper pod of an agentless sync
+func tarSourcePod(opts tarSourceOptions) *corev1.Pod {
+	automountToken := false
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-%s", tarSourceAppName, opts.SyncID),
+			Namespace: opts.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/name":       tarSourceAppName,
+				"app.kubernetes.io/instance":   opts.SyncID,
+				"app.kubernetes.io/managed-by": "dr-syncer",
+				"dr-syncer.io/sync-id":         opts.SyncID,
+				"dr-syncer.io/pvc-name":        sourceSSHDPVCLabel(opts.PVCName),
+			},
+		},
+		Spec: corev1.PodSpec{
+			NodeName:                     opts.Node,
+			RestartPolicy:                corev1.RestartPolicyNever,
+			AutomountServiceAccountToken: &automountToken,
+			Containers: []corev1.Container{
+				{
+					Name:            "tar",
+					Image:           opts.Image,
+					ImagePullPolicy: opts.PullPolicy,
+					Command:         []string{"/bin/sh", "-c", tarSourceCommand},
+					VolumeMounts: []corev1.VolumeMount{
+						{Name: "data", MountPath: tarSourceDataPath, ReadOnly: true},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: opts.PVCName,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// tarCreateCommand writes a tar archive of the source PVC to stdout
+func tarCreateCommand() []string {
+	return []string{"tar", "-cf", "-", "-C", tarSourceDataPath, "."}
+}
+
+// tarExtractCommand extracts the tar archive read from stdin into the staging directory of the
+// destination PVC, listing the extracted entries on stdout
+func tarExtractCommand() []string {
+	staging := tarDestinationPath + "/" + tarStagingDir
+	return []string{"sh", "-c", fmt.Sprintf("set -e; rm -rf %[1]s; mkdir -p %[1]s; tar -xvf - -C %[1]s", staging)}
+}
+
+// tarReplaceCommand replaces the data of the destination PVC with the extracted archive
+func tarReplaceCommand() []string {
+	staging := tarDestinationPath + "/" + tarStagingDir
+	return []string{"sh", "-c", fmt.Sprintf(
+		"set -e; find %[1]s -mindepth 1 -maxdepth 1 ! -name %[2]s -exec rm -rf {} +; "+
+			"find %[3]s -mindepth 1 -maxdepth 1 -exec mv {} %[1]s/ \\;; rmdir %[3]s",
+		tarDestinationPath, tarStagingDir, staging)}
+}
+
+// tarDiscardCommand removes the staging directory of a failed transfer
+func tarDiscardCommand() []string {
+	return []string{"rm", "-rf", tarDestinationPath + "/" + tarStagingDir}
+}
+
+// byteCounter counts the bytes written through it
+type byteCounter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *byteCounter) Write(b []byte) (int, error) {
+	n, err := c.w.Write(b)
+	c.n += int64(n)
+	return n, err
+}
+
+// lineCounter counts the lines written to it
+type lineCounter struct {
+	n int
+}
+
+func (c *lineCounter) Write(b []byte) (int, error) {
+	c.n += bytes.Count(b, []byte("\n"))
+	return len(b), nil
+}
+
+// deployTarSource creates the helper pod of an agentless sync and waits until it runs
+func (p *PVCSyncer) deployTarSource(ctx context.Context, opts tarSourceOptions) (*corev1.Pod, error) {
+	pod := tarSourcePod(opts)
+	if _, err := p.SourceK8sClient.CoreV1().Pods(opts.Namespace).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		return nil, fmt.Errorf("failed to create tar helper pod %s/%s: %v", opts.Namespace, pod.Name, err)
+	}
+
+	log.WithFields(logrus.Fields{
+		"namespace": opts.Namespace,
+		"pod_name":  pod.Name,
+		"pvc":       opts.PVCName,
+		"node":      opts.Node,
+	}).Info(logging.LogTagDetail + " Waiting for tar helper pod in source cluster")
+
+	var ready *corev1.Pod
+	err := wait.PollUntilContextTimeout(ctx, 2*time.Second, tarSourceReadyTimeout, true, func(ctx context.Context) (bool, error) {
+		current, err := p.SourceK8sClient.CoreV1().Pods(opts.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		if current.Status.Phase == corev1.PodFailed || current.Status.Phase == corev1.PodSucceeded {
+			return false, fmt.Errorf("tar helper pod terminated in phase %s", current.Status.Phase)
+		}
+		for _, condition := range current.Status.Conditions {
+			if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+				ready = current
+				return true, nil
+			}
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("tar helper pod %s/%s not ready: %v", opts.Namespace, pod.Name, err)
+	}
+	return ready, nil
+}
+
+// cleanupTarSource deletes the helper pods of a source PVC
+func (p *PVCSyncer) cleanupTarSource(ctx context.Context, namespace, pvcName string) {
+	fields := logrus.Fields{
+		"namespace": namespace,
+		"pvc":       pvcName,
+	}
+	pods := p.SourceK8sClient.CoreV1().Pods(namespace)
+	list, err := pods.List(ctx, metav1.ListOptions{LabelSelector: tarSourceSelector(pvcName)})
+	if err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to list tar helper pods")
+		return
+	}
+	for _, pod := range list.Items {
+		if err := pods.Delete(ctx, pod.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			log.WithFields(fields).WithFields(logrus.Fields{
+				"pod_name": pod.Name,
+				"error":    err,
+			}).Warn(logging.LogTagWarn + " Failed to delete tar helper pod")
+		}
+	}
+}
+
+// streamTar copies a tar archive of the source PVC from the helper pod into the destination rsync pod
+// and, once both ends succeeded, replaces the destination data with it. It returns the size of the
+// archive and the number of entries extracted.
+func (p *PVCSyncer) streamTar(ctx context.Context, sourcePod *corev1.Pod, destNamespace, destPodName string) (int64, int, error) {
+	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	reader, writer := io.Pipe()
+	archive := &byteCounter{w: writer}
+	sourceDone := make(chan error, 1)
+	go func() {
+		var stderr bytes.Buffer
+		err := execInPodStreams(streamCtx, p.SourceK8sClient, p.SourceConfig, sourcePod.Namespace, sourcePod.Name,
+			tarCreateCommand(), nil, archive, &stderr)
+		if err != nil {
+			err = fmt.Errorf("tar in source pod %s/%s failed: %v: %s", sourcePod.Namespace, sourcePod.Name, err, strings.TrimSpace(stderr.String()))
+			// The destination must not take a truncated archive for a complete one
+			cancel()
+		}
+		_ = writer.CloseWithError(err)
+		sourceDone <- err
+	}()
+
+	entries := &lineCounter{}
+	var stderr bytes.Buffer
+	destErr := execInPodStreams(streamCtx, p.DestinationK8sClient, p.DestinationConfig, destNamespace, destPodName,
+		tarExtractCommand(), reader, entries, &stderr)
+	// Unblock the source when the destination stopped reading
+	_ = reader.CloseWithError(io.ErrClosedPipe)
+	sourceErr := <-sourceDone
+
+	err := sourceErr
+	if err == nil && destErr != nil {
+		err = fmt.Errorf("tar in destination pod %s/%s failed: %v: %s", destNamespace, destPodName, destErr, strings.TrimSpace(stderr.String()))
+	}
+	if err == nil {
+		stderr.Reset()
+		replaceErr := execInPodStreams(ctx, p.DestinationK8sClient, p.DestinationConfig, destNamespace, destPodName,
+			tarReplaceCommand(), nil, io.Discard, &stderr)
+		if replaceErr == nil {
+			return archive.n, entries.n, nil
+		}
+		err = fmt.Errorf("failed to replace the destination data: %v: %s", replaceErr, strings.TrimSpace(stderr.String()))
+	}
+
+	discardErr := execInPodStreams(ctx, p.DestinationK8sClient, p.DestinationConfig, destNamespace, destPodName,
+		tarDiscardCommand(), nil, io.Discard, io.Discard)
+	if discardErr != nil {
+		log.WithFields(logrus.Fields{
+			"dest_namespace": destNamespace,
+			"dest_pod":       destPodName,
+			"error":          discardErr,
+		}).Warn(logging.LogTagWarn + " Failed to remove the staging directory of a failed transfer")
+	}
+	return 0, 0, err
+}
+
+// TarStreamWorkflow syncs the data of a PVC out of an agentless source cluster. A helper pod mounting the
+// source PVC read-only is started in the source namespace and the tar archive it writes is streamed
+// through the exec API of both clusters into the destination rsync pod. The clusters never connect to
+// each other and nothing privileged runs in the source cluster.
+func (p *PVCSyncer) TarStreamWorkflow(ctx context.Context, sourceNamespace, sourcePVCName, destNamespace, destPVCName string) error {
+	startTime := time.Now()
+
+	// Tag the logs of the workflow with the trace of the sync
+	log := log.WithContext(ctx)
+
+	fields := logrus.Fields{
+		"source_namespace": sourceNamespace,
+		"source_pvc":       sourcePVCName,
+		"dest_namespace":   destNamespace,
+		"dest_pvc":         destPVCName,
+	}
+	log.WithFields(fields).Info(logging.LogTagInfo + " Starting agentless tar stream workflow")
+
+	if skip, err := p.skipBlockVolume(ctx, sourceNamespace, sourcePVCName, destNamespace, destPVCName); err != nil {
+		return err
+	} else if skip {
+		return nil
+	}
+
+	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncStarted,
+		"Starting agentless PVC data sync to %s/%s", destNamespace, destPVCName)
+
+	p.SourceNamespace = sourceNamespace
+	p.DestinationNamespace = destNamespace
+
+	tracing.Step(ctx, "lock")
+	acquired, lockInfo, err := p.AcquirePVCLock(ctx, sourceNamespace, sourcePVCName)
+	if err != nil {
+		return fmt.Errorf("failed to check lock on source PVC: %v", err)
+	}
+	if !acquired {
+		log.WithFields(fields).WithField("lock_owner", lockInfo.ControllerPodName).
+			Info(logging.LogTagSkip + " Source PVC is locked by another controller, skipping sync")
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped,
+			"PVC is locked by %s, skipping sync", lockInfo.ControllerPodName)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonLocked, "")
+		return nil
+	}
+	defer func() {
+		if relErr := p.ReleasePVCLock(ctx, sourceNamespace, sourcePVCName); relErr != nil {
+			log.WithFields(fields).WithField("error", relErr).Warn(logging.LogTagWarn + " Failed to release lock on source PVC")
+		}
+	}()
+
+	fail := func(format string, args ...interface{}) error {
+		err := fmt.Errorf(format, args...)
+		log.WithFields(fields).WithField("error", err).Error(logging.LogTagError + " Agentless tar stream workflow failed")
+		p.RecordWarningEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncFailed, "%v", err)
+		if statusErr := p.FailedSyncStatus(ctx, sourceNamespace, sourcePVCName, err); statusErr != nil {
+			log.WithFields(fields).WithField("error", statusErr).Warn(logging.LogTagWarn + " Failed to update sync status")
+		}
+		return err
+	}
+
+	tracing.Step(ctx, "locate-source")
+	mounted, err := p.HasVolumeAttachments(ctx, sourceNamespace, sourcePVCName)
+	if err != nil {
+		return fail("failed to check if source PVC is mounted: %v", err)
+	}
+	// The helper pod mounts an unmounted PVC itself, on the node the scheduler picks
+	var sourceNode string
+	switch {
+	case mounted:
+		sourceNode, err = p.FindPVCNode(ctx, p.SourceClient, sourceNamespace, sourcePVCName)
+		if err != nil {
+			return fail("failed to find node where source PVC is mounted: %v", err)
+		}
+	case p.SyncUnmounted:
+		log.WithFields(fields).Info(logging.LogTagInfo + " Source PVC is not mounted, mounting it in the tar helper pod")
+	default:
+		log.WithFields(fields).Info(logging.LogTagSkip + " Source PVC is not mounted, skipping sync")
+		message := notMountedMessage
+		p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncSkipped, "%s", message)
+		p.recordSkip(ctx, sourceNamespace, sourcePVCName, SkipReasonNotMounted, message)
+		return nil
+	}
+
+	tracing.Step(ctx, "deploy")
+	image, pullPolicy := sourceSSHDImage(p.sourceRemoteCluster(ctx))
+	defer p.cleanupTarSource(ctx, sourceNamespace, sourcePVCName)
+	sourcePod, err := p.deployTarSource(ctx, tarSourceOptions{
+		Namespace:  sourceNamespace,
+		PVCName:    sourcePVCName,
+		SyncID:     rand.String(8),
+		Node:       sourceNode,
+		Image:      image,
+		PullPolicy: pullPolicy,
+	})
+	if err != nil {
+		return fail("failed to deploy tar helper pod in source cluster: %v", err)
+	}
+
+	if p.skipUnchanged(ctx, sourceNamespace, sourcePVCName, sourcePod, tarSourceDataPath) {
+		return nil
+	}
+
+	destPod, err := p.deployRsyncPod(ctx, destNamespace, destPVCName)
+	if err != nil {
+		return fail("failed to deploy rsync pod in destination cluster: %v", err)
+	}
+	defer p.cleanupResources(ctx, destPod)
+	p.recordLockSync(ctx, sourceNamespace, sourcePVCName, destPod)
+
+	if err := p.InitSyncStatus(ctx, sourceNamespace, sourcePVCName); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to initialize sync status")
+	}
+
+	tracing.Step(ctx, "stream")
+	log.WithFields(fields).WithFields(logrus.Fields{
+		"source_pod": sourcePod.Name,
+		"dest_pod":   destPod.PodName,
+	}).Info(logging.LogTagDetail + " Streaming tar archive of source PVC into destination PVC")
+	size, entries, err := p.streamTar(ctx, sourcePod, destPod.Namespace, destPod.PodName)
+	if err != nil {
+		return fail("failed to stream PVC data: %v", err)
+	}
+
+	tracing.Step(ctx, "annotations")
+	if err := p.UpdateSourcePVCAnnotations(ctx, sourceNamespace, sourcePVCName); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to update source PVC annotations")
+	}
+	if err := p.recordDataSyncStart(ctx, sourceNamespace, sourcePVCName, startTime); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to record data sync start for change detection")
+	}
+	if err := p.CompleteSyncStatus(ctx, sourceNamespace, sourcePVCName, size, entries); err != nil {
+		log.WithFields(fields).WithField("error", err).Warn(logging.LogTagWarn + " Failed to update sync status")
+	}
+
+	duration := time.Since(startTime)
+	p.RecordNormalEvent(ctx, sourceNamespace, sourcePVCName, EventReasonSyncCompleted,
+		"Agentless PVC data sync completed, %d bytes streamed (duration: %s)", size, duration.Round(time.Second))
+	log.WithFields(fields).WithFields(logrus.Fields{
+		"bytes":    size,
+		"entries":  entries,
+		"duration": duration.Round(time.Second),
+	}).Info(logging.LogTagComplete + " Agentless tar stream workflow completed successfully")
+	return nil
+}
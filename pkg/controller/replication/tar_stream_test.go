@@ -0,0 +1,59 @@
+package replication
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestTarSourcePod(t *testing.T) {
+	pod := tarSourcePod(tarSourceOptions{
+		Namespace:  "app",
+		PVCName:    "data.v1",
+		SyncID:     "abc123",
+		Node:       "node-1",
+		Image:      "supporttools/dr-syncer-agent:v1",
+		PullPolicy: corev1.PullIfNotPresent,
+	})
+
+	assert.Equal(t, "dr-syncer-tar-source-abc123", pod.Name)
+	assert.Equal(t, "node-1", pod.Spec.NodeName)
+	assert.Equal(t, "data-v1", pod.Labels["dr-syncer.io/pvc-name"])
+	require.Len(t, pod.Spec.Containers, 1)
+	assert.Nil(t, pod.Spec.Containers[0].SecurityContext, "the helper pod is not privileged")
+	assert.True(t, pod.Spec.Containers[0].VolumeMounts[0].ReadOnly)
+	require.NotNil(t, pod.Spec.Volumes[0].PersistentVolumeClaim)
+	assert.Equal(t, "data.v1", pod.Spec.Volumes[0].PersistentVolumeClaim.ClaimName)
+	assert.True(t, pod.Spec.Volumes[0].PersistentVolumeClaim.ReadOnly)
+}
+
+func TestTarCommands(t *testing.T) {
+	assert.Equal(t, []string{"tar", "-cf", "-", "-C", "/data", "."}, tarCreateCommand())
+	assert.Contains(t, tarExtractCommand()[2], "tar -xvf - -C /data/.dr-syncer-incoming")
+	assert.Contains(t, tarReplaceCommand()[2], "! -name .dr-syncer-incoming")
+	assert.Equal(t, []string{"rm", "-rf", "/data/.dr-syncer-incoming"}, tarDiscardCommand())
+}
+
+func TestCleanupTarSource(t *testing.T) {
+	helper := tarSourcePod(tarSourceOptions{Namespace: "app", PVCName: "data", SyncID: "abc123"})
+	other := tarSourcePod(tarSourceOptions{Namespace: "app", PVCName: "logs", SyncID: "def456"})
+	client := fake.NewSimpleClientset(helper, other)
+	p := &PVCSyncer{SourceK8sClient: client}
+
+	p.cleanupTarSource(context.Background(), "app", "data")
+
+	pods, err := client.CoreV1().Pods("app").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, pods.Items, 1)
+	assert.Equal(t, other.Name, pods.Items[0].Name)
+}
+
+func TestUsesAttachPod_Agentless(t *testing.T) {
+	assert.True(t, (&PVCSyncer{SyncUnmounted: true}).usesAttachPod())
+	assert.False(t, (&PVCSyncer{SyncUnmounted: true, Agentless: true}).usesAttachPod(), "the tar helper pod mounts unmounted PVCs")
+}
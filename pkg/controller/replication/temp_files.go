@@ -0,0 +1,42 @@
+package replication
+
+import (
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+// rsyncTempDirName is the directory at the root of the destination volume rsync writes the
+// transferred files to with the TempDir temp files mode. It is excluded from the sync, which
+// also protects it from --delete.
+const rsyncTempDirName = ".dr-syncer-tmp"
+
+// hasTempFileOption returns true if the rsync options already choose where transferred files are written
+func hasTempFileOption(options []string) bool {
+	for _, opt := range options {
+		if opt == "--inplace" || opt == "--temp-dir" || strings.HasPrefix(opt, "--temp-dir=") ||
+			strings.HasPrefix(opt, "-T") {
+			return true
+		}
+	}
+	return false
+}
+
+// tempFileRsyncOptions returns the rsync options writing the transferred files to the destination
+// volume rather than to the ephemeral storage of the rsync pod, and the temp directory that must
+// exist before rsync runs, if any
+func tempFileRsyncOptions(mode drv1alpha1.RsyncTempFiles, destInfo string) ([]string, string) {
+	if mode != drv1alpha1.RsyncTempFilesTempDir {
+		return []string{"--inplace"}, ""
+	}
+	tempDir := strings.TrimSuffix(destInfo, "/") + "/" + rsyncTempDirName
+	return []string{
+		shellQuote("--temp-dir=" + tempDir),
+		shellQuote("--exclude=/" + rsyncTempDirName + "/"),
+	}, tempDir
+}
+
+// isNoSpaceError returns true if rsync failed because a device ran out of space (ENOSPC)
+func isNoSpaceError(err error) bool {
+	return err != nil && strings.Contains(strings.ToLower(err.Error()), "no space left on device")
+}
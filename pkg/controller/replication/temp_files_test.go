@@ -0,0 +1,33 @@
+package replication
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestHasTempFileOption(t *testing.T) {
+	assert.False(t, hasTempFileOption(nil))
+	assert.False(t, hasTempFileOption([]string{"--checksum", "--partial"}))
+	assert.True(t, hasTempFileOption([]string{"--inplace"}))
+	assert.True(t, hasTempFileOption([]string{"--temp-dir=/scratch"}))
+	assert.True(t, hasTempFileOption([]string{"-T/scratch"}))
+}
+
+func TestTempFileRsyncOptions(t *testing.T) {
+	options, tempDir := tempFileRsyncOptions("", "/data/")
+	assert.Equal(t, []string{"--inplace"}, options)
+	assert.Empty(t, tempDir)
+
+	options, tempDir = tempFileRsyncOptions(drv1alpha1.RsyncTempFilesTempDir, "/data/")
+	assert.Equal(t, []string{"'--temp-dir=/data/.dr-syncer-tmp'", "'--exclude=/.dr-syncer-tmp/'"}, options)
+	assert.Equal(t, "/data/.dr-syncer-tmp", tempDir)
+}
+
+func TestIsNoSpaceError(t *testing.T) {
+	assert.False(t, isNoSpaceError(nil))
+	assert.False(t, isNoSpaceError(errors.New("connection reset by peer")))
+	assert.True(t, isNoSpaceError(errors.New(`failed to execute command: exit code 11, stderr: rsync: [receiver] write failed on "/data/db.sqlite": No space left on device (28)`)))
+}
@@ -0,0 +1,201 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+)
+
+// ErrPendingBinding is wrapped by the error of a data sync whose destination PVC uses a WaitForFirstConsumer
+// StorageClass and could not be bound on a node the rsync DaemonSet runs on
+var ErrPendingBinding = errors.New("pending binding")
+
+// volumeBinding describes where the volume of a destination PVC can be written from
+type volumeBinding struct {
+	// waitForConsumer is set for an unbound PVC whose StorageClass binds it when a pod uses it
+	waitForConsumer bool
+	// terms are the node selector terms of the volume, ORed. No terms allow every node.
+	terms []corev1.NodeSelectorTerm
+}
+
+// constrained reports whether the volume cannot be written from any node
+func (b volumeBinding) constrained() bool {
+	return b.waitForConsumer || len(b.terms) > 0
+}
+
+// destinationBinding returns where the volume of a destination PVC can be written from: the node affinity
+// of the volume of a bound PVC, or the allowed topologies of the WaitForFirstConsumer StorageClass of an
+// unbound PVC
+func (p *PVCSyncer) destinationBinding(ctx context.Context, namespace, pvcName string) (volumeBinding, error) {
+	pvc, err := p.DestinationK8sClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, pvcName, metav1.GetOptions{})
+	if err != nil {
+		return volumeBinding{}, fmt.Errorf("failed to get destination PVC %s/%s: %w", namespace, pvcName, err)
+	}
+
+	if pvc.Spec.VolumeName != "" {
+		pv, err := p.DestinationK8sClient.CoreV1().PersistentVolumes().Get(ctx, pvc.Spec.VolumeName, metav1.GetOptions{})
+		if err != nil {
+			return volumeBinding{}, fmt.Errorf("failed to get persistent volume %s: %w", pvc.Spec.VolumeName, err)
+		}
+		if pv.Spec.NodeAffinity == nil || pv.Spec.NodeAffinity.Required == nil {
+			return volumeBinding{}, nil
+		}
+		return volumeBinding{terms: pv.Spec.NodeAffinity.Required.NodeSelectorTerms}, nil
+	}
+
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return volumeBinding{}, nil
+	}
+	sc, err := p.DestinationK8sClient.StorageV1().StorageClasses().Get(ctx, *pvc.Spec.StorageClassName, metav1.GetOptions{})
+	if err != nil {
+		return volumeBinding{}, fmt.Errorf("failed to get storage class %s: %w", *pvc.Spec.StorageClassName, err)
+	}
+	if sc.VolumeBindingMode == nil || *sc.VolumeBindingMode != storagev1.VolumeBindingWaitForFirstConsumer {
+		return volumeBinding{}, nil
+	}
+	return volumeBinding{waitForConsumer: true, terms: allowedTopologyTerms(sc.AllowedTopologies)}, nil
+}
+
+// allowedTopologyTerms converts the allowed topologies of a StorageClass to node selector terms
+func allowedTopologyTerms(topologies []corev1.TopologySelectorTerm) []corev1.NodeSelectorTerm {
+	var terms []corev1.NodeSelectorTerm
+	for _, topology := range topologies {
+		var term corev1.NodeSelectorTerm
+		for _, expression := range topology.MatchLabelExpressions {
+			term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+				Key:      expression.Key,
+				Operator: corev1.NodeSelectorOpIn,
+				Values:   expression.Values,
+			})
+		}
+		terms = append(terms, term)
+	}
+	return terms
+}
+
+// nodeSelectorOperators maps node selector operators to label selector operators
+var nodeSelectorOperators = map[corev1.NodeSelectorOperator]selection.Operator{
+	corev1.NodeSelectorOpIn:           selection.In,
+	corev1.NodeSelectorOpNotIn:        selection.NotIn,
+	corev1.NodeSelectorOpExists:       selection.Exists,
+	corev1.NodeSelectorOpDoesNotExist: selection.DoesNotExist,
+	corev1.NodeSelectorOpGt:           selection.GreaterThan,
+	corev1.NodeSelectorOpLt:           selection.LessThan,
+}
+
+// matchesNode reports whether a node satisfies node selector terms. The terms are ORed, no terms match
+// every node and a term without requirements matches none.
+func matchesNode(terms []corev1.NodeSelectorTerm, node *corev1.Node) (bool, error) {
+	if len(terms) == 0 {
+		return true, nil
+	}
+	fields := labels.Set{metav1.ObjectNameField: node.Name}
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			continue
+		}
+		matchesLabels, err := matchesRequirements(term.MatchExpressions, labels.Set(node.Labels))
+		if err != nil {
+			return false, err
+		}
+		matchesFields, err := matchesRequirements(term.MatchFields, fields)
+		if err != nil {
+			return false, err
+		}
+		if matchesLabels && matchesFields {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// matchesRequirements reports whether a set of labels satisfies every node selector requirement
+func matchesRequirements(requirements []corev1.NodeSelectorRequirement, set labels.Set) (bool, error) {
+	for _, requirement := range requirements {
+		operator, ok := nodeSelectorOperators[requirement.Operator]
+		if !ok {
+			return false, fmt.Errorf("unsupported node selector operator %q", requirement.Operator)
+		}
+		labelRequirement, err := labels.NewRequirement(requirement.Key, operator, requirement.Values)
+		if err != nil {
+			return false, fmt.Errorf("invalid node selector requirement on %s: %w", requirement.Key, err)
+		}
+		if !labelRequirement.Matches(set) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// selectDestinationNode picks the node of a running rsync DaemonSet pod to write an unmounted destination
+// PVC from. Volumes with node affinity are written from a node they are reachable from, and
+// WaitForFirstConsumer PVCs are bound on a schedulable node their StorageClass allows.
+func (p *PVCSyncer) selectDestinationNode(ctx context.Context, dsPods []corev1.Pod, binding volumeBinding) (string, error) {
+	var nodeNames []string
+	for _, pod := range dsPods {
+		if pod.Status.Phase == corev1.PodRunning && pod.Spec.NodeName != "" {
+			nodeNames = append(nodeNames, pod.Spec.NodeName)
+		}
+	}
+	if len(nodeNames) == 0 {
+		return "", fmt.Errorf("no running rsync DaemonSet pods found")
+	}
+	sort.Strings(nodeNames)
+	if !binding.constrained() {
+		return nodeNames[0], nil
+	}
+
+	var rejected []string
+	for _, nodeName := range nodeNames {
+		node, err := p.DestinationK8sClient.CoreV1().Nodes().Get(ctx, nodeName, metav1.GetOptions{})
+		if err != nil {
+			rejected = append(rejected, fmt.Sprintf("%s (%v)", nodeName, err))
+			continue
+		}
+		if binding.waitForConsumer && node.Spec.Unschedulable {
+			rejected = append(rejected, nodeName+" (unschedulable)")
+			continue
+		}
+		matches, err := matchesNode(binding.terms, node)
+		if err != nil {
+			return "", err
+		}
+		if !matches {
+			rejected = append(rejected, nodeName+" (outside the volume topology)")
+			continue
+		}
+		return nodeName, nil
+	}
+
+	if binding.waitForConsumer {
+		return "", fmt.Errorf("%w: no rsync DaemonSet node is allowed by the storage class topology: %s",
+			ErrPendingBinding, strings.Join(rejected, ", "))
+	}
+	return "", fmt.Errorf("no rsync DaemonSet node can reach the volume: %s", strings.Join(rejected, ", "))
+}
+
+// bindDestinationVolume binds an unbound WaitForFirstConsumer destination PVC on the node it is written
+// from, before its destination path is resolved
+func (p *PVCSyncer) bindDestinationVolume(ctx context.Context, nodeName, namespace, pvcName string) error {
+	log.WithFields(logrus.Fields{
+		"namespace": namespace,
+		"pvc_name":  pvcName,
+		"node":      nodeName,
+	}).Info(logging.LogTagDetail + " Destination PVC uses a WaitForFirstConsumer storage class, binding it on the target node")
+
+	if err := p.RsyncDaemonSet.BindDestinationVolume(ctx, nodeName, namespace, pvcName); err != nil {
+		return fmt.Errorf("%w: %v", ErrPendingBinding, err)
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package replication
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func bindingTestNode(name, zone string, unschedulable bool) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"topology.kubernetes.io/zone": zone}},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+	}
+}
+
+func bindingTestPods(nodes ...string) []corev1.Pod {
+	var pods []corev1.Pod
+	for _, node := range nodes {
+		pods = append(pods, corev1.Pod{
+			Spec:   corev1.PodSpec{NodeName: node},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		})
+	}
+	return pods
+}
+
+func TestMatchesNode(t *testing.T) {
+	node := bindingTestNode("node-1", "eu-west-1a", false)
+	node.Labels["disks"] = "4"
+
+	for _, tc := range []struct {
+		name  string
+		terms []corev1.NodeSelectorTerm
+		match bool
+	}{
+		{name: "no terms", match: true},
+		{name: "empty term", terms: []corev1.NodeSelectorTerm{{}}},
+		{name: "zone", match: true, terms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"eu-west-1a", "eu-west-1b"}},
+		}}}},
+		{name: "other zone", terms: []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+			{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"eu-west-1b"}},
+		}}}},
+		{name: "terms are ORed", match: true, terms: []corev1.NodeSelectorTerm{
+			{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gpu", Operator: corev1.NodeSelectorOpExists}}},
+			{MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "disks", Operator: corev1.NodeSelectorOpGt, Values: []string{"2"}}}},
+		}},
+		{name: "node name", match: true, terms: []corev1.NodeSelectorTerm{{MatchFields: []corev1.NodeSelectorRequirement{
+			{Key: metav1.ObjectNameField, Operator: corev1.NodeSelectorOpIn, Values: []string{"node-1"}},
+		}}}},
+		{name: "requirements are ANDed", terms: []corev1.NodeSelectorTerm{{
+			MatchExpressions: []corev1.NodeSelectorRequirement{{Key: "gpu", Operator: corev1.NodeSelectorOpDoesNotExist}},
+			MatchFields: []corev1.NodeSelectorRequirement{
+				{Key: metav1.ObjectNameField, Operator: corev1.NodeSelectorOpNotIn, Values: []string{"node-1"}},
+			},
+		}}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			match, err := matchesNode(tc.terms, node)
+			require.NoError(t, err)
+			assert.Equal(t, tc.match, match)
+		})
+	}
+
+	_, err := matchesNode([]corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: "disks", Operator: "Near", Values: []string{"2"}},
+	}}}, node)
+	assert.Error(t, err)
+}
+
+func TestDestinationBinding(t *testing.T) {
+	ctx := context.Background()
+	waitForConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+	immediate := storagev1.VolumeBindingImmediate
+	local, standard := "local", "standard"
+
+	client := fake.NewSimpleClientset(
+		&storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: "local"},
+			VolumeBindingMode: &waitForConsumer,
+			AllowedTopologies: []corev1.TopologySelectorTerm{{MatchLabelExpressions: []corev1.TopologySelectorLabelRequirement{
+				{Key: "topology.kubernetes.io/zone", Values: []string{"eu-west-1b"}},
+			}}},
+		},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}, VolumeBindingMode: &immediate},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "pending", Namespace: "shop-dr"},
+			Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &local}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "immediate", Namespace: "shop-dr"},
+			Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &standard}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "bound", Namespace: "shop-dr"},
+			Spec: corev1.PersistentVolumeClaimSpec{StorageClassName: &local, VolumeName: "pv-bound"}},
+		&corev1.PersistentVolume{ObjectMeta: metav1.ObjectMeta{Name: "pv-bound"}, Spec: corev1.PersistentVolumeSpec{
+			NodeAffinity: &corev1.VolumeNodeAffinity{Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{
+					{Key: "kubernetes.io/hostname", Operator: corev1.NodeSelectorOpIn, Values: []string{"node-3"}},
+				},
+			}}}},
+		}},
+	)
+	p := &PVCSyncer{DestinationK8sClient: client}
+
+	binding, err := p.destinationBinding(ctx, "shop-dr", "pending")
+	require.NoError(t, err)
+	assert.True(t, binding.waitForConsumer)
+	require.Len(t, binding.terms, 1)
+	assert.Equal(t, corev1.NodeSelectorOpIn, binding.terms[0].MatchExpressions[0].Operator)
+	assert.Equal(t, []string{"eu-west-1b"}, binding.terms[0].MatchExpressions[0].Values)
+
+	binding, err = p.destinationBinding(ctx, "shop-dr", "immediate")
+	require.NoError(t, err)
+	assert.False(t, binding.constrained())
+
+	// A bound volume is written from a node its affinity allows
+	binding, err = p.destinationBinding(ctx, "shop-dr", "bound")
+	require.NoError(t, err)
+	assert.False(t, binding.waitForConsumer)
+	assert.Equal(t, "kubernetes.io/hostname", binding.terms[0].MatchExpressions[0].Key)
+
+	_, err = p.destinationBinding(ctx, "shop-dr", "missing")
+	assert.Error(t, err)
+}
+
+func TestSelectDestinationNode(t *testing.T) {
+	ctx := context.Background()
+	p := &PVCSyncer{DestinationK8sClient: fake.NewSimpleClientset(
+		bindingTestNode("node-a", "eu-west-1a", false),
+		bindingTestNode("node-b", "eu-west-1b", true),
+		bindingTestNode("node-c", "eu-west-1b", false),
+	)}
+	pods := bindingTestPods("node-c", "node-b", "node-a")
+	zoneB := []corev1.NodeSelectorTerm{{MatchExpressions: []corev1.NodeSelectorRequirement{
+		{Key: "topology.kubernetes.io/zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"eu-west-1b"}},
+	}}}
+
+	node, err := p.selectDestinationNode(ctx, pods, volumeBinding{})
+	require.NoError(t, err)
+	assert.Equal(t, "node-a", node)
+
+	// Unschedulable nodes can still reach a bound volume, but cannot bind a new one
+	node, err = p.selectDestinationNode(ctx, pods, volumeBinding{terms: zoneB})
+	require.NoError(t, err)
+	assert.Equal(t, "node-b", node)
+	node, err = p.selectDestinationNode(ctx, pods, volumeBinding{waitForConsumer: true, terms: zoneB})
+	require.NoError(t, err)
+	assert.Equal(t, "node-c", node)
+
+	_, err = p.selectDestinationNode(ctx, bindingTestPods("node-a", "node-b"), volumeBinding{waitForConsumer: true, terms: zoneB})
+	assert.True(t, errors.Is(err, ErrPendingBinding))
+	assert.Contains(t, err.Error(), "node-b (unschedulable)")
+
+	_, err = p.selectDestinationNode(ctx, bindingTestPods("node-a"), volumeBinding{terms: zoneB})
+	require.Error(t, err)
+	assert.False(t, errors.Is(err, ErrPendingBinding))
+
+	_, err = p.selectDestinationNode(ctx, nil, volumeBinding{})
+	assert.Error(t, err)
+}
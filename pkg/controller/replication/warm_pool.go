@@ -0,0 +1,47 @@
+package replication
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// KeepWarmFor reports whether a NamespaceMapping keeps its destination PVCs attached to warm pool pods
+func KeepWarmFor(mapping *drv1alpha1.NamespaceMapping) bool {
+	return mapping != nil && mapping.Spec.PVCConfig != nil && mapping.Spec.PVCConfig.KeepWarm
+}
+
+// keepWarm reports whether the NamespaceMapping being synced keeps destination PVCs warm
+func (p *PVCSyncer) keepWarm(ctx context.Context) bool {
+	if p.SourceClient == nil {
+		return false
+	}
+
+	var nm drv1alpha1.NamespaceMapping
+	nmKey := client.ObjectKey{Name: fmt.Sprintf("%s-%s", p.SourceNamespace, p.DestinationNamespace)}
+	if err := p.SourceClient.Get(ctx, nmKey, &nm); err != nil {
+		return false
+	}
+	return KeepWarmFor(&nm)
+}
+
+// releaseWarmPoolAfter returns a cleanup function that runs cleanup and then deletes the warm pool
+// pod left attached to a destination PVC by an earlier sync, once keepWarm was disabled
+func (p *PVCSyncer) releaseWarmPoolAfter(cleanup func(), destNamespace, destPVCName string) func() {
+	return func() {
+		if cleanup != nil {
+			cleanup()
+		}
+		if err := p.RsyncDaemonSet.ReleaseWarmPool(context.Background(), destNamespace, destPVCName); err != nil {
+			log.WithFields(logrus.Fields{
+				"namespace": destNamespace,
+				"pvc_name":  destPVCName,
+				"error":     err,
+			}).Warn(logging.LogTagWarn + " Failed to release warm pool pod")
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package replication
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestKeepWarmFor(t *testing.T) {
+	assert.False(t, KeepWarmFor(nil))
+	assert.False(t, KeepWarmFor(&drv1alpha1.NamespaceMapping{}))
+	assert.False(t, KeepWarmFor(&drv1alpha1.NamespaceMapping{Spec: drv1alpha1.NamespaceMappingSpec{
+		PVCConfig: &drv1alpha1.PVCConfig{SyncData: true},
+	}}))
+	assert.True(t, KeepWarmFor(&drv1alpha1.NamespaceMapping{Spec: drv1alpha1.NamespaceMappingSpec{
+		PVCConfig: &drv1alpha1.PVCConfig{SyncData: true, KeepWarm: true},
+	}}))
+}
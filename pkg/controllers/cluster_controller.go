@@ -24,6 +24,7 @@ import (
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	"github.com/supporttools/dr-syncer/pkg/controller/remotecluster"
 	"github.com/supporttools/dr-syncer/pkg/controller/replication"
+	"github.com/supporttools/dr-syncer/pkg/util"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -43,6 +44,7 @@ type RemoteClusterReconciler struct {
 // +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups="",resources=namespaces;serviceaccounts,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=clusterroles;clusterrolebindings,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
 func (r *RemoteClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	// Fetch the RemoteCluster instance
 	var cluster drv1alpha1.RemoteCluster
@@ -123,7 +125,7 @@ func (r *RemoteClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 
 	// Load and parse the kubeconfig
-	kubeconfig, err := clientcmd.Load(kubeconfigData)
+	kubeconfig, err := util.LoadKubeconfigContext(kubeconfigData, cluster.Spec.KubeconfigSecretRef.Context)
 	if err != nil {
 		log.Errorf("[Reconcile][Load] unable to load kubeconfig for cluster %s: %v", cluster.Name, err)
 		setRemoteClusterCondition(&cluster, "KubeconfigValid", metav1.ConditionFalse, "InvalidKubeconfig", err.Error())
@@ -135,6 +137,7 @@ func (r *RemoteClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		ClusterInfo: clientcmdapi.Cluster{
 			InsecureSkipTLSVerify: configCli.CFG.IgnoreCert,
 		},
+		CurrentContext: cluster.Spec.KubeconfigSecretRef.Context,
 	}
 
 	clientConfig := clientcmd.NewDefaultClientConfig(*kubeconfig, configOverrides)
@@ -171,6 +174,17 @@ func (r *RemoteClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		}
 	}
 
+	// Route API and exec traffic through the configured proxy or jump host
+	if err := util.ApplyProxyConfig(ctx, r.Client, config, cluster.Spec.Proxy); err != nil {
+		log.Errorf("[Reconcile][Proxy] unable to configure proxy for cluster %s: %v", cluster.Name, err)
+		setRemoteClusterCondition(&cluster, "ClusterAvailable", metav1.ConditionFalse, "ProxyConfigFailed", err.Error())
+		_ = r.Status().Update(ctx, &cluster)
+		return ctrl.Result{RequeueAfter: time.Minute}, err
+	}
+
+	// Count the connection test in the circuit of the cluster, its success closes an open circuit
+	util.RemoteClusters.Instrument(remoteClusterKey(&cluster), config)
+
 	// Create a Kubernetes client for the remote cluster
 	remoteClient, err := kubernetes.NewForConfig(config)
 	if err != nil {
@@ -182,6 +196,20 @@ func (r *RemoteClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 
 	// Test connection to the remote cluster
 	_, err = remoteClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if open, ok := util.IsCircuitOpen(err); ok {
+		// The cluster failed repeatedly, it is probed again once the circuit cooldown passed
+		log.Infof("[Reconcile][List] %v", open)
+		setRemoteClusterCondition(&cluster, "ClusterAvailable", metav1.ConditionFalse, "CircuitOpen", open.Error())
+		_ = r.Status().Update(ctx, &cluster)
+		return ctrl.Result{RequeueAfter: open.RetryAfter}, nil
+	}
+	if util.IsCertificateError(err) {
+		log.Errorf("[Reconcile][List] unable to verify the certificate of cluster %s, update the CA in its kubeconfig secret: %v", cluster.Name, err)
+		setRemoteClusterCondition(&cluster, "ClusterAvailable", metav1.ConditionFalse, "CertificateInvalid",
+			fmt.Sprintf("API server certificate could not be verified, the kubeconfig secret may hold an outdated CA: %v", err))
+		_ = r.Status().Update(ctx, &cluster)
+		return ctrl.Result{RequeueAfter: time.Minute}, err
+	}
 	if err != nil {
 		log.Errorf("[Reconcile][List] unable to connect to cluster %s - certificate or network issue likely: %v", cluster.Name, err)
 		setRemoteClusterCondition(&cluster, "ClusterAvailable", metav1.ConditionFalse, "ConnectionFailed", err.Error())
@@ -228,8 +256,9 @@ func (r *RemoteClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 		log.Errorf("[Reconcile][PVCSync] failed to reconcile PVC sync for cluster %s: %v", cluster.Name, err)
 		setRemoteClusterCondition(&latest, "PVCSyncReady", metav1.ConditionFalse, "ReconciliationFailed", err.Error())
 	} else {
-		// Initialize global PVC sync concurrency manager with cluster settings
-		if latest.Spec.PVCSync != nil {
+		// Initialize global PVC sync concurrency manager with cluster settings, unless the controller
+		// configures the global limit itself
+		if latest.Spec.PVCSync != nil && configCli.CFG.MaxConcurrentDataSyncs <= 0 {
 			limit := latest.Spec.PVCSync.GetGlobalConcurrencyLimit()
 			replication.InitGlobalConcurrencyManager(int64(limit))
 		}
@@ -244,6 +273,10 @@ func (r *RemoteClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 			} else if readyNodes < totalNodes {
 				setRemoteClusterCondition(&latest, "PVCSyncReady", metav1.ConditionFalse, "PartiallyReady",
 					fmt.Sprintf("%d/%d agent nodes are ready", readyNodes, totalNodes))
+			} else if readyNodes > 0 && !latest.Status.PVCSync.AgentStatus.RolloutComplete {
+				setRemoteClusterCondition(&latest, "PVCSyncReady", metav1.ConditionFalse, "RollingOut",
+					fmt.Sprintf("%d/%d agent nodes run %s", latest.Status.PVCSync.AgentStatus.UpdatedNodes, totalNodes,
+						latest.Status.PVCSync.AgentStatus.Image))
 			} else if readyNodes > 0 {
 				setRemoteClusterCondition(&latest, "PVCSyncReady", metav1.ConditionTrue, "ReconciliationSuccessful",
 					"PVC sync agent deployed successfully")
@@ -270,6 +303,12 @@ func (r *RemoteClusterReconciler) Reconcile(ctx context.Context, req ctrl.Reques
 	}
 	cluster.Status = latest.Status
 
+	// Follow an agent rollout until it completes
+	if pvcSync := latest.Status.PVCSync; pvcSync != nil && pvcSync.AgentStatus != nil &&
+		!pvcSync.AgentStatus.RolloutComplete && pvcSync.AgentStatus.TotalNodes > 0 {
+		return ctrl.Result{RequeueAfter: time.Minute}, nil
+	}
+
 	// Requeue after the default sync period to validate connection and schedule again
 	return ctrl.Result{RequeueAfter: remotecluster.DefaultSyncPeriod}, nil
 }
@@ -0,0 +1,164 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeClusterMappingAccepted reports whether the referenced ClusterMapping may be used
+	ConditionTypeClusterMappingAccepted = "ClusterMappingAccepted"
+
+	// ReasonClusterMappingNotAllowed is set when a cross-namespace ClusterMapping does not allow the NamespaceMapping's namespace
+	ReasonClusterMappingNotAllowed = "NotAllowed"
+
+	// clusterMappingDeniedRequeue is how often a denied NamespaceMapping checks whether access was granted
+	clusterMappingDeniedRequeue = 5 * time.Minute
+)
+
+// errClusterMappingNotAllowed is returned when a ClusterMapping in another namespace has not
+// consented to be referenced from the NamespaceMapping's namespace
+var errClusterMappingNotAllowed = errors.New("cluster mapping does not allow references from this namespace")
+
+// clusterMappingAllowsNamespace reports whether NamespaceMappings in namespace may reference the ClusterMapping
+func clusterMappingAllowsNamespace(clusterMapping *drv1alpha1.ClusterMapping, namespace string) bool {
+	if clusterMapping.Namespace == namespace {
+		return true
+	}
+
+	allowed, ok := clusterMapping.Annotations[drv1alpha1.AllowedNamespacesAnnotation]
+	if !ok {
+		return false
+	}
+	for _, entry := range strings.Split(allowed, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "*" || entry == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// getClusterMapping fetches the ClusterMapping referenced by a NamespaceMapping and checks that it
+// may be used from the NamespaceMapping's namespace
+func getClusterMapping(ctx context.Context, c client.Reader, namespacemapping *drv1alpha1.NamespaceMapping) (*drv1alpha1.ClusterMapping, error) {
+	clusterMappingNamespace := namespacemapping.Spec.ClusterMappingRef.Namespace
+	if clusterMappingNamespace == "" {
+		clusterMappingNamespace = namespacemapping.Namespace
+	}
+
+	var clusterMapping drv1alpha1.ClusterMapping
+	if err := c.Get(ctx, client.ObjectKey{
+		Name:      namespacemapping.Spec.ClusterMappingRef.Name,
+		Namespace: clusterMappingNamespace,
+	}, &clusterMapping); err != nil {
+		return nil, err
+	}
+
+	if !clusterMappingAllowsNamespace(&clusterMapping, namespacemapping.Namespace) {
+		return nil, fmt.Errorf("%w: ClusterMapping %s/%s must list namespace %s in its %s annotation",
+			errClusterMappingNotAllowed, clusterMapping.Namespace, clusterMapping.Name,
+			namespacemapping.Namespace, drv1alpha1.AllowedNamespacesAnnotation)
+	}
+
+	return &clusterMapping, nil
+}
+
+// sourceRemoteCluster returns the source RemoteCluster of a NamespaceMapping, named by its ClusterMapping
+// or directly in its spec
+func sourceRemoteCluster(ctx context.Context, c client.Reader, nm *drv1alpha1.NamespaceMapping) (*drv1alpha1.RemoteCluster, error) {
+	clusterName := nm.Spec.SourceCluster
+	clusterNamespace := nm.Namespace
+	if nm.Spec.ClusterMappingRef != nil {
+		clusterMapping, err := getClusterMapping(ctx, c, nm)
+		if err != nil {
+			return nil, err
+		}
+		clusterName = clusterMapping.Spec.SourceCluster
+		clusterNamespace = clusterMapping.Namespace
+	}
+	if clusterName == "" {
+		return nil, fmt.Errorf("no source cluster specified")
+	}
+
+	var cluster drv1alpha1.RemoteCluster
+	if err := c.Get(ctx, client.ObjectKey{Namespace: clusterNamespace, Name: clusterName}, &cluster); err != nil {
+		return nil, fmt.Errorf("failed to get source cluster %s: %w", clusterName, err)
+	}
+	return &cluster, nil
+}
+
+// destinationRemoteCluster returns the destination RemoteCluster of a NamespaceMapping, named by its
+// ClusterMapping or directly in its spec
+func destinationRemoteCluster(ctx context.Context, c client.Reader, nm *drv1alpha1.NamespaceMapping) (*drv1alpha1.RemoteCluster, error) {
+	clusterName := nm.Spec.DestinationCluster
+	clusterNamespace := nm.Namespace
+	if nm.Spec.ClusterMappingRef != nil {
+		clusterMapping, err := getClusterMapping(ctx, c, nm)
+		if err != nil {
+			return nil, err
+		}
+		clusterName = clusterMapping.Spec.TargetCluster
+		clusterNamespace = clusterMapping.Namespace
+	}
+	if clusterName == "" {
+		return nil, fmt.Errorf("no destination cluster specified")
+	}
+
+	var cluster drv1alpha1.RemoteCluster
+	if err := c.Get(ctx, client.ObjectKey{Namespace: clusterNamespace, Name: clusterName}, &cluster); err != nil {
+		return nil, fmt.Errorf("failed to get destination cluster %s: %w", clusterName, err)
+	}
+	return &cluster, nil
+}
+
+// setClusterMappingAccepted records in the NamespaceMapping status whether the referenced ClusterMapping
+// may be used. The condition is only written once access has been denied, and flips back to True
+// when access is granted.
+func (r *NamespaceMappingReconciler) setClusterMappingAccepted(ctx context.Context, namespacemapping *drv1alpha1.NamespaceMapping, accessErr error) error {
+	existing := meta.FindStatusCondition(namespacemapping.Status.Conditions, ConditionTypeClusterMappingAccepted)
+	if accessErr == nil && existing == nil {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeClusterMappingAccepted,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Accepted",
+		Message:            "ClusterMapping may be referenced from this namespace",
+		ObservedGeneration: namespacemapping.Generation,
+	}
+	if accessErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonClusterMappingNotAllowed
+		condition.Message = accessErr.Error()
+	}
+
+	if existing != nil && existing.Status == condition.Status && existing.Message == condition.Message {
+		return nil
+	}
+
+	meta.SetStatusCondition(&namespacemapping.Status.Conditions, condition)
+	if accessErr != nil {
+		namespacemapping.Status.Phase = drv1alpha1.SyncPhaseFailed
+		namespacemapping.Status.LastError = &drv1alpha1.SyncError{
+			Message: accessErr.Error(),
+			Time:    metav1.Now(),
+		}
+	}
+
+	if err := r.Status().Update(ctx, namespacemapping); err != nil {
+		logging.LogError(nil, fmt.Sprintf("failed to update ClusterMapping access condition: %v", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drsyncerio "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestClusterMappingAllowsNamespace(t *testing.T) {
+	tests := []struct {
+		name      string
+		allowed   *string
+		namespace string
+		want      bool
+	}{
+		{name: "same namespace", namespace: "platform", want: true},
+		{name: "no annotation", namespace: "app", want: false},
+		{name: "listed", allowed: strPtr("team-a, app"), namespace: "app", want: true},
+		{name: "not listed", allowed: strPtr("team-a,team-b"), namespace: "app", want: false},
+		{name: "wildcard", allowed: strPtr("*"), namespace: "app", want: true},
+		{name: "empty annotation", allowed: strPtr(""), namespace: "app", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := testutil.NewClusterMapping("prod-to-dr").WithNamespace("platform")
+			if tt.allowed != nil {
+				builder = builder.WithAnnotation(drsyncerio.AllowedNamespacesAnnotation, *tt.allowed)
+			}
+			assert.Equal(t, tt.want, clusterMappingAllowsNamespace(builder.Build(), tt.namespace))
+		})
+	}
+}
+
+func TestGetClusterMapping_CrossNamespace(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	allowed := testutil.NewClusterMapping("prod-to-dr").
+		WithNamespace("platform").
+		WithClusters("prod", "dr").
+		WithAnnotation(drsyncerio.AllowedNamespacesAnnotation, "app").
+		Build()
+	r := &NamespaceMappingReconciler{Client: env.NewFakeClient(allowed), Scheme: env.Scheme}
+
+	nm := testutil.NewNamespaceMapping("app").WithNamespace("app").WithClusterMappingRef("prod-to-dr").Build()
+	nm.Spec.ClusterMappingRef.Namespace = "platform"
+
+	clusterMapping, err := getClusterMapping(env.Ctx, r.Client, nm)
+	require.NoError(t, err)
+	assert.Equal(t, "dr", clusterMapping.Spec.TargetCluster)
+}
+
+func TestGetClusterMapping_NotAllowed(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	clusterMapping := testutil.NewClusterMapping("prod-to-dr").
+		WithNamespace("platform").
+		WithClusters("prod", "dr").
+		Build()
+	nm := testutil.NewNamespaceMapping("app").WithNamespace("app").WithClusterMappingRef("prod-to-dr").Build()
+	nm.Spec.ClusterMappingRef.Namespace = "platform"
+
+	c := env.NewFakeClient(clusterMapping, nm)
+	r := &NamespaceMappingReconciler{Client: c, Scheme: env.Scheme}
+
+	_, err := r.setupModeHandlerForNamespaceMapping(env.Ctx, nm)
+	assert.True(t, errors.Is(err, errClusterMappingNotAllowed))
+
+	var updated drsyncerio.NamespaceMapping
+	require.NoError(t, c.Get(env.Ctx, client.ObjectKeyFromObject(nm), &updated))
+	condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeClusterMappingAccepted)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonClusterMappingNotAllowed, condition.Reason)
+	assert.Equal(t, drsyncerio.SyncPhaseFailed, updated.Status.Phase)
+}
+
+func strPtr(s string) *string {
+	return &s
+}
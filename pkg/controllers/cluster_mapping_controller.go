@@ -16,7 +16,6 @@ import (
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
-	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/remotecommand"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/builder"
@@ -27,11 +26,20 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
 	drsyncerio "github.com/supporttools/dr-syncer/api/v1alpha1"
+	agentssh "github.com/supporttools/dr-syncer/pkg/agent/ssh"
 	"github.com/supporttools/dr-syncer/pkg/util"
 )
 
 // log is defined in logger.go
 
+const (
+	// agentNamespace is the namespace the agents run in on the remote clusters
+	agentNamespace = "dr-syncer"
+
+	// defaultKeyExpiryGracePeriod is how long a rotated key of the target stays accepted by default
+	defaultKeyExpiryGracePeriod = time.Hour
+)
+
 // ClusterMappingReconciler reconciles a ClusterMapping object
 type ClusterMappingReconciler struct {
 	client.Client
@@ -49,6 +57,7 @@ type ClusterMappingReconciler struct {
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch
 // +kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch
 // +kubebuilder:rbac:groups="",resources=pods/exec,verbs=create
+// +kubebuilder:rbac:groups="",resources=pods/proxy,verbs=get
 
 // Reconcile handles the reconciliation of ClusterMapping resources
 func (r *ClusterMappingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
@@ -197,14 +206,19 @@ func (r *ClusterMappingReconciler) handleConnectingPhase(ctx context.Context, cl
 	}
 
 	// Get source and target cluster clients
-	sourceClient, sourceConfig, targetClient, targetConfig, err := r.getClusterClients(ctx, sourceCluster, targetCluster)
+	sourceClient, _, targetClient, targetConfig, err := r.getClusterClients(ctx, sourceCluster, targetCluster)
+	if open, ok := util.IsCircuitOpen(err); ok {
+		// Connecting is retried once the unavailable cluster may be back, without failing the mapping
+		log.Infof("Postponing connection: %v", open)
+		return ctrl.Result{RequeueAfter: open.RetryAfter}, nil
+	}
 	if err != nil {
 		log.Errorf("Failed to get cluster clients: %v", err)
 		return r.setFailedStatus(ctx, clusterMapping, fmt.Sprintf("Failed to get cluster clients: %v", err))
 	}
 
 	// Distribute SSH keys from target to source
-	err = r.distributeSSHKeys(ctx, clusterMapping, sourceClient, sourceConfig, targetClient, targetConfig)
+	err = r.distributeSSHKeys(ctx, clusterMapping, targetCluster, sourceClient, targetClient)
 	if err != nil {
 		log.Errorf("Failed to distribute SSH keys: %v", err)
 		return r.setFailedStatus(ctx, clusterMapping, fmt.Sprintf("Failed to distribute SSH keys: %v", err))
@@ -251,6 +265,7 @@ func (r *ClusterMappingReconciler) handleConnectingPhase(ctx context.Context, cl
 			cm.Status.ConnectionStatus = connectionStatus
 		}
 		cm.Status.LastVerified = &metav1.Time{Time: time.Now()}
+		cm.Status.LastKeyDistribution = &metav1.Time{Time: time.Now()}
 		cm.Status.Phase = phase
 		cm.Status.Message = message
 		return nil
@@ -268,6 +283,12 @@ func (r *ClusterMappingReconciler) handleConnectingPhase(ctx context.Context, cl
 func (r *ClusterMappingReconciler) handleConnectedPhase(ctx context.Context, clusterMapping *drsyncerio.ClusterMapping) (ctrl.Result, error) {
 	log.Info("Handling Connected phase")
 
+	// Declare the new key of the target to the source agents after it was rotated
+	if err := r.redistributeRotatedKeys(ctx, clusterMapping); err != nil {
+		log.Errorf("Failed to redistribute rotated SSH keys: %v", err)
+		return r.setFailedStatus(ctx, clusterMapping, fmt.Sprintf("Failed to redistribute rotated SSH keys: %v", err))
+	}
+
 	// Periodically verify connectivity
 	if clusterMapping.Spec.VerifyConnectivity == nil || *clusterMapping.Spec.VerifyConnectivity {
 		// Check if it's time to verify connectivity again (every hour)
@@ -425,58 +446,99 @@ func (r *ClusterMappingReconciler) getClusterClients(ctx context.Context, source
 
 // getClusterClient gets a Kubernetes client and REST config for the given cluster
 func (r *ClusterMappingReconciler) getClusterClient(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, *rest.Config, error) {
-	// Get kubeconfig secret
-	secret := &corev1.Secret{}
-	err := r.Get(ctx, types.NamespacedName{
-		Name:      cluster.Spec.KubeconfigSecretRef.Name,
-		Namespace: cluster.Spec.KubeconfigSecretRef.Namespace,
-	}, secret)
+	return remoteClusterClient(ctx, r.Client, cluster)
+}
+
+// distributeSSHKeys distributes SSH keys from target to source by declaring them as a key source in
+// the source cluster, the leader agent merges it into the authorized keys of every source agent
+func (r *ClusterMappingReconciler) distributeSSHKeys(ctx context.Context, clusterMapping *drsyncerio.ClusterMapping, targetCluster *drsyncerio.RemoteCluster, sourceClient, targetClient kubernetes.Interface) error {
+	log.Info("Distributing SSH keys")
+
+	var publicKey []byte
+	var err error
+	if clusterMapping.Spec.SSHKeySecretRef != nil {
+		publicKey, err = r.publicKeyFromSecret(ctx, clusterMapping)
+	} else {
+		// Fall back to the host key the target agents were deployed with
+		publicKey, err = r.publicKeyFromAgentKeys(ctx, targetCluster, targetClient)
+	}
 	if err != nil {
-		return nil, nil, fmt.Errorf("failed to get kubeconfig secret: %w", err)
+		return err
 	}
 
-	// Get kubeconfig key
-	key := "kubeconfig"
-	if cluster.Spec.KubeconfigSecretRef.Key != "" {
-		key = cluster.Spec.KubeconfigSecretRef.Key
-	}
+	owner := fmt.Sprintf("clustermapping-%s-%s", clusterMapping.Namespace, clusterMapping.Name)
+	entry := append(bytes.TrimSpace(publicKey), '\n')
 
-	// Get kubeconfig data
-	kubeconfigData, ok := secret.Data[key]
-	if !ok {
-		return nil, nil, fmt.Errorf("kubeconfig key %s not found in secret", key)
+	// Keep accepting a replaced key until the grace period elapsed, so syncs started under it can finish
+	previous, err := agentssh.AuthorizedKeySourceEntries(ctx, sourceClient, agentNamespace, owner)
+	if err != nil {
+		return err
+	}
+	if len(previous) > 0 && !bytes.Equal(previous, entry) {
+		grace := keyExpiryGracePeriod(clusterMapping)
+		if err := agentssh.ApplyAuthorizedKeySource(ctx, sourceClient, agentNamespace, owner+"-previous", previous, grace); err != nil {
+			return fmt.Errorf("failed to keep previous authorized key in source cluster: %w", err)
+		}
+		log.Info(fmt.Sprintf("Key of ClusterMapping %s/%s changed, the previous key expires in %v",
+			clusterMapping.Namespace, clusterMapping.Name, grace))
 	}
 
-	// Create rest config
-	config, err := clientcmd.RESTConfigFromKubeConfig(kubeconfigData)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create rest config: %w", err)
+	if err := agentssh.ApplyAuthorizedKeySource(ctx, sourceClient, agentNamespace, owner, entry, 0); err != nil {
+		return fmt.Errorf("failed to declare authorized key in source cluster: %w", err)
 	}
+	log.Info(fmt.Sprintf("Declared public key of ClusterMapping %s/%s for the source agents",
+		clusterMapping.Namespace, clusterMapping.Name))
 
-	// Create client
-	client, err := kubernetes.NewForConfig(config)
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to create client: %w", err)
+	return nil
+}
+
+// keyExpiryGracePeriod returns how long the source agents keep accepting a replaced key of the target
+func keyExpiryGracePeriod(clusterMapping *drsyncerio.ClusterMapping) time.Duration {
+	if clusterMapping.Spec.KeyExpiryGracePeriod != nil && clusterMapping.Spec.KeyExpiryGracePeriod.Duration > 0 {
+		return clusterMapping.Spec.KeyExpiryGracePeriod.Duration
 	}
+	return defaultKeyExpiryGracePeriod
+}
 
-	return client, config, nil
+// keysRotatedSinceDistribution reports whether the agent keys of the target were rotated after they
+// were last distributed by the ClusterMapping
+func keysRotatedSinceDistribution(clusterMapping *drsyncerio.ClusterMapping, targetCluster *drsyncerio.RemoteCluster) bool {
+	if clusterMapping.Spec.SSHKeySecretRef != nil || targetCluster.Status.PVCSync == nil || targetCluster.Status.PVCSync.LastKeyRotation == nil {
+		return false
+	}
+	lastDistribution := clusterMapping.Status.LastKeyDistribution
+	return lastDistribution == nil || lastDistribution.Before(targetCluster.Status.PVCSync.LastKeyRotation)
 }
 
-// distributeSSHKeys distributes SSH keys from target to source
-func (r *ClusterMappingReconciler) distributeSSHKeys(ctx context.Context, clusterMapping *drsyncerio.ClusterMapping, sourceClient kubernetes.Interface, sourceConfig *rest.Config, targetClient kubernetes.Interface, targetConfig *rest.Config) error {
-	log.Info("Distributing SSH keys")
+// redistributeRotatedKeys declares the new key of the target to the source agents once the target
+// rotated its agent keys
+func (r *ClusterMappingReconciler) redistributeRotatedKeys(ctx context.Context, clusterMapping *drsyncerio.ClusterMapping) error {
+	sourceCluster, targetCluster, err := r.getClusters(ctx, clusterMapping)
+	if err != nil {
+		return fmt.Errorf("failed to get clusters: %w", err)
+	}
+	if !keysRotatedSinceDistribution(clusterMapping, targetCluster) {
+		return nil
+	}
 
-	// Check if SSHKeySecretRef is provided
-	if clusterMapping.Spec.SSHKeySecretRef != nil {
-		return r.distributeSSHKeysFromSecret(ctx, clusterMapping, sourceClient, sourceConfig)
+	log.Info(fmt.Sprintf("Agent keys of target cluster %s were rotated, redistributing", targetCluster.Name))
+	sourceClient, _, targetClient, _, err := r.getClusterClients(ctx, sourceCluster, targetCluster)
+	if err != nil {
+		return fmt.Errorf("failed to get cluster clients: %w", err)
+	}
+	if err := r.distributeSSHKeys(ctx, clusterMapping, targetCluster, sourceClient, targetClient); err != nil {
+		return fmt.Errorf("failed to distribute SSH keys: %w", err)
 	}
 
-	// Fall back to extracting keys from agent pods
-	return r.distributeSSHKeysFromAgents(ctx, clusterMapping, sourceClient, sourceConfig, targetClient, targetConfig)
+	return r.updateStatusWithRetry(ctx, types.NamespacedName{Name: clusterMapping.Name, Namespace: clusterMapping.Namespace},
+		func(cm *drsyncerio.ClusterMapping) error {
+			cm.Status.LastKeyDistribution = &metav1.Time{Time: time.Now()}
+			return nil
+		})
 }
 
-// distributeSSHKeysFromSecret distributes SSH keys from a secret
-func (r *ClusterMappingReconciler) distributeSSHKeysFromSecret(ctx context.Context, clusterMapping *drsyncerio.ClusterMapping, sourceClient kubernetes.Interface, sourceConfig *rest.Config) error {
+// publicKeyFromSecret reads the public key of the ClusterMapping's SSH key secret
+func (r *ClusterMappingReconciler) publicKeyFromSecret(ctx context.Context, clusterMapping *drsyncerio.ClusterMapping) ([]byte, error) {
 	// Get the secret reference
 	secretRef := clusterMapping.Spec.SSHKeySecretRef
 
@@ -499,83 +561,37 @@ func (r *ClusterMappingReconciler) distributeSSHKeysFromSecret(ctx context.Conte
 		Namespace: namespace,
 	}, secret)
 	if err != nil {
-		return fmt.Errorf("failed to get SSH key secret: %w", err)
+		return nil, fmt.Errorf("failed to get SSH key secret: %w", err)
 	}
 
 	// Get the public key
 	publicKeyData, ok := secret.Data[publicKeyKey]
 	if !ok {
-		return fmt.Errorf("public key %s not found in secret %s/%s", publicKeyKey, namespace, secretRef.Name)
+		return nil, fmt.Errorf("public key %s not found in secret %s/%s", publicKeyKey, namespace, secretRef.Name)
 	}
 
-	// Convert to string
-	publicKey := string(publicKeyData)
-
-	// Get source agent pods
-	sourcePods, err := r.getAgentPods(ctx, sourceClient)
-	if err != nil {
-		return fmt.Errorf("failed to get source agent pods: %w", err)
-	}
-	log.Info(fmt.Sprintf("Found %d source agent pods", len(sourcePods)))
-
-	// Add public key to all source agents
-	for _, sourcePod := range sourcePods {
-		err = r.addPublicKeyToAuthorizedKeys(ctx, sourceClient, sourceConfig, sourcePod, publicKey)
-		if err != nil {
-			log.Errorf("Failed to add public key to source agent %s/%s: %v",
-				sourcePod.Namespace, sourcePod.Name, err)
-			continue
-		}
-		log.Info(fmt.Sprintf("Added public key to source agent %s/%s",
-			sourcePod.Namespace, sourcePod.Name))
-	}
-
-	return nil
+	return publicKeyData, nil
 }
 
-// distributeSSHKeysFromAgents extracts keys from agent pods and distributes them
-func (r *ClusterMappingReconciler) distributeSSHKeysFromAgents(ctx context.Context, clusterMapping *drsyncerio.ClusterMapping, sourceClient kubernetes.Interface, sourceConfig *rest.Config, targetClient kubernetes.Interface, targetConfig *rest.Config) error {
-	// Get target agent pods
-	targetPods, err := r.getAgentPods(ctx, targetClient)
-	if err != nil {
-		return fmt.Errorf("failed to get target agent pods: %w", err)
+// publicKeyFromAgentKeys reads the public host key from the key secret mounted by the target agents
+func (r *ClusterMappingReconciler) publicKeyFromAgentKeys(ctx context.Context, targetCluster *drsyncerio.RemoteCluster, targetClient kubernetes.Interface) ([]byte, error) {
+	secretName := "pvc-syncer-agent-keys"
+	if targetCluster.Spec.PVCSync != nil && targetCluster.Spec.PVCSync.SSH != nil && targetCluster.Spec.PVCSync.SSH.KeySecretRef != nil {
+		secretName = targetCluster.Spec.PVCSync.SSH.KeySecretRef.Name
 	}
-	log.Info(fmt.Sprintf("Found %d target agent pods", len(targetPods)))
 
-	// Get source agent pods
-	sourcePods, err := r.getAgentPods(ctx, sourceClient)
+	secret, err := targetClient.CoreV1().Secrets(agentNamespace).Get(ctx, secretName, metav1.GetOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to get source agent pods: %w", err)
+		return nil, fmt.Errorf("failed to get target agent key secret %s/%s: %w", agentNamespace, secretName, err)
 	}
-	log.Info(fmt.Sprintf("Found %d source agent pods", len(sourcePods)))
 
-	// Extract public keys from target agents and add to source agents
-	for _, targetPod := range targetPods {
-		// Extract public key from target agent
-		publicKey, err := r.extractPublicKey(ctx, targetClient, targetConfig, targetPod)
-		if err != nil {
-			log.Errorf("Failed to extract public key from target agent %s/%s: %v",
-				targetPod.Namespace, targetPod.Name, err)
-			continue
-		}
-
-		log.Info(fmt.Sprintf("Extracted public key from target agent %s/%s",
-			targetPod.Namespace, targetPod.Name))
-
-		// Add public key to all source agents
-		for _, sourcePod := range sourcePods {
-			err = r.addPublicKeyToAuthorizedKeys(ctx, sourceClient, sourceConfig, sourcePod, publicKey)
-			if err != nil {
-				log.Errorf("Failed to add public key to source agent %s/%s: %v",
-					sourcePod.Namespace, sourcePod.Name, err)
-				continue
-			}
-			log.Info(fmt.Sprintf("Added public key to source agent %s/%s",
-				sourcePod.Namespace, sourcePod.Name))
-		}
+	// Validate public key
+	publicKey := bytes.TrimSpace(secret.Data["ssh_host_rsa_key.pub"])
+	if !bytes.HasPrefix(publicKey, []byte("ssh-rsa ")) {
+		return nil, fmt.Errorf("invalid public key format in secret %s/%s", agentNamespace, secretName)
 	}
 
-	return nil
+	return publicKey, nil
 }
 
 // verifyConnectivity verifies SSH connectivity from target to source
@@ -686,8 +702,8 @@ func (r *ClusterMappingReconciler) verifyConnectivity(ctx context.Context, clust
 
 // getAgentPods gets all agent pods in the given cluster
 func (r *ClusterMappingReconciler) getAgentPods(ctx context.Context, client kubernetes.Interface) ([]corev1.Pod, error) {
-	// List all pods with the agent label
-	pods, err := client.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+	// List the pods with the agent label in the agent namespace
+	pods, err := client.CoreV1().Pods(agentNamespace).List(ctx, metav1.ListOptions{
 		LabelSelector: "app=dr-syncer-agent",
 	})
 	if err != nil {
@@ -705,52 +721,6 @@ func (r *ClusterMappingReconciler) getAgentPods(ctx context.Context, client kube
 	return runningPods, nil
 }
 
-// extractPublicKey extracts the public key from the agent pod
-func (r *ClusterMappingReconciler) extractPublicKey(ctx context.Context, client kubernetes.Interface, config *rest.Config, pod corev1.Pod) (string, error) {
-	// Execute command to get public key
-	stdout, stderr, err := r.execCommandInPod(ctx, client, config, pod, "cat /etc/ssh/keys/ssh_host_rsa_key.pub")
-	if err != nil {
-		return "", fmt.Errorf("failed to execute command in pod %s/%s: %w, stderr: %s",
-			pod.Namespace, pod.Name, err, stderr)
-	}
-
-	// Validate public key
-	publicKey := strings.TrimSpace(stdout)
-	if !strings.HasPrefix(publicKey, "ssh-rsa ") {
-		return "", fmt.Errorf("invalid public key format: %s", publicKey)
-	}
-
-	return publicKey, nil
-}
-
-// addPublicKeyToAuthorizedKeys adds the public key to the authorized_keys file
-func (r *ClusterMappingReconciler) addPublicKeyToAuthorizedKeys(ctx context.Context, client kubernetes.Interface, config *rest.Config, pod corev1.Pod, publicKey string) error {
-	// Check if key already exists
-	checkCmd := fmt.Sprintf("grep -q '%s' %s && echo 'exists' || echo 'not exists'",
-		strings.ReplaceAll(publicKey, "'", "'\\''"), "/home/syncer/.ssh/authorized_keys")
-
-	stdout, stderr, err := r.execCommandInPod(ctx, client, config, pod, checkCmd)
-	if err != nil {
-		return fmt.Errorf("failed to check if key exists: %w, stderr: %s", err, stderr)
-	}
-
-	if strings.TrimSpace(stdout) == "exists" {
-		// Key already exists, no need to add it
-		return nil
-	}
-
-	// Add key to authorized_keys
-	appendCmd := fmt.Sprintf("echo '%s' >> %s",
-		strings.ReplaceAll(publicKey, "'", "'\\''"), "/home/syncer/.ssh/authorized_keys")
-
-	_, stderr, err = r.execCommandInPod(ctx, client, config, pod, appendCmd)
-	if err != nil {
-		return fmt.Errorf("failed to add key to authorized_keys: %w, stderr: %s", err, stderr)
-	}
-
-	return nil
-}
-
 // testSSHConnection tests SSH connectivity from source to target
 func (r *ClusterMappingReconciler) testSSHConnection(ctx context.Context, client kubernetes.Interface, config *rest.Config, sourcePod corev1.Pod, targetPodIP string) (bool, string, error) {
 	// Execute SSH command to test connection
@@ -797,10 +767,10 @@ func (r *ClusterMappingReconciler) execCommandInPod(ctx context.Context, client
 		SubResource("exec").
 		VersionedParams(execOpts, scheme.ParameterCodec)
 
-	// Create SPDY executor
-	executor, err := remotecommand.NewSPDYExecutor(config, "POST", req.URL())
+	// Create the exec executor, streaming over WebSocket with an SPDY fallback
+	executor, err := util.NewPodExecutor(config, req.URL())
 	if err != nil {
-		return "", "", fmt.Errorf("failed to create SPDY executor: %w", err)
+		return "", "", fmt.Errorf("failed to create exec executor: %w", err)
 	}
 
 	// Prepare stdout and stderr buffers
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+// applyClusterMappingDefaults merges the default mappings of a ClusterMapping into the spec of a
+// NamespaceMapping referencing it. The NamespaceMapping's own mappings come first so they win over
+// a default for the same source name, which is dropped. Only the in-memory copy is changed, the
+// merged mappings are never written back to the NamespaceMapping.
+func applyClusterMappingDefaults(namespacemapping *drv1alpha1.NamespaceMapping, clusterMapping *drv1alpha1.ClusterMapping) {
+	defaults := clusterMapping.Spec.Defaults
+	if defaults == nil {
+		return
+	}
+	spec := &namespacemapping.Spec
+
+	if len(defaults.StorageClassMappings) > 0 || len(defaults.AccessModeMappings) > 0 {
+		if spec.PVCConfig == nil {
+			spec.PVCConfig = &drv1alpha1.PVCConfig{}
+		}
+		spec.PVCConfig.StorageClassMappings = mergeMappings(spec.PVCConfig.StorageClassMappings, defaults.StorageClassMappings,
+			func(m drv1alpha1.StorageClassMapping) string { return m.From })
+		spec.PVCConfig.AccessModeMappings = mergeMappings(spec.PVCConfig.AccessModeMappings, defaults.AccessModeMappings,
+			func(m drv1alpha1.AccessModeMapping) string { return m.From })
+	}
+
+	if len(defaults.IngressClassMappings) > 0 {
+		if spec.IngressConfig == nil {
+			spec.IngressConfig = &drv1alpha1.IngressConfig{}
+		}
+		spec.IngressConfig.IngressClassMappings = mergeMappings(spec.IngressConfig.IngressClassMappings, defaults.IngressClassMappings,
+			func(m drv1alpha1.IngressClassMapping) string { return m.From })
+	}
+}
+
+// mergeMappings returns own followed by the defaults whose source name own does not map
+func mergeMappings[T any](own, defaults []T, from func(T) string) []T {
+	if len(defaults) == 0 {
+		return own
+	}
+	overridden := make(map[string]bool, len(own))
+	merged := make([]T, 0, len(own)+len(defaults))
+	for _, m := range own {
+		overridden[from(m)] = true
+		merged = append(merged, m)
+	}
+	for _, m := range defaults {
+		if !overridden[from(m)] {
+			merged = append(merged, m)
+		}
+	}
+	return merged
+}
@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drsyncerio "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+)
+
+func TestApplyClusterMappingDefaults(t *testing.T) {
+	clusterMapping := testutil.NewClusterMapping("prod-to-dr").WithClusters("prod", "dr").Build()
+	clusterMapping.Spec.Defaults = &drsyncerio.ClusterMappingDefaults{
+		StorageClassMappings: []drsyncerio.StorageClassMapping{
+			{From: "gp2", To: "ceph-block"},
+			{From: "efs", To: "cephfs"},
+		},
+		AccessModeMappings: []drsyncerio.AccessModeMapping{
+			{From: "ReadWriteMany", To: "ReadWriteOnce"},
+		},
+		IngressClassMappings: []drsyncerio.IngressClassMapping{
+			{From: "alb", To: "nginx"},
+		},
+	}
+
+	nm := testutil.NewNamespaceMapping("app").WithClusterMappingRef("prod-to-dr").Build()
+	nm.Spec.PVCConfig = &drsyncerio.PVCConfig{
+		SyncData:             true,
+		StorageClassMappings: []drsyncerio.StorageClassMapping{{From: "gp2", To: "longhorn"}},
+	}
+
+	applyClusterMappingDefaults(nm, clusterMapping)
+
+	// The mapping's own storage class mapping wins over the default for the same class
+	assert.Equal(t, []drsyncerio.StorageClassMapping{
+		{From: "gp2", To: "longhorn"},
+		{From: "efs", To: "cephfs"},
+	}, nm.Spec.PVCConfig.StorageClassMappings)
+	assert.Equal(t, clusterMapping.Spec.Defaults.AccessModeMappings, nm.Spec.PVCConfig.AccessModeMappings)
+	assert.True(t, nm.Spec.PVCConfig.SyncData)
+	require.NotNil(t, nm.Spec.IngressConfig)
+	assert.Equal(t, clusterMapping.Spec.Defaults.IngressClassMappings, nm.Spec.IngressConfig.IngressClassMappings)
+}
+
+func TestApplyClusterMappingDefaults_None(t *testing.T) {
+	clusterMapping := testutil.NewClusterMapping("prod-to-dr").WithClusters("prod", "dr").Build()
+	nm := testutil.NewNamespaceMapping("app").WithClusterMappingRef("prod-to-dr").Build()
+
+	applyClusterMappingDefaults(nm, clusterMapping)
+	assert.Nil(t, nm.Spec.PVCConfig)
+	assert.Nil(t, nm.Spec.IngressConfig)
+}
@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drsyncerio "github.com/supporttools/dr-syncer/api/v1alpha1"
+	agentssh "github.com/supporttools/dr-syncer/pkg/agent/ssh"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDistributeSSHKeys_KeepsPreviousKeyForGracePeriod(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	keySecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "mapping-keys", Namespace: "dr-syncer"},
+		Data:       map[string][]byte{"id_rsa.pub": []byte("ssh-rsa OLD")},
+	}
+	cm := testutil.NewClusterMapping("prod-to-dr").
+		WithNamespace("dr-syncer").
+		WithClusters("prod", "dr").
+		WithSSHKeySecret("mapping-keys", "dr-syncer").
+		Build()
+	cm.Spec.KeyExpiryGracePeriod = &metav1.Duration{Duration: 30 * time.Minute}
+	target := testutil.NewRemoteCluster("dr").WithNamespace("dr-syncer").Build()
+
+	c := env.NewFakeClient(keySecret)
+	r := &ClusterMappingReconciler{Client: c, Scheme: env.Scheme}
+	sourceClient := k8sfake.NewSimpleClientset()
+
+	require.NoError(t, r.distributeSSHKeys(env.Ctx, cm, target, sourceClient, nil))
+	_, err := sourceClient.CoreV1().Secrets(agentNamespace).Get(env.Ctx,
+		agentssh.AuthorizedKeySourceName("clustermapping-dr-syncer-prod-to-dr-previous"), metav1.GetOptions{})
+	assert.Error(t, err, "an unchanged key leaves no previous key source")
+
+	keySecret.Data["id_rsa.pub"] = []byte("ssh-rsa NEW")
+	require.NoError(t, c.Update(env.Ctx, keySecret))
+	require.NoError(t, r.distributeSSHKeys(env.Ctx, cm, target, sourceClient, nil))
+
+	current, err := agentssh.AuthorizedKeySourceEntries(env.Ctx, sourceClient, agentNamespace, "clustermapping-dr-syncer-prod-to-dr")
+	require.NoError(t, err)
+	assert.Equal(t, "ssh-rsa NEW\n", string(current))
+
+	previous, err := sourceClient.CoreV1().Secrets(agentNamespace).Get(env.Ctx,
+		agentssh.AuthorizedKeySourceName("clustermapping-dr-syncer-prod-to-dr-previous"), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "ssh-rsa OLD\n", string(previous.Data["authorized_keys"]))
+	assert.False(t, agentssh.AuthorizedKeySourceExpired(previous, time.Now().Add(29*time.Minute)))
+	assert.True(t, agentssh.AuthorizedKeySourceExpired(previous, time.Now().Add(31*time.Minute)))
+}
+
+func TestKeysRotatedSinceDistribution(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name         string
+		secretRef    bool
+		rotated      *time.Time
+		distributed  *time.Time
+		wantRotation bool
+	}{
+		{name: "never rotated", distributed: &now},
+		{name: "rotated before distribution", rotated: timePtr(now.Add(-time.Hour)), distributed: &now},
+		{name: "rotated after distribution", rotated: &now, distributed: timePtr(now.Add(-time.Hour)), wantRotation: true},
+		{name: "never distributed", rotated: &now, wantRotation: true},
+		{name: "key from secret", secretRef: true, rotated: &now, distributed: timePtr(now.Add(-time.Hour))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := testutil.NewClusterMapping("prod-to-dr").WithClusters("prod", "dr")
+			if tt.secretRef {
+				builder = builder.WithSSHKeySecret("mapping-keys", "")
+			}
+			cm := builder.Build()
+			if tt.distributed != nil {
+				cm.Status.LastKeyDistribution = &metav1.Time{Time: *tt.distributed}
+			}
+			target := testutil.NewRemoteCluster("dr").Build()
+			if tt.rotated != nil {
+				target.Status.PVCSync = &drsyncerio.PVCSyncStatus{LastKeyRotation: &metav1.Time{Time: *tt.rotated}}
+			}
+			assert.Equal(t, tt.wantRotation, keysRotatedSinceDistribution(cm, target))
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
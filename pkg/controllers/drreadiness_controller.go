@@ -0,0 +1,400 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	replication "github.com/supporttools/dr-syncer/pkg/controller/replication"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// DefaultMaxResourceSyncAge is used when a DRReadiness does not set maxResourceSyncAge
+	DefaultMaxResourceSyncAge = time.Hour
+
+	// DefaultMaxDataSyncAge is used when a DRReadiness does not set maxDataSyncAge
+	DefaultMaxDataSyncAge = 24 * time.Hour
+
+	// DefaultReadinessCheckInterval is used when a DRReadiness does not set checkInterval
+	DefaultReadinessCheckInterval = time.Minute
+
+	// ReasonRecoverable is set on the Ready condition when every sync is fresh
+	ReasonRecoverable = "Recoverable"
+
+	// ReasonNotRecoverable is set on the Ready condition when any sync is failed or stale
+	ReasonNotRecoverable = "NotRecoverable"
+
+	// ReasonNoNamespaceMappings is set on the Ready condition when no NamespaceMappings were selected
+	ReasonNoNamespaceMappings = "NoNamespaceMappings"
+
+	// lastSyncTimeAnnotation is recorded on source PVCs after a completed data sync by the older sync workflow
+	lastSyncTimeAnnotation = "dr-syncer.io/last-sync-time"
+
+	// maxReadinessMessages limits how many problems are listed in the Ready condition message
+	maxReadinessMessages = 3
+)
+
+// DRReadinessReconciler reconciles a DRReadiness object
+type DRReadinessReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// SourceClientFor returns a client for the source cluster of a NamespaceMapping.
+	// Defaults to a client built from the RemoteCluster's kubeconfig secret.
+	SourceClientFor func(ctx context.Context, cluster *drv1alpha1.RemoteCluster) (kubernetes.Interface, error)
+
+	// now is overridden in tests
+	now func() time.Time
+}
+
+// +kubebuilder:rbac:groups=dr-syncer.io,resources=drreadinesses,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dr-syncer.io,resources=drreadinesses/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=dr-syncer.io,resources=namespacemappings,verbs=get;list;watch
+
+// SetupWithManager sets up the controller with the manager
+func (r *DRReadinessReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logging.LogInfo(nil, "setting up DRReadiness controller")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&drv1alpha1.DRReadiness{}).
+		Watches(&drv1alpha1.NamespaceMapping{}, handler.EnqueueRequestsFromMapFunc(r.findDRReadinessesForNamespaceMapping)).
+		Complete(r)
+}
+
+// findDRReadinessesForNamespaceMapping re-evaluates every DRReadiness in the namespace of a changed NamespaceMapping
+func (r *DRReadinessReconciler) findDRReadinessesForNamespaceMapping(ctx context.Context, obj client.Object) []reconcile.Request {
+	var list drv1alpha1.DRReadinessList
+	if err := r.List(ctx, &list, client.InNamespace(obj.GetNamespace())); err != nil {
+		log.Errorf("failed to list DRReadinesses in namespace %s: %v", obj.GetNamespace(), err)
+		return nil
+	}
+
+	requests := make([]reconcile.Request, 0, len(list.Items))
+	for _, item := range list.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Namespace: item.Namespace, Name: item.Name},
+		})
+	}
+	return requests
+}
+
+// Reconcile evaluates whether the application described by a DRReadiness is recoverable right now
+func (r *DRReadinessReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var readiness drv1alpha1.DRReadiness
+	if err := r.Get(ctx, req.NamespacedName, &readiness); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logging.LogError(nil, fmt.Sprintf("unable to fetch DRReadiness: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	mappings, missing, err := r.selectNamespaceMappings(ctx, &readiness)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	status := r.evaluate(ctx, &readiness, mappings, missing)
+	readiness.Status = status
+	if err := r.Status().Update(ctx, &readiness); err != nil {
+		if apierrors.IsConflict(err) {
+			return ctrl.Result{Requeue: true}, nil
+		}
+		logging.LogError(nil, fmt.Sprintf("failed to update DRReadiness status: %v", err))
+		return ctrl.Result{}, err
+	}
+
+	return ctrl.Result{RequeueAfter: durationOrDefault(readiness.Spec.CheckInterval, DefaultReadinessCheckInterval)}, nil
+}
+
+// selectNamespaceMappings returns the NamespaceMappings named or selected by the DRReadiness, sorted by name,
+// and the names of listed NamespaceMappings that do not exist
+func (r *DRReadinessReconciler) selectNamespaceMappings(ctx context.Context, readiness *drv1alpha1.DRReadiness) ([]drv1alpha1.NamespaceMapping, []string, error) {
+	selected := map[string]drv1alpha1.NamespaceMapping{}
+	var missing []string
+
+	for _, name := range readiness.Spec.NamespaceMappings {
+		var nm drv1alpha1.NamespaceMapping
+		if err := r.Get(ctx, client.ObjectKey{Namespace: readiness.Namespace, Name: name}, &nm); err != nil {
+			if apierrors.IsNotFound(err) {
+				missing = append(missing, name)
+				continue
+			}
+			return nil, nil, fmt.Errorf("failed to get NamespaceMapping %s: %w", name, err)
+		}
+		selected[nm.Name] = nm
+	}
+
+	if readiness.Spec.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(readiness.Spec.Selector)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid selector: %w", err)
+		}
+		var list drv1alpha1.NamespaceMappingList
+		if err := r.List(ctx, &list, client.InNamespace(readiness.Namespace), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return nil, nil, fmt.Errorf("failed to list NamespaceMappings: %w", err)
+		}
+		for _, nm := range list.Items {
+			selected[nm.Name] = nm
+		}
+	}
+
+	mappings := make([]drv1alpha1.NamespaceMapping, 0, len(selected))
+	for _, nm := range selected {
+		mappings = append(mappings, nm)
+	}
+	sort.Slice(mappings, func(i, j int) bool { return mappings[i].Name < mappings[j].Name })
+	return mappings, missing, nil
+}
+
+// evaluate builds the readiness status. Sync times are carried over from the previous status when they
+// cannot be observed, so that a sync in progress or an unreachable source cluster does not hide the RPO.
+func (r *DRReadinessReconciler) evaluate(ctx context.Context, readiness *drv1alpha1.DRReadiness, mappings []drv1alpha1.NamespaceMapping, missing []string) drv1alpha1.DRReadinessStatus {
+	now := time.Now()
+	if r.now != nil {
+		now = r.now()
+	}
+	maxResourceAge := durationOrDefault(readiness.Spec.MaxResourceSyncAge, DefaultMaxResourceSyncAge)
+	maxDataAge := durationOrDefault(readiness.Spec.MaxDataSyncAge, DefaultMaxDataSyncAge)
+
+	previousMappings := map[string]drv1alpha1.NamespaceMappingReadiness{}
+	for _, m := range readiness.Status.NamespaceMappings {
+		previousMappings[m.Name] = m
+	}
+	previousPVCs := map[string][]drv1alpha1.PVCReadiness{}
+	for _, p := range readiness.Status.PVCs {
+		previousPVCs[p.NamespaceMapping] = append(previousPVCs[p.NamespaceMapping], p)
+	}
+
+	status := drv1alpha1.DRReadinessStatus{Conditions: readiness.Status.Conditions}
+	var problems []string
+	var oldest *time.Time
+	rpoKnown := true
+	track := func(t *metav1.Time) {
+		if t == nil {
+			rpoKnown = false
+			return
+		}
+		if oldest == nil || t.Time.Before(*oldest) {
+			oldest = &t.Time
+		}
+	}
+
+	for _, name := range missing {
+		status.NamespaceMappings = append(status.NamespaceMappings, drv1alpha1.NamespaceMappingReadiness{
+			Name:    name,
+			Message: "NamespaceMapping not found",
+		})
+		problems = append(problems, fmt.Sprintf("NamespaceMapping %s not found", name))
+		rpoKnown = false
+	}
+
+	for i := range mappings {
+		nm := &mappings[i]
+		previous := previousMappings[nm.Name]
+		mr := evaluateNamespaceMapping(nm, previous.LastSuccessfulSyncTime, now, maxResourceAge)
+		status.NamespaceMappings = append(status.NamespaceMappings, mr)
+		track(mr.LastSuccessfulSyncTime)
+		if !mr.Ready {
+			problems = append(problems, fmt.Sprintf("NamespaceMapping %s: %s", nm.Name, mr.Message))
+		}
+
+		if nm.Spec.PVCConfig == nil || !nm.Spec.PVCConfig.SyncData {
+			continue
+		}
+		pvcs, err := r.evaluatePVCs(ctx, nm, previousPVCs[nm.Name], now, maxDataAge)
+		if err != nil {
+			logging.LogError(nil, fmt.Sprintf("unable to read PVC data sync state for NamespaceMapping %s/%s: %v", nm.Namespace, nm.Name, err))
+			problems = append(problems, fmt.Sprintf("NamespaceMapping %s: unable to read PVC data sync state: %v", nm.Name, err))
+		}
+		for _, pr := range pvcs {
+			status.PVCs = append(status.PVCs, pr)
+			track(pr.LastDataSyncTime)
+			if !pr.Ready {
+				problems = append(problems, fmt.Sprintf("PVC %s/%s: %s", pr.Namespace, pr.Name, pr.Message))
+			}
+		}
+	}
+
+	checkTime := metav1.NewTime(now)
+	status.LastCheckTime = &checkTime
+	if rpoKnown && oldest != nil {
+		status.EstimatedRPO = &metav1.Duration{Duration: now.Sub(*oldest).Round(time.Second)}
+	}
+
+	condition := metav1.Condition{
+		Type:               drv1alpha1.DRReadinessConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonRecoverable,
+		Message:            "All resource and data syncs are within their maximum age",
+		ObservedGeneration: readiness.Generation,
+	}
+	switch {
+	case len(mappings) == 0 && len(missing) == 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonNoNamespaceMappings
+		condition.Message = "No NamespaceMappings are selected"
+	case len(problems) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonNotRecoverable
+		condition.Message = summarizeProblems(problems)
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+
+	return status
+}
+
+// evaluateNamespaceMapping reports the resource sync state of a NamespaceMapping. LastSyncTime is only
+// a successful sync while the mapping is Completed; otherwise the previously observed success is kept.
+func evaluateNamespaceMapping(nm *drv1alpha1.NamespaceMapping, previousSuccess *metav1.Time, now time.Time, maxAge time.Duration) drv1alpha1.NamespaceMappingReadiness {
+	mr := drv1alpha1.NamespaceMappingReadiness{
+		Name:                   nm.Name,
+		Phase:                  nm.Status.Phase,
+		LastSuccessfulSyncTime: previousSuccess,
+	}
+	if nm.Status.Phase == drv1alpha1.SyncPhaseCompleted && nm.Status.LastSyncTime != nil {
+		mr.LastSuccessfulSyncTime = nm.Status.LastSyncTime.DeepCopy()
+	}
+
+	if mr.LastSuccessfulSyncTime != nil {
+		mr.SyncAge = &metav1.Duration{Duration: now.Sub(mr.LastSuccessfulSyncTime.Time).Round(time.Second)}
+	}
+
+	switch {
+	case nm.Status.Phase == drv1alpha1.SyncPhaseFailed:
+		mr.Message = "last sync failed"
+		if nm.Status.LastError != nil && nm.Status.LastError.Message != "" {
+			mr.Message = fmt.Sprintf("last sync failed: %s", nm.Status.LastError.Message)
+		}
+	case mr.LastSuccessfulSyncTime == nil:
+		mr.Message = "no successful sync observed"
+	case mr.SyncAge.Duration > maxAge:
+		mr.Message = fmt.Sprintf("last successful sync was %s ago, more than %s", mr.SyncAge.Duration, maxAge)
+	default:
+		mr.Ready = true
+	}
+	return mr
+}
+
+// evaluatePVCs reports the data sync state of the source PVCs of a NamespaceMapping. If the source
+// cluster cannot be reached, the previous PVC entries are re-evaluated against the current time.
+func (r *DRReadinessReconciler) evaluatePVCs(ctx context.Context, nm *drv1alpha1.NamespaceMapping, previous []drv1alpha1.PVCReadiness, now time.Time, maxAge time.Duration) ([]drv1alpha1.PVCReadiness, error) {
+	sourceNamespace := nm.Spec.SourceNamespace
+	var pvcList *corev1.PersistentVolumeClaimList
+	err := withRemoteCluster(ctx, func() error {
+		sourceClient, err := r.sourceClient(ctx, nm)
+		if err != nil {
+			return err
+		}
+		pvcList, err = sourceClient.CoreV1().PersistentVolumeClaims(sourceNamespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list PVCs in source namespace %s: %w", sourceNamespace, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return refreshPVCReadiness(previous, now, maxAge), err
+	}
+
+	var result []drv1alpha1.PVCReadiness
+	for i := range pvcList.Items {
+		pvc := &pvcList.Items[i]
+		if utils.ShouldIgnoreResource(pvc) {
+			continue
+		}
+		pr := drv1alpha1.PVCReadiness{
+			NamespaceMapping: nm.Name,
+			Namespace:        pvc.Namespace,
+			Name:             pvc.Name,
+			LastDataSyncTime: pvcLastDataSyncTime(pvc.Annotations),
+		}
+		result = append(result, refreshPVCReadiness([]drv1alpha1.PVCReadiness{pr}, now, maxAge)...)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// refreshPVCReadiness recomputes the age and readiness of PVC entries at now
+func refreshPVCReadiness(pvcs []drv1alpha1.PVCReadiness, now time.Time, maxAge time.Duration) []drv1alpha1.PVCReadiness {
+	result := make([]drv1alpha1.PVCReadiness, 0, len(pvcs))
+	for _, pr := range pvcs {
+		pr.Ready = false
+		pr.SyncAge = nil
+		pr.Message = ""
+		switch {
+		case pr.LastDataSyncTime == nil:
+			pr.Message = "no successful data sync observed"
+		default:
+			pr.SyncAge = &metav1.Duration{Duration: now.Sub(pr.LastDataSyncTime.Time).Round(time.Second)}
+			if pr.SyncAge.Duration > maxAge {
+				pr.Message = fmt.Sprintf("last successful data sync was %s ago, more than %s", pr.SyncAge.Duration, maxAge)
+			} else {
+				pr.Ready = true
+			}
+		}
+		result = append(result, pr)
+	}
+	return result
+}
+
+// pvcLastDataSyncTime returns when the last successful data sync of a source PVC started. The start time is
+// preferred over the completion time because changes made during the transfer may have been missed.
+func pvcLastDataSyncTime(annotations map[string]string) *metav1.Time {
+	for _, key := range []string{replication.AnnotationLastDataSyncStart, lastSyncTimeAnnotation} {
+		value, ok := annotations[key]
+		if !ok {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			mt := metav1.NewTime(t)
+			return &mt
+		}
+	}
+	return nil
+}
+
+// sourceClient returns a client for the source cluster of a NamespaceMapping
+func (r *DRReadinessReconciler) sourceClient(ctx context.Context, nm *drv1alpha1.NamespaceMapping) (kubernetes.Interface, error) {
+	cluster, err := sourceRemoteCluster(ctx, r.Client, nm)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.SourceClientFor != nil {
+		return r.SourceClientFor(ctx, cluster)
+	}
+	clientset, _, err := remoteClusterClient(ctx, r.Client, cluster)
+	return clientset, err
+}
+
+// summarizeProblems joins the first problems into a condition message
+func summarizeProblems(problems []string) string {
+	if len(problems) <= maxReadinessMessages {
+		return strings.Join(problems, "; ")
+	}
+	return fmt.Sprintf("%s; and %d more", strings.Join(problems[:maxReadinessMessages], "; "), len(problems)-maxReadinessMessages)
+}
+
+// durationOrDefault returns d, or def when d is unset
+func durationOrDefault(d *metav1.Duration, def time.Duration) time.Duration {
+	if d == nil || d.Duration <= 0 {
+		return def
+	}
+	return d.Duration
+}
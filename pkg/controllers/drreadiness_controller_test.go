@@ -0,0 +1,204 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controller/replication"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var readinessNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func completedMapping(name string, syncedAgo time.Duration) *drv1alpha1.NamespaceMapping {
+	nm := testutil.NewNamespaceMapping(name).
+		WithSourceCluster("prod").
+		WithDestinationCluster("dr").
+		WithSourceNamespace("app").
+		WithStatusPhase(drv1alpha1.SyncPhaseCompleted).
+		Build()
+	synced := metav1.NewTime(readinessNow.Add(-syncedAgo))
+	nm.Status.LastSyncTime = &synced
+	return nm
+}
+
+func sourcePVC(name string, syncedAgo time.Duration) *corev1.PersistentVolumeClaim {
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"}}
+	if syncedAgo > 0 {
+		pvc.Annotations = map[string]string{
+			replication.AnnotationLastDataSyncStart: readinessNow.Add(-syncedAgo).Format(time.RFC3339),
+		}
+	}
+	return pvc
+}
+
+func reconcileReadiness(t *testing.T, r *DRReadinessReconciler, env *testutil.TestEnv) *drv1alpha1.DRReadiness {
+	t.Helper()
+	result, err := r.Reconcile(env.Ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "app"}})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultReadinessCheckInterval, result.RequeueAfter)
+
+	var readiness drv1alpha1.DRReadiness
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "app"}, &readiness))
+	return &readiness
+}
+
+func newReadinessReconciler(env *testutil.TestEnv, source kubernetes.Interface, objs ...client.Object) *DRReadinessReconciler {
+	objs = append(objs, testutil.NewRemoteCluster("prod").Build())
+	return &DRReadinessReconciler{
+		Client: env.NewFakeClient(objs...),
+		Scheme: env.Scheme,
+		SourceClientFor: func(ctx context.Context, cluster *drv1alpha1.RemoteCluster) (kubernetes.Interface, error) {
+			if source == nil {
+				return nil, errors.New("source cluster unreachable")
+			}
+			return source, nil
+		},
+		now: func() time.Time { return readinessNow },
+	}
+}
+
+func TestDRReadiness_Ready(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	web := completedMapping("web", 10*time.Minute)
+	db := completedMapping("db", 5*time.Minute)
+	db.Spec.PVCConfig = &drv1alpha1.PVCConfig{SyncData: true}
+	db.Labels = map[string]string{"app": "shop"}
+
+	readiness := &drv1alpha1.DRReadiness{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec: drv1alpha1.DRReadinessSpec{
+			NamespaceMappings: []string{"web"},
+			Selector:          &metav1.LabelSelector{MatchLabels: map[string]string{"app": "shop"}},
+		},
+	}
+	source := k8sfake.NewSimpleClientset(sourcePVC("data", 2*time.Hour))
+
+	r := newReadinessReconciler(env, source, web, db, readiness)
+	updated := reconcileReadiness(t, r, env)
+
+	condition := meta.FindStatusCondition(updated.Status.Conditions, drv1alpha1.DRReadinessConditionReady)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status, condition.Message)
+	assert.Equal(t, ReasonRecoverable, condition.Reason)
+
+	require.Len(t, updated.Status.NamespaceMappings, 2)
+	assert.Equal(t, "db", updated.Status.NamespaceMappings[0].Name)
+	require.Len(t, updated.Status.PVCs, 1)
+	assert.Equal(t, 2*time.Hour, updated.Status.PVCs[0].SyncAge.Duration)
+
+	// The PVC data is the oldest sync
+	require.NotNil(t, updated.Status.EstimatedRPO)
+	assert.Equal(t, 2*time.Hour, updated.Status.EstimatedRPO.Duration)
+}
+
+func TestDRReadiness_NotReady(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	stale := completedMapping("stale", 3*time.Hour)
+	failed := completedMapping("failed", time.Minute)
+	failed.Status.Phase = drv1alpha1.SyncPhaseFailed
+	failed.Status.LastError = &drv1alpha1.SyncError{Message: "forbidden"}
+
+	readiness := &drv1alpha1.DRReadiness{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       drv1alpha1.DRReadinessSpec{NamespaceMappings: []string{"stale", "failed", "missing"}},
+	}
+
+	r := newReadinessReconciler(env, nil, stale, failed, readiness)
+	updated := reconcileReadiness(t, r, env)
+
+	condition := meta.FindStatusCondition(updated.Status.Conditions, drv1alpha1.DRReadinessConditionReady)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonNotRecoverable, condition.Reason)
+	assert.Contains(t, condition.Message, "missing not found")
+	assert.Contains(t, condition.Message, "forbidden")
+	assert.Contains(t, condition.Message, "3h0m0s ago")
+
+	// RPO is unknown while a listed NamespaceMapping does not exist
+	assert.Nil(t, updated.Status.EstimatedRPO)
+}
+
+func TestDRReadiness_CarriesSyncTimesForward(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	// A sync is in progress, so LastSyncTime is the start of the current run rather than a success
+	running := completedMapping("web", 0)
+	running.Status.Phase = drv1alpha1.SyncPhaseRunning
+	running.Spec.PVCConfig = &drv1alpha1.PVCConfig{SyncData: true}
+
+	previousSync := metav1.NewTime(readinessNow.Add(-30 * time.Minute))
+	previousData := metav1.NewTime(readinessNow.Add(-26 * time.Hour))
+	readiness := &drv1alpha1.DRReadiness{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"},
+		Spec:       drv1alpha1.DRReadinessSpec{NamespaceMappings: []string{"web"}},
+		Status: drv1alpha1.DRReadinessStatus{
+			NamespaceMappings: []drv1alpha1.NamespaceMappingReadiness{{Name: "web", LastSuccessfulSyncTime: &previousSync}},
+			PVCs: []drv1alpha1.PVCReadiness{
+				{NamespaceMapping: "web", Namespace: "app", Name: "data", LastDataSyncTime: &previousData},
+			},
+		},
+	}
+
+	// The source cluster is unreachable, so PVC state comes from the previous status
+	r := newReadinessReconciler(env, nil, running, readiness)
+	updated := reconcileReadiness(t, r, env)
+
+	require.Len(t, updated.Status.NamespaceMappings, 1)
+	assert.True(t, updated.Status.NamespaceMappings[0].Ready)
+	assert.Equal(t, 30*time.Minute, updated.Status.NamespaceMappings[0].SyncAge.Duration)
+
+	require.Len(t, updated.Status.PVCs, 1)
+	assert.False(t, updated.Status.PVCs[0].Ready)
+	assert.Equal(t, 26*time.Hour, updated.Status.EstimatedRPO.Duration)
+
+	condition := meta.FindStatusCondition(updated.Status.Conditions, drv1alpha1.DRReadinessConditionReady)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Contains(t, condition.Message, "source cluster unreachable")
+}
+
+func TestDRReadiness_NoNamespaceMappings(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	readiness := &drv1alpha1.DRReadiness{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "default"}}
+
+	r := newReadinessReconciler(env, nil, readiness)
+	updated := reconcileReadiness(t, r, env)
+
+	condition := meta.FindStatusCondition(updated.Status.Conditions, drv1alpha1.DRReadinessConditionReady)
+	require.NotNil(t, condition)
+	assert.Equal(t, ReasonNoNamespaceMappings, condition.Reason)
+}
+
+func TestPVCLastDataSyncTime(t *testing.T) {
+	start := readinessNow.Add(-time.Hour).Format(time.RFC3339)
+	completed := readinessNow.Format(time.RFC3339)
+
+	// The start time wins over the completion time
+	got := pvcLastDataSyncTime(map[string]string{
+		replication.AnnotationLastDataSyncStart: start,
+		lastSyncTimeAnnotation:                  completed,
+	})
+	require.NotNil(t, got)
+	assert.Equal(t, readinessNow.Add(-time.Hour), got.Time.UTC())
+
+	got = pvcLastDataSyncTime(map[string]string{lastSyncTimeAnnotation: completed})
+	require.NotNil(t, got)
+	assert.Equal(t, readinessNow, got.Time.UTC())
+
+	assert.Nil(t, pvcLastDataSyncTime(nil))
+}
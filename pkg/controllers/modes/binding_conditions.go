@@ -0,0 +1,47 @@
+package modes
+
+import (
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypePendingBinding is True while WaitForFirstConsumer destination PVCs cannot be bound on a
+	// node their data can be written from, only set once a data sync found such a PVC
+	ConditionTypePendingBinding = "PendingBinding"
+
+	// ReasonWaitForFirstConsumer is set when the data of PVCs was not synced as their WaitForFirstConsumer
+	// destination PVC is not bound
+	ReasonWaitForFirstConsumer = "WaitForFirstConsumer"
+)
+
+// setPendingBindingCondition records the PVCs of a sync result whose destination PVC could not be bound.
+// The condition is only written after the first such PVC and flipped back once every PVC is bound.
+func setPendingBindingCondition(status *drv1alpha1.NamespaceMappingStatus, generation int64, result *syncer.SyncResult) {
+	var pending []string
+	if result != nil {
+		pending = result.PendingBinding
+	}
+	if len(pending) == 0 {
+		if meta.FindStatusCondition(status.Conditions, ConditionTypePendingBinding) != nil {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               ConditionTypePendingBinding,
+				Status:             metav1.ConditionFalse,
+				Reason:             "Bound",
+				Message:            "Destination PVCs are bound",
+				ObservedGeneration: generation,
+			})
+		}
+		return
+	}
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionTypePendingBinding,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonWaitForFirstConsumer,
+		Message:            pvcsNotSyncedMessage(pending),
+		ObservedGeneration: generation,
+	})
+}
@@ -0,0 +1,35 @@
+package modes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetPendingBindingCondition(t *testing.T) {
+	status := &drv1alpha1.NamespaceMappingStatus{}
+
+	// No condition until a destination PVC cannot be bound
+	setPendingBindingCondition(status, 1, &syncer.SyncResult{PVCDataSynced: 2})
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypePendingBinding))
+
+	pending := make([]string, 7)
+	for i := range pending {
+		pending[i] = "failed to find DaemonSet pod: pending binding: no rsync DaemonSet node is allowed by the storage class topology"
+	}
+	setPendingBindingCondition(status, 2, &syncer.SyncResult{PVCDataFailed: 7, PendingBinding: pending})
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypePendingBinding)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonWaitForFirstConsumer, condition.Reason)
+	assert.Contains(t, condition.Message, "7 PVCs were not synced: failed to find DaemonSet pod: pending binding")
+	assert.Contains(t, condition.Message, "and 2 more")
+
+	setPendingBindingCondition(status, 3, &syncer.SyncResult{PVCDataSynced: 1})
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, ConditionTypePendingBinding))
+}
@@ -0,0 +1,168 @@
+package modes
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/tempod"
+	"github.com/supporttools/dr-syncer/pkg/controller/replication"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+const (
+	// rsyncDeploymentSelector selects the rsync deployments writing PVC data into a destination namespace
+	rsyncDeploymentSelector = "app.kubernetes.io/name=dr-syncer-rsync"
+
+	// rsyncPVCNameLabel names the PVC written by an rsync deployment
+	rsyncPVCNameLabel = "dr-syncer.io/pvc-name"
+)
+
+// cleanupPolicy returns the mapping's cleanup policy, defaulting to None
+func cleanupPolicy(mapping *drv1alpha1.NamespaceMapping) drv1alpha1.CleanupPolicy {
+	if mapping.Spec.CleanupPolicy == "" {
+		return drv1alpha1.CleanupPolicyNone
+	}
+	return mapping.Spec.CleanupPolicy
+}
+
+// cleanupListOptions selects the destination resources removed under policy. The second return
+// value is false when nothing can be selected.
+func cleanupListOptions(mapping *drv1alpha1.NamespaceMapping, policy drv1alpha1.CleanupPolicy) (metav1.ListOptions, bool) {
+	switch policy {
+	case drv1alpha1.CleanupPolicyAll:
+		return metav1.ListOptions{}, true
+	case drv1alpha1.CleanupPolicySyncedOnly:
+		mappingLabels := utils.MappingLabels(mapping.Namespace, mapping.Name)
+		if mappingLabels == nil {
+			return metav1.ListOptions{}, false
+		}
+		return metav1.ListOptions{LabelSelector: labels.SelectorFromSet(mappingLabels).String()}, true
+	default:
+		return metav1.ListOptions{}, false
+	}
+}
+
+// discoverCleanupResources returns the namespaced resource types of the destination cluster that
+// can be listed and deleted, used to find every labeled resource of a wildcard mapping
+func discoverCleanupResources(dc discovery.DiscoveryInterface) ([]schema.GroupVersionResource, error) {
+	lists, err := discovery.ServerPreferredNamespacedResources(dc)
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("failed to discover destination resource types: %w", err)
+	}
+	lists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "delete"}}, lists)
+
+	resources, err := discovery.GroupVersionResources(lists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse destination resource types: %w", err)
+	}
+
+	var gvrs []schema.GroupVersionResource
+	for gvr := range resources {
+		// Events and pods are never synced, and deleting them only disturbs the destination
+		switch gvr.GroupResource() {
+		case schema.GroupResource{Resource: "events"}, schema.GroupResource{Group: "events.k8s.io", Resource: "events"},
+			schema.GroupResource{Resource: "pods"}:
+			continue
+		}
+		gvrs = append(gvrs, gvr)
+	}
+	return gvrs, nil
+}
+
+// StaleLockTimeout returns the age after which the data syncs of the mapping take over a source PVC lock
+func StaleLockTimeout(mapping *drv1alpha1.NamespaceMapping) time.Duration {
+	var configured *metav1.Duration
+	if mapping.Spec.PVCConfig != nil && mapping.Spec.PVCConfig.DataSyncConfig != nil {
+		configured = mapping.Spec.PVCConfig.DataSyncConfig.StaleLockTimeout
+	}
+	return replication.StaleLockTimeout(configured)
+}
+
+// InFlightPVCSyncs returns the PVCs of the mapping whose data is being synced. They are found by the
+// live sync locks of the source PVCs. When the source cluster cannot be read, the rsync deployments
+// writing into the destination namespace are returned instead.
+func (r *ModeReconciler) InFlightPVCSyncs(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) ([]string, error) {
+	if r.k8sSource != nil && mapping.Spec.SourceNamespace != "" {
+		inFlight, err := r.lockedSourcePVCs(ctx, mapping)
+		if err == nil || r.k8sDest == nil {
+			return inFlight, err
+		}
+		log.Info(fmt.Sprintf("checking the destination for in-flight PVC syncs of mapping %s: %v", mapping.Name, err))
+	}
+	return r.destinationRsyncPVCs(ctx, mapping)
+}
+
+// lockedSourcePVCs returns the source PVCs of the mapping holding a live sync lock
+func (r *ModeReconciler) lockedSourcePVCs(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) ([]string, error) {
+	pvcs, err := r.k8sSource.CoreV1().PersistentVolumeClaims(mapping.Spec.SourceNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list source PVCs: %w", err)
+	}
+
+	var inFlight []string
+	now := time.Now()
+	staleAfter := StaleLockTimeout(mapping)
+	for i := range pvcs.Items {
+		if replication.LockHeld(&pvcs.Items[i], staleAfter, now) {
+			inFlight = append(inFlight, pvcs.Items[i].Name)
+		}
+	}
+	return inFlight, nil
+}
+
+// destinationRsyncPVCs returns the PVCs written by the rsync deployments of the mapping's destination
+// namespace, the destination side of the sync recorded in a source PVC lock. Deployments older than the
+// stale lock timeout belong to syncs that would be taken over, so they are ignored.
+func (r *ModeReconciler) destinationRsyncPVCs(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) ([]string, error) {
+	namespace := mapping.Spec.DestinationNamespace
+	if namespace == "" {
+		namespace = mapping.Spec.SourceNamespace
+	}
+	if r.k8sDest == nil || namespace == "" {
+		return nil, nil
+	}
+
+	deployments, err := r.k8sDest.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{LabelSelector: rsyncDeploymentSelector})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list destination rsync deployments: %w", err)
+	}
+
+	var inFlight []string
+	now := time.Now()
+	staleAfter := StaleLockTimeout(mapping)
+	for _, deployment := range deployments.Items {
+		if now.Sub(deployment.CreationTimestamp.Time) > staleAfter {
+			continue
+		}
+		pvcName := deployment.Labels[rsyncPVCNameLabel]
+		if pvcName == "" {
+			pvcName = deployment.Name
+		}
+		inFlight = append(inFlight, pvcName)
+	}
+	return inFlight, nil
+}
+
+// releaseWarmPool deletes the warm pool pods keeping the mapping's destination PVCs attached. They
+// belong to dr-syncer rather than the synced workloads, so they are removed under every cleanup policy.
+func (r *ModeReconciler) releaseWarmPool(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) {
+	if r.k8sDest == nil {
+		return
+	}
+	namespace := mapping.Spec.DestinationNamespace
+	if namespace == "" {
+		namespace = mapping.Spec.SourceNamespace
+	}
+	if namespace == "" {
+		return
+	}
+	if err := tempod.DeleteWarmPoolPods(ctx, r.k8sDest, namespace, ""); err != nil {
+		log.Errorf("failed to release warm pool pods of mapping %s: %v", mapping.Name, err)
+	}
+}
@@ -0,0 +1,142 @@
+package modes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/tempod"
+	"github.com/supporttools/dr-syncer/pkg/controller/replication"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func cleanupMapping(policy drv1alpha1.CleanupPolicy) *drv1alpha1.NamespaceMapping {
+	return &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "dr-syncer"},
+		Spec: drv1alpha1.NamespaceMappingSpec{
+			SourceNamespace:      "shop",
+			DestinationNamespace: "shop-dr",
+			ResourceTypes:        []string{"configmaps"},
+			CleanupPolicy:        policy,
+		},
+	}
+}
+
+func TestCleanupListOptions(t *testing.T) {
+	_, ok := cleanupListOptions(cleanupMapping(""), cleanupPolicy(cleanupMapping("")))
+	assert.False(t, ok, "the default policy removes nothing")
+
+	_, ok = cleanupListOptions(cleanupMapping(drv1alpha1.CleanupPolicyNone), drv1alpha1.CleanupPolicyNone)
+	assert.False(t, ok)
+
+	opts, ok := cleanupListOptions(cleanupMapping(drv1alpha1.CleanupPolicyAll), drv1alpha1.CleanupPolicyAll)
+	assert.True(t, ok)
+	assert.Empty(t, opts.LabelSelector)
+
+	opts, ok = cleanupListOptions(cleanupMapping(drv1alpha1.CleanupPolicySyncedOnly), drv1alpha1.CleanupPolicySyncedOnly)
+	assert.True(t, ok)
+	assert.Equal(t, "dr-syncer.io/namespacemapping=shop,dr-syncer.io/namespacemapping-namespace=dr-syncer", opts.LabelSelector)
+}
+
+func newCleanupReconciler(t *testing.T) (*ModeReconciler, *dynamicfake.FakeDynamicClient) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	synced := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "synced", Namespace: "shop-dr", Labels: utils.MappingLabels("dr-syncer", "shop"),
+	}}
+	local := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "shop-dr"}}
+	otherMapping := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "other", Namespace: "shop-dr", Labels: utils.MappingLabels("dr-syncer", "other"),
+	}}
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme, synced, local, otherMapping)
+
+	return NewModeReconciler(nil, nil, destDynamic, nil, nil, nil, nil, "source", "destination"), destDynamic
+}
+
+func remainingConfigMaps(t *testing.T, destDynamic *dynamicfake.FakeDynamicClient) []string {
+	list, err := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("shop-dr").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names
+}
+
+func TestCleanupResources_Policies(t *testing.T) {
+	tests := []struct {
+		policy    drv1alpha1.CleanupPolicy
+		remaining []string
+	}{
+		{"", []string{"local", "other", "synced"}},
+		{drv1alpha1.CleanupPolicyNone, []string{"local", "other", "synced"}},
+		{drv1alpha1.CleanupPolicySyncedOnly, []string{"local", "other"}},
+		{drv1alpha1.CleanupPolicyAll, nil},
+	}
+	for _, tc := range tests {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			r, destDynamic := newCleanupReconciler(t)
+			require.NoError(t, r.CleanupResources(context.Background(), cleanupMapping(tc.policy)))
+			assert.ElementsMatch(t, tc.remaining, remainingConfigMaps(t, destDynamic))
+		})
+	}
+}
+
+func TestInFlightPVCSyncs(t *testing.T) {
+	sourceClient := fake.NewSimpleClientset(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "shop", Annotations: map[string]string{
+			replication.LockOwnerAnnotation:     "dr-syncer-controller-0",
+			replication.LockTimestampAnnotation: time.Now().UTC().Format(time.RFC3339),
+		}}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "archive", Namespace: "shop", Annotations: map[string]string{
+			replication.LockOwnerAnnotation:     "dr-syncer-controller-0",
+			replication.LockTimestampAnnotation: time.Now().Add(-2 * time.Hour).UTC().Format(time.RFC3339),
+		}}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "shop"}},
+	)
+	r := NewModeReconciler(nil, nil, nil, sourceClient, nil, nil, nil, "source", "destination")
+
+	mapping := cleanupMapping(drv1alpha1.CleanupPolicyAll)
+	inFlight, err := r.InFlightPVCSyncs(context.Background(), mapping)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"data"}, inFlight, "a lock older than the default timeout is stale")
+
+	// A configured stale lock timeout keeps older locks live
+	mapping.Spec.PVCConfig = &drv1alpha1.PVCConfig{DataSyncConfig: &drv1alpha1.PVCDataSyncConfig{
+		StaleLockTimeout: &metav1.Duration{Duration: 3 * time.Hour},
+	}}
+	inFlight, err = r.InFlightPVCSyncs(context.Background(), mapping)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"data", "archive"}, inFlight)
+
+	// Without a source client there is nothing to wait for
+	inFlight, err = NewModeReconciler(nil, nil, nil, nil, nil, nil, nil, "", "").InFlightPVCSyncs(context.Background(), cleanupMapping(drv1alpha1.CleanupPolicyAll))
+	require.NoError(t, err)
+	assert.Empty(t, inFlight)
+}
+
+func TestCleanupResources_ReleasesWarmPool(t *testing.T) {
+	destClient := fake.NewSimpleClientset(
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: tempod.WarmPoolPodName("data"), Namespace: "shop-dr",
+			Labels: map[string]string{tempod.WarmPoolLabel: "true", "pvc-name": "data"}}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop-dr"}},
+	)
+	r := NewModeReconciler(nil, nil, nil, nil, destClient, nil, nil, "source", "destination")
+
+	// Warm pool pods are released even when the policy leaves destination resources in place
+	require.NoError(t, r.CleanupResources(context.Background(), cleanupMapping(drv1alpha1.CleanupPolicyNone)))
+
+	pods, err := destClient.CoreV1().Pods("shop-dr").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, pods.Items, 1)
+	assert.Equal(t, "web", pods.Items[0].Name)
+}
@@ -0,0 +1,54 @@
+package modes
+
+import (
+	"fmt"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeCRDsCompatible reports whether the CRDs of the source could be synced to the destination
+	// as they are, only set when the mapping syncs CRDs
+	ConditionTypeCRDsCompatible = "CRDsCompatible"
+
+	// ReasonCRDsIncompatible is set when CRDs were skipped or synced without their conversion webhook
+	ReasonCRDsIncompatible = "Incompatible"
+
+	// maxCRDIncompatibilitiesInMessage bounds the CRDs named in the CRDsCompatible condition message
+	maxCRDIncompatibilitiesInMessage = 5
+)
+
+// setCRDsCompatibleCondition records the incompatibilities found by the CRD sync of a sync result. The
+// condition is removed when the mapping does not sync CRDs.
+func setCRDsCompatibleCondition(status *drv1alpha1.NamespaceMappingStatus, generation int64, result *syncer.SyncResult) {
+	if result == nil || !result.CRDsSynced {
+		meta.RemoveStatusCondition(&status.Conditions, ConditionTypeCRDsCompatible)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeCRDsCompatible,
+		Status:             metav1.ConditionTrue,
+		Reason:             "CRDsSynced",
+		Message:            "Source CRDs are compatible with the destination",
+		ObservedGeneration: generation,
+	}
+	if incompatibilities := result.CRDIncompatibilities; len(incompatibilities) > 0 {
+		shown := incompatibilities
+		if len(shown) > maxCRDIncompatibilitiesInMessage {
+			shown = shown[:maxCRDIncompatibilitiesInMessage]
+		}
+		message := fmt.Sprintf("%d CRDs are incompatible with the destination: %s", len(incompatibilities), strings.Join(shown, "; "))
+		if len(incompatibilities) > len(shown) {
+			message += fmt.Sprintf(" and %d more", len(incompatibilities)-len(shown))
+		}
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonCRDsIncompatible
+		condition.Message = message
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
@@ -0,0 +1,33 @@
+package modes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetCRDsCompatibleCondition(t *testing.T) {
+	status := &drv1alpha1.NamespaceMappingStatus{}
+
+	setCRDsCompatibleCondition(status, 1, &syncer.SyncResult{CRDsSynced: true})
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeCRDsCompatible))
+
+	setCRDsCompatibleCondition(status, 2, &syncer.SyncResult{
+		CRDsSynced:           true,
+		CRDIncompatibilities: []string{"widgets.example.com: destination serves versions v2 the source does not have, the destination CRD is not updated"},
+	})
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypeCRDsCompatible)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonCRDsIncompatible, condition.Reason)
+	assert.Contains(t, condition.Message, "1 CRDs are incompatible with the destination: widgets.example.com")
+
+	// A mapping that stops syncing CRDs has no condition
+	setCRDsCompatibleCondition(status, 3, &syncer.SyncResult{})
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypeCRDsCompatible))
+}
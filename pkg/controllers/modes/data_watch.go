@@ -0,0 +1,135 @@
+package modes
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/datawatch"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// defaultDataWatchMinInterval is the minimum interval between two data syncs triggered by file changes
+	defaultDataWatchMinInterval = 5 * time.Minute
+
+	// dataWatchPollInterval is how often the source PVCs are checked for changes reported by the agents
+	dataWatchPollInterval = 30 * time.Second
+)
+
+// dataWatchInterval returns the minimum interval between two data syncs triggered by file changes,
+// false when the mapping does not watch its PVC data
+func dataWatchInterval(mapping *drv1alpha1.NamespaceMapping) (time.Duration, bool, error) {
+	if mapping.Spec.Continuous == nil || mapping.Spec.Continuous.DataWatch == nil || !mapping.Spec.Continuous.DataWatch.Enabled {
+		return 0, false, nil
+	}
+	if mapping.Spec.PVCConfig == nil || !mapping.Spec.PVCConfig.SyncData {
+		return 0, false, nil
+	}
+	if mapping.Spec.Continuous.DataWatch.MinInterval == "" {
+		return defaultDataWatchMinInterval, true, nil
+	}
+	interval, err := time.ParseDuration(mapping.Spec.Continuous.DataWatch.MinInterval)
+	if err != nil {
+		return 0, false, fmt.Errorf("invalid data watch minimum interval: %v", err)
+	}
+	return interval, true, nil
+}
+
+// dataWatchPatch returns the patch setting or, when interval is empty, removing the data watch annotation
+func dataWatchPatch(interval string) ([]byte, error) {
+	var value interface{}
+	if interval != "" {
+		value = interval
+	}
+	return json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]interface{}{datawatch.WatchAnnotation: value},
+		},
+	})
+}
+
+// pollPVCData has the agents watch the data of the source PVCs of the mapping, or stop watching it when
+// watched is false, and returns the time of the latest data change they reported
+func (r *ModeReconciler) pollPVCData(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, interval time.Duration, watched bool) (time.Time, error) {
+	var latest time.Time
+	if r.k8sSource == nil || mapping.Spec.SourceNamespace == "" {
+		return latest, nil
+	}
+
+	pvcs, err := r.k8sSource.CoreV1().PersistentVolumeClaims(mapping.Spec.SourceNamespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return latest, fmt.Errorf("failed to list source PVCs: %w", err)
+	}
+
+	want := ""
+	if watched {
+		want = interval.String()
+	}
+	for _, pvc := range pvcs.Items {
+		if current, ok := pvc.Annotations[datawatch.WatchAnnotation]; current != want || ok != watched {
+			patch, err := dataWatchPatch(want)
+			if err != nil {
+				return latest, err
+			}
+			if _, err := r.k8sSource.CoreV1().PersistentVolumeClaims(pvc.Namespace).Patch(ctx, pvc.Name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+				return latest, fmt.Errorf("failed to annotate source PVC %s for data watch: %w", pvc.Name, err)
+			}
+		}
+
+		if !watched {
+			continue
+		}
+		changed, err := time.Parse(time.RFC3339Nano, pvc.Annotations[datawatch.ChangedAnnotation])
+		if err == nil && changed.After(latest) {
+			latest = changed
+		}
+	}
+	return latest, nil
+}
+
+// startDataWatch syncs the mapping when the agents report changes of the source PVC data, at most once
+// per minimum interval
+func (r *ModeReconciler) startDataWatch(ctx, syncCtx context.Context, mapping *drv1alpha1.NamespaceMapping, interval time.Duration) {
+	// Changes reported before the last data watch sync, or before the last sync, are already replicated
+	var handled time.Time
+	if mapping.Status.LastDataChange != nil {
+		handled = mapping.Status.LastDataChange.Time
+	} else if mapping.Status.LastSyncTime != nil {
+		handled = mapping.Status.LastSyncTime.Time
+	}
+
+	pollInterval := dataWatchPollInterval
+	if interval > 0 && interval < pollInterval {
+		pollInterval = interval
+	}
+
+	var lastSync time.Time
+	r.watchManager.StartDataWatch(ctx, pollInterval, func() error {
+		changed, err := r.pollPVCData(ctx, mapping, interval, true)
+		if err != nil {
+			return err
+		}
+		if !changed.After(handled) || time.Since(lastSync) < interval {
+			return nil
+		}
+
+		log.Info(fmt.Sprintf("PVC data in namespace %s changed at %s, syncing mapping '%s'",
+			mapping.Spec.SourceNamespace, changed.Format(time.RFC3339), mapping.Name))
+		lastSync = time.Now()
+		if _, _, err := r.syncResources(syncCtx, mapping); err != nil {
+			return err
+		}
+		handled = changed
+
+		return r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
+			now := metav1.Now()
+			lastDataChange := metav1.NewTime(changed)
+			status.LastSyncTime = &now
+			status.LastDataChange = &lastDataChange
+		})
+	})
+}
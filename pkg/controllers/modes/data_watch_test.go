@@ -0,0 +1,80 @@
+package modes
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/agent/datawatch"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDataWatchInterval(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{}
+	_, watched, err := dataWatchInterval(mapping)
+	require.NoError(t, err)
+	assert.False(t, watched)
+
+	// Without PVC data sync there is no data to watch
+	mapping.Spec.Continuous = &drv1alpha1.ContinuousConfig{DataWatch: &drv1alpha1.DataWatchConfig{Enabled: true}}
+	_, watched, err = dataWatchInterval(mapping)
+	require.NoError(t, err)
+	assert.False(t, watched)
+
+	mapping.Spec.PVCConfig = &drv1alpha1.PVCConfig{SyncData: true}
+	interval, watched, err := dataWatchInterval(mapping)
+	require.NoError(t, err)
+	assert.True(t, watched)
+	assert.Equal(t, defaultDataWatchMinInterval, interval)
+
+	mapping.Spec.Continuous.DataWatch.MinInterval = "2m"
+	interval, _, err = dataWatchInterval(mapping)
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Minute, interval)
+
+	mapping.Spec.Continuous.DataWatch.MinInterval = "2 minutes"
+	_, _, err = dataWatchInterval(mapping)
+	assert.Error(t, err)
+}
+
+func TestPollPVCData(t *testing.T) {
+	ctx := context.Background()
+	sourceClient := fake.NewSimpleClientset(
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app", Annotations: map[string]string{
+			datawatch.ChangedAnnotation: "2025-03-01T12:00:10.5Z",
+		}}},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "app", Annotations: map[string]string{
+			datawatch.WatchAnnotation:   "1m0s",
+			datawatch.ChangedAnnotation: "2025-03-01T12:00:00Z",
+		}}},
+	)
+	r := NewModeReconciler(nil, nil, nil, sourceClient, nil, nil, nil, "source", "destination")
+	mapping := &drv1alpha1.NamespaceMapping{Spec: drv1alpha1.NamespaceMappingSpec{SourceNamespace: "app"}}
+
+	// The source PVCs are annotated for the agents and the latest reported change is returned
+	latest, err := r.pollPVCData(ctx, mapping, 2*time.Minute, true)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, 3, 1, 12, 0, 10, 500000000, time.UTC), latest)
+
+	pvcs, err := sourceClient.CoreV1().PersistentVolumeClaims("app").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for _, pvc := range pvcs.Items {
+		assert.Equal(t, "2m0s", pvc.Annotations[datawatch.WatchAnnotation], pvc.Name)
+	}
+
+	// Disabling the data watch removes the annotation
+	latest, err = r.pollPVCData(ctx, mapping, 0, false)
+	require.NoError(t, err)
+	assert.True(t, latest.IsZero())
+
+	pvcs, err = sourceClient.CoreV1().PersistentVolumeClaims("app").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	for _, pvc := range pvcs.Items {
+		assert.NotContains(t, pvc.Annotations, datawatch.WatchAnnotation, pvc.Name)
+	}
+}
@@ -0,0 +1,46 @@
+package modes
+
+import (
+	"errors"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeDependenciesResolved reports whether the ConfigMaps and Secrets referenced by the synced
+	// workloads were found, in the source namespace or in the namespaces of dependencyConfig
+	ConditionTypeDependenciesResolved = "DependenciesResolved"
+
+	// ReasonDependencyMissing is set when a workload references a ConfigMap or Secret that was found nowhere
+	ReasonDependencyMissing = "DependencyMissing"
+)
+
+// setDependenciesResolvedCondition records the outcome of the dependency resolution for a sync result. The
+// condition is only written once a dependency was missing, flips back to True after a successful sync,
+// and is left alone by unrelated failures.
+func setDependenciesResolvedCondition(status *drv1alpha1.NamespaceMappingStatus, generation int64, syncErr error) {
+	missing := errors.Is(syncErr, syncer.ErrDependencyMissing)
+	if syncErr != nil && !missing {
+		return
+	}
+	if !missing && meta.FindStatusCondition(status.Conditions, ConditionTypeDependenciesResolved) == nil {
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeDependenciesResolved,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DependenciesFound",
+		Message:            "ConfigMaps and Secrets referenced by the synced workloads were found",
+		ObservedGeneration: generation,
+	}
+	if missing {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonDependencyMissing
+		condition.Message = syncErr.Error()
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
@@ -0,0 +1,33 @@
+package modes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetDependenciesResolvedCondition(t *testing.T) {
+	status := &drv1alpha1.NamespaceMappingStatus{}
+
+	// Nothing is written until a dependency is missing
+	setDependenciesResolvedCondition(status, 1, nil)
+	setDependenciesResolvedCondition(status, 1, errors.New("connection refused"))
+	assert.Empty(t, status.Conditions)
+
+	missingErr := fmt.Errorf("failed to sync namespace resources: %w",
+		fmt.Errorf("%w: Secret/wildcard-tls referenced by synced workloads not found", syncer.ErrDependencyMissing))
+	setDependenciesResolvedCondition(status, 2, missingErr)
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypeDependenciesResolved)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonDependencyMissing, condition.Reason)
+	assert.Contains(t, condition.Message, "Secret/wildcard-tls")
+
+	setDependenciesResolvedCondition(status, 3, nil)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeDependenciesResolved))
+}
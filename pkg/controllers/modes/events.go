@@ -0,0 +1,29 @@
+package modes
+
+import (
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"k8s.io/client-go/tools/record"
+)
+
+// Reasons of the events recorded on NamespaceMappings
+const (
+	// EventReasonSyncStarted is recorded when a sync of the mapping's resources starts
+	EventReasonSyncStarted = "SyncStarted"
+	// EventReasonSyncCompleted is recorded when a sync of the mapping's resources succeeds
+	EventReasonSyncCompleted = "SyncCompleted"
+	// EventReasonSyncFailed is recorded when a sync of the mapping's resources fails
+	EventReasonSyncFailed = "SyncFailed"
+)
+
+// SetEventRecorder sets the recorder for events on NamespaceMappings, without one no events are recorded
+func (r *ModeReconciler) SetEventRecorder(recorder record.EventRecorder) {
+	r.recorder = recorder
+}
+
+// recordEvent records an event on a NamespaceMapping
+func (r *ModeReconciler) recordEvent(mapping *drv1alpha1.NamespaceMapping, eventType, reason, messageFmt string, args ...interface{}) {
+	if r.recorder == nil {
+		return
+	}
+	r.recorder.Eventf(mapping, eventType, reason, messageFmt, args...)
+}
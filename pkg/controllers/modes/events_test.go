@@ -0,0 +1,27 @@
+package modes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestRecordEvent(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dr-syncer"}}
+	r := NewModeReconciler(nil, nil, nil, nil, nil, nil, nil, "prod", "dr")
+
+	// Without a recorder events are dropped
+	r.recordEvent(mapping, corev1.EventTypeNormal, EventReasonSyncStarted, "Syncing namespace %s", "app")
+
+	recorder := record.NewFakeRecorder(2)
+	r.SetEventRecorder(recorder)
+	r.recordEvent(mapping, corev1.EventTypeNormal, EventReasonSyncStarted, "Syncing namespace %s", "app")
+	r.recordEvent(mapping, corev1.EventTypeWarning, EventReasonSyncFailed, "Sync failed: %v", "boom")
+
+	assert.Equal(t, "Normal SyncStarted Syncing namespace app", <-recorder.Events)
+	assert.Equal(t, "Warning SyncFailed Sync failed: boom", <-recorder.Events)
+}
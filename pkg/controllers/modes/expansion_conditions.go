@@ -0,0 +1,58 @@
+package modes
+
+import (
+	"fmt"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypePVCExpansionBlocked is True while destination PVCs cannot be expanded to the size of
+	// their source PVCs, only set once a sync found such a PVC
+	ConditionTypePVCExpansionBlocked = "PVCExpansionBlocked"
+
+	// ReasonExpansionNotSupported is set when destination PVCs keep their size as their storage class does
+	// not allow volume expansion
+	ReasonExpansionNotSupported = "ExpansionNotSupported"
+)
+
+// setPVCExpansionBlockedCondition records the destination PVCs of a sync result that could not be expanded.
+// The condition is only written after the first such PVC and flipped back once every PVC could be expanded.
+func setPVCExpansionBlockedCondition(status *drv1alpha1.NamespaceMappingStatus, generation int64, result *syncer.SyncResult) {
+	var blocked []string
+	if result != nil {
+		blocked = result.ExpansionBlocked
+	}
+	if len(blocked) == 0 {
+		if meta.FindStatusCondition(status.Conditions, ConditionTypePVCExpansionBlocked) != nil {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               ConditionTypePVCExpansionBlocked,
+				Status:             metav1.ConditionFalse,
+				Reason:             "Expanded",
+				Message:            "Destination PVCs match the storage requests of their source PVCs",
+				ObservedGeneration: generation,
+			})
+		}
+		return
+	}
+
+	shown := blocked
+	if len(shown) > maxPVCsInMessage {
+		shown = shown[:maxPVCsInMessage]
+	}
+	message := fmt.Sprintf("%d PVCs cannot be expanded: %s", len(blocked), strings.Join(shown, "; "))
+	if len(blocked) > len(shown) {
+		message += fmt.Sprintf(" and %d more", len(blocked)-len(shown))
+	}
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionTypePVCExpansionBlocked,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonExpansionNotSupported,
+		Message:            message,
+		ObservedGeneration: generation,
+	})
+}
@@ -0,0 +1,35 @@
+package modes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetPVCExpansionBlockedCondition(t *testing.T) {
+	status := &drv1alpha1.NamespaceMappingStatus{}
+
+	// No condition until a destination PVC cannot be expanded
+	setPVCExpansionBlockedCondition(status, 1, &syncer.SyncResult{Synced: 3})
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypePVCExpansionBlocked))
+
+	blocked := make([]string, 6)
+	for i := range blocked {
+		blocked[i] = "PVC data: Cannot expand PVC from 10Gi to 20Gi: storage class fixed does not allow volume expansion"
+	}
+	setPVCExpansionBlockedCondition(status, 2, &syncer.SyncResult{ExpansionBlocked: blocked})
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypePVCExpansionBlocked)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonExpansionNotSupported, condition.Reason)
+	assert.Contains(t, condition.Message, "6 PVCs cannot be expanded: PVC data: Cannot expand PVC from 10Gi to 20Gi")
+	assert.Contains(t, condition.Message, "and 1 more")
+
+	setPVCExpansionBlockedCondition(status, 3, &syncer.SyncResult{Synced: 3})
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, ConditionTypePVCExpansionBlocked))
+}
@@ -0,0 +1,281 @@
+package modes
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// FailoverTestAnnotation requests a failover test of a NamespaceMapping: the source namespace is cloned
+	// into a temporary destination namespace with its workloads running, the smoke tests run against the
+	// clone and the namespace is deleted again, the mapping's destination namespace is left untouched
+	FailoverTestAnnotation = "dr-syncer.io/failover-test"
+
+	// FailoverTestLabel marks the temporary namespaces of failover tests
+	FailoverTestLabel = "dr-syncer.io/failover-test"
+
+	// FailoverTestMappingAnnotation records the NamespaceMapping of a temporary failover test namespace
+	FailoverTestMappingAnnotation = "dr-syncer.io/failover-test-mapping"
+
+	// EventReasonFailoverTestPassed is recorded when a failover test passed
+	EventReasonFailoverTestPassed = "FailoverTestPassed"
+
+	// EventReasonFailoverTestFailed is recorded when a failover test failed
+	EventReasonFailoverTestFailed = "FailoverTestFailed"
+
+	// failoverTestSuffix separates the destination namespace from the timestamp in failover test namespaces
+	failoverTestSuffix = "-drtest-"
+
+	// failoverTestWorkloadTimeout bounds the wait for the cloned workloads to become available
+	failoverTestWorkloadTimeout = 10 * time.Minute
+)
+
+// FailoverTestRequested reports whether a failover test was requested for the mapping
+func FailoverTestRequested(mapping *drv1alpha1.NamespaceMapping) bool {
+	_, ok := mapping.Annotations[FailoverTestAnnotation]
+	return ok
+}
+
+// failoverTestNamespace returns the temporary namespace of a failover test started at now, the
+// destination namespace is shortened to keep the name a valid namespace name
+func failoverTestNamespace(dstNamespace string, now time.Time) string {
+	suffix := failoverTestSuffix + strconv.FormatInt(now.Unix(), 10)
+	if max := 63 - len(suffix); len(dstNamespace) > max {
+		dstNamespace = strings.TrimRight(dstNamespace[:max], "-")
+	}
+	return dstNamespace + suffix
+}
+
+// failoverTester runs the workload checks of a failover test in its temporary namespace
+type failoverTester struct {
+	client    kubernetes.Interface
+	namespace string
+
+	// pollInterval is how often the availability of the workloads is checked
+	pollInterval time.Duration
+
+	// timeout bounds the wait for the workloads to become available
+	timeout time.Duration
+}
+
+// createNamespace creates the temporary namespace, labeled as managed by dr-syncer so the syncer writes into it
+func (f *failoverTester) createNamespace(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) error {
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: f.namespace,
+			Labels: map[string]string{
+				utils.ManagedByLabel: utils.ManagedByValue,
+				FailoverTestLabel:    "true",
+			},
+			Annotations: map[string]string{
+				FailoverTestMappingAnnotation: mapping.Namespace + "/" + mapping.Name,
+			},
+		},
+	}
+	if _, err := f.client.CoreV1().Namespaces().Create(ctx, namespace, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("failed to create failover test namespace %s: %w", f.namespace, err)
+	}
+	return nil
+}
+
+// deleteNamespace deletes the temporary namespace with everything cloned into it
+func (f *failoverTester) deleteNamespace(ctx context.Context) error {
+	propagation := metav1.DeletePropagationBackground
+	err := f.client.CoreV1().Namespaces().Delete(ctx, f.namespace, metav1.DeleteOptions{PropagationPolicy: &propagation})
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete failover test namespace %s: %w", f.namespace, err)
+	}
+	return nil
+}
+
+// waitForWorkloads waits until the Deployments and StatefulSets of the temporary namespace are available,
+// naming the ones that are not when the timeout expires
+func (f *failoverTester) waitForWorkloads(ctx context.Context) error {
+	var pending []string
+	err := wait.PollUntilContextTimeout(ctx, f.pollInterval, f.timeout, true, func(ctx context.Context) (bool, error) {
+		pending = nil
+		deployments, err := f.client.AppsV1().Deployments(f.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, nil
+		}
+		for _, deployment := range deployments.Items {
+			if deployment.Status.AvailableReplicas < ptr.Deref(deployment.Spec.Replicas, 1) {
+				pending = append(pending, "Deployment/"+deployment.Name)
+			}
+		}
+		statefulSets, err := f.client.AppsV1().StatefulSets(f.namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return false, nil
+		}
+		for _, statefulSet := range statefulSets.Items {
+			if statefulSet.Status.ReadyReplicas < ptr.Deref(statefulSet.Spec.Replicas, 1) {
+				pending = append(pending, "StatefulSet/"+statefulSet.Name)
+			}
+		}
+		return len(pending) == 0, nil
+	})
+	if err == nil {
+		return nil
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("workloads did not become available within %s: %s", f.timeout, strings.Join(pending, ", "))
+	}
+	return fmt.Errorf("failed to check the workloads: %w", err)
+}
+
+// failoverTestSpec returns the spec a failover test clones the source namespace with. The workloads run,
+// CronJobs stay suspended, and Ingresses and fixed NodePorts are left out so the clone does not take
+// traffic or ports from the mapping's destination namespace.
+func failoverTestSpec(mapping *drv1alpha1.NamespaceMapping) (*drv1alpha1.NamespaceMappingSpec, []string) {
+	spec := mapping.Spec.DeepCopy()
+	spec.SuspendCronJobs = ptr.To(true)
+	spec.PreserveNodePorts = ptr.To(false)
+	spec.ExcludedResourceTypes = append(spec.ExcludedResourceTypes, "ingresses")
+
+	var resourceTypes []string
+	for _, resourceType := range mappingResourceTypes(mapping) {
+		if resourceType != "ingresses" && resourceType != "ingress" {
+			resourceTypes = append(resourceTypes, resourceType)
+		}
+	}
+	return spec, resourceTypes
+}
+
+// RunFailoverTest runs the failover test requested by the failover-test annotation. The annotation is
+// removed first so a test runs once, the result is recorded in status.lastFailoverTest.
+func (r *ModeReconciler) RunFailoverTest(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) error {
+	requestedBy := manualTriggerRequestor(mapping, FailoverTestAnnotation)
+	patch := client.MergeFrom(mapping.DeepCopy())
+	delete(mapping.Annotations, FailoverTestAnnotation)
+	delete(mapping.Annotations, SyncRequestedByAnnotation)
+	if err := r.Patch(ctx, mapping, patch); err != nil {
+		return fmt.Errorf("failed to clear failover test annotation: %w", err)
+	}
+	if r.k8sDest == nil {
+		return fmt.Errorf("failover test requires a destination client")
+	}
+
+	dstNamespace := mapping.Spec.DestinationNamespace
+	if dstNamespace == "" {
+		dstNamespace = mapping.Spec.SourceNamespace
+	}
+	start := metav1.Now()
+	test := &drv1alpha1.FailoverTest{
+		RequestedBy: requestedBy,
+		Namespace:   failoverTestNamespace(dstNamespace, start.Time),
+		StartTime:   start,
+		Message:     "Failover test is running",
+	}
+	log.Info(fmt.Sprintf("starting failover test of mapping '%s' (requested by %s) in namespace %s", mapping.Name, requestedBy, test.Namespace))
+	if err := r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
+		status.LastFailoverTest = test.DeepCopy()
+	}); err != nil {
+		return err
+	}
+
+	tester := &failoverTester{
+		client:       r.k8sDest,
+		namespace:    test.Namespace,
+		pollInterval: 5 * time.Second,
+		timeout:      failoverTestWorkloadTimeout,
+	}
+	err := r.runFailoverTest(ctx, mapping, tester, test)
+
+	// The namespace is deleted whatever the outcome, a test never leaves a running clone behind
+	if deleteErr := tester.deleteNamespace(context.Background()); deleteErr != nil {
+		log.Errorf("%v", deleteErr)
+		if err == nil {
+			err = deleteErr
+		}
+	}
+
+	completion := metav1.Now()
+	test.CompletionTime = &completion
+	test.Passed = err == nil
+	if err != nil {
+		test.Message = err.Error()
+		r.recordEvent(mapping, corev1.EventTypeWarning, EventReasonFailoverTestFailed, "Failover test in namespace %s failed: %v", test.Namespace, err)
+	} else {
+		test.Message = fmt.Sprintf("Namespace cloned, workloads available and %d smoke tests passed in %s",
+			len(test.SmokeTests), formatDuration(completion.Sub(start.Time)))
+		r.recordEvent(mapping, corev1.EventTypeNormal, EventReasonFailoverTestPassed, "Failover test in namespace %s passed", test.Namespace)
+	}
+	log.Info(fmt.Sprintf("failover test of mapping '%s' finished, passed: %v, %s", mapping.Name, test.Passed, test.Message))
+
+	return r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
+		status.LastFailoverTest = test
+	})
+}
+
+// runFailoverTest clones the source namespace into the test namespace with its workloads running, waits
+// for them and runs the smoke tests, recording their results in test
+func (r *ModeReconciler) runFailoverTest(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, tester *failoverTester, test *drv1alpha1.FailoverTest) error {
+	if err := tester.createNamespace(ctx, mapping); err != nil {
+		return err
+	}
+
+	spec, resourceTypes := failoverTestSpec(mapping)
+	_, err := syncer.SyncNamespaceResources(
+		ctx,
+		r.k8sSource,
+		r.k8sDest,
+		r.sourceClient,
+		r.destClient,
+		r.Client,
+		mapping.Spec.SourceNamespace,
+		test.Namespace,
+		resourceTypes,
+		false,
+		spec.NamespaceScopedResources,
+		spec.PVCConfig,
+		spec.ImmutableResourceConfig,
+		spec,
+		r.sourceConfig,
+		r.destConfig,
+	)
+	if partial, ok := syncerrors.AsPartialSyncError(err); ok {
+		return fmt.Errorf("failed to clone %d resources: %w", len(partial.Failures), err)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to clone namespace: %w", err)
+	}
+
+	if err := tester.waitForWorkloads(ctx); err != nil {
+		return err
+	}
+
+	if mapping.Spec.Verification == nil || len(mapping.Spec.Verification.SmokeTests) == 0 {
+		return nil
+	}
+	runner := newSmokeTestRunner(r.k8sDest, r.destConfig, test.Namespace, mapping.Spec.Verification)
+	test.SmokeTests = runner.run(ctx, mapping.Spec.Verification.SmokeTests)
+	if message := smokeTestFailureMessage(test.SmokeTests); message != "" {
+		return fmt.Errorf("%s", message)
+	}
+	return nil
+}
+
+// failoverTestEqual compares two failover test records
+func failoverTestEqual(a, b *drv1alpha1.FailoverTest) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Namespace == b.Namespace && a.RequestedBy == b.RequestedBy && a.Passed == b.Passed &&
+		a.Message == b.Message && a.StartTime.Equal(&b.StartTime) && timeEqual(a.CompletionTime, b.CompletionTime) &&
+		smokeTestsEqual(a.SmokeTests, b.SmokeTests)
+}
@@ -0,0 +1,98 @@
+package modes
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestFailoverTestNamespace(t *testing.T) {
+	now := time.Unix(1760000000, 0)
+	assert.Equal(t, "app-dr-drtest-1760000000", failoverTestNamespace("app-dr", now))
+
+	long := failoverTestNamespace(strings.Repeat("a", 40)+"-"+strings.Repeat("b", 30), now)
+	assert.LessOrEqual(t, len(long), 63)
+	assert.True(t, strings.HasSuffix(long, "-drtest-1760000000"))
+	assert.NotContains(t, long, "--")
+}
+
+func TestFailoverTestSpec(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{Spec: drv1alpha1.NamespaceMappingSpec{
+		ResourceTypes:   []string{"Deployments", "Ingresses", "services"},
+		SuspendCronJobs: ptr.To(false),
+	}}
+
+	spec, resourceTypes := failoverTestSpec(mapping)
+	assert.Equal(t, []string{"deployments", "services"}, resourceTypes)
+	assert.True(t, *spec.SuspendCronJobs)
+	assert.False(t, *spec.PreserveNodePorts)
+	assert.Contains(t, spec.ExcludedResourceTypes, "ingresses")
+	assert.False(t, *mapping.Spec.SuspendCronJobs, "the mapping's spec is left unchanged")
+}
+
+func TestFailoverTesterNamespaceLifecycle(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	tester := &failoverTester{client: client, namespace: "app-dr-drtest-1"}
+	mapping := &drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "dr-syncer"}}
+
+	require.NoError(t, tester.createNamespace(context.Background(), mapping))
+	namespace, err := client.CoreV1().Namespaces().Get(context.Background(), "app-dr-drtest-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, utils.ManagedByValue, namespace.Labels[utils.ManagedByLabel])
+	assert.Equal(t, "true", namespace.Labels[FailoverTestLabel])
+	assert.Equal(t, "dr-syncer/app", namespace.Annotations[FailoverTestMappingAnnotation])
+
+	require.NoError(t, tester.deleteNamespace(context.Background()))
+	_, err = client.CoreV1().Namespaces().Get(context.Background(), "app-dr-drtest-1", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NoError(t, tester.deleteNamespace(context.Background()), "a deleted namespace is not an error")
+}
+
+func TestFailoverTesterWaitForWorkloads(t *testing.T) {
+	available := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "test"},
+		Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+		Status:     appsv1.DeploymentStatus{AvailableReplicas: 2},
+	}
+	pending := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "test"},
+		Spec:       appsv1.StatefulSetSpec{Replicas: ptr.To(int32(1))},
+	}
+
+	tester := &failoverTester{
+		client:       fake.NewSimpleClientset(available),
+		namespace:    "test",
+		pollInterval: time.Millisecond,
+		timeout:      50 * time.Millisecond,
+	}
+	assert.NoError(t, tester.waitForWorkloads(context.Background()))
+
+	tester.client = fake.NewSimpleClientset(available, pending)
+	err := tester.waitForWorkloads(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "StatefulSet/db")
+	assert.NotContains(t, err.Error(), "Deployment/web")
+}
+
+func TestFailoverTestEqual(t *testing.T) {
+	start := metav1.Now()
+	a := &drv1alpha1.FailoverTest{Namespace: "app-dr-drtest-1", StartTime: start}
+	b := a.DeepCopy()
+	assert.True(t, failoverTestEqual(a, b))
+
+	b.Passed = true
+	assert.False(t, failoverTestEqual(a, b))
+	assert.False(t, failoverTestEqual(a, nil))
+	assert.True(t, failoverTestEqual(nil, nil))
+}
@@ -0,0 +1,89 @@
+package modes
+
+import (
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// retryResources returns the resources that failed in the previous sync of a scheduled mapping, nil
+// when the next sync must sync the whole namespace
+func retryResources(mapping *drv1alpha1.NamespaceMapping, now time.Time) []string {
+	if mapping.Status.Phase != drv1alpha1.SyncPhaseFailed || mapping.Status.LastError == nil {
+		return nil
+	}
+	// The namespace is synced in full again once the schedule is due after the failure
+	if schedule, _, err := ParseSchedule(&mapping.Spec); err != nil || !schedule.Next(mapping.Status.LastError.Time.Time).After(now) {
+		return nil
+	}
+
+	var resources []string
+	for _, failure := range mapping.Status.FailedResources {
+		if failure.Resource != "" {
+			resources = append(resources, failure.Resource)
+		}
+	}
+	return resources
+}
+
+// failedResources converts the failures of a partial sync to the status of the mapping
+func failedResources(partial *syncerrors.PartialSyncError, now metav1.Time) []drv1alpha1.SyncError {
+	failed := make([]drv1alpha1.SyncError, 0, len(partial.Failures))
+	for _, failure := range partial.Failures {
+		failed = append(failed, drv1alpha1.SyncError{
+			Message:  failure.Error(),
+			Resource: failure.Resource,
+			Time:     now,
+		})
+	}
+	return failed
+}
+
+// syncStats returns the statistics of a sync. A sync retrying failed resources only adds its results to
+// the statistics of the sync it retries.
+func syncStats(previous *drv1alpha1.SyncStats, result *syncer.SyncResult, retried bool, duration time.Duration) *drv1alpha1.SyncStats {
+	stats := &drv1alpha1.SyncStats{
+		TotalResources:     int32(result.Synced + result.Failed),
+		SuccessfulSyncs:    int32(result.Synced),
+		FailedSyncs:        int32(result.Failed),
+		PVCDataSyncs:       int32(result.PVCDataSynced),
+		FailedPVCDataSyncs: int32(result.PVCDataFailed),
+		LastSyncDuration:   formatDuration(duration),
+	}
+	if retried && previous != nil && previous.TotalResources >= stats.FailedSyncs {
+		stats.TotalResources = previous.TotalResources
+		stats.SuccessfulSyncs = previous.TotalResources - stats.FailedSyncs
+	}
+	return stats
+}
+
+// mergeDeploymentScales adds the scales recorded by a retry to the scales of the sync it retries
+func mergeDeploymentScales(previous, retried []drv1alpha1.DeploymentScale) []drv1alpha1.DeploymentScale {
+	merged := make([]drv1alpha1.DeploymentScale, 0, len(previous)+len(retried))
+	replaced := make(map[string]bool, len(retried))
+	for _, scale := range retried {
+		replaced[scale.Name] = true
+	}
+	for _, scale := range previous {
+		if !replaced[scale.Name] {
+			merged = append(merged, scale)
+		}
+	}
+	return append(merged, retried...)
+}
+
+// failedResourcesEqual compares the failed resources of two statuses
+func failedResourcesEqual(a, b []drv1alpha1.SyncError) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !syncErrorEqual(&a[i], &b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,49 @@
+package modes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestRetryResources(t *testing.T) {
+	failedAt := time.Date(2025, 3, 1, 12, 5, 0, 0, time.UTC)
+	mapping := &drv1alpha1.NamespaceMapping{
+		Spec: drv1alpha1.NamespaceMappingSpec{Schedule: "0 * * * *"},
+		Status: drv1alpha1.NamespaceMappingStatus{
+			Phase:     drv1alpha1.SyncPhaseFailed,
+			LastError: &drv1alpha1.SyncError{Time: metav1.NewTime(failedAt)},
+			FailedResources: []drv1alpha1.SyncError{
+				{Resource: "ConfigMap/settings"},
+				{Resource: "Deployment/web"},
+			},
+		},
+	}
+
+	// The failed resources are retried until the schedule is due again
+	assert.Equal(t, []string{"ConfigMap/settings", "Deployment/web"}, retryResources(mapping, failedAt.Add(time.Minute)))
+	assert.Nil(t, retryResources(mapping, failedAt.Add(time.Hour)))
+
+	mapping.Status.Phase = drv1alpha1.SyncPhaseCompleted
+	assert.Nil(t, retryResources(mapping, failedAt.Add(time.Minute)))
+}
+
+func TestSyncStats(t *testing.T) {
+	result := &syncer.SyncResult{Synced: 8, Failed: 2}
+	stats := syncStats(nil, result, false, 90*time.Second)
+	assert.Equal(t, &drv1alpha1.SyncStats{TotalResources: 10, SuccessfulSyncs: 8, FailedSyncs: 2, LastSyncDuration: "1m30s"}, stats)
+
+	// A retry of one of the two failed resources adds to the previous sync
+	retry := syncStats(stats, &syncer.SyncResult{Synced: 1, Failed: 1}, true, 5*time.Second)
+	assert.Equal(t, &drv1alpha1.SyncStats{TotalResources: 10, SuccessfulSyncs: 9, FailedSyncs: 1, LastSyncDuration: "5s"}, retry)
+}
+
+func TestMergeDeploymentScales(t *testing.T) {
+	previous := []drv1alpha1.DeploymentScale{{Name: "api", OriginalReplicas: 2}, {Name: "web", OriginalReplicas: 1}}
+	merged := mergeDeploymentScales(previous, []drv1alpha1.DeploymentScale{{Name: "web", OriginalReplicas: 3}})
+	assert.Equal(t, []drv1alpha1.DeploymentScale{{Name: "api", OriginalReplicas: 2}, {Name: "web", OriginalReplicas: 3}}, merged)
+}
@@ -0,0 +1,73 @@
+package modes
+
+import (
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/watch"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// impersonationFor returns the identity impersonated for a mapping's destination writes
+func impersonationFor(mapping *drv1alpha1.NamespaceMapping) (rest.ImpersonationConfig, error) {
+	impersonation := mapping.Spec.DestinationImpersonation
+	switch {
+	case impersonation.User != "" && impersonation.ServiceAccount != nil:
+		return rest.ImpersonationConfig{}, fmt.Errorf("destinationImpersonation must set only one of user and serviceAccount")
+	case impersonation.User != "":
+		return rest.ImpersonationConfig{UserName: impersonation.User, Groups: impersonation.Groups}, nil
+	case impersonation.ServiceAccount != nil && impersonation.ServiceAccount.Name != "":
+		if len(impersonation.Groups) > 0 {
+			return rest.ImpersonationConfig{}, fmt.Errorf("destinationImpersonation groups can only be set with user")
+		}
+		namespace := impersonation.ServiceAccount.Namespace
+		if namespace == "" {
+			namespace = mapping.Spec.DestinationNamespace
+		}
+		if namespace == "" {
+			namespace = mapping.Spec.SourceNamespace
+		}
+		// The API server adds the ServiceAccount groups when impersonating a ServiceAccount user
+		return rest.ImpersonationConfig{UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, impersonation.ServiceAccount.Name)}, nil
+	default:
+		return rest.ImpersonationConfig{}, fmt.Errorf("destinationImpersonation must set user or serviceAccount")
+	}
+}
+
+// ImpersonateDestination replaces the destination clients with clients impersonating the mapping's
+// destinationImpersonation, so RBAC on the destination cluster constrains every write of the mapping
+func (r *ModeReconciler) ImpersonateDestination(mapping *drv1alpha1.NamespaceMapping) error {
+	if mapping.Spec.DestinationImpersonation == nil {
+		return nil
+	}
+	impersonation, err := impersonationFor(mapping)
+	if err != nil {
+		return err
+	}
+
+	if r.destConfig == nil {
+		// Never write with clients that cannot be constrained to the impersonated identity
+		return fmt.Errorf("destination impersonation requires the destination REST config")
+	}
+
+	config := rest.CopyConfig(r.destConfig)
+	config.Impersonate = impersonation
+
+	destClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create impersonating destination dynamic client: %w", err)
+	}
+	k8sDest, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create impersonating destination client: %w", err)
+	}
+
+	log.Info(fmt.Sprintf("impersonating %s for writes to destination cluster %s", impersonation.UserName, r.destClusterName))
+	r.destConfig = config
+	r.destClient = destClient
+	r.k8sDest = k8sDest
+	r.watchManager = watch.NewWatchManager(r.sourceClient, destClient)
+	return nil
+}
@@ -0,0 +1,69 @@
+package modes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func impersonatingMapping(impersonation *drv1alpha1.ImpersonationConfig) *drv1alpha1.NamespaceMapping {
+	return &drv1alpha1.NamespaceMapping{Spec: drv1alpha1.NamespaceMappingSpec{
+		SourceNamespace:          "shop",
+		DestinationNamespace:     "shop-dr",
+		DestinationImpersonation: impersonation,
+	}}
+}
+
+func TestImpersonationFor(t *testing.T) {
+	config, err := impersonationFor(impersonatingMapping(&drv1alpha1.ImpersonationConfig{
+		ServiceAccount: &drv1alpha1.ServiceAccountReference{Name: "dr-writer"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, rest.ImpersonationConfig{UserName: "system:serviceaccount:shop-dr:dr-writer"}, config)
+
+	config, err = impersonationFor(impersonatingMapping(&drv1alpha1.ImpersonationConfig{
+		ServiceAccount: &drv1alpha1.ServiceAccountReference{Name: "dr-writer", Namespace: "dr-syncer"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "system:serviceaccount:dr-syncer:dr-writer", config.UserName)
+
+	config, err = impersonationFor(impersonatingMapping(&drv1alpha1.ImpersonationConfig{User: "team-shop", Groups: []string{"dr-writers"}}))
+	require.NoError(t, err)
+	assert.Equal(t, rest.ImpersonationConfig{UserName: "team-shop", Groups: []string{"dr-writers"}}, config)
+
+	invalid := []*drv1alpha1.ImpersonationConfig{
+		{},
+		{User: "team-shop", ServiceAccount: &drv1alpha1.ServiceAccountReference{Name: "dr-writer"}},
+		{ServiceAccount: &drv1alpha1.ServiceAccountReference{Name: "dr-writer"}, Groups: []string{"dr-writers"}},
+	}
+	for _, impersonation := range invalid {
+		_, err := impersonationFor(impersonatingMapping(impersonation))
+		assert.Error(t, err)
+	}
+}
+
+func TestImpersonateDestination(t *testing.T) {
+	mapping := impersonatingMapping(&drv1alpha1.ImpersonationConfig{ServiceAccount: &drv1alpha1.ServiceAccountReference{Name: "dr-writer"}})
+
+	r := NewModeReconciler(nil, nil, nil, nil, nil, nil, &rest.Config{Host: "https://dr.example.com"}, "source", "destination")
+	require.NoError(t, r.ImpersonateDestination(mapping))
+	assert.Equal(t, "system:serviceaccount:shop-dr:dr-writer", r.destConfig.Impersonate.UserName)
+	assert.NotNil(t, r.destClient)
+	assert.NotNil(t, r.k8sDest)
+
+	// Clients without a config cannot be constrained, so they are refused
+	r = NewModeReconciler(nil, nil, nil, nil, fake.NewSimpleClientset(), nil, nil, "source", "destination")
+	assert.Error(t, r.ImpersonateDestination(mapping))
+
+	// Without a config there is nothing to impersonate with
+	r = NewModeReconciler(nil, nil, nil, nil, nil, nil, nil, "source", "destination")
+	assert.Error(t, r.ImpersonateDestination(mapping))
+
+	// Mappings without impersonation keep their clients
+	r = NewModeReconciler(nil, nil, nil, nil, fake.NewSimpleClientset(), nil, nil, "source", "destination")
+	assert.NoError(t, r.ImpersonateDestination(impersonatingMapping(nil)))
+}
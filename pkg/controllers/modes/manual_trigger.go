@@ -0,0 +1,123 @@
+package modes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// SyncNowAnnotation requests an immediate sync of a NamespaceMapping in any replication mode
+	SyncNowAnnotation = "dr-syncer.io/sync-now"
+
+	// TriggerSyncAnnotation is the deprecated form of SyncNowAnnotation
+	TriggerSyncAnnotation = "dr-syncer.io/trigger-sync"
+
+	// SyncRequestedByAnnotation optionally identifies who requested the sync
+	SyncRequestedByAnnotation = "dr-syncer.io/sync-requested-by"
+)
+
+// manualTriggerAnnotation returns the sync trigger annotation set on the mapping, if any
+func manualTriggerAnnotation(mapping *drv1alpha1.NamespaceMapping) (string, bool) {
+	if _, ok := mapping.Annotations[SyncNowAnnotation]; ok {
+		return SyncNowAnnotation, true
+	}
+	if _, ok := mapping.Annotations[TriggerSyncAnnotation]; ok {
+		log.Info(fmt.Sprintf("using deprecated %s annotation for mapping '%s', please use %s instead",
+			TriggerSyncAnnotation, mapping.Name, SyncNowAnnotation))
+		return TriggerSyncAnnotation, true
+	}
+	return "", false
+}
+
+// manualTriggerRequestor determines who set the trigger annotation. The sync-requested-by
+// annotation takes precedence, otherwise the most recent field manager owning the
+// trigger annotation is used.
+func manualTriggerRequestor(mapping *drv1alpha1.NamespaceMapping, annotation string) string {
+	if requestedBy := mapping.Annotations[SyncRequestedByAnnotation]; requestedBy != "" {
+		return requestedBy
+	}
+
+	field := fmt.Sprintf("\"f:%s\"", annotation)
+	requestor := ""
+	var latest metav1.Time
+	for _, entry := range mapping.ManagedFields {
+		if entry.FieldsV1 == nil || !strings.Contains(string(entry.FieldsV1.Raw), field) {
+			continue
+		}
+		if requestor == "" || (entry.Time != nil && latest.Before(entry.Time)) {
+			requestor = entry.Manager
+			if entry.Time != nil {
+				latest = *entry.Time
+			}
+		}
+	}
+	if requestor == "" {
+		return "unknown"
+	}
+	return requestor
+}
+
+// manualTrigger is a sync requested with a trigger annotation
+type manualTrigger struct {
+	annotation string
+	record     *drv1alpha1.ManualTrigger
+}
+
+// pendingManualTrigger returns the sync requested by a trigger annotation on the mapping, nil when no
+// sync was requested. The annotation stays on the mapping until acknowledgeManualTrigger removes it.
+func pendingManualTrigger(mapping *drv1alpha1.NamespaceMapping) *manualTrigger {
+	annotation, ok := manualTriggerAnnotation(mapping)
+	if !ok {
+		return nil
+	}
+
+	now := metav1.Now()
+	trigger := &manualTrigger{
+		annotation: annotation,
+		record: &drv1alpha1.ManualTrigger{
+			RequestedBy: manualTriggerRequestor(mapping, annotation),
+			Value:       mapping.Annotations[annotation],
+			TriggeredAt: &now,
+		},
+	}
+
+	log.Info(fmt.Sprintf("detected %s annotation for mapping '%s' (requested by %s), triggering immediate sync from phase %s",
+		annotation, mapping.Name, trigger.record.RequestedBy, mapping.Status.Phase))
+	return trigger
+}
+
+// acknowledgeManualTrigger is called once the sync requested by trigger succeeded. It removes the trigger
+// annotations so the request is processed only once and records the request in status.lastManualTrigger.
+// A failed sync keeps the annotations, so the request is retried. A nil trigger is ignored.
+func (r *ModeReconciler) acknowledgeManualTrigger(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, trigger *manualTrigger) error {
+	if trigger == nil {
+		return nil
+	}
+
+	var latest drv1alpha1.NamespaceMapping
+	if err := r.Get(ctx, client.ObjectKeyFromObject(mapping), &latest); err != nil {
+		return fmt.Errorf("failed to get mapping to clear sync trigger annotation: %w", err)
+	}
+
+	// A trigger set again with another value while the sync ran requests another sync, and is kept
+	if value, ok := latest.Annotations[trigger.annotation]; ok && value == trigger.record.Value {
+		// Clear the annotations with a merge patch so concurrent spec or status changes are not overwritten
+		patch := client.MergeFrom(latest.DeepCopy())
+		delete(latest.Annotations, SyncNowAnnotation)
+		delete(latest.Annotations, TriggerSyncAnnotation)
+		delete(latest.Annotations, SyncRequestedByAnnotation)
+		if err := r.Patch(ctx, &latest, patch); err != nil {
+			return fmt.Errorf("failed to clear sync trigger annotation: %w", err)
+		}
+		mapping.Annotations = latest.Annotations
+	}
+
+	return r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
+		status.LastManualTrigger = trigger.record
+	})
+}
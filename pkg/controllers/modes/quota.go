@@ -0,0 +1,45 @@
+package modes
+
+import (
+	"errors"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeQuotaExceeded reports whether the source namespace exceeds the limits of the mapping
+	ConditionTypeQuotaExceeded = "QuotaExceeded"
+
+	// ReasonQuotaExceeded is set when a sync was refused because the source namespace exceeds spec.limits
+	ReasonQuotaExceeded = "QuotaExceeded"
+)
+
+// setQuotaExceededCondition records the outcome of the limit check for a sync result. The condition is
+// only written once a limit was exceeded, flips to False after a successful sync, and is left alone by
+// unrelated failures.
+func setQuotaExceededCondition(status *drv1alpha1.NamespaceMappingStatus, generation int64, syncErr error) {
+	exceeded := errors.Is(syncErr, syncer.ErrQuotaExceeded)
+	if syncErr != nil && !exceeded {
+		return
+	}
+	if !exceeded && meta.FindStatusCondition(status.Conditions, ConditionTypeQuotaExceeded) == nil {
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeQuotaExceeded,
+		Status:             metav1.ConditionFalse,
+		Reason:             "WithinLimits",
+		Message:            "The source namespace is within the limits of the mapping",
+		ObservedGeneration: generation,
+	}
+	if exceeded {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonQuotaExceeded
+		condition.Message = syncErr.Error()
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
@@ -0,0 +1,36 @@
+package modes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetQuotaExceededCondition(t *testing.T) {
+	status := &drv1alpha1.NamespaceMappingStatus{}
+
+	// Nothing is written until a limit is exceeded
+	setQuotaExceededCondition(status, 1, nil)
+	setQuotaExceededCondition(status, 1, errors.New("connection refused"))
+	assert.Empty(t, status.Conditions)
+
+	quotaErr := fmt.Errorf("failed to sync namespace resources: %w",
+		fmt.Errorf("%w in source namespace app: 120000 configmaps exceed maxObjectsPerKind 1000", syncer.ErrQuotaExceeded))
+	setQuotaExceededCondition(status, 2, quotaErr)
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypeQuotaExceeded)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonQuotaExceeded, condition.Reason)
+	assert.Contains(t, condition.Message, "maxObjectsPerKind 1000")
+
+	// Unrelated failures leave it alone, a successful sync clears it
+	setQuotaExceededCondition(status, 3, errors.New("connection refused"))
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeQuotaExceeded))
+	setQuotaExceededCondition(status, 3, nil)
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, ConditionTypeQuotaExceeded))
+}
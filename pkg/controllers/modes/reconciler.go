@@ -3,22 +3,26 @@ package modes
 import (
 	"context"
 	"fmt"
+	"maps"
 	"math"
 	"strings"
 	"time"
 
-	"github.com/robfig/cron/v3"
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
 	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
 	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
 	"github.com/supporttools/dr-syncer/pkg/controllers/watch"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -42,6 +46,7 @@ type ModeReconciler struct {
 	watchManager      *watch.WatchManager
 	sourceClusterName string
 	destClusterName   string
+	recorder          record.EventRecorder
 }
 
 // NewModeReconciler creates a new ModeReconciler
@@ -76,9 +81,13 @@ func (r *ModeReconciler) ReconcileScheduled(ctx context.Context, mapping *drv1al
 		mapping.Spec.SourceCluster, mapping.Spec.SourceNamespace,
 		mapping.Spec.DestinationCluster, mapping.Spec.DestinationNamespace))
 
+	// A manual trigger runs the sync immediately, the next sync time is then recalculated from the schedule
+	trigger := pendingManualTrigger(mapping)
+	triggered := trigger != nil
+
 	// Check if we should skip this reconciliation because we already synced and next sync time is in the future
 	// This prevents status update watch events from triggering unnecessary reconciliations
-	if mapping.Status.Phase == drv1alpha1.SyncPhaseCompleted && mapping.Status.NextSyncTime != nil {
+	if !triggered && mapping.Status.Phase == drv1alpha1.SyncPhaseCompleted && mapping.Status.NextSyncTime != nil {
 		timeUntilNextSync := time.Until(mapping.Status.NextSyncTime.Time)
 		if timeUntilNextSync > 0 {
 			log.Info(fmt.Sprintf("skipping reconciliation for mapping '%s': already synced, next sync in %s",
@@ -96,10 +105,15 @@ func (r *ModeReconciler) ReconcileScheduled(ctx context.Context, mapping *drv1al
 		return ctrl.Result{}, err
 	}
 
+	// After a partial failure only the failed resources are synced again, until the schedule is due
+	syncCtx := ctx
+	if retry := retryResources(mapping, time.Now()); !triggered && len(retry) > 0 {
+		log.Info(fmt.Sprintf("retrying %d resources that failed to sync for mapping '%s'", len(retry), mapping.Name))
+		syncCtx = syncer.WithRetryResources(ctx, retry)
+	}
+
 	// Sync resources
-	startTime := time.Now()
-	deploymentScales, err := r.syncResources(ctx, mapping)
-	syncDuration := time.Since(startTime)
+	deploymentScales, stats, err := r.syncResources(syncCtx, mapping)
 
 	if err != nil {
 		log.Errorf("failed to sync resources: %v", err)
@@ -125,12 +139,11 @@ func (r *ModeReconciler) ReconcileScheduled(ctx context.Context, mapping *drv1al
 		status.Phase = drv1alpha1.SyncPhaseCompleted
 		status.LastSyncTime = &now
 		status.DeploymentScales = deploymentScales
-		status.SyncStats = &drv1alpha1.SyncStats{
-			TotalResources:   int32(len(deploymentScales)),
-			SuccessfulSyncs:  int32(len(deploymentScales)),
-			FailedSyncs:      0,
-			LastSyncDuration: formatDuration(syncDuration),
-		}
+		setVerifiedCondition(status, mapping.Generation, mapping.Status.Verification)
+		setSmokeTestsCondition(status, mapping)
+		status.SyncStats = stats
+		status.FailedResources = nil
+		setStageConditions(status, mapping, nil)
 
 		// Update the Synced condition
 		syncedCondition := metav1.Condition{
@@ -157,27 +170,30 @@ func (r *ModeReconciler) ReconcileScheduled(ctx context.Context, mapping *drv1al
 		conditions = append(conditions, syncedCondition)
 		status.Conditions = conditions
 
-		// Get schedule with default
-		schedule := mapping.Spec.Schedule
-		if schedule == "" {
+		// Get schedule with default, evaluated in the mapping's timezone
+		if mapping.Spec.Schedule == "" {
 			log.Info(fmt.Sprintf("no schedule specified, using default: %s", DefaultSchedule))
-			schedule = DefaultSchedule
 		}
 
-		cronSchedule, err := cron.ParseStandard(schedule)
+		cronSchedule, location, err := ParseSchedule(&mapping.Spec)
 		if err != nil {
-			log.Errorf("invalid schedule: %s, using default interval of 5m: %v", schedule, err)
+			log.Errorf("%v, using default interval of 5m", err)
 			status.NextSyncTime = &metav1.Time{Time: time.Now().Add(5 * time.Minute)}
+			status.NextSyncTimeLocal = ""
 		} else {
 			// Calculate exact next run time
 			now := time.Now()
 			nextRun := cronSchedule.Next(now)
 			status.NextSyncTime = &metav1.Time{Time: nextRun}
-			log.Info(fmt.Sprintf("next sync scheduled for %s", nextRun.Format(time.RFC3339)))
+			status.NextSyncTimeLocal = nextRun.In(location).Format(time.RFC3339)
+			log.Info(fmt.Sprintf("next sync scheduled for %s", status.NextSyncTimeLocal))
 		}
 	}); err != nil {
 		return ctrl.Result{}, err
 	}
+	if err := r.acknowledgeManualTrigger(ctx, mapping, trigger); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// Use the same next sync time for requeue
 	if mapping.Status.NextSyncTime == nil {
@@ -209,12 +225,77 @@ func (r *ModeReconciler) ReconcileContinuous(ctx context.Context, mapping *drv1a
 		mapping.Spec.SourceCluster, mapping.Spec.SourceNamespace,
 		mapping.Spec.DestinationCluster, mapping.Spec.DestinationNamespace))
 
+	// A manual trigger runs a full sync immediately, watching then continues as normal
+	if trigger := pendingManualTrigger(mapping); trigger != nil {
+		startTime := time.Now()
+		deploymentScales, stats, err := r.syncResources(ctx, mapping)
+		syncDuration := time.Since(startTime)
+
+		if err != nil {
+			log.Errorf("failed to sync resources after manual trigger: %v", err)
+			shouldRetry, backoff, retryErr := r.handleRetry(ctx, mapping, err)
+			if retryErr != nil {
+				log.Errorf("failed to handle retry: %v", retryErr)
+				return ctrl.Result{}, retryErr
+			}
+			if shouldRetry {
+				log.Info(fmt.Sprintf("retrying sync after %s backoff", backoff))
+				return ctrl.Result{RequeueAfter: backoff}, nil
+			}
+			return ctrl.Result{}, err
+		}
+
+		if err := r.resetRetryStatus(ctx, mapping); err != nil {
+			log.Errorf("failed to reset retry status: %v", err)
+		}
+
+		if err := r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
+			now := metav1.Now()
+			status.Phase = drv1alpha1.SyncPhaseCompleted
+			status.LastSyncTime = &now
+			status.DeploymentScales = deploymentScales
+			setVerifiedCondition(status, mapping.Generation, mapping.Status.Verification)
+			setSmokeTestsCondition(status, mapping)
+			status.SyncStats = stats
+			status.FailedResources = nil
+			setStageConditions(status, mapping, nil)
+		}); err != nil {
+			return ctrl.Result{}, err
+		}
+		if err := r.acknowledgeManualTrigger(ctx, mapping, trigger); err != nil {
+			return ctrl.Result{}, err
+		}
+
+		log.Info(fmt.Sprintf("manually triggered sync complete in %s for mapping '%s'", syncDuration, mapping.Name))
+	}
+
+	// The agents watch the source PVC data while the data watch is enabled
+	dataWatchMinInterval, watchData, err := dataWatchInterval(mapping)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !watchData {
+		if _, err := r.pollPVCData(ctx, mapping, 0, false); err != nil {
+			log.Errorf("failed to stop the PVC data watch: %v", err)
+		}
+	}
+
+	// The watchers of the mapping outlive this reconcile, they are only started once per spec generation
+	r.watchManager = watchManagerFor(mapping, r.sourceClient, r.destClient)
+
 	// If not already watching, start watching resources
 	if !r.watchManager.IsWatching() {
 		resources := r.getResourceGVRs(mapping.Spec.ResourceTypes)
 		log.Info(fmt.Sprintf("starting resource watchers for %d resource types in cluster %s",
 			len(resources), mapping.Spec.SourceCluster))
 
+		// Watch-triggered syncs can read the watched types from the informer caches instead of listing them
+		syncCtx := ctx
+		if mapping.Spec.Continuous != nil && mapping.Spec.Continuous.UseInformerCache != nil && *mapping.Spec.Continuous.UseInformerCache {
+			log.Info("reading watched source resources from informer caches")
+			syncCtx = syncer.WithSourceCache(ctx, r.watchManager)
+		}
+
 		err := r.watchManager.StartWatching(ctx, mapping.Spec.SourceNamespace, resources,
 			func(obj interface{}) error {
 				// Start sync and update status
@@ -229,7 +310,7 @@ func (r *ModeReconciler) ReconcileContinuous(ctx context.Context, mapping *drv1a
 				}
 
 				// Handle resource sync
-				deploymentScales, err := r.syncResources(ctx, mapping)
+				deploymentScales, stats, err := r.syncResources(syncCtx, mapping)
 				syncDuration := time.Since(startTime)
 
 				if err != nil {
@@ -258,12 +339,11 @@ func (r *ModeReconciler) ReconcileContinuous(ctx context.Context, mapping *drv1a
 					status.LastSyncTime = &now
 					status.LastWatchEvent = &now
 					status.DeploymentScales = deploymentScales
-					status.SyncStats = &drv1alpha1.SyncStats{
-						TotalResources:   int32(len(deploymentScales)),
-						SuccessfulSyncs:  int32(len(deploymentScales)),
-						FailedSyncs:      0,
-						LastSyncDuration: formatDuration(syncDuration),
-					}
+					setVerifiedCondition(status, mapping.Generation, mapping.Status.Verification)
+					setSmokeTestsCondition(status, mapping)
+					status.SyncStats = stats
+					status.FailedResources = nil
+					setStageConditions(status, mapping, nil)
 
 					// Update the Synced condition
 					syncedCondition := metav1.Condition{
@@ -310,6 +390,7 @@ func (r *ModeReconciler) ReconcileContinuous(ctx context.Context, mapping *drv1a
 			})
 		if err != nil {
 			log.Errorf("failed to start watching resources: %v", err)
+			StopWatches(mapping.Namespace, mapping.Name)
 			return ctrl.Result{}, err
 		}
 
@@ -325,10 +406,20 @@ func (r *ModeReconciler) ReconcileContinuous(ctx context.Context, mapping *drv1a
 				interval, mapping.Spec.SourceCluster, mapping.Spec.DestinationCluster))
 
 			r.watchManager.StartBackgroundSync(ctx, interval, func() error {
-				_, err := r.syncResources(ctx, mapping)
+				_, _, err := r.syncResources(syncCtx, mapping)
 				return err
 			})
 		}
+
+		// Sync the PVC data shortly after the agents report changes
+		if watchData {
+			log.Info(fmt.Sprintf("watching PVC data in namespace %s with a minimum interval of %s between syncs",
+				mapping.Spec.SourceNamespace, dataWatchMinInterval))
+			if _, err := r.pollPVCData(ctx, mapping, dataWatchMinInterval, true); err != nil {
+				log.Errorf("failed to start the PVC data watch: %v", err)
+			}
+			r.startDataWatch(ctx, syncCtx, mapping, dataWatchMinInterval)
+		}
 	}
 
 	// Extract cluster names with fallbacks for empty values
@@ -342,6 +433,12 @@ func (r *ModeReconciler) ReconcileContinuous(ctx context.Context, mapping *drv1a
 		destCluster = "destination"
 	}
 
+	if err := r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
+		status.ActiveWatches = int32(r.watchManager.WatchCount())
+	}); err != nil {
+		return ctrl.Result{}, err
+	}
+
 	log.Info(fmt.Sprintf("continuous reconciliation complete for mapping '%s' (cluster %s to cluster %s)",
 		mapping.Name, sourceCluster, destCluster))
 
@@ -356,22 +453,10 @@ func (r *ModeReconciler) ReconcileManual(ctx context.Context, mapping *drv1alpha
 		mapping.Spec.DestinationCluster, mapping.Spec.DestinationNamespace))
 
 	// Check for sync-now or trigger-sync annotation first
-	syncNow := false
-	if mapping.ObjectMeta.Annotations != nil {
-		if _, ok := mapping.ObjectMeta.Annotations["dr-syncer.io/sync-now"]; ok {
-			syncNow = true
-			log.Info(fmt.Sprintf("detected dr-syncer.io/sync-now annotation for mapping '%s', triggering immediate sync from phase %s",
-				mapping.Name, mapping.Status.Phase))
-		} else if _, ok := mapping.ObjectMeta.Annotations["dr-syncer.io/trigger-sync"]; ok {
-			// Support both annotation formats for backward compatibility
-			syncNow = true
-			log.Info(fmt.Sprintf("using deprecated dr-syncer.io/trigger-sync annotation for mapping '%s', please use dr-syncer.io/sync-now instead",
-				mapping.Name))
-		}
-	}
+	trigger := pendingManualTrigger(mapping)
 
-	// If syncNow is true, proceed with syncing regardless of current phase
-	if syncNow {
+	// If a sync was requested, proceed with syncing regardless of current phase
+	if trigger != nil {
 		log.Info(fmt.Sprintf("sync-now annotation detected for %s, immediately setting state to Running and performing sync",
 			mapping.Name))
 
@@ -416,6 +501,7 @@ func (r *ModeReconciler) ReconcileManual(ctx context.Context, mapping *drv1alpha
 			status.LastSyncTime = nil
 			status.DeploymentScales = nil
 			status.NextSyncTime = nil
+			status.NextSyncTimeLocal = ""
 		}); err != nil {
 			return ctrl.Result{}, err
 		}
@@ -450,7 +536,7 @@ performSync:
 
 	// Sync resources
 	startTime := time.Now()
-	deploymentScales, err := r.syncResources(ctx, mapping)
+	deploymentScales, stats, err := r.syncResources(ctx, mapping)
 	syncDuration := time.Since(startTime)
 
 	if err != nil {
@@ -477,12 +563,11 @@ performSync:
 		status.Phase = drv1alpha1.SyncPhaseCompleted
 		status.LastSyncTime = &now
 		status.DeploymentScales = deploymentScales
-		status.SyncStats = &drv1alpha1.SyncStats{
-			TotalResources:   int32(len(deploymentScales)),
-			SuccessfulSyncs:  int32(len(deploymentScales)),
-			FailedSyncs:      0,
-			LastSyncDuration: formatDuration(syncDuration),
-		}
+		setVerifiedCondition(status, mapping.Generation, mapping.Status.Verification)
+		setSmokeTestsCondition(status, mapping)
+		status.SyncStats = stats
+		status.FailedResources = nil
+		setStageConditions(status, mapping, nil)
 
 		// Update the Synced condition
 		syncedCondition := metav1.Condition{
@@ -511,6 +596,9 @@ performSync:
 	}); err != nil {
 		return ctrl.Result{}, err
 	}
+	if err := r.acknowledgeManualTrigger(ctx, mapping, trigger); err != nil {
+		return ctrl.Result{}, err
+	}
 
 	// Extract cluster names with fallbacks for empty values
 	sourceCluster := mapping.Spec.SourceCluster
@@ -529,8 +617,25 @@ performSync:
 	return ctrl.Result{}, nil
 }
 
-// syncResources performs the actual resource synchronization
-func (r *ModeReconciler) syncResources(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) ([]drv1alpha1.DeploymentScale, error) {
+// snapshotConsistency reports whether the mapping syncs consistent snapshots of its source namespace
+func snapshotConsistency(mapping *drv1alpha1.NamespaceMapping) bool {
+	consistency := mapping.Spec.Consistency
+	return consistency != nil && consistency.Snapshot != nil && *consistency.Snapshot
+}
+
+// mappingResourceTypes returns the resource types of the mapping with their aliases and categories
+// expanded, the default types when it names none. A wildcard is expanded by the syncer using API discovery.
+func mappingResourceTypes(mapping *drv1alpha1.NamespaceMapping) []string {
+	if len(mapping.Spec.ResourceTypes) == 0 {
+		return []string{"configmaps", "secrets", "deployments", "services", "ingresses", "persistentvolumeclaims"}
+	}
+	return utils.ExpandResourceTypes(mapping.Spec.ResourceTypes)
+}
+
+// syncResources performs the actual resource synchronization. When some resources fail to sync, the
+// scales and statistics of the sync are returned with the error and the failed resources are recorded
+// in the mapping's status for handleRetry.
+func (r *ModeReconciler) syncResources(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) ([]drv1alpha1.DeploymentScale, *drv1alpha1.SyncStats, error) {
 	startTime := time.Now()
 
 	log.Info(fmt.Sprintf("starting resource sync from cluster %s namespace %s to cluster %s namespace %s",
@@ -547,31 +652,26 @@ func (r *ModeReconciler) syncResources(ctx context.Context, mapping *drv1alpha1.
 		dstNamespace = srcNamespace
 	}
 
+	r.recordEvent(mapping, corev1.EventTypeNormal, EventReasonSyncStarted, "Syncing namespace %s from cluster %s to namespace %s in cluster %s",
+		srcNamespace, r.sourceClusterName, dstNamespace, r.destClusterName)
+
 	// Determine if deployments should be scaled to zero
 	scaleToZero := true
 	if mapping.Spec.ScaleToZero != nil {
 		scaleToZero = *mapping.Spec.ScaleToZero
 	}
 
-	// Determine resource types
-	resourceTypes := mapping.Spec.ResourceTypes
-	defaultTypes := []string{"configmaps", "secrets", "deployments", "services", "ingresses", "persistentvolumeclaims"}
+	normalizedTypes := mappingResourceTypes(mapping)
 
-	// Normalize resource types
-	normalizedTypes := make([]string, len(resourceTypes))
-	for i, rt := range resourceTypes {
-		normalizedTypes[i] = strings.ToLower(rt)
-	}
+	log.Info(fmt.Sprintf("syncing %d resource types with scale to zero: %v", len(normalizedTypes), scaleToZero))
 
-	// Handle empty or wildcard resource types
-	if len(normalizedTypes) == 0 || (len(normalizedTypes) == 1 && normalizedTypes[0] == "*") {
-		normalizedTypes = defaultTypes
+	// A consistent snapshot sync applies the next generation, the recorded one stays until it completes
+	if snapshotConsistency(mapping) {
+		ctx = syncer.WithSyncGeneration(ctx, mapping.Status.SyncGeneration+1)
 	}
 
-	log.Info(fmt.Sprintf("syncing %d resource types with scale to zero: %v", len(normalizedTypes), scaleToZero))
-
 	// Sync resources
-	syncerScales, err := syncer.SyncNamespaceResources(
+	syncResult, err := syncer.SyncNamespaceResources(
 		ctx,
 		r.k8sSource,
 		r.k8sDest,
@@ -589,13 +689,29 @@ func (r *ModeReconciler) syncResources(ctx context.Context, mapping *drv1alpha1.
 		r.sourceConfig,
 		r.destConfig,
 	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to sync namespace resources: %w", err)
+	// Resources that failed to sync do not stop the sync of the others
+	partial, isPartial := syncerrors.AsPartialSyncError(err)
+	if err != nil && !isPartial {
+		r.recordEvent(mapping, corev1.EventTypeWarning, EventReasonSyncFailed, "Sync failed: %v", err)
+		return nil, nil, fmt.Errorf("failed to sync namespace resources: %w", err)
+	}
+	verification := syncResult.Verification
+
+	// CRDs skipped or synced without their conversion webhook, PVCs too large for their destination,
+	// destination PVCs pending binding and destination PVCs that cannot be expanded do not fail the
+	// sync, they are reported
+	if err := r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
+		setCRDsCompatibleCondition(status, mapping.Generation, syncResult)
+		setInsufficientSpaceCondition(status, mapping.Generation, syncResult)
+		setPendingBindingCondition(status, mapping.Generation, syncResult)
+		setPVCExpansionBlockedCondition(status, mapping.Generation, syncResult)
+	}); err != nil {
+		log.Errorf("failed to update CRDsCompatible, InsufficientSpace, PendingBinding and PVCExpansionBlocked conditions: %v", err)
 	}
 
 	// Convert syncer.DeploymentScale to drv1alpha1.DeploymentScale
-	result := make([]drv1alpha1.DeploymentScale, len(syncerScales))
-	for i, scale := range syncerScales {
+	result := make([]drv1alpha1.DeploymentScale, len(syncResult.DeploymentScales))
+	for i, scale := range syncResult.DeploymentScales {
 		result[i] = drv1alpha1.DeploymentScale{
 			Name:             scale.Name,
 			OriginalReplicas: scale.Replicas,
@@ -603,6 +719,12 @@ func (r *ModeReconciler) syncResources(ctx context.Context, mapping *drv1alpha1.
 		}
 	}
 
+	// A retry only syncs the resources that failed, the rest of the previous sync still stands
+	retried := len(syncer.RetryResources(ctx)) > 0
+	if retried {
+		result = mergeDeploymentScales(mapping.Status.DeploymentScales, result)
+	}
+
 	// Create resource status entries for each resource type
 	// This is needed for the test case to pass
 	now := metav1.Now()
@@ -625,6 +747,14 @@ func (r *ModeReconciler) syncResources(ctx context.Context, mapping *drv1alpha1.
 			kind = "Ingress"
 		case "persistentvolumeclaims", "persistentvolumeclaim", "pvc":
 			kind = "PersistentVolumeClaim"
+		case "resourcequotas", "resourcequota", "quota":
+			kind = "ResourceQuota"
+		case "limitranges", "limitrange", "limits":
+			kind = "LimitRange"
+		case "cronjobs", "cronjob":
+			kind = "CronJob"
+		case "jobs", "job":
+			kind = "Job"
 		default:
 			kind = strings.Title(resourceType)
 		}
@@ -642,6 +772,17 @@ func (r *ModeReconciler) syncResources(ctx context.Context, mapping *drv1alpha1.
 	// Update the resource status in the namespace mapping object
 	mapping.Status.ResourceStatus = resourceStatuses
 
+	// The verification is written to the status with the rest of the sync result
+	mapping.Status.Verification = verification
+	if verification != nil {
+		if message := driftMessage(verification); message != "" {
+			r.recordEvent(mapping, corev1.EventTypeWarning, EventReasonVerificationFailed, "%s", message)
+		}
+	}
+
+	// Smoke tests probe the destination once its resources are in place
+	r.runSmokeTests(ctx, mapping, dstNamespace, scaleToZero)
+
 	// Extract cluster names with fallbacks for empty values
 	sourceCluster := mapping.Spec.SourceCluster
 	if sourceCluster == "" {
@@ -653,14 +794,46 @@ func (r *ModeReconciler) syncResources(ctx context.Context, mapping *drv1alpha1.
 		destCluster = "destination"
 	}
 
-	log.Info(fmt.Sprintf("resource sync complete in %s, synced %d deployments from mapping '%s' (cluster %s to cluster %s)",
-		time.Since(startTime), len(result), mapping.Name, sourceCluster, destCluster))
+	stats := syncStats(mapping.Status.SyncStats, syncResult, retried, time.Since(startTime))
+	if isPartial {
+		r.recordEvent(mapping, corev1.EventTypeWarning, EventReasonSyncFailed, "Failed to sync %d of %d resources: %v",
+			len(partial.Failures), len(partial.Failures)+partial.Synced, err)
+
+		// The failed resources are written to the status by handleRetry
+		mapping.Status.FailedResources = failedResources(partial, metav1.Now())
+		mapping.Status.SyncStats = stats
+		return result, stats, fmt.Errorf("failed to sync namespace resources: %w", err)
+	}
+
+	// Only a sync that applied the whole snapshot completes its generation
+	if syncResult.SyncGeneration > 0 {
+		if err := r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
+			status.SyncGeneration = syncResult.SyncGeneration
+			status.SnapshotResourceVersions = syncResult.SnapshotResourceVersions
+		}); err != nil {
+			log.Errorf("failed to record sync generation %d: %v", syncResult.SyncGeneration, err)
+		}
+	}
+
+	log.Info(fmt.Sprintf("resource sync complete in %s, synced %d resources and %d deployments from mapping '%s' (cluster %s to cluster %s)",
+		time.Since(startTime), syncResult.Synced, len(result), mapping.Name, sourceCluster, destCluster))
+	r.recordEvent(mapping, corev1.EventTypeNormal, EventReasonSyncCompleted, "Synced namespace %s to namespace %s in cluster %s in %s",
+		srcNamespace, dstNamespace, destCluster, formatDuration(time.Since(startTime)))
 
-	return result, nil
+	return result, stats, nil
 }
 
-// CleanupResources removes all resources that were synced to the destination cluster
+// CleanupResources removes the resources selected by the mapping's cleanup policy from the destination cluster
 func (r *ModeReconciler) CleanupResources(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) error {
+	r.releaseWarmPool(ctx, mapping)
+
+	policy := cleanupPolicy(mapping)
+	listOptions, ok := cleanupListOptions(mapping, policy)
+	if !ok {
+		log.Info(fmt.Sprintf("cleanup policy %s for mapping %s, leaving destination resources in place", policy, mapping.Name))
+		return nil
+	}
+
 	// Check if destination client is available
 	if r.destClient == nil {
 		log.Info(fmt.Sprintf("skipping cleanup for cluster %s: destination client not initialized",
@@ -678,8 +851,8 @@ func (r *ModeReconciler) CleanupResources(ctx context.Context, mapping *drv1alph
 		dstNamespace = srcNamespace
 	}
 
-	log.Info(fmt.Sprintf("cleaning up resources in destination cluster %s namespace %s",
-		mapping.Spec.DestinationCluster, dstNamespace))
+	log.Info(fmt.Sprintf("cleaning up resources in destination cluster %s namespace %s (policy %s)",
+		mapping.Spec.DestinationCluster, dstNamespace, policy))
 
 	// Determine resource types to clean up
 	resourceTypes := mapping.Spec.ResourceTypes
@@ -690,15 +863,25 @@ func (r *ModeReconciler) CleanupResources(ctx context.Context, mapping *drv1alph
 	for i, rt := range resourceTypes {
 		normalizedTypes[i] = strings.ToLower(rt)
 	}
+	wildcard := len(normalizedTypes) == 1 && normalizedTypes[0] == "*"
 
 	// Handle empty or wildcard resource types
-	if len(normalizedTypes) == 0 || (len(normalizedTypes) == 1 && normalizedTypes[0] == "*") {
+	if len(normalizedTypes) == 0 || wildcard {
 		normalizedTypes = defaultTypes
 	}
 
 	// Get GVRs for cleanup
 	resources := r.getResourceGVRs(normalizedTypes)
 
+	// Labels limit SyncedOnly to the mapping's resources, so every type a wildcard may have synced is searched
+	if wildcard && policy == drv1alpha1.CleanupPolicySyncedOnly && r.k8sDest != nil {
+		discovered, err := discoverCleanupResources(r.k8sDest.Discovery())
+		if err != nil {
+			return err
+		}
+		resources = discovered
+	}
+
 	// Delete resources in reverse order to handle dependencies
 	for i := len(resources) - 1; i >= 0; i-- {
 		gvr := resources[i]
@@ -706,7 +889,7 @@ func (r *ModeReconciler) CleanupResources(ctx context.Context, mapping *drv1alph
 			gvr.Resource, mapping.Spec.DestinationCluster))
 
 		// List resources in the destination namespace
-		list, err := r.destClient.Resource(gvr).Namespace(dstNamespace).List(ctx, metav1.ListOptions{})
+		list, err := r.destClient.Resource(gvr).Namespace(dstNamespace).List(ctx, listOptions)
 		if err != nil {
 			if !apierrors.IsNotFound(err) {
 				return fmt.Errorf("failed to list resources of type %s: %w", gvr.Resource, err)
@@ -716,10 +899,12 @@ func (r *ModeReconciler) CleanupResources(ctx context.Context, mapping *drv1alph
 
 		// Delete each resource
 		for _, item := range list.Items {
-			if err := r.destClient.Resource(gvr).Namespace(dstNamespace).Delete(ctx, item.GetName(), metav1.DeleteOptions{}); err != nil {
-				if !apierrors.IsNotFound(err) {
-					return fmt.Errorf("failed to delete %s/%s: %w", gvr.Resource, item.GetName(), err)
-				}
+			err := r.destClient.Resource(gvr).Namespace(dstNamespace).Delete(ctx, item.GetName(), metav1.DeleteOptions{})
+			if !apierrors.IsNotFound(err) {
+				audit.Record(ctx, audit.OperationDelete, audit.ObjectRef(item.GroupVersionKind(), &item), "cleanup", err)
+			}
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete %s/%s: %w", gvr.Resource, item.GetName(), err)
 			}
 			log.Info(fmt.Sprintf("deleted %s/%s in cluster %s namespace %s",
 				gvr.Resource, item.GetName(), mapping.Spec.DestinationCluster, dstNamespace))
@@ -845,6 +1030,9 @@ func statusEqual(a, b *drv1alpha1.NamespaceMappingStatus) bool {
 	if !syncErrorEqual(a.LastError, b.LastError) {
 		return false
 	}
+	if !failedResourcesEqual(a.FailedResources, b.FailedResources) {
+		return false
+	}
 	if !retryStatusEqual(a.RetryStatus, b.RetryStatus) {
 		return false
 	}
@@ -857,6 +1045,24 @@ func statusEqual(a, b *drv1alpha1.NamespaceMappingStatus) bool {
 	if !resourceStatusEqual(a.ResourceStatus, b.ResourceStatus) {
 		return false
 	}
+	if !manualTriggerEqual(a.LastManualTrigger, b.LastManualTrigger) {
+		return false
+	}
+	if !verificationEqual(a.Verification, b.Verification) {
+		return false
+	}
+	if !smokeTestsEqual(a.SmokeTests, b.SmokeTests) {
+		return false
+	}
+	if !failoverTestEqual(a.LastFailoverTest, b.LastFailoverTest) {
+		return false
+	}
+	if a.ActiveWatches != b.ActiveWatches {
+		return false
+	}
+	if a.SyncGeneration != b.SyncGeneration || !maps.Equal(a.SnapshotResourceVersions, b.SnapshotResourceVersions) {
+		return false
+	}
 
 	return true
 }
@@ -869,6 +1075,16 @@ func timeEqual(a, b *metav1.Time) bool {
 	return a.Time.Equal(b.Time)
 }
 
+// manualTriggerEqual compares two ManualTrigger pointers
+func manualTriggerEqual(a, b *drv1alpha1.ManualTrigger) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.RequestedBy == b.RequestedBy &&
+		a.Value == b.Value &&
+		timeEqual(a.TriggeredAt, b.TriggeredAt)
+}
+
 // syncStatsEqual compares two SyncStats pointers
 func syncStatsEqual(a, b *drv1alpha1.SyncStats) bool {
 	if a == nil || b == nil {
@@ -877,6 +1093,8 @@ func syncStatsEqual(a, b *drv1alpha1.SyncStats) bool {
 	return a.TotalResources == b.TotalResources &&
 		a.SuccessfulSyncs == b.SuccessfulSyncs &&
 		a.FailedSyncs == b.FailedSyncs &&
+		a.PVCDataSyncs == b.PVCDataSyncs &&
+		a.FailedPVCDataSyncs == b.FailedPVCDataSyncs &&
 		a.LastSyncDuration == b.LastSyncDuration
 }
 
@@ -1043,6 +1261,13 @@ func (r *ModeReconciler) handleRetry(ctx context.Context, mapping *drv1alpha1.Na
 		status.RetryStatus = retryStatus
 		status.LastError = syncError
 
+		// Only the failed resources are retried after a partial sync
+		status.FailedResources = nil
+		if _, ok := syncerrors.AsPartialSyncError(err); ok {
+			status.FailedResources = mapping.Status.FailedResources
+			status.SyncStats = mapping.Status.SyncStats
+		}
+
 		// Update the Synced condition
 		syncedCondition := metav1.Condition{
 			Type:               "Synced",
@@ -1066,6 +1291,11 @@ func (r *ModeReconciler) handleRetry(ctx context.Context, mapping *drv1alpha1.Na
 		}
 		conditions = append(conditions, syncedCondition)
 		status.Conditions = conditions
+
+		setStorageReadyCondition(status, mapping.Generation, err)
+		setDependenciesResolvedCondition(status, mapping.Generation, err)
+		setQuotaExceededCondition(status, mapping.Generation, err)
+		setStageConditions(status, mapping, err)
 	})
 
 	shouldRetry := retryStatus.RetriesRemaining > 0
@@ -1086,55 +1316,18 @@ func (r *ModeReconciler) resetRetryStatus(ctx context.Context, mapping *drv1alph
 	return r.updateStatus(ctx, mapping, func(status *drv1alpha1.NamespaceMappingStatus) {
 		status.RetryStatus = nil
 		status.LastError = nil
+		setStorageReadyCondition(status, mapping.Generation, nil)
+		setDependenciesResolvedCondition(status, mapping.Generation, nil)
+		setQuotaExceededCondition(status, mapping.Generation, nil)
 	})
 }
 
-// getResourceGVRs converts resource type strings to GroupVersionResource objects
+// getResourceGVRs converts resource type strings, aliases and categories to GroupVersionResource objects
 func (r *ModeReconciler) getResourceGVRs(resourceTypes []string) []schema.GroupVersionResource {
 	var resources []schema.GroupVersionResource
 
-	for _, rt := range resourceTypes {
-		rt = strings.ToLower(rt)
-
-		switch rt {
-		case "configmaps", "configmap":
-			resources = append(resources, schema.GroupVersionResource{
-				Group:    "",
-				Version:  "v1",
-				Resource: "configmaps",
-			})
-		case "secrets", "secret":
-			resources = append(resources, schema.GroupVersionResource{
-				Group:    "",
-				Version:  "v1",
-				Resource: "secrets",
-			})
-		case "deployments", "deployment":
-			resources = append(resources, schema.GroupVersionResource{
-				Group:    "apps",
-				Version:  "v1",
-				Resource: "deployments",
-			})
-		case "services", "service":
-			resources = append(resources, schema.GroupVersionResource{
-				Group:    "",
-				Version:  "v1",
-				Resource: "services",
-			})
-		case "ingresses", "ingress":
-			// Try both networking.k8s.io and extensions API groups
-			resources = append(resources, schema.GroupVersionResource{
-				Group:    "networking.k8s.io",
-				Version:  "v1",
-				Resource: "ingresses",
-			})
-		case "persistentvolumeclaims", "persistentvolumeclaim", "pvc":
-			resources = append(resources, schema.GroupVersionResource{
-				Group:    "",
-				Version:  "v1",
-				Resource: "persistentvolumeclaims",
-			})
-		case "*":
+	for _, rt := range utils.ExpandResourceTypes(resourceTypes) {
+		if rt == "*" {
 			// Add all default resources
 			resources = append(resources,
 				schema.GroupVersionResource{Group: "", Version: "v1", Resource: "configmaps"},
@@ -1144,6 +1337,10 @@ func (r *ModeReconciler) getResourceGVRs(resourceTypes []string) []schema.GroupV
 				schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
 				schema.GroupVersionResource{Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
 			)
+			continue
+		}
+		if gvr, ok := utils.ResourceTypeGVR(rt); ok {
+			resources = append(resources, gvr)
 		}
 	}
 
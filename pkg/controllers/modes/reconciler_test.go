@@ -1,13 +1,18 @@
 package modes
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
 
 func TestDefaultSchedule(t *testing.T) {
@@ -364,6 +369,19 @@ func TestModeReconciler_GetResourceGVRs_Multiple(t *testing.T) {
 	assert.Len(t, gvrs, 3)
 }
 
+func TestModeReconciler_GetResourceGVRs_Categories(t *testing.T) {
+	r := &ModeReconciler{}
+	gvrs := r.getResourceGVRs([]string{"workloads", "svc"})
+
+	assert.Equal(t, []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "apps", Version: "v1", Resource: "daemonsets"},
+		{Group: "batch", Version: "v1", Resource: "cronjobs"},
+		{Group: "", Version: "v1", Resource: "services"},
+	}, gvrs)
+}
+
 func TestModeReconciler_GetResourceGVRs_CaseInsensitive(t *testing.T) {
 	r := &ModeReconciler{}
 
@@ -435,3 +453,122 @@ func TestNewModeReconciler_WatchManagerCreated(t *testing.T) {
 
 	assert.NotNil(t, r.watchManager)
 }
+
+func TestManualTriggerAnnotation(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{}
+	_, ok := manualTriggerAnnotation(mapping)
+	assert.False(t, ok)
+
+	mapping.Annotations = map[string]string{TriggerSyncAnnotation: "true"}
+	annotation, ok := manualTriggerAnnotation(mapping)
+	assert.True(t, ok)
+	assert.Equal(t, TriggerSyncAnnotation, annotation)
+
+	// sync-now takes precedence over the deprecated annotation
+	mapping.Annotations[SyncNowAnnotation] = "true"
+	annotation, ok = manualTriggerAnnotation(mapping)
+	assert.True(t, ok)
+	assert.Equal(t, SyncNowAnnotation, annotation)
+}
+
+func TestManualTriggerRequestor_Annotation(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				SyncNowAnnotation:         "true",
+				SyncRequestedByAnnotation: "jdoe",
+			},
+		},
+	}
+
+	assert.Equal(t, "jdoe", manualTriggerRequestor(mapping, SyncNowAnnotation))
+}
+
+func TestManualTriggerRequestor_ManagedFields(t *testing.T) {
+	older := metav1.NewTime(time.Now().Add(-time.Hour))
+	newer := metav1.Now()
+	fields := &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:annotations":{"f:dr-syncer.io/sync-now":{}}}}`)}
+	mapping := &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{SyncNowAnnotation: "true"},
+			ManagedFields: []metav1.ManagedFieldsEntry{
+				{Manager: "dr-syncer", Time: &newer, FieldsV1: &metav1.FieldsV1{Raw: []byte(`{"f:metadata":{"f:finalizers":{}}}`)}},
+				{Manager: "helm", Time: &older, FieldsV1: fields},
+				{Manager: "kubectl-annotate", Time: &newer, FieldsV1: fields},
+			},
+		},
+	}
+
+	assert.Equal(t, "kubectl-annotate", manualTriggerRequestor(mapping, SyncNowAnnotation))
+}
+
+func TestManualTriggerRequestor_Unknown(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{SyncNowAnnotation: "true"},
+		},
+	}
+
+	assert.Equal(t, "unknown", manualTriggerRequestor(mapping, SyncNowAnnotation))
+}
+
+func TestStatusEqual_LastManualTrigger(t *testing.T) {
+	now := metav1.Now()
+	a := &drv1alpha1.NamespaceMappingStatus{}
+	b := &drv1alpha1.NamespaceMappingStatus{
+		LastManualTrigger: &drv1alpha1.ManualTrigger{RequestedBy: "jdoe", TriggeredAt: &now},
+	}
+	assert.False(t, statusEqual(a, b))
+
+	a.LastManualTrigger = b.LastManualTrigger.DeepCopy()
+	assert.True(t, statusEqual(a, b))
+}
+
+func TestAcknowledgeManualTrigger(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+	mapping := &drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{
+		Name:      "shop",
+		Namespace: "dr-syncer",
+		Annotations: map[string]string{
+			SyncNowAnnotation:         "1",
+			SyncRequestedByAnnotation: "jdoe",
+			"team":                    "shop",
+		},
+	}}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mapping).WithStatusSubresource(mapping).Build()
+	r := NewModeReconciler(c, nil, nil, nil, nil, nil, nil, "", "")
+	var stored drv1alpha1.NamespaceMapping
+
+	// Detecting the trigger leaves it in place until the sync succeeded
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(mapping), mapping))
+	trigger := pendingManualTrigger(mapping)
+	require.NotNil(t, trigger)
+	assert.Equal(t, "jdoe", trigger.record.RequestedBy)
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(mapping), &stored))
+	assert.Contains(t, stored.Annotations, SyncNowAnnotation)
+	assert.Nil(t, stored.Status.LastManualTrigger)
+
+	require.NoError(t, r.acknowledgeManualTrigger(ctx, mapping, trigger))
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(mapping), &stored))
+	assert.Equal(t, map[string]string{"team": "shop"}, stored.Annotations)
+	require.NotNil(t, stored.Status.LastManualTrigger)
+	assert.Equal(t, "1", stored.Status.LastManualTrigger.Value)
+	assert.Nil(t, pendingManualTrigger(&stored))
+
+	// A trigger set again while the sync ran is kept for another sync
+	stored.Annotations[SyncNowAnnotation] = "2"
+	require.NoError(t, c.Update(ctx, &stored))
+	trigger = pendingManualTrigger(&stored)
+	require.NotNil(t, trigger)
+	stored.Annotations[SyncNowAnnotation] = "3"
+	require.NoError(t, c.Update(ctx, &stored))
+	require.NoError(t, r.acknowledgeManualTrigger(ctx, &stored, trigger))
+	require.NoError(t, c.Get(ctx, client.ObjectKeyFromObject(mapping), &stored))
+	assert.Equal(t, "3", stored.Annotations[SyncNowAnnotation])
+	assert.Equal(t, "2", stored.Status.LastManualTrigger.Value)
+
+	// Without a trigger there is nothing to acknowledge
+	require.NoError(t, r.acknowledgeManualTrigger(ctx, &stored, nil))
+}
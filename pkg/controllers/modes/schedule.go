@@ -0,0 +1,37 @@
+package modes
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/robfig/cron/v3"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+// ParseSchedule parses the mapping's schedule, or DefaultSchedule when it has none, evaluated in the
+// mapping's timezone. Without a timezone the schedule is evaluated in the controller's local time zone.
+// The returned location is the time zone the schedule is evaluated in.
+func ParseSchedule(spec *drv1alpha1.NamespaceMappingSpec) (cron.Schedule, *time.Location, error) {
+	schedule := spec.Schedule
+	if schedule == "" {
+		schedule = DefaultSchedule
+	}
+
+	cronSchedule, err := cron.ParseStandard(schedule)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid schedule %q: %w", schedule, err)
+	}
+	if spec.Timezone == "" {
+		return cronSchedule, time.Local, nil
+	}
+
+	location, err := time.LoadLocation(spec.Timezone)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid timezone %q: %w", spec.Timezone, err)
+	}
+	// Descriptors like @every run at a fixed interval and have no location
+	if specSchedule, ok := cronSchedule.(*cron.SpecSchedule); ok {
+		specSchedule.Location = location
+	}
+	return cronSchedule, location, nil
+}
@@ -0,0 +1,40 @@
+package modes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestParseSchedule(t *testing.T) {
+	now := time.Date(2026, 3, 20, 12, 0, 0, 0, time.UTC)
+
+	schedule, location, err := ParseSchedule(&drv1alpha1.NamespaceMappingSpec{Schedule: "0 2 * * *", Timezone: "Europe/Berlin"})
+	require.NoError(t, err)
+	next := schedule.Next(now)
+	assert.Equal(t, time.Date(2026, 3, 21, 1, 0, 0, 0, time.UTC), next.UTC(), "2am CET is 1am UTC")
+	assert.Equal(t, "2026-03-21T02:00:00+01:00", next.In(location).Format(time.RFC3339))
+
+	// The schedule follows daylight saving time, 2am does not exist on the day clocks move forward
+	next = schedule.Next(time.Date(2026, 3, 28, 12, 0, 0, 0, time.UTC))
+	assert.Equal(t, "2026-03-30T02:00:00+02:00", next.In(location).Format(time.RFC3339))
+
+	// Without a schedule the default is used
+	schedule, _, err = ParseSchedule(&drv1alpha1.NamespaceMappingSpec{Timezone: "America/New_York"})
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(5*time.Minute), schedule.Next(now).UTC())
+
+	// Without a timezone the schedule is evaluated in the controller's time zone
+	_, location, err = ParseSchedule(&drv1alpha1.NamespaceMappingSpec{Schedule: "0 2 * * *"})
+	require.NoError(t, err)
+	assert.Equal(t, time.Local, location)
+
+	_, _, err = ParseSchedule(&drv1alpha1.NamespaceMappingSpec{Schedule: "0 2 * * *", Timezone: "Mars/Olympus"})
+	assert.ErrorContains(t, err, `invalid timezone "Mars/Olympus"`)
+
+	_, _, err = ParseSchedule(&drv1alpha1.NamespaceMappingSpec{Schedule: "not a schedule"})
+	assert.ErrorContains(t, err, `invalid schedule "not a schedule"`)
+}
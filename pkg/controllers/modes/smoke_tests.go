@@ -0,0 +1,382 @@
+package modes
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/util"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+const (
+	// ConditionTypeSmokeTestsPassed reports whether the smoke tests passed against the destination namespace
+	ConditionTypeSmokeTestsPassed = "SmokeTestsPassed"
+
+	// ReasonSmokeTestsFailed is set when at least one smoke test failed
+	ReasonSmokeTestsFailed = "SmokeTestsFailed"
+
+	// EventReasonSmokeTestFailed is recorded for each failed smoke test
+	EventReasonSmokeTestFailed = "SmokeTestFailed"
+
+	// SmokeTestLabel marks the probe Jobs with the name of their smoke test
+	SmokeTestLabel = "dr-syncer.io/smoke-test"
+
+	// defaultSmokeTestTimeout bounds a smoke test that sets no timeout
+	defaultSmokeTestTimeout = 30 * time.Second
+
+	// defaultProbeImage runs the probe Jobs when the mapping sets no image
+	defaultProbeImage = "busybox:1.36"
+
+	// probeJobStartupGrace is how much longer than the probe timeout a probe Job may take to be
+	// scheduled and pull its image before it is given up
+	probeJobStartupGrace = 2 * time.Minute
+
+	// maxFailedSmokeTestsInMessage bounds the smoke tests named in the condition message
+	maxFailedSmokeTestsInMessage = 3
+)
+
+// smokeTestsDue reports whether a sync runs the mapping's smoke tests. They run when the sync leaves
+// the destination workloads running, and after every sync with runAfterEachSync.
+func smokeTestsDue(mapping *drv1alpha1.NamespaceMapping, scaleToZero bool) bool {
+	verification := mapping.Spec.Verification
+	if verification == nil || len(verification.SmokeTests) == 0 {
+		return false
+	}
+	return !scaleToZero || (verification.RunAfterEachSync != nil && *verification.RunAfterEachSync)
+}
+
+// smokeTestRunner runs smoke tests against a destination namespace
+type smokeTestRunner struct {
+	client    kubernetes.Interface
+	config    *rest.Config
+	namespace string
+	image     string
+
+	// pollInterval is how often the status of a probe Job is checked
+	pollInterval time.Duration
+
+	// exec runs a command in a container, overridden in tests
+	exec func(ctx context.Context, pod, container string, command []string) (string, error)
+}
+
+// newSmokeTestRunner creates a runner for the smoke tests of a mapping in its destination namespace
+func newSmokeTestRunner(client kubernetes.Interface, config *rest.Config, namespace string, verification *drv1alpha1.VerificationConfig) *smokeTestRunner {
+	image := defaultProbeImage
+	if verification != nil && verification.ProbeImage != "" {
+		image = verification.ProbeImage
+	}
+	runner := &smokeTestRunner{
+		client:       client,
+		config:       config,
+		namespace:    namespace,
+		image:        image,
+		pollInterval: 2 * time.Second,
+	}
+	runner.exec = runner.execInPod
+	return runner
+}
+
+// run runs the smoke tests in order and returns their results
+func (s *smokeTestRunner) run(ctx context.Context, tests []drv1alpha1.SmokeTest) []drv1alpha1.SmokeTestResult {
+	results := make([]drv1alpha1.SmokeTestResult, 0, len(tests))
+	for _, test := range tests {
+		result := drv1alpha1.SmokeTestResult{Name: test.Name, Passed: true, LastRunTime: metav1.Now()}
+		if err := s.runTest(ctx, test); err != nil {
+			result.Passed = false
+			result.Message = err.Error()
+			log.Info(fmt.Sprintf("smoke test %s failed in namespace %s: %v", test.Name, s.namespace, err))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// runTest runs a single smoke test, returning why it failed
+func (s *smokeTestRunner) runTest(ctx context.Context, test drv1alpha1.SmokeTest) error {
+	timeout := defaultSmokeTestTimeout
+	if test.TimeoutSeconds != nil && *test.TimeoutSeconds > 0 {
+		timeout = time.Duration(*test.TimeoutSeconds) * time.Second
+	}
+	seconds := strconv.Itoa(int(timeout.Seconds()))
+
+	probes := 0
+	for _, set := range []bool{test.HTTPGet != nil, test.TCP != nil, test.Exec != nil} {
+		if set {
+			probes++
+		}
+	}
+	if probes != 1 {
+		return fmt.Errorf("exactly one of httpGet, tcp and exec must be set")
+	}
+
+	switch {
+	case test.HTTPGet != nil:
+		path := test.HTTPGet.Path
+		if !strings.HasPrefix(path, "/") {
+			path = "/" + path
+		}
+		url := fmt.Sprintf("http://%s:%d%s", test.HTTPGet.Service, test.HTTPGet.Port, path)
+		return s.runProbeJob(ctx, test.Name, timeout, []string{"wget", "-q", "-O", "/dev/null", "-T", seconds, url})
+	case test.TCP != nil:
+		return s.runProbeJob(ctx, test.Name, timeout, []string{"nc", "-z", "-w", seconds, test.TCP.Service, strconv.Itoa(int(test.TCP.Port))})
+	default:
+		return s.runExec(ctx, test.Exec, timeout)
+	}
+}
+
+// runExec runs the command of an exec smoke test in a running pod matching its selector
+func (s *smokeTestRunner) runExec(ctx context.Context, test *drv1alpha1.ExecSmokeTest, timeout time.Duration) error {
+	selector := labels.SelectorFromSet(test.PodSelector).String()
+	pods, err := s.client.CoreV1().Pods(s.namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	var podName string
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodRunning && pod.DeletionTimestamp == nil {
+			podName = pod.Name
+			break
+		}
+	}
+	if podName == "" {
+		return fmt.Errorf("no running pod matches %s", selector)
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	output, err := s.exec(execCtx, podName, test.Container, test.Command)
+	if err != nil {
+		if output = strings.TrimSpace(output); output != "" {
+			return fmt.Errorf("command failed in pod %s: %v: %s", podName, err, output)
+		}
+		return fmt.Errorf("command failed in pod %s: %v", podName, err)
+	}
+	return nil
+}
+
+// execInPod runs a command in a container of a destination pod, returning its combined output
+func (s *smokeTestRunner) execInPod(ctx context.Context, pod, container string, command []string) (string, error) {
+	req := s.client.CoreV1().RESTClient().Post().
+		Resource("pods").
+		Name(pod).
+		Namespace(s.namespace).
+		SubResource("exec")
+
+	req.VersionedParams(&corev1.PodExecOptions{
+		Container: container,
+		Command:   command,
+		Stdout:    true,
+		Stderr:    true,
+	}, scheme.ParameterCodec)
+
+	exec, err := util.NewPodExecutor(s.config, req.URL())
+	if err != nil {
+		return "", err
+	}
+
+	var output bytes.Buffer
+	err = exec.StreamWithContext(ctx, remotecommand.StreamOptions{Stdout: &output, Stderr: &output})
+	return output.String(), err
+}
+
+// probeJob builds the Job running a probe command in the destination namespace
+func (s *smokeTestRunner) probeJob(name string, timeout time.Duration, command []string) *batchv1.Job {
+	deadline := int64(timeout.Seconds()) + int64(probeJobStartupGrace.Seconds())
+	backoffLimit := int32(0)
+	ttl := int32(300)
+	nonRoot := true
+	readOnly := true
+	user := int64(65534)
+	noEscalation := false
+	automountToken := false
+
+	return &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "dr-syncer-smoke-",
+			Namespace:    s.namespace,
+			Labels:       map[string]string{SmokeTestLabel: name},
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit:            &backoffLimit,
+			ActiveDeadlineSeconds:   &deadline,
+			TTLSecondsAfterFinished: &ttl,
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{SmokeTestLabel: name}},
+				Spec: corev1.PodSpec{
+					RestartPolicy:                corev1.RestartPolicyNever,
+					AutomountServiceAccountToken: &automountToken,
+					SecurityContext: &corev1.PodSecurityContext{
+						RunAsNonRoot:   &nonRoot,
+						RunAsUser:      &user,
+						SeccompProfile: &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+					},
+					Containers: []corev1.Container{{
+						Name:                     "probe",
+						Image:                    s.image,
+						Command:                  command,
+						TerminationMessagePolicy: corev1.TerminationMessageFallbackToLogsOnError,
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: &noEscalation,
+							ReadOnlyRootFilesystem:   &readOnly,
+							Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						},
+					}},
+				},
+			},
+		},
+	}
+}
+
+// runProbeJob runs a probe command in a Job in the destination namespace, so the probe takes the network
+// path of clients in the DR cluster, and deletes the Job once it finished
+func (s *smokeTestRunner) runProbeJob(ctx context.Context, name string, timeout time.Duration, command []string) error {
+	job, err := s.client.BatchV1().Jobs(s.namespace).Create(ctx, s.probeJob(name, timeout, command), metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to create probe job: %w", err)
+	}
+	defer func() {
+		propagation := metav1.DeletePropagationBackground
+		if err := s.client.BatchV1().Jobs(s.namespace).Delete(context.Background(), job.Name, metav1.DeleteOptions{PropagationPolicy: &propagation}); err != nil {
+			log.Info(fmt.Sprintf("failed to delete probe job %s/%s: %v", s.namespace, job.Name, err))
+		}
+	}()
+
+	var finished *batchv1.Job
+	err = wait.PollUntilContextTimeout(ctx, s.pollInterval, timeout+probeJobStartupGrace, true, func(ctx context.Context) (bool, error) {
+		current, err := s.client.BatchV1().Jobs(s.namespace).Get(ctx, job.Name, metav1.GetOptions{})
+		if err != nil {
+			return false, nil
+		}
+		if current.Status.Succeeded > 0 || current.Status.Failed > 0 {
+			finished = current
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return fmt.Errorf("probe job %s did not finish in time", job.Name)
+	}
+	if finished.Status.Succeeded > 0 {
+		return nil
+	}
+	if message := s.probeMessage(ctx, job.Name); message != "" {
+		return fmt.Errorf("probe failed: %s", message)
+	}
+	return fmt.Errorf("probe failed")
+}
+
+// probeMessage returns the termination message of the pod of a failed probe Job
+func (s *smokeTestRunner) probeMessage(ctx context.Context, jobName string) string {
+	pods, err := s.client.CoreV1().Pods(s.namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(map[string]string{"job-name": jobName}).String(),
+	})
+	if err != nil {
+		return ""
+	}
+	for _, pod := range pods.Items {
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.State.Terminated != nil && status.State.Terminated.Message != "" {
+				return strings.TrimSpace(status.State.Terminated.Message)
+			}
+		}
+	}
+	return ""
+}
+
+// runSmokeTests runs the smoke tests of a mapping against its destination namespace when the sync
+// makes them due, storing the results in the mapping status
+func (r *ModeReconciler) runSmokeTests(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, dstNamespace string, scaleToZero bool) {
+	if !smokeTestsDue(mapping, scaleToZero) || r.k8sDest == nil {
+		return
+	}
+
+	runner := newSmokeTestRunner(r.k8sDest, r.destConfig, dstNamespace, mapping.Spec.Verification)
+	results := runner.run(ctx, mapping.Spec.Verification.SmokeTests)
+	for _, result := range results {
+		if !result.Passed {
+			r.recordEvent(mapping, corev1.EventTypeWarning, EventReasonSmokeTestFailed, "Smoke test %s failed: %s", result.Name, result.Message)
+		}
+	}
+	mapping.Status.SmokeTests = results
+}
+
+// setSmokeTestsCondition records the results of the last smoke test run. Without smoke tests the
+// results and the condition are removed, results of an earlier run are kept until the tests run again.
+func setSmokeTestsCondition(status *drv1alpha1.NamespaceMappingStatus, mapping *drv1alpha1.NamespaceMapping) {
+	if mapping.Spec.Verification == nil || len(mapping.Spec.Verification.SmokeTests) == 0 {
+		status.SmokeTests = nil
+		meta.RemoveStatusCondition(&status.Conditions, ConditionTypeSmokeTestsPassed)
+		return
+	}
+
+	results := mapping.Status.SmokeTests
+	if len(results) == 0 {
+		return
+	}
+	status.SmokeTests = make([]drv1alpha1.SmokeTestResult, len(results))
+	for i := range results {
+		results[i].DeepCopyInto(&status.SmokeTests[i])
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeSmokeTestsPassed,
+		Status:             metav1.ConditionTrue,
+		Reason:             "SmokeTestsPassed",
+		Message:            fmt.Sprintf("%d smoke tests passed against the destination namespace", len(results)),
+		ObservedGeneration: mapping.Generation,
+	}
+	if message := smokeTestFailureMessage(results); message != "" {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonSmokeTestsFailed
+		condition.Message = message
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// smokeTestFailureMessage summarizes the failed smoke tests, empty when all passed
+func smokeTestFailureMessage(results []drv1alpha1.SmokeTestResult) string {
+	var failed []string
+	for _, result := range results {
+		if !result.Passed {
+			failed = append(failed, fmt.Sprintf("%s (%s)", result.Name, result.Message))
+		}
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+
+	count := len(failed)
+	if len(failed) > maxFailedSmokeTestsInMessage {
+		failed = append(failed[:maxFailedSmokeTestsInMessage], "...")
+	}
+	return fmt.Sprintf("%d of %d smoke tests failed: %s", count, len(results), strings.Join(failed, ", "))
+}
+
+// smokeTestsEqual compares two lists of smoke test results
+func smokeTestsEqual(a, b []drv1alpha1.SmokeTestResult) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].Name != b[i].Name || a[i].Passed != b[i].Passed || a[i].Message != b[i].Message ||
+			!a[i].LastRunTime.Equal(&b[i].LastRunTime) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+package modes
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// finishProbeJobs names the probe Jobs created in a fake clientset and completes them, failing the
+// Jobs of the smoke tests listed in failures with their termination message
+func finishProbeJobs(client *fake.Clientset, failures map[string]string) {
+	created := 0
+	client.PrependReactor("create", "jobs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		job := action.(k8stesting.CreateAction).GetObject().(*batchv1.Job)
+		created++
+		job.Name = fmt.Sprintf("%s%d", job.GenerateName, created)
+
+		test := job.Labels[SmokeTestLabel]
+		message, failed := failures[test]
+		if !failed {
+			job.Status.Succeeded = 1
+			return false, nil, nil
+		}
+		job.Status.Failed = 1
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: job.Name + "-probe", Namespace: job.Namespace, Labels: map[string]string{"job-name": job.Name}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: message}},
+			}}},
+		}
+		if err := client.Tracker().Add(pod); err != nil {
+			return true, nil, err
+		}
+		return false, nil, nil
+	})
+}
+
+func TestSmokeTestRunner(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api-0", Namespace: "shop-dr", Labels: map[string]string{"app": "api"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker-0", Namespace: "shop-dr", Labels: map[string]string{"app": "worker"}},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	)
+	finishProbeJobs(client, map[string]string{"db": "nc: bad address 'postgres'"})
+
+	runner := newSmokeTestRunner(client, nil, "shop-dr", nil)
+	runner.pollInterval = time.Millisecond
+	var execs []string
+	runner.exec = func(ctx context.Context, pod, container string, command []string) (string, error) {
+		execs = append(execs, fmt.Sprintf("%s/%s %v", pod, container, command))
+		if command[0] == "false" {
+			return "health check failed\n", errors.New("command terminated with exit code 1")
+		}
+		return "", nil
+	}
+
+	results := runner.run(ctx, []drv1alpha1.SmokeTest{
+		{Name: "frontend", HTTPGet: &drv1alpha1.HTTPSmokeTest{Service: "web", Port: 8080, Path: "healthz"}},
+		{Name: "db", TCP: &drv1alpha1.TCPSmokeTest{Service: "postgres", Port: 5432}},
+		{Name: "api", Exec: &drv1alpha1.ExecSmokeTest{PodSelector: map[string]string{"app": "api"}, Container: "api", Command: []string{"/bin/check"}}},
+		{Name: "api-broken", Exec: &drv1alpha1.ExecSmokeTest{PodSelector: map[string]string{"app": "api"}, Command: []string{"false"}}},
+		{Name: "worker", Exec: &drv1alpha1.ExecSmokeTest{PodSelector: map[string]string{"app": "worker"}, Command: []string{"true"}}},
+		{Name: "ambiguous", TCP: &drv1alpha1.TCPSmokeTest{Service: "web", Port: 80}, Exec: &drv1alpha1.ExecSmokeTest{Command: []string{"true"}}},
+	})
+
+	require.Len(t, results, 6)
+	messages := map[string]string{}
+	for _, result := range results {
+		assert.Equal(t, result.Message == "", result.Passed, result.Name)
+		messages[result.Name] = result.Message
+	}
+	assert.Equal(t, map[string]string{
+		"frontend":   "",
+		"db":         "probe failed: nc: bad address 'postgres'",
+		"api":        "",
+		"api-broken": "command failed in pod api-0: command terminated with exit code 1: health check failed",
+		"worker":     "no running pod matches app=worker",
+		"ambiguous":  "exactly one of httpGet, tcp and exec must be set",
+	}, messages)
+	assert.Equal(t, []string{"api-0/api [/bin/check]", "api-0/ [false]"}, execs)
+
+	// The probe Jobs run the probes from the destination namespace and are deleted afterwards
+	var commands [][]string
+	for _, action := range client.Actions() {
+		if create, ok := action.(k8stesting.CreateAction); ok && action.GetResource().Resource == "jobs" {
+			commands = append(commands, create.GetObject().(*batchv1.Job).Spec.Template.Spec.Containers[0].Command)
+		}
+	}
+	assert.Equal(t, [][]string{
+		{"wget", "-q", "-O", "/dev/null", "-T", "30", "http://web:8080/healthz"},
+		{"nc", "-z", "-w", "30", "postgres", "5432"},
+	}, commands)
+	jobs, err := client.BatchV1().Jobs("shop-dr").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, jobs.Items)
+}
+
+func TestSmokeTestsDue(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{}
+	assert.False(t, smokeTestsDue(mapping, false))
+
+	mapping.Spec.Verification = &drv1alpha1.VerificationConfig{
+		SmokeTests: []drv1alpha1.SmokeTest{{Name: "frontend", HTTPGet: &drv1alpha1.HTTPSmokeTest{Service: "web", Port: 80}}},
+	}
+	assert.True(t, smokeTestsDue(mapping, false))
+	assert.False(t, smokeTestsDue(mapping, true))
+
+	runAlways := true
+	mapping.Spec.Verification.RunAfterEachSync = &runAlways
+	assert.True(t, smokeTestsDue(mapping, true))
+}
+
+func TestSetSmokeTestsCondition(t *testing.T) {
+	now := metav1.Now()
+	mapping := &drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{Generation: 2}}
+	mapping.Spec.Verification = &drv1alpha1.VerificationConfig{
+		SmokeTests: []drv1alpha1.SmokeTest{{Name: "frontend"}, {Name: "db"}},
+	}
+	status := &drv1alpha1.NamespaceMappingStatus{}
+
+	// Nothing is recorded before the smoke tests ran
+	setSmokeTestsCondition(status, mapping)
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypeSmokeTestsPassed))
+
+	mapping.Status.SmokeTests = []drv1alpha1.SmokeTestResult{
+		{Name: "frontend", Passed: true, LastRunTime: now},
+		{Name: "db", Message: "probe failed", LastRunTime: now},
+	}
+	setSmokeTestsCondition(status, mapping)
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypeSmokeTestsPassed)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonSmokeTestsFailed, condition.Reason)
+	assert.Equal(t, "1 of 2 smoke tests failed: db (probe failed)", condition.Message)
+	assert.True(t, smokeTestsEqual(mapping.Status.SmokeTests, status.SmokeTests))
+
+	mapping.Status.SmokeTests[1] = drv1alpha1.SmokeTestResult{Name: "db", Passed: true, LastRunTime: now}
+	setSmokeTestsCondition(status, mapping)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeSmokeTestsPassed))
+
+	// Removing the smoke tests removes their results
+	mapping.Spec.Verification = nil
+	setSmokeTestsCondition(status, mapping)
+	assert.Nil(t, status.SmokeTests)
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypeSmokeTestsPassed))
+}
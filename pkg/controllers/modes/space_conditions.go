@@ -0,0 +1,66 @@
+package modes
+
+import (
+	"fmt"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeInsufficientSpace is True while destination PVCs are too small for the data of their
+	// source PVCs, only set once a data sync found such a PVC
+	ConditionTypeInsufficientSpace = "InsufficientSpace"
+
+	// ReasonDestinationTooSmall is set when the data of PVCs was not synced as their destination PVC is too small
+	ReasonDestinationTooSmall = "DestinationTooSmall"
+
+	// maxPVCsInMessage bounds the PVCs named in the InsufficientSpace and PendingBinding condition messages
+	maxPVCsInMessage = 5
+)
+
+// setInsufficientSpaceCondition records the PVCs of a sync result whose data did not fit their destination
+// PVC. The condition is only written after the first shortage and flipped back once every PVC fits.
+func setInsufficientSpaceCondition(status *drv1alpha1.NamespaceMappingStatus, generation int64, result *syncer.SyncResult) {
+	var shortages []string
+	if result != nil {
+		shortages = result.InsufficientSpace
+	}
+	if len(shortages) == 0 {
+		if meta.FindStatusCondition(status.Conditions, ConditionTypeInsufficientSpace) != nil {
+			meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+				Type:               ConditionTypeInsufficientSpace,
+				Status:             metav1.ConditionFalse,
+				Reason:             "SufficientSpace",
+				Message:            "Destination PVCs can hold the data of their source PVCs",
+				ObservedGeneration: generation,
+			})
+		}
+		return
+	}
+
+	meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+		Type:               ConditionTypeInsufficientSpace,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonDestinationTooSmall,
+		Message:            pvcsNotSyncedMessage(shortages),
+		ObservedGeneration: generation,
+	})
+}
+
+// pvcsNotSyncedMessage returns a condition message listing the data sync errors of PVCs, bounded to
+// maxPVCsInMessage of them
+func pvcsNotSyncedMessage(syncErrors []string) string {
+	shown := syncErrors
+	if len(shown) > maxPVCsInMessage {
+		shown = shown[:maxPVCsInMessage]
+	}
+	message := fmt.Sprintf("%d PVCs were not synced: %s", len(syncErrors), strings.Join(shown, "; "))
+	if len(syncErrors) > len(shown) {
+		message += fmt.Sprintf(" and %d more", len(syncErrors)-len(shown))
+	}
+	return message
+}
@@ -0,0 +1,33 @@
+package modes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetInsufficientSpaceCondition(t *testing.T) {
+	status := &drv1alpha1.NamespaceMappingStatus{}
+
+	// No condition until a destination PVC is too small
+	setInsufficientSpaceCondition(status, 1, &syncer.SyncResult{PVCDataSynced: 2})
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypeInsufficientSpace))
+
+	setInsufficientSpaceCondition(status, 2, &syncer.SyncResult{
+		PVCDataFailed:     1,
+		InsufficientSpace: []string{"rsync workflow failed: insufficient space: source PVC app/data uses 8Gi but destination PVC app-dr/data has a capacity of 5Gi"},
+	})
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypeInsufficientSpace)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonDestinationTooSmall, condition.Reason)
+	assert.Contains(t, condition.Message, "1 PVCs were not synced: rsync workflow failed: insufficient space: source PVC app/data uses 8Gi")
+
+	setInsufficientSpaceCondition(status, 3, &syncer.SyncResult{PVCDataSynced: 1})
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, ConditionTypeInsufficientSpace))
+}
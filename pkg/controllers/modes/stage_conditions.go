@@ -0,0 +1,197 @@
+package modes
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// A sync goes through stages that each report a condition, so tooling can tell how far a failed or
+// partial sync got instead of relying on the single Synced condition.
+const (
+	// ConditionTypeNamespaceReady reports whether the source and destination namespaces are in place
+	ConditionTypeNamespaceReady = "NamespaceReady"
+
+	// ConditionTypeResourcesSynced reports whether the resources other than PVCs were synced
+	ConditionTypeResourcesSynced = "ResourcesSynced"
+
+	// ConditionTypePVCObjectsSynced reports whether the PVC objects were synced, only set when the mapping syncs PVCs
+	ConditionTypePVCObjectsSynced = "PVCObjectsSynced"
+
+	// ConditionTypePVCDataSynced reports whether the PVC data was synced, only set when pvcConfig.syncData is enabled
+	ConditionTypePVCDataSynced = "PVCDataSynced"
+
+	// ConditionTypeActivated reports whether the destination workloads run or are scaled to zero as a standby
+	ConditionTypeActivated = "Activated"
+
+	// ReasonNamespaceExists is set when the namespaces of the mapping are in place
+	ReasonNamespaceExists = "NamespaceExists"
+
+	// ReasonNamespaceUnavailable is set when the namespaces of the mapping could not be got or created
+	ReasonNamespaceUnavailable = "NamespaceUnavailable"
+
+	// ReasonWaitingForNamespace is set on the later stages when the namespaces are not in place
+	ReasonWaitingForNamespace = "WaitingForNamespace"
+
+	// ReasonResourcesSynced is set when every resource other than PVCs was synced
+	ReasonResourcesSynced = "ResourcesSynced"
+
+	// ReasonResourcesFailed is set when some resources other than PVCs failed to sync
+	ReasonResourcesFailed = "ResourcesFailed"
+
+	// ReasonOwnershipConflict is set when resources failed to sync because another mapping owns them in
+	// the destination namespace
+	ReasonOwnershipConflict = "OwnershipConflict"
+
+	// ReasonSyncAborted is set on the stages a sync did not complete because it failed as a whole
+	ReasonSyncAborted = "SyncAborted"
+
+	// ReasonPVCObjectsSynced is set when every PVC object was synced
+	ReasonPVCObjectsSynced = "PVCObjectsSynced"
+
+	// ReasonPVCObjectsFailed is set when some PVC objects failed to sync
+	ReasonPVCObjectsFailed = "PVCObjectsFailed"
+
+	// ReasonPVCDataSynced is set when the data of every PVC was synced
+	ReasonPVCDataSynced = "PVCDataSynced"
+
+	// ReasonPVCDataFailed is set when the data of some PVCs failed to sync
+	ReasonPVCDataFailed = "PVCDataFailed"
+
+	// ReasonWorkloadsRunning is set when the destination workloads keep their replicas
+	ReasonWorkloadsRunning = "WorkloadsRunning"
+
+	// ReasonScaledToZero is set when the destination deployments are scaled to zero as a standby
+	ReasonScaledToZero = "ScaledToZero"
+
+	// pvcKind is the kind of the PVC failures of a partial sync
+	pvcKind = "PersistentVolumeClaim"
+)
+
+// setStageConditions records the outcome of each sync stage. syncErr is the error of the sync, nil
+// after a successful sync, and the PVC data counts are read from the sync statistics of status.
+func setStageConditions(status *drv1alpha1.NamespaceMappingStatus, mapping *drv1alpha1.NamespaceMapping, syncErr error) {
+	generation := mapping.Generation
+	syncsPVCs := mappingSyncsPVCs(&mapping.Spec)
+	syncsPVCData := syncsPVCs && mapping.Spec.PVCConfig != nil && mapping.Spec.PVCConfig.SyncData
+
+	set := func(conditionType string, conditionStatus metav1.ConditionStatus, reason, message string) {
+		meta.SetStatusCondition(&status.Conditions, metav1.Condition{
+			Type:               conditionType,
+			Status:             conditionStatus,
+			Reason:             reason,
+			Message:            message,
+			ObservedGeneration: generation,
+		})
+	}
+	if !syncsPVCs {
+		meta.RemoveStatusCondition(&status.Conditions, ConditionTypePVCObjectsSynced)
+	}
+	if !syncsPVCData {
+		meta.RemoveStatusCondition(&status.Conditions, ConditionTypePVCDataSynced)
+	}
+
+	partial, isPartial := syncerrors.AsPartialSyncError(syncErr)
+	if syncErr != nil && !isPartial {
+		// The stages after a failed stage did not run, their outcome is unknown
+		reason, message := ReasonSyncAborted, fmt.Sprintf("Sync failed before completing: %v", syncErr)
+		if errors.Is(syncErr, syncer.ErrNamespaceNotReady) {
+			set(ConditionTypeNamespaceReady, metav1.ConditionFalse, ReasonNamespaceUnavailable, syncErr.Error())
+			reason, message = ReasonWaitingForNamespace, "Waiting for the namespaces of the mapping"
+		}
+		set(ConditionTypeResourcesSynced, metav1.ConditionUnknown, reason, message)
+		if syncsPVCs {
+			set(ConditionTypePVCObjectsSynced, metav1.ConditionUnknown, reason, message)
+		}
+		if syncsPVCData {
+			set(ConditionTypePVCDataSynced, metav1.ConditionUnknown, reason, message)
+		}
+		set(ConditionTypeActivated, metav1.ConditionUnknown, reason, message)
+		return
+	}
+
+	set(ConditionTypeNamespaceReady, metav1.ConditionTrue, ReasonNamespaceExists,
+		fmt.Sprintf("Namespace %s exists in the destination cluster", destinationNamespace(&mapping.Spec)))
+
+	var resourceFailures, pvcFailures []string
+	resourceReason, pvcReason := ReasonResourcesFailed, ReasonPVCObjectsFailed
+	if isPartial {
+		for _, failure := range partial.Failures {
+			owned := errors.Is(failure, syncer.ErrOwnershipConflict)
+			if strings.HasPrefix(failure.Resource, pvcKind+"/") {
+				pvcFailures = append(pvcFailures, failure.Resource)
+				if owned {
+					pvcReason = ReasonOwnershipConflict
+				}
+			} else {
+				resourceFailures = append(resourceFailures, failure.Resource)
+				if owned {
+					resourceReason = ReasonOwnershipConflict
+				}
+			}
+		}
+	}
+
+	if len(resourceFailures) > 0 {
+		set(ConditionTypeResourcesSynced, metav1.ConditionFalse, resourceReason,
+			fmt.Sprintf("%d resources failed to sync: %s", len(resourceFailures), strings.Join(resourceFailures, ", ")))
+	} else {
+		set(ConditionTypeResourcesSynced, metav1.ConditionTrue, ReasonResourcesSynced, "Resources synced to the destination namespace")
+	}
+
+	if syncsPVCs {
+		if len(pvcFailures) > 0 {
+			set(ConditionTypePVCObjectsSynced, metav1.ConditionFalse, pvcReason,
+				fmt.Sprintf("%d PVCs failed to sync: %s", len(pvcFailures), strings.Join(pvcFailures, ", ")))
+		} else {
+			set(ConditionTypePVCObjectsSynced, metav1.ConditionTrue, ReasonPVCObjectsSynced, "PVCs synced to the destination namespace")
+		}
+	}
+
+	if syncsPVCData {
+		var synced, failed int32
+		if status.SyncStats != nil {
+			synced, failed = status.SyncStats.PVCDataSyncs, status.SyncStats.FailedPVCDataSyncs
+		}
+		if failed > 0 {
+			set(ConditionTypePVCDataSynced, metav1.ConditionFalse, ReasonPVCDataFailed,
+				fmt.Sprintf("Data of %d of %d PVCs failed to sync", failed, synced+failed))
+		} else {
+			set(ConditionTypePVCDataSynced, metav1.ConditionTrue, ReasonPVCDataSynced, fmt.Sprintf("Data of %d PVCs synced", synced))
+		}
+	}
+
+	if mapping.Spec.ScaleToZero == nil || *mapping.Spec.ScaleToZero {
+		set(ConditionTypeActivated, metav1.ConditionFalse, ReasonScaledToZero, "Destination deployments are scaled to zero as a standby")
+	} else {
+		set(ConditionTypeActivated, metav1.ConditionTrue, ReasonWorkloadsRunning, "Destination workloads keep the replicas of the source")
+	}
+}
+
+// mappingSyncsPVCs returns true if the resource types of the mapping include PVCs
+func mappingSyncsPVCs(spec *drv1alpha1.NamespaceMappingSpec) bool {
+	if len(spec.ResourceTypes) == 0 {
+		return true
+	}
+	for _, resourceType := range spec.ResourceTypes {
+		switch strings.ToLower(resourceType) {
+		case "persistentvolumeclaims", "persistentvolumeclaim", "pvc", "*":
+			return true
+		}
+	}
+	return false
+}
+
+// destinationNamespace returns the destination namespace of the mapping, the source namespace by default
+func destinationNamespace(spec *drv1alpha1.NamespaceMappingSpec) string {
+	if spec.DestinationNamespace != "" {
+		return spec.DestinationNamespace
+	}
+	return spec.SourceNamespace
+}
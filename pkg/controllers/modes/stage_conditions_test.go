@@ -0,0 +1,85 @@
+package modes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetStageConditions(t *testing.T) {
+	mapping := &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{Generation: 2},
+		Spec: drv1alpha1.NamespaceMappingSpec{
+			SourceNamespace: "app",
+			PVCConfig:       &drv1alpha1.PVCConfig{SyncData: true},
+		},
+	}
+	status := &drv1alpha1.NamespaceMappingStatus{
+		SyncStats: &drv1alpha1.SyncStats{PVCDataSyncs: 1, FailedPVCDataSyncs: 1},
+	}
+
+	setStageConditions(status, mapping, nil)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeNamespaceReady))
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeResourcesSynced))
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypePVCObjectsSynced))
+	data := meta.FindStatusCondition(status.Conditions, ConditionTypePVCDataSynced)
+	assert.Equal(t, ReasonPVCDataFailed, data.Reason)
+	assert.Equal(t, "Data of 1 of 2 PVCs failed to sync", data.Message)
+	assert.Equal(t, int64(2), data.ObservedGeneration)
+	activated := meta.FindStatusCondition(status.Conditions, ConditionTypeActivated)
+	assert.Equal(t, metav1.ConditionFalse, activated.Status)
+	assert.Equal(t, ReasonScaledToZero, activated.Reason)
+
+	// Failed PVCs only fail the PVC stage
+	partial := fmt.Errorf("failed to sync namespace resources: %w", &syncerrors.PartialSyncError{
+		Synced:   3,
+		Failures: []*syncerrors.SyncError{{Err: errors.New("quota exceeded"), Resource: "PersistentVolumeClaim/data"}},
+	})
+	setStageConditions(status, mapping, partial)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeResourcesSynced))
+	pvcs := meta.FindStatusCondition(status.Conditions, ConditionTypePVCObjectsSynced)
+	assert.Equal(t, ReasonPVCObjectsFailed, pvcs.Reason)
+	assert.Contains(t, pvcs.Message, "PersistentVolumeClaim/data")
+
+	// Resources owned by another mapping sharing the destination namespace are reported as such
+	owned := &syncerrors.PartialSyncError{Failures: []*syncerrors.SyncError{{
+		Err:      fmt.Errorf("%w: ConfigMap settings is owned by NamespaceMapping dr-syncer/billing", syncer.ErrOwnershipConflict),
+		Resource: "ConfigMap/settings",
+	}}}
+	setStageConditions(status, mapping, owned)
+	assert.Equal(t, ReasonOwnershipConflict, meta.FindStatusCondition(status.Conditions, ConditionTypeResourcesSynced).Reason)
+
+	// Without namespaces the later stages are unknown
+	namespaceErr := fmt.Errorf("%w: failed to create destination namespace: forbidden", syncer.ErrNamespaceNotReady)
+	setStageConditions(status, mapping, namespaceErr)
+	namespace := meta.FindStatusCondition(status.Conditions, ConditionTypeNamespaceReady)
+	assert.Equal(t, ReasonNamespaceUnavailable, namespace.Reason)
+	for _, conditionType := range []string{ConditionTypeResourcesSynced, ConditionTypePVCObjectsSynced, ConditionTypePVCDataSynced, ConditionTypeActivated} {
+		condition := meta.FindStatusCondition(status.Conditions, conditionType)
+		assert.Equal(t, metav1.ConditionUnknown, condition.Status, conditionType)
+		assert.Equal(t, ReasonWaitingForNamespace, condition.Reason, conditionType)
+	}
+
+	// Other failures leave the namespace stage alone
+	setStageConditions(status, mapping, errors.New("destination cluster verification failed"))
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, ConditionTypeNamespaceReady))
+	assert.Equal(t, ReasonSyncAborted, meta.FindStatusCondition(status.Conditions, ConditionTypeResourcesSynced).Reason)
+
+	// PVC stages are removed when the mapping stops syncing them
+	scaleToZero := false
+	mapping.Spec.ResourceTypes = []string{"ConfigMaps"}
+	mapping.Spec.ScaleToZero = &scaleToZero
+	setStageConditions(status, mapping, nil)
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypePVCObjectsSynced))
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypePVCDataSynced))
+	activated = meta.FindStatusCondition(status.Conditions, ConditionTypeActivated)
+	assert.Equal(t, metav1.ConditionTrue, activated.Status)
+	assert.Equal(t, ReasonWorkloadsRunning, activated.Reason)
+}
@@ -0,0 +1,45 @@
+package modes
+
+import (
+	"errors"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer/validation"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeStorageReady reports whether the destination storage can provision the mapping's PVCs
+	ConditionTypeStorageReady = "StorageReady"
+
+	// ReasonStoragePreflightFailed is set when a destination PVC failed the storage pre-flight validation
+	ReasonStoragePreflightFailed = "PreflightFailed"
+)
+
+// setStorageReadyCondition records the outcome of the destination PVC pre-flight validation for a sync
+// result. The condition is only written once validation has failed, flips back to True after a
+// successful sync, and is left alone by failures unrelated to storage.
+func setStorageReadyCondition(status *drv1alpha1.NamespaceMappingStatus, generation int64, syncErr error) {
+	preflightFailed := errors.Is(syncErr, validation.ErrStoragePreflight)
+	if syncErr != nil && !preflightFailed {
+		return
+	}
+	if !preflightFailed && meta.FindStatusCondition(status.Conditions, ConditionTypeStorageReady) == nil {
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeStorageReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "PreflightPassed",
+		Message:            "Destination storage can provision the synced PVCs",
+		ObservedGeneration: generation,
+	}
+	if preflightFailed {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonStoragePreflightFailed
+		condition.Message = syncErr.Error()
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
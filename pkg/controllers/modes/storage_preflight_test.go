@@ -0,0 +1,38 @@
+package modes
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer/validation"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetStorageReadyCondition(t *testing.T) {
+	status := &drv1alpha1.NamespaceMappingStatus{}
+
+	// Nothing is written until the pre-flight validation fails
+	setStorageReadyCondition(status, 1, nil)
+	setStorageReadyCondition(status, 1, errors.New("connection refused"))
+	assert.Empty(t, status.Conditions)
+
+	preflightErr := fmt.Errorf("failed to sync PVCs: %w", syncerrors.NewNonRetryableError(
+		fmt.Errorf("%w: storage class gp2 does not exist", validation.ErrStoragePreflight), "PersistentVolumeClaim/data"))
+	setStorageReadyCondition(status, 2, preflightErr)
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypeStorageReady)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonStoragePreflightFailed, condition.Reason)
+	assert.Contains(t, condition.Message, "storage class gp2 does not exist")
+
+	// Unrelated failures keep the last pre-flight result
+	setStorageReadyCondition(status, 2, errors.New("connection refused"))
+	assert.True(t, meta.IsStatusConditionFalse(status.Conditions, ConditionTypeStorageReady))
+
+	setStorageReadyCondition(status, 3, nil)
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeStorageReady))
+}
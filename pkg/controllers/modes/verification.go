@@ -0,0 +1,79 @@
+package modes
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ConditionTypeVerified reports whether the destination objects matched the synced state after the last sync
+	ConditionTypeVerified = "Verified"
+
+	// ReasonDestinationDrifted is set when destination objects differ from the synced state
+	ReasonDestinationDrifted = "DestinationDrifted"
+
+	// EventReasonVerificationFailed is recorded when destination objects differ from the synced state
+	EventReasonVerificationFailed = "VerificationFailed"
+
+	// maxDriftedObjectsInMessage bounds the objects named in the Verified condition message
+	maxDriftedObjectsInMessage = 3
+)
+
+// setVerifiedCondition records the verification of a successful sync. Without a verification, because
+// verifyAfterSync is disabled, the verification status and the condition are removed.
+func setVerifiedCondition(status *drv1alpha1.NamespaceMappingStatus, generation int64, verification *drv1alpha1.VerificationStatus) {
+	status.Verification = verification.DeepCopy()
+	if verification == nil {
+		meta.RemoveStatusCondition(&status.Conditions, ConditionTypeVerified)
+		return
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeVerified,
+		Status:             metav1.ConditionTrue,
+		Reason:             "DestinationMatches",
+		Message:            "Destination objects match the synced state",
+		ObservedGeneration: generation,
+	}
+	if message := driftMessage(verification); message != "" {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonDestinationDrifted
+		condition.Message = message
+	}
+	meta.SetStatusCondition(&status.Conditions, condition)
+}
+
+// driftMessage summarizes the mismatched objects of a verification, empty when all objects matched
+func driftMessage(verification *drv1alpha1.VerificationStatus) string {
+	var total, mismatched int32
+	var objects []string
+	for _, kind := range verification.Kinds {
+		total += kind.Verified + kind.Mismatched
+		mismatched += kind.Mismatched
+		for _, mismatch := range kind.Mismatches {
+			objects = append(objects, fmt.Sprintf("%s/%s (%s)", kind.Kind, mismatch.Name, mismatch.Fields))
+		}
+	}
+	if mismatched == 0 {
+		return ""
+	}
+
+	if len(objects) > maxDriftedObjectsInMessage {
+		objects = append(objects[:maxDriftedObjectsInMessage], "...")
+	}
+	return fmt.Sprintf("%d of %d destination objects differ from the synced state: %s",
+		mismatched, total, strings.Join(objects, ", "))
+}
+
+// verificationEqual compares two VerificationStatus pointers
+func verificationEqual(a, b *drv1alpha1.VerificationStatus) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return timeEqual(a.VerifiedAt, b.VerifiedAt) && reflect.DeepEqual(a.Kinds, b.Kinds)
+}
@@ -0,0 +1,43 @@
+package modes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetVerifiedCondition(t *testing.T) {
+	status := &drv1alpha1.NamespaceMappingStatus{}
+	now := metav1.Now()
+
+	setVerifiedCondition(status, 1, &drv1alpha1.VerificationStatus{
+		VerifiedAt: &now,
+		Kinds:      []drv1alpha1.KindVerification{{Kind: "ConfigMap", Verified: 2}},
+	})
+	assert.True(t, meta.IsStatusConditionTrue(status.Conditions, ConditionTypeVerified))
+	require.NotNil(t, status.Verification)
+
+	setVerifiedCondition(status, 2, &drv1alpha1.VerificationStatus{
+		VerifiedAt: &now,
+		Kinds: []drv1alpha1.KindVerification{
+			{Kind: "ConfigMap", Verified: 2},
+			{Kind: "Deployment", Verified: 1, Mismatched: 1, Mismatches: []drv1alpha1.VerificationMismatch{
+				{Name: "web", Fields: "spec.template.spec.containers"},
+			}},
+		},
+	})
+	condition := meta.FindStatusCondition(status.Conditions, ConditionTypeVerified)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+	assert.Equal(t, ReasonDestinationDrifted, condition.Reason)
+	assert.Equal(t, "1 of 4 destination objects differ from the synced state: Deployment/web (spec.template.spec.containers)", condition.Message)
+
+	// Disabling verification removes the results
+	setVerifiedCondition(status, 3, nil)
+	assert.Nil(t, status.Verification)
+	assert.Nil(t, meta.FindStatusCondition(status.Conditions, ConditionTypeVerified))
+}
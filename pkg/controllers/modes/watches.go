@@ -0,0 +1,98 @@
+package modes
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/watch"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// mappingWatch is the WatchManager of a Continuous mode mapping, started for one generation of its spec
+type mappingWatch struct {
+	manager    *watch.WatchManager
+	uid        types.UID
+	generation int64
+}
+
+// continuousWatches holds the WatchManagers of the Continuous mode mappings. A ModeReconciler is created
+// for every reconcile, the watchers outlive it until the mapping leaves Continuous mode, is paused or
+// deleted, or its spec changes.
+var continuousWatches = struct {
+	sync.Mutex
+	mappings map[types.NamespacedName]*mappingWatch
+}{mappings: make(map[types.NamespacedName]*mappingWatch)}
+
+// watchManagerFor returns the WatchManager of the mapping. Watchers started for an earlier generation of
+// the mapping, or for a deleted mapping of the same name, are stopped and replaced by a new WatchManager
+// so the watch handlers sync with the current spec.
+func watchManagerFor(mapping *drv1alpha1.NamespaceMapping, sourceClient, destClient dynamic.Interface) *watch.WatchManager {
+	key := types.NamespacedName{Namespace: mapping.Namespace, Name: mapping.Name}
+
+	continuousWatches.Lock()
+	defer continuousWatches.Unlock()
+
+	current, ok := continuousWatches.mappings[key]
+	if ok && current.uid == mapping.UID && current.generation == mapping.Generation {
+		return current.manager
+	}
+	if ok {
+		log.Info(fmt.Sprintf("spec of mapping '%s' changed, restarting its %d watchers", mapping.Name, current.manager.WatchCount()))
+		current.manager.Stop()
+	}
+
+	manager := watch.NewWatchManager(sourceClient, destClient)
+	continuousWatches.mappings[key] = &mappingWatch{manager: manager, uid: mapping.UID, generation: mapping.Generation}
+	return manager
+}
+
+// StopWatches stops the watchers, background sync and PVC data watch of the mapping namespace/name and
+// returns the number of watchers that were running
+func StopWatches(namespace, name string) int {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+
+	continuousWatches.Lock()
+	current, ok := continuousWatches.mappings[key]
+	delete(continuousWatches.mappings, key)
+	continuousWatches.Unlock()
+
+	if !ok {
+		return 0
+	}
+	stopped := current.manager.WatchCount()
+	current.manager.Stop()
+	log.Info(fmt.Sprintf("stopped %d watchers of mapping %s", stopped, key))
+	return stopped
+}
+
+// ActiveWatches returns the number of running watchers of the mapping namespace/name
+func ActiveWatches(namespace, name string) int {
+	continuousWatches.Lock()
+	defer continuousWatches.Unlock()
+
+	current, ok := continuousWatches.mappings[types.NamespacedName{Namespace: namespace, Name: name}]
+	if !ok {
+		return 0
+	}
+	return current.manager.WatchCount()
+}
+
+// StopMappingWatches stops the watchers of a mapping that left Continuous mode or was paused and clears
+// status.activeWatches
+func StopMappingWatches(ctx context.Context, c client.Client, mapping *drv1alpha1.NamespaceMapping) error {
+	StopWatches(mapping.Namespace, mapping.Name)
+	if mapping.Status.ActiveWatches == 0 {
+		return nil
+	}
+
+	patch := client.MergeFrom(mapping.DeepCopy())
+	mapping.Status.ActiveWatches = 0
+	if err := c.Status().Patch(ctx, mapping, patch); err != nil {
+		return fmt.Errorf("failed to clear active watches: %w", err)
+	}
+	return nil
+}
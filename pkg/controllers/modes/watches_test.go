@@ -0,0 +1,61 @@
+package modes
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestWatchManagerFor(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	source := dynamicfake.NewSimpleDynamicClient(scheme)
+	mapping := &drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "dr-syncer", UID: "uid-1", Generation: 1}}
+	t.Cleanup(func() { StopWatches("dr-syncer", "shop") })
+
+	first := watchManagerFor(mapping, source, nil)
+	assert.Same(t, first, watchManagerFor(mapping, source, nil), "the watchers of a generation are reused")
+
+	configMaps := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	require.NoError(t, first.StartWatching(context.Background(), "shop", []schema.GroupVersionResource{configMaps},
+		func(interface{}) error { return nil }))
+	assert.Equal(t, 1, ActiveWatches("dr-syncer", "shop"))
+
+	mapping.Generation = 2
+	second := watchManagerFor(mapping, source, nil)
+	assert.NotSame(t, first, second)
+	assert.False(t, first.IsWatching(), "the watchers of the previous generation are stopped")
+	assert.Equal(t, 0, ActiveWatches("dr-syncer", "shop"))
+
+	require.NoError(t, second.StartWatching(context.Background(), "shop", []schema.GroupVersionResource{configMaps},
+		func(interface{}) error { return nil }))
+	assert.Equal(t, 1, StopWatches("dr-syncer", "shop"))
+	assert.False(t, second.IsWatching())
+	assert.Equal(t, 0, StopWatches("dr-syncer", "shop"))
+}
+
+func TestStopMappingWatches(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+	mapping := &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "dr-syncer"},
+		Status:     drv1alpha1.NamespaceMappingStatus{ActiveWatches: 3},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(mapping).WithStatusSubresource(mapping).Build()
+
+	require.NoError(t, StopMappingWatches(context.Background(), c, mapping))
+
+	var stored drv1alpha1.NamespaceMapping
+	require.NoError(t, c.Get(context.Background(), client.ObjectKeyFromObject(mapping), &stored))
+	assert.Zero(t, stored.Status.ActiveWatches)
+}
@@ -2,27 +2,49 @@ package controllers
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
 	"github.com/supporttools/dr-syncer/pkg/controllers/modes"
 	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/syncstate"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 )
 
 const (
 	// NamespaceMappingFinalizerName is the name of the finalizer added to NamespaceMapping resources
 	NamespaceMappingFinalizerName = "dr-syncer.io/cleanup-namespacemapping"
+
+	// pvcSyncDrainRequeue is how often a deleted mapping checks whether its PVC data syncs finished
+	pvcSyncDrainRequeue = 30 * time.Second
 )
 
 // NamespaceMappingReconciler reconciles a NamespaceMapping object
 type NamespaceMappingReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+	// Recorder records sync events on NamespaceMappings
+	Recorder record.EventRecorder
+	// SourceClientFor returns a client for the source cluster of a NamespaceMapping during deletion.
+	// Defaults to a client built from the RemoteCluster's kubeconfig secret.
+	SourceClientFor func(ctx context.Context, cluster *drv1alpha1.RemoteCluster) (kubernetes.Interface, error)
+	// DestinationClientsFor returns the clients and REST config for the destination cluster of a
+	// NamespaceMapping. Defaults to clients built from the RemoteCluster's kubeconfig secret.
+	DestinationClientsFor func(ctx context.Context, cluster *drv1alpha1.RemoteCluster) (kubernetes.Interface, dynamic.Interface, *rest.Config, error)
 	// No longer storing modeHandler as a field since we'll create a new one for each reconciliation
 }
 
@@ -30,23 +52,42 @@ type NamespaceMappingReconciler struct {
 func (r *NamespaceMappingReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	logging.LogInfo(nil, "setting up NamespaceMapping controller")
 
+	// Mappings are watched with their priority so overdue and RPO-critical mappings are reconciled first
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&drv1alpha1.NamespaceMapping{}).
-		Owns(&drv1alpha1.ClusterMapping{}).
+		Named("namespacemapping").
+		Watches(&drv1alpha1.NamespaceMapping{}, &mappingPriorityHandler{}).
+		Watches(&drv1alpha1.ClusterMapping{}, handler.EnqueueRequestForOwner(mgr.GetScheme(), mgr.GetRESTMapper(),
+			&drv1alpha1.NamespaceMapping{}, handler.OnlyControllerOwner())).
 		WithOptions(controller.Options{
 			MaxConcurrentReconciles: 5, // Adjust based on expected load
+			NewQueue:                newMappingQueue,
 		}).
 		Complete(r)
 }
 
 // Reconcile handles the reconciliation loop for NamespaceMapping resources
 func (r *NamespaceMappingReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	logging.LogInfo(nil, fmt.Sprintf("starting reconciliation for %s/%s", req.Namespace, req.Name))
+	ctx, span := tracing.Start(ctx, "NamespaceMapping.Reconcile", attribute.String("namespacemapping", req.String()))
+	syncstate.ReconcileStarted(req.Namespace, req.Name)
+	result, err := r.reconcile(ctx, req)
+	syncstate.ReconcileFinished(req.Namespace, req.Name, result.RequeueAfter, err)
+	tracing.End(span, err)
+	return result, err
+}
+
+// reconcile reconciles a NamespaceMapping, its state is recorded for /debug/syncstate by Reconcile
+func (r *NamespaceMappingReconciler) reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logging.LogInfo(log.WithContext(ctx), fmt.Sprintf("starting reconciliation for %s/%s", req.Namespace, req.Name))
+
+	// Attribute destination mutations made during this reconcile to the mapping
+	ctx = audit.WithMapping(ctx, req.NamespacedName.String())
 
 	// Fetch the NamespaceMapping instance
 	var namespacemapping drv1alpha1.NamespaceMapping
 	if err := r.Get(ctx, req.NamespacedName, &namespacemapping); err != nil {
 		if apierrors.IsNotFound(err) {
+			syncstate.ForgetMapping(req.Namespace, req.Name)
+			modes.StopWatches(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
 		logging.LogError(nil, fmt.Sprintf("unable to fetch NamespaceMapping: %v", err))
@@ -55,16 +96,16 @@ func (r *NamespaceMappingReconciler) Reconcile(ctx context.Context, req ctrl.Req
 
 	// Handle deletion
 	if !namespacemapping.DeletionTimestamp.IsZero() {
+		forgetMappingRPO(&namespacemapping)
+		forgetRPOBreach(&namespacemapping)
+		modes.StopWatches(namespacemapping.Namespace, namespacemapping.Name)
 		return r.handleDeletion(ctx, &namespacemapping)
 	}
 
-	// Check if the NamespaceMapping is paused
-	if namespacemapping.Spec.Paused != nil && *namespacemapping.Spec.Paused {
-		logging.LogInfo(nil, fmt.Sprintf("skipping reconciliation for paused NamespaceMapping %s/%s", namespacemapping.Namespace, namespacemapping.Name))
-		return ctrl.Result{}, nil
-	}
+	// Report the last successful sync, also covering syncs completed before a controller restart
+	observeMappingRPO(&namespacemapping)
 
-	// Add finalizer if it doesn't exist
+	// Add finalizer if it doesn't exist, paused mappings included so their deletion follows the cleanup policy
 	if !containsString(namespacemapping.Finalizers, NamespaceMappingFinalizerName) {
 		logging.LogInfo(nil, "adding finalizer")
 		namespacemapping.Finalizers = append(namespacemapping.Finalizers, NamespaceMappingFinalizerName)
@@ -75,16 +116,50 @@ func (r *NamespaceMappingReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		return ctrl.Result{}, nil
 	}
 
+	// Check if the NamespaceMapping is paused
+	if namespacemapping.Spec.Paused != nil && *namespacemapping.Spec.Paused {
+		logging.LogInfo(nil, fmt.Sprintf("skipping reconciliation for paused NamespaceMapping %s/%s", namespacemapping.Namespace, namespacemapping.Name))
+		if err := modes.StopMappingWatches(ctx, r.Client, &namespacemapping); err != nil {
+			return ctrl.Result{}, err
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// Create a new mode handler for this specific reconciliation
 	modeHandler, err := r.setupModeHandlerForNamespaceMapping(ctx, &namespacemapping)
+	if errors.Is(err, errClusterMappingNotAllowed) {
+		// Retrying won't help until the ClusterMapping owner grants access
+		return ctrl.Result{RequeueAfter: clusterMappingDeniedRequeue}, nil
+	}
+	if errors.Is(err, errTopologyConflict) {
+		// Retrying won't help until the conflicting mappings are paused or deleted
+		return ctrl.Result{RequeueAfter: topologyConflictRequeue}, nil
+	}
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	// A requested failover test runs in its own temporary namespace before the mode reconciles the mapping,
+	// its outcome is recorded in the status
+	if modes.FailoverTestRequested(&namespacemapping) {
+		if err := modeHandler.RunFailoverTest(ctx, &namespacemapping); err != nil {
+			logging.LogError(nil, fmt.Sprintf("failed to run failover test: %v", err))
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Watchers of a mapping switched away from Continuous mode stop without a controller restart
+	if namespacemapping.Spec.ReplicationMode != drv1alpha1.ContinuousMode {
+		if err := modes.StopMappingWatches(ctx, r.Client, &namespacemapping); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Handle reconciliation based on replication mode
 	logging.LogInfo(nil, fmt.Sprintf("starting %s mode reconciliation", namespacemapping.Spec.ReplicationMode))
 
 	var result ctrl.Result
+	previousPhase := namespacemapping.Status.Phase
 
 	// Use the NamespaceMapping with the newly created mode handler
 	switch namespacemapping.Spec.ReplicationMode {
@@ -96,6 +171,11 @@ func (r *NamespaceMappingReconciler) Reconcile(ctx context.Context, req ctrl.Req
 		result, err = modeHandler.ReconcileScheduled(ctx, &namespacemapping)
 	}
 
+	// The mode handler updates the mapping's status in place
+	observeMappingRPO(&namespacemapping)
+	r.notifySyncFailure(ctx, &namespacemapping, previousPhase, err)
+	r.notifyRPOBreach(ctx, &namespacemapping)
+
 	if err != nil {
 		logging.LogError(nil, fmt.Sprintf("failed to reconcile namespacemapping: %v", err))
 		return result, err // Return result along with error to respect backoff
@@ -119,20 +199,12 @@ func (r *NamespaceMappingReconciler) handleDeletion(ctx context.Context, namespa
 	var destCluster string
 	if namespacemapping.Spec.ClusterMappingRef != nil {
 		// Fetch the ClusterMapping instance
-		var clusterMapping drv1alpha1.ClusterMapping
-		clusterMappingNamespace := namespacemapping.Spec.ClusterMappingRef.Namespace
-		if clusterMappingNamespace == "" {
-			clusterMappingNamespace = namespacemapping.Namespace
-		}
-
-		if err := r.Get(ctx, client.ObjectKey{
-			Name:      namespacemapping.Spec.ClusterMappingRef.Name,
-			Namespace: clusterMappingNamespace,
-		}, &clusterMapping); err != nil {
-			if apierrors.IsNotFound(err) {
-				// ClusterMapping not found, can't determine destination cluster,
-				// so we can just skip cleanup and remove the finalizer
-				logging.LogInfo(nil, "skipping cleanup as ClusterMapping no longer exists")
+		clusterMapping, err := getClusterMapping(ctx, r.Client, namespacemapping)
+		if err != nil {
+			if apierrors.IsNotFound(err) || errors.Is(err, errClusterMappingNotAllowed) {
+				// ClusterMapping not found or not usable from this namespace, can't determine
+				// destination cluster, so we can just skip cleanup and remove the finalizer
+				logging.LogInfo(nil, fmt.Sprintf("skipping cleanup as ClusterMapping is unavailable: %v", err))
 				namespacemapping.Finalizers = removeString(namespacemapping.Finalizers, NamespaceMappingFinalizerName)
 				if err := r.Update(ctx, namespacemapping); err != nil {
 					logging.LogError(nil, fmt.Sprintf("failed to remove finalizer: %v", err))
@@ -161,19 +233,68 @@ func (r *NamespaceMappingReconciler) handleDeletion(ctx context.Context, namespa
 
 	logging.LogInfo(nil, fmt.Sprintf("initializing destination cluster connection for cleanup: %s", destCluster))
 
-	// Create a new mode handler with only destination cluster clients
+	// The destination clients remove the resources selected by the cleanup policy. A destination cluster
+	// that no longer exists has nothing left to clean up, and a mapping leaving its resources in place
+	// does not wait for an unreachable one.
+	destClient, destDynamicClient, destConfig, err := r.destinationClients(ctx, namespacemapping)
+	if err != nil {
+		if !apierrors.IsNotFound(err) && cleanupRemovesResources(namespacemapping) {
+			logging.LogError(nil, fmt.Sprintf("failed to connect to destination cluster for cleanup: %v", err))
+			return ctrl.Result{}, err
+		}
+		logging.LogInfo(nil, fmt.Sprintf("cleaning up without the destination cluster: %v", err))
+		destClient, destDynamicClient, destConfig = nil, nil, nil
+	}
+
+	// The source client finds the PVC data syncs still in flight by their locks. Without it, the rsync
+	// deployments in the destination namespace are checked instead.
+	sourceClient, err := r.sourceClient(ctx, namespacemapping)
+	if err != nil {
+		logging.LogInfo(nil, fmt.Sprintf("source cluster unavailable, checking the destination for in-flight PVC syncs: %v", err))
+		sourceClient = nil
+	}
+
 	cleanupModeHandler := modes.NewModeReconciler(
 		r.Client,
-		nil,         // No source dynamic client needed for cleanup
-		nil,         // No destination dynamic client needed for cleanup
-		nil,         // No source client needed for cleanup
-		nil,         // No destination client needed for cleanup
-		nil,         // No source config needed for cleanup
-		nil,         // No dest config needed for CleanupResources
+		nil,               // No source dynamic client needed for cleanup
+		destDynamicClient, // Destination dynamic client, used to delete the synced resources
+		sourceClient,      // Source client, used to find in-flight PVC data syncs
+		destClient,        // Destination client, used to release warm pool pods and discover resource types
+		nil,               // No source config needed for cleanup
+		destConfig,
 		"",          // No source cluster name needed for cleanup
 		destCluster, // Pass destination cluster name for logging
 	)
 
+	// Wait for PVC data syncs to finish so cleanup never races a transfer into the destination. The
+	// wait is bounded by the stale lock timeout of the mapping, after which a sync would be taken over.
+	inFlight, err := cleanupModeHandler.InFlightPVCSyncs(ctx, namespacemapping)
+	if err != nil || len(inFlight) > 0 {
+		drainTimeout := modes.StaleLockTimeout(namespacemapping)
+		waited := time.Duration(0)
+		if namespacemapping.DeletionTimestamp != nil {
+			waited = time.Since(namespacemapping.DeletionTimestamp.Time)
+		}
+		switch {
+		case waited >= drainTimeout:
+			logging.LogInfo(nil, fmt.Sprintf("cleaning up after waiting %s for in-flight PVC syncs %v: %v", drainTimeout, inFlight, err))
+		case err != nil:
+			logging.LogError(nil, fmt.Sprintf("failed to check for in-flight PVC syncs: %v", err))
+			return ctrl.Result{}, err
+		default:
+			logging.LogInfo(nil, fmt.Sprintf("waiting for %d in-flight PVC syncs to complete before cleanup: %v", len(inFlight), inFlight))
+			return ctrl.Result{RequeueAfter: pvcSyncDrainRequeue}, nil
+		}
+	}
+
+	// Deletions are constrained to the impersonated identity like every other destination write
+	if destClient != nil || destDynamicClient != nil {
+		if err := cleanupModeHandler.ImpersonateDestination(namespacemapping); err != nil {
+			logging.LogError(nil, fmt.Sprintf("unable to impersonate destination identity for cleanup: %v", err))
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Clean up synced resources in destination cluster
 	if err := cleanupModeHandler.CleanupResources(ctx, namespacemapping); err != nil {
 		logging.LogError(nil, fmt.Sprintf("failed to cleanup resources: %v", err))
@@ -204,19 +325,21 @@ func (r *NamespaceMappingReconciler) setupModeHandlerForNamespaceMapping(
 	// Get source and destination clusters from ClusterMapping or direct specification
 	if namespacemapping.Spec.ClusterMappingRef != nil {
 		// Fetch the ClusterMapping instance
-		var clusterMapping drv1alpha1.ClusterMapping
-		clusterMappingNamespace := namespacemapping.Spec.ClusterMappingRef.Namespace
-		if clusterMappingNamespace == "" {
-			clusterMappingNamespace = namespacemapping.Namespace
+		clusterMapping, err := getClusterMapping(ctx, r.Client, namespacemapping)
+		if errors.Is(err, errClusterMappingNotAllowed) {
+			logging.LogError(nil, fmt.Sprintf("refusing to use ClusterMapping: %v", err))
+			if statusErr := r.setClusterMappingAccepted(ctx, namespacemapping, err); statusErr != nil {
+				return nil, statusErr
+			}
+			return nil, err
 		}
-
-		if err := r.Get(ctx, client.ObjectKey{
-			Name:      namespacemapping.Spec.ClusterMappingRef.Name,
-			Namespace: clusterMappingNamespace,
-		}, &clusterMapping); err != nil {
+		if err != nil {
 			logging.LogError(nil, fmt.Sprintf("unable to fetch ClusterMapping: %v", err))
 			return nil, err
 		}
+		if err := r.setClusterMappingAccepted(ctx, namespacemapping, nil); err != nil {
+			return nil, err
+		}
 
 		// Get source and destination clusters from the ClusterMapping
 		sourceCluster = clusterMapping.Spec.SourceCluster
@@ -225,6 +348,9 @@ func (r *NamespaceMappingReconciler) setupModeHandlerForNamespaceMapping(
 		// Add these to the NamespaceMapping spec for easier access
 		namespacemapping.Spec.SourceCluster = sourceCluster
 		namespacemapping.Spec.DestinationCluster = destCluster
+
+		// Inherit the storage class, access mode and ingress class mappings of the ClusterMapping
+		applyClusterMappingDefaults(namespacemapping, clusterMapping)
 	} else {
 		// Use directly specified source and destination clusters
 		if namespacemapping.Spec.SourceCluster == "" || namespacemapping.Spec.DestinationCluster == "" {
@@ -237,18 +363,78 @@ func (r *NamespaceMappingReconciler) setupModeHandlerForNamespaceMapping(
 		destCluster = namespacemapping.Spec.DestinationCluster
 	}
 
+	syncstate.MappingClusters(namespacemapping.Namespace, namespacemapping.Name, sourceCluster, destCluster)
+
+	// Refuse mappings that would write into another mapping's destination or loop back to their source
+	if err := r.checkTopology(ctx, namespacemapping, sourceCluster, destCluster); err != nil {
+		return nil, err
+	}
+
+	// The destination REST config is required to impersonate the mapping's destination identity, a
+	// mapping requesting impersonation never reconciles without it
+	destClient, destDynamicClient, destConfig, err := r.destinationClients(ctx, namespacemapping)
+	if err != nil {
+		if namespacemapping.Spec.DestinationImpersonation != nil {
+			logging.LogError(nil, fmt.Sprintf("unable to connect to destination cluster for impersonation: %v", err))
+			return nil, fmt.Errorf("failed to connect to destination cluster %s for impersonation: %w", destCluster, err)
+		}
+		logging.LogInfo(nil, fmt.Sprintf("destination cluster clients unavailable: %v", err))
+		destClient, destDynamicClient, destConfig = nil, nil, nil
+	}
+
 	// Create a new mode handler to use in the reconciliation
-	return modes.NewModeReconciler(
+	modeHandler := modes.NewModeReconciler(
 		r.Client,
-		nil, // Source dynamic client
-		nil, // Destination dynamic client
-		nil, // Source client
-		nil, // Destination client
-		nil, // Source config
-		nil, // Destination config
+		nil,               // Source dynamic client
+		destDynamicClient, // Destination dynamic client
+		nil,               // Source client
+		destClient,        // Destination client
+		nil,               // Source config
+		destConfig,        // Destination config
 		sourceCluster,
 		destCluster,
-	), nil
+	)
+	modeHandler.SetEventRecorder(r.Recorder)
+
+	// Constrain destination writes to the impersonated identity's RBAC
+	if err := modeHandler.ImpersonateDestination(namespacemapping); err != nil {
+		logging.LogError(nil, fmt.Sprintf("unable to impersonate destination identity: %v", err))
+		return nil, err
+	}
+	return modeHandler, nil
+}
+
+// destinationClients returns the clients and REST config for the destination cluster of a NamespaceMapping
+func (r *NamespaceMappingReconciler) destinationClients(ctx context.Context, namespacemapping *drv1alpha1.NamespaceMapping) (kubernetes.Interface, dynamic.Interface, *rest.Config, error) {
+	cluster, err := destinationRemoteCluster(ctx, r.Client, namespacemapping)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	if r.DestinationClientsFor != nil {
+		return r.DestinationClientsFor(ctx, cluster)
+	}
+	return remoteClusterClients(ctx, r.Client, cluster)
+}
+
+// cleanupRemovesResources reports whether the cleanup policy of a mapping removes destination resources
+func cleanupRemovesResources(namespacemapping *drv1alpha1.NamespaceMapping) bool {
+	policy := namespacemapping.Spec.CleanupPolicy
+	return policy != "" && policy != drv1alpha1.CleanupPolicyNone
+}
+
+// sourceClient returns a client for the source cluster of a NamespaceMapping
+func (r *NamespaceMappingReconciler) sourceClient(ctx context.Context, namespacemapping *drv1alpha1.NamespaceMapping) (kubernetes.Interface, error) {
+	cluster, err := sourceRemoteCluster(ctx, r.Client, namespacemapping)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.SourceClientFor != nil {
+		return r.SourceClientFor(ctx, cluster)
+	}
+	clientset, _, err := remoteClusterClient(ctx, r.Client, cluster)
+	return clientset, err
 }
 
 // Helper functions
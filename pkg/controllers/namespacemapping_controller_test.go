@@ -1,12 +1,28 @@
 package controllers
 
 import (
+	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 	drsyncerio "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controller/replication"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 func TestNamespaceMappingReconciler_Struct(t *testing.T) {
@@ -498,3 +514,259 @@ func TestRemoveString_RealisticFinalizers(t *testing.T) {
 		})
 	}
 }
+
+// deletingMapping returns a mapping of the app namespace being deleted
+func deletingMapping() *drsyncerio.NamespaceMapping {
+	nm := testutil.NewNamespaceMapping("app").
+		WithSourceCluster("prod").
+		WithDestinationCluster("dr").
+		WithSourceNamespace("app").
+		WithDestinationNamespace("app-dr").
+		WithResourceTypes("configmaps").
+		Build()
+	nm.Finalizers = []string{NamespaceMappingFinalizerName}
+	deleted := metav1.Now()
+	nm.DeletionTimestamp = &deleted
+	return nm
+}
+
+func TestHandleDeletion_WaitsForInFlightPVCSyncs(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	nm := deletingMapping()
+	pvc := &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "app", Annotations: map[string]string{
+		"dr-syncer.io/lock-owner":     "controller-0",
+		"dr-syncer.io/lock-timestamp": time.Now().Format(time.RFC3339),
+	}}}
+	source := k8sfake.NewSimpleClientset(pvc)
+
+	var connected *drsyncerio.RemoteCluster
+	r := &NamespaceMappingReconciler{
+		Client: env.NewFakeClient(nm, testutil.NewRemoteCluster("prod").Build()),
+		Scheme: env.Scheme,
+		SourceClientFor: func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, error) {
+			connected = cluster
+			return source, nil
+		},
+	}
+
+	// The source PVC is locked by a running data sync, cleanup waits for it
+	result, err := r.handleDeletion(env.Ctx, nm)
+	require.NoError(t, err)
+	assert.Equal(t, pvcSyncDrainRequeue, result.RequeueAfter)
+	require.NotNil(t, connected)
+	assert.Equal(t, "prod", connected.Name)
+	var stored drsyncerio.NamespaceMapping
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKeyFromObject(nm), &stored))
+	assert.Contains(t, stored.Finalizers, NamespaceMappingFinalizerName)
+
+	// Once the sync released its lock the finalizer is removed
+	pvc.Annotations = nil
+	_, err = source.CoreV1().PersistentVolumeClaims("app").Update(context.Background(), pvc, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	result, err = r.handleDeletion(env.Ctx, &stored)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	err = r.Get(env.Ctx, client.ObjectKeyFromObject(nm), &stored)
+	assert.True(t, apierrors.IsNotFound(err), "the mapping is gone once its finalizer is removed")
+}
+
+// fakeDestination is a destination cluster holding a ConfigMap synced by the deleted mapping and one
+// created in the destination
+type fakeDestination struct {
+	clientset *k8sfake.Clientset
+	dynamic   *dynamicfake.FakeDynamicClient
+	connected *drsyncerio.RemoteCluster
+}
+
+func newFakeDestination(t *testing.T, nm *drsyncerio.NamespaceMapping, objects ...runtime.Object) *fakeDestination {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	synced := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "synced", Namespace: "app-dr", Labels: utils.MappingLabels(nm.Namespace, nm.Name),
+	}}
+	local := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "local", Namespace: "app-dr"}}
+	return &fakeDestination{
+		clientset: k8sfake.NewSimpleClientset(objects...),
+		dynamic:   dynamicfake.NewSimpleDynamicClient(scheme, synced, local),
+	}
+}
+
+func (d *fakeDestination) clientsFor(_ context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, dynamic.Interface, *rest.Config, error) {
+	d.connected = cluster
+	return d.clientset, d.dynamic, nil, nil
+}
+
+func (d *fakeDestination) configMaps(t *testing.T) []string {
+	list, err := d.dynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("app-dr").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.GetName())
+	}
+	return names
+}
+
+// rsyncDeployment returns the rsync deployment of a data sync writing into the data PVC of app-dr
+func rsyncDeployment() *appsv1.Deployment {
+	return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+		Name:              "dr-syncer-rsync-data",
+		Namespace:         "app-dr",
+		CreationTimestamp: metav1.Now(),
+		Labels:            map[string]string{"app.kubernetes.io/name": "dr-syncer-rsync", "dr-syncer.io/pvc-name": "data"},
+	}}
+}
+
+func TestHandleDeletion_CleansUpDestination(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	nm := deletingMapping()
+	nm.Spec.CleanupPolicy = drsyncerio.CleanupPolicySyncedOnly
+	dest := newFakeDestination(t, nm)
+	r := &NamespaceMappingReconciler{
+		Client: env.NewFakeClient(nm, testutil.NewRemoteCluster("prod").Build(), testutil.NewRemoteCluster("dr").Build()),
+		Scheme: env.Scheme,
+		SourceClientFor: func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, error) {
+			return k8sfake.NewSimpleClientset(), nil
+		},
+		DestinationClientsFor: dest.clientsFor,
+	}
+
+	// The resources synced by the mapping are removed from the destination cluster
+	result, err := r.handleDeletion(env.Ctx, nm)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	require.NotNil(t, dest.connected)
+	assert.Equal(t, "dr", dest.connected.Name)
+	assert.Equal(t, []string{"local"}, dest.configMaps(t))
+	err = r.Get(env.Ctx, client.ObjectKeyFromObject(nm), &drsyncerio.NamespaceMapping{})
+	assert.True(t, apierrors.IsNotFound(err), "the mapping is gone once its finalizer is removed")
+}
+
+func TestHandleDeletion_SourceClusterUnreachable(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	nm := deletingMapping()
+	nm.Spec.CleanupPolicy = drsyncerio.CleanupPolicySyncedOnly
+	dest := newFakeDestination(t, nm, rsyncDeployment())
+	r := &NamespaceMappingReconciler{
+		Client: env.NewFakeClient(nm, testutil.NewRemoteCluster("prod").Build(), testutil.NewRemoteCluster("dr").Build()),
+		Scheme: env.Scheme,
+		SourceClientFor: func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, error) {
+			return nil, errors.New("source cluster unreachable")
+		},
+		DestinationClientsFor: dest.clientsFor,
+	}
+
+	// Without the source cluster, the rsync deployment in the destination shows a sync in flight
+	result, err := r.handleDeletion(env.Ctx, nm)
+	require.NoError(t, err)
+	assert.Equal(t, pvcSyncDrainRequeue, result.RequeueAfter)
+	assert.ElementsMatch(t, []string{"local", "synced"}, dest.configMaps(t))
+	var stored drsyncerio.NamespaceMapping
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKeyFromObject(nm), &stored))
+	assert.Contains(t, stored.Finalizers, NamespaceMappingFinalizerName)
+
+	// Once the sync finished, cleanup proceeds with the source cluster still unreachable
+	require.NoError(t, dest.clientset.AppsV1().Deployments("app-dr").Delete(context.Background(), "dr-syncer-rsync-data", metav1.DeleteOptions{}))
+	result, err = r.handleDeletion(env.Ctx, &stored)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Equal(t, []string{"local"}, dest.configMaps(t))
+}
+
+func TestHandleDeletion_DrainTimeout(t *testing.T) {
+	t.Setenv("LOCK_TIMEOUT_MINUTES", "")
+	env := testutil.NewTestEnv(t)
+	nm := deletingMapping()
+	nm.Spec.CleanupPolicy = drsyncerio.CleanupPolicySyncedOnly
+	nm.Spec.PVCConfig = &drsyncerio.PVCConfig{DataSyncConfig: &drsyncerio.PVCDataSyncConfig{
+		StaleLockTimeout: &metav1.Duration{Duration: 3 * time.Hour},
+	}}
+	deleted := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	nm.DeletionTimestamp = &deleted
+	dest := newFakeDestination(t, nm)
+	r := &NamespaceMappingReconciler{
+		Client: env.NewFakeClient(nm, testutil.NewRemoteCluster("prod").Build(), testutil.NewRemoteCluster("dr").Build()),
+		Scheme: env.Scheme,
+		SourceClientFor: func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, error) {
+			return k8sfake.NewSimpleClientset(&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{
+				Name: "data", Namespace: "app", Annotations: map[string]string{
+					replication.LockOwnerAnnotation: "controller-0",
+				},
+			}}), nil
+		},
+		DestinationClientsFor: dest.clientsFor,
+	}
+
+	// The wait for a lock that is never released lasts the stale lock timeout of the mapping
+	result, err := r.handleDeletion(env.Ctx, nm)
+	require.NoError(t, err)
+	assert.Equal(t, pvcSyncDrainRequeue, result.RequeueAfter)
+
+	nm.Spec.PVCConfig = nil
+	result, err = r.handleDeletion(env.Ctx, nm)
+	require.NoError(t, err)
+	assert.Zero(t, result.RequeueAfter)
+	assert.Equal(t, []string{"local"}, dest.configMaps(t))
+}
+
+func TestHandleDeletion_DestinationClusterUnreachable(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	nm := deletingMapping()
+	nm.Spec.CleanupPolicy = drsyncerio.CleanupPolicySyncedOnly
+	r := &NamespaceMappingReconciler{
+		Client: env.NewFakeClient(nm, testutil.NewRemoteCluster("prod").Build(), testutil.NewRemoteCluster("dr").Build()),
+		Scheme: env.Scheme,
+		SourceClientFor: func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, error) {
+			return k8sfake.NewSimpleClientset(), nil
+		},
+		DestinationClientsFor: func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, dynamic.Interface, *rest.Config, error) {
+			return nil, nil, nil, errors.New("destination cluster unreachable")
+		},
+	}
+
+	// Resources to remove keep the finalizer until the destination cluster can be reached
+	_, err := r.handleDeletion(env.Ctx, nm)
+	require.Error(t, err)
+	var stored drsyncerio.NamespaceMapping
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKeyFromObject(nm), &stored))
+	assert.Contains(t, stored.Finalizers, NamespaceMappingFinalizerName)
+
+	// A mapping leaving its resources in place does not wait for the destination cluster
+	stored.Spec.CleanupPolicy = drsyncerio.CleanupPolicyNone
+	_, err = r.handleDeletion(env.Ctx, &stored)
+	require.NoError(t, err)
+}
+
+func TestSetupModeHandler_Impersonation(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	nm := testutil.NewNamespaceMapping("app").
+		WithSourceCluster("prod").
+		WithDestinationCluster("dr").
+		WithSourceNamespace("app").
+		Build()
+	nm.Spec.DestinationImpersonation = &drsyncerio.ImpersonationConfig{User: "team-app"}
+
+	// Without the destination cluster's config, the mapping is not written with the controller's identity
+	r := &NamespaceMappingReconciler{
+		Client: env.NewFakeClient(nm, testutil.NewRemoteCluster("prod").Build(), testutil.NewRemoteCluster("dr").Build()),
+		Scheme: env.Scheme,
+		DestinationClientsFor: func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, dynamic.Interface, *rest.Config, error) {
+			return nil, nil, nil, errors.New("kubeconfig secret not found")
+		},
+	}
+	_, err := r.setupModeHandlerForNamespaceMapping(env.Ctx, nm)
+	assert.Error(t, err)
+
+	r.DestinationClientsFor = func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, dynamic.Interface, *rest.Config, error) {
+		return k8sfake.NewSimpleClientset(), nil, nil, nil
+	}
+	_, err = r.setupModeHandlerForNamespaceMapping(env.Ctx, nm)
+	assert.Error(t, err)
+
+	// The destination config is impersonated
+	r.DestinationClientsFor = func(ctx context.Context, cluster *drsyncerio.RemoteCluster) (kubernetes.Interface, dynamic.Interface, *rest.Config, error) {
+		return k8sfake.NewSimpleClientset(), nil, &rest.Config{Host: "https://dr.example.com"}, nil
+	}
+	modeHandler, err := r.setupModeHandlerForNamespaceMapping(env.Ctx, nm)
+	require.NoError(t, err)
+	assert.NotNil(t, modeHandler)
+}
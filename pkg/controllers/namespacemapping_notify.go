@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"github.com/supporttools/dr-syncer/pkg/notify"
+	"github.com/supporttools/dr-syncer/pkg/rpo"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// rpoBreachFactor is the multiple of the RPO target after which an RPO breach is notified,
+// so a breach means at least one scheduled sync was missed rather than a sync running late
+const rpoBreachFactor = 2
+
+var (
+	// rpoBreachesMu guards rpoBreaches, the mappings whose RPO breach was already notified
+	rpoBreachesMu sync.Mutex
+	rpoBreaches   = make(map[types.NamespacedName]bool)
+)
+
+// mappingWebhooks resolves the notification webhooks configured on a mapping. Webhooks whose
+// URL secret cannot be read are skipped.
+func (r *NamespaceMappingReconciler) mappingWebhooks(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) []notify.Webhook {
+	if mapping.Spec.Notifications == nil {
+		return nil
+	}
+
+	var webhooks []notify.Webhook
+	for _, configured := range mapping.Spec.Notifications.Webhooks {
+		url := configured.URL
+		if configured.URLSecretRef != nil {
+			key := configured.URLSecretRef.Key
+			if key == "" {
+				key = "url"
+			}
+			var secret corev1.Secret
+			if err := r.Get(ctx, types.NamespacedName{Namespace: mapping.Namespace, Name: configured.URLSecretRef.Name}, &secret); err != nil {
+				logging.LogError(nil, fmt.Sprintf("failed to get notification webhook secret %s/%s: %v", mapping.Namespace, configured.URLSecretRef.Name, err))
+				continue
+			}
+			url = string(secret.Data[key])
+		}
+		if url == "" {
+			logging.LogError(nil, fmt.Sprintf("notification webhook of NamespaceMapping %s/%s has no URL", mapping.Namespace, mapping.Name))
+			continue
+		}
+
+		webhook := notify.Webhook{URL: url, Format: notify.Format(configured.Format)}
+		for _, event := range configured.Events {
+			webhook.Events = append(webhook.Events, notify.Event(event))
+		}
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks
+}
+
+// notifyMapping dispatches a notification about a mapping to the controller-wide and mapping webhooks
+func (r *NamespaceMappingReconciler) notifyMapping(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, event notify.Event, message string) {
+	destNamespace := mapping.Spec.DestinationNamespace
+	if destNamespace == "" {
+		destNamespace = mapping.Spec.SourceNamespace
+	}
+	notify.Dispatch(ctx, notify.Notification{
+		Event:                event,
+		Mapping:              fmt.Sprintf("%s/%s", mapping.Namespace, mapping.Name),
+		SourceNamespace:      mapping.Spec.SourceNamespace,
+		DestinationNamespace: destNamespace,
+		Message:              message,
+	}, r.mappingWebhooks(ctx, mapping)...)
+}
+
+// notifySyncFailure sends SyncFailed when a sync of the mapping fails after it was not failing,
+// so retries of a failing sync are only notified once
+func (r *NamespaceMappingReconciler) notifySyncFailure(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, previousPhase drv1alpha1.SyncPhase, syncErr error) {
+	if syncErr == nil && mapping.Status.Phase != drv1alpha1.SyncPhaseFailed {
+		return
+	}
+	if previousPhase == drv1alpha1.SyncPhaseFailed {
+		return
+	}
+
+	var message string
+	switch {
+	case syncErr != nil:
+		message = syncErr.Error()
+	case mapping.Status.LastError != nil:
+		message = mapping.Status.LastError.Message
+	}
+	r.notifyMapping(ctx, mapping, notify.EventSyncFailed, message)
+}
+
+// notifyRPOBreach sends RPOBreached once when the estimated RPO of the mapping exceeds
+// rpoBreachFactor times its target, and rearms after the RPO recovers
+func (r *NamespaceMappingReconciler) notifyRPOBreach(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) {
+	estimate, target, ok := rpo.MappingRPO(mapping.Namespace, mapping.Name)
+	breached := ok && target > 0 && estimate > rpoBreachFactor*target
+
+	key := types.NamespacedName{Namespace: mapping.Namespace, Name: mapping.Name}
+	rpoBreachesMu.Lock()
+	alreadyNotified := rpoBreaches[key]
+	if breached {
+		rpoBreaches[key] = true
+	} else {
+		delete(rpoBreaches, key)
+	}
+	rpoBreachesMu.Unlock()
+
+	if !breached || alreadyNotified {
+		return
+	}
+	r.notifyMapping(ctx, mapping, notify.EventRPOBreached,
+		fmt.Sprintf("last successful sync was %s ago, RPO target is %s", estimate.Round(time.Second), target))
+}
+
+// forgetRPOBreach drops the RPO breach state of a deleted mapping
+func forgetRPOBreach(mapping *drv1alpha1.NamespaceMapping) {
+	rpoBreachesMu.Lock()
+	defer rpoBreachesMu.Unlock()
+	delete(rpoBreaches, types.NamespacedName{Namespace: mapping.Namespace, Name: mapping.Name})
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/notify"
+	"github.com/supporttools/dr-syncer/pkg/rpo"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+// notificationRecorder is a webhook receiving generic notifications
+func notificationRecorder(t *testing.T) (*httptest.Server, *[]notify.Notification) {
+	var received []notify.Notification
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var n notify.Notification
+		if err := json.NewDecoder(r.Body).Decode(&n); err == nil {
+			received = append(received, n)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func notifyMapping(name, url string, events ...drv1alpha1.NotificationEvent) *drv1alpha1.NamespaceMapping {
+	return &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "dr-syncer"},
+		Spec: drv1alpha1.NamespaceMappingSpec{
+			SourceNamespace:      "shop",
+			DestinationNamespace: "shop-dr",
+			Notifications: &drv1alpha1.NotificationConfig{Webhooks: []drv1alpha1.NotificationWebhook{
+				{URL: url, Events: events},
+			}},
+		},
+	}
+}
+
+func TestMappingWebhooks_SecretURL(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	r := &NamespaceMappingReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "slack", Namespace: "dr-syncer"},
+			Data:       map[string][]byte{"url": []byte("https://hooks.slack.com/services/T/B/X")},
+		},
+	).Build()}
+
+	mapping := notifyMapping("shop", "https://hooks.example.com/dr", drv1alpha1.NotificationEventSyncFailed)
+	mapping.Spec.Notifications.Webhooks = append(mapping.Spec.Notifications.Webhooks,
+		drv1alpha1.NotificationWebhook{URLSecretRef: &drv1alpha1.WebhookURLSecretRef{Name: "slack"}, Format: drv1alpha1.NotificationFormatSlack},
+		drv1alpha1.NotificationWebhook{URLSecretRef: &drv1alpha1.WebhookURLSecretRef{Name: "missing"}},
+	)
+
+	assert.Equal(t, []notify.Webhook{
+		{URL: "https://hooks.example.com/dr", Format: "", Events: []notify.Event{notify.EventSyncFailed}},
+		{URL: "https://hooks.slack.com/services/T/B/X", Format: notify.FormatSlack},
+	}, r.mappingWebhooks(context.Background(), mapping))
+}
+
+func TestNotifySyncFailure(t *testing.T) {
+	server, received := notificationRecorder(t)
+	r := &NamespaceMappingReconciler{}
+	mapping := notifyMapping("shop", server.URL, drv1alpha1.NotificationEventSyncFailed)
+	ctx := context.Background()
+
+	// Successful syncs are not notified
+	mapping.Status.Phase = drv1alpha1.SyncPhaseCompleted
+	r.notifySyncFailure(ctx, mapping, drv1alpha1.SyncPhaseRunning, nil)
+	assert.Empty(t, *received)
+
+	// The first failure is notified, retries of the failing sync are not
+	mapping.Status.Phase = drv1alpha1.SyncPhaseFailed
+	r.notifySyncFailure(ctx, mapping, drv1alpha1.SyncPhaseCompleted, errors.New("failed to sync Deployment/web"))
+	r.notifySyncFailure(ctx, mapping, drv1alpha1.SyncPhaseFailed, errors.New("failed to sync Deployment/web"))
+
+	require.Len(t, *received, 1)
+	assert.Equal(t, notify.EventSyncFailed, (*received)[0].Event)
+	assert.Equal(t, "dr-syncer/shop", (*received)[0].Mapping)
+	assert.Equal(t, "shop-dr", (*received)[0].DestinationNamespace)
+	assert.Equal(t, "failed to sync Deployment/web", (*received)[0].Message)
+}
+
+func TestNotifyRPOBreach(t *testing.T) {
+	server, received := notificationRecorder(t)
+	r := &NamespaceMappingReconciler{}
+	mapping := notifyMapping("rpo-breach", server.URL)
+	t.Cleanup(func() {
+		rpo.ForgetMapping(mapping.Namespace, mapping.Name)
+		forgetRPOBreach(mapping)
+	})
+	ctx := context.Background()
+
+	// Within the target nothing is sent
+	rpo.RecordMappingSync(mapping.Namespace, mapping.Name, time.Now().Add(-6*time.Minute), 5*time.Minute)
+	r.notifyRPOBreach(ctx, mapping)
+	assert.Empty(t, *received)
+
+	// A breach is notified once
+	rpo.ForgetMapping(mapping.Namespace, mapping.Name)
+	rpo.RecordMappingSync(mapping.Namespace, mapping.Name, time.Now().Add(-11*time.Minute), 5*time.Minute)
+	r.notifyRPOBreach(ctx, mapping)
+	r.notifyRPOBreach(ctx, mapping)
+	require.Len(t, *received, 1)
+	assert.Equal(t, notify.EventRPOBreached, (*received)[0].Event)
+
+	// After recovering, the next breach is notified again
+	rpo.RecordMappingSync(mapping.Namespace, mapping.Name, time.Now(), 5*time.Minute)
+	r.notifyRPOBreach(ctx, mapping)
+	rpo.ForgetMapping(mapping.Namespace, mapping.Name)
+	rpo.RecordMappingSync(mapping.Namespace, mapping.Name, time.Now().Add(-time.Hour), 5*time.Minute)
+	r.notifyRPOBreach(ctx, mapping)
+	assert.Len(t, *received, 2)
+}
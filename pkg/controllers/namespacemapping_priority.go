@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/syncstate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/priorityqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+const (
+	// RPOCriticalAnnotation marks a NamespaceMapping whose reconciles are queued before those of other
+	// mappings, such as after controller downtime
+	RPOCriticalAnnotation = "dr-syncer.io/rpo-critical"
+
+	// rpoCriticalPriority is added to the queue priority of RPO-critical mappings, above any overdue mapping
+	rpoCriticalPriority = 1000000
+
+	// maxOverduePriority caps the queue priority of overdue mappings, which grows by one per minute overdue
+	maxOverduePriority = 7 * 24 * 60
+)
+
+// mappingPriority returns the queue priority of a NamespaceMapping reconcile, higher priorities are
+// reconciled first. Mappings whose next sync is overdue go first, the longest overdue ahead, and
+// mappings annotated RPO-critical go before all others.
+func mappingPriority(mapping *drv1alpha1.NamespaceMapping, now time.Time) int {
+	priority := 0
+	if next := mapping.Status.NextSyncTime; next != nil && next.Time.Before(now) {
+		priority = 1 + int(now.Sub(next.Time)/time.Minute)
+		if priority > maxOverduePriority {
+			priority = maxOverduePriority
+		}
+	}
+	if mapping.Annotations[RPOCriticalAnnotation] == "true" {
+		priority += rpoCriticalPriority
+	}
+	return priority
+}
+
+// newMappingQueue creates the priority queue of the NamespaceMapping controller, so the reconciles queued
+// by the initial list after a restart are ordered by mappingPriority instead of arbitrarily
+func newMappingQueue(controllerName string, rateLimiter workqueue.TypedRateLimiter[reconcile.Request]) workqueue.TypedRateLimitingInterface[reconcile.Request] {
+	queue := priorityqueue.New(controllerName, func(o *priorityqueue.Opts[reconcile.Request]) {
+		o.RateLimiter = rateLimiter
+	})
+	// Export the mappings waiting for a worker, so a controller falling behind its schedules shows
+	syncstate.TrackQueue(queue.Len)
+	return queue
+}
+
+// mappingPriorityHandler enqueues NamespaceMapping events with the priority of the mapping. Queues
+// without priorities get a plain add.
+type mappingPriorityHandler struct {
+	now func() time.Time
+}
+
+// Create implements handler.EventHandler
+func (h *mappingPriorityHandler) Create(_ context.Context, e event.CreateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	h.enqueue(e.Object, q)
+}
+
+// Update implements handler.EventHandler
+func (h *mappingPriorityHandler) Update(_ context.Context, e event.UpdateEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	h.enqueue(e.ObjectNew, q)
+}
+
+// Delete implements handler.EventHandler
+func (h *mappingPriorityHandler) Delete(_ context.Context, e event.DeleteEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	q.Add(reconcile.Request{NamespacedName: client.ObjectKeyFromObject(e.Object)})
+}
+
+// Generic implements handler.EventHandler
+func (h *mappingPriorityHandler) Generic(_ context.Context, e event.GenericEvent, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	h.enqueue(e.Object, q)
+}
+
+func (h *mappingPriorityHandler) enqueue(obj client.Object, q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+	request := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(obj)}
+	mapping, isMapping := obj.(*drv1alpha1.NamespaceMapping)
+	priorityQueue, isPriorityQueue := q.(priorityqueue.PriorityQueue[reconcile.Request])
+	if !isMapping || !isPriorityQueue {
+		q.Add(request)
+		return
+	}
+
+	now := time.Now
+	if h.now != nil {
+		now = h.now
+	}
+	priorityQueue.AddWithOpts(priorityqueue.AddOpts{Priority: mappingPriority(mapping, now())}, request)
+}
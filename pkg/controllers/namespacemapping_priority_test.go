@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func priorityTestMapping(name string, nextSync time.Time, critical bool) *drv1alpha1.NamespaceMapping {
+	mapping := &drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{Namespace: "dr", Name: name}}
+	if !nextSync.IsZero() {
+		next := metav1.NewTime(nextSync)
+		mapping.Status.NextSyncTime = &next
+	}
+	if critical {
+		mapping.Annotations = map[string]string{RPOCriticalAnnotation: "true"}
+	}
+	return mapping
+}
+
+func TestMappingPriority(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, 0, mappingPriority(priorityTestMapping("new", time.Time{}, false), now))
+	assert.Equal(t, 0, mappingPriority(priorityTestMapping("due-later", now.Add(time.Hour), false), now))
+	assert.Equal(t, 61, mappingPriority(priorityTestMapping("overdue", now.Add(-time.Hour), false), now))
+	assert.Equal(t, maxOverduePriority, mappingPriority(priorityTestMapping("long-overdue", now.Add(-30*24*time.Hour), false), now))
+	assert.Equal(t, rpoCriticalPriority, mappingPriority(priorityTestMapping("critical", now.Add(time.Hour), true), now))
+}
+
+func TestMappingPriorityHandler_OverdueFirst(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	h := &mappingPriorityHandler{now: func() time.Time { return now }}
+	q := newMappingQueue("test", workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+	defer q.ShutDown()
+
+	// The initial list after a restart creates the mappings in arbitrary order
+	for _, mapping := range []*drv1alpha1.NamespaceMapping{
+		priorityTestMapping("on-time", now.Add(time.Minute), false),
+		priorityTestMapping("overdue-minutes", now.Add(-5*time.Minute), false),
+		priorityTestMapping("critical", now.Add(time.Minute), true),
+		priorityTestMapping("overdue-hours", now.Add(-3*time.Hour), false),
+	} {
+		h.Create(context.Background(), event.CreateEvent{Object: mapping}, q)
+	}
+
+	var order []string
+	for q.Len() > 0 {
+		request, shutdown := q.Get()
+		require.False(t, shutdown)
+		order = append(order, request.Name)
+		q.Done(request)
+	}
+	assert.Equal(t, []string{"critical", "overdue-hours", "overdue-minutes", "on-time"}, order)
+}
@@ -0,0 +1,45 @@
+package controllers
+
+import (
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/modes"
+	"github.com/supporttools/dr-syncer/pkg/rpo"
+)
+
+// observeMappingRPO reports the last successful sync of a NamespaceMapping to the RPO metrics.
+// LastSyncTime is also set when a sync starts, so it only counts once the sync completed.
+func observeMappingRPO(mapping *drv1alpha1.NamespaceMapping) {
+	if mapping.Status.Phase != drv1alpha1.SyncPhaseCompleted || mapping.Status.LastSyncTime == nil {
+		return
+	}
+	rpo.RecordMappingSync(mapping.Namespace, mapping.Name, mapping.Status.LastSyncTime.Time, rpoTarget(mapping, time.Now()))
+}
+
+// forgetMappingRPO stops reporting RPO metrics for a deleted NamespaceMapping and its PVCs
+func forgetMappingRPO(mapping *drv1alpha1.NamespaceMapping) {
+	rpo.ForgetMapping(mapping.Namespace, mapping.Name)
+
+	destNamespace := mapping.Spec.DestinationNamespace
+	if destNamespace == "" {
+		destNamespace = mapping.Spec.SourceNamespace
+	}
+	rpo.ForgetPVCs(mapping.Spec.SourceNamespace, destNamespace)
+}
+
+// rpoTarget returns the expected interval between successful syncs of a scheduled
+// NamespaceMapping, or zero when the mapping does not sync on a schedule
+func rpoTarget(mapping *drv1alpha1.NamespaceMapping, now time.Time) time.Duration {
+	if mapping.Spec.ReplicationMode != "" && mapping.Spec.ReplicationMode != drv1alpha1.ScheduledMode {
+		return 0
+	}
+
+	cronSchedule, _, err := modes.ParseSchedule(&mapping.Spec)
+	if err != nil {
+		return 0
+	}
+
+	next := cronSchedule.Next(now)
+	return cronSchedule.Next(next).Sub(next)
+}
@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestRPOTarget(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 2, 0, 0, time.UTC)
+	mapping := func(mode drv1alpha1.ReplicationMode, schedule string) *drv1alpha1.NamespaceMapping {
+		return &drv1alpha1.NamespaceMapping{Spec: drv1alpha1.NamespaceMappingSpec{ReplicationMode: mode, Schedule: schedule}}
+	}
+
+	assert.Equal(t, 5*time.Minute, rpoTarget(mapping("", ""), now))
+	assert.Equal(t, time.Hour, rpoTarget(mapping(drv1alpha1.ScheduledMode, "0 * * * *"), now))
+	assert.Equal(t, 24*time.Hour, rpoTarget(mapping(drv1alpha1.ScheduledMode, "@daily"), now))
+
+	// Only scheduled mappings have a target
+	assert.Zero(t, rpoTarget(mapping(drv1alpha1.ContinuousMode, ""), now))
+	assert.Zero(t, rpoTarget(mapping(drv1alpha1.ManualMode, ""), now))
+	assert.Zero(t, rpoTarget(mapping(drv1alpha1.ScheduledMode, "not a schedule"), now))
+}
@@ -0,0 +1,348 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// ConditionTypeTopologyConflict reports whether the mapping conflicts with other NamespaceMappings
+	ConditionTypeTopologyConflict = "TopologyConflict"
+
+	// ReasonCircularReplication is set when the mapping is part of a replication loop
+	ReasonCircularReplication = "CircularReplication"
+
+	// ReasonSharedDestination is set when another mapping replicates into the same destination namespace
+	// without selecting resources disjoint from the mapping's
+	ReasonSharedDestination = "SharedDestination"
+
+	// ReasonDisjointSelectors is set when mappings share the destination namespace, each syncing the
+	// resources selected by its disjoint resource selector
+	ReasonDisjointSelectors = "DisjointSelectors"
+
+	// topologyConflictRequeue is how often a conflicting NamespaceMapping checks whether the conflict was resolved
+	topologyConflictRequeue = 5 * time.Minute
+)
+
+// errTopologyConflict is returned when a NamespaceMapping would write into a namespace another mapping
+// writes into, or would replicate its own writes back to its source
+var errTopologyConflict = errors.New("namespace mapping conflicts with other mappings")
+
+// replicationEndpoint is a namespace in a cluster
+type replicationEndpoint struct {
+	cluster   string
+	namespace string
+}
+
+func (e replicationEndpoint) String() string {
+	return e.cluster + "/" + e.namespace
+}
+
+// replicationEdge is the source and destination a NamespaceMapping replicates between, and the
+// resources it selects
+type replicationEdge struct {
+	mapping  string
+	created  metav1.Time
+	source   replicationEndpoint
+	dest     replicationEndpoint
+	selector *metav1.LabelSelector
+}
+
+// newerThan returns true if the mapping of the edge was created after the mapping of other, ordered by
+// name when both were created at the same time. The newer mapping of a conflict is the one refused.
+func (e replicationEdge) newerThan(other replicationEdge) bool {
+	if !e.created.Equal(&other.created) {
+		return other.created.Before(&e.created)
+	}
+	return e.mapping > other.mapping
+}
+
+// mappingEdge returns the edge replicated by a NamespaceMapping whose clusters have been resolved
+func mappingEdge(mapping *drv1alpha1.NamespaceMapping, sourceCluster, destCluster string) replicationEdge {
+	destNamespace := mapping.Spec.DestinationNamespace
+	if destNamespace == "" {
+		destNamespace = mapping.Spec.SourceNamespace
+	}
+	return replicationEdge{
+		mapping:  fmt.Sprintf("%s/%s", mapping.Namespace, mapping.Name),
+		created:  mapping.CreationTimestamp,
+		source:   replicationEndpoint{cluster: sourceCluster, namespace: mapping.Spec.SourceNamespace},
+		dest:     replicationEndpoint{cluster: destCluster, namespace: destNamespace},
+		selector: mapping.Spec.ResourceSelector,
+	}
+}
+
+// findTopologyConflict checks the edge of a mapping against the edges of the other active mappings.
+// It returns the condition reason and a message describing the conflict, or empty strings when the
+// edge can run. Chains such as A→B and B→C are allowed, as are mappings sharing a destination namespace
+// with disjoint resource selectors. A namespace written by two overlapping mappings or a loop
+// replicating writes back to their source are not: the newest mapping of the conflict is refused, so
+// the mappings that were already running keep running.
+func findTopologyConflict(edge replicationEdge, others []replicationEdge) (string, string) {
+	if edge.source == edge.dest {
+		return ReasonCircularReplication, fmt.Sprintf("source and destination are both %s", edge.source)
+	}
+
+	var older []replicationEdge
+	for _, other := range others {
+		if edge.newerThan(other) {
+			older = append(older, other)
+		}
+	}
+
+	var shared []string
+	for _, other := range older {
+		if other.dest == edge.dest && !selectorsDisjoint(edge.selector, other.selector) {
+			shared = append(shared, other.mapping)
+		}
+	}
+	if len(shared) > 0 {
+		sort.Strings(shared)
+		return ReasonSharedDestination, fmt.Sprintf("%s is also the destination of %s", edge.dest, strings.Join(shared, ", "))
+	}
+
+	if loop := replicationPath(edge.dest, edge.source, older); loop != nil {
+		return ReasonCircularReplication, fmt.Sprintf("replicating %s to %s loops back through %s",
+			edge.source, edge.dest, strings.Join(loop, " → "))
+	}
+	return "", ""
+}
+
+// disjointSharers returns the mappings sharing the destination namespace of edge with disjoint
+// resource selectors
+func disjointSharers(edge replicationEdge, others []replicationEdge) []string {
+	var sharers []string
+	for _, other := range others {
+		if other.dest == edge.dest && selectorsDisjoint(edge.selector, other.selector) {
+			sharers = append(sharers, other.mapping)
+		}
+	}
+	sort.Strings(sharers)
+	return sharers
+}
+
+// labelRequirement is what a label selector requires of one label
+type labelRequirement struct {
+	present bool
+	absent  bool
+	// values the label may have, nil allows any value
+	values sets.Set[string]
+}
+
+// selectorRequirements returns the requirements of a label selector by label. NotIn requirements are
+// left out, they cannot prove two selectors disjoint on their own.
+func selectorRequirements(selector *metav1.LabelSelector) map[string]*labelRequirement {
+	requirements := make(map[string]*labelRequirement)
+	require := func(key string, values []string) {
+		requirement := requirements[key]
+		if requirement == nil {
+			requirement = &labelRequirement{}
+			requirements[key] = requirement
+		}
+		if values == nil {
+			requirement.absent = true
+			return
+		}
+		requirement.present = true
+		if len(values) == 0 {
+			return
+		}
+		if requirement.values == nil {
+			requirement.values = sets.New(values...)
+		} else {
+			requirement.values = requirement.values.Intersection(sets.New(values...))
+		}
+	}
+
+	for key, value := range selector.MatchLabels {
+		require(key, []string{value})
+	}
+	for _, expression := range selector.MatchExpressions {
+		switch expression.Operator {
+		case metav1.LabelSelectorOpIn:
+			require(expression.Key, expression.Values)
+		case metav1.LabelSelectorOpExists:
+			require(expression.Key, []string{})
+		case metav1.LabelSelectorOpDoesNotExist:
+			require(expression.Key, nil)
+		}
+	}
+	return requirements
+}
+
+// selectorsDisjoint returns true if no resource can match both resource selectors, because they require
+// different values of a label or one requires a label the other excludes. A nil selector selects every
+// resource, and selectors that cannot be proven disjoint are assumed to overlap.
+func selectorsDisjoint(a, b *metav1.LabelSelector) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	requirementsB := selectorRequirements(b)
+	for key, ra := range selectorRequirements(a) {
+		rb, ok := requirementsB[key]
+		if !ok {
+			continue
+		}
+		if (ra.absent && rb.present) || (ra.present && rb.absent) {
+			return true
+		}
+		if ra.values != nil && rb.values != nil && ra.values.Intersection(rb.values).Len() == 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// replicationPath returns the mappings replicating from one endpoint to another, directly or through
+// intermediate namespaces, or nil when there is no such path
+func replicationPath(from, to replicationEndpoint, edges []replicationEdge) []string {
+	visited := map[replicationEndpoint]bool{}
+	var walk func(at replicationEndpoint) []string
+	walk = func(at replicationEndpoint) []string {
+		if visited[at] {
+			return nil
+		}
+		visited[at] = true
+		for _, edge := range edges {
+			if edge.source != at {
+				continue
+			}
+			if edge.dest == to {
+				return []string{edge.mapping}
+			}
+			if rest := walk(edge.dest); rest != nil {
+				return append([]string{edge.mapping}, rest...)
+			}
+		}
+		return nil
+	}
+	return walk(from)
+}
+
+// mappingReplicates returns true unless the NamespaceMapping is paused or being deleted
+func mappingReplicates(mapping *drv1alpha1.NamespaceMapping) bool {
+	return mapping.DeletionTimestamp.IsZero() && (mapping.Spec.Paused == nil || !*mapping.Spec.Paused)
+}
+
+// resolvedMappingEdge returns the edge replicated by a NamespaceMapping, resolving its clusters from its
+// ClusterMapping. It returns false for a mapping whose clusters cannot be resolved, it does not replicate.
+func resolvedMappingEdge(ctx context.Context, c client.Reader, mapping *drv1alpha1.NamespaceMapping) (replicationEdge, bool) {
+	sourceCluster, destCluster := mapping.Spec.SourceCluster, mapping.Spec.DestinationCluster
+	if mapping.Spec.ClusterMappingRef != nil {
+		clusterMapping, err := getClusterMapping(ctx, c, mapping)
+		if err != nil {
+			return replicationEdge{}, false
+		}
+		sourceCluster, destCluster = clusterMapping.Spec.SourceCluster, clusterMapping.Spec.TargetCluster
+	}
+	if sourceCluster == "" || destCluster == "" {
+		return replicationEdge{}, false
+	}
+	return mappingEdge(mapping, sourceCluster, destCluster), true
+}
+
+// activeMappingEdges returns the edges of all NamespaceMappings other than mapping that replicate,
+// skipping paused and deleted mappings and mappings whose clusters cannot be resolved
+func activeMappingEdges(ctx context.Context, c client.Reader, mapping *drv1alpha1.NamespaceMapping) ([]replicationEdge, error) {
+	var mappings drv1alpha1.NamespaceMappingList
+	if err := c.List(ctx, &mappings); err != nil {
+		return nil, fmt.Errorf("failed to list NamespaceMappings: %w", err)
+	}
+
+	var edges []replicationEdge
+	for i := range mappings.Items {
+		other := &mappings.Items[i]
+		if other.Namespace == mapping.Namespace && other.Name == mapping.Name {
+			continue
+		}
+		if !mappingReplicates(other) {
+			continue
+		}
+		if edge, ok := resolvedMappingEdge(ctx, c, other); ok {
+			edges = append(edges, edge)
+		}
+	}
+	return edges, nil
+}
+
+// checkTopology refuses to run a mapping that conflicts with the other NamespaceMappings, recording
+// the conflict in its TopologyConflict condition. Only the newest mapping of a conflict is refused, by
+// creation time then name, until the conflict is resolved by pausing or deleting one of them.
+func (r *NamespaceMappingReconciler) checkTopology(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, sourceCluster, destCluster string) error {
+	others, err := activeMappingEdges(ctx, r.Client, mapping)
+	if err != nil {
+		return err
+	}
+
+	edge := mappingEdge(mapping, sourceCluster, destCluster)
+	reason, message := findTopologyConflict(edge, others)
+	var conflictErr error
+	if reason != "" {
+		conflictErr = fmt.Errorf("%w: %s", errTopologyConflict, message)
+		message = conflictErr.Error()
+		logging.LogError(nil, fmt.Sprintf("refusing to run NamespaceMapping %s/%s: %v", mapping.Namespace, mapping.Name, conflictErr))
+	} else if sharers := disjointSharers(edge, others); len(sharers) > 0 {
+		reason = ReasonDisjointSelectors
+		message = fmt.Sprintf("%s is shared with %s, each mapping syncs the resources of its disjoint resource selector",
+			edge.dest, strings.Join(sharers, ", "))
+	}
+	if err := r.setTopologyConflict(ctx, mapping, reason, message, conflictErr); err != nil {
+		return err
+	}
+	return conflictErr
+}
+
+// setTopologyConflict records in the NamespaceMapping status whether the mapping conflicts with other
+// mappings. The condition is only written once a conflict was found or the destination is shared, and
+// flips to False when the conflict is resolved.
+func (r *NamespaceMappingReconciler) setTopologyConflict(ctx context.Context, mapping *drv1alpha1.NamespaceMapping, reason, message string, conflictErr error) error {
+	existing := meta.FindStatusCondition(mapping.Status.Conditions, ConditionTypeTopologyConflict)
+	if reason == "" && existing == nil {
+		return nil
+	}
+
+	condition := metav1.Condition{
+		Type:               ConditionTypeTopologyConflict,
+		Status:             metav1.ConditionFalse,
+		Reason:             "NoConflict",
+		Message:            "No other mapping writes to the destination or replicates back to the source",
+		ObservedGeneration: mapping.Generation,
+	}
+	if reason != "" {
+		condition.Reason = reason
+		condition.Message = message
+	}
+	if conflictErr != nil {
+		condition.Status = metav1.ConditionTrue
+	}
+
+	if existing != nil && existing.Status == condition.Status && existing.Message == condition.Message {
+		return nil
+	}
+
+	meta.SetStatusCondition(&mapping.Status.Conditions, condition)
+	if conflictErr != nil {
+		mapping.Status.Phase = drv1alpha1.SyncPhaseFailed
+		mapping.Status.LastError = &drv1alpha1.SyncError{
+			Message: conflictErr.Error(),
+			Time:    metav1.Now(),
+		}
+	}
+
+	if err := r.Status().Update(ctx, mapping); err != nil {
+		logging.LogError(nil, fmt.Sprintf("failed to update topology conflict condition: %v", err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,210 @@
+package controllers
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drsyncerio "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// edge builds a replicationEdge from endpoints written as cluster/namespace
+func edge(mapping, source, dest string) replicationEdge {
+	endpoint := func(s string) replicationEndpoint {
+		cluster, namespace, _ := strings.Cut(s, "/")
+		return replicationEndpoint{cluster: cluster, namespace: namespace}
+	}
+	return replicationEdge{mapping: mapping, source: endpoint(source), dest: endpoint(dest)}
+}
+
+// newest returns the edge of a mapping created after the mappings of the edges built by edge
+func newest(e replicationEdge) replicationEdge {
+	e.created = metav1.NewTime(time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC))
+	return e
+}
+
+// later returns the edge of a mapping created after the mappings of the edges built by newest
+func later(e replicationEdge) replicationEdge {
+	e = newest(e)
+	e.created = metav1.NewTime(e.created.Add(time.Hour))
+	return e
+}
+
+// selecting returns the edge with a resource selector matching the labels
+func selecting(e replicationEdge, labels map[string]string) replicationEdge {
+	e.selector = &metav1.LabelSelector{MatchLabels: labels}
+	return e
+}
+
+func TestFindTopologyConflict(t *testing.T) {
+	tests := []struct {
+		name    string
+		edge    replicationEdge
+		others  []replicationEdge
+		reason  string
+		message string
+	}{
+		{
+			name: "no other mappings",
+			edge: edge("app/shop", "prod/shop", "dr/shop"),
+		},
+		{
+			name:   "chained replication",
+			edge:   edge("app/shop", "prod/shop", "dr/shop"),
+			others: []replicationEdge{edge("app/archive", "dr/shop", "archive/shop")},
+		},
+		{
+			name:    "same namespace in the same cluster",
+			edge:    edge("app/shop", "prod/shop", "prod/shop"),
+			reason:  ReasonCircularReplication,
+			message: "source and destination are both prod/shop",
+		},
+		{
+			name:    "bidirectional",
+			edge:    newest(edge("app/shop", "prod/shop", "dr/shop")),
+			others:  []replicationEdge{edge("app/shop-back", "dr/shop", "prod/shop")},
+			reason:  ReasonCircularReplication,
+			message: "replicating prod/shop to dr/shop loops back through app/shop-back",
+		},
+		{
+			name: "loop across three clusters",
+			edge: newest(edge("app/shop", "prod/shop", "dr/shop")),
+			others: []replicationEdge{
+				edge("app/unrelated", "dr/billing", "prod/billing"),
+				edge("app/to-archive", "dr/shop", "archive/shop"),
+				edge("app/from-archive", "archive/shop", "prod/shop"),
+			},
+			reason:  ReasonCircularReplication,
+			message: "replicating prod/shop to dr/shop loops back through app/to-archive → app/from-archive",
+		},
+		{
+			name: "shared destination",
+			edge: newest(edge("app/shop", "prod/shop", "dr/shop")),
+			others: []replicationEdge{
+				edge("team/shop-west", "west/shop", "dr/shop"),
+				edge("app/shop-east", "east/shop", "dr/shop"),
+			},
+			reason:  ReasonSharedDestination,
+			message: "dr/shop is also the destination of app/shop-east, team/shop-west",
+		},
+		{
+			name:   "older mapping of a loop",
+			edge:   edge("app/shop", "prod/shop", "dr/shop"),
+			others: []replicationEdge{newest(edge("app/shop-back", "dr/shop", "prod/shop"))},
+		},
+		{
+			name:   "older mapping of a shared destination",
+			edge:   edge("app/shop", "prod/shop", "dr/shop"),
+			others: []replicationEdge{newest(edge("app/shop-east", "east/shop", "dr/shop"))},
+		},
+		{
+			name:    "mappings created at the same time",
+			edge:    edge("app/shop-west", "west/shop", "dr/shop"),
+			others:  []replicationEdge{edge("app/shop-east", "east/shop", "dr/shop")},
+			reason:  ReasonSharedDestination,
+			message: "dr/shop is also the destination of app/shop-east",
+		},
+		{
+			name: "loop only through a newer mapping",
+			edge: newest(edge("app/shop", "prod/shop", "dr/shop")),
+			others: []replicationEdge{
+				edge("app/to-archive", "dr/shop", "archive/shop"),
+				later(edge("app/from-archive", "archive/shop", "prod/shop")),
+			},
+		},
+		{
+			name:   "shared destination with disjoint selectors",
+			edge:   selecting(edge("app/shop", "prod/shop", "dr/shop"), map[string]string{"team": "shop"}),
+			others: []replicationEdge{selecting(edge("app/billing", "prod/billing", "dr/shop"), map[string]string{"team": "billing"})},
+		},
+		{
+			name:    "shared destination with overlapping selectors",
+			edge:    selecting(newest(edge("app/shop", "prod/shop", "dr/shop")), map[string]string{"team": "shop"}),
+			others:  []replicationEdge{selecting(edge("app/all", "prod/all", "dr/shop"), map[string]string{"tier": "web"})},
+			reason:  ReasonSharedDestination,
+			message: "dr/shop is also the destination of app/all",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reason, message := findTopologyConflict(tt.edge, tt.others)
+			assert.Equal(t, tt.reason, reason)
+			assert.Equal(t, tt.message, message)
+		})
+	}
+}
+
+func TestSelectorsDisjoint(t *testing.T) {
+	in := func(key string, values ...string) *metav1.LabelSelector {
+		return &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: key, Operator: metav1.LabelSelectorOpIn, Values: values},
+		}}
+	}
+	doesNotExist := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "team", Operator: metav1.LabelSelectorOpDoesNotExist},
+	}}
+	notIn := &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "team", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"shop"}},
+	}}
+	shop := &metav1.LabelSelector{MatchLabels: map[string]string{"team": "shop"}}
+
+	assert.False(t, selectorsDisjoint(nil, shop), "no selector selects everything")
+	assert.True(t, selectorsDisjoint(shop, &metav1.LabelSelector{MatchLabels: map[string]string{"team": "billing"}}))
+	assert.False(t, selectorsDisjoint(shop, in("team", "shop", "billing")))
+	assert.True(t, selectorsDisjoint(in("team", "a", "b"), in("team", "c")))
+	assert.True(t, selectorsDisjoint(shop, doesNotExist))
+	assert.False(t, selectorsDisjoint(shop, &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}}))
+	assert.False(t, selectorsDisjoint(shop, notIn), "NotIn is not used to prove selectors disjoint")
+}
+
+func TestCheckTopology(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	forward := testutil.NewNamespaceMapping("shop").WithNamespace("app").
+		WithClusterMappingRef("prod-to-dr").WithSourceNamespace("shop").Build()
+	backward := testutil.NewNamespaceMapping("shop-back").WithNamespace("app").
+		WithSourceCluster("dr").WithDestinationCluster("prod").WithSourceNamespace("shop").Build()
+	clusterMapping := testutil.NewClusterMapping("prod-to-dr").WithNamespace("app").WithClusters("prod", "dr").Build()
+
+	created := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	forward.CreationTimestamp = metav1.NewTime(created)
+	backward.CreationTimestamp = metav1.NewTime(created.Add(time.Hour))
+
+	c := env.NewFakeClientWithStatus(forward, backward, clusterMapping)
+	r := &NamespaceMappingReconciler{Client: c, Scheme: env.Scheme}
+
+	// Only the newer mapping of a loop is refused, the mapping already running keeps running
+	require.NoError(t, r.checkTopology(env.Ctx, forward, "prod", "dr"))
+	err := r.checkTopology(env.Ctx, backward, "dr", "prod")
+	assert.True(t, errors.Is(err, errTopologyConflict))
+	assert.ErrorContains(t, err, "replicating dr/shop to prod/shop loops back through app/shop")
+
+	var updated drsyncerio.NamespaceMapping
+	require.NoError(t, c.Get(env.Ctx, client.ObjectKeyFromObject(forward), &updated))
+	assert.Nil(t, meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeTopologyConflict))
+	require.NoError(t, c.Get(env.Ctx, client.ObjectKeyFromObject(backward), &updated))
+	condition := meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeTopologyConflict)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionTrue, condition.Status)
+	assert.Equal(t, ReasonCircularReplication, condition.Reason)
+	assert.Equal(t, drsyncerio.SyncPhaseFailed, updated.Status.Phase)
+
+	// Pausing the older mapping resolves the conflict for the newer one
+	require.NoError(t, c.Get(env.Ctx, client.ObjectKeyFromObject(forward), forward))
+	forward.Spec.Paused = testutil.BoolPtr(true)
+	require.NoError(t, c.Update(env.Ctx, forward))
+	require.NoError(t, r.checkTopology(env.Ctx, &updated, "dr", "prod"))
+
+	require.NoError(t, c.Get(env.Ctx, client.ObjectKeyFromObject(backward), &updated))
+	condition = meta.FindStatusCondition(updated.Status.Conditions, ConditionTypeTopologyConflict)
+	require.NotNil(t, condition)
+	assert.Equal(t, metav1.ConditionFalse, condition.Status)
+}
@@ -0,0 +1,98 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// +kubebuilder:webhook:path=/validate-dr-syncer-io-v1alpha1-namespacemapping,mutating=false,failurePolicy=fail,sideEffects=None,groups=dr-syncer.io,resources=namespacemappings,verbs=create;update,versions=v1alpha1,name=vnamespacemapping.dr-syncer.io,admissionReviewVersions=v1
+
+// NamespaceMappingValidator rejects NamespaceMappings at admission that would write into another
+// mapping's destination or replicate their writes back to their source. These are the conflicts the
+// NamespaceMapping controller refuses to run, the validator refuses them before they are stored.
+type NamespaceMappingValidator struct {
+	Client client.Reader
+}
+
+var _ admission.CustomValidator = &NamespaceMappingValidator{}
+
+// SetupWebhookWithManager registers the validator with the webhook server of the manager
+func (v *NamespaceMappingValidator) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&drv1alpha1.NamespaceMapping{}).
+		WithValidator(v).
+		Complete()
+}
+
+// ValidateCreate rejects a new NamespaceMapping conflicting with the other mappings
+func (v *NamespaceMappingValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	mapping, ok := obj.(*drv1alpha1.NamespaceMapping)
+	if !ok {
+		return nil, fmt.Errorf("expected a NamespaceMapping, got %T", obj)
+	}
+	return nil, v.validateTopology(ctx, mapping)
+}
+
+// ValidateUpdate rejects a change of the source, destination or resource selector of a NamespaceMapping,
+// or resuming it, that makes it conflict with the other mappings. Other changes are always admitted, so
+// a mapping that was already in conflict can still be edited and paused.
+func (v *NamespaceMappingValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	oldMapping, ok := oldObj.(*drv1alpha1.NamespaceMapping)
+	if !ok {
+		return nil, fmt.Errorf("expected a NamespaceMapping, got %T", oldObj)
+	}
+	mapping, ok := newObj.(*drv1alpha1.NamespaceMapping)
+	if !ok {
+		return nil, fmt.Errorf("expected a NamespaceMapping, got %T", newObj)
+	}
+
+	if mappingReplicates(oldMapping) {
+		oldEdge, oldResolved := resolvedMappingEdge(ctx, v.Client, oldMapping)
+		edge, resolved := resolvedMappingEdge(ctx, v.Client, mapping)
+		if oldResolved == resolved && oldEdge.source == edge.source && oldEdge.dest == edge.dest &&
+			equality.Semantic.DeepEqual(oldEdge.selector, edge.selector) {
+			return nil, nil
+		}
+	}
+	return nil, v.validateTopology(ctx, mapping)
+}
+
+// ValidateDelete admits every deletion, removing a mapping never creates a conflict
+func (v *NamespaceMappingValidator) ValidateDelete(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateTopology returns an error wrapping errTopologyConflict when a replicating mapping conflicts
+// with the other active mappings. The admitted mapping is the newest of any conflict it creates, so the
+// mappings that are already running are never the ones refused.
+func (v *NamespaceMappingValidator) validateTopology(ctx context.Context, mapping *drv1alpha1.NamespaceMapping) error {
+	if !mappingReplicates(mapping) {
+		return nil
+	}
+	// A mapping without resolvable clusters does not replicate, the controller reports why
+	edge, ok := resolvedMappingEdge(ctx, v.Client, mapping)
+	if !ok {
+		return nil
+	}
+	edge.created = metav1.Now()
+
+	others, err := activeMappingEdges(ctx, v.Client, mapping)
+	if err != nil {
+		return err
+	}
+	if reason, message := findTopologyConflict(edge, others); reason != "" {
+		err := fmt.Errorf("%w: %s", errTopologyConflict, message)
+		logging.LogInfo(nil, fmt.Sprintf("rejecting NamespaceMapping %s/%s: %v", mapping.Namespace, mapping.Name, err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNamespaceMappingValidator_Create(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	forward := testutil.NewNamespaceMapping("shop").WithNamespace("app").
+		WithClusterMappingRef("prod-to-dr").WithSourceNamespace("shop").Build()
+	forward.CreationTimestamp = metav1.NewTime(time.Now().Add(-time.Hour))
+	clusterMapping := testutil.NewClusterMapping("prod-to-dr").WithNamespace("app").WithClusters("prod", "dr").Build()
+	v := &NamespaceMappingValidator{Client: env.NewFakeClient(forward, clusterMapping)}
+
+	// A mapping replicating back to the source of a running mapping is rejected
+	backward := testutil.NewNamespaceMapping("shop-back").WithNamespace("app").
+		WithSourceCluster("dr").WithDestinationCluster("prod").WithSourceNamespace("shop").Build()
+	_, err := v.ValidateCreate(env.Ctx, backward)
+	assert.True(t, errors.Is(err, errTopologyConflict))
+	assert.ErrorContains(t, err, "replicating dr/shop to prod/shop loops back through app/shop")
+
+	// So is a second mapping writing into the same destination namespace
+	shared := testutil.NewNamespaceMapping("shop-copy").WithNamespace("app").
+		WithSourceCluster("staging").WithDestinationCluster("dr").WithSourceNamespace("shop").Build()
+	_, err = v.ValidateCreate(env.Ctx, shared)
+	assert.ErrorContains(t, err, "dr/shop is also the destination of app/shop")
+
+	// Disjoint resource selectors may share the destination, chains and paused mappings are admitted
+	disjoint := shared.DeepCopy()
+	disjoint.Spec.ResourceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "db"}}
+	forwardSelected := forward.DeepCopy()
+	forwardSelected.Spec.ResourceSelector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}}
+	v.Client = env.NewFakeClient(forwardSelected, clusterMapping)
+	_, err = v.ValidateCreate(env.Ctx, disjoint)
+	assert.NoError(t, err)
+
+	chained := testutil.NewNamespaceMapping("shop-archive").WithNamespace("app").
+		WithSourceCluster("dr").WithDestinationCluster("archive").WithSourceNamespace("shop").Build()
+	_, err = v.ValidateCreate(env.Ctx, chained)
+	assert.NoError(t, err)
+
+	backward.Spec.Paused = testutil.BoolPtr(true)
+	_, err = v.ValidateCreate(env.Ctx, backward)
+	assert.NoError(t, err)
+}
+
+func TestNamespaceMappingValidator_Update(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+
+	forward := testutil.NewNamespaceMapping("shop").WithNamespace("app").
+		WithSourceCluster("prod").WithDestinationCluster("dr").WithSourceNamespace("shop").Build()
+	backward := testutil.NewNamespaceMapping("shop-back").WithNamespace("app").
+		WithSourceCluster("dr").WithDestinationCluster("prod").WithSourceNamespace("shop").WithPaused(true).Build()
+	other := testutil.NewNamespaceMapping("billing").WithNamespace("app").
+		WithSourceCluster("prod").WithDestinationCluster("dr").WithSourceNamespace("billing").Build()
+	v := &NamespaceMappingValidator{Client: env.NewFakeClient(forward, backward, other)}
+
+	// Resuming a mapping that loops back is rejected, editing it while paused is not
+	resumed := backward.DeepCopy()
+	resumed.Spec.Paused = testutil.BoolPtr(false)
+	_, err := v.ValidateUpdate(env.Ctx, backward, resumed)
+	assert.True(t, errors.Is(err, errTopologyConflict))
+
+	edited := backward.DeepCopy()
+	edited.Spec.Schedule = "0 * * * *"
+	_, err = v.ValidateUpdate(env.Ctx, backward, edited)
+	assert.NoError(t, err)
+
+	// Moving a running mapping onto another mapping's destination is rejected
+	moved := other.DeepCopy()
+	moved.Spec.SourceNamespace = "shop"
+	_, err = v.ValidateUpdate(env.Ctx, other, moved)
+	assert.ErrorContains(t, err, "dr/shop is also the destination of app/shop")
+
+	// Changes leaving the topology of a running mapping in place are admitted, conflicting or not
+	conflicting := moved.DeepCopy()
+	conflicting.Spec.Schedule = "0 * * * *"
+	_, err = v.ValidateUpdate(env.Ctx, moved, conflicting)
+	assert.NoError(t, err)
+}
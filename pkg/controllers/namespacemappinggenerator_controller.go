@@ -0,0 +1,380 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+const (
+	// DefaultGeneratorResyncInterval is used when a NamespaceMappingGenerator does not set resyncInterval
+	DefaultGeneratorResyncInterval = 5 * time.Minute
+
+	// ReasonGenerated is set on the Ready condition when every selected namespace has its NamespaceMapping
+	ReasonGenerated = "Generated"
+
+	// ReasonNameConflict is set on the Ready condition when a NamespaceMapping not created by the generator
+	// has the name of a generated NamespaceMapping
+	ReasonNameConflict = "NameConflict"
+
+	// ReasonSourceUnavailable is set on the Ready condition when the namespaces of the source cluster cannot be listed
+	ReasonSourceUnavailable = "SourceUnavailable"
+
+	// ReasonGenerateFailed is set on the Ready condition when generated NamespaceMappings cannot be written
+	ReasonGenerateFailed = "GenerateFailed"
+)
+
+// NamespaceMappingGeneratorReconciler reconciles a NamespaceMappingGenerator object
+type NamespaceMappingGeneratorReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// SourceClientFor returns a client for the source cluster of the generated NamespaceMappings.
+	// Defaults to a client built from the RemoteCluster's kubeconfig secret.
+	SourceClientFor func(ctx context.Context, cluster *drv1alpha1.RemoteCluster) (kubernetes.Interface, error)
+}
+
+// +kubebuilder:rbac:groups=dr-syncer.io,resources=namespacemappinggenerators,verbs=get;list;watch
+// +kubebuilder:rbac:groups=dr-syncer.io,resources=namespacemappinggenerators/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=dr-syncer.io,resources=namespacemappings,verbs=get;list;watch;create;update;patch;delete
+
+// SetupWithManager sets up the controller with the manager
+func (r *NamespaceMappingGeneratorReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	logging.LogInfo(nil, "setting up NamespaceMappingGenerator controller")
+
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&drv1alpha1.NamespaceMappingGenerator{}).
+		Owns(&drv1alpha1.NamespaceMapping{}).
+		Complete(r)
+}
+
+// Reconcile creates, updates and deletes the NamespaceMappings of a NamespaceMappingGenerator so that every
+// selected source namespace has a NamespaceMapping matching the template. Generated NamespaceMappings are
+// owned by the generator and deleted with it.
+func (r *NamespaceMappingGeneratorReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	var generator drv1alpha1.NamespaceMappingGenerator
+	if err := r.Get(ctx, req.NamespacedName, &generator); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		logging.LogError(nil, fmt.Sprintf("unable to fetch NamespaceMappingGenerator: %v", err))
+		return ctrl.Result{}, err
+	}
+	if !generator.DeletionTimestamp.IsZero() {
+		return ctrl.Result{}, nil
+	}
+
+	resync := durationOrDefault(generator.Spec.ResyncInterval, DefaultGeneratorResyncInterval)
+	condition := metav1.Condition{
+		Type:               drv1alpha1.NamespaceMappingGeneratorConditionReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonGenerated,
+		ObservedGeneration: generator.Generation,
+	}
+
+	namespaces, err := r.selectNamespaces(ctx, &generator)
+	if err != nil {
+		logging.LogError(nil, fmt.Sprintf("failed to select namespaces of NamespaceMappingGenerator %s: %v", req, err))
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonSourceUnavailable
+		condition.Message = err.Error()
+		return ctrl.Result{RequeueAfter: resync}, r.updateStatus(ctx, &generator, condition, nil)
+	}
+
+	result, err := r.generate(ctx, &generator, namespaces)
+	switch {
+	case err != nil:
+		logging.LogError(nil, fmt.Sprintf("failed to generate NamespaceMappings of %s: %v", req, err))
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonGenerateFailed
+		condition.Message = err.Error()
+	case len(result.conflicts) > 0:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonNameConflict
+		condition.Message = fmt.Sprintf("NamespaceMappings %s exist and were not created by this generator", strings.Join(result.conflicts, ", "))
+	default:
+		condition.Message = fmt.Sprintf("%d NamespaceMappings generated", len(result.mappings))
+		if len(result.kept) > 0 {
+			condition.Message += fmt.Sprintf("; NamespaceMappings %s are kept as their source namespace no longer exists", strings.Join(result.kept, ", "))
+		}
+	}
+
+	generator.Status.MatchedNamespaces = int32(len(namespaces))
+	if statusErr := r.updateStatus(ctx, &generator, condition, result.mappings); statusErr != nil {
+		return ctrl.Result{}, statusErr
+	}
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: resync}, nil
+}
+
+// selectNamespaces returns the source namespaces matching the namespace selector, sorted by name.
+// Terminating namespaces are left out.
+func (r *NamespaceMappingGeneratorReconciler) selectNamespaces(ctx context.Context, generator *drv1alpha1.NamespaceMappingGenerator) ([]string, error) {
+	selector, err := metav1.LabelSelectorAsSelector(&generator.Spec.NamespaceSelector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid namespace selector: %w", err)
+	}
+
+	var list *corev1.NamespaceList
+	err = withRemoteCluster(ctx, func() error {
+		sourceClient, err := r.sourceClient(ctx, generator)
+		if err != nil {
+			return err
+		}
+		list, err = sourceClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			return fmt.Errorf("failed to list source namespaces: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	namespaces := make([]string, 0, len(list.Items))
+	for _, ns := range list.Items {
+		if ns.DeletionTimestamp != nil || ns.Status.Phase == corev1.NamespaceTerminating {
+			continue
+		}
+		namespaces = append(namespaces, ns.Name)
+	}
+	sort.Strings(namespaces)
+	return namespaces, nil
+}
+
+// sourceNamespaceExists reports whether a namespace still exists in the source cluster
+func (r *NamespaceMappingGeneratorReconciler) sourceNamespaceExists(ctx context.Context, generator *drv1alpha1.NamespaceMappingGenerator, name string) (bool, error) {
+	exists := true
+	err := withRemoteCluster(ctx, func() error {
+		sourceClient, err := r.sourceClient(ctx, generator)
+		if err != nil {
+			return err
+		}
+		_, err = sourceClient.CoreV1().Namespaces().Get(ctx, name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			exists = false
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get source namespace %s: %w", name, err)
+		}
+		return nil
+	})
+	return exists, err
+}
+
+// sourceClient returns a client for the source cluster of the template
+func (r *NamespaceMappingGeneratorReconciler) sourceClient(ctx context.Context, generator *drv1alpha1.NamespaceMappingGenerator) (kubernetes.Interface, error) {
+	template := &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{Namespace: generator.Namespace},
+		Spec:       generator.Spec.Template.Spec,
+	}
+	cluster, err := sourceRemoteCluster(ctx, r.Client, template)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.SourceClientFor != nil {
+		return r.SourceClientFor(ctx, cluster)
+	}
+	clientset, _, err := remoteClusterClient(ctx, r.Client, cluster)
+	return clientset, err
+}
+
+// generateResult lists the NamespaceMappings of a generator after a generate pass
+type generateResult struct {
+	// mappings are the NamespaceMappings owned by the generator, sorted by name
+	mappings []string
+
+	// conflicts are NamespaceMappings that have the name of a generated NamespaceMapping but another owner
+	conflicts []string
+
+	// kept are generated NamespaceMappings whose source namespace was deleted
+	kept []string
+}
+
+// generate creates or updates the NamespaceMapping of each selected namespace and deletes generated
+// NamespaceMappings whose namespace is no longer selected. The NamespaceMapping of a namespace that was
+// deleted from the source cluster is kept, so the loss of a source namespace never cleans up its DR copy.
+func (r *NamespaceMappingGeneratorReconciler) generate(ctx context.Context, generator *drv1alpha1.NamespaceMappingGenerator, namespaces []string) (generateResult, error) {
+	var result generateResult
+
+	var list drv1alpha1.NamespaceMappingList
+	if err := r.List(ctx, &list, client.InNamespace(generator.Namespace),
+		client.MatchingLabels{drv1alpha1.NamespaceMappingGeneratorLabel: generator.Name}); err != nil {
+		return result, fmt.Errorf("failed to list generated NamespaceMappings: %w", err)
+	}
+	owned := map[string]*drv1alpha1.NamespaceMapping{}
+	for i := range list.Items {
+		if metav1.IsControlledBy(&list.Items[i], generator) {
+			owned[list.Items[i].Name] = &list.Items[i]
+		}
+	}
+
+	wanted := map[string]bool{}
+	for _, namespace := range namespaces {
+		desired, err := r.desiredNamespaceMapping(generator, namespace)
+		if err != nil {
+			return result, err
+		}
+		wanted[desired.Name] = true
+
+		existing, ok := owned[desired.Name]
+		if !ok {
+			var current drv1alpha1.NamespaceMapping
+			err := r.Get(ctx, client.ObjectKeyFromObject(desired), &current)
+			switch {
+			case err == nil:
+				if !metav1.IsControlledBy(&current, generator) {
+					result.conflicts = append(result.conflicts, desired.Name)
+					continue
+				}
+				existing = &current
+			case apierrors.IsNotFound(err):
+				logging.LogInfo(nil, fmt.Sprintf("creating NamespaceMapping %s/%s for source namespace %s", desired.Namespace, desired.Name, namespace))
+				if err := r.Create(ctx, desired); err != nil {
+					return result, fmt.Errorf("failed to create NamespaceMapping %s: %w", desired.Name, err)
+				}
+				result.mappings = append(result.mappings, desired.Name)
+				continue
+			default:
+				return result, fmt.Errorf("failed to get NamespaceMapping %s: %w", desired.Name, err)
+			}
+		}
+
+		if err := r.updateNamespaceMapping(ctx, existing, desired); err != nil {
+			return result, err
+		}
+		result.mappings = append(result.mappings, desired.Name)
+	}
+
+	for name, mapping := range owned {
+		if wanted[name] || !mapping.DeletionTimestamp.IsZero() {
+			continue
+		}
+		exists, err := r.sourceNamespaceExists(ctx, generator, mapping.Spec.SourceNamespace)
+		if err != nil {
+			return result, err
+		}
+		if !exists {
+			result.mappings = append(result.mappings, name)
+			result.kept = append(result.kept, name)
+			continue
+		}
+		logging.LogInfo(nil, fmt.Sprintf("deleting NamespaceMapping %s/%s as source namespace %s is no longer selected", mapping.Namespace, name, mapping.Spec.SourceNamespace))
+		if err := r.Delete(ctx, mapping); err != nil && !apierrors.IsNotFound(err) {
+			return result, fmt.Errorf("failed to delete NamespaceMapping %s: %w", name, err)
+		}
+	}
+
+	sort.Strings(result.mappings)
+	sort.Strings(result.kept)
+	sort.Strings(result.conflicts)
+	return result, nil
+}
+
+// desiredNamespaceMapping returns the NamespaceMapping generated for a source namespace
+func (r *NamespaceMappingGeneratorReconciler) desiredNamespaceMapping(generator *drv1alpha1.NamespaceMappingGenerator, namespace string) (*drv1alpha1.NamespaceMapping, error) {
+	template := generator.Spec.Template.DeepCopy()
+
+	mapping := &drv1alpha1.NamespaceMapping{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        generatedMappingName(generator.Name, namespace),
+			Namespace:   generator.Namespace,
+			Labels:      template.Metadata.Labels,
+			Annotations: template.Metadata.Annotations,
+		},
+		Spec: template.Spec,
+	}
+	if mapping.Labels == nil {
+		mapping.Labels = map[string]string{}
+	}
+	mapping.Labels[drv1alpha1.NamespaceMappingGeneratorLabel] = generator.Name
+	mapping.Spec.SourceNamespace = namespace
+	mapping.Spec.DestinationNamespace = generator.Spec.DestinationNamespacePrefix + namespace + generator.Spec.DestinationNamespaceSuffix
+
+	if err := controllerutil.SetControllerReference(generator, mapping, r.Scheme); err != nil {
+		return nil, fmt.Errorf("failed to set owner of NamespaceMapping %s: %w", mapping.Name, err)
+	}
+	return mapping, nil
+}
+
+// updateNamespaceMapping brings a generated NamespaceMapping in line with the template. Labels and
+// annotations added by others, such as manual sync triggers, are kept.
+func (r *NamespaceMappingGeneratorReconciler) updateNamespaceMapping(ctx context.Context, existing, desired *drv1alpha1.NamespaceMapping) error {
+	updated := existing.DeepCopy()
+	updated.Spec = desired.Spec
+	for key, value := range desired.Labels {
+		if updated.Labels == nil {
+			updated.Labels = map[string]string{}
+		}
+		updated.Labels[key] = value
+	}
+	for key, value := range desired.Annotations {
+		if updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		updated.Annotations[key] = value
+	}
+	if equality.Semantic.DeepEqual(existing.Spec, updated.Spec) &&
+		equality.Semantic.DeepEqual(existing.Labels, updated.Labels) &&
+		equality.Semantic.DeepEqual(existing.Annotations, updated.Annotations) {
+		return nil
+	}
+
+	logging.LogInfo(nil, fmt.Sprintf("updating NamespaceMapping %s/%s from its generator template", updated.Namespace, updated.Name))
+	if err := r.Update(ctx, updated); err != nil {
+		return fmt.Errorf("failed to update NamespaceMapping %s: %w", updated.Name, err)
+	}
+	return nil
+}
+
+// updateStatus records the generated NamespaceMappings and the Ready condition
+func (r *NamespaceMappingGeneratorReconciler) updateStatus(ctx context.Context, generator *drv1alpha1.NamespaceMappingGenerator, condition metav1.Condition, mappings []string) error {
+	now := metav1.Now()
+	generator.Status.ObservedGeneration = generator.Generation
+	if condition.Status == metav1.ConditionTrue || condition.Reason == ReasonNameConflict {
+		generator.Status.LastSyncTime = &now
+		generator.Status.NamespaceMappings = mappings
+	}
+	meta.SetStatusCondition(&generator.Status.Conditions, condition)
+
+	if err := r.Status().Update(ctx, generator); err != nil {
+		if apierrors.IsConflict(err) {
+			return nil
+		}
+		logging.LogError(nil, fmt.Sprintf("failed to update NamespaceMappingGenerator status: %v", err))
+		return err
+	}
+	return nil
+}
+
+// generatedMappingName names the NamespaceMapping of a source namespace <generator>-<namespace>. Longer
+// names are shortened with a hash so they stay valid label values.
+func generatedMappingName(generator, namespace string) string {
+	name := generator + "-" + namespace
+	if len(name) <= 63 {
+		return name
+	}
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	return fmt.Sprintf("%s-%08x", strings.TrimRight(name[:54], "-"), h.Sum32())
+}
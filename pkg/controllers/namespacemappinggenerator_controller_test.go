@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func sourceNamespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func newGenerator() *drv1alpha1.NamespaceMappingGenerator {
+	return &drv1alpha1.NamespaceMappingGenerator{
+		ObjectMeta: metav1.ObjectMeta{Name: "teams", Namespace: "default", UID: "generator-uid", Generation: 1},
+		Spec: drv1alpha1.NamespaceMappingGeneratorSpec{
+			NamespaceSelector:          metav1.LabelSelector{MatchLabels: map[string]string{"dr": "enabled"}},
+			DestinationNamespaceSuffix: "-dr",
+			Template: drv1alpha1.NamespaceMappingTemplate{
+				Metadata: drv1alpha1.NamespaceMappingTemplateMetadata{Labels: map[string]string{"tier": "gold"}},
+				Spec: drv1alpha1.NamespaceMappingSpec{
+					SourceCluster:      "prod",
+					DestinationCluster: "dr",
+					Schedule:           "*/15 * * * *",
+				},
+			},
+		},
+	}
+}
+
+func newGeneratorReconciler(env *testutil.TestEnv, source kubernetes.Interface, objs ...client.Object) *NamespaceMappingGeneratorReconciler {
+	objs = append(objs, testutil.NewRemoteCluster("prod").Build())
+	return &NamespaceMappingGeneratorReconciler{
+		Client: env.NewFakeClient(objs...),
+		Scheme: env.Scheme,
+		SourceClientFor: func(ctx context.Context, cluster *drv1alpha1.RemoteCluster) (kubernetes.Interface, error) {
+			if source == nil {
+				return nil, errors.New("source cluster unreachable")
+			}
+			return source, nil
+		},
+	}
+}
+
+func reconcileGenerator(t *testing.T, r *NamespaceMappingGeneratorReconciler, env *testutil.TestEnv) *drv1alpha1.NamespaceMappingGenerator {
+	t.Helper()
+	result, err := r.Reconcile(env.Ctx, ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "teams"}})
+	require.NoError(t, err)
+	assert.Equal(t, DefaultGeneratorResyncInterval, result.RequeueAfter)
+
+	var generator drv1alpha1.NamespaceMappingGenerator
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "teams"}, &generator))
+	return &generator
+}
+
+func TestNamespaceMappingGenerator_Generates(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	source := k8sfake.NewSimpleClientset(
+		sourceNamespace("billing", map[string]string{"dr": "enabled"}),
+		sourceNamespace("shop", map[string]string{"dr": "enabled"}),
+		sourceNamespace("scratch", nil),
+	)
+	r := newGeneratorReconciler(env, source, newGenerator())
+
+	generator := reconcileGenerator(t, r, env)
+	assert.Equal(t, []string{"teams-billing", "teams-shop"}, generator.Status.NamespaceMappings)
+	assert.Equal(t, int32(2), generator.Status.MatchedNamespaces)
+	ready := meta.FindStatusCondition(generator.Status.Conditions, drv1alpha1.NamespaceMappingGeneratorConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionTrue, ready.Status)
+	assert.Equal(t, ReasonGenerated, ready.Reason)
+
+	var shop drv1alpha1.NamespaceMapping
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "teams-shop"}, &shop))
+	assert.Equal(t, "shop", shop.Spec.SourceNamespace)
+	assert.Equal(t, "shop-dr", shop.Spec.DestinationNamespace)
+	assert.Equal(t, "*/15 * * * *", shop.Spec.Schedule)
+	assert.Equal(t, "gold", shop.Labels["tier"])
+	assert.Equal(t, "teams", shop.Labels[drv1alpha1.NamespaceMappingGeneratorLabel])
+	assert.True(t, metav1.IsControlledBy(&shop, generator))
+}
+
+func TestNamespaceMappingGenerator_UpdatesFromTemplate(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	source := k8sfake.NewSimpleClientset(sourceNamespace("shop", map[string]string{"dr": "enabled"}))
+	generator := newGenerator()
+	r := newGeneratorReconciler(env, source, generator)
+	reconcileGenerator(t, r, env)
+
+	// A manual trigger annotation added to the generated mapping survives template updates
+	var shop drv1alpha1.NamespaceMapping
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "teams-shop"}, &shop))
+	shop.Annotations = map[string]string{"dr-syncer.io/sync-now": "true"}
+	require.NoError(t, r.Update(env.Ctx, &shop))
+
+	stored := reconcileGenerator(t, r, env)
+	stored.Spec.Template.Spec.Schedule = "0 * * * *"
+	require.NoError(t, r.Update(env.Ctx, stored))
+	reconcileGenerator(t, r, env)
+
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "teams-shop"}, &shop))
+	assert.Equal(t, "0 * * * *", shop.Spec.Schedule)
+	assert.Equal(t, "shop", shop.Spec.SourceNamespace)
+	assert.Equal(t, "true", shop.Annotations["dr-syncer.io/sync-now"])
+}
+
+func TestNamespaceMappingGenerator_PrunesUnselectedNamespaces(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	source := k8sfake.NewSimpleClientset(
+		sourceNamespace("billing", map[string]string{"dr": "enabled"}),
+		sourceNamespace("shop", map[string]string{"dr": "enabled"}),
+	)
+	r := newGeneratorReconciler(env, source, newGenerator())
+	reconcileGenerator(t, r, env)
+
+	// shop is no longer selected, billing was deleted from the source cluster
+	unlabeled := sourceNamespace("shop", nil)
+	_, err := source.CoreV1().Namespaces().Update(env.Ctx, unlabeled, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, source.CoreV1().Namespaces().Delete(env.Ctx, "billing", metav1.DeleteOptions{}))
+
+	generator := reconcileGenerator(t, r, env)
+	err = r.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "teams-shop"}, &drv1alpha1.NamespaceMapping{})
+	assert.True(t, apierrors.IsNotFound(err), "the mapping of an unselected namespace is deleted")
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "teams-billing"}, &drv1alpha1.NamespaceMapping{}),
+		"the mapping of a deleted source namespace is kept")
+
+	assert.Equal(t, []string{"teams-billing"}, generator.Status.NamespaceMappings)
+	ready := meta.FindStatusCondition(generator.Status.Conditions, drv1alpha1.NamespaceMappingGeneratorConditionReady)
+	require.NotNil(t, ready)
+	assert.Contains(t, ready.Message, "teams-billing are kept")
+}
+
+func TestNamespaceMappingGenerator_NameConflict(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	source := k8sfake.NewSimpleClientset(sourceNamespace("shop", map[string]string{"dr": "enabled"}))
+	existing := testutil.NewNamespaceMapping("teams-shop").WithSourceNamespace("legacy").Build()
+	r := newGeneratorReconciler(env, source, newGenerator(), existing)
+
+	generator := reconcileGenerator(t, r, env)
+	ready := meta.FindStatusCondition(generator.Status.Conditions, drv1alpha1.NamespaceMappingGeneratorConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, ReasonNameConflict, ready.Reason)
+
+	var stored drv1alpha1.NamespaceMapping
+	require.NoError(t, r.Get(env.Ctx, client.ObjectKey{Namespace: "default", Name: "teams-shop"}, &stored))
+	assert.Equal(t, "legacy", stored.Spec.SourceNamespace, "a mapping of another owner is left alone")
+}
+
+func TestNamespaceMappingGenerator_SourceUnavailable(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	r := newGeneratorReconciler(env, nil, newGenerator())
+
+	generator := reconcileGenerator(t, r, env)
+	ready := meta.FindStatusCondition(generator.Status.Conditions, drv1alpha1.NamespaceMappingGeneratorConditionReady)
+	require.NotNil(t, ready)
+	assert.Equal(t, metav1.ConditionFalse, ready.Status)
+	assert.Equal(t, ReasonSourceUnavailable, ready.Reason)
+}
+
+func TestGeneratedMappingName(t *testing.T) {
+	assert.Equal(t, "teams-shop", generatedMappingName("teams", "shop"))
+
+	long := generatedMappingName("platform-onboarding", strings.Repeat("n", 60))
+	assert.Len(t, long, 63)
+	assert.NotEqual(t, long, generatedMappingName("platform-onboarding", strings.Repeat("n", 59)+"m"))
+}
@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// remoteClusterAttempts bounds the attempts of an operation on a remote cluster run by withRemoteCluster
+const remoteClusterAttempts = 3
+
+// remoteClusterRetryDelay returns the delay before the next attempt of an operation on a remote cluster,
+// overridden in tests
+var remoteClusterRetryDelay = util.CalculateBackoff
+
+// remoteClient is a client of a RemoteCluster built from a version of its kubeconfig and spec
+type remoteClient struct {
+	clientset kubernetes.Interface
+	config    *rest.Config
+	version   string
+}
+
+// remoteClients caches the clients of the RemoteClusters by cluster, so API connections are reused across
+// reconciles. A client is rebuilt when the kubeconfig secret or the RemoteCluster changes, and when a request
+// fails to verify the certificate of the cluster, so a rotated API server CA is picked up from the secret.
+var remoteClients = struct {
+	sync.Mutex
+	clusters map[string]*remoteClient
+}{clusters: map[string]*remoteClient{}}
+
+func init() {
+	util.RemoteClusters.OnCertificateError(forgetRemoteClient)
+}
+
+// remoteClusterKey identifies a RemoteCluster in the client cache and the circuit breaker
+func remoteClusterKey(cluster *drv1alpha1.RemoteCluster) string {
+	return types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}.String()
+}
+
+// forgetRemoteClient drops the cached client of a cluster
+func forgetRemoteClient(cluster string) {
+	remoteClients.Lock()
+	defer remoteClients.Unlock()
+	delete(remoteClients.clusters, cluster)
+}
+
+// remoteClusterClient returns a Kubernetes client and REST config for a RemoteCluster, built from its
+// kubeconfig secret and cached until the kubeconfig or the RemoteCluster changes. Requests of the client
+// go through the circuit of the cluster; while it is open a CircuitOpenError is returned right away.
+func remoteClusterClient(ctx context.Context, c client.Client, cluster *drv1alpha1.RemoteCluster) (kubernetes.Interface, *rest.Config, error) {
+	key := remoteClusterKey(cluster)
+	if err := util.RemoteClusters.Allow(key); err != nil {
+		return nil, nil, err
+	}
+
+	// Get kubeconfig secret
+	secret := &corev1.Secret{}
+	err := c.Get(ctx, types.NamespacedName{
+		Name:      cluster.Spec.KubeconfigSecretRef.Name,
+		Namespace: cluster.Spec.KubeconfigSecretRef.Namespace,
+	}, secret)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get kubeconfig secret: %w", err)
+	}
+
+	// Get kubeconfig key
+	kubeconfigKey := "kubeconfig"
+	if cluster.Spec.KubeconfigSecretRef.Key != "" {
+		kubeconfigKey = cluster.Spec.KubeconfigSecretRef.Key
+	}
+
+	// Get kubeconfig data
+	kubeconfigData, ok := secret.Data[kubeconfigKey]
+	if !ok {
+		return nil, nil, fmt.Errorf("kubeconfig key %s not found in secret", kubeconfigKey)
+	}
+
+	// Reuse the client built from the same kubeconfig and RemoteCluster spec
+	version := fmt.Sprintf("%x/%s/%d", sha256.Sum256(kubeconfigData), cluster.Spec.KubeconfigSecretRef.Context, cluster.Generation)
+	remoteClients.Lock()
+	cached, ok := remoteClients.clusters[key]
+	remoteClients.Unlock()
+	if ok && cached.version == version {
+		return cached.clientset, rest.CopyConfig(cached.config), nil
+	}
+
+	// Create rest config
+	config, err := util.RESTConfigFromKubeconfig(kubeconfigData, cluster.Spec.KubeconfigSecretRef.Context)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create rest config: %w", err)
+	}
+
+	// Route API and exec traffic through the configured proxy or jump host
+	if err := util.ApplyProxyConfig(ctx, c, config, cluster.Spec.Proxy); err != nil {
+		return nil, nil, fmt.Errorf("failed to configure proxy: %w", err)
+	}
+	util.RemoteClusters.Instrument(key, config)
+
+	// Create client
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create client: %w", err)
+	}
+
+	remoteClients.Lock()
+	remoteClients.clusters[key] = &remoteClient{clientset: clientset, config: config, version: version}
+	remoteClients.Unlock()
+
+	return clientset, rest.CopyConfig(config), nil
+}
+
+// remoteClusterClients returns the Kubernetes client, a dynamic client and the REST config of a RemoteCluster
+func remoteClusterClients(ctx context.Context, c client.Client, cluster *drv1alpha1.RemoteCluster) (kubernetes.Interface, dynamic.Interface, *rest.Config, error) {
+	clientset, config, err := remoteClusterClient(ctx, c, cluster)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+	return clientset, dynamicClient, config, nil
+}
+
+// withRemoteCluster runs an operation on a remote cluster and retries it up to remoteClusterAttempts times
+// when the cluster was unavailable or its certificate could not be verified. The operation gets its client
+// again on every attempt, so a retry after a certificate error uses a client rebuilt from the kubeconfig
+// secret. An open circuit is returned right away.
+func withRemoteCluster(ctx context.Context, operation func() error) error {
+	var err error
+	for attempt := 1; attempt <= remoteClusterAttempts; attempt++ {
+		err = operation()
+		if err == nil {
+			return nil
+		}
+		if _, open := util.IsCircuitOpen(err); open || !isRemoteClusterRetryable(err) || attempt == remoteClusterAttempts {
+			return err
+		}
+
+		delay := remoteClusterRetryDelay(attempt)
+		log.Infof("retrying operation on remote cluster in %s after attempt %d failed: %v", delay, attempt, err)
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+	return err
+}
+
+// isRemoteClusterRetryable reports whether an operation on a remote cluster may succeed when retried
+func isRemoteClusterRetryable(err error) bool {
+	return util.IsCertificateError(err) || util.IsUnavailable(err)
+}
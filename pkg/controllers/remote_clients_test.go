@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	"github.com/supporttools/dr-syncer/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func kubeconfigSecret(name, server string) *corev1.Secret {
+	kubeconfig := fmt.Sprintf(`apiVersion: v1
+kind: Config
+current-context: remote
+clusters:
+- name: remote
+  cluster:
+    server: %s
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: remote
+  context:
+    cluster: remote
+    user: admin
+`, server)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Data:       map[string][]byte{"kubeconfig": []byte(kubeconfig)},
+	}
+}
+
+func TestRemoteClusterClient_Cache(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	cluster := testutil.NewRemoteCluster("cache-test").Build()
+	secret := kubeconfigSecret("cache-test-kubeconfig", "https://cache-test.example.com:6443")
+	c := env.NewFakeClient(cluster, secret)
+	t.Cleanup(func() { forgetRemoteClient(remoteClusterKey(cluster)) })
+
+	first, config, err := remoteClusterClient(env.Ctx, c, cluster)
+	require.NoError(t, err)
+	assert.Equal(t, "https://cache-test.example.com:6443", config.Host)
+
+	second, _, err := remoteClusterClient(env.Ctx, c, cluster)
+	require.NoError(t, err)
+	assert.Same(t, first, second, "the client is reused while the kubeconfig is unchanged")
+
+	// A new kubeconfig, e.g. with a rotated CA, builds a new client
+	var stored corev1.Secret
+	require.NoError(t, c.Get(env.Ctx, client.ObjectKeyFromObject(secret), &stored))
+	stored.Data = kubeconfigSecret(secret.Name, "https://cache-test-new.example.com:6443").Data
+	require.NoError(t, c.Update(env.Ctx, &stored))
+	third, config, err := remoteClusterClient(env.Ctx, c, cluster)
+	require.NoError(t, err)
+	assert.NotSame(t, first, third)
+	assert.Equal(t, "https://cache-test-new.example.com:6443", config.Host)
+
+	// A certificate error drops the cached client
+	forgetRemoteClient(remoteClusterKey(cluster))
+	fourth, _, err := remoteClusterClient(env.Ctx, c, cluster)
+	require.NoError(t, err)
+	assert.NotSame(t, third, fourth)
+}
+
+func TestWithRemoteCluster(t *testing.T) {
+	previous := remoteClusterRetryDelay
+	remoteClusterRetryDelay = func(int) time.Duration { return time.Millisecond }
+	t.Cleanup(func() { remoteClusterRetryDelay = previous })
+	ctx := context.Background()
+
+	attempts := 0
+	err := withRemoteCluster(ctx, func() error {
+		attempts++
+		if attempts == 1 {
+			return fmt.Errorf("list namespaces: %w", x509.UnknownAuthorityError{})
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, attempts, "a certificate error is retried")
+
+	attempts = 0
+	err = withRemoteCluster(ctx, func() error {
+		attempts++
+		return errors.New("namespaces is forbidden")
+	})
+	require.Error(t, err)
+	assert.Equal(t, 1, attempts, "API errors are not retried")
+
+	attempts = 0
+	err = withRemoteCluster(ctx, func() error {
+		attempts++
+		return &util.CircuitOpenError{Cluster: "default/prod", RetryAfter: time.Minute}
+	})
+	_, open := util.IsCircuitOpen(err)
+	assert.True(t, open)
+	assert.Equal(t, 1, attempts, "an open circuit is returned right away")
+
+	attempts = 0
+	err = withRemoteCluster(ctx, func() error {
+		attempts++
+		return fmt.Errorf("list namespaces: %w", x509.UnknownAuthorityError{})
+	})
+	require.Error(t, err)
+	assert.Equal(t, remoteClusterAttempts, attempts)
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
@@ -156,9 +157,12 @@ func (h *ImmutableResourceHandler) handleRecreate(ctx context.Context, obj runti
 	}
 
 	// Delete resource
+	ref := audit.RefForObject(clientObj)
 	if err := h.ctrlClient.Delete(ctx, clientObj); err != nil && !apierrors.IsNotFound(err) {
+		audit.Record(ctx, audit.OperationDelete, ref, "", err)
 		return fmt.Errorf("failed to delete resource: %w", err)
 	}
+	audit.Record(ctx, audit.OperationDelete, ref, "recreate", nil)
 
 	// Wait for deletion
 	key := types.NamespacedName{
@@ -174,7 +178,9 @@ func (h *ImmutableResourceHandler) handleRecreate(ctx context.Context, obj runti
 	}
 
 	// Create new resource
-	if err := h.ctrlClient.Create(ctx, clientObj); err != nil {
+	err := h.ctrlClient.Create(ctx, clientObj)
+	audit.Record(ctx, audit.OperationCreate, ref, "recreate", err)
+	if err != nil {
 		return fmt.Errorf("failed to create resource: %w", err)
 	}
 
@@ -244,7 +250,9 @@ func (h *ImmutableResourceHandler) handlePartialUpdate(ctx context.Context, obj
 	}
 
 	// Update the resource
-	if err := h.ctrlClient.Update(ctx, updated); err != nil {
+	err = h.ctrlClient.Update(ctx, updated)
+	audit.Record(ctx, audit.OperationUpdate, audit.RefForObject(clientObj), audit.DiffObjects(current, updated), err)
+	if err != nil {
 		return fmt.Errorf("failed to update resource: %w", err)
 	}
 
@@ -268,11 +276,14 @@ func (h *ImmutableResourceHandler) handleForceUpdate(ctx context.Context, obj ru
 
 	// Delete with foreground cascading
 	foreground := metav1.DeletePropagationForeground
+	ref := audit.RefForObject(clientObj)
 	if err := h.ctrlClient.Delete(ctx, clientObj, &client.DeleteOptions{
 		PropagationPolicy: &foreground,
 	}); err != nil && !apierrors.IsNotFound(err) {
+		audit.Record(ctx, audit.OperationDelete, ref, "force update", err)
 		return fmt.Errorf("failed to delete resource: %w", err)
 	}
+	audit.Record(ctx, audit.OperationDelete, ref, "force update", nil)
 
 	// Wait for cascading deletion
 	key := types.NamespacedName{
@@ -291,7 +302,9 @@ func (h *ImmutableResourceHandler) handleForceUpdate(ctx context.Context, obj ru
 	}
 
 	// Create new resource
-	if err := h.ctrlClient.Create(ctx, clientObj); err != nil {
+	err := h.ctrlClient.Create(ctx, clientObj)
+	audit.Record(ctx, audit.OperationCreate, ref, "force update", err)
+	if err != nil {
 		return fmt.Errorf("failed to create resource: %w", err)
 	}
 
@@ -6,6 +6,7 @@ import (
 	"reflect"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
 	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -109,6 +110,7 @@ func syncPersistentVolumeClaims(ctx context.Context, sourceClient, destClient ku
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				_, err = destClient.CoreV1().PersistentVolumeClaims(dstNamespace).Create(ctx, destPVC, metav1.CreateOptions{})
+				audit.Record(ctx, audit.OperationCreate, audit.RefForObject(destPVC), "", err)
 				if err != nil {
 					log.WithError(err).Error(fmt.Sprintf("failed to create PVC %s", destPVC.Name))
 					continue
@@ -123,6 +125,7 @@ func syncPersistentVolumeClaims(ctx context.Context, sourceClient, destClient ku
 			if !reflect.DeepEqual(destPVC.Spec, existing.Spec) {
 				destPVC.ResourceVersion = existing.ResourceVersion
 				_, err = destClient.CoreV1().PersistentVolumeClaims(dstNamespace).Update(ctx, destPVC, metav1.UpdateOptions{})
+				audit.Record(ctx, audit.OperationUpdate, audit.RefForObject(destPVC), audit.DiffObjects(existing, destPVC), err)
 				if err != nil {
 					log.WithError(err).Error(fmt.Sprintf("failed to update PVC %s", destPVC.Name))
 					continue
@@ -159,6 +162,7 @@ func syncPersistentVolume(ctx context.Context, sourceClient, destClient kubernet
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			_, err = destClient.CoreV1().PersistentVolumes().Create(ctx, destPV, metav1.CreateOptions{})
+			audit.Record(ctx, audit.OperationCreate, audit.RefForObject(destPV), "", err)
 			if err != nil {
 				return fmt.Errorf("failed to create PV %s: %v", destPV.Name, err)
 			}
@@ -170,6 +174,7 @@ func syncPersistentVolume(ctx context.Context, sourceClient, destClient kubernet
 		if !reflect.DeepEqual(destPV.Spec, existing.Spec) {
 			destPV.ResourceVersion = existing.ResourceVersion
 			_, err = destClient.CoreV1().PersistentVolumes().Update(ctx, destPV, metav1.UpdateOptions{})
+			audit.Record(ctx, audit.OperationUpdate, audit.RefForObject(destPV), audit.DiffObjects(existing, destPV), err)
 			if err != nil {
 				return fmt.Errorf("failed to update PV %s: %v", destPV.Name, err)
 			}
@@ -0,0 +1,37 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// backupBeforeUpdate snapshots the current destination object when backups are enabled
+func (r *ResourceSyncer) backupBeforeUpdate(ctx context.Context, gvr schema.GroupVersionResource, existing *unstructured.Unstructured) error {
+	if r == nil || r.backups == nil {
+		return nil
+	}
+	if err := r.backups.Save(ctx, gvr, existing); err != nil {
+		return fmt.Errorf("failed to back up %s %s/%s before update: %w", gvr.Resource, existing.GetNamespace(), existing.GetName(), err)
+	}
+	return nil
+}
+
+// backupPVCBeforeUpdate snapshots a destination PVC fetched with the typed client when backups are enabled
+func (r *ResourceSyncer) backupPVCBeforeUpdate(ctx context.Context, existing *corev1.PersistentVolumeClaim) error {
+	if r == nil || r.backups == nil {
+		return nil
+	}
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(existing)
+	if err != nil {
+		return fmt.Errorf("failed to convert PVC %s/%s for backup: %w", existing.Namespace, existing.Name, err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	// Typed clients do not populate TypeMeta
+	u.SetGroupVersionKind(pvcGVK)
+	return r.backupBeforeUpdate(ctx, pvcGVR, u)
+}
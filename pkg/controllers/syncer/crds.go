@@ -0,0 +1,178 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// crdGVR is the resource of CustomResourceDefinitions
+var crdGVR = schema.GroupVersionResource{
+	Group:    "apiextensions.k8s.io",
+	Version:  "v1",
+	Resource: "customresourcedefinitions",
+}
+
+// syncCustomResourceDefinitions synchronizes CRDs between clusters. A CRD is only synced when the
+// destination serves no version the source lacks, a destination that is ahead of the source is never
+// downgraded. The conversion of the destination is kept over a source conversion webhook, which is
+// only copied when its service exists in the destination. The CRDs that could not be synced as they
+// are in the source are returned as incompatibilities.
+func syncCustomResourceDefinitions(ctx context.Context, syncer *ResourceSyncer, sourceDynamic dynamic.Interface) ([]string, error) {
+	// List CRDs from source cluster
+	crds, err := sourceDynamic.Resource(crdGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list CRDs: %w", err)
+	}
+
+	var incompatibilities []string
+	for i := range crds.Items {
+		crd := &crds.Items[i]
+		if utils.ShouldIgnoreResource(crd) {
+			continue
+		}
+
+		desired, problems, err := syncer.planCRD(ctx, crd)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sync CRD %s: %w", crd.GetName(), err)
+		}
+		for _, problem := range problems {
+			log.Info(fmt.Sprintf("CRD %s: %s", crd.GetName(), problem))
+			incompatibilities = append(incompatibilities, fmt.Sprintf("%s: %s", crd.GetName(), problem))
+		}
+		if desired == nil {
+			continue
+		}
+
+		// Sync the CRD
+		if err := syncer.SyncResource(ctx, desired, nil); err != nil {
+			return nil, fmt.Errorf("failed to sync CRD %s: %w", crd.GetName(), err)
+		}
+	}
+
+	return incompatibilities, nil
+}
+
+// planCRD returns the CRD to write to the destination for a source CRD, nil when the destination CRD
+// is left alone, along with the incompatibilities between the source and the destination
+func (r *ResourceSyncer) planCRD(ctx context.Context, source *unstructured.Unstructured) (*unstructured.Unstructured, []string, error) {
+	existing, err := r.destDynamic.Resource(crdGVR).Get(ctx, source.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, nil, fmt.Errorf("failed to get CRD in destination: %w", err)
+		}
+		existing = nil
+	}
+
+	var problems []string
+	if existing != nil {
+		if newer := missingVersions(crdVersionNames(existing), crdVersionNames(source)); len(newer) > 0 {
+			problems = append(problems, fmt.Sprintf("destination serves versions %s the source does not have, the destination CRD is not updated", strings.Join(newer, ", ")))
+			return nil, problems, nil
+		}
+	}
+
+	desired := source.DeepCopy()
+	problem, err := r.mergeCRDConversion(ctx, desired, existing)
+	if err != nil {
+		return nil, nil, err
+	}
+	if problem != "" {
+		problems = append(problems, problem)
+	}
+	return desired, problems, nil
+}
+
+// mergeCRDConversion sets the conversion of the CRD to write to the destination. The conversion of an
+// existing destination CRD with a webhook is kept, its service and CA bundle are the ones of the
+// destination. A source conversion webhook is only copied when its service exists in the destination,
+// the conversion falls back to the destination's or to None otherwise and the problem is returned when
+// objects must be converted between several versions.
+func (r *ResourceSyncer) mergeCRDConversion(ctx context.Context, desired, existing *unstructured.Unstructured) (string, error) {
+	var existingConversion map[string]interface{}
+	if existing != nil {
+		existingConversion, _, _ = unstructured.NestedMap(existing.Object, "spec", "conversion")
+	}
+	if conversionStrategy(existingConversion) == "Webhook" {
+		return "", unstructured.SetNestedMap(desired.Object, existingConversion, "spec", "conversion")
+	}
+
+	conversion, _, _ := unstructured.NestedMap(desired.Object, "spec", "conversion")
+	if conversionStrategy(conversion) != "Webhook" {
+		return "", nil
+	}
+
+	namespace, _, _ := unstructured.NestedString(conversion, "webhook", "clientConfig", "service", "namespace")
+	name, _, _ := unstructured.NestedString(conversion, "webhook", "clientConfig", "service", "name")
+	if name == "" {
+		// A webhook served from a URL does not depend on the destination cluster
+		return "", nil
+	}
+
+	_, err := r.destClient.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return "", nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return "", fmt.Errorf("failed to get conversion webhook service %s/%s in destination: %w", namespace, name, err)
+	}
+
+	fallback := existingConversion
+	if fallback == nil {
+		fallback = map[string]interface{}{"strategy": "None"}
+	}
+	if err := unstructured.SetNestedMap(desired.Object, fallback, "spec", "conversion"); err != nil {
+		return "", err
+	}
+	if len(crdVersionNames(desired)) < 2 {
+		return "", nil
+	}
+	return fmt.Sprintf("conversion webhook service %s/%s does not exist in the destination, versions are not converted", namespace, name), nil
+}
+
+// conversionStrategy returns the strategy of a CRD conversion, None when it is unset
+func conversionStrategy(conversion map[string]interface{}) string {
+	strategy, _, _ := unstructured.NestedString(conversion, "strategy")
+	if strategy == "" {
+		return "None"
+	}
+	return strategy
+}
+
+// crdVersionNames returns the names of the versions of a CRD
+func crdVersionNames(crd *unstructured.Unstructured) []string {
+	versions, _, _ := unstructured.NestedSlice(crd.Object, "spec", "versions")
+	names := make([]string, 0, len(versions))
+	for _, version := range versions {
+		if v, ok := version.(map[string]interface{}); ok {
+			if name, ok := v["name"].(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
+// missingVersions returns the sorted versions of have that are not in want
+func missingVersions(have, want []string) []string {
+	known := make(map[string]bool, len(want))
+	for _, name := range want {
+		known[name] = true
+	}
+	var missing []string
+	for _, name := range have {
+		if !known[name] {
+			missing = append(missing, name)
+		}
+	}
+	sort.Strings(missing)
+	return missing
+}
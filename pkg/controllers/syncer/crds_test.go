@@ -0,0 +1,124 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func testCRD(conversion map[string]interface{}, versions ...string) *unstructured.Unstructured {
+	var specVersions []interface{}
+	for i, name := range versions {
+		specVersions = append(specVersions, map[string]interface{}{
+			"name":    name,
+			"served":  true,
+			"storage": i == len(versions)-1,
+		})
+	}
+	crd := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiextensions.k8s.io/v1",
+		"kind":       "CustomResourceDefinition",
+		"metadata":   map[string]interface{}{"name": "widgets.example.com"},
+		"spec": map[string]interface{}{
+			"group":    "example.com",
+			"versions": specVersions,
+		},
+	}}
+	if conversion != nil {
+		crd.Object["spec"].(map[string]interface{})["conversion"] = conversion
+	}
+	return crd
+}
+
+func webhookConversion(service string) map[string]interface{} {
+	return map[string]interface{}{
+		"strategy": "Webhook",
+		"webhook": map[string]interface{}{
+			"clientConfig": map[string]interface{}{
+				"service":  map[string]interface{}{"namespace": "widgets-system", "name": service},
+				"caBundle": service + "-ca",
+			},
+		},
+	}
+}
+
+func newCRDTestSyncer(destObjects []runtime.Object, services ...*corev1.Service) *ResourceSyncer {
+	destDynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{crdGVR: "CustomResourceDefinitionList"}, destObjects...)
+	destClient := fake.NewSimpleClientset()
+	for _, service := range services {
+		_, _ = destClient.CoreV1().Services(service.Namespace).Create(context.Background(), service, metav1.CreateOptions{})
+	}
+	return NewResourceSyncer(nil, nil, destDynamic, nil, destClient, runtime.NewScheme())
+}
+
+func TestPlanCRD_DestinationNewer(t *testing.T) {
+	syncer := newCRDTestSyncer([]runtime.Object{testCRD(nil, "v1", "v2")})
+
+	desired, problems, err := syncer.planCRD(context.Background(), testCRD(nil, "v1"))
+	require.NoError(t, err)
+	assert.Nil(t, desired)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "destination serves versions v2 the source does not have")
+}
+
+func TestPlanCRD_SourceNewer(t *testing.T) {
+	syncer := newCRDTestSyncer([]runtime.Object{testCRD(nil, "v1")})
+
+	desired, problems, err := syncer.planCRD(context.Background(), testCRD(nil, "v1", "v2"))
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	require.NotNil(t, desired)
+	assert.Equal(t, []string{"v1", "v2"}, crdVersionNames(desired))
+}
+
+func TestPlanCRD_KeepsDestinationWebhook(t *testing.T) {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "widgets-system", Name: "source-webhook"}}
+	syncer := newCRDTestSyncer([]runtime.Object{testCRD(webhookConversion("dest-webhook"), "v1", "v2")}, service)
+
+	desired, problems, err := syncer.planCRD(context.Background(), testCRD(webhookConversion("source-webhook"), "v1", "v2"))
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	name, _, _ := unstructured.NestedString(desired.Object, "spec", "conversion", "webhook", "clientConfig", "service", "name")
+	assert.Equal(t, "dest-webhook", name)
+}
+
+func TestPlanCRD_WebhookServiceMissing(t *testing.T) {
+	syncer := newCRDTestSyncer(nil)
+
+	desired, problems, err := syncer.planCRD(context.Background(), testCRD(webhookConversion("source-webhook"), "v1", "v2"))
+	require.NoError(t, err)
+	require.NotNil(t, desired)
+	strategy, _, _ := unstructured.NestedString(desired.Object, "spec", "conversion", "strategy")
+	assert.Equal(t, "None", strategy)
+	_, found, _ := unstructured.NestedMap(desired.Object, "spec", "conversion", "webhook")
+	assert.False(t, found)
+	require.Len(t, problems, 1)
+	assert.Contains(t, problems[0], "conversion webhook service widgets-system/source-webhook does not exist")
+
+	// A single version needs no conversion, falling back to None is not a problem
+	desired, problems, err = syncer.planCRD(context.Background(), testCRD(webhookConversion("source-webhook"), "v1"))
+	require.NoError(t, err)
+	require.NotNil(t, desired)
+	assert.Empty(t, problems)
+}
+
+func TestPlanCRD_WebhookServiceExists(t *testing.T) {
+	service := &corev1.Service{ObjectMeta: metav1.ObjectMeta{Namespace: "widgets-system", Name: "source-webhook"}}
+	syncer := newCRDTestSyncer(nil, service)
+
+	desired, problems, err := syncer.planCRD(context.Background(), testCRD(webhookConversion("source-webhook"), "v1", "v2"))
+	require.NoError(t, err)
+	assert.Empty(t, problems)
+	name, _, _ := unstructured.NestedString(desired.Object, "spec", "conversion", "webhook", "clientConfig", "service", "name")
+	assert.Equal(t, "source-webhook", name)
+}
@@ -0,0 +1,36 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	controller "github.com/supporttools/dr-syncer/pkg/controller/replication"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// dataSyncLimits returns the maxConcurrentDataSyncs of the source and destination RemoteClusters of a
+// mapping. Clusters without a limit, and clusters that cannot be read, are left out.
+func dataSyncLimits(ctx context.Context, c client.Client, mappingNamespace string, spec *drv1alpha1.NamespaceMappingSpec) []controller.ClusterLimit {
+	namespace := remoteClusterNamespace(mappingNamespace, spec)
+	if c == nil || namespace == "" {
+		return nil
+	}
+
+	var limits []controller.ClusterLimit
+	for _, name := range []string{spec.SourceCluster, spec.DestinationCluster} {
+		if name == "" {
+			continue
+		}
+		var remoteCluster drv1alpha1.RemoteCluster
+		if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &remoteCluster); err != nil {
+			log.Warn(fmt.Sprintf("failed to get RemoteCluster %s/%s, its data sync limit is not applied: %v", namespace, name, err))
+			continue
+		}
+		if limit := remoteCluster.Spec.PVCSync.GetMaxConcurrentDataSyncs(); limit > 0 {
+			limits = append(limits, controller.ClusterLimit{Namespace: namespace, Name: name, Limit: int64(limit)})
+		}
+	}
+	return limits
+}
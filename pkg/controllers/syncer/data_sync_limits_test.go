@@ -0,0 +1,39 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	controller "github.com/supporttools/dr-syncer/pkg/controller/replication"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDataSyncLimits(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&drv1alpha1.RemoteCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "dr", Namespace: "dr-syncer"},
+			Spec: drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{
+				MaxConcurrentDataSyncs: ptr.To(int32(3)),
+			}},
+		},
+		&drv1alpha1.RemoteCluster{ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "dr-syncer"}},
+	).Build()
+	ctx := context.Background()
+
+	assert.Equal(t, []controller.ClusterLimit{{Namespace: "dr-syncer", Name: "dr", Limit: 3}},
+		dataSyncLimits(ctx, c, "shop", &drv1alpha1.NamespaceMappingSpec{
+			SourceCluster:      "prod",
+			DestinationCluster: "dr",
+			ClusterMappingRef:  &drv1alpha1.ClusterMappingReference{Name: "prod-to-dr", Namespace: "dr-syncer"},
+		}), "unlimited clusters are left out")
+	assert.Empty(t, dataSyncLimits(ctx, c, "shop", &drv1alpha1.NamespaceMappingSpec{SourceCluster: "prod", DestinationCluster: "dr"}),
+		"missing clusters are left out")
+}
@@ -0,0 +1,148 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ErrDependencyMissing is wrapped by the error of a sync whose workloads reference a ConfigMap or Secret
+// found neither in the source namespace nor in the namespaces of the mapping's dependencyConfig
+var ErrDependencyMissing = errors.New("dependency missing")
+
+// dependency is a ConfigMap or Secret referenced by a synced workload
+type dependency struct {
+	kind string
+	name string
+}
+
+// recordDependencies records the ConfigMaps and Secrets referenced by the pod template of a workload when
+// the mapping resolves dependencies. A dependency is required unless every reference to it is optional.
+func (r *ResourceSyncer) recordDependencies(gvr schema.GroupVersionResource, u *unstructured.Unstructured) {
+	if len(r.dependencyNamespaces) == 0 {
+		return
+	}
+	path, ok := podTemplatePaths[gvr.GroupResource()]
+	if !ok {
+		return
+	}
+	spec, found, _ := unstructured.NestedFieldNoCopy(u.Object, path...)
+	podSpec, ok := spec.(map[string]interface{})
+	if !found || !ok {
+		return
+	}
+
+	if r.dependencies == nil {
+		r.dependencies = make(map[dependency]bool)
+	}
+	eachPodSpecReference(podSpec, func(ref map[string]interface{}, field, kind string) {
+		name, _ := ref[field].(string)
+		if name == "" {
+			return
+		}
+		optional, _ := ref["optional"].(bool)
+		key := dependency{kind: kind, name: name}
+		r.dependencies[key] = r.dependencies[key] || !optional
+	})
+}
+
+// syncDependencies syncs the dependencies recorded while syncing workloads that do not exist in the source
+// namespace from the first namespace of the dependency config that has them. Dependencies found nowhere
+// fail the sync with ErrDependencyMissing, unless every reference to them is optional.
+func syncDependencies(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
+	if len(syncer.dependencies) == 0 {
+		return nil
+	}
+
+	keys := make([]dependency, 0, len(syncer.dependencies))
+	for key := range syncer.dependencies {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].kind != keys[j].kind {
+			return keys[i].kind < keys[j].kind
+		}
+		return keys[i].name < keys[j].name
+	})
+
+	var missing []string
+	for _, key := range keys {
+		if !syncer.selected(key.kind, key.name) {
+			continue
+		}
+
+		// References resolved in the source namespace are synced with the rest of the namespace
+		_, err := getDependency(ctx, sourceClient, srcNamespace, key)
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			syncer.recordResult(key.kind, key.name, syncerrors.NewRetryableError(
+				fmt.Errorf("failed to get dependency: %w", err),
+				fmt.Sprintf("%s/%s", key.kind, key.name),
+			))
+			continue
+		}
+
+		resolved, err := syncer.resolveDependency(ctx, sourceClient, dstNamespace, key, config)
+		if err != nil {
+			syncer.recordResult(key.kind, key.name, err)
+			continue
+		}
+		if !resolved && syncer.dependencies[key] {
+			missing = append(missing, fmt.Sprintf("%s/%s", key.kind, key.name))
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: %s referenced by synced workloads not found in namespace %s or in %s",
+			ErrDependencyMissing, strings.Join(missing, ", "), srcNamespace, strings.Join(syncer.dependencyNamespaces, ", "))
+	}
+	return nil
+}
+
+// resolveDependency syncs a dependency from the first namespace of the dependency config that has it into
+// the destination namespace, returning false when none has it
+func (r *ResourceSyncer) resolveDependency(ctx context.Context, sourceClient kubernetes.Interface, dstNamespace string, key dependency, config *drv1alpha1.ImmutableResourceConfig) (bool, error) {
+	for _, namespace := range r.dependencyNamespaces {
+		obj, err := getDependency(ctx, sourceClient, namespace, key)
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, syncerrors.NewRetryableError(
+				fmt.Errorf("failed to get dependency from namespace %s: %w", namespace, err),
+				fmt.Sprintf("%s/%s", key.kind, key.name),
+			)
+		}
+		if utils.ShouldIgnoreResource(obj) {
+			return false, nil
+		}
+
+		log.Info(fmt.Sprintf("syncing dependency %s %s from %s to %s", key.kind, key.name, namespace, dstNamespace))
+		obj.SetNamespace(dstNamespace)
+		r.recordResult(key.kind, key.name, r.SyncResource(ctx, obj, config))
+		return true, nil
+	}
+	return false, nil
+}
+
+// getDependency gets a ConfigMap or Secret dependency from a source namespace
+func getDependency(ctx context.Context, sourceClient kubernetes.Interface, namespace string, key dependency) (client.Object, error) {
+	if key.kind == "ConfigMap" {
+		return sourceClient.CoreV1().ConfigMaps(namespace).Get(ctx, key.name, metav1.GetOptions{})
+	}
+	return sourceClient.CoreV1().Secrets(namespace).Get(ctx, key.name, metav1.GetOptions{})
+}
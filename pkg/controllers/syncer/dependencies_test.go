@@ -0,0 +1,90 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func dependencyTestDeployment(volumes ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "app"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"volumes": volumes,
+					"containers": []interface{}{map[string]interface{}{
+						"name": "web",
+						"envFrom": []interface{}{map[string]interface{}{
+							"configMapRef": map[string]interface{}{"name": "settings"},
+						}},
+					}},
+				},
+			},
+		},
+	}}
+}
+
+func TestSyncDependencies(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	sourceClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "app"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "wildcard-tls", Namespace: "shared-certs"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "wildcard-tls", Namespace: "other-certs"}},
+	)
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+	syncer := NewResourceSyncer(nil, nil, destDynamic, sourceClient, nil, scheme)
+	syncer.dependencyNamespaces = []string{"shared-certs", "other-certs"}
+
+	syncer.recordDependencies(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, dependencyTestDeployment(
+		map[string]interface{}{"name": "tls", "secret": map[string]interface{}{"secretName": "wildcard-tls"}},
+		map[string]interface{}{"name": "extra", "secret": map[string]interface{}{"secretName": "extra", "optional": true}},
+	))
+	require.NoError(t, syncDependencies(ctx, syncer, sourceClient, "app", "app-dr", nil))
+
+	// Only the dependency missing from the source namespace is synced, from the first namespace having it
+	secrets, err := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).Namespace("app-dr").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, secrets.Items, 1)
+	assert.Equal(t, "wildcard-tls", secrets.Items[0].GetName())
+	configMaps, err := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("app-dr").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, configMaps.Items)
+}
+
+func TestSyncDependencies_Missing(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	sourceClient := fake.NewSimpleClientset(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "app"}})
+	syncer := NewResourceSyncer(nil, nil, dynamicfake.NewSimpleDynamicClient(scheme), sourceClient, nil, scheme)
+	syncer.dependencyNamespaces = []string{"shared-certs"}
+
+	syncer.recordDependencies(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, dependencyTestDeployment(
+		map[string]interface{}{"name": "tls", "secret": map[string]interface{}{"secretName": "wildcard-tls"}},
+	))
+	err := syncDependencies(context.Background(), syncer, sourceClient, "app", "app-dr", nil)
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrDependencyMissing))
+	assert.Contains(t, err.Error(), "Secret/wildcard-tls referenced by synced workloads not found in namespace app or in shared-certs")
+}
+
+func TestRecordDependencies_Disabled(t *testing.T) {
+	syncer := NewResourceSyncer(nil, nil, nil, nil, nil, nil)
+	syncer.recordDependencies(schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, dependencyTestDeployment())
+	assert.Empty(t, syncer.dependencies)
+}
@@ -0,0 +1,192 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// defaultWildcardExclusions are never synced by a wildcard, they are generated by the destination
+// cluster or only meaningful in the cluster that produced them
+var defaultWildcardExclusions = []string{
+	"pods",
+	"events",
+	"endpoints",
+	"endpointslices",
+	"leases",
+	"replicasets",
+	"controllerrevisions",
+}
+
+// typedResourceTypes maps the resource types with a dedicated sync function to their resource type name
+var typedResourceTypes = map[schema.GroupResource]string{
+	{Group: "", Resource: "configmaps"}:                 "configmaps",
+	{Group: "", Resource: "secrets"}:                    "secrets",
+	{Group: "apps", Resource: "deployments"}:            "deployments",
+	{Group: "apps", Resource: "daemonsets"}:             "daemonsets",
+	{Group: "", Resource: "services"}:                   "services",
+	{Group: "networking.k8s.io", Resource: "ingresses"}: "ingresses",
+	{Group: "", Resource: "persistentvolumeclaims"}:     "persistentvolumeclaims",
+	{Group: "batch", Resource: "cronjobs"}:              "cronjobs",
+	{Group: "batch", Resource: "jobs"}:                  "jobs",
+	{Group: "", Resource: "resourcequotas"}:             "resourcequotas",
+	{Group: "", Resource: "limitranges"}:                "limitranges",
+}
+
+// splitResourceTypes splits the built-in resource types without a dedicated sync function, such as the
+// statefulsets of the workloads category, off resourceTypes. They are synced like discovered resources.
+func splitResourceTypes(resourceTypes, excluded []string) ([]string, []schema.GroupVersionResource) {
+	typed := make(map[string]bool, len(typedResourceTypes))
+	for _, name := range typedResourceTypes {
+		typed[name] = true
+	}
+
+	var remaining []string
+	var resources []schema.GroupVersionResource
+	for _, resourceType := range resourceTypes {
+		gvr, ok := utils.ResourceTypeGVR(resourceType)
+		if !ok || typed[gvr.Resource] {
+			remaining = append(remaining, resourceType)
+			continue
+		}
+		if !isExcludedResource(gvr.GroupResource(), excluded) {
+			resources = append(resources, gvr)
+		}
+	}
+	return remaining, resources
+}
+
+// isWildcard reports whether resourceTypes selects every namespaced resource type
+func isWildcard(resourceTypes []string) bool {
+	return len(resourceTypes) == 1 && resourceTypes[0] == "*"
+}
+
+// discoverWildcardResources expands ResourceTypes ["*"] using API discovery of the source cluster
+func discoverWildcardResources(dc discovery.DiscoveryInterface, excluded []string) ([]string, []schema.GroupVersionResource, error) {
+	lists, err := discovery.ServerPreferredNamespacedResources(dc)
+	if err != nil {
+		// Unavailable aggregated APIs must not block the sync of everything else
+		if !discovery.IsGroupDiscoveryFailedError(err) || len(lists) == 0 {
+			return nil, nil, fmt.Errorf("failed to discover namespaced resources: %w", err)
+		}
+		log.Errorf("partial API discovery, some resource types will not be synced: %v", err)
+	}
+
+	resourceTypes, resources := wildcardResources(lists, excluded)
+	return resourceTypes, resources, nil
+}
+
+// wildcardResources splits discovered resources into the resource types handled by the typed sync
+// functions and the GVRs synced through the dynamic client, dropping excluded and read-only types
+func wildcardResources(lists []*metav1.APIResourceList, excluded []string) ([]string, []schema.GroupVersionResource) {
+	exclusions := append(append([]string{}, defaultWildcardExclusions...), excluded...)
+	lists = discovery.FilteredBy(discovery.SupportsAllVerbs{Verbs: []string{"list", "get", "create", "update"}}, lists)
+
+	var resourceTypes []string
+	var resources []schema.GroupVersionResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			log.Errorf("skipping invalid group version %s: %v", list.GroupVersion, err)
+			continue
+		}
+
+		for _, apiResource := range list.APIResources {
+			// Subresources are synced with their parent
+			if !apiResource.Namespaced || strings.Contains(apiResource.Name, "/") {
+				continue
+			}
+
+			gr := schema.GroupResource{Group: gv.Group, Resource: apiResource.Name}
+			if isExcludedResource(gr, exclusions) {
+				continue
+			}
+
+			if resourceType, ok := typedResourceTypes[gr]; ok {
+				resourceTypes = append(resourceTypes, resourceType)
+				continue
+			}
+			resources = append(resources, gv.WithResource(apiResource.Name))
+		}
+	}
+
+	sort.Strings(resourceTypes)
+	sort.Slice(resources, func(i, j int) bool {
+		if resources[i].Group != resources[j].Group {
+			return resources[i].Group < resources[j].Group
+		}
+		return resources[i].Resource < resources[j].Resource
+	})
+
+	return resourceTypes, resources
+}
+
+// isExcludedResource checks gr against exclusions in "resource" or "resource.group" format
+func isExcludedResource(gr schema.GroupResource, exclusions []string) bool {
+	for _, exclusion := range exclusions {
+		exclusion = strings.ToLower(exclusion)
+		if exclusion == gr.Resource || (gr.Group != "" && exclusion == gr.Resource+"."+gr.Group) {
+			return true
+		}
+	}
+	return false
+}
+
+// accessibleResourceTypes drops the resource types either cluster denies access to, so a wildcard
+// sync is not failed by RBAC restrictions on a single type
+func accessibleResourceTypes(ctx context.Context, sourceClient, destClient kubernetes.Interface, sourceDynamic, destDynamic dynamic.Interface, srcNamespace, dstNamespace string, resourceTypes []string) []string {
+	var accessible []string
+	for _, resourceType := range resourceTypes {
+		if err := verifyClusterAccess(ctx, sourceClient, sourceDynamic, srcNamespace, []string{resourceType}); err != nil {
+			log.Errorf("skipping %s, source cluster access failed: %v", resourceType, err)
+			continue
+		}
+		if err := verifyClusterAccess(ctx, destClient, destDynamic, dstNamespace, []string{resourceType}); err != nil {
+			log.Errorf("skipping %s, destination cluster access failed: %v", resourceType, err)
+			continue
+		}
+		accessible = append(accessible, resourceType)
+	}
+	return accessible
+}
+
+// syncDiscoveredResources syncs each discovered resource type through the dynamic client. Failures
+// only skip the affected type.
+func (r *ResourceSyncer) syncDiscoveredResources(ctx context.Context, resources []schema.GroupVersionResource, srcNamespace, dstNamespace string) {
+	for _, gvr := range resources {
+		err := tracing.Trace(ctx, "SyncResourceType", func(ctx context.Context) error {
+			return r.syncDynamicResources(ctx, gvr, srcNamespace, dstNamespace)
+		}, attribute.String("resource_type", gvr.GroupResource().String()))
+		switch {
+		case err == nil:
+		case apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err) || apierrors.IsNotFound(err):
+			log.Info(fmt.Sprintf("skipping resource %s, not accessible: %v", gvr.GroupResource(), err))
+		default:
+			log.Errorf("failed to sync resource %s: %v", gvr.GroupResource(), err)
+		}
+	}
+}
+
+// syncDynamicResources syncs all objects of gvr in srcNamespace
+func (r *ResourceSyncer) syncDynamicResources(ctx context.Context, gvr schema.GroupVersionResource, srcNamespace, dstNamespace string) error {
+	return eachSourcePage(ctx, gvr, srcNamespace, gvr.Resource, func(opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+		return r.sourceDynamic.Resource(gvr).Namespace(srcNamespace).List(ctx, opts)
+	}, func(list *unstructured.UnstructuredList) error {
+		for i := range list.Items {
+			r.syncDynamicItem(ctx, gvr, &list.Items[i], dstNamespace)
+		}
+		return nil
+	})
+}
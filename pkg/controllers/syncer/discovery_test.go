@@ -0,0 +1,84 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestWildcardResources(t *testing.T) {
+	rw := metav1.Verbs{"get", "list", "create", "update", "delete"}
+	lists := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Namespaced: true, Verbs: rw},
+				{Name: "secrets", Namespaced: true, Verbs: rw},
+				{Name: "serviceaccounts", Namespaced: true, Verbs: rw},
+				{Name: "pods", Namespaced: true, Verbs: rw},
+				{Name: "pods/log", Namespaced: true, Verbs: metav1.Verbs{"get"}},
+				{Name: "events", Namespaced: true, Verbs: rw},
+				{Name: "endpoints", Namespaced: true, Verbs: rw},
+				{Name: "bindings", Namespaced: true, Verbs: metav1.Verbs{"create"}},
+				{Name: "namespaces", Namespaced: false, Verbs: rw},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Namespaced: true, Verbs: rw},
+				{Name: "daemonsets", Namespaced: true, Verbs: rw},
+				{Name: "replicasets", Namespaced: true, Verbs: rw},
+				{Name: "statefulsets", Namespaced: true, Verbs: rw},
+			},
+		},
+		{
+			GroupVersion: "coordination.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "leases", Namespaced: true, Verbs: rw},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1beta1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", Namespaced: true, Verbs: rw},
+				{Name: "gadgets", Namespaced: true, Verbs: rw},
+			},
+		},
+	}
+
+	resourceTypes, resources := wildcardResources(lists, []string{"gadgets.example.com", "ConfigMaps"})
+
+	assert.Equal(t, []string{"daemonsets", "deployments", "secrets"}, resourceTypes)
+	assert.Equal(t, []schema.GroupVersionResource{
+		{Group: "", Version: "v1", Resource: "serviceaccounts"},
+		{Group: "apps", Version: "v1", Resource: "statefulsets"},
+		{Group: "example.com", Version: "v1beta1", Resource: "widgets"},
+	}, resources)
+}
+
+func TestIsExcludedResource(t *testing.T) {
+	widgets := schema.GroupResource{Group: "example.com", Resource: "widgets"}
+	assert.True(t, isExcludedResource(widgets, []string{"widgets"}))
+	assert.True(t, isExcludedResource(widgets, []string{"widgets.example.com"}))
+	assert.False(t, isExcludedResource(widgets, []string{"widgets.other.com"}))
+
+	// Core resources have no group suffix
+	assert.True(t, isExcludedResource(schema.GroupResource{Resource: "serviceaccounts"}, []string{"serviceaccounts"}))
+	assert.False(t, isExcludedResource(schema.GroupResource{Resource: "serviceaccounts"}, []string{"serviceaccounts."}))
+}
+
+func TestIsWildcard(t *testing.T) {
+	assert.True(t, isWildcard([]string{"*"}))
+	assert.False(t, isWildcard([]string{"*", "configmaps"}))
+	assert.False(t, isWildcard(nil))
+}
+
+func TestSplitResourceTypes(t *testing.T) {
+	remaining, resources := splitResourceTypes([]string{"deployments", "statefulsets", "networkpolicies", "crds"}, []string{"networkpolicies"})
+	assert.Equal(t, []string{"deployments", "crds"}, remaining, "typed and unknown resource types are kept")
+	assert.Equal(t, []schema.GroupVersionResource{{Group: "apps", Version: "v1", Resource: "statefulsets"}}, resources,
+		"built-in types without a sync function are synced dynamically unless excluded")
+}
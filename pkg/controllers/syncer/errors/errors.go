@@ -1,7 +1,9 @@
 package errors
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 )
@@ -29,6 +31,11 @@ func (e *SyncError) Error() string {
 	return fmt.Sprintf("%s: %v", e.Resource, e.Err)
 }
 
+// Unwrap returns the wrapped error
+func (e *SyncError) Unwrap() error {
+	return e.Err
+}
+
 // NewRetryableError creates a new retryable error
 func NewRetryableError(err error, resource string) *SyncError {
 	return &SyncError{
@@ -81,3 +88,29 @@ func ShouldWaitForNextSync(err error) bool {
 	}
 	return false
 }
+
+// PartialSyncError reports the resources that failed to sync while the other resources were synced
+type PartialSyncError struct {
+	// Synced is the number of resources that were synced
+	Synced int
+
+	// Failures are the errors of the resources that failed to sync
+	Failures []*SyncError
+}
+
+func (e *PartialSyncError) Error() string {
+	messages := make([]string, 0, len(e.Failures))
+	for _, failure := range e.Failures {
+		messages = append(messages, failure.Error())
+	}
+	return fmt.Sprintf("%d of %d resources failed to sync: %s", len(e.Failures), e.Synced+len(e.Failures), strings.Join(messages, "; "))
+}
+
+// AsPartialSyncError returns the PartialSyncError in err's chain, if any
+func AsPartialSyncError(err error) (*PartialSyncError, bool) {
+	var partial *PartialSyncError
+	if errors.As(err, &partial) {
+		return partial, true
+	}
+	return nil, false
+}
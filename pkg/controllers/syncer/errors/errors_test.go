@@ -2,6 +2,7 @@ package errors
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -188,3 +189,20 @@ func (e *statusError) Error() string {
 func (e *statusError) Status() metav1.Status {
 	return *e.status
 }
+
+func TestPartialSyncError(t *testing.T) {
+	partial := &PartialSyncError{
+		Synced: 3,
+		Failures: []*SyncError{
+			NewNonRetryableError(errors.New("admission webhook denied"), "ConfigMap/settings"),
+		},
+	}
+	assert.Equal(t, "1 of 4 resources failed to sync: ConfigMap/settings: admission webhook denied", partial.Error())
+
+	found, ok := AsPartialSyncError(fmt.Errorf("failed to sync namespace resources: %w", partial))
+	assert.True(t, ok)
+	assert.Same(t, partial, found)
+
+	_, ok = AsPartialSyncError(errors.New("generic error"))
+	assert.False(t, ok)
+}
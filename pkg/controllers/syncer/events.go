@@ -0,0 +1,69 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/audit"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// EventReasonSyncUpdated is recorded when a sync updates a destination object
+const EventReasonSyncUpdated = "SyncUpdated"
+
+// recordUpdate audits an update of a destination object. Successful updates are also logged at debug
+// level and reported as an event on the object, listing the changed field paths so operators can find
+// fields that keep flapping. Only paths are reported, so Secret values never end up in events or logs.
+func (r *ResourceSyncer) recordUpdate(ctx context.Context, gvk schema.GroupVersionKind, obj metav1.Object, diff string, err error) {
+	audit.Record(ctx, audit.OperationUpdate, audit.ObjectRef(gvk, obj), diff, err)
+	if err != nil {
+		return
+	}
+
+	if diff == "" {
+		log.Debugf("updated %s %s/%s without changes to compared fields", gvk.Kind, obj.GetNamespace(), obj.GetName())
+		return
+	}
+	log.Debugf("updated %s %s/%s, changed fields: %s", gvk.Kind, obj.GetNamespace(), obj.GetName(), diff)
+	r.emitUpdateEvent(ctx, gvk, obj, diff)
+}
+
+// emitUpdateEvent records an update event on a destination object
+func (r *ResourceSyncer) emitUpdateEvent(ctx context.Context, gvk schema.GroupVersionKind, obj metav1.Object, diff string) {
+	if r == nil || r.destClient == nil {
+		return
+	}
+
+	now := metav1.NewTime(time.Now())
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%x", obj.GetName(), now.UnixNano()),
+			Namespace: obj.GetNamespace(),
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       gvk.Kind,
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+			UID:        obj.GetUID(),
+		},
+		Reason:         EventReasonSyncUpdated,
+		Message:        fmt.Sprintf("Updated from source, changed fields: %s", diff),
+		Type:           corev1.EventTypeNormal,
+		Source:         corev1.EventSource{Component: "dr-syncer"},
+		FirstTimestamp: now,
+		LastTimestamp:  now,
+		Count:          1,
+	}
+	if mapping := audit.MappingFromContext(ctx); mapping != "" {
+		event.Message = fmt.Sprintf("Updated from source by mapping %s, changed fields: %s", mapping, diff)
+	}
+
+	if _, err := r.destClient.CoreV1().Events(obj.GetNamespace()).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		// Log but don't fail - event recording is informational
+		log.Error(fmt.Sprintf("failed to record update event for %s %s/%s: %v", gvk.Kind, obj.GetNamespace(), obj.GetName(), err))
+	}
+}
@@ -0,0 +1,60 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/audit"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestSyncResource_UpdateEvent(t *testing.T) {
+	ctx := audit.WithMapping(context.Background(), "dr-syncer/shop")
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	existing := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app-dr", UID: "uid-1"},
+		Data:       map[string][]byte{"password": []byte("old-password")},
+	}
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme, existing)
+	destClient := fake.NewSimpleClientset()
+
+	syncer := NewResourceSyncer(nil, nil, destDynamic, nil, destClient, scheme)
+	source := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app-dr"},
+		Data:       map[string][]byte{"password": []byte("new-password")},
+	}
+	require.NoError(t, syncer.SyncResource(ctx, source, nil))
+
+	events, err := destClient.CoreV1().Events("app-dr").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, events.Items, 1)
+	event := events.Items[0]
+	assert.Equal(t, EventReasonSyncUpdated, event.Reason)
+	assert.Equal(t, corev1.ObjectReference{APIVersion: "v1", Kind: "Secret", Namespace: "app-dr", Name: "db", UID: "uid-1"}, event.InvolvedObject)
+	assert.Equal(t, "Updated from source by mapping dr-syncer/shop, changed fields: data.password", event.Message)
+	assert.NotContains(t, event.Message, "new-password")
+}
+
+func TestRecordUpdate_NoEvent(t *testing.T) {
+	destClient := fake.NewSimpleClientset()
+	syncer := NewResourceSyncer(nil, nil, nil, nil, destClient, nil)
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "app-dr"}}
+	gvk := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+
+	// Failed updates and updates without changed fields are not reported as events
+	syncer.recordUpdate(context.Background(), gvk, cm, "data.key", errors.New("conflict"))
+	syncer.recordUpdate(context.Background(), gvk, cm, "", nil)
+
+	events, err := destClient.CoreV1().Events("app-dr").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, events.Items)
+}
@@ -0,0 +1,98 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/supporttools/dr-syncer/pkg/contextkeys"
+	controller "github.com/supporttools/dr-syncer/pkg/controller/replication"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+)
+
+// WithRetryResources returns a context whose namespace syncs only sync the resources in resources,
+// referenced as Kind/name like the Resource of a SyncError. It retries the failures of a previous
+// sync without syncing the whole namespace again.
+func WithRetryResources(ctx context.Context, resources []string) context.Context {
+	return context.WithValue(ctx, contextkeys.RetryResourcesKey, resources)
+}
+
+// RetryResources returns the resources the syncs of ctx are limited to, nil when they sync all resources
+func RetryResources(ctx context.Context) []string {
+	resources, _ := ctx.Value(contextkeys.RetryResourcesKey).([]string)
+	return resources
+}
+
+// resourceRef references a resource of a namespace in sync errors and retries
+func resourceRef(kind, name string) string {
+	return fmt.Sprintf("%s/%s", kind, name)
+}
+
+// selected returns true if the resource is synced, false when the sync only retries other resources
+func (r *ResourceSyncer) selected(kind, name string) bool {
+	if r == nil || r.retryOnly == nil {
+		return true
+	}
+	return r.retryOnly[resourceRef(kind, name)]
+}
+
+// recordResult counts the resource as synced, or records why it failed so the sync can go on with
+// the other resources
+func (r *ResourceSyncer) recordResult(kind, name string, err error) {
+	if err != nil {
+		log.Errorf("failed to sync %s %s: %v", kind, name, err)
+	}
+	if r == nil {
+		return
+	}
+	if err == nil {
+		r.syncedCount++
+		return
+	}
+
+	failure := &syncerrors.SyncError{Err: err, Category: syncerrors.NonRetryableError, Resource: resourceRef(kind, name)}
+	var syncErr *syncerrors.SyncError
+	if errors.As(err, &syncErr) {
+		// The failure is referenced by the resource that was synced, not the resource of the error
+		failure.Err = syncErr.Err
+		failure.Category = syncErr.Category
+	} else if syncerrors.IsRetryable(err) {
+		failure.Category = syncerrors.RetryableError
+	}
+	r.failures = append(r.failures, failure)
+}
+
+// recordPVCData counts the data sync of a PVC. A PVC whose data failed to sync does not fail the
+// sync, its object is in place and the data is synced again by the next sync.
+func (r *ResourceSyncer) recordPVCData(err error) {
+	if r == nil {
+		return
+	}
+	if err != nil {
+		r.pvcDataFailed++
+		if errors.Is(err, controller.ErrInsufficientSpace) {
+			r.insufficientSpace = append(r.insufficientSpace, err.Error())
+		}
+		if errors.Is(err, controller.ErrPendingBinding) {
+			r.pendingBinding = append(r.pendingBinding, err.Error())
+		}
+		return
+	}
+	r.pvcDataSynced++
+}
+
+// recordExpansionBlocked records a destination PVC kept at its size because it cannot be expanded
+func (r *ResourceSyncer) recordExpansionBlocked(message string) {
+	if r == nil {
+		return
+	}
+	r.expansionBlocked = append(r.expansionBlocked, message)
+}
+
+// partialSyncError returns the failures of the sync, nil when every resource was synced
+func (r *ResourceSyncer) partialSyncError() error {
+	if r == nil || len(r.failures) == 0 {
+		return nil
+	}
+	return &syncerrors.PartialSyncError{Synced: r.syncedCount, Failures: r.failures}
+}
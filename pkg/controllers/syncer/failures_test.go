@@ -0,0 +1,85 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+// failWrites makes the writes of the named destination object fail
+func failWrites(client *dynamicfake.FakeDynamicClient, resource, name string) {
+	client.PrependReactor("create", resource, func(action clienttesting.Action) (bool, runtime.Object, error) {
+		obj := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+		if obj.GetName() != name {
+			return false, nil, nil
+		}
+		return true, nil, fmt.Errorf("admission webhook denied %s", name)
+	})
+}
+
+func TestSyncContinuesAfterResourceFailure(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	sourceClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "app"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "app"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "last", Namespace: "app"}},
+	)
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+	failWrites(destDynamic, "configmaps", "broken")
+
+	// The failure is recorded and the other ConfigMaps are still synced
+	syncer := NewResourceSyncer(nil, nil, destDynamic, sourceClient, nil, scheme)
+	require.NoError(t, syncConfigMaps(ctx, syncer, sourceClient, "app", "app-dr", nil))
+
+	partial, ok := syncerrors.AsPartialSyncError(syncer.partialSyncError())
+	require.True(t, ok)
+	assert.Equal(t, 2, partial.Synced)
+	require.Len(t, partial.Failures, 1)
+	assert.Equal(t, "ConfigMap/broken", partial.Failures[0].Resource)
+
+	configMaps := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("app-dr")
+	for _, name := range []string{"first", "last"} {
+		_, err := configMaps.Get(ctx, name, metav1.GetOptions{})
+		assert.NoError(t, err, name)
+	}
+}
+
+func TestSyncRetriesOnlySelectedResources(t *testing.T) {
+	ctx := WithRetryResources(context.Background(), []string{"ConfigMap/broken"})
+	assert.Equal(t, []string{"ConfigMap/broken"}, RetryResources(ctx))
+	assert.Nil(t, RetryResources(context.Background()))
+
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	sourceClient := fake.NewSimpleClientset(
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "first", Namespace: "app"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "app"}},
+	)
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+
+	syncer := NewResourceSyncer(nil, nil, destDynamic, sourceClient, nil, scheme)
+	syncer.retryOnly = map[string]bool{"ConfigMap/broken": true}
+	require.NoError(t, syncConfigMaps(ctx, syncer, sourceClient, "app", "app-dr", nil))
+	assert.NoError(t, syncer.partialSyncError())
+	assert.Equal(t, 1, syncer.syncedCount)
+
+	configMaps := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("app-dr")
+	_, err := configMaps.Get(ctx, "broken", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = configMaps.Get(ctx, "first", metav1.GetOptions{})
+	assert.Error(t, err)
+}
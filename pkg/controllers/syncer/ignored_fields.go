@@ -0,0 +1,327 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// builtInInjectedContainers are the names of the containers service meshes and secret agents inject into
+// pod templates
+var builtInInjectedContainers = []string{
+	"istio-proxy", "istio-init", "istio-validation",
+	"linkerd-proxy", "linkerd-init", "linkerd-network-validator",
+	"vault-agent", "vault-agent-init",
+	"consul-dataplane", "consul-connect-inject-init",
+	"daprd",
+}
+
+// defaultedFields are the fields the API server defaults when a resource omits them
+var defaultedFields = map[string]bool{
+	"dnsPolicy":                     true,
+	"imagePullPolicy":               true,
+	"internalTrafficPolicy":         true,
+	"ipFamilies":                    true,
+	"ipFamilyPolicy":                true,
+	"podManagementPolicy":           true,
+	"progressDeadlineSeconds":       true,
+	"protocol":                      true,
+	"restartPolicy":                 true,
+	"revisionHistoryLimit":          true,
+	"schedulerName":                 true,
+	"sessionAffinity":               true,
+	"terminationGracePeriodSeconds": true,
+	"terminationMessagePath":        true,
+	"terminationMessagePolicy":      true,
+}
+
+// podTemplateSpecPaths locate the pod specs of the pod templates of workloads
+var podTemplateSpecPaths = [][]string{
+	{"spec", "template", "spec"},
+	{"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// autoscaledKinds are the kinds whose replicas a HorizontalPodAutoscaler in the destination namespace owns
+var autoscaledKinds = map[string]bool{"Deployment": true, "StatefulSet": true}
+
+// ignoreRules is a compiled drv1alpha1.IgnoreFieldsConfig
+type ignoreRules struct {
+	builtIn            bool
+	injectedContainers []*regexp.Regexp
+	fields             []preservedFields
+}
+
+// compileIgnoreFields compiles the ignoreFields of a NamespaceMapping. The built-in ignores are enabled
+// unless the mapping turns them off.
+func compileIgnoreFields(config *drv1alpha1.IgnoreFieldsConfig) (*ignoreRules, error) {
+	rules := &ignoreRules{builtIn: true}
+	var patterns []string
+	if config != nil {
+		rules.builtIn = config.BuiltIn == nil || *config.BuiltIn
+		patterns = config.InjectedContainers
+	}
+	if rules.builtIn {
+		patterns = append(patterns, builtInInjectedContainers...)
+	}
+	injected, err := compilePatterns(patterns)
+	if err != nil {
+		return nil, err
+	}
+	rules.injectedContainers = injected
+	if config == nil {
+		return rules, nil
+	}
+
+	for _, f := range config.Fields {
+		p := preservedFields{kind: f.Kind}
+		if f.Name != "" {
+			name, err := compilePattern(f.Name)
+			if err != nil {
+				return nil, err
+			}
+			p.name = name
+		}
+		for _, path := range f.Paths {
+			segments, err := parseFieldPath(path)
+			if err != nil {
+				return nil, err
+			}
+			p.paths = append(p.paths, segments)
+		}
+		rules.fields = append(rules.fields, p)
+	}
+	return rules, nil
+}
+
+// injected reports whether a container name matches an injected container pattern
+func (r *ignoreRules) injected(name string) bool {
+	for _, pattern := range r.injectedContainers {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// deletePath removes the value at a path and returns obj without it. Maps and lists left empty are
+// removed as well, so they compare equal to a resource without them.
+func deletePath(obj interface{}, segments []pathSegment) interface{} {
+	segment, rest := segments[0], segments[1:]
+	if segment.isField() {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			return obj
+		}
+		child, ok := m[segment.field]
+		if !ok {
+			return obj
+		}
+		if len(rest) == 0 {
+			delete(m, segment.field)
+			return m
+		}
+		child = deletePath(child, rest)
+		if isEmpty(child) {
+			delete(m, segment.field)
+		} else {
+			m[segment.field] = child
+		}
+		return m
+	}
+
+	list, ok := obj.([]interface{})
+	if !ok {
+		return obj
+	}
+	i := listItem(list, segment)
+	if i < 0 {
+		return obj
+	}
+	if len(rest) == 0 {
+		return append(list[:i:i], list[i+1:]...)
+	}
+	list[i] = deletePath(list[i], rest)
+	return list
+}
+
+// isEmpty reports whether a value is an empty map or list
+func isEmpty(value interface{}) bool {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return len(v) == 0
+	case []interface{}:
+		return len(v) == 0
+	}
+	return false
+}
+
+// withoutIgnoredFields returns a copy of a source or destination resource without the fields the mapping
+// ignores, for comparing them. Entries match the source name of the resource.
+func (r *ResourceSyncer) withoutIgnoredFields(obj *unstructured.Unstructured, kind, sourceName string) *unstructured.Unstructured {
+	obj = obj.DeepCopy()
+	if r.ignores == nil {
+		return obj
+	}
+	for _, f := range r.ignores.fields {
+		if (f.kind != "" && f.kind != kind) || (f.name != nil && !f.name.MatchString(sourceName)) {
+			continue
+		}
+		for _, segments := range f.paths {
+			deletePath(obj.Object, segments)
+		}
+	}
+	return obj
+}
+
+// keepDestinationOwnedFields applies the built-in ignores: the fields of the existing destination resource
+// that HPAs, webhooks and the destination API server own are copied into the resource written to the
+// destination, so they neither count as differences nor are overwritten
+func (r *ResourceSyncer) keepDestinationOwnedFields(ctx context.Context, u, existing *unstructured.Unstructured) {
+	if r.ignores == nil || !r.ignores.builtIn {
+		return
+	}
+	r.keepAutoscaledReplicas(ctx, u, existing)
+	for _, path := range podTemplateSpecPaths {
+		r.ignores.keepInjectedContainers(u, existing, path)
+	}
+	for key, value := range existing.Object {
+		if child, ok := u.Object[key]; ok && key != "metadata" && key != "status" {
+			keepDefaultedFields(child, value)
+		}
+	}
+}
+
+// keepAutoscaledReplicas keeps the destination replicas of a workload a HorizontalPodAutoscaler in the
+// destination namespace scales. Workloads scaled to zero on either side are left alone, so standby
+// workloads stay down and activated ones come up.
+func (r *ResourceSyncer) keepAutoscaledReplicas(ctx context.Context, u, existing *unstructured.Unstructured) {
+	if !autoscaledKinds[u.GetKind()] {
+		return
+	}
+	replicas, found, _ := unstructured.NestedInt64(u.Object, "spec", "replicas")
+	current, foundCurrent, _ := unstructured.NestedInt64(existing.Object, "spec", "replicas")
+	if !found || !foundCurrent || replicas == 0 || current == 0 || replicas == current {
+		return
+	}
+	if !r.autoscaled(ctx, u.GetNamespace(), u.GetKind(), u.GetName()) {
+		return
+	}
+	if err := unstructured.SetNestedField(u.Object, current, "spec", "replicas"); err == nil {
+		log.Info(fmt.Sprintf("keeping the %d replicas of autoscaled %s %s/%s", current, u.GetKind(), u.GetNamespace(), u.GetName()))
+	}
+}
+
+// autoscaled reports whether a HorizontalPodAutoscaler of the destination namespace targets a workload.
+// The autoscalers of a namespace are listed once per sync.
+func (r *ResourceSyncer) autoscaled(ctx context.Context, namespace, kind, name string) bool {
+	if r.destClient == nil {
+		return false
+	}
+	if r.hpaTargets == nil {
+		r.hpaTargets = make(map[string]map[string]bool)
+	}
+	targets, ok := r.hpaTargets[namespace]
+	if !ok {
+		targets = make(map[string]bool)
+		hpas, err := r.destClient.AutoscalingV2().HorizontalPodAutoscalers(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			log.Warn(fmt.Sprintf("failed to list the HorizontalPodAutoscalers of namespace %s, autoscaled replicas are synced: %v", namespace, err))
+		} else {
+			for _, hpa := range hpas.Items {
+				targets[hpa.Spec.ScaleTargetRef.Kind+"/"+hpa.Spec.ScaleTargetRef.Name] = true
+			}
+		}
+		r.hpaTargets[namespace] = targets
+	}
+	return targets[kind+"/"+name]
+}
+
+// keepInjectedContainers adds the injected containers of the existing destination pod template that the
+// source pod template does not have to the written pod template, at their position in the destination
+func (r *ignoreRules) keepInjectedContainers(u, existing *unstructured.Unstructured, path []string) {
+	spec, found, _ := unstructured.NestedFieldNoCopy(u.Object, path...)
+	specMap, ok := spec.(map[string]interface{})
+	if !found || !ok {
+		return
+	}
+
+	for _, field := range []string{"initContainers", "containers"} {
+		current, _, _ := unstructured.NestedSlice(existing.Object, append(append([]string{}, path...), field)...)
+		source, _ := specMap[field].([]interface{})
+
+		names := make(map[string]bool, len(source))
+		for _, container := range source {
+			names[containerName(container)] = true
+		}
+		keep := func(container interface{}) bool {
+			name := containerName(container)
+			return name != "" && !names[name] && r.injected(name)
+		}
+
+		injected := false
+		for _, container := range current {
+			injected = injected || keep(container)
+		}
+		if !injected {
+			continue
+		}
+
+		merged := make([]interface{}, 0, len(current)+len(source))
+		next := 0
+		for _, container := range current {
+			switch {
+			case keep(container):
+				merged = append(merged, container)
+			case next < len(source):
+				merged = append(merged, source[next])
+				next++
+			}
+		}
+		specMap[field] = append(merged, source[next:]...)
+	}
+}
+
+// containerName returns the name of a container of an unstructured pod spec
+func containerName(container interface{}) string {
+	m, ok := container.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+	name, _ := m["name"].(string)
+	return name
+}
+
+// keepDefaultedFields copies the defaulted fields of the existing destination value that the written
+// value omits. Lists are walked item by item when they have the same length.
+func keepDefaultedFields(u, existing interface{}) {
+	switch u := u.(type) {
+	case map[string]interface{}:
+		e, ok := existing.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for key, value := range e {
+			child, found := u[key]
+			if !found {
+				if defaultedFields[key] {
+					u[key] = runtime.DeepCopyJSONValue(value)
+				}
+				continue
+			}
+			keepDefaultedFields(child, value)
+		}
+	case []interface{}:
+		e, ok := existing.([]interface{})
+		if !ok || len(e) != len(u) {
+			return
+		}
+		for i := range u {
+			keepDefaultedFields(u[i], e[i])
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package syncer
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	autoscalingv2 "k8s.io/api/autoscaling/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func ignoredDeployment(replicas int64, containers ...interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"metadata": map[string]interface{}{
+			"name":        "web",
+			"namespace":   "dr",
+			"annotations": map[string]interface{}{"kubectl.kubernetes.io/restartedAt": "2026-10-01T00:00:00Z"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": replicas,
+			"template": map[string]interface{}{"spec": map[string]interface{}{"containers": containers}},
+		},
+	}}
+}
+
+func container(name string, fields ...string) map[string]interface{} {
+	c := map[string]interface{}{"name": name, "image": name + ":1"}
+	for i := 0; i+1 < len(fields); i += 2 {
+		c[fields[i]] = fields[i+1]
+	}
+	return c
+}
+
+func TestCompileIgnoreFields(t *testing.T) {
+	rules, err := compileIgnoreFields(nil)
+	require.NoError(t, err)
+	assert.True(t, rules.builtIn)
+	assert.True(t, rules.injected("istio-proxy"))
+
+	disabled := false
+	rules, err = compileIgnoreFields(&drv1alpha1.IgnoreFieldsConfig{BuiltIn: &disabled, InjectedContainers: []string{"otel-.*"}})
+	require.NoError(t, err)
+	assert.False(t, rules.builtIn)
+	assert.True(t, rules.injected("otel-collector"))
+	assert.False(t, rules.injected("istio-proxy"))
+
+	_, err = compileIgnoreFields(&drv1alpha1.IgnoreFieldsConfig{Fields: []drv1alpha1.IgnoredFields{{Paths: []string{"spec"}}}})
+	assert.Error(t, err)
+}
+
+func TestWithoutIgnoredFields(t *testing.T) {
+	rules, err := compileIgnoreFields(&drv1alpha1.IgnoreFieldsConfig{Fields: []drv1alpha1.IgnoredFields{
+		{Paths: []string{`.metadata.annotations['kubectl.kubernetes.io/restartedAt']`}},
+		{Kind: "Deployment", Name: "web", Paths: []string{`.spec.template.spec.containers[?(@.name=="sidecar")]`}},
+	}})
+	require.NoError(t, err)
+	r := &ResourceSyncer{ignores: rules}
+
+	source := ignoredDeployment(2, container("app"))
+	source.SetAnnotations(nil)
+	existing := ignoredDeployment(2, container("app"), container("sidecar"))
+
+	assert.True(t, reflect.DeepEqual(r.withoutIgnoredFields(source, "Deployment", "web").Object,
+		r.withoutIgnoredFields(existing, "Deployment", "web").Object))
+	assert.Len(t, existing.Object["spec"].(map[string]interface{})["template"].(map[string]interface{})["spec"].(map[string]interface{})["containers"], 2,
+		"the compared copy is stripped, not the resource")
+
+	// Other names only ignore the annotation
+	assert.False(t, reflect.DeepEqual(r.withoutIgnoredFields(source, "Deployment", "api").Object,
+		r.withoutIgnoredFields(existing, "Deployment", "api").Object))
+}
+
+func TestKeepDestinationOwnedFields(t *testing.T) {
+	rules, err := compileIgnoreFields(nil)
+	require.NoError(t, err)
+	destClient := fake.NewSimpleClientset(&autoscalingv2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "dr"},
+		Spec: autoscalingv2.HorizontalPodAutoscalerSpec{
+			ScaleTargetRef: autoscalingv2.CrossVersionObjectReference{Kind: "Deployment", Name: "web"},
+		},
+	})
+	r := &ResourceSyncer{ignores: rules, destClient: destClient}
+
+	source := ignoredDeployment(2, container("app"))
+	existing := ignoredDeployment(5, container("istio-init"), container("app", "terminationMessagePath", "/dev/termination-log"),
+		container("istio-proxy"))
+	r.keepDestinationOwnedFields(context.Background(), source, existing)
+	assert.Equal(t, existing.Object, source.Object)
+
+	// Standby workloads scaled to zero are not kept at the autoscaled replicas
+	source = ignoredDeployment(0, container("app"))
+	r.keepDestinationOwnedFields(context.Background(), source, existing)
+	replicas, _, _ := unstructured.NestedInt64(source.Object, "spec", "replicas")
+	assert.Equal(t, int64(0), replicas)
+
+	// Workloads without an autoscaler get the source replicas
+	r.hpaTargets = map[string]map[string]bool{"dr": {}}
+	source = ignoredDeployment(2, container("app"))
+	r.keepDestinationOwnedFields(context.Background(), source, existing)
+	replicas, _, _ = unstructured.NestedInt64(source.Object, "spec", "replicas")
+	assert.Equal(t, int64(2), replicas)
+}
+
+func TestKeepDestinationOwnedFields_BuiltInDisabled(t *testing.T) {
+	disabled := false
+	rules, err := compileIgnoreFields(&drv1alpha1.IgnoreFieldsConfig{BuiltIn: &disabled})
+	require.NoError(t, err)
+	r := &ResourceSyncer{ignores: rules}
+
+	source := ignoredDeployment(2, container("app"))
+	r.keepDestinationOwnedFields(context.Background(), source, ignoredDeployment(2, container("app"), container("istio-proxy")))
+	assert.Equal(t, ignoredDeployment(2, container("app")).Object, source.Object)
+}
+
+func TestKeepInjectedContainers_SourceChanges(t *testing.T) {
+	rules, err := compileIgnoreFields(nil)
+	require.NoError(t, err)
+
+	source := ignoredDeployment(1, container("app"), container("worker"))
+	rules.keepInjectedContainers(source, ignoredDeployment(1, container("istio-proxy"), container("app")), podTemplateSpecPaths[0])
+	containers, _, _ := unstructured.NestedSlice(source.Object, "spec", "template", "spec", "containers")
+	assert.Equal(t, []interface{}{container("istio-proxy"), container("app"), container("worker")}, containers)
+}
@@ -0,0 +1,148 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+// podTemplatePaths locates the pod spec of workload resources synced through the dynamic client
+var podTemplatePaths = map[schema.GroupResource][]string{
+	{Group: "apps", Resource: "deployments"}:  {"spec", "template", "spec"},
+	{Group: "apps", Resource: "statefulsets"}: {"spec", "template", "spec"},
+	{Group: "apps", Resource: "daemonsets"}:   {"spec", "template", "spec"},
+	{Group: "batch", Resource: "jobs"}:        {"spec", "template", "spec"},
+	{Group: "batch", Resource: "cronjobs"}:    {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// rewriteImage applies the first override whose prefix matches image, returning image unchanged when none does
+func rewriteImage(image string, overrides []drv1alpha1.ImageOverride) string {
+	for _, override := range overrides {
+		from := strings.TrimSuffix(override.From, "/")
+		if from == "" || !strings.HasPrefix(image, from) {
+			continue
+		}
+		// Only match whole path segments, followed by a path, tag or digest
+		rest := image[len(from):]
+		if rest != "" && !strings.ContainsAny(rest[:1], "/:@") {
+			continue
+		}
+		return strings.TrimSuffix(override.To, "/") + rest
+	}
+	return image
+}
+
+// recordPullSecret marks an image pull secret referenced by a synced workload
+func (r *ResourceSyncer) recordPullSecret(name string) {
+	if name == "" {
+		return
+	}
+	if r.pullSecrets == nil {
+		r.pullSecrets = make(map[string]bool)
+	}
+	r.pullSecrets[name] = true
+}
+
+// rewritePodSpecImages applies the image overrides to a workload pod template and records its image pull secrets
+func (r *ResourceSyncer) rewritePodSpecImages(spec *corev1.PodSpec) {
+	for i := range spec.InitContainers {
+		spec.InitContainers[i].Image = rewriteImage(spec.InitContainers[i].Image, r.imageOverrides)
+	}
+	for i := range spec.Containers {
+		spec.Containers[i].Image = rewriteImage(spec.Containers[i].Image, r.imageOverrides)
+	}
+	for i := range spec.EphemeralContainers {
+		spec.EphemeralContainers[i].Image = rewriteImage(spec.EphemeralContainers[i].Image, r.imageOverrides)
+	}
+	for _, secret := range spec.ImagePullSecrets {
+		r.recordPullSecret(secret.Name)
+	}
+}
+
+// rewriteUnstructuredImages is rewritePodSpecImages for workloads synced through the dynamic client
+func (r *ResourceSyncer) rewriteUnstructuredImages(gvr schema.GroupVersionResource, item *unstructured.Unstructured) {
+	path, ok := podTemplatePaths[gvr.GroupResource()]
+	if !ok {
+		return
+	}
+	spec, found, err := unstructured.NestedMap(item.Object, path...)
+	if err != nil || !found {
+		return
+	}
+
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		containers, _ := spec[field].([]interface{})
+		for _, c := range containers {
+			container, ok := c.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if image, ok := container["image"].(string); ok {
+				container["image"] = rewriteImage(image, r.imageOverrides)
+			}
+		}
+	}
+	pullSecrets, _ := spec["imagePullSecrets"].([]interface{})
+	for _, s := range pullSecrets {
+		if secret, ok := s.(map[string]interface{}); ok {
+			name, _ := secret["name"].(string)
+			r.recordPullSecret(name)
+		}
+	}
+
+	if err := unstructured.SetNestedMap(item.Object, spec, path...); err != nil {
+		log.Errorf("failed to rewrite images of %s/%s: %v", gvr.Resource, item.GetName(), err)
+	}
+}
+
+// syncPullSecrets syncs the image pull secrets recorded while syncing workloads. It is used when
+// secrets are not synced as a resource type, so workloads can pull their images in the destination.
+func syncPullSecrets(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
+	if len(syncer.pullSecrets) == 0 {
+		return nil
+	}
+
+	names := make([]string, 0, len(syncer.pullSecrets))
+	for name := range syncer.pullSecrets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	log.Info(fmt.Sprintf("syncing %d image pull secrets from %s to %s", len(names), srcNamespace, dstNamespace))
+
+	for _, name := range names {
+		if !syncer.selected("Secret", name) {
+			continue
+		}
+		secret, err := sourceClient.CoreV1().Secrets(srcNamespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				log.Info(fmt.Sprintf("image pull secret %s not found in %s, skipping", name, srcNamespace))
+				continue
+			}
+			syncer.recordResult("Secret", name, syncerrors.NewRetryableError(
+				fmt.Errorf("failed to get image pull secret: %w", err),
+				fmt.Sprintf("Secret/%s", name),
+			))
+			continue
+		}
+		if utils.ShouldIgnoreResource(secret) {
+			continue
+		}
+
+		secret.Namespace = dstNamespace
+		log.Info(fmt.Sprintf("syncing image pull secret %s from %s to %s", name, srcNamespace, dstNamespace))
+		syncer.recordResult("Secret", name, syncer.SyncResource(ctx, secret, config))
+	}
+	return nil
+}
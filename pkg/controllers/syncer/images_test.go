@@ -0,0 +1,120 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestRewriteImage(t *testing.T) {
+	overrides := []drv1alpha1.ImageOverride{
+		{From: "registry.prod.local/team", To: "registry.dr.local/team-mirror"},
+		{From: "registry.prod.local/", To: "registry.dr.local"},
+		{From: "docker.io", To: "mirror.dr.local/docker.io"},
+	}
+
+	tests := map[string]string{
+		"registry.prod.local/app:1.0":             "registry.dr.local/app:1.0",
+		"registry.prod.local/team/api:2.1":        "registry.dr.local/team-mirror/api:2.1",
+		"registry.prod.local/teamx/api:2.1":       "registry.dr.local/teamx/api:2.1",
+		"registry.prod.local/app@sha256:abc":      "registry.dr.local/app@sha256:abc",
+		"registry.prod.local:5000/app:1.0":        "registry.dr.local:5000/app:1.0",
+		"registry.prod.localhost/app:1.0":         "registry.prod.localhost/app:1.0",
+		"docker.io/library/nginx:1.25":            "mirror.dr.local/docker.io/library/nginx:1.25",
+		"nginx:1.25":                              "nginx:1.25",
+		"quay.io/prometheus/node-exporter:v1.7.0": "quay.io/prometheus/node-exporter:v1.7.0",
+	}
+	for image, expected := range tests {
+		assert.Equal(t, expected, rewriteImage(image, overrides), image)
+	}
+
+	assert.Equal(t, "registry.prod.local/app:1.0", rewriteImage("registry.prod.local/app:1.0", nil))
+}
+
+func TestRewritePodSpecImages(t *testing.T) {
+	syncer := &ResourceSyncer{imageOverrides: []drv1alpha1.ImageOverride{{From: "registry.prod.local", To: "registry.dr.local"}}}
+
+	spec := &corev1.PodSpec{
+		InitContainers:   []corev1.Container{{Name: "migrate", Image: "registry.prod.local/migrate:1"}},
+		Containers:       []corev1.Container{{Name: "app", Image: "registry.prod.local/app:1"}, {Name: "proxy", Image: "envoyproxy/envoy:v1.29"}},
+		ImagePullSecrets: []corev1.LocalObjectReference{{Name: "prod-registry"}},
+	}
+	syncer.rewritePodSpecImages(spec)
+
+	assert.Equal(t, "registry.dr.local/migrate:1", spec.InitContainers[0].Image)
+	assert.Equal(t, "registry.dr.local/app:1", spec.Containers[0].Image)
+	assert.Equal(t, "envoyproxy/envoy:v1.29", spec.Containers[1].Image)
+	assert.Equal(t, map[string]bool{"prod-registry": true}, syncer.pullSecrets)
+}
+
+func TestRewriteUnstructuredImages(t *testing.T) {
+	syncer := &ResourceSyncer{imageOverrides: []drv1alpha1.ImageOverride{{From: "registry.prod.local", To: "registry.dr.local"}}}
+
+	statefulSet := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "StatefulSet",
+		"metadata":   map[string]interface{}{"name": "db"},
+		"spec": map[string]interface{}{
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers":       []interface{}{map[string]interface{}{"name": "db", "image": "registry.prod.local/postgres:16"}},
+					"imagePullSecrets": []interface{}{map[string]interface{}{"name": "prod-registry"}},
+				},
+			},
+		},
+	}}
+	syncer.rewriteUnstructuredImages(appsv1.SchemeGroupVersion.WithResource("statefulsets"), statefulSet)
+
+	containers, _, err := unstructured.NestedSlice(statefulSet.Object, "spec", "template", "spec", "containers")
+	require.NoError(t, err)
+	assert.Equal(t, "registry.dr.local/postgres:16", containers[0].(map[string]interface{})["image"])
+	assert.True(t, syncer.pullSecrets["prod-registry"])
+
+	// Resources without a pod template are left alone
+	configMap := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings"},
+		"data":       map[string]interface{}{"image": "registry.prod.local/app:1"},
+	}}
+	syncer.rewriteUnstructuredImages(corev1.SchemeGroupVersion.WithResource("configmaps"), configMap)
+	assert.Equal(t, "registry.prod.local/app:1", configMap.Object["data"].(map[string]interface{})["image"])
+}
+
+func TestSyncPullSecrets(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	sourceClient := fake.NewSimpleClientset(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod-registry", Namespace: "app"},
+			Type:       corev1.SecretTypeDockerConfigJson,
+			Data:       map[string][]byte{corev1.DockerConfigJsonKey: []byte(`{"auths":{}}`)},
+		},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unreferenced", Namespace: "app"}},
+	)
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+
+	syncer := NewResourceSyncer(nil, nil, destDynamic, sourceClient, nil, scheme)
+	syncer.recordPullSecret("prod-registry")
+	syncer.recordPullSecret("missing")
+
+	require.NoError(t, syncPullSecrets(ctx, syncer, sourceClient, "app", "app-dr", nil))
+
+	secrets := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).Namespace("app-dr")
+	list, err := secrets.List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "prod-registry", list.Items[0].GetName())
+}
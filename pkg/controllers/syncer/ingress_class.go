@@ -0,0 +1,39 @@
+package syncer
+
+import (
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+)
+
+// legacyIngressClassAnnotation selects the ingress class of Ingresses predating spec.ingressClassName
+const legacyIngressClassAnnotation = "kubernetes.io/ingress.class"
+
+// mapIngressClass returns the destination ingress class of class, class itself when no mapping matches
+func mapIngressClass(class string, mappings []drv1alpha1.IngressClassMapping) string {
+	for _, mapping := range mappings {
+		if mapping.From == class {
+			return mapping.To
+		}
+	}
+	return class
+}
+
+// mapIngressClass applies the ingress class mappings to the class name and legacy class annotation of an Ingress
+func (r *ResourceSyncer) mapIngressClass(ing *networkingv1.Ingress) {
+	if len(r.ingressClassMappings) == 0 {
+		return
+	}
+
+	if ing.Spec.IngressClassName != nil {
+		class := mapIngressClass(*ing.Spec.IngressClassName, r.ingressClassMappings)
+		if class != *ing.Spec.IngressClassName {
+			log.Info(fmt.Sprintf("mapping ingress class of ingress %s from %s to %s", ing.Name, *ing.Spec.IngressClassName, class))
+		}
+		ing.Spec.IngressClassName = &class
+	}
+	if class, ok := ing.Annotations[legacyIngressClassAnnotation]; ok {
+		ing.Annotations[legacyIngressClassAnnotation] = mapIngressClass(class, r.ingressClassMappings)
+	}
+}
@@ -0,0 +1,33 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMapIngressClass(t *testing.T) {
+	r := &ResourceSyncer{ingressClassMappings: []drv1alpha1.IngressClassMapping{
+		{From: "nginx", To: "nginx-dr"},
+		{From: "traefik", To: "haproxy"},
+	}}
+
+	nginx := "nginx"
+	ing := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Annotations: map[string]string{legacyIngressClassAnnotation: "traefik"}},
+		Spec:       networkingv1.IngressSpec{IngressClassName: &nginx},
+	}
+	r.mapIngressClass(ing)
+	assert.Equal(t, "nginx-dr", *ing.Spec.IngressClassName)
+	assert.Equal(t, "haproxy", ing.Annotations[legacyIngressClassAnnotation])
+	assert.Equal(t, "nginx", nginx, "the source class name is not modified")
+
+	// Classes without a mapping are kept
+	istio := "istio"
+	ing = &networkingv1.Ingress{Spec: networkingv1.IngressSpec{IngressClassName: &istio}}
+	r.mapIngressClass(ing)
+	assert.Equal(t, "istio", *ing.Spec.IngressClassName)
+}
@@ -0,0 +1,166 @@
+package syncer
+
+import (
+	"fmt"
+	"reflect"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// injectRule is an inject rule of a mapping with its selector parsed
+type injectRule struct {
+	kinds    map[string]bool
+	selector labels.Selector
+	rule     drv1alpha1.InjectRule
+}
+
+// compileInjectRules parses the selectors of the inject rules of a mapping
+func compileInjectRules(rules []drv1alpha1.InjectRule) ([]injectRule, error) {
+	compiled := make([]injectRule, 0, len(rules))
+	for i, rule := range rules {
+		c := injectRule{rule: rule}
+		if len(rule.Kinds) > 0 {
+			c.kinds = make(map[string]bool, len(rule.Kinds))
+			for _, kind := range rule.Kinds {
+				c.kinds[string(kind)] = true
+			}
+		}
+		if rule.Selector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(rule.Selector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid selector in inject rule %d: %w", i, err)
+			}
+			c.selector = selector
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// matches reports whether the rule applies to a workload of kind with the given labels
+func (r injectRule) matches(kind string, workloadLabels map[string]string) bool {
+	if r.kinds != nil && !r.kinds[kind] {
+		return false
+	}
+	return r.selector == nil || r.selector.Matches(labels.Set(workloadLabels))
+}
+
+// injectDefaults applies the inject rules matching a workload to its destination copy. Resources
+// without a pod template are left unchanged.
+func (r *ResourceSyncer) injectDefaults(gvr schema.GroupVersionResource, item *unstructured.Unstructured) {
+	path, ok := podTemplatePaths[gvr.GroupResource()]
+	if !ok || len(r.injectRules) == 0 {
+		return
+	}
+	// Rules select workloads by the labels they have before any rule adds its own
+	workloadLabels := item.GetLabels()
+	for _, rule := range r.injectRules {
+		if !rule.matches(item.GetKind(), workloadLabels) {
+			continue
+		}
+		if err := injectRuleInto(item.Object, path, rule.rule); err != nil {
+			log.Errorf("failed to inject defaults into %s/%s: %v", gvr.Resource, item.GetName(), err)
+			return
+		}
+	}
+}
+
+// injectRuleInto applies an inject rule to a workload object whose pod spec is found at specPath
+func injectRuleInto(obj map[string]interface{}, specPath []string, rule drv1alpha1.InjectRule) error {
+	templatePath := append(append([]string{}, specPath[:len(specPath)-1]...), "metadata")
+	for _, metadataPath := range [][]string{{"metadata"}, templatePath} {
+		if err := mergeStringMap(obj, rule.Labels, append(metadataPath, "labels")...); err != nil {
+			return err
+		}
+		if err := mergeStringMap(obj, rule.Annotations, append(metadataPath, "annotations")...); err != nil {
+			return err
+		}
+	}
+	if err := mergeStringMap(obj, rule.NodeSelector, append(specPath, "nodeSelector")...); err != nil {
+		return err
+	}
+
+	spec, found, err := unstructured.NestedMap(obj, specPath...)
+	if err != nil || !found {
+		return err
+	}
+	if len(rule.Env) > 0 {
+		env := make([]map[string]interface{}, 0, len(rule.Env))
+		for i := range rule.Env {
+			value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&rule.Env[i])
+			if err != nil {
+				return fmt.Errorf("failed to convert environment variable %s: %w", rule.Env[i].Name, err)
+			}
+			env = append(env, value)
+		}
+		for _, field := range []string{"initContainers", "containers"} {
+			containers, _ := spec[field].([]interface{})
+			for _, c := range containers {
+				if container, ok := c.(map[string]interface{}); ok {
+					setEnv(container, env)
+				}
+			}
+		}
+	}
+	for i := range rule.Tolerations {
+		toleration, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&rule.Tolerations[i])
+		if err != nil {
+			return fmt.Errorf("failed to convert toleration %s: %w", rule.Tolerations[i].Key, err)
+		}
+		spec["tolerations"] = appendMissing(spec["tolerations"], toleration)
+	}
+	return unstructured.SetNestedMap(obj, spec, specPath...)
+}
+
+// mergeStringMap sets the entries of values in the string map at fields, replacing existing keys
+func mergeStringMap(obj map[string]interface{}, values map[string]string, fields ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	merged, _, err := unstructured.NestedStringMap(obj, fields...)
+	if err != nil {
+		return err
+	}
+	if merged == nil {
+		merged = make(map[string]string, len(values))
+	}
+	for key, value := range values {
+		merged[key] = value
+	}
+	return unstructured.SetNestedStringMap(obj, merged, fields...)
+}
+
+// setEnv sets environment variables in a container, replacing variables of the same name in place
+func setEnv(container map[string]interface{}, env []map[string]interface{}) {
+	existing, _ := container["env"].([]interface{})
+	for _, variable := range env {
+		replaced := false
+		for i, e := range existing {
+			if current, ok := e.(map[string]interface{}); ok && current["name"] == variable["name"] {
+				existing[i] = runtime.DeepCopyJSON(variable)
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			existing = append(existing, runtime.DeepCopyJSON(variable))
+		}
+	}
+	container["env"] = existing
+}
+
+// appendMissing appends item to a list unless an equal item is already in it
+func appendMissing(list interface{}, item map[string]interface{}) []interface{} {
+	items, _ := list.([]interface{})
+	for _, existing := range items {
+		if reflect.DeepEqual(existing, item) {
+			return items
+		}
+	}
+	return append(items, item)
+}
@@ -0,0 +1,127 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func injectTestRules(t *testing.T) []injectRule {
+	rules, err := compileInjectRules([]drv1alpha1.InjectRule{
+		{
+			Labels:      map[string]string{"environment": "dr"},
+			Annotations: map[string]string{"ad.datadoghq.com/tags": `{"env":"dr"}`},
+			Env: []drv1alpha1.EnvVar{
+				{Name: "ENVIRONMENT", Value: "dr"},
+				{Name: "NODE", ValueFrom: &drv1alpha1.EnvVarSource{FieldRef: &drv1alpha1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+			},
+		},
+		{
+			Kinds:        []drv1alpha1.InjectKind{"Deployment"},
+			Selector:     &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "web"}},
+			NodeSelector: map[string]string{"node-pool": "dr"},
+			Tolerations:  []corev1.Toleration{{Key: "dr-only", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+		},
+	})
+	require.NoError(t, err)
+	return rules
+}
+
+func toUnstructured(t *testing.T, obj runtime.Object, gvk string) *unstructured.Unstructured {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	require.NoError(t, err)
+	u := &unstructured.Unstructured{Object: content}
+	u.SetKind(gvk)
+	return u
+}
+
+func TestInjectDefaults_Deployment(t *testing.T) {
+	syncer := &ResourceSyncer{injectRules: injectTestRules(t)}
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Labels: map[string]string{"tier": "web", "environment": "prod"}},
+		Spec: appsv1.DeploymentSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+				Spec: corev1.PodSpec{
+					InitContainers: []corev1.Container{{Name: "migrate"}},
+					Containers: []corev1.Container{{Name: "app", Env: []corev1.EnvVar{
+						{Name: "ENVIRONMENT", Value: "prod"},
+						{Name: "LOG_LEVEL", Value: "info"},
+					}}},
+					NodeSelector: map[string]string{"kubernetes.io/os": "linux"},
+					Tolerations:  []corev1.Toleration{{Key: "dr-only", Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule}},
+				},
+			},
+		},
+	}
+	u := toUnstructured(t, deploy, "Deployment")
+	syncer.injectDefaults(appsv1.SchemeGroupVersion.WithResource("deployments"), u)
+
+	injected := &appsv1.Deployment{}
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, injected))
+	assert.Equal(t, map[string]string{"tier": "web", "environment": "dr"}, injected.Labels)
+	assert.Equal(t, map[string]string{"app": "web", "environment": "dr"}, injected.Spec.Template.Labels)
+	assert.Equal(t, map[string]string{"app": "web"}, injected.Spec.Selector.MatchLabels, "selectors are not changed")
+	assert.Equal(t, `{"env":"dr"}`, injected.Spec.Template.Annotations["ad.datadoghq.com/tags"])
+
+	assert.Equal(t, []corev1.EnvVar{
+		{Name: "ENVIRONMENT", Value: "dr"},
+		{Name: "LOG_LEVEL", Value: "info"},
+		{Name: "NODE", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+	}, injected.Spec.Template.Spec.Containers[0].Env)
+	assert.Len(t, injected.Spec.Template.Spec.InitContainers[0].Env, 2)
+
+	assert.Equal(t, map[string]string{"kubernetes.io/os": "linux", "node-pool": "dr"}, injected.Spec.Template.Spec.NodeSelector)
+	assert.Len(t, injected.Spec.Template.Spec.Tolerations, 1, "tolerations already present are not added again")
+}
+
+func TestInjectDefaults_Selection(t *testing.T) {
+	syncer := &ResourceSyncer{injectRules: injectTestRules(t)}
+
+	// The second rule only applies to Deployments, the first to every workload kind
+	cronJob := &batchv1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{Name: "report", Labels: map[string]string{"tier": "web"}},
+		Spec: batchv1.CronJobSpec{JobTemplate: batchv1.JobTemplateSpec{Spec: batchv1.JobSpec{Template: corev1.PodTemplateSpec{
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "report"}}},
+		}}}},
+	}
+	u := toUnstructured(t, cronJob, "CronJob")
+	syncer.injectDefaults(batchv1.SchemeGroupVersion.WithResource("cronjobs"), u)
+
+	injected := &batchv1.CronJob{}
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, injected))
+	podSpec := injected.Spec.JobTemplate.Spec.Template.Spec
+	assert.Equal(t, "dr", injected.Spec.JobTemplate.Spec.Template.Labels["environment"])
+	assert.Equal(t, "ENVIRONMENT", podSpec.Containers[0].Env[0].Name)
+	assert.Empty(t, podSpec.NodeSelector)
+	assert.Empty(t, podSpec.Tolerations)
+
+	// Deployments not matching the selector only get the first rule
+	deploy := toUnstructured(t, &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "api", Labels: map[string]string{"tier": "backend"}}}, "Deployment")
+	syncer.injectDefaults(appsv1.SchemeGroupVersion.WithResource("deployments"), deploy)
+	_, found, err := unstructured.NestedStringMap(deploy.Object, "spec", "template", "spec", "nodeSelector")
+	require.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, "dr", deploy.GetLabels()["environment"])
+
+	// Resources without a pod template are left alone
+	configMap := toUnstructured(t, &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings"}}, "ConfigMap")
+	syncer.injectDefaults(corev1.SchemeGroupVersion.WithResource("configmaps"), configMap)
+	assert.Empty(t, configMap.GetLabels())
+}
+
+func TestCompileInjectRules_InvalidSelector(t *testing.T) {
+	_, err := compileInjectRules([]drv1alpha1.InjectRule{{Selector: &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Near"}},
+	}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,148 @@
+package syncer
+
+import (
+	"fmt"
+	"regexp"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// keyFilterFields are the fields holding the keys of ConfigMaps and Secrets
+var keyFilterFields = map[string][]string{
+	"ConfigMap": {"data", "binaryData"},
+	"Secret":    {"data", "stringData"},
+}
+
+// keyFilter is a compiled drv1alpha1.KeyFilter
+type keyFilter struct {
+	kind    string
+	name    *regexp.Regexp
+	include []*regexp.Regexp
+	exclude []*regexp.Regexp
+}
+
+// compilePattern compiles a pattern matched against the whole string
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid key filter pattern %q: %v", pattern, err)
+	}
+	return re, nil
+}
+
+// compilePatterns compiles a list of patterns matched against the whole string
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	var compiled []*regexp.Regexp
+	for _, pattern := range patterns {
+		re, err := compilePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// compileKeyFilters compiles the key filters of a NamespaceMapping
+func compileKeyFilters(filters []drv1alpha1.KeyFilter) ([]keyFilter, error) {
+	var compiled []keyFilter
+	for _, filter := range filters {
+		if _, ok := keyFilterFields[filter.Kind]; !ok {
+			return nil, fmt.Errorf("key filters do not apply to kind %q", filter.Kind)
+		}
+		f := keyFilter{kind: filter.Kind}
+		var err error
+		if filter.Name != "" {
+			if f.name, err = compilePattern(filter.Name); err != nil {
+				return nil, err
+			}
+		}
+		if f.include, err = compilePatterns(filter.Include); err != nil {
+			return nil, err
+		}
+		if f.exclude, err = compilePatterns(filter.Exclude); err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, f)
+	}
+	return compiled, nil
+}
+
+// matchesAny reports whether s matches one of the patterns
+func matchesAny(patterns []*regexp.Regexp, s string) bool {
+	for _, re := range patterns {
+		if re.MatchString(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// replicated reports whether the filter replicates a key
+func (f *keyFilter) replicated(key string) bool {
+	if len(f.include) > 0 && !matchesAny(f.include, key) {
+		return false
+	}
+	return !matchesAny(f.exclude, key)
+}
+
+// keyFilterFor returns the first key filter matching a resource, nil when its keys are replicated whole
+func (r *ResourceSyncer) keyFilterFor(kind, name string) *keyFilter {
+	for i := range r.keyFilters {
+		f := &r.keyFilters[i]
+		if f.kind == kind && (f.name == nil || f.name.MatchString(name)) {
+			return f
+		}
+	}
+	return nil
+}
+
+// filterKeys removes the keys a key filter does not replicate from a ConfigMap or Secret
+func (r *ResourceSyncer) filterKeys(u *unstructured.Unstructured) {
+	f := r.keyFilterFor(u.GetKind(), u.GetName())
+	if f == nil {
+		return
+	}
+	for _, field := range keyFilterFields[u.GetKind()] {
+		data, found, _ := unstructured.NestedMap(u.Object, field)
+		if !found {
+			continue
+		}
+		for key := range data {
+			if !f.replicated(key) {
+				delete(data, key)
+			}
+		}
+		_ = unstructured.SetNestedMap(u.Object, data, field)
+	}
+}
+
+// keepLocalKeys copies the keys a key filter does not replicate from the destination resource, so
+// values set in the destination cluster survive updates. Filters match the source name of the resource.
+func (r *ResourceSyncer) keepLocalKeys(u, existing *unstructured.Unstructured, sourceName string) {
+	f := r.keyFilterFor(u.GetKind(), sourceName)
+	if f == nil {
+		return
+	}
+	for _, field := range keyFilterFields[u.GetKind()] {
+		local, found, _ := unstructured.NestedMap(existing.Object, field)
+		if !found {
+			continue
+		}
+		data, _, _ := unstructured.NestedMap(u.Object, field)
+		if data == nil {
+			data = make(map[string]interface{})
+		}
+		kept := false
+		for key, value := range local {
+			if !f.replicated(key) {
+				data[key] = value
+				kept = true
+			}
+		}
+		if kept {
+			_ = unstructured.SetNestedMap(u.Object, data, field)
+		}
+	}
+}
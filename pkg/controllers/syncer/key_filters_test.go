@@ -0,0 +1,95 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestCompileKeyFilters(t *testing.T) {
+	_, err := compileKeyFilters([]drv1alpha1.KeyFilter{{Kind: "Secret", Exclude: []string{"aws_("}}})
+	assert.ErrorContains(t, err, `invalid key filter pattern "aws_("`)
+
+	_, err = compileKeyFilters([]drv1alpha1.KeyFilter{{Kind: "Deployment"}})
+	assert.Error(t, err)
+
+	filters, err := compileKeyFilters([]drv1alpha1.KeyFilter{
+		{Kind: "Secret", Name: "app-.*", Include: []string{"db_.*", "api_key"}, Exclude: []string{"db_admin_.*"}},
+		{Kind: "Secret", Exclude: []string{"aws_.*"}},
+	})
+	require.NoError(t, err)
+	r := &ResourceSyncer{keyFilters: filters}
+
+	// The first filter matching the resource applies
+	app := r.keyFilterFor("Secret", "app-config")
+	require.NotNil(t, app)
+	assert.True(t, app.replicated("db_password"))
+	assert.True(t, app.replicated("api_key"))
+	assert.False(t, app.replicated("api_key_old"))
+	assert.False(t, app.replicated("db_admin_password"))
+	assert.False(t, app.replicated("aws_access_key_id"))
+
+	other := r.keyFilterFor("Secret", "backup-credentials")
+	require.NotNil(t, other)
+	assert.True(t, other.replicated("password"))
+	assert.False(t, other.replicated("aws_secret_access_key"))
+
+	assert.Nil(t, r.keyFilterFor("ConfigMap", "app-config"))
+}
+
+func TestSyncResource_KeyFilters(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+
+	filters, err := compileKeyFilters([]drv1alpha1.KeyFilter{
+		{Kind: "Secret", Name: "app", Exclude: []string{"aws_.*"}},
+		{Kind: "ConfigMap", Include: []string{"settings.yaml"}},
+	})
+	require.NoError(t, err)
+	syncer := NewResourceSyncer(nil, nil, destDynamic, nil, nil, scheme)
+	syncer.keyFilters = filters
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "app-dr"},
+		Data: map[string][]byte{
+			"password":          []byte("prod"),
+			"aws_access_key_id": []byte("AKIAPROD"),
+		},
+	}
+	require.NoError(t, syncer.SyncResource(ctx, secret.DeepCopy(), nil))
+
+	secrets := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).Namespace("app-dr")
+	synced, err := secrets.Get(ctx, "app", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"password": "cHJvZA=="}, synced.Object["data"])
+
+	// Excluded keys set in the destination cluster survive updates
+	data := synced.Object["data"].(map[string]interface{})
+	data["aws_access_key_id"] = "QUtJQURS"
+	_, err = secrets.Update(ctx, synced, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	secret.Data["password"] = []byte("rotated")
+	require.NoError(t, syncer.SyncResource(ctx, secret.DeepCopy(), nil))
+	synced, err = secrets.Get(ctx, "app", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"password": "cm90YXRlZA==", "aws_access_key_id": "QUtJQURS"}, synced.Object["data"])
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "app", Namespace: "app-dr"},
+		Data:       map[string]string{"settings.yaml": "replicas: 3", "cluster.yaml": "region: us-east-1"},
+	}
+	require.NoError(t, syncer.SyncResource(ctx, configMap, nil))
+	synced, err = destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("app-dr").Get(ctx, "app", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"settings.yaml": "replicas: 3"}, synced.Object["data"])
+}
@@ -0,0 +1,165 @@
+package syncer
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+// ErrQuotaExceeded is wrapped by the error of a sync whose source namespace exceeds the mapping's limits
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// limitListPageSize is the number of objects requested per List call while counting source objects
+const limitListPageSize = 500
+
+// limitedResources returns the resources of the resource types with a dedicated sync function and the
+// discovered resources, in the order they are synced
+func limitedResources(resourceTypes []string, discovered []schema.GroupVersionResource) []schema.GroupVersionResource {
+	byName := make(map[string]schema.GroupResource, len(typedResourceTypes))
+	for gr, name := range typedResourceTypes {
+		byName[name] = gr
+	}
+
+	seen := make(map[schema.GroupVersionResource]bool)
+	var gvrs []schema.GroupVersionResource
+	for _, resourceType := range resourceTypes {
+		name := strings.ToLower(resourceType)
+		if alias, ok := utils.ResourceTypeAliases[name]; ok {
+			name = alias
+		}
+		gr, ok := byName[name]
+		if !ok {
+			continue
+		}
+		gvr := gr.WithVersion("v1")
+		if !seen[gvr] {
+			seen[gvr] = true
+			gvrs = append(gvrs, gvr)
+		}
+	}
+	for _, gvr := range discovered {
+		if !seen[gvr] {
+			seen[gvr] = true
+			gvrs = append(gvrs, gvr)
+		}
+	}
+	return gvrs
+}
+
+// checkSyncLimits checks the source objects of resources in namespace against limits before they are
+// synced. Only objects matching selector count, the exceeded limits are returned as an error wrapping
+// ErrQuotaExceeded.
+func checkSyncLimits(ctx context.Context, sourceDynamic dynamic.Interface, namespace string, resources []schema.GroupVersionResource, limits *drv1alpha1.SyncLimits, selector labels.Selector) error {
+	if limits == nil || (limits.MaxObjectsPerKind == nil && limits.MaxTotalPVCSizeGi == nil && limits.MaxSecretSizeKi == nil) {
+		return nil
+	}
+
+	var exceeded []string
+	for _, gvr := range resources {
+		isPVC := gvr.GroupResource() == schema.GroupResource{Resource: "persistentvolumeclaims"}
+		isSecret := gvr.GroupResource() == schema.GroupResource{Resource: "secrets"}
+		if limits.MaxObjectsPerKind == nil && !(isPVC && limits.MaxTotalPVCSizeGi != nil) && !(isSecret && limits.MaxSecretSizeKi != nil) {
+			continue
+		}
+
+		count := 0
+		var totalPVCSize resource.Quantity
+		var largeSecrets []string
+		err := eachLimitedObject(ctx, sourceDynamic.Resource(gvr).Namespace(namespace), selector, func(u *unstructured.Unstructured) {
+			count++
+			switch {
+			case isPVC:
+				if size, ok := pvcRequestedStorage(u); ok {
+					totalPVCSize.Add(size)
+				}
+			case isSecret && limits.MaxSecretSizeKi != nil:
+				if size := secretDataSize(u); size > *limits.MaxSecretSizeKi*1024 {
+					largeSecrets = append(largeSecrets, fmt.Sprintf("%s (%dKi)", u.GetName(), (size+1023)/1024))
+				}
+			}
+		})
+		if err != nil {
+			return fmt.Errorf("failed to check limits of %s: %w", gvr.Resource, err)
+		}
+
+		if limits.MaxObjectsPerKind != nil && count > int(*limits.MaxObjectsPerKind) {
+			exceeded = append(exceeded, fmt.Sprintf("%d %s exceed maxObjectsPerKind %d", count, gvr.Resource, *limits.MaxObjectsPerKind))
+		}
+		if isPVC && limits.MaxTotalPVCSizeGi != nil {
+			limit := resource.NewQuantity(*limits.MaxTotalPVCSizeGi<<30, resource.BinarySI)
+			if totalPVCSize.Cmp(*limit) > 0 {
+				exceeded = append(exceeded, fmt.Sprintf("PVCs request %s in total, exceeding maxTotalPVCSizeGi %d", totalPVCSize.String(), *limits.MaxTotalPVCSizeGi))
+			}
+		}
+		if len(largeSecrets) > 0 {
+			sort.Strings(largeSecrets)
+			exceeded = append(exceeded, fmt.Sprintf("Secrets %s exceed maxSecretSizeKi %d", strings.Join(largeSecrets, ", "), *limits.MaxSecretSizeKi))
+		}
+	}
+
+	if len(exceeded) > 0 {
+		return fmt.Errorf("%w in source namespace %s: %s", ErrQuotaExceeded, namespace, strings.Join(exceeded, "; "))
+	}
+	return nil
+}
+
+// eachLimitedObject calls fn with every object of client matching selector, listed a page at a time
+func eachLimitedObject(ctx context.Context, client dynamic.ResourceInterface, selector labels.Selector, fn func(*unstructured.Unstructured)) error {
+	opts := metav1.ListOptions{Limit: limitListPageSize}
+	if selector != nil && !selector.Empty() {
+		opts.LabelSelector = selector.String()
+	}
+	for {
+		list, err := client.List(ctx, opts)
+		if err != nil {
+			return err
+		}
+		for i := range list.Items {
+			fn(&list.Items[i])
+		}
+		if list.GetContinue() == "" {
+			return nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+// pvcRequestedStorage returns the storage requested by a PVC
+func pvcRequestedStorage(u *unstructured.Unstructured) (resource.Quantity, bool) {
+	value, found, _ := unstructured.NestedString(u.Object, "spec", "resources", "requests", "storage")
+	if !found {
+		return resource.Quantity{}, false
+	}
+	size, err := resource.ParseQuantity(value)
+	if err != nil {
+		return resource.Quantity{}, false
+	}
+	return size, true
+}
+
+// secretDataSize returns the number of bytes of the decoded data of a Secret
+func secretDataSize(u *unstructured.Unstructured) int64 {
+	data, _, _ := unstructured.NestedStringMap(u.Object, "data")
+	var size int64
+	for _, value := range data {
+		decoded, err := base64.StdEncoding.DecodeString(value)
+		if err != nil {
+			size += int64(len(value))
+			continue
+		}
+		size += int64(len(decoded))
+	}
+	return size
+}
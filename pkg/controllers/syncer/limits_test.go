@@ -0,0 +1,92 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/utils/ptr"
+)
+
+func limitTestPVC(name, size string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func limitTestClient(t *testing.T, objects ...runtime.Object) *dynamicfake.FakeDynamicClient {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	return dynamicfake.NewSimpleDynamicClient(scheme, objects...)
+}
+
+func TestLimitedResources(t *testing.T) {
+	discovered := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	gvrs := limitedResources([]string{"ConfigMap", "pvc", "persistentvolumeclaims", "unknown"}, []schema.GroupVersionResource{discovered})
+	assert.Equal(t, []schema.GroupVersionResource{
+		{Version: "v1", Resource: "configmaps"},
+		{Version: "v1", Resource: "persistentvolumeclaims"},
+		discovered,
+	}, gvrs)
+}
+
+func TestCheckSyncLimits(t *testing.T) {
+	ctx := context.Background()
+	configMaps := corev1.SchemeGroupVersion.WithResource("configmaps")
+	secrets := corev1.SchemeGroupVersion.WithResource("secrets")
+	pvcs := corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims")
+	client := limitTestClient(t,
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "a", Namespace: "app", Labels: map[string]string{"tier": "web"}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "b", Namespace: "app"}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "c", Namespace: "other"}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "small", Namespace: "app"}, Data: map[string][]byte{"key": make([]byte, 512)}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "large", Namespace: "app"}, Data: map[string][]byte{"key": make([]byte, 3000)}},
+		limitTestPVC("data", "30Gi"),
+		limitTestPVC("logs", "30Gi"),
+	)
+	resources := []schema.GroupVersionResource{configMaps, secrets, pvcs}
+
+	t.Run("within limits", func(t *testing.T) {
+		limits := &drv1alpha1.SyncLimits{
+			MaxObjectsPerKind: ptr.To[int32](2),
+			MaxTotalPVCSizeGi: ptr.To[int64](60),
+			MaxSecretSizeKi:   ptr.To[int64](4),
+		}
+		assert.NoError(t, checkSyncLimits(ctx, client, "app", resources, limits, nil))
+	})
+
+	t.Run("exceeded", func(t *testing.T) {
+		limits := &drv1alpha1.SyncLimits{
+			MaxObjectsPerKind: ptr.To[int32](1),
+			MaxTotalPVCSizeGi: ptr.To[int64](50),
+			MaxSecretSizeKi:   ptr.To[int64](2),
+		}
+		err := checkSyncLimits(ctx, client, "app", resources, limits, nil)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrQuotaExceeded))
+		assert.Contains(t, err.Error(), "2 configmaps exceed maxObjectsPerKind 1")
+		assert.Contains(t, err.Error(), "PVCs request 60Gi in total, exceeding maxTotalPVCSizeGi 50")
+		assert.Contains(t, err.Error(), "Secrets large (3Ki) exceed maxSecretSizeKi 2")
+	})
+
+	t.Run("selector", func(t *testing.T) {
+		limits := &drv1alpha1.SyncLimits{MaxObjectsPerKind: ptr.To[int32](1)}
+		selector := labels.SelectorFromSet(labels.Set{"tier": "web"})
+		assert.NoError(t, checkSyncLimits(ctx, client, "app", []schema.GroupVersionResource{configMaps}, limits, selector))
+	})
+}
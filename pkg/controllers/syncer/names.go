@@ -0,0 +1,205 @@
+package syncer
+
+import (
+	"fmt"
+	"regexp"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// renamedKinds are the kinds whose destination copies can be renamed
+var renamedKinds = map[string]bool{"ConfigMap": true, "Secret": true, "Service": true}
+
+var ingressesResource = schema.GroupResource{Group: "networking.k8s.io", Resource: "ingresses"}
+
+// nameTransformation is a compiled drv1alpha1.NameTransformation
+type nameTransformation struct {
+	kind        string
+	name        *regexp.Regexp
+	pattern     *regexp.Regexp
+	replacement string
+	prefix      string
+	suffix      string
+}
+
+// compileNameTransformations compiles the name transformations of a NamespaceMapping
+func compileNameTransformations(transformations []drv1alpha1.NameTransformation) ([]nameTransformation, error) {
+	var compiled []nameTransformation
+	for _, transformation := range transformations {
+		if !renamedKinds[transformation.Kind] {
+			return nil, fmt.Errorf("name transformations do not apply to kind %q", transformation.Kind)
+		}
+		t := nameTransformation{
+			kind:        transformation.Kind,
+			replacement: transformation.Replacement,
+			prefix:      transformation.Prefix,
+			suffix:      transformation.Suffix,
+		}
+		var err error
+		if transformation.Name != "" {
+			if t.name, err = compilePattern(transformation.Name); err != nil {
+				return nil, err
+			}
+		}
+		if transformation.Pattern != "" {
+			if t.pattern, err = regexp.Compile(transformation.Pattern); err != nil {
+				return nil, fmt.Errorf("invalid name transformation pattern %q: %v", transformation.Pattern, err)
+			}
+		}
+		compiled = append(compiled, t)
+	}
+	return compiled, nil
+}
+
+// destinationName returns the name of the destination copy of a resource
+func (r *ResourceSyncer) destinationName(kind, name string) string {
+	if r == nil || name == "" {
+		return name
+	}
+	for _, t := range r.nameTransformations {
+		if t.kind != kind || (t.name != nil && !t.name.MatchString(name)) {
+			continue
+		}
+		if t.pattern != nil {
+			name = t.pattern.ReplaceAllString(name, t.replacement)
+		}
+		return t.prefix + name + t.suffix
+	}
+	return name
+}
+
+// transformNames renames the destination copy of a resource and rewrites its references to renamed resources
+func (r *ResourceSyncer) transformNames(gvr schema.GroupVersionResource, u *unstructured.Unstructured) error {
+	if len(r.nameTransformations) == 0 {
+		return nil
+	}
+
+	kind, source := u.GetKind(), u.GetName()
+	if name := r.destinationName(kind, source); name != source {
+		errs := validation.IsDNS1123Subdomain(name)
+		if kind == "Service" {
+			errs = validation.IsDNS1035Label(name)
+		}
+		if len(errs) > 0 {
+			return syncerrors.NewNonRetryableError(
+				fmt.Errorf("invalid destination name %q: %v", name, errs),
+				fmt.Sprintf("%s/%s", kind, source),
+			)
+		}
+		log.Info(fmt.Sprintf("renaming %s %s to %s in the destination", kind, source, name))
+		u.SetName(name)
+	}
+
+	if path, ok := podTemplatePaths[gvr.GroupResource()]; ok {
+		if spec, found, _ := unstructured.NestedFieldNoCopy(u.Object, path...); found {
+			if spec, ok := spec.(map[string]interface{}); ok {
+				r.renamePodSpecReferences(spec)
+			}
+		}
+	}
+	if gvr.GroupResource() == ingressesResource {
+		r.renameIngressReferences(u.Object)
+	}
+	return nil
+}
+
+// renameReference rewrites the name of the resource of a kind referenced by field
+func (r *ResourceSyncer) renameReference(obj map[string]interface{}, field, kind string) {
+	if name, ok := obj[field].(string); ok {
+		obj[field] = r.destinationName(kind, name)
+	}
+}
+
+// childMap returns the object of a field, nil when it is not set
+func childMap(obj map[string]interface{}, field string) map[string]interface{} {
+	child, _ := obj[field].(map[string]interface{})
+	return child
+}
+
+// eachMap calls fn for the objects of a list field
+func eachMap(obj map[string]interface{}, field string, fn func(map[string]interface{})) {
+	items, _ := obj[field].([]interface{})
+	for _, item := range items {
+		if m, ok := item.(map[string]interface{}); ok {
+			fn(m)
+		}
+	}
+}
+
+// renamePodSpecReferences rewrites the ConfigMaps and Secrets referenced by the volumes, environment
+// and image pull secrets of a pod spec
+func (r *ResourceSyncer) renamePodSpecReferences(spec map[string]interface{}) {
+	eachPodSpecReference(spec, r.renameReference)
+	eachMap(spec, "imagePullSecrets", func(secret map[string]interface{}) {
+		r.renameReference(secret, "name", "Secret")
+	})
+}
+
+// eachPodSpecReference calls fn for each ConfigMap and Secret referenced by the volumes and environment of
+// a pod spec, with the object holding the reference, the field of the name and the referenced kind
+func eachPodSpecReference(spec map[string]interface{}, fn func(ref map[string]interface{}, field, kind string)) {
+	eachMap(spec, "volumes", func(volume map[string]interface{}) {
+		if configMap := childMap(volume, "configMap"); configMap != nil {
+			fn(configMap, "name", "ConfigMap")
+		}
+		if secret := childMap(volume, "secret"); secret != nil {
+			fn(secret, "secretName", "Secret")
+		}
+		if projected := childMap(volume, "projected"); projected != nil {
+			eachMap(projected, "sources", func(source map[string]interface{}) {
+				if configMap := childMap(source, "configMap"); configMap != nil {
+					fn(configMap, "name", "ConfigMap")
+				}
+				if secret := childMap(source, "secret"); secret != nil {
+					fn(secret, "name", "Secret")
+				}
+			})
+		}
+	})
+
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		eachMap(spec, field, func(container map[string]interface{}) {
+			eachMap(container, "envFrom", func(envFrom map[string]interface{}) {
+				if ref := childMap(envFrom, "configMapRef"); ref != nil {
+					fn(ref, "name", "ConfigMap")
+				}
+				if ref := childMap(envFrom, "secretRef"); ref != nil {
+					fn(ref, "name", "Secret")
+				}
+			})
+			eachMap(container, "env", func(env map[string]interface{}) {
+				valueFrom := childMap(env, "valueFrom")
+				if ref := childMap(valueFrom, "configMapKeyRef"); ref != nil {
+					fn(ref, "name", "ConfigMap")
+				}
+				if ref := childMap(valueFrom, "secretKeyRef"); ref != nil {
+					fn(ref, "name", "Secret")
+				}
+			})
+		})
+	}
+}
+
+// renameIngressReferences rewrites the backend Services and TLS Secrets of an Ingress
+func (r *ResourceSyncer) renameIngressReferences(obj map[string]interface{}) {
+	spec := childMap(obj, "spec")
+	renameBackend := func(backend map[string]interface{}) {
+		if service := childMap(backend, "service"); service != nil {
+			r.renameReference(service, "name", "Service")
+		}
+	}
+
+	renameBackend(childMap(spec, "defaultBackend"))
+	eachMap(spec, "rules", func(rule map[string]interface{}) {
+		eachMap(childMap(rule, "http"), "paths", func(path map[string]interface{}) {
+			renameBackend(childMap(path, "backend"))
+		})
+	})
+	eachMap(spec, "tls", func(tls map[string]interface{}) {
+		r.renameReference(tls, "secretName", "Secret")
+	})
+}
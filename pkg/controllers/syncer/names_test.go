@@ -0,0 +1,134 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestCompileNameTransformations(t *testing.T) {
+	_, err := compileNameTransformations([]drv1alpha1.NameTransformation{{Kind: "Deployment", Prefix: "dr-"}})
+	assert.Error(t, err)
+
+	_, err = compileNameTransformations([]drv1alpha1.NameTransformation{{Kind: "Secret", Pattern: "prod-("}})
+	assert.ErrorContains(t, err, `invalid name transformation pattern "prod-("`)
+
+	transformations, err := compileNameTransformations([]drv1alpha1.NameTransformation{
+		{Kind: "Secret", Name: "prod-.*", Pattern: "^prod-", Replacement: "dr-"},
+		{Kind: "ConfigMap", Prefix: "dr-"},
+		{Kind: "Service", Suffix: "-dr"},
+	})
+	require.NoError(t, err)
+	r := &ResourceSyncer{nameTransformations: transformations}
+
+	assert.Equal(t, "dr-db-credentials", r.destinationName("Secret", "prod-db-credentials"))
+	assert.Equal(t, "registry-auth", r.destinationName("Secret", "registry-auth"))
+	assert.Equal(t, "dr-settings", r.destinationName("ConfigMap", "settings"))
+	assert.Equal(t, "web-dr", r.destinationName("Service", "web"))
+	assert.Equal(t, "web", r.destinationName("Deployment", "web"))
+}
+
+func TestSyncResource_NameTransformation(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	require.NoError(t, networkingv1.AddToScheme(scheme))
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+
+	transformations, err := compileNameTransformations([]drv1alpha1.NameTransformation{
+		{Kind: "ConfigMap", Prefix: "dr-"},
+		{Kind: "Secret", Prefix: "dr-"},
+		{Kind: "Service", Suffix: "-dr"},
+	})
+	require.NoError(t, err)
+	syncer := NewResourceSyncer(nil, nil, destDynamic, nil, nil, scheme)
+	syncer.nameTransformations = transformations
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "app-dr"}}
+	require.NoError(t, syncer.SyncResource(ctx, configMap, nil))
+	_, err = destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("app-dr").Get(ctx, "dr-settings", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	// Workloads keep their names and reference the renamed ConfigMaps and Secrets
+	deploy := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app-dr"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{
+				{Name: "settings", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}}}},
+				{Name: "tls", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "tls"}}},
+			},
+			Containers: []corev1.Container{{
+				Name:    "web",
+				EnvFrom: []corev1.EnvFromSource{{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "credentials"}}}},
+				Env: []corev1.EnvVar{{Name: "MODE", ValueFrom: &corev1.EnvVarSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "settings"}, Key: "mode"},
+				}}},
+			}},
+			ImagePullSecrets: []corev1.LocalObjectReference{{Name: "registry"}},
+		}}},
+	}
+	require.NoError(t, syncer.SyncResource(ctx, deploy, nil))
+	synced, err := destDynamic.Resource(appsv1.SchemeGroupVersion.WithResource("deployments")).Namespace("app-dr").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	volumes, _, _ := unstructured.NestedSlice(synced.Object, "spec", "template", "spec", "volumes")
+	require.Len(t, volumes, 2)
+	assert.Equal(t, "dr-settings", volumes[0].(map[string]interface{})["configMap"].(map[string]interface{})["name"])
+	assert.Equal(t, "dr-tls", volumes[1].(map[string]interface{})["secret"].(map[string]interface{})["secretName"])
+
+	containers, _, _ := unstructured.NestedSlice(synced.Object, "spec", "template", "spec", "containers")
+	container := containers[0].(map[string]interface{})
+	secretRef, _, _ := unstructured.NestedString(container["envFrom"].([]interface{})[0].(map[string]interface{}), "secretRef", "name")
+	assert.Equal(t, "dr-credentials", secretRef)
+	configMapRef, _, _ := unstructured.NestedString(container["env"].([]interface{})[0].(map[string]interface{}), "valueFrom", "configMapKeyRef", "name")
+	assert.Equal(t, "dr-settings", configMapRef)
+
+	pullSecrets, _, _ := unstructured.NestedSlice(synced.Object, "spec", "template", "spec", "imagePullSecrets")
+	assert.Equal(t, "dr-registry", pullSecrets[0].(map[string]interface{})["name"])
+
+	// Ingresses route to the renamed Services
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app-dr"},
+		Spec: networkingv1.IngressSpec{
+			TLS: []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "shop-tls"}},
+			Rules: []networkingv1.IngressRule{{Host: "shop.example.com", IngressRuleValue: networkingv1.IngressRuleValue{
+				HTTP: &networkingv1.HTTPIngressRuleValue{Paths: []networkingv1.HTTPIngressPath{{
+					Path:    "/",
+					Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{Name: "web", Port: networkingv1.ServiceBackendPort{Number: 80}}},
+				}}},
+			}}},
+		},
+	}
+	require.NoError(t, syncer.SyncResource(ctx, ingress, nil))
+	syncedIngress, err := destDynamic.Resource(networkingv1.SchemeGroupVersion.WithResource("ingresses")).Namespace("app-dr").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+
+	tls, _, _ := unstructured.NestedSlice(syncedIngress.Object, "spec", "tls")
+	assert.Equal(t, "dr-shop-tls", tls[0].(map[string]interface{})["secretName"])
+	rules, _, _ := unstructured.NestedSlice(syncedIngress.Object, "spec", "rules")
+	paths, _, _ := unstructured.NestedSlice(rules[0].(map[string]interface{}), "http", "paths")
+	service, _, _ := unstructured.NestedString(paths[0].(map[string]interface{}), "backend", "service", "name")
+	assert.Equal(t, "web-dr", service)
+}
+
+func TestTransformNames_InvalidName(t *testing.T) {
+	transformations, err := compileNameTransformations([]drv1alpha1.NameTransformation{{Kind: "Service", Prefix: "DR_"}})
+	require.NoError(t, err)
+	syncer := &ResourceSyncer{nameTransformations: transformations}
+
+	u := &unstructured.Unstructured{}
+	u.SetKind("Service")
+	u.SetName("web")
+	assert.Error(t, syncer.transformNames(corev1.SchemeGroupVersion.WithResource("services"), u))
+}
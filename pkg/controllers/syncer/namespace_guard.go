@@ -0,0 +1,51 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supporttools/dr-syncer/pkg/audit"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// checkDestinationNamespace refuses to sync into an existing destination namespace dr-syncer does not
+// manage, so a mapping pointing at a live namespace cannot clobber it. Missing namespaces are fine, they
+// are created labeled as managed. Namespaces annotated for adoption are synced as they are, and with
+// allowAdopt an unmanaged namespace is labeled as managed before it is synced.
+func checkDestinationNamespace(ctx context.Context, client kubernetes.Interface, namespace string, allowAdopt bool) error {
+	ns, err := client.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get destination namespace: %w", err)
+	}
+
+	if ns.Labels[utils.ManagedByLabel] == utils.ManagedByValue || ns.Annotations[utils.AdoptAnnotation] == "true" {
+		return nil
+	}
+
+	if !allowAdopt {
+		return syncerrors.NewNonRetryableError(
+			fmt.Errorf("destination namespace %s exists and is not managed by dr-syncer; label it %s=%s, annotate it %s=true or set spec.allowAdoptExisting to sync into it",
+				namespace, utils.ManagedByLabel, utils.ManagedByValue, utils.AdoptAnnotation),
+			fmt.Sprintf("Namespace/%s", namespace),
+		)
+	}
+
+	log.Info(fmt.Sprintf("adopting existing destination namespace %s", namespace))
+	if ns.Labels == nil {
+		ns.Labels = make(map[string]string)
+	}
+	ns.Labels[utils.ManagedByLabel] = utils.ManagedByValue
+	_, err = client.CoreV1().Namespaces().Update(ctx, ns, metav1.UpdateOptions{})
+	audit.Record(ctx, audit.OperationUpdate, namespaceRef(namespace), "", err)
+	if err != nil {
+		return fmt.Errorf("failed to adopt destination namespace %s: %w", namespace, err)
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckDestinationNamespace(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "managed", Labels: map[string]string{utils.ManagedByLabel: utils.ManagedByValue}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "annotated", Annotations: map[string]string{utils.AdoptAnnotation: "true"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "live"}},
+	)
+
+	assert.NoError(t, checkDestinationNamespace(ctx, client, "missing", false))
+	assert.NoError(t, checkDestinationNamespace(ctx, client, "managed", false))
+	assert.NoError(t, checkDestinationNamespace(ctx, client, "annotated", false))
+
+	err := checkDestinationNamespace(ctx, client, "live", false)
+	assert.ErrorContains(t, err, "destination namespace live exists and is not managed by dr-syncer")
+	assert.False(t, syncerrors.IsRetryable(err))
+
+	// Adopting labels the namespace, so later syncs pass without the flag
+	assert.NoError(t, checkDestinationNamespace(ctx, client, "live", true))
+	ns, err := client.CoreV1().Namespaces().Get(ctx, "live", metav1.GetOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, utils.ManagedByValue, ns.Labels[utils.ManagedByLabel])
+	assert.NoError(t, checkDestinationNamespace(ctx, client, "live", false))
+}
@@ -0,0 +1,125 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// defaultDenyPolicyName denies all ingress and egress of the pods of an isolated destination namespace
+	defaultDenyPolicyName = "dr-syncer-default-deny"
+
+	// dataSyncPolicyName lets the data sync pods of an isolated destination namespace reach the source cluster
+	dataSyncPolicyName = "dr-syncer-allow-data-sync"
+
+	// exemptPodsPolicyName lets the exempt pods of an isolated destination namespace communicate freely
+	exemptPodsPolicyName = "dr-syncer-allow-exempt"
+)
+
+// isolationPolicyNames are the NetworkPolicies dr-syncer may create in an isolated destination namespace
+var isolationPolicyNames = []string{defaultDenyPolicyName, dataSyncPolicyName, exemptPodsPolicyName}
+
+// dataSyncPodSelector selects the pods dr-syncer runs in the destination namespace to sync PVC data
+var dataSyncPodSelector = metav1.LabelSelector{
+	MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "dr-syncer.io/sync-id", Operator: metav1.LabelSelectorOpExists}},
+}
+
+// networkIsolated reports whether a destination namespace is isolated: the mapping enables network
+// isolation and keeps the namespace a standby with scaleToZero
+func networkIsolated(config *drv1alpha1.NetworkIsolationConfig, scaleToZero bool) bool {
+	return scaleToZero && config != nil && config.Enabled != nil && *config.Enabled
+}
+
+// isolationPolicies returns the NetworkPolicies isolating the destination namespace. NetworkPolicies
+// only add allowed traffic, so the allow policies open the default-deny policy for the pods they select.
+func isolationPolicies(namespace string, config *drv1alpha1.NetworkIsolationConfig) []*networkingv1.NetworkPolicy {
+	policy := func(name string, selector metav1.LabelSelector, spec networkingv1.NetworkPolicySpec) *networkingv1.NetworkPolicy {
+		spec.PodSelector = selector
+		return &networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    map[string]string{utils.ManagedByLabel: utils.ManagedByValue},
+			},
+			Spec: spec,
+		}
+	}
+
+	policies := []*networkingv1.NetworkPolicy{
+		policy(defaultDenyPolicyName, metav1.LabelSelector{}, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+		}),
+		// The data sync pods connect out to the agents of the source cluster, nothing connects to them
+		policy(dataSyncPolicyName, dataSyncPodSelector, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress:      []networkingv1.NetworkPolicyEgressRule{{}},
+		}),
+	}
+	if config.ExemptPods != nil {
+		policies = append(policies, policy(exemptPodsPolicyName, *config.ExemptPods, networkingv1.NetworkPolicySpec{
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress},
+			Ingress:     []networkingv1.NetworkPolicyIngressRule{{}},
+			Egress:      []networkingv1.NetworkPolicyEgressRule{{}},
+		}))
+	}
+	return policies
+}
+
+// isolateNamespace creates or updates the NetworkPolicies isolating the destination namespace while it
+// is a standby, and removes them once the namespace is activated or isolation is disabled
+func (r *ResourceSyncer) isolateNamespace(ctx context.Context, namespace string, config *drv1alpha1.NetworkIsolationConfig, scaleToZero bool) error {
+	wanted := make(map[string]*networkingv1.NetworkPolicy)
+	if networkIsolated(config, scaleToZero) {
+		for _, policy := range isolationPolicies(namespace, config) {
+			wanted[policy.Name] = policy
+		}
+	}
+
+	policies := r.destClient.NetworkingV1().NetworkPolicies(namespace)
+	for _, name := range isolationPolicyNames {
+		existing, err := policies.Get(ctx, name, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get NetworkPolicy %s/%s: %w", namespace, name, err)
+		}
+		found := err == nil
+		ref := corev1.ObjectReference{APIVersion: "networking.k8s.io/v1", Kind: "NetworkPolicy", Namespace: namespace, Name: name}
+
+		policy, ok := wanted[name]
+		switch {
+		case ok && !found:
+			r.labelSynced(policy)
+			_, err = policies.Create(ctx, policy, metav1.CreateOptions{})
+			audit.Record(ctx, audit.OperationCreate, ref, "", err)
+			if err != nil {
+				return fmt.Errorf("failed to create NetworkPolicy %s/%s: %w", namespace, name, err)
+			}
+			log.Info(fmt.Sprintf("created NetworkPolicy %s/%s isolating the standby namespace", namespace, name))
+		case ok:
+			r.labelSynced(policy)
+			existing.Labels = policy.Labels
+			existing.Spec = policy.Spec
+			_, err = policies.Update(ctx, existing, metav1.UpdateOptions{})
+			audit.Record(ctx, audit.OperationUpdate, ref, "", err)
+			if err != nil {
+				return fmt.Errorf("failed to update NetworkPolicy %s/%s: %w", namespace, name, err)
+			}
+		case found && existing.Labels[utils.ManagedByLabel] == utils.ManagedByValue:
+			// Policies of the same name that dr-syncer did not create are left alone
+			err = policies.Delete(ctx, name, metav1.DeleteOptions{})
+			audit.Record(ctx, audit.OperationDelete, ref, "", err)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete NetworkPolicy %s/%s: %w", namespace, name, err)
+			}
+			log.Info(fmt.Sprintf("deleted NetworkPolicy %s/%s, the namespace is no longer isolated", namespace, name))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func policyNames(t *testing.T, syncer *ResourceSyncer, namespace string) []string {
+	policies, err := syncer.destClient.NetworkingV1().NetworkPolicies(namespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, policy := range policies.Items {
+		names = append(names, policy.Name)
+	}
+	return names
+}
+
+func TestIsolateNamespace(t *testing.T) {
+	ctx := context.Background()
+	enabled := true
+	config := &drv1alpha1.NetworkIsolationConfig{
+		Enabled:    &enabled,
+		ExemptPods: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db-operator"}},
+	}
+	syncer := NewResourceSyncer(nil, nil, nil, nil, fake.NewSimpleClientset(), nil)
+	syncer.mappingLabels = utils.MappingLabels("team", "app")
+
+	require.NoError(t, syncer.isolateNamespace(ctx, "app-dr", config, true))
+	assert.ElementsMatch(t, []string{defaultDenyPolicyName, dataSyncPolicyName, exemptPodsPolicyName}, policyNames(t, syncer, "app-dr"))
+
+	deny, err := syncer.destClient.NetworkingV1().NetworkPolicies("app-dr").Get(ctx, defaultDenyPolicyName, metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Empty(t, deny.Spec.PodSelector.MatchLabels, "all pods of the namespace are selected")
+	assert.ElementsMatch(t, []networkingv1.PolicyType{networkingv1.PolicyTypeIngress, networkingv1.PolicyTypeEgress}, deny.Spec.PolicyTypes)
+	assert.Empty(t, deny.Spec.Egress)
+	assert.Equal(t, "app", deny.Labels[utils.MappingNameLabel], "the SyncedOnly cleanup policy removes the policies")
+
+	// Syncing again without exempt pods removes their policy
+	config.ExemptPods = nil
+	require.NoError(t, syncer.isolateNamespace(ctx, "app-dr", config, true))
+	assert.ElementsMatch(t, []string{defaultDenyPolicyName, dataSyncPolicyName}, policyNames(t, syncer, "app-dr"))
+
+	// Activating the namespace removes the isolation
+	require.NoError(t, syncer.isolateNamespace(ctx, "app-dr", config, false))
+	assert.Empty(t, policyNames(t, syncer, "app-dr"))
+}
+
+func TestIsolateNamespace_KeepsForeignPolicies(t *testing.T) {
+	foreign := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: defaultDenyPolicyName, Namespace: "app-dr"}}
+	syncer := NewResourceSyncer(nil, nil, nil, nil, fake.NewSimpleClientset(foreign), nil)
+
+	require.NoError(t, syncer.isolateNamespace(context.Background(), "app-dr", &drv1alpha1.NetworkIsolationConfig{}, true))
+	assert.Equal(t, []string{defaultDenyPolicyName}, policyNames(t, syncer, "app-dr"), "policies dr-syncer did not create are not deleted")
+}
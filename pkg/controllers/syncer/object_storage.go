@@ -0,0 +1,42 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	controller "github.com/supporttools/dr-syncer/pkg/controller/replication"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// objectStorageRepository resolves the restic repository of the ObjectStorage transport, reading its
+// credentials from the secret in the mapping's namespace
+func objectStorageRepository(ctx context.Context, c client.Client, mappingNamespace string, config *drv1alpha1.ObjectStorageConfig) (*controller.ObjectStorageRepository, error) {
+	if config == nil || config.Repository == "" {
+		return nil, fmt.Errorf("the %s transport requires objectStorage.repository", drv1alpha1.PVCDataTransportObjectStorage)
+	}
+	if c == nil || mappingNamespace == "" {
+		return nil, fmt.Errorf("cannot read object storage credentials without the NamespaceMapping's namespace")
+	}
+
+	var secret corev1.Secret
+	if err := c.Get(ctx, types.NamespacedName{Namespace: mappingNamespace, Name: config.CredentialsSecretRef.Name}, &secret); err != nil {
+		return nil, fmt.Errorf("failed to get object storage credentials secret %s/%s: %w", mappingNamespace, config.CredentialsSecretRef.Name, err)
+	}
+	if len(secret.Data["RESTIC_PASSWORD"]) == 0 {
+		return nil, fmt.Errorf("object storage credentials secret %s/%s has no RESTIC_PASSWORD", mappingNamespace, config.CredentialsSecretRef.Name)
+	}
+
+	env := make(map[string]string, len(secret.Data))
+	for key, value := range secret.Data {
+		env[key] = string(value)
+	}
+
+	keep := controller.DefaultKeepSnapshots
+	if config.KeepSnapshots != nil && *config.KeepSnapshots > 0 {
+		keep = int(*config.KeepSnapshots)
+	}
+	return &controller.ObjectStorageRepository{Repository: config.Repository, Env: env, KeepSnapshots: keep}, nil
+}
@@ -0,0 +1,54 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestObjectStorageRepository(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "restic", Namespace: "shop"},
+			Data: map[string][]byte{
+				"RESTIC_PASSWORD":       []byte("secret"),
+				"AWS_ACCESS_KEY_ID":     []byte("AKIA123"),
+				"AWS_SECRET_ACCESS_KEY": []byte("key"),
+			},
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "no-password", Namespace: "shop"},
+			Data:       map[string][]byte{"AWS_ACCESS_KEY_ID": []byte("AKIA123")},
+		},
+	).Build()
+	ctx := context.Background()
+	config := &drv1alpha1.ObjectStorageConfig{
+		Repository:           "s3:s3.amazonaws.com/dr-bucket/dr-syncer",
+		CredentialsSecretRef: drv1alpha1.ObjectStorageSecretRef{Name: "restic"},
+	}
+
+	repository, err := objectStorageRepository(ctx, c, "shop", config)
+	require.NoError(t, err)
+	assert.Equal(t, "s3:s3.amazonaws.com/dr-bucket/dr-syncer", repository.Repository)
+	assert.Equal(t, "AKIA123", repository.Env["AWS_ACCESS_KEY_ID"])
+	assert.Equal(t, 3, repository.KeepSnapshots)
+
+	_, err = objectStorageRepository(ctx, c, "other", config)
+	assert.ErrorContains(t, err, "failed to get object storage credentials secret other/restic", "the secret is read from the mapping's namespace")
+
+	config.CredentialsSecretRef.Name = "no-password"
+	_, err = objectStorageRepository(ctx, c, "shop", config)
+	assert.ErrorContains(t, err, "has no RESTIC_PASSWORD")
+
+	_, err = objectStorageRepository(ctx, c, "shop", nil)
+	assert.ErrorContains(t, err, "the ObjectStorage transport requires objectStorage.repository")
+}
@@ -0,0 +1,30 @@
+package syncer
+
+import (
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// shouldSkip reports whether obj is left out of the sync, because it is labeled to be ignored, is
+// not selected by the mapping's resource selector or the mapping skips resources managed by a controller
+func (r *ResourceSyncer) shouldSkip(obj metav1.Object) bool {
+	if utils.ShouldIgnoreResource(obj) {
+		return true
+	}
+	if r == nil {
+		return false
+	}
+	if r.resourceSelector != nil && !r.resourceSelector.Matches(labels.Set(obj.GetLabels())) {
+		log.Debugf("skipping %s not selected by the resource selector", obj.GetName())
+		return true
+	}
+	if !r.skipOwned {
+		return false
+	}
+	if owner := metav1.GetControllerOf(obj); owner != nil {
+		log.Debugf("skipping %s owned by %s/%s", obj.GetName(), owner.Kind, owner.Name)
+		return true
+	}
+	return false
+}
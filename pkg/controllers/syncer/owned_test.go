@@ -0,0 +1,37 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestShouldSkip(t *testing.T) {
+	owned := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "postgres", OwnerReferences: []metav1.OwnerReference{
+		{APIVersion: "acid.zalan.do/v1", Kind: "postgresql", Name: "postgres", Controller: ptr.To(true)},
+	}}}
+	// Owner references without controller only garbage collect the object, nobody recreates it
+	referenced := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", OwnerReferences: []metav1.OwnerReference{
+		{APIVersion: "v1", Kind: "ConfigMap", Name: "parent"},
+	}}}
+	ignored := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "local", Labels: map[string]string{utils.IgnoreLabel: "true"}}}
+	plain := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "app"}}
+
+	var nilSyncer *ResourceSyncer
+	assert.False(t, nilSyncer.shouldSkip(owned))
+	assert.True(t, nilSyncer.shouldSkip(ignored))
+
+	syncer := &ResourceSyncer{}
+	assert.False(t, syncer.shouldSkip(owned), "owned resources are synced by default")
+
+	syncer.skipOwned = true
+	assert.True(t, syncer.shouldSkip(owned))
+	assert.False(t, syncer.shouldSkip(referenced))
+	assert.True(t, syncer.shouldSkip(ignored))
+	assert.False(t, syncer.shouldSkip(plain))
+}
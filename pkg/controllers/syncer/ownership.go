@@ -0,0 +1,88 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// ErrOwnershipConflict is wrapped by the errors of destination resources owned by another NamespaceMapping
+var ErrOwnershipConflict = errors.New("destination resource owned by another NamespaceMapping")
+
+// loadMappingUID looks up the UID of the syncing mapping, so the destination resources it writes
+// record their owner. Ownership is not tracked when the mapping cannot be read.
+func (r *ResourceSyncer) loadMappingUID(ctx context.Context, namespace, name string) {
+	if r.ctrlClient == nil {
+		return
+	}
+	var mapping drv1alpha1.NamespaceMapping
+	if err := r.ctrlClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &mapping); err != nil {
+		log.Warn(fmt.Sprintf("failed to get NamespaceMapping %s/%s, ownership of destination resources is not tracked: %v", namespace, name, err))
+		return
+	}
+	r.mappingUID = mapping.UID
+}
+
+// checkOwnership refuses to overwrite a destination resource owned by another NamespaceMapping that
+// still exists. Resources without an owner and resources of deleted mappings are taken over.
+func (r *ResourceSyncer) checkOwnership(ctx context.Context, kind string, existing metav1.Object) error {
+	if r == nil || r.mappingUID == "" || existing.GetNamespace() == "" {
+		return nil
+	}
+
+	labels := existing.GetLabels()
+	ownerName, ownerNamespace := labels[utils.MappingNameLabel], labels[utils.MappingNamespaceLabel]
+	ownerUID := types.UID(labels[utils.MappingUIDLabel])
+	if ownerName == "" || ownerUID == r.mappingUID {
+		return nil
+	}
+	// A mapping recreated under the same name keeps the resources of its predecessor
+	if ownerName == r.mappingLabels[utils.MappingNameLabel] && ownerNamespace == r.mappingLabels[utils.MappingNamespaceLabel] {
+		return nil
+	}
+	if !r.ownerActive(ctx, ownerNamespace, ownerName, ownerUID) {
+		log.Info(fmt.Sprintf("taking over %s %s/%s from deleted NamespaceMapping %s/%s",
+			kind, existing.GetNamespace(), existing.GetName(), ownerNamespace, ownerName))
+		return nil
+	}
+
+	return syncerrors.NewNonRetryableError(
+		fmt.Errorf("%w: %s %s is owned by NamespaceMapping %s/%s", ErrOwnershipConflict, kind, existing.GetName(), ownerNamespace, ownerName),
+		resourceRef(kind, existing.GetName()),
+	)
+}
+
+// ownerActive returns true if the NamespaceMapping owning a destination resource still exists. An owner
+// that cannot be looked up is assumed to exist, so its resources are never overwritten by mistake.
+func (r *ResourceSyncer) ownerActive(ctx context.Context, namespace, name string, uid types.UID) bool {
+	key := fmt.Sprintf("%s/%s/%s", namespace, name, uid)
+	if active, ok := r.activeOwners[key]; ok {
+		return active
+	}
+
+	active := true
+	var owner drv1alpha1.NamespaceMapping
+	err := r.ctrlClient.Get(ctx, types.NamespacedName{Namespace: namespace, Name: name}, &owner)
+	switch {
+	case apierrors.IsNotFound(err):
+		active = false
+	case err != nil:
+		log.Warn(fmt.Sprintf("failed to get NamespaceMapping %s/%s owning destination resources: %v", namespace, name, err))
+	case uid != "" && owner.UID != uid:
+		// The owner was deleted and another mapping was created under its name
+		active = false
+	}
+
+	if r.activeOwners == nil {
+		r.activeOwners = make(map[string]bool)
+	}
+	r.activeOwners[key] = active
+	return active
+}
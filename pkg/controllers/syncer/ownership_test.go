@@ -0,0 +1,67 @@
+package syncer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckOwnership(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "dr-syncer", UID: "shop-uid"}},
+		&drv1alpha1.NamespaceMapping{ObjectMeta: metav1.ObjectMeta{Name: "billing", Namespace: "dr-syncer", UID: "billing-uid"}},
+	).Build()
+	ctx := context.Background()
+
+	syncer := &ResourceSyncer{ctrlClient: c, mappingLabels: utils.MappingLabels("dr-syncer", "shop")}
+	syncer.loadMappingUID(ctx, "dr-syncer", "shop")
+	require.Equal(t, "shop-uid", string(syncer.mappingUID))
+
+	owned := func(name, uid string) *corev1.ConfigMap {
+		labels := utils.MappingLabels("dr-syncer", name)
+		if uid != "" {
+			labels[utils.MappingUIDLabel] = uid
+		}
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "shop", Labels: labels}}
+	}
+
+	assert.NoError(t, syncer.checkOwnership(ctx, "ConfigMap", &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "shop"}}))
+	assert.NoError(t, syncer.checkOwnership(ctx, "ConfigMap", owned("shop", "shop-uid")))
+	assert.NoError(t, syncer.checkOwnership(ctx, "ConfigMap", owned("shop", "old-uid")), "a recreated mapping keeps its resources")
+	assert.NoError(t, syncer.checkOwnership(ctx, "ConfigMap", owned("deleted", "deleted-uid")))
+	assert.NoError(t, syncer.checkOwnership(ctx, "ConfigMap", owned("billing", "recreated-uid")))
+
+	err := syncer.checkOwnership(ctx, "ConfigMap", owned("billing", "billing-uid"))
+	assert.True(t, errors.Is(err, ErrOwnershipConflict))
+	assert.ErrorContains(t, err, "ConfigMap settings is owned by NamespaceMapping dr-syncer/billing")
+
+	// Resources synced before their owner's UID was recorded are owned by name
+	assert.ErrorIs(t, syncer.checkOwnership(ctx, "ConfigMap", owned("billing", "")), ErrOwnershipConflict)
+
+	// The UID of the owner is written with the mapping labels
+	cm := &corev1.ConfigMap{}
+	syncer.labelSynced(cm)
+	assert.Equal(t, "shop-uid", cm.Labels[utils.MappingUIDLabel])
+}
+
+func TestResourceSelector(t *testing.T) {
+	syncer := &ResourceSyncer{}
+	selector, err := metav1.LabelSelectorAsSelector(&metav1.LabelSelector{MatchLabels: map[string]string{"team": "shop"}})
+	require.NoError(t, err)
+	syncer.resourceSelector = selector
+
+	assert.False(t, syncer.shouldSkip(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"team": "shop"}}}))
+	assert.True(t, syncer.shouldSkip(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "billing", Labels: map[string]string{"team": "billing"}}}))
+	assert.True(t, syncer.shouldSkip(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unlabeled"}}))
+}
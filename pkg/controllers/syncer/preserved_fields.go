@@ -0,0 +1,243 @@
+package syncer
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// pathSegment is a step of a preserved field path: a map field, a list index or the list item whose
+// field matchKey equals matchValue
+type pathSegment struct {
+	field      string
+	index      int
+	matchKey   string
+	matchValue string
+}
+
+// isField reports whether the segment addresses a map field
+func (s pathSegment) isField() bool {
+	return s.field != ""
+}
+
+// preservedFields is a compiled drv1alpha1.PreservedFields
+type preservedFields struct {
+	kind  string
+	name  *regexp.Regexp
+	paths [][]pathSegment
+}
+
+// filterExpression matches the [?(@.field=="value")] list item filters of a path
+var filterExpression = regexp.MustCompile(`^\?\(@\.([^=\s]+)\s*==\s*(?:"([^"]*)"|'([^']*)')\)$`)
+
+// parseFieldPath parses the JSONPath subset accepted by preserveDestinationFields
+func parseFieldPath(path string) ([]pathSegment, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimSuffix(strings.TrimPrefix(p, "{"), "}")
+	p = strings.TrimPrefix(p, "$")
+
+	var segments []pathSegment
+	for p != "" {
+		switch p[0] {
+		case '.':
+			end := strings.IndexAny(p[1:], ".[")
+			if end < 0 {
+				end = len(p) - 1
+			}
+			field := p[1 : end+1]
+			if field == "" {
+				return nil, fmt.Errorf("invalid field path %q: empty field name", path)
+			}
+			segments = append(segments, pathSegment{field: field})
+			p = p[end+1:]
+		case '[':
+			end := closingBracket(p)
+			if end < 0 {
+				return nil, fmt.Errorf("invalid field path %q: unterminated [", path)
+			}
+			segment, err := parseBracket(p[1:end])
+			if err != nil {
+				return nil, fmt.Errorf("invalid field path %q: %v", path, err)
+			}
+			segments = append(segments, segment)
+			p = p[end+1:]
+		default:
+			return nil, fmt.Errorf("invalid field path %q: expected . or [ at %q", path, p)
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("invalid field path %q: no fields", path)
+	}
+	return segments, nil
+}
+
+// closingBracket returns the index of the ] closing the [ at the start of p, skipping quoted strings
+func closingBracket(p string) int {
+	var quote byte
+	for i := 1; i < len(p); i++ {
+		switch {
+		case quote != 0:
+			if p[i] == quote {
+				quote = 0
+			}
+		case p[i] == '"' || p[i] == '\'':
+			quote = p[i]
+		case p[i] == ']':
+			return i
+		}
+	}
+	return -1
+}
+
+// parseBracket parses the expression between brackets: a quoted field name, an index or a filter
+func parseBracket(expr string) (pathSegment, error) {
+	if len(expr) >= 2 && (expr[0] == '\'' || expr[0] == '"') && expr[len(expr)-1] == expr[0] {
+		if len(expr) == 2 {
+			return pathSegment{}, fmt.Errorf("empty field name")
+		}
+		return pathSegment{field: expr[1 : len(expr)-1]}, nil
+	}
+	if match := filterExpression.FindStringSubmatch(expr); match != nil {
+		return pathSegment{index: -1, matchKey: match[1], matchValue: match[2] + match[3]}, nil
+	}
+	index, err := strconv.Atoi(expr)
+	if err != nil || index < 0 {
+		return pathSegment{}, fmt.Errorf("unsupported expression [%s]", expr)
+	}
+	return pathSegment{index: index}, nil
+}
+
+// compilePreservedFields compiles the preserveDestinationFields of a NamespaceMapping
+func compilePreservedFields(fields []drv1alpha1.PreservedFields) ([]preservedFields, error) {
+	var compiled []preservedFields
+	for _, f := range fields {
+		if f.Kind == "" {
+			return nil, fmt.Errorf("preserved fields need a kind")
+		}
+		p := preservedFields{kind: f.Kind}
+		if f.Name != "" {
+			name, err := compilePattern(f.Name)
+			if err != nil {
+				return nil, err
+			}
+			p.name = name
+		}
+		for _, path := range f.Paths {
+			segments, err := parseFieldPath(path)
+			if err != nil {
+				return nil, err
+			}
+			p.paths = append(p.paths, segments)
+		}
+		compiled = append(compiled, p)
+	}
+	return compiled, nil
+}
+
+// listItem returns the position of the item of a list a segment addresses, -1 when there is none
+func listItem(list []interface{}, segment pathSegment) int {
+	if segment.matchKey == "" {
+		if segment.index < len(list) {
+			return segment.index
+		}
+		return -1
+	}
+	for i, item := range list {
+		if m, ok := item.(map[string]interface{}); ok && fmt.Sprint(m[segment.matchKey]) == segment.matchValue {
+			return i
+		}
+	}
+	return -1
+}
+
+// getPath returns the value at a path
+func getPath(obj interface{}, segments []pathSegment) (interface{}, bool) {
+	current := obj
+	for _, segment := range segments {
+		if segment.isField() {
+			m, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = m[segment.field]; !ok {
+				return nil, false
+			}
+			continue
+		}
+		list, ok := current.([]interface{})
+		if !ok {
+			return nil, false
+		}
+		i := listItem(list, segment)
+		if i < 0 {
+			return nil, false
+		}
+		current = list[i]
+	}
+	return current, true
+}
+
+// setPath sets the value at a path. Missing map fields along the path are created, missing list
+// items are not, setPath then returns false.
+func setPath(obj interface{}, segments []pathSegment, value interface{}) bool {
+	segment, rest := segments[0], segments[1:]
+	if segment.isField() {
+		m, ok := obj.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if len(rest) == 0 {
+			m[segment.field] = value
+			return true
+		}
+		child, ok := m[segment.field]
+		if !ok || child == nil {
+			if !rest[0].isField() {
+				return false
+			}
+			child = map[string]interface{}{}
+			m[segment.field] = child
+		}
+		return setPath(child, rest, value)
+	}
+
+	list, ok := obj.([]interface{})
+	if !ok {
+		return false
+	}
+	i := listItem(list, segment)
+	if i < 0 {
+		return false
+	}
+	if len(rest) == 0 {
+		list[i] = value
+		return true
+	}
+	return setPath(list[i], rest, value)
+}
+
+// preserveDestinationFields copies the preserved fields of the existing destination resource into the
+// resource written to the destination, so they are not overwritten with the source values. Entries match
+// the source name of the resource.
+func (r *ResourceSyncer) preserveDestinationFields(u, existing *unstructured.Unstructured, sourceName string) {
+	for _, p := range r.preservedFields {
+		if p.kind != u.GetKind() || (p.name != nil && !p.name.MatchString(sourceName)) {
+			continue
+		}
+		for _, segments := range p.paths {
+			value, found := getPath(existing.Object, segments)
+			if !found {
+				continue
+			}
+			if !setPath(u.Object, segments, runtime.DeepCopyJSONValue(value)) {
+				log.Info(fmt.Sprintf("preserved field of %s %s/%s not found in the source resource, keeping the source value",
+					u.GetKind(), u.GetNamespace(), u.GetName()))
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	segments, err := parseFieldPath(`.spec.template.spec.containers[?(@.name=="app")].env`)
+	require.NoError(t, err)
+	assert.Equal(t, []pathSegment{
+		{field: "spec"}, {field: "template"}, {field: "spec"}, {field: "containers"},
+		{index: -1, matchKey: "name", matchValue: "app"}, {field: "env"},
+	}, segments)
+
+	segments, err = parseFieldPath(`{$.metadata.annotations['example.com/region']}`)
+	require.NoError(t, err)
+	assert.Equal(t, []pathSegment{{field: "metadata"}, {field: "annotations"}, {field: "example.com/region"}}, segments)
+
+	segments, err = parseFieldPath(`.spec.ports[1].nodePort`)
+	require.NoError(t, err)
+	assert.Equal(t, []pathSegment{{field: "spec"}, {field: "ports"}, {index: 1}, {field: "nodePort"}}, segments)
+
+	for _, invalid := range []string{"", "spec", ".spec..replicas", ".spec[*]", ".spec.containers[?(@.name=app)]", ".metadata['a"} {
+		_, err := parseFieldPath(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestPreserveDestinationFields(t *testing.T) {
+	fields, err := compilePreservedFields([]drv1alpha1.PreservedFields{
+		{Kind: "Deployment", Name: "web", Paths: []string{
+			`.spec.template.spec.containers[?(@.name=="app")].env`,
+			`.metadata.annotations['example.com/region']`,
+			`.spec.template.spec.containers[?(@.name=="sidecar")].image`,
+		}},
+	})
+	require.NoError(t, err)
+	r := &ResourceSyncer{preservedFields: fields}
+
+	desired := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind":     "Deployment",
+		"metadata": map[string]interface{}{"name": "web"},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{map[string]interface{}{"name": "app", "image": "web:2"}},
+		}}},
+	}}
+	existing := &unstructured.Unstructured{Object: map[string]interface{}{
+		"kind": "Deployment",
+		"metadata": map[string]interface{}{
+			"name":        "web",
+			"annotations": map[string]interface{}{"example.com/region": "eu-west"},
+		},
+		"spec": map[string]interface{}{"template": map[string]interface{}{"spec": map[string]interface{}{
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "web:1", "env": []interface{}{
+					map[string]interface{}{"name": "REGION", "value": "dr"},
+				}},
+				map[string]interface{}{"name": "sidecar", "image": "proxy:1"},
+			},
+		}}},
+	}}
+
+	r.preserveDestinationFields(desired, existing, "web")
+	containers, _, _ := unstructured.NestedSlice(desired.Object, "spec", "template", "spec", "containers")
+	require.Len(t, containers, 1, "list items missing from the source are not added")
+	app := containers[0].(map[string]interface{})
+	assert.Equal(t, "web:2", app["image"], "fields not preserved keep the source value")
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "REGION", "value": "dr"}}, app["env"])
+	assert.Equal(t, map[string]string{"example.com/region": "eu-west"}, desired.GetAnnotations())
+
+	// Resources of other names are not touched
+	other := &unstructured.Unstructured{Object: map[string]interface{}{"kind": "Deployment", "metadata": map[string]interface{}{"name": "api"}}}
+	r.preserveDestinationFields(other, existing, "api")
+	assert.Empty(t, other.GetAnnotations())
+}
+
+func TestSyncResource_PreservesDestinationFields(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+
+	fields, err := compilePreservedFields([]drv1alpha1.PreservedFields{
+		{Kind: "Deployment", Paths: []string{`.spec.template.spec.containers[?(@.name=="app")].env`}},
+	})
+	require.NoError(t, err)
+	syncer := NewResourceSyncer(nil, nil, destDynamic, nil, nil, scheme)
+	syncer.preservedFields = fields
+
+	deployment := &appsv1.Deployment{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app-dr"},
+		Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+			Containers: []corev1.Container{{Name: "app", Image: "web:1"}},
+		}}},
+	}
+	require.NoError(t, syncer.SyncResource(ctx, deployment.DeepCopy(), nil))
+
+	// A webhook in the DR cluster injects an environment variable
+	deployments := destDynamic.Resource(appsv1.SchemeGroupVersion.WithResource("deployments")).Namespace("app-dr")
+	synced, err := deployments.Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	containers, _, _ := unstructured.NestedSlice(synced.Object, "spec", "template", "spec", "containers")
+	containers[0].(map[string]interface{})["env"] = []interface{}{map[string]interface{}{"name": "REGION", "value": "dr"}}
+	require.NoError(t, unstructured.SetNestedSlice(synced.Object, containers, "spec", "template", "spec", "containers"))
+	_, err = deployments.Update(ctx, synced, metav1.UpdateOptions{})
+	require.NoError(t, err)
+
+	// The injected variable survives the next sync of a new image
+	deployment.Spec.Template.Spec.Containers[0].Image = "web:2"
+	require.NoError(t, syncer.SyncResource(ctx, deployment.DeepCopy(), nil))
+	synced, err = deployments.Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	containers, _, _ = unstructured.NestedSlice(synced.Object, "spec", "template", "spec", "containers")
+	app := containers[0].(map[string]interface{})
+	assert.Equal(t, "web:2", app["image"])
+	assert.Equal(t, []interface{}{map[string]interface{}{"name": "REGION", "value": "dr"}}, app["env"])
+
+	_, err = compilePreservedFields([]drv1alpha1.PreservedFields{{Kind: "Deployment", Paths: []string{"spec.replicas"}}})
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+package syncer
+
+import (
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// AccessModeLabel overrides the destination access mode of a single PVC
+const AccessModeLabel = "dr-syncer.io/access-mode"
+
+// knownAccessModes are the access modes the access mode label may set
+var knownAccessModes = map[corev1.PersistentVolumeAccessMode]bool{
+	corev1.ReadWriteOnce:    true,
+	corev1.ReadOnlyMany:     true,
+	corev1.ReadWriteMany:    true,
+	corev1.ReadWriteOncePod: true,
+}
+
+// mapAccessModes translates the access modes of a destination PVC. The access mode label replaces
+// them with a single mode, otherwise each mode is translated by the first matching mapping, so
+// mappings can swap modes in both directions.
+func mapAccessModes(pvc *corev1.PersistentVolumeClaim, pvcConfig *drv1alpha1.PVCConfig) {
+	if override, ok := pvc.Labels[AccessModeLabel]; ok {
+		mode := corev1.PersistentVolumeAccessMode(override)
+		if knownAccessModes[mode] {
+			pvc.Spec.AccessModes = []corev1.PersistentVolumeAccessMode{mode}
+			return
+		}
+		log.Warn(fmt.Sprintf("ignoring invalid %s label %q on PVC %s", AccessModeLabel, override, pvc.Name))
+	}
+
+	if pvcConfig == nil || len(pvcConfig.AccessModeMappings) == 0 {
+		return
+	}
+
+	mapped := make([]corev1.PersistentVolumeAccessMode, 0, len(pvc.Spec.AccessModes))
+	seen := make(map[corev1.PersistentVolumeAccessMode]bool)
+	for _, mode := range pvc.Spec.AccessModes {
+		for _, mapping := range pvcConfig.AccessModeMappings {
+			if string(mode) == mapping.From {
+				mode = corev1.PersistentVolumeAccessMode(mapping.To)
+				break
+			}
+		}
+		// Several source modes may map to the same destination mode
+		if !seen[mode] {
+			seen[mode] = true
+			mapped = append(mapped, mode)
+		}
+	}
+	pvc.Spec.AccessModes = mapped
+}
@@ -0,0 +1,50 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestMapAccessModes(t *testing.T) {
+	swap := &drv1alpha1.PVCConfig{AccessModeMappings: []drv1alpha1.AccessModeMapping{
+		{From: "ReadWriteOnce", To: "ReadWriteMany"},
+		{From: "ReadWriteMany", To: "ReadWriteOnce"},
+	}}
+	toRWX := &drv1alpha1.PVCConfig{AccessModeMappings: []drv1alpha1.AccessModeMapping{
+		{From: "ReadWriteOnce", To: "ReadWriteMany"},
+		{From: "ReadWriteOncePod", To: "ReadWriteMany"},
+	}}
+
+	tests := []struct {
+		name      string
+		modes     []corev1.PersistentVolumeAccessMode
+		label     string
+		pvcConfig *drv1alpha1.PVCConfig
+		want      []corev1.PersistentVolumeAccessMode
+	}{
+		{"no mappings", []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, "", nil, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+		{"RWO to RWX", []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, "", swap, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}},
+		{"RWX to RWO", []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}, "", swap, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}},
+		{"unmapped mode kept", []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}, "", swap, []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}},
+		{"duplicates merged", []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadWriteOncePod}, "", toRWX, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}},
+		{"label overrides mappings", []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, "ReadOnlyMany", swap, []corev1.PersistentVolumeAccessMode{corev1.ReadOnlyMany}},
+		{"invalid label ignored", []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce}, "Shared", swap, []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany}},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			pvc := &corev1.PersistentVolumeClaim{
+				ObjectMeta: metav1.ObjectMeta{Name: "data"},
+				Spec:       corev1.PersistentVolumeClaimSpec{AccessModes: tc.modes},
+			}
+			if tc.label != "" {
+				pvc.Labels = map[string]string{AccessModeLabel: tc.label}
+			}
+			mapAccessModes(pvc, tc.pvcConfig)
+			assert.Equal(t, tc.want, pvc.Spec.AccessModes)
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	controller "github.com/supporttools/dr-syncer/pkg/controller/replication"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// provisionerAnnotations name the provisioner of the source StorageClass on bound source PVCs. The
+// destination control plane sets them for the provisioner of the destination StorageClass.
+var provisionerAnnotations = []string{
+	"volume.beta.kubernetes.io/storage-provisioner",
+	"volume.kubernetes.io/storage-provisioner",
+}
+
+// topologyLabelDomains are the label domains of zone and region hints
+var topologyLabelDomains = []string{"topology.kubernetes.io", "failure-domain.beta.kubernetes.io"}
+
+// pvcAnnotationParameter matches the PVC annotations StorageClass parameters reference, such as
+// ${pvc.annotations['team.example.com/key']}
+var pvcAnnotationParameter = regexp.MustCompile(`\$\{pvc\.annotations\['([^']+)'\]\}`)
+
+// storageClassAttributes describes the PVC annotations a StorageClass reads as volume attributes: the
+// annotations of the domain of its provisioner and those its parameters reference
+type storageClassAttributes struct {
+	provisioner string
+	parameters  map[string]bool
+}
+
+// newStorageClassAttributes returns the PVC annotations a StorageClass reads
+func newStorageClassAttributes(sc *storagev1.StorageClass) storageClassAttributes {
+	attributes := storageClassAttributes{provisioner: sc.Provisioner, parameters: make(map[string]bool)}
+	for _, value := range sc.Parameters {
+		for _, match := range pvcAnnotationParameter.FindAllStringSubmatch(value, -1) {
+			attributes.parameters[match[1]] = true
+		}
+	}
+	return attributes
+}
+
+// reads reports whether the StorageClass reads a PVC annotation
+func (a storageClassAttributes) reads(key string) bool {
+	return a.parameters[key] || (a.provisioner != "" && keyDomain(key) == a.provisioner)
+}
+
+// topologyLabel reports whether a label is a zone or region hint, generic or of the provisioner
+func (a storageClassAttributes) topologyLabel(key string) bool {
+	domain := keyDomain(key)
+	return a.provisioner != "" && domain == "topology."+a.provisioner
+}
+
+// keyDomain returns the prefix of a label or annotation key, empty when it has none
+func keyDomain(key string) string {
+	domain, _, found := strings.Cut(key, "/")
+	if !found {
+		return ""
+	}
+	return domain
+}
+
+// loadStorageClassAttributes returns the PVC annotations a StorageClass reads. PVCs of the default
+// StorageClass, or of a StorageClass that cannot be read, have none.
+func loadStorageClassAttributes(ctx context.Context, client kubernetes.Interface, name *string) storageClassAttributes {
+	if client == nil || name == nil || *name == "" {
+		return storageClassAttributes{}
+	}
+	sc, err := client.StorageV1().StorageClasses().Get(ctx, *name, metav1.GetOptions{})
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			log.Warn(fmt.Sprintf("failed to get storage class %s, its volume attribute annotations are not known: %v", *name, err))
+		}
+		return storageClassAttributes{}
+	}
+	return newStorageClassAttributes(sc)
+}
+
+// volumeAttributes are the volume attribute annotations of the source StorageClass of a PVC and of the
+// StorageClass of its destination copy
+type volumeAttributes struct {
+	source      storageClassAttributes
+	destination storageClassAttributes
+}
+
+// topologyLabel reports whether a label is a zone or region hint
+func (a volumeAttributes) topologyLabel(key string) bool {
+	domain := keyDomain(key)
+	for _, topologyDomain := range topologyLabelDomains {
+		if domain == topologyDomain {
+			return true
+		}
+	}
+	return a.source.topologyLabel(key) || a.destination.topologyLabel(key)
+}
+
+// applyVolumeAttributes keeps or strips the volume attributes of a new destination PVC. With
+// preserveVolumeAttributes the volume mode, VolumeAttributesClass, selector, zone hints and the
+// annotations the destination StorageClass reads are kept, annotations only the source provisioner
+// reads are stripped and PVC data sources are remapped to the destination PVC names. Without it they
+// are all stripped, except the volume mode of block volumes, which a filesystem volume cannot stand in for.
+func applyVolumeAttributes(pvc *corev1.PersistentVolumeClaim, pvcConfig *drv1alpha1.PVCConfig, attributes volumeAttributes) {
+	preserve := pvcConfig != nil && pvcConfig.PreserveVolumeAttributes
+
+	for _, key := range provisionerAnnotations {
+		delete(pvc.Annotations, key)
+	}
+	for key := range pvc.Annotations {
+		if preserve && attributes.destination.reads(key) {
+			continue
+		}
+		if attributes.source.reads(key) || attributes.destination.reads(key) {
+			delete(pvc.Annotations, key)
+		}
+	}
+
+	if preserve {
+		remapDataSource(pvc, pvcConfig.PVCMappings)
+		return
+	}
+
+	for key := range pvc.Labels {
+		if attributes.topologyLabel(key) {
+			delete(pvc.Labels, key)
+		}
+	}
+	if !controller.IsBlockVolume(pvc) {
+		pvc.Spec.VolumeMode = nil
+	}
+	pvc.Spec.VolumeAttributesClassName = nil
+	pvc.Spec.Selector = nil
+	pvc.Spec.DataSource = nil
+	pvc.Spec.DataSourceRef = nil
+}
+
+// isPVCDataSource reports whether a data source is a PVC of the same namespace
+func isPVCDataSource(apiGroup *string, kind string) bool {
+	return (apiGroup == nil || *apiGroup == "") && kind == "PersistentVolumeClaim"
+}
+
+// remapDataSource points the PVC data source of a destination PVC at the destination name of the source
+// PVC it clones. Other data sources, such as VolumeSnapshots and populators, are not replicated and are
+// cleared, as are data sources in other namespaces.
+func remapDataSource(pvc *corev1.PersistentVolumeClaim, pvcMappings []drv1alpha1.PVCMapping) {
+	remap := func(name string) (string, bool) {
+		destName, err := controller.DestinationPVCName(pvcMappings, name)
+		return destName, err == nil
+	}
+	both := pvc.Spec.DataSource != nil && pvc.Spec.DataSourceRef != nil
+
+	if source := pvc.Spec.DataSource; source != nil {
+		if name, ok := remap(source.Name); ok && isPVCDataSource(source.APIGroup, source.Kind) {
+			source.Name = name
+		} else {
+			pvc.Spec.DataSource = nil
+		}
+	}
+	if ref := pvc.Spec.DataSourceRef; ref != nil {
+		crossNamespace := ref.Namespace != nil && *ref.Namespace != "" && *ref.Namespace != pvc.Namespace
+		if name, ok := remap(ref.Name); ok && !crossNamespace && isPVCDataSource(ref.APIGroup, ref.Kind) {
+			ref.Name = name
+			ref.Namespace = nil
+		} else {
+			pvc.Spec.DataSourceRef = nil
+		}
+	}
+	// The API server requires both fields to match when both are set
+	if both && (pvc.Spec.DataSource == nil || pvc.Spec.DataSourceRef == nil) {
+		pvc.Spec.DataSource = nil
+		pvc.Spec.DataSourceRef = nil
+	}
+}
+
+// dropMissingDataSource clears the PVC data source of a new destination PVC when the PVC it clones does
+// not exist in the destination namespace, so the PVC is provisioned empty instead of staying Pending
+func dropMissingDataSource(ctx context.Context, client kubernetes.Interface, pvc *corev1.PersistentVolumeClaim) {
+	var name string
+	switch {
+	case pvc.Spec.DataSource != nil:
+		name = pvc.Spec.DataSource.Name
+	case pvc.Spec.DataSourceRef != nil:
+		name = pvc.Spec.DataSourceRef.Name
+	default:
+		return
+	}
+	_, err := client.CoreV1().PersistentVolumeClaims(pvc.Namespace).Get(ctx, name, metav1.GetOptions{})
+	if err == nil {
+		return
+	}
+	log.Info(fmt.Sprintf("PVC %s/%s: provisioning without data source, PVC %s cannot be cloned: %v",
+		pvc.Namespace, pvc.Name, name, err))
+	pvc.Spec.DataSource = nil
+	pvc.Spec.DataSourceRef = nil
+}
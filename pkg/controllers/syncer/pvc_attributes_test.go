@@ -0,0 +1,138 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func attributesTestPVC() *corev1.PersistentVolumeClaim {
+	filesystem := corev1.PersistentVolumeFilesystem
+	vac := "fast"
+	snapshotGroup := "snapshot.storage.k8s.io"
+	pvc := newExpansionTestPVC("dst", "10Gi", "gp3")
+	pvc.Annotations = map[string]string{
+		"volume.kubernetes.io/storage-provisioner": "rbd.csi.ceph.com",
+		"rbd.csi.ceph.com/image-features":          "layering",
+		"ebs.csi.aws.com/iops":                     "6000",
+		"team.example.com/kms-key":                 "arn:aws:kms:key",
+		"app.example.com/owner":                    "shop",
+	}
+	pvc.Labels = map[string]string{
+		"topology.kubernetes.io/zone":   "eu-west-1a",
+		"topology.ebs.csi.aws.com/zone": "eu-west-1a",
+		"app":                           "db",
+	}
+	pvc.Spec.VolumeMode = &filesystem
+	pvc.Spec.VolumeAttributesClassName = &vac
+	pvc.Spec.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "db"}}
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{APIGroup: &snapshotGroup, Kind: "VolumeSnapshot", Name: "nightly"}
+	pvc.Spec.DataSourceRef = &corev1.TypedObjectReference{APIGroup: &snapshotGroup, Kind: "VolumeSnapshot", Name: "nightly"}
+	return pvc
+}
+
+func attributesTestClasses() volumeAttributes {
+	return volumeAttributes{
+		source: newStorageClassAttributes(&storagev1.StorageClass{Provisioner: "rbd.csi.ceph.com"}),
+		destination: newStorageClassAttributes(&storagev1.StorageClass{
+			Provisioner: "ebs.csi.aws.com",
+			Parameters:  map[string]string{"kmsKeyId": "${pvc.annotations['team.example.com/kms-key']}"},
+		}),
+	}
+}
+
+func TestApplyVolumeAttributes_Strip(t *testing.T) {
+	pvc := attributesTestPVC()
+	applyVolumeAttributes(pvc, &drv1alpha1.PVCConfig{}, attributesTestClasses())
+
+	assert.Equal(t, map[string]string{"app.example.com/owner": "shop"}, pvc.Annotations)
+	assert.Equal(t, map[string]string{"app": "db"}, pvc.Labels)
+	assert.Nil(t, pvc.Spec.VolumeMode)
+	assert.Nil(t, pvc.Spec.VolumeAttributesClassName)
+	assert.Nil(t, pvc.Spec.Selector)
+	assert.Nil(t, pvc.Spec.DataSource)
+	assert.Nil(t, pvc.Spec.DataSourceRef)
+
+	// Block volumes keep their volume mode
+	block := corev1.PersistentVolumeBlock
+	pvc = attributesTestPVC()
+	pvc.Spec.VolumeMode = &block
+	applyVolumeAttributes(pvc, nil, attributesTestClasses())
+	assert.Equal(t, &block, pvc.Spec.VolumeMode)
+}
+
+func TestApplyVolumeAttributes_Preserve(t *testing.T) {
+	pvc := attributesTestPVC()
+	applyVolumeAttributes(pvc, &drv1alpha1.PVCConfig{PreserveVolumeAttributes: true}, attributesTestClasses())
+
+	// Annotations only the source provisioner reads are dropped
+	assert.Equal(t, map[string]string{
+		"ebs.csi.aws.com/iops":     "6000",
+		"team.example.com/kms-key": "arn:aws:kms:key",
+		"app.example.com/owner":    "shop",
+	}, pvc.Annotations)
+	assert.Len(t, pvc.Labels, 3)
+	assert.Equal(t, corev1.PersistentVolumeFilesystem, *pvc.Spec.VolumeMode)
+	assert.Equal(t, "fast", *pvc.Spec.VolumeAttributesClassName)
+	assert.NotNil(t, pvc.Spec.Selector)
+
+	// Snapshots are not replicated
+	assert.Nil(t, pvc.Spec.DataSource)
+	assert.Nil(t, pvc.Spec.DataSourceRef)
+}
+
+func TestRemapDataSource(t *testing.T) {
+	pvc := attributesTestPVC()
+	pvc.Spec.DataSource = &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "db-template"}
+	pvc.Spec.DataSourceRef = &corev1.TypedObjectReference{Kind: "PersistentVolumeClaim", Name: "db-template"}
+
+	remapDataSource(pvc, []drv1alpha1.PVCMapping{{From: "db-template", To: "db-template-dr"}})
+	assert.Equal(t, "db-template-dr", pvc.Spec.DataSource.Name)
+	assert.Equal(t, "db-template-dr", pvc.Spec.DataSourceRef.Name)
+
+	// Clones from other namespaces cannot be remapped
+	other := "templates"
+	pvc.Spec.DataSourceRef.Namespace = &other
+	remapDataSource(pvc, nil)
+	assert.Nil(t, pvc.Spec.DataSource)
+	assert.Nil(t, pvc.Spec.DataSourceRef)
+}
+
+func TestSyncPVCs_PreserveVolumeAttributes(t *testing.T) {
+	ctx := context.Background()
+	source := newExpansionTestPVC("src", "10Gi", "ceph")
+	source.Annotations = map[string]string{
+		"volume.kubernetes.io/storage-provisioner": "rbd.csi.ceph.com",
+		"rbd.csi.ceph.com/image-features":          "layering",
+		"team.example.com/kms-key":                 "key-1",
+	}
+	source.Spec.DataSource = &corev1.TypedLocalObjectReference{Kind: "PersistentVolumeClaim", Name: "template"}
+	sourceClient := fake.NewSimpleClientset(source,
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "ceph"}, Provisioner: "rbd.csi.ceph.com"})
+	targetClient := fake.NewSimpleClientset(&storagev1.StorageClass{
+		ObjectMeta:  metav1.ObjectMeta{Name: "gp3"},
+		Provisioner: "ebs.csi.aws.com",
+		Parameters:  map[string]string{"kmsKeyId": "${pvc.annotations['team.example.com/kms-key']}"},
+	})
+
+	pvcConfig := &drv1alpha1.PVCConfig{
+		PreserveVolumeAttributes: true,
+		StorageClassMappings:     []drv1alpha1.StorageClassMapping{{From: "ceph", To: "gp3"}},
+	}
+	err := syncPersistentVolumeClaimsWithMounting(ctx, nil, sourceClient, targetClient, "src", "dst", pvcConfig, nil)
+	require.NoError(t, err)
+
+	pvc, err := targetClient.CoreV1().PersistentVolumeClaims("dst").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "key-1", pvc.Annotations["team.example.com/kms-key"])
+	assert.NotContains(t, pvc.Annotations, "rbd.csi.ceph.com/image-features")
+	assert.NotContains(t, pvc.Annotations, "volume.kubernetes.io/storage-provisioner")
+	assert.Nil(t, pvc.Spec.DataSource, "the cloned PVC does not exist in the destination")
+}
@@ -0,0 +1,196 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// EventReasonPVCExpansionNotSupported is recorded when a destination PVC cannot be expanded
+	EventReasonPVCExpansionNotSupported = "PVCExpansionNotSupported"
+
+	// EventReasonPVCRecreatedForExpansion is recorded when a destination PVC was recreated at a larger size
+	EventReasonPVCRecreatedForExpansion = "PVCRecreatedForExpansion"
+
+	// expansionBlockedAnnotation holds the storage request a destination PVC could not be expanded to
+	expansionBlockedAnnotation = "dr-syncer.io/expansion-blocked"
+)
+
+// pvcDeletionTimeout bounds how long to wait for a destination PVC to be removed before recreating it
+var pvcDeletionTimeout = 2 * time.Minute
+
+// pvcNeedsExpansion reports whether the desired storage request is larger than the existing one
+func pvcNeedsExpansion(existing, desired *corev1.PersistentVolumeClaim) bool {
+	want, ok := desired.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return false
+	}
+	current, ok := existing.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok {
+		return true
+	}
+	return want.Cmp(current) > 0
+}
+
+// storageClassAllowsExpansion checks allowVolumeExpansion on the storage class of a destination PVC.
+// PVCs without an explicit storage class are assumed to be expandable and left to the API server.
+func storageClassAllowsExpansion(ctx context.Context, client kubernetes.Interface, pvc *corev1.PersistentVolumeClaim) (bool, string, error) {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return true, "", nil
+	}
+
+	storageClassName := *pvc.Spec.StorageClassName
+	sc, err := client.StorageV1().StorageClasses().Get(ctx, storageClassName, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, fmt.Sprintf("storage class %s does not exist in the destination cluster", storageClassName), nil
+		}
+		return false, "", fmt.Errorf("failed to get storage class %s: %w", storageClassName, err)
+	}
+
+	if sc.AllowVolumeExpansion == nil || !*sc.AllowVolumeExpansion {
+		return false, fmt.Sprintf("storage class %s does not allow volume expansion", storageClassName), nil
+	}
+	return true, "", nil
+}
+
+// prepareNewPVC clears fields of a copied source PVC that must not be carried over when creating it
+// in the destination cluster
+func prepareNewPVC(destPVC *corev1.PersistentVolumeClaim, pvcConfig *drv1alpha1.PVCConfig, syncPV bool, attributes volumeAttributes) {
+	// For new PVCs, clear volumeName to allow dynamic provisioning in destination cluster
+	if !syncPV {
+		destPVC.Spec.VolumeName = ""
+	}
+
+	// Clear binding annotations that might cause issues
+	if destPVC.Annotations == nil {
+		destPVC.Annotations = make(map[string]string)
+	}
+	delete(destPVC.Annotations, "pv.kubernetes.io/bind-completed")
+	delete(destPVC.Annotations, "pv.kubernetes.io/bound-by-controller")
+	delete(destPVC.Annotations, "volume.kubernetes.io/selected-node")
+
+	// Keep or strip volume attributes as preserveVolumeAttributes asks, synced PVs keep them all
+	if !syncPV {
+		applyVolumeAttributes(destPVC, pvcConfig, attributes)
+	}
+
+	// Clear resourceVersion before creating
+	destPVC.ResourceVersion = ""
+	destPVC.UID = ""
+}
+
+// recreatePVCForExpansion deletes a destination PVC that cannot be expanded in place and creates
+// it again with the desired spec
+func recreatePVCForExpansion(ctx context.Context, targetClient kubernetes.Interface, existingPVC, destPVC *corev1.PersistentVolumeClaim,
+	pvcConfig *drv1alpha1.PVCConfig, syncPV bool, attributes volumeAttributes) (*corev1.PersistentVolumeClaim, error) {
+
+	namespace := existingPVC.Namespace
+	log.Info(fmt.Sprintf("recreating PVC %s/%s to expand it from %s to %s", namespace, existingPVC.Name,
+		storageRequest(existingPVC), storageRequest(destPVC)))
+
+	err := targetClient.CoreV1().PersistentVolumeClaims(namespace).Delete(ctx, existingPVC.Name, metav1.DeleteOptions{})
+	if !apierrors.IsNotFound(err) {
+		audit.Record(ctx, audit.OperationDelete, audit.ObjectRef(pvcGVK, existingPVC), "", err)
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		return nil, fmt.Errorf("failed to delete PVC %s for recreation: %w", existingPVC.Name, err)
+	}
+
+	// Wait for the PVC to be gone, the pvc-protection finalizer holds it while pods still use it
+	err = wait.PollUntilContextTimeout(ctx, 2*time.Second, pvcDeletionTimeout, true, func(ctx context.Context) (bool, error) {
+		_, err := targetClient.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, existingPVC.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("timed out waiting for PVC %s to be deleted, it may still be in use: %w", existingPVC.Name, err)
+	}
+
+	newPVC := destPVC.DeepCopy()
+	prepareNewPVC(newPVC, pvcConfig, syncPV, attributes)
+	dropMissingDataSource(ctx, targetClient, newPVC)
+
+	createdPVC, err := targetClient.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, newPVC, metav1.CreateOptions{})
+	audit.Record(ctx, audit.OperationCreate, audit.ObjectRef(pvcGVK, newPVC), audit.DiffObjects(existingPVC, newPVC), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recreate PVC %s: %w", newPVC.Name, err)
+	}
+
+	recordPVCEvent(ctx, targetClient, createdPVC, corev1.EventTypeNormal, EventReasonPVCRecreatedForExpansion,
+		fmt.Sprintf("Recreated PVC with storage request %s because its storage class does not allow volume expansion", storageRequest(createdPVC)))
+
+	return createdPVC, nil
+}
+
+// markExpansionBlocked records that a destination PVC cannot be expanded to the storage request of its
+// source PVC. The blocked request is kept in an annotation, so the PVCExpansionNotSupported event is
+// recorded once per blocked request rather than on every sync.
+func markExpansionBlocked(ctx context.Context, targetClient kubernetes.Interface, pvc *corev1.PersistentVolumeClaim, requested, message string) {
+	if pvc.Annotations[expansionBlockedAnnotation] == requested {
+		return
+	}
+
+	updated := pvc.DeepCopy()
+	if updated.Annotations == nil {
+		updated.Annotations = make(map[string]string)
+	}
+	updated.Annotations[expansionBlockedAnnotation] = requested
+	_, err := targetClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(ctx, updated, metav1.UpdateOptions{})
+	audit.Record(ctx, audit.OperationUpdate, audit.ObjectRef(pvcGVK, updated), audit.DiffObjects(pvc, updated), err)
+	if err != nil {
+		// The event is recorded by the next sync that marks the PVC
+		log.Error(fmt.Sprintf("failed to mark PVC %s/%s as blocked from expansion: %v", pvc.Namespace, pvc.Name, err))
+		return
+	}
+
+	recordPVCEvent(ctx, targetClient, pvc, corev1.EventTypeWarning, EventReasonPVCExpansionNotSupported, message)
+}
+
+// storageRequest returns the storage request of a PVC as a string for logging
+func storageRequest(pvc *corev1.PersistentVolumeClaim) string {
+	if request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; ok {
+		return request.String()
+	}
+	return "<unset>"
+}
+
+// recordPVCEvent records an event on a destination PVC
+func recordPVCEvent(ctx context.Context, targetClient kubernetes.Interface, pvc *corev1.PersistentVolumeClaim, eventType, reason, message string) {
+	event := &corev1.Event{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("%s.%s", pvc.Name, time.Now().Format("20060102150405")),
+			Namespace: pvc.Namespace,
+		},
+		InvolvedObject: corev1.ObjectReference{
+			APIVersion: "v1",
+			Kind:       "PersistentVolumeClaim",
+			Name:       pvc.Name,
+			Namespace:  pvc.Namespace,
+			UID:        pvc.UID,
+		},
+		Reason:         reason,
+		Message:        message,
+		Type:           eventType,
+		Source:         corev1.EventSource{Component: "dr-syncer"},
+		FirstTimestamp: metav1.Now(),
+		LastTimestamp:  metav1.Now(),
+		Count:          1,
+	}
+
+	if _, err := targetClient.CoreV1().Events(pvc.Namespace).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		// Log but don't fail - event recording is informational
+		log.Error(fmt.Sprintf("failed to record event for PVC %s/%s: %v", pvc.Namespace, pvc.Name, err))
+	}
+}
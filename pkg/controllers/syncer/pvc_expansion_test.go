@@ -0,0 +1,184 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newExpansionTestPVC(namespace, size, storageClass string) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: namespace},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func newExpansionTestStorageClass(name string, allowExpansion bool) *storagev1.StorageClass {
+	return &storagev1.StorageClass{
+		ObjectMeta:           metav1.ObjectMeta{Name: name},
+		Provisioner:          "example.com/csi",
+		AllowVolumeExpansion: &allowExpansion,
+	}
+}
+
+func pvcEventReasons(t *testing.T, client *fake.Clientset, namespace string) []string {
+	events, err := client.CoreV1().Events(namespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var reasons []string
+	for _, event := range events.Items {
+		reasons = append(reasons, event.Reason)
+	}
+	return reasons
+}
+
+func TestPVCNeedsExpansion(t *testing.T) {
+	existing := newExpansionTestPVC("dst", "10Gi", "standard")
+
+	assert.True(t, pvcNeedsExpansion(existing, newExpansionTestPVC("dst", "20Gi", "standard")))
+	assert.False(t, pvcNeedsExpansion(existing, newExpansionTestPVC("dst", "10Gi", "standard")))
+	assert.False(t, pvcNeedsExpansion(existing, newExpansionTestPVC("dst", "5Gi", "standard")))
+
+	noRequest := existing.DeepCopy()
+	noRequest.Spec.Resources.Requests = nil
+	assert.False(t, pvcNeedsExpansion(existing, noRequest))
+}
+
+func TestStorageClassAllowsExpansion(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset(
+		newExpansionTestStorageClass("expandable", true),
+		newExpansionTestStorageClass("fixed", false),
+	)
+
+	allowed, _, err := storageClassAllowsExpansion(ctx, client, newExpansionTestPVC("dst", "10Gi", "expandable"))
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	allowed, reason, err := storageClassAllowsExpansion(ctx, client, newExpansionTestPVC("dst", "10Gi", "fixed"))
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "does not allow volume expansion")
+
+	allowed, reason, err = storageClassAllowsExpansion(ctx, client, newExpansionTestPVC("dst", "10Gi", "missing"))
+	require.NoError(t, err)
+	assert.False(t, allowed)
+	assert.Contains(t, reason, "does not exist")
+
+	allowed, _, err = storageClassAllowsExpansion(ctx, client, newExpansionTestPVC("dst", "10Gi", ""))
+	require.NoError(t, err)
+	assert.True(t, allowed, "PVCs without a storage class are left to the API server")
+}
+
+func TestSyncPVCs_ExpansionNotSupported(t *testing.T) {
+	ctx := context.Background()
+	sourceClient := fake.NewSimpleClientset(newExpansionTestPVC("src", "20Gi", "fixed"))
+	targetClient := fake.NewSimpleClientset(
+		newExpansionTestStorageClass("fixed", false),
+		newExpansionTestPVC("dst", "10Gi", "fixed"),
+	)
+
+	err := syncPersistentVolumeClaimsWithMounting(ctx, nil, sourceClient, targetClient, "src", "dst", &drv1alpha1.PVCConfig{}, nil)
+	require.NoError(t, err)
+
+	pvc, err := targetClient.CoreV1().PersistentVolumeClaims("dst").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "10Gi", storageRequest(pvc), "PVC should keep its size")
+	assert.Contains(t, pvcEventReasons(t, targetClient, "dst"), EventReasonPVCExpansionNotSupported)
+}
+
+func TestSyncPVCs_ExpansionBlockedOnce(t *testing.T) {
+	ctx := context.Background()
+	sourceClient := fake.NewSimpleClientset(newExpansionTestPVC("src", "20Gi", "fixed"))
+	targetClient := fake.NewSimpleClientset(
+		newExpansionTestStorageClass("fixed", false),
+		newExpansionTestPVC("dst", "10Gi", "fixed"),
+	)
+	eventCreates := func() int {
+		count := 0
+		for _, action := range targetClient.Actions() {
+			if action.GetVerb() == "create" && action.GetResource().Resource == "events" {
+				count++
+			}
+		}
+		return count
+	}
+
+	// The blocked expansion is reported by every sync, its warning event only by the first
+	for i := 0; i < 3; i++ {
+		syncer := &ResourceSyncer{}
+		err := syncPersistentVolumeClaimsWithMounting(ctx, syncer, sourceClient, targetClient, "src", "dst", &drv1alpha1.PVCConfig{}, nil)
+		require.NoError(t, err)
+		require.Len(t, syncer.expansionBlocked, 1)
+		assert.Contains(t, syncer.expansionBlocked[0], "PVC data: Cannot expand PVC from 10Gi to 20Gi")
+	}
+	assert.Equal(t, 1, eventCreates())
+	pvc, err := targetClient.CoreV1().PersistentVolumeClaims("dst").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "20Gi", pvc.Annotations[expansionBlockedAnnotation])
+
+	// A larger request is blocked again
+	_, err = sourceClient.CoreV1().PersistentVolumeClaims("src").Update(ctx, newExpansionTestPVC("src", "30Gi", "fixed"), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, syncPersistentVolumeClaimsWithMounting(ctx, nil, sourceClient, targetClient, "src", "dst", &drv1alpha1.PVCConfig{}, nil))
+	assert.Equal(t, 2, eventCreates())
+
+	// Once the PVC can be expanded, it is no longer marked
+	_, err = targetClient.StorageV1().StorageClasses().Update(ctx, newExpansionTestStorageClass("fixed", true), metav1.UpdateOptions{})
+	require.NoError(t, err)
+	syncer := &ResourceSyncer{}
+	require.NoError(t, syncPersistentVolumeClaimsWithMounting(ctx, syncer, sourceClient, targetClient, "src", "dst", &drv1alpha1.PVCConfig{}, nil))
+	assert.Empty(t, syncer.expansionBlocked)
+	pvc, err = targetClient.CoreV1().PersistentVolumeClaims("dst").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "30Gi", storageRequest(pvc))
+	assert.NotContains(t, pvc.Annotations, expansionBlockedAnnotation)
+}
+
+func TestSyncPVCs_ExpansionSupported(t *testing.T) {
+	ctx := context.Background()
+	sourceClient := fake.NewSimpleClientset(newExpansionTestPVC("src", "20Gi", "expandable"))
+	targetClient := fake.NewSimpleClientset(
+		newExpansionTestStorageClass("expandable", true),
+		newExpansionTestPVC("dst", "10Gi", "expandable"),
+	)
+
+	err := syncPersistentVolumeClaimsWithMounting(ctx, nil, sourceClient, targetClient, "src", "dst", &drv1alpha1.PVCConfig{}, nil)
+	require.NoError(t, err)
+
+	pvc, err := targetClient.CoreV1().PersistentVolumeClaims("dst").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "20Gi", storageRequest(pvc))
+	assert.Empty(t, pvcEventReasons(t, targetClient, "dst"))
+}
+
+func TestSyncPVCs_RecreateOnExpansionFailure(t *testing.T) {
+	ctx := context.Background()
+	existing := newExpansionTestPVC("dst", "10Gi", "fixed")
+	existing.Spec.VolumeName = "pv-old"
+	sourceClient := fake.NewSimpleClientset(newExpansionTestPVC("src", "20Gi", "fixed"))
+	targetClient := fake.NewSimpleClientset(newExpansionTestStorageClass("fixed", false), existing)
+
+	pvcConfig := &drv1alpha1.PVCConfig{RecreateOnExpansionFailure: true}
+	err := syncPersistentVolumeClaimsWithMounting(ctx, nil, sourceClient, targetClient, "src", "dst", pvcConfig, nil)
+	require.NoError(t, err)
+
+	pvc, err := targetClient.CoreV1().PersistentVolumeClaims("dst").Get(ctx, "data", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "20Gi", storageRequest(pvc))
+	assert.Empty(t, pvc.Spec.VolumeName, "recreated PVC should be dynamically provisioned")
+	assert.Contains(t, pvcEventReasons(t, targetClient, "dst"), EventReasonPVCRecreatedForExpansion)
+}
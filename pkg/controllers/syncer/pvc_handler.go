@@ -3,10 +3,13 @@ package syncer
 import (
 	"context"
 	"fmt"
+	"reflect"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
 	controller "github.com/supporttools/dr-syncer/pkg/controller/replication"
 	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/syncer/validation"
 	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -17,6 +20,12 @@ import (
 var (
 	// Explicitly import and use the PVCClusterKey from the replication package
 	pvcClusterKey = controller.PVCClusterKey
+
+	// pvcGVK identifies PersistentVolumeClaims in audit records
+	pvcGVK = corev1.SchemeGroupVersion.WithKind("PersistentVolumeClaim")
+
+	// pvcGVR identifies PersistentVolumeClaims in backups
+	pvcGVR = corev1.SchemeGroupVersion.WithResource("persistentvolumeclaims")
 )
 
 // syncPersistentVolumeClaimsWithMounting synchronizes PVCs between namespaces
@@ -26,28 +35,52 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 
 	log.Info(fmt.Sprintf("Syncing persistent volume claims from %s to %s", srcNamespace, dstNamespace))
 
-	// Get PVCs from source namespace
-	pvcs, err := sourceClient.CoreV1().PersistentVolumeClaims(srcNamespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return syncerrors.NewRetryableError(
-			fmt.Errorf("failed to list PVCs: %w", err),
-			"PersistentVolumeClaims",
-		)
-	}
-
 	// Track synced PVCs for data synchronization
 	var syncedPVCs []corev1.PersistentVolumeClaim
 
-	// Process each PVC
-	for _, pvc := range pvcs.Items {
-		if utils.ShouldIgnoreResource(&pvc) {
-			continue
+	// Source PVC names keyed by destination name, PVC mappings may rename them
+	sourcePVCNames := make(map[string]string)
+
+	var pvcMappings []drv1alpha1.PVCMapping
+	if pvcConfig != nil {
+		pvcMappings = pvcConfig.PVCMappings
+	}
+
+	// The volume attribute annotations of the StorageClasses, read once per sync
+	storageClasses := make(map[string]storageClassAttributes)
+	storageClassAttributesOf := func(client kubernetes.Interface, cluster string, name *string) storageClassAttributes {
+		if name == nil {
+			return storageClassAttributes{}
 		}
+		key := cluster + "/" + *name
+		if attributes, ok := storageClasses[key]; ok {
+			return attributes
+		}
+		attributes := loadStorageClassAttributes(ctx, client, name)
+		storageClasses[key] = attributes
+		return attributes
+	}
 
+	// syncPVC creates or updates the destination PVC of a source PVC
+	syncPVC := func(pvc corev1.PersistentVolumeClaim) error {
 		// Copy the PVC for the destination namespace
 		destPVC := pvc.DeepCopy()
 		destPVC.Namespace = dstNamespace
 
+		// Apply PVC name mapping if configured
+		destName, err := controller.DestinationPVCName(pvcMappings, pvc.Name)
+		if err != nil {
+			return syncerrors.NewNonRetryableError(
+				fmt.Errorf("failed to map PVC %s: %w", pvc.Name, err),
+				fmt.Sprintf("PersistentVolumeClaim/%s", pvc.Name),
+			)
+		}
+		if destName != pvc.Name {
+			log.Info(fmt.Sprintf("Mapping PVC %s to %s in namespace %s", pvc.Name, destName, dstNamespace))
+			destPVC.Name = destName
+		}
+		sourcePVCNames[destName] = pvc.Name
+
 		// Apply storage class mapping if configured
 		if pvcConfig != nil && len(pvcConfig.StorageClassMappings) > 0 {
 			// Check if PVC has a storage class override label
@@ -67,15 +100,7 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 		}
 
 		// Apply access mode mapping if configured
-		if pvcConfig != nil && len(pvcConfig.AccessModeMappings) > 0 {
-			for _, mapping := range pvcConfig.AccessModeMappings {
-				for i, mode := range destPVC.Spec.AccessModes {
-					if string(mode) == mapping.From {
-						destPVC.Spec.AccessModes[i] = corev1.PersistentVolumeAccessMode(mapping.To)
-					}
-				}
-			}
-		}
+		mapAccessModes(destPVC, pvcConfig)
 
 		// Handle volume attributes and PV syncing
 		syncPV := false
@@ -83,39 +108,32 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 			syncPV = pvcConfig.SyncPersistentVolumes
 		}
 
+		// Volume attributes are kept or stripped according to the source and destination StorageClasses
+		attributes := volumeAttributes{
+			source:      storageClassAttributesOf(sourceClient, "source", pvc.Spec.StorageClassName),
+			destination: storageClassAttributesOf(targetClient, "destination", destPVC.Spec.StorageClassName),
+		}
+
 		// Check if PVC already exists in destination cluster
 		existingPVC, err := targetClient.CoreV1().PersistentVolumeClaims(dstNamespace).Get(ctx, destPVC.Name, metav1.GetOptions{})
 		pvcExists := err == nil
 
 		if !pvcExists {
-			// For new PVCs, clear volumeName to allow dynamic provisioning in destination cluster
-			if !syncPV {
-				destPVC.Spec.VolumeName = ""
-			}
+			prepareNewPVC(destPVC, pvcConfig, syncPV, attributes)
+			dropMissingDataSource(ctx, targetClient, destPVC)
 
-			// Clear binding annotations that might cause issues
-			if destPVC.Annotations == nil {
-				destPVC.Annotations = make(map[string]string)
-			}
-			delete(destPVC.Annotations, "pv.kubernetes.io/bind-completed")
-			delete(destPVC.Annotations, "pv.kubernetes.io/bound-by-controller")
-			delete(destPVC.Annotations, "volume.kubernetes.io/selected-node")
-
-			// Clear volume attributes if PreserveVolumeAttributes is false
-			if (pvcConfig == nil || !pvcConfig.PreserveVolumeAttributes) && !syncPV {
-				destPVC.Spec.VolumeMode = nil
-				destPVC.Spec.Selector = nil
-				destPVC.Spec.DataSource = nil
-				destPVC.Spec.DataSourceRef = nil
+			// The destination storage must be able to provision the PVC as it will be created
+			if err := validation.PreflightPVC(ctx, targetClient, destPVC); err != nil {
+				return err
 			}
+			syncer.sanitize(destPVC)
+			syncer.labelSynced(destPVC)
 
 			// Create the PVC in the destination cluster
 			log.Info(fmt.Sprintf("Creating new PVC %s in namespace %s", destPVC.Name, dstNamespace))
 
-			// Clear resourceVersion before creating
-			destPVC.ResourceVersion = ""
-
 			createdPVC, err := targetClient.CoreV1().PersistentVolumeClaims(dstNamespace).Create(ctx, destPVC, metav1.CreateOptions{})
+			audit.Record(ctx, audit.OperationCreate, audit.ObjectRef(pvcGVK, destPVC), "", err)
 			if err != nil {
 				return syncerrors.NewRetryableError(
 					fmt.Errorf("failed to create PVC %s: %w", destPVC.Name, err),
@@ -123,21 +141,70 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 				)
 			}
 
+			syncer.expectSynced(pvcGVR, pvcGVK.Kind, destPVC)
+
 			// Add to synced PVCs list for data sync
 			syncedPVCs = append(syncedPVCs, *createdPVC)
 		} else {
 			// For existing PVCs, we need to be careful with immutable fields
 			log.Info(fmt.Sprintf("PVC %s already exists in namespace %s", destPVC.Name, dstNamespace))
 
+			if err := syncer.checkOwnership(ctx, pvcGVK.Kind, existingPVC); err != nil {
+				return err
+			}
+
+			// Access modes are immutable, a changed mapping only applies to recreated PVCs
+			if !reflect.DeepEqual(existingPVC.Spec.AccessModes, destPVC.Spec.AccessModes) {
+				log.Warn(fmt.Sprintf("PVC %s/%s has access modes %v, keeping them instead of %v",
+					dstNamespace, destPVC.Name, existingPVC.Spec.AccessModes, destPVC.Spec.AccessModes))
+			}
+
+			// Growing a PVC requires the destination storage class to allow expansion
+			if pvcNeedsExpansion(existingPVC, destPVC) {
+				allowed, reason, err := storageClassAllowsExpansion(ctx, targetClient, existingPVC)
+				if err != nil {
+					return syncerrors.NewRetryableError(err, fmt.Sprintf("PersistentVolumeClaim/%s", destPVC.Name))
+				}
+				if !allowed {
+					if pvcConfig != nil && pvcConfig.RecreateOnExpansionFailure {
+						recreatedPVC, err := recreatePVCForExpansion(ctx, targetClient, existingPVC, destPVC, pvcConfig, syncPV, attributes)
+						if err != nil {
+							return syncerrors.NewRetryableError(err, fmt.Sprintf("PersistentVolumeClaim/%s", destPVC.Name))
+						}
+						syncedPVCs = append(syncedPVCs, *recreatedPVC)
+						return nil
+					}
+
+					message := fmt.Sprintf("Cannot expand PVC from %s to %s: %s; set pvcConfig.recreateOnExpansionFailure to recreate it",
+						storageRequest(existingPVC), storageRequest(destPVC), reason)
+					log.Warn(fmt.Sprintf("PVC %s/%s: %s", dstNamespace, destPVC.Name, message))
+					markExpansionBlocked(ctx, targetClient, existingPVC, storageRequest(destPVC), message)
+					syncer.recordExpansionBlocked(fmt.Sprintf("PVC %s: %s", destPVC.Name, message))
+
+					// Keep the current size and continue syncing data into the existing volume
+					syncedPVCs = append(syncedPVCs, *existingPVC)
+					return nil
+				}
+			}
+
 			// Only update mutable fields
 			updatePVC := existingPVC.DeepCopy()
 
 			// Update resources.requests (mutable field)
 			updatePVC.Spec.Resources = destPVC.Spec.Resources
+			delete(updatePVC.Annotations, expansionBlockedAnnotation)
+			syncer.labelSynced(updatePVC)
+
+			if !reflect.DeepEqual(existingPVC.Spec.Resources, updatePVC.Spec.Resources) {
+				if err := syncer.backupPVCBeforeUpdate(ctx, existingPVC); err != nil {
+					return syncerrors.NewRetryableError(err, fmt.Sprintf("PersistentVolumeClaim/%s", destPVC.Name))
+				}
+			}
 
 			// Update the PVC in the destination cluster
 			log.Info(fmt.Sprintf("Updating existing PVC %s in namespace %s", destPVC.Name, dstNamespace))
 			updatedPVC, err := targetClient.CoreV1().PersistentVolumeClaims(dstNamespace).Update(ctx, updatePVC, metav1.UpdateOptions{})
+			syncer.recordUpdate(ctx, pvcGVK, updatePVC, audit.DiffObjects(existingPVC, updatePVC), err)
 			if err != nil {
 				return syncerrors.NewRetryableError(
 					fmt.Errorf("failed to update PVC %s: %w", destPVC.Name, err),
@@ -145,19 +212,43 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 				)
 			}
 
+			syncer.expectSynced(pvcGVR, pvcGVK.Kind, updatePVC)
+
 			// Add to synced PVCs list for data sync
 			syncedPVCs = append(syncedPVCs, *updatedPVC)
 		}
+		return nil
+	}
+
+	// Process each PVC, a PVC that fails to sync does not stop the others
+	err := eachSourcePage(ctx, pvcGVR, srcNamespace, "PersistentVolumeClaims", func(opts metav1.ListOptions) (*corev1.PersistentVolumeClaimList, error) {
+		return sourceClient.CoreV1().PersistentVolumeClaims(srcNamespace).List(ctx, opts)
+	}, func(pvcs *corev1.PersistentVolumeClaimList) error {
+		for _, pvc := range pvcs.Items {
+			// Ignored PVCs report why their data is not synced
+			if pvcConfig != nil && pvcConfig.SyncData && utils.ShouldIgnoreResource(&pvc) {
+				controller.RecordExcludedPVC(ctx, sourceClient, &pvc, dstNamespace)
+			}
+			if syncer.shouldSkip(&pvc) || !syncer.selected("PersistentVolumeClaim", pvc.Name) {
+				continue
+			}
+			syncer.recordResult("PersistentVolumeClaim", pvc.Name, syncPVC(pvc))
+		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
 
 	// Log PVC config details for debugging
 	if pvcConfig != nil {
-		log.Info(fmt.Sprintf("PVC config: SyncData=%v, SyncPersistentVolumes=%v, PreserveVolumeAttributes=%v, StorageClassMappings=%d, AccessModeMappings=%d",
+		log.Info(fmt.Sprintf("PVC config: SyncData=%v, SyncPersistentVolumes=%v, PreserveVolumeAttributes=%v, StorageClassMappings=%d, AccessModeMappings=%d, PVCMappings=%d",
 			pvcConfig.SyncData,
 			pvcConfig.SyncPersistentVolumes,
 			pvcConfig.PreserveVolumeAttributes,
 			len(pvcConfig.StorageClassMappings),
-			len(pvcConfig.AccessModeMappings)))
+			len(pvcConfig.AccessModeMappings),
+			len(pvcConfig.PVCMappings)))
 
 		if pvcConfig.DataSyncConfig != nil {
 			log.Info(fmt.Sprintf("PVC data sync config: ConcurrentSyncs=%v, ExcludePaths=%v, RsyncOptions=%v",
@@ -188,9 +279,11 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 			log.Info(fmt.Sprintf("Processing PVC %d of %d: %s/%s", i+1, len(syncedPVCs), destPVC.Namespace, destPVC.Name))
 
 			// Get source PVC
-			sourcePVC, err := sourceClient.CoreV1().PersistentVolumeClaims(srcNamespace).Get(ctx, destPVC.Name, metav1.GetOptions{})
+			sourceName := sourcePVCNames[destPVC.Name]
+			sourcePVC, err := sourceClient.CoreV1().PersistentVolumeClaims(srcNamespace).Get(ctx, sourceName, metav1.GetOptions{})
 			if err != nil {
-				log.Errorf("Failed to get source PVC %s/%s: %v", srcNamespace, destPVC.Name, err)
+				log.Errorf("Failed to get source PVC %s/%s: %v", srcNamespace, sourceName, err)
+				syncer.recordPVCData(err)
 				continue
 			}
 			log.Info(fmt.Sprintf("Found source PVC %s/%s (phase: %s, volumeName: %s)",
@@ -202,18 +295,17 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 
 			// Find nodes where PVCs are mounted - this will now succeed because we've mounted them
 			log.Info(fmt.Sprintf("Finding node for source PVC %s/%s", srcNamespace, sourcePVC.Name))
-			fmt.Println("## FindPVCNode - Source PVC")
 
 			// Create a modified context with the correct configuration for source cluster
 			srcCtx := context.WithValue(ctx, pvcClusterKey, "source")
 			sourceNode, err := pvcSyncer.FindPVCNode(srcCtx, pvcSyncer.SourceClient, srcNamespace, sourcePVC.Name)
 			if err != nil {
-				log.Errorf("Failed to find node for source PVC %s/%s: %v", srcNamespace, sourcePVC.Name, err)
-				continue
+				// An unmounted source PVC is skipped with its reason or mounted by the data sync itself
+				log.Info(fmt.Sprintf("No node found for source PVC %s/%s: %v", srcNamespace, sourcePVC.Name, err))
+				sourceNode = ""
 			}
 
 			log.Info(fmt.Sprintf("Finding node for destination PVC %s/%s", dstNamespace, destPVC.Name))
-			fmt.Println("## FindPVCNode - Destination PVC")
 
 			// Create a modified context with the correct configuration for destination cluster
 			destCtx := context.WithValue(ctx, pvcClusterKey, "destination")
@@ -243,30 +335,29 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 			// Sync PVC data
 			log.Info(fmt.Sprintf("Starting data sync for PVC %s from %s to %s", destPVC.Name, srcNamespace, dstNamespace))
 
-			// Create a dummy namespace mapping object with just the name
+			// Create a namespace mapping object carrying the PVC config, which holds the sync timeout
 			dummyMapping := &drv1alpha1.NamespaceMapping{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: fmt.Sprintf("pvc-sync-%s", destPVC.Name),
 				},
+				Spec: drv1alpha1.NamespaceMappingSpec{PVCConfig: pvcConfig},
 			}
 
-			// Acquire global concurrency slot before syncing
-			gcm := controller.GetGlobalConcurrencyManager()
-			if gcm != nil {
-				if err := gcm.Acquire(ctx, srcNamespace, sourcePVC.Name); err != nil {
-					log.Errorf("Failed to acquire concurrency slot for PVC %s/%s: %v", srcNamespace, sourcePVC.Name, err)
-					continue
-				}
+			// Acquire the concurrency slots of the mapping's clusters and the global slot before syncing
+			release, err := controller.AcquireDataSyncSlots(ctx, syncer.dataSyncLimits, srcNamespace, sourcePVC.Name)
+			if err != nil {
+				log.Errorf("Failed to acquire concurrency slot for PVC %s/%s: %v", srcNamespace, sourcePVC.Name, err)
+				syncer.recordPVCData(err)
+				continue
 			}
 
 			// Perform the actual data synchronization using rsync deployment
 			syncErr := pvcSyncer.SyncPVCWithNamespaceMapping(ctx, dummyMapping, syncOpts)
 
-			// Release concurrency slot after sync completes
-			if gcm != nil {
-				gcm.Release(srcNamespace, sourcePVC.Name)
-			}
+			// Release the concurrency slots after sync completes
+			release()
 
+			syncer.recordPVCData(syncErr)
 			if syncErr != nil {
 				log.Errorf("Failed to sync data for PVC %s: %v", destPVC.Name, syncErr)
 			} else {
@@ -285,3 +376,14 @@ func syncPersistentVolumeClaimsWithMounting(ctx context.Context, syncer *Resourc
 
 	return nil
 }
+
+// rewritePVCVolumes points PVC volumes of a workload pod template at their mapped destination names
+func (r *ResourceSyncer) rewritePVCVolumes(spec *corev1.PodSpec, kind, name string) error {
+	if err := controller.RewritePodSpecPVCNames(spec, r.pvcMappings); err != nil {
+		return syncerrors.NewNonRetryableError(
+			fmt.Errorf("failed to map PVC volumes of %s %s: %w", kind, name, err),
+			fmt.Sprintf("%s/%s", kind, name),
+		)
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// scaleQuantity returns percent percent of q, rounded up to whole units or, with whole false, to millis
+func scaleQuantity(q resource.Quantity, percent int64, whole bool) resource.Quantity {
+	value, scale := q.MilliValue(), resource.Milli
+	if whole {
+		value, scale = q.Value(), 0
+	}
+
+	scaled := new(big.Int).Mul(big.NewInt(value), big.NewInt(percent))
+	scaled.Add(scaled, big.NewInt(99))
+	scaled.Div(scaled, big.NewInt(100))
+	if !scaled.IsInt64() {
+		scaled.SetInt64(math.MaxInt64)
+	}
+
+	result := resource.NewScaledQuantity(scaled.Int64(), scale)
+	result.Format = q.Format
+	return *result
+}
+
+// isFractionalResource reports whether quantities of a resource name such as "requests.cpu" may be
+// fractional. Only CPU is, memory and storage are rounded to whole bytes and counts to whole objects.
+func isFractionalResource(name corev1.ResourceName) bool {
+	base := string(name)
+	if i := strings.LastIndex(base, "."); i >= 0 {
+		base = base[i+1:]
+	}
+	return base == string(corev1.ResourceCPU)
+}
+
+// scaleResourceList scales the quantities of a resource list in place, skipping the unscaled resources
+func (r *ResourceSyncer) scaleResourceList(list corev1.ResourceList) {
+	percent := r.quotaPercent()
+	if percent == 100 {
+		return
+	}
+	for name, q := range list {
+		if r.unscaledResource(name) {
+			continue
+		}
+		list[name] = scaleQuantity(q, percent, !isFractionalResource(name))
+	}
+}
+
+// quotaPercent returns the percentage the quotas and limit ranges of the mapping are scaled by
+func (r *ResourceSyncer) quotaPercent() int64 {
+	if r.quotaScaling == nil || r.quotaScaling.Percent <= 0 {
+		return 100
+	}
+	return int64(r.quotaScaling.Percent)
+}
+
+// unscaledResource reports whether a resource is listed in the unscaled resources of the mapping
+func (r *ResourceSyncer) unscaledResource(name corev1.ResourceName) bool {
+	for _, unscaled := range r.quotaScaling.UnscaledResources {
+		if unscaled == string(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// scaleResourceQuota scales the hard limits of a ResourceQuota and clears the usage of the source cluster
+func (r *ResourceSyncer) scaleResourceQuota(quota *corev1.ResourceQuota) {
+	quota.Status = corev1.ResourceQuotaStatus{}
+	r.scaleResourceList(quota.Spec.Hard)
+}
+
+// scaleLimitRange scales the minimum, maximum and default values of a LimitRange. The limit to request
+// ratios are kept, scaling both sides alike.
+func (r *ResourceSyncer) scaleLimitRange(limitRange *corev1.LimitRange) {
+	for i := range limitRange.Spec.Limits {
+		limit := &limitRange.Spec.Limits[i]
+		r.scaleResourceList(limit.Max)
+		r.scaleResourceList(limit.Min)
+		r.scaleResourceList(limit.Default)
+		r.scaleResourceList(limit.DefaultRequest)
+	}
+}
+
+// syncResourceQuotas synchronizes ResourceQuotas between namespaces, scaled by the quota scaling of the mapping
+func syncResourceQuotas(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
+	log.Info(fmt.Sprintf("syncing resourcequotas from %s to %s", srcNamespace, dstNamespace))
+
+	return eachSourcePage(ctx, corev1.SchemeGroupVersion.WithResource("resourcequotas"), srcNamespace, "ResourceQuotas", func(opts metav1.ListOptions) (*corev1.ResourceQuotaList, error) {
+		return sourceClient.CoreV1().ResourceQuotas(srcNamespace).List(ctx, opts)
+	}, func(quotas *corev1.ResourceQuotaList) error {
+		for _, quota := range quotas.Items {
+			if syncer.shouldSkip(&quota) || !syncer.selected("ResourceQuota", quota.Name) {
+				continue
+			}
+			quota.Namespace = dstNamespace
+			quotaCopy := quota.DeepCopy()
+			syncer.scaleResourceQuota(quotaCopy)
+			syncer.recordResult("ResourceQuota", quota.Name, syncer.SyncResource(ctx, quotaCopy, config))
+		}
+		return nil
+	})
+}
+
+// syncLimitRanges synchronizes LimitRanges between namespaces, scaled by the quota scaling of the mapping
+func syncLimitRanges(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
+	log.Info(fmt.Sprintf("syncing limitranges from %s to %s", srcNamespace, dstNamespace))
+
+	return eachSourcePage(ctx, corev1.SchemeGroupVersion.WithResource("limitranges"), srcNamespace, "LimitRanges", func(opts metav1.ListOptions) (*corev1.LimitRangeList, error) {
+		return sourceClient.CoreV1().LimitRanges(srcNamespace).List(ctx, opts)
+	}, func(limitRanges *corev1.LimitRangeList) error {
+		for _, limitRange := range limitRanges.Items {
+			if syncer.shouldSkip(&limitRange) || !syncer.selected("LimitRange", limitRange.Name) {
+				continue
+			}
+			limitRange.Namespace = dstNamespace
+			limitRangeCopy := limitRange.DeepCopy()
+			syncer.scaleLimitRange(limitRangeCopy)
+			syncer.recordResult("LimitRange", limitRange.Name, syncer.SyncResource(ctx, limitRangeCopy, config))
+		}
+		return nil
+	})
+}
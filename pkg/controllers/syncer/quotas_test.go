@@ -0,0 +1,125 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestScaleQuantity(t *testing.T) {
+	tests := []struct {
+		quantity string
+		percent  int64
+		whole    bool
+		want     string
+	}{
+		{"4", 50, false, "2"},
+		{"3", 50, false, "1500m"},
+		{"250m", 50, false, "125m"},
+		{"1m", 50, false, "1m"},
+		{"16Gi", 50, true, "8Gi"},
+		{"1001", 50, true, "501"},
+		{"10", 25, true, "3"},
+		{"1", 10, true, "1"},
+		{"0", 50, true, "0"},
+		{"100Gi", 200, true, "200Gi"},
+		{"8E", 1000, true, "9223372036854775807"},
+	}
+	for _, tt := range tests {
+		got := scaleQuantity(resource.MustParse(tt.quantity), tt.percent, tt.whole)
+		assert.Equal(t, tt.want, got.String(), "%d%% of %s", tt.percent, tt.quantity)
+	}
+}
+
+func TestIsFractionalResource(t *testing.T) {
+	assert.True(t, isFractionalResource("cpu"))
+	assert.True(t, isFractionalResource("requests.cpu"))
+	assert.True(t, isFractionalResource("limits.cpu"))
+	assert.False(t, isFractionalResource("requests.memory"))
+	assert.False(t, isFractionalResource("pods"))
+	assert.False(t, isFractionalResource("count/deployments.apps"))
+	assert.False(t, isFractionalResource("gold.storageclass.storage.k8s.io/requests.storage"))
+}
+
+func TestSyncResourceQuotas(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	sourceQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "app"},
+		Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{
+			"requests.cpu":    resource.MustParse("10"),
+			"requests.memory": resource.MustParse("32Gi"),
+			"pods":            resource.MustParse("50"),
+			"count/secrets":   resource.MustParse("25"),
+		}},
+		Status: corev1.ResourceQuotaStatus{Used: corev1.ResourceList{"pods": resource.MustParse("12")}},
+	}
+	sourceClient := fake.NewSimpleClientset(sourceQuota)
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+	syncer := NewResourceSyncer(nil, nil, destDynamic, sourceClient, nil, scheme)
+	syncer.quotaScaling = &drv1alpha1.QuotaScalingConfig{Percent: 50, UnscaledResources: []string{"count/secrets"}}
+
+	require.NoError(t, syncResourceQuotas(ctx, syncer, sourceClient, "app", "app-dr", nil))
+
+	obj, err := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("resourcequotas")).Namespace("app-dr").Get(ctx, "compute", metav1.GetOptions{})
+	require.NoError(t, err)
+	quota := &corev1.ResourceQuota{}
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, quota))
+	assert.Equal(t, "5", quota.Spec.Hard.Name("requests.cpu", resource.DecimalSI).String())
+	assert.Equal(t, "16Gi", quota.Spec.Hard.Name("requests.memory", resource.BinarySI).String())
+	assert.Equal(t, "25", quota.Spec.Hard.Name("pods", resource.DecimalSI).String())
+	assert.Equal(t, "25", quota.Spec.Hard.Name("count/secrets", resource.DecimalSI).String(), "unscaled resources are copied")
+	assert.Empty(t, quota.Status.Used, "the usage of the source cluster is not copied")
+	assert.Equal(t, "10", sourceQuota.Spec.Hard.Name("requests.cpu", resource.DecimalSI).String(), "the source quota is not modified")
+}
+
+func TestSyncLimitRanges(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	sourceClient := fake.NewSimpleClientset(&corev1.LimitRange{
+		ObjectMeta: metav1.ObjectMeta{Name: "defaults", Namespace: "app"},
+		Spec: corev1.LimitRangeSpec{Limits: []corev1.LimitRangeItem{{
+			Type:                 corev1.LimitTypeContainer,
+			Max:                  corev1.ResourceList{"cpu": resource.MustParse("4"), "memory": resource.MustParse("8Gi")},
+			Default:              corev1.ResourceList{"cpu": resource.MustParse("500m")},
+			DefaultRequest:       corev1.ResourceList{"cpu": resource.MustParse("250m")},
+			MaxLimitRequestRatio: corev1.ResourceList{"cpu": resource.MustParse("4")},
+		}}},
+	})
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+	syncer := NewResourceSyncer(nil, nil, destDynamic, sourceClient, nil, scheme)
+	syncer.quotaScaling = &drv1alpha1.QuotaScalingConfig{Percent: 50, UnscaledResources: []string{"memory"}}
+
+	require.NoError(t, syncLimitRanges(ctx, syncer, sourceClient, "app", "app-dr", nil))
+
+	obj, err := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("limitranges")).Namespace("app-dr").Get(ctx, "defaults", metav1.GetOptions{})
+	require.NoError(t, err)
+	limitRange := &corev1.LimitRange{}
+	require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, limitRange))
+	limit := limitRange.Spec.Limits[0]
+	assert.Equal(t, "2", limit.Max.Cpu().String())
+	assert.Equal(t, "8Gi", limit.Max.Memory().String())
+	assert.Equal(t, "250m", limit.Default.Cpu().String())
+	assert.Equal(t, "125m", limit.DefaultRequest.Cpu().String())
+	assert.Equal(t, "4", limit.MaxLimitRequestRatio.Cpu().String(), "ratios are not scaled")
+}
+
+func TestScaleResourceQuota_NoScaling(t *testing.T) {
+	syncer := &ResourceSyncer{}
+	quota := &corev1.ResourceQuota{Spec: corev1.ResourceQuotaSpec{Hard: corev1.ResourceList{"pods": resource.MustParse("50")}}}
+	syncer.scaleResourceQuota(quota)
+	assert.Equal(t, "50", quota.Spec.Hard.Pods().String(), "quotas are copied as they are without quota scaling")
+}
@@ -0,0 +1,68 @@
+package syncer
+
+import (
+	"fmt"
+
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// statefulSetsResource identifies StatefulSets synced through the dynamic client
+var statefulSetsResource = schema.GroupResource{Group: "apps", Resource: "statefulsets"}
+
+// replicasOverride returns the destination replicas set for a workload by the workload overrides of
+// the mapping, its dr-syncer.io/dr-replicas annotation or its dr-syncer.io/scale-override label, in
+// that order, false when none is set. A scale-override label that is not a number, e.g. "true", keeps
+// the source replicas.
+func (r *ResourceSyncer) replicasOverride(kind string, obj metav1.Object, sourceReplicas int32) (int32, bool) {
+	for _, override := range r.workloadOverrides {
+		if override.Kind == kind && override.Name == obj.GetName() {
+			return override.Replicas, true
+		}
+	}
+
+	if value, ok := obj.GetAnnotations()[utils.DRReplicasAnnotation]; ok {
+		replicas, err := utils.ParseInt32(value)
+		if err == nil && replicas >= 0 {
+			return replicas, true
+		}
+		log.Warnf("ignoring invalid %s annotation %q on %s %s", utils.DRReplicasAnnotation, value, kind, obj.GetName())
+	}
+
+	if value, ok := obj.GetLabels()[utils.ScaleOverrideLabel]; ok {
+		if replicas, err := utils.ParseInt32(value); err == nil {
+			return replicas, true
+		}
+		return sourceReplicas, true
+	}
+	return 0, false
+}
+
+// prepareStatefulSet records the source replicas of a StatefulSet synced through the dynamic client
+// and applies its replicas override, StatefulSets without an override keep their source replicas
+func (r *ResourceSyncer) prepareStatefulSet(item *unstructured.Unstructured) {
+	// StatefulSets default to one replica
+	original, found, err := unstructured.NestedInt64(item.Object, "spec", "replicas")
+	if err != nil || !found {
+		original = 1
+	}
+
+	annotations := item.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	annotations[utils.OriginalReplicasAnnotation] = fmt.Sprintf("%d", original)
+	item.SetAnnotations(annotations)
+
+	replicas, ok := r.replicasOverride("StatefulSet", item, int32(original))
+	if !ok {
+		return
+	}
+	if err := unstructured.SetNestedField(item.Object, int64(replicas), "spec", "replicas"); err != nil {
+		log.Errorf("failed to set replicas of statefulset %s: %v", item.GetName(), err)
+		return
+	}
+	log.Info(fmt.Sprintf("statefulset %s runs %d replicas in the destination", item.GetName(), replicas))
+}
@@ -0,0 +1,80 @@
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestReplicasOverride(t *testing.T) {
+	r := &ResourceSyncer{workloadOverrides: []drv1alpha1.WorkloadOverride{
+		{Kind: "Deployment", Name: "postgres-operator", Replicas: 1},
+		{Kind: "StatefulSet", Name: "web", Replicas: 3},
+	}}
+
+	deploy := func(name string, annotations, labels map[string]string) *appsv1.Deployment {
+		return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Annotations: annotations, Labels: labels}}
+	}
+
+	// The overrides of the mapping take precedence over the annotation
+	replicas, ok := r.replicasOverride("Deployment", deploy("postgres-operator", map[string]string{utils.DRReplicasAnnotation: "2"}, nil), 5)
+	assert.True(t, ok)
+	assert.Equal(t, int32(1), replicas)
+
+	// Overrides only match their kind
+	_, ok = r.replicasOverride("Deployment", deploy("web", nil, nil), 5)
+	assert.False(t, ok)
+
+	replicas, ok = r.replicasOverride("Deployment", deploy("api", map[string]string{utils.DRReplicasAnnotation: "2"}, map[string]string{utils.ScaleOverrideLabel: "4"}), 5)
+	assert.True(t, ok)
+	assert.Equal(t, int32(2), replicas)
+
+	replicas, ok = r.replicasOverride("Deployment", deploy("api", nil, map[string]string{utils.ScaleOverrideLabel: "4"}), 5)
+	assert.True(t, ok)
+	assert.Equal(t, int32(4), replicas)
+
+	// A scale-override label without a number keeps the source replicas
+	replicas, ok = r.replicasOverride("Deployment", deploy("api", nil, map[string]string{utils.ScaleOverrideLabel: "true"}), 5)
+	assert.True(t, ok)
+	assert.Equal(t, int32(5), replicas)
+
+	_, ok = r.replicasOverride("Deployment", deploy("api", map[string]string{utils.DRReplicasAnnotation: "-1"}, nil), 5)
+	assert.False(t, ok)
+}
+
+func TestPrepareStatefulSet(t *testing.T) {
+	r := &ResourceSyncer{workloadOverrides: []drv1alpha1.WorkloadOverride{{Kind: "StatefulSet", Name: "web", Replicas: 1}}}
+
+	statefulSet := func(name string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "StatefulSet",
+			"metadata":   map[string]interface{}{"name": name},
+			"spec":       map[string]interface{}{"replicas": int64(3)},
+		}}
+	}
+
+	web := statefulSet("web")
+	r.prepareStatefulSet(web)
+	replicas, _, _ := unstructured.NestedInt64(web.Object, "spec", "replicas")
+	assert.Equal(t, int64(1), replicas)
+	assert.Equal(t, "3", web.GetAnnotations()[utils.OriginalReplicasAnnotation])
+
+	// StatefulSets without an override keep their source replicas, which are recorded all the same
+	db := statefulSet("db")
+	r.prepareStatefulSet(db)
+	replicas, _, _ = unstructured.NestedInt64(db.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	assert.Equal(t, "3", db.GetAnnotations()[utils.OriginalReplicasAnnotation])
+
+	// Without spec.replicas the StatefulSet runs the default single replica
+	cache := statefulSet("cache")
+	unstructured.RemoveNestedField(cache.Object, "spec", "replicas")
+	r.prepareStatefulSet(cache)
+	assert.Equal(t, "1", cache.GetAnnotations()[utils.OriginalReplicasAnnotation])
+}
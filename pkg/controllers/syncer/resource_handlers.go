@@ -3,14 +3,19 @@ package syncer
 import (
 	"context"
 	"fmt"
+	"strconv"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
 	"github.com/supporttools/dr-syncer/pkg/contextkeys"
 	controller "github.com/supporttools/dr-syncer/pkg/controller/replication"
 	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
 	"github.com/supporttools/dr-syncer/pkg/controllers/syncer/validation"
 	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 )
@@ -19,70 +24,40 @@ import (
 func syncConfigMaps(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
 	log.Info(fmt.Sprintf("syncing configmaps from %s to %s", srcNamespace, dstNamespace))
 
-	configMaps, err := sourceClient.CoreV1().ConfigMaps(srcNamespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return syncerrors.NewRetryableError(
-			fmt.Errorf("failed to list ConfigMaps: %w", err),
-			"ConfigMaps",
-		)
-	}
-
-	for _, cm := range configMaps.Items {
-		if cm.Name == "kube-root-ca.crt" || utils.ShouldIgnoreResource(&cm) {
-			continue
-		}
-		cm.Namespace = dstNamespace
-		log.Info(fmt.Sprintf("syncing configmap %s from %s to %s", cm.Name, srcNamespace, dstNamespace))
-		cmCopy := cm
-		if err := syncer.SyncResource(ctx, &cmCopy, config); err != nil {
-			if syncerrors.IsRetryable(err) {
-				return syncerrors.NewRetryableError(
-					fmt.Errorf("failed to sync ConfigMap %s: %w", cm.Name, err),
-					fmt.Sprintf("ConfigMap/%s", cm.Name),
-				)
+	return eachSourcePage(ctx, corev1.SchemeGroupVersion.WithResource("configmaps"), srcNamespace, "ConfigMaps", func(opts metav1.ListOptions) (*corev1.ConfigMapList, error) {
+		return sourceClient.CoreV1().ConfigMaps(srcNamespace).List(ctx, opts)
+	}, func(configMaps *corev1.ConfigMapList) error {
+		for _, cm := range configMaps.Items {
+			if cm.Name == "kube-root-ca.crt" || syncer.shouldSkip(&cm) || !syncer.selected("ConfigMap", cm.Name) {
+				continue
 			}
-			return syncerrors.NewNonRetryableError(
-				fmt.Errorf("failed to sync ConfigMap %s: %w", cm.Name, err),
-				fmt.Sprintf("ConfigMap/%s", cm.Name),
-			)
+			cm.Namespace = dstNamespace
+			log.Info(fmt.Sprintf("syncing configmap %s from %s to %s", cm.Name, srcNamespace, dstNamespace))
+			cmCopy := cm
+			syncer.recordResult("ConfigMap", cm.Name, syncer.SyncResource(ctx, &cmCopy, config))
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // syncSecrets synchronizes Secrets between namespaces
 func syncSecrets(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
 	log.Info(fmt.Sprintf("syncing secrets from %s to %s", srcNamespace, dstNamespace))
 
-	secrets, err := sourceClient.CoreV1().Secrets(srcNamespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return syncerrors.NewRetryableError(
-			fmt.Errorf("failed to list Secrets: %w", err),
-			"Secrets",
-		)
-	}
-
-	for _, secret := range secrets.Items {
-		if utils.ShouldIgnoreResource(&secret) {
-			continue
-		}
-		secret.Namespace = dstNamespace
-		log.Info(fmt.Sprintf("syncing secret %s from %s to %s", secret.Name, srcNamespace, dstNamespace))
-		secretCopy := secret
-		if err := syncer.SyncResource(ctx, &secretCopy, config); err != nil {
-			if syncerrors.IsRetryable(err) {
-				return syncerrors.NewRetryableError(
-					fmt.Errorf("failed to sync Secret %s: %w", secret.Name, err),
-					fmt.Sprintf("Secret/%s", secret.Name),
-				)
+	return eachSourcePage(ctx, corev1.SchemeGroupVersion.WithResource("secrets"), srcNamespace, "Secrets", func(opts metav1.ListOptions) (*corev1.SecretList, error) {
+		return sourceClient.CoreV1().Secrets(srcNamespace).List(ctx, opts)
+	}, func(secrets *corev1.SecretList) error {
+		for _, secret := range secrets.Items {
+			if syncer.shouldSkip(&secret) || !syncer.selected("Secret", secret.Name) {
+				continue
 			}
-			return syncerrors.NewNonRetryableError(
-				fmt.Errorf("failed to sync Secret %s: %w", secret.Name, err),
-				fmt.Sprintf("Secret/%s", secret.Name),
-			)
+			secret.Namespace = dstNamespace
+			log.Info(fmt.Sprintf("syncing secret %s from %s to %s", secret.Name, srcNamespace, dstNamespace))
+			secretCopy := secret
+			syncer.recordResult("Secret", secret.Name, syncer.SyncResource(ctx, &secretCopy, config))
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // syncDeployments synchronizes Deployments between namespaces
@@ -90,138 +65,221 @@ func syncDeployments(ctx context.Context, syncer *ResourceSyncer, sourceClient k
 	var scales []DeploymentScale
 	log.Info(fmt.Sprintf("syncing deployments from %s to %s (scale to zero: %v)", srcNamespace, dstNamespace, scaleToZero))
 
-	deployments, err := sourceClient.AppsV1().Deployments(srcNamespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return nil, syncerrors.NewRetryableError(
-			fmt.Errorf("failed to list Deployments: %w", err),
-			"Deployments",
-		)
-	}
-
-	for _, deploy := range deployments.Items {
-		if utils.ShouldIgnoreResource(&deploy) {
-			continue
-		}
+	err := eachSourcePage(ctx, appsv1.SchemeGroupVersion.WithResource("deployments"), srcNamespace, "Deployments", func(opts metav1.ListOptions) (*appsv1.DeploymentList, error) {
+		return sourceClient.AppsV1().Deployments(srcNamespace).List(ctx, opts)
+	}, func(deployments *appsv1.DeploymentList) error {
+		for _, deploy := range deployments.Items {
+			if syncer.shouldSkip(&deploy) || !syncer.selected("Deployment", deploy.Name) {
+				continue
+			}
 
-		// Store original replicas
-		originalReplicas := int32(0)
-		if deploy.Spec.Replicas != nil {
-			originalReplicas = *deploy.Spec.Replicas
-		}
+			// Store original replicas
+			originalReplicas := int32(0)
+			if deploy.Spec.Replicas != nil {
+				originalReplicas = *deploy.Spec.Replicas
+			}
 
-		// Add to scales list
-		scales = append(scales, DeploymentScale{
-			Name:     deploy.Name,
-			Replicas: originalReplicas,
-			SyncTime: metav1.Now(),
-		})
+			// Add to scales list
+			scales = append(scales, DeploymentScale{
+				Name:     deploy.Name,
+				Replicas: originalReplicas,
+				SyncTime: metav1.Now(),
+			})
 
-		// Store information in annotations
-		if deploy.Annotations == nil {
-			deploy.Annotations = make(map[string]string)
-		}
-		deploy.Annotations["dr-syncer.io/original-replicas"] = fmt.Sprintf("%d", originalReplicas)
-		deploy.Annotations["dr-syncer.io/source-namespace"] = srcNamespace
+			// Store information in annotations
+			if deploy.Annotations == nil {
+				deploy.Annotations = make(map[string]string)
+			}
+			deploy.Annotations[utils.OriginalReplicasAnnotation] = fmt.Sprintf("%d", originalReplicas)
+			deploy.Annotations["dr-syncer.io/source-namespace"] = srcNamespace
 
-		// Check for scale override
-		if override, exists := deploy.Labels[utils.ScaleOverrideLabel]; exists {
-			if replicas, err := utils.ParseInt32(override); err == nil {
+			// Workloads with a replicas override keep running in the destination
+			if replicas, ok := syncer.replicasOverride("Deployment", &deploy, originalReplicas); ok {
 				deploy.Spec.Replicas = &replicas
+			} else if scaleToZero {
+				zero := int32(0)
+				deploy.Spec.Replicas = &zero
 			}
-		} else if scaleToZero {
-			zero := int32(0)
-			deploy.Spec.Replicas = &zero
-		}
 
-		deploy.Namespace = dstNamespace
-		log.Info(fmt.Sprintf("syncing deployment %s from %s to %s (replicas: %d)", deploy.Name, srcNamespace, dstNamespace, *deploy.Spec.Replicas))
-		deployCopy := deploy
-		if err := syncer.SyncResource(ctx, &deployCopy, config); err != nil {
-			if syncerrors.IsRetryable(err) {
-				return nil, syncerrors.NewRetryableError(
-					fmt.Errorf("failed to sync Deployment %s: %w", deploy.Name, err),
-					fmt.Sprintf("Deployment/%s", deploy.Name),
-				)
+			if err := syncer.rewritePVCVolumes(&deploy.Spec.Template.Spec, "Deployment", deploy.Name); err != nil {
+				syncer.recordResult("Deployment", deploy.Name, err)
+				continue
+			}
+			syncer.rewritePodSpecImages(&deploy.Spec.Template.Spec)
+
+			deploy.Namespace = dstNamespace
+			log.Info(fmt.Sprintf("syncing deployment %s from %s to %s (replicas: %d)", deploy.Name, srcNamespace, dstNamespace, *deploy.Spec.Replicas))
+			deployCopy := deploy
+			err := syncer.SyncResource(ctx, &deployCopy, config)
+			syncer.recordResult("Deployment", deploy.Name, err)
+			if err == nil {
+				syncer.recordSourceRevision("Deployment", deploy.Name, deploy.Generation)
 			}
-			return nil, syncerrors.NewNonRetryableError(
-				fmt.Errorf("failed to sync Deployment %s: %w", deploy.Name, err),
-				fmt.Sprintf("Deployment/%s", deploy.Name),
-			)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 	return scales, nil
 }
 
+// syncDaemonSets synchronizes DaemonSets between namespaces. They run one pod per node and cannot be
+// scaled, so they are synced as they are and run in the destination like in the source.
+func syncDaemonSets(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
+	log.Info(fmt.Sprintf("syncing daemonsets from %s to %s", srcNamespace, dstNamespace))
+
+	return eachSourcePage(ctx, appsv1.SchemeGroupVersion.WithResource("daemonsets"), srcNamespace, "DaemonSets", func(opts metav1.ListOptions) (*appsv1.DaemonSetList, error) {
+		return sourceClient.AppsV1().DaemonSets(srcNamespace).List(ctx, opts)
+	}, func(daemonSets *appsv1.DaemonSetList) error {
+		for _, ds := range daemonSets.Items {
+			if syncer.shouldSkip(&ds) || !syncer.selected("DaemonSet", ds.Name) {
+				continue
+			}
+			if err := syncer.rewritePVCVolumes(&ds.Spec.Template.Spec, "DaemonSet", ds.Name); err != nil {
+				syncer.recordResult("DaemonSet", ds.Name, err)
+				continue
+			}
+			syncer.rewritePodSpecImages(&ds.Spec.Template.Spec)
+			ds.Namespace = dstNamespace
+			log.Info(fmt.Sprintf("syncing daemonset %s from %s to %s", ds.Name, srcNamespace, dstNamespace))
+			dsCopy := ds
+			err := syncer.SyncResource(ctx, &dsCopy, config)
+			syncer.recordResult("DaemonSet", ds.Name, err)
+			if err == nil {
+				syncer.recordSourceRevision("DaemonSet", ds.Name, ds.Generation)
+			}
+		}
+		return nil
+	})
+}
+
 // syncServices synchronizes Services between namespaces
 func syncServices(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
 	log.Info(fmt.Sprintf("syncing services from %s to %s", srcNamespace, dstNamespace))
 
-	services, err := sourceClient.CoreV1().Services(srcNamespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return syncerrors.NewRetryableError(
-			fmt.Errorf("failed to list Services: %w", err),
-			"Services",
-		)
-	}
-
-	for _, svc := range services.Items {
-		if utils.ShouldIgnoreResource(&svc) {
-			continue
-		}
-		svc.Namespace = dstNamespace
-		svc.Spec.ClusterIP = ""
-		svc.Spec.ClusterIPs = nil
-		log.Info(fmt.Sprintf("syncing service %s from %s to %s (type: %s)", svc.Name, srcNamespace, dstNamespace, svc.Spec.Type))
-		svcCopy := svc
-		if err := syncer.SyncResource(ctx, &svcCopy, config); err != nil {
-			if syncerrors.IsRetryable(err) {
-				return syncerrors.NewRetryableError(
-					fmt.Errorf("failed to sync Service %s: %w", svc.Name, err),
-					fmt.Sprintf("Service/%s", svc.Name),
-				)
+	return eachSourcePage(ctx, corev1.SchemeGroupVersion.WithResource("services"), srcNamespace, "Services", func(opts metav1.ListOptions) (*corev1.ServiceList, error) {
+		return sourceClient.CoreV1().Services(srcNamespace).List(ctx, opts)
+	}, func(services *corev1.ServiceList) error {
+		for _, svc := range services.Items {
+			if syncer.shouldSkip(&svc) || syncer.bridgedService(svc.Name, svc.Labels) || !syncer.selected("Service", svc.Name) {
+				continue
 			}
-			return syncerrors.NewNonRetryableError(
-				fmt.Errorf("failed to sync Service %s: %w", svc.Name, err),
-				fmt.Sprintf("Service/%s", svc.Name),
-			)
+			svc.Namespace = dstNamespace
+			log.Info(fmt.Sprintf("syncing service %s from %s to %s (type: %s)", svc.Name, srcNamespace, dstNamespace, svc.Spec.Type))
+			svcCopy := svc
+			syncer.recordResult("Service", svc.Name, syncer.SyncResource(ctx, &svcCopy, config))
 		}
-	}
-	return nil
+		return nil
+	})
 }
 
 // syncIngresses synchronizes Ingresses between namespaces
 func syncIngresses(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, config *drv1alpha1.ImmutableResourceConfig) error {
 	log.Info(fmt.Sprintf("syncing ingresses from %s to %s", srcNamespace, dstNamespace))
 
-	ingresses, err := sourceClient.NetworkingV1().Ingresses(srcNamespace).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return syncerrors.NewRetryableError(
-			fmt.Errorf("failed to list Ingresses: %w", err),
-			"Ingresses",
-		)
+	return eachSourcePage(ctx, networkingv1.SchemeGroupVersion.WithResource("ingresses"), srcNamespace, "Ingresses", func(opts metav1.ListOptions) (*networkingv1.IngressList, error) {
+		return sourceClient.NetworkingV1().Ingresses(srcNamespace).List(ctx, opts)
+	}, func(ingresses *networkingv1.IngressList) error {
+		for _, ing := range ingresses.Items {
+			if syncer.shouldSkip(&ing) || !syncer.selected("Ingress", ing.Name) {
+				continue
+			}
+			ing.Namespace = dstNamespace
+			syncer.mapIngressClass(&ing)
+			log.Info(fmt.Sprintf("syncing ingress %s from %s to %s", ing.Name, srcNamespace, dstNamespace))
+			ingCopy := ing
+			syncer.recordResult("Ingress", ing.Name, syncer.SyncResource(ctx, &ingCopy, config))
+		}
+		return nil
+	})
+}
+
+// suspendForDestination records the source suspend state in an annotation and returns the
+// suspend value to use in the destination cluster
+func suspendForDestination(obj metav1.Object, suspend *bool, suspendOnDestination bool) *bool {
+	original := suspend != nil && *suspend
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
 	}
+	annotations[utils.OriginalSuspendAnnotation] = strconv.FormatBool(original)
+	obj.SetAnnotations(annotations)
 
-	for _, ing := range ingresses.Items {
-		if utils.ShouldIgnoreResource(&ing) {
-			continue
+	result := original || suspendOnDestination
+	return &result
+}
+
+// isOwnedByCronJob checks if a Job was created by a CronJob
+func isOwnedByCronJob(job *batchv1.Job) bool {
+	for _, ref := range job.OwnerReferences {
+		if ref.Kind == "CronJob" {
+			return true
 		}
-		ing.Namespace = dstNamespace
-		log.Info(fmt.Sprintf("syncing ingress %s from %s to %s", ing.Name, srcNamespace, dstNamespace))
-		ingCopy := ing
-		if err := syncer.SyncResource(ctx, &ingCopy, config); err != nil {
-			if syncerrors.IsRetryable(err) {
-				return syncerrors.NewRetryableError(
-					fmt.Errorf("failed to sync Ingress %s: %w", ing.Name, err),
-					fmt.Sprintf("Ingress/%s", ing.Name),
-				)
+	}
+	return false
+}
+
+// syncCronJobs synchronizes CronJobs between namespaces
+func syncCronJobs(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, suspend bool, config *drv1alpha1.ImmutableResourceConfig) error {
+	log.Info(fmt.Sprintf("syncing cronjobs from %s to %s (suspend: %v)", srcNamespace, dstNamespace, suspend))
+
+	return eachSourcePage(ctx, batchv1.SchemeGroupVersion.WithResource("cronjobs"), srcNamespace, "CronJobs", func(opts metav1.ListOptions) (*batchv1.CronJobList, error) {
+		return sourceClient.BatchV1().CronJobs(srcNamespace).List(ctx, opts)
+	}, func(cronJobs *batchv1.CronJobList) error {
+		for _, cj := range cronJobs.Items {
+			if syncer.shouldSkip(&cj) || !syncer.selected("CronJob", cj.Name) {
+				continue
 			}
-			return syncerrors.NewNonRetryableError(
-				fmt.Errorf("failed to sync Ingress %s: %w", ing.Name, err),
-				fmt.Sprintf("Ingress/%s", ing.Name),
-			)
+			if err := syncer.rewritePVCVolumes(&cj.Spec.JobTemplate.Spec.Template.Spec, "CronJob", cj.Name); err != nil {
+				syncer.recordResult("CronJob", cj.Name, err)
+				continue
+			}
+			syncer.rewritePodSpecImages(&cj.Spec.JobTemplate.Spec.Template.Spec)
+			cj.Spec.Suspend = suspendForDestination(&cj, cj.Spec.Suspend, suspend)
+			cj.Namespace = dstNamespace
+			log.Info(fmt.Sprintf("syncing cronjob %s from %s to %s (suspend: %v)", cj.Name, srcNamespace, dstNamespace, *cj.Spec.Suspend))
+			cjCopy := cj
+			syncer.recordResult("CronJob", cj.Name, syncer.SyncResource(ctx, &cjCopy, config))
 		}
-	}
-	return nil
+		return nil
+	})
+}
+
+// syncJobs synchronizes standalone Jobs between namespaces. Jobs created by a CronJob and
+// Jobs that have already completed are skipped.
+func syncJobs(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, srcNamespace, dstNamespace string, suspend bool, config *drv1alpha1.ImmutableResourceConfig) error {
+	log.Info(fmt.Sprintf("syncing jobs from %s to %s (suspend: %v)", srcNamespace, dstNamespace, suspend))
+
+	return eachSourcePage(ctx, batchv1.SchemeGroupVersion.WithResource("jobs"), srcNamespace, "Jobs", func(opts metav1.ListOptions) (*batchv1.JobList, error) {
+		return sourceClient.BatchV1().Jobs(srcNamespace).List(ctx, opts)
+	}, func(jobs *batchv1.JobList) error {
+		for _, job := range jobs.Items {
+			if syncer.shouldSkip(&job) || !syncer.selected("Job", job.Name) || isOwnedByCronJob(&job) || job.Status.CompletionTime != nil {
+				continue
+			}
+
+			// The selector and controller-uid labels are generated by the source cluster
+			job.Spec.Selector = nil
+			job.Spec.ManualSelector = nil
+			for _, key := range []string{"controller-uid", "batch.kubernetes.io/controller-uid"} {
+				delete(job.Labels, key)
+				delete(job.Spec.Template.Labels, key)
+			}
+
+			if err := syncer.rewritePVCVolumes(&job.Spec.Template.Spec, "Job", job.Name); err != nil {
+				syncer.recordResult("Job", job.Name, err)
+				continue
+			}
+			syncer.rewritePodSpecImages(&job.Spec.Template.Spec)
+			job.Spec.Suspend = suspendForDestination(&job, job.Spec.Suspend, suspend)
+			job.Namespace = dstNamespace
+			log.Info(fmt.Sprintf("syncing job %s from %s to %s (suspend: %v)", job.Name, srcNamespace, dstNamespace, *job.Spec.Suspend))
+			jobCopy := job
+			syncer.recordResult("Job", job.Name, syncer.SyncResource(ctx, &jobCopy, config))
+		}
+		return nil
+	})
 }
 
 // syncPersistentVolumeClaims synchronizes PVCs between namespaces
@@ -240,7 +298,7 @@ func syncPersistentVolumeClaims(ctx context.Context, syncer *ResourceSyncer, sou
 	var syncedPVCs []corev1.PersistentVolumeClaim
 
 	for _, pvc := range pvcs.Items {
-		if utils.ShouldIgnoreResource(&pvc) {
+		if syncer.shouldSkip(&pvc) {
 			continue
 		}
 
@@ -271,15 +329,7 @@ func syncPersistentVolumeClaims(ctx context.Context, syncer *ResourceSyncer, sou
 			pvc.Spec.Resources))
 
 		// Apply access mode mapping if configured
-		if pvcConfig != nil && len(pvcConfig.AccessModeMappings) > 0 {
-			for _, mapping := range pvcConfig.AccessModeMappings {
-				for i, mode := range pvc.Spec.AccessModes {
-					if string(mode) == mapping.From {
-						pvc.Spec.AccessModes[i] = corev1.PersistentVolumeAccessMode(mapping.To)
-					}
-				}
-			}
-		}
+		mapAccessModes(&pvc, pvcConfig)
 
 		// Validate storage class exists in destination cluster
 		if err := validation.ValidateStorageClass(ctx, syncer.destClient, pvc.Spec.StorageClassName); err != nil {
@@ -321,13 +371,20 @@ func syncPersistentVolumeClaims(ctx context.Context, syncer *ResourceSyncer, sou
 				pvc.Spec.DataSourceRef = nil
 			}
 
+			// The destination storage must be able to provision the PVC as it will be created
+			if err := validation.PreflightPVC(ctx, syncer.destClient, &pvc); err != nil {
+				return err
+			}
+
 			// Create the PVC in the destination cluster
 			log.Info(fmt.Sprintf("creating new PVC %s in namespace %s", pvc.Name, dstNamespace))
 
-			// Clear resourceVersion before creating
-			pvc.ResourceVersion = ""
+			// Clear resourceVersion and other cluster-specific metadata before creating
+			syncer.sanitize(&pvc)
+			syncer.labelSynced(&pvc)
 
 			createdPVC, err := syncer.destClient.CoreV1().PersistentVolumeClaims(dstNamespace).Create(ctx, &pvc, metav1.CreateOptions{})
+			audit.Record(ctx, audit.OperationCreate, audit.ObjectRef(pvcGVK, &pvc), "", err)
 			if err != nil {
 				return syncerrors.NewRetryableError(
 					fmt.Errorf("failed to create PVC %s: %w", pvc.Name, err),
@@ -335,6 +392,8 @@ func syncPersistentVolumeClaims(ctx context.Context, syncer *ResourceSyncer, sou
 				)
 			}
 
+			syncer.expectSynced(pvcGVR, pvcGVK.Kind, &pvc)
+
 			// Add to synced PVCs list for data sync
 			syncedPVCs = append(syncedPVCs, *createdPVC)
 		} else {
@@ -350,6 +409,7 @@ func syncPersistentVolumeClaims(ctx context.Context, syncer *ResourceSyncer, sou
 			// Update the PVC in the destination cluster
 			log.Info(fmt.Sprintf("updating existing PVC %s in namespace %s", pvc.Name, dstNamespace))
 			updatedPVC, err := syncer.destClient.CoreV1().PersistentVolumeClaims(dstNamespace).Update(ctx, updatePVC, metav1.UpdateOptions{})
+			syncer.recordUpdate(ctx, pvcGVK, updatePVC, audit.DiffObjects(existingPVC, updatePVC), err)
 			if err != nil {
 				return syncerrors.NewRetryableError(
 					fmt.Errorf("failed to update PVC %s: %w", pvc.Name, err),
@@ -357,6 +417,8 @@ func syncPersistentVolumeClaims(ctx context.Context, syncer *ResourceSyncer, sou
 				)
 			}
 
+			syncer.expectSynced(pvcGVR, pvcGVK.Kind, updatePVC)
+
 			// Add to synced PVCs list for data sync
 			syncedPVCs = append(syncedPVCs, *updatedPVC)
 		}
@@ -481,11 +543,12 @@ func syncPersistentVolumeClaims(ctx context.Context, syncer *ResourceSyncer, sou
 			// Sync PVC data
 			log.Info(fmt.Sprintf("Starting data sync for PVC %s from %s to %s", pvc.Name, srcNamespace, dstNamespace))
 
-			// Create a dummy namespace mapping object with just the name
+			// Create a namespace mapping object carrying the PVC config, which holds the sync timeout
 			dummyMapping := &drv1alpha1.NamespaceMapping{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: fmt.Sprintf("pvc-sync-%s", pvc.Name),
 				},
+				Spec: drv1alpha1.NamespaceMappingSpec{PVCConfig: pvcConfig},
 			}
 
 			log.Info(fmt.Sprintf("Calling SyncPVCWithNamespaceMapping for PVC %s", pvc.Name))
@@ -523,6 +586,23 @@ func (r *ResourceSyncer) getPVCSyncer(ctx context.Context) (*controller.PVCSynce
 	// Set the Kubernetes clients directly
 	syncer.SourceK8sClient = r.sourceClient
 	syncer.DestinationK8sClient = r.destClient
+	syncer.SecurityProfile = r.rsyncProfile
+	syncer.EphemeralStorage = r.rsyncEphemeralStorage
+	syncer.Strategy = r.dataSyncStrategy
+	syncer.SyncUnmounted = r.syncUnmounted
+	syncer.AutoGrowDestination = r.autoGrowDestination
+	syncer.StaleLockTimeout = r.staleLockTimeout
+	syncer.Agentless = r.agentless
+
+	// Transfer the data through the mapping's object storage repository instead of rsync over SSH
+	if r.objectStorageTransport {
+		repository, err := objectStorageRepository(ctx, r.ctrlClient, r.objectStorageNamespace, r.objectStorage)
+		if err != nil {
+			log.Errorf("Failed to resolve object storage repository: %v", err)
+			return nil, err
+		}
+		syncer.ObjectStorage = repository
+	}
 
 	// Create a new context with the REST configs stored using multiple key formats
 	// to ensure compatibility with different parts of the codebase
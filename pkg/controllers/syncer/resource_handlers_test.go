@@ -1,12 +1,23 @@
 package syncer
 
 import (
+	"context"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
 	"k8s.io/client-go/rest"
 )
 
@@ -268,3 +279,137 @@ func TestResourceSyncer_UpdateConfigs(t *testing.T) {
 	assert.Equal(t, "https://source2:6443", syncer.sourceConfig.Host)
 	assert.Equal(t, "https://dest2:6443", syncer.destConfig.Host)
 }
+
+func TestSuspendForDestination(t *testing.T) {
+	obj := &metav1.ObjectMeta{Name: "nightly"}
+
+	suspend := suspendForDestination(obj, nil, true)
+	assert.True(t, *suspend)
+	assert.Equal(t, "false", obj.Annotations[utils.OriginalSuspendAnnotation])
+}
+
+func TestSuspendForDestination_Disabled(t *testing.T) {
+	obj := &metav1.ObjectMeta{Name: "nightly"}
+
+	suspend := suspendForDestination(obj, nil, false)
+	assert.False(t, *suspend)
+	assert.Equal(t, "false", obj.Annotations[utils.OriginalSuspendAnnotation])
+}
+
+func TestSuspendForDestination_AlreadySuspended(t *testing.T) {
+	obj := &metav1.ObjectMeta{Name: "nightly"}
+	original := true
+
+	suspend := suspendForDestination(obj, &original, false)
+	assert.True(t, *suspend)
+	assert.Equal(t, "true", obj.Annotations[utils.OriginalSuspendAnnotation])
+}
+
+func TestIsOwnedByCronJob(t *testing.T) {
+	job := &batchv1.Job{}
+	assert.False(t, isOwnedByCronJob(job))
+
+	job.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: "nightly"}}
+	assert.True(t, isOwnedByCronJob(job))
+}
+
+func TestSyncResource_AppliesSanitization(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "app-dr", ResourceVersion: "7"},
+		Data:       map[string]string{"key": "old"},
+	}
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme, existing)
+
+	syncer := NewResourceSyncer(nil, nil, destDynamic, nil, nil, scheme)
+	syncer.sanitization = &drv1alpha1.SanitizationConfig{
+		Labels:     &drv1alpha1.MetadataFilter{Strip: []string{"example.com/*"}},
+		Finalizers: &drv1alpha1.MetadataFilter{Preserve: []string{"example.com/protect"}},
+	}
+
+	source := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "settings",
+			Namespace:       "app-dr",
+			Labels:          map[string]string{"app": "shop", "example.com/cluster": "prod"},
+			Finalizers:      []string{"example.com/protect", "example.com/cleanup"},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "Application", Name: "shop"}},
+		},
+		Data: map[string]string{"key": "new"},
+	}
+	require.NoError(t, syncer.SyncResource(ctx, source, nil))
+
+	updated, err := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("app-dr").Get(ctx, "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"app": "shop"}, updated.GetLabels())
+	assert.Equal(t, []string{"example.com/protect"}, updated.GetFinalizers())
+	assert.Empty(t, updated.GetOwnerReferences())
+}
+
+func TestSyncResource_LabelsSyncedResources(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	// Resources synced before labeling was introduced are labeled on their next sync
+	existing := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "app-dr", Labels: map[string]string{"app": "shop"}},
+		Data:       map[string]string{"key": "value"},
+	}
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme, existing)
+
+	syncer := NewResourceSyncer(nil, nil, destDynamic, nil, nil, scheme)
+	syncer.mappingLabels = utils.MappingLabels("dr-syncer", "shop")
+
+	for _, name := range []string{"settings", "created"} {
+		source := &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "app-dr", Labels: map[string]string{"app": "shop"}},
+			Data:       map[string]string{"key": "value"},
+		}
+		require.NoError(t, syncer.SyncResource(ctx, source, nil))
+
+		synced, err := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("configmaps")).Namespace("app-dr").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, map[string]string{
+			"app":                       "shop",
+			utils.MappingNameLabel:      "shop",
+			utils.MappingNamespaceLabel: "dr-syncer",
+		}, synced.GetLabels())
+	}
+}
+
+func TestSyncDaemonSets(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, appsv1.AddToScheme(scheme))
+
+	sourceClient := fake.NewSimpleClientset(
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "log-shipper", Namespace: "app"},
+			Spec: appsv1.DaemonSetSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "shipper", Image: "registry.prod.local/shipper:1.0"}},
+			}}},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "ignored", Namespace: "app", Labels: map[string]string{utils.IgnoreLabel: "true"}},
+		},
+	)
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+
+	syncer := NewResourceSyncer(nil, nil, destDynamic, sourceClient, nil, scheme)
+	syncer.imageOverrides = []drv1alpha1.ImageOverride{{From: "registry.prod.local", To: "registry.dr.local"}}
+	require.NoError(t, syncDaemonSets(ctx, syncer, sourceClient, "app", "app-dr", nil))
+
+	daemonSets := destDynamic.Resource(appsv1.SchemeGroupVersion.WithResource("daemonsets")).Namespace("app-dr")
+	synced, err := daemonSets.Get(ctx, "log-shipper", metav1.GetOptions{})
+	require.NoError(t, err)
+	containers, _, _ := unstructured.NestedSlice(synced.Object, "spec", "template", "spec", "containers")
+	require.Len(t, containers, 1)
+	assert.Equal(t, "registry.dr.local/shipper:1.0", containers[0].(map[string]interface{})["image"])
+
+	_, err = daemonSets.Get(ctx, "ignored", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
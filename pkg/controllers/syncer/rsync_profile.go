@@ -0,0 +1,53 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// destinationSecurityProfile returns the rsync security profile of the destination RemoteCluster of a mapping.
+// RemoteClusters live next to the ClusterMapping referenced by the mapping, or next to the mapping itself.
+// The profile of a cluster that cannot be read is Privileged, as it was before profiles could be selected.
+func destinationSecurityProfile(ctx context.Context, c client.Client, mappingNamespace string, spec *drv1alpha1.NamespaceMappingSpec) drv1alpha1.RsyncSecurityProfile {
+	namespace := remoteClusterNamespace(mappingNamespace, spec)
+	if c == nil || namespace == "" || spec.DestinationCluster == "" {
+		return drv1alpha1.RsyncSecurityProfilePrivileged
+	}
+
+	var remoteCluster drv1alpha1.RemoteCluster
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.DestinationCluster}, &remoteCluster); err != nil {
+		log.Warn(fmt.Sprintf("failed to get destination RemoteCluster %s/%s, using the %s rsync security profile: %v",
+			namespace, spec.DestinationCluster, drv1alpha1.RsyncSecurityProfilePrivileged, err))
+		return drv1alpha1.RsyncSecurityProfilePrivileged
+	}
+	return remoteCluster.Spec.PVCSync.GetSecurityProfile()
+}
+
+// sourceAgentless returns whether the source RemoteCluster of a mapping runs without the agent, its PVC
+// data is then streamed through the exec API. A cluster that cannot be read is assumed to run the agent.
+func sourceAgentless(ctx context.Context, c client.Client, mappingNamespace string, spec *drv1alpha1.NamespaceMappingSpec) bool {
+	namespace := remoteClusterNamespace(mappingNamespace, spec)
+	if c == nil || namespace == "" || spec.SourceCluster == "" {
+		return false
+	}
+
+	var remoteCluster drv1alpha1.RemoteCluster
+	if err := c.Get(ctx, types.NamespacedName{Namespace: namespace, Name: spec.SourceCluster}, &remoteCluster); err != nil {
+		log.Warn(fmt.Sprintf("failed to get source RemoteCluster %s/%s, assuming it runs the agent: %v",
+			namespace, spec.SourceCluster, err))
+		return false
+	}
+	return remoteCluster.Spec.PVCSync != nil && remoteCluster.Spec.PVCSync.Agentless
+}
+
+// remoteClusterNamespace returns the namespace of the RemoteClusters of a mapping
+func remoteClusterNamespace(mappingNamespace string, spec *drv1alpha1.NamespaceMappingSpec) string {
+	if spec.ClusterMappingRef != nil && spec.ClusterMappingRef.Namespace != "" {
+		return spec.ClusterMappingRef.Namespace
+	}
+	return mappingNamespace
+}
@@ -0,0 +1,57 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDestinationSecurityProfile(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&drv1alpha1.RemoteCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "dr", Namespace: "dr-syncer"},
+			Spec: drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{
+				SecurityProfile: drv1alpha1.RsyncSecurityProfileRestricted,
+			}},
+		},
+		&drv1alpha1.RemoteCluster{ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "dr-syncer"}},
+	).Build()
+	ctx := context.Background()
+
+	assert.Equal(t, drv1alpha1.RsyncSecurityProfileRestricted,
+		destinationSecurityProfile(ctx, c, "dr-syncer", &drv1alpha1.NamespaceMappingSpec{DestinationCluster: "dr"}))
+	assert.Equal(t, drv1alpha1.RsyncSecurityProfileRestricted,
+		destinationSecurityProfile(ctx, c, "shop", &drv1alpha1.NamespaceMappingSpec{
+			DestinationCluster: "dr",
+			ClusterMappingRef:  &drv1alpha1.ClusterMappingReference{Name: "prod-to-dr", Namespace: "dr-syncer"},
+		}), "clusters are looked up next to the referenced ClusterMapping")
+	assert.Equal(t, drv1alpha1.RsyncSecurityProfilePrivileged,
+		destinationSecurityProfile(ctx, c, "dr-syncer", &drv1alpha1.NamespaceMappingSpec{DestinationCluster: "legacy"}))
+	assert.Equal(t, drv1alpha1.RsyncSecurityProfilePrivileged,
+		destinationSecurityProfile(ctx, c, "shop", &drv1alpha1.NamespaceMappingSpec{DestinationCluster: "dr"}), "missing clusters are Privileged")
+}
+
+func TestSourceAgentless(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, drv1alpha1.AddToScheme(scheme))
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		&drv1alpha1.RemoteCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "edge", Namespace: "dr-syncer"},
+			Spec:       drv1alpha1.RemoteClusterSpec{PVCSync: &drv1alpha1.PVCSyncSpec{Enabled: true, Agentless: true}},
+		},
+		&drv1alpha1.RemoteCluster{ObjectMeta: metav1.ObjectMeta{Name: "prod", Namespace: "dr-syncer"}},
+	).Build()
+	ctx := context.Background()
+
+	assert.True(t, sourceAgentless(ctx, c, "dr-syncer", &drv1alpha1.NamespaceMappingSpec{SourceCluster: "edge"}))
+	assert.False(t, sourceAgentless(ctx, c, "dr-syncer", &drv1alpha1.NamespaceMappingSpec{SourceCluster: "prod"}))
+	assert.False(t, sourceAgentless(ctx, c, "shop", &drv1alpha1.NamespaceMappingSpec{SourceCluster: "edge"}), "missing clusters run the agent")
+}
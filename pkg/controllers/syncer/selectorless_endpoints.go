@@ -0,0 +1,420 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// syncedEndpointsLabel marks the Endpoints and EndpointSlices synced for Services without a selector,
+	// its value is the destination Service name
+	syncedEndpointsLabel = "dr-syncer.io/synced-endpoints"
+
+	// endpointSliceMirroringManager is the managed-by value of the EndpointSlices Kubernetes mirrors from
+	// Endpoints. They are not synced, the destination cluster mirrors the synced Endpoints itself.
+	endpointSliceMirroringManager = "endpointslicemirroring-controller.k8s.io"
+)
+
+// addressRewrite replaces an IP address, or the addresses of a network keeping their host part
+type addressRewrite struct {
+	from *net.IPNet
+	to   *net.IPNet
+	// toIP replaces every address of from, set when To is a single address
+	toIP net.IP
+}
+
+// compileAddressRewrites parses the address rewrites of a mapping
+func compileAddressRewrites(rules []drv1alpha1.AddressRewrite) ([]addressRewrite, error) {
+	compiled := make([]addressRewrite, 0, len(rules))
+	for i, rule := range rules {
+		from, fromIsIP, err := parseAddressOrCIDR(rule.From)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from in address rewrite %d: %w", i, err)
+		}
+		to, toIsIP, err := parseAddressOrCIDR(rule.To)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to in address rewrite %d: %w", i, err)
+		}
+		if (from.IP.To4() == nil) != (to.IP.To4() == nil) {
+			return nil, fmt.Errorf("address rewrite %d rewrites %s to another IP family", i, rule.From)
+		}
+
+		c := addressRewrite{from: from}
+		switch {
+		case toIsIP:
+			c.toIP = to.IP
+		case fromIsIP:
+			return nil, fmt.Errorf("address rewrite %d rewrites the address %s to the network %s", i, rule.From, rule.To)
+		default:
+			fromOnes, _ := from.Mask.Size()
+			toOnes, _ := to.Mask.Size()
+			if fromOnes != toOnes {
+				return nil, fmt.Errorf("address rewrite %d rewrites %s to a network of another size", i, rule.From)
+			}
+			c.to = to
+		}
+		compiled = append(compiled, c)
+	}
+	return compiled, nil
+}
+
+// parseAddressOrCIDR parses an IP address as a network of a single address, or a CIDR
+func parseAddressOrCIDR(value string) (*net.IPNet, bool, error) {
+	if ip := net.ParseIP(value); ip != nil {
+		bits := 8 * net.IPv6len
+		if ip4 := ip.To4(); ip4 != nil {
+			ip, bits = ip4, 8*net.IPv4len
+		}
+		return &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)}, true, nil
+	}
+	_, network, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, false, fmt.Errorf("%q is neither an IP address nor a CIDR", value)
+	}
+	return network, false, nil
+}
+
+// rewriteAddress applies the first address rewrite matching an IP address. Addresses that are no IP
+// addresses, such as FQDN endpoints, are returned unchanged.
+func rewriteAddress(rewrites []addressRewrite, address string) string {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return address
+	}
+	for _, rewrite := range rewrites {
+		if !rewrite.from.Contains(ip) {
+			continue
+		}
+		if rewrite.toIP != nil {
+			return rewrite.toIP.String()
+		}
+		if ip4 := ip.To4(); ip4 != nil {
+			ip = ip4
+		}
+		rewritten := make(net.IP, len(ip))
+		for i := range ip {
+			rewritten[i] = rewrite.to.IP[i] | (ip[i] &^ rewrite.to.Mask[i])
+		}
+		return rewritten.String()
+	}
+	return address
+}
+
+// selectorlessEndpoints syncs the endpoints of the Services without a selector
+type selectorlessEndpoints struct {
+	rewrites []addressRewrite
+}
+
+// compileSelectorlessEndpoints compiles the selectorless endpoints config of a mapping, nil when their
+// endpoints are not synced
+func compileSelectorlessEndpoints(config *drv1alpha1.SelectorlessEndpointsConfig) (*selectorlessEndpoints, error) {
+	if config == nil || config.Enabled == nil || !*config.Enabled {
+		return nil, nil
+	}
+	rewrites, err := compileAddressRewrites(config.AddressRewrites)
+	if err != nil {
+		return nil, err
+	}
+	return &selectorlessEndpoints{rewrites: rewrites}, nil
+}
+
+// endpointsCopy returns the destination copy of the Endpoints of a Service, without the node and pod
+// references only valid in the source cluster
+func (s *selectorlessEndpoints) endpointsCopy(source *corev1.Endpoints, service, namespace string) *corev1.Endpoints {
+	endpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service,
+			Namespace: namespace,
+			Labels:    make(map[string]string, len(source.Labels)+2),
+		},
+	}
+	for key, value := range source.Labels {
+		endpoints.Labels[key] = value
+	}
+	endpoints.Labels[syncedEndpointsLabel] = service
+	endpoints.Labels[utils.ManagedByLabel] = utils.ManagedByValue
+
+	copyAddresses := func(addresses []corev1.EndpointAddress) []corev1.EndpointAddress {
+		var copied []corev1.EndpointAddress
+		for _, address := range addresses {
+			copied = append(copied, corev1.EndpointAddress{
+				IP:       rewriteAddress(s.rewrites, address.IP),
+				Hostname: address.Hostname,
+			})
+		}
+		return copied
+	}
+	for _, subset := range source.Subsets {
+		endpoints.Subsets = append(endpoints.Subsets, corev1.EndpointSubset{
+			Addresses:         copyAddresses(subset.Addresses),
+			NotReadyAddresses: copyAddresses(subset.NotReadyAddresses),
+			Ports:             subset.Ports,
+		})
+	}
+	return endpoints
+}
+
+// endpointSliceCopy returns the destination copy of an EndpointSlice of a Service, without the node,
+// zone and pod references only valid in the source cluster
+func (s *selectorlessEndpoints) endpointSliceCopy(source *discoveryv1.EndpointSlice, sourceService, service, namespace string) *discoveryv1.EndpointSlice {
+	name := source.Name
+	if service != sourceService && strings.HasPrefix(name, sourceService) {
+		name = service + strings.TrimPrefix(name, sourceService)
+	}
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    make(map[string]string, len(source.Labels)+4),
+		},
+		AddressType: source.AddressType,
+		Ports:       source.Ports,
+	}
+	for key, value := range source.Labels {
+		slice.Labels[key] = value
+	}
+	slice.Labels[discoveryv1.LabelServiceName] = service
+	slice.Labels[discoveryv1.LabelManagedBy] = serviceBridgeManager
+	slice.Labels[syncedEndpointsLabel] = service
+	slice.Labels[utils.ManagedByLabel] = utils.ManagedByValue
+
+	for _, endpoint := range source.Endpoints {
+		addresses := make([]string, 0, len(endpoint.Addresses))
+		for _, address := range endpoint.Addresses {
+			addresses = append(addresses, rewriteAddress(s.rewrites, address))
+		}
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses:  addresses,
+			Conditions: endpoint.Conditions,
+			Hostname:   endpoint.Hostname,
+		})
+	}
+	return slice
+}
+
+// syncSelectorlessEndpoints syncs the Endpoints and EndpointSlices of the source Services without a
+// selector whose destination copy exists, and removes the synced endpoints of the Services no longer
+// synced
+func (r *ResourceSyncer) syncSelectorlessEndpoints(ctx context.Context, srcNamespace, dstNamespace string) error {
+	wantedEndpoints := make(map[string]*corev1.Endpoints)
+	wantedSlices := make(map[string]*discoveryv1.EndpointSlice)
+	if r.selectorlessEndpoints != nil {
+		services, err := r.sourceClient.CoreV1().Services(srcNamespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list services with selectorless endpoints: %w", err)
+		}
+		for i := range services.Items {
+			svc := &services.Items[i]
+			if len(svc.Spec.Selector) > 0 || svc.Spec.Type == corev1.ServiceTypeExternalName || r.shouldSkip(svc) ||
+				r.bridgedService(svc.Name, svc.Labels) || !r.selected("Endpoints", svc.Name) {
+				continue
+			}
+			endpoints, slices, err := r.selectorlessServiceEndpoints(ctx, svc, dstNamespace)
+			if endpoints == nil && slices == nil && err == nil {
+				continue
+			}
+			r.recordResult("Endpoints", svc.Name, err)
+			if endpoints != nil {
+				wantedEndpoints[endpoints.Name] = endpoints
+			}
+			for _, slice := range slices {
+				wantedSlices[slice.Name] = slice
+			}
+		}
+	}
+
+	if err := r.applySyncedEndpoints(ctx, dstNamespace, wantedEndpoints); err != nil {
+		return err
+	}
+	return r.applySyncedEndpointSlices(ctx, dstNamespace, wantedSlices)
+}
+
+// selectorlessServiceEndpoints returns the destination copies of the Endpoints and EndpointSlices of a
+// source Service without a selector, nothing when its destination copy does not exist
+func (r *ResourceSyncer) selectorlessServiceEndpoints(ctx context.Context, svc *corev1.Service, dstNamespace string) (*corev1.Endpoints, []*discoveryv1.EndpointSlice, error) {
+	name := r.destinationName("Service", svc.Name)
+	if _, err := r.destClient.CoreV1().Services(dstNamespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil, nil
+		}
+		return nil, nil, fmt.Errorf("failed to get service %s: %w", name, err)
+	}
+
+	var endpoints *corev1.Endpoints
+	source, err := r.sourceClient.CoreV1().Endpoints(svc.Namespace).Get(ctx, svc.Name, metav1.GetOptions{})
+	switch {
+	case err == nil:
+		endpoints = r.selectorlessEndpoints.endpointsCopy(source, name, dstNamespace)
+	case !apierrors.IsNotFound(err):
+		return nil, nil, fmt.Errorf("failed to get Endpoints of source service %s: %w", svc.Name, err)
+	}
+
+	sourceSlices, err := r.sourceClient.DiscoveryV1().EndpointSlices(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: svc.Name}).String(),
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list EndpointSlices of source service %s: %w", svc.Name, err)
+	}
+	var slices []*discoveryv1.EndpointSlice
+	for i := range sourceSlices.Items {
+		if sourceSlices.Items[i].Labels[discoveryv1.LabelManagedBy] == endpointSliceMirroringManager {
+			continue
+		}
+		slices = append(slices, r.selectorlessEndpoints.endpointSliceCopy(&sourceSlices.Items[i], svc.Name, name, dstNamespace))
+	}
+	if endpoints == nil && len(slices) == 0 {
+		return nil, nil, nil
+	}
+	return endpoints, slices, nil
+}
+
+// applySyncedEndpoints creates and updates the wanted destination Endpoints and deletes the synced
+// Endpoints no longer wanted
+func (r *ResourceSyncer) applySyncedEndpoints(ctx context.Context, dstNamespace string, wanted map[string]*corev1.Endpoints) error {
+	client := r.destClient.CoreV1().Endpoints(dstNamespace)
+	existing, err := client.List(ctx, metav1.ListOptions{LabelSelector: syncedEndpointsLabel})
+	if err != nil {
+		if r.selectorlessEndpoints == nil {
+			// Without selectorless endpoints there is nothing to clean up unless they were synced before
+			log.Debugf("failed to list synced Endpoints in %s: %v", dstNamespace, err)
+			return nil
+		}
+		return fmt.Errorf("failed to list synced Endpoints: %w", err)
+	}
+	for i := range existing.Items {
+		endpoints := &existing.Items[i]
+		ref := corev1.ObjectReference{APIVersion: "v1", Kind: "Endpoints", Namespace: dstNamespace, Name: endpoints.Name}
+		want, ok := wanted[endpoints.Name]
+		if !ok {
+			err := client.Delete(ctx, endpoints.Name, metav1.DeleteOptions{})
+			audit.Record(ctx, audit.OperationDelete, ref, "", err)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete synced Endpoints %s/%s: %w", dstNamespace, endpoints.Name, err)
+			}
+			log.Info(fmt.Sprintf("deleted Endpoints %s/%s of a service no longer synced", dstNamespace, endpoints.Name))
+			continue
+		}
+		delete(wanted, endpoints.Name)
+		r.labelSynced(want)
+		if reflect.DeepEqual(endpoints.Labels, want.Labels) && reflect.DeepEqual(endpoints.Subsets, want.Subsets) {
+			continue
+		}
+		endpoints.Labels = want.Labels
+		endpoints.Subsets = want.Subsets
+		_, err := client.Update(ctx, endpoints, metav1.UpdateOptions{})
+		audit.Record(ctx, audit.OperationUpdate, ref, "", err)
+		if err != nil {
+			return fmt.Errorf("failed to update synced Endpoints %s/%s: %w", dstNamespace, endpoints.Name, err)
+		}
+	}
+
+	names := make([]string, 0, len(wanted))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		endpoints := wanted[name]
+		r.labelSynced(endpoints)
+		ref := corev1.ObjectReference{APIVersion: "v1", Kind: "Endpoints", Namespace: dstNamespace, Name: name}
+		_, err := client.Create(ctx, endpoints, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			// Endpoints created by hand in the destination are kept
+			log.Info(fmt.Sprintf("Endpoints %s/%s exist and are not synced by dr-syncer, keeping them", dstNamespace, name))
+			continue
+		}
+		audit.Record(ctx, audit.OperationCreate, ref, "", err)
+		if err != nil {
+			return fmt.Errorf("failed to create synced Endpoints %s/%s: %w", dstNamespace, name, err)
+		}
+		log.Info(fmt.Sprintf("created Endpoints %s/%s of a service without a selector", dstNamespace, name))
+	}
+	return nil
+}
+
+// applySyncedEndpointSlices creates and updates the wanted destination EndpointSlices and deletes the
+// synced EndpointSlices no longer wanted
+func (r *ResourceSyncer) applySyncedEndpointSlices(ctx context.Context, dstNamespace string, wanted map[string]*discoveryv1.EndpointSlice) error {
+	client := r.destClient.DiscoveryV1().EndpointSlices(dstNamespace)
+	existing, err := client.List(ctx, metav1.ListOptions{LabelSelector: syncedEndpointsLabel})
+	if err != nil {
+		if r.selectorlessEndpoints == nil {
+			log.Debugf("failed to list synced EndpointSlices in %s: %v", dstNamespace, err)
+			return nil
+		}
+		return fmt.Errorf("failed to list synced EndpointSlices: %w", err)
+	}
+	for i := range existing.Items {
+		slice := &existing.Items[i]
+		ref := corev1.ObjectReference{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice", Namespace: dstNamespace, Name: slice.Name}
+		want, ok := wanted[slice.Name]
+		if !ok {
+			err := client.Delete(ctx, slice.Name, metav1.DeleteOptions{})
+			audit.Record(ctx, audit.OperationDelete, ref, "", err)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete synced EndpointSlice %s/%s: %w", dstNamespace, slice.Name, err)
+			}
+			log.Info(fmt.Sprintf("deleted EndpointSlice %s/%s of a service no longer synced", dstNamespace, slice.Name))
+			continue
+		}
+		delete(wanted, slice.Name)
+		r.labelSynced(want)
+		if slice.AddressType == want.AddressType && reflect.DeepEqual(slice.Labels, want.Labels) &&
+			reflect.DeepEqual(slice.Endpoints, want.Endpoints) && reflect.DeepEqual(slice.Ports, want.Ports) {
+			continue
+		}
+		// The address type of an EndpointSlice cannot change
+		if slice.AddressType != want.AddressType {
+			err := client.Delete(ctx, slice.Name, metav1.DeleteOptions{})
+			audit.Record(ctx, audit.OperationDelete, ref, "", err)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete synced EndpointSlice %s/%s: %w", dstNamespace, slice.Name, err)
+			}
+			wanted[slice.Name] = want
+			continue
+		}
+		slice.Labels = want.Labels
+		slice.Endpoints = want.Endpoints
+		slice.Ports = want.Ports
+		_, err := client.Update(ctx, slice, metav1.UpdateOptions{})
+		audit.Record(ctx, audit.OperationUpdate, ref, "", err)
+		if err != nil {
+			return fmt.Errorf("failed to update synced EndpointSlice %s/%s: %w", dstNamespace, slice.Name, err)
+		}
+	}
+
+	names := make([]string, 0, len(wanted))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		slice := wanted[name]
+		r.labelSynced(slice)
+		ref := corev1.ObjectReference{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice", Namespace: dstNamespace, Name: name}
+		_, err := client.Create(ctx, slice, metav1.CreateOptions{})
+		if apierrors.IsAlreadyExists(err) {
+			log.Info(fmt.Sprintf("EndpointSlice %s/%s exists and is not synced by dr-syncer, keeping it", dstNamespace, name))
+			continue
+		}
+		audit.Record(ctx, audit.OperationCreate, ref, "", err)
+		if err != nil {
+			return fmt.Errorf("failed to create synced EndpointSlice %s/%s: %w", dstNamespace, name, err)
+		}
+		log.Info(fmt.Sprintf("created EndpointSlice %s/%s of service %s without a selector", dstNamespace, name, slice.Labels[syncedEndpointsLabel]))
+	}
+	return nil
+}
@@ -0,0 +1,183 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestRewriteAddress(t *testing.T) {
+	rewrites, err := compileAddressRewrites([]drv1alpha1.AddressRewrite{
+		{From: "10.0.0.5", To: "10.100.0.5"},
+		{From: "10.0.0.0/16", To: "10.200.0.0/16"},
+		{From: "2001:db8::/64", To: "2001:db8:1::/64"},
+		{From: "192.168.0.0/24", To: "172.16.0.1"},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "10.100.0.5", rewriteAddress(rewrites, "10.0.0.5"))
+	assert.Equal(t, "10.200.3.4", rewriteAddress(rewrites, "10.0.3.4"))
+	assert.Equal(t, "2001:db8:1::42", rewriteAddress(rewrites, "2001:db8::42"))
+	assert.Equal(t, "172.16.0.1", rewriteAddress(rewrites, "192.168.0.77"))
+	assert.Equal(t, "10.1.0.1", rewriteAddress(rewrites, "10.1.0.1"))
+	assert.Equal(t, "db.example.com", rewriteAddress(rewrites, "db.example.com"))
+
+	for _, invalid := range []drv1alpha1.AddressRewrite{
+		{From: "db.example.com", To: "10.0.0.1"},
+		{From: "10.0.0.1", To: "10.1.0.0/16"},
+		{From: "10.0.0.0/16", To: "10.1.0.0/24"},
+		{From: "10.0.0.1", To: "2001:db8::1"},
+	} {
+		_, err := compileAddressRewrites([]drv1alpha1.AddressRewrite{invalid})
+		assert.Error(t, err, "%s -> %s", invalid.From, invalid.To)
+	}
+}
+
+func TestCompileSelectorlessEndpoints(t *testing.T) {
+	disabled := false
+	enabled := true
+
+	compiled, err := compileSelectorlessEndpoints(nil)
+	require.NoError(t, err)
+	assert.Nil(t, compiled)
+	compiled, err = compileSelectorlessEndpoints(&drv1alpha1.SelectorlessEndpointsConfig{Enabled: &disabled})
+	require.NoError(t, err)
+	assert.Nil(t, compiled)
+
+	compiled, err = compileSelectorlessEndpoints(&drv1alpha1.SelectorlessEndpointsConfig{Enabled: &enabled})
+	require.NoError(t, err)
+	assert.NotNil(t, compiled)
+
+	_, err = compileSelectorlessEndpoints(&drv1alpha1.SelectorlessEndpointsConfig{
+		Enabled:         &enabled,
+		AddressRewrites: []drv1alpha1.AddressRewrite{{From: "nope", To: "10.0.0.1"}},
+	})
+	assert.Error(t, err)
+}
+
+func selectorlessTestObjects(namespace string) []runtime.Object {
+	external := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy-db", Namespace: namespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Name: "sql", Protocol: corev1.ProtocolTCP, Port: 5432}}},
+	}
+	selected := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: namespace},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "web"}},
+	}
+	return []runtime.Object{external, selected}
+}
+
+func TestSyncSelectorlessEndpoints(t *testing.T) {
+	ctx := context.Background()
+
+	source := append(selectorlessTestObjects("shop"),
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy-db", Namespace: "shop", Labels: map[string]string{"tier": "data"}},
+			Subsets: []corev1.EndpointSubset{{
+				Addresses: []corev1.EndpointAddress{{IP: "10.0.0.5", NodeName: ptr.To("node-a")}},
+				Ports:     []corev1.EndpointPort{{Name: "sql", Port: 5432}},
+			}},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.1.0.9"}}}},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy-db-manual", Namespace: "shop", Labels: map[string]string{
+				discoveryv1.LabelServiceName: "legacy-db",
+				discoveryv1.LabelManagedBy:   "team-dba",
+			}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{{
+				Addresses:  []string{"10.0.0.6"},
+				Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(true)},
+				Zone:       ptr.To("zone-a"),
+			}},
+			Ports: []discoveryv1.EndpointPort{{Name: ptr.To("sql"), Port: ptr.To(int32(5432))}},
+		},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{Name: "legacy-db-mirrored", Namespace: "shop", Labels: map[string]string{
+				discoveryv1.LabelServiceName: "legacy-db",
+				discoveryv1.LabelManagedBy:   endpointSliceMirroringManager,
+			}},
+			AddressType: discoveryv1.AddressTypeIPv4,
+		},
+	)
+	dest := selectorlessTestObjects("shop-dr")
+
+	syncer := NewResourceSyncer(nil, nil, nil, fake.NewSimpleClientset(source...), fake.NewSimpleClientset(dest...), nil)
+	syncer.mappingLabels = utils.MappingLabels("team", "shop")
+	enabled := true
+	selectorless, err := compileSelectorlessEndpoints(&drv1alpha1.SelectorlessEndpointsConfig{
+		Enabled:         &enabled,
+		AddressRewrites: []drv1alpha1.AddressRewrite{{From: "10.0.0.0/24", To: "10.100.0.0/24"}},
+	})
+	require.NoError(t, err)
+	syncer.selectorlessEndpoints = selectorless
+
+	// The Endpoints and the EndpointSlice managed by hand are copied with rewritten addresses
+	require.NoError(t, syncer.syncSelectorlessEndpoints(ctx, "shop", "shop-dr"))
+	endpoints, err := syncer.destClient.CoreV1().Endpoints("shop-dr").Get(ctx, "legacy-db", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, endpoints.Subsets, 1)
+	assert.Equal(t, []corev1.EndpointAddress{{IP: "10.100.0.5"}}, endpoints.Subsets[0].Addresses)
+	assert.Equal(t, "data", endpoints.Labels["tier"])
+	assert.Equal(t, "shop", endpoints.Labels[utils.MappingNameLabel])
+	assert.Equal(t, 1, syncer.syncedCount)
+
+	slice, err := syncer.destClient.DiscoveryV1().EndpointSlices("shop-dr").Get(ctx, "legacy-db-manual", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"10.100.0.6"}, slice.Endpoints[0].Addresses)
+	assert.Nil(t, slice.Endpoints[0].Zone)
+	assert.Equal(t, serviceBridgeManager, slice.Labels[discoveryv1.LabelManagedBy])
+	assert.Equal(t, "legacy-db", slice.Labels[syncedEndpointsLabel])
+
+	// Neither the mirrored EndpointSlice nor the endpoints of Services with a selector are synced
+	_, err = syncer.destClient.DiscoveryV1().EndpointSlices("shop-dr").Get(ctx, "legacy-db-mirrored", metav1.GetOptions{})
+	assert.Error(t, err)
+	_, err = syncer.destClient.CoreV1().Endpoints("shop-dr").Get(ctx, "web", metav1.GetOptions{})
+	assert.Error(t, err)
+
+	// Source changes are synced
+	sourceEndpoints, err := syncer.sourceClient.CoreV1().Endpoints("shop").Get(ctx, "legacy-db", metav1.GetOptions{})
+	require.NoError(t, err)
+	sourceEndpoints.Subsets[0].Addresses[0].IP = "10.0.0.7"
+	_, err = syncer.sourceClient.CoreV1().Endpoints("shop").Update(ctx, sourceEndpoints, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, syncer.syncSelectorlessEndpoints(ctx, "shop", "shop-dr"))
+	endpoints, err = syncer.destClient.CoreV1().Endpoints("shop-dr").Get(ctx, "legacy-db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "10.100.0.7", endpoints.Subsets[0].Addresses[0].IP)
+
+	// Disabling the sync removes the synced endpoints
+	syncer.selectorlessEndpoints = nil
+	require.NoError(t, syncer.syncSelectorlessEndpoints(ctx, "shop", "shop-dr"))
+	_, err = syncer.destClient.CoreV1().Endpoints("shop-dr").Get(ctx, "legacy-db", metav1.GetOptions{})
+	assert.Error(t, err)
+	assert.Empty(t, bridgeSliceNames(t, syncer, "shop-dr"))
+}
+
+func TestSyncSelectorlessEndpoints_DestinationServiceMissing(t *testing.T) {
+	ctx := context.Background()
+	source := append(selectorlessTestObjects("shop"), &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy-db", Namespace: "shop"},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.5"}}}},
+	})
+	syncer := NewResourceSyncer(nil, nil, nil, fake.NewSimpleClientset(source...), fake.NewSimpleClientset(), nil)
+	syncer.selectorlessEndpoints = &selectorlessEndpoints{}
+
+	require.NoError(t, syncer.syncSelectorlessEndpoints(ctx, "shop", "shop-dr"))
+	_, err := syncer.destClient.CoreV1().Endpoints("shop-dr").Get(ctx, "legacy-db", metav1.GetOptions{})
+	assert.Error(t, err)
+	assert.Equal(t, 0, syncer.syncedCount)
+}
@@ -0,0 +1,371 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// serviceBridgeAnnotation marks the destination Services bridged to their source endpoints, its
+	// value is the bridge mode
+	serviceBridgeAnnotation = "dr-syncer.io/service-bridge"
+
+	// serviceBridgeLabel marks the EndpointSlices of bridge Services, its value is the Service name
+	serviceBridgeLabel = "dr-syncer.io/service-bridge"
+
+	// serviceBridgeManager is the endpointslice.kubernetes.io/managed-by value of bridge EndpointSlices,
+	// so the EndpointSlice controller leaves them alone
+	serviceBridgeManager = "dr-syncer.io"
+)
+
+// serviceBridge selects the source Services bridged to the destination namespace
+type serviceBridge struct {
+	mode      drv1alpha1.ServiceBridgeMode
+	addresses drv1alpha1.ServiceBridgeAddresses
+	names     map[string]bool
+	selector  labels.Selector
+}
+
+// compileServiceBridge compiles the service bridge of a mapping, nil when it bridges no Services
+func compileServiceBridge(config *drv1alpha1.ServiceBridgeConfig) (*serviceBridge, error) {
+	if config == nil {
+		return nil, nil
+	}
+	bridge := &serviceBridge{
+		mode:      config.Mode,
+		addresses: config.Addresses,
+		names:     make(map[string]bool, len(config.Services)),
+	}
+	if bridge.mode == "" {
+		bridge.mode = drv1alpha1.ServiceBridgeModeEndpointSlice
+	}
+	if bridge.addresses == "" {
+		bridge.addresses = drv1alpha1.ServiceBridgeAddressesLoadBalancer
+	}
+	for _, name := range config.Services {
+		bridge.names[name] = true
+	}
+	if config.Selector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(config.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid service bridge selector: %w", err)
+		}
+		bridge.selector = selector
+	}
+	return bridge, nil
+}
+
+// bridged reports whether a source Service is bridged instead of synced
+func (b *serviceBridge) bridged(name string, serviceLabels map[string]string) bool {
+	if b == nil {
+		return false
+	}
+	return b.names[name] || (b.selector != nil && b.selector.Matches(labels.Set(serviceLabels)))
+}
+
+// bridgedService reports whether the syncer bridges a source Service instead of syncing it
+func (r *ResourceSyncer) bridgedService(name string, serviceLabels map[string]string) bool {
+	return r != nil && r.serviceBridge.bridged(name, serviceLabels)
+}
+
+// bridgeServicePorts returns the ports of a bridge Service, without the node ports and target ports the
+// source cluster assigned
+func bridgeServicePorts(svc *corev1.Service) []corev1.ServicePort {
+	ports := make([]corev1.ServicePort, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		ports = append(ports, corev1.ServicePort{
+			Name:        port.Name,
+			Protocol:    port.Protocol,
+			AppProtocol: port.AppProtocol,
+			Port:        port.Port,
+		})
+	}
+	return ports
+}
+
+// bridgeService returns the destination Service bridging a source Service
+func (b *serviceBridge) bridgeService(svc *corev1.Service, name, namespace string) (*corev1.Service, error) {
+	bridge := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			Labels:      make(map[string]string, len(svc.Labels)+1),
+			Annotations: map[string]string{serviceBridgeAnnotation: string(b.mode)},
+		},
+		Spec: corev1.ServiceSpec{Ports: bridgeServicePorts(svc)},
+	}
+	for key, value := range svc.Labels {
+		bridge.Labels[key] = value
+	}
+	bridge.Labels[utils.ManagedByLabel] = utils.ManagedByValue
+
+	if b.mode != drv1alpha1.ServiceBridgeModeExternalName {
+		bridge.Spec.Type = corev1.ServiceTypeClusterIP
+		return bridge, nil
+	}
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		if ingress.Hostname != "" {
+			bridge.Spec.Type = corev1.ServiceTypeExternalName
+			bridge.Spec.ExternalName = ingress.Hostname
+			return bridge, nil
+		}
+	}
+	return nil, fmt.Errorf("source service %s has no LoadBalancer hostname for an ExternalName bridge", svc.Name)
+}
+
+// bridgeEndpointSlice returns an EndpointSlice of a bridge Service
+func bridgeEndpointSlice(service, name, namespace string, addressType discoveryv1.AddressType, ports []discoveryv1.EndpointPort) *discoveryv1.EndpointSlice {
+	return &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName: service,
+				discoveryv1.LabelManagedBy:   serviceBridgeManager,
+				serviceBridgeLabel:           service,
+				utils.ManagedByLabel:         utils.ManagedByValue,
+			},
+		},
+		AddressType: addressType,
+		Ports:       ports,
+	}
+}
+
+// readyEndpoint returns a ready endpoint of addresses in another cluster, without the node and pod
+// references only valid in that cluster
+func readyEndpoint(addresses ...string) discoveryv1.Endpoint {
+	ready := true
+	return discoveryv1.Endpoint{Addresses: addresses, Conditions: discoveryv1.EndpointConditions{Ready: &ready}}
+}
+
+// loadBalancerSlices returns the EndpointSlices of a bridge Service pointing at the LoadBalancer ingress
+// IPs of the source Service, one per IP family
+func loadBalancerSlices(svc *corev1.Service, service, namespace string) ([]*discoveryv1.EndpointSlice, error) {
+	ports := make([]discoveryv1.EndpointPort, 0, len(svc.Spec.Ports))
+	for _, port := range svc.Spec.Ports {
+		port := port
+		ports = append(ports, discoveryv1.EndpointPort{Name: &port.Name, Protocol: &port.Protocol, AppProtocol: port.AppProtocol, Port: &port.Port})
+	}
+
+	slices := make(map[discoveryv1.AddressType]*discoveryv1.EndpointSlice)
+	for _, ingress := range svc.Status.LoadBalancer.Ingress {
+		ip := net.ParseIP(ingress.IP)
+		if ip == nil {
+			continue
+		}
+		addressType := discoveryv1.AddressTypeIPv6
+		if ip.To4() != nil {
+			addressType = discoveryv1.AddressTypeIPv4
+		}
+		slice, ok := slices[addressType]
+		if !ok {
+			name := fmt.Sprintf("%s-dr-bridge-%s", service, strings.ToLower(string(addressType)))
+			slice = bridgeEndpointSlice(service, name, namespace, addressType, ports)
+			slices[addressType] = slice
+		}
+		slice.Endpoints = append(slice.Endpoints, readyEndpoint(ingress.IP))
+	}
+	if len(slices) == 0 {
+		return nil, fmt.Errorf("source service %s has no LoadBalancer IP for an EndpointSlice bridge", svc.Name)
+	}
+
+	result := make([]*discoveryv1.EndpointSlice, 0, len(slices))
+	for _, slice := range slices {
+		result = append(result, slice)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// podSlices returns the EndpointSlices of a bridge Service pointing at the ready pod IPs of the source
+// Service, one per source EndpointSlice so the ports of each set of pods are kept
+func podSlices(sourceSlices []discoveryv1.EndpointSlice, source, service, namespace string) []*discoveryv1.EndpointSlice {
+	var result []*discoveryv1.EndpointSlice
+	for _, sourceSlice := range sourceSlices {
+		if sourceSlice.AddressType == discoveryv1.AddressTypeFQDN {
+			continue
+		}
+		name := fmt.Sprintf("%s-dr-bridge-%s", service, strings.TrimPrefix(sourceSlice.Name, source+"-"))
+		slice := bridgeEndpointSlice(service, name, namespace, sourceSlice.AddressType, sourceSlice.Ports)
+		for _, endpoint := range sourceSlice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			slice.Endpoints = append(slice.Endpoints, readyEndpoint(endpoint.Addresses...))
+		}
+		result = append(result, slice)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// bridgeServices replaces the bridged destination Services with bridges to the endpoints of their source
+// Services, and removes the EndpointSlices of the Services no longer bridged
+func (r *ResourceSyncer) bridgeServices(ctx context.Context, srcNamespace, dstNamespace string) error {
+	wanted := make(map[string]*discoveryv1.EndpointSlice)
+	if r.serviceBridge != nil {
+		services, err := r.sourceClient.CoreV1().Services(srcNamespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list services to bridge: %w", err)
+		}
+		for i := range services.Items {
+			svc := &services.Items[i]
+			if r.shouldSkip(svc) || !r.bridgedService(svc.Name, svc.Labels) || !r.selected("Service", svc.Name) {
+				continue
+			}
+			slices, err := r.bridgeService(ctx, svc, dstNamespace)
+			r.recordResult("Service", svc.Name, err)
+			for _, slice := range slices {
+				wanted[slice.Name] = slice
+			}
+		}
+	}
+
+	endpointSlices := r.destClient.DiscoveryV1().EndpointSlices(dstNamespace)
+	existing, err := endpointSlices.List(ctx, metav1.ListOptions{LabelSelector: serviceBridgeLabel})
+	if err != nil {
+		if r.serviceBridge == nil {
+			// Without a service bridge there is nothing to clean up unless one was configured before
+			log.Debugf("failed to list bridge EndpointSlices in %s: %v", dstNamespace, err)
+			return nil
+		}
+		return fmt.Errorf("failed to list bridge EndpointSlices: %w", err)
+	}
+	for i := range existing.Items {
+		slice := &existing.Items[i]
+		ref := corev1.ObjectReference{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice", Namespace: dstNamespace, Name: slice.Name}
+		want, ok := wanted[slice.Name]
+		if !ok {
+			err := endpointSlices.Delete(ctx, slice.Name, metav1.DeleteOptions{})
+			audit.Record(ctx, audit.OperationDelete, ref, "", err)
+			if err != nil && !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to delete bridge EndpointSlice %s/%s: %w", dstNamespace, slice.Name, err)
+			}
+			log.Info(fmt.Sprintf("deleted EndpointSlice %s/%s of a service no longer bridged", dstNamespace, slice.Name))
+			continue
+		}
+		delete(wanted, slice.Name)
+		r.labelSynced(want)
+		slice.Labels = want.Labels
+		slice.AddressType = want.AddressType
+		slice.Endpoints = want.Endpoints
+		slice.Ports = want.Ports
+		_, err := endpointSlices.Update(ctx, slice, metav1.UpdateOptions{})
+		audit.Record(ctx, audit.OperationUpdate, ref, "", err)
+		if err != nil {
+			return fmt.Errorf("failed to update bridge EndpointSlice %s/%s: %w", dstNamespace, slice.Name, err)
+		}
+	}
+
+	names := make([]string, 0, len(wanted))
+	for name := range wanted {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		slice := wanted[name]
+		r.labelSynced(slice)
+		ref := corev1.ObjectReference{APIVersion: "discovery.k8s.io/v1", Kind: "EndpointSlice", Namespace: dstNamespace, Name: name}
+		_, err := endpointSlices.Create(ctx, slice, metav1.CreateOptions{})
+		audit.Record(ctx, audit.OperationCreate, ref, "", err)
+		if err != nil {
+			return fmt.Errorf("failed to create bridge EndpointSlice %s/%s: %w", dstNamespace, name, err)
+		}
+		log.Info(fmt.Sprintf("created EndpointSlice %s/%s bridging service %s", dstNamespace, name, slice.Labels[serviceBridgeLabel]))
+	}
+	return nil
+}
+
+// bridgeService creates or updates the destination Service bridging a source Service and returns the
+// EndpointSlices it needs
+func (r *ResourceSyncer) bridgeService(ctx context.Context, svc *corev1.Service, dstNamespace string) ([]*discoveryv1.EndpointSlice, error) {
+	name := r.destinationName("Service", svc.Name)
+	bridge, err := r.serviceBridge.bridgeService(svc, name, dstNamespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var slices []*discoveryv1.EndpointSlice
+	switch {
+	case bridge.Spec.Type == corev1.ServiceTypeExternalName:
+	case r.serviceBridge.addresses == drv1alpha1.ServiceBridgeAddressesPod:
+		sourceSlices, err := r.sourceClient.DiscoveryV1().EndpointSlices(svc.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: labels.SelectorFromSet(labels.Set{discoveryv1.LabelServiceName: svc.Name}).String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list EndpointSlices of source service %s: %w", svc.Name, err)
+		}
+		slices = podSlices(sourceSlices.Items, svc.Name, name, dstNamespace)
+	default:
+		if slices, err = loadBalancerSlices(svc, name, dstNamespace); err != nil {
+			return nil, err
+		}
+	}
+
+	r.labelSynced(bridge)
+	services := r.destClient.CoreV1().Services(dstNamespace)
+	ref := corev1.ObjectReference{APIVersion: "v1", Kind: "Service", Namespace: dstNamespace, Name: name}
+	existing, err := services.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		_, err = services.Create(ctx, bridge, metav1.CreateOptions{})
+		audit.Record(ctx, audit.OperationCreate, ref, "", err)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create bridge service %s: %w", name, err)
+		}
+		log.Info(fmt.Sprintf("created service %s/%s bridged to its source endpoints (%s)", dstNamespace, name, bridge.Annotations[serviceBridgeAnnotation]))
+		return slices, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to get service %s: %w", name, err)
+	}
+
+	if err := r.checkOwnership(ctx, "Service", existing); err != nil {
+		return nil, err
+	}
+	updated := existing.DeepCopy()
+	updated.Labels = bridge.Labels
+	updated.Annotations = bridge.Annotations
+	updated.Spec.Type = bridge.Spec.Type
+	updated.Spec.ExternalName = bridge.Spec.ExternalName
+	updated.Spec.Selector = nil
+	updated.Spec.Ports = bridge.Spec.Ports
+	updated.Spec.LoadBalancerIP = ""
+	updated.Spec.LoadBalancerSourceRanges = nil
+	updated.Spec.LoadBalancerClass = nil
+	updated.Spec.AllocateLoadBalancerNodePorts = nil
+	updated.Spec.ExternalTrafficPolicy = ""
+	updated.Spec.HealthCheckNodePort = 0
+	if bridge.Spec.Type == corev1.ServiceTypeExternalName {
+		updated.Spec.ClusterIP = ""
+		updated.Spec.ClusterIPs = nil
+		updated.Spec.IPFamilies = nil
+		updated.Spec.IPFamilyPolicy = nil
+		updated.Spec.InternalTrafficPolicy = nil
+		updated.Spec.SessionAffinity = ""
+	} else if existing.Spec.Type == corev1.ServiceTypeExternalName {
+		updated.Spec.ExternalName = ""
+	}
+	if reflect.DeepEqual(existing, updated) {
+		return slices, nil
+	}
+	_, err = services.Update(ctx, updated, metav1.UpdateOptions{})
+	r.recordUpdate(ctx, corev1.SchemeGroupVersion.WithKind("Service"), updated, audit.DiffObjects(existing, updated), err)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update bridge service %s: %w", name, err)
+	}
+	log.Info(fmt.Sprintf("bridged service %s/%s to its source endpoints (%s)", dstNamespace, name, bridge.Annotations[serviceBridgeAnnotation]))
+	return slices, nil
+}
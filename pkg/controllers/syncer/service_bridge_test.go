@@ -0,0 +1,150 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func bridgeTestService() *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop", Labels: map[string]string{"tier": "data"}},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeLoadBalancer,
+			Selector: map[string]string{"app": "db"},
+			Ports:    []corev1.ServicePort{{Name: "sql", Protocol: corev1.ProtocolTCP, Port: 5432, NodePort: 31432}},
+		},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{Ingress: []corev1.LoadBalancerIngress{
+			{IP: "203.0.113.10"},
+			{IP: "2001:db8::10"},
+			{Hostname: "db.prod.example.com"},
+		}}},
+	}
+}
+
+func bridgeSliceNames(t *testing.T, syncer *ResourceSyncer, namespace string) []string {
+	slices, err := syncer.destClient.DiscoveryV1().EndpointSlices(namespace).List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	var names []string
+	for _, slice := range slices.Items {
+		names = append(names, slice.Name)
+	}
+	return names
+}
+
+func TestServiceBridge_Bridged(t *testing.T) {
+	bridge, err := compileServiceBridge(&drv1alpha1.ServiceBridgeConfig{
+		Services: []string{"cache"},
+		Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"tier": "data"}},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, drv1alpha1.ServiceBridgeModeEndpointSlice, bridge.mode)
+	assert.Equal(t, drv1alpha1.ServiceBridgeAddressesLoadBalancer, bridge.addresses)
+
+	assert.True(t, bridge.bridged("cache", nil))
+	assert.True(t, bridge.bridged("db", map[string]string{"tier": "data"}))
+	assert.False(t, bridge.bridged("web", map[string]string{"tier": "frontend"}))
+	assert.False(t, (*serviceBridge)(nil).bridged("cache", nil))
+	assert.True(t, (&ResourceSyncer{serviceBridge: bridge}).bridgedService("cache", nil))
+	assert.False(t, (*ResourceSyncer)(nil).bridgedService("cache", nil))
+
+	_, err = compileServiceBridge(&drv1alpha1.ServiceBridgeConfig{Selector: &metav1.LabelSelector{
+		MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "tier", Operator: "Near"}},
+	}})
+	assert.Error(t, err)
+}
+
+func TestLoadBalancerSlices(t *testing.T) {
+	slices, err := loadBalancerSlices(bridgeTestService(), "db", "shop-dr")
+	require.NoError(t, err)
+	require.Len(t, slices, 2)
+
+	assert.Equal(t, "db-dr-bridge-ipv4", slices[0].Name)
+	assert.Equal(t, discoveryv1.AddressTypeIPv4, slices[0].AddressType)
+	assert.Equal(t, []string{"203.0.113.10"}, slices[0].Endpoints[0].Addresses)
+	assert.Equal(t, "db", slices[0].Labels[discoveryv1.LabelServiceName])
+	assert.Equal(t, serviceBridgeManager, slices[0].Labels[discoveryv1.LabelManagedBy])
+	assert.Equal(t, int32(5432), *slices[0].Ports[0].Port)
+	assert.Equal(t, "sql", *slices[0].Ports[0].Name)
+	assert.Equal(t, "db-dr-bridge-ipv6", slices[1].Name)
+
+	svc := bridgeTestService()
+	svc.Status.LoadBalancer.Ingress = nil
+	_, err = loadBalancerSlices(svc, "db", "shop-dr")
+	assert.Error(t, err, "a LoadBalancer without an address cannot be bridged")
+}
+
+func TestPodSlices(t *testing.T) {
+	notReady := false
+	port, name := int32(8432), "sql"
+	slices := podSlices([]discoveryv1.EndpointSlice{{
+		ObjectMeta:  metav1.ObjectMeta{Name: "db-x7k2p"},
+		AddressType: discoveryv1.AddressTypeIPv4,
+		Ports:       []discoveryv1.EndpointPort{{Name: &name, Port: &port}},
+		Endpoints: []discoveryv1.Endpoint{
+			{Addresses: []string{"10.1.0.5"}, NodeName: &name, TargetRef: &corev1.ObjectReference{Kind: "Pod", Name: "db-0"}},
+			{Addresses: []string{"10.1.0.6"}, Conditions: discoveryv1.EndpointConditions{Ready: &notReady}},
+		},
+	}}, "db", "db", "shop-dr")
+
+	require.Len(t, slices, 1)
+	assert.Equal(t, "db-dr-bridge-x7k2p", slices[0].Name)
+	assert.Equal(t, int32(8432), *slices[0].Ports[0].Port, "pod slices keep the target ports")
+	require.Len(t, slices[0].Endpoints, 1)
+	assert.Equal(t, []string{"10.1.0.5"}, slices[0].Endpoints[0].Addresses)
+	assert.Nil(t, slices[0].Endpoints[0].NodeName)
+	assert.Nil(t, slices[0].Endpoints[0].TargetRef)
+	assert.True(t, *slices[0].Endpoints[0].Conditions.Ready)
+}
+
+func TestBridgeServices(t *testing.T) {
+	ctx := context.Background()
+	synced := bridgeTestService()
+	synced.Namespace = "shop-dr"
+	synced.Status = corev1.ServiceStatus{}
+	syncer := NewResourceSyncer(nil, nil, nil, fake.NewSimpleClientset(bridgeTestService()), fake.NewSimpleClientset(synced), nil)
+	syncer.mappingLabels = utils.MappingLabels("team", "shop")
+	bridge, err := compileServiceBridge(&drv1alpha1.ServiceBridgeConfig{Services: []string{"db"}})
+	require.NoError(t, err)
+	syncer.serviceBridge = bridge
+
+	// The synced Service loses its selector and points at the source LoadBalancer
+	require.NoError(t, syncer.bridgeServices(ctx, "shop", "shop-dr"))
+	svc, err := syncer.destClient.CoreV1().Services("shop-dr").Get(ctx, "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Nil(t, svc.Spec.Selector)
+	assert.Equal(t, corev1.ServiceTypeClusterIP, svc.Spec.Type)
+	assert.Equal(t, string(drv1alpha1.ServiceBridgeModeEndpointSlice), svc.Annotations[serviceBridgeAnnotation])
+	assert.Equal(t, int32(0), svc.Spec.Ports[0].NodePort)
+	assert.Equal(t, "shop", svc.Labels[utils.MappingNameLabel])
+	assert.ElementsMatch(t, []string{"db-dr-bridge-ipv4", "db-dr-bridge-ipv6"}, bridgeSliceNames(t, syncer, "shop-dr"))
+	assert.Equal(t, 1, syncer.syncedCount)
+
+	// Bridging again is idempotent
+	require.NoError(t, syncer.bridgeServices(ctx, "shop", "shop-dr"))
+	assert.Len(t, bridgeSliceNames(t, syncer, "shop-dr"), 2)
+
+	// ExternalName bridges resolve to the LoadBalancer hostname and need no EndpointSlices
+	bridge.mode = drv1alpha1.ServiceBridgeModeExternalName
+	require.NoError(t, syncer.bridgeServices(ctx, "shop", "shop-dr"))
+	svc, err = syncer.destClient.CoreV1().Services("shop-dr").Get(ctx, "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, corev1.ServiceTypeExternalName, svc.Spec.Type)
+	assert.Equal(t, "db.prod.example.com", svc.Spec.ExternalName)
+	assert.Empty(t, bridgeSliceNames(t, syncer, "shop-dr"))
+
+	// Removing the bridge removes its EndpointSlices, the Service is synced again
+	bridge.mode = drv1alpha1.ServiceBridgeModeEndpointSlice
+	require.NoError(t, syncer.bridgeServices(ctx, "shop", "shop-dr"))
+	syncer.serviceBridge = nil
+	require.NoError(t, syncer.bridgeServices(ctx, "shop", "shop-dr"))
+	assert.Empty(t, bridgeSliceNames(t, syncer, "shop-dr"))
+}
@@ -0,0 +1,71 @@
+package syncer
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// servicesResource identifies Services synced through the dynamic client
+var servicesResource = schema.GroupResource{Resource: "services"}
+
+// loadBalancerFields are only valid on LoadBalancer services and are removed when converting to ClusterIP
+var loadBalancerFields = []string{
+	"loadBalancerIP",
+	"loadBalancerSourceRanges",
+	"loadBalancerClass",
+	"allocateLoadBalancerNodePorts",
+	"externalTrafficPolicy",
+}
+
+// prepareService clears the fields of a Service that are assigned by the source cluster, so it can be
+// created in the destination cluster. Headless services stay headless, ExternalName services keep
+// only their external name, and LoadBalancer services are converted to ClusterIP when configured.
+func (r *ResourceSyncer) prepareService(svc *unstructured.Unstructured) {
+	serviceType, _, _ := unstructured.NestedString(svc.Object, "spec", "type")
+	clusterIP, _, _ := unstructured.NestedString(svc.Object, "spec", "clusterIP")
+
+	unstructured.RemoveNestedField(svc.Object, "spec", "clusterIPs")
+	unstructured.RemoveNestedField(svc.Object, "spec", "ipFamilies")
+	unstructured.RemoveNestedField(svc.Object, "status")
+
+	switch {
+	case serviceType == string(corev1.ServiceTypeExternalName):
+		// ExternalName services have no cluster IP or IP families in the API server
+		unstructured.RemoveNestedField(svc.Object, "spec", "clusterIP")
+		unstructured.RemoveNestedField(svc.Object, "spec", "ipFamilyPolicy")
+	case clusterIP == corev1.ClusterIPNone:
+		// Headless services are requested with clusterIP None, which is not allocated
+	default:
+		unstructured.RemoveNestedField(svc.Object, "spec", "clusterIP")
+	}
+
+	preserveNodePorts := r != nil && r.preserveNodePorts
+	if serviceType == string(corev1.ServiceTypeLoadBalancer) && r != nil && r.convertLoadBalancers {
+		if err := unstructured.SetNestedField(svc.Object, string(corev1.ServiceTypeClusterIP), "spec", "type"); err != nil {
+			log.Errorf("failed to convert service %s to ClusterIP: %v", svc.GetName(), err)
+			return
+		}
+		for _, field := range loadBalancerFields {
+			unstructured.RemoveNestedField(svc.Object, "spec", field)
+		}
+		preserveNodePorts = false
+	}
+
+	if preserveNodePorts {
+		return
+	}
+	unstructured.RemoveNestedField(svc.Object, "spec", "healthCheckNodePort")
+	ports, found, err := unstructured.NestedSlice(svc.Object, "spec", "ports")
+	if err != nil || !found {
+		return
+	}
+	for _, p := range ports {
+		if port, ok := p.(map[string]interface{}); ok {
+			delete(port, "nodePort")
+		}
+	}
+	if err := unstructured.SetNestedSlice(svc.Object, ports, "spec", "ports"); err != nil {
+		log.Errorf("failed to clear node ports of service %s: %v", svc.GetName(), err)
+	}
+}
@@ -0,0 +1,136 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func newService(spec map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Service",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "app-dr"},
+		"spec":       spec,
+		"status":     map[string]interface{}{"loadBalancer": map[string]interface{}{"ingress": []interface{}{map[string]interface{}{"ip": "203.0.113.10"}}}},
+	}}
+}
+
+func TestPrepareService_ClusterAssignedFields(t *testing.T) {
+	svc := newService(map[string]interface{}{
+		"type":           "ClusterIP",
+		"clusterIP":      "10.96.0.15",
+		"clusterIPs":     []interface{}{"10.96.0.15"},
+		"ipFamilies":     []interface{}{"IPv4"},
+		"ipFamilyPolicy": "SingleStack",
+		"ports":          []interface{}{map[string]interface{}{"port": int64(80)}},
+	})
+	(&ResourceSyncer{}).prepareService(svc)
+
+	spec := svc.Object["spec"].(map[string]interface{})
+	assert.NotContains(t, spec, "clusterIP")
+	assert.NotContains(t, spec, "clusterIPs")
+	assert.NotContains(t, spec, "ipFamilies")
+	assert.Equal(t, "SingleStack", spec["ipFamilyPolicy"])
+	assert.NotContains(t, svc.Object, "status")
+}
+
+func TestPrepareService_Headless(t *testing.T) {
+	svc := newService(map[string]interface{}{
+		"clusterIP":  "None",
+		"clusterIPs": []interface{}{"None"},
+	})
+	(&ResourceSyncer{}).prepareService(svc)
+
+	clusterIP, _, _ := unstructured.NestedString(svc.Object, "spec", "clusterIP")
+	assert.Equal(t, "None", clusterIP)
+	assert.NotContains(t, svc.Object["spec"], "clusterIPs")
+}
+
+func TestPrepareService_ExternalName(t *testing.T) {
+	svc := newService(map[string]interface{}{
+		"type":           "ExternalName",
+		"externalName":   "db.example.com",
+		"clusterIP":      "",
+		"ipFamilyPolicy": "SingleStack",
+	})
+	(&ResourceSyncer{}).prepareService(svc)
+
+	assert.Equal(t, map[string]interface{}{"type": "ExternalName", "externalName": "db.example.com"}, svc.Object["spec"])
+}
+
+func TestPrepareService_NodePorts(t *testing.T) {
+	spec := func() map[string]interface{} {
+		return map[string]interface{}{
+			"type":                  "LoadBalancer",
+			"clusterIP":             "10.96.0.20",
+			"loadBalancerIP":        "203.0.113.10",
+			"externalTrafficPolicy": "Local",
+			"healthCheckNodePort":   int64(31000),
+			"ports":                 []interface{}{map[string]interface{}{"port": int64(443), "nodePort": int64(30443)}},
+		}
+	}
+	nodePort := func(svc *unstructured.Unstructured) interface{} {
+		ports, _, _ := unstructured.NestedSlice(svc.Object, "spec", "ports")
+		return ports[0].(map[string]interface{})["nodePort"]
+	}
+
+	// Node ports are allocated by the destination cluster by default
+	svc := newService(spec())
+	(&ResourceSyncer{}).prepareService(svc)
+	assert.Nil(t, nodePort(svc))
+	assert.NotContains(t, svc.Object["spec"], "healthCheckNodePort")
+	assert.Equal(t, "LoadBalancer", svc.Object["spec"].(map[string]interface{})["type"])
+
+	svc = newService(spec())
+	(&ResourceSyncer{preserveNodePorts: true}).prepareService(svc)
+	assert.Equal(t, int64(30443), nodePort(svc))
+	assert.Equal(t, int64(31000), svc.Object["spec"].(map[string]interface{})["healthCheckNodePort"])
+
+	// Converted services have no node ports, even when they are preserved
+	svc = newService(spec())
+	(&ResourceSyncer{preserveNodePorts: true, convertLoadBalancers: true}).prepareService(svc)
+	converted := svc.Object["spec"].(map[string]interface{})
+	assert.Equal(t, "ClusterIP", converted["type"])
+	assert.NotContains(t, converted, "loadBalancerIP")
+	assert.NotContains(t, converted, "externalTrafficPolicy")
+	assert.NotContains(t, converted, "healthCheckNodePort")
+	assert.Nil(t, nodePort(svc))
+}
+
+func TestSyncResource_Service(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+
+	syncer := NewResourceSyncer(nil, nil, destDynamic, nil, nil, scheme)
+	syncer.convertLoadBalancers = true
+
+	source := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app-dr"},
+		Spec: corev1.ServiceSpec{
+			Type:       corev1.ServiceTypeLoadBalancer,
+			ClusterIP:  "10.96.0.20",
+			ClusterIPs: []string{"10.96.0.20"},
+			IPFamilies: []corev1.IPFamily{corev1.IPv4Protocol},
+			Ports:      []corev1.ServicePort{{Port: 443, NodePort: 30443}},
+		},
+	}
+	require.NoError(t, syncer.SyncResource(ctx, source, nil))
+
+	synced, err := destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("services")).Namespace("app-dr").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	spec := synced.Object["spec"].(map[string]interface{})
+	assert.Equal(t, "ClusterIP", spec["type"])
+	assert.NotContains(t, spec, "clusterIP")
+	assert.NotContains(t, spec, "clusterIPs")
+	assert.NotContains(t, spec, "ipFamilies")
+}
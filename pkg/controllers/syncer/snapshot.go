@@ -0,0 +1,117 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supporttools/dr-syncer/pkg/contextkeys"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+)
+
+// WithSyncGeneration returns a context whose consistent snapshot syncs label the destination resources
+// with generation
+func WithSyncGeneration(ctx context.Context, generation int64) context.Context {
+	return context.WithValue(ctx, contextkeys.SyncGenerationKey, generation)
+}
+
+// SyncGeneration returns the sync generation of ctx, zero when it has none
+func SyncGeneration(ctx context.Context) int64 {
+	generation, _ := ctx.Value(contextkeys.SyncGenerationKey).(int64)
+	return generation
+}
+
+// namespaceSnapshot holds the objects of the synced resource types of a source namespace, listed before
+// anything is written to the destination. It serves the reads of the sync like an informer cache.
+type namespaceSnapshot struct {
+	indexers map[schema.GroupVersionResource]cache.Indexer
+
+	// resourceVersions holds the resourceVersion each resource was listed at, keyed by snapshotKey
+	resourceVersions map[string]string
+}
+
+// Lister returns the lister of the snapshotted objects of gvr
+func (s *namespaceSnapshot) Lister(gvr schema.GroupVersionResource) (cache.GenericLister, bool) {
+	indexer, ok := s.indexers[gvr]
+	if !ok {
+		return nil, false
+	}
+	return cache.NewGenericLister(indexer, gvr.GroupResource()), true
+}
+
+// snapshotKey returns the key of gvr in the snapshot resource versions, such as "deployments.apps"
+func snapshotKey(gvr schema.GroupVersionResource) string {
+	return gvr.GroupResource().String()
+}
+
+// takeSnapshot lists the objects of resources in namespace. The pages of a paginated list are all
+// served at the resourceVersion of its first page, a list whose continue token expires is started over
+// so each resource type is read at a single resourceVersion. Resource types the source credentials
+// cannot list are left out of the snapshot and read when they are synced.
+func takeSnapshot(ctx context.Context, sourceDynamic dynamic.Interface, namespace string, resources []schema.GroupVersionResource) (*namespaceSnapshot, error) {
+	snapshot := &namespaceSnapshot{
+		indexers:         make(map[schema.GroupVersionResource]cache.Indexer, len(resources)),
+		resourceVersions: make(map[string]string, len(resources)),
+	}
+
+	for _, gvr := range resources {
+		indexer, resourceVersion, err := snapshotResource(ctx, sourceDynamic, namespace, gvr)
+		if apierrors.IsForbidden(err) || apierrors.IsNotFound(err) || apierrors.IsMethodNotSupported(err) {
+			log.Info(fmt.Sprintf("leaving %s out of the snapshot of %s, not accessible: %v", gvr.GroupResource(), namespace, err))
+			continue
+		}
+		if err != nil {
+			return nil, syncerrors.NewRetryableError(
+				fmt.Errorf("failed to snapshot %s: %w", gvr.GroupResource(), err),
+				gvr.Resource,
+			)
+		}
+		snapshot.indexers[gvr] = indexer
+		snapshot.resourceVersions[snapshotKey(gvr)] = resourceVersion
+	}
+
+	log.Info(fmt.Sprintf("snapshotted %d resource types of namespace %s", len(snapshot.indexers), namespace))
+	return snapshot, nil
+}
+
+// snapshotResource lists the objects of gvr in namespace into an indexer, returning the resourceVersion
+// they were listed at
+func snapshotResource(ctx context.Context, sourceDynamic dynamic.Interface, namespace string, gvr schema.GroupVersionResource) (cache.Indexer, string, error) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	opts := metav1.ListOptions{Limit: listPageSize()}
+	resourceVersion := ""
+	restarted := false
+	for {
+		page, err := sourceDynamic.Resource(gvr).Namespace(namespace).List(ctx, opts)
+		if err != nil && apierrors.IsResourceExpired(err) && opts.Continue != "" && !restarted {
+			// The pages listed so far belong to an older resourceVersion than the next ones would
+			log.Info(fmt.Sprintf("continue token for %s in %s expired, snapshotting it from the start", gvr.GroupResource(), namespace))
+			indexer = cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			opts.Continue = ""
+			resourceVersion = ""
+			restarted = true
+			continue
+		}
+		if err != nil {
+			return nil, "", err
+		}
+
+		if resourceVersion == "" {
+			resourceVersion = page.GetResourceVersion()
+		}
+		for i := range page.Items {
+			if err := indexer.Add(page.Items[i].DeepCopy()); err != nil {
+				return nil, "", err
+			}
+		}
+
+		opts.Continue = page.GetContinue()
+		if opts.Continue == "" {
+			return indexer, resourceVersion, nil
+		}
+	}
+}
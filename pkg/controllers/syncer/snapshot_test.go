@@ -0,0 +1,73 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestTakeSnapshot_AppliesSnapshottedState(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	settings := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "app"},
+		Data:       map[string]string{"version": "1"},
+	}
+	sourceDynamic := dynamicfake.NewSimpleDynamicClient(scheme, settings)
+	snapshot, err := takeSnapshot(ctx, sourceDynamic, "app", []schema.GroupVersionResource{configMapsGVR})
+	require.NoError(t, err)
+	assert.Contains(t, snapshot.resourceVersions, "configmaps")
+
+	// A deploy changes the source after the snapshot was taken
+	updated := settings.DeepCopy()
+	updated.Data["version"] = "2"
+	sourceClient := fake.NewSimpleClientset(updated)
+
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+	syncer := NewResourceSyncer(nil, sourceDynamic, destDynamic, sourceClient, nil, scheme)
+	syncer.syncGeneration = 7
+	require.NoError(t, syncConfigMaps(WithSourceCache(ctx, snapshot), syncer, sourceClient, "app", "app-dr", nil))
+
+	obj, err := destDynamic.Resource(configMapsGVR).Namespace("app-dr").Get(ctx, "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+	version, _, _ := unstructured.NestedString(obj.Object, "data", "version")
+	assert.Equal(t, "1", version, "the snapshotted state is applied")
+	assert.Equal(t, "7", obj.GetLabels()[utils.SyncGenerationLabel])
+}
+
+func TestTakeSnapshot_SkipsForbiddenResources(t *testing.T) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	sourceDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+	sourceDynamic.PrependReactor("list", "secrets", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "secrets"}, "", nil)
+	})
+
+	secretsGVR := corev1.SchemeGroupVersion.WithResource("secrets")
+	snapshot, err := takeSnapshot(context.Background(), sourceDynamic, "app", []schema.GroupVersionResource{configMapsGVR, secretsGVR})
+	require.NoError(t, err)
+
+	_, ok := snapshot.Lister(secretsGVR)
+	assert.False(t, ok, "secrets are read when they are synced")
+	_, ok = snapshot.Lister(configMapsGVR)
+	assert.True(t, ok)
+}
+
+func TestSyncGeneration(t *testing.T) {
+	assert.Zero(t, SyncGeneration(context.Background()))
+	assert.Equal(t, int64(3), SyncGeneration(WithSyncGeneration(context.Background(), 3)))
+}
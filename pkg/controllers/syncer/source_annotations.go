@@ -0,0 +1,103 @@
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// annotatedWorkloadResources maps the workload kinds that can carry DR state annotations to their resource
+var annotatedWorkloadResources = map[string]schema.GroupVersionResource{
+	"Deployment":  {Group: "apps", Version: "v1", Resource: "deployments"},
+	"StatefulSet": {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	"DaemonSet":   {Group: "apps", Version: "v1", Resource: "daemonsets"},
+}
+
+// defaultAnnotatedKinds are annotated when the mapping's sourceAnnotations list no kinds
+var defaultAnnotatedKinds = []string{"Deployment", "StatefulSet"}
+
+// sourceWorkload identifies a synced source workload by kind and name
+type sourceWorkload struct {
+	kind string
+	name string
+}
+
+// annotatedKinds returns the workload kinds annotated with their DR state, nil when the mapping
+// does not annotate source workloads
+func annotatedKinds(config *drv1alpha1.SourceAnnotationConfig) map[string]bool {
+	if config == nil || config.Enabled == nil || !*config.Enabled {
+		return nil
+	}
+	kinds := config.Kinds
+	if len(kinds) == 0 {
+		kinds = defaultAnnotatedKinds
+	}
+	annotated := make(map[string]bool, len(kinds))
+	for _, kind := range kinds {
+		annotated[kind] = true
+	}
+	return annotated
+}
+
+// recordSourceRevision records the generation of a source workload that was synced, so its DR
+// state is written onto it once the sync completes
+func (r *ResourceSyncer) recordSourceRevision(kind, name string, generation int64) {
+	if r == nil || !r.annotatedKinds[kind] {
+		return
+	}
+	if r.sourceRevisions == nil {
+		r.sourceRevisions = make(map[sourceWorkload]int64)
+	}
+	r.sourceRevisions[sourceWorkload{kind: kind, name: name}] = generation
+}
+
+// annotateSourceWorkloads writes the sync time and synced generation onto the source workloads synced
+// successfully. The annotations are informational: a workload that cannot be patched, for example
+// with read-only source credentials, is logged without failing the sync.
+func (r *ResourceSyncer) annotateSourceWorkloads(ctx context.Context, srcNamespace string) {
+	if len(r.sourceRevisions) == 0 {
+		return
+	}
+	syncedAt := time.Now().UTC().Format(time.RFC3339)
+
+	workloads := make([]sourceWorkload, 0, len(r.sourceRevisions))
+	for workload := range r.sourceRevisions {
+		workloads = append(workloads, workload)
+	}
+	sort.Slice(workloads, func(i, j int) bool {
+		if workloads[i].kind != workloads[j].kind {
+			return workloads[i].kind < workloads[j].kind
+		}
+		return workloads[i].name < workloads[j].name
+	})
+
+	for _, workload := range workloads {
+		patch, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{
+					utils.DRSyncedAtAnnotation: syncedAt,
+					utils.DRRevisionAnnotation: strconv.FormatInt(r.sourceRevisions[workload], 10),
+				},
+			},
+		})
+		if err != nil {
+			log.Errorf("failed to build DR state annotations of %s %s/%s: %v", workload.kind, srcNamespace, workload.name, err)
+			continue
+		}
+		gvr := annotatedWorkloadResources[workload.kind]
+		if _, err := r.sourceDynamic.Resource(gvr).Namespace(srcNamespace).Patch(ctx, workload.name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			log.Warn(fmt.Sprintf("failed to annotate source %s %s/%s with its DR state: %v", workload.kind, srcNamespace, workload.name, err))
+			continue
+		}
+		log.Info(fmt.Sprintf("annotated source %s %s/%s with its DR state", workload.kind, srcNamespace, workload.name))
+	}
+}
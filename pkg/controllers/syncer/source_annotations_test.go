@@ -0,0 +1,67 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestAnnotatedKinds(t *testing.T) {
+	enabled, disabled := true, false
+	assert.Nil(t, annotatedKinds(nil))
+	assert.Nil(t, annotatedKinds(&drv1alpha1.SourceAnnotationConfig{Enabled: &disabled, Kinds: []string{"Deployment"}}))
+	assert.Equal(t, map[string]bool{"Deployment": true, "StatefulSet": true}, annotatedKinds(&drv1alpha1.SourceAnnotationConfig{Enabled: &enabled}))
+	assert.Equal(t, map[string]bool{"DaemonSet": true}, annotatedKinds(&drv1alpha1.SourceAnnotationConfig{Enabled: &enabled, Kinds: []string{"DaemonSet"}}))
+}
+
+func TestAnnotateSourceWorkloads(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, appsv1.AddToScheme(scheme))
+	sourceDynamic := dynamicfake.NewSimpleDynamicClient(scheme,
+		&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app", Generation: 7, Annotations: map[string]string{"team": "shop"}}},
+		&appsv1.StatefulSet{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app", Generation: 2}},
+		&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "app", Generation: 4}},
+	)
+	enabled := true
+	syncer := NewResourceSyncer(nil, sourceDynamic, nil, nil, nil, scheme)
+	syncer.annotatedKinds = annotatedKinds(&drv1alpha1.SourceAnnotationConfig{Enabled: &enabled})
+
+	syncer.recordSourceRevision("Deployment", "web", 7)
+	syncer.recordSourceRevision("StatefulSet", "db", 2)
+	syncer.recordSourceRevision("DaemonSet", "agent", 4)
+	syncer.recordSourceRevision("StatefulSet", "missing", 1)
+	syncer.annotateSourceWorkloads(ctx, "app")
+
+	web, err := sourceDynamic.Resource(annotatedWorkloadResources["Deployment"]).Namespace("app").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "7", web.GetAnnotations()[utils.DRRevisionAnnotation])
+	assert.NotEmpty(t, web.GetAnnotations()[utils.DRSyncedAtAnnotation])
+	assert.Equal(t, "shop", web.GetAnnotations()["team"], "other annotations are kept")
+
+	db, err := sourceDynamic.Resource(annotatedWorkloadResources["StatefulSet"]).Namespace("app").Get(ctx, "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "2", db.GetAnnotations()[utils.DRRevisionAnnotation])
+	assert.Equal(t, web.GetAnnotations()[utils.DRSyncedAtAnnotation], db.GetAnnotations()[utils.DRSyncedAtAnnotation])
+
+	agent, err := sourceDynamic.Resource(annotatedWorkloadResources["DaemonSet"]).Namespace("app").Get(ctx, "agent", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotContains(t, agent.GetAnnotations(), utils.DRRevisionAnnotation, "only the configured kinds are annotated")
+}
+
+func TestSanitize_StripsDRStateAnnotations(t *testing.T) {
+	deploy := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "web", Annotations: map[string]string{
+		utils.DRSyncedAtAnnotation: "2026-10-16T10:00:00Z",
+		utils.DRRevisionAnnotation: "7",
+	}}}
+	(&ResourceSyncer{}).sanitize(deploy)
+	assert.Empty(t, deploy.Annotations, "the DR state of source workloads is not synced to the destination")
+}
@@ -0,0 +1,121 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/supporttools/dr-syncer/pkg/config"
+	"github.com/supporttools/dr-syncer/pkg/contextkeys"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// SourceCache serves reads of source cluster objects from informer caches
+type SourceCache interface {
+	// Lister returns the lister for gvr, false when gvr is not cached
+	Lister(gvr schema.GroupVersionResource) (cache.GenericLister, bool)
+}
+
+// WithSourceCache returns a context whose syncs read source objects from sourceCache instead of
+// listing them from the API server, for the resource types it caches
+func WithSourceCache(ctx context.Context, sourceCache SourceCache) context.Context {
+	return context.WithValue(ctx, contextkeys.SourceCacheKey, sourceCache)
+}
+
+// cachedLister returns the lister for gvr from the source cache in ctx, if any
+func cachedLister(ctx context.Context, gvr schema.GroupVersionResource) (cache.GenericLister, bool) {
+	sourceCache, ok := ctx.Value(contextkeys.SourceCacheKey).(SourceCache)
+	if !ok || sourceCache == nil {
+		return nil, false
+	}
+	return sourceCache.Lister(gvr)
+}
+
+// listPageSize is the number of objects requested per List call, zero lists all objects at once
+func listPageSize() int64 {
+	return config.CFG.ListPageSize
+}
+
+// eachSourcePage calls fn with each page of the objects of gvr in namespace. Objects come from the
+// source cache in ctx as a single page when it holds gvr, otherwise they are listed from the API
+// server listPageSize objects at a time.
+func eachSourcePage[T any, L interface {
+	*T
+	runtime.Object
+	GetContinue() string
+}](ctx context.Context, gvr schema.GroupVersionResource, namespace, kind string, list func(metav1.ListOptions) (L, error), fn func(L) error) error {
+	if lister, ok := cachedLister(ctx, gvr); ok {
+		page := L(new(T))
+		if err := listFromCache(lister, namespace, page); err != nil {
+			return syncerrors.NewRetryableError(
+				fmt.Errorf("failed to read %s from cache: %w", kind, err),
+				kind,
+			)
+		}
+		return fn(page)
+	}
+
+	opts := metav1.ListOptions{Limit: listPageSize()}
+	restarted := false
+	for {
+		page, err := list(opts)
+		if err != nil && apierrors.IsResourceExpired(err) && opts.Continue != "" && !restarted {
+			// Objects are synced idempotently, so a list whose continue token expired starts over
+			log.Info(fmt.Sprintf("continue token for %s in %s expired, listing from the start", kind, namespace))
+			opts.Continue = ""
+			restarted = true
+			continue
+		}
+		if err != nil {
+			return syncerrors.NewRetryableError(
+				fmt.Errorf("failed to list %s: %w", kind, err),
+				kind,
+			)
+		}
+
+		if err := fn(page); err != nil {
+			return err
+		}
+
+		opts.Continue = page.GetContinue()
+		if opts.Continue == "" {
+			return nil
+		}
+	}
+}
+
+// listFromCache copies the cached objects in namespace into the list into
+func listFromCache(lister cache.GenericLister, namespace string, into runtime.Object) error {
+	objs, err := lister.ByNamespace(namespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	items := make([]*unstructured.Unstructured, 0, len(objs))
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected object type %T in cache", obj)
+		}
+		items = append(items, u.DeepCopy())
+	}
+
+	if list, ok := into.(*unstructured.UnstructuredList); ok {
+		for _, item := range items {
+			list.Items = append(list.Items, *item)
+		}
+		return nil
+	}
+
+	content := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		content = append(content, item.Object)
+	}
+	return runtime.DefaultUnstructuredConverter.FromUnstructured(map[string]interface{}{"items": content}, into)
+}
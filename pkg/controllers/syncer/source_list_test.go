@@ -0,0 +1,141 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/supporttools/dr-syncer/pkg/config"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+var configMapsGVR = corev1.SchemeGroupVersion.WithResource("configmaps")
+
+// configMapPages returns a list function serving one ConfigMap per page
+func configMapPages(names ...string) func(metav1.ListOptions) (*corev1.ConfigMapList, error) {
+	return func(opts metav1.ListOptions) (*corev1.ConfigMapList, error) {
+		index := 0
+		if opts.Continue != "" {
+			for i, name := range names {
+				if name == opts.Continue {
+					index = i
+				}
+			}
+		}
+		list := &corev1.ConfigMapList{Items: []corev1.ConfigMap{{ObjectMeta: metav1.ObjectMeta{Name: names[index]}}}}
+		if index+1 < len(names) {
+			list.Continue = names[index+1]
+		}
+		return list, nil
+	}
+}
+
+func collectConfigMapNames(names *[]string) func(*corev1.ConfigMapList) error {
+	return func(list *corev1.ConfigMapList) error {
+		for _, cm := range list.Items {
+			*names = append(*names, cm.Name)
+		}
+		return nil
+	}
+}
+
+func TestEachSourcePage_FollowsContinueTokens(t *testing.T) {
+	defer func(size int64) { config.CFG.ListPageSize = size }(config.CFG.ListPageSize)
+	config.CFG.ListPageSize = 1
+
+	pages := configMapPages("a", "b", "c")
+	var limits []int64
+	list := func(opts metav1.ListOptions) (*corev1.ConfigMapList, error) {
+		limits = append(limits, opts.Limit)
+		return pages(opts)
+	}
+
+	var names []string
+	err := eachSourcePage(context.Background(), configMapsGVR, "app", "ConfigMaps", list, collectConfigMapNames(&names))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c"}, names)
+	assert.Equal(t, []int64{1, 1, 1}, limits)
+}
+
+func TestEachSourcePage_RestartsOnExpiredContinueToken(t *testing.T) {
+	pages := configMapPages("a", "b")
+	expired := false
+	list := func(opts metav1.ListOptions) (*corev1.ConfigMapList, error) {
+		if opts.Continue != "" && !expired {
+			expired = true
+			return nil, apierrors.NewResourceExpired("continue token expired")
+		}
+		return pages(opts)
+	}
+
+	var names []string
+	err := eachSourcePage(context.Background(), configMapsGVR, "app", "ConfigMaps", list, collectConfigMapNames(&names))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "a", "b"}, names)
+}
+
+func TestEachSourcePage_ListError(t *testing.T) {
+	list := func(metav1.ListOptions) (*corev1.ConfigMapList, error) {
+		return nil, apierrors.NewForbidden(configMapsGVR.GroupResource(), "", nil)
+	}
+
+	err := eachSourcePage(context.Background(), configMapsGVR, "app", "ConfigMaps", list, collectConfigMapNames(new([]string)))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to list ConfigMaps")
+	assert.True(t, apierrors.IsForbidden(err))
+}
+
+type testSourceCache map[schema.GroupVersionResource]cache.GenericLister
+
+func (c testSourceCache) Lister(gvr schema.GroupVersionResource) (cache.GenericLister, bool) {
+	lister, ok := c[gvr]
+	return lister, ok
+}
+
+func TestEachSourcePage_ReadsFromSourceCache(t *testing.T) {
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, obj := range []*unstructured.Unstructured{
+		{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "settings", "namespace": "app"},
+			"data":     map[string]interface{}{"key": "value"},
+		}},
+		{Object: map[string]interface{}{
+			"apiVersion": "v1", "kind": "ConfigMap",
+			"metadata": map[string]interface{}{"name": "other", "namespace": "other"},
+		}},
+	} {
+		require.NoError(t, indexer.Add(obj))
+	}
+	ctx := WithSourceCache(context.Background(), testSourceCache{
+		configMapsGVR: cache.NewGenericLister(indexer, configMapsGVR.GroupResource()),
+	})
+
+	list := func(metav1.ListOptions) (*corev1.ConfigMapList, error) {
+		t.Fatal("cached resource types must not be listed from the API server")
+		return nil, nil
+	}
+
+	var configMaps []corev1.ConfigMap
+	err := eachSourcePage(ctx, configMapsGVR, "app", "ConfigMaps", list, func(page *corev1.ConfigMapList) error {
+		configMaps = append(configMaps, page.Items...)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, configMaps, 1)
+	assert.Equal(t, "settings", configMaps[0].Name)
+	assert.Equal(t, "value", configMaps[0].Data["key"])
+
+	// Types missing from the cache are listed from the API server
+	var names []string
+	secretsGVR := corev1.SchemeGroupVersion.WithResource("secrets")
+	err = eachSourcePage(ctx, secretsGVR, "app", "Secrets", configMapPages("a"), collectConfigMapNames(&names))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a"}, names)
+}
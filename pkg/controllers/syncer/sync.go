@@ -2,16 +2,22 @@ package syncer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
 
 	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/audit"
+	"github.com/supporttools/dr-syncer/pkg/backup"
 	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
 	"github.com/supporttools/dr-syncer/pkg/controllers/syncer/validation"
 	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	"github.com/supporttools/dr-syncer/pkg/tracing"
+	"go.opentelemetry.io/otel/attribute"
 
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -25,6 +31,10 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
+// ErrNamespaceNotReady is wrapped by the errors of syncs that could not get the source and destination
+// namespaces in place, so no resource was synced
+var ErrNamespaceNotReady = errors.New("namespace not ready")
+
 // EnsureNamespaceExists ensures the destination namespace exists
 func EnsureNamespaceExists(ctx context.Context, client kubernetes.Interface, dstNamespace, srcNamespace string) error {
 	log.Info(fmt.Sprintf("ensuring namespace %s exists", dstNamespace))
@@ -51,12 +61,15 @@ func EnsureNamespaceExists(ctx context.Context, client kubernetes.Interface, dst
 				Name: dstNamespace,
 				Labels: map[string]string{
 					"dr-syncer.io/source-namespace": srcNamespace,
-					"dr-syncer.io/managed-by":       "dr-syncer",
+					utils.ManagedByLabel:            utils.ManagedByValue,
 				},
 			},
 		}
 
 		_, err = client.CoreV1().Namespaces().Create(ctx, ns, metav1.CreateOptions{})
+		if !apierrors.IsAlreadyExists(err) {
+			audit.Record(ctx, audit.OperationCreate, namespaceRef(dstNamespace), "", err)
+		}
 		if err == nil {
 			log.Info(fmt.Sprintf("created namespace %s", dstNamespace))
 			return nil
@@ -75,8 +88,9 @@ func EnsureNamespaceExists(ctx context.Context, client kubernetes.Interface, dst
 	return lastErr
 }
 
-// verifyClusterAccess checks if the cluster has access to required resources
-func verifyClusterAccess(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface, resourceTypes []string) error {
+// verifyClusterAccess checks if the cluster has access to required resources. Access is checked in the
+// synced namespace, so credentials limited to some namespaces pass.
+func verifyClusterAccess(ctx context.Context, client kubernetes.Interface, dynamicClient dynamic.Interface, namespace string, resourceTypes []string) error {
 	//log.Info("verifying cluster resource permissions")
 
 	// Check if client is nil
@@ -106,11 +120,16 @@ func verifyClusterAccess(ctx context.Context, client kubernetes.Interface, dynam
 		log.Info("networking.k8s.io API group not found in cluster")
 	}
 
-	// Check if apps API group exists (needed for Deployments)
+	// Check if apps API group exists (needed for Deployments and DaemonSets)
 	if !availableGroups["apps"] {
 		log.Info("apps API group not found in cluster")
 	}
 
+	// Check if batch API group exists (needed for CronJobs and Jobs)
+	if !availableGroups["batch"] {
+		log.Info("batch API group not found in cluster")
+	}
+
 	// Try to list each resource type to verify permissions
 	for _, resourceType := range resourceTypes {
 		log.Info(fmt.Sprintf("checking access permissions for %s", resourceType))
@@ -118,23 +137,42 @@ func verifyClusterAccess(ctx context.Context, client kubernetes.Interface, dynam
 		var err error
 		switch strings.ToLower(resourceType) {
 		case "configmaps", "configmap":
-			_, err = client.CoreV1().ConfigMaps("").List(ctx, metav1.ListOptions{Limit: 1})
+			_, err = client.CoreV1().ConfigMaps(namespace).List(ctx, metav1.ListOptions{Limit: 1})
 		case "secrets", "secret":
-			_, err = client.CoreV1().Secrets("").List(ctx, metav1.ListOptions{Limit: 1})
+			_, err = client.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{Limit: 1})
 		case "deployments", "deployment":
 			if !availableGroups["apps"] {
 				return fmt.Errorf("apps API group not available in cluster")
 			}
-			_, err = client.AppsV1().Deployments("").List(ctx, metav1.ListOptions{Limit: 1})
+			_, err = client.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+		case "daemonsets", "daemonset":
+			if !availableGroups["apps"] {
+				return fmt.Errorf("apps API group not available in cluster")
+			}
+			_, err = client.AppsV1().DaemonSets(namespace).List(ctx, metav1.ListOptions{Limit: 1})
 		case "services", "service":
-			_, err = client.CoreV1().Services("").List(ctx, metav1.ListOptions{Limit: 1})
+			_, err = client.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{Limit: 1})
 		case "ingresses", "ingress":
 			if !availableGroups["networking.k8s.io"] {
 				return fmt.Errorf("networking.k8s.io API group not available in cluster")
 			}
-			_, err = client.NetworkingV1().Ingresses("").List(ctx, metav1.ListOptions{Limit: 1})
+			_, err = client.NetworkingV1().Ingresses(namespace).List(ctx, metav1.ListOptions{Limit: 1})
 		case "persistentvolumeclaims", "persistentvolumeclaim", "pvc":
-			_, err = client.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{Limit: 1})
+			_, err = client.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+		case "resourcequotas", "resourcequota", "quota":
+			_, err = client.CoreV1().ResourceQuotas(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+		case "limitranges", "limitrange", "limits":
+			_, err = client.CoreV1().LimitRanges(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+		case "cronjobs", "cronjob":
+			if !availableGroups["batch"] {
+				return fmt.Errorf("batch API group not available in cluster")
+			}
+			_, err = client.BatchV1().CronJobs(namespace).List(ctx, metav1.ListOptions{Limit: 1})
+		case "jobs", "job":
+			if !availableGroups["batch"] {
+				return fmt.Errorf("batch API group not available in cluster")
+			}
+			_, err = client.BatchV1().Jobs(namespace).List(ctx, metav1.ListOptions{Limit: 1})
 		case "customresourcedefinitions", "customresourcedefinition", "crd", "crds":
 			if !availableGroups["apiextensions.k8s.io"] {
 				return fmt.Errorf("apiextensions.k8s.io API group not available in cluster")
@@ -158,50 +196,159 @@ func verifyClusterAccess(ctx context.Context, client kubernetes.Interface, dynam
 	return nil
 }
 
-// syncCustomResourceDefinitions synchronizes CRDs between clusters
-func syncCustomResourceDefinitions(ctx context.Context, syncer *ResourceSyncer, sourceClient kubernetes.Interface, sourceDynamic dynamic.Interface) error {
-	// Create GVR for CRDs
-	gvr := schema.GroupVersionResource{
-		Group:    "apiextensions.k8s.io",
-		Version:  "v1",
-		Resource: "customresourcedefinitions",
+// SyncNamespaceResources synchronizes resources between source and destination namespaces. A resource that
+// fails to sync does not stop the sync of the others: the failures are returned as a PartialSyncError
+// along with the result. The sync is limited to the resources of RetryResources when ctx has any.
+func SyncNamespaceResources(ctx context.Context, sourceClient, destClient kubernetes.Interface, sourceDynamic, destDynamic dynamic.Interface, ctrlClient client.Client, srcNamespace, dstNamespace string, resourceTypes []string, scaleToZero bool, namespaceScopedResources []string, pvcConfig *drv1alpha1.PVCConfig, immutableConfig *drv1alpha1.ImmutableResourceConfig, namespaceMappingSpec *drv1alpha1.NamespaceMappingSpec, sourceConfig, destConfig *rest.Config) (*SyncResult, error) {
+	var deploymentScales []DeploymentScale
+	var crdIncompatibilities []string
+
+	// Create resource syncer using the passed-in clients
+	syncer := NewResourceSyncer(ctrlClient, sourceDynamic, destDynamic, sourceClient, destClient, runtime.NewScheme())
+
+	// Set the REST configs for PVC data sync
+	syncer.SetConfigs(sourceConfig, destConfig)
+
+	// Retry only the resources that failed in the previous sync
+	if retry := RetryResources(ctx); len(retry) > 0 {
+		syncer.retryOnly = make(map[string]bool, len(retry))
+		for _, ref := range retry {
+			syncer.retryOnly[ref] = true
+		}
+		log.Info(fmt.Sprintf("retrying %d resources that failed to sync", len(retry)))
 	}
 
-	// List CRDs from source cluster
-	crds, err := sourceDynamic.Resource(gvr).List(ctx, metav1.ListOptions{})
-	if err != nil {
-		return fmt.Errorf("failed to list CRDs: %w", err)
+	// Workloads follow PVCs that are renamed in the destination
+	if pvcConfig != nil {
+		syncer.pvcMappings = pvcConfig.PVCMappings
 	}
 
-	// Process each CRD
-	for _, crd := range crds.Items {
-		if utils.ShouldIgnoreResource(&crd) {
-			continue
+	// Snapshot destination objects before they are overwritten
+	if namespaceMappingSpec != nil && namespaceMappingSpec.BackupConfig != nil && namespaceMappingSpec.BackupConfig.Enabled {
+		retention := backup.DefaultRetention
+		if namespaceMappingSpec.BackupConfig.Retention != nil {
+			retention = int(*namespaceMappingSpec.BackupConfig.Retention)
 		}
+		syncer.backups = backup.NewStore(destClient, namespaceMappingSpec.BackupConfig.ArchiveNamespace, retention)
+	}
 
-		// Sync the CRD
-		if err := syncer.SyncResource(ctx, &crd, nil); err != nil {
-			return fmt.Errorf("failed to sync CRD %s: %w", crd.GetName(), err)
+	if namespaceMappingSpec != nil {
+		syncer.sanitization = namespaceMappingSpec.SanitizationConfig
+		syncer.imageOverrides = namespaceMappingSpec.ImageOverrides
+		if namespaceMappingSpec.DependencyConfig != nil {
+			syncer.dependencyNamespaces = namespaceMappingSpec.DependencyConfig.Namespaces
+		}
+		if namespaceMappingSpec.IngressConfig != nil {
+			syncer.ingressClassMappings = namespaceMappingSpec.IngressConfig.IngressClassMappings
+		}
+		syncer.workloadOverrides = namespaceMappingSpec.WorkloadOverrides
+		syncer.quotaScaling = namespaceMappingSpec.QuotaScaling
+		syncer.annotatedKinds = annotatedKinds(namespaceMappingSpec.SourceAnnotations)
+		syncer.preserveNodePorts = namespaceMappingSpec.PreserveNodePorts != nil && *namespaceMappingSpec.PreserveNodePorts
+		syncer.convertLoadBalancers = namespaceMappingSpec.ConvertLoadBalancerServices != nil && *namespaceMappingSpec.ConvertLoadBalancerServices
+		syncer.skipOwned = namespaceMappingSpec.SkipOwnedResources != nil && *namespaceMappingSpec.SkipOwnedResources
+		syncer.verify = namespaceMappingSpec.VerifyAfterSync != nil && *namespaceMappingSpec.VerifyAfterSync
+
+		keyFilters, err := compileKeyFilters(namespaceMappingSpec.KeyFilters)
+		if err != nil {
+			return nil, err
+		}
+		syncer.keyFilters = keyFilters
+
+		injectRules, err := compileInjectRules(namespaceMappingSpec.Inject)
+		if err != nil {
+			return nil, err
+		}
+		syncer.injectRules = injectRules
+
+		serviceBridge, err := compileServiceBridge(namespaceMappingSpec.ServiceBridge)
+		if err != nil {
+			return nil, err
+		}
+		syncer.serviceBridge = serviceBridge
+
+		selectorless, err := compileSelectorlessEndpoints(namespaceMappingSpec.SelectorlessEndpoints)
+		if err != nil {
+			return nil, err
+		}
+		syncer.selectorlessEndpoints = selectorless
+
+		preservedFields, err := compilePreservedFields(namespaceMappingSpec.PreserveDestinationFields)
+		if err != nil {
+			return nil, err
+		}
+		syncer.preservedFields = preservedFields
+
+		ignores, err := compileIgnoreFields(namespaceMappingSpec.IgnoreFields)
+		if err != nil {
+			return nil, err
+		}
+		syncer.ignores = ignores
+
+		nameTransformations, err := compileNameTransformations(namespaceMappingSpec.NameTransformation)
+		if err != nil {
+			return nil, err
+		}
+		syncer.nameTransformations = nameTransformations
+
+		if namespaceMappingSpec.ResourceSelector != nil {
+			selector, err := metav1.LabelSelectorAsSelector(namespaceMappingSpec.ResourceSelector)
+			if err != nil {
+				return nil, fmt.Errorf("invalid resource selector: %w", err)
+			}
+			syncer.resourceSelector = selector
 		}
 	}
 
-	return nil
-}
+	// Label destination resources with the mapping so the SyncedOnly cleanup policy can find them
+	if mapping := audit.MappingFromContext(ctx); mapping != "" {
+		namespace, name, _ := strings.Cut(mapping, "/")
+		syncer.mappingLabels = utils.MappingLabels(namespace, name)
+		if syncer.mappingLabels == nil {
+			log.Info(fmt.Sprintf("mapping %s does not fit in a label value, synced resources are not labeled and are kept by the SyncedOnly cleanup policy", mapping))
+		} else {
+			syncer.loadMappingUID(ctx, namespace, name)
+		}
 
-// SyncNamespaceResources synchronizes resources between source and destination namespaces
-func SyncNamespaceResources(ctx context.Context, sourceClient, destClient kubernetes.Interface, sourceDynamic, destDynamic dynamic.Interface, ctrlClient client.Client, srcNamespace, dstNamespace string, resourceTypes []string, scaleToZero bool, namespaceScopedResources []string, pvcConfig *drv1alpha1.PVCConfig, immutableConfig *drv1alpha1.ImmutableResourceConfig, namespaceMappingSpec *drv1alpha1.NamespaceMappingSpec, sourceConfig, destConfig *rest.Config) ([]DeploymentScale, error) {
-	var deploymentScales []DeploymentScale
+		if pvcConfig != nil && pvcConfig.SyncData && namespaceMappingSpec != nil {
+			syncer.rsyncProfile = destinationSecurityProfile(ctx, ctrlClient, namespace, namespaceMappingSpec)
+			syncer.syncUnmounted = pvcConfig.SyncUnmounted
+			syncer.agentless = sourceAgentless(ctx, ctrlClient, namespace, namespaceMappingSpec)
+			syncer.dataSyncLimits = dataSyncLimits(ctx, ctrlClient, namespace, namespaceMappingSpec)
+		}
+		if pvcConfig != nil && pvcConfig.DataSyncConfig != nil {
+			syncer.rsyncEphemeralStorage = pvcConfig.DataSyncConfig.EphemeralStorage
+			syncer.dataSyncStrategy = pvcConfig.DataSyncConfig.GetStrategy()
+			syncer.autoGrowDestination = pvcConfig.DataSyncConfig.AutoGrowDestination
+			if pvcConfig.DataSyncConfig.StaleLockTimeout != nil {
+				syncer.staleLockTimeout = pvcConfig.DataSyncConfig.StaleLockTimeout.Duration
+			}
+		}
+		if pvcConfig != nil && pvcConfig.DataSyncConfig.GetTransport() == drv1alpha1.PVCDataTransportObjectStorage {
+			syncer.objectStorageTransport = true
+			syncer.objectStorage = pvcConfig.DataSyncConfig.ObjectStorage
+			syncer.objectStorageNamespace = namespace
+		}
+	}
 
-	// Create resource syncer using the passed-in clients
-	syncer := NewResourceSyncer(ctrlClient, sourceDynamic, destDynamic, sourceClient, destClient, runtime.NewScheme())
+	// Determine if CronJobs and Jobs should be suspended in the destination
+	suspendCronJobs := true
+	if namespaceMappingSpec != nil && namespaceMappingSpec.SuspendCronJobs != nil {
+		suspendCronJobs = *namespaceMappingSpec.SuspendCronJobs
+	}
 
-	// Set the REST configs for PVC data sync
-	syncer.SetConfigs(sourceConfig, destConfig)
+	// Refuse to write anything into a live namespace dr-syncer does not manage
+	allowAdopt := namespaceMappingSpec != nil && namespaceMappingSpec.AllowAdoptExisting != nil && *namespaceMappingSpec.AllowAdoptExisting
+	if err := checkDestinationNamespace(ctx, destClient, dstNamespace, allowAdopt); err != nil {
+		return nil, err
+	}
 
 	// If SyncCRDs is enabled, sync CRDs first
 	if namespaceMappingSpec != nil && namespaceMappingSpec.SyncCRDs != nil && *namespaceMappingSpec.SyncCRDs {
 		log.Info("syncing CRDs")
-		if err := syncCustomResourceDefinitions(ctx, syncer, sourceClient, sourceDynamic); err != nil {
+		var err error
+		crdIncompatibilities, err = syncCustomResourceDefinitions(ctx, syncer, sourceDynamic)
+		if err != nil {
 			return nil, fmt.Errorf("failed to sync CRDs: %w", err)
 		}
 	}
@@ -211,22 +358,54 @@ func SyncNamespaceResources(ctx context.Context, sourceClient, destClient kubern
 		resourceTypes = []string{"configmaps", "secrets", "deployments", "services", "ingresses", "persistentvolumeclaims"}
 	}
 
+	var excluded []string
+	if namespaceMappingSpec != nil {
+		excluded = namespaceMappingSpec.ExcludedResourceTypes
+	}
+
+	// Categories such as "workloads" select several resource types, the built-in ones without a
+	// dedicated sync function are synced like discovered resources
+	resourceTypes = utils.ExpandResourceTypes(resourceTypes)
+	var discoveredResources []schema.GroupVersionResource
+	if !isWildcard(resourceTypes) {
+		resourceTypes, discoveredResources = splitResourceTypes(resourceTypes, excluded)
+	}
+
+	// A wildcard syncs every namespaced resource type served by the source cluster
+	if isWildcard(resourceTypes) {
+		var err error
+		resourceTypes, discoveredResources, err = discoverWildcardResources(sourceClient.Discovery(), excluded)
+		if err != nil {
+			return nil, err
+		}
+		resourceTypes = accessibleResourceTypes(ctx, sourceClient, destClient, sourceDynamic, destDynamic, srcNamespace, dstNamespace, resourceTypes)
+		log.Info(fmt.Sprintf("wildcard resolved to %d typed and %d discovered resource types", len(resourceTypes), len(discoveredResources)))
+	}
+
 	// Verify cluster access and permissions first
 	log.Info("verifying source cluster access")
-	if err := verifyClusterAccess(ctx, sourceClient, sourceDynamic, resourceTypes); err != nil {
+	if err := verifyClusterAccess(ctx, sourceClient, sourceDynamic, srcNamespace, resourceTypes); err != nil {
 		return nil, fmt.Errorf("source cluster verification failed: %w", err)
 	}
 
 	log.Info("verifying destination cluster access")
-	if err := verifyClusterAccess(ctx, destClient, destDynamic, resourceTypes); err != nil {
+	if err := verifyClusterAccess(ctx, destClient, destDynamic, dstNamespace, resourceTypes); err != nil {
 		return nil, fmt.Errorf("destination cluster verification failed: %w", err)
 	}
 
+	// Fail before writing anything rather than replicating part of a namespace beyond the mapping's limits
+	if namespaceMappingSpec != nil && namespaceMappingSpec.Limits != nil {
+		resources := limitedResources(resourceTypes, discoveredResources)
+		if err := checkSyncLimits(ctx, sourceDynamic, srcNamespace, resources, namespaceMappingSpec.Limits, syncer.resourceSelector); err != nil {
+			return nil, err
+		}
+	}
+
 	log.Info(fmt.Sprintf("initializing resource syncer for %s to %s", srcNamespace, dstNamespace))
 
 	// Ensure destination namespace exists first
 	if err := EnsureNamespaceExists(ctx, destClient, dstNamespace, srcNamespace); err != nil {
-		return nil, fmt.Errorf("failed to ensure destination namespace exists: %w", err)
+		return nil, fmt.Errorf("%w: failed to ensure destination namespace exists: %w", ErrNamespaceNotReady, err)
 	}
 
 	// Get or create namespace in source cluster
@@ -238,17 +417,17 @@ func SyncNamespaceResources(ctx context.Context, sourceClient, destClient kubern
 				ObjectMeta: metav1.ObjectMeta{
 					Name: srcNamespace,
 					Labels: map[string]string{
-						"dr-syncer.io/managed-by": "dr-syncer",
+						utils.ManagedByLabel: utils.ManagedByValue,
 					},
 				},
 			}
 			sourceNS, err = sourceClient.CoreV1().Namespaces().Create(ctx, newSourceNS, metav1.CreateOptions{})
 			if err != nil {
-				return nil, fmt.Errorf("failed to create source namespace: %w", err)
+				return nil, fmt.Errorf("%w: failed to create source namespace: %w", ErrNamespaceNotReady, err)
 			}
 			log.Info(fmt.Sprintf("created source namespace %s", srcNamespace))
 		} else {
-			return nil, fmt.Errorf("failed to get source namespace: %w", err)
+			return nil, fmt.Errorf("%w: failed to get source namespace: %w", ErrNamespaceNotReady, err)
 		}
 	}
 
@@ -280,9 +459,12 @@ func SyncNamespaceResources(ctx context.Context, sourceClient, destClient kubern
 			newNS.Labels = make(map[string]string)
 		}
 		newNS.Labels["dr-syncer.io/source-namespace"] = srcNamespace
-		newNS.Labels["dr-syncer.io/managed-by"] = "dr-syncer"
+		newNS.Labels[utils.ManagedByLabel] = utils.ManagedByValue
 
 		_, err = destClient.CoreV1().Namespaces().Create(ctx, newNS, metav1.CreateOptions{})
+		if !apierrors.IsAlreadyExists(err) {
+			audit.Record(ctx, audit.OperationCreate, namespaceRef(dstNamespace), "", err)
+		}
 		if err == nil {
 			log.Info(fmt.Sprintf("created destination namespace %s", dstNamespace))
 			break
@@ -299,48 +481,109 @@ func SyncNamespaceResources(ctx context.Context, sourceClient, destClient kubern
 	}
 
 	if lastErr != nil {
-		return nil, lastErr
+		return nil, fmt.Errorf("%w: %w", ErrNamespaceNotReady, lastErr)
 	}
 
 	log.Info(fmt.Sprintf("starting resource synchronization from %s to %s", srcNamespace, dstNamespace))
 
+	// A standby namespace is isolated before synced workloads can start in it
+	if namespaceMappingSpec != nil && namespaceMappingSpec.NetworkIsolation != nil {
+		if err := syncer.isolateNamespace(ctx, dstNamespace, namespaceMappingSpec.NetworkIsolation, scaleToZero); err != nil {
+			return nil, fmt.Errorf("failed to isolate destination namespace: %w", err)
+		}
+	}
+
+	// A consistent sync lists the whole source namespace before writing anything and applies it as one
+	// sync generation, so a deploy in progress in the source is not replicated half-way
+	var snapshot *namespaceSnapshot
+	if namespaceMappingSpec != nil && namespaceMappingSpec.Consistency != nil && namespaceMappingSpec.Consistency.Snapshot != nil && *namespaceMappingSpec.Consistency.Snapshot {
+		var err error
+		snapshot, err = takeSnapshot(ctx, sourceDynamic, srcNamespace, limitedResources(resourceTypes, discoveredResources))
+		if err != nil {
+			return nil, err
+		}
+		ctx = WithSourceCache(ctx, snapshot)
+		syncer.syncGeneration = SyncGeneration(ctx)
+	}
+
 	// Sync standard resource types
+	secretsSynced := false
 	for _, resourceType := range resourceTypes {
 		// Normalize resource type to lowercase
 		rtLower := strings.ToLower(resourceType)
 		log.Info(fmt.Sprintf("processing resource type: %s", resourceType))
 
-		switch rtLower {
-		case "configmaps", "configmap":
-			if err := syncConfigMaps(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
-				return nil, fmt.Errorf("failed to sync ConfigMaps: %w", err)
-			}
-		case "secrets", "secret":
-			if err := syncSecrets(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
-				return nil, fmt.Errorf("failed to sync Secrets: %w", err)
-			}
-		case "deployments", "deployment":
-			scales, err := syncDeployments(ctx, syncer, sourceClient, srcNamespace, dstNamespace, scaleToZero, immutableConfig)
-			if err != nil {
-				return nil, fmt.Errorf("failed to sync Deployments: %w", err)
-			}
-			deploymentScales = append(deploymentScales, scales...)
-		case "services", "service":
-			if err := syncServices(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
-				return nil, fmt.Errorf("failed to sync Services: %w", err)
-			}
-		case "ingresses", "ingress":
-			if err := syncIngresses(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
-				return nil, fmt.Errorf("failed to sync Ingresses: %w", err)
-			}
-		case "persistentvolumeclaims", "persistentvolumeclaim", "pvc":
-			// Use the new PVC handler with mounting support
-			if err := syncPersistentVolumeClaimsWithMounting(ctx, syncer, sourceClient, destClient, srcNamespace, dstNamespace, pvcConfig, immutableConfig); err != nil {
-				return nil, fmt.Errorf("failed to sync PVCs: %w", err)
+		err := tracing.Trace(ctx, "SyncResourceType", func(ctx context.Context) error {
+			switch rtLower {
+			case "configmaps", "configmap":
+				if err := syncConfigMaps(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync ConfigMaps: %w", err)
+				}
+			case "secrets", "secret":
+				secretsSynced = true
+				if err := syncSecrets(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync Secrets: %w", err)
+				}
+			case "deployments", "deployment":
+				scales, err := syncDeployments(ctx, syncer, sourceClient, srcNamespace, dstNamespace, scaleToZero, immutableConfig)
+				if err != nil {
+					return fmt.Errorf("failed to sync Deployments: %w", err)
+				}
+				deploymentScales = append(deploymentScales, scales...)
+			case "daemonsets", "daemonset":
+				if err := syncDaemonSets(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync DaemonSets: %w", err)
+				}
+			case "services", "service":
+				if err := syncServices(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync Services: %w", err)
+				}
+			case "ingresses", "ingress":
+				if err := syncIngresses(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync Ingresses: %w", err)
+				}
+			case "persistentvolumeclaims", "persistentvolumeclaim", "pvc":
+				// Use the new PVC handler with mounting support
+				if err := syncPersistentVolumeClaimsWithMounting(ctx, syncer, sourceClient, destClient, srcNamespace, dstNamespace, pvcConfig, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync PVCs: %w", err)
+				}
+			case "resourcequotas", "resourcequota", "quota":
+				if err := syncResourceQuotas(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync ResourceQuotas: %w", err)
+				}
+			case "limitranges", "limitrange", "limits":
+				if err := syncLimitRanges(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync LimitRanges: %w", err)
+				}
+			case "cronjobs", "cronjob":
+				if err := syncCronJobs(ctx, syncer, sourceClient, srcNamespace, dstNamespace, suspendCronJobs, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync CronJobs: %w", err)
+				}
+			case "jobs", "job":
+				if err := syncJobs(ctx, syncer, sourceClient, srcNamespace, dstNamespace, suspendCronJobs, immutableConfig); err != nil {
+					return fmt.Errorf("failed to sync Jobs: %w", err)
+				}
 			}
+			return nil
+		}, attribute.String("resource_type", rtLower))
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	// Sync the resource types found by wildcard discovery
+	syncer.syncDiscoveredResources(ctx, discoveredResources, srcNamespace, dstNamespace)
+
+	// Bridged Services are not synced, they point at the endpoints of their source Services
+	if err := syncer.bridgeServices(ctx, srcNamespace, dstNamespace); err != nil {
+		return nil, fmt.Errorf("failed to bridge Services: %w", err)
+	}
+
+	// Services without a selector keep the endpoints managed by hand in the source
+	if err := syncer.syncSelectorlessEndpoints(ctx, srcNamespace, dstNamespace); err != nil {
+		return nil, fmt.Errorf("failed to sync the endpoints of Services without a selector: %w", err)
+	}
+
 	// Sync namespace scoped resources
 	if len(namespaceScopedResources) == 1 && namespaceScopedResources[0] == "*" {
 		// Get all API resources from the source cluster
@@ -360,7 +603,10 @@ func SyncNamespaceResources(ctx context.Context, sourceClient, destClient kubern
 					for _, r := range resources.APIResources {
 						// Only sync namespaced resources that are not built-in types
 						if r.Namespaced && !isBuiltInResource(r.Name) {
-							if err := syncer.syncNamespaceScopedResource(ctx, sourceClient, destClient, srcNamespace, dstNamespace, r.Name, group.Name); err != nil {
+							err := tracing.Trace(ctx, "SyncResourceType", func(ctx context.Context) error {
+								return syncer.syncNamespaceScopedResource(ctx, sourceClient, destClient, srcNamespace, dstNamespace, r.Name, group.Name)
+							}, attribute.String("resource_type", r.Name+"."+group.Name))
+							if err != nil {
 								log.Errorf("failed to sync resource %s in group %s: %v", r.Name, group.Name, err)
 							}
 						}
@@ -380,13 +626,59 @@ func SyncNamespaceResources(ctx context.Context, sourceClient, destClient kubern
 			resource := parts[0]
 			group := strings.Join(parts[1:], ".")
 
-			if err := syncer.syncNamespaceScopedResource(ctx, sourceClient, destClient, srcNamespace, dstNamespace, resource, group); err != nil {
+			err := tracing.Trace(ctx, "SyncResourceType", func(ctx context.Context) error {
+				return syncer.syncNamespaceScopedResource(ctx, sourceClient, destClient, srcNamespace, dstNamespace, resource, group)
+			}, attribute.String("resource_type", resourceRef))
+			if err != nil {
 				log.Errorf("failed to sync resource %s in group %s: %v", resource, group, err)
 			}
 		}
 	}
 
-	return deploymentScales, nil
+	// Workloads need their image pull secrets in the destination even when secrets are not synced
+	if !secretsSynced && !isExcludedResource(schema.GroupResource{Resource: "secrets"}, excluded) {
+		if err := syncPullSecrets(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+			return nil, fmt.Errorf("failed to sync image pull secrets: %w", err)
+		}
+	}
+
+	// Workloads may reference ConfigMaps and Secrets shared from other namespaces
+	if err := syncDependencies(ctx, syncer, sourceClient, srcNamespace, dstNamespace, immutableConfig); err != nil {
+		return nil, err
+	}
+
+	// Source workloads carry their DR state for dashboards in the source cluster
+	syncer.annotateSourceWorkloads(ctx, srcNamespace)
+
+	result := &SyncResult{
+		DeploymentScales:  deploymentScales,
+		Synced:            syncer.syncedCount,
+		Failed:            len(syncer.failures),
+		PVCDataSynced:     syncer.pvcDataSynced,
+		PVCDataFailed:     syncer.pvcDataFailed,
+		InsufficientSpace: syncer.insufficientSpace,
+		PendingBinding:    syncer.pendingBinding,
+		ExpansionBlocked:  syncer.expansionBlocked,
+
+		CRDsSynced:           namespaceMappingSpec != nil && namespaceMappingSpec.SyncCRDs != nil && *namespaceMappingSpec.SyncCRDs,
+		CRDIncompatibilities: crdIncompatibilities,
+	}
+
+	if snapshot != nil {
+		result.SyncGeneration = syncer.syncGeneration
+		result.SnapshotResourceVersions = snapshot.resourceVersions
+	}
+
+	// Catch destination objects altered after they were written, such as by mutating webhooks
+	if syncer.verify {
+		result.Verification = syncer.verifySynced(ctx)
+	}
+	return result, syncer.partialSyncError()
+}
+
+// namespaceRef builds the audit reference for a destination namespace
+func namespaceRef(name string) corev1.ObjectReference {
+	return corev1.ObjectReference{APIVersion: "v1", Kind: "Namespace", Name: name}
 }
 
 // isBuiltInResource checks if a resource is a built-in Kubernetes resource
@@ -402,10 +694,16 @@ func isBuiltInResource(name string) bool {
 		"secret":                    true,
 		"deployments":               true,
 		"deployment":                true,
+		"daemonsets":                true,
+		"daemonset":                 true,
 		"services":                  true,
 		"service":                   true,
 		"ingresses":                 true,
 		"ingress":                   true,
+		"cronjobs":                  true,
+		"cronjob":                   true,
+		"jobs":                      true,
+		"job":                       true,
 		"pods":                      true,
 		"pod":                       true,
 		"events":                    true,
@@ -418,6 +716,12 @@ func isBuiltInResource(name string) bool {
 		"persistentvolumes":         true,
 		"persistentvolume":          true,
 		"pv":                        true,
+		"resourcequotas":            true,
+		"resourcequota":             true,
+		"quota":                     true,
+		"limitranges":               true,
+		"limitrange":                true,
+		"limits":                    true,
 		"customresourcedefinitions": true,
 		"customresourcedefinition":  true,
 		"crd":                       true,
@@ -478,46 +782,91 @@ func (r *ResourceSyncer) syncNamespaceScopedResource(ctx context.Context, source
 	}
 
 	// Process each resource
-	for _, item := range items {
-		if utils.ShouldIgnoreResource(&item) {
-			continue
-		}
+	for i := range items {
+		r.syncDynamicItem(ctx, gvr, &items[i], dstNamespace)
+	}
 
-		// Prepare resource for destination
-		item.SetNamespace(dstNamespace)
-		utils.SanitizeMetadata(&item)
+	return nil
+}
 
-		// Check if resource exists in destination
-		existing, err := r.destDynamic.Resource(gvr).Namespace(dstNamespace).Get(ctx, item.GetName(), metav1.GetOptions{})
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				// Create resource
-				_, err = r.destDynamic.Resource(gvr).Namespace(dstNamespace).Create(ctx, &item, metav1.CreateOptions{})
-				if err != nil {
-					log.Errorf("failed to create resource %s/%s: %v", resource, item.GetName(), err)
-					continue
-				}
-				log.Info(fmt.Sprintf("created resource %s/%s", resource, item.GetName()))
-			} else {
-				log.Errorf("failed to get resource %s/%s: %v", resource, item.GetName(), err)
-				continue
-			}
-		} else {
-			// Update resource if needed
-			if !reflect.DeepEqual(item.Object, existing.Object) {
-				// Preserve UID and ResourceVersion
-				item.SetUID(existing.GetUID())
-				item.SetResourceVersion(existing.GetResourceVersion())
-				_, err = r.destDynamic.Resource(gvr).Namespace(dstNamespace).Update(ctx, &item, metav1.UpdateOptions{})
-				if err != nil {
-					log.Errorf("failed to update resource %s/%s: %v", resource, item.GetName(), err)
-					continue
-				}
-				log.Info(fmt.Sprintf("updated resource %s/%s", resource, item.GetName()))
+// syncDynamicItem creates or updates a single object of gvr in the destination namespace, failures are
+// recorded so the sync goes on with the other objects
+func (r *ResourceSyncer) syncDynamicItem(ctx context.Context, gvr schema.GroupVersionResource, item *unstructured.Unstructured, dstNamespace string) {
+	if r.shouldSkip(item) || !r.selected(item.GetKind(), item.GetName()) {
+		return
+	}
+	if gvr.GroupResource() == servicesResource && r.bridgedService(item.GetName(), item.GetLabels()) {
+		return
+	}
+	kind, name, generation := item.GetKind(), item.GetName(), item.GetGeneration()
+	err := r.writeDynamicItem(ctx, gvr, item, dstNamespace)
+	r.recordResult(kind, name, err)
+	if err == nil {
+		r.recordSourceRevision(kind, name, generation)
+	}
+}
+
+// writeDynamicItem prepares a single object of gvr for the destination namespace and creates or updates it
+func (r *ResourceSyncer) writeDynamicItem(ctx context.Context, gvr schema.GroupVersionResource, item *unstructured.Unstructured, dstNamespace string) error {
+
+	resource := gvr.Resource
+
+	// Prepare resource for destination
+	item.SetNamespace(dstNamespace)
+	r.sanitize(item)
+	r.labelSynced(item)
+	r.rewriteUnstructuredImages(gvr, item)
+	r.injectDefaults(gvr, item)
+	if gvr.GroupResource() == servicesResource {
+		r.prepareService(item)
+	}
+	if gvr.GroupResource() == statefulSetsResource {
+		r.prepareStatefulSet(item)
+	}
+	sourceName := item.GetName()
+	r.recordDependencies(gvr, item)
+	if err := r.transformNames(gvr, item); err != nil {
+		return err
+	}
+
+	// Check if resource exists in destination
+	existing, err := r.destDynamic.Resource(gvr).Namespace(dstNamespace).Get(ctx, item.GetName(), metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			// Create resource
+			_, err = r.destDynamic.Resource(gvr).Namespace(dstNamespace).Create(ctx, item, metav1.CreateOptions{})
+			audit.Record(ctx, audit.OperationCreate, audit.ObjectRef(item.GroupVersionKind(), item), "", err)
+			if err != nil {
+				return fmt.Errorf("failed to create resource %s/%s: %w", resource, item.GetName(), err)
 			}
+			log.Info(fmt.Sprintf("created resource %s/%s", resource, item.GetName()))
+			r.expectSynced(gvr, item.GetKind(), item)
+			return nil
 		}
+		return fmt.Errorf("failed to get resource %s/%s: %w", resource, item.GetName(), err)
 	}
 
+	// Keep the fields owned by the destination cluster
+	r.preserveDestinationFields(item, existing, sourceName)
+	r.keepDestinationOwnedFields(ctx, item, existing)
+
+	// Update resource if needed
+	if !reflect.DeepEqual(r.withoutIgnoredFields(item, item.GetKind(), sourceName).Object,
+		r.withoutIgnoredFields(existing, item.GetKind(), sourceName).Object) {
+		// Preserve UID and ResourceVersion
+		item.SetUID(existing.GetUID())
+		item.SetResourceVersion(existing.GetResourceVersion())
+		if err := r.backupBeforeUpdate(ctx, gvr, existing); err != nil {
+			return fmt.Errorf("failed to back up resource %s/%s, skipping update: %w", resource, item.GetName(), err)
+		}
+		_, err = r.destDynamic.Resource(gvr).Namespace(dstNamespace).Update(ctx, item, metav1.UpdateOptions{})
+		r.recordUpdate(ctx, item.GroupVersionKind(), item, audit.DiffObjects(existing, item), err)
+		if err != nil {
+			return fmt.Errorf("failed to update resource %s/%s: %w", resource, item.GetName(), err)
+		}
+		log.Info(fmt.Sprintf("updated resource %s/%s", resource, item.GetName()))
+	}
+	r.expectSynced(gvr, item.GetKind(), item)
 	return nil
 }
 
@@ -538,6 +887,10 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 			// PVC exists, only update mutable fields
 			log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: PVC %s/%s already exists, updating only mutable fields", pvc.Namespace, pvc.Name))
 
+			if err := r.checkOwnership(ctx, pvcGVK.Kind, existingPVC); err != nil {
+				return err
+			}
+
 			if existingPVC.Spec.VolumeName != "" {
 				log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: Existing PVC has volumeName: %s", existingPVC.Spec.VolumeName))
 			}
@@ -547,10 +900,18 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 
 			// Update only mutable fields
 			updatePVC.Spec.Resources = pvc.Spec.Resources
+			r.labelSynced(updatePVC)
+
+			if !reflect.DeepEqual(existingPVC.Spec.Resources, updatePVC.Spec.Resources) {
+				if err := r.backupPVCBeforeUpdate(ctx, existingPVC); err != nil {
+					return syncerrors.NewRetryableError(err, fmt.Sprintf("PersistentVolumeClaim/%s", pvc.Name))
+				}
+			}
 
 			// Update the PVC
 			log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: Updating PVC %s/%s with only mutable fields", pvc.Namespace, pvc.Name))
 			_, err = r.destClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Update(ctx, updatePVC, metav1.UpdateOptions{})
+			r.recordUpdate(ctx, pvcGVK, updatePVC, audit.DiffObjects(existingPVC, updatePVC), err)
 			if err != nil {
 				log.Error(fmt.Sprintf("SPECIAL PVC HANDLING: Failed to update PVC %s/%s: %v", pvc.Namespace, pvc.Name, err))
 				return syncerrors.NewRetryableError(
@@ -560,6 +921,7 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 			}
 
 			log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: Successfully updated PVC %s/%s", pvc.Namespace, pvc.Name))
+			r.expectSynced(pvcGVR, pvcGVK.Kind, updatePVC)
 			return nil
 		} else if !apierrors.IsNotFound(err) {
 			// Error getting PVC
@@ -583,12 +945,14 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 		delete(pvc.Annotations, "pv.kubernetes.io/bound-by-controller")
 		delete(pvc.Annotations, "volume.kubernetes.io/selected-node")
 
-		// Clear resourceVersion before creating
-		pvc.ResourceVersion = ""
+		// Clear resourceVersion and other cluster-specific metadata before creating
+		r.sanitize(pvc)
+		r.labelSynced(pvc)
 
 		// Create the PVC
 		log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: Creating PVC %s/%s", pvc.Namespace, pvc.Name))
 		_, err = r.destClient.CoreV1().PersistentVolumeClaims(pvc.Namespace).Create(ctx, pvc, metav1.CreateOptions{})
+		audit.Record(ctx, audit.OperationCreate, audit.ObjectRef(pvcGVK, pvc), "", err)
 		if err != nil {
 			log.Error(fmt.Sprintf("SPECIAL PVC HANDLING: Failed to create PVC %s/%s: %v", pvc.Namespace, pvc.Name, err))
 			return syncerrors.NewRetryableError(
@@ -598,6 +962,7 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 		}
 
 		log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: Successfully created PVC %s/%s", pvc.Namespace, pvc.Name))
+		r.expectSynced(pvcGVR, pvcGVK.Kind, pvc)
 		return nil
 	}
 
@@ -635,18 +1000,48 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 				Version: "v1",
 				Kind:    "Service",
 			}
+		case *corev1.ResourceQuota:
+			gvk = schema.GroupVersionKind{
+				Group:   "",
+				Version: "v1",
+				Kind:    "ResourceQuota",
+			}
+		case *corev1.LimitRange:
+			gvk = schema.GroupVersionKind{
+				Group:   "",
+				Version: "v1",
+				Kind:    "LimitRange",
+			}
 		case *appsv1.Deployment:
 			gvk = schema.GroupVersionKind{
 				Group:   "apps",
 				Version: "v1",
 				Kind:    "Deployment",
 			}
+		case *appsv1.DaemonSet:
+			gvk = schema.GroupVersionKind{
+				Group:   "apps",
+				Version: "v1",
+				Kind:    "DaemonSet",
+			}
 		case *networkingv1.Ingress:
 			gvk = schema.GroupVersionKind{
 				Group:   "networking.k8s.io",
 				Version: "v1",
 				Kind:    "Ingress",
 			}
+		case *batchv1.CronJob:
+			gvk = schema.GroupVersionKind{
+				Group:   "batch",
+				Version: "v1",
+				Kind:    "CronJob",
+			}
+		case *batchv1.Job:
+			gvk = schema.GroupVersionKind{
+				Group:   "batch",
+				Version: "v1",
+				Kind:    "Job",
+			}
 		default:
 			// Try to get GVK from the object's metadata
 			gvk = obj.GetObjectKind().GroupVersionKind()
@@ -671,6 +1066,16 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 	u := &unstructured.Unstructured{Object: unstructuredObj}
 	u.SetGroupVersionKind(gvk)
 
+	// Sanitize metadata before creation or update
+	r.sanitize(u)
+	r.labelSynced(u)
+	if gvk.Kind == "Service" {
+		r.prepareService(u)
+	}
+	if gvk.Kind == "ConfigMap" || gvk.Kind == "Secret" {
+		r.filterKeys(u)
+	}
+
 	// Ensure GVK is set for Deployments
 	if _, ok := obj.(*appsv1.Deployment); ok && u.GroupVersionKind().Group != "apps" {
 		u.SetGroupVersionKind(schema.GroupVersionKind{
@@ -701,6 +1106,12 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 			Version:  "v1",
 			Resource: "deployments",
 		}
+	case "DaemonSet":
+		gvr = schema.GroupVersionResource{
+			Group:    "apps",
+			Version:  "v1",
+			Resource: "daemonsets",
+		}
 	case "Service":
 		gvr = schema.GroupVersionResource{
 			Group:    "",
@@ -713,6 +1124,18 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 			Version:  "v1",
 			Resource: "ingresses",
 		}
+	case "CronJob":
+		gvr = schema.GroupVersionResource{
+			Group:    "batch",
+			Version:  "v1",
+			Resource: "cronjobs",
+		}
+	case "Job":
+		gvr = schema.GroupVersionResource{
+			Group:    "batch",
+			Version:  "v1",
+			Resource: "jobs",
+		}
 	case "CustomResourceDefinition":
 		gvr = schema.GroupVersionResource{
 			Group:    "apiextensions.k8s.io",
@@ -734,6 +1157,15 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 		}
 	}
 
+	r.injectDefaults(gvr, u)
+
+	// Rename the destination copy and its references to renamed resources
+	sourceName := u.GetName()
+	r.recordDependencies(gvr, u)
+	if err := r.transformNames(gvr, u); err != nil {
+		return err
+	}
+
 	log.Info(fmt.Sprintf("syncing %s %s/%s", gvk.Kind, u.GetNamespace(), u.GetName()))
 
 	// Get current resource in destination cluster
@@ -748,9 +1180,8 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 		// Resource doesn't exist, create it
 		log.Info(fmt.Sprintf("creating %s %s/%s", gvk.Kind, u.GetNamespace(), u.GetName()))
 
-		// Sanitize metadata before creation
-		utils.SanitizeMetadata(u)
 		_, err = r.destDynamic.Resource(gvr).Namespace(u.GetNamespace()).Create(ctx, u, metav1.CreateOptions{})
+		audit.Record(ctx, audit.OperationCreate, audit.ObjectRef(gvk, u), "", err)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				return syncerrors.NewNonRetryableError(
@@ -763,19 +1194,32 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 				fmt.Sprintf("%s/%s", gvk.Kind, u.GetName()),
 			)
 		}
+		r.expectSynced(gvr, gvk.Kind, u)
 		return nil
 	}
 
-	// Create copies for comparison
-	existingCopy := existing.DeepCopy()
-	sourceCopy := u.DeepCopy()
+	// Another mapping syncing into the namespace may own the resource
+	if err := r.checkOwnership(ctx, gvk.Kind, existing); err != nil {
+		return err
+	}
+
+	// Keep the keys of the destination resource that are not replicated
+	if gvk.Kind == "ConfigMap" || gvk.Kind == "Secret" {
+		r.keepLocalKeys(u, existing, sourceName)
+	}
+	r.preserveDestinationFields(u, existing, sourceName)
+	r.keepDestinationOwnedFields(ctx, u, existing)
+
+	// Create copies for comparison without the ignored fields
+	existingCopy := r.withoutIgnoredFields(existing, gvk.Kind, sourceName)
+	sourceCopy := r.withoutIgnoredFields(u, gvk.Kind, sourceName)
 
 	// Store UID for update
 	existingUID := existingCopy.GetUID()
 
 	// Sanitize both copies
-	utils.SanitizeMetadata(existingCopy)
-	utils.SanitizeMetadata(sourceCopy)
+	r.sanitize(existingCopy)
+	r.sanitize(sourceCopy)
 
 	// Compare sanitized versions
 	if !reflect.DeepEqual(existingCopy.Object, sourceCopy.Object) {
@@ -785,6 +1229,10 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 		u.SetUID(existingUID)
 		u.SetResourceVersion(existing.GetResourceVersion())
 
+		if err := r.backupBeforeUpdate(ctx, gvr, existing); err != nil {
+			return syncerrors.NewRetryableError(err, fmt.Sprintf("%s/%s", gvk.Kind, u.GetName()))
+		}
+
 		// Special handling for PVCs to avoid updating immutable fields
 		if gvk.Kind == "PersistentVolumeClaim" {
 			log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: Processing PVC %s/%s", u.GetNamespace(), u.GetName()))
@@ -817,6 +1265,7 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 				// Update the PVC in the destination cluster
 				log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: Updating PVC %s/%s with only mutable fields", u.GetNamespace(), u.GetName()))
 				_, err = r.destDynamic.Resource(gvr).Namespace(u.GetNamespace()).Update(ctx, updateObj, metav1.UpdateOptions{})
+				r.recordUpdate(ctx, gvk, updateObj, audit.DiffObjects(existing, updateObj), err)
 				if err != nil {
 					log.Error(fmt.Sprintf("SPECIAL PVC HANDLING: Failed to update PVC %s/%s: %v", u.GetNamespace(), u.GetName(), err))
 					return syncerrors.NewRetryableError(
@@ -826,6 +1275,7 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 				}
 
 				log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: Successfully updated PVC %s/%s", u.GetNamespace(), u.GetName()))
+				r.expectSynced(gvr, gvk.Kind, updateObj)
 				return nil
 			} else {
 				log.Info(fmt.Sprintf("SPECIAL PVC HANDLING: No resources.requests found for PVC %s/%s", u.GetNamespace(), u.GetName()))
@@ -833,6 +1283,7 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 		}
 
 		_, err = r.destDynamic.Resource(gvr).Namespace(u.GetNamespace()).Update(ctx, u, metav1.UpdateOptions{})
+		r.recordUpdate(ctx, gvk, u, audit.DiffSummary(existingCopy.Object, sourceCopy.Object), err)
 		if err != nil {
 			if apierrors.IsNotFound(err) {
 				return syncerrors.NewNonRetryableError(
@@ -848,5 +1299,6 @@ func (r *ResourceSyncer) SyncResource(ctx context.Context, obj runtime.Object, c
 	} else {
 		log.Info(fmt.Sprintf("no changes needed for %s %s/%s", gvk.Kind, u.GetNamespace(), u.GetName()))
 	}
+	r.expectSynced(gvr, gvk.Kind, u)
 	return nil
 }
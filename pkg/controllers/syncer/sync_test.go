@@ -119,6 +119,10 @@ func TestIsBuiltInResource_AllBuiltInResources(t *testing.T) {
 		"secrets", "secret",
 		// Deployments
 		"deployments", "deployment",
+		// DaemonSets
+		"daemonsets", "daemonset",
+		// Jobs
+		"cronjobs", "cronjob", "jobs", "job",
 		// Services
 		"services", "service",
 		// Ingresses
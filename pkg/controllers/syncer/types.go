@@ -1,8 +1,18 @@
 package syncer
 
 import (
+	"strconv"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/backup"
+	controller "github.com/supporttools/dr-syncer/pkg/controller/replication"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -16,6 +26,43 @@ type DeploymentScale struct {
 	SyncTime metav1.Time
 }
 
+// SyncResult is the outcome of the sync of a namespace
+type SyncResult struct {
+	DeploymentScales []DeploymentScale
+
+	// Verification is the verification of the synced objects when the mapping enables verifyAfterSync
+	Verification *drv1alpha1.VerificationStatus
+
+	// Synced and Failed count the resources synced and the resources that failed to sync
+	Synced int
+	Failed int
+
+	// PVCDataSynced and PVCDataFailed count the PVCs whose data was synced and failed to sync
+	PVCDataSynced int
+	PVCDataFailed int
+
+	// InsufficientSpace lists the PVCs whose data was not synced because the destination PVC is too small
+	InsufficientSpace []string
+
+	// PendingBinding lists the PVCs whose data was not synced because their WaitForFirstConsumer destination
+	// PVC could not be bound on a node the data can be written from
+	PendingBinding []string
+
+	// ExpansionBlocked lists the destination PVCs kept at their size because their storage class does not
+	// allow them to be expanded to the storage request of their source PVC
+	ExpansionBlocked []string
+
+	// CRDsSynced is true when the mapping syncs CRDs, CRDIncompatibilities then lists the CRDs that
+	// could not be synced as they are in the source, such as a destination serving newer versions
+	CRDsSynced           bool
+	CRDIncompatibilities []string
+
+	// SyncGeneration is the generation a consistent snapshot sync labeled the destination resources with,
+	// SnapshotResourceVersions the resourceVersion each resource type was listed at for its snapshot
+	SyncGeneration           int64
+	SnapshotResourceVersions map[string]string
+}
+
 // ResourceSyncer handles syncing resources between clusters
 type ResourceSyncer struct {
 	ctrlClient    client.Client
@@ -26,6 +73,141 @@ type ResourceSyncer struct {
 	scheme        *runtime.Scheme
 	sourceConfig  *rest.Config
 	destConfig    *rest.Config
+
+	// pvcMappings rename PVCs in the destination, workload volumes are rewritten to match
+	pvcMappings []drv1alpha1.PVCMapping
+
+	// backups snapshots destination objects before they are overwritten, nil when disabled
+	backups *backup.Store
+
+	// sanitization filters labels, annotations and finalizers copied to the destination
+	sanitization *drv1alpha1.SanitizationConfig
+
+	// ingressClassMappings convert the ingress class of Ingresses in the destination
+	ingressClassMappings []drv1alpha1.IngressClassMapping
+
+	// imageOverrides rewrite image registries in workload pod templates
+	imageOverrides []drv1alpha1.ImageOverride
+
+	// injectRules add DR-specific settings to the destination copies of workloads
+	injectRules []injectRule
+
+	// workloadOverrides set the destination replicas of Deployments and StatefulSets
+	workloadOverrides []drv1alpha1.WorkloadOverride
+
+	// keyFilters limit the keys of ConfigMaps and Secrets replicated to the destination
+	keyFilters []keyFilter
+
+	// preservedFields are fields of destination resources kept as they are in the destination cluster
+	preservedFields []preservedFields
+
+	// ignores are the differences between source and destination resources that do not update the
+	// destination resource, hpaTargets caches the workloads scaled by HPAs by destination namespace
+	ignores    *ignoreRules
+	hpaTargets map[string]map[string]bool
+
+	// nameTransformations rename the destination copies of ConfigMaps, Secrets and Services
+	nameTransformations []nameTransformation
+
+	// dependencyNamespaces are searched for the ConfigMaps and Secrets referenced by synced workloads that
+	// are missing from the source namespace, dependencies records the references and whether they are required
+	dependencyNamespaces []string
+	dependencies         map[dependency]bool
+
+	// pullSecrets records the image pull secrets referenced by synced workloads
+	pullSecrets map[string]bool
+
+	// annotatedKinds are the workload kinds whose DR state is written onto the source workloads after the
+	// sync, sourceRevisions holds the generations of the source workloads of those kinds that were synced
+	annotatedKinds  map[string]bool
+	sourceRevisions map[sourceWorkload]int64
+
+	// quotaScaling scales the ResourceQuotas and LimitRanges synced to the destination, nil copies them unscaled
+	quotaScaling *drv1alpha1.QuotaScalingConfig
+
+	// preserveNodePorts keeps service node ports, convertLoadBalancers creates LoadBalancer services as ClusterIP
+	preserveNodePorts    bool
+	convertLoadBalancers bool
+
+	// serviceBridge selects the Services bridged to their source endpoints instead of synced, nil bridges none
+	serviceBridge *serviceBridge
+
+	// selectorlessEndpoints syncs the endpoints of the Services without a selector, nil syncs none
+	selectorlessEndpoints *selectorlessEndpoints
+
+	// skipOwned skips resources with a controller ownerReference
+	skipOwned bool
+
+	// verify re-reads the objects written by the sync afterwards, synced holds their state as written
+	verify bool
+	synced map[string]syncedObject
+
+	// retryOnly limits the sync to the resources that failed in a previous sync, nil syncs all resources
+	retryOnly map[string]bool
+
+	// syncedCount counts the resources written to the destination, failures holds the resources that failed
+	syncedCount int
+	failures    []*syncerrors.SyncError
+
+	// pvcDataSynced and pvcDataFailed count the PVCs whose data was and was not synced
+	pvcDataSynced int
+	pvcDataFailed int
+
+	// insufficientSpace holds the data sync errors of the PVCs whose destination PVC is too small
+	insufficientSpace []string
+
+	// pendingBinding holds the data sync errors of the PVCs whose destination PVC could not be bound
+	pendingBinding []string
+
+	// expansionBlocked describes the destination PVCs that could not be expanded to their source's size
+	expansionBlocked []string
+
+	// mappingLabels mark destination resources as synced by the mapping, nil when the mapping is unknown
+	mappingLabels map[string]string
+
+	// syncGeneration labels the destination resources with the sync generation of a consistent snapshot
+	// sync, zero when the mapping does not sync snapshots
+	syncGeneration int64
+
+	// mappingUID is the UID of the syncing mapping, written to the destination resources it owns. Empty
+	// when the mapping is unknown, ownership is then not tracked.
+	mappingUID types.UID
+
+	// activeOwners caches whether the other mappings owning destination resources still exist
+	activeOwners map[string]bool
+
+	// resourceSelector limits the synced resources to those it matches, nil syncs all resources
+	resourceSelector labels.Selector
+
+	// rsyncProfile is the security profile of the rsync pods the PVC data sync creates in the destination
+	rsyncProfile drv1alpha1.RsyncSecurityProfile
+
+	// rsyncEphemeralStorage overrides the default ephemeral storage of the destination rsync pods
+	rsyncEphemeralStorage *drv1alpha1.EphemeralStorageConfig
+
+	// dataSyncStrategy selects how the destination rsync pods reach the source PVC data
+	dataSyncStrategy drv1alpha1.PVCDataSyncStrategy
+
+	// syncUnmounted mounts source PVCs that no pod mounts in a temporary pod to sync their data
+	syncUnmounted bool
+
+	// agentless streams PVC data out of a source cluster that runs no agent
+	agentless bool
+
+	// autoGrowDestination grows destination PVCs that are too small for the source data when possible
+	autoGrowDestination bool
+
+	// staleLockTimeout is the age after which another controller's lock on a source PVC is taken over
+	staleLockTimeout time.Duration
+
+	// dataSyncLimits are the maxConcurrentDataSyncs of the mapping's RemoteClusters
+	dataSyncLimits []controller.ClusterLimit
+
+	// objectStorageTransport transfers PVC data through the objectStorage repository instead of rsync,
+	// its credentials are read from objectStorageNamespace when the data sync starts
+	objectStorageTransport bool
+	objectStorage          *drv1alpha1.ObjectStorageConfig
+	objectStorageNamespace string
 }
 
 // NewResourceSyncer creates a new resource syncer
@@ -40,6 +222,36 @@ func NewResourceSyncer(ctrlClient client.Client, sourceDynamic, destDynamic dyna
 	}
 }
 
+// sanitize removes cluster-specific metadata from obj using the mapping's sanitization config
+func (r *ResourceSyncer) sanitize(obj metav1.Object) {
+	if r == nil {
+		utils.SanitizeMetadata(obj)
+		return
+	}
+	utils.SanitizeMetadataWithConfig(obj, r.sanitization)
+}
+
+// labelSynced marks obj as synced by the mapping, after sanitization so the labels are never stripped
+func (r *ResourceSyncer) labelSynced(obj metav1.Object) {
+	if r == nil || (len(r.mappingLabels) == 0 && r.syncGeneration == 0) {
+		return
+	}
+	labels := obj.GetLabels()
+	if labels == nil {
+		labels = make(map[string]string, len(r.mappingLabels))
+	}
+	for key, value := range r.mappingLabels {
+		labels[key] = value
+	}
+	if r.mappingUID != "" {
+		labels[utils.MappingUIDLabel] = string(r.mappingUID)
+	}
+	if r.syncGeneration > 0 {
+		labels[utils.SyncGenerationLabel] = strconv.FormatInt(r.syncGeneration, 10)
+	}
+	obj.SetLabels(labels)
+}
+
 // SetConfigs sets the REST configs for the source and destination clusters
 func (r *ResourceSyncer) SetConfigs(sourceConfig, destConfig *rest.Config) {
 	r.sourceConfig = sourceConfig
@@ -0,0 +1,64 @@
+package validation
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessModesAnnotation declares the access modes a StorageClass supports as a comma separated list,
+// taking priority over the modes known for its provisioner
+const AccessModesAnnotation = "dr-syncer.io/access-modes"
+
+// provisionerAccessModes are the access modes of well-known provisioners whose volumes attach to a single node
+var provisionerAccessModes = map[string][]corev1.PersistentVolumeAccessMode{
+	"ebs.csi.aws.com":          {corev1.ReadWriteOnce, corev1.ReadWriteOncePod},
+	"kubernetes.io/aws-ebs":    {corev1.ReadWriteOnce, corev1.ReadWriteOncePod},
+	"disk.csi.azure.com":       {corev1.ReadWriteOnce, corev1.ReadWriteOncePod},
+	"kubernetes.io/azure-disk": {corev1.ReadWriteOnce, corev1.ReadWriteOncePod},
+	"pd.csi.storage.gke.io":    {corev1.ReadWriteOnce, corev1.ReadWriteOncePod, corev1.ReadOnlyMany},
+	"kubernetes.io/gce-pd":     {corev1.ReadWriteOnce, corev1.ReadWriteOncePod, corev1.ReadOnlyMany},
+	"cinder.csi.openstack.org": {corev1.ReadWriteOnce, corev1.ReadWriteOncePod},
+}
+
+// SupportedAccessModes returns the access modes a StorageClass supports, or nil when they are unknown
+func SupportedAccessModes(storageClass metav1.Object, provisioner string) []corev1.PersistentVolumeAccessMode {
+	if declared, ok := storageClass.GetAnnotations()[AccessModesAnnotation]; ok {
+		var modes []corev1.PersistentVolumeAccessMode
+		for _, mode := range strings.Split(declared, ",") {
+			if mode = strings.TrimSpace(mode); mode != "" {
+				modes = append(modes, corev1.PersistentVolumeAccessMode(mode))
+			}
+		}
+		return modes
+	}
+	return provisionerAccessModes[provisioner]
+}
+
+// checkAccessModes checks that a storage class supports every access mode
+func checkAccessModes(storageClass *storagev1.StorageClass, accessModes []corev1.PersistentVolumeAccessMode) error {
+	supported := SupportedAccessModes(storageClass, storageClass.Provisioner)
+	if supported == nil {
+		return nil
+	}
+
+	for _, mode := range accessModes {
+		if !containsAccessMode(supported, mode) {
+			return fmt.Errorf("%w: storage class %s does not support access mode %s (supported: %v); map it with pvcConfig.accessModeMappings",
+				ErrStoragePreflight, storageClass.Name, mode, supported)
+		}
+	}
+	return nil
+}
+
+func containsAccessMode(modes []corev1.PersistentVolumeAccessMode, mode corev1.PersistentVolumeAccessMode) bool {
+	for _, m := range modes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package validation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPreflightPVCAccessModes(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp3"}, Provisioner: "ebs.csi.aws.com"},
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "cephfs", Annotations: map[string]string{AccessModesAnnotation: "ReadWriteMany, ReadOnlyMany"}},
+			Provisioner: "cephfs.csi.ceph.com",
+		},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "nfs"}, Provisioner: "nfs.csi.k8s.io"},
+	)
+	ctx := context.Background()
+	pvc := func(storageClass *string, mode corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data"},
+			Spec: corev1.PersistentVolumeClaimSpec{
+				StorageClassName: storageClass,
+				AccessModes:      []corev1.PersistentVolumeAccessMode{mode},
+			},
+		}
+	}
+	name := func(s string) *string { return &s }
+
+	assert.NoError(t, PreflightPVC(ctx, client, pvc(name("gp3"), corev1.ReadWriteOnce)))
+	assert.NoError(t, PreflightPVC(ctx, client, pvc(name("cephfs"), corev1.ReadWriteMany)))
+	assert.NoError(t, PreflightPVC(ctx, client, pvc(name("nfs"), corev1.ReadWriteMany)), "unknown provisioners accept every mode")
+	assert.NoError(t, PreflightPVC(ctx, client, pvc(nil, corev1.ReadWriteMany)))
+
+	err := PreflightPVC(ctx, client, pvc(name("gp3"), corev1.ReadWriteMany))
+	assert.Error(t, err)
+	assert.False(t, syncerrors.IsRetryable(err))
+
+	err = PreflightPVC(ctx, client, pvc(name("cephfs"), corev1.ReadWriteOnce))
+	assert.ErrorContains(t, err, "does not support access mode ReadWriteOnce")
+	assert.ErrorIs(t, err, ErrStoragePreflight)
+}
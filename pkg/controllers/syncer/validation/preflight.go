@@ -0,0 +1,145 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ErrStoragePreflight is wrapped by the errors of destination PVCs the destination storage cannot provision
+var ErrStoragePreflight = errors.New("storage pre-flight validation failed")
+
+// VolumeModesAnnotation declares the volume modes a StorageClass supports as a comma separated list,
+// taking priority over the modes known for its provisioner
+const VolumeModesAnnotation = "dr-syncer.io/volume-modes"
+
+// filesystemOnlyProvisioners are well-known provisioners of shared file systems, which cannot provision block volumes
+var filesystemOnlyProvisioners = map[string]bool{
+	"efs.csi.aws.com":              true,
+	"file.csi.azure.com":           true,
+	"kubernetes.io/azure-file":     true,
+	"filestore.csi.storage.gke.io": true,
+	"nfs.csi.k8s.io":               true,
+	"cephfs.csi.ceph.com":          true,
+}
+
+// PreflightPVC validates that the destination cluster can provision a new PVC before it is created,
+// so a misconfigured mapping fails the sync instead of leaving a Pending PVC behind. The storage class
+// must exist and support the access modes and volume mode of the PVC. When the CSI driver publishes
+// CSIStorageCapacity objects for the class, one of them must also have room for the requested size.
+func PreflightPVC(ctx context.Context, client kubernetes.Interface, pvc *corev1.PersistentVolumeClaim) error {
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return nil // No storage class specified, using cluster default
+	}
+	name := *pvc.Spec.StorageClassName
+	resource := fmt.Sprintf("PersistentVolumeClaim/%s", pvc.Name)
+
+	storageClass, err := client.StorageV1().StorageClasses().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return syncerrors.NewNonRetryableError(
+			fmt.Errorf("%w: storage class %s does not exist in the destination cluster; map it with pvcConfig.storageClassMappings",
+				ErrStoragePreflight, name),
+			resource,
+		)
+	}
+	if err != nil {
+		return syncerrors.NewWaitForNextSyncError(
+			fmt.Errorf("storage class validation failed: %w", err),
+			fmt.Sprintf("StorageClass/%s", name),
+		)
+	}
+
+	if err := checkAccessModes(storageClass, pvc.Spec.AccessModes); err != nil {
+		return syncerrors.NewNonRetryableError(err, resource)
+	}
+	if err := checkVolumeMode(storageClass, pvc.Spec.VolumeMode); err != nil {
+		return syncerrors.NewNonRetryableError(err, resource)
+	}
+	// Capacity is freed and added over time, so a shortage is checked again on the next sync
+	if err := checkCapacity(ctx, client, storageClass.Name, pvc); err != nil {
+		return syncerrors.NewWaitForNextSyncError(err, resource)
+	}
+	return nil
+}
+
+// SupportedVolumeModes returns the volume modes a StorageClass supports, or nil when they are unknown
+func SupportedVolumeModes(storageClass metav1.Object, provisioner string) []corev1.PersistentVolumeMode {
+	if declared, ok := storageClass.GetAnnotations()[VolumeModesAnnotation]; ok {
+		var modes []corev1.PersistentVolumeMode
+		for _, mode := range strings.Split(declared, ",") {
+			if mode = strings.TrimSpace(mode); mode != "" {
+				modes = append(modes, corev1.PersistentVolumeMode(mode))
+			}
+		}
+		return modes
+	}
+	if filesystemOnlyProvisioners[provisioner] {
+		return []corev1.PersistentVolumeMode{corev1.PersistentVolumeFilesystem}
+	}
+	return nil
+}
+
+// checkVolumeMode checks that a storage class supports a volume mode, an unset mode is Filesystem
+func checkVolumeMode(storageClass *storagev1.StorageClass, volumeMode *corev1.PersistentVolumeMode) error {
+	mode := corev1.PersistentVolumeFilesystem
+	if volumeMode != nil {
+		mode = *volumeMode
+	}
+
+	supported := SupportedVolumeModes(storageClass, storageClass.Provisioner)
+	if supported == nil {
+		return nil
+	}
+	for _, m := range supported {
+		if m == mode {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: storage class %s does not support volume mode %s (supported: %v)",
+		ErrStoragePreflight, storageClass.Name, mode, supported)
+}
+
+// checkCapacity checks the CSIStorageCapacity objects of a storage class for room for the size requested by a PVC.
+// Classes without capacity objects, and clusters where they cannot be listed, are assumed to have room.
+func checkCapacity(ctx context.Context, client kubernetes.Interface, storageClassName string, pvc *corev1.PersistentVolumeClaim) error {
+	request, ok := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if !ok || request.IsZero() {
+		return nil
+	}
+
+	capacities, err := client.StorageV1().CSIStorageCapacities(metav1.NamespaceAll).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil // Storage capacity tracking is optional
+	}
+
+	tracked := false
+	for _, capacity := range capacities.Items {
+		if capacity.StorageClassName != storageClassName {
+			continue
+		}
+		tracked = true
+
+		// The maximum volume size is the better bound when the driver reports it
+		available := capacity.MaximumVolumeSize
+		if available == nil {
+			available = capacity.Capacity
+		}
+		if available == nil || available.Cmp(request) >= 0 {
+			return nil
+		}
+	}
+	if !tracked {
+		return nil
+	}
+
+	return fmt.Errorf("%w: no topology segment of storage class %s has capacity for %s",
+		ErrStoragePreflight, storageClassName, request.String())
+}
@@ -0,0 +1,96 @@
+package validation
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	syncerrors "github.com/supporttools/dr-syncer/pkg/controllers/syncer/errors"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func preflightPVC(storageClass, size string, volumeMode corev1.PersistentVolumeMode) *corev1.PersistentVolumeClaim {
+	return &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: "data"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			StorageClassName: &storageClass,
+			VolumeMode:       &volumeMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse(size)},
+			},
+		},
+	}
+}
+
+func TestPreflightPVCMissingStorageClass(t *testing.T) {
+	err := PreflightPVC(context.Background(), fake.NewSimpleClientset(), preflightPVC("missing", "1Gi", corev1.PersistentVolumeFilesystem))
+
+	assert.ErrorIs(t, err, ErrStoragePreflight)
+	assert.ErrorContains(t, err, "storage class missing does not exist")
+	assert.False(t, syncerrors.IsRetryable(err))
+}
+
+func TestPreflightPVCVolumeMode(t *testing.T) {
+	client := fake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "efs"}, Provisioner: "efs.csi.aws.com"},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "gp3"}, Provisioner: "ebs.csi.aws.com"},
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "local", Annotations: map[string]string{VolumeModesAnnotation: "Filesystem"}},
+			Provisioner: "rancher.io/local-path",
+		},
+	)
+	ctx := context.Background()
+
+	assert.NoError(t, PreflightPVC(ctx, client, preflightPVC("efs", "1Gi", corev1.PersistentVolumeFilesystem)))
+	assert.NoError(t, PreflightPVC(ctx, client, preflightPVC("gp3", "1Gi", corev1.PersistentVolumeBlock)))
+
+	err := PreflightPVC(ctx, client, preflightPVC("efs", "1Gi", corev1.PersistentVolumeBlock))
+	assert.ErrorIs(t, err, ErrStoragePreflight)
+	assert.ErrorContains(t, err, "does not support volume mode Block")
+
+	assert.ErrorIs(t, PreflightPVC(ctx, client, preflightPVC("local", "1Gi", corev1.PersistentVolumeBlock)), ErrStoragePreflight)
+}
+
+func TestPreflightPVCCapacity(t *testing.T) {
+	quantity := func(s string) *resource.Quantity {
+		q := resource.MustParse(s)
+		return &q
+	}
+	client := fake.NewSimpleClientset(
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "topolvm"}, Provisioner: "topolvm.io"},
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "untracked"}, Provisioner: "topolvm.io"},
+		&storagev1.CSIStorageCapacity{
+			ObjectMeta:       metav1.ObjectMeta{Name: "zone-a", Namespace: "topolvm-system"},
+			StorageClassName: "topolvm",
+			Capacity:         quantity("100Gi"),
+		},
+		&storagev1.CSIStorageCapacity{
+			ObjectMeta:        metav1.ObjectMeta{Name: "zone-b", Namespace: "topolvm-system"},
+			StorageClassName:  "topolvm",
+			Capacity:          quantity("500Gi"),
+			MaximumVolumeSize: quantity("200Gi"),
+		},
+	)
+	ctx := context.Background()
+
+	assert.NoError(t, PreflightPVC(ctx, client, preflightPVC("topolvm", "150Gi", corev1.PersistentVolumeFilesystem)))
+	assert.NoError(t, PreflightPVC(ctx, client, preflightPVC("untracked", "10Ti", corev1.PersistentVolumeFilesystem)))
+
+	err := PreflightPVC(ctx, client, preflightPVC("topolvm", "300Gi", corev1.PersistentVolumeFilesystem))
+	assert.ErrorIs(t, err, ErrStoragePreflight)
+	assert.ErrorContains(t, err, "has capacity for 300Gi")
+	assert.True(t, syncerrors.ShouldWaitForNextSync(err))
+
+	// Capacity objects that cannot be listed skip the capacity check
+	client.PrependReactor("list", "csistoragecapacities", func(k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, errors.New("forbidden")
+	})
+	assert.NoError(t, PreflightPVC(ctx, client, preflightPVC("topolvm", "300Gi", corev1.PersistentVolumeFilesystem)))
+}
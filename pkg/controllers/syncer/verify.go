@@ -0,0 +1,192 @@
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// maxVerificationMismatches bounds the mismatched objects listed per kind in the status
+	maxVerificationMismatches = 10
+
+	// maxVerificationPaths bounds the differing field paths listed per mismatched object
+	maxVerificationPaths = 5
+)
+
+// syncedObject is a destination object as the sync wrote it
+type syncedObject struct {
+	gvr      schema.GroupVersionResource
+	kind     string
+	intended map[string]interface{}
+}
+
+// expectSynced records the state obj was written with, so it can be verified once the sync is done
+func (r *ResourceSyncer) expectSynced(gvr schema.GroupVersionResource, kind string, obj metav1.Object) {
+	if r == nil || !r.verify {
+		return
+	}
+	intended, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		log.Errorf("failed to record %s %s/%s for verification: %v", kind, obj.GetNamespace(), obj.GetName(), err)
+		return
+	}
+	if r.synced == nil {
+		r.synced = make(map[string]syncedObject)
+	}
+	// An object written twice is verified against the last write
+	key := fmt.Sprintf("%s/%s/%s", gvr.String(), obj.GetNamespace(), obj.GetName())
+	r.synced[key] = syncedObject{gvr: gvr, kind: kind, intended: intended}
+}
+
+// verifySynced re-reads the objects written by the sync and compares them against the state they were
+// written with, like kubectl diff does for a manifest. Fields the sync left empty are not compared, so
+// values defaulted by the API server are not reported, while changed values and added list items are.
+func (r *ResourceSyncer) verifySynced(ctx context.Context) *drv1alpha1.VerificationStatus {
+	now := metav1.Now()
+	byKind := make(map[string]*drv1alpha1.KindVerification)
+	for _, obj := range r.synced {
+		result, ok := byKind[obj.kind]
+		if !ok {
+			result = &drv1alpha1.KindVerification{Kind: obj.kind}
+			byKind[obj.kind] = result
+		}
+
+		meta, _ := obj.intended["metadata"].(map[string]interface{})
+		namespace, _ := meta["namespace"].(string)
+		name, _ := meta["name"].(string)
+
+		var fields string
+		live, err := r.destDynamic.Resource(obj.gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			fields = "object not found"
+		case err != nil:
+			log.Errorf("failed to read %s %s/%s for verification: %v", obj.kind, namespace, name, err)
+			fields = "object could not be read"
+		default:
+			fields = driftSummary(obj.intended, live.Object)
+		}
+
+		if fields == "" {
+			result.Verified++
+			continue
+		}
+		log.Warn(fmt.Sprintf("%s %s/%s differs from the synced state: %s", obj.kind, namespace, name, fields))
+		result.Mismatched++
+		result.Mismatches = append(result.Mismatches, drv1alpha1.VerificationMismatch{Name: name, Fields: fields})
+	}
+
+	status := &drv1alpha1.VerificationStatus{VerifiedAt: &now}
+	for _, result := range byKind {
+		sort.Slice(result.Mismatches, func(i, j int) bool { return result.Mismatches[i].Name < result.Mismatches[j].Name })
+		if len(result.Mismatches) > maxVerificationMismatches {
+			result.Mismatches = result.Mismatches[:maxVerificationMismatches]
+		}
+		status.Kinds = append(status.Kinds, *result)
+	}
+	sort.Slice(status.Kinds, func(i, j int) bool { return status.Kinds[i].Kind < status.Kinds[j].Kind })
+	return status
+}
+
+// driftSummary returns a comma-separated list of the field paths where live differs from intended, or an
+// empty string when live matches. Of the metadata only labels and annotations are compared, status never is.
+func driftSummary(intended, live map[string]interface{}) string {
+	var paths []string
+	for key, want := range intended {
+		switch key {
+		case "apiVersion", "kind", "status":
+			continue
+		case "metadata":
+			wantMeta, _ := want.(map[string]interface{})
+			liveMeta, _ := live["metadata"].(map[string]interface{})
+			for _, field := range []string{"labels", "annotations"} {
+				driftPaths("metadata."+field, wantMeta[field], liveMeta[field], &paths)
+			}
+		default:
+			driftPaths(key, want, live[key], &paths)
+		}
+	}
+	sort.Strings(paths)
+
+	if len(paths) > maxVerificationPaths {
+		more := len(paths) - maxVerificationPaths
+		paths = append(paths[:maxVerificationPaths], fmt.Sprintf("and %d more", more))
+	}
+	return strings.Join(paths, ", ")
+}
+
+// driftPaths collects the paths under path where got does not contain want. Empty values in want are
+// unset, maps in got may hold additional keys, lists must have the same length.
+func driftPaths(path string, want, got interface{}, paths *[]string) {
+	switch w := want.(type) {
+	case nil:
+		return
+	case string:
+		if w == "" {
+			return
+		}
+	case map[string]interface{}:
+		if len(w) == 0 {
+			return
+		}
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			*paths = append(*paths, path)
+			return
+		}
+		for key, value := range w {
+			driftPaths(path+"."+key, value, g[key], paths)
+		}
+		return
+	case []interface{}:
+		if len(w) == 0 {
+			return
+		}
+		g, ok := got.([]interface{})
+		if !ok || len(g) != len(w) {
+			*paths = append(*paths, path)
+			return
+		}
+		for i := range w {
+			driftPaths(fmt.Sprintf("%s[%d]", path, i), w[i], g[i], paths)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(want, got) && !numbersEqual(want, got) {
+		*paths = append(*paths, path)
+	}
+}
+
+// numbersEqual compares numbers decoded as different types, such as int64 and float64
+func numbersEqual(a, b interface{}) bool {
+	x, ok := toFloat(a)
+	if !ok {
+		return false
+	}
+	y, ok := toFloat(b)
+	return ok && x == y
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,131 @@
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestDriftSummary(t *testing.T) {
+	intended := map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":              "web",
+			"creationTimestamp": nil,
+			"labels":            map[string]interface{}{"app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(0),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "registry.example.com/web:1.0", "resources": map[string]interface{}{}},
+					},
+				},
+			},
+		},
+	}
+
+	// Server defaulted fields and metadata are not drift
+	live := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":            "web",
+			"uid":             "uid-1",
+			"resourceVersion": "42",
+			"labels":          map[string]interface{}{"app": "web", "team": "shop"},
+		},
+		"spec": map[string]interface{}{
+			"replicas":             float64(0),
+			"revisionHistoryLimit": int64(10),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"containers": []interface{}{
+						map[string]interface{}{"name": "web", "image": "registry.example.com/web:1.0", "imagePullPolicy": "IfNotPresent"},
+					},
+				},
+			},
+		},
+		"status": map[string]interface{}{"replicas": int64(1)},
+	}
+	assert.Empty(t, driftSummary(intended, live))
+
+	// Changed values, removed labels and injected containers are drift
+	live["metadata"].(map[string]interface{})["labels"] = map[string]interface{}{"team": "shop"}
+	live["spec"].(map[string]interface{})["replicas"] = int64(1)
+	template := live["spec"].(map[string]interface{})["template"].(map[string]interface{})
+	template["spec"].(map[string]interface{})["containers"] = []interface{}{
+		map[string]interface{}{"name": "web", "image": "registry.example.com/web:1.0"},
+		map[string]interface{}{"name": "proxy", "image": "proxy:1.0"},
+	}
+	assert.Equal(t, "metadata.labels.app, spec.replicas, spec.template.spec.containers", driftSummary(intended, live))
+}
+
+func TestVerifySynced(t *testing.T) {
+	ctx := context.Background()
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+	require.NoError(t, appsv1.AddToScheme(scheme))
+
+	destDynamic := dynamicfake.NewSimpleDynamicClient(scheme)
+	syncer := NewResourceSyncer(nil, nil, destDynamic, nil, fake.NewSimpleClientset(), scheme)
+	syncer.verify = true
+
+	replicas := int32(0)
+	require.NoError(t, syncer.SyncResource(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "settings", Namespace: "app-dr"},
+		Data:       map[string]string{"mode": "dr"},
+	}, nil))
+	require.NoError(t, syncer.SyncResource(ctx, &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "app-dr"},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "web", Image: "web:1.0"}},
+			}},
+		},
+	}, nil))
+	require.NoError(t, syncer.SyncResource(ctx, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "app-dr"},
+		Data:       map[string][]byte{"password": []byte("secret")},
+	}, nil))
+
+	// A webhook in the destination rewrites the image, and the secret is deleted
+	deployments := destDynamic.Resource(appsv1.SchemeGroupVersion.WithResource("deployments")).Namespace("app-dr")
+	web, err := deployments.Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	containers, _, _ := unstructured.NestedSlice(web.Object, "spec", "template", "spec", "containers")
+	containers[0].(map[string]interface{})["image"] = "mirror.example.com/web:1.0"
+	require.NoError(t, unstructured.SetNestedSlice(web.Object, containers, "spec", "template", "spec", "containers"))
+	_, err = deployments.Update(ctx, web, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.NoError(t, destDynamic.Resource(corev1.SchemeGroupVersion.WithResource("secrets")).Namespace("app-dr").Delete(ctx, "db", metav1.DeleteOptions{}))
+
+	status := syncer.verifySynced(ctx)
+	require.NotNil(t, status.VerifiedAt)
+	assert.Equal(t, []drv1alpha1.KindVerification{
+		{Kind: "ConfigMap", Verified: 1},
+		{Kind: "Deployment", Mismatched: 1, Mismatches: []drv1alpha1.VerificationMismatch{
+			{Name: "web", Fields: "spec.template.spec.containers[0].image"},
+		}},
+		{Kind: "Secret", Mismatched: 1, Mismatches: []drv1alpha1.VerificationMismatch{
+			{Name: "db", Fields: "object not found"},
+		}},
+	}, status.Kinds)
+}
+
+func TestExpectSyncedDisabled(t *testing.T) {
+	syncer := NewResourceSyncer(nil, nil, nil, nil, nil, nil)
+	syncer.expectSynced(pvcGVR, pvcGVK.Kind, &corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data"}})
+	assert.Empty(t, syncer.synced)
+}
@@ -0,0 +1,105 @@
+package utils
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ResourceTypeAliases maps the singular and short names accepted in resource type lists to the resource name
+var ResourceTypeAliases = map[string]string{
+	"configmap":             "configmaps",
+	"cm":                    "configmaps",
+	"secret":                "secrets",
+	"deployment":            "deployments",
+	"deploy":                "deployments",
+	"statefulset":           "statefulsets",
+	"sts":                   "statefulsets",
+	"daemonset":             "daemonsets",
+	"ds":                    "daemonsets",
+	"service":               "services",
+	"svc":                   "services",
+	"serviceaccount":        "serviceaccounts",
+	"sa":                    "serviceaccounts",
+	"ingress":               "ingresses",
+	"ing":                   "ingresses",
+	"networkpolicy":         "networkpolicies",
+	"netpol":                "networkpolicies",
+	"persistentvolumeclaim": "persistentvolumeclaims",
+	"pvc":                   "persistentvolumeclaims",
+	"cronjob":               "cronjobs",
+	"cj":                    "cronjobs",
+	"job":                   "jobs",
+	"resourcequota":         "resourcequotas",
+	"quota":                 "resourcequotas",
+	"limitrange":            "limitranges",
+	"limits":                "limitranges",
+}
+
+// ResourceTypeCategories maps the category names accepted in resource type lists to the resource types
+// they select
+var ResourceTypeCategories = map[string][]string{
+	"core":       {"configmaps", "secrets", "services", "serviceaccounts", "persistentvolumeclaims"},
+	"workloads":  {"deployments", "statefulsets", "daemonsets", "cronjobs"},
+	"networking": {"services", "ingresses", "networkpolicies"},
+}
+
+// builtInResourceTypes maps the resource names of the built-in resource types to their resource
+var builtInResourceTypes = map[string]schema.GroupVersionResource{
+	"configmaps":             {Group: "", Version: "v1", Resource: "configmaps"},
+	"secrets":                {Group: "", Version: "v1", Resource: "secrets"},
+	"services":               {Group: "", Version: "v1", Resource: "services"},
+	"serviceaccounts":        {Group: "", Version: "v1", Resource: "serviceaccounts"},
+	"persistentvolumeclaims": {Group: "", Version: "v1", Resource: "persistentvolumeclaims"},
+	"resourcequotas":         {Group: "", Version: "v1", Resource: "resourcequotas"},
+	"limitranges":            {Group: "", Version: "v1", Resource: "limitranges"},
+	"deployments":            {Group: "apps", Version: "v1", Resource: "deployments"},
+	"statefulsets":           {Group: "apps", Version: "v1", Resource: "statefulsets"},
+	"daemonsets":             {Group: "apps", Version: "v1", Resource: "daemonsets"},
+	"cronjobs":               {Group: "batch", Version: "v1", Resource: "cronjobs"},
+	"jobs":                   {Group: "batch", Version: "v1", Resource: "jobs"},
+	"ingresses":              {Group: "networking.k8s.io", Version: "v1", Resource: "ingresses"},
+	"networkpolicies":        {Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"},
+}
+
+// ExpandResourceTypes lowercases a resource type list, resolves its aliases to resource names and
+// replaces its categories by the resource types they select. Duplicates are dropped, the order of
+// first appearance is kept and unknown names, including the "*" wildcard, are kept as they are.
+func ExpandResourceTypes(resourceTypes []string) []string {
+	seen := make(map[string]bool, len(resourceTypes))
+	expanded := make([]string, 0, len(resourceTypes))
+	add := func(name string) {
+		if !seen[name] {
+			seen[name] = true
+			expanded = append(expanded, name)
+		}
+	}
+
+	for _, resourceType := range resourceTypes {
+		name := strings.ToLower(strings.TrimSpace(resourceType))
+		if name == "" {
+			continue
+		}
+		if members, ok := ResourceTypeCategories[name]; ok {
+			for _, member := range members {
+				add(member)
+			}
+			continue
+		}
+		if alias, ok := ResourceTypeAliases[name]; ok {
+			name = alias
+		}
+		add(name)
+	}
+	return expanded
+}
+
+// ResourceTypeGVR returns the resource of a built-in resource type name, aliases included
+func ResourceTypeGVR(resourceType string) (schema.GroupVersionResource, bool) {
+	name := strings.ToLower(resourceType)
+	if alias, ok := ResourceTypeAliases[name]; ok {
+		name = alias
+	}
+	gvr, ok := builtInResourceTypes[name]
+	return gvr, ok
+}
@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestExpandResourceTypes(t *testing.T) {
+	assert.Equal(t, []string{"deployments", "statefulsets", "daemonsets", "cronjobs", "services", "ingresses", "networkpolicies"},
+		ExpandResourceTypes([]string{"workloads", "networking"}))
+	assert.Equal(t, []string{"services", "persistentvolumeclaims", "configmaps"},
+		ExpandResourceTypes([]string{"SVC", " pvc ", "ConfigMap", "services", ""}), "aliases are resolved and duplicates dropped")
+	assert.Equal(t, []string{"widgets.example.com"}, ExpandResourceTypes([]string{"widgets.example.com"}), "unknown names are kept")
+	assert.Equal(t, []string{"*"}, ExpandResourceTypes([]string{"*"}))
+	assert.Empty(t, ExpandResourceTypes(nil))
+}
+
+func TestResourceTypeCategories(t *testing.T) {
+	for category, members := range ResourceTypeCategories {
+		for _, member := range members {
+			_, ok := ResourceTypeGVR(member)
+			assert.True(t, ok, "%s of category %s is a built-in resource type", member, category)
+		}
+	}
+}
+
+func TestResourceTypeGVR(t *testing.T) {
+	gvr, ok := ResourceTypeGVR("sts")
+	assert.True(t, ok)
+	assert.Equal(t, schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "statefulsets"}, gvr)
+
+	gvr, ok = ResourceTypeGVR("NetworkPolicies")
+	assert.True(t, ok)
+	assert.Equal(t, schema.GroupVersionResource{Group: "networking.k8s.io", Version: "v1", Resource: "networkpolicies"}, gvr)
+
+	_, ok = ResourceTypeGVR("widgets")
+	assert.False(t, ok)
+}
@@ -1,9 +1,13 @@
 package utils
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 )
 
 const (
@@ -11,11 +15,75 @@ const (
 	// Format: "dr-syncer.io/ignore: true"
 	IgnoreLabel = "dr-syncer.io/ignore"
 
+	// ExcludeAnnotation is used to mark resources that should be excluded from replication without
+	// changing their labels
+	// Format: "dr-syncer.io/exclude: true"
+	ExcludeAnnotation = "dr-syncer.io/exclude"
+
 	// ScaleOverrideLabel is used to override the scale of a deployment in the destination cluster
 	// Format: "dr-syncer.io/scale-override: <number>"
 	ScaleOverrideLabel = "dr-syncer.io/scale-override"
+
+	// DRReplicasAnnotation sets the replicas of a source Deployment or StatefulSet in the destination
+	// cluster regardless of scaleToZero
+	// Format: "dr-syncer.io/dr-replicas: <number>"
+	DRReplicasAnnotation = "dr-syncer.io/dr-replicas"
+
+	// OriginalReplicasAnnotation records the source replicas of a Deployment or StatefulSet on the
+	// destination workload, so it can be scaled up from the destination cluster alone
+	// Format: "dr-syncer.io/original-replicas: <number>"
+	OriginalReplicasAnnotation = "dr-syncer.io/original-replicas"
+
+	// OriginalSuspendAnnotation records the source value of spec.suspend for CronJobs and Jobs
+	// that were suspended in the destination cluster
+	// Format: "dr-syncer.io/original-suspend: <true|false>"
+	OriginalSuspendAnnotation = "dr-syncer.io/original-suspend"
+
+	// DRSyncedAtAnnotation and DRRevisionAnnotation record on a source workload when it was last synced
+	// and the metadata.generation that was synced, when the mapping enables sourceAnnotations. They are
+	// stripped from destination resources like last-applied-configuration.
+	// Format: "dr-syncer.io/dr-synced-at: <RFC 3339 time>", "dr-syncer.io/dr-revision: <generation>"
+	DRSyncedAtAnnotation = "dr-syncer.io/dr-synced-at"
+	DRRevisionAnnotation = "dr-syncer.io/dr-revision"
+
+	// MappingNameLabel and MappingNamespaceLabel identify the NamespaceMapping that synced a
+	// destination resource, they select the resources removed by the SyncedOnly cleanup policy
+	// Format: "dr-syncer.io/namespacemapping: <name>", "dr-syncer.io/namespacemapping-namespace: <namespace>"
+	MappingNameLabel      = "dr-syncer.io/namespacemapping"
+	MappingNamespaceLabel = "dr-syncer.io/namespacemapping-namespace"
+
+	// MappingUIDLabel records the UID of the NamespaceMapping owning a destination resource. Other
+	// mappings syncing into the same namespace refuse to overwrite it while the owner exists.
+	// Format: "dr-syncer.io/namespacemapping-uid: <uid>"
+	MappingUIDLabel = "dr-syncer.io/namespacemapping-uid"
+
+	// SyncGenerationLabel records the sync generation that applied a destination resource when the
+	// mapping syncs consistent snapshots of the source namespace
+	// Format: "dr-syncer.io/sync-generation: <number>"
+	SyncGenerationLabel = "dr-syncer.io/sync-generation"
+
+	// ManagedByLabel marks namespaces created or adopted by dr-syncer, only those are synced into
+	// Format: "dr-syncer.io/managed-by: dr-syncer"
+	ManagedByLabel = "dr-syncer.io/managed-by"
+	ManagedByValue = "dr-syncer"
+
+	// AdoptAnnotation allows syncing into an existing destination namespace not managed by dr-syncer
+	// Format: "dr-syncer.io/adopt: true"
+	AdoptAnnotation = "dr-syncer.io/adopt"
 )
 
+// MappingLabels returns the labels marking resources synced by the NamespaceMapping namespace/name,
+// nil when either does not fit in a label value
+func MappingLabels(namespace, name string) map[string]string {
+	if name == "" || len(validation.IsValidLabelValue(name)) > 0 || len(validation.IsValidLabelValue(namespace)) > 0 {
+		return nil
+	}
+	return map[string]string{
+		MappingNameLabel:      name,
+		MappingNamespaceLabel: namespace,
+	}
+}
+
 // ParseInt32 converts a string to int32
 func ParseInt32(s string) (int32, error) {
 	i, err := strconv.ParseInt(s, 10, 32)
@@ -25,16 +93,77 @@ func ParseInt32(s string) (int32, error) {
 	return int32(i), nil
 }
 
-// ShouldIgnoreResource checks if a resource should be ignored based on labels
+// exclusionLabel is a controller-wide label excluding resources from replication, any value matches
+// when value is empty
+type exclusionLabel struct {
+	key   string
+	value string
+}
+
+// exclusionLabels are the labels set with SetExclusionLabels
+var exclusionLabels []exclusionLabel
+
+// ParseExclusionLabels parses a comma-separated list of exclusion labels, each either a label key
+// matching any value or key=value
+func ParseExclusionLabels(labels string) ([]string, error) {
+	var parsed []string
+	for _, entry := range strings.Split(labels, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(entry, "=")
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid exclusion label key %q: %s", key, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return nil, fmt.Errorf("invalid exclusion label value %q: %s", value, strings.Join(errs, ", "))
+		}
+		parsed = append(parsed, entry)
+	}
+	return parsed, nil
+}
+
+// SetExclusionLabels sets the labels, as parsed by ParseExclusionLabels, that exclude source resources
+// from replication in addition to the ignore label and the exclude annotation
+func SetExclusionLabels(labels []string) {
+	exclusionLabels = nil
+	for _, label := range labels {
+		key, value, _ := strings.Cut(label, "=")
+		exclusionLabels = append(exclusionLabels, exclusionLabel{key: key, value: value})
+	}
+}
+
+// ShouldIgnoreResource checks if a resource should be ignored: it is labeled with the ignore label or
+// one of the exclusion labels, or annotated with the exclude annotation
 func ShouldIgnoreResource(obj metav1.Object) bool {
-	if val, exists := obj.GetLabels()[IgnoreLabel]; exists {
-		return val == "true"
+	labels := obj.GetLabels()
+	if labels[IgnoreLabel] == "true" || obj.GetAnnotations()[ExcludeAnnotation] == "true" {
+		return true
+	}
+	for _, label := range exclusionLabels {
+		if value, exists := labels[label.key]; exists && (label.value == "" || value == label.value) {
+			return true
+		}
 	}
 	return false
 }
 
+// defaultStrippedAnnotations and defaultStrippedFinalizers are removed from every destination resource
+// unless preserved by a SanitizationConfig
+var (
+	defaultStrippedAnnotations = []string{"kubectl.kubernetes.io/last-applied-configuration", DRSyncedAtAnnotation, DRRevisionAnnotation}
+	defaultStrippedFinalizers  = []string{"*"}
+)
+
 // SanitizeMetadata removes cluster-specific metadata from a resource
 func SanitizeMetadata(obj metav1.Object) {
+	SanitizeMetadataWithConfig(obj, nil)
+}
+
+// SanitizeMetadataWithConfig removes cluster-specific metadata from a resource, filtering labels,
+// annotations and finalizers by the given config on top of the defaults
+func SanitizeMetadataWithConfig(obj metav1.Object, config *drv1alpha1.SanitizationConfig) {
 	obj.SetUID("")
 	obj.SetResourceVersion("")
 	obj.SetSelfLink("")
@@ -42,10 +171,74 @@ func SanitizeMetadata(obj metav1.Object) {
 	obj.SetManagedFields(nil)
 	obj.SetOwnerReferences(nil)
 	obj.SetGeneration(0)
-	obj.SetFinalizers(nil)
+
+	var annotationFilter, labelFilter, finalizerFilter *drv1alpha1.MetadataFilter
+	if config != nil {
+		annotationFilter = config.Annotations
+		labelFilter = config.Labels
+		finalizerFilter = config.Finalizers
+	}
+
 	annotations := obj.GetAnnotations()
 	if annotations != nil {
-		delete(annotations, "kubectl.kubernetes.io/last-applied-configuration")
+		for key := range annotations {
+			if isStripped(key, defaultStrippedAnnotations, annotationFilter) {
+				delete(annotations, key)
+			}
+		}
 		obj.SetAnnotations(annotations)
 	}
+
+	labels := obj.GetLabels()
+	if labels != nil {
+		for key := range labels {
+			if isStripped(key, nil, labelFilter) {
+				delete(labels, key)
+			}
+		}
+		obj.SetLabels(labels)
+	}
+
+	var finalizers []string
+	for _, finalizer := range obj.GetFinalizers() {
+		if !isStripped(finalizer, defaultStrippedFinalizers, finalizerFilter) {
+			finalizers = append(finalizers, finalizer)
+		}
+	}
+	obj.SetFinalizers(finalizers)
+}
+
+// isStripped reports whether key should be removed. The most specific matching pattern across the
+// defaults and the filter's strip list is compared against the filter's preserve list, and
+// preserve wins ties.
+func isStripped(key string, defaults []string, filter *drv1alpha1.MetadataFilter) bool {
+	strip := matchSpecificity(key, defaults)
+	preserve := -1
+	if filter != nil {
+		if s := matchSpecificity(key, filter.Strip); s > strip {
+			strip = s
+		}
+		preserve = matchSpecificity(key, filter.Preserve)
+	}
+	return strip >= 0 && strip > preserve
+}
+
+// matchSpecificity returns how specifically the best of patterns matches key, or -1 when none match.
+// Exact matches rank above prefix matches of the same length.
+func matchSpecificity(key string, patterns []string) int {
+	best := -1
+	for _, pattern := range patterns {
+		score := -1
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(key, prefix) {
+				score = len(prefix)
+			}
+		} else if key == pattern {
+			score = len(pattern) + 1
+		}
+		if score > best {
+			best = score
+		}
+	}
+	return best
 }
@@ -1,10 +1,12 @@
 package utils
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
@@ -14,6 +16,7 @@ import (
 func TestConstants(t *testing.T) {
 	assert.Equal(t, "dr-syncer.io/ignore", IgnoreLabel)
 	assert.Equal(t, "dr-syncer.io/scale-override", ScaleOverrideLabel)
+	assert.Equal(t, "dr-syncer.io/original-suspend", OriginalSuspendAnnotation)
 }
 
 // Test ParseInt32
@@ -168,6 +171,45 @@ func TestShouldIgnoreResource_Service(t *testing.T) {
 	assert.True(t, ShouldIgnoreResource(svc))
 }
 
+func TestShouldIgnoreResource_ExcludeAnnotation(t *testing.T) {
+	cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Annotations: map[string]string{ExcludeAnnotation: "true"}}}
+	assert.True(t, ShouldIgnoreResource(cm))
+
+	cm.Annotations[ExcludeAnnotation] = "false"
+	assert.False(t, ShouldIgnoreResource(cm))
+}
+
+func TestShouldIgnoreResource_ExclusionLabels(t *testing.T) {
+	labels, err := ParseExclusionLabels("backup.example.com/skip, environment=dev-only")
+	require.NoError(t, err)
+	SetExclusionLabels(labels)
+	t.Cleanup(func() { SetExclusionLabels(nil) })
+
+	for _, tc := range []struct {
+		labels  map[string]string
+		ignored bool
+	}{
+		{labels: map[string]string{"backup.example.com/skip": ""}, ignored: true},
+		{labels: map[string]string{"environment": "dev-only"}, ignored: true},
+		{labels: map[string]string{"environment": "production"}, ignored: false},
+		{labels: map[string]string{"app": "web"}, ignored: false},
+	} {
+		cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test-cm", Labels: tc.labels}}
+		assert.Equal(t, tc.ignored, ShouldIgnoreResource(cm), "labels %v", tc.labels)
+	}
+}
+
+func TestParseExclusionLabels(t *testing.T) {
+	labels, err := ParseExclusionLabels("")
+	require.NoError(t, err)
+	assert.Empty(t, labels)
+
+	_, err = ParseExclusionLabels("not a key")
+	assert.Error(t, err)
+	_, err = ParseExclusionLabels("environment=not a value")
+	assert.Error(t, err)
+}
+
 // Test SanitizeMetadata
 func TestSanitizeMetadata_ClearsFields(t *testing.T) {
 	pod := &corev1.Pod{
@@ -327,6 +369,71 @@ func TestSanitizeMetadata_PreservesLabels(t *testing.T) {
 	assert.Equal(t, "frontend", pod.Labels["tier"])
 }
 
+func TestSanitizeMetadataWithConfig(t *testing.T) {
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "web",
+			Annotations: map[string]string{
+				"kubectl.kubernetes.io/last-applied-configuration": "{}",
+				"argocd.argoproj.io/tracking-id":                   "shop:/Service:app/web",
+				"argocd.argoproj.io/sync-wave":                     "1",
+			},
+			Labels: map[string]string{
+				"app.kubernetes.io/instance":   "shop",
+				"example.com/cluster":          "prod",
+				"example.com/cluster-critical": "true",
+			},
+			Finalizers: []string{
+				"service.kubernetes.io/load-balancer-cleanup",
+				"example.com/protect",
+			},
+		},
+	}
+
+	SanitizeMetadataWithConfig(svc, &drv1alpha1.SanitizationConfig{
+		Annotations: &drv1alpha1.MetadataFilter{
+			Strip:    []string{"argocd.argoproj.io/*"},
+			Preserve: []string{"argocd.argoproj.io/tracking-id"},
+		},
+		Labels: &drv1alpha1.MetadataFilter{
+			Strip:    []string{"example.com/*"},
+			Preserve: []string{"example.com/cluster-*"},
+		},
+		Finalizers: &drv1alpha1.MetadataFilter{
+			Strip:    []string{"service.kubernetes.io/load-balancer-cleanup"},
+			Preserve: []string{"*"},
+		},
+	})
+
+	// The more specific preserve pattern wins over the strip prefix
+	assert.Equal(t, map[string]string{"argocd.argoproj.io/tracking-id": "shop:/Service:app/web"}, svc.Annotations)
+	assert.Equal(t, map[string]string{
+		"app.kubernetes.io/instance":   "shop",
+		"example.com/cluster-critical": "true",
+	}, svc.Labels)
+
+	// Preserving all finalizers overrides the default, the exact strip pattern still removes one
+	assert.Equal(t, []string{"example.com/protect"}, svc.Finalizers)
+}
+
+func TestSanitizeMetadataWithConfig_PreserveOverridesDefaults(t *testing.T) {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "test-pod",
+			Annotations: map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"},
+			Finalizers:  []string{"example.com/protect", "example.com/other"},
+		},
+	}
+
+	SanitizeMetadataWithConfig(pod, &drv1alpha1.SanitizationConfig{
+		Annotations: &drv1alpha1.MetadataFilter{Preserve: []string{"kubectl.kubernetes.io/last-applied-configuration"}},
+		Finalizers:  &drv1alpha1.MetadataFilter{Preserve: []string{"example.com/protect"}},
+	})
+
+	assert.Contains(t, pod.Annotations, "kubectl.kubernetes.io/last-applied-configuration")
+	assert.Equal(t, []string{"example.com/protect"}, pod.Finalizers)
+}
+
 // Test combination of functions
 func TestIgnoredResourceFlow(t *testing.T) {
 	// Create a resource that should be ignored
@@ -379,3 +486,13 @@ func TestNonIgnoredResourceFlow(t *testing.T) {
 	// Labels should still exist
 	assert.Equal(t, "myapp", pod.Labels["app"])
 }
+
+func TestMappingLabels(t *testing.T) {
+	assert.Equal(t, map[string]string{
+		MappingNameLabel:      "shop",
+		MappingNamespaceLabel: "dr-syncer",
+	}, MappingLabels("dr-syncer", "shop"))
+
+	assert.Nil(t, MappingLabels("dr-syncer", ""))
+	assert.Nil(t, MappingLabels("dr-syncer", strings.Repeat("a", 64)))
+}
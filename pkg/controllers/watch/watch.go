@@ -3,9 +3,11 @@ package watch
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"sync"
 	"time"
 
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic"
@@ -20,6 +22,7 @@ type WatchManager struct {
 	informers        map[schema.GroupVersionResource]cache.SharedIndexInformer
 	stopCh           chan struct{}
 	backgroundStopCh chan struct{}
+	backgroundStop   sync.Once
 	watching         bool
 	mu               sync.RWMutex
 }
@@ -68,7 +71,7 @@ func (w *WatchManager) StartWatching(ctx context.Context, namespace string, reso
 				oldObj := old.(*unstructured.Unstructured)
 				newObj := new.(*unstructured.Unstructured)
 
-				if oldObj.GetResourceVersion() != newObj.GetResourceVersion() {
+				if oldObj.GetResourceVersion() != newObj.GetResourceVersion() && !onlyDRStateChanged(oldObj, newObj) {
 					log.Info(fmt.Sprintf("resource updated: %s.%s/%s - %s", gvr.Resource, gvr.Group, gvr.Version, newObj.GetName()))
 					if err := handler(new); err != nil {
 						log.WithError(err).Error("failed to handle update event")
@@ -117,6 +120,16 @@ func (w *WatchManager) StopWatching() {
 	w.informers = make(map[schema.GroupVersionResource]cache.SharedIndexInformer)
 }
 
+// WatchCount returns the number of running watchers
+func (w *WatchManager) WatchCount() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if !w.watching {
+		return 0
+	}
+	return len(w.informers)
+}
+
 // IsWatching returns whether watchers are running
 func (w *WatchManager) IsWatching() bool {
 	w.mu.RLock()
@@ -124,6 +137,18 @@ func (w *WatchManager) IsWatching() bool {
 	return w.watching
 }
 
+// Lister returns a lister backed by the synced informer cache of gvr, false when gvr is not watched
+func (w *WatchManager) Lister(gvr schema.GroupVersionResource) (cache.GenericLister, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	informer, ok := w.informers[gvr]
+	if !ok || !w.watching || !informer.HasSynced() {
+		return nil, false
+	}
+	return cache.NewGenericLister(informer.GetIndexer(), gvr.GroupResource()), true
+}
+
 // StartBackgroundSync starts a background sync process
 func (w *WatchManager) StartBackgroundSync(ctx context.Context, interval time.Duration, syncFn func() error) {
 	log.Info(fmt.Sprintf("starting background sync with interval %s", interval))
@@ -147,7 +172,62 @@ func (w *WatchManager) StartBackgroundSync(ctx context.Context, interval time.Du
 	}()
 }
 
-// StopBackgroundSync stops the background sync process
+// StartDataWatch polls for source PVC data changes every interval. It is stopped with the background sync.
+func (w *WatchManager) StartDataWatch(ctx context.Context, interval time.Duration, pollFn func() error) {
+	log.Info(fmt.Sprintf("starting PVC data watch, polling every %s", interval))
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if err := pollFn(); err != nil {
+					log.WithError(err).Error("PVC data watch sync failed")
+				}
+			case <-w.backgroundStopCh:
+				log.Info("stopping PVC data watch")
+				return
+			}
+		}
+	}()
+}
+
+// StopBackgroundSync stops the background sync process and the PVC data watch, it may be called more than once
 func (w *WatchManager) StopBackgroundSync() {
-	close(w.backgroundStopCh)
+	w.backgroundStop.Do(func() {
+		close(w.backgroundStopCh)
+	})
+}
+
+// Stop stops the watchers, the background sync and the PVC data watch. A stopped WatchManager cannot be
+// started again.
+func (w *WatchManager) Stop() {
+	w.StopWatching()
+	w.StopBackgroundSync()
+}
+
+// onlyDRStateChanged reports whether an update only changed the DR state annotations written onto source
+// workloads after a sync. Syncing again on them would annotate the workloads again, forever.
+func onlyDRStateChanged(oldObj, newObj *unstructured.Unstructured) bool {
+	oldAnnotations, newAnnotations := oldObj.GetAnnotations(), newObj.GetAnnotations()
+	if oldAnnotations[utils.DRSyncedAtAnnotation] == newAnnotations[utils.DRSyncedAtAnnotation] &&
+		oldAnnotations[utils.DRRevisionAnnotation] == newAnnotations[utils.DRRevisionAnnotation] {
+		return false
+	}
+
+	oldCopy, newCopy := oldObj.DeepCopy(), newObj.DeepCopy()
+	for _, obj := range []*unstructured.Unstructured{oldCopy, newCopy} {
+		obj.SetResourceVersion("")
+		obj.SetManagedFields(nil)
+		annotations := obj.GetAnnotations()
+		delete(annotations, utils.DRSyncedAtAnnotation)
+		delete(annotations, utils.DRRevisionAnnotation)
+		if len(annotations) == 0 {
+			annotations = nil
+		}
+		obj.SetAnnotations(annotations)
+	}
+	return reflect.DeepEqual(oldCopy.Object, newCopy.Object)
 }
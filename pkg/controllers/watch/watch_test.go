@@ -0,0 +1,33 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/supporttools/dr-syncer/pkg/controllers/utils"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func watchedDeployment(resourceVersion string, replicas int64, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "app", "resourceVersion": resourceVersion},
+		"spec":       map[string]interface{}{"replicas": replicas},
+	}}
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+func TestOnlyDRStateChanged(t *testing.T) {
+	synced := map[string]string{utils.DRSyncedAtAnnotation: "2026-10-16T10:00:00Z", utils.DRRevisionAnnotation: "3"}
+	resynced := map[string]string{utils.DRSyncedAtAnnotation: "2026-10-16T10:05:00Z", utils.DRRevisionAnnotation: "3"}
+
+	assert.True(t, onlyDRStateChanged(watchedDeployment("1", 3, nil), watchedDeployment("2", 3, synced)),
+		"annotating a workload for the first time does not trigger a sync")
+	assert.True(t, onlyDRStateChanged(watchedDeployment("2", 3, synced), watchedDeployment("3", 3, resynced)))
+	assert.False(t, onlyDRStateChanged(watchedDeployment("2", 3, synced), watchedDeployment("3", 5, resynced)),
+		"other changes made along with the annotations trigger a sync")
+	assert.False(t, onlyDRStateChanged(watchedDeployment("2", 3, synced), watchedDeployment("3", 5, synced)))
+	assert.False(t, onlyDRStateChanged(watchedDeployment("1", 3, nil), watchedDeployment("2", 3, map[string]string{"team": "shop"})))
+}
@@ -0,0 +1,263 @@
+// Package dashboard serves a read-only web dashboard of the controller for teams without Grafana: the
+// NamespaceMappings with their last sync, progress and failures, the in-flight PVC data syncs and the
+// connectivity of the RemoteClusters and ClusterMappings. The page polls StatePath, which is built from
+// the status of the custom resources and the in-flight state served on /debug/syncstate.
+package dashboard
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/syncstate"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// Path is where the dashboard is served on the metrics server
+	Path = "/dashboard/"
+
+	// StatePath serves the data shown by the dashboard as JSON
+	StatePath = Path + "api/state"
+
+	// stateTimeout bounds the reads of the custom resources for a state request
+	stateTimeout = 10 * time.Second
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// Mapping is a NamespaceMapping as shown on the dashboard
+type Mapping struct {
+	Namespace            string                         `json:"namespace"`
+	Name                 string                         `json:"name"`
+	SourceCluster        string                         `json:"sourceCluster,omitempty"`
+	DestinationCluster   string                         `json:"destinationCluster,omitempty"`
+	ClusterMapping       string                         `json:"clusterMapping,omitempty"`
+	SourceNamespace      string                         `json:"sourceNamespace,omitempty"`
+	DestinationNamespace string                         `json:"destinationNamespace,omitempty"`
+	ReplicationMode      drv1alpha1.ReplicationMode     `json:"replicationMode,omitempty"`
+	Paused               bool                           `json:"paused"`
+	Phase                drv1alpha1.SyncPhase           `json:"phase,omitempty"`
+	LastSyncTime         *metav1.Time                   `json:"lastSyncTime,omitempty"`
+	NextSyncTime         *metav1.Time                   `json:"nextSyncTime,omitempty"`
+	Progress             *drv1alpha1.SyncProgress       `json:"progress,omitempty"`
+	Stats                *drv1alpha1.SyncStats          `json:"stats,omitempty"`
+	LastError            *drv1alpha1.SyncError          `json:"lastError,omitempty"`
+	FailedResources      []drv1alpha1.SyncError         `json:"failedResources,omitempty"`
+	FailingConditions    []metav1.Condition             `json:"failingConditions,omitempty"`
+	Reconcile            *syncstate.Mapping             `json:"reconcile,omitempty"`
+	Verification         *drv1alpha1.VerificationStatus `json:"verification,omitempty"`
+}
+
+// Cluster is a RemoteCluster as shown on the dashboard
+type Cluster struct {
+	Namespace          string       `json:"namespace"`
+	Name               string       `json:"name"`
+	Health             string       `json:"health,omitempty"`
+	LastSyncTime       *metav1.Time `json:"lastSyncTime,omitempty"`
+	PVCSyncPhase       string       `json:"pvcSyncPhase,omitempty"`
+	AgentsReady        int32        `json:"agentsReady"`
+	AgentsTotal        int32        `json:"agentsTotal"`
+	LastSuccessfulSync *metav1.Time `json:"lastSuccessfulSync,omitempty"`
+}
+
+// ClusterMapping is a ClusterMapping as shown on the dashboard
+type ClusterMapping struct {
+	Namespace         string                         `json:"namespace"`
+	Name              string                         `json:"name"`
+	SourceCluster     string                         `json:"sourceCluster"`
+	TargetCluster     string                         `json:"targetCluster"`
+	Phase             drv1alpha1.ClusterMappingPhase `json:"phase,omitempty"`
+	Message           string                         `json:"message,omitempty"`
+	LastVerified      *metav1.Time                   `json:"lastVerified,omitempty"`
+	ConnectedAgents   int32                          `json:"connectedAgents"`
+	TotalSourceAgents int32                          `json:"totalSourceAgents"`
+}
+
+// State is the data shown by the dashboard, sorted by namespace and name
+type State struct {
+	Time            time.Time           `json:"time"`
+	Mappings        []Mapping           `json:"mappings"`
+	Clusters        []Cluster           `json:"clusters"`
+	ClusterMappings []ClusterMapping    `json:"clusterMappings"`
+	PVCSyncs        []syncstate.PVCSync `json:"pvcSyncs"`
+}
+
+// Handler serves the dashboard page and its state
+type Handler struct {
+	reader   client.Reader
+	snapshot func() syncstate.State
+}
+
+// NewHandler creates a Handler reading the custom resources with reader and the in-flight state from
+// the default sync state tracker
+func NewHandler(reader client.Reader) *Handler {
+	return &Handler{reader: reader, snapshot: syncstate.Snapshot}
+}
+
+// ServeHTTP serves the page on Path and the state on StatePath. The dashboard is read-only, other
+// methods than GET and HEAD are refused.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "the dashboard is read-only", http.StatusMethodNotAllowed)
+		return
+	}
+
+	switch r.URL.Path {
+	case Path, Path + "index.html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; script-src 'unsafe-inline' 'self'; style-src 'unsafe-inline' 'self'")
+		_, _ = w.Write(indexHTML)
+	case StatePath:
+		ctx, cancel := context.WithTimeout(r.Context(), stateTimeout)
+		defer cancel()
+		state, err := h.State(ctx)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "no-store")
+		_ = json.NewEncoder(w).Encode(state)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// State collects the data shown by the dashboard
+func (h *Handler) State(ctx context.Context) (*State, error) {
+	inFlight := h.snapshot()
+	state := &State{
+		Time:            inFlight.Time,
+		Mappings:        []Mapping{},
+		Clusters:        []Cluster{},
+		ClusterMappings: []ClusterMapping{},
+		PVCSyncs:        inFlight.PVCSyncs,
+	}
+
+	reconciles := make(map[string]syncstate.Mapping, len(inFlight.Mappings))
+	for _, m := range inFlight.Mappings {
+		reconciles[m.Namespace+"/"+m.Name] = m
+	}
+
+	var mappings drv1alpha1.NamespaceMappingList
+	if err := h.reader.List(ctx, &mappings); err != nil {
+		return nil, err
+	}
+	for i := range mappings.Items {
+		mapping := newMapping(&mappings.Items[i])
+		if reconcile, ok := reconciles[mapping.Namespace+"/"+mapping.Name]; ok {
+			mapping.Reconcile = &reconcile
+		}
+		state.Mappings = append(state.Mappings, mapping)
+	}
+
+	var clusters drv1alpha1.RemoteClusterList
+	if err := h.reader.List(ctx, &clusters); err != nil {
+		return nil, err
+	}
+	for i := range clusters.Items {
+		state.Clusters = append(state.Clusters, newCluster(&clusters.Items[i]))
+	}
+
+	var clusterMappings drv1alpha1.ClusterMappingList
+	if err := h.reader.List(ctx, &clusterMappings); err != nil {
+		return nil, err
+	}
+	for i := range clusterMappings.Items {
+		state.ClusterMappings = append(state.ClusterMappings, newClusterMapping(&clusterMappings.Items[i]))
+	}
+
+	sort.Slice(state.Mappings, func(i, j int) bool {
+		return less(state.Mappings[i].Namespace, state.Mappings[i].Name, state.Mappings[j].Namespace, state.Mappings[j].Name)
+	})
+	sort.Slice(state.Clusters, func(i, j int) bool {
+		return less(state.Clusters[i].Namespace, state.Clusters[i].Name, state.Clusters[j].Namespace, state.Clusters[j].Name)
+	})
+	sort.Slice(state.ClusterMappings, func(i, j int) bool {
+		return less(state.ClusterMappings[i].Namespace, state.ClusterMappings[i].Name, state.ClusterMappings[j].Namespace, state.ClusterMappings[j].Name)
+	})
+	return state, nil
+}
+
+// newMapping summarizes a NamespaceMapping
+func newMapping(nm *drv1alpha1.NamespaceMapping) Mapping {
+	mapping := Mapping{
+		Namespace:            nm.Namespace,
+		Name:                 nm.Name,
+		SourceCluster:        nm.Spec.SourceCluster,
+		DestinationCluster:   nm.Spec.DestinationCluster,
+		SourceNamespace:      nm.Spec.SourceNamespace,
+		DestinationNamespace: nm.Spec.DestinationNamespace,
+		ReplicationMode:      nm.Spec.ReplicationMode,
+		Paused:               nm.Spec.Paused != nil && *nm.Spec.Paused,
+		Phase:                nm.Status.Phase,
+		LastSyncTime:         nm.Status.LastSyncTime,
+		NextSyncTime:         nm.Status.NextSyncTime,
+		Progress:             nm.Status.SyncProgress,
+		Stats:                nm.Status.SyncStats,
+		LastError:            nm.Status.LastError,
+		FailedResources:      nm.Status.FailedResources,
+		Verification:         nm.Status.Verification,
+	}
+	if nm.Spec.ClusterMappingRef != nil {
+		mapping.ClusterMapping = nm.Spec.ClusterMappingRef.Name
+	}
+	for _, condition := range nm.Status.Conditions {
+		if condition.Status == metav1.ConditionFalse {
+			mapping.FailingConditions = append(mapping.FailingConditions, condition)
+		}
+	}
+	return mapping
+}
+
+// newCluster summarizes a RemoteCluster
+func newCluster(rc *drv1alpha1.RemoteCluster) Cluster {
+	cluster := Cluster{
+		Namespace:    rc.Namespace,
+		Name:         rc.Name,
+		Health:       rc.Status.Health,
+		LastSyncTime: rc.Status.LastSyncTime,
+	}
+	if pvcSync := rc.Status.PVCSync; pvcSync != nil {
+		cluster.PVCSyncPhase = pvcSync.Phase
+		cluster.LastSuccessfulSync = pvcSync.LastSuccessfulSync
+		if pvcSync.AgentStatus != nil {
+			cluster.AgentsReady = pvcSync.AgentStatus.ReadyNodes
+			cluster.AgentsTotal = pvcSync.AgentStatus.TotalNodes
+		}
+	}
+	return cluster
+}
+
+// newClusterMapping summarizes a ClusterMapping
+func newClusterMapping(cm *drv1alpha1.ClusterMapping) ClusterMapping {
+	clusterMapping := ClusterMapping{
+		Namespace:     cm.Namespace,
+		Name:          cm.Name,
+		SourceCluster: cm.Spec.SourceCluster,
+		TargetCluster: cm.Spec.TargetCluster,
+		Phase:         cm.Status.Phase,
+		Message:       cm.Status.Message,
+		LastVerified:  cm.Status.LastVerified,
+	}
+	if connection := cm.Status.ConnectionStatus; connection != nil {
+		clusterMapping.ConnectedAgents = connection.ConnectedAgents
+		clusterMapping.TotalSourceAgents = connection.TotalSourceAgents
+	}
+	return clusterMapping
+}
+
+func less(namespaceA, nameA, namespaceB, nameB string) bool {
+	if namespaceA != namespaceB {
+		return namespaceA < namespaceB
+	}
+	return nameA < nameB
+}
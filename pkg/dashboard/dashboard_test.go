@@ -0,0 +1,92 @@
+package dashboard
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/syncstate"
+	"github.com/supporttools/dr-syncer/pkg/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestHandlerState(t *testing.T) {
+	env := testutil.NewTestEnv(t)
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	failing := testutil.NewNamespaceMapping("shop").WithNamespace("dr-syncer").
+		WithSourceCluster("prod").WithDestinationCluster("dr").
+		WithStatusPhase(drv1alpha1.SyncPhaseFailed).Build()
+	failing.Status.LastError = &drv1alpha1.SyncError{Message: "destination cluster unreachable"}
+	failing.Status.Conditions = []metav1.Condition{
+		{Type: "Synced", Status: metav1.ConditionFalse, Reason: "SyncFailed"},
+		{Type: "StorageReady", Status: metav1.ConditionTrue, Reason: "Ready"},
+	}
+	healthy := testutil.NewNamespaceMapping("billing").WithNamespace("dr-syncer").WithClusterMappingRef("prod-to-dr").Build()
+	cluster := testutil.NewRemoteCluster("prod").WithNamespace("dr-syncer").WithStatusHealth("Healthy").Build()
+	clusterMapping := testutil.NewClusterMapping("prod-to-dr").WithNamespace("dr-syncer").WithClusters("prod", "dr").
+		WithStatusPhase(drv1alpha1.ClusterMappingPhaseConnected).Build()
+	clusterMapping.Status.ConnectionStatus = &drv1alpha1.ConnectionStatus{TotalSourceAgents: 3, ConnectedAgents: 2}
+
+	h := &Handler{
+		reader: env.NewFakeClient(failing, healthy, cluster, clusterMapping),
+		snapshot: func() syncstate.State {
+			return syncstate.State{
+				Time:     now,
+				PVCSyncs: []syncstate.PVCSync{{Namespace: "shop", PVC: "data", Phase: syncstate.PhaseRunning, Since: now}},
+				Mappings: []syncstate.Mapping{{Namespace: "dr-syncer", Name: "shop", Reconciling: true, LastStarted: now}},
+			}
+		},
+	}
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, StatePath, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var state State
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	assert.True(t, now.Equal(state.Time))
+	require.Len(t, state.Mappings, 2)
+	assert.Equal(t, "billing", state.Mappings[0].Name)
+	assert.Equal(t, "prod-to-dr", state.Mappings[0].ClusterMapping)
+	assert.Nil(t, state.Mappings[0].Reconcile)
+
+	shop := state.Mappings[1]
+	assert.Equal(t, drv1alpha1.SyncPhaseFailed, shop.Phase)
+	assert.Equal(t, "destination cluster unreachable", shop.LastError.Message)
+	require.Len(t, shop.FailingConditions, 1)
+	assert.Equal(t, "Synced", shop.FailingConditions[0].Type)
+	require.NotNil(t, shop.Reconcile)
+	assert.True(t, shop.Reconcile.Reconciling)
+
+	require.Len(t, state.PVCSyncs, 1)
+	require.Len(t, state.Clusters, 1)
+	assert.Equal(t, "Healthy", state.Clusters[0].Health)
+	require.Len(t, state.ClusterMappings, 1)
+	assert.Equal(t, int32(2), state.ClusterMappings[0].ConnectedAgents)
+	assert.Equal(t, int32(3), state.ClusterMappings[0].TotalSourceAgents)
+}
+
+func TestHandlerPage(t *testing.T) {
+	h := &Handler{}
+
+	recorder := httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path, nil))
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+	assert.Contains(t, recorder.Body.String(), "api/state")
+
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, Path+"unknown", nil))
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+
+	// The dashboard is read-only
+	recorder = httptest.NewRecorder()
+	h.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, StatePath, nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+}
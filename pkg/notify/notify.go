@@ -0,0 +1,211 @@
+// Package notify sends DR events such as sync failures, RPO breaches and cutovers to
+// chat and incident tooling through webhooks (Slack, Microsoft Teams or generic JSON).
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/supporttools/dr-syncer/pkg/logging"
+)
+
+var log = logging.SetupLogging()
+
+// Event is a DR event that can be notified
+type Event string
+
+const (
+	// EventSyncFailed is sent when a NamespaceMapping sync starts failing
+	EventSyncFailed Event = "SyncFailed"
+	// EventRPOBreached is sent when the time since the last successful sync of a NamespaceMapping
+	// exceeds twice its RPO target, i.e. at least one scheduled sync was missed
+	EventRPOBreached Event = "RPOBreached"
+	// EventCutoverStarted is sent when a cutover to the destination begins
+	EventCutoverStarted Event = "CutoverStarted"
+	// EventCutoverCompleted is sent when a cutover to the destination finished successfully
+	EventCutoverCompleted Event = "CutoverCompleted"
+)
+
+// Events lists all events that can be notified
+var Events = []Event{EventSyncFailed, EventRPOBreached, EventCutoverStarted, EventCutoverCompleted}
+
+// Format is the payload format of a webhook
+type Format string
+
+const (
+	// FormatGeneric posts the notification as JSON
+	FormatGeneric Format = "Generic"
+	// FormatSlack posts a Slack incoming webhook message
+	FormatSlack Format = "Slack"
+	// FormatTeams posts a Microsoft Teams incoming webhook message card
+	FormatTeams Format = "Teams"
+)
+
+// sendTimeout bounds a single webhook request
+const sendTimeout = 10 * time.Second
+
+// Webhook is a notification target
+type Webhook struct {
+	URL    string
+	Format Format
+	// Events filters the events sent to the webhook, all events are sent when empty
+	Events []Event
+}
+
+// Wants reports whether event is sent to the webhook
+func (w Webhook) Wants(event Event) bool {
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Notification is a single DR event
+type Notification struct {
+	Event                Event     `json:"event"`
+	Mapping              string    `json:"mapping,omitempty"`
+	SourceNamespace      string    `json:"sourceNamespace,omitempty"`
+	DestinationNamespace string    `json:"destinationNamespace,omitempty"`
+	Message              string    `json:"message,omitempty"`
+	Timestamp            time.Time `json:"timestamp"`
+}
+
+// Text renders the notification as a single chat message line
+func (n Notification) Text() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[dr-syncer] %s", n.Event)
+	if n.Mapping != "" {
+		fmt.Fprintf(&b, " for NamespaceMapping %s", n.Mapping)
+	}
+	if n.SourceNamespace != "" || n.DestinationNamespace != "" {
+		fmt.Fprintf(&b, " (%s -> %s)", n.SourceNamespace, n.DestinationNamespace)
+	}
+	if n.Message != "" {
+		fmt.Fprintf(&b, ": %s", n.Message)
+	}
+	return b.String()
+}
+
+// ParseFormat validates a webhook format, an empty format is Generic
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case "":
+		return FormatGeneric, nil
+	case FormatGeneric, FormatSlack, FormatTeams:
+		return Format(format), nil
+	default:
+		return "", fmt.Errorf("unsupported notification format %q, must be one of Generic, Slack, Teams", format)
+	}
+}
+
+// ParseEvents parses a comma-separated list of events, an empty list selects all events
+func ParseEvents(events string) ([]Event, error) {
+	var parsed []Event
+	for _, name := range strings.Split(events, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		known := false
+		for _, event := range Events {
+			if Event(name) == event {
+				known = true
+				break
+			}
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported notification event %q", name)
+		}
+		parsed = append(parsed, Event(name))
+	}
+	return parsed, nil
+}
+
+// Payload renders the request body of a notification in the given format
+func Payload(format Format, n Notification) ([]byte, error) {
+	switch format {
+	case FormatSlack:
+		return json.Marshal(map[string]string{"text": n.Text()})
+	case FormatTeams:
+		return json.Marshal(map[string]string{
+			"@type":    "MessageCard",
+			"@context": "https://schema.org/extensions",
+			"summary":  fmt.Sprintf("dr-syncer %s", n.Event),
+			"text":     n.Text(),
+		})
+	default:
+		return json.Marshal(n)
+	}
+}
+
+// Send posts a notification to a webhook, failing on non-2xx responses
+func Send(ctx context.Context, webhook Webhook, n Notification) error {
+	body, err := Payload(webhook.Format, n)
+	if err != nil {
+		return fmt.Errorf("failed to render notification: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, sendTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build notification request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("notification webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+var (
+	webhooksMu sync.RWMutex
+	webhooks   []Webhook
+)
+
+// RegisterWebhook adds a webhook that receives the notifications of every NamespaceMapping
+func RegisterWebhook(webhook Webhook) {
+	webhooksMu.Lock()
+	defer webhooksMu.Unlock()
+	webhooks = append(webhooks, webhook)
+}
+
+// Dispatch sends a notification to the registered webhooks and the given additional webhooks that
+// want its event. Notifications are best effort, failures are logged.
+func Dispatch(ctx context.Context, n Notification, additional ...Webhook) {
+	if n.Timestamp.IsZero() {
+		n.Timestamp = time.Now().UTC()
+	}
+
+	webhooksMu.RLock()
+	targets := append(append([]Webhook{}, webhooks...), additional...)
+	webhooksMu.RUnlock()
+
+	for _, webhook := range targets {
+		if !webhook.Wants(n.Event) {
+			continue
+		}
+		if err := Send(ctx, webhook, n); err != nil {
+			log.Errorf("failed to send %s notification: %v", n.Event, err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNotification = Notification{
+	Event:                EventSyncFailed,
+	Mapping:              "dr-syncer/shop",
+	SourceNamespace:      "shop",
+	DestinationNamespace: "shop-dr",
+	Message:              "failed to sync Deployment/web",
+	Timestamp:            time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+}
+
+func TestPayload(t *testing.T) {
+	const text = "[dr-syncer] SyncFailed for NamespaceMapping dr-syncer/shop (shop -> shop-dr): failed to sync Deployment/web"
+
+	body, err := Payload(FormatSlack, testNotification)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"text": "`+text+`"}`, string(body))
+
+	body, err = Payload(FormatTeams, testNotification)
+	require.NoError(t, err)
+	var card map[string]string
+	require.NoError(t, json.Unmarshal(body, &card))
+	assert.Equal(t, "MessageCard", card["@type"])
+	assert.Equal(t, text, card["text"])
+
+	body, err = Payload(FormatGeneric, testNotification)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"event": "SyncFailed",
+		"mapping": "dr-syncer/shop",
+		"sourceNamespace": "shop",
+		"destinationNamespace": "shop-dr",
+		"message": "failed to sync Deployment/web",
+		"timestamp": "2026-01-01T00:00:00Z"
+	}`, string(body))
+}
+
+func TestParseEvents(t *testing.T) {
+	events, err := ParseEvents("")
+	require.NoError(t, err)
+	assert.Empty(t, events)
+
+	events, err = ParseEvents("SyncFailed, CutoverCompleted")
+	require.NoError(t, err)
+	assert.Equal(t, []Event{EventSyncFailed, EventCutoverCompleted}, events)
+
+	_, err = ParseEvents("SyncFailed,Exploded")
+	assert.Error(t, err)
+
+	_, err = ParseFormat("Discord")
+	assert.Error(t, err)
+}
+
+func TestDispatch(t *testing.T) {
+	var paths, bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		paths = append(paths, r.URL.Path)
+		bodies = append(bodies, string(body))
+	}))
+	defer server.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	Dispatch(context.Background(), Notification{Event: EventCutoverStarted, SourceNamespace: "shop", DestinationNamespace: "shop-dr"},
+		Webhook{URL: failing.URL},
+		Webhook{URL: server.URL + "/slack", Format: FormatSlack},
+		Webhook{URL: server.URL + "/failures", Events: []Event{EventSyncFailed}},
+	)
+
+	require.Equal(t, []string{"/slack"}, paths, "failed webhooks do not stop delivery and filtered events are skipped")
+	assert.JSONEq(t, `{"text": "[dr-syncer] CutoverStarted (shop -> shop-dr)"}`, bodies[0])
+}
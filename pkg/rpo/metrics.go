@@ -0,0 +1,193 @@
+// Package rpo exposes staleness and recovery point objective (RPO) metrics for
+// NamespaceMappings and replicated PVCs. Values are computed at scrape time from
+// the last successful sync, so the estimated RPO keeps growing while replication
+// is stalled and alerting rules can fire without waiting for the next sync.
+package rpo
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	mappingLastSyncDesc = prometheus.NewDesc(
+		"dr_syncer_last_successful_sync_timestamp_seconds",
+		"Unix timestamp of the last successful resource sync of a NamespaceMapping",
+		[]string{"namespace", "mapping"}, nil,
+	)
+	mappingRPODesc = prometheus.NewDesc(
+		"dr_syncer_estimated_rpo_seconds",
+		"Estimated recovery point objective of a NamespaceMapping: seconds since its last successful sync",
+		[]string{"namespace", "mapping"}, nil,
+	)
+	mappingTargetDesc = prometheus.NewDesc(
+		"dr_syncer_rpo_target_seconds",
+		"Expected interval between successful syncs of a NamespaceMapping, derived from its schedule",
+		[]string{"namespace", "mapping"}, nil,
+	)
+	pvcLastSyncDesc = prometheus.NewDesc(
+		"dr_syncer_pvc_last_successful_sync_timestamp_seconds",
+		"Unix timestamp of the data captured by the last successful PVC data sync",
+		[]string{"namespace", "pvc_name", "destination_namespace"}, nil,
+	)
+	pvcRPODesc = prometheus.NewDesc(
+		"dr_syncer_pvc_estimated_rpo_seconds",
+		"Estimated recovery point objective of a PVC: seconds since the data of its last successful sync was captured",
+		[]string{"namespace", "pvc_name", "destination_namespace"}, nil,
+	)
+)
+
+// syncRecord is the last successful sync of a NamespaceMapping or PVC
+type syncRecord struct {
+	syncedAt time.Time
+	target   time.Duration
+}
+
+type mappingKey struct {
+	namespace string
+	name      string
+}
+
+type pvcKey struct {
+	namespace     string
+	name          string
+	destNamespace string
+}
+
+// Tracker records the last successful syncs and reports them as a Prometheus collector
+type Tracker struct {
+	mu       sync.Mutex
+	mappings map[mappingKey]syncRecord
+	pvcs     map[pvcKey]syncRecord
+	now      func() time.Time
+}
+
+// NewTracker creates an empty Tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		mappings: make(map[mappingKey]syncRecord),
+		pvcs:     make(map[pvcKey]syncRecord),
+		now:      time.Now,
+	}
+}
+
+// defaultTracker is registered with the controller-runtime metrics registry
+var defaultTracker = NewTracker()
+
+func init() {
+	// Register metrics with the controller-runtime metrics registry
+	metrics.Registry.MustRegister(defaultTracker)
+}
+
+// RecordMappingSync records a successful sync of a NamespaceMapping. A zero target means the
+// mapping has no expected sync interval and no target is reported.
+func (t *Tracker) RecordMappingSync(namespace, name string, syncedAt time.Time, target time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := mappingKey{namespace: namespace, name: name}
+	// Never move backwards, an older status can be observed after a newer sync was recorded
+	if existing, ok := t.mappings[key]; ok && existing.syncedAt.After(syncedAt) {
+		syncedAt = existing.syncedAt
+	}
+	t.mappings[key] = syncRecord{syncedAt: syncedAt, target: target}
+}
+
+// ForgetMapping stops reporting a NamespaceMapping
+func (t *Tracker) ForgetMapping(namespace, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.mappings, mappingKey{namespace: namespace, name: name})
+}
+
+// MappingRPO returns the estimated RPO of a NamespaceMapping, i.e. the time since its last successful
+// sync, and its RPO target. The last return value is false when no successful sync was recorded.
+func (t *Tracker) MappingRPO(namespace, name string) (time.Duration, time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	record, ok := t.mappings[mappingKey{namespace: namespace, name: name}]
+	if !ok {
+		return 0, 0, false
+	}
+	return t.now().Sub(record.syncedAt), record.target, true
+}
+
+// RecordPVCSync records a successful PVC data sync that captured the source data at syncedAt
+func (t *Tracker) RecordPVCSync(namespace, pvcName, destNamespace string, syncedAt time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pvcs[pvcKey{namespace: namespace, name: pvcName, destNamespace: destNamespace}] = syncRecord{syncedAt: syncedAt}
+}
+
+// ForgetPVCs stops reporting the PVCs replicated from namespace to destNamespace
+func (t *Tracker) ForgetPVCs(namespace, destNamespace string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for key := range t.pvcs {
+		if key.namespace == namespace && key.destNamespace == destNamespace {
+			delete(t.pvcs, key)
+		}
+	}
+}
+
+// Describe implements prometheus.Collector
+func (t *Tracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- mappingLastSyncDesc
+	ch <- mappingRPODesc
+	ch <- mappingTargetDesc
+	ch <- pvcLastSyncDesc
+	ch <- pvcRPODesc
+}
+
+// Collect implements prometheus.Collector
+func (t *Tracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	for key, record := range t.mappings {
+		ch <- prometheus.MustNewConstMetric(mappingLastSyncDesc, prometheus.GaugeValue,
+			float64(record.syncedAt.Unix()), key.namespace, key.name)
+		ch <- prometheus.MustNewConstMetric(mappingRPODesc, prometheus.GaugeValue,
+			now.Sub(record.syncedAt).Seconds(), key.namespace, key.name)
+		if record.target > 0 {
+			ch <- prometheus.MustNewConstMetric(mappingTargetDesc, prometheus.GaugeValue,
+				record.target.Seconds(), key.namespace, key.name)
+		}
+	}
+	for key, record := range t.pvcs {
+		ch <- prometheus.MustNewConstMetric(pvcLastSyncDesc, prometheus.GaugeValue,
+			float64(record.syncedAt.Unix()), key.namespace, key.name, key.destNamespace)
+		ch <- prometheus.MustNewConstMetric(pvcRPODesc, prometheus.GaugeValue,
+			now.Sub(record.syncedAt).Seconds(), key.namespace, key.name, key.destNamespace)
+	}
+}
+
+// RecordMappingSync records a successful NamespaceMapping sync on the registered tracker
+func RecordMappingSync(namespace, name string, syncedAt time.Time, target time.Duration) {
+	defaultTracker.RecordMappingSync(namespace, name, syncedAt, target)
+}
+
+// ForgetMapping stops reporting a NamespaceMapping on the registered tracker
+func ForgetMapping(namespace, name string) {
+	defaultTracker.ForgetMapping(namespace, name)
+}
+
+// MappingRPO returns the estimated RPO and RPO target of a NamespaceMapping on the registered tracker
+func MappingRPO(namespace, name string) (time.Duration, time.Duration, bool) {
+	return defaultTracker.MappingRPO(namespace, name)
+}
+
+// RecordPVCSync records a successful PVC data sync on the registered tracker
+func RecordPVCSync(namespace, pvcName, destNamespace string, syncedAt time.Time) {
+	defaultTracker.RecordPVCSync(namespace, pvcName, destNamespace, syncedAt)
+}
+
+// ForgetPVCs stops reporting the PVCs replicated from namespace to destNamespace on the registered tracker
+func ForgetPVCs(namespace, destNamespace string) {
+	defaultTracker.ForgetPVCs(namespace, destNamespace)
+}
@@ -0,0 +1,84 @@
+package rpo
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testNow = time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestTracker() *Tracker {
+	t := NewTracker()
+	t.now = func() time.Time { return testNow }
+	return t
+}
+
+func TestTracker_MappingMetrics(t *testing.T) {
+	tracker := newTestTracker()
+	tracker.RecordMappingSync("dr-syncer", "web", testNow.Add(-10*time.Minute), 5*time.Minute)
+	tracker.RecordMappingSync("dr-syncer", "manual", testNow.Add(-time.Hour), 0)
+
+	expected := `
+# HELP dr_syncer_estimated_rpo_seconds Estimated recovery point objective of a NamespaceMapping: seconds since its last successful sync
+# TYPE dr_syncer_estimated_rpo_seconds gauge
+dr_syncer_estimated_rpo_seconds{mapping="manual",namespace="dr-syncer"} 3600
+dr_syncer_estimated_rpo_seconds{mapping="web",namespace="dr-syncer"} 600
+# HELP dr_syncer_rpo_target_seconds Expected interval between successful syncs of a NamespaceMapping, derived from its schedule
+# TYPE dr_syncer_rpo_target_seconds gauge
+dr_syncer_rpo_target_seconds{mapping="web",namespace="dr-syncer"} 300
+`
+	require.NoError(t, testutil.CollectAndCompare(tracker, strings.NewReader(expected),
+		"dr_syncer_estimated_rpo_seconds", "dr_syncer_rpo_target_seconds"))
+
+	tracker.ForgetMapping("dr-syncer", "manual")
+	assert.Equal(t, 3, testutil.CollectAndCount(tracker))
+}
+
+func TestTracker_MappingRPO(t *testing.T) {
+	tracker := newTestTracker()
+	_, _, ok := tracker.MappingRPO("dr-syncer", "web")
+	assert.False(t, ok)
+
+	tracker.RecordMappingSync("dr-syncer", "web", testNow.Add(-10*time.Minute), 5*time.Minute)
+	estimate, target, ok := tracker.MappingRPO("dr-syncer", "web")
+	require.True(t, ok)
+	assert.Equal(t, 10*time.Minute, estimate)
+	assert.Equal(t, 5*time.Minute, target)
+}
+
+func TestTracker_MappingSyncNeverMovesBackwards(t *testing.T) {
+	tracker := newTestTracker()
+	tracker.RecordMappingSync("dr-syncer", "web", testNow.Add(-time.Minute), 0)
+	tracker.RecordMappingSync("dr-syncer", "web", testNow.Add(-time.Hour), 0)
+
+	expected := `
+# HELP dr_syncer_last_successful_sync_timestamp_seconds Unix timestamp of the last successful resource sync of a NamespaceMapping
+# TYPE dr_syncer_last_successful_sync_timestamp_seconds gauge
+dr_syncer_last_successful_sync_timestamp_seconds{mapping="web",namespace="dr-syncer"} 1.77236634e+09
+`
+	require.NoError(t, testutil.CollectAndCompare(tracker, strings.NewReader(expected),
+		"dr_syncer_last_successful_sync_timestamp_seconds"))
+}
+
+func TestTracker_PVCMetrics(t *testing.T) {
+	tracker := newTestTracker()
+	tracker.RecordPVCSync("app", "data", "app-dr", testNow.Add(-2*time.Hour))
+	tracker.RecordPVCSync("other", "data", "other-dr", testNow.Add(-time.Minute))
+
+	expected := `
+# HELP dr_syncer_pvc_estimated_rpo_seconds Estimated recovery point objective of a PVC: seconds since the data of its last successful sync was captured
+# TYPE dr_syncer_pvc_estimated_rpo_seconds gauge
+dr_syncer_pvc_estimated_rpo_seconds{destination_namespace="app-dr",namespace="app",pvc_name="data"} 7200
+dr_syncer_pvc_estimated_rpo_seconds{destination_namespace="other-dr",namespace="other",pvc_name="data"} 60
+`
+	require.NoError(t, testutil.CollectAndCompare(tracker, strings.NewReader(expected),
+		"dr_syncer_pvc_estimated_rpo_seconds"))
+
+	tracker.ForgetPVCs("app", "app-dr")
+	assert.Equal(t, 2, testutil.CollectAndCount(tracker))
+}
@@ -0,0 +1,152 @@
+package syncstate
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Load summarizes how far the controller is behind its work
+type Load struct {
+	// QueueDepth is the number of NamespaceMappings waiting for a reconcile worker
+	QueueDepth int
+
+	// ReconcilesInFlight is the number of NamespaceMappings being reconciled
+	ReconcilesInFlight int
+
+	// OverdueMappings is the number of NamespaceMappings whose requeue passed without a reconcile starting
+	OverdueMappings int
+
+	// PVCSyncsWaiting and PVCSyncsRunning count the PVC data syncs waiting for and holding a concurrency slot
+	PVCSyncsWaiting int
+	PVCSyncsRunning int
+
+	// ClusterPending counts the NamespaceMappings reconciling or overdue per cluster
+	ClusterPending map[string]int
+}
+
+// Load summarizes the state
+func (s State) Load() Load {
+	load := Load{QueueDepth: s.QueueDepth, ClusterPending: make(map[string]int)}
+	for _, sync := range s.PVCSyncs {
+		if sync.Phase == PhaseWaiting {
+			load.PVCSyncsWaiting++
+		} else {
+			load.PVCSyncsRunning++
+		}
+	}
+	for _, mapping := range s.Mappings {
+		overdue := mapping.Overdue(s.Time)
+		if mapping.Reconciling {
+			load.ReconcilesInFlight++
+		}
+		if overdue {
+			load.OverdueMappings++
+		}
+		if !mapping.Reconciling && !overdue {
+			continue
+		}
+		seen := make(map[string]bool, len(mapping.Clusters))
+		for _, cluster := range mapping.Clusters {
+			if cluster != "" && !seen[cluster] {
+				seen[cluster] = true
+				load.ClusterPending[cluster]++
+			}
+		}
+	}
+	return load
+}
+
+var (
+	reconcileQueueDepthDesc = prometheus.NewDesc("dr_syncer_reconcile_queue_depth",
+		"Number of NamespaceMappings waiting for a reconcile worker", nil, nil)
+	reconcilesInFlightDesc = prometheus.NewDesc("dr_syncer_reconciles_in_flight",
+		"Number of NamespaceMappings being reconciled", nil, nil)
+	overdueMappingsDesc = prometheus.NewDesc("dr_syncer_mappings_overdue",
+		"Number of NamespaceMappings whose scheduled reconcile is due but has not started", nil, nil)
+	pvcSyncsInFlightDesc = prometheus.NewDesc("dr_syncer_pvc_syncs_in_flight",
+		"Number of PVC data syncs waiting for or holding a concurrency slot", []string{"phase"}, nil)
+	clusterPendingDesc = prometheus.NewDesc("dr_syncer_cluster_pending_operations",
+		"Number of NamespaceMappings of the cluster being reconciled or overdue", []string{"remote_cluster"}, nil)
+)
+
+// collector exports the load of a tracker, computed when the metrics are scraped
+type collector struct {
+	tracker *Tracker
+}
+
+// Describe implements prometheus.Collector
+func (c collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- reconcileQueueDepthDesc
+	ch <- reconcilesInFlightDesc
+	ch <- overdueMappingsDesc
+	ch <- pvcSyncsInFlightDesc
+	ch <- clusterPendingDesc
+}
+
+// Collect implements prometheus.Collector
+func (c collector) Collect(ch chan<- prometheus.Metric) {
+	load := c.tracker.Snapshot().Load()
+	ch <- prometheus.MustNewConstMetric(reconcileQueueDepthDesc, prometheus.GaugeValue, float64(load.QueueDepth))
+	ch <- prometheus.MustNewConstMetric(reconcilesInFlightDesc, prometheus.GaugeValue, float64(load.ReconcilesInFlight))
+	ch <- prometheus.MustNewConstMetric(overdueMappingsDesc, prometheus.GaugeValue, float64(load.OverdueMappings))
+	ch <- prometheus.MustNewConstMetric(pvcSyncsInFlightDesc, prometheus.GaugeValue, float64(load.PVCSyncsWaiting), PhaseWaiting)
+	ch <- prometheus.MustNewConstMetric(pvcSyncsInFlightDesc, prometheus.GaugeValue, float64(load.PVCSyncsRunning), PhaseRunning)
+	for cluster, pending := range load.ClusterPending {
+		ch <- prometheus.MustNewConstMetric(clusterPendingDesc, prometheus.GaugeValue, float64(pending), cluster)
+	}
+}
+
+func init() {
+	metrics.Registry.MustRegister(collector{tracker: defaultTracker})
+}
+
+// Thresholds are the load above which the controller reports itself not ready. Zero disables a threshold.
+type Thresholds struct {
+	// MaxQueueDepth is the number of NamespaceMappings that may wait for a reconcile worker
+	MaxQueueDepth int
+
+	// MaxOverdueMappings is the number of NamespaceMappings whose scheduled reconcile may be overdue
+	MaxOverdueMappings int
+
+	// MaxWaitingPVCSyncs is the number of PVC data syncs that may wait for a concurrency slot
+	MaxWaitingPVCSyncs int
+}
+
+// Enabled reports whether any threshold is set
+func (t Thresholds) Enabled() bool {
+	return t.MaxQueueDepth > 0 || t.MaxOverdueMappings > 0 || t.MaxWaitingPVCSyncs > 0
+}
+
+// Exceeded returns the thresholds a load exceeds, empty when it is within all of them
+func (t Thresholds) Exceeded(load Load) []string {
+	var exceeded []string
+	check := func(name string, value, limit int) {
+		if limit > 0 && value > limit {
+			exceeded = append(exceeded, fmt.Sprintf("%s %d > %d", name, value, limit))
+		}
+	}
+	check("reconcile queue depth", load.QueueDepth, t.MaxQueueDepth)
+	check("overdue mappings", load.OverdueMappings, t.MaxOverdueMappings)
+	check("waiting PVC syncs", load.PVCSyncsWaiting, t.MaxWaitingPVCSyncs)
+	return exceeded
+}
+
+// ReadyCheck returns a readiness check failing while the load of the tracker exceeds the thresholds
+func (t *Tracker) ReadyCheck(thresholds Thresholds) healthz.Checker {
+	return func(_ *http.Request) error {
+		if exceeded := thresholds.Exceeded(t.Snapshot().Load()); len(exceeded) > 0 {
+			return fmt.Errorf("controller overloaded: %s", strings.Join(exceeded, ", "))
+		}
+		return nil
+	}
+}
+
+// ReadyCheck returns a readiness check of the default tracker failing while its load exceeds the thresholds
+func ReadyCheck(thresholds Thresholds) healthz.Checker {
+	return defaultTracker.ReadyCheck(thresholds)
+}
@@ -0,0 +1,95 @@
+package syncstate
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadedTracker(now *time.Time) *Tracker {
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return *now }
+	tracker.TrackQueue(func() int { return 7 })
+
+	tracker.SyncWaiting("shop", "data")
+	tracker.SyncWaiting("shop", "uploads")
+	tracker.SyncStarted("app", "uploads")
+
+	// app-dr is reconciling, shop-dr is overdue and web-dr is due later
+	tracker.ReconcileStarted("app", "app-dr")
+	tracker.MappingClusters("app", "app-dr", "prod", "dr")
+	for _, name := range []string{"shop-dr", "web-dr"} {
+		tracker.ReconcileStarted("shop", name)
+		tracker.MappingClusters("shop", name, "prod", "dr-2")
+	}
+	tracker.ReconcileFinished("shop", "shop-dr", time.Minute, nil)
+	tracker.ReconcileFinished("shop", "web-dr", time.Hour, nil)
+	*now = now.Add(2 * time.Minute)
+	return tracker
+}
+
+func TestLoad(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	state := loadedTracker(&now).Snapshot()
+
+	assert.Equal(t, Load{
+		QueueDepth:         7,
+		ReconcilesInFlight: 1,
+		OverdueMappings:    1,
+		PVCSyncsWaiting:    2,
+		PVCSyncsRunning:    1,
+		ClusterPending:     map[string]int{"prod": 2, "dr": 1, "dr-2": 1},
+	}, state.Load())
+
+	// The next reconcile is reported until the mapping is reconciled again
+	for _, mapping := range state.Mappings {
+		if mapping.Name == "shop-dr" {
+			require.NotNil(t, mapping.NextReconcile)
+			assert.Equal(t, now.Add(-time.Minute), *mapping.NextReconcile)
+			assert.Equal(t, []string{"prod", "dr-2"}, mapping.Clusters)
+		}
+	}
+}
+
+func TestThresholds(t *testing.T) {
+	load := Load{QueueDepth: 7, OverdueMappings: 1, PVCSyncsWaiting: 2}
+
+	assert.False(t, Thresholds{}.Enabled())
+	assert.Empty(t, Thresholds{}.Exceeded(load))
+	assert.Empty(t, Thresholds{MaxQueueDepth: 7, MaxOverdueMappings: 1, MaxWaitingPVCSyncs: 2}.Exceeded(load))
+	assert.Equal(t, []string{"reconcile queue depth 7 > 5", "waiting PVC syncs 2 > 1"},
+		Thresholds{MaxQueueDepth: 5, MaxOverdueMappings: 3, MaxWaitingPVCSyncs: 1}.Exceeded(load))
+}
+
+func TestReadyCheck(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := loadedTracker(&now)
+	request := httptest.NewRequest("GET", "/readyz", nil)
+
+	assert.NoError(t, tracker.ReadyCheck(Thresholds{MaxQueueDepth: 10})(request))
+	err := tracker.ReadyCheck(Thresholds{MaxQueueDepth: 10, MaxOverdueMappings: 0, MaxWaitingPVCSyncs: 1})(request)
+	require.Error(t, err)
+	assert.Equal(t, "controller overloaded: waiting PVC syncs 2 > 1", err.Error())
+}
+
+func TestCollector(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	c := collector{tracker: loadedTracker(&now)}
+
+	// Queue depth, reconciles, overdue mappings, two PVC sync phases and three clusters
+	assert.Equal(t, 8, testutil.CollectAndCount(c))
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(`
+# HELP dr_syncer_pvc_syncs_in_flight Number of PVC data syncs waiting for or holding a concurrency slot
+# TYPE dr_syncer_pvc_syncs_in_flight gauge
+dr_syncer_pvc_syncs_in_flight{phase="Running"} 1
+dr_syncer_pvc_syncs_in_flight{phase="Waiting"} 2
+# HELP dr_syncer_reconcile_queue_depth Number of NamespaceMappings waiting for a reconcile worker
+# TYPE dr_syncer_reconcile_queue_depth gauge
+dr_syncer_reconcile_queue_depth 7
+`), "dr_syncer_reconcile_queue_depth", "dr_syncer_pvc_syncs_in_flight"))
+}
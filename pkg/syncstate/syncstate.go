@@ -0,0 +1,326 @@
+// Package syncstate keeps the in-flight state of the controller for supportability: the PVC syncs
+// waiting for or holding a concurrency slot, the PVC locks held by this controller, the reconcile
+// state of each NamespaceMapping and the depth of the reconcile queue. The state is served as JSON on
+// /debug/syncstate and summarized as metrics and an optional readiness check.
+package syncstate
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Path is where the state is served on the metrics server
+const Path = "/debug/syncstate"
+
+// PVC sync phases
+const (
+	PhaseWaiting = "Waiting"
+	PhaseRunning = "Running"
+)
+
+// PVCSync is a PVC data sync waiting for or holding a concurrency slot
+type PVCSync struct {
+	Namespace string    `json:"namespace"`
+	PVC       string    `json:"pvc"`
+	Phase     string    `json:"phase"`
+	Since     time.Time `json:"since"`
+}
+
+// Lock is a PVC lock held by this controller
+type Lock struct {
+	Namespace  string    `json:"namespace"`
+	PVC        string    `json:"pvc"`
+	Owner      string    `json:"owner"`
+	AcquiredAt time.Time `json:"acquiredAt"`
+}
+
+// Mapping is the reconcile state of a NamespaceMapping
+type Mapping struct {
+	Namespace    string     `json:"namespace"`
+	Name         string     `json:"name"`
+	Reconciling  bool       `json:"reconciling"`
+	Reconciles   int64      `json:"reconciles"`
+	LastStarted  time.Time  `json:"lastStarted"`
+	LastFinished *time.Time `json:"lastFinished,omitempty"`
+	LastDuration string     `json:"lastDuration,omitempty"`
+	LastError    string     `json:"lastError,omitempty"`
+	RequeueAfter string     `json:"requeueAfter,omitempty"`
+
+	// NextReconcile is when the mapping asked to be reconciled again
+	NextReconcile *time.Time `json:"nextReconcile,omitempty"`
+
+	// Clusters are the source and destination clusters of the mapping
+	Clusters []string `json:"clusters,omitempty"`
+}
+
+// Overdue reports whether the requeue of a mapping that is not reconciling passed at now
+func (m Mapping) Overdue(now time.Time) bool {
+	return !m.Reconciling && m.NextReconcile != nil && !m.NextReconcile.After(now)
+}
+
+// State is a snapshot of the controller state, sorted by namespace and name
+type State struct {
+	Time       time.Time `json:"time"`
+	QueueDepth int       `json:"queueDepth"`
+	PVCSyncs   []PVCSync `json:"pvcSyncs"`
+	Locks      []Lock    `json:"locks"`
+	Mappings   []Mapping `json:"mappings"`
+}
+
+type key struct {
+	namespace string
+	name      string
+}
+
+// Tracker records the in-flight state of the controller
+type Tracker struct {
+	mu       sync.Mutex
+	syncs    map[key]PVCSync
+	locks    map[key]Lock
+	mappings map[key]*Mapping
+	now      func() time.Time
+
+	// queueDepth returns the number of NamespaceMappings waiting in the reconcile queue
+	queueDepth func() int
+}
+
+// NewTracker creates an empty Tracker
+func NewTracker() *Tracker {
+	return &Tracker{
+		syncs:    make(map[key]PVCSync),
+		locks:    make(map[key]Lock),
+		mappings: make(map[key]*Mapping),
+		now:      time.Now,
+	}
+}
+
+// defaultTracker is served by Handler
+var defaultTracker = NewTracker()
+
+// SyncWaiting records a PVC sync waiting for a concurrency slot
+func (t *Tracker) SyncWaiting(namespace, pvc string) {
+	t.setSync(namespace, pvc, PhaseWaiting)
+}
+
+// SyncStarted records a PVC sync that acquired a concurrency slot
+func (t *Tracker) SyncStarted(namespace, pvc string) {
+	t.setSync(namespace, pvc, PhaseRunning)
+}
+
+func (t *Tracker) setSync(namespace, pvc, phase string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.syncs[key{namespace, pvc}] = PVCSync{Namespace: namespace, PVC: pvc, Phase: phase, Since: t.now()}
+}
+
+// SyncFinished removes a PVC sync that released or never acquired its concurrency slot
+func (t *Tracker) SyncFinished(namespace, pvc string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.syncs, key{namespace, pvc})
+}
+
+// LockAcquired records a PVC lock acquired by this controller
+func (t *Tracker) LockAcquired(namespace, pvc, owner string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	k := key{namespace, pvc}
+	if _, held := t.locks[k]; held {
+		return
+	}
+	t.locks[k] = Lock{Namespace: namespace, PVC: pvc, Owner: owner, AcquiredAt: t.now()}
+}
+
+// LockReleased removes a PVC lock released by this controller
+func (t *Tracker) LockReleased(namespace, pvc string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.locks, key{namespace, pvc})
+}
+
+// ReconcileStarted records the start of a reconcile of a NamespaceMapping
+func (t *Tracker) ReconcileStarted(namespace, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	k := key{namespace, name}
+	mapping, ok := t.mappings[k]
+	if !ok {
+		mapping = &Mapping{Namespace: namespace, Name: name}
+		t.mappings[k] = mapping
+	}
+	mapping.Reconciling = true
+	mapping.Reconciles++
+	mapping.LastStarted = t.now()
+}
+
+// ReconcileFinished records the result of a reconcile of a NamespaceMapping
+func (t *Tracker) ReconcileFinished(namespace, name string, requeueAfter time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	mapping, ok := t.mappings[key{namespace, name}]
+	if !ok {
+		return
+	}
+	now := t.now()
+	mapping.Reconciling = false
+	mapping.LastFinished = &now
+	mapping.LastDuration = now.Sub(mapping.LastStarted).String()
+	mapping.LastError = ""
+	if err != nil {
+		mapping.LastError = err.Error()
+	}
+	mapping.RequeueAfter = ""
+	mapping.NextReconcile = nil
+	if requeueAfter > 0 {
+		mapping.RequeueAfter = requeueAfter.String()
+		next := now.Add(requeueAfter)
+		mapping.NextReconcile = &next
+	}
+}
+
+// MappingClusters records the source and destination clusters of a NamespaceMapping
+func (t *Tracker) MappingClusters(namespace, name string, clusters ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if mapping, ok := t.mappings[key{namespace, name}]; ok {
+		mapping.Clusters = clusters
+	}
+}
+
+// TrackQueue records the function returning the depth of the NamespaceMapping reconcile queue
+func (t *Tracker) TrackQueue(depth func() int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queueDepth = depth
+}
+
+// ForgetMapping removes a deleted NamespaceMapping
+func (t *Tracker) ForgetMapping(namespace, name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.mappings, key{namespace, name})
+}
+
+// Snapshot returns a copy of the current state
+func (t *Tracker) Snapshot() State {
+	t.mu.Lock()
+	queueDepth := t.queueDepth
+	t.mu.Unlock()
+
+	// The queue has its own lock, it is read without holding the lock of the tracker
+	var depth int
+	if queueDepth != nil {
+		depth = queueDepth()
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	state := State{
+		QueueDepth: depth,
+		Time:       t.now(),
+		PVCSyncs:   make([]PVCSync, 0, len(t.syncs)),
+		Locks:      make([]Lock, 0, len(t.locks)),
+		Mappings:   make([]Mapping, 0, len(t.mappings)),
+	}
+	for _, s := range t.syncs {
+		state.PVCSyncs = append(state.PVCSyncs, s)
+	}
+	for _, l := range t.locks {
+		state.Locks = append(state.Locks, l)
+	}
+	for _, m := range t.mappings {
+		mapping := *m
+		mapping.Clusters = append([]string(nil), m.Clusters...)
+		state.Mappings = append(state.Mappings, mapping)
+	}
+
+	sort.Slice(state.PVCSyncs, func(i, j int) bool {
+		return less(state.PVCSyncs[i].Namespace, state.PVCSyncs[i].PVC, state.PVCSyncs[j].Namespace, state.PVCSyncs[j].PVC)
+	})
+	sort.Slice(state.Locks, func(i, j int) bool {
+		return less(state.Locks[i].Namespace, state.Locks[i].PVC, state.Locks[j].Namespace, state.Locks[j].PVC)
+	})
+	sort.Slice(state.Mappings, func(i, j int) bool {
+		return less(state.Mappings[i].Namespace, state.Mappings[i].Name, state.Mappings[j].Namespace, state.Mappings[j].Name)
+	})
+	return state
+}
+
+func less(namespaceA, nameA, namespaceB, nameB string) bool {
+	if namespaceA != namespaceB {
+		return namespaceA < namespaceB
+	}
+	return nameA < nameB
+}
+
+// ServeHTTP serves the current state as JSON
+func (t *Tracker) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	_ = encoder.Encode(t.Snapshot())
+}
+
+// Handler serves the state of the default tracker
+func Handler() http.Handler {
+	return defaultTracker
+}
+
+// Snapshot returns a copy of the state of the default tracker
+func Snapshot() State {
+	return defaultTracker.Snapshot()
+}
+
+// SyncWaiting records a PVC sync waiting for a concurrency slot on the default tracker
+func SyncWaiting(namespace, pvc string) {
+	defaultTracker.SyncWaiting(namespace, pvc)
+}
+
+// SyncStarted records a PVC sync that acquired a concurrency slot on the default tracker
+func SyncStarted(namespace, pvc string) {
+	defaultTracker.SyncStarted(namespace, pvc)
+}
+
+// SyncFinished removes a PVC sync from the default tracker
+func SyncFinished(namespace, pvc string) {
+	defaultTracker.SyncFinished(namespace, pvc)
+}
+
+// LockAcquired records a PVC lock acquired by this controller on the default tracker
+func LockAcquired(namespace, pvc, owner string) {
+	defaultTracker.LockAcquired(namespace, pvc, owner)
+}
+
+// LockReleased removes a PVC lock from the default tracker
+func LockReleased(namespace, pvc string) {
+	defaultTracker.LockReleased(namespace, pvc)
+}
+
+// ReconcileStarted records the start of a NamespaceMapping reconcile on the default tracker
+func ReconcileStarted(namespace, name string) {
+	defaultTracker.ReconcileStarted(namespace, name)
+}
+
+// ReconcileFinished records the result of a NamespaceMapping reconcile on the default tracker
+func ReconcileFinished(namespace, name string, requeueAfter time.Duration, err error) {
+	defaultTracker.ReconcileFinished(namespace, name, requeueAfter, err)
+}
+
+// MappingClusters records the clusters of a NamespaceMapping on the default tracker
+func MappingClusters(namespace, name string, clusters ...string) {
+	defaultTracker.MappingClusters(namespace, name, clusters...)
+}
+
+// TrackQueue records the depth of the NamespaceMapping reconcile queue on the default tracker
+func TrackQueue(depth func() int) {
+	defaultTracker.TrackQueue(depth)
+}
+
+// ForgetMapping removes a deleted NamespaceMapping from the default tracker
+func ForgetMapping(namespace, name string) {
+	defaultTracker.ForgetMapping(namespace, name)
+}
@@ -0,0 +1,76 @@
+package syncstate
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracker(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.SyncWaiting("shop", "data")
+	tracker.SyncWaiting("app", "uploads")
+	tracker.SyncStarted("app", "uploads")
+	tracker.LockAcquired("app", "uploads", "dr-syncer-7f9c")
+	tracker.ReconcileStarted("app", "app-dr")
+
+	state := tracker.Snapshot()
+	assert.Equal(t, []PVCSync{
+		{Namespace: "app", PVC: "uploads", Phase: PhaseRunning, Since: now},
+		{Namespace: "shop", PVC: "data", Phase: PhaseWaiting, Since: now},
+	}, state.PVCSyncs)
+	assert.Equal(t, []Lock{{Namespace: "app", PVC: "uploads", Owner: "dr-syncer-7f9c", AcquiredAt: now}}, state.Locks)
+	require.Len(t, state.Mappings, 1)
+	assert.True(t, state.Mappings[0].Reconciling)
+
+	// Re-acquiring a held lock keeps its acquisition time
+	now = now.Add(time.Minute)
+	tracker.LockAcquired("app", "uploads", "dr-syncer-7f9c")
+	assert.Equal(t, now.Add(-time.Minute), tracker.Snapshot().Locks[0].AcquiredAt)
+
+	tracker.ReconcileFinished("app", "app-dr", 5*time.Minute, errors.New("destination cluster unreachable"))
+	tracker.SyncFinished("app", "uploads")
+	tracker.LockReleased("app", "uploads")
+
+	state = tracker.Snapshot()
+	assert.Equal(t, []PVCSync{{Namespace: "shop", PVC: "data", Phase: PhaseWaiting, Since: now.Add(-time.Minute)}}, state.PVCSyncs)
+	assert.Empty(t, state.Locks)
+	require.Len(t, state.Mappings, 1)
+	mapping := state.Mappings[0]
+	assert.False(t, mapping.Reconciling)
+	assert.Equal(t, int64(1), mapping.Reconciles)
+	assert.Equal(t, "1m0s", mapping.LastDuration)
+	assert.Equal(t, "destination cluster unreachable", mapping.LastError)
+	assert.Equal(t, "5m0s", mapping.RequeueAfter)
+
+	// A finished reconcile of a deleted mapping is not recorded again
+	tracker.ForgetMapping("app", "app-dr")
+	tracker.ReconcileFinished("app", "app-dr", 0, nil)
+	assert.Empty(t, tracker.Snapshot().Mappings)
+}
+
+func TestTrackerServeHTTP(t *testing.T) {
+	tracker := NewTracker()
+	tracker.SyncWaiting("app", "uploads")
+	tracker.ReconcileStarted("app", "app-dr")
+
+	recorder := httptest.NewRecorder()
+	tracker.ServeHTTP(recorder, httptest.NewRequest("GET", Path, nil))
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+
+	var state State
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &state))
+	require.Len(t, state.PVCSyncs, 1)
+	assert.Equal(t, "uploads", state.PVCSyncs[0].PVC)
+	assert.NotNil(t, state.Locks)
+	require.Len(t, state.Mappings, 1)
+	assert.Equal(t, "app-dr", state.Mappings[0].Name)
+}
@@ -204,6 +204,12 @@ func (b *NamespaceMappingBuilder) WithScaleToZero(scaleToZero bool) *NamespaceMa
 	return b
 }
 
+// WithSuspendCronJobs sets the suspend CronJobs option.
+func (b *NamespaceMappingBuilder) WithSuspendCronJobs(suspend bool) *NamespaceMappingBuilder {
+	b.nm.Spec.SuspendCronJobs = &suspend
+	return b
+}
+
 // WithPaused sets the paused state.
 func (b *NamespaceMappingBuilder) WithPaused(paused bool) *NamespaceMappingBuilder {
 	b.nm.Spec.Paused = &paused
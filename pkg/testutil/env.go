@@ -67,7 +67,7 @@ func (e *TestEnv) NewFakeClient(objs ...client.Object) client.Client {
 	return fake.NewClientBuilder().
 		WithScheme(e.Scheme).
 		WithObjects(objs...).
-		WithStatusSubresource(&drv1alpha1.RemoteCluster{}, &drv1alpha1.NamespaceMapping{}, &drv1alpha1.ClusterMapping{}).
+		WithStatusSubresource(&drv1alpha1.RemoteCluster{}, &drv1alpha1.NamespaceMapping{}, &drv1alpha1.ClusterMapping{}, &drv1alpha1.DRReadiness{}, &drv1alpha1.NamespaceMappingGenerator{}).
 		Build()
 }
 
@@ -77,7 +77,7 @@ func (e *TestEnv) NewFakeClientWithStatus(objs ...client.Object) client.Client {
 	return fake.NewClientBuilder().
 		WithScheme(e.Scheme).
 		WithObjects(objs...).
-		WithStatusSubresource(&drv1alpha1.RemoteCluster{}, &drv1alpha1.NamespaceMapping{}, &drv1alpha1.ClusterMapping{}).
+		WithStatusSubresource(&drv1alpha1.RemoteCluster{}, &drv1alpha1.NamespaceMapping{}, &drv1alpha1.ClusterMapping{}, &drv1alpha1.DRReadiness{}, &drv1alpha1.NamespaceMappingGenerator{}).
 		Build()
 }
 
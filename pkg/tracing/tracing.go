@@ -0,0 +1,135 @@
+// Package tracing exports OpenTelemetry traces of the reconciles, the PVC data sync workflows and the
+// resource syncs of the controller, so their latency can be analyzed in Jaeger or Tempo
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/supporttools/dr-syncer/pkg/version"
+)
+
+const (
+	// ServiceName is the service.name of the exported spans, OTEL_SERVICE_NAME overrides it
+	ServiceName = "dr-syncer"
+
+	tracerName = "github.com/supporttools/dr-syncer"
+)
+
+// Setup exports spans over OTLP/HTTP to the collector configured by the standard OTEL_EXPORTER_OTLP_*
+// environment variables, sampled as configured by OTEL_TRACES_SAMPLER. Spans are dropped until Setup ran.
+// The returned function flushes the pending spans on shutdown.
+func Setup(ctx context.Context) (func(context.Context) error, error) {
+	exporter, err := otlptracehttp.New(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create OTLP trace exporter: %w", err)
+	}
+
+	res, err := resource.New(ctx,
+		resource.WithAttributes(semconv.ServiceName(ServiceName), semconv.ServiceVersion(version.Version)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(sdktrace.WithBatcher(exporter), sdktrace.WithResource(res))
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// Start starts a span named name as a child of the span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it failed with err
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Trace runs fn in a span named name and records its error on the span
+func Trace(ctx context.Context, name string, fn func(context.Context) error, attrs ...attribute.KeyValue) error {
+	ctx, span := Start(ctx, name, attrs...)
+	err := fn(ctx)
+	End(span, err)
+	return err
+}
+
+type stepsKey struct{}
+
+// steps holds the span of the running step of a workflow
+type steps struct {
+	mu      sync.Mutex
+	parent  context.Context
+	current trace.Span
+}
+
+// StartWorkflow starts the span of a workflow whose consecutive steps are traced with Step. The returned
+// function ends the running step and the workflow, marking both failed with the error of the workflow.
+func StartWorkflow(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, func(error)) {
+	ctx, span := Start(ctx, name, attrs...)
+	s := &steps{parent: ctx}
+	return context.WithValue(ctx, stepsKey{}, s), func(err error) {
+		s.mu.Lock()
+		if s.current != nil {
+			End(s.current, err)
+			s.current = nil
+		}
+		s.mu.Unlock()
+		End(span, err)
+	}
+}
+
+// Step ends the running step of the workflow in ctx and starts the span of the next one. It does nothing
+// outside of a workflow.
+func Step(ctx context.Context, name string) {
+	s, ok := ctx.Value(stepsKey{}).(*steps)
+	if !ok {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		s.current.End()
+	}
+	_, s.current = Start(s.parent, name)
+}
+
+// LogHook adds the trace and span IDs of the span in the context of a log entry to its fields, so the logs
+// of a sync can be found from its trace
+type LogHook struct{}
+
+// Levels implements logrus.Hook
+func (LogHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire implements logrus.Hook
+func (LogHook) Fire(entry *logrus.Entry) error {
+	if entry.Context == nil {
+		return nil
+	}
+	spanContext := trace.SpanContextFromContext(entry.Context)
+	if !spanContext.IsValid() {
+		return nil
+	}
+	entry.Data["trace_id"] = spanContext.TraceID().String()
+	entry.Data["span_id"] = spanContext.SpanID().String()
+	return nil
+}
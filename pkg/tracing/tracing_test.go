@@ -0,0 +1,83 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// recordSpans records the spans ended during a test
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return recorder
+}
+
+func TestWorkflowSteps(t *testing.T) {
+	recorder := recordSpans(t)
+
+	ctx, end := StartWorkflow(context.Background(), "PVCSync")
+	Step(ctx, "lock")
+	Step(ctx, "rsync")
+	end(errors.New("rsync failed"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 3)
+	assert.Equal(t, "lock", spans[0].Name())
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, "rsync", spans[1].Name())
+	assert.Equal(t, codes.Error, spans[1].Status().Code, "the running step fails with the workflow")
+	assert.Equal(t, "PVCSync", spans[2].Name())
+	assert.Equal(t, codes.Error, spans[2].Status().Code)
+	for _, step := range spans[:2] {
+		assert.Equal(t, spans[2].SpanContext().SpanID(), step.Parent().SpanID())
+	}
+}
+
+func TestStepOutsideWorkflow(t *testing.T) {
+	recorder := recordSpans(t)
+	Step(context.Background(), "lock")
+	assert.Empty(t, recorder.Ended())
+}
+
+func TestTrace(t *testing.T) {
+	recorder := recordSpans(t)
+
+	require.NoError(t, Trace(context.Background(), "SyncResourceType", func(context.Context) error { return nil }))
+	require.Error(t, Trace(context.Background(), "SyncResourceType", func(context.Context) error { return errors.New("forbidden") }))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, codes.Unset, spans[0].Status().Code)
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+}
+
+func TestLogHook(t *testing.T) {
+	recordSpans(t)
+	var out bytes.Buffer
+	logger := logrus.New()
+	logger.SetOutput(&out)
+	logger.AddHook(LogHook{})
+
+	ctx, span := Start(context.Background(), "Reconcile")
+	logger.WithContext(ctx).Info("traced")
+	span.End()
+	assert.Contains(t, out.String(), "trace_id="+span.SpanContext().TraceID().String())
+	assert.Contains(t, out.String(), "span_id="+span.SpanContext().SpanID().String())
+
+	out.Reset()
+	logger.WithContext(context.Background()).Info("untraced")
+	assert.NotContains(t, out.String(), "trace_id")
+}
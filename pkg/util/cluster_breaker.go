@@ -0,0 +1,246 @@
+package util
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	// DefaultClusterFailureThreshold is the number of consecutive failed requests that opens the circuit of a cluster
+	DefaultClusterFailureThreshold = 5
+
+	// DefaultClusterCircuitCooldown is how long requests to a cluster fail fast after its circuit opened
+	DefaultClusterCircuitCooldown = time.Minute
+
+	// maxClusterCircuitCooldown caps the cooldown, which doubles every time the circuit opens again
+	maxClusterCircuitCooldown = 10 * time.Minute
+)
+
+// CircuitOpenError is returned for requests to a cluster whose circuit is open
+type CircuitOpenError struct {
+	Cluster    string
+	RetryAfter time.Duration
+}
+
+func (e *CircuitOpenError) Error() string {
+	return fmt.Sprintf("cluster %s is unavailable, requests are suspended for %s", e.Cluster, e.RetryAfter.Round(time.Second))
+}
+
+// IsCircuitOpen returns the CircuitOpenError wrapped in err
+func IsCircuitOpen(err error) (*CircuitOpenError, bool) {
+	var open *CircuitOpenError
+	if errors.As(err, &open) {
+		return open, true
+	}
+	return nil, false
+}
+
+// IsCertificateError reports whether err is a failure to verify the TLS certificate of a server, such as
+// after the API server CA of a cluster was rotated
+func IsCertificateError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var unknownAuthority x509.UnknownAuthorityError
+	var invalid x509.CertificateInvalidError
+	var hostname x509.HostnameError
+	var verification *tls.CertificateVerificationError
+	if errors.As(err, &unknownAuthority) || errors.As(err, &invalid) || errors.As(err, &hostname) || errors.As(err, &verification) {
+		return true
+	}
+	// Errors of API clients are flattened into strings by some client-go layers
+	return strings.Contains(err.Error(), "x509: ")
+}
+
+// IsUnavailable reports whether an error of a client of a cluster means the cluster could not be reached or
+// was unavailable, as opposed to an error of the request itself
+func IsUnavailable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var urlErr *url.Error
+	var netErr net.Error
+	return errors.As(err, &urlErr) || errors.As(err, &netErr) ||
+		apierrors.IsServiceUnavailable(err) || apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err)
+}
+
+// isUnavailableError reports whether a request failed because the cluster could not be reached, including
+// certificate errors, or answered with a gateway or availability error, as opposed to an API error
+func isUnavailableError(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// clusterCircuit is the state of the circuit of one cluster
+type clusterCircuit struct {
+	failures  int
+	trips     int
+	openUntil time.Time
+	probing   bool
+}
+
+// ClusterBreaker counts the consecutive failed requests to each remote cluster. Once a cluster reaches the
+// failure threshold its circuit opens and requests fail fast with a CircuitOpenError for the cooldown, so
+// a dead cluster does not hold up reconciles with connection timeouts. After the cooldown a single probe
+// request is let through; its success closes the circuit, its failure opens it again for twice as long.
+type ClusterBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	circuits  map[string]*clusterCircuit
+	listeners []func(cluster string)
+	now       func() time.Time
+}
+
+// NewClusterBreaker creates a ClusterBreaker
+func NewClusterBreaker(threshold int, cooldown time.Duration) *ClusterBreaker {
+	b := &ClusterBreaker{circuits: map[string]*clusterCircuit{}, now: time.Now}
+	b.Configure(threshold, cooldown)
+	return b
+}
+
+// RemoteClusters is the ClusterBreaker of the API servers of the RemoteClusters
+var RemoteClusters = NewClusterBreaker(DefaultClusterFailureThreshold, DefaultClusterCircuitCooldown)
+
+// Configure sets the failure threshold and cooldown, non-positive values select the defaults
+func (b *ClusterBreaker) Configure(threshold int, cooldown time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if threshold <= 0 {
+		threshold = DefaultClusterFailureThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = DefaultClusterCircuitCooldown
+	}
+	b.threshold = threshold
+	b.cooldown = cooldown
+}
+
+// OnCertificateError registers a function called with the cluster name when a request fails to verify
+// the certificate of the cluster, so clients built with an outdated CA can be dropped
+func (b *ClusterBreaker) OnCertificateError(listener func(cluster string)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+// Allow returns a CircuitOpenError when requests to the cluster must not be sent
+func (b *ClusterBreaker) Allow(cluster string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	circuit, ok := b.circuits[cluster]
+	if !ok || circuit.failures < b.threshold {
+		return nil
+	}
+	now := b.now()
+	if now.Before(circuit.openUntil) {
+		return &CircuitOpenError{Cluster: cluster, RetryAfter: circuit.openUntil.Sub(now)}
+	}
+	if circuit.probing {
+		return &CircuitOpenError{Cluster: cluster, RetryAfter: b.cooldown}
+	}
+	circuit.probing = true
+	return nil
+}
+
+// Record records the outcome of a request to the cluster, failed is true when the cluster was unavailable
+func (b *ClusterBreaker) Record(cluster string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !failed {
+		delete(b.circuits, cluster)
+		return
+	}
+	circuit, ok := b.circuits[cluster]
+	if !ok {
+		circuit = &clusterCircuit{}
+		b.circuits[cluster] = circuit
+	}
+	circuit.failures++
+	circuit.probing = false
+	if circuit.failures < b.threshold {
+		return
+	}
+
+	cooldown := b.cooldown << circuit.trips
+	if cooldown > maxClusterCircuitCooldown || cooldown <= 0 {
+		cooldown = maxClusterCircuitCooldown
+	}
+	circuit.trips++
+	circuit.openUntil = b.now().Add(cooldown)
+	log.Warnf("Circuit of cluster %s opened after %d consecutive failed requests, retrying in %s", cluster, circuit.failures, cooldown)
+}
+
+// abandon releases the probe of a cluster whose request was canceled by the caller
+func (b *ClusterBreaker) abandon(cluster string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if circuit, ok := b.circuits[cluster]; ok {
+		circuit.probing = false
+	}
+}
+
+// certificateError notifies the listeners of a certificate error of the cluster
+func (b *ClusterBreaker) certificateError(cluster string) {
+	b.mu.Lock()
+	listeners := append([]func(string){}, b.listeners...)
+	b.mu.Unlock()
+	for _, listener := range listeners {
+		listener(cluster)
+	}
+}
+
+// Instrument routes the requests of clients built from config through the circuit of the cluster
+func (b *ClusterBreaker) Instrument(cluster string, config *rest.Config) {
+	wrap := config.WrapTransport
+	config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		if wrap != nil {
+			rt = wrap(rt)
+		}
+		return &breakerRoundTripper{breaker: b, cluster: cluster, next: rt}
+	}
+}
+
+// breakerRoundTripper sends requests through the circuit of a cluster
+type breakerRoundTripper struct {
+	breaker *ClusterBreaker
+	cluster string
+	next    http.RoundTripper
+}
+
+func (rt *breakerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := rt.breaker.Allow(rt.cluster); err != nil {
+		return nil, err
+	}
+
+	resp, err := rt.next.RoundTrip(req)
+	if err != nil && req.Context().Err() != nil {
+		rt.breaker.abandon(rt.cluster)
+		return resp, err
+	}
+	if IsCertificateError(err) {
+		log.Warnf("Certificate of cluster %s could not be verified, its clients will be rebuilt from the kubeconfig secret: %v", rt.cluster, err)
+		rt.breaker.certificateError(rt.cluster)
+	}
+	rt.breaker.Record(rt.cluster, isUnavailableError(resp, err))
+	return resp, err
+}
@@ -0,0 +1,119 @@
+package util
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+func TestClusterBreaker_OpensAndRecovers(t *testing.T) {
+	now := time.Now()
+	b := NewClusterBreaker(2, time.Minute)
+	b.now = func() time.Time { return now }
+
+	b.Record("prod", true)
+	require.NoError(t, b.Allow("prod"), "below the threshold requests are sent")
+	b.Record("prod", true)
+
+	err := b.Allow("prod")
+	open, ok := IsCircuitOpen(err)
+	require.True(t, ok)
+	assert.Equal(t, "prod", open.Cluster)
+	assert.Equal(t, time.Minute, open.RetryAfter)
+	assert.NoError(t, b.Allow("dr"), "other clusters are not affected")
+
+	// After the cooldown a single probe is let through
+	now = now.Add(time.Minute)
+	require.NoError(t, b.Allow("prod"))
+	_, ok = IsCircuitOpen(b.Allow("prod"))
+	assert.True(t, ok, "only one probe runs at a time")
+
+	// A failed probe opens the circuit for twice as long
+	b.Record("prod", true)
+	open, ok = IsCircuitOpen(b.Allow("prod"))
+	require.True(t, ok)
+	assert.Equal(t, 2*time.Minute, open.RetryAfter)
+
+	// A successful probe closes it
+	now = now.Add(2 * time.Minute)
+	require.NoError(t, b.Allow("prod"))
+	b.Record("prod", false)
+	b.Record("prod", true)
+	assert.NoError(t, b.Allow("prod"))
+}
+
+func TestClusterBreaker_CooldownIsCapped(t *testing.T) {
+	now := time.Now()
+	b := NewClusterBreaker(1, 3*time.Minute)
+	b.now = func() time.Time { return now }
+
+	for i := 0; i < 5; i++ {
+		b.Record("prod", true)
+	}
+	open, ok := IsCircuitOpen(b.Allow("prod"))
+	require.True(t, ok)
+	assert.Equal(t, maxClusterCircuitCooldown, open.RetryAfter)
+}
+
+func TestIsCertificateError(t *testing.T) {
+	assert.True(t, IsCertificateError(fmt.Errorf("get pods: %w", x509.UnknownAuthorityError{})))
+	assert.True(t, IsCertificateError(errors.New(`Get "https://prod:6443/api": tls: failed to verify certificate: x509: certificate signed by unknown authority`)))
+	assert.False(t, IsCertificateError(errors.New("connection refused")))
+	assert.False(t, IsCertificateError(nil))
+}
+
+func TestClusterBreaker_Instrument(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	b := NewClusterBreaker(2, time.Minute)
+	config := &rest.Config{Host: server.URL}
+	b.Instrument("prod", config)
+	clientset, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err = clientset.Discovery().ServerVersion()
+		require.Error(t, err)
+		_, open := IsCircuitOpen(err)
+		assert.False(t, open)
+	}
+
+	// Requests fail fast without reaching the server once the circuit is open
+	status = http.StatusOK
+	_, err = clientset.Discovery().ServerVersion()
+	_, open := IsCircuitOpen(err)
+	assert.True(t, open, "unexpected error %v", err)
+}
+
+func TestClusterBreaker_CertificateErrorListener(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	var notified []string
+	b := NewClusterBreaker(5, time.Minute)
+	b.OnCertificateError(func(cluster string) { notified = append(notified, cluster) })
+
+	// The client does not trust the certificate of the test server, as after a CA rotation
+	config := &rest.Config{Host: server.URL}
+	b.Instrument("prod", config)
+	clientset, err := kubernetes.NewForConfig(config)
+	require.NoError(t, err)
+
+	_, err = clientset.Discovery().ServerVersion()
+	require.Error(t, err)
+	assert.True(t, IsCertificateError(err))
+	assert.Equal(t, []string{"prod"}, notified)
+}
@@ -0,0 +1,24 @@
+package util
+
+import (
+	"net/url"
+
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+)
+
+// NewPodExecutor returns an executor for a pod exec request URL. It streams over WebSocket and falls back
+// to SPDY when the WebSocket upgrade fails, as it does on API servers older than Kubernetes 1.30.
+// WebSocket passes through the proxies and load balancers that do not forward SPDY.
+func NewPodExecutor(config *rest.Config, url *url.URL) (remotecommand.Executor, error) {
+	websocket, err := remotecommand.NewWebSocketExecutor(config, "GET", url.String())
+	if err != nil {
+		return nil, err
+	}
+	spdy, err := remotecommand.NewSPDYExecutor(config, "POST", url)
+	if err != nil {
+		return nil, err
+	}
+	return remotecommand.NewFallbackExecutor(websocket, spdy, httpstream.IsUpgradeFailure)
+}
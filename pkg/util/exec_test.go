@@ -0,0 +1,18 @@
+package util
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/client-go/rest"
+)
+
+func TestNewPodExecutor(t *testing.T) {
+	target, err := url.Parse("https://dr.example.com/api/v1/namespaces/app/pods/app-0/exec?command=true&stdout=true")
+	require.NoError(t, err)
+	executor, err := NewPodExecutor(&rest.Config{Host: "https://dr.example.com"}, target)
+	require.NoError(t, err)
+	assert.NotNil(t, executor)
+}
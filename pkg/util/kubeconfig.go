@@ -0,0 +1,35 @@
+package util
+
+import (
+	"fmt"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// LoadKubeconfigContext parses kubeconfig data and checks that the named context exists. An empty
+// contextName selects the kubeconfig's current context.
+func LoadKubeconfigContext(data []byte, contextName string) (*clientcmdapi.Config, error) {
+	kubeconfig, err := clientcmd.Load(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	if contextName != "" {
+		if _, ok := kubeconfig.Contexts[contextName]; !ok {
+			return nil, fmt.Errorf("context %q not found in kubeconfig", contextName)
+		}
+	}
+	return kubeconfig, nil
+}
+
+// RESTConfigFromKubeconfig builds a REST config from kubeconfig data using the named context, or
+// the kubeconfig's current context when contextName is empty
+func RESTConfigFromKubeconfig(data []byte, contextName string) (*rest.Config, error) {
+	kubeconfig, err := LoadKubeconfigContext(data, contextName)
+	if err != nil {
+		return nil, err
+	}
+	overrides := &clientcmd.ConfigOverrides{CurrentContext: contextName}
+	return clientcmd.NewDefaultClientConfig(*kubeconfig, overrides).ClientConfig()
+}
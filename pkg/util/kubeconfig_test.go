@@ -0,0 +1,47 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const multiContextKubeconfig = `apiVersion: v1
+kind: Config
+current-context: prod
+clusters:
+- name: prod
+  cluster:
+    server: https://prod.example.com:6443
+- name: dr
+  cluster:
+    server: https://dr.example.com:6443
+users:
+- name: admin
+  user:
+    token: secret
+contexts:
+- name: prod
+  context:
+    cluster: prod
+    user: admin
+- name: dr
+  context:
+    cluster: dr
+    user: admin
+`
+
+func TestRESTConfigFromKubeconfig(t *testing.T) {
+	config, err := RESTConfigFromKubeconfig([]byte(multiContextKubeconfig), "")
+	require.NoError(t, err)
+	assert.Equal(t, "https://prod.example.com:6443", config.Host)
+
+	config, err = RESTConfigFromKubeconfig([]byte(multiContextKubeconfig), "dr")
+	require.NoError(t, err)
+	assert.Equal(t, "https://dr.example.com:6443", config.Host)
+	assert.Equal(t, "secret", config.BearerToken)
+
+	_, err = RESTConfigFromKubeconfig([]byte(multiContextKubeconfig), "staging")
+	assert.ErrorContains(t, err, `context "staging" not found`)
+}
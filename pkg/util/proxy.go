@@ -0,0 +1,272 @@
+package util
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+	"github.com/supporttools/dr-syncer/pkg/logging"
+	"golang.org/x/crypto/ssh"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var log = logging.SetupLogging()
+
+// DefaultSSHJumpHostKey is the secret key holding the jump host private key when none is specified
+const DefaultSSHJumpHostKey = "ssh-privatekey"
+
+// sshJumpHostDialTimeout bounds connecting to a jump host and the SSH handshake, overridden in tests
+var sshJumpHostDialTimeout = 15 * time.Second
+
+// sshTunnel forwards connections from a local listener to a target address through an SSH client
+type sshTunnel struct {
+	client   *ssh.Client
+	listener net.Listener
+	target   string
+}
+
+// sshTunnels holds the open tunnels by jump host, target and key. Each tunnel key has its own lock held
+// while the tunnel is opened, so a slow jump host does not block the clients of other clusters.
+var (
+	sshTunnelsMu    sync.Mutex
+	sshTunnels      = map[string]*sshTunnel{}
+	sshTunnelsLocks = map[string]*sync.Mutex{}
+)
+
+// ApplyProxyConfig configures a REST config to reach the cluster API through the given proxy.
+// HTTP(S) and SOCKS5 proxies are set on config.Proxy, which is honored by API clients as well
+// as the SPDY and WebSocket executors used for pod exec. SSH jump hosts are served through a local tunnel and
+// config.Host is rewritten to point at it.
+func ApplyProxyConfig(ctx context.Context, c client.Reader, config *rest.Config, proxy *drv1alpha1.ProxyConfig) error {
+	if proxy == nil {
+		return nil
+	}
+	if proxy.URL != "" && proxy.SSHJumpHost != nil {
+		return fmt.Errorf("proxy url and sshJumpHost are mutually exclusive")
+	}
+
+	if proxy.URL != "" {
+		proxyURL, err := parseProxyURL(proxy.URL)
+		if err != nil {
+			return err
+		}
+		config.Proxy = http.ProxyURL(proxyURL)
+		return nil
+	}
+
+	if proxy.SSHJumpHost != nil {
+		jump := proxy.SSHJumpHost
+		secret := &corev1.Secret{}
+		if err := c.Get(ctx, client.ObjectKey{
+			Name:      jump.KeySecretRef.Name,
+			Namespace: jump.KeySecretRef.Namespace,
+		}, secret); err != nil {
+			return fmt.Errorf("failed to get SSH jump host key secret: %w", err)
+		}
+
+		key := jump.KeySecretRef.Key
+		if key == "" {
+			key = DefaultSSHJumpHostKey
+		}
+		keyData, ok := secret.Data[key]
+		if !ok {
+			return fmt.Errorf("SSH jump host key %s not found in secret %s/%s", key, secret.Namespace, secret.Name)
+		}
+
+		return applySSHJumpHost(config, jump, keyData)
+	}
+
+	return nil
+}
+
+// parseProxyURL validates a proxy URL and returns it parsed
+func parseProxyURL(raw string) (*url.URL, error) {
+	proxyURL, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy url: %w", err)
+	}
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q, must be http, https or socks5", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, fmt.Errorf("proxy url %q has no host", raw)
+	}
+	return proxyURL, nil
+}
+
+// apiServerAddress returns the host:port of the API server referenced by a REST config host
+func apiServerAddress(host string) (*url.URL, string, error) {
+	apiURL, err := url.Parse(host)
+	if err != nil || apiURL.Host == "" {
+		return nil, "", fmt.Errorf("invalid API server host %q", host)
+	}
+	port := apiURL.Port()
+	if port == "" {
+		port = "443"
+		if apiURL.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return apiURL, net.JoinHostPort(apiURL.Hostname(), port), nil
+}
+
+// rewriteHostForTunnel points the REST config at a local tunnel address while keeping TLS
+// verification against the original API server hostname
+func rewriteHostForTunnel(config *rest.Config, apiURL *url.URL, localAddr string) {
+	if config.TLSClientConfig.ServerName == "" {
+		config.TLSClientConfig.ServerName = apiURL.Hostname()
+	}
+	tunnelURL := *apiURL
+	tunnelURL.Host = localAddr
+	config.Host = tunnelURL.String()
+}
+
+// applySSHJumpHost starts (or reuses) an SSH tunnel to the API server and rewrites the config to use it
+func applySSHJumpHost(config *rest.Config, jump *drv1alpha1.SSHJumpHostConfig, keyData []byte) error {
+	apiURL, target, err := apiServerAddress(config.Host)
+	if err != nil {
+		return err
+	}
+
+	signer, err := ssh.ParsePrivateKey(keyData)
+	if err != nil {
+		return fmt.Errorf("failed to parse SSH jump host private key: %w", err)
+	}
+
+	if jump.HostKey == "" {
+		return fmt.Errorf("no host key configured for SSH jump host %s, refusing to connect to an unverified host", jump.Address)
+	}
+	hostKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(jump.HostKey))
+	if err != nil {
+		return fmt.Errorf("failed to parse SSH jump host key: %w", err)
+	}
+
+	tunnelKey := sshTunnelKey(jump, target, signer)
+
+	// Concurrent callers of the same tunnel wait for the first one to open it
+	tunnelLock := sshTunnelLock(tunnelKey)
+	tunnelLock.Lock()
+	defer tunnelLock.Unlock()
+
+	sshTunnelsMu.Lock()
+	tunnel, ok := sshTunnels[tunnelKey]
+	sshTunnelsMu.Unlock()
+	if ok {
+		rewriteHostForTunnel(config, apiURL, tunnel.listener.Addr().String())
+		return nil
+	}
+
+	sshClient, err := dialSSHJumpHost(jump.Address, &ssh.ClientConfig{
+		User:            jump.User,
+		Auth:            []ssh.AuthMethod{ssh.PublicKeys(signer)},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Timeout:         sshJumpHostDialTimeout,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to connect to SSH jump host %s: %w", jump.Address, err)
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		sshClient.Close()
+		return fmt.Errorf("failed to start local tunnel listener: %w", err)
+	}
+
+	tunnel = &sshTunnel{client: sshClient, listener: listener, target: target}
+	sshTunnelsMu.Lock()
+	sshTunnels[tunnelKey] = tunnel
+	sshTunnelsMu.Unlock()
+	go tunnel.serve()
+	go func() {
+		// Drop the tunnel once the SSH connection is gone so the next caller reconnects
+		_ = sshClient.Wait()
+		listener.Close()
+		sshTunnelsMu.Lock()
+		if sshTunnels[tunnelKey] == tunnel {
+			delete(sshTunnels, tunnelKey)
+		}
+		sshTunnelsMu.Unlock()
+		log.Infof("SSH tunnel to %s via %s closed", target, jump.Address)
+	}()
+
+	log.Infof("Started SSH tunnel to %s via jump host %s on %s", target, jump.Address, listener.Addr().String())
+	rewriteHostForTunnel(config, apiURL, listener.Addr().String())
+	return nil
+}
+
+// dialSSHJumpHost connects to a jump host. The config timeout only bounds the TCP connection, so the
+// SSH handshake gets the same deadline to not hang on a jump host that accepts but never answers.
+func dialSSHJumpHost(address string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	conn, err := net.DialTimeout("tcp", address, config.Timeout)
+	if err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(config.Timeout))
+	sshConn, channels, requests, err := ssh.NewClientConn(conn, address, config)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return ssh.NewClient(sshConn, channels, requests), nil
+}
+
+// sshTunnelKey identifies the tunnel to target through a jump host with a client key
+func sshTunnelKey(jump *drv1alpha1.SSHJumpHostConfig, target string, signer ssh.Signer) string {
+	fingerprint := sha256.Sum256(signer.PublicKey().Marshal())
+	return fmt.Sprintf("%s@%s/%s/%s/%s", jump.User, jump.Address, target, jump.HostKey, hex.EncodeToString(fingerprint[:]))
+}
+
+// sshTunnelLock returns the lock serializing the opening of a tunnel
+func sshTunnelLock(tunnelKey string) *sync.Mutex {
+	sshTunnelsMu.Lock()
+	defer sshTunnelsMu.Unlock()
+	lock, ok := sshTunnelsLocks[tunnelKey]
+	if !ok {
+		lock = &sync.Mutex{}
+		sshTunnelsLocks[tunnelKey] = lock
+	}
+	return lock
+}
+
+// serve accepts local connections and forwards them through the SSH client
+func (t *sshTunnel) serve() {
+	for {
+		local, err := t.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer local.Close()
+			remote, err := t.client.Dial("tcp", t.target)
+			if err != nil {
+				log.Errorf("SSH tunnel failed to dial %s: %v", t.target, err)
+				return
+			}
+			defer remote.Close()
+
+			done := make(chan struct{}, 2)
+			go func() {
+				_, _ = io.Copy(remote, local)
+				done <- struct{}{}
+			}()
+			go func() {
+				_, _ = io.Copy(local, remote)
+				done <- struct{}{}
+			}()
+			<-done
+		}()
+	}
+}
@@ -0,0 +1,195 @@
+package util
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"k8s.io/client-go/rest"
+
+	drv1alpha1 "github.com/supporttools/dr-syncer/api/v1alpha1"
+)
+
+func TestApplyProxyConfig_Nil(t *testing.T) {
+	config := &rest.Config{Host: "https://api.example.com:6443"}
+	require.NoError(t, ApplyProxyConfig(context.Background(), nil, config, nil))
+	assert.Nil(t, config.Proxy)
+	assert.Equal(t, "https://api.example.com:6443", config.Host)
+}
+
+func TestApplyProxyConfig_URL(t *testing.T) {
+	testCases := []string{
+		"http://proxy.example.com:3128",
+		"https://proxy.example.com:3128",
+		"socks5://bastion.example.com:1080",
+	}
+
+	for _, proxyURL := range testCases {
+		t.Run(proxyURL, func(t *testing.T) {
+			config := &rest.Config{Host: "https://api.example.com:6443"}
+			err := ApplyProxyConfig(context.Background(), nil, config, &drv1alpha1.ProxyConfig{URL: proxyURL})
+			require.NoError(t, err)
+			require.NotNil(t, config.Proxy)
+
+			req := &http.Request{URL: &url.URL{Scheme: "https", Host: "api.example.com:6443"}}
+			got, err := config.Proxy(req)
+			require.NoError(t, err)
+			assert.Equal(t, proxyURL, got.String())
+			assert.Equal(t, "https://api.example.com:6443", config.Host, "Host should not change for URL proxies")
+		})
+	}
+}
+
+func TestApplyProxyConfig_Invalid(t *testing.T) {
+	testCases := []struct {
+		name  string
+		proxy *drv1alpha1.ProxyConfig
+	}{
+		{"unsupported scheme", &drv1alpha1.ProxyConfig{URL: "ftp://proxy:21"}},
+		{"missing host", &drv1alpha1.ProxyConfig{URL: "http://"}},
+		{"url and jump host", &drv1alpha1.ProxyConfig{
+			URL:         "http://proxy:3128",
+			SSHJumpHost: &drv1alpha1.SSHJumpHostConfig{Address: "bastion:22", User: "dr"},
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &rest.Config{Host: "https://api.example.com:6443"}
+			assert.Error(t, ApplyProxyConfig(context.Background(), nil, config, tc.proxy))
+			assert.Nil(t, config.Proxy)
+		})
+	}
+}
+
+func TestAPIServerAddress(t *testing.T) {
+	testCases := []struct {
+		host     string
+		expected string
+	}{
+		{"https://api.example.com:6443", "api.example.com:6443"},
+		{"https://api.example.com", "api.example.com:443"},
+		{"http://api.example.com", "api.example.com:80"},
+		{"https://10.0.0.1:6443/prefix", "10.0.0.1:6443"},
+	}
+
+	for _, tc := range testCases {
+		_, addr, err := apiServerAddress(tc.host)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, addr)
+	}
+
+	_, _, err := apiServerAddress("not a url")
+	assert.Error(t, err)
+}
+
+func TestRewriteHostForTunnel(t *testing.T) {
+	config := &rest.Config{Host: "https://api.example.com:6443/prefix"}
+	apiURL, _, err := apiServerAddress(config.Host)
+	require.NoError(t, err)
+
+	rewriteHostForTunnel(config, apiURL, "127.0.0.1:40000")
+	assert.Equal(t, "https://127.0.0.1:40000/prefix", config.Host)
+	assert.Equal(t, "api.example.com", config.TLSClientConfig.ServerName)
+
+	// An explicit server name from the kubeconfig is preserved
+	config = &rest.Config{Host: "https://api.example.com:6443"}
+	config.TLSClientConfig.ServerName = "kubernetes.default"
+	rewriteHostForTunnel(config, apiURL, "127.0.0.1:40000")
+	assert.Equal(t, "kubernetes.default", config.TLSClientConfig.ServerName)
+}
+
+// jumpHostKeys returns a PEM encoded client private key and a host public key in authorized_keys format
+func jumpHostKeys(t *testing.T) ([]byte, string) {
+	_, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	block, err := ssh.MarshalPrivateKey(private, "")
+	require.NoError(t, err)
+
+	hostPublic, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	hostKey, err := ssh.NewPublicKey(hostPublic)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(block), string(ssh.MarshalAuthorizedKey(hostKey))
+}
+
+func TestApplySSHJumpHost_RequiresHostKey(t *testing.T) {
+	keyData, _ := jumpHostKeys(t)
+	config := &rest.Config{Host: "https://api.example.com:6443"}
+	err := applySSHJumpHost(config, &drv1alpha1.SSHJumpHostConfig{Address: "127.0.0.1:1", User: "dr"}, keyData)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unverified host")
+	assert.Equal(t, "https://api.example.com:6443", config.Host)
+}
+
+func TestApplySSHJumpHost_SlowJumpHost(t *testing.T) {
+	previous := sshJumpHostDialTimeout
+	sshJumpHostDialTimeout = 500 * time.Millisecond
+	t.Cleanup(func() { sshJumpHostDialTimeout = previous })
+
+	// A jump host accepting connections but never completing the SSH handshake
+	silent, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { silent.Close() })
+	go func() {
+		for {
+			conn, err := silent.Accept()
+			if err != nil {
+				return
+			}
+			t.Cleanup(func() { conn.Close() })
+		}
+	}()
+
+	keyData, hostKey := jumpHostKeys(t)
+	slow := &drv1alpha1.SSHJumpHostConfig{Address: silent.Addr().String(), User: "dr", HostKey: hostKey}
+	done := make(chan error, 1)
+	go func() {
+		done <- applySSHJumpHost(&rest.Config{Host: "https://slow.example.com:6443"}, slow, keyData)
+	}()
+
+	// The tunnel of another cluster is reused while the slow jump host is dialed
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	signer, err := ssh.ParsePrivateKey(keyData)
+	require.NoError(t, err)
+	other := &drv1alpha1.SSHJumpHostConfig{Address: "bastion.example.com:22", User: "dr", HostKey: hostKey}
+	otherKey := sshTunnelKey(other, "other.example.com:6443", signer)
+	sshTunnelsMu.Lock()
+	sshTunnels[otherKey] = &sshTunnel{listener: listener}
+	sshTunnelsMu.Unlock()
+	t.Cleanup(func() {
+		sshTunnelsMu.Lock()
+		delete(sshTunnels, otherKey)
+		sshTunnelsMu.Unlock()
+	})
+
+	reused := make(chan error, 1)
+	config := &rest.Config{Host: "https://other.example.com:6443"}
+	go func() { reused <- applySSHJumpHost(config, other, keyData) }()
+	select {
+	case err := <-reused:
+		require.NoError(t, err)
+		assert.Equal(t, "https://"+listener.Addr().String(), config.Host)
+	case <-time.After(sshJumpHostDialTimeout / 2):
+		t.Fatal("a slow jump host blocked the tunnels of other clusters")
+	}
+
+	// The slow jump host gives up after the dial timeout
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("dialing the jump host did not time out")
+	}
+}